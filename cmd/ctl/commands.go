@@ -0,0 +1,153 @@
+// Copyright (C) 2026 jredh-dev. All rights reserved.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of nexus.
+//
+// nexus is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Affero General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or (at your
+// option) any later version.
+
+// commands.go implements the build, run and status subcommands.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+)
+
+// cmdBuild builds the named services in order, stopping at the first
+// failure.
+func cmdBuild(m *Manifest, names []string) int {
+	svcs, err := m.Lookup(names)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ctl: %v\n", err)
+		return 1
+	}
+	for _, s := range svcs {
+		if err := build(m.Root, s); err != nil {
+			fmt.Fprintf(os.Stderr, "ctl: build %s: %v\n", s.Name, err)
+			return 1
+		}
+	}
+	return 0
+}
+
+// build runs s's build command from root, streaming its output.
+func build(root string, s Service) error {
+	argv := strings.Fields(s.Build)
+	fmt.Fprintf(os.Stderr, "==> %s: %s\n", s.Name, s.Build)
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Dir = root
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// cmdRun builds one service and runs it in the foreground with its dev
+// environment until it exits or ctl is interrupted.
+func cmdRun(m *Manifest, name string, args []string) int {
+	svcs, err := m.Lookup([]string{name})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ctl: %v\n", err)
+		return 1
+	}
+	s := svcs[0]
+	if err := build(m.Root, s); err != nil {
+		fmt.Fprintf(os.Stderr, "ctl: build %s: %v\n", s.Name, err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	cmd := exec.CommandContext(ctx, filepath.Join(m.Root, s.Binary), append(s.Args, args...)...)
+	cmd.Dir = m.Root
+	data := filepath.Join(m.Root, "bin")
+	if err := os.MkdirAll(data, 0o755); err != nil {
+		fmt.Fprintf(os.Stderr, "ctl: %v\n", err)
+		return 1
+	}
+	cmd.Env = s.Environ(data)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	// Interrupting ctl interrupts the service, so it shuts down cleanly.
+	cmd.Cancel = func() error { return cmd.Process.Signal(os.Interrupt) }
+	cmd.WaitDelay = 15 * time.Second
+	fmt.Fprintf(os.Stderr, "==> running %s\n", s.Name)
+	if err := cmd.Run(); err != nil {
+		var exit *exec.ExitError
+		if errors.As(err, &exit) && ctx.Err() == nil {
+			return exit.ExitCode()
+		}
+		if ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "ctl: run %s: %v\n", s.Name, err)
+			return 1
+		}
+	}
+	return 0
+}
+
+// cmdStatus prints whether each named service is built and answering
+// its health check.
+func cmdStatus(m *Manifest, names []string) int {
+	if len(names) == 0 {
+		names = []string{"all"}
+	}
+	svcs, err := m.Lookup(names)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ctl: %v\n", err)
+		return 1
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "SERVICE\tBUILT\tHEALTH\tADDRESS")
+	for _, s := range svcs {
+		built := "no"
+		if fi, err := os.Stat(filepath.Join(m.Root, s.Binary)); err == nil {
+			built = fi.ModTime().Format("2006-01-02 15:04")
+		}
+		health := "-"
+		if s.Health != "" {
+			health = "down"
+			if err := checkHealth(s.Health, 2*time.Second); err == nil {
+				health = "up"
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", s.Name, built, health, s.Health)
+	}
+	tw.Flush()
+	return 0
+}
+
+// checkHealth returns nil if addr, an http:// URL or tcp://host:port,
+// answers within timeout.
+func checkHealth(addr string, timeout time.Duration) error {
+	if hostport, ok := strings.CutPrefix(addr, "tcp://"); ok {
+		conn, err := net.DialTimeout("tcp", hostport, timeout)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(addr)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", addr, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+// Copyright (C) 2026 jredh-dev. All rights reserved.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of nexus.
+//
+// nexus is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Affero General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or (at your
+// option) any later version.
+
+// db.go implements `ctl db migrate` and `ctl db seed`, which run each
+// service's own migration and demo data code in a one-off process against
+// the data directory `ctl run` uses, or another one given with -data.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// cmdDb handles `ctl db migrate|seed [-data dir] <service...|all>`.
+func cmdDb(m *Manifest, args []string) int {
+	if len(args) == 0 || (args[0] != "migrate" && args[0] != "seed") {
+		fmt.Fprintln(os.Stderr, "usage: ctl db migrate|seed [-data dir] <service...|all>")
+		return 1
+	}
+	op := args[0]
+	fs := flag.NewFlagSet("db "+op, flag.ContinueOnError)
+	data := fs.String("data", filepath.Join(m.Root, "bin"), "data directory the services' ${DATA} points at")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 1
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintf(os.Stderr, "usage: ctl db %s [-data dir] <service...|all>\n", op)
+		return 1
+	}
+	svcs, err := m.Lookup(fs.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ctl: %v\n", err)
+		return 1
+	}
+	if err := os.MkdirAll(*data, 0o755); err != nil {
+		fmt.Fprintf(os.Stderr, "ctl: %v\n", err)
+		return 1
+	}
+
+	status := 0
+	for _, s := range svcs {
+		if err := dbTask(m.Root, s, op, *data); err != nil {
+			fmt.Fprintf(os.Stderr, "ctl: %s %s: %v\n", op, s.Name, err)
+			status = 1
+		}
+	}
+	return status
+}
+
+// dbTask migrates or seeds one service, building it first if it has
+// arguments for op. A service seeded through its API must already be up.
+func dbTask(root string, s Service, op, data string) error {
+	argv := s.Migrate
+	if op == "seed" {
+		argv = s.Seed
+	}
+	if len(argv) == 0 {
+		if _, ok := seeds[s.Name]; op == "seed" && ok {
+			if err := checkHealth(s.Health, 2*time.Second); err != nil {
+				return fmt.Errorf("seeded through its API, so it must be running (ctl run %s): %w", s.Name, err)
+			}
+			if err := seedAPI(s); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "==> %s: seeded through %s\n", s.Name, s.BaseURL())
+			return nil
+		}
+		fmt.Fprintf(os.Stderr, "==> %s: nothing to %s\n", s.Name, op)
+		return nil
+	}
+	if err := build(root, s); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "==> %s: %s\n", s.Name, op)
+	return runOnce(root, s, argv, data, os.Stderr)
+}
+
+// runOnce runs s's binary with args and its environment for data, sending
+// its output to w, and waits for it to exit.
+func runOnce(root string, s Service, args []string, data string, w io.Writer) error {
+	cmd := exec.Command(filepath.Join(root, s.Binary), args...)
+	cmd.Dir = root
+	cmd.Env = s.Environ(data)
+	cmd.Stdout = w
+	cmd.Stderr = w
+	return cmd.Run()
+}
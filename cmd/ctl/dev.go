@@ -0,0 +1,334 @@
+// Copyright (C) 2026 jredh-dev. All rights reserved.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of nexus.
+//
+// nexus is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Affero General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or (at your
+// option) any later version.
+
+// dev.go implements `ctl dev up` and `ctl dev down`: every service at
+// once, with its dev defaults, fresh databases and demo data, its logs
+// interleaved on one terminal.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// devStateFile records a running `ctl dev up`, relative to the repo root,
+// for `ctl dev down` to find it.
+const devStateFile = "bin/dev.json"
+
+// devState is what devStateFile holds.
+type devState struct {
+	PID      int            `json:"pid"`      // the ctl dev up process
+	Data     string         `json:"data"`     // the temporary data directory
+	Services map[string]int `json:"services"` // pid of each service
+}
+
+// cmdDev dispatches `ctl dev up` and `ctl dev down`.
+func cmdDev(m *Manifest, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: ctl dev up [service...] | ctl dev down")
+		return 1
+	}
+	switch args[0] {
+	case "up":
+		return devUp(m, args[1:])
+	case "down":
+		return devDown(m)
+	default:
+		fmt.Fprintf(os.Stderr, "ctl: unknown dev command %q\n", args[0])
+		return 1
+	}
+}
+
+// devUp builds and starts the named services, or all of them, and streams
+// their logs until they exit or ctl is interrupted or told to stop by
+// `ctl dev down`, then stops them and removes their data.
+func devUp(m *Manifest, names []string) int {
+	if len(names) == 0 {
+		names = []string{"all"}
+	}
+	svcs, err := m.Lookup(names)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ctl: %v\n", err)
+		return 1
+	}
+	statePath := filepath.Join(m.Root, devStateFile)
+	if st, err := readDevState(statePath); err == nil && alive(st.PID) {
+		fmt.Fprintf(os.Stderr, "ctl: dev environment already up (pid %d); run 'ctl dev down' first\n", st.PID)
+		return 1
+	}
+	for _, s := range svcs {
+		if err := build(m.Root, s); err != nil {
+			fmt.Fprintf(os.Stderr, "ctl: build %s: %v\n(name the services to start to leave it out)\n", s.Name, err)
+			return 1
+		}
+	}
+
+	data, err := os.MkdirTemp("", "nexus-dev-")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ctl: %v\n", err)
+		return 1
+	}
+	defer os.RemoveAll(data)
+
+	// Signals are caught from before the first service starts, so none is
+	// left behind.
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sig)
+
+	logs := newMux(os.Stdout, colorEnabled(os.Stdout), svcs)
+	st := devState{PID: os.Getpid(), Data: data, Services: map[string]int{}}
+	var running []*exec.Cmd
+	exited := make(chan string, len(svcs))
+	var wg sync.WaitGroup
+	for _, s := range svcs {
+		w := logs.Writer(s.Name)
+		dir := filepath.Join(data, s.Name)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			fmt.Fprintf(w, "ctl: %v\n", err)
+			continue
+		}
+		if len(s.Seed) > 0 {
+			if err := runOnce(m.Root, s, s.Seed, dir, w); err != nil {
+				fmt.Fprintf(w, "ctl: seed demo data: %v\n", err)
+			}
+			w.Flush()
+		}
+		cmd := exec.Command(filepath.Join(m.Root, s.Binary), s.Args...)
+		cmd.Dir = m.Root
+		cmd.Env = s.Environ(dir)
+		cmd.Stdout = w
+		cmd.Stderr = w
+		if err := cmd.Start(); err != nil {
+			fmt.Fprintf(w, "ctl: start: %v\n", err)
+			continue
+		}
+		running = append(running, cmd)
+		st.Services[s.Name] = cmd.Process.Pid
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := cmd.Wait()
+			w.Flush()
+			if err != nil {
+				fmt.Fprintf(w, "ctl: exited: %v\n", err)
+			} else {
+				fmt.Fprintln(w, "ctl: exited")
+			}
+			exited <- s.Name
+		}()
+		go seedWhenUp(s, w)
+	}
+	if err := writeDevState(statePath, st); err != nil {
+		fmt.Fprintf(os.Stderr, "ctl: %v\n", err)
+	}
+	defer os.Remove(statePath)
+	fmt.Fprintf(os.Stderr, "ctl: %d services up, data in %s; Ctrl-C or 'ctl dev down' stops them\n", len(running), data)
+
+	for left := len(running); left > 0; left-- {
+		select {
+		case <-sig:
+			fmt.Fprintln(os.Stderr, "ctl: stopping services")
+			kill := stopAll(running)
+			wg.Wait()
+			kill.Stop()
+			return 0
+		case <-exited:
+		}
+	}
+	wg.Wait()
+	return 1
+}
+
+// stopAll interrupts each service so it shuts down cleanly. Those still
+// running after devStopTimeout are killed, unless the returned timer is
+// stopped first.
+func stopAll(cmds []*exec.Cmd) *time.Timer {
+	for _, cmd := range cmds {
+		cmd.Process.Signal(os.Interrupt)
+	}
+	return time.AfterFunc(devStopTimeout, func() {
+		for _, cmd := range cmds {
+			cmd.Process.Kill()
+		}
+	})
+}
+
+// devStopTimeout is how long a service gets to shut down once told to.
+const devStopTimeout = 15 * time.Second
+
+// devDown stops the running `ctl dev up`: it asks it to stop its services
+// and waits for it, or, if it died, stops them itself.
+func devDown(m *Manifest) int {
+	statePath := filepath.Join(m.Root, devStateFile)
+	st, err := readDevState(statePath)
+	if errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintln(os.Stderr, "ctl: no dev environment is up")
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ctl: %v\n", err)
+		return 1
+	}
+
+	if alive(st.PID) {
+		if p, err := os.FindProcess(st.PID); err == nil {
+			p.Signal(os.Interrupt)
+		}
+		deadline := time.Now().Add(devStopTimeout + 5*time.Second)
+		for alive(st.PID) && time.Now().Before(deadline) {
+			time.Sleep(200 * time.Millisecond)
+		}
+		if alive(st.PID) {
+			fmt.Fprintf(os.Stderr, "ctl: dev environment (pid %d) didn't stop\n", st.PID)
+			return 1
+		}
+		fmt.Fprintln(os.Stderr, "ctl: dev environment stopped")
+		return 0
+	}
+
+	// ctl dev up is gone; clean up after it.
+	for name, pid := range st.Services {
+		if !alive(pid) {
+			continue
+		}
+		if p, err := os.FindProcess(pid); err == nil {
+			p.Signal(os.Interrupt)
+			fmt.Fprintf(os.Stderr, "ctl: stopped %s (pid %d)\n", name, pid)
+		}
+	}
+	os.RemoveAll(st.Data)
+	os.Remove(statePath)
+	return 0
+}
+
+// alive reports whether process pid exists.
+func alive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return p.Signal(syscall.Signal(0)) == nil
+}
+
+func readDevState(path string) (devState, error) {
+	var st devState
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return st, err
+	}
+	if err := json.Unmarshal(b, &st); err != nil {
+		return st, fmt.Errorf("%s: %w", path, err)
+	}
+	return st, nil
+}
+
+func writeDevState(path string, st devState) error {
+	b, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
+}
+
+// mux interleaves the services' logs on one writer, a line at a time,
+// each prefixed with its service's name in its own color.
+type mux struct {
+	mu    sync.Mutex
+	out   io.Writer
+	color bool
+	width int
+	index map[string]int // service name to position, for its color
+}
+
+// colors are the ANSI colors services' names cycle through.
+var colors = []string{"36", "33", "32", "35", "34", "31"}
+
+func newMux(out io.Writer, color bool, svcs []Service) *mux {
+	m := &mux{out: out, color: color, index: map[string]int{}}
+	for i, s := range svcs {
+		m.index[s.Name] = i
+		m.width = max(m.width, len(s.Name))
+	}
+	return m
+}
+
+// Writer returns the writer for name's log.
+func (m *mux) Writer(name string) *lineWriter {
+	prefix := fmt.Sprintf("%-*s | ", m.width, name)
+	if m.color {
+		prefix = "\x1b[" + colors[m.index[name]%len(colors)] + "m" + prefix + "\x1b[0m"
+	}
+	return &lineWriter{mux: m, prefix: []byte(prefix)}
+}
+
+// lineWriter writes one service's output to its mux, holding back a
+// partial line until it is finished so lines never interleave.
+type lineWriter struct {
+	mux    *mux
+	prefix []byte
+	mu     sync.Mutex
+	buf    []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.emit(w.buf[:i+1])
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush writes out a final line left without a newline.
+func (w *lineWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.buf) > 0 {
+		w.emit(append(w.buf, '\n'))
+		w.buf = nil
+	}
+}
+
+func (w *lineWriter) emit(line []byte) {
+	w.mux.mu.Lock()
+	defer w.mux.mu.Unlock()
+	w.mux.out.Write(w.prefix)
+	w.mux.out.Write(line)
+}
+
+// colorEnabled reports whether to color output to f: it is a terminal
+// and NO_COLOR isn't set.
+func colorEnabled(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
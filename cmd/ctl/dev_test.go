@@ -0,0 +1,22 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMux(t *testing.T) {
+	var out bytes.Buffer
+	m := newMux(&out, false, []Service{{Name: "cal"}, {Name: "portal"}})
+	cal, portal := m.Writer("cal"), m.Writer("portal")
+
+	cal.Write([]byte("one\ntw"))
+	portal.Write([]byte("hello\n"))
+	cal.Write([]byte("o\nthree"))
+	cal.Flush()
+
+	want := "cal    | one\nportal | hello\ncal    | two\ncal    | three\n"
+	if out.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright (C) 2026 jredh-dev. All rights reserved.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of nexus.
+//
+// nexus is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Affero General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or (at your
+// option) any later version.
+
+// ctl is the nexus control CLI: it builds, runs and checks the monorepo's
+// services from one place, so developing them doesn't take remembering
+// each one's path, build command and environment. What it knows about
+// each service comes from services.toml at the repo root.
+//
+// Usage:
+//
+//	ctl list                     List the services in the manifest
+//	ctl build <service...|all>   Build services
+//	ctl run <service> [args]     Build a service and run it in the foreground
+//	ctl status [service...]      Show which services are built and up
+//	ctl dev up [service...]      Start services with fresh data and demo content
+//	ctl dev down                 Stop what 'ctl dev up' started
+//	ctl db migrate <service...>  Apply a service's migrations to its dev data
+//	ctl db seed <service...>     Load a service's demo data into its dev data
+//	ctl smoke [service...]       Start services and walk each one's golden path
+//
+// Examples:
+//
+//	ctl build all                # build every service
+//	ctl run cal                  # run cal on its dev port with its dev env
+//	ctl run hermit --grpc-port 9190
+//	ctl status                   # what's built, what's answering
+//	ctl dev up portal cal        # just those two, logs interleaved
+//	ctl db seed -data /tmp/env all
+//	ctl smoke -running           # smoke test what 'ctl dev up' started
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(1)
+	}
+	cmd, args := os.Args[1], os.Args[2:]
+	switch cmd {
+	case "help", "--help", "-h", "-?":
+		printUsage()
+		return
+	case "list", "build", "run", "status", "dev", "db", "smoke":
+	default:
+		fmt.Fprintf(os.Stderr, "ctl: unknown command %q\n\n", cmd)
+		printUsage()
+		os.Exit(1)
+	}
+
+	m, err := loadManifest()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ctl: %v\n", err)
+		os.Exit(1)
+	}
+	switch cmd {
+	case "list":
+		cmdList(m)
+	case "build":
+		if len(args) == 0 {
+			fmt.Fprintln(os.Stderr, "usage: ctl build <service...|all>")
+			os.Exit(1)
+		}
+		os.Exit(cmdBuild(m, args))
+	case "run":
+		if len(args) == 0 {
+			fmt.Fprintln(os.Stderr, "usage: ctl run <service> [args]")
+			os.Exit(1)
+		}
+		os.Exit(cmdRun(m, args[0], args[1:]))
+	case "status":
+		os.Exit(cmdStatus(m, args))
+	case "dev":
+		os.Exit(cmdDev(m, args))
+	case "db":
+		os.Exit(cmdDb(m, args))
+	case "smoke":
+		os.Exit(cmdSmoke(m, args))
+	}
+}
+
+// cmdList prints the services in the manifest.
+func cmdList(m *Manifest) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "SERVICE\tDESCRIPTION\tHEALTH")
+	for _, s := range m.Services {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", s.Name, s.Description, s.Health)
+	}
+	tw.Flush()
+}
+
+// printUsage prints the top-level help text.
+func printUsage() {
+	fmt.Fprintf(os.Stderr, `ctl — nexus control CLI
+
+Usage:
+  ctl list                     List the services in services.toml
+  ctl build <service...|all>   Build services
+  ctl run <service> [args]     Build a service and run it with its dev environment
+  ctl status [service...]      Show which services are built and answering
+  ctl dev up [service...]      Start services (default all) with fresh databases
+                               and demo data, logs interleaved; Ctrl-C stops them
+  ctl dev down                 Stop what 'ctl dev up' started
+  ctl db migrate [-data dir] <service...|all>
+                               Apply services' migrations (data default bin/)
+  ctl db seed [-data dir] <service...|all>
+                               Load services' demo data; secrets must be running
+  ctl smoke [-running] [service...]
+                               Start services (default all) with fresh data, walk
+                               each one's golden path and print a pass/fail table;
+                               -running tests services that are already up
+
+Environment variables already set override a service's dev defaults.
+`)
+}
@@ -0,0 +1,177 @@
+// Copyright (C) 2026 jredh-dev. All rights reserved.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of nexus.
+//
+// nexus is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Affero General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or (at your
+// option) any later version.
+
+// manifest.go loads services.toml, the manifest at the repo root that
+// says how to build, run and health-check each service.
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// manifestFile is the manifest's name, at the repo root.
+const manifestFile = "services.toml"
+
+// Service is one entry of the manifest.
+type Service struct {
+	Name        string            `toml:"name"`
+	Description string            `toml:"description"`
+	Build       string            `toml:"build"`  // command, split on spaces, run from the repo root
+	Binary      string            `toml:"binary"` // what Build produces, relative to the repo root
+	Args        []string          `toml:"args"`
+	Migrate     []string          `toml:"migrate"` // arguments that make the binary apply its migrations and exit
+	Seed        []string          `toml:"seed"`    // arguments that make the binary load its demo data and exit
+	Health      string            `toml:"health"`  // http:// URL or tcp://host:port
+	Env         map[string]string `toml:"env"`     // dev defaults; the environment wins; ${DATA} is the data directory
+}
+
+// Manifest is the parsed services.toml.
+type Manifest struct {
+	Root     string    `toml:"-"` // the repo root it was read from
+	Services []Service `toml:"service"`
+}
+
+// loadManifest finds the repo root and reads its manifest.
+func loadManifest() (*Manifest, error) {
+	root, err := findRepoRoot()
+	if err != nil {
+		return nil, err
+	}
+	return readManifest(root)
+}
+
+// readManifest reads and checks root's manifest.
+func readManifest(root string) (*Manifest, error) {
+	path := filepath.Join(root, manifestFile)
+	var m Manifest
+	if _, err := toml.DecodeFile(path, &m); err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	m.Root = root
+	seen := map[string]bool{}
+	for _, s := range m.Services {
+		switch {
+		case s.Name == "":
+			return nil, fmt.Errorf("%s: a service has no name", path)
+		case seen[s.Name]:
+			return nil, fmt.Errorf("%s: service %q is listed twice", path, s.Name)
+		case s.Build == "" || s.Binary == "":
+			return nil, fmt.Errorf("%s: service %q needs build and binary", path, s.Name)
+		case s.Health != "" && !strings.HasPrefix(s.Health, "http://") && !strings.HasPrefix(s.Health, "tcp://"):
+			return nil, fmt.Errorf("%s: service %q: health %q must be an http:// or tcp:// address", path, s.Name, s.Health)
+		}
+		seen[s.Name] = true
+	}
+	return &m, nil
+}
+
+// Lookup returns the named services, or every service for "all".
+func (m *Manifest) Lookup(names []string) ([]Service, error) {
+	if len(names) == 1 && names[0] == "all" {
+		return m.Services, nil
+	}
+	var out []Service
+	for _, name := range names {
+		s, ok := m.service(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown service %q (have %s)", name, strings.Join(m.Names(), ", "))
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+func (m *Manifest) service(name string) (Service, bool) {
+	for _, s := range m.Services {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Service{}, false
+}
+
+// BaseURL returns the scheme and host of s's HTTP health check, where its
+// API is, or "" if it has none.
+func (s Service) BaseURL() string {
+	u, err := url.Parse(s.Health)
+	if err != nil || u.Scheme != "http" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// Names returns the service names in manifest order.
+func (m *Manifest) Names() []string {
+	names := make([]string, len(m.Services))
+	for i, s := range m.Services {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// Environ returns the environment to run s with: the current one, plus
+// s's defaults for whatever it doesn't set, with ${DATA} in them replaced
+// by data, the directory its databases go in.
+func (s Service) Environ(data string) []string {
+	env := os.Environ()
+	keys := make([]string, 0, len(s.Env))
+	for k := range s.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if _, set := os.LookupEnv(k); !set {
+			env = append(env, k+"="+strings.ReplaceAll(s.Env[k], "${DATA}", data))
+		}
+	}
+	return env
+}
+
+// findRepoRoot returns the nexus checkout containing the working
+// directory, or else $WORK_SOURCE/jredh-dev/nexus, so ctl works from
+// anywhere.
+func findRepoRoot() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for {
+		if isNexusRoot(dir) {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	if ws := os.Getenv("WORK_SOURCE"); ws != "" {
+		if root := filepath.Join(ws, "jredh-dev", "nexus"); isNexusRoot(root) {
+			return root, nil
+		}
+	}
+	return "", fmt.Errorf("not in a nexus checkout, and none at $WORK_SOURCE/jredh-dev/nexus")
+}
+
+// isNexusRoot returns true if dir contains a go.mod with the nexus module.
+func isNexusRoot(dir string) bool {
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return false
+	}
+	return strings.Contains(string(data), "module github.com/jredh-dev/nexus")
+}
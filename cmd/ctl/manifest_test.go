@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// TestRepoManifest checks that the checked-in services.toml parses and
+// lists every service with a health check.
+func TestRepoManifest(t *testing.T) {
+	m, err := readManifest(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"portal", "secrets", "cal", "hermit", "sms-sender", "notify", "gateway"}
+	for _, name := range want {
+		if _, ok := m.service(name); !ok {
+			t.Errorf("services.toml has no %q", name)
+		}
+	}
+	for _, s := range m.Services {
+		if s.Health == "" {
+			t.Errorf("%s: no health check", s.Name)
+		}
+	}
+	if _, err := m.Lookup([]string{"nope"}); err == nil {
+		t.Error("Lookup(nope) succeeded")
+	}
+	all, _ := m.Lookup([]string{"all"})
+	if len(all) != len(m.Services) {
+		t.Errorf("Lookup(all) = %d services, want %d", len(all), len(m.Services))
+	}
+}
+
+func TestEnviron(t *testing.T) {
+	t.Setenv("CTL_TEST_SET", "mine")
+	os.Unsetenv("CTL_TEST_UNSET")
+	s := Service{Env: map[string]string{"CTL_TEST_SET": "default", "CTL_TEST_UNSET": "${DATA}/x.db"}}
+	env := s.Environ("/tmp/data")
+	if !slices.Contains(env, "CTL_TEST_UNSET=/tmp/data/x.db") {
+		t.Error("unset variable didn't get its default")
+	}
+	if slices.Contains(env, "CTL_TEST_SET=default") || !slices.Contains(env, "CTL_TEST_SET=mine") {
+		t.Error("set variable was overridden by its default")
+	}
+}
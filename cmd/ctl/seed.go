@@ -0,0 +1,100 @@
+// Copyright (C) 2026 jredh-dev. All rights reserved.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of nexus.
+//
+// nexus is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Affero General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or (at your
+// option) any later version.
+
+// seed.go loads demo data into running services that can't seed
+// themselves out of process (their manifest entry has no seed arguments),
+// through their own APIs.
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// seeds holds the API demo data loader of each service that needs one.
+// secrets keeps everything in memory, so it can only be seeded while up.
+var seeds = map[string]func(c *seedClient) error{
+	"secrets": seedSecrets,
+}
+
+// devUpTimeout is how long a service has to pass its health check
+// before its demo data is given up on.
+const devUpTimeout = 60 * time.Second
+
+// seedWhenUp waits for s to pass its health check and loads its demo
+// data through its API if it has a loader in seeds, reporting to w.
+func seedWhenUp(s Service, w io.Writer) {
+	if s.Health == "" {
+		return
+	}
+	if err := waitHealthy(s.Health, devUpTimeout); err != nil {
+		fmt.Fprintf(w, "ctl: %v\n", err)
+		return
+	}
+	fmt.Fprintf(w, "ctl: up at %s\n", s.Health)
+	if _, ok := seeds[s.Name]; !ok {
+		return
+	}
+	if err := seedAPI(s); err != nil {
+		fmt.Fprintf(w, "ctl: seed demo data: %v\n", err)
+		return
+	}
+	fmt.Fprintln(w, "ctl: seeded demo data")
+}
+
+// waitHealthy polls the health check addr until it passes or timeout is
+// up.
+func waitHealthy(addr string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		err := checkHealth(addr, time.Second)
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("not healthy after %s: %w", timeout, err)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+// seedAPI loads s's demo data through its API, which must be up.
+func seedAPI(s Service) error {
+	seed, ok := seeds[s.Name]
+	if !ok || s.BaseURL() == "" {
+		return fmt.Errorf("%s has no API seed", s.Name)
+	}
+	return seed(&seedClient{base: s.BaseURL(), http: &http.Client{Timeout: 10 * time.Second}})
+}
+
+// seedClient posts demo data to one service.
+type seedClient struct {
+	base string
+	http *http.Client
+}
+
+// postJSON posts body to path and decodes the response into out, if set.
+func (c *seedClient) postJSON(path string, body, out any) error {
+	return postJSON(context.Background(), c.http, c.base+path, body, out)
+}
+
+// seedSecrets submits a few secrets, one of them twice so it shows a
+// count above one.
+func seedSecrets(c *seedClient) error {
+	for _, v := range []string{"I never read the terms", "I still use tabs", "I never read the terms"} {
+		if err := c.postJSON("/api/secrets", map[string]string{"value": v, "submitted_by": "demo"}, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,485 @@
+// Copyright (C) 2026 jredh-dev. All rights reserved.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+//
+// This file is part of nexus.
+//
+// nexus is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Affero General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or (at your
+// option) any later version.
+
+// smoke.go implements `ctl smoke`: boot the services with fresh data and
+// walk each one's golden path from the outside, the way a user would, to
+// catch what the per-service tests can't see — a binary that won't start,
+// a route that isn't mounted, an environment that doesn't fit together.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+
+	pb "github.com/jredh-dev/nexus/cmd/tui/proto"
+	"github.com/jredh-dev/nexus/internal/secretsmgr"
+)
+
+// smokeCheck is one service's golden path.
+type smokeCheck struct {
+	name string
+	run  func(ctx context.Context, s Service) error
+}
+
+// smokes holds the golden path of each service that has one; the others
+// only have to pass their health check.
+var smokes = map[string]smokeCheck{
+	"portal":  {"signup+login", smokePortal},
+	"cal":     {"feed+subscribe", smokeCal},
+	"secrets": {"submit+get", smokeSecrets},
+	"hermit":  {"kv roundtrip", smokeHermit},
+}
+
+// smokeTimeout bounds one service's golden path once it is up.
+const smokeTimeout = 30 * time.Second
+
+// smokeResult is the outcome of smoke testing one service.
+type smokeResult struct {
+	service string
+	check   string
+	took    time.Duration
+	err     error
+}
+
+// cmdSmoke handles `ctl smoke [-running] [service...]`.
+func cmdSmoke(m *Manifest, args []string) int {
+	fs := flag.NewFlagSet("smoke", flag.ContinueOnError)
+	running := fs.Bool("running", false, "test services that are already up (e.g. by 'ctl dev up') instead of starting them")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	names := fs.Args()
+	if len(names) == 0 {
+		names = []string{"all"}
+	}
+	svcs, err := m.Lookup(names)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ctl: %v\n", err)
+		return 1
+	}
+
+	var logs map[string]string
+	wait := devUpTimeout
+	if *running {
+		wait = 2 * time.Second
+	} else {
+		stop, l, err := smokeStart(m, svcs)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ctl: %v\n", err)
+			return 1
+		}
+		defer stop()
+		logs = l
+	}
+
+	results := make([]smokeResult, len(svcs))
+	for i, s := range svcs {
+		results[i] = smokeOne(s, wait)
+	}
+
+	failed := printSmoke(os.Stdout, results)
+	for _, r := range results {
+		if r.err != nil && logs[r.service] != "" {
+			fmt.Fprintf(os.Stderr, "\n==> %s log (last lines)\n%s", r.service, tail(logs[r.service], 20))
+		}
+	}
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// smokeStart builds svcs and starts them on fresh data in a temporary
+// directory, each logging to a file there. It returns a function that
+// stops them and removes the directory, and each service's log path.
+func smokeStart(m *Manifest, svcs []Service) (stop func(), logs map[string]string, err error) {
+	for _, s := range svcs {
+		if s.Health != "" && checkHealth(s.Health, time.Second) == nil {
+			return nil, nil, fmt.Errorf("%s is already up at %s; stop it or use -running", s.Name, s.Health)
+		}
+	}
+	for _, s := range svcs {
+		if err := build(m.Root, s); err != nil {
+			return nil, nil, fmt.Errorf("build %s: %w", s.Name, err)
+		}
+	}
+	data, err := os.MkdirTemp("", "nexus-smoke-")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	logs = map[string]string{}
+	var cmds []*exec.Cmd
+	var done []chan struct{}
+	stop = func() {
+		kill := stopAll(cmds)
+		for _, ch := range done {
+			<-ch
+		}
+		kill.Stop()
+		os.RemoveAll(data)
+	}
+	for _, s := range svcs {
+		dir := filepath.Join(data, s.Name)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			stop()
+			return nil, nil, err
+		}
+		logs[s.Name] = filepath.Join(data, s.Name+".log")
+		f, err := os.Create(logs[s.Name])
+		if err != nil {
+			stop()
+			return nil, nil, err
+		}
+		cmd := exec.Command(filepath.Join(m.Root, s.Binary), s.Args...)
+		cmd.Dir = m.Root
+		cmd.Env = s.Environ(dir)
+		cmd.Stdout = f
+		cmd.Stderr = f
+		if err := cmd.Start(); err != nil {
+			f.Close()
+			stop()
+			return nil, nil, fmt.Errorf("start %s: %w", s.Name, err)
+		}
+		ch := make(chan struct{})
+		go func() {
+			cmd.Wait()
+			f.Close()
+			close(ch)
+		}()
+		cmds = append(cmds, cmd)
+		done = append(done, ch)
+	}
+	fmt.Fprintf(os.Stderr, "==> started %d services, data in %s\n", len(cmds), data)
+	return stop, logs, nil
+}
+
+// smokeOne waits up to wait for s to come up and walks its golden path.
+func smokeOne(s Service, wait time.Duration) (r smokeResult) {
+	check, ok := smokes[s.Name]
+	if !ok {
+		check = smokeCheck{name: "health"}
+	}
+	r = smokeResult{service: s.Name, check: check.name}
+	start := time.Now()
+	defer func() { r.took = time.Since(start) }()
+
+	if s.Health == "" && check.run == nil {
+		r.check, r.err = "-", errSkipped
+		return r
+	}
+	if s.Health != "" {
+		if r.err = waitHealthy(s.Health, wait); r.err != nil {
+			return r
+		}
+	}
+	if check.run != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), smokeTimeout)
+		defer cancel()
+		r.err = check.run(ctx, s)
+	}
+	return r
+}
+
+// errSkipped marks a service with nothing to check.
+var errSkipped = errors.New("nothing to check")
+
+// printSmoke writes the results table to w and returns how many failed.
+func printSmoke(w io.Writer, results []smokeResult) int {
+	failed, skipped := 0, 0
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "SERVICE\tCHECK\tRESULT\tTIME\tDETAIL")
+	for _, r := range results {
+		result, detail := "pass", ""
+		switch {
+		case errors.Is(r.err, errSkipped):
+			result, detail = "skip", r.err.Error()
+			skipped++
+		case r.err != nil:
+			result, detail = "FAIL", r.err.Error()
+			failed++
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", r.service, r.check, result, r.took.Round(time.Millisecond), detail)
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "\n%d passed, %d failed, %d skipped\n", len(results)-failed-skipped, failed, skipped)
+	return failed
+}
+
+// tail returns the last n lines of the file at path.
+func tail(path string, n int) string {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err.Error() + "\n"
+	}
+	lines := strings.SplitAfter(string(b), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "")
+}
+
+// smokeID returns a suffix unique to this run, so the golden paths can be
+// walked again against the same data with -running.
+func smokeID() string {
+	return strconv.FormatInt(time.Now().UnixNano(), 10)
+}
+
+// noRedirect is an HTTP client that hands redirects back, for checking
+// where a form post sends the browser.
+func noRedirect(jar http.CookieJar) *http.Client {
+	return &http.Client{
+		Jar:           jar,
+		Timeout:       10 * time.Second,
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+}
+
+// smokePortal signs up a new user, logs in as them with a fresh session
+// and reads their profile back.
+func smokePortal(ctx context.Context, s Service) error {
+	id := smokeID()
+	email := "smoke-" + id + "@example.com"
+	form := url.Values{
+		"username": {"smoke" + id},
+		"email":    {email},
+		"phone":    {"+1" + id[len(id)-10:]},
+		"password": {"smoke-" + id},
+		"name":     {"Smoke Test"},
+	}
+	if err := portalPost(ctx, noRedirect(nil), s.BaseURL()+"/signup", form); err != nil {
+		return fmt.Errorf("signup: %w", err)
+	}
+
+	jar, _ := cookiejar.New(nil)
+	c := noRedirect(jar)
+	login := url.Values{"email": {email}, "password": form["password"]}
+	if err := portalPost(ctx, c, s.BaseURL()+"/login", login); err != nil {
+		return fmt.Errorf("login: %w", err)
+	}
+	var me struct {
+		Email string `json:"email"`
+	}
+	if err := getJSON(ctx, c, s.BaseURL()+"/api/me/", &me); err != nil {
+		return fmt.Errorf("me: %w", err)
+	}
+	if me.Email != email {
+		return fmt.Errorf("me: logged in as %q, want %q", me.Email, email)
+	}
+	return nil
+}
+
+// portalPost posts a form to portal, which answers success by
+// redirecting to the dashboard and failure by redirecting back with
+// ?error=.
+func portalPost(ctx context.Context, c *http.Client, target string, form url.Values) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	loc := resp.Header.Get("Location")
+	if resp.StatusCode != http.StatusSeeOther || loc != "/dashboard" {
+		if u, err := url.Parse(loc); err == nil && u.Query().Get("error") != "" {
+			return errors.New(u.Query().Get("error"))
+		}
+		return fmt.Errorf("%s, redirected to %q", resp.Status, loc)
+	}
+	return nil
+}
+
+// smokeCal creates a feed with one event and subscribes to it.
+func smokeCal(ctx context.Context, s Service) error {
+	c := &http.Client{Timeout: 10 * time.Second}
+	var feed struct {
+		ID  string `json:"id"`
+		URL string `json:"url"`
+	}
+	if err := postJSON(ctx, c, s.BaseURL()+"/api/feeds", map[string]string{"name": "Smoke " + smokeID()}, &feed); err != nil {
+		return fmt.Errorf("create feed: %w", err)
+	}
+	start := time.Now().UTC().Add(24 * time.Hour).Truncate(time.Hour)
+	event := map[string]string{
+		"feed_id": feed.ID,
+		"summary": "Smoke test",
+		"start":   start.Format(time.RFC3339),
+		"end":     start.Add(time.Hour).Format(time.RFC3339),
+	}
+	if err := postJSON(ctx, c, s.BaseURL()+"/api/events", event, nil); err != nil {
+		return fmt.Errorf("create event: %w", err)
+	}
+
+	body, err := get(ctx, c, s.BaseURL()+feed.URL)
+	if err != nil {
+		return fmt.Errorf("subscribe: %w", err)
+	}
+	if !bytes.HasPrefix(body, []byte("BEGIN:VCALENDAR")) || !bytes.Contains(body, []byte("SUMMARY:Smoke test")) {
+		return fmt.Errorf("subscribe: %s is not a calendar with the event", feed.URL)
+	}
+	return nil
+}
+
+// smokeSecrets submits a secret and reads it back by ID.
+func smokeSecrets(ctx context.Context, s Service) error {
+	c := &http.Client{Timeout: 10 * time.Second}
+	value := "smoke test " + smokeID()
+	var sub struct {
+		Secret struct {
+			ID string `json:"id"`
+		} `json:"secret"`
+	}
+	if err := postJSON(ctx, c, s.BaseURL()+"/api/secrets", map[string]string{"value": value, "submitted_by": "smoke"}, &sub); err != nil {
+		return fmt.Errorf("submit: %w", err)
+	}
+	var got struct {
+		Value string `json:"value"`
+	}
+	if err := getJSON(ctx, c, s.BaseURL()+"/api/secrets/"+url.PathEscape(sub.Secret.ID), &got); err != nil {
+		return fmt.Errorf("get: %w", err)
+	}
+	if got.Value != value {
+		return fmt.Errorf("get: value %q, want %q", got.Value, value)
+	}
+	return nil
+}
+
+// smokeHermit sets a key, reads it back and deletes it. HERMIT_SECRET, if
+// set, is sent as the shared secret.
+func smokeHermit(ctx context.Context, s Service) error {
+	addr, ok := strings.CutPrefix(s.Health, "tcp://")
+	if !ok {
+		return fmt.Errorf("health %q is not tcp://host:port", s.Health)
+	}
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	secret, _, err := secretsmgr.Default().Lookup(ctx, "HERMIT_SECRET")
+	if err != nil {
+		return err
+	}
+	if secret != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-hermit-secret", secret)
+	}
+	c := pb.NewHermitClient(conn)
+
+	key, value := "ctl-smoke-"+smokeID(), []byte("ok")
+	set, err := c.KvSet(ctx, &pb.KvSetRequest{Key: key, Value: value})
+	if err != nil {
+		return fmt.Errorf("set: %w", err)
+	}
+	if !set.GetOk() {
+		return fmt.Errorf("set: %s", set.GetError())
+	}
+	got, err := c.KvGet(ctx, &pb.KvGetRequest{Key: key})
+	if err != nil {
+		return fmt.Errorf("get: %w", err)
+	}
+	if !got.GetFound() || !bytes.Equal(got.GetValue(), value) {
+		return fmt.Errorf("get: found=%v value=%q, want %q", got.GetFound(), got.GetValue(), value)
+	}
+	if _, err := c.KvDelete(ctx, &pb.KvDeleteRequest{Key: key}); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+	return nil
+}
+
+// postJSON posts body as JSON and decodes a 2xx response into out, if set.
+func postJSON(ctx context.Context, c *http.Client, target string, body, out any) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return doJSON(c, req, out)
+}
+
+// getJSON decodes the 2xx response to a GET of target into out.
+func getJSON(ctx context.Context, c *http.Client, target string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	return doJSON(c, req, out)
+}
+
+// get returns the body of a 2xx response to a GET of target.
+func get(ctx context.Context, c *http.Client, target string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err := httpError(resp); err != nil {
+		return nil, err
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func doJSON(c *http.Client, req *http.Request, out any) error {
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := httpError(resp); err != nil {
+		return err
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
+
+// httpError returns an error carrying the status and start of the body
+// of a non-2xx response.
+func httpError(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("%s %s: %s: %s", resp.Request.Method, resp.Request.URL.Path, resp.Status, strings.TrimSpace(string(msg)))
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	pb "github.com/jredh-dev/nexus/cmd/tui/proto"
+)
+
+// kvServer is a hermit that only does KvSet, KvGet and KvDelete.
+type kvServer struct {
+	pb.UnimplementedHermitServer
+	mu sync.Mutex
+	kv map[string][]byte
+}
+
+func (s *kvServer) KvSet(_ context.Context, req *pb.KvSetRequest) (*pb.KvSetResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.kv[req.Key] = req.Value
+	return &pb.KvSetResponse{Ok: true}, nil
+}
+
+func (s *kvServer) KvGet(_ context.Context, req *pb.KvGetRequest) (*pb.KvGetResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.kv[req.Key]
+	return &pb.KvGetResponse{Found: ok, Value: v}, nil
+}
+
+func (s *kvServer) KvDelete(_ context.Context, req *pb.KvDeleteRequest) (*pb.KvDeleteResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.kv, req.Key)
+	return &pb.KvDeleteResponse{}, nil
+}
+
+func TestSmokeHermit(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer()
+	kv := &kvServer{kv: map[string][]byte{}}
+	pb.RegisterHermitServer(srv, kv)
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := smokeHermit(ctx, Service{Name: "hermit", Health: "tcp://" + lis.Addr().String()}); err != nil {
+		t.Fatalf("smokeHermit: %v", err)
+	}
+	if len(kv.kv) != 0 {
+		t.Errorf("left keys behind: %v", kv.kv)
+	}
+}
+
+func TestPrintSmoke(t *testing.T) {
+	var out bytes.Buffer
+	failed := printSmoke(&out, []smokeResult{
+		{service: "cal", check: "feed+subscribe", took: 12 * time.Millisecond},
+		{service: "portal", check: "signup+login", err: errors.New("login: Invalid email or password.")},
+		{service: "worker", check: "-", err: errSkipped},
+	})
+	if failed != 1 {
+		t.Errorf("failed = %d, want 1", failed)
+	}
+	for _, want := range []string{"cal ", "pass", "FAIL  ", "Invalid email", "skip", "1 passed, 1 failed, 1 skipped"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
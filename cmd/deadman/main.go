@@ -41,13 +41,17 @@ import (
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jredh-dev/nexus/internal/deadman"
+	"github.com/jredh-dev/nexus/internal/logging"
+	"github.com/jredh-dev/nexus/internal/secretsmgr"
 )
 
 //go:embed PRIVACY.txt
 var privacyPolicy string
 
 func main() {
-	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo})))
+	if err := logging.Setup("deadman"); err != nil {
+		fatalf("%v", err)
+	}
 
 	args := os.Args[1:]
 	if len(args) == 0 || isHelp(args[0]) {
@@ -430,7 +434,7 @@ type appConfig struct {
 func loadConfig() appConfig {
 	c := appConfig{
 		twilioSID:   os.Getenv("TWILIO_ACCOUNT_SID"),
-		twilioToken: os.Getenv("TWILIO_AUTH_TOKEN"),
+		twilioToken: secretsmgr.Get("TWILIO_AUTH_TOKEN"),
 		twilioFrom:  os.Getenv("TWILIO_FROM"),
 		port:        os.Getenv("PORT"),
 		databaseURL: os.Getenv("DATABASE_URL"),
@@ -14,47 +14,110 @@
 package main
 
 import (
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"path/filepath"
 
+	"github.com/jredh-dev/nexus/internal/config"
+	"github.com/jredh-dev/nexus/internal/conversation"
 	"github.com/jredh-dev/nexus/internal/handlers"
+	"github.com/jredh-dev/nexus/internal/health"
+	"github.com/jredh-dev/nexus/internal/httpserver"
+	"github.com/jredh-dev/nexus/internal/logging"
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
+)
+
+var (
+	version   = "dev"
+	commit    = "unknown"
+	buildDate = "unknown"
 )
 
 func main() {
-	// Serve static files (CSS, JS, images)
-	fs := http.FileServer(http.Dir("./static"))
-	http.Handle("/images/", http.StripPrefix("/images/", http.FileServer(http.Dir("./static/images"))))
-	http.Handle("/css/", http.StripPrefix("/css/", http.FileServer(http.Dir("./static/css"))))
-	http.Handle("/js/", http.StripPrefix("/js/", http.FileServer(http.Dir("./static/js"))))
-
-	// Homepage
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path == "/" {
-			http.ServeFile(w, r, "./static/index.html")
-			return
-		}
-		// For any other path, try serving from static
-		fs.ServeHTTP(w, r)
-	})
+	showVersion := flag.Bool("version", false, "Show version information")
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Printf("nascent-nexus %s\n", version)
+		fmt.Printf("Commit: %s\n", commit)
+		fmt.Printf("Built: %s\n", buildDate)
+		os.Exit(0)
+	}
 
-	// Health check endpoint
-	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		fmt.Fprintf(w, "OK")
+	if err := logging.Setup("nascent-nexus"); err != nil {
+		fmt.Fprintf(os.Stderr, "nascent-nexus: %v\n", err)
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nascent-nexus: %v\n", err)
+		os.Exit(1)
+	}
+	slog.Info("config", "settings", cfg.Settings)
+	responder, err := newResponder(cfg)
+	if err != nil {
+		logging.Fatal("responder", "err", err)
+	}
+	// Replies go out through sms-outbox when there is Kafka, and inline
+	// in the webhook's TwiML when there isn't.
+	var pipeline *conversation.Pipeline
+	if len(cfg.Kafka.Brokers) > 0 {
+		outbox := smsoutbox.NewKafkaPublisher(cfg.Kafka.Brokers, cfg.Kafka.OutboxTopic)
+		defer outbox.Close()
+		pipeline = conversation.New(responder, outbox)
+		slog.Info("replying through sms-outbox", "topic", cfg.Kafka.OutboxTopic, "brokers", cfg.Kafka.Brokers)
+	} else {
+		pipeline = conversation.New(responder, nil)
+		slog.Warn("replying inline", "hint", "set NEXUS_KAFKA_BROKERS")
+	}
+
+	checks := health.New()
+	if len(cfg.Kafka.Brokers) > 0 {
+		checks.Ready("kafka", health.Kafka(cfg.Kafka.Brokers))
+	}
+
+	srv := httpserver.New(
+		httpserver.WithHealth(checks),
+		httpserver.WithVersion(httpserver.Version{Service: "nascent-nexus", Version: version, Commit: commit, Built: buildDate}),
+	)
+	r := srv.Router
+
+	// SMS webhook (Twilio POSTs here)
+	r.Post("/sms", handlers.SMSHandler(pipeline))
+
+	// Website: the homepage, and anything else from the static directory
+	static := http.FileServer(http.Dir(cfg.StaticDir))
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, filepath.Join(cfg.StaticDir, "index.html"))
 	})
+	r.Get("/*", static.ServeHTTP)
 
-	// SMS webhook endpoint (Twilio will POST here)
-	http.HandleFunc("/sms", handlers.SMSHandler)
+	addr := ":" + cfg.Port
+	slog.Info("nascent-nexus starting", "addr", addr, "version", version, "responder", cfg.Responder,
+		"website", "http://localhost"+addr,
+		"sms", "http://localhost"+addr+"/sms")
 
-	// Start server
-	port := "8080"
-	fmt.Printf("🚀 nexus server starting on port %s\n", port)
-	fmt.Printf("🌐 Website available at: http://localhost:%s\n", port)
-	fmt.Printf("📱 SMS webhook available at: http://localhost:%s/sms\n", port)
-	fmt.Printf("💚 Health check at: http://localhost:%s/health\n", port)
+	if err := srv.ListenAndServe(addr); err != nil {
+		logging.Fatal("server", "err", err)
+	}
+}
 
-	if err := http.ListenAndServe(":"+port, nil); err != nil {
-		log.Fatalf("Server failed to start: %v", err)
+// newResponder returns the Responder NEXUS_RESPONDER names. With "llm",
+// the keyword rules still answer first and the model answers the rest.
+func newResponder(cfg *config.Config) (conversation.Responder, error) {
+	switch cfg.Responder {
+	case "rules":
+		return conversation.DefaultRules(), nil
+	case "llm":
+		rules := conversation.DefaultRules()
+		rules.Default = ""
+		llm := conversation.NewLLM(cfg.LLM.URL, cfg.LLM.APIKey, cfg.LLM.Model, cfg.LLM.SystemPrompt)
+		return conversation.Chain{rules, llm}, nil
+	default:
+		return nil, fmt.Errorf("NEXUS_RESPONDER %q: want rules or llm", cfg.Responder)
 	}
 }
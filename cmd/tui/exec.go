@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jredh-dev/nexus/cmd/tui/internal/app"
+)
+
+// execUser is who tui exec logs in and submits secrets as.
+const execUser = "operator"
+
+// runExec runs the command in cfg.Args and returns the exit status: 0 on
+// success, 1 if the command failed, 2 for usage errors.
+func runExec(cfg config) int {
+	line := strings.Join(cfg.Args, " ")
+	if strings.TrimSpace(line) == "" {
+		fmt.Fprintln(os.Stderr, `usage: tui exec [--json] "<command>"`)
+		fmt.Fprintln(os.Stderr, "commands: kv:set kv:setex kv:get kv:del kv:exists kv:ttl kv:cas kv:incr kv:list sql:insert sql:query sql:count sql:delete sql:update db:snapshot db:restore limits limits:set limits:clear replication buckets bucket:create diag bench:tcp stats "+app.ExecCommands)
+		return 2
+	}
+
+	var (
+		h   app.HermitClient
+		s   app.SecretsClient
+		err error
+	)
+	if cfg.Demo {
+		h, s = app.NewDemoHermitClient(), app.NewDemoSecretsClient()
+	} else {
+		var t app.HermitTLS
+		if t, err = cfg.hermitTLS(); err == nil {
+			h, err = app.NewHermitClient(cfg.HermitAddr, cfg.Secret, t)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "tui: hermit dial: %v\n", err)
+			return 1
+		}
+		s = app.NewSecretsClient(cfg.SecretsURL)
+	}
+	defer h.Close()
+	cfg.useBucket(h)
+
+	// Secrets commands don't need hermit, so don't make them wait on it.
+	if !strings.HasPrefix(strings.ToLower(line), "secrets:") {
+		if _, err := h.Login(execUser, cfg.Token); err != nil {
+			fmt.Fprintf(os.Stderr, "tui: login: %v\n", err)
+			return 1
+		}
+		if tw, ok := h.(app.TLSWarner); ok && tw.TLSWarning() != "" {
+			fmt.Fprintf(os.Stderr, "tui: warning: TLS not verified: %s\n", tw.TLSWarning())
+		}
+	}
+
+	res, err := app.Exec(h, s, execUser, line)
+	if werr := writeExecResult(os.Stdout, res, cfg.JSON); werr != nil {
+		fmt.Fprintf(os.Stderr, "tui: %v\n", werr)
+		return 1
+	}
+	if err != nil {
+		if !cfg.JSON {
+			fmt.Fprintf(os.Stderr, "tui: %v\n", err)
+		}
+		return 1
+	}
+	return 0
+}
+
+// writeExecResult prints res as JSON, or its output as plain text.
+func writeExecResult(w io.Writer, res app.ExecResult, asJSON bool) error {
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(res)
+	}
+	if res.Output == "" {
+		return nil
+	}
+	_, err := fmt.Fprintln(w, res.Output)
+	return err
+}
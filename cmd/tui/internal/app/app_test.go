@@ -4,13 +4,28 @@
 package app_test
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/jredh-dev/nexus/cmd/tui/internal/app"
 	pb "github.com/jredh-dev/nexus/cmd/tui/proto"
@@ -20,42 +35,141 @@ import (
 
 type mockHermit struct {
 	loginErr   error
+	role       string // Login's
+	loginToken string // as last passed to Login
+	logins     int
 	serverInfo *pb.ServerInfoResponse
 	serverErr  error
 	benchResp  *pb.BenchmarkResponse
 	benchErr   error
+	benchCalls atomic.Int32
 	dbStats    *pb.DbStatsResponse
 	dbStatsErr error
 	kvSetOK    bool
 	kvGetFound bool
 	kvGetValue []byte
 	kvListKeys []string
+	kvTTLs     map[string]time.Duration // as last passed to KvSet
+	kvVersion  uint64                   // of every key, for KvGet and Txn guards
+	txnFails   int                      // Txns to fail before guards are checked
+	txns       int
+	sqlRows    []*pb.SqlRow
+	sqlReq     *pb.SqlQueryRequest // the last SqlQuery
+	metrics    *pb.MetricsResponse
+
+	// What each BenchmarkStream sends; nil makes it unsupported.
+	benchStream []*pb.BenchmarkProgress
+	benchIters  atomic.Int32 // asked of BenchmarkStream, across calls
+	benchOpens  atomic.Int32
 }
 
-func (m *mockHermit) Login(_, _ string) error { return m.loginErr }
+func (m *mockHermit) Login(_, token string) (string, error) {
+	m.loginToken = token
+	m.logins++
+	return m.role, m.loginErr
+}
 func (m *mockHermit) ServerInfo() (*pb.ServerInfoResponse, error) {
 	return m.serverInfo, m.serverErr
 }
 func (m *mockHermit) Benchmark(_, _ uint32) (*pb.BenchmarkResponse, error) {
+	m.benchCalls.Add(1)
 	return m.benchResp, m.benchErr
 }
-func (m *mockHermit) KvSet(_ string, _ []byte) (*pb.KvSetResponse, error) {
+func (m *mockHermit) BenchmarkStream(_ context.Context, iterations, _ uint32) (app.BenchProgressStream, error) {
+	if m.benchStream == nil {
+		return nil, app.ErrUnsupported
+	}
+	m.benchOpens.Add(1)
+	m.benchIters.Add(int32(iterations))
+	return &benchStream{msgs: m.benchStream}, nil
+}
+func (m *mockHermit) KvSet(key string, _ []byte, ttl time.Duration) (*pb.KvSetResponse, error) {
+	if m.kvTTLs == nil {
+		m.kvTTLs = map[string]time.Duration{}
+	}
+	m.kvTTLs[key] = ttl
 	return &pb.KvSetResponse{Ok: m.kvSetOK}, nil
 }
 func (m *mockHermit) KvGet(_ string) (*pb.KvGetResponse, error) {
-	return &pb.KvGetResponse{Found: m.kvGetFound, Value: m.kvGetValue}, nil
+	return &pb.KvGetResponse{Found: m.kvGetFound, Value: m.kvGetValue, Version: m.kvVersion}, nil
+}
+func (m *mockHermit) KvList(prefix, cursor string, limit uint32) (*pb.KvListResponse, error) {
+	var keys []string
+	for _, k := range m.kvListKeys {
+		if strings.HasPrefix(k, prefix) && k > cursor {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	if limit == 0 || len(keys) <= int(limit) {
+		return &pb.KvListResponse{Keys: keys}, nil
+	}
+	return &pb.KvListResponse{Keys: keys[:limit], NextCursor: keys[limit-1]}, nil
+}
+func (m *mockHermit) KvDelete(key string) (*pb.KvDeleteResponse, error) {
+	i := slices.Index(m.kvListKeys, key)
+	if i >= 0 {
+		m.kvListKeys = slices.Delete(m.kvListKeys, i, i+1)
+	}
+	return &pb.KvDeleteResponse{Deleted: i >= 0}, nil
+}
+func (m *mockHermit) KvExists(key string) (*pb.KvExistsResponse, error) {
+	return &pb.KvExistsResponse{Exists: slices.Contains(m.kvListKeys, key)}, nil
 }
-func (m *mockHermit) KvList() (*pb.KvListResponse, error) {
-	return &pb.KvListResponse{Keys: m.kvListKeys}, nil
+func (m *mockHermit) KvTTL(key string) (*pb.KvTTLResponse, error) {
+	ttl, ok := m.kvTTLs[key]
+	if !ok {
+		return &pb.KvTTLResponse{Found: slices.Contains(m.kvListKeys, key)}, nil
+	}
+	return &pb.KvTTLResponse{Found: true, Expires: ttl > 0, TtlMs: uint64(ttl.Milliseconds())}, nil
+}
+func (m *mockHermit) Txn(guards []*pb.TxnGuard, writes []*pb.TxnWrite) (*pb.TxnResponse, error) {
+	m.txns++
+	if m.txnFails > 0 {
+		m.txnFails--
+		return &pb.TxnResponse{}, nil
+	}
+	for i, g := range guards {
+		if g.Version != nil && *g.Version != m.kvVersion {
+			return &pb.TxnResponse{FailedGuard: uint32(i)}, nil
+		}
+	}
+	resp := &pb.TxnResponse{Ok: true}
+	for range writes {
+		m.kvVersion++
+		resp.Versions = append(resp.Versions, m.kvVersion)
+	}
+	return resp, nil
 }
 func (m *mockHermit) SqlInsert(_, _ string) (*pb.SqlInsertResponse, error) {
 	return &pb.SqlInsertResponse{Queued: true}, nil
 }
-func (m *mockHermit) SqlQuery(_ string, _ uint32) (*pb.SqlQueryResponse, error) {
-	return &pb.SqlQueryResponse{}, nil
+func (m *mockHermit) SqlQuery(req *pb.SqlQueryRequest) (*pb.SqlQueryResponse, error) {
+	m.sqlReq = req
+	n := uint64(len(m.sqlRows))
+	if req.CountOnly {
+		return &pb.SqlQueryResponse{TotalCommitted: n, Matched: n}, nil
+	}
+	return &pb.SqlQueryResponse{Rows: m.sqlRows, TotalCommitted: n, Matched: n}, nil
+}
+func (m *mockHermit) SqlDelete(key string) (*pb.SqlDeleteResponse, error) {
+	return &pb.SqlDeleteResponse{Rows: m.sqlMatching(key)}, nil
+}
+func (m *mockHermit) SqlUpdate(key, _ string) (*pb.SqlUpdateResponse, error) {
+	return &pb.SqlUpdateResponse{Rows: m.sqlMatching(key)}, nil
+}
+func (m *mockHermit) sqlMatching(key string) uint64 {
+	var n uint64
+	for _, r := range m.sqlRows {
+		if r.Key == key {
+			n++
+		}
+	}
+	return n
 }
 func (m *mockHermit) DbStats() (*pb.DbStatsResponse, error) { return m.dbStats, m.dbStatsErr }
 func (m *mockHermit) Close()                                {}
+func (m *mockHermit) Metrics() (*pb.MetricsResponse, error) { return m.metrics, nil }
 
 // --- Test helpers ---
 
@@ -208,6 +322,134 @@ func TestDBConsole_KvSet(t *testing.T) {
 	hasContent(t, m, "after kv:set")
 }
 
+func TestDBConsole_KvDelete(t *testing.T) {
+	h := &mockHermit{
+		serverInfo: &pb.ServerInfoResponse{},
+		dbStats:    &pb.DbStatsResponse{},
+		kvListKeys: []string{"alpha", "apple", "beta"},
+	}
+	m := doLogin(app.New("localhost:9090", "", h, nil).WithToastDuration(time.Millisecond))
+	m, cmd := pressEnter(m) // Hermit DB; warms the key cache
+	m = runBatch(m, cmd)
+
+	run := func(line string) string {
+		for _, c := range line {
+			m, _ = sendKey(m, c)
+		}
+		m, cmd = pressEnter(m)
+		m, cmd = runCmd(m, cmd)
+		m = runBatch(m, cmd) // stats refresh and toast
+		return ansi.Strip(m.View().Content)
+	}
+
+	if v := run("kv:del alpha"); !strings.Contains(v, `DELETED  key="alpha"`) {
+		t.Errorf("kv:del alpha:\n%s", v)
+	}
+	if v := run("kv:del alpha"); !strings.Contains(v, `NOT FOUND  key="alpha"`) {
+		t.Errorf("deleting again should report the key missing:\n%s", v)
+	}
+	if v := run("kv:exists beta"); !strings.Contains(v, `EXISTS  key="beta"`) {
+		t.Errorf("kv:exists beta:\n%s", v)
+	}
+
+	// The deleted key is no longer offered.
+	for _, c := range "kv:del a" {
+		m, _ = sendKey(m, c)
+	}
+	m, _ = mustModel2(m.Update(tea.KeyPressMsg{Code: tea.KeyTab}))
+	if v := m.View().Content; !strings.Contains(v, "kv:del apple █") {
+		t.Errorf("want only apple to complete:\n%s", v)
+	}
+}
+
+func TestDBConsole_KvTTL(t *testing.T) {
+	h := &mockHermit{
+		serverInfo: &pb.ServerInfoResponse{},
+		dbStats:    &pb.DbStatsResponse{},
+		kvSetOK:    true,
+		kvListKeys: []string{"plain"},
+	}
+	m := doLogin(app.New("localhost:9090", "", h, nil).WithToastDuration(time.Millisecond))
+	m, cmd := pressEnter(m) // Hermit DB
+	m = runBatch(m, cmd)
+
+	run := func(line string) string {
+		for _, c := range line {
+			m, _ = sendKey(m, c)
+		}
+		m, cmd = pressEnter(m)
+		m, cmd = runCmd(m, cmd)
+		m = runBatch(m, cmd)
+		return ansi.Strip(m.View().Content)
+	}
+
+	if v := run("kv:setex sess 90s token abc"); !strings.Contains(v, `OK  key="sess" ttl=1m30s`) {
+		t.Errorf("kv:setex:\n%s", v)
+	}
+	if h.kvTTLs["sess"] != 90*time.Second {
+		t.Errorf("KvSet got ttl %v, want 1m30s", h.kvTTLs["sess"])
+	}
+	if v := run("kv:ttl sess"); !strings.Contains(v, `TTL  key="sess" ttl=1m30s`) {
+		t.Errorf("kv:ttl sess:\n%s", v)
+	}
+	if v := run("kv:ttl plain"); !strings.Contains(v, `NO EXPIRY  key="plain"`) {
+		t.Errorf("kv:ttl plain:\n%s", v)
+	}
+	if v := run("kv:ttl nope"); !strings.Contains(v, `NOT FOUND  key="nope"`) {
+		t.Errorf("kv:ttl nope:\n%s", v)
+	}
+	if v := run("kv:setex sess soon x"); !strings.Contains(v, `ttl "soon"`) {
+		t.Errorf("a bad ttl should be rejected:\n%s", v)
+	}
+
+	// A plain kv:set clears the TTL.
+	run("kv:set sess again")
+	if ttl, ok := h.kvTTLs["sess"]; !ok || ttl != 0 {
+		t.Errorf("kv:set passed ttl %v, want 0", ttl)
+	}
+}
+
+func TestDBConsole_CasAndIncr(t *testing.T) {
+	h := &mockHermit{
+		serverInfo: &pb.ServerInfoResponse{},
+		dbStats:    &pb.DbStatsResponse{},
+		kvGetFound: true,
+		kvGetValue: []byte("41"),
+		kvVersion:  7,
+		txnFails:   1, // another client gets in first once
+	}
+	m := doLogin(app.New("localhost:9090", "", h, nil).WithToastDuration(time.Millisecond))
+	m, cmd := pressEnter(m) // Hermit DB
+	m = runBatch(m, cmd)
+
+	run := func(line string) string {
+		for _, c := range line {
+			m, _ = sendKey(m, c)
+		}
+		m, cmd = pressEnter(m)
+		m, cmd = runCmd(m, cmd)
+		m = runBatch(m, cmd)
+		return ansi.Strip(m.View().Content)
+	}
+
+	if v := run("kv:incr hits"); !strings.Contains(v, `OK  key="hits" value=42 version=8`) {
+		t.Errorf("kv:incr:\n%s", v)
+	}
+	if h.txns != 2 {
+		t.Errorf("kv:incr made %d Txns, want a retry after the conflict", h.txns)
+	}
+	if v := run("kv:cas hits 3 x"); !strings.Contains(v, `CONFLICT  key="hits" is not at version 3`) {
+		t.Errorf("kv:cas at a stale version:\n%s", v)
+	}
+	if v := run("kv:cas hits 8 x"); !strings.Contains(v, `OK  key="hits" version=9`) {
+		t.Errorf("kv:cas at the current version:\n%s", v)
+	}
+	h.kvGetValue = []byte("many")
+	if v := run("kv:incr hits"); !strings.Contains(v, `value "many" is not an integer`) {
+		t.Errorf("kv:incr on a non-integer:\n%s", v)
+	}
+}
+
 func TestDBConsole_EscReturns(t *testing.T) {
 	h := &mockHermit{serverInfo: &pb.ServerInfoResponse{}, dbStats: &pb.DbStatsResponse{}}
 	m := app.New("localhost:9090", "", h, nil)
@@ -221,11 +463,45 @@ func TestDBConsole_EscReturns(t *testing.T) {
 	hasContent(t, m, "after esc from DB console")
 }
 
+func TestDBConsole_ScrollHistory(t *testing.T) {
+	h := &mockHermit{serverInfo: &pb.ServerInfoResponse{}, dbStats: &pb.DbStatsResponse{}}
+	m := app.New("localhost:9090", "", h, nil)
+	m = doLogin(m)
+
+	m, cmd := pressEnter(m)
+	m, _ = runCmd(m, cmd)
+
+	// More history than fits on screen.
+	for i := 0; i < 40; i++ {
+		for _, c := range "help" {
+			m, _ = sendKey(m, c)
+		}
+		m, cmd = pressEnter(m)
+		m, _ = runCmd(m, cmd)
+	}
+	if !strings.Contains(m.View().Content, "following") {
+		t.Fatal("expected history to follow the tail")
+	}
+
+	next, _ := m.Update(tea.KeyPressMsg{Code: tea.KeyPgUp})
+	m = mustModel(next)
+	if strings.Contains(m.View().Content, "following") {
+		t.Error("expected PgUp to stop following")
+	}
+
+	next, _ = m.Update(tea.KeyPressMsg{Code: 'f', Mod: tea.ModCtrl})
+	m = mustModel(next)
+	if !strings.Contains(m.View().Content, "following") {
+		t.Error("expected ctrl+f to resume following")
+	}
+}
+
 // --- Secrets panel tests (httptest.Server) ---
 
 type secretsState struct {
 	secrets []app.Secret
 	stats   app.SecretsStats
+	lenses  map[string]string // exposing lens by value; nil serves only the text wall
 }
 
 func newSecretsTestServer(t *testing.T) (*httptest.Server, *secretsState) {
@@ -261,6 +537,30 @@ func newSecretsTestServer(t *testing.T) (*httptest.Server, *secretsState) {
 			json.NewEncoder(w).Encode(result) //nolint:errcheck
 		}
 	})
+	mux.HandleFunc("/api/exposed", func(w http.ResponseWriter, r *http.Request) {
+		var exposed []string
+		page := app.ExposedPage{Entries: []app.ExposedEntry{}}
+		for _, s := range state.secrets {
+			if !s.IsSecret() {
+				exposed = append(exposed, s.Value)
+				page.Entries = append(page.Entries, app.ExposedEntry{
+					Value: s.Value, Count: s.Count, Lens: state.lenses[s.Value],
+				})
+			}
+		}
+		page.Total = len(exposed)
+		if state.lenses != nil && strings.Contains(r.Header.Get("Accept"), "application/json") {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(page) //nolint:errcheck
+			return
+		}
+		w.Header().Set("X-Exposed-Total", fmt.Sprintf("%d", len(exposed)))
+		if len(exposed) == 0 {
+			w.Write([]byte("No exposed secrets yet. Submit one to begin.")) //nolint:errcheck
+			return
+		}
+		w.Write([]byte(strings.Join(exposed, "\n"))) //nolint:errcheck
+	})
 	mux.HandleFunc("/api/stats", func(w http.ResponseWriter, _ *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(state.stats) //nolint:errcheck
@@ -269,7 +569,7 @@ func newSecretsTestServer(t *testing.T) (*httptest.Server, *secretsState) {
 }
 
 // navToSecrets navigates an already-logged-in model to the Secrets panel.
-// menuItems = ["Hermit DB", "Benchmark", "Secrets", "Quit"] → Secrets is index 2.
+// menuItems = ["Hermit DB", "Benchmark", "Secrets", ...] → Secrets is index 2.
 func navToSecrets(m app.Model) (app.Model, tea.Cmd) {
 	m, _ = pressDown(m) // index 1
 	m, _ = pressDown(m) // index 2
@@ -323,7 +623,1431 @@ func TestSecrets_Submit(t *testing.T) {
 	hasContent(t, m, "after secret submission")
 }
 
-// mustModel2 is a variant that works when Update() is called directly.
-func mustModel2(iface tea.Model, cmd tea.Cmd) (app.Model, tea.Cmd) {
-	return iface.(app.Model), cmd
+// runBatch runs every command in a tea.Batch and dispatches the results,
+// skipping ticks so polling doesn't block the test.
+func runBatch(m app.Model, cmd tea.Cmd) app.Model {
+	if cmd == nil {
+		return m
+	}
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		for _, c := range batch {
+			m = runBatch(m, c)
+		}
+		return m
+	}
+	m, _ = mustModel2(m.Update(msg))
+	return m
+}
+
+func TestSecrets_ExposuresAndLeaderboard(t *testing.T) {
+	srv, state := newSecretsTestServer(t)
+	defer srv.Close()
+	state.secrets = append(state.secrets, app.Secret{ID: "2", Value: "open-secret", Count: 3})
+
+	sc := app.NewSecretsClient(srv.URL)
+	h := &mockHermit{serverInfo: &pb.ServerInfoResponse{}}
+	m := app.New("localhost:9090", "", h, sc)
+	m = doLogin(m)
+
+	m, cmd := navToSecrets(m)
+	// The batch holds the refresh and the poll tick; only run the refresh.
+	if batch, ok := cmd().(tea.BatchMsg); ok {
+		m = runBatch(m, batch[0])
+	}
+
+	m, _ = mustModel2(m.Update(tea.KeyPressMsg{Code: tea.KeyTab}))
+	if v := m.View().Content; !strings.Contains(v, "open-secret") {
+		t.Errorf("exposures tab missing exposed value:\n%s", v)
+	} else if !strings.Contains(v, "lens unknown") {
+		t.Errorf("text-only wall should leave the lens unknown:\n%s", v)
+	}
+
+	m, cmd = mustModel2(m.Update(tea.KeyPressMsg{Code: tea.KeyTab}))
+	m = runBatch(m, cmd)
+	if v := m.View().Content; !strings.Contains(v, "doesn't publish a leaderboard") {
+		t.Errorf("leaderboard tab should report missing endpoint:\n%s", v)
+	}
+}
+
+func TestSecrets_ExposuresShowLens(t *testing.T) {
+	srv, state := newSecretsTestServer(t)
+	defer srv.Close()
+	state.secrets = append(state.secrets, app.Secret{ID: "2", Value: "open-secret", Count: 2})
+	state.lenses = map[string]string{"open-secret": "casefold"}
+
+	sc := app.NewSecretsClient(srv.URL)
+	h := &mockHermit{serverInfo: &pb.ServerInfoResponse{}}
+	m := app.New("localhost:9090", "", h, sc)
+	m = doLogin(m)
+
+	m, cmd := navToSecrets(m)
+	if batch, ok := cmd().(tea.BatchMsg); ok {
+		m = runBatch(m, batch[0])
+	}
+
+	m, _ = mustModel2(m.Update(tea.KeyPressMsg{Code: tea.KeyTab}))
+	if v := m.View().Content; !strings.Contains(v, "via casefold") {
+		t.Errorf("exposures tab missing lens from the wall:\n%s", v)
+	}
+}
+
+func TestDBConsole_TabCompletion(t *testing.T) {
+	h := &mockHermit{
+		serverInfo: &pb.ServerInfoResponse{},
+		dbStats:    &pb.DbStatsResponse{},
+		kvListKeys: []string{"alpha", "apple", "beta"},
+	}
+	m := app.New("localhost:9090", "", h, nil)
+	m = doLogin(m)
+	m, cmd := pressEnter(m) // Hermit DB; warms the key cache
+	m = runBatch(m, cmd)
+
+	typeAndTab := func(s string) string {
+		for _, c := range s {
+			m, _ = sendKey(m, c)
+		}
+		m, _ = mustModel2(m.Update(tea.KeyPressMsg{Code: tea.KeyTab}))
+		return m.View().Content
+	}
+
+	if v := typeAndTab("kv:g"); !strings.Contains(v, "kv:get █") {
+		t.Fatalf("verb not completed:\n%s", v)
+	}
+	if v := typeAndTab("a"); !strings.Contains(v, "kv:get a█") || !strings.Contains(v, "alpha  apple") {
+		t.Fatalf("ambiguous key should list candidates:\n%s", v)
+	}
+	if v := typeAndTab("l"); !strings.Contains(v, "kv:get alpha █") {
+		t.Errorf("key not completed:\n%s", v)
+	}
+}
+
+func TestBenchmark_ConfigureAndRun(t *testing.T) {
+	h := &mockHermit{
+		serverInfo: &pb.ServerInfoResponse{},
+		benchResp:  &pb.BenchmarkResponse{LatenciesNs: []int64{1000, 2000, 2000, 3000}, TlsVersion: "TLS 1.3"},
+	}
+	m := app.New("localhost:9090", "", h, nil)
+	m = doLogin(m)
+	m, _ = pressDown(m)
+	m, _ = pressEnter(m) // Benchmark: shows the form, doesn't run yet
+	if !strings.Contains(m.View().Content, "Benchmark Settings") {
+		t.Fatalf("expected the settings form:\n%s", m.View().Content)
+	}
+	if h.benchCalls.Load() != 0 {
+		t.Fatal("benchmark ran before enter")
+	}
+
+	// iterations = 60 → chunks of 25, 25, 10; concurrency = 2.
+	m, _ = sendKey(m, '6')
+	m, _ = sendKey(m, '0')
+	m, _ = pressDown(m)
+	m, _ = pressDown(m)
+	m, _ = sendKey(m, '2')
+	v := m.View().Content
+	if !strings.Contains(v, "iterations     60") || !strings.Contains(v, "concurrency    2") {
+		t.Fatalf("form not updated:\n%s", v)
+	}
+
+	m, cmd := pressEnter(m)
+	if !strings.Contains(m.View().Content, "0/60") {
+		t.Errorf("expected progress while running:\n%s", m.View().Content)
+	}
+	// The batch holds the run and the redraw tick; only run the run.
+	batch := cmd().(tea.BatchMsg)
+	m, _ = runCmd(m, batch[0])
+
+	if got := h.benchCalls.Load(); got != 3 {
+		t.Errorf("Benchmark called %d times, want 3", got)
+	}
+	v = m.View().Content
+	if !strings.Contains(v, "p50:") || !strings.Contains(v, "█") {
+		t.Errorf("expected results with a histogram:\n%s", v)
+	}
+}
+
+func TestBenchmark_StreamsAndMergesHistograms(t *testing.T) {
+	h := &mockHermit{
+		serverInfo: &pb.ServerInfoResponse{},
+		benchStream: []*pb.BenchmarkProgress{
+			{Done: 10, Total: 30, Histogram: []*pb.HistogramBucket{{UpperNs: 1000, Count: 10}}},
+			{
+				Done: 30, Total: 30, Final: true,
+				MinNs: 1000, MaxNs: 3000, MeanNs: 2000,
+				Histogram: []*pb.HistogramBucket{
+					{UpperNs: 1000, Count: 10}, {UpperNs: 2000, Count: 15}, {UpperNs: 3000, Count: 5},
+				},
+				IterationsPerSec: 500, BytesPerSec: 512_000,
+				TlsActive: true, TlsVersion: "TLS 1.3",
+			},
+		},
+	}
+	m := app.New("localhost:9090", "", h, nil)
+	m = doLogin(m)
+	m, _ = pressDown(m)
+	m, _ = pressEnter(m)
+	m, _ = sendKey(m, '6')
+	m, _ = sendKey(m, '0')
+	m, _ = pressDown(m)
+	m, _ = pressDown(m)
+	m, _ = sendKey(m, '2')
+
+	m, cmd := pressEnter(m)
+	batch := cmd().(tea.BatchMsg)
+	m, _ = runCmd(m, batch[0])
+
+	if h.benchCalls.Load() != 0 {
+		t.Error("fell back to Benchmark though BenchmarkStream works")
+	}
+	if opens, iters := h.benchOpens.Load(), h.benchIters.Load(); opens != 2 || iters != 60 {
+		t.Errorf("%d streams for %d iterations, want 2 for 60", opens, iters)
+	}
+	// Both workers' histograms merge: 20 at 1µs, 30 at 2µs, 10 at 3µs.
+	v := ansi.Strip(m.View().Content)
+	for _, want := range []string{
+		"min: 1.00μs  p50: 2.00μs  p90: 3.00μs  max: 3.00μs",
+		"p99: 3.00μs  p99.9: 3.00μs",
+		"mean: 2.00μs  tls: TLS 1.3",
+		"throughput: 1000 req/s  1000.0KB/s",
+		"█",
+	} {
+		if !strings.Contains(v, want) {
+			t.Errorf("missing %q:\n%s", want, v)
+		}
+	}
+}
+
+func TestExport_DBConsole(t *testing.T) {
+	dir := t.TempDir()
+	h := &mockHermit{serverInfo: &pb.ServerInfoResponse{}, dbStats: &pb.DbStatsResponse{DocKeyCount: 1}, kvSetOK: true}
+	m := app.New("localhost:9090", "", h, nil).WithExportDir(dir)
+	m = doLogin(m)
+	m, cmd := pressEnter(m) // Hermit DB
+	m = runBatch(m, cmd)
+	for _, c := range "kv:set greeting hi" {
+		m, _ = sendKey(m, c)
+	}
+	m, cmd = pressEnter(m)
+	m, _ = runCmd(m, cmd)
+
+	m, cmd = mustModel2(m.Update(tea.KeyPressMsg{Code: 's', Mod: tea.ModCtrl}))
+	m, _ = runCmd(m, cmd)
+
+	files, _ := filepath.Glob(filepath.Join(dir, "nexus-tui-db-*.json"))
+	if len(files) != 1 {
+		t.Fatalf("export files = %v, want one", files)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Panel string `json:"panel"`
+		DB    struct {
+			History []struct {
+				Cmd string `json:"cmd"`
+			} `json:"history"`
+		} `json:"db"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Panel != "db" || len(got.DB.History) != 1 || got.DB.History[0].Cmd != "kv:set greeting hi" {
+		t.Errorf("export = %s", data)
+	}
+	if !strings.Contains(m.View().Content, "exported to") {
+		t.Errorf("export not reported in the console:\n%s", m.View().Content)
+	}
+}
+
+func TestTheme_CycleAndPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "theme")
+	h := &mockHermit{serverInfo: &pb.ServerInfoResponse{}}
+	m, err := app.New("localhost:9090", "", h, nil).WithTheme("default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m = doLogin(m.WithThemeFile(path))
+
+	m, cmd := mustModel2(m.Update(tea.KeyPressMsg{Code: 't', Mod: tea.ModCtrl}))
+	m, _ = runCmd(m, cmd)
+	if !strings.Contains(m.View().Content, "theme: light") {
+		t.Error("view does not log the theme change")
+	}
+	if got := app.ReadThemeFile(path); got != "light" {
+		t.Errorf("saved theme = %q, want light", got)
+	}
+
+	if _, err := m.WithTheme("nope"); err == nil {
+		t.Error("WithTheme(nope) succeeded, want error")
+	}
+}
+
+func TestReconnect_AfterConnectionLoss(t *testing.T) {
+	h := &mockHermit{serverInfo: &pb.ServerInfoResponse{}, dbStats: &pb.DbStatsResponse{}}
+	m := app.New("localhost:9090", "", h, nil)
+	m = doLogin(m)
+	if !strings.Contains(m.View().Content, "connected") {
+		t.Fatalf("expected a connection badge:\n%s", m.View().Content)
+	}
+
+	h.dbStatsErr = status.Error(codes.Unavailable, "connection refused")
+	m, cmd := pressEnter(m) // Hermit DB → DbStats fails
+	batch := cmd().(tea.BatchMsg)
+	m, retry := runCmd(m, batch[0])
+	v := m.View().Content
+	if !strings.Contains(v, "reconnecting") {
+		t.Fatalf("expected reconnecting badge:\n%s", v)
+	}
+	if strings.Contains(v, "connection refused") {
+		t.Errorf("connection error should not be surfaced:\n%s", v)
+	}
+
+	// Hermit comes back: the retry logs in again and refreshes the panel.
+	h.dbStatsErr = nil
+	m, cmd = runCmd(m, retry) // backoff tick → login
+	m, cmd = runCmd(m, cmd)   // login ok → refresh
+	m = runBatch(m, cmd)
+	if v := m.View().Content; strings.Contains(v, "reconnecting") || !strings.Contains(v, "connected") {
+		t.Errorf("expected connected badge after reconnect:\n%s", v)
+	}
+}
+
+func TestLogin_TokenRoleAndExpiredSession(t *testing.T) {
+	h := &mockHermit{role: "read", serverInfo: &pb.ServerInfoResponse{}, dbStats: &pb.DbStatsResponse{}}
+	m := doLogin(app.New("localhost:9090", "", h, nil).WithToken("t0ken"))
+	if h.loginToken != "t0ken" {
+		t.Errorf("logged in with token %q", h.loginToken)
+	}
+	if v := ansi.Strip(m.View().Content); !strings.Contains(v, "connected as operator (read)") {
+		t.Fatalf("expected the role in the badge:\n%s", v)
+	}
+
+	// The session expired: log in again, picking up any new role.
+	h.dbStatsErr = status.Error(codes.Unauthenticated, "session expired; log in again")
+	m, cmd := pressEnter(m) // Hermit DB → DbStats fails
+	batch := cmd().(tea.BatchMsg)
+	m, retry := runCmd(m, batch[0])
+	if v := m.View().Content; !strings.Contains(v, "reconnecting") || strings.Contains(v, "session expired") {
+		t.Fatalf("expected a quiet reconnect:\n%s", v)
+	}
+	h.dbStatsErr, h.role = nil, "write"
+	m, cmd = runCmd(m, retry) // backoff tick → login
+	m, cmd = runCmd(m, cmd)   // login ok → refresh
+	m = runBatch(m, cmd)
+	if v := ansi.Strip(m.View().Content); h.logins != 2 || !strings.Contains(v, "connected as operator (write)") {
+		t.Errorf("after %d logins:\n%s", h.logins, v)
+	}
+}
+
+// screenPos finds text on screen, ignoring styling, and returns the cell
+// where it starts.
+func screenPos(t *testing.T, m app.Model, text string) (x, y int) {
+	t.Helper()
+	for y, line := range strings.Split(ansi.Strip(m.View().Content), "\n") {
+		if i := strings.Index(line, text); i >= 0 {
+			return ansi.StringWidth(line[:i]), y
+		}
+	}
+	t.Fatalf("%q not on screen", text)
+	return 0, 0
+}
+
+func click(m app.Model, x, y int) (app.Model, tea.Cmd) {
+	return mustModel2(m.Update(tea.MouseClickMsg{X: x, Y: y, Button: tea.MouseLeft}))
+}
+
+func TestMouse_MenuClick(t *testing.T) {
+	h := &mockHermit{serverInfo: &pb.ServerInfoResponse{Version: "1.0"}}
+	m := doLogin(app.New("localhost:9090", "", h, nil))
+
+	x, y := screenPos(t, m, "Benchmark")
+	m, _ = click(m, x, y) // selects
+	if strings.Contains(m.View().Content, "Benchmark Settings") {
+		t.Fatal("first click opened the item, want select only")
+	}
+	m, _ = click(m, x, y) // runs
+	if !strings.Contains(m.View().Content, "Benchmark Settings") {
+		t.Error("second click did not open Benchmark")
+	}
+}
+
+func TestMouse_DBHistoryRecall(t *testing.T) {
+	h := &mockHermit{serverInfo: &pb.ServerInfoResponse{}, dbStats: &pb.DbStatsResponse{}, kvSetOK: true}
+	m := doLogin(app.New("localhost:9090", "", h, nil))
+	m, cmd := pressEnter(m) // Hermit DB
+	m = runBatch(m, cmd)
+	for _, c := range "kv:set greeting hi" {
+		m, _ = sendKey(m, c)
+	}
+	m, cmd = pressEnter(m)
+	m, _ = runCmd(m, cmd)
+
+	x, y := screenPos(t, m, "OK  key=")
+	m, _ = click(m, x, y)
+	if !strings.Contains(ansi.Strip(m.View().Content), "> kv:set greeting hi█") {
+		t.Error("clicking the history line did not recall its command")
+	}
+}
+
+func TestLayout_ResizeAndSideBySide(t *testing.T) {
+	h := &mockHermit{serverInfo: &pb.ServerInfoResponse{}}
+	m := doLogin(app.New("localhost:9090", "", h, nil))
+	ctrl := func(m app.Model, code rune) app.Model {
+		m, _ = mustModel2(m.Update(tea.KeyPressMsg{Code: code, Mod: tea.ModCtrl}))
+		return m
+	}
+
+	_, before := screenPos(t, m, "Menu")
+	m = ctrl(m, tea.KeyUp)
+	m = ctrl(m, tea.KeyUp)
+	if _, after := screenPos(t, m, "Menu"); after >= before {
+		t.Errorf("Menu at row %d after shrinking the top panel, was %d", after, before)
+	}
+
+	m = ctrl(m, 'l')
+	_, infoY := screenPos(t, m, "Server Information")
+	menuX, menuY := screenPos(t, m, "Menu")
+	if menuY != infoY || menuX == 0 {
+		t.Errorf("Menu at (%d,%d), want beside Server Information on row %d", menuX, menuY, infoY)
+	}
+}
+
+func TestKVBrowser_PageAndPreview(t *testing.T) {
+	var keys []string
+	for i := range 50 {
+		keys = append(keys, fmt.Sprintf("key-%02d", i))
+	}
+	h := &mockHermit{serverInfo: &pb.ServerInfoResponse{}, kvListKeys: keys, kvGetFound: true, kvGetValue: []byte("hello world")}
+	m := doLogin(app.New("localhost:9090", "", h, nil))
+	for range 3 {
+		m, _ = pressDown(m)
+	}
+	m, cmd := pressEnter(m) // KV Browser
+	m, _ = runCmd(m, cmd)
+	if v := m.View().Content; !strings.Contains(v, "page 1") || !strings.Contains(v, "key-00") || strings.Contains(v, "key-49") {
+		t.Fatalf("browser does not list the first page of keys:\n%s", v)
+	}
+
+	m, cmd = mustModel2(m.Update(tea.KeyPressMsg{Code: tea.KeyRight}))
+	m, _ = runCmd(m, cmd)
+	if v := m.View().Content; strings.Contains(v, "key-00") || !strings.Contains(v, "page 2") {
+		t.Errorf("right arrow did not fetch the next page:\n%s", v)
+	}
+	m, cmd = mustModel2(m.Update(tea.KeyPressMsg{Code: tea.KeyLeft}))
+	m, _ = runCmd(m, cmd)
+	if v := m.View().Content; !strings.Contains(v, "key-00") || !strings.Contains(v, "page 1") {
+		t.Errorf("left arrow did not go back a page:\n%s", v)
+	}
+
+	m, cmd = pressEnter(m)
+	m, _ = runCmd(m, cmd)
+	if !strings.Contains(m.View().Content, "hello world") {
+		t.Error("enter did not preview the selected value")
+	}
+}
+
+func TestKVBrowser_Prefix(t *testing.T) {
+	keys := []string{"config:motd", "config:region", "session:1", "session:2"}
+	h := &mockHermit{serverInfo: &pb.ServerInfoResponse{}, kvListKeys: keys}
+	m := doLogin(app.New("localhost:9090", "", h, nil))
+	for range 3 {
+		m, _ = pressDown(m)
+	}
+	m, cmd := pressEnter(m) // KV Browser
+	m, _ = runCmd(m, cmd)
+
+	m, _ = sendKey(m, '/')
+	for _, c := range "sess" {
+		m, _ = sendKey(m, c)
+	}
+	if v := ansi.Strip(m.View().Content); !strings.Contains(v, "prefix> sess█") {
+		t.Fatalf("want the prefix prompt:\n%s", v)
+	}
+	m, cmd = pressEnter(m)
+	m, _ = runCmd(m, cmd)
+	v := ansi.Strip(m.View().Content)
+	if !strings.Contains(v, `prefix "sess"`) || !strings.Contains(v, "session:1") || strings.Contains(v, "config:motd") {
+		t.Errorf("want only session keys:\n%s", v)
+	}
+
+	// esc drops the prefix before it leaves the browser.
+	m, cmd = pressEsc(m)
+	m, _ = runCmd(m, cmd)
+	if v := ansi.Strip(m.View().Content); !strings.Contains(v, "config:motd") {
+		t.Errorf("esc should list every key again:\n%s", v)
+	}
+}
+
+// watchHermit is a mockHermit whose Watch stream replays events.
+type watchHermit struct {
+	*mockHermit
+	events chan *pb.WatchEvent
+}
+
+func (h *watchHermit) Watch(_ context.Context, _ string) (app.KvEventStream, error) {
+	return watchStream(h.events), nil
+}
+
+type watchStream chan *pb.WatchEvent
+
+type benchStream struct {
+	msgs []*pb.BenchmarkProgress
+}
+
+func (s *benchStream) Recv() (*pb.BenchmarkProgress, error) {
+	if len(s.msgs) == 0 {
+		return nil, io.EOF
+	}
+	p := s.msgs[0]
+	s.msgs = s.msgs[1:]
+	return p, nil
+}
+
+func (s watchStream) Recv() (*pb.WatchEvent, error) {
+	ev, ok := <-s
+	if !ok {
+		return nil, io.EOF
+	}
+	return ev, nil
+}
+
+func TestKVBrowser_FollowsWatch(t *testing.T) {
+	h := &watchHermit{
+		mockHermit: &mockHermit{serverInfo: &pb.ServerInfoResponse{}, kvListKeys: []string{"a", "b"}, kvGetFound: true, kvGetValue: []byte("old")},
+		events:     make(chan *pb.WatchEvent, 4),
+	}
+	m := doLogin(app.New("localhost:9090", "", h, nil))
+	for range 3 {
+		m, _ = pressDown(m)
+	}
+	m, cmd := pressEnter(m) // KV Browser
+	var wait tea.Cmd
+	for _, c := range cmd().(tea.BatchMsg) {
+		var next tea.Cmd
+		if m, next = runCmd(m, c); next != nil {
+			wait = next
+		}
+	}
+	if v := ansi.Strip(m.View().Content); !strings.Contains(v, "● live") {
+		t.Fatalf("want the browser watching:\n%s", v)
+	}
+	m, cmd = pressEnter(m) // preview a
+	m, _ = runCmd(m, cmd)
+
+	// Another client rewrites a and adds c, then the stream ends.
+	h.kvListKeys = append(h.kvListKeys, "c")
+	h.events <- &pb.WatchEvent{Kind: pb.WatchEvent_SET, Key: "a", Value: []byte("new")}
+	h.events <- &pb.WatchEvent{Kind: pb.WatchEvent_SET, Key: "c", Value: []byte("x")}
+	close(h.events)
+	m, cmd = runCmd(m, wait)
+	m = runBatch(m, cmd)
+	v := ansi.Strip(m.View().Content)
+	if !strings.Contains(v, "new") || strings.Contains(v, "old") {
+		t.Errorf("preview should follow the write:\n%s", v)
+	}
+	if !strings.Contains(v, "│    c ") {
+		t.Errorf("want the new key listed:\n%s", v)
+	}
+	if strings.Contains(v, "● live") {
+		t.Errorf("the ended stream should not show as live:\n%s", v)
+	}
+}
+
+// topicsHermit is a mockHermit with topics. Every Subscribe stream reads
+// msgs.
+type topicsHermit struct {
+	*mockHermit
+	msgs       chan *pb.TopicMessage
+	subscribed []string
+	published  []string // topic: data
+	pubResp    *pb.PublishResponse
+}
+
+func (h *topicsHermit) Publish(topic string, data []byte) (*pb.PublishResponse, error) {
+	h.published = append(h.published, topic+": "+string(data))
+	if h.pubResp != nil {
+		return h.pubResp, nil
+	}
+	return &pb.PublishResponse{Delivered: 1}, nil
+}
+
+func (h *topicsHermit) Subscribe(_ context.Context, topic string) (app.TopicStream, error) {
+	h.subscribed = append(h.subscribed, topic)
+	return topicStream(h.msgs), nil
+}
+
+type topicStream chan *pb.TopicMessage
+
+func (s topicStream) Recv() (*pb.TopicMessage, error) {
+	msg, ok := <-s
+	if !ok {
+		return nil, io.EOF
+	}
+	return msg, nil
+}
+
+func TestTopics_ChatAndJoin(t *testing.T) {
+	h := &topicsHermit{mockHermit: &mockHermit{serverInfo: &pb.ServerInfoResponse{}}, msgs: make(chan *pb.TopicMessage, 4)}
+	m := doLogin(app.New("localhost:9090", "", h, nil))
+	for range 8 {
+		m, _ = pressDown(m)
+	}
+	m, cmd := pressEnter(m) // Topics
+	m, wait := runCmd(m, cmd)
+	h.msgs <- &pb.TopicMessage{Topic: "lobby", From: "alice", Data: []byte("hello"), Seq: 1}
+	h.msgs <- &pb.TopicMessage{Topic: "lobby", Data: []byte("who's there"), Seq: 4, Dropped: 2}
+	// Both may come in one batch or one at a time.
+	for range 2 {
+		if m, wait = runCmd(m, wait); strings.Contains(ansi.Strip(m.View().Content), "who's there") {
+			break
+		}
+	}
+	v := ansi.Strip(m.View().Content)
+	for _, want := range []string{"#lobby", "joined #lobby", "<alice> hello", "missed 2 messages", "<anonymous> who's there"} {
+		if !strings.Contains(v, want) {
+			t.Errorf("missing %q:\n%s", want, v)
+		}
+	}
+
+	// Letters are text here, not key bindings.
+	for _, c := range "quit now" {
+		m, _ = sendKey(m, c)
+	}
+	m, cmd = pressEnter(m)
+	m, _ = runCmd(m, cmd)
+	h.pubResp = &pb.PublishResponse{Error: "message is over 65536 bytes"}
+	m, _ = sendKey(m, 'x')
+	m, cmd = pressEnter(m)
+	m, _ = runCmd(m, cmd)
+	if want := []string{"lobby: quit now", "lobby: x"}; !slices.Equal(h.published, want) {
+		t.Errorf("published %q, want %q", h.published, want)
+	}
+	if v := ansi.Strip(m.View().Content); !strings.Contains(v, "send failed: message is over 65536 bytes") {
+		t.Errorf("want the rejected message reported:\n%s", v)
+	}
+
+	for _, c := range "/join ops" {
+		m, _ = sendKey(m, c)
+	}
+	m, cmd = pressEnter(m)
+	m, _ = runCmd(m, cmd)
+	if want := []string{"lobby", "ops"}; !slices.Equal(h.subscribed, want) {
+		t.Errorf("subscribed to %q, want %q", h.subscribed, want)
+	}
+	if v := ansi.Strip(m.View().Content); !strings.Contains(v, "joined #ops") || !strings.Contains(v, "#ops> █") {
+		t.Errorf("want the panel in #ops:\n%s", v)
+	}
+
+	m, _ = pressEsc(m)
+	if v := m.View().Content; strings.Contains(v, "#ops>") {
+		t.Errorf("esc did not leave the topics panel:\n%s", v)
+	}
+}
+
+func TestSQLTable_SortAndBack(t *testing.T) {
+	h := &mockHermit{serverInfo: &pb.ServerInfoResponse{}, dbStats: &pb.DbStatsResponse{}, sqlRows: []*pb.SqlRow{
+		{Id: "11111111-a", Key: "zebra", Value: "first", CreatedAtMs: 1},
+		{Id: "22222222-b", Key: "apple", Value: "second", CreatedAtMs: 2},
+	}}
+	m := doLogin(app.New("localhost:9090", "", h, nil))
+	m, cmd := pressEnter(m) // Hermit DB
+	m = runBatch(m, cmd)
+	for _, c := range "sql:query" {
+		m, _ = sendKey(m, c)
+	}
+	m, cmd = pressEnter(m)
+	m, _ = runCmd(m, cmd)
+
+	v := ansi.Strip(m.View().Content)
+	if !strings.Contains(v, "Query Results") || strings.Index(v, "zebra") > strings.Index(v, "apple") {
+		t.Fatalf("want results table in commit order:\n%s", v)
+	}
+
+	m, _ = sendKey(m, 's') // sort by key
+	v = ansi.Strip(m.View().Content)
+	if !strings.Contains(v, "sorted by key") || strings.Index(v, "apple") > strings.Index(v, "zebra") {
+		t.Errorf("want rows sorted by key:\n%s", v)
+	}
+
+	m, _ = pressEsc(m)
+	if !strings.Contains(m.View().Content, "DB Console") {
+		t.Error("esc did not return to the DB console")
+	}
+}
+
+func TestDBConsole_SqlOptionsDeleteUpdate(t *testing.T) {
+	h := &mockHermit{serverInfo: &pb.ServerInfoResponse{}, dbStats: &pb.DbStatsResponse{}, sqlRows: []*pb.SqlRow{
+		{Id: "11111111-a", Key: "zebra", Value: "first", CreatedAtMs: 1},
+		{Id: "22222222-b", Key: "apple", Value: "second", CreatedAtMs: 2},
+	}}
+	m := doLogin(app.New("localhost:9090", "", h, nil).WithToastDuration(time.Millisecond))
+	m, cmd := pressEnter(m) // Hermit DB
+	m = runBatch(m, cmd)
+	run := func(line string) string {
+		for _, c := range line {
+			m, _ = sendKey(m, c)
+		}
+		m, cmd = pressEnter(m)
+		m, cmd = runCmd(m, cmd)
+		m = runBatch(m, cmd)
+		return ansi.Strip(m.View().Content)
+	}
+
+	v := run("sql:query a value=ir by=key desc offset=1 limit=5")
+	if r := h.sqlReq; r.KeyFilter != "a" || r.ValueFilter != "ir" || r.OrderBy != pb.SqlQueryRequest_KEY ||
+		!r.Desc || r.Offset != 1 || r.Limit != 5 {
+		t.Errorf("SqlQuery got %v", r)
+	}
+	// The table starts in the order asked for.
+	if !strings.Contains(v, "sorted by key") || strings.Index(v, "apple") < strings.Index(v, "zebra") {
+		t.Errorf("want rows by key, descending:\n%s", v)
+	}
+	m, _ = pressEsc(m)
+
+	for line, errText := range map[string]string{
+		"sql:query a b":     `unexpected "b"`,
+		"sql:query by=size": "want id, key, value or created",
+		"sql:query limit=x": "not a count",
+	} {
+		if v := run(line); !strings.Contains(v, errText) {
+			t.Errorf("%s: want %q in\n%s", line, errText, v)
+		}
+	}
+
+	if v := run("sql:count"); !strings.Contains(v, "COUNT  2") || !h.sqlReq.CountOnly {
+		t.Errorf("sql:count:\n%s", v)
+	}
+	if v := run("sql:update zebra new value"); !strings.Contains(v, `UPDATED  key="zebra" rows=1`) {
+		t.Errorf("sql:update:\n%s", v)
+	}
+	if v := run("sql:delete nope"); !strings.Contains(v, `DELETED  key="nope" rows=0`) {
+		t.Errorf("sql:delete:\n%s", v)
+	}
+}
+
+func TestSecrets_ComposerLensHints(t *testing.T) {
+	srv, _ := newSecretsTestServer(t)
+	m := app.New("localhost:9090", "", &mockHermit{serverInfo: &pb.ServerInfoResponse{}}, app.NewSecretsClient(srv.URL))
+	m = doLogin(m)
+	m, _ = navToSecrets(m)
+
+	for _, c := range "Abba" {
+		m, _ = sendKey(m, c)
+	}
+	v := ansi.Strip(m.View().Content)
+	for _, want := range []string{"casefold → abba", "palindrome"} {
+		if !strings.Contains(v, want) {
+			t.Errorf("composer hints missing %q:\n%s", want, v)
+		}
+	}
+
+	m, _ = mustModel2(m.Update(tea.KeyPressMsg{Code: tea.KeyEnter, Mod: tea.ModAlt}))
+	for _, c := range "6869" {
+		m, _ = sendKey(m, c)
+	}
+	v = ansi.Strip(m.View().Content)
+	if !strings.Contains(v, "> Abba") || !strings.Contains(v, "  6869█") {
+		t.Errorf("alt+enter did not start a second line:\n%s", v)
+	}
+}
+
+func TestKeys_RemapAndHelp(t *testing.T) {
+	h := &mockHermit{serverInfo: &pb.ServerInfoResponse{}}
+	m, err := app.New("localhost:9090", "", h, nil).WithKeyBindings(map[string][]string{"down": {"x"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m = doLogin(m)
+
+	m, _ = sendKey(m, 'j') // no longer bound
+	m, _ = sendKey(m, 'x')
+	if !strings.Contains(ansi.Strip(m.View().Content), "▸ Benchmark") {
+		t.Error("remapped down key did not move the selection by one")
+	}
+
+	m, _ = sendKey(m, '?')
+	v := ansi.Strip(m.View().Content)
+	if !strings.Contains(v, "Key Bindings") || !strings.Contains(v, "down          x") {
+		t.Errorf("help overlay does not show the remapped binding:\n%s", v)
+	}
+	m, _ = sendKey(m, 'q') // closes the overlay instead of quitting
+	if strings.Contains(m.View().Content, "Key Bindings") {
+		t.Error("a key did not close the help overlay")
+	}
+
+	if _, err := m.WithKeyBindings(map[string][]string{"jump": {"J"}}); err == nil {
+		t.Error("unknown action accepted")
+	}
+}
+
+func TestToast_KvSetShowsAndExpires(t *testing.T) {
+	h := &mockHermit{serverInfo: &pb.ServerInfoResponse{}, kvSetOK: true, dbStats: &pb.DbStatsResponse{}}
+	m := doLogin(app.New("localhost:9090", "", h, nil).WithToastDuration(10 * time.Millisecond))
+	m, cmd := pressEnter(m) // Hermit DB
+	m, _ = runCmd(m, cmd)
+
+	for _, c := range "kv:set foo bar" {
+		m, _ = sendKey(m, c)
+	}
+	m, cmd = pressEnter(m)
+	m, cmd = runCmd(m, cmd) // kvSet result
+	if v := ansi.Strip(m.View().Content); !strings.Contains(v, "kv:set ok") {
+		t.Fatalf("want a kv:set toast:\n%s", v)
+	}
+
+	m = runBatch(m, cmd) // stats refresh and the toast's expiry
+	if strings.Contains(ansi.Strip(m.View().Content), "kv:set ok") {
+		t.Error("toast still showing after its duration")
+	}
+}
+
+func TestHealth_AlertsOnFailureAndRestart(t *testing.T) {
+	h := &mockHermit{serverInfo: &pb.ServerInfoResponse{UptimeSeconds: 100}, dbStats: &pb.DbStatsResponse{}}
+	// Nothing listens on port 1, so the TCP and TLS probes fail.
+	m := doLogin(app.New("127.0.0.1:1", "", h, nil))
+	for range 4 {
+		m, _ = pressDown(m)
+	}
+	m, cmd := pressEnter(m) // Health
+	m, _ = runCmd(m, cmd)
+
+	v := ansi.Strip(m.View().Content)
+	for _, want := range []string{"Health", "ServerInfo", "TCP failing", "TLS failing", "uptime 1m40s"} {
+		if !strings.Contains(v, want) {
+			t.Errorf("health panel missing %q:\n%s", want, v)
+		}
+	}
+
+	h.serverInfo = &pb.ServerInfoResponse{UptimeSeconds: 3}
+	m, cmd = sendKey(m, 'r')
+	m, _ = runCmd(m, cmd)
+	if v := ansi.Strip(m.View().Content); !strings.Contains(v, "hermit restarted") {
+		t.Errorf("want a restart alert:\n%s", v)
+	}
+}
+
+func TestMetrics_PanelShowsRPCsAndStores(t *testing.T) {
+	h := &mockHermit{serverInfo: &pb.ServerInfoResponse{}, metrics: &pb.MetricsResponse{
+		LatencyBoundsUs: []uint64{100, 1000, 10000},
+		// 99 calls at most 1ms, one slower: p99 is 1ms.
+		Rpcs:    []*pb.RpcMetric{{Method: "KvGet", Calls: 100, TotalUs: 40000, MaxUs: 9000, Buckets: []uint64{60, 39, 1, 0}}},
+		DocKeys: 3, RelRows: 7, RelPendingWrites: 2, Connections: 5, TlsHandshakes: 5, UptimeSeconds: 90,
+	}}
+	m := doLogin(app.New("localhost:9090", "", h, nil))
+	for range 7 {
+		m, _ = pressDown(m)
+	}
+	m, cmd := pressEnter(m) // Metrics
+	m, _ = runCmd(m, cmd)
+
+	v := ansi.Strip(m.View().Content)
+	for _, want := range []string{"Metrics", "KvGet", "100", "1.00ms", "3 keys", "7 committed, 2 pending", "5, 5 TLS handshakes", "1m30s"} {
+		if !strings.Contains(v, want) {
+			t.Errorf("metrics panel missing %q:\n%s", want, v)
+		}
+	}
+
+	m, _ = pressEsc(m)
+	if v := m.View().Content; strings.Contains(v, "KvGet") {
+		t.Errorf("esc did not leave the metrics panel:\n%s", v)
+	}
+}
+
+func TestDemo_BackendsServeData(t *testing.T) {
+	m := doLogin(app.New(app.DemoAddr, "", app.NewDemoHermitClient(), app.NewDemoSecretsClient()))
+	if v := m.View().Content; !strings.Contains(v, "0.9.0-demo") {
+		t.Errorf("want demo server info on the dashboard:\n%s", v)
+	}
+
+	for range 3 {
+		m, _ = pressDown(m)
+	}
+	m, cmd := pressEnter(m) // KV Browser; the demo also starts a watch
+	m = runBatch(m, cmd)
+	if v := ansi.Strip(m.View().Content); !strings.Contains(v, "config:motd") {
+		t.Errorf("want seeded keys in the KV browser:\n%s", v)
+	}
+}
+
+func TestExec_RunsOneCommand(t *testing.T) {
+	h := &mockHermit{kvGetFound: true, kvGetValue: []byte("bar")}
+	res, err := app.Exec(h, nil, "operator", "kv:get foo")
+	if err != nil || res.Output != `value="bar"` {
+		t.Fatalf("kv:get = %+v, %v", res, err)
+	}
+	if data, ok := res.Data.(*pb.KvGetResponse); !ok || string(data.Value) != "bar" {
+		t.Errorf("data = %#v, want the KvGet response", res.Data)
+	}
+
+	res, err = app.Exec(h, nil, "operator", "kv:set foo")
+	if err == nil || !strings.Contains(res.Error, "usage") {
+		t.Errorf("kv:set without a value = %+v, %v; want a usage error", res, err)
+	}
+
+	srv, _ := newSecretsTestServer(t)
+	res, err = app.Exec(h, app.NewSecretsClient(srv.URL), "operator", "secrets:submit hello")
+	if err != nil || !strings.Contains(res.Output, `"hello" admitted`) {
+		t.Errorf("secrets:submit = %+v, %v", res, err)
+	}
+}
+
+func TestExec_Limits(t *testing.T) {
+	h := app.NewDemoHermitClient()
+	run := func(line string) string {
+		t.Helper()
+		res, err := app.Exec(h, nil, "operator", line)
+		if err != nil {
+			return "error: " + err.Error()
+		}
+		return res.Output
+	}
+	for _, c := range []struct{ line, want string }{
+		{"limits", "write unlimited, bench 1/s burst 3"},
+		{"limits:set write 2.5 0 ci", "write unlimited, bench 1/s burst 3, write@ci 2.5/s burst 3"},
+		{"limits:set write 10 20", "write 10/s burst 20, bench 1/s burst 3, write@ci 2.5/s burst 3"},
+		{"limits:clear write ci", "write 10/s burst 20, bench 1/s burst 3"},
+		{"limits:set read 1 1", `error: class "read": want write or bench`},
+		{"limits:set write fast 1", `error: rate "fast": want calls a second, 0 for unlimited`},
+		{"limits:clear write", "error: usage: limits:clear <write|bench> <client>"},
+	} {
+		if got := run(c.line); got != c.want {
+			t.Errorf("%s = %q, want %q", c.line, got, c.want)
+		}
+	}
+
+	if _, err := app.Exec(&mockHermit{}, nil, "operator", "limits"); !errors.Is(err, app.ErrUnsupported) {
+		t.Errorf("limits on a hermit without them: %v, want ErrUnsupported", err)
+	}
+}
+
+type replicationHermit struct {
+	*mockHermit
+	status *pb.ReplicationStatusResponse
+}
+
+func (h *replicationHermit) ReplicationStatus() (*pb.ReplicationStatusResponse, error) {
+	return h.status, nil
+}
+
+func TestExec_Replication(t *testing.T) {
+	h := app.NewDemoHermitClient()
+	run := func(h app.HermitClient, line string) string {
+		t.Helper()
+		res, err := app.Exec(h, nil, "operator", line)
+		if err != nil {
+			return "error: " + err.Error()
+		}
+		return res.Output
+	}
+	if got, want := run(h, "replication"), "primary at 0, followers: us-east1/hermit-replica 0 behind"; got != want {
+		t.Errorf("replication = %q, want %q", got, want)
+	}
+	run(h, "kv:set a 1")
+	run(h, "kv:del a")
+	if got, want := run(h, "replication"), "primary at 2, followers: us-east1/hermit-replica 0 behind"; got != want {
+		t.Errorf("replication after two writes = %q, want %q", got, want)
+	}
+
+	for _, c := range []struct {
+		status *pb.ReplicationStatusResponse
+		want   string
+	}{
+		{&pb.ReplicationStatusResponse{Role: "primary", OplogSeq: 9}, "primary at 9, no followers"},
+		{
+			&pb.ReplicationStatusResponse{Role: "follower", Primary: "http://primary:9090", Connected: true, AppliedSeq: 118, PrimarySeq: 120, Lag: 2, LastContactMs: 250},
+			"follower of http://primary:9090, applied 118 of 120 (2 behind), heard from 250ms ago",
+		},
+		{
+			&pb.ReplicationStatusResponse{Role: "follower", Primary: "http://primary:9090", Error: "connect: refused"},
+			"follower of http://primary:9090, not streaming: connect: refused",
+		},
+	} {
+		if got := run(&replicationHermit{&mockHermit{}, c.status}, "replication"); got != c.want {
+			t.Errorf("replication = %q, want %q", got, c.want)
+		}
+	}
+
+	if _, err := app.Exec(&mockHermit{}, nil, "operator", "replication"); !errors.Is(err, app.ErrUnsupported) {
+		t.Errorf("replication on a hermit without it: %v, want ErrUnsupported", err)
+	}
+}
+
+func TestExec_Buckets(t *testing.T) {
+	h := app.NewDemoHermitClient()
+	run := func(line string) string {
+		t.Helper()
+		res, err := app.Exec(h, nil, "operator", line)
+		if err != nil {
+			return "error: " + err.Error()
+		}
+		return res.Output
+	}
+	if got, want := run("buckets"), "default 6 keys 61B (1.7x) admin, orders 2 keys 25B (1.7x) admin"; got != want {
+		t.Errorf("buckets = %q, want %q", got, want)
+	}
+	if got, want := run("bucket:use orders"), "using orders 2 keys 25B (1.7x) admin"; got != want {
+		t.Errorf("bucket:use orders = %q, want %q", got, want)
+	}
+	if got := run("kv:exists config:motd"); !strings.HasPrefix(got, "NOT FOUND") {
+		t.Errorf("kv:exists config:motd in orders = %q, want NOT FOUND", got)
+	}
+	run("kv:set order:1043 x")
+	if got, want := run("bucket:create archive"), "created archive"; got != want {
+		t.Errorf("bucket:create = %q, want %q", got, want)
+	}
+	if got, want := run("bucket:create archive"), "archive already exists"; got != want {
+		t.Errorf("bucket:create again = %q, want %q", got, want)
+	}
+	if got, want := run("bucket:use missing"), `error: no bucket "missing" that you can read`; got != want {
+		t.Errorf("bucket:use missing = %q, want %q", got, want)
+	}
+	if got, want := run("bucket:use"), "using default 6 keys 61B (1.7x) admin"; got != want {
+		t.Errorf("bucket:use = %q, want %q", got, want)
+	}
+	if got := run("kv:exists order:1043"); !strings.HasPrefix(got, "NOT FOUND") {
+		t.Errorf("kv:exists order:1043 in default = %q, want NOT FOUND", got)
+	}
+	if got := run("kv:exists config:motd"); !strings.HasPrefix(got, "EXISTS") {
+		t.Errorf("kv:exists config:motd in default = %q, want EXISTS", got)
+	}
+
+	if _, err := app.Exec(&mockHermit{}, nil, "operator", "buckets"); !errors.Is(err, app.ErrUnsupported) {
+		t.Errorf("buckets on a hermit without them: %v, want ErrUnsupported", err)
+	}
+}
+
+func TestDBConsole_BucketUse(t *testing.T) {
+	m := doLogin(app.New(app.DemoAddr, "", app.NewDemoHermitClient(), nil))
+	m, cmd := pressEnter(m) // Hermit DB
+	m = runBatch(m, cmd)
+	if v := ansi.Strip(m.View().Content); !strings.Contains(v, "bucket in use: default") {
+		t.Errorf("want the default bucket in use:\n%s", v)
+	}
+	for _, c := range "bucket:use orders" {
+		m, _ = sendKey(m, c)
+	}
+	m, cmd = pressEnter(m)
+	m, _ = runCmd(m, cmd)
+	if v := ansi.Strip(m.View().Content); !strings.Contains(v, "bucket in use: orders") {
+		t.Errorf("want orders in use:\n%s", v)
+	}
+
+	// The history moves down with the stats above it; clicking it still
+	// recalls the command.
+	x, y := screenPos(t, m, "→ using orders")
+	m, _ = click(m, x, y)
+	if !strings.Contains(ansi.Strip(m.View().Content), "> bucket:use orders█") {
+		t.Error("clicking the history line did not recall its command")
+	}
+}
+
+func TestExec_Diag(t *testing.T) {
+	res, err := app.Exec(app.NewDemoHermitClient(), nil, "operator", "diag")
+	if err != nil {
+		t.Fatalf("diag: %v", err)
+	}
+	for _, want := range []string{"slow (over 100ms): KvList 412ms at ", "KvSet blob:thumbnail 187ms", "largest: config:motd 40B (24B stored)", "orders/order:1041 21B"} {
+		if !strings.Contains(res.Output, want) {
+			t.Errorf("diag = %q, want %q in it", res.Output, want)
+		}
+	}
+	if _, err := app.Exec(&mockHermit{}, nil, "operator", "diag"); !errors.Is(err, app.ErrUnsupported) {
+		t.Errorf("diag on a hermit without it: %v, want ErrUnsupported", err)
+	}
+}
+
+func TestDBConsole_ShowsDiagnostics(t *testing.T) {
+	m := doLogin(app.New(app.DemoAddr, "", app.NewDemoHermitClient(), nil))
+	m, cmd := pressEnter(m) // Hermit DB
+	m = runBatch(m, cmd)
+	v := ansi.Strip(m.View().Content)
+	for _, want := range []string{"slowest call (over 100ms): KvList 412ms at ", "largest value: config:motd 40B (24B stored)"} {
+		if !strings.Contains(v, want) {
+			t.Errorf("want %q in the stats panel:\n%s", want, v)
+		}
+	}
+	if strings.Contains(v, "blob:thumbnail") {
+		t.Errorf("want only the slowest call:\n%s", v)
+	}
+}
+
+func TestExec_TCPBench(t *testing.T) {
+	res, err := app.Exec(app.NewDemoHermitClient(), nil, "operator", "bench:tcp 5")
+	if err != nil {
+		t.Fatalf("bench:tcp: %v", err)
+	}
+	want := "median of 5: SET tcp 52.00μs grpc 198.00μs (3.8x); GET tcp 41.00μs grpc 162.00μs (4.0x); STATS tcp 33.00μs grpc 147.00μs (4.5x)"
+	if res.Output != want {
+		t.Errorf("bench:tcp = %q, want %q", res.Output, want)
+	}
+	if _, err := app.Exec(app.NewDemoHermitClient(), nil, "operator", "bench:tcp 0"); err == nil {
+		t.Error("bench:tcp 0: want an error")
+	}
+	if _, err := app.Exec(&mockHermit{}, nil, "operator", "bench:tcp"); !errors.Is(err, app.ErrUnsupported) {
+		t.Errorf("bench:tcp on a hermit without the TCP protocol: %v, want ErrUnsupported", err)
+	}
+}
+
+type quotaHermit struct {
+	*mockHermit
+	quota app.Quota
+}
+
+func (h *quotaHermit) Quota(class string) (app.Quota, bool) {
+	return h.quota, class == h.quota.Class
+}
+
+func TestDBConsole_ShowsWriteQuota(t *testing.T) {
+	h := &quotaHermit{
+		mockHermit: &mockHermit{serverInfo: &pb.ServerInfoResponse{}, dbStats: &pb.DbStatsResponse{}},
+		quota:      app.Quota{Class: "write", Limit: 20, Rate: 2.5, Remaining: 7},
+	}
+	m := doLogin(app.New("localhost:9090", "", h, nil))
+	m, cmd := pressEnter(m) // Hermit DB
+	m = runBatch(m, cmd)
+	if v := ansi.Strip(m.View().Content); !strings.Contains(v, "write quota: 7 of 20 left, refilling at 2.5/s") {
+		t.Errorf("want the write quota:\n%s", v)
+	}
+
+	h.quota.Class = "bench"
+	for _, c := range "stats" {
+		m, _ = sendKey(m, c)
+	}
+	m, cmd = pressEnter(m)
+	m, cmd = runCmd(m, cmd) // stats result
+	m = runBatch(m, cmd)    // dbStats refresh
+	if v := ansi.Strip(m.View().Content); strings.Contains(v, "write quota") {
+		t.Errorf("want no write quota once hermit stops limiting writes:\n%s", v)
+	}
+}
+
+func TestExec_StatsCodecs(t *testing.T) {
+	h := &mockHermit{dbStats: &pb.DbStatsResponse{
+		DocKeyCount: 3,
+		Namespaces: []*pb.DocNamespace{
+			{Name: "session", Codec: "lz4", Keys: 2, RawBytes: 4000, StoredBytes: 1000},
+			{Codec: "zstd", Keys: 1, RawBytes: 300, StoredBytes: 150},
+		},
+	}}
+	res, err := app.Exec(h, nil, "operator", "stats")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "codecs: session=lz4 4.0x, *=zstd 2.0x"; !strings.Contains(res.Output, want) {
+		t.Errorf("stats = %q, want %q", res.Output, want)
+	}
+}
+
+func TestExec_SnapshotRestore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hermit.snap")
+	src := app.NewDemoHermitClient()
+	if _, err := app.Exec(src, nil, "operator", "kv:set moved yes"); err != nil {
+		t.Fatal(err)
+	}
+	res, err := app.Exec(src, nil, "operator", "db:snapshot "+path)
+	if err != nil || !strings.HasPrefix(res.Output, "SAVED  "+path) {
+		t.Fatalf("db:snapshot = %+v, %v", res, err)
+	}
+
+	dst := app.NewDemoHermitClient()
+	res, err = app.Exec(dst, nil, "operator", "db:restore "+path)
+	if err != nil || !strings.Contains(res.Output, "keys=7 rows=12") {
+		t.Fatalf("db:restore = %+v, %v", res, err)
+	}
+	if res, err := app.Exec(dst, nil, "operator", "kv:get moved"); err != nil || res.Output != `value="yes" version=1` {
+		t.Errorf("kv:get after restore = %+v, %v", res, err)
+	}
+
+	// A failed snapshot leaves the last good one in place.
+	before, _ := os.ReadFile(path)
+	if _, err := app.Exec(&mockHermit{}, nil, "operator", "db:snapshot "+path); !errors.Is(err, app.ErrUnsupported) {
+		t.Errorf("db:snapshot without DbSnapshot: err = %v, want ErrUnsupported", err)
+	}
+	if after, _ := os.ReadFile(path); !bytes.Equal(before, after) {
+		t.Error("a failed db:snapshot changed the file")
+	}
+	if _, err := app.Exec(dst, nil, "operator", "db:restore "+path+".missing"); err == nil {
+		t.Error("db:restore of a missing file should fail")
+	}
+}
+
+type urlRestoreHermit struct {
+	*mockHermit
+	url string
+}
+
+func (h *urlRestoreHermit) RestoreFromURL(url string) (*pb.DbRestoreResponse, error) {
+	h.url = url
+	return &pb.DbRestoreResponse{DocKeys: 3, RelRows: 4}, nil
+}
+
+func TestExec_RestoreFromURL(t *testing.T) {
+	h := &urlRestoreHermit{mockHermit: &mockHermit{}}
+	res, err := app.Exec(h, nil, "operator", "db:restore s3://backups/hermit/snapshot-20261016T120000Z.bin")
+	if err != nil || res.Output != "RESTORED  s3://backups/hermit/snapshot-20261016T120000Z.bin  keys=3 rows=4" {
+		t.Fatalf("db:restore s3:// = %+v, %v", res, err)
+	}
+	if h.url != "s3://backups/hermit/snapshot-20261016T120000Z.bin" {
+		t.Errorf("RestoreFromURL got %q", h.url)
+	}
+	// The demo restores files only; a URL isn't read as one.
+	if _, err := app.Exec(app.NewDemoHermitClient(), nil, "operator", "db:restore https://example.com/hermit.snap"); !errors.Is(err, app.ErrUnsupported) {
+		t.Errorf("db:restore of a URL on the demo: %v, want ErrUnsupported", err)
+	}
+}
+
+func TestPortal_SignInAndConfirmClaim(t *testing.T) {
+	var confirmed []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /auth/magic", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("token") != "tok123" {
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "sess"})
+		http.Redirect(w, r, "/dashboard", http.StatusSeeOther)
+	})
+	authed := func(h http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if c, err := r.Cookie("session"); err != nil || c.Value != "sess" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			h(w, r)
+		}
+	}
+	mux.HandleFunc("GET /api/me", authed(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(app.PortalUser{ID: "u1", Name: "Ada", IsAdmin: true})
+	}))
+	mux.HandleFunc("GET /api/admin/claims", authed(func(w http.ResponseWriter, r *http.Request) {
+		claims := []app.PortalClaim{}
+		for _, id := range []string{"c1", "c2"} {
+			if !slices.Contains(confirmed, id) {
+				claims = append(claims, app.PortalClaim{ID: id, ClaimerName: "claimer-" + id, Status: app.ClaimPending})
+			}
+		}
+		json.NewEncoder(w).Encode(claims)
+	}))
+	mux.HandleFunc("POST /api/admin/claims/{id}/confirm", authed(func(w http.ResponseWriter, r *http.Request) {
+		confirmed = append(confirmed, r.PathValue("id"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	h := &mockHermit{serverInfo: &pb.ServerInfoResponse{}}
+	m := doLogin(app.New("localhost:9090", "", h, nil).WithPortal(app.NewPortalClient(srv.URL)).WithToastDuration(time.Millisecond))
+	for range 5 {
+		m, _ = pressDown(m)
+	}
+	m, _ = pressEnter(m) // Portal
+	// The link form works as well as the bare token.
+	for _, r := range srv.URL + "/auth/magic?token=tok123" {
+		m, _ = sendKey(m, r)
+	}
+	if v := ansi.Strip(m.View().Content); strings.Contains(v, "tok123") {
+		t.Errorf("the token should be masked:\n%s", v)
+	}
+	m, cmd := pressEnter(m)
+	m, cmd = runCmd(m, cmd) // signed in → claims
+	m, _ = runCmd(m, cmd)
+
+	v := ansi.Strip(m.View().Content)
+	for _, want := range []string{"signed in as Ada", "2 pending claims", "claimer-c1"} {
+		if !strings.Contains(v, want) {
+			t.Errorf("portal panel missing %q:\n%s", want, v)
+		}
+	}
+
+	m, cmd = sendKey(m, 'c')
+	m, cmd = runCmd(m, cmd)
+	m = runBatch(m, cmd) // reload and the toast's expiry
+	if !slices.Equal(confirmed, []string{"c1"}) {
+		t.Errorf("confirmed = %v, want [c1]", confirmed)
+	}
+	if v := ansi.Strip(m.View().Content); !strings.Contains(v, "1 pending claims") || !strings.Contains(v, "confirmed claim by claimer-c1") {
+		t.Errorf("want the list reloaded after confirming:\n%s", v)
+	}
+}
+
+func TestLogs_TailFilterAndSearch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"time":"2026-05-01T10:00:00Z","level":"info","msg":"server started","port":9090}`)
+		fmt.Fprintln(w, `{"time":"2026-05-01T10:00:01Z","level":"debug","msg":"cache warm"}`)
+		fmt.Fprintln(w, "2026-05-01 10:00:02 WARN disk 91% full")
+		fmt.Fprintln(w, `{"time":"2026-05-01T10:00:03Z","level":"error","msg":"flush failed","logger":"db"}`)
+	}))
+	defer srv.Close()
+
+	h := &mockHermit{serverInfo: &pb.ServerInfoResponse{}}
+	m := doLogin(app.New("localhost:9090", "", h, nil).WithLogSource("test", app.NewHTTPLogSource(srv.URL)))
+	for range 6 {
+		m, _ = pressDown(m)
+	}
+	m, cmd := pressEnter(m) // Logs
+	for cmd != nil {
+		m, cmd = runCmd(m, cmd)
+	}
+
+	v := ansi.Strip(m.View().Content)
+	for _, want := range []string{`server started {"port":9090}`, "cache warm", "disk 91% full", "db: flush failed", "4/4 lines", "ended"} {
+		if !strings.Contains(v, want) {
+			t.Errorf("logs panel missing %q:\n%s", want, v)
+		}
+	}
+
+	// TRACE → DEBUG → INFO → WARN hides the info and debug lines.
+	for range 3 {
+		m, _ = sendKey(m, 'v')
+	}
+	v = ansi.Strip(m.View().Content)
+	if strings.Contains(v, "cache warm") || strings.Contains(v, "server started") || !strings.Contains(v, "flush failed") || !strings.Contains(v, "2/4 lines") {
+		t.Errorf("want only WARN and up:\n%s", v)
+	}
+
+	m, _ = sendKey(m, '/')
+	for _, r := range "DISK" {
+		m, _ = sendKey(m, r)
+	}
+	m, _ = pressEnter(m)
+	v = ansi.Strip(m.View().Content)
+	if strings.Contains(v, "flush failed") || !strings.Contains(v, "disk 91% full") || !strings.Contains(v, "1/4 lines") {
+		t.Errorf("want the search to keep only the disk line:\n%s", v)
+	}
+
+	m, _ = pressEsc(m) // clears the search
+	if v := ansi.Strip(m.View().Content); !strings.Contains(v, "flush failed") {
+		t.Errorf("esc should clear the search:\n%s", v)
+	}
+}
+
+func TestLanguage_Spanish(t *testing.T) {
+	h := &mockHermit{serverInfo: &pb.ServerInfoResponse{Version: "1.2.3"}}
+	m, err := app.New("localhost:9090", "", h, nil).WithLanguage("es_MX.UTF-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m = doLogin(m)
+	v := ansi.Strip(m.View().Content)
+	for _, want := range []string{"Información del servidor", "Versión:", "Menú", "Explorador KV", "Salir", "[enter] elegir"} {
+		if !strings.Contains(v, want) {
+			t.Errorf("dashboard missing %q:\n%s", want, v)
+		}
+	}
+	// Data is shown as is.
+	if !strings.Contains(v, "Hermit DB") || !strings.Contains(v, "1.2.3") {
+		t.Errorf("want data untranslated:\n%s", v)
+	}
+
+	if _, err := m.WithLanguage("xx"); err == nil {
+		t.Error("WithLanguage(xx) succeeded, want error")
+	}
+}
+
+func TestPalette_RunsAction(t *testing.T) {
+	h := &mockHermit{serverInfo: &pb.ServerInfoResponse{}, dbStats: &pb.DbStatsResponse{}}
+	m := app.New("localhost:9090", "", h, nil)
+	m = doLogin(m)
+
+	m, _ = mustModel2(m.Update(tea.KeyPressMsg{Code: 'k', Mod: tea.ModCtrl}))
+	if v := m.View().Content; !strings.Contains(v, "Run benchmark") {
+		t.Fatalf("palette not shown:\n%s", v)
+	}
+
+	// A fuzzy query: "kvg" matches kv:get ahead of the other kv actions.
+	for _, c := range "kvg" {
+		m, _ = sendKey(m, c)
+	}
+	m, _ = pressEnter(m)
+	v := m.View().Content
+	if !strings.Contains(v, "DB Console") || !strings.Contains(v, "kv:get █") {
+		t.Errorf("expected DB console with kv:get typed:\n%s", v)
+	}
+	if strings.Contains(v, "Run benchmark") {
+		t.Error("palette should close after running an action")
+	}
+}
+
+func TestPalette_NotBeforeLogin(t *testing.T) {
+	m := app.New("localhost:9090", "", nil, nil)
+	m, _ = setSize(m, 120, 40)
+	m, _ = mustModel2(m.Update(tea.KeyPressMsg{Code: 'k', Mod: tea.ModCtrl}))
+	if strings.Contains(m.View().Content, "Run benchmark") {
+		t.Error("palette opened on the login screen")
+	}
+}
+
+// mustModel2 is a variant that works when Update() is called directly.
+func mustModel2(iface tea.Model, cmd tea.Cmd) (app.Model, tea.Cmd) {
+	return iface.(app.Model), cmd
+}
+
+// --- Hermit TLS checks ---
+
+type tcpPinger interface {
+	TCPPing(useTLS bool) (time.Duration, error)
+}
+
+func TestHermitTLS_Pin(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.NotFoundHandler())
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0) // refused handshakes are the point
+	srv.StartTLS()
+	defer srv.Close()
+	addr := srv.Listener.Addr().String()
+	pin := app.CertFingerprint(srv.Certificate().Raw)
+	other := strings.Repeat("ab", 32)
+
+	tests := []struct {
+		name    string
+		tls     app.HermitTLS
+		wantErr string // from the handshake; empty for none
+		warning string // TLSWarning after it
+	}{
+		{"pinned", app.HermitTLS{Pin: "SHA256:" + strings.ToUpper(pin)}, "", ""},
+		{"wrong pin", app.HermitTLS{Pin: other}, "pinned sha256:" + other, ""},
+		{"wrong pin, warn only", app.HermitTLS{Pin: other, WarnOnly: true}, "", "pinned sha256:" + other},
+		{"private CA", app.HermitTLS{CA: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})}, "", ""},
+		{"system pool", app.HermitTLS{}, "certificate", ""},
+		{"system pool, warn only", app.HermitTLS{WarnOnly: true}, "", "unknown authority"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := app.NewHermitClient(addr, "", tt.tls)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer h.Close()
+			_, err = h.(tcpPinger).TCPPing(true)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("handshake: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("handshake error = %v, want %q", err, tt.wantErr)
+			}
+			w := h.(app.TLSWarner).TLSWarning()
+			if tt.warning == "" && w != "" || !strings.Contains(w, tt.warning) {
+				t.Errorf("TLSWarning() = %q, want %q", w, tt.warning)
+			}
+		})
+	}
+
+	if _, err := app.NewHermitClient(addr, "", app.HermitTLS{Pin: "nope"}); err == nil {
+		t.Error("want an error for a pin that isn't a fingerprint")
+	}
+}
+
+type tlsWarnHermit struct {
+	*mockHermit
+	warning string
+}
+
+func (h *tlsWarnHermit) TLSWarning() string { return h.warning }
+
+func TestDashboard_ShowsTLSWarning(t *testing.T) {
+	h := &tlsWarnHermit{mockHermit: &mockHermit{serverInfo: &pb.ServerInfoResponse{Version: "1"}}, warning: "hermit's certificate is sha256:aa, pinned sha256:bb"}
+	m := doLogin(app.New("localhost:9090", "", h, nil))
+	if v := ansi.Strip(m.View().Content); !strings.Contains(v, "TLS not verified: hermit's certificate is sha256:aa") {
+		t.Errorf("want the TLS warning on the dashboard:\n%s", v)
+	}
 }
@@ -0,0 +1,457 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package app
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"math/bits"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+
+	pb "github.com/jredh-dev/nexus/cmd/tui/proto"
+)
+
+// benchConfig is what the Benchmark panel runs.
+type benchConfig struct {
+	iterations  int // total Benchmark iterations across all workers
+	payload     int // bytes per iteration
+	concurrency int // parallel Benchmark calls
+}
+
+func defaultBenchConfig() benchConfig {
+	return benchConfig{iterations: 100, payload: 0, concurrency: 1}
+}
+
+// benchField is one editable row of the benchmark form.
+type benchField struct {
+	label    string
+	min, max int
+	get      func(*benchConfig) *int
+}
+
+var benchFields = []benchField{
+	{"iterations", 1, 100_000, func(c *benchConfig) *int { return &c.iterations }},
+	{"payload bytes", 0, 1 << 20, func(c *benchConfig) *int { return &c.payload }},
+	{"concurrency", 1, 64, func(c *benchConfig) *int { return &c.concurrency }},
+}
+
+// BenchProgressStream is an open BenchmarkStream call.
+type BenchProgressStream interface {
+	Recv() (*pb.BenchmarkProgress, error)
+}
+
+// BenchmarkStreamer is a HermitClient that can run a benchmark as one
+// streaming call (the BenchmarkStream RPC), reporting progress and a latency
+// histogram as it goes. Without it the panel runs Benchmark in chunks.
+type BenchmarkStreamer interface {
+	BenchmarkStream(ctx context.Context, iterations, payloadBytes uint32) (BenchProgressStream, error)
+}
+
+// benchChunk caps the iterations per Benchmark call so progress moves in
+// visible steps instead of jumping from 0 to done.
+const benchChunk = 25
+
+// benchTickInterval is how often the panel redraws a running benchmark.
+const benchTickInterval = 100 * time.Millisecond
+
+// histSubBits is hermit's histogram precision: each power of two is split
+// into 1<<histSubBits buckets.
+const histSubBits = 4
+
+// histUpper is the bound of the histogram bucket ns falls in.
+func histUpper(ns int64) int64 {
+	v := uint64(max(ns, 0))
+	if v < 1<<histSubBits {
+		return int64(v)
+	}
+	shift := bits.Len64(v) - 1 - histSubBits
+	return int64(min((v>>shift+1)<<shift-1, math.MaxInt64))
+}
+
+// histAdd counts ns into h, which is sorted by bound.
+func histAdd(h []*pb.HistogramBucket, ns int64) []*pb.HistogramBucket {
+	u := histUpper(ns)
+	i, found := slices.BinarySearchFunc(h, u, func(b *pb.HistogramBucket, u int64) int {
+		return cmp.Compare(b.UpperNs, u)
+	})
+	if found {
+		h[i].Count++
+		return h
+	}
+	return slices.Insert(h, i, &pb.HistogramBucket{UpperNs: u, Count: 1})
+}
+
+// histQuantile is the bound of the bucket holding the q quantile of p's
+// histogram, but never above its slowest sample.
+func histQuantile(p *pb.BenchmarkProgress, q float64) int64 {
+	var n uint64
+	for _, b := range p.Histogram {
+		n += b.Count
+	}
+	if n == 0 {
+		return 0
+	}
+	want := min(max(uint64(math.Ceil(q*float64(n))), 1), n)
+	var cum uint64
+	for _, b := range p.Histogram {
+		cum += b.Count
+		if cum >= want {
+			return min(b.UpperNs, p.MaxNs)
+		}
+	}
+	return p.MaxNs
+}
+
+// benchProgress is shared between the workers of one run and the UI, which
+// samples it on every tick. Each worker keeps its own totals; a sample
+// merges them.
+type benchProgress struct {
+	mu      sync.Mutex
+	total   int
+	payload int
+	parts   []*pb.BenchmarkProgress // one per worker
+	err     error
+	started time.Time
+}
+
+func newBenchProgress(cfg benchConfig) *benchProgress {
+	p := &benchProgress{total: cfg.iterations, payload: cfg.payload, started: time.Now()}
+	p.reset(cfg.concurrency)
+	return p
+}
+
+// reset forgets everything the workers recorded.
+func (p *benchProgress) reset(workers int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.parts = make([]*pb.BenchmarkProgress, workers)
+	for i := range p.parts {
+		p.parts[i] = &pb.BenchmarkProgress{}
+	}
+	p.err = nil
+}
+
+// set records worker i's latest BenchmarkStream progress, which covers
+// everything it has run.
+func (p *benchProgress) set(i int, bp *pb.BenchmarkProgress) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.parts[i] = bp
+}
+
+// add folds a Benchmark call of n iterations into worker i's totals.
+func (p *benchProgress) add(i int, resp *pb.BenchmarkResponse, n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	part := p.parts[i]
+	var samples uint64
+	for _, b := range part.Histogram {
+		samples += b.Count
+	}
+	sum := float64(part.MeanNs) * float64(samples)
+	for _, l := range resp.LatenciesNs {
+		if samples == 0 || l < part.MinNs {
+			part.MinNs = l
+		}
+		part.MaxNs = max(part.MaxNs, l)
+		part.Histogram = histAdd(part.Histogram, l)
+		sum += float64(l)
+		samples++
+	}
+	if samples > 0 {
+		part.MeanNs = int64(sum / float64(samples))
+	}
+	part.Done += uint32(n)
+	part.IterationsPerSec = float64(part.Done) / time.Since(p.started).Seconds()
+	part.BytesPerSec = part.IterationsPerSec * float64(p.payload)
+	part.TlsActive, part.TlsVersion = resp.TlsActive, resp.TlsVersion
+}
+
+func (p *benchProgress) fail(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err == nil {
+		p.err = err
+	}
+}
+
+func (p *benchProgress) failure() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+// snapshot merges the workers' totals so far. The workers run side by
+// side, so their rates add up.
+func (p *benchProgress) snapshot() *pb.BenchmarkProgress {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := &pb.BenchmarkProgress{Total: uint32(p.total), ElapsedNs: time.Since(p.started).Nanoseconds()}
+	counts := map[int64]uint64{}
+	var sum float64
+	for _, part := range p.parts {
+		if part.Done == 0 {
+			continue
+		}
+		if out.Done == 0 || part.MinNs < out.MinNs {
+			out.MinNs = part.MinNs
+		}
+		out.MaxNs = max(out.MaxNs, part.MaxNs)
+		sum += float64(part.MeanNs) * float64(part.Done)
+		out.Done += part.Done
+		out.IterationsPerSec += part.IterationsPerSec
+		out.BytesPerSec += part.BytesPerSec
+		for _, b := range part.Histogram {
+			counts[b.UpperNs] += b.Count
+		}
+		if part.TlsActive {
+			out.TlsActive, out.TlsVersion = true, part.TlsVersion
+		}
+	}
+	if out.Done > 0 {
+		out.MeanNs = int64(sum / float64(out.Done))
+	}
+	for _, u := range slices.Sorted(maps.Keys(counts)) {
+		out.Histogram = append(out.Histogram, &pb.HistogramBucket{UpperNs: u, Count: counts[u]})
+	}
+	out.P50Ns = histQuantile(out, 0.50)
+	out.P90Ns = histQuantile(out, 0.90)
+	out.P99Ns = histQuantile(out, 0.99)
+	out.P999Ns = histQuantile(out, 0.999)
+	return out
+}
+
+// result is the final snapshot.
+func (p *benchProgress) result() *pb.BenchmarkProgress {
+	out := p.snapshot()
+	out.Final = true
+	return out
+}
+
+// summarize computes the aggregate stats hermit reports for one call.
+func summarize(lat []int64) *pb.BenchmarkResponse {
+	resp := &pb.BenchmarkResponse{LatenciesNs: lat}
+	if len(lat) == 0 {
+		return resp
+	}
+	sorted := slices.Clone(lat)
+	slices.Sort(sorted)
+	var sum int64
+	for _, l := range sorted {
+		sum += l
+	}
+	resp.MinNs = sorted[0]
+	resp.MaxNs = sorted[len(sorted)-1]
+	resp.MeanNs = sum / int64(len(sorted))
+	resp.P50Ns = sorted[len(sorted)*50/100]
+	resp.P99Ns = sorted[min(len(sorted)-1, len(sorted)*99/100)]
+	return resp
+}
+
+// doBenchmark runs cfg against hermit, recording into prog as it goes, and
+// reports the aggregate when every worker is done. It streams when hermit
+// can and falls back to chunked Benchmark calls when it can't.
+func (m Model) doBenchmark(cfg benchConfig, prog *benchProgress, gen int) tea.Cmd {
+	h := m.hermit
+	return func() tea.Msg {
+		if h == nil {
+			return benchmarkResultMsg{gen: gen, err: fmt.Errorf("not connected")}
+		}
+		err := ErrUnsupported
+		if s, ok := h.(BenchmarkStreamer); ok {
+			err = benchStreams(s, cfg, prog)
+		}
+		if errors.Is(err, ErrUnsupported) {
+			prog.reset(cfg.concurrency)
+			err = benchChunks(h, cfg, prog)
+		}
+		if err != nil {
+			return benchmarkResultMsg{gen: gen, err: err}
+		}
+		return benchmarkResultMsg{gen: gen, resp: prog.result()}
+	}
+}
+
+// benchStreams splits cfg's iterations between one BenchmarkStream per
+// worker.
+func benchStreams(s BenchmarkStreamer, cfg benchConfig, prog *benchProgress) error {
+	var wg sync.WaitGroup
+	for i := range cfg.concurrency {
+		n := cfg.iterations / cfg.concurrency
+		if i < cfg.iterations%cfg.concurrency {
+			n++
+		}
+		if n == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			stream, err := s.BenchmarkStream(ctx, uint32(n), uint32(cfg.payload))
+			if err != nil {
+				prog.fail(err)
+				return
+			}
+			for {
+				p, err := stream.Recv()
+				if err == io.EOF {
+					return
+				}
+				if err != nil {
+					prog.fail(err)
+					return
+				}
+				prog.set(i, p)
+			}
+		}()
+	}
+	wg.Wait()
+	return prog.failure()
+}
+
+// benchChunks runs cfg as Benchmark calls of at most benchChunk
+// iterations, shared out between the workers.
+func benchChunks(h HermitClient, cfg benchConfig, prog *benchProgress) error {
+	chunks := make(chan int)
+	go func() {
+		defer close(chunks)
+		for left := cfg.iterations; left > 0; left -= benchChunk {
+			chunks <- min(left, benchChunk)
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := range cfg.concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range chunks {
+				resp, err := h.Benchmark(uint32(n), uint32(cfg.payload))
+				if err != nil {
+					prog.fail(err)
+					continue // drain so the feeder can finish
+				}
+				prog.add(i, resp, n)
+			}
+		}()
+	}
+	wg.Wait()
+	return prog.failure()
+}
+
+func (m Model) benchTick() tea.Cmd {
+	gen := m.benchGen
+	return tea.Tick(benchTickInterval, func(time.Time) tea.Msg {
+		return benchTickMsg{gen: gen}
+	})
+}
+
+// startBenchmark launches the configured run.
+func (m Model) startBenchmark() (Model, tea.Cmd) {
+	m.benchGen++
+	m.benchRunning = true
+	m.grpcBench = nil
+	m.err = nil
+	m.benchProg = newBenchProgress(m.benchCfg)
+	return m, tea.Batch(m.doBenchmark(m.benchCfg, m.benchProg, m.benchGen), m.benchTick())
+}
+
+func (m Model) handleBenchKey(k tea.Key) (tea.Model, tea.Cmd) {
+	if m.benchRunning {
+		if m.pressed(k, m.keys.back) {
+			m.state = stateDashboard
+		}
+		return m, nil
+	}
+	f := benchFields[m.benchField]
+	v := f.get(&m.benchCfg)
+	typed := m.benchTyped
+	m.benchTyped = false
+	switch {
+	case m.pressed(k, m.keys.back, m.keys.quit):
+		m.state = stateDashboard
+	case m.pressed(k, m.keys.up):
+		if m.benchField > 0 {
+			m.benchField--
+		}
+	case m.pressed(k, m.keys.down):
+		if m.benchField < len(benchFields)-1 {
+			m.benchField++
+		}
+	case m.pressed(k, m.keys.right):
+		*v = clampInt(max(*v*2, *v+1), f.min, f.max)
+	case m.pressed(k, m.keys.left):
+		*v = clampInt(*v/2, f.min, f.max)
+	case k.Code == tea.KeyBackspace:
+		*v = clampInt(*v/10, f.min, f.max)
+		m.benchTyped = true
+	case m.pressed(k, m.keys.sel):
+		if m.conn == connReconnecting {
+			return m, nil
+		}
+		return m.startBenchmark()
+	default:
+		// The first digit replaces the value; later ones append.
+		if d, err := strconv.Atoi(k.Text); err == nil && len(k.Text) == 1 {
+			if !typed {
+				*v = 0
+			}
+			*v = clampInt(*v*10+d, f.min, f.max)
+			m.benchTyped = true
+		}
+	}
+	return m, nil
+}
+
+func clampInt(v, lo, hi int) int {
+	return max(lo, min(v, hi))
+}
+
+// sparkBars are the eight heights a histogram bucket can take.
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline renders p's histogram as width columns between the fastest
+// sample and the p99, so a few outliers don't flatten the shape. Slower
+// samples land in the last column.
+func sparkline(p *pb.BenchmarkProgress, width int) string {
+	if len(p.Histogram) == 0 || width < 1 {
+		return ""
+	}
+	lo := p.MinNs
+	hi := histQuantile(p, 0.99)
+	if hi <= lo {
+		hi = lo + 1
+	}
+
+	cols := make([]uint64, width)
+	var peak uint64
+	for _, b := range p.Histogram {
+		i := int(int64(width) * (b.UpperNs - lo) / (hi - lo))
+		i = clampInt(i, 0, width-1)
+		cols[i] += b.Count
+		peak = max(peak, cols[i])
+	}
+
+	out := make([]rune, width)
+	for i, c := range cols {
+		if c == 0 {
+			out[i] = ' '
+			continue
+		}
+		out[i] = sparkBars[c*uint64(len(sparkBars)-1)/peak]
+	}
+	return string(out)
+}
@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package app
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+
+	pb "github.com/jredh-dev/nexus/cmd/tui/proto"
+)
+
+// defaultBucket is the bucket hermit uses for calls that don't name one.
+const defaultBucket = "default"
+
+// Bucketer is a HermitClient that can work in one of hermit's buckets,
+// isolated keyspaces of the document store (the ListBuckets and
+// CreateBucket RPCs; document store calls name their bucket in the
+// x-hermit-bucket metadata).
+type Bucketer interface {
+	Buckets() (*pb.ListBucketsResponse, error)
+	CreateBucket(name string) (*pb.CreateBucketResponse, error)
+	UseBucket(name string) // "" is hermit's default
+	Bucket() string
+}
+
+// currentBucket names the bucket h's document store calls go to.
+func currentBucket(h HermitClient) string {
+	if b, ok := h.(Bucketer); ok {
+		return cmp.Or(b.Bucket(), defaultBucket)
+	}
+	return defaultBucket
+}
+
+// fmtBucket shows a bucket as "orders 12 keys 3.4 KB (2.0x) write".
+func fmtBucket(b *pb.Bucket) string {
+	return fmt.Sprintf("%s %d keys %s (%s) %s",
+		b.Name, b.Keys, fmtBytes(b.StoredBytes), fmtRatio(b.RawBytes, b.StoredBytes), b.Role)
+}
+
+// bucketsCommand runs the buckets, bucket:create and bucket:use console
+// commands.
+func (m Model) bucketsCommand(raw string, parts []string) tea.Cmd {
+	verb := strings.ToLower(parts[0])
+	switch {
+	case verb == "bucket:create" && len(parts) != 2:
+		return m.dbResult(raw, "", fmt.Errorf("usage: bucket:create <name>"))
+	case verb == "bucket:use" && len(parts) > 2:
+		return m.dbResult(raw, "", fmt.Errorf("usage: bucket:use [name]"))
+	}
+
+	h := m.hermit
+	return func() tea.Msg {
+		if h == nil {
+			return dbCmdResultMsg{cmd: raw, err: fmt.Errorf("not connected")}
+		}
+		b, ok := h.(Bucketer)
+		if !ok {
+			return dbCmdResultMsg{cmd: raw, err: ErrUnsupported}
+		}
+		switch verb {
+		case "bucket:create":
+			resp, err := b.CreateBucket(parts[1])
+			if err != nil {
+				return dbCmdResultMsg{cmd: raw, err: err}
+			}
+			out := "created " + parts[1]
+			if !resp.Created {
+				out = parts[1] + " already exists"
+			}
+			return dbCmdResultMsg{cmd: raw, output: out, data: resp}
+
+		case "bucket:use":
+			name := defaultBucket
+			if len(parts) == 2 {
+				name = parts[1]
+			}
+			resp, err := b.Buckets()
+			if err != nil {
+				return dbCmdResultMsg{cmd: raw, err: err}
+			}
+			for _, bk := range resp.Buckets {
+				if bk.Name != name {
+					continue
+				}
+				// Calls without a bucket go to the default, so there's
+				// no need to name it.
+				if name == defaultBucket {
+					name = ""
+				}
+				b.UseBucket(name)
+				return dbCmdResultMsg{cmd: raw, output: "using " + fmtBucket(bk), data: bk}
+			}
+			return dbCmdResultMsg{cmd: raw, err: fmt.Errorf("no bucket %q that you can read", name)}
+		}
+
+		resp, err := b.Buckets()
+		if err != nil {
+			return dbCmdResultMsg{cmd: raw, err: err}
+		}
+		buckets := make([]string, len(resp.Buckets))
+		for i, bk := range resp.Buckets {
+			buckets[i] = fmtBucket(bk)
+		}
+		return dbCmdResultMsg{cmd: raw, output: strings.Join(buckets, ", "), data: resp}
+	}
+}
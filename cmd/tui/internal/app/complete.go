@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package app
+
+import (
+	"sort"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// dbVerbs are the DB console commands, in the order help lists them.
+var dbVerbs = []string{"kv:set", "kv:setex", "kv:get", "kv:del", "kv:exists", "kv:ttl", "kv:cas", "kv:incr", "kv:list", "sql:insert", "sql:query", "sql:count", "sql:delete", "sql:update", "db:snapshot", "db:restore", "limits", "limits:set", "limits:clear", "replication", "buckets", "bucket:create", "bucket:use", "diag", "bench:tcp", "stats", "help"}
+
+// keyVerbs take a key as their first argument.
+var keyVerbs = map[string]bool{"kv:set": true, "kv:setex": true, "kv:get": true, "kv:del": true, "kv:exists": true, "kv:ttl": true, "kv:cas": true, "kv:incr": true, "sql:insert": true, "sql:query": true, "sql:count": true, "sql:delete": true, "sql:update": true}
+
+// completeDB completes the last word of input against the console verbs or,
+// after a key-taking verb, the cached document store keys. It returns the
+// new input and, when the word is still ambiguous, the candidates.
+func completeDB(input string, keys []string) (string, []string) {
+	fields := strings.Fields(input)
+	atBoundary := input == "" || strings.HasSuffix(input, " ")
+
+	var word string
+	var pool []string
+	switch {
+	case len(fields) == 0 || (len(fields) == 1 && !atBoundary):
+		pool = dbVerbs
+	case keyVerbs[strings.ToLower(fields[0])] &&
+		(len(fields) == 1 && atBoundary || len(fields) == 2 && !atBoundary):
+		pool = keys
+	default:
+		return input, nil
+	}
+	if !atBoundary {
+		word = fields[len(fields)-1]
+	}
+
+	var matches []string
+	for _, c := range pool {
+		if strings.HasPrefix(c, word) {
+			matches = append(matches, c)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return input, nil
+	case 1:
+		return input[:len(input)-len(word)] + matches[0] + " ", nil
+	}
+	prefix := commonPrefix(matches)
+	return input[:len(input)-len(word)] + prefix, matches
+}
+
+func commonPrefix(ss []string) string {
+	p := ss[0]
+	for _, s := range ss[1:] {
+		for !strings.HasPrefix(s, p) {
+			p = p[:len(p)-1]
+		}
+	}
+	return p
+}
+
+// rememberKeys merges keys into the completion cache.
+func (m *Model) rememberKeys(keys ...string) {
+	if m.kvKeys == nil {
+		m.kvKeys = make(map[string]bool)
+	}
+	for _, k := range keys {
+		m.kvKeys[k] = true
+	}
+}
+
+// forgetKeys drops deleted keys from the completion cache.
+func (m *Model) forgetKeys(keys ...string) {
+	for _, k := range keys {
+		delete(m.kvKeys, k)
+	}
+}
+
+// knownKeys returns the cached keys, sorted.
+func (m Model) knownKeys() []string {
+	keys := make([]string, 0, len(m.kvKeys))
+	for k := range m.kvKeys {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// doKvKeys refreshes the completion cache in the background.
+func (m Model) doKvKeys() tea.Cmd {
+	return func() tea.Msg {
+		if m.hermit == nil {
+			return kvKeysMsg{}
+		}
+		resp, err := m.hermit.KvList("", "", 0)
+		if err != nil {
+			return kvKeysMsg{err: err}
+		}
+		return kvKeysMsg{keys: resp.Keys}
+	}
+}
@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package app
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// connState is the TUI's view of the hermit connection.
+type connState int
+
+const (
+	connUp connState = iota
+	connReconnecting
+)
+
+// Reconnect backoff: the first retry comes after reconnectBase, doubling up
+// to reconnectMax, each with ±20% jitter.
+const (
+	reconnectBase = 500 * time.Millisecond
+	reconnectMax  = 30 * time.Second
+)
+
+// isConnLost reports whether err means hermit is unreachable, as opposed to
+// an error from a request that reached it. A session that hermit no longer
+// knows, because it ran out or hermit restarted, counts: the reconnect logs
+// in again.
+func isConnLost(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.Unauthenticated:
+		return true
+	}
+	return false
+}
+
+// WithToken sets the token the username logs in to hermit with. A hermit
+// without a users file takes any token.
+func (m Model) WithToken(token string) Model {
+	m.token = token
+	return m
+}
+
+// reconnectDelay returns the backoff before retry attempt n (0-based).
+func reconnectDelay(n int) time.Duration {
+	d := reconnectBase << min(n, 6)
+	d = min(d, reconnectMax)
+	jitter := 0.8 + 0.4*rand.Float64()
+	return time.Duration(float64(d) * jitter)
+}
+
+// connLost handles an error from a hermit call. If it means the connection
+// dropped, the model starts reconnecting (once, however many calls fail) and
+// lost is true so the caller drops the error instead of showing it.
+func (m Model) connLost(err error) (_ Model, cmd tea.Cmd, lost bool) {
+	if !isConnLost(err) {
+		return m, nil, false
+	}
+	if m.conn == connReconnecting {
+		return m, nil, true
+	}
+	m.conn = connReconnecting
+	m.reconnectAttempt = 0
+	m.connGen++
+	return m, m.scheduleReconnect(), true
+}
+
+func (m Model) scheduleReconnect() tea.Cmd {
+	gen := m.connGen
+	delay := reconnectDelay(m.reconnectAttempt)
+	return tea.Tick(delay, func(time.Time) tea.Msg {
+		return reconnectTickMsg{gen: gen}
+	})
+}
+
+// doReconnect logs in again; a successful login is the sign hermit is back.
+func (m Model) doReconnect() tea.Cmd {
+	gen := m.connGen
+	return func() tea.Msg {
+		if m.hermit == nil {
+			return reconnectResultMsg{gen: gen, err: fmt.Errorf("hermit client not configured")}
+		}
+		role, err := m.hermit.Login(m.username, m.token)
+		return reconnectResultMsg{gen: gen, role: role, err: err}
+	}
+}
+
+func (m Model) handleReconnectTick(msg reconnectTickMsg) (tea.Model, tea.Cmd) {
+	if msg.gen != m.connGen || m.conn != connReconnecting {
+		return m, nil
+	}
+	return m, m.doReconnect()
+}
+
+func (m Model) handleReconnectResult(msg reconnectResultMsg) (tea.Model, tea.Cmd) {
+	if msg.gen != m.connGen || m.conn != connReconnecting {
+		return m, nil
+	}
+	if msg.err != nil {
+		m.reconnectAttempt++
+		return m, m.scheduleReconnect()
+	}
+	m.conn = connUp
+	m.reconnectAttempt = 0
+	m.role = msg.role
+	ts := time.Now().Format("15:04:05")
+	m.viewHistory = append(m.viewHistory, fmt.Sprintf("[%s] reconnected to %s", ts, m.addr))
+
+	// Refresh whatever the current panel shows from hermit.
+	cmds := []tea.Cmd{m.doServerInfo()}
+	switch m.state {
+	case stateDB:
+		cmds = append(cmds, m.doDbStats(), m.doKvKeys())
+	case stateKV:
+		cmds = append(cmds, m.doKvBrowse())
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// connBadge is the status shown in the top-right corner once logged in.
+func (m Model) connBadge() string {
+	if m.conn == connReconnecting {
+		return m.st.err.Render(m.trf(" ● reconnecting (attempt %d) ", m.reconnectAttempt+1))
+	}
+	if m.role != "" {
+		return m.st.prompt.Render(m.trf(" ● connected as %s (%s) ", m.username, m.role))
+	}
+	return m.st.prompt.Render(m.tr(" ● connected "))
+}
@@ -0,0 +1,1167 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package app
+
+import (
+	"bytes"
+	"cmp"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/jredh-dev/nexus/cmd/tui/proto"
+)
+
+// DemoAddr is the address the TUI shows in demo mode.
+const DemoAddr = "demo (no server)"
+
+// --- Demo hermit ---
+
+// demoHermit is an in-memory stand-in for hermit with made-up but
+// plausible latencies, for trying the TUI without servers.
+type demoHermit struct {
+	started time.Time
+
+	mu      sync.Mutex
+	kv      map[string][]byte
+	vers    map[string]uint64                 // each key's version, as in hermit
+	expires map[string]time.Time              // keys set with a TTL
+	watches map[chan *pb.WatchEvent]demoWatch // Watch streams
+	rows    []*pb.SqlRow
+	pending []*pb.SqlRow // inserted but not yet flushed to rows
+
+	// kv, vers and expires are the maps of the bucket in use; the rest
+	// sit here until UseBucket switches to them.
+	bucket  string // "" is the default
+	buckets map[string]*demoBucket
+
+	user     string                           // as logged in, for Publish
+	subs     map[chan *pb.TopicMessage]string // Subscribe streams and their topics
+	topicSeq map[string]uint64
+
+	limits []*pb.RateLimit // as SetLimit left them; not enforced
+	oplog  uint64          // document store changes, as ReplicationStatus counts them
+}
+
+// NewDemoHermitClient returns a HermitClient backed by memory, seeded with
+// some keys and rows.
+func NewDemoHermitClient() HermitClient {
+	h := &demoHermit{
+		started: time.Now().Add(-3*time.Hour - 17*time.Minute),
+		kv: map[string][]byte{
+			"config:motd":     []byte("Welcome to hermit. Be kind to the cache."),
+			"config:region":   []byte("us-central1"),
+			"session:7f3a":    []byte(`{"user":"operator","ttl":3600}`),
+			"blob:thumbnail":  {0x89, 'P', 'N', 'G', 0x0d, 0x0a, 0x1a, 0x0a, 0, 0, 0, 0x0d},
+			"counter:visits":  []byte("18234"),
+			"feature:dark-ui": []byte("true"),
+		},
+		vers:     map[string]uint64{},
+		expires:  map[string]time.Time{},
+		watches:  map[chan *pb.WatchEvent]demoWatch{},
+		subs:     map[chan *pb.TopicMessage]string{},
+		topicSeq: map[string]uint64{},
+		limits:   []*pb.RateLimit{{Class: "write"}, {Class: "bench", PerSecond: 1, Burst: 3}},
+	}
+	for k := range h.kv {
+		h.vers[k] = 1
+	}
+	h.vers["counter:visits"] = 18234
+	h.expires["session:7f3a"] = time.Now().Add(time.Hour)
+	h.buckets = map[string]*demoBucket{
+		defaultBucket: {kv: h.kv, vers: h.vers, expires: h.expires, created: h.started},
+		"orders": {
+			kv:      map[string][]byte{"order:1041": []byte(`{"sku":"mug","qty":2}`), "order:1042": []byte(`{"sku":"tee","qty":1}`)},
+			vers:    map[string]uint64{"order:1041": 3, "order:1042": 1},
+			expires: map[string]time.Time{},
+			created: h.started.Add(40 * time.Minute),
+		},
+	}
+	for i := range 12 {
+		h.rows = append(h.rows, &pb.SqlRow{
+			Id:          uuid.NewString(),
+			Key:         fmt.Sprintf("event:%02d", i),
+			Value:       demoEvents[i%len(demoEvents)],
+			CreatedAtMs: uint64(h.started.Add(time.Duration(i) * 11 * time.Minute).UnixMilli()),
+		})
+	}
+	return h
+}
+
+var demoEvents = []string{"login", "kv:set config:motd", "benchmark 1000x", "logout", "sql:insert", "cache miss"}
+
+// demoLatency is a round trip of about 300µs with a long tail.
+func demoLatency() time.Duration {
+	d := time.Duration(200_000 + rand.ExpFloat64()*100_000)
+	if rand.IntN(50) == 0 {
+		d *= 8
+	}
+	return d
+}
+
+// demoCall sleeps like a round trip would take.
+func demoCall() { time.Sleep(demoLatency()) }
+
+func (h *demoHermit) Login(username, _ string) (string, error) {
+	demoCall()
+	if strings.TrimSpace(username) == "" {
+		return "", fmt.Errorf("empty username")
+	}
+	h.mu.Lock()
+	h.user = username
+	h.mu.Unlock()
+	return "admin", nil
+}
+
+func (h *demoHermit) ServerInfo() (*pb.ServerInfoResponse, error) {
+	demoCall()
+	return &pb.ServerInfoResponse{
+		Version:       "0.9.0-demo",
+		Region:        "demo",
+		StartedAt:     timestamppb.New(h.started),
+		UptimeSeconds: int64(time.Since(h.started).Seconds()),
+		RustVersion:   "1.87.0",
+		TlsEnabled:    true,
+		GrpcPort:      9090,
+	}, nil
+}
+
+func (h *demoHermit) Benchmark(iterations, payloadBytes uint32) (*pb.BenchmarkResponse, error) {
+	lat := make([]int64, iterations)
+	var total time.Duration
+	for i := range lat {
+		d := demoLatency() + time.Duration(payloadBytes)*2 // ~2ns a byte on the wire
+		lat[i] = d.Nanoseconds()
+		total += d
+	}
+	time.Sleep(total)
+	resp := summarize(lat)
+	resp.ProcessingOverheadNs = 18_000
+	resp.TlsActive = true
+	resp.TlsVersion = "TLS 1.3"
+	return resp, nil
+}
+
+// BenchmarkStream runs the demo benchmark a tenth of a second at a time,
+// sending progress after each.
+func (h *demoHermit) BenchmarkStream(ctx context.Context, iterations, payloadBytes uint32) (BenchProgressStream, error) {
+	demoCall()
+	ch := make(chan *pb.BenchmarkProgress)
+	go func() {
+		defer close(ch)
+		prog := newBenchProgress(benchConfig{iterations: int(iterations), payload: int(payloadBytes), concurrency: 1})
+		for left := int(iterations); left > 0; {
+			var lat []int64
+			var took time.Duration
+			for ; left > 0 && took < benchTickInterval; left-- {
+				d := demoLatency() + time.Duration(payloadBytes)*2
+				lat = append(lat, d.Nanoseconds())
+				took += d
+			}
+			time.Sleep(took)
+			prog.add(0, &pb.BenchmarkResponse{LatenciesNs: lat, TlsActive: true, TlsVersion: "TLS 1.3"}, len(lat))
+			p := prog.snapshot()
+			p.Final = left == 0
+			select {
+			case ch <- p:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return demoBenchStream(ch), nil
+}
+
+// expire drops keys whose TTL has passed, like hermit's expirer. Callers
+// hold h.mu.
+func (h *demoHermit) expire() {
+	now := time.Now()
+	for k, t := range h.expires {
+		if !now.Before(t) {
+			delete(h.kv, k)
+			delete(h.vers, k)
+			delete(h.expires, k)
+			h.publish(&pb.WatchEvent{Kind: pb.WatchEvent_EXPIRE, Key: k})
+		}
+	}
+}
+
+// put writes a key and returns its new version. Callers hold h.mu and have
+// expired keys.
+func (h *demoHermit) put(key string, value []byte, ttl time.Duration) uint64 {
+	h.kv[key] = append([]byte(nil), value...)
+	h.vers[key]++
+	delete(h.expires, key)
+	if ttl > 0 {
+		h.expires[key] = time.Now().Add(ttl)
+	}
+	h.publish(&pb.WatchEvent{Kind: pb.WatchEvent_SET, Key: key, Value: h.kv[key]})
+	return h.vers[key]
+}
+
+// remove deletes a key, reporting whether it was set. Callers hold h.mu.
+func (h *demoHermit) remove(key string) bool {
+	_, ok := h.kv[key]
+	delete(h.kv, key)
+	delete(h.vers, key)
+	delete(h.expires, key)
+	if ok {
+		h.publish(&pb.WatchEvent{Kind: pb.WatchEvent_DELETE, Key: key})
+	}
+	return ok
+}
+
+func (h *demoHermit) KvSet(key string, value []byte, ttl time.Duration) (*pb.KvSetResponse, error) {
+	demoCall()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.expire()
+	return &pb.KvSetResponse{Ok: true, Version: h.put(key, value, ttl)}, nil
+}
+
+func (h *demoHermit) KvGet(key string) (*pb.KvGetResponse, error) {
+	demoCall()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.expire()
+	v, ok := h.kv[key]
+	return &pb.KvGetResponse{Found: ok, Value: v, Version: h.vers[key]}, nil
+}
+
+func (h *demoHermit) KvList(prefix, cursor string, limit uint32) (*pb.KvListResponse, error) {
+	demoCall()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.expire()
+	keys := make([]string, 0, len(h.kv))
+	for k := range h.kv {
+		if strings.HasPrefix(k, prefix) && k > cursor {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return pageKeys(keys, limit), nil
+}
+
+// pageKeys cuts the first page of sorted keys the way hermit does.
+func pageKeys(keys []string, limit uint32) *pb.KvListResponse {
+	if limit == 0 || limit > 1000 {
+		limit = 1000
+	}
+	if len(keys) <= int(limit) {
+		return &pb.KvListResponse{Keys: keys}
+	}
+	keys = keys[:limit]
+	return &pb.KvListResponse{Keys: keys, NextCursor: keys[len(keys)-1]}
+}
+
+func (h *demoHermit) KvDelete(key string) (*pb.KvDeleteResponse, error) {
+	demoCall()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.expire()
+	return &pb.KvDeleteResponse{Deleted: h.remove(key)}, nil
+}
+
+func (h *demoHermit) KvExists(key string) (*pb.KvExistsResponse, error) {
+	demoCall()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.expire()
+	_, ok := h.kv[key]
+	return &pb.KvExistsResponse{Exists: ok}, nil
+}
+
+func (h *demoHermit) KvTTL(key string) (*pb.KvTTLResponse, error) {
+	demoCall()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.expire()
+	if _, ok := h.kv[key]; !ok {
+		return &pb.KvTTLResponse{}, nil
+	}
+	t, ok := h.expires[key]
+	if !ok {
+		return &pb.KvTTLResponse{Found: true}, nil
+	}
+	return &pb.KvTTLResponse{Found: true, Expires: true, TtlMs: uint64(time.Until(t).Milliseconds())}, nil
+}
+
+func (h *demoHermit) Txn(guards []*pb.TxnGuard, writes []*pb.TxnWrite) (*pb.TxnResponse, error) {
+	demoCall()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.expire()
+	for i, g := range guards {
+		v, ok := h.kv[g.Key]
+		if g.Version != nil && h.vers[g.Key] != *g.Version ||
+			g.Value != nil && (!ok || !bytes.Equal(v, g.Value)) {
+			return &pb.TxnResponse{FailedGuard: uint32(i)}, nil
+		}
+	}
+	resp := &pb.TxnResponse{Ok: true}
+	for _, w := range writes {
+		var ver uint64
+		if w.Delete {
+			h.remove(w.Key)
+		} else {
+			ver = h.put(w.Key, w.Value, time.Duration(w.TtlMs)*time.Millisecond)
+		}
+		resp.Versions = append(resp.Versions, ver)
+	}
+	return resp, nil
+}
+
+// SqlInsert queues the row like hermit's write-behind buffer; the next
+// query or stats call flushes it.
+func (h *demoHermit) SqlInsert(key, value string) (*pb.SqlInsertResponse, error) {
+	demoCall()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.pending = append(h.pending, &pb.SqlRow{
+		Id:          uuid.NewString(),
+		Key:         key,
+		Value:       value,
+		CreatedAtMs: uint64(time.Now().UnixMilli()),
+	})
+	return &pb.SqlInsertResponse{Queued: true}, nil
+}
+
+// SqlQuery filters, orders and pages like hermit, whose filters match
+// substrings.
+func (h *demoHermit) SqlQuery(req *pb.SqlQueryRequest) (*pb.SqlQueryResponse, error) {
+	demoCall()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	pending := uint64(len(h.pending))
+	h.flush()
+	var rows []*pb.SqlRow
+	for _, r := range h.rows {
+		if strings.Contains(r.Key, req.KeyFilter) && strings.Contains(r.Value, req.ValueFilter) {
+			rows = append(rows, r)
+		}
+	}
+	resp := &pb.SqlQueryResponse{TotalCommitted: uint64(len(h.rows)), PendingWrites: pending, Matched: uint64(len(rows))}
+	if req.CountOnly {
+		return resp, nil
+	}
+	slices.SortStableFunc(rows, func(a, b *pb.SqlRow) int {
+		switch req.OrderBy {
+		case pb.SqlQueryRequest_KEY:
+			return cmp.Compare(a.Key, b.Key)
+		case pb.SqlQueryRequest_VALUE:
+			return cmp.Compare(a.Value, b.Value)
+		case pb.SqlQueryRequest_CREATED:
+			return cmp.Compare(a.CreatedAtMs, b.CreatedAtMs)
+		}
+		return 0 // insertion order
+	})
+	if req.Desc {
+		slices.Reverse(rows)
+	}
+	rows = rows[min(int(req.Offset), len(rows)):]
+	if req.Limit > 0 && len(rows) > int(req.Limit) {
+		rows = rows[:req.Limit]
+	}
+	resp.Rows = rows
+	return resp, nil
+}
+
+func (h *demoHermit) SqlDelete(key string) (*pb.SqlDeleteResponse, error) {
+	demoCall()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.flush()
+	n := len(h.rows)
+	h.rows = slices.DeleteFunc(h.rows, func(r *pb.SqlRow) bool { return r.Key == key })
+	return &pb.SqlDeleteResponse{Rows: uint64(n - len(h.rows))}, nil
+}
+
+func (h *demoHermit) SqlUpdate(key, value string) (*pb.SqlUpdateResponse, error) {
+	demoCall()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.flush()
+	var n uint64
+	for i, r := range h.rows {
+		if r.Key == key {
+			// A new row: earlier query results still hold the old one.
+			h.rows[i] = &pb.SqlRow{Id: r.Id, Key: r.Key, Value: value, CreatedAtMs: r.CreatedAtMs}
+			n++
+		}
+	}
+	return &pb.SqlUpdateResponse{Rows: n}, nil
+}
+
+func (h *demoHermit) DbStats() (*pb.DbStatsResponse, error) {
+	demoCall()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.expire()
+	// Pretend session: keys get lz4 and everything else zstd, which
+	// squeezes harder.
+	session := &pb.DocNamespace{Name: "session", Codec: "lz4"}
+	other := &pb.DocNamespace{Codec: "zstd"}
+	for _, b := range h.buckets {
+		for k, v := range b.kv {
+			ns, ratio := other, uint64(6)
+			if strings.HasPrefix(k, "session:") {
+				ns, ratio = session, 8
+			}
+			ns.Keys++
+			ns.RawBytes += uint64(len(v))
+			ns.StoredBytes += uint64(len(v)) * ratio / 10
+		}
+	}
+	resp := &pb.DbStatsResponse{
+		DocKeyCount:        session.Keys + other.Keys,
+		DocCompressedBytes: session.StoredBytes + other.StoredBytes,
+		RelRowCount:        uint64(len(h.rows)),
+		RelPendingWrites:   uint64(len(h.pending)),
+		DocRawBytes:        session.RawBytes + other.RawBytes,
+		Namespaces:         []*pb.DocNamespace{session, other},
+	}
+	h.flush()
+	return resp, nil
+}
+
+// demoRPCs is the demo's made-up traffic: calls per second since it
+// started, and mean latency.
+var demoRPCs = []struct {
+	method string
+	perSec float64
+	meanUs float64
+}{
+	{"KvGet", 42, 180}, {"KvSet", 9, 260}, {"KvList", 1.5, 900}, {"SqlQuery", 3, 1400},
+	{"SqlInsert", 2, 310}, {"ServerInfo", 0.5, 90}, {"Watch", 0.01, 120},
+}
+
+// demoLatencyBoundsUs are hermit's latency buckets.
+var demoLatencyBoundsUs = []uint64{100, 250, 500, 1_000, 2_500, 5_000, 10_000, 25_000, 100_000, 250_000, 1_000_000, 5_000_000}
+
+// Metrics reports the demo traffic, with exponentially distributed
+// latencies, and the real size of the demo stores.
+func (h *demoHermit) Metrics() (*pb.MetricsResponse, error) {
+	demoCall()
+	up := time.Since(h.started)
+	resp := &pb.MetricsResponse{LatencyBoundsUs: demoLatencyBoundsUs, UptimeSeconds: int64(up.Seconds())}
+	for _, r := range demoRPCs {
+		calls := uint64(r.perSec * up.Seconds())
+		m := &pb.RpcMetric{Method: r.method, Calls: calls, TotalUs: uint64(float64(calls) * r.meanUs), MaxUs: uint64(r.meanUs * 14)}
+		var below uint64
+		for _, b := range demoLatencyBoundsUs {
+			n := uint64(float64(calls)*(1-math.Exp(-float64(b)/r.meanUs))) - below
+			m.Buckets = append(m.Buckets, n)
+			below += n
+		}
+		m.Buckets = append(m.Buckets, calls-below)
+		resp.Rpcs = append(resp.Rpcs, m)
+	}
+	secs := uint64(up.Seconds())
+	resp.ExpireSweeps, resp.ExpiredKeys, resp.ExpireLastSweepUs = secs, secs/90, 35
+	resp.Connections, resp.TlsHandshakes = secs/40+3, secs/40+3
+	resp.RssBytes = 14<<20 + secs*512
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.expire()
+	for _, b := range h.buckets {
+		for k, v := range b.kv {
+			resp.DocBytes += uint64(len(k) + len(v))
+		}
+		resp.DocKeys += uint64(len(b.kv))
+	}
+	resp.RelRows, resp.RelPendingWrites = uint64(len(h.rows)), uint64(len(h.pending))
+	resp.Watchers = uint64(len(h.watches))
+	return resp, nil
+}
+
+// flush commits pending rows. Callers hold mu.
+func (h *demoHermit) flush() {
+	h.rows = append(h.rows, h.pending...)
+	h.pending = nil
+}
+
+func (h *demoHermit) Close() {}
+
+// TCPPing fakes the health panel's connect probes, which would otherwise
+// dial the demo address.
+func (h *demoHermit) TCPPing(useTLS bool) (time.Duration, error) {
+	d := demoLatency() / 2
+	if useTLS {
+		d += demoLatency()
+	}
+	time.Sleep(d)
+	return d, nil
+}
+
+// TCPBench fakes the TCP protocol against gRPC, the TCP side saving
+// gRPC's HTTP/2 framing and metadata on every call.
+func (h *demoHermit) TCPBench(n int) ([]TCPBenchResult, error) {
+	results := []TCPBenchResult{
+		{Op: "SET", TCP: 52 * time.Microsecond, GRPC: 198 * time.Microsecond},
+		{Op: "GET", TCP: 41 * time.Microsecond, GRPC: 162 * time.Microsecond},
+		{Op: "STATS", TCP: 33 * time.Microsecond, GRPC: 147 * time.Microsecond},
+	}
+	for _, r := range results {
+		time.Sleep(time.Duration(n) * (r.TCP + r.GRPC))
+	}
+	return results, nil
+}
+
+// demoLogs are the lines the demo server logs, weighted towards INFO.
+var demoLogs = []LogLine{
+	{Level: "INFO", Target: "hermit_server::grpc", Message: "Ping client_send_ns=1712"},
+	{Level: "INFO", Target: "hermit_server::grpc", Message: "KvGet key=config:motd"},
+	{Level: "INFO", Target: "hermit_server::grpc", Message: "SqlQuery key_filter=event: limit=50"},
+	{Level: "INFO", Target: "hermit_server::db", Message: "flushed 3 rows"},
+	{Level: "DEBUG", Target: "hermit_server::db", Message: "zstd level=3 in=512 out=211"},
+	{Level: "DEBUG", Target: "h2::codec", Message: "send frame=Headers stream=17"},
+	{Level: "WARN", Target: "hermit_server::grpc", Message: "Login rejected username=guest"},
+	{Level: "ERROR", Target: "hermit_server::db", Message: "flush failed: disk quota exceeded, retrying"},
+}
+
+// TailLogs sends a backlog of made-up lines, then a new one every second
+// or so.
+func (h *demoHermit) TailLogs(ctx context.Context, backlog int) (LogStream, error) {
+	demoCall()
+	ch := make(chan LogLine)
+	go func() {
+		defer close(ch)
+		at := time.Now().Add(-time.Duration(backlog) * 2 * time.Second)
+		for i := 0; ; i++ {
+			l := demoLogs[rand.IntN(len(demoLogs))]
+			if i < backlog {
+				at = at.Add(2 * time.Second)
+			} else {
+				select {
+				case <-time.After(time.Duration(300+rand.IntN(1700)) * time.Millisecond):
+				case <-ctx.Done():
+					return
+				}
+				at = time.Now()
+			}
+			l.Time = at
+			select {
+			case ch <- l:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return demoLogStream(ch), nil
+}
+
+// demoWatch is what a Watch stream watches.
+type demoWatch struct {
+	bucket string
+	prefix string
+}
+
+// Watch streams the demo store's changes to keys starting with prefix.
+func (h *demoHermit) Watch(ctx context.Context, prefix string) (KvEventStream, error) {
+	demoCall()
+	ch := make(chan *pb.WatchEvent, 64)
+	h.mu.Lock()
+	h.watches[ch] = demoWatch{bucket: h.bucket, prefix: prefix}
+	h.mu.Unlock()
+	go func() {
+		<-ctx.Done()
+		h.mu.Lock()
+		delete(h.watches, ch)
+		h.mu.Unlock()
+	}()
+	return demoWatchStream{ctx: ctx, ch: ch}, nil
+}
+
+// publish counts ev in the oplog and sends it to the watchers of its key
+// in the bucket in use, dropping it for any that are full. Callers hold
+// h.mu.
+func (h *demoHermit) publish(ev *pb.WatchEvent) {
+	h.oplog++
+	for ch, w := range h.watches {
+		if w.bucket == h.bucket && strings.HasPrefix(ev.Key, w.prefix) {
+			select {
+			case ch <- ev:
+			default:
+			}
+		}
+	}
+}
+
+// Limits lists the demo's limits table.
+func (h *demoHermit) Limits() (*pb.LimitsResponse, error) {
+	demoCall()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.limitsResponse(""), nil
+}
+
+// SetLimit changes the demo's limits table the way hermit would.
+func (h *demoHermit) SetLimit(l *pb.RateLimit, remove bool) (*pb.LimitsResponse, error) {
+	demoCall()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	switch {
+	case l.Class != "write" && l.Class != "bench":
+		return h.limitsResponse(fmt.Sprintf("class %q: want write or bench", l.Class)), nil
+	case remove && l.Client == "":
+		return h.limitsResponse("the default limit can't be removed; set it to 0"), nil
+	case l.PerSecond < 0:
+		return h.limitsResponse(fmt.Sprintf("rate %v is not a number of calls a second", l.PerSecond)), nil
+	}
+	l = &pb.RateLimit{Class: l.Class, Client: l.Client, PerSecond: l.PerSecond, Burst: l.Burst}
+	if l.PerSecond == 0 {
+		l.Burst = 0
+	} else if l.Burst == 0 {
+		l.Burst = uint32(math.Ceil(l.PerSecond))
+	}
+	h.limits = slices.DeleteFunc(h.limits, func(o *pb.RateLimit) bool {
+		return o.Class == l.Class && o.Client == l.Client
+	})
+	if !remove {
+		h.limits = append(h.limits, l)
+	}
+	// Defaults first, then clients' own, as hermit lists them.
+	rank := func(l *pb.RateLimit) int {
+		r := slices.Index([]string{"write", "bench"}, l.Class)
+		if l.Client != "" {
+			r += 2
+		}
+		return r
+	}
+	slices.SortStableFunc(h.limits, func(a, b *pb.RateLimit) int {
+		return cmp.Or(cmp.Compare(rank(a), rank(b)), strings.Compare(a.Client, b.Client))
+	})
+	return h.limitsResponse(""), nil
+}
+
+// ReplicationStatus reports the demo as a primary with one follower that
+// keeps up.
+func (h *demoHermit) ReplicationStatus() (*pb.ReplicationStatusResponse, error) {
+	demoCall()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return &pb.ReplicationStatusResponse{
+		Role:     "primary",
+		OplogSeq: h.oplog,
+		Followers: []*pb.Follower{{
+			Name:    "us-east1/hermit-replica",
+			Addr:    "10.0.2.7:51532",
+			SentSeq: h.oplog,
+			Since:   timestamppb.New(h.started),
+		}},
+	}, nil
+}
+
+// demoBucket is one of the demo's buckets.
+type demoBucket struct {
+	kv      map[string][]byte
+	vers    map[string]uint64
+	expires map[string]time.Time
+	created time.Time
+}
+
+func newDemoBucket() *demoBucket {
+	return &demoBucket{kv: map[string][]byte{}, vers: map[string]uint64{}, expires: map[string]time.Time{}, created: time.Now()}
+}
+
+// Buckets lists the demo's buckets, sized as DbStats guesses.
+func (h *demoHermit) Buckets() (*pb.ListBucketsResponse, error) {
+	demoCall()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.expire()
+	resp := &pb.ListBucketsResponse{}
+	for _, name := range slices.Sorted(maps.Keys(h.buckets)) {
+		b := h.buckets[name]
+		bk := &pb.Bucket{Name: name, Keys: uint64(len(b.kv)), Created: timestamppb.New(b.created), Role: "admin"}
+		for _, v := range b.kv {
+			bk.RawBytes += uint64(len(v))
+		}
+		bk.StoredBytes = bk.RawBytes * 6 / 10
+		resp.Buckets = append(resp.Buckets, bk)
+	}
+	return resp, nil
+}
+
+// CreateBucket adds an empty bucket.
+func (h *demoHermit) CreateBucket(name string) (*pb.CreateBucketResponse, error) {
+	demoCall()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.buckets[name]; ok {
+		return &pb.CreateBucketResponse{}, nil
+	}
+	h.buckets[name] = newDemoBucket()
+	h.oplog++
+	return &pb.CreateBucketResponse{Created: true}, nil
+}
+
+// UseBucket switches the store to another bucket. Hermit would refuse
+// calls to a bucket that doesn't exist; the demo, which the console checks
+// with Buckets first, just creates it.
+func (h *demoHermit) UseBucket(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	key := cmp.Or(name, defaultBucket)
+	b, ok := h.buckets[key]
+	if !ok {
+		b = newDemoBucket()
+		h.buckets[key] = b
+	}
+	h.bucket, h.kv, h.vers, h.expires = name, b.kv, b.vers, b.expires
+}
+
+// Bucket is the bucket in use.
+func (h *demoHermit) Bucket() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.bucket
+}
+
+// demoSlowCalls are the demo's made-up slow calls: how long each took and
+// how long ago.
+var demoSlowCalls = []struct {
+	method, key string
+	took, ago   time.Duration
+}{
+	{"KvList", "", 412 * time.Millisecond, 4 * time.Minute},
+	{"KvSet", "blob:thumbnail", 187 * time.Millisecond, 31 * time.Minute},
+	{"SqlQuery", "", 133 * time.Millisecond, 52 * time.Minute},
+	{"KvGet", "session:7f3a", 104 * time.Millisecond, 2 * time.Hour},
+}
+
+// Diagnostics reports the made-up slow calls and the demo's real largest
+// values.
+func (h *demoHermit) Diagnostics(limit uint32) (*pb.DiagnosticsResponse, error) {
+	demoCall()
+	resp := &pb.DiagnosticsResponse{SlowThresholdUs: 100_000, LargeValueBytes: 1 << 20}
+	for _, c := range demoSlowCalls[:min(int(limit), len(demoSlowCalls))] {
+		sc := &pb.SlowCall{Method: c.method, TookUs: uint64(c.took.Microseconds()), At: timestamppb.New(time.Now().Add(-c.ago))}
+		if c.key != "" {
+			sc.Bucket, sc.Key = defaultBucket, c.key
+		}
+		resp.SlowCalls = append(resp.SlowCalls, sc)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.expire()
+	for name, b := range h.buckets {
+		for k, v := range b.kv {
+			n := uint64(len(v))
+			resp.LargeValues = append(resp.LargeValues, &pb.LargeValue{Bucket: name, Key: k, RawBytes: n, StoredBytes: n * 6 / 10})
+		}
+	}
+	slices.SortFunc(resp.LargeValues, func(a, b *pb.LargeValue) int {
+		return cmp.Or(cmp.Compare(b.RawBytes, a.RawBytes), strings.Compare(a.Bucket, b.Bucket), strings.Compare(a.Key, b.Key))
+	})
+	resp.LargeValues = resp.LargeValues[:min(int(limit), len(resp.LargeValues))]
+	return resp, nil
+}
+
+// limitsResponse copies the limits table. Callers hold h.mu.
+func (h *demoHermit) limitsResponse(errText string) *pb.LimitsResponse {
+	resp := &pb.LimitsResponse{Error: errText}
+	for _, l := range h.limits {
+		resp.Limits = append(resp.Limits, &pb.RateLimit{Class: l.Class, Client: l.Client, PerSecond: l.PerSecond, Burst: l.Burst})
+	}
+	return resp
+}
+
+// Publish sends data to the topic's subscribers, dropping it for any that
+// are full.
+func (h *demoHermit) Publish(topic string, data []byte) (*pb.PublishResponse, error) {
+	demoCall()
+	if topic == "" {
+		return &pb.PublishResponse{Error: "topic is empty"}, nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return &pb.PublishResponse{Delivered: h.deliver(topic, h.user, data)}, nil
+}
+
+// deliver sends a message to topic's subscribers and returns how many took
+// it. Callers hold h.mu.
+func (h *demoHermit) deliver(topic, from string, data []byte) uint64 {
+	var n uint64
+	for ch, t := range h.subs {
+		if t != topic {
+			continue
+		}
+		if n == 0 {
+			h.topicSeq[topic]++
+		}
+		msg := &pb.TopicMessage{Topic: topic, From: from, Data: data, Time: timestamppb.Now(), Seq: h.topicSeq[topic]}
+		select {
+		case ch <- msg:
+			n++
+		default:
+		}
+	}
+	return n
+}
+
+// Subscribe streams topic's messages. Other players chat in the lobby now
+// and then.
+func (h *demoHermit) Subscribe(ctx context.Context, topic string) (TopicStream, error) {
+	demoCall()
+	ch := make(chan *pb.TopicMessage, 64)
+	h.mu.Lock()
+	h.subs[ch] = topic
+	h.mu.Unlock()
+	go func() {
+		defer func() {
+			h.mu.Lock()
+			delete(h.subs, ch)
+			h.mu.Unlock()
+		}()
+		for {
+			select {
+			case <-time.After(time.Duration(4+rand.IntN(8)) * time.Second):
+			case <-ctx.Done():
+				return
+			}
+			if topic == defaultTopic {
+				line := demoChatter[rand.IntN(len(demoChatter))]
+				h.mu.Lock()
+				h.deliver(topic, line[0], []byte(line[1]))
+				h.mu.Unlock()
+			}
+		}
+	}()
+	return demoTopicStream{ctx: ctx, ch: ch}, nil
+}
+
+// demoChatter is what other players say in the lobby, as (from, text).
+var demoChatter = [][2]string{
+	{"mallory", "anyone else seeing p99 spikes on kv:get?"},
+	{"trent", "snapshot went fine, 2.1MB"},
+	{"peggy", "who keeps setting feature:dark-ui to false"},
+	{"victor", "benchmark with 64 workers is fun"},
+	{"mallory", "brb, restarting hermit"},
+	{"trent", "the expirer ate my session again"},
+}
+
+type demoTopicStream struct {
+	ctx context.Context
+	ch  <-chan *pb.TopicMessage
+}
+
+func (s demoTopicStream) Recv() (*pb.TopicMessage, error) {
+	select {
+	case msg := <-s.ch:
+		return msg, nil
+	case <-s.ctx.Done():
+		return nil, s.ctx.Err()
+	}
+}
+
+// demoSnapshot is the demo's snapshot format, gzipped JSON. It is not
+// hermit's, so neither restores into the other.
+type demoSnapshot struct {
+	KV   map[string][]byte        `json:"kv"`
+	Vers map[string]uint64        `json:"versions"`
+	TTLs map[string]time.Duration `json:"ttls,omitempty"` // time left when taken
+	Rows []*pb.SqlRow             `json:"rows"`
+}
+
+func (h *demoHermit) Snapshot(_ context.Context, w io.Writer) (int64, error) {
+	demoCall()
+	h.mu.Lock()
+	h.expire()
+	h.flush()
+	snap := demoSnapshot{KV: maps.Clone(h.kv), Vers: maps.Clone(h.vers), TTLs: map[string]time.Duration{}, Rows: slices.Clone(h.rows)}
+	for k, t := range h.expires {
+		snap.TTLs[k] = time.Until(t)
+	}
+	h.mu.Unlock()
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(snap); err != nil {
+		return 0, err
+	}
+	if err := zw.Close(); err != nil {
+		return 0, err
+	}
+	return buf.WriteTo(w)
+}
+
+// Restore replaces the demo's keys and rows, telling watchers, as hermit
+// does.
+func (h *demoHermit) Restore(_ context.Context, r io.Reader) (*pb.DbRestoreResponse, error) {
+	demoCall()
+	var snap demoSnapshot
+	zr, err := gzip.NewReader(r)
+	if err == nil {
+		err = json.NewDecoder(zr).Decode(&snap)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("not a demo snapshot: %w", err)
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.expire()
+	for k := range h.kv {
+		if _, ok := snap.KV[k]; !ok {
+			h.publish(&pb.WatchEvent{Kind: pb.WatchEvent_DELETE, Key: k})
+		}
+	}
+	clear(h.kv)
+	clear(h.vers)
+	clear(h.expires)
+	maps.Copy(h.kv, snap.KV)
+	maps.Copy(h.vers, snap.Vers)
+	for k, d := range snap.TTLs {
+		h.expires[k] = time.Now().Add(d)
+	}
+	for k, v := range h.kv {
+		h.publish(&pb.WatchEvent{Kind: pb.WatchEvent_SET, Key: k, Value: v})
+	}
+	h.rows, h.pending = snap.Rows, nil
+	return &pb.DbRestoreResponse{DocKeys: uint64(len(h.kv)), RelRows: uint64(len(h.rows))}, nil
+}
+
+type demoWatchStream struct {
+	ctx context.Context
+	ch  <-chan *pb.WatchEvent
+}
+
+func (s demoWatchStream) Recv() (*pb.WatchEvent, error) {
+	select {
+	case ev := <-s.ch:
+		return ev, nil
+	case <-s.ctx.Done():
+		return nil, s.ctx.Err()
+	}
+}
+
+type demoBenchStream <-chan *pb.BenchmarkProgress
+
+func (s demoBenchStream) Recv() (*pb.BenchmarkProgress, error) {
+	p, ok := <-s
+	if !ok {
+		return nil, io.EOF
+	}
+	return p, nil
+}
+
+type demoLogStream <-chan LogLine
+
+func (s demoLogStream) Recv() (LogLine, error) {
+	l, ok := <-s
+	if !ok {
+		return LogLine{}, context.Canceled
+	}
+	return l, nil
+}
+
+// --- Demo secrets ---
+
+// demoSecrets is an in-memory secrets service. Other players submit now
+// and then, so the panel's polling has something to show.
+type demoSecrets struct {
+	mu      sync.Mutex
+	secrets []Secret
+	lens    map[string]string // lens that exposed each value
+}
+
+var demoPlayers = []string{"moth", "quill", "ash", "juniper", "vex"}
+
+var demoConfessions = []string{
+	"I never read the terms", "i never read the terms", "I fake laugh at meetings",
+	"I reuse my passwords", "racecar", "I like pineapple on pizza",
+	"I haven't backed up since 2019", "I still use tabs", "6e6f7468696e67",
+	"I talk to my plants", "I TALK TO MY PLANTS",
+}
+
+// NewDemoSecretsClient returns a SecretsClient backed by memory, seeded
+// with a few secrets and exposures.
+func NewDemoSecretsClient() SecretsClient {
+	s := &demoSecrets{lens: map[string]string{}}
+	now := time.Now()
+	for i, v := range demoConfessions[:6] {
+		s.submit(v, demoPlayers[i%len(demoPlayers)], now.Add(-time.Duration(60-i*7)*time.Minute))
+	}
+	return s
+}
+
+// submit admits value, exposing any secret it matches case-insensitively,
+// like the casefold lens. Callers hold mu (or own s exclusively).
+func (s *demoSecrets) submit(value, by string, at time.Time) *SubmitResult {
+	for i := range s.secrets {
+		sec := &s.secrets[i]
+		if sec.Value == value || strings.EqualFold(sec.Value, value) {
+			sec.Count++
+			sec.LastAdmitAt = at
+			lens := "identity"
+			if sec.Value != value {
+				lens = "casefold"
+			}
+			if s.lens[sec.Value] == "" {
+				s.lens[sec.Value] = lens
+			}
+			cp := *sec
+			return &SubmitResult{Secret: &cp, Lens: lens, Message: "no longer a secret"}
+		}
+	}
+	sec := Secret{ID: uuid.NewString(), Value: value, SubmittedBy: by, Count: 1, CreatedAt: at, LastAdmitAt: at}
+	s.secrets = append(s.secrets, sec)
+	return &SubmitResult{Secret: &sec, WasNew: true, Message: "it's a secret, for now"}
+}
+
+// others lets another player submit something, one poll in three.
+func (s *demoSecrets) others() {
+	if rand.IntN(3) != 0 {
+		return
+	}
+	v := demoConfessions[rand.IntN(len(demoConfessions))]
+	s.submit(v, demoPlayers[rand.IntN(len(demoPlayers))], time.Now())
+}
+
+func (s *demoSecrets) List() ([]Secret, error) {
+	demoCall()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.others()
+	return append([]Secret(nil), s.secrets...), nil
+}
+
+func (s *demoSecrets) Submit(value, submittedBy string) (*SubmitResult, error) {
+	demoCall()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.submit(value, submittedBy, time.Now()), nil
+}
+
+func (s *demoSecrets) Stats() (SecretsStats, error) {
+	demoCall()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := SecretsStats{Total: len(s.secrets), Lenses: 2}
+	for i := range s.secrets {
+		if s.secrets[i].IsSecret() {
+			st.Secrets++
+		} else {
+			st.NotSecrets++
+		}
+	}
+	return st, nil
+}
+
+func (s *demoSecrets) Exposed() (ExposedPage, error) {
+	demoCall()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var page ExposedPage
+	for _, sec := range s.secrets {
+		if !sec.IsSecret() {
+			page.Entries = append(page.Entries, ExposedEntry{Value: sec.Value, Count: sec.Count, Lens: s.lens[sec.Value], LastAdmitAt: sec.LastAdmitAt})
+		}
+	}
+	page.Total, page.Page, page.Pages = len(page.Entries), 1, 1
+	return page, nil
+}
+
+func (s *demoSecrets) Leaderboard() ([]LeaderboardEntry, error) {
+	demoCall()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	byPlayer := map[string]*LeaderboardEntry{}
+	for _, sec := range s.secrets {
+		e := byPlayer[sec.SubmittedBy]
+		if e == nil {
+			e = &LeaderboardEntry{SubmittedBy: sec.SubmittedBy}
+			byPlayer[sec.SubmittedBy] = e
+		}
+		if sec.IsSecret() {
+			e.Secrets++
+			e.Score += 3
+		} else {
+			e.Exposed++
+			e.Score++
+		}
+	}
+	var board []LeaderboardEntry
+	for _, e := range byPlayer {
+		board = append(board, *e)
+	}
+	sort.Slice(board, func(i, j int) bool {
+		if board[i].Score != board[j].Score {
+			return board[i].Score > board[j].Score
+		}
+		return board[i].SubmittedBy < board[j].SubmittedBy
+	})
+	return board, nil
+}
+
+// --- Demo portal ---
+
+// demoPortal signs in any token as an admin and serves a few pending
+// giveaway claims.
+type demoPortal struct {
+	mu     sync.Mutex
+	claims []PortalClaim
+}
+
+// NewDemoPortalClient returns a PortalClient backed by memory.
+func NewDemoPortalClient() PortalClient {
+	p := &demoPortal{}
+	now := time.Now()
+	for i, item := range []string{"bookshelf", "desk lamp", "road bike", "cast iron pan"} {
+		p.claims = append(p.claims, PortalClaim{
+			ID:           uuid.NewString(),
+			ItemID:       item,
+			ClaimerName:  demoPlayers[i],
+			ClaimerEmail: demoPlayers[i] + "@example.com",
+			DeliveryFee:  float64(5 + 5*i),
+			Status:       ClaimPending,
+			CreatedAt:    now.Add(-time.Duration(90-i*20) * time.Minute),
+		})
+	}
+	return p
+}
+
+func (p *demoPortal) MagicLogin(token string) (PortalUser, error) {
+	demoCall()
+	if magicToken(token) == "" {
+		return PortalUser{}, fmt.Errorf("portal login: invalid or expired token")
+	}
+	return PortalUser{ID: "demo", Email: "operator@example.com", Username: "operator", Name: "Operator", IsAdmin: true}, nil
+}
+
+func (p *demoPortal) PendingClaims() ([]PortalClaim, error) {
+	demoCall()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]PortalClaim(nil), p.claims...), nil
+}
+
+func (p *demoPortal) SetClaimStatus(id string, status ClaimStatus) error {
+	demoCall()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, c := range p.claims {
+		if c.ID == id {
+			p.claims = append(p.claims[:i], p.claims[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("portal claim %s: not found", id)
+}
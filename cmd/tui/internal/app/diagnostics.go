@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+
+	pb "github.com/jredh-dev/nexus/cmd/tui/proto"
+)
+
+// Diagnoser is a HermitClient that can list hermit's slowest recent calls
+// and largest values (the Diagnostics RPC; admins only).
+type Diagnoser interface {
+	Diagnostics(limit uint32) (*pb.DiagnosticsResponse, error)
+}
+
+// diagListed is how many slow calls and large values the diag command
+// lists; the DB stats panel shows just the worst of each.
+const diagListed = 10
+
+// diagKey names a key as bucket/key, or just key in the default bucket.
+func diagKey(bucket, key string) string {
+	if bucket == "" || bucket == defaultBucket {
+		return key
+	}
+	return bucket + "/" + key
+}
+
+// fmtSlowCall shows a slow call as "KvSet orders/big 120ms at 14:03:22".
+func fmtSlowCall(c *pb.SlowCall) string {
+	what := c.Method
+	if c.Key != "" {
+		what += " " + diagKey(c.Bucket, c.Key)
+	}
+	took := (time.Duration(c.TookUs) * time.Microsecond).Round(time.Millisecond)
+	return fmt.Sprintf("%s %s at %s", what, took, c.At.AsTime().Local().Format("15:04:05"))
+}
+
+// fmtLargeValue shows a value as "orders/big 4.0MB (1.2MB stored)".
+func fmtLargeValue(v *pb.LargeValue) string {
+	return fmt.Sprintf("%s %s (%s stored)", diagKey(v.Bucket, v.Key), fmtBytes(v.RawBytes), fmtBytes(v.StoredBytes))
+}
+
+// fmtSlowThreshold says which calls hermit keeps as slow.
+func fmtSlowThreshold(us uint64) string {
+	if us == 0 {
+		return "off"
+	}
+	return "over " + (time.Duration(us) * time.Microsecond).String()
+}
+
+// dbDiagnostics fetches the DB stats panel's diagnostics, or nil from a
+// hermit without them or a caller who isn't an admin.
+func dbDiagnostics(h HermitClient) *pb.DiagnosticsResponse {
+	d, ok := h.(Diagnoser)
+	if !ok {
+		return nil
+	}
+	resp, err := d.Diagnostics(1)
+	if err != nil {
+		return nil
+	}
+	return resp
+}
+
+// diagCommand runs the diag console command.
+func (m Model) diagCommand(raw string) tea.Cmd {
+	h := m.hermit
+	return func() tea.Msg {
+		if h == nil {
+			return dbCmdResultMsg{cmd: raw, err: fmt.Errorf("not connected")}
+		}
+		d, ok := h.(Diagnoser)
+		if !ok {
+			return dbCmdResultMsg{cmd: raw, err: ErrUnsupported}
+		}
+		resp, err := d.Diagnostics(diagListed)
+		if err != nil {
+			return dbCmdResultMsg{cmd: raw, err: err}
+		}
+		slow := make([]string, len(resp.SlowCalls))
+		for i, c := range resp.SlowCalls {
+			slow[i] = fmtSlowCall(c)
+		}
+		large := make([]string, len(resp.LargeValues))
+		for i, v := range resp.LargeValues {
+			large[i] = fmtLargeValue(v)
+		}
+		out := fmt.Sprintf("slow (%s): %s; largest: %s",
+			fmtSlowThreshold(resp.SlowThresholdUs), orNone(strings.Join(slow, ", ")), orNone(strings.Join(large, ", ")))
+		return dbCmdResultMsg{cmd: raw, output: out, data: resp}
+	}
+}
+
+// orNone is s, or "none" if it is empty.
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
@@ -0,0 +1,125 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ExecResult is the outcome of one command run by Exec.
+type ExecResult struct {
+	Command string `json:"command"`
+	Output  string `json:"output"`         // what the console would print
+	Data    any    `json:"data,omitempty"` // the raw response
+	Error   string `json:"error,omitempty"`
+}
+
+// ExecCommands lists what Exec accepts beyond the DB console's commands.
+const ExecCommands = "secrets:list  secrets:stats  secrets:exposed  secrets:submit <value>"
+
+// Exec runs one command without the UI, for scripts: any DB console
+// command (see executeDBCommand), or one of ExecCommands against the
+// secrets service. A failed command is an error, with the result still
+// describing it.
+func Exec(h HermitClient, s SecretsClient, user, line string) (ExecResult, error) {
+	line = strings.TrimSpace(line)
+	res := ExecResult{Command: line}
+	fail := func(err error) (ExecResult, error) {
+		res.Error = err.Error()
+		return res, err
+	}
+	if line == "" {
+		return fail(fmt.Errorf("no command"))
+	}
+
+	verb, arg, _ := strings.Cut(line, " ")
+	verb = strings.ToLower(verb)
+	arg = strings.TrimSpace(arg)
+	if strings.HasPrefix(verb, "secrets:") {
+		if s == nil {
+			return fail(fmt.Errorf("secrets client not configured"))
+		}
+		data, out, err := execSecrets(s, user, verb, arg)
+		if err != nil {
+			return fail(err)
+		}
+		res.Output, res.Data = out, data
+		return res, nil
+	}
+
+	m := New("", "", h, s)
+	msg, ok := m.executeDBCommand(line)().(dbCmdResultMsg)
+	if !ok {
+		return fail(fmt.Errorf("unknown command %q", verb))
+	}
+	if msg.err != nil {
+		return fail(msg.err)
+	}
+	res.Output, res.Data = msg.output, msg.data
+	if msg.sql != nil {
+		// The console shows rows in a table; a script wants them inline.
+		var b strings.Builder
+		b.WriteString(msg.output)
+		for _, r := range msg.sql.Rows {
+			created := time.UnixMilli(int64(r.CreatedAtMs)).UTC().Format(time.RFC3339)
+			fmt.Fprintf(&b, "\n%s\t%s\t%s\t%s", r.Id, r.Key, r.Value, created)
+		}
+		res.Output = b.String()
+	}
+	return res, nil
+}
+
+func execSecrets(s SecretsClient, user, verb, arg string) (data any, out string, err error) {
+	switch verb {
+	case "secrets:list":
+		list, err := s.List()
+		if err != nil {
+			return nil, "", err
+		}
+		lines := make([]string, len(list))
+		for i, sec := range list {
+			state := "secret"
+			if !sec.IsSecret() {
+				state = "exposed"
+			}
+			lines[i] = fmt.Sprintf("%s\t%d\t%s\t%s", state, sec.Count, sec.SubmittedBy, sec.Value)
+		}
+		return list, strings.Join(lines, "\n"), nil
+
+	case "secrets:stats":
+		st, err := s.Stats()
+		if err != nil {
+			return nil, "", err
+		}
+		return st, fmt.Sprintf("total=%d secrets=%d exposed=%d lenses=%d", st.Total, st.Secrets, st.NotSecrets, st.Lenses), nil
+
+	case "secrets:exposed":
+		page, err := s.Exposed()
+		if err != nil {
+			return nil, "", err
+		}
+		lines := make([]string, len(page.Entries))
+		for i, e := range page.Entries {
+			lines[i] = fmt.Sprintf("%d\t%s\t%s", e.Count, e.Lens, e.Value)
+		}
+		return page, strings.Join(lines, "\n"), nil
+
+	case "secrets:submit":
+		if arg == "" {
+			return nil, "", fmt.Errorf("usage: secrets:submit <value>")
+		}
+		r, err := s.Submit(arg, user)
+		if err != nil {
+			return nil, "", err
+		}
+		out := fmt.Sprintf("SECRET  %q admitted (count=%d)", r.Secret.Value, r.Secret.Count)
+		if !r.WasNew {
+			out = fmt.Sprintf("EXPOSED  %q admitted again (count=%d)", r.Secret.Value, r.Secret.Count)
+		}
+		return r, out, nil
+	}
+	return nil, "", fmt.Errorf("unknown command %q (have: %s)", verb, ExecCommands)
+}
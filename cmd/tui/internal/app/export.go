@@ -0,0 +1,268 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+
+	pb "github.com/jredh-dev/nexus/cmd/tui/proto"
+)
+
+// WithExportDir sets where ctrl+s writes panel exports. The default is the
+// working directory; empty disables exports.
+func (m Model) WithExportDir(dir string) Model {
+	m.exportDir = dir
+	return m
+}
+
+// panelExport is the file ctrl+s writes. Only the current panel's field is
+// set.
+type panelExport struct {
+	Panel      string    `json:"panel"`
+	ExportedAt time.Time `json:"exported_at"`
+	Addr       string    `json:"hermit_addr"`
+
+	ServerInfo *pb.ServerInfoResponse `json:"server_info,omitempty"`
+	Benchmark  *benchExport           `json:"benchmark,omitempty"`
+	DB         *dbExport              `json:"db,omitempty"`
+	Secrets    *secretsExport         `json:"secrets,omitempty"`
+	KV         *kvExport              `json:"kv,omitempty"`
+	SQL        *sqlExport             `json:"sql,omitempty"`
+	Health     []healthExport         `json:"health,omitempty"`
+	Metrics    *pb.MetricsResponse    `json:"metrics,omitempty"`
+	Logs       *logsExport            `json:"logs,omitempty"`
+	Topics     *topicsExport          `json:"topics,omitempty"`
+}
+
+// topicsExport is what the Topics panel has seen said in its topic.
+type topicsExport struct {
+	Topic    string             `json:"topic"`
+	Messages []topicExportEntry `json:"messages"`
+}
+
+type topicExportEntry struct {
+	Time time.Time `json:"time"`
+	From string    `json:"from,omitempty"`
+	Text string    `json:"text"`
+	Note bool      `json:"note,omitempty"` // from the panel, not a client
+}
+
+// logsExport is the Logs panel as filtered on screen.
+type logsExport struct {
+	Source   string    `json:"source"`
+	MinLevel string    `json:"min_level"`
+	Search   string    `json:"search,omitempty"`
+	Lines    []LogLine `json:"lines"`
+}
+
+type benchExport struct {
+	Iterations  int                   `json:"iterations"`
+	Payload     int                   `json:"payload_bytes"`
+	Concurrency int                   `json:"concurrency"`
+	Result      *pb.BenchmarkProgress `json:"result,omitempty"`
+}
+
+type dbExport struct {
+	Stats   *pb.DbStatsResponse `json:"stats,omitempty"`
+	History []dbExportEntry     `json:"history"`
+}
+
+type dbExportEntry struct {
+	Time   string `json:"time"`
+	Cmd    string `json:"cmd"`
+	Output string `json:"output"`
+	Error  bool   `json:"error,omitempty"`
+}
+
+type sqlExport struct {
+	Query     string       `json:"query"`
+	SortedBy  string       `json:"sorted_by"`
+	Desc      bool         `json:"desc,omitempty"`
+	Committed uint64       `json:"committed"`
+	Pending   uint64       `json:"pending"`
+	Rows      []*pb.SqlRow `json:"rows"`
+}
+
+type kvExport struct {
+	Keys     []string `json:"keys"` // the page on screen
+	Prefix   string   `json:"prefix,omitempty"`
+	Selected string   `json:"selected,omitempty"`
+	Value    string   `json:"value,omitempty"`
+}
+
+// healthExport is one watchdog sample; RTTs are in milliseconds and a
+// failed probe has an error instead.
+type healthExport struct {
+	At     time.Time              `json:"at"`
+	Uptime int64                  `json:"uptime_seconds,omitempty"`
+	Probes map[string]probeExport `json:"probes"`
+}
+
+type probeExport struct {
+	RTTMs float64 `json:"rtt_ms,omitempty"`
+	Error string  `json:"error,omitempty"`
+}
+
+type secretsExport struct {
+	Stats     SecretsStats     `json:"stats"`
+	Secrets   []Secret         `json:"secrets"`
+	Exposures []exposureExport `json:"exposures"`
+}
+
+type exposureExport struct {
+	Value string    `json:"value"`
+	Lens  string    `json:"lens,omitempty"`
+	Seen  time.Time `json:"seen"`
+}
+
+// panelName names the current panel in export file names.
+func (m Model) panelName() string {
+	switch m.state {
+	case stateBenchmark:
+		return "benchmark"
+	case stateDB:
+		return "db"
+	case stateSecrets:
+		return "secrets"
+	case stateKV:
+		return "kv"
+	case stateSQL:
+		return "sql"
+	case stateHealth:
+		return "health"
+	case stateLogs:
+		return "logs"
+	case stateMetrics:
+		return "metrics"
+	case stateTopics:
+		return "topics"
+	default:
+		return "server"
+	}
+}
+
+// snapshotPanel captures the current panel for export.
+func (m Model) snapshotPanel(now time.Time) panelExport {
+	e := panelExport{Panel: m.panelName(), ExportedAt: now, Addr: m.addr}
+	switch m.state {
+	case stateBenchmark:
+		e.Benchmark = &benchExport{
+			Iterations:  m.benchCfg.iterations,
+			Payload:     m.benchCfg.payload,
+			Concurrency: m.benchCfg.concurrency,
+			Result:      m.grpcBench,
+		}
+	case stateDB:
+		d := &dbExport{Stats: m.dbStats, History: []dbExportEntry{}}
+		for _, h := range m.dbHistory {
+			d.History = append(d.History, dbExportEntry{Time: h.ts, Cmd: h.cmd, Output: h.output, Error: h.isErr})
+		}
+		e.DB = d
+	case stateSecrets:
+		s := &secretsExport{Stats: m.secretsStats, Secrets: m.secretsList, Exposures: []exposureExport{}}
+		if s.Secrets == nil {
+			s.Secrets = []Secret{}
+		}
+		for _, x := range m.exposures {
+			s.Exposures = append(s.Exposures, exposureExport{Value: x.value, Lens: x.lens, Seen: x.seen})
+		}
+		e.Secrets = s
+	case stateKV:
+		k := &kvExport{Keys: m.kvList, Prefix: m.kvPrefix}
+		if k.Keys == nil {
+			k.Keys = []string{}
+		}
+		if p := m.kvPreview; p.found {
+			k.Selected, k.Value = p.key, string(p.value)
+		}
+		e.KV = k
+	case stateSQL:
+		e.SQL = &sqlExport{
+			Query:     m.sql.query,
+			SortedBy:  sqlSortNames[m.sql.sortBy],
+			Desc:      m.sql.desc,
+			Committed: m.sql.committed,
+			Pending:   m.sql.pending,
+			Rows:      m.sql.sortedRows(),
+		}
+	case stateHealth:
+		e.Health = []healthExport{}
+		for _, s := range m.health {
+			h := healthExport{At: s.at, Uptime: s.uptime, Probes: map[string]probeExport{}}
+			for p, r := range s.probes {
+				if r.err != nil {
+					h.Probes[healthProbeNames[p]] = probeExport{Error: r.err.Error()}
+				} else {
+					h.Probes[healthProbeNames[p]] = probeExport{RTTMs: float64(r.rtt.Microseconds()) / 1000}
+				}
+			}
+			e.Health = append(e.Health, h)
+		}
+	case stateMetrics:
+		e.Metrics = m.metrics.cur
+	case stateTopics:
+		t := &topicsExport{Topic: m.topics.topic, Messages: []topicExportEntry{}}
+		for _, l := range m.topics.lines {
+			t.Messages = append(t.Messages, topicExportEntry{Time: l.at, From: l.from, Text: l.text, Note: l.note})
+		}
+		e.Topics = t
+	case stateLogs:
+		_, name := m.logsSource()
+		l := &logsExport{Source: name, MinLevel: logLevels[m.logs.minLevel], Search: m.logs.search, Lines: m.shownLogs()}
+		if l.Lines == nil {
+			l.Lines = []LogLine{}
+		}
+		e.Logs = l
+	default:
+		e.ServerInfo = m.serverInfo
+	}
+	return e
+}
+
+// doExport writes the current panel to a timestamped JSON file.
+func (m Model) doExport() tea.Cmd {
+	now := time.Now()
+	snap := m.snapshotPanel(now)
+	dir := m.exportDir
+	state := m.state
+	return func() tea.Msg {
+		if dir == "" {
+			return exportMsg{state: state, err: fmt.Errorf("export: disabled in this session")}
+		}
+		data, err := json.MarshalIndent(snap, "", "  ")
+		if err != nil {
+			return exportMsg{state: state, err: fmt.Errorf("export: %w", err)}
+		}
+		name := fmt.Sprintf("nexus-tui-%s-%s.json", snap.Panel, now.Format("20060102-150405"))
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+			return exportMsg{state: state, err: fmt.Errorf("export: %w", err)}
+		}
+		return exportMsg{state: state, path: path}
+	}
+}
+
+// handleExport reports the export in the log of the panel it came from.
+func (m Model) handleExport(msg exportMsg) (tea.Model, tea.Cmd) {
+	ts := time.Now().Format("15:04:05")
+	text := "exported to " + msg.path
+	if msg.err != nil {
+		text = msg.err.Error()
+	}
+	switch msg.state {
+	case stateDB:
+		m.dbHistory = append(m.dbHistory, dbHistoryEntry{ts: ts, cmd: "export", output: text, isErr: msg.err != nil})
+	case stateSecrets:
+		m.secretsLog = append(m.secretsLog, secretsLogEntry{ts: ts, text: text, isErr: msg.err != nil})
+	default:
+		m.viewHistory = append(m.viewHistory, fmt.Sprintf("[%s] %s", ts, text))
+	}
+	return m.notify(text, msg.err != nil)
+}
@@ -0,0 +1,362 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package app
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// The watchdog samples every healthInterval while its panel is open and
+// keeps the last maxHealthSamples.
+const (
+	healthInterval   = 2 * time.Second
+	maxHealthSamples = 120
+	healthTimeout    = 3 * time.Second
+)
+
+// A probe's RTT is a spike when it exceeds spikeFactor times the median of
+// the window and is at least spikeFloor, so sub-millisecond jitter on a
+// local server doesn't alert.
+const (
+	spikeFactor = 3
+	spikeFloor  = 5 * time.Millisecond
+)
+
+// healthProbe is one of the checks the watchdog runs each sample.
+type healthProbe int
+
+const (
+	probeServerInfo healthProbe = iota
+	probeTCP
+	probeTLS
+	probeDbStats
+	numHealthProbes
+)
+
+var healthProbeNames = [numHealthProbes]string{"ServerInfo", "TCP", "TLS", "DbStats"}
+
+// probeResult is one probe's outcome in a sample.
+type probeResult struct {
+	rtt time.Duration
+	err error
+}
+
+// healthSample is one round of probes.
+type healthSample struct {
+	at     time.Time
+	uptime int64 // from ServerInfo; 0 if it failed
+	probes [numHealthProbes]probeResult
+}
+
+// healthEvent is an alert in the watchdog log.
+type healthEvent struct {
+	ts    string
+	text  string
+	isErr bool
+}
+
+// healthSampleMsg delivers a sample. gen ties it to one visit of the panel.
+type healthSampleMsg struct {
+	gen    int
+	sample healthSample
+}
+
+// healthPollMsg triggers the next sample.
+type healthPollMsg struct {
+	gen int
+}
+
+// tcpPinger is implemented by hermit clients that run the connect probes
+// themselves: the demo client fakes them, the gRPC client checks TLS as it
+// was told to. Others are dialed for real against the system CA pool.
+type tcpPinger interface {
+	TCPPing(useTLS bool) (time.Duration, error)
+}
+
+// tcpPing times a TCP connect to addr, and with useTLS the TLS handshake
+// on top of it, checked as cfg says (nil is the system CA pool).
+func tcpPing(addr string, useTLS bool, cfg *tls.Config) (time.Duration, error) {
+	start := time.Now()
+	d := &net.Dialer{Timeout: healthTimeout}
+	if !useTLS {
+		conn, err := d.Dial("tcp", addr)
+		if err != nil {
+			return 0, err
+		}
+		conn.Close()
+		return time.Since(start), nil
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return 0, err
+	}
+	if cfg == nil {
+		cfg = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	cfg = cfg.Clone()
+	cfg.ServerName = host
+	conn, err := tls.DialWithDialer(d, "tcp", addr, cfg)
+	if err != nil {
+		return 0, err
+	}
+	conn.Close()
+	return time.Since(start), nil
+}
+
+func openHealth(m Model) (Model, tea.Cmd) {
+	m.state = stateHealth
+	m.healthGen++
+	return m, m.doHealthSample()
+}
+
+// doHealthSample runs every probe once. Failures are recorded, not treated
+// as a lost connection: watching them is the point of the panel.
+func (m Model) doHealthSample() tea.Cmd {
+	gen := m.healthGen
+	addr := m.addr
+	h := m.hermit
+	return func() tea.Msg {
+		s := healthSample{at: time.Now()}
+		timed := func(p healthProbe, call func() error) {
+			start := time.Now()
+			err := call()
+			s.probes[p] = probeResult{rtt: time.Since(start), err: err}
+		}
+		timed(probeServerInfo, func() error {
+			if h == nil {
+				return fmt.Errorf("not connected")
+			}
+			resp, err := h.ServerInfo()
+			if err == nil {
+				s.uptime = resp.UptimeSeconds
+			}
+			return err
+		})
+		ping := func(useTLS bool) (time.Duration, error) { return tcpPing(addr, useTLS, nil) }
+		if p, ok := h.(tcpPinger); ok {
+			ping = p.TCPPing
+		}
+		rtt, err := ping(false)
+		s.probes[probeTCP] = probeResult{rtt: rtt, err: err}
+		rtt, err = ping(true)
+		s.probes[probeTLS] = probeResult{rtt: rtt, err: err}
+		timed(probeDbStats, func() error {
+			if h == nil {
+				return fmt.Errorf("not connected")
+			}
+			_, err := h.DbStats()
+			return err
+		})
+		return healthSampleMsg{gen: gen, sample: s}
+	}
+}
+
+func (m Model) pollHealth() tea.Cmd {
+	gen := m.healthGen
+	return tea.Tick(healthInterval, func(time.Time) tea.Msg {
+		return healthPollMsg{gen: gen}
+	})
+}
+
+func (m Model) handleHealthPoll(msg healthPollMsg) (tea.Model, tea.Cmd) {
+	if m.state != stateHealth || msg.gen != m.healthGen {
+		return m, nil
+	}
+	return m, m.doHealthSample()
+}
+
+func (m Model) handleHealthSample(msg healthSampleMsg) (tea.Model, tea.Cmd) {
+	if msg.gen != m.healthGen {
+		return m, nil
+	}
+	s := msg.sample
+	ts := s.at.Format("15:04:05")
+	for p := range numHealthProbes {
+		r := s.probes[p]
+		prev, hadPrev := m.lastProbe(p)
+		switch {
+		case r.err != nil && (!hadPrev || prev.err == nil):
+			m.logHealth(ts, fmt.Sprintf("%s failing: %v", healthProbeNames[p], r.err), true)
+		case r.err == nil && hadPrev && prev.err != nil:
+			m.logHealth(ts, fmt.Sprintf("%s recovered (%s)", healthProbeNames[p], fmtNs(r.rtt.Nanoseconds())), false)
+		case r.err == nil && m.isSpike(p, r.rtt):
+			m.logHealth(ts, fmt.Sprintf("%s spike: %s", healthProbeNames[p], fmtNs(r.rtt.Nanoseconds())), true)
+		}
+	}
+	if last, ok := m.lastUptime(); ok && s.uptime > 0 && s.uptime < last {
+		m.logHealth(ts, fmt.Sprintf("hermit restarted (uptime %ds, was %ds)", s.uptime, last), true)
+	}
+	m.health = append(m.health, s)
+	if len(m.health) > maxHealthSamples {
+		m.health = m.health[len(m.health)-maxHealthSamples:]
+	}
+	if m.state != stateHealth {
+		return m, nil
+	}
+	return m, m.pollHealth()
+}
+
+func (m *Model) logHealth(ts, text string, isErr bool) {
+	m.healthLog = append(m.healthLog, healthEvent{ts: ts, text: text, isErr: isErr})
+	if len(m.healthLog) > maxHistory {
+		m.healthLog = m.healthLog[len(m.healthLog)-maxHistory:]
+	}
+}
+
+func (m Model) lastProbe(p healthProbe) (probeResult, bool) {
+	if len(m.health) == 0 {
+		return probeResult{}, false
+	}
+	return m.health[len(m.health)-1].probes[p], true
+}
+
+// lastUptime is the most recent uptime ServerInfo reported.
+func (m Model) lastUptime() (int64, bool) {
+	for i := len(m.health) - 1; i >= 0; i-- {
+		if u := m.health[i].uptime; u > 0 {
+			return u, true
+		}
+	}
+	return 0, false
+}
+
+// medianRTT is the median RTT of p's successful samples in the window.
+func (m Model) medianRTT(p healthProbe) (time.Duration, bool) {
+	var rtts []time.Duration
+	for _, s := range m.health {
+		if r := s.probes[p]; r.err == nil {
+			rtts = append(rtts, r.rtt)
+		}
+	}
+	if len(rtts) == 0 {
+		return 0, false
+	}
+	slices.Sort(rtts)
+	return rtts[len(rtts)/2], true
+}
+
+func (m Model) isSpike(p healthProbe, rtt time.Duration) bool {
+	med, ok := m.medianRTT(p)
+	return ok && rtt >= spikeFloor && rtt > spikeFactor*med
+}
+
+func (m Model) handleHealthKey(k tea.Key) (tea.Model, tea.Cmd) {
+	switch {
+	case m.pressed(k, m.keys.back, m.keys.quit):
+		m.state = stateDashboard
+		m.healthGen++ // stop polling
+	case m.pressed(k, m.keys.refresh):
+		m.healthGen++ // the new sample restarts polling
+		return m, m.doHealthSample()
+	}
+	return m, nil
+}
+
+// sparkBlocks draw a sparkline from lowest to highest.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws the last width samples of p, scaled to the largest RTT
+// shown. Failures are an ✗ and spikes are drawn in the error style.
+func (m Model) sparkline(p healthProbe, width int) string {
+	samples := m.health
+	if len(samples) > width {
+		samples = samples[len(samples)-width:]
+	}
+	med, _ := m.medianRTT(p)
+	var peak time.Duration
+	for _, s := range samples {
+		if r := s.probes[p]; r.err == nil {
+			peak = max(peak, r.rtt)
+		}
+	}
+	var b strings.Builder
+	for _, s := range samples {
+		r := s.probes[p]
+		if r.err != nil {
+			b.WriteString(m.st.err.Render("✗"))
+			continue
+		}
+		i := 0
+		if peak > 0 {
+			i = int(int64(len(sparkBlocks)-1) * int64(r.rtt) / int64(peak))
+		}
+		block := string(sparkBlocks[i])
+		if r.rtt >= spikeFloor && r.rtt > spikeFactor*med {
+			b.WriteString(m.st.err.Render(block))
+		} else {
+			b.WriteString(m.st.value.Render(block))
+		}
+	}
+	return b.String()
+}
+
+func (m Model) renderHealthPanel(innerW, _ int) string {
+	var b strings.Builder
+	b.WriteString(m.st.title.Render(m.tr("Health")))
+	b.WriteString(m.st.dim.Render(m.trf("  %s  every %s  %d samples", m.addr, healthInterval, len(m.health))))
+	b.WriteString("\n\n")
+	if len(m.health) == 0 {
+		b.WriteString(m.st.dim.Render(m.tr("probing...")))
+		return b.String()
+	}
+
+	last := m.health[len(m.health)-1]
+	const labelW, lastW, upW = 11, 10, 7
+	graphW := max(10, innerW-4-labelW-lastW-upW-3)
+	for p := range numHealthProbes {
+		r := last.probes[p]
+		cur := m.st.value.Render(fmt.Sprintf("%-*s", lastW, fmtNs(r.rtt.Nanoseconds())))
+		if r.err != nil {
+			cur = m.st.err.Render(fmt.Sprintf("%-*s", lastW, m.tr("down")))
+		}
+		ok := 0
+		for _, s := range m.health {
+			if s.probes[p].err == nil {
+				ok++
+			}
+		}
+		up := fmt.Sprintf("%5.1f%%", 100*float64(ok)/float64(len(m.health)))
+		fmt.Fprintf(&b, "%-*s %s %s %s\n", labelW, healthProbeNames[p], cur, m.sparkline(p, graphW), m.st.dim.Render(up))
+	}
+
+	b.WriteString("\n")
+	if u, ok := m.lastUptime(); ok {
+		b.WriteString(m.trf("uptime %s", m.st.value.Render((time.Duration(u) * time.Second).String())))
+	} else {
+		b.WriteString(m.trf("uptime %s", m.st.err.Render(m.tr("unknown"))))
+	}
+	return b.String()
+}
+
+func (m Model) renderHealthLogPanel(innerW, maxLines int) string {
+	var b strings.Builder
+	b.WriteString(m.st.title.Render(m.tr("Alerts")))
+	b.WriteString("\n\n")
+	rows := max(1, maxLines-4)
+	log := m.healthLog
+	if len(log) > rows {
+		log = log[len(log)-rows:]
+	}
+	if len(log) == 0 {
+		b.WriteString(m.st.dim.Render(m.tr("No alerts.")))
+		b.WriteString("\n")
+	}
+	for _, e := range log {
+		text := truncate(e.text, innerW-14)
+		if e.isErr {
+			text = m.st.err.Render(text)
+		}
+		b.WriteString(m.st.dim.Render("["+e.ts+"] ") + text + "\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(m.st.dim.Render(m.tr("[r] probe now  [esc] back")))
+	return b.String()
+}
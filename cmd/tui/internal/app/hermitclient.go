@@ -6,29 +6,51 @@ package app
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
+	"io"
+	"strconv"
+	"sync"
 	"time"
 
 	pb "github.com/jredh-dev/nexus/cmd/tui/proto"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
-const secretMetadataKey = "x-hermit-secret"
+const (
+	secretMetadataKey  = "x-hermit-secret"
+	sessionMetadataKey = "x-hermit-session"
+	bucketMetadataKey  = "x-hermit-bucket"
+
+	// Rate-limited calls come back with these.
+	quotaClassMetadataKey     = "x-hermit-quota-class"
+	quotaLimitMetadataKey     = "x-hermit-quota-limit"
+	quotaRateMetadataKey      = "x-hermit-quota-rate"
+	quotaRemainingMetadataKey = "x-hermit-quota-remaining"
+)
 
 // HermitClient is the interface for the hermit gRPC server.
 // Tests inject a mock; production uses grpcHermitClient.
 type HermitClient interface {
-	Login(username, token string) error
+	Login(username, token string) (role string, err error) // role is read, write or admin
 	ServerInfo() (*pb.ServerInfoResponse, error)
 	Benchmark(iterations, payloadBytes uint32) (*pb.BenchmarkResponse, error)
-	KvSet(key string, value []byte) (*pb.KvSetResponse, error)
+	KvSet(key string, value []byte, ttl time.Duration) (*pb.KvSetResponse, error) // ttl 0 never expires
 	KvGet(key string) (*pb.KvGetResponse, error)
-	KvList() (*pb.KvListResponse, error)
+	KvList(prefix, cursor string, limit uint32) (*pb.KvListResponse, error) // limit 0 is the server's default
+	KvDelete(key string) (*pb.KvDeleteResponse, error)
+	KvExists(key string) (*pb.KvExistsResponse, error)
+	KvTTL(key string) (*pb.KvTTLResponse, error)
+	Txn(guards []*pb.TxnGuard, writes []*pb.TxnWrite) (*pb.TxnResponse, error)
 	SqlInsert(key, value string) (*pb.SqlInsertResponse, error)
-	SqlQuery(keyFilter string, limit uint32) (*pb.SqlQueryResponse, error)
+	SqlQuery(req *pb.SqlQueryRequest) (*pb.SqlQueryResponse, error)
+	SqlDelete(key string) (*pb.SqlDeleteResponse, error)
+	SqlUpdate(key, value string) (*pb.SqlUpdateResponse, error)
 	DbStats() (*pb.DbStatsResponse, error)
 	Close()
 }
@@ -39,54 +61,136 @@ type grpcHermitClient struct {
 	conn   *grpc.ClientConn
 	client pb.HermitClient
 	secret string
+	addr   string
+	tls    *tls.Config // nil for plaintext
+	check  *certCheck  // nil when the standard verification applies
+
+	mu      sync.Mutex
+	session string           // from the last Login
+	bucket  string           // for document store calls; empty is hermit's default
+	quotas  map[string]Quota // by class, from the last limited call
 }
 
 // NewHermitClient dials addr and returns a HermitClient.
 //
-// TLS modes:
-//   - insecurePlaintext=true:  plaintext h2c (local Docker, dev mode)
-//   - insecurePlaintext=false: proper TLS with system CA pool (Cloud Run, production)
+// TLS modes (see HermitTLS):
+//   - Plaintext: plaintext h2c (local Docker, dev mode)
+//   - Pin or CA: hermit's certificate must match the pin or chain to the CA
+//   - neither:   proper TLS with system CA pool (Cloud Run, production)
 //
 // Non-blocking: errors surface on first RPC call.
-func NewHermitClient(addr, secret string, insecurePlaintext bool) (HermitClient, error) {
+func NewHermitClient(addr, secret string, t HermitTLS) (HermitClient, error) {
+	cfg, check, err := t.tlsConfig()
+	if err != nil {
+		return nil, fmt.Errorf("hermit TLS: %w", err)
+	}
 	var creds credentials.TransportCredentials
-	if insecurePlaintext {
+	if cfg == nil {
 		creds = insecure.NewCredentials()
 	} else {
-		// Production: use system CA pool. Cloud Run has valid certs from Google.
-		creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+		creds = credentials.NewTLS(cfg)
 	}
 
-	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	c := &grpcHermitClient{secret: secret, addr: addr, tls: cfg, check: check, quotas: map[string]Quota{}}
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds), grpc.WithUnaryInterceptor(c.recordQuota))
 	if err != nil {
 		return nil, fmt.Errorf("connect to %s: %w", addr, err)
 	}
-	return &grpcHermitClient{
-		conn:   conn,
-		client: pb.NewHermitClient(conn),
-		secret: secret,
-	}, nil
+	c.conn, c.client = conn, pb.NewHermitClient(conn)
+	return c, nil
 }
 
-func (c *grpcHermitClient) ctx(timeout time.Duration) (context.Context, context.CancelFunc) {
-	base := context.Background()
+// recordQuota keeps the quota hermit sends with a rate-limited call, in
+// its headers or, when it refuses the call, its trailers.
+func (c *grpcHermitClient) recordQuota(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	var header, trailer metadata.MD
+	err := invoker(ctx, method, req, reply, cc, append(opts, grpc.Header(&header), grpc.Trailer(&trailer))...)
+	for _, md := range []metadata.MD{header, trailer} {
+		if q, ok := parseQuota(md); ok {
+			c.mu.Lock()
+			c.quotas[q.Class] = q
+			c.mu.Unlock()
+			break
+		}
+	}
+	return err
+}
+
+// parseQuota reads the x-hermit-quota-* metadata.
+func parseQuota(md metadata.MD) (Quota, bool) {
+	get := func(key string) string {
+		if v := md.Get(key); len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+	q := Quota{Class: get(quotaClassMetadataKey)}
+	limit, err1 := strconv.ParseUint(get(quotaLimitMetadataKey), 10, 32)
+	rate, err2 := strconv.ParseFloat(get(quotaRateMetadataKey), 64)
+	left, err3 := strconv.ParseUint(get(quotaRemainingMetadataKey), 10, 32)
+	if q.Class == "" || err1 != nil || err2 != nil || err3 != nil {
+		return Quota{}, false
+	}
+	q.Limit, q.Rate, q.Remaining = uint32(limit), rate, uint32(left)
+	return q, true
+}
+
+// Quota is the quota hermit sent with the last limited call of class.
+func (c *grpcHermitClient) Quota(class string) (Quota, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	q, ok := c.quotas[class]
+	return q, ok
+}
+
+// TLSWarning is why hermit's certificate failed the check, in warn-only
+// mode; empty if it passed.
+func (c *grpcHermitClient) TLSWarning() string {
+	return c.check.lastWarning()
+}
+
+// TCPPing times a connect to hermit for the health panel, with the TLS
+// handshake checked the same way as the client's own.
+func (c *grpcHermitClient) TCPPing(useTLS bool) (time.Duration, error) {
+	return tcpPing(c.addr, useTLS, c.tls)
+}
+
+// outgoing adds the shared secret, session and bucket to ctx's metadata.
+func (c *grpcHermitClient) outgoing(ctx context.Context) context.Context {
 	if c.secret != "" {
-		base = metadata.AppendToOutgoingContext(base, secretMetadataKey, c.secret)
+		ctx = metadata.AppendToOutgoingContext(ctx, secretMetadataKey, c.secret)
+	}
+	c.mu.Lock()
+	session, bucket := c.session, c.bucket
+	c.mu.Unlock()
+	if session != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, sessionMetadataKey, session)
 	}
-	return context.WithTimeout(base, timeout)
+	if bucket != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, bucketMetadataKey, bucket)
+	}
+	return ctx
 }
 
-func (c *grpcHermitClient) Login(username, token string) error {
+func (c *grpcHermitClient) ctx(timeout time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(c.outgoing(context.Background()), timeout)
+}
+
+// Login starts a session that later calls run in.
+func (c *grpcHermitClient) Login(username, token string) (string, error) {
 	ctx, cancel := c.ctx(5 * time.Second)
 	defer cancel()
 	resp, err := c.client.Login(ctx, &pb.LoginRequest{Username: username, Token: token})
 	if err != nil {
-		return err
+		return "", err
 	}
 	if !resp.Success {
-		return fmt.Errorf("login failed: %s", resp.Error)
+		return "", fmt.Errorf("login failed: %s", resp.Error)
 	}
-	return nil
+	c.mu.Lock()
+	c.session = resp.SessionId
+	c.mu.Unlock()
+	return resp.Role, nil
 }
 
 func (c *grpcHermitClient) ServerInfo() (*pb.ServerInfoResponse, error) {
@@ -101,10 +205,33 @@ func (c *grpcHermitClient) Benchmark(iterations, payloadBytes uint32) (*pb.Bench
 	return c.client.Benchmark(ctx, &pb.BenchmarkRequest{Iterations: iterations, PayloadBytes: payloadBytes})
 }
 
-func (c *grpcHermitClient) KvSet(key string, value []byte) (*pb.KvSetResponse, error) {
+// BenchmarkStream runs a benchmark as one call. Like TailLogs the stream
+// has no deadline; cancel ctx to stop it.
+func (c *grpcHermitClient) BenchmarkStream(ctx context.Context, iterations, payloadBytes uint32) (BenchProgressStream, error) {
+	ctx = c.outgoing(ctx)
+	stream, err := c.client.BenchmarkStream(ctx, &pb.BenchmarkRequest{Iterations: iterations, PayloadBytes: payloadBytes})
+	if err != nil {
+		return nil, grpcLogErr(err)
+	}
+	return grpcBenchStream{stream}, nil
+}
+
+type grpcBenchStream struct {
+	stream grpc.ServerStreamingClient[pb.BenchmarkProgress]
+}
+
+func (s grpcBenchStream) Recv() (*pb.BenchmarkProgress, error) {
+	p, err := s.stream.Recv()
+	if err != nil {
+		return nil, grpcLogErr(err)
+	}
+	return p, nil
+}
+
+func (c *grpcHermitClient) KvSet(key string, value []byte, ttl time.Duration) (*pb.KvSetResponse, error) {
 	ctx, cancel := c.ctx(5 * time.Second)
 	defer cancel()
-	return c.client.KvSet(ctx, &pb.KvSetRequest{Key: key, Value: value})
+	return c.client.KvSet(ctx, &pb.KvSetRequest{Key: key, Value: value, TtlMs: uint64(ttl.Milliseconds())})
 }
 
 func (c *grpcHermitClient) KvGet(key string) (*pb.KvGetResponse, error) {
@@ -113,10 +240,34 @@ func (c *grpcHermitClient) KvGet(key string) (*pb.KvGetResponse, error) {
 	return c.client.KvGet(ctx, &pb.KvGetRequest{Key: key})
 }
 
-func (c *grpcHermitClient) KvList() (*pb.KvListResponse, error) {
+func (c *grpcHermitClient) KvList(prefix, cursor string, limit uint32) (*pb.KvListResponse, error) {
+	ctx, cancel := c.ctx(5 * time.Second)
+	defer cancel()
+	return c.client.KvList(ctx, &pb.KvListRequest{Prefix: prefix, Cursor: cursor, Limit: limit})
+}
+
+func (c *grpcHermitClient) KvDelete(key string) (*pb.KvDeleteResponse, error) {
+	ctx, cancel := c.ctx(5 * time.Second)
+	defer cancel()
+	return c.client.KvDelete(ctx, &pb.KvDeleteRequest{Key: key})
+}
+
+func (c *grpcHermitClient) KvExists(key string) (*pb.KvExistsResponse, error) {
+	ctx, cancel := c.ctx(5 * time.Second)
+	defer cancel()
+	return c.client.KvExists(ctx, &pb.KvExistsRequest{Key: key})
+}
+
+func (c *grpcHermitClient) KvTTL(key string) (*pb.KvTTLResponse, error) {
+	ctx, cancel := c.ctx(5 * time.Second)
+	defer cancel()
+	return c.client.KvTTL(ctx, &pb.KvTTLRequest{Key: key})
+}
+
+func (c *grpcHermitClient) Txn(guards []*pb.TxnGuard, writes []*pb.TxnWrite) (*pb.TxnResponse, error) {
 	ctx, cancel := c.ctx(5 * time.Second)
 	defer cancel()
-	return c.client.KvList(ctx, &pb.KvListRequest{})
+	return c.client.Txn(ctx, &pb.TxnRequest{Guards: guards, Writes: writes})
 }
 
 func (c *grpcHermitClient) SqlInsert(key, value string) (*pb.SqlInsertResponse, error) {
@@ -125,10 +276,22 @@ func (c *grpcHermitClient) SqlInsert(key, value string) (*pb.SqlInsertResponse,
 	return c.client.SqlInsert(ctx, &pb.SqlInsertRequest{Key: key, Value: value})
 }
 
-func (c *grpcHermitClient) SqlQuery(keyFilter string, limit uint32) (*pb.SqlQueryResponse, error) {
+func (c *grpcHermitClient) SqlQuery(req *pb.SqlQueryRequest) (*pb.SqlQueryResponse, error) {
 	ctx, cancel := c.ctx(5 * time.Second)
 	defer cancel()
-	return c.client.SqlQuery(ctx, &pb.SqlQueryRequest{KeyFilter: keyFilter, Limit: limit})
+	return c.client.SqlQuery(ctx, req)
+}
+
+func (c *grpcHermitClient) SqlDelete(key string) (*pb.SqlDeleteResponse, error) {
+	ctx, cancel := c.ctx(5 * time.Second)
+	defer cancel()
+	return c.client.SqlDelete(ctx, &pb.SqlDeleteRequest{Key: key})
+}
+
+func (c *grpcHermitClient) SqlUpdate(key, value string) (*pb.SqlUpdateResponse, error) {
+	ctx, cancel := c.ctx(5 * time.Second)
+	defer cancel()
+	return c.client.SqlUpdate(ctx, &pb.SqlUpdateRequest{Key: key, Value: value})
 }
 
 func (c *grpcHermitClient) DbStats() (*pb.DbStatsResponse, error) {
@@ -137,6 +300,260 @@ func (c *grpcHermitClient) DbStats() (*pb.DbStatsResponse, error) {
 	return c.client.DbStats(ctx, &pb.DbStatsRequest{})
 }
 
+// TailLogs follows hermit's log. The stream has no deadline; cancel ctx to
+// end it.
+func (c *grpcHermitClient) TailLogs(ctx context.Context, backlog int) (LogStream, error) {
+	ctx = c.outgoing(ctx)
+	stream, err := c.client.TailLogs(ctx, &pb.TailLogsRequest{Backlog: uint32(backlog)})
+	if err != nil {
+		return nil, grpcLogErr(err)
+	}
+	return grpcLogStream{stream}, nil
+}
+
+type grpcLogStream struct {
+	stream grpc.ServerStreamingClient[pb.LogLine]
+}
+
+func (s grpcLogStream) Recv() (LogLine, error) {
+	l, err := s.stream.Recv()
+	if err != nil {
+		return LogLine{}, grpcLogErr(err)
+	}
+	return LogLine{Time: l.Time.AsTime(), Level: l.Level, Target: l.Target, Message: l.Message}, nil
+}
+
+// Watch follows changes to keys starting with prefix. Like TailLogs the
+// stream has no deadline.
+func (c *grpcHermitClient) Watch(ctx context.Context, prefix string) (KvEventStream, error) {
+	ctx = c.outgoing(ctx)
+	stream, err := c.client.Watch(ctx, &pb.WatchRequest{Prefix: prefix})
+	if err != nil {
+		return nil, grpcLogErr(err)
+	}
+	return grpcWatchStream{stream}, nil
+}
+
+type grpcWatchStream struct {
+	stream grpc.ServerStreamingClient[pb.WatchEvent]
+}
+
+func (s grpcWatchStream) Recv() (*pb.WatchEvent, error) {
+	ev, err := s.stream.Recv()
+	if err != nil {
+		return nil, grpcLogErr(err)
+	}
+	return ev, nil
+}
+
+// Snapshot copies hermit's stores to w, returning the bytes written.
+func (c *grpcHermitClient) Snapshot(ctx context.Context, w io.Writer) (int64, error) {
+	ctx = c.outgoing(ctx)
+	stream, err := c.client.DbSnapshot(ctx, &pb.DbSnapshotRequest{})
+	if err != nil {
+		return 0, grpcLogErr(err)
+	}
+	var n int64
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return n, nil
+		}
+		if err != nil {
+			return n, grpcLogErr(err)
+		}
+		k, err := w.Write(chunk.Data)
+		n += int64(k)
+		if err != nil {
+			return n, err
+		}
+	}
+}
+
+// Restore replaces hermit's stores with the snapshot read from r.
+func (c *grpcHermitClient) Restore(ctx context.Context, r io.Reader) (*pb.DbRestoreResponse, error) {
+	ctx = c.outgoing(ctx)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel() // abandons the stream if r fails
+	stream, err := c.client.DbRestore(ctx)
+	if err != nil {
+		return nil, grpcLogErr(err)
+	}
+	buf := make([]byte, snapshotChunk)
+	for {
+		n, rerr := r.Read(buf)
+		if n > 0 {
+			if err := stream.Send(&pb.SnapshotChunk{Data: buf[:n]}); err != nil {
+				if errors.Is(err, io.EOF) {
+					// The server ended the call; CloseAndRecv has why.
+					_, err = stream.CloseAndRecv()
+				}
+				return nil, grpcLogErr(err)
+			}
+		}
+		if errors.Is(rerr, io.EOF) {
+			break
+		}
+		if rerr != nil {
+			return nil, rerr
+		}
+	}
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, grpcLogErr(err)
+	}
+	return resp, nil
+}
+
+// Metrics reports hermit's RPC counts and latencies, store sizes and
+// connection counts.
+func (c *grpcHermitClient) Metrics() (*pb.MetricsResponse, error) {
+	ctx, cancel := c.ctx(5 * time.Second)
+	defer cancel()
+	resp, err := c.client.Metrics(ctx, &pb.MetricsRequest{})
+	if err != nil {
+		return nil, grpcLogErr(err)
+	}
+	return resp, nil
+}
+
+func (c *grpcHermitClient) Publish(topic string, data []byte) (*pb.PublishResponse, error) {
+	ctx, cancel := c.ctx(5 * time.Second)
+	defer cancel()
+	resp, err := c.client.Publish(ctx, &pb.PublishRequest{Topic: topic, Data: data})
+	if err != nil {
+		return nil, grpcLogErr(err)
+	}
+	return resp, nil
+}
+
+// Subscribe follows a topic. Like TailLogs the stream has no deadline.
+func (c *grpcHermitClient) Subscribe(ctx context.Context, topic string) (TopicStream, error) {
+	ctx = c.outgoing(ctx)
+	stream, err := c.client.Subscribe(ctx, &pb.SubscribeRequest{Topic: topic})
+	if err != nil {
+		return nil, grpcLogErr(err)
+	}
+	return grpcTopicStream{stream}, nil
+}
+
+type grpcTopicStream struct {
+	stream grpc.ServerStreamingClient[pb.TopicMessage]
+}
+
+func (s grpcTopicStream) Recv() (*pb.TopicMessage, error) {
+	msg, err := s.stream.Recv()
+	if err != nil {
+		return nil, grpcLogErr(err)
+	}
+	return msg, nil
+}
+
+// Limits lists hermit's rate limits.
+func (c *grpcHermitClient) Limits() (*pb.LimitsResponse, error) {
+	ctx, cancel := c.ctx(5 * time.Second)
+	defer cancel()
+	resp, err := c.client.Limits(ctx, &pb.LimitsRequest{})
+	if err != nil {
+		return nil, grpcLogErr(err)
+	}
+	return resp, nil
+}
+
+// SetLimit changes a rate limit, or with remove puts a client back on its
+// class's default.
+func (c *grpcHermitClient) SetLimit(limit *pb.RateLimit, remove bool) (*pb.LimitsResponse, error) {
+	ctx, cancel := c.ctx(5 * time.Second)
+	defer cancel()
+	resp, err := c.client.SetLimit(ctx, &pb.SetLimitRequest{Limit: limit, Remove: remove})
+	if err != nil {
+		return nil, grpcLogErr(err)
+	}
+	return resp, nil
+}
+
+// ReplicationStatus says whether hermit is a primary or a follower.
+func (c *grpcHermitClient) ReplicationStatus() (*pb.ReplicationStatusResponse, error) {
+	ctx, cancel := c.ctx(5 * time.Second)
+	defer cancel()
+	resp, err := c.client.ReplicationStatus(ctx, &pb.ReplicationStatusRequest{})
+	if err != nil {
+		return nil, grpcLogErr(err)
+	}
+	return resp, nil
+}
+
+// Buckets lists the document store's buckets the session can read.
+func (c *grpcHermitClient) Buckets() (*pb.ListBucketsResponse, error) {
+	ctx, cancel := c.ctx(5 * time.Second)
+	defer cancel()
+	resp, err := c.client.ListBuckets(ctx, &pb.ListBucketsRequest{})
+	if err != nil {
+		return nil, grpcLogErr(err)
+	}
+	return resp, nil
+}
+
+// CreateBucket adds a bucket to the document store.
+func (c *grpcHermitClient) CreateBucket(name string) (*pb.CreateBucketResponse, error) {
+	ctx, cancel := c.ctx(5 * time.Second)
+	defer cancel()
+	resp, err := c.client.CreateBucket(ctx, &pb.CreateBucketRequest{Name: name})
+	if err != nil {
+		return nil, grpcLogErr(err)
+	}
+	return resp, nil
+}
+
+// UseBucket sends later document store calls to bucket; "" is hermit's
+// default.
+func (c *grpcHermitClient) UseBucket(bucket string) {
+	c.mu.Lock()
+	c.bucket = bucket
+	c.mu.Unlock()
+}
+
+// Bucket is the bucket document store calls go to.
+func (c *grpcHermitClient) Bucket() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.bucket
+}
+
+// Diagnostics lists hermit's slowest recent calls and largest values,
+// up to limit of each.
+func (c *grpcHermitClient) Diagnostics(limit uint32) (*pb.DiagnosticsResponse, error) {
+	ctx, cancel := c.ctx(5 * time.Second)
+	defer cancel()
+	resp, err := c.client.Diagnostics(ctx, &pb.DiagnosticsRequest{Limit: limit})
+	if err != nil {
+		return nil, grpcLogErr(err)
+	}
+	return resp, nil
+}
+
+// RestoreFromURL has hermit download the snapshot at url and restore it.
+func (c *grpcHermitClient) RestoreFromURL(url string) (*pb.DbRestoreResponse, error) {
+	ctx, cancel := c.ctx(snapshotTimeout)
+	defer cancel()
+	resp, err := c.client.RestoreFromUrl(ctx, &pb.RestoreFromUrlRequest{Url: url})
+	if err != nil {
+		return nil, grpcLogErr(err)
+	}
+	return resp, nil
+}
+
+// grpcLogErr reports a hermit without one of the optional RPCs
+// (BenchmarkStream, TailLogs, Watch, DbSnapshot, DbRestore, RestoreFromUrl,
+// Metrics, Publish, Subscribe, Limits, SetLimit, ReplicationStatus,
+// ListBuckets, CreateBucket, Diagnostics) as ErrUnsupported.
+func grpcLogErr(err error) error {
+	if status.Code(err) == codes.Unimplemented {
+		return ErrUnsupported
+	}
+	return err
+}
+
 func (c *grpcHermitClient) Close() {
 	if c.conn != nil {
 		c.conn.Close()
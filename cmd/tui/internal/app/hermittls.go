@@ -0,0 +1,138 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package app
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// HermitTLS is how a client checks the certificate hermit presents.
+//
+// With neither Pin nor CA the system CA pool is trusted, which suits a
+// hermit behind Cloud Run. A self-signed hermit is pinned instead: Pin is
+// the SHA-256 fingerprint hermit logs at startup. CA trusts a private CA in
+// place of the system pool, for certificates that rotate.
+type HermitTLS struct {
+	Plaintext bool   // no TLS at all: local Docker, dev mode
+	Pin       string // hex SHA-256 of hermit's certificate; colons and a "sha256:" prefix are fine
+	CA        []byte // PEM certificates to trust instead of the system pool
+	WarnOnly  bool   // report a failed check instead of refusing to connect (dev)
+}
+
+// CertFingerprint is the SHA-256 of a DER certificate, as lowercase hex.
+func CertFingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:])
+}
+
+// parsePin normalizes a fingerprint to lowercase hex.
+func parsePin(pin string) (string, error) {
+	p := strings.ToLower(strings.TrimSpace(pin))
+	p = strings.TrimPrefix(p, "sha256:")
+	p = strings.ReplaceAll(p, ":", "")
+	if b, err := hex.DecodeString(p); err != nil || len(b) != sha256.Size {
+		return "", fmt.Errorf("pin %q: want a hex SHA-256 fingerprint", pin)
+	}
+	return p, nil
+}
+
+// TLSWarner is a HermitClient in warn-only mode that can say why hermit's
+// certificate would have been refused.
+type TLSWarner interface {
+	TLSWarning() string // empty while every handshake has passed
+}
+
+// certCheck verifies hermit's certificate against a pin and/or a CA pool.
+type certCheck struct {
+	pin   string         // normalized; empty to skip
+	roots *x509.CertPool // nil is the system pool
+	chain bool           // verify the chain and name (always, unless only pinned)
+	warn  bool
+
+	mu      sync.Mutex
+	warning string
+}
+
+// tlsConfig builds the client's tls.Config, or nil for plaintext.
+func (t HermitTLS) tlsConfig() (*tls.Config, *certCheck, error) {
+	if t.Plaintext {
+		return nil, nil, nil
+	}
+	if t.Pin == "" && len(t.CA) == 0 && !t.WarnOnly {
+		return &tls.Config{MinVersion: tls.VersionTLS12}, nil, nil
+	}
+	cc := &certCheck{warn: t.WarnOnly, chain: t.Pin == "" || len(t.CA) > 0}
+	if t.Pin != "" {
+		pin, err := parsePin(t.Pin)
+		if err != nil {
+			return nil, nil, err
+		}
+		cc.pin = pin
+	}
+	if len(t.CA) > 0 {
+		cc.roots = x509.NewCertPool()
+		if !cc.roots.AppendCertsFromPEM(t.CA) {
+			return nil, nil, errors.New("CA: no PEM certificates found")
+		}
+	}
+	// The standard check can't be told to accept a pinned certificate
+	// with the wrong name or no chain, so it is replaced by verify.
+	return &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: true,
+		VerifyConnection:   cc.verify,
+	}, cc, nil
+}
+
+// verify runs during the handshake. In warn-only mode a failure is kept
+// for TLSWarning and the handshake goes ahead.
+func (cc *certCheck) verify(cs tls.ConnectionState) error {
+	err := cc.check(cs)
+	if err != nil && cc.warn {
+		cc.mu.Lock()
+		cc.warning = err.Error()
+		cc.mu.Unlock()
+		return nil
+	}
+	return err
+}
+
+func (cc *certCheck) check(cs tls.ConnectionState) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("hermit sent no certificate")
+	}
+	leaf := cs.PeerCertificates[0]
+	if cc.pin != "" {
+		if got := CertFingerprint(leaf.Raw); got != cc.pin {
+			return fmt.Errorf("hermit's certificate is sha256:%s, pinned sha256:%s", got, cc.pin)
+		}
+	}
+	if !cc.chain {
+		return nil
+	}
+	opts := x509.VerifyOptions{Roots: cc.roots, DNSName: cs.ServerName, Intermediates: x509.NewCertPool()}
+	for _, c := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(c)
+	}
+	if _, err := leaf.Verify(opts); err != nil {
+		return fmt.Errorf("hermit's certificate: %w", err)
+	}
+	return nil
+}
+
+func (cc *certCheck) lastWarning() string {
+	if cc == nil {
+		return ""
+	}
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	return cc.warning
+}
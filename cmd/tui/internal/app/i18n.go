@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Interface strings are written in English where they are rendered and
+// looked up in the chosen language's catalog on the way out: m.tr("Menu"),
+// or m.trf("%d keys", n) for a format. The English text is the key, so
+// English needs no catalog, and a string a catalog lacks shows in English
+// rather than not at all. Data (keys, values, server errors) is never
+// translated.
+
+// language is a catalog of translations keyed by the English text.
+type language struct {
+	code string // ISO 639-1, as given to WithLanguage
+	name string
+	msgs map[string]string
+}
+
+var languages = []language{
+	{code: "en", name: "English"},
+	{code: "es", name: "Español", msgs: catalogES},
+}
+
+// LanguageNames lists the interface languages, default first.
+func LanguageNames() []string {
+	codes := make([]string, len(languages))
+	for i, l := range languages {
+		codes[i] = l.code
+	}
+	return codes
+}
+
+// WithLanguage selects the interface language by code. Locale forms such
+// as "es_MX.UTF-8" select their language. An empty code keeps the current
+// one.
+func (m Model) WithLanguage(code string) (Model, error) {
+	if code == "" {
+		return m, nil
+	}
+	base, _, _ := strings.Cut(strings.ToLower(code), ".")
+	base, _, _ = strings.Cut(base, "_")
+	base, _, _ = strings.Cut(base, "-")
+	for i, l := range languages {
+		if l.code == base {
+			m.langIdx = i
+			return m, nil
+		}
+	}
+	return m, fmt.Errorf("unknown language %q (have: %s)", code, strings.Join(LanguageNames(), ", "))
+}
+
+// tr translates s into the interface language.
+func (m Model) tr(s string) string {
+	if t, ok := languages[m.langIdx].msgs[s]; ok {
+		return t
+	}
+	return s
+}
+
+// trf translates format and formats args with it.
+func (m Model) trf(format string, args ...any) string {
+	return fmt.Sprintf(m.tr(format), args...)
+}
@@ -0,0 +1,276 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package app
+
+// catalogES is the Spanish interface. Key names in help lines ([enter],
+// [esc], ...) stay as they are typed; panel labels that line up with each
+// other keep their padding.
+var catalogES = map[string]string{
+	// Login, errors, dashboard
+	"loading...":                    "cargando...",
+	"  Connect to: ":                "  Conectar a: ",
+	"  Username: ":                  "  Usuario: ",
+	"  [enter] login  [esc] quit":   "  [enter] entrar  [esc] salir",
+	"  Connecting to %s...":         "  Conectando a %s...",
+	"  ERROR: ":                     "  ERROR: ",
+	"  [q/esc] quit":                "  [q/esc] salir",
+	" ● reconnecting (attempt %d) ": " ● reconectando (intento %d) ",
+	" ● connected ":                 " ● conectado ",
+	" ● connected as %s (%s) ":      " ● conectado como %s (%s) ",
+	"Server Information":            "Información del servidor",
+	"No server info yet. Select 'Hermit DB' or 'Benchmark' to connect.": "Aún no hay datos del servidor. Elige 'Hermit DB' o 'Benchmark' para conectar.",
+	"Log:":          "Registro:",
+	"Version:   %s": "Versión:     %s",
+	"Region:    %s": "Región:      %s",
+	"Uptime:    %s": "Activo:      %s",
+	"TLS:       %s": "TLS:         %s",
+	"gRPC Port: %s": "Puerto gRPC: %s",
+	"Menu":          "Menú",
+	"[↑/↓ or k/j] navigate  [enter] select  [ctrl+k] commands  [ctrl+s] export  [ctrl+t] theme  [ctrl+l] layout  [?] keys  [q] quit": "[↑/↓ o k/j] navegar  [enter] elegir  [ctrl+k] comandos  [ctrl+s] exportar  [ctrl+t] tema  [ctrl+l] diseño  [?] teclas  [q] salir",
+	"TLS not verified: %s": "TLS sin verificar: %s",
+	"using bucket %s":      "usando el bucket %s",
+
+	// Menu items and secrets tabs
+	"Hermit DB":   "Hermit DB",
+	"Benchmark":   "Benchmark",
+	"Secrets":     "Secretos",
+	"KV Browser":  "Explorador KV",
+	"Health":      "Salud",
+	"Portal":      "Portal",
+	"Logs":        "Registros",
+	"Metrics":     "Métricas",
+	"Topics":      "Temas",
+	"Quit":        "Salir",
+	"Exposures":   "Expuestos",
+	"Leaderboard": "Clasificación",
+
+	// Benchmark
+	"Benchmark Results":      "Resultados del benchmark",
+	"%.0f req/s":             "%.0f pet/s",
+	"  mean: %s  tls: %s\n":  "  media: %s  tls: %s\n",
+	"  throughput: %s  %s\n": "  rendimiento: %s  %s\n",
+	"Benchmark Settings":     "Ajustes del benchmark",
+	"iterations":             "iteraciones",
+	"payload bytes":          "bytes de carga",
+	"concurrency":            "concurrencia",
+	"[esc] back":             "[esc] volver",
+	"running…  [esc] back":   "en curso…  [esc] volver",
+	"[↑/↓] field  [←/→] halve/double  [0-9] type  [enter] run  [esc] back": "[↑/↓] campo  [←/→] mitad/doble  [0-9] escribir  [enter] ejecutar  [esc] volver",
+
+	// DB console
+	"In-Memory Database — Stats":                    "Base de datos en memoria — Estadísticas",
+	"Document Store":                                "Almacén de documentos",
+	" (compressed KVP, fast reads)":                 " (KVP comprimido, lecturas rápidas)",
+	"  keys: %s   compressed: %s\n":                 "  claves: %s   comprimido: %s\n",
+	"  bucket in use: %s\n":                         "  bucket en uso: %s\n",
+	"  slowest call (%s): %s   largest value: %s\n": "  llamada más lenta (%s): %s   valor más grande: %s\n",
+	"none":                             "nada",
+	"%d keys":                          "%d claves",
+	"  %s %s  %d keys  %s → %s (%s)\n": "  %s %s  %d claves  %s → %s (%s)\n",
+	"  loading...":                     "  cargando...",
+	"Relational Store":                 "Almacén relacional",
+	" (MPSC queue, eventual reads)":    " (cola MPSC, lecturas eventuales)",
+	"  committed rows: %s   pending writes: %s\n": "  filas confirmadas: %s   escrituras pendientes: %s\n",
+
+	"  write quota: %s of %d left, refilling at %s/s\n": "  cuota de escritura: quedan %s de %d, se recarga a %s/s\n",
+	"Recent: ":   "Recientes: ",
+	"DB Console": "Consola de BD",
+	"kv:set <k> <v>  kv:get <k>  kv:del <k>  kv:list":                                                   "kv:set <c> <v>  kv:get <c>  kv:del <c>  kv:list",
+	"sql:insert <k> <v>  sql:query [k]  stats  help":                                                    "sql:insert <c> <v>  sql:query [c]  stats  help",
+	"[enter] execute  [tab] complete  [pgup/pgdn] scroll  [ctrl+f] follow  [ctrl+s] export  [esc] back": "[enter] ejecutar  [tab] completar  [pgup/pgdn] desplazar  [ctrl+f] seguir  [ctrl+s] exportar  [esc] volver",
+
+	// SQL results
+	"Query Results":                          "Resultados de la consulta",
+	"  %d of %d committed  row %d":           "  %d de %d confirmadas  fila %d",
+	"Query":                                  "Consulta",
+	"sorted by %s %s   pending writes: %s\n": "orden por %s %s   escrituras pendientes: %s\n",
+	"[↑/↓] row  [pgup/pgdn] page  [s] sort column  [r] reverse  [esc] back to console": "[↑/↓] fila  [pgup/pgdn] página  [s] columna de orden  [r] invertir  [esc] volver a la consola",
+
+	// Secrets
+	"  total:%d  secrets:%d  exposed:%d  lenses:%d": "  total:%d  secretos:%d  expuestos:%d  lentes:%d",
+	"Log: ":                "Registro: ",
+	"No secrets yet.":      "Aún no hay secretos.",
+	"secret":               "secreto",
+	"exposed":              "expuesto",
+	"by %s":                "de %s",
+	"Nothing exposed yet.": "Nada expuesto todavía.",
+	"lens unknown":         "lente desconocida",
+	"via %s":               "por %s",
+	"This server doesn't publish a leaderboard yet.": "Este servidor aún no publica una clasificación.",
+	"No entries yet.":     "Aún no hay entradas.",
+	"submitter":           "autor",
+	"score":               "puntos",
+	"Submit a Secret":     "Enviar un secreto",
+	"  (%d more lines)":   "  (%d líneas más)",
+	"lenses: ":            "lentes: ",
+	"lenses: none likely": "lentes: ninguna probable",
+	"[enter] submit  [alt+enter/ctrl+j] newline  [enter on empty] refresh  [tab] switch view  [esc] back": "[enter] enviar  [alt+enter/ctrl+j] nueva línea  [enter vacío] actualizar  [tab] cambiar vista  [esc] volver",
+	"[pgup/pgdn] scroll log  [ctrl+f] follow  [ctrl+s] export":                                            "[pgup/pgdn] desplazar registro  [ctrl+f] seguir  [ctrl+s] exportar",
+
+	// KV browser
+	"Keys":                   "Claves",
+	"  page %d":              "  página %d",
+	"  prefix %q":            "  prefijo %q",
+	"  ● live":               "  ● en vivo",
+	"  [→] more":             "  [→] más",
+	"No keys start with %q.": "Ninguna clave empieza por %q.",
+	"No keys. Write one with kv:set in the DB console.": "No hay claves. Escribe una con kv:set en la consola de BD.",
+	"Value": "Valor",
+	"Select a key and press enter to preview it.":                                   "Elige una clave y pulsa enter para verla.",
+	"Not found; it may have been deleted. [r] reloads the list.":                    "No encontrada; puede que se haya borrado. [r] recarga la lista.",
+	"[↑/↓] select  [←/→] page  [/] prefix  [enter] preview  [r] reload  [esc] back": "[↑/↓] elegir  [←/→] página  [/] prefijo  [enter] ver  [r] recargar  [esc] volver",
+	"prefix> ":                  "prefijo> ",
+	"[enter] list  [esc] clear": "[enter] listar  [esc] borrar",
+
+	// Health
+	"  %s  every %s  %d samples": "  %s  cada %s  %d muestras",
+	"probing...":                 "sondeando...",
+	"down":                       "caído",
+	"uptime %s":                  "activo %s",
+	"unknown":                    "desconocido",
+	"Alerts":                     "Alertas",
+	"No alerts.":                 "Sin alertas.",
+	"[r] probe now  [esc] back":  "[r] sondear ahora  [esc] volver",
+
+	// Metrics
+	"  %s  every %s":                  "  %s  cada %s",
+	"This hermit has no Metrics RPC.": "Este hermit no tiene la RPC Metrics.",
+	"RPC":                             "RPC",
+	"calls":                           "llamadas",
+	"per sec":                         "por seg",
+	"mean":                            "media",
+	"p99 ≤":                           "p99 ≤",
+	"max":                             "máx",
+	"…and %d more":                    "…y %d más",
+	"Server":                          "Servidor",
+	"documents":                       "documentos",
+	"rows":                            "filas",
+	"watchers":                        "observadores",
+	"expirer":                         "caducador",
+	"connections":                     "conexiones",
+	"memory":                          "memoria",
+	"uptime":                          "actividad",
+	"%d keys, %s":                     "%d claves, %s",
+	"%d committed, %d pending":        "%d confirmadas, %d pendientes",
+	"%d runs, %d keys, last %s":       "%d pasadas, %d claves, última %s",
+	"%d, %d TLS handshakes":           "%d, %d negociaciones TLS",
+	"rate limited":                    "limitadas",
+	"%d calls":                        "%d llamadas",
+	"[r] refresh  [esc] back":         "[r] refrescar  [esc] volver",
+
+	// Portal
+	"Paste a magic login token or link from the portal.": "Pega un token o enlace mágico de acceso del portal.",
+	"token> ":         "token> ",
+	"  signing in...": "  entrando...",
+	"This account is not an admin; claims are admin only.":             "Esta cuenta no es de administrador; las solicitudes son solo para administradores.",
+	"This portal was built without the giveaway, so it has no claims.": "Este portal se compiló sin los regalos, así que no tiene solicitudes.",
+	"loading claims...":           "cargando solicitudes...",
+	"No pending claims.":          "No hay solicitudes pendientes.",
+	"%d pending claims":           "%d solicitudes pendientes",
+	"Claim":                       "Solicitud",
+	"Log":                         "Registro",
+	"item":                        "objeto",
+	"name":                        "nombre",
+	"email":                       "correo",
+	"phone":                       "tel.",
+	"fee":                         "tarifa",
+	"notes":                       "notas",
+	"[enter] sign in  [esc] back": "[enter] entrar  [esc] volver",
+	"[↑/↓] select  [c] confirm  [x] cancel claim  [r] reload  [esc] back": "[↑/↓] elegir  [c] confirmar  [x] cancelar solicitud  [r] recargar  [esc] volver",
+
+	// Logs
+	"  %s  ≥%s  %d/%d lines  %s": "  %s  ≥%s  %d/%d líneas  %s",
+	"Tail":                       "Seguimiento",
+	"No log source: this hermit client can't tail logs and no --logs-url is set.":   "Sin origen de registros: este cliente de hermit no puede seguirlos y no hay --logs-url.",
+	"This server doesn't serve its logs; point --logs-url at an HTTP log endpoint.": "Este servidor no ofrece sus registros; apunta --logs-url a un endpoint HTTP de registros.",
+	"stream failed: ":           "falló el flujo: ",
+	"status %s":                 "estado %s",
+	"streaming":                 "recibiendo",
+	"ended":                     "terminado",
+	"search> ":                  "buscar> ",
+	"[enter] keep  [esc] clear": "[enter] mantener  [esc] borrar",
+	"[v] level  [/] search  [↑/↓/pgup/pgdn] scroll  [ctrl+f] follow  [r] reconnect  [esc] back": "[v] nivel  [/] buscar  [↑/↓/pgup/pgdn] desplazar  [ctrl+f] seguir  [r] reconectar  [esc] volver",
+
+	// Topics
+	"joined #%s":                        "entraste en #%s",
+	"missed %d messages":                "se perdieron %d mensajes",
+	"send failed: ":                     "falló el envío: ",
+	"sent to #%s (%d listening)":        "enviado a #%s (%d escuchando)",
+	"anonymous":                         "anónimo",
+	"Say":                               "Decir",
+	"This hermit has no topics.":        "Este hermit no tiene temas.",
+	"not subscribed; [enter] to rejoin": "sin suscripción; [enter] para volver a entrar",
+	"[enter] send  /join <topic> switch topic  [esc] back": "[enter] enviar  /join <tema> cambiar de tema  [esc] volver",
+	"[pgup/pgdn] scroll  [ctrl+f] follow  [ctrl+s] export": "[pgup/pgdn] desplazar  [ctrl+f] seguir  [ctrl+s] exportar",
+
+	// Command palette
+	"No matching actions.":                   "Ninguna acción coincide.",
+	"[↑/↓] select  [enter] run  [esc] close": "[↑/↓] elegir  [enter] ejecutar  [esc] cerrar",
+	"Dashboard":              "Panel principal",
+	"Server info and menu":   "Datos del servidor y menú",
+	"Open the DB console":    "Abrir la consola de BD",
+	"Open the Secrets panel": "Abrir el panel de secretos",
+	"Run benchmark":          "Ejecutar benchmark",
+	"Measure gRPC round trips with the current settings":                  "Medir idas y vueltas gRPC con los ajustes actuales",
+	"Read a key from the document store":                                  "Leer una clave del almacén de documentos",
+	"Write a key to the document store":                                   "Escribir una clave en el almacén de documentos",
+	"Write a key that expires after a TTL":                                "Escribir una clave que caduca tras un TTL",
+	"Save hermit's stores to a local file":                                "Guardar los almacenes de hermit en un archivo local",
+	"Replace hermit's stores with a saved snapshot":                       "Reemplazar los almacenes de hermit con una instantánea guardada",
+	"Change hermit's rate limit for writes or benchmarks":                 "Cambiar el límite de frecuencia de hermit para escrituras o benchmarks",
+	"Delete a key from the document store":                                "Borrar una clave del almacén de documentos",
+	"List document store keys":                                            "Listar las claves del almacén de documentos",
+	"Page through document store keys and preview values":                 "Recorrer las claves del almacén de documentos y ver sus valores",
+	"Watch hermit's RPC rates and latencies, store sizes and connections": "Vigilar las tasas y latencias de RPC, el tamaño de los almacenes y las conexiones de hermit",
+	"Watch hermit's RTT, uptime and failures":                             "Vigilar el RTT, la actividad y los fallos de hermit",
+	"Sign in to the portal and review giveaway claims":                    "Entrar al portal y revisar solicitudes de regalos",
+	"Tail a service's log with level filter and search":                   "Seguir el registro de un servicio con filtro de nivel y búsqueda",
+	"Chat with other clients over hermit's pub/sub topics":                "Chatear con otros clientes por los temas pub/sub de hermit",
+	"Set the value of every row with a key":                               "Cambiar el valor de todas las filas con una clave",
+	"Delete every row with a key":                                         "Borrar todas las filas con una clave",
+	"Query the relational store":                                          "Consultar el almacén relacional",
+	"Submit secret":                                                       "Enviar secreto",
+	"Type a secret to admit":                                              "Escribe un secreto que confesar",
+	"Export panel":                                                        "Exportar panel",
+	"Save the current panel to a JSON file":                               "Guardar el panel actual en un archivo JSON",
+	"Next theme":                                                          "Siguiente tema",
+	"Cycle default, light, high-contrast and solarized":                   "Alternar entre default, light, high-contrast y solarized",
+	"Toggle side-by-side":                                                 "Alternar lado a lado",
+	"Put the panels side by side on wide terminals; ctrl+arrows resize":   "Poner los paneles lado a lado en terminales anchas; ctrl+flechas redimensiona",
+	"Close the connection and exit":                                       "Cerrar la conexión y salir",
+	"Show whether hermit is a primary or a follower, and its lag":         "Mostrar si hermit es primario o seguidor, y su retraso",
+	"Send document store commands to another bucket":                      "Enviar las órdenes del almacén de documentos a otro bucket",
+	"List hermit's slowest recent calls and largest values":               "Listar las llamadas recientes más lentas de hermit y sus valores más grandes",
+	"Compare hermit's TCP protocol with gRPC for the same calls":          "Comparar el protocolo TCP de hermit con gRPC para las mismas llamadas",
+
+	// Key bindings overlay
+	"Key Bindings": "Atajos de teclado",
+	"Remap under [keys] in the config file. Any key closes this.": "Se reasignan en [keys] del archivo de configuración. Cualquier tecla cierra esto.",
+	"move up":                        "subir",
+	"move down":                      "bajar",
+	"halve / previous page":          "mitad / página anterior",
+	"double / next page":             "doble / página siguiente",
+	"page up":                        "página arriba",
+	"page down":                      "página abajo",
+	"first":                          "primero",
+	"last":                           "último",
+	"select / run":                   "elegir / ejecutar",
+	"back":                           "volver",
+	"quit (dashboard)":               "salir (panel principal)",
+	"reload list":                    "recargar lista",
+	"sort column (sql)":              "columna de orden (sql)",
+	"reverse sort (sql)":             "invertir orden (sql)",
+	"confirm claim (portal)":         "confirmar solicitud (portal)",
+	"cancel claim (portal)":          "cancelar solicitud (portal)",
+	"cycle level filter (logs)":      "cambiar filtro de nivel (registros)",
+	"search (logs), key prefix (kv)": "buscar (registros), prefijo de clave (kv)",
+	"command palette":                "paleta de comandos",
+	"export panel":                   "exportar panel",
+	"next theme":                     "siguiente tema",
+	"toggle side-by-side":            "alternar lado a lado",
+	"shrink first panel":             "encoger el primer panel",
+	"grow first panel":               "agrandar el primer panel",
+	"follow log tail":                "seguir el final del registro",
+	"key bindings":                   "atajos de teclado",
+}
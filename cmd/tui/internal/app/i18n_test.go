@@ -0,0 +1,31 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestCatalogs_CoverPalette checks every catalog translates every palette
+// title and description, which are easy to add without one. Titles that
+// are console commands, such as kv:get, are typed as is and stay English.
+func TestCatalogs_CoverPalette(t *testing.T) {
+	command := func(s string) bool { return s == strings.ToLower(s) && !strings.Contains(s, " ") }
+	for _, l := range languages {
+		if l.msgs == nil {
+			continue // English
+		}
+		for _, a := range paletteActions() {
+			for _, s := range []string{a.title, a.description} {
+				if s == a.title && command(s) {
+					continue
+				}
+				if _, ok := l.msgs[s]; !ok {
+					t.Errorf("%s catalog lacks palette string %q (action %s)", l.code, s, a.id)
+				}
+			}
+		}
+	}
+}
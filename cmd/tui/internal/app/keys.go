@@ -0,0 +1,165 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+)
+
+// keyMap holds the remappable bindings. Text entry (typing, backspace, tab
+// completion, enter to submit) is not remappable.
+type keyMap struct {
+	up, down, left, right key.Binding
+	pageUp, pageDown      key.Binding
+	home, end             key.Binding
+	sel, back, quit       key.Binding
+	refresh, sort, rev    key.Binding
+	confirm, cancelClaim  key.Binding
+	level, search         key.Binding
+
+	palette, export, theme, layout key.Binding
+	shrinkSplit, growSplit         key.Binding
+	follow, help                   key.Binding
+}
+
+func defaultKeyMap() keyMap {
+	b := func(help string, keys ...string) key.Binding {
+		return key.NewBinding(key.WithKeys(keys...), key.WithHelp(strings.Join(keys, "/"), help))
+	}
+	return keyMap{
+		up:          b("move up", "up", "k"),
+		down:        b("move down", "down", "j"),
+		left:        b("halve / previous page", "left", "h", "-"),
+		right:       b("double / next page", "right", "l", "+"),
+		pageUp:      b("page up", "pgup"),
+		pageDown:    b("page down", "pgdown"),
+		home:        b("first", "home", "g"),
+		end:         b("last", "end", "G"),
+		sel:         b("select / run", "enter"),
+		back:        b("back", "esc"),
+		quit:        b("quit (dashboard)", "q"),
+		refresh:     b("reload list", "r"),
+		sort:        b("sort column (sql)", "s"),
+		rev:         b("reverse sort (sql)", "r"),
+		confirm:     b("confirm claim (portal)", "c"),
+		cancelClaim: b("cancel claim (portal)", "x"),
+		level:       b("cycle level filter (logs)", "v"),
+		search:      b("search (logs), key prefix (kv)", "/"),
+
+		palette:     b("command palette", "ctrl+k"),
+		export:      b("export panel", "ctrl+s"),
+		theme:       b("next theme", "ctrl+t"),
+		layout:      b("toggle side-by-side", "ctrl+l"),
+		shrinkSplit: b("shrink first panel", "ctrl+up", "ctrl+left"),
+		growSplit:   b("grow first panel", "ctrl+down", "ctrl+right"),
+		follow:      b("follow log tail", "ctrl+f"),
+		help:        b("key bindings", "?"),
+	}
+}
+
+// keyAction names a binding for the config file's [keys] table.
+type keyAction struct {
+	name string
+	b    *key.Binding
+}
+
+func (km *keyMap) actions() []keyAction {
+	return []keyAction{
+		{"up", &km.up}, {"down", &km.down}, {"left", &km.left}, {"right", &km.right},
+		{"page_up", &km.pageUp}, {"page_down", &km.pageDown}, {"home", &km.home}, {"end", &km.end},
+		{"select", &km.sel}, {"back", &km.back}, {"quit", &km.quit},
+		{"refresh", &km.refresh}, {"sort", &km.sort}, {"reverse", &km.rev},
+		{"confirm", &km.confirm}, {"cancel_claim", &km.cancelClaim},
+		{"level", &km.level}, {"search", &km.search},
+		{"palette", &km.palette}, {"export", &km.export}, {"theme", &km.theme}, {"layout", &km.layout},
+		{"shrink_split", &km.shrinkSplit}, {"grow_split", &km.growSplit},
+		{"follow", &km.follow}, {"help", &km.help},
+	}
+}
+
+// KeyActions lists the action names WithKeyBindings accepts.
+func KeyActions() []string {
+	km := defaultKeyMap()
+	var names []string
+	for _, a := range km.actions() {
+		names = append(names, a.name)
+	}
+	return names
+}
+
+// WithKeyBindings replaces the keys of the named actions, e.g.
+// {"up": {"up", "w"}}. An empty list unbinds the action. Unknown action
+// names are an error.
+func (m Model) WithKeyBindings(bindings map[string][]string) (Model, error) {
+	acts := map[string]*key.Binding{}
+	for _, a := range m.keys.actions() {
+		acts[a.name] = a.b
+	}
+	names := make([]string, 0, len(bindings))
+	for name := range bindings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b, ok := acts[name]
+		if !ok {
+			return m, fmt.Errorf("unknown key action %q (have: %s)", name, strings.Join(KeyActions(), ", "))
+		}
+		keys := bindings[name]
+		if len(keys) == 0 {
+			b.Unbind()
+			continue
+		}
+		b.SetKeys(keys...)
+		b.SetHelp(strings.Join(keys, "/"), b.Help().Desc)
+		b.SetEnabled(true)
+	}
+	return m, nil
+}
+
+// typing reports whether the current panel takes text input, in which case
+// printable keys are text rather than bindings.
+func (m Model) typing() bool {
+	switch m.state {
+	case stateLogin, stateDB, stateSecrets, stateTopics:
+		return true
+	case statePortal:
+		return m.portal.user == nil
+	case stateLogs:
+		return m.logs.searching
+	case stateKV:
+		return m.kvPrefixing
+	}
+	return m.palette.open
+}
+
+// pressed reports whether k triggers any of bs.
+func (m Model) pressed(k tea.Key, bs ...key.Binding) bool {
+	if m.typing() && k.Text != "" && k.Mod&(tea.ModCtrl|tea.ModAlt) == 0 {
+		return false
+	}
+	return key.Matches(k, bs...)
+}
+
+// overlayHelp draws the active bindings over base.
+func (m Model) overlayHelp(base string) string {
+	var b strings.Builder
+	b.WriteString(m.st.title.Render(m.tr("Key Bindings")))
+	b.WriteString("\n\n")
+	for _, a := range m.keys.actions() {
+		if !a.b.Enabled() {
+			continue
+		}
+		h := a.b.Help()
+		b.WriteString(fmt.Sprintf("%-13s %s %s\n", a.name, m.st.value.Render(fmt.Sprintf("%-22s", h.Key)), m.st.dim.Render(m.tr(h.Desc))))
+	}
+	b.WriteString("\n")
+	b.WriteString(m.st.dim.Render(m.tr("Remap under [keys] in the config file. Any key closes this.")))
+	return m.overlay(base, b.String(), 70)
+}
@@ -0,0 +1,337 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package app
+
+import (
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// kvPreview is the value shown for the selected key in the KV browser.
+type kvPreview struct {
+	key     string
+	value   []byte
+	found   bool
+	loading bool
+	err     error
+}
+
+// kvPreviewMax caps how much of a value the preview renders.
+const kvPreviewMax = 4096
+
+// kvListLimit is how many keys kv:list prints.
+const kvListLimit = 200
+
+// The browser fetches one page of keys at a time from hermit. kvPages holds
+// the cursor each visited page was fetched with, so paging back re-fetches
+// rather than caching keys that may have changed.
+
+// openKV switches to the KV browser and loads the first page of keys. The
+// prefix is kept from the last visit.
+func openKV(m Model) (Model, tea.Cmd) {
+	m.state = stateKV
+	m.kvPreview = kvPreview{}
+	return m.kvFirstPage()
+}
+
+// kvFirstPage goes back to the first page of keys under the prefix,
+// watching the prefix if it isn't already.
+func (m Model) kvFirstPage() (Model, tea.Cmd) {
+	m.kvIdx = 0
+	m.kvPages = []string{""}
+	m.kvNext = ""
+	if m.kvWatch.live && m.kvWatch.prefix == m.kvPrefix {
+		return m, m.doKvBrowse()
+	}
+	m, watch := m.startKvWatch()
+	return m, tea.Batch(m.doKvBrowse(), watch)
+}
+
+// kvCursor is the cursor the current page was fetched with.
+func (m Model) kvCursor() string {
+	if len(m.kvPages) == 0 {
+		return ""
+	}
+	return m.kvPages[len(m.kvPages)-1]
+}
+
+func (m Model) doKvBrowse() tea.Cmd {
+	prefix, cursor, limit := m.kvPrefix, m.kvCursor(), uint32(m.kvPageSize())
+	return func() tea.Msg {
+		if m.hermit == nil {
+			return kvBrowseMsg{prefix: prefix, cursor: cursor, err: fmt.Errorf("not connected")}
+		}
+		resp, err := m.hermit.KvList(prefix, cursor, limit)
+		if err != nil {
+			return kvBrowseMsg{prefix: prefix, cursor: cursor, err: err}
+		}
+		return kvBrowseMsg{prefix: prefix, cursor: cursor, keys: resp.Keys, next: resp.NextCursor}
+	}
+}
+
+func (m Model) doKvPreview(key string) tea.Cmd {
+	return func() tea.Msg {
+		if m.hermit == nil {
+			return kvPreviewMsg{key: key, err: fmt.Errorf("not connected")}
+		}
+		resp, err := m.hermit.KvGet(key)
+		if err != nil {
+			return kvPreviewMsg{key: key, err: err}
+		}
+		return kvPreviewMsg{key: key, value: resp.Value, found: resp.Found}
+	}
+}
+
+func (m Model) handleKvBrowse(msg kvBrowseMsg) (tea.Model, tea.Cmd) {
+	if m, cmd, lost := m.connLost(msg.err); lost {
+		return m, cmd
+	}
+	if msg.prefix != m.kvPrefix || msg.cursor != m.kvCursor() {
+		return m, nil // the page or prefix changed since
+	}
+	if msg.err != nil {
+		m.kvErr = msg.err
+		return m, nil
+	}
+	m.kvErr = nil
+	m.kvList = msg.keys
+	m.kvNext = msg.next
+	m.kvIdx = clampInt(m.kvIdx, 0, max(0, len(m.kvList)-1))
+	m.rememberKeys(m.kvList...)
+	return m, nil
+}
+
+func (m Model) handleKvPreview(msg kvPreviewMsg) (tea.Model, tea.Cmd) {
+	if m, cmd, lost := m.connLost(msg.err); lost {
+		return m, cmd
+	}
+	if msg.key != m.kvPreview.key {
+		return m, nil // the selection moved on
+	}
+	m.kvPreview = kvPreview{key: msg.key, value: msg.value, found: msg.found, err: msg.err}
+	return m, nil
+}
+
+// kvPageSize is how many keys one page of the browser lists.
+func (m Model) kvPageSize() int {
+	return max(1, m.layout().topH-3)
+}
+
+// kvSelected returns the selected key, if any.
+func (m Model) kvSelected() (string, bool) {
+	if m.kvIdx < 0 || m.kvIdx >= len(m.kvList) {
+		return "", false
+	}
+	return m.kvList[m.kvIdx], true
+}
+
+// previewSelected fetches the selected key's value.
+func (m Model) previewSelected() (Model, tea.Cmd) {
+	key, ok := m.kvSelected()
+	if !ok {
+		return m, nil
+	}
+	m.kvPreview = kvPreview{key: key, loading: true}
+	return m, m.doKvPreview(key)
+}
+
+func (m Model) handleKVKey(k tea.Key) (tea.Model, tea.Cmd) {
+	if m.kvPrefixing {
+		return m.handleKVPrefixKey(k)
+	}
+	last := max(0, len(m.kvList)-1)
+	switch {
+	case m.pressed(k, m.keys.back) && m.kvPrefix != "":
+		m.kvPrefix = ""
+		return m.kvFirstPage()
+	case m.pressed(k, m.keys.back, m.keys.quit):
+		m.stopKvWatch()
+		m.state = stateDashboard
+		return m, nil
+	case m.pressed(k, m.keys.up):
+		m.kvIdx = max(0, m.kvIdx-1)
+	case m.pressed(k, m.keys.down):
+		m.kvIdx = min(last, m.kvIdx+1)
+	case m.pressed(k, m.keys.pageUp, m.keys.left):
+		if len(m.kvPages) > 1 {
+			m.kvPages = slices.Clip(m.kvPages[:len(m.kvPages)-1])
+			m.kvIdx = 0
+			return m, m.doKvBrowse()
+		}
+		m.kvIdx = 0
+	case m.pressed(k, m.keys.pageDown, m.keys.right):
+		if m.kvNext != "" {
+			m.kvPages = append(m.kvPages, m.kvNext)
+			m.kvIdx = 0
+			return m, m.doKvBrowse()
+		}
+		m.kvIdx = last
+	case m.pressed(k, m.keys.home):
+		if len(m.kvPages) > 1 {
+			return m.kvFirstPage()
+		}
+		m.kvIdx = 0
+	case m.pressed(k, m.keys.end):
+		m.kvIdx = last
+	case m.pressed(k, m.keys.sel), k.Code == tea.KeySpace:
+		return m.previewSelected()
+	case m.pressed(k, m.keys.refresh):
+		return m, m.doKvBrowse()
+	case m.pressed(k, m.keys.search):
+		m.kvPrefixing = true
+	}
+	return m, nil
+}
+
+// handleKVPrefixKey edits the key prefix. Enter lists the keys under it
+// and esc drops it.
+func (m Model) handleKVPrefixKey(k tea.Key) (tea.Model, tea.Cmd) {
+	switch k.Code {
+	case tea.KeyEnter:
+		m.kvPrefixing = false
+		return m.kvFirstPage()
+	case tea.KeyEscape:
+		m.kvPrefixing = false
+		m.kvPrefix = ""
+		return m.kvFirstPage()
+	case tea.KeyBackspace:
+		if m.kvPrefix != "" {
+			_, size := utf8.DecodeLastRuneInString(m.kvPrefix)
+			m.kvPrefix = m.kvPrefix[:len(m.kvPrefix)-size]
+		}
+	default:
+		if k.Text != "" {
+			m.kvPrefix += k.Text
+		}
+	}
+	return m, nil
+}
+
+func (m Model) renderKVListPanel(innerW, maxLines int) string {
+	var b strings.Builder
+	page := m.kvPageSize()
+	cur := m.kvIdx / page // more than one only if the terminal shrank
+	b.WriteString(m.st.title.Render(m.tr("Keys")))
+	b.WriteString(m.st.dim.Render(m.trf("  page %d", max(1, len(m.kvPages)))))
+	if m.kvPrefix != "" && !m.kvPrefixing {
+		b.WriteString(m.st.dim.Render(m.trf("  prefix %q", m.kvPrefix)))
+	}
+	if m.kvNext != "" {
+		b.WriteString(m.st.dim.Render(m.tr("  [→] more")))
+	}
+	if m.kvWatch.live {
+		b.WriteString(m.st.dim.Render(m.tr("  ● live")))
+	}
+	b.WriteString("\n\n")
+
+	switch {
+	case m.kvErr != nil:
+		b.WriteString(m.st.err.Render(m.kvErr.Error()))
+	case len(m.kvList) == 0 && m.kvPrefix != "":
+		b.WriteString(m.st.dim.Render(m.trf("No keys start with %q.", m.kvPrefix)))
+	case len(m.kvList) == 0:
+		b.WriteString(m.st.dim.Render(m.tr("No keys. Write one with kv:set in the DB console.")))
+	default:
+		end := min(len(m.kvList), (cur+1)*page)
+		for i := cur * page; i < end; i++ {
+			line := truncate(m.kvList[i], innerW-8)
+			if i == m.kvIdx {
+				b.WriteString(m.st.selected.Render(" ▸ " + line + " "))
+			} else {
+				b.WriteString("   " + line)
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+func (m Model) renderKVPreviewPanel(innerW, maxLines int) string {
+	var b strings.Builder
+	p := m.kvPreview
+	b.WriteString(m.st.title.Render(m.tr("Value")))
+	if p.key != "" {
+		b.WriteString(m.st.dim.Render("  " + truncate(p.key, innerW-14)))
+	}
+	b.WriteString("\n\n")
+
+	rows := max(1, maxLines-4)
+	switch {
+	case p.key == "":
+		b.WriteString(m.st.dim.Render(m.tr("Select a key and press enter to preview it.")))
+	case p.loading:
+		b.WriteString(m.st.dim.Render(m.tr("loading...")))
+	case p.err != nil:
+		b.WriteString(m.st.err.Render(p.err.Error()))
+	case !p.found:
+		b.WriteString(m.st.dim.Render(m.tr("Not found; it may have been deleted. [r] reloads the list.")))
+	default:
+		b.WriteString(m.st.dim.Render(fmt.Sprintf("%s\n", fmtBytes(uint64(len(p.value))))))
+		for i, line := range previewLines(p.value, innerW-4) {
+			if i >= rows-1 {
+				b.WriteString(m.st.dim.Render("…"))
+				break
+			}
+			b.WriteString(m.st.value.Render(line))
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("\n\n")
+	if m.kvPrefixing {
+		b.WriteString(m.st.prompt.Render(m.tr("prefix> ")))
+		b.WriteString(truncate(m.kvPrefix, max(1, innerW-12)))
+		b.WriteString("█\n\n")
+		b.WriteString(m.st.dim.Render(m.tr("[enter] list  [esc] clear")))
+		return b.String()
+	}
+	b.WriteString(m.st.dim.Render(m.tr("[↑/↓] select  [←/→] page  [/] prefix  [enter] preview  [r] reload  [esc] back")))
+	return b.String()
+}
+
+// previewLines renders a value for the preview: wrapped text if it is
+// printable UTF-8, a hex dump otherwise.
+func previewLines(v []byte, width int) []string {
+	if len(v) > kvPreviewMax {
+		cut := kvPreviewMax
+		for cut > 0 && !utf8.RuneStart(v[cut]) {
+			cut--
+		}
+		v = v[:cut]
+	}
+	if !isPrintable(v) {
+		return strings.Split(strings.TrimRight(hex.Dump(v), "\n"), "\n")
+	}
+	width = max(8, width)
+	var lines []string
+	for _, l := range strings.Split(string(v), "\n") {
+		for len(l) > width {
+			cut := width
+			for cut > 0 && !utf8.RuneStart(l[cut]) {
+				cut--
+			}
+			lines = append(lines, l[:cut])
+			l = l[cut:]
+		}
+		lines = append(lines, l)
+	}
+	return lines
+}
+
+func isPrintable(v []byte) bool {
+	if !utf8.Valid(v) {
+		return false
+	}
+	for _, r := range string(v) {
+		if !unicode.IsPrint(r) && r != '\n' && r != '\t' {
+			return false
+		}
+	}
+	return true
+}
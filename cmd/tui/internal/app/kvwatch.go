@@ -0,0 +1,194 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package app
+
+import (
+	"context"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/jredh-dev/nexus/cmd/tui/proto"
+)
+
+// KvEventStream yields key changes until its context is cancelled.
+type KvEventStream interface {
+	Recv() (*pb.WatchEvent, error)
+}
+
+// KvWatcher is a HermitClient that can stream key changes (the Watch RPC).
+// The KV browser watches its prefix so the page and preview follow writes
+// made elsewhere; without it they change only on [r].
+type KvWatcher interface {
+	Watch(ctx context.Context, prefix string) (KvEventStream, error)
+}
+
+// kvWatchBatch is the most events delivered in one message.
+const kvWatchBatch = 100
+
+// kvWatchState is the KV browser's Watch stream.
+type kvWatchState struct {
+	prefix string // the prefix it watches
+	live   bool
+	cancel context.CancelFunc
+}
+
+// kvWatchEvent is a change, or the error that ended the stream.
+type kvWatchEvent struct {
+	ev  *pb.WatchEvent
+	err error
+}
+
+// kvWatchStartedMsg delivers a connected stream; gen ties it to one
+// startKvWatch.
+type kvWatchStartedMsg struct {
+	gen    int
+	events <-chan kvWatchEvent
+	cancel context.CancelFunc
+	err    error
+}
+
+// kvWatchMsg delivers the changes that arrived since the last one.
+type kvWatchMsg struct {
+	gen    int
+	events <-chan kvWatchEvent
+	evs    []*pb.WatchEvent
+	err    error
+	closed bool
+}
+
+// startKvWatch (re)watches the browser's prefix, dropping any current
+// stream. It does nothing if hermit's client can't watch.
+func (m Model) startKvWatch() (Model, tea.Cmd) {
+	m.stopKvWatch()
+	w, ok := m.hermit.(KvWatcher)
+	if !ok {
+		return m, nil
+	}
+	prefix := m.kvPrefix
+	m.kvWatch.prefix, m.kvWatch.live = prefix, true
+	gen := m.kvWatchGen
+	return m, func() tea.Msg {
+		ctx, cancel := context.WithCancel(context.Background())
+		stream, err := w.Watch(ctx, prefix)
+		if err != nil {
+			cancel()
+			return kvWatchStartedMsg{gen: gen, err: err}
+		}
+		events := make(chan kvWatchEvent, kvWatchBatch)
+		go func() {
+			defer close(events)
+			for {
+				ev, err := stream.Recv()
+				select {
+				case events <- kvWatchEvent{ev: ev, err: err}:
+				case <-ctx.Done():
+					return
+				}
+				if err != nil {
+					return
+				}
+			}
+		}()
+		return kvWatchStartedMsg{gen: gen, events: events, cancel: cancel}
+	}
+}
+
+// stopKvWatch cancels the stream; messages from it are ignored after.
+func (m *Model) stopKvWatch() {
+	if m.kvWatch.cancel != nil {
+		m.kvWatch.cancel()
+		m.kvWatch.cancel = nil
+	}
+	m.kvWatch.live = false
+	m.kvWatchGen++
+}
+
+// waitKvWatch blocks for the next change, then takes whatever else has
+// arrived.
+func waitKvWatch(gen int, events <-chan kvWatchEvent) tea.Cmd {
+	return func() tea.Msg {
+		msg := kvWatchMsg{gen: gen, events: events}
+		ev, ok := <-events
+		for ok {
+			if ev.err != nil {
+				msg.err = ev.err
+				return msg
+			}
+			msg.evs = append(msg.evs, ev.ev)
+			if len(msg.evs) == kvWatchBatch {
+				return msg
+			}
+			select {
+			case ev, ok = <-events:
+			default:
+				return msg
+			}
+		}
+		msg.closed = true
+		return msg
+	}
+}
+
+func (m Model) handleKvWatchStarted(msg kvWatchStartedMsg) (tea.Model, tea.Cmd) {
+	if msg.gen != m.kvWatchGen {
+		if msg.cancel != nil {
+			msg.cancel()
+		}
+		return m, nil
+	}
+	if msg.err != nil {
+		m.kvWatch.live = false // ErrUnsupported or down; [r] still works
+		return m, nil
+	}
+	m.kvWatch.cancel = msg.cancel
+	return m, waitKvWatch(msg.gen, msg.events)
+}
+
+func (m Model) handleKvWatch(msg kvWatchMsg) (tea.Model, tea.Cmd) {
+	if msg.gen != m.kvWatchGen {
+		return m, nil
+	}
+	if m.state != stateKV { // left via the palette
+		m.stopKvWatch()
+		return m, nil
+	}
+	refetch := false
+	for _, ev := range msg.evs {
+		set := ev.Kind == pb.WatchEvent_SET
+		if set {
+			m.rememberKeys(ev.Key)
+		} else {
+			m.forgetKeys(ev.Key)
+		}
+		if ev.Key == m.kvPreview.key {
+			m.kvPreview = kvPreview{key: ev.Key, value: ev.Value, found: set}
+		}
+		refetch = refetch || m.kvOnPage(ev.Key)
+	}
+	if status.Code(msg.err) == codes.DataLoss {
+		// Fell behind: re-read what was missed and watch again.
+		var watch tea.Cmd
+		m, watch = m.startKvWatch()
+		return m, tea.Batch(m.doKvBrowse(), watch)
+	}
+	var cmds []tea.Cmd
+	if refetch && !m.kvPrefixing {
+		cmds = append(cmds, m.doKvBrowse())
+	}
+	if msg.err != nil || msg.closed {
+		m.stopKvWatch()
+	} else {
+		cmds = append(cmds, waitKvWatch(msg.gen, msg.events))
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// kvOnPage reports whether key belongs on the current page, which holds
+// the keys after its cursor up to and including the next page's.
+func (m Model) kvOnPage(key string) bool {
+	return strings.HasPrefix(key, m.kvPrefix) && key > m.kvCursor() && (m.kvNext == "" || key <= m.kvNext)
+}
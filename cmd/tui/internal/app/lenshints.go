@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package app
+
+import (
+	"encoding/hex"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// lensHint is a guess that one of the secrets service's lenses will see a
+// submission as something else. The service is the authority; these are
+// client-side previews of services/secrets/internal/lens so players can see
+// what they are about to admit.
+type lensHint struct {
+	lens   string
+	detail string // what the lens turns the value into, if short
+}
+
+// lensHints previews which lenses apply to s.
+func lensHints(s string) []lensHint {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	var hints []lensHint
+	if lower := strings.ToLower(s); lower != s {
+		hint := lensHint{lens: "casefold", detail: lower}
+		if !isASCII(s) {
+			hint.lens = "unicode_casefold"
+		}
+		hints = append(hints, hint)
+	}
+	if nfc := norm.NFC.String(s); nfc != s {
+		hints = append(hints, lensHint{lens: "identity", detail: "NFC-normalizes to a different form"})
+	}
+	if isPalindrome(s) {
+		hints = append(hints, lensHint{lens: "palindrome", detail: "reads the same backwards"})
+	}
+	if decoded, ok := hexText(s); ok {
+		hints = append(hints, lensHint{lens: "hexdecode", detail: decoded})
+	}
+	if r, ok := lookalike(s); ok {
+		hints = append(hints, lensHint{lens: "homoglyph", detail: "contains lookalike " + string(r)})
+	}
+	return hints
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// isPalindrome matches the palindrome lens: letters and digits only,
+// case-folded, at least two of them.
+func isPalindrome(s string) bool {
+	var cleaned []rune
+	for _, r := range strings.ToLower(norm.NFC.String(s)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			cleaned = append(cleaned, r)
+		}
+	}
+	if len(cleaned) < 2 {
+		return false
+	}
+	for i, j := 0, len(cleaned)-1; i < j; i, j = i+1, j-1 {
+		if cleaned[i] != cleaned[j] {
+			return false
+		}
+	}
+	return true
+}
+
+// hexText reports whether s is hex that decodes to UTF-8 text.
+func hexText(s string) (string, bool) {
+	if len(s) < 2 || len(s)%2 != 0 {
+		return "", false
+	}
+	decoded, err := hex.DecodeString(s)
+	if err != nil || !utf8.Valid(decoded) {
+		return "", false
+	}
+	return string(decoded), true
+}
+
+// lookalike returns the first Cyrillic, Greek or fullwidth letter in s, the
+// scripts the homoglyph lens maps to Latin.
+func lookalike(s string) (rune, bool) {
+	for _, r := range s {
+		if unicode.In(r, unicode.Cyrillic, unicode.Greek) || (r >= 0xFF21 && r <= 0xFF5A) {
+			return r, true
+		}
+	}
+	return 0, false
+}
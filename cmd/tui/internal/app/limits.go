@@ -0,0 +1,121 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+
+	pb "github.com/jredh-dev/nexus/cmd/tui/proto"
+)
+
+// RateLimiter is a HermitClient that can list and change hermit's rate
+// limits (the Limits and SetLimit RPCs; admins only).
+type RateLimiter interface {
+	Limits() (*pb.LimitsResponse, error)
+	SetLimit(limit *pb.RateLimit, remove bool) (*pb.LimitsResponse, error)
+}
+
+// Quota is where this client stands against one of hermit's rate limits,
+// as of its last limited call.
+type Quota struct {
+	Class     string  // write or bench
+	Limit     uint32  // calls at once
+	Rate      float64 // calls a second
+	Remaining uint32
+}
+
+// QuotaReporter is a HermitClient that remembers the quotas hermit sent
+// with limited calls.
+type QuotaReporter interface {
+	Quota(class string) (Quota, bool)
+}
+
+// fmtRateLimit shows a limit as "write 10/s burst 20", with "@client" when
+// it is one client's own.
+func fmtRateLimit(l *pb.RateLimit) string {
+	name := l.Class
+	if l.Client != "" {
+		name += "@" + l.Client
+	}
+	if l.PerSecond == 0 {
+		return name + " unlimited"
+	}
+	return fmt.Sprintf("%s %s/s burst %d", name, strconv.FormatFloat(l.PerSecond, 'f', -1, 64), l.Burst)
+}
+
+// limitsCommand runs the limits, limits:set and limits:clear console
+// commands.
+func (m Model) limitsCommand(raw string, parts []string) tea.Cmd {
+	verb := strings.ToLower(parts[0])
+	var call func(RateLimiter) (*pb.LimitsResponse, error)
+	switch verb {
+	case "limits":
+		call = RateLimiter.Limits
+
+	case "limits:set":
+		if len(parts) != 4 && len(parts) != 5 {
+			return m.dbResult(raw, "", fmt.Errorf("usage: limits:set <write|bench> <per-sec> <burst> [client]"))
+		}
+		rate, err := strconv.ParseFloat(parts[2], 64)
+		if err != nil || rate < 0 {
+			return m.dbResult(raw, "", fmt.Errorf("rate %q: want calls a second, 0 for unlimited", parts[2]))
+		}
+		burst, err := strconv.ParseUint(parts[3], 10, 32)
+		if err != nil {
+			return m.dbResult(raw, "", fmt.Errorf("burst %q: want a number of calls, 0 for a second's worth", parts[3]))
+		}
+		l := &pb.RateLimit{Class: parts[1], PerSecond: rate, Burst: uint32(burst)}
+		if len(parts) == 5 {
+			l.Client = parts[4]
+		}
+		call = func(rl RateLimiter) (*pb.LimitsResponse, error) { return rl.SetLimit(l, false) }
+
+	case "limits:clear":
+		if len(parts) != 3 {
+			return m.dbResult(raw, "", fmt.Errorf("usage: limits:clear <write|bench> <client>"))
+		}
+		l := &pb.RateLimit{Class: parts[1], Client: parts[2]}
+		call = func(rl RateLimiter) (*pb.LimitsResponse, error) { return rl.SetLimit(l, true) }
+	}
+
+	h := m.hermit
+	return func() tea.Msg {
+		if h == nil {
+			return dbCmdResultMsg{cmd: raw, err: fmt.Errorf("not connected")}
+		}
+		rl, ok := h.(RateLimiter)
+		if !ok {
+			return dbCmdResultMsg{cmd: raw, err: ErrUnsupported}
+		}
+		resp, err := call(rl)
+		if err != nil {
+			return dbCmdResultMsg{cmd: raw, err: err}
+		}
+		if resp.Error != "" {
+			return dbCmdResultMsg{cmd: raw, err: fmt.Errorf("%s", resp.Error)}
+		}
+		limits := make([]string, len(resp.Limits))
+		for i, l := range resp.Limits {
+			limits[i] = fmtRateLimit(l)
+		}
+		return dbCmdResultMsg{cmd: raw, output: strings.Join(limits, ", "), data: resp}
+	}
+}
+
+// writeQuota is the client's write quota, if hermit limits its writes.
+func writeQuota(h HermitClient) *Quota {
+	qr, ok := h.(QuotaReporter)
+	if !ok {
+		return nil
+	}
+	q, ok := qr.Quota("write")
+	if !ok {
+		return nil
+	}
+	return &q
+}
@@ -0,0 +1,356 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// The Logs panel asks for logsBacklog recent lines when it connects and
+// keeps the last maxLogLines.
+const (
+	logsBacklog = 200
+	maxLogLines = 2000
+	logsBatch   = 500 // most lines delivered in one message
+)
+
+// logLevels are the level filter's steps, least severe first.
+var logLevels = []string{"TRACE", "DEBUG", "INFO", "WARN", "ERROR"}
+
+// logsState is the Logs panel: a tail of the log source, filtered by
+// minimum level and a search string.
+type logsState struct {
+	lines     []LogLine
+	minLevel  int    // index into logLevels
+	search    string // case-insensitive substring filter
+	searching bool   // the search is being typed
+	streaming bool
+	err       error // why the stream ended
+	cancel    context.CancelFunc
+}
+
+// logEvent is a line, or the error that ended the stream.
+type logEvent struct {
+	line LogLine
+	err  error
+}
+
+// logsStartedMsg delivers a connected stream. gen ties it to one visit of
+// the panel.
+type logsStartedMsg struct {
+	gen    int
+	events <-chan logEvent
+	cancel context.CancelFunc
+	err    error
+}
+
+// logsMsg delivers the lines that arrived since the last one.
+type logsMsg struct {
+	gen    int
+	events <-chan logEvent
+	lines  []LogLine
+	err    error
+	closed bool
+}
+
+// WithLogSource tails src in the Logs panel instead of hermit's own log.
+// name labels it in the panel header.
+func (m Model) WithLogSource(name string, src LogSource) Model {
+	m.logSource, m.logSourceName = src, name
+	return m
+}
+
+// logsSource is the panel's source: the configured one, else hermit if it
+// can tail its log.
+func (m Model) logsSource() (LogSource, string) {
+	if m.logSource != nil {
+		return m.logSource, m.logSourceName
+	}
+	if src, ok := m.hermit.(LogSource); ok {
+		return src, "hermit " + m.addr
+	}
+	return nil, ""
+}
+
+func openLogs(m Model) (Model, tea.Cmd) {
+	m.state = stateLogs
+	return m.startLogs()
+}
+
+// startLogs (re)connects to the log source, dropping any current stream.
+func (m Model) startLogs() (Model, tea.Cmd) {
+	m.stopLogs()
+	m.logs.lines, m.logs.err = nil, nil
+	src, _ := m.logsSource()
+	if src == nil {
+		return m, nil
+	}
+	m.logs.streaming = true
+	gen := m.logsGen
+	return m, func() tea.Msg {
+		ctx, cancel := context.WithCancel(context.Background())
+		stream, err := src.TailLogs(ctx, logsBacklog)
+		if err != nil {
+			cancel()
+			return logsStartedMsg{gen: gen, err: err}
+		}
+		events := make(chan logEvent, logsBatch)
+		go func() {
+			defer close(events)
+			for {
+				line, err := stream.Recv()
+				select {
+				case events <- logEvent{line: line, err: err}:
+				case <-ctx.Done():
+					return
+				}
+				if err != nil {
+					return
+				}
+			}
+		}()
+		return logsStartedMsg{gen: gen, events: events, cancel: cancel}
+	}
+}
+
+// stopLogs cancels the stream; messages from it are ignored after.
+func (m *Model) stopLogs() {
+	if m.logs.cancel != nil {
+		m.logs.cancel()
+		m.logs.cancel = nil
+	}
+	m.logs.streaming = false
+	m.logsGen++
+}
+
+// waitLogs blocks for the next line, then takes whatever else has arrived.
+func waitLogs(gen int, events <-chan logEvent) tea.Cmd {
+	return func() tea.Msg {
+		msg := logsMsg{gen: gen, events: events}
+		ev, ok := <-events
+		for ok {
+			if ev.err != nil {
+				msg.err = ev.err
+				return msg
+			}
+			msg.lines = append(msg.lines, ev.line)
+			if len(msg.lines) == logsBatch {
+				return msg
+			}
+			select {
+			case ev, ok = <-events:
+			default:
+				return msg
+			}
+		}
+		msg.closed = true
+		return msg
+	}
+}
+
+func (m Model) handleLogsStarted(msg logsStartedMsg) (tea.Model, tea.Cmd) {
+	if msg.gen != m.logsGen {
+		if msg.cancel != nil {
+			msg.cancel()
+		}
+		return m, nil
+	}
+	if msg.err != nil {
+		m.logs.streaming, m.logs.err = false, msg.err
+		return m, nil
+	}
+	m.logs.cancel = msg.cancel
+	return m, waitLogs(msg.gen, msg.events)
+}
+
+func (m Model) handleLogs(msg logsMsg) (tea.Model, tea.Cmd) {
+	if msg.gen != m.logsGen {
+		return m, nil
+	}
+	m.logs.lines = append(m.logs.lines, msg.lines...)
+	if len(m.logs.lines) > maxLogLines {
+		m.logs.lines = m.logs.lines[len(m.logs.lines)-maxLogLines:]
+	}
+	if msg.err != nil || msg.closed {
+		m.logs.err = msg.err
+		m.stopLogs()
+		return m, nil
+	}
+	if m.state != stateLogs { // left via the palette
+		m.stopLogs()
+		return m, nil
+	}
+	return m, waitLogs(msg.gen, msg.events)
+}
+
+func (m Model) handleLogsKey(k tea.Key) (tea.Model, tea.Cmd) {
+	if m.logs.searching {
+		return m.handleLogsSearchKey(k), nil
+	}
+	if m.scrollKey(&m.logsScroll, k) {
+		return m, nil
+	}
+	switch {
+	case m.pressed(k, m.keys.back) && m.logs.search != "":
+		m.logs.search = ""
+	case m.pressed(k, m.keys.back, m.keys.quit):
+		m.stopLogs()
+		m.state = stateDashboard
+	case m.pressed(k, m.keys.up):
+		m.logsScroll.lineUp()
+	case m.pressed(k, m.keys.down):
+		m.logsScroll.lineDown()
+	case m.pressed(k, m.keys.refresh):
+		return m.startLogs()
+	case m.pressed(k, m.keys.level):
+		m.logs.minLevel = (m.logs.minLevel + 1) % len(logLevels)
+	case m.pressed(k, m.keys.search):
+		m.logs.searching = true
+	}
+	return m, nil
+}
+
+// handleLogsSearchKey edits the search. It filters as it is typed; enter
+// keeps it and esc drops it.
+func (m Model) handleLogsSearchKey(k tea.Key) Model {
+	switch k.Code {
+	case tea.KeyEnter:
+		m.logs.searching = false
+	case tea.KeyEscape:
+		m.logs.searching = false
+		m.logs.search = ""
+	case tea.KeyBackspace:
+		if m.logs.search != "" {
+			_, size := utf8.DecodeLastRuneInString(m.logs.search)
+			m.logs.search = m.logs.search[:len(m.logs.search)-size]
+		}
+	default:
+		if k.Text != "" {
+			m.logs.search += k.Text
+		}
+	}
+	return m
+}
+
+// levelRank orders a level within logLevels. Lines of unknown level rank
+// as INFO, so they show until the filter is raised past it.
+func levelRank(level string) int {
+	for i, l := range logLevels {
+		if l == level {
+			return i
+		}
+	}
+	return 2
+}
+
+// shownLogs returns the lines that pass the level filter and search.
+func (m Model) shownLogs() []LogLine {
+	q := strings.ToLower(m.logs.search)
+	var shown []LogLine
+	for _, l := range m.logs.lines {
+		if levelRank(l.Level) < m.logs.minLevel {
+			continue
+		}
+		if q != "" && !strings.Contains(strings.ToLower(l.Message), q) && !strings.Contains(strings.ToLower(l.Target), q) {
+			continue
+		}
+		shown = append(shown, l)
+	}
+	return shown
+}
+
+// logsLines renders the shown lines for the scrollback.
+func (m Model) logsLines() []string {
+	shown := m.shownLogs()
+	lines := make([]string, len(shown))
+	for i, l := range shown {
+		ts := "        "
+		if !l.Time.IsZero() {
+			ts = l.Time.Local().Format("15:04:05")
+		}
+		level := fmt.Sprintf("%-5s", l.Level)
+		switch levelRank(l.Level) {
+		case 4:
+			level = m.st.err.Render(level)
+		case 3:
+			level = m.st.value.Render(level)
+		case 0, 1:
+			level = m.st.dim.Render(level)
+		}
+		text := l.Message
+		if l.Target != "" {
+			text = m.st.dim.Render(l.Target+":") + " " + text
+		}
+		lines[i] = m.st.dim.Render(ts) + " " + level + " " + text
+	}
+	return lines
+}
+
+// logsHeight is the number of lines the Logs panel gives its scrollback,
+// under the title and a blank line.
+func logsHeight(maxLines int) int {
+	return maxLines - 2
+}
+
+func (m Model) renderLogsPanel(innerW, _ int) string {
+	var b strings.Builder
+	b.WriteString(m.st.title.Render(m.tr("Logs")))
+	_, name := m.logsSource()
+	header := m.trf("  %s  ≥%s  %d/%d lines  %s", name, logLevels[m.logs.minLevel], len(m.shownLogs()), len(m.logs.lines), m.logsScroll.status())
+	if m.logs.search != "" {
+		header += fmt.Sprintf("  /%s", m.logs.search)
+	}
+	b.WriteString(m.st.dim.Render(truncate(header, max(0, innerW-6))))
+	b.WriteString("\n\n")
+	b.WriteString(m.logsScroll.view())
+	return b.String()
+}
+
+func (m Model) renderLogsInfoPanel(innerW, _ int) string {
+	var b strings.Builder
+	b.WriteString(m.st.title.Render(m.tr("Tail")))
+	b.WriteString("\n\n")
+
+	src, _ := m.logsSource()
+	switch {
+	case src == nil:
+		b.WriteString(m.st.dim.Render(m.tr("No log source: this hermit client can't tail logs and no --logs-url is set.")))
+	case errors.Is(m.logs.err, ErrUnsupported):
+		b.WriteString(m.st.dim.Render(m.tr("This server doesn't serve its logs; point --logs-url at an HTTP log endpoint.")))
+	case m.logs.err != nil && !errors.Is(m.logs.err, errLogEnded):
+		b.WriteString(m.st.err.Render(m.tr("stream failed: ") + m.logs.err.Error()))
+	case m.logs.streaming:
+		b.WriteString(m.trf("status %s", m.st.value.Render(m.tr("streaming"))))
+	default:
+		b.WriteString(m.trf("status %s", m.st.dim.Render(m.tr("ended"))))
+	}
+	b.WriteString("\n")
+
+	counts := make([]int, len(logLevels))
+	for _, l := range m.logs.lines {
+		counts[levelRank(l.Level)]++
+	}
+	var parts []string
+	for i, l := range logLevels {
+		parts = append(parts, fmt.Sprintf("%s %d", strings.ToLower(l), counts[i]))
+	}
+	b.WriteString(m.st.dim.Render(strings.Join(parts, "  ")))
+	b.WriteString("\n\n")
+
+	if m.logs.searching {
+		b.WriteString(m.st.prompt.Render(m.tr("search> ")))
+		b.WriteString(truncate(m.logs.search, max(1, innerW-12)))
+		b.WriteString("█\n\n")
+		b.WriteString(m.st.dim.Render(m.tr("[enter] keep  [esc] clear")))
+		return b.String()
+	}
+	b.WriteString(m.st.dim.Render(m.tr("[v] level  [/] search  [↑/↓/pgup/pgdn] scroll  [ctrl+f] follow  [r] reconnect  [esc] back")))
+	return b.String()
+}
@@ -0,0 +1,166 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package app
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// LogLine is one line of a service's log.
+type LogLine struct {
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"` // TRACE, DEBUG, INFO, WARN or ERROR; empty if unknown
+	Target  string    `json:"target,omitempty"`
+	Message string    `json:"message"`
+}
+
+// LogStream yields log lines until the source ends it or its context is
+// cancelled.
+type LogStream interface {
+	Recv() (LogLine, error)
+}
+
+// LogSource tails a service's log: up to backlog recent lines, then new
+// ones as they are written. hermit's client is one (the TailLogs RPC); an
+// HTTP endpoint is another.
+type LogSource interface {
+	TailLogs(ctx context.Context, backlog int) (LogStream, error)
+}
+
+// --- HTTP implementation ---
+
+type httpLogSource struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPLogSource tails the log served at url, one line per line of the
+// response body. JSON lines in the usual slog/zap/logrus shapes keep their
+// time and level; other lines are plain text with the level guessed from
+// the text. An endpoint that holds the response open (a follow mode) is
+// tailed live; one that doesn't ends the stream when its body does.
+func NewHTTPLogSource(url string) LogSource {
+	// No timeout: the stream lives until its context is cancelled.
+	return &httpLogSource{url: url, client: &http.Client{}}
+}
+
+func (s *httpLogSource) TailLogs(ctx context.Context, _ int) (LogStream, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("logs: %w", err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrUnsupported
+	case resp.StatusCode != http.StatusOK:
+		resp.Body.Close()
+		return nil, fmt.Errorf("logs: %s", resp.Status)
+	}
+	sc := bufio.NewScanner(resp.Body)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	return &httpLogStream{sc: sc, close: resp.Body.Close}, nil
+}
+
+type httpLogStream struct {
+	sc    *bufio.Scanner
+	close func() error
+}
+
+func (s *httpLogStream) Recv() (LogLine, error) {
+	for s.sc.Scan() {
+		if line := strings.TrimRight(s.sc.Text(), "\r"); strings.TrimSpace(line) != "" {
+			return parseLogLine(line), nil
+		}
+	}
+	s.close()
+	if err := s.sc.Err(); err != nil {
+		return LogLine{}, err
+	}
+	return LogLine{}, errLogEnded
+}
+
+// errLogEnded is returned by a stream whose source closed it.
+var errLogEnded = errors.New("log stream ended")
+
+// logLevelRe finds a level word in a plain text line: "ERROR", "[warn]",
+// "level=info" and the like.
+var logLevelRe = regexp.MustCompile(`(?i)\b(trace|debug|info|warn|warning|error|err|fatal|panic)\b`)
+
+// parseLogLine reads a JSON log line if it is one, or takes the line as
+// plain text otherwise.
+func parseLogLine(line string) LogLine {
+	if strings.HasPrefix(line, "{") {
+		var fields map[string]any
+		if json.Unmarshal([]byte(line), &fields) == nil {
+			return jsonLogLine(fields)
+		}
+	}
+	l := LogLine{Message: line}
+	if m := logLevelRe.FindString(line); m != "" {
+		l.Level = normalizeLevel(m)
+	}
+	return l
+}
+
+// jsonLogLine picks the common fields out of a structured log line.
+func jsonLogLine(fields map[string]any) LogLine {
+	str := func(keys ...string) string {
+		for _, k := range keys {
+			if v, ok := fields[k].(string); ok {
+				delete(fields, k)
+				return v
+			}
+		}
+		return ""
+	}
+	var l LogLine
+	l.Level = normalizeLevel(str("level", "lvl", "severity"))
+	l.Message = str("msg", "message")
+	l.Target = str("target", "logger", "source")
+	switch ts := fields["time"].(type) {
+	case string:
+		l.Time, _ = time.Parse(time.RFC3339Nano, ts)
+		delete(fields, "time")
+	}
+	if ts, ok := fields["ts"].(float64); ok { // zap: seconds since the epoch
+		l.Time = time.Unix(0, int64(ts*float64(time.Second)))
+		delete(fields, "ts")
+	}
+	// Whatever is left is context worth keeping, in a stable order.
+	if rest, err := json.Marshal(fields); err == nil && len(fields) > 0 {
+		l.Message = strings.TrimSpace(l.Message + " " + string(rest))
+	}
+	return l
+}
+
+// normalizeLevel maps level spellings onto TRACE, DEBUG, INFO, WARN and
+// ERROR.
+func normalizeLevel(s string) string {
+	switch strings.ToUpper(s) {
+	case "TRACE":
+		return "TRACE"
+	case "DEBUG":
+		return "DEBUG"
+	case "INFO", "NOTICE":
+		return "INFO"
+	case "WARN", "WARNING":
+		return "WARN"
+	case "ERROR", "ERR", "FATAL", "PANIC", "CRITICAL":
+		return "ERROR"
+	}
+	return ""
+}
@@ -10,7 +10,8 @@ import (
 // --- Tea messages ---
 
 type loginResultMsg struct {
-	err error
+	role string
+	err  error
 }
 
 type serverInfoMsg struct {
@@ -19,21 +20,78 @@ type serverInfoMsg struct {
 }
 
 type benchmarkResultMsg struct {
-	resp *pb.BenchmarkResponse
+	gen  int
+	resp *pb.BenchmarkProgress
 	err  error
 }
 
-type dbStatsMsg struct {
-	resp *pb.DbStatsResponse
+// reconnectTickMsg starts a reconnect attempt. gen ties it to one outage.
+type reconnectTickMsg struct {
+	gen int
+}
+
+type reconnectResultMsg struct {
+	gen  int
+	role string
 	err  error
 }
 
+type themeSavedMsg struct {
+	name string
+	err  error
+}
+
+// exportMsg reports a ctrl+s export of the panel in state.
+type exportMsg struct {
+	state appState
+	path  string
+	err   error
+}
+
+// benchTickMsg redraws a running benchmark. gen ties it to one run.
+type benchTickMsg struct {
+	gen int
+}
+
+type dbStatsMsg struct {
+	resp  *pb.DbStatsResponse
+	quota *Quota                  // the write quota as of the last write, if limited
+	diag  *pb.DiagnosticsResponse // for admins of a hermit with Diagnostics
+	err   error
+}
+
 type dbCmdResultMsg struct {
 	cmd    string
 	output string
+	keys   []string             // keys the command listed or wrote, for completion
+	gone   []string             // keys the command deleted
+	sql    *pb.SqlQueryResponse // rows for the results table
+	sqlReq *pb.SqlQueryRequest  // the query, for the table's initial sort
+	data   any                  // the raw response, for tui exec --json
 	err    error
 }
 
+type kvKeysMsg struct {
+	keys []string
+	err  error
+}
+
+// kvBrowseMsg is one page of keys for the KV browser. prefix and cursor
+// are what it was fetched with, so a stale page is dropped.
+type kvBrowseMsg struct {
+	prefix, cursor string
+	keys           []string
+	next           string
+	err            error
+}
+
+type kvPreviewMsg struct {
+	key   string
+	value []byte
+	found bool
+	err   error
+}
+
 type secretsListMsg struct {
 	secrets []Secret
 	err     error
@@ -48,3 +106,19 @@ type secretSubmitMsg struct {
 	result *SubmitResult
 	err    error
 }
+
+type secretsExposedMsg struct {
+	page ExposedPage
+	err  error
+}
+
+type secretsLeaderboardMsg struct {
+	entries []LeaderboardEntry
+	err     error
+}
+
+// secretsPollMsg triggers a refresh of the Secrets panel. gen ties it to one
+// visit of the panel so leaving and re-entering doesn't double the polling.
+type secretsPollMsg struct {
+	gen int
+}
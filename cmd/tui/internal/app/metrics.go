@@ -0,0 +1,217 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package app
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+
+	pb "github.com/jredh-dev/nexus/cmd/tui/proto"
+)
+
+// MetricsSource is a HermitClient that reports server metrics (the Metrics
+// RPC).
+type MetricsSource interface {
+	Metrics() (*pb.MetricsResponse, error)
+}
+
+// metricsInterval is how often the Metrics panel refreshes while open.
+const metricsInterval = 2 * time.Second
+
+// metricsState is the Metrics panel: the last two samples, so call counts
+// can be turned into rates.
+type metricsState struct {
+	cur, prev     *pb.MetricsResponse
+	curAt, prevAt time.Time
+	err           error
+}
+
+// metricsMsg delivers a sample. gen ties it to one visit of the panel.
+type metricsMsg struct {
+	gen  int
+	at   time.Time
+	resp *pb.MetricsResponse
+	err  error
+}
+
+// metricsPollMsg triggers the next sample.
+type metricsPollMsg struct {
+	gen int
+}
+
+func openMetrics(m Model) (Model, tea.Cmd) {
+	m.state = stateMetrics
+	m.metricsGen++
+	return m, m.doMetrics()
+}
+
+func (m Model) doMetrics() tea.Cmd {
+	gen := m.metricsGen
+	h := m.hermit
+	return func() tea.Msg {
+		src, ok := h.(MetricsSource)
+		if !ok {
+			return metricsMsg{gen: gen, err: ErrUnsupported}
+		}
+		resp, err := src.Metrics()
+		return metricsMsg{gen: gen, at: time.Now(), resp: resp, err: err}
+	}
+}
+
+func (m Model) pollMetrics() tea.Cmd {
+	gen := m.metricsGen
+	return tea.Tick(metricsInterval, func(time.Time) tea.Msg {
+		return metricsPollMsg{gen: gen}
+	})
+}
+
+func (m Model) handleMetricsPoll(msg metricsPollMsg) (tea.Model, tea.Cmd) {
+	if m.state != stateMetrics || msg.gen != m.metricsGen {
+		return m, nil
+	}
+	return m, m.doMetrics()
+}
+
+func (m Model) handleMetrics(msg metricsMsg) (tea.Model, tea.Cmd) {
+	if msg.gen != m.metricsGen {
+		return m, nil
+	}
+	if m, cmd, lost := m.connLost(msg.err); lost {
+		return m, cmd
+	}
+	if msg.err != nil {
+		m.metrics.err = msg.err
+		if errors.Is(msg.err, ErrUnsupported) {
+			return m, nil // asking again won't help
+		}
+	} else {
+		s := &m.metrics
+		s.prev, s.prevAt = s.cur, s.curAt
+		s.cur, s.curAt, s.err = msg.resp, msg.at, nil
+	}
+	if m.state != stateMetrics {
+		return m, nil
+	}
+	return m, m.pollMetrics()
+}
+
+func (m Model) handleMetricsKey(k tea.Key) (tea.Model, tea.Cmd) {
+	switch {
+	case m.pressed(k, m.keys.back, m.keys.quit):
+		m.state = stateDashboard
+		m.metricsGen++ // stop polling
+	case m.pressed(k, m.keys.refresh):
+		m.metricsGen++ // the new sample restarts polling
+		return m, m.doMetrics()
+	}
+	return m, nil
+}
+
+// rpcRate is method's calls per second between the last two samples.
+func (s metricsState) rpcRate(method string, calls uint64) (float64, bool) {
+	if s.prev == nil {
+		return 0, false
+	}
+	secs := s.curAt.Sub(s.prevAt).Seconds()
+	for _, r := range s.prev.Rpcs {
+		if r.Method == method && calls >= r.Calls && secs > 0 {
+			return float64(calls-r.Calls) / secs, true
+		}
+	}
+	return 0, false
+}
+
+// latencyQuantile returns the upper bound of the bucket the q quantile of
+// r's calls falls in, and false when that is the unbounded last bucket or
+// there are no calls.
+func latencyQuantile(r *pb.RpcMetric, bounds []uint64, q float64) (time.Duration, bool) {
+	if r.Calls == 0 {
+		return 0, false
+	}
+	want := uint64(math.Ceil(q * float64(r.Calls)))
+	var cum uint64
+	for i, n := range r.Buckets {
+		cum += n
+		if cum >= want && i < len(bounds) {
+			return time.Duration(bounds[i]) * time.Microsecond, true
+		}
+	}
+	return 0, false
+}
+
+func (m Model) renderMetricsPanel(_, maxLines int) string {
+	var b strings.Builder
+	b.WriteString(m.st.title.Render(m.tr("Metrics")))
+	b.WriteString(m.st.dim.Render(m.trf("  %s  every %s", m.addr, metricsInterval)))
+	b.WriteString("\n\n")
+	s := m.metrics
+	switch {
+	case s.err != nil && errors.Is(s.err, ErrUnsupported):
+		b.WriteString(m.st.dim.Render(m.tr("This hermit has no Metrics RPC.")))
+		return b.String()
+	case s.err != nil:
+		b.WriteString(m.st.err.Render(m.tr("  ERROR: ") + s.err.Error()))
+		b.WriteString("\n\n")
+	}
+	if s.cur == nil {
+		if s.err == nil {
+			b.WriteString(m.st.dim.Render(m.tr("loading...")))
+		}
+		return b.String()
+	}
+
+	const methodW, numW = 12, 9
+	fmt.Fprintf(&b, "%-*s %*s %*s %*s %*s %*s\n", methodW, m.tr("RPC"), numW, m.tr("calls"),
+		numW, m.tr("per sec"), numW, m.tr("mean"), numW, m.tr("p99 ≤"), numW, m.tr("max"))
+	rows := max(1, maxLines-4)
+	for i, r := range s.cur.Rpcs {
+		if i == rows {
+			b.WriteString(m.st.dim.Render(m.trf("…and %d more", len(s.cur.Rpcs)-rows)))
+			break
+		}
+		rate := "-"
+		if v, ok := s.rpcRate(r.Method, r.Calls); ok {
+			rate = fmt.Sprintf("%.1f", v)
+		}
+		mean := "-"
+		if r.Calls > 0 {
+			mean = fmtNs(int64(r.TotalUs/r.Calls) * 1000)
+		}
+		p99 := "-"
+		if d, ok := latencyQuantile(r, s.cur.LatencyBoundsUs, 0.99); ok {
+			p99 = fmtNs(d.Nanoseconds())
+		}
+		fmt.Fprintf(&b, "%-*s %s %*s %*s %*s %*s\n", methodW, truncate(r.Method, methodW),
+			m.st.value.Render(fmt.Sprintf("%*d", numW, r.Calls)), numW, rate, numW, mean, numW, p99,
+			numW, fmtNs(int64(r.MaxUs)*1000))
+	}
+	return b.String()
+}
+
+func (m Model) renderMetricsInfoPanel(_, _ int) string {
+	var b strings.Builder
+	b.WriteString(m.st.title.Render(m.tr("Server")))
+	b.WriteString("\n\n")
+	if r := m.metrics.cur; r != nil {
+		line := func(label, value string) {
+			fmt.Fprintf(&b, "%-16s %s\n", m.tr(label), m.st.value.Render(value))
+		}
+		line("documents", m.trf("%d keys, %s", r.DocKeys, fmtBytes(r.DocBytes)))
+		line("rows", m.trf("%d committed, %d pending", r.RelRows, r.RelPendingWrites))
+		line("watchers", fmt.Sprintf("%d", r.Watchers))
+		line("expirer", m.trf("%d runs, %d keys, last %s", r.ExpireSweeps, r.ExpiredKeys, fmtNs(int64(r.ExpireLastSweepUs)*1000)))
+		line("connections", m.trf("%d, %d TLS handshakes", r.Connections, r.TlsHandshakes))
+		line("rate limited", m.trf("%d calls", r.RateLimited))
+		line("memory", fmtBytes(r.RssBytes))
+		line("uptime", (time.Duration(r.UptimeSeconds) * time.Second).String())
+		b.WriteString("\n")
+	}
+	b.WriteString(m.st.dim.Render(m.tr("[r] refresh  [esc] back")))
+	return b.String()
+}
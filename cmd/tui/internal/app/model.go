@@ -4,6 +4,8 @@
 package app
 
 import (
+	"time"
+
 	pb "github.com/jredh-dev/nexus/cmd/tui/proto"
 )
 
@@ -16,6 +18,13 @@ const (
 	stateBenchmark
 	stateDB
 	stateSecrets
+	stateKV
+	stateSQL
+	stateHealth
+	statePortal
+	stateLogs
+	stateMetrics
+	stateTopics
 	stateError
 )
 
@@ -36,38 +45,149 @@ type Model struct {
 	width  int
 	height int
 
-	hermit  HermitClient
-	secrets SecretsClient
+	hermit       HermitClient
+	secrets      SecretsClient
+	portalClient PortalClient
+
+	logSource     LogSource // overrides hermit's log; see WithLogSource
+	logSourceName string
 
 	err error
 
+	// Connection to hermit; see conn.go
+	conn             connState
+	connGen          int // current outage; see reconnectTickMsg
+	reconnectAttempt int
+
 	// Login
 	username string
+	token    string // see WithToken
+	role     string // from hermit's Login: read, write or admin
 
 	// Dashboard
 	serverInfo  *pb.ServerInfoResponse
 	viewHistory []string
+	tlsWarning  string // see TLSWarner; toasted when it changes
 
 	// Benchmark
-	grpcBench    *pb.BenchmarkResponse
+	grpcBench    *pb.BenchmarkProgress
 	benchRunning bool
+	benchCfg     benchConfig
+	benchField   int            // selected row of the config form
+	benchTyped   bool           // digits typed into the field since selecting it
+	benchProg    *benchProgress // the current or last run
+	benchGen     int            // current run; see benchTickMsg
 
 	// DB Console
 	dbStats   *pb.DbStatsResponse
+	dbQuota   *Quota                  // the write quota, when hermit limits writes
+	dbDiag    *pb.DiagnosticsResponse // slow calls and large values, for admins
 	dbInput   string
 	dbHistory []dbHistoryEntry
+	dbScroll  scrollback
+	kvKeys    map[string]bool // keys seen via kv:list or kv:set, for completion
+	dbMatches []string        // completion candidates after an ambiguous tab
+	sql       sqlResults      // last sql:query with rows; see stateSQL
+
+	// KV browser
+	kvList      []string // keys on the current page, sorted
+	kvIdx       int      // selected key
+	kvErr       error
+	kvPreview   kvPreview
+	kvPrefix    string   // only keys starting with this are listed
+	kvPrefixing bool     // the prefix is being typed
+	kvPages     []string // cursor of each page visited; see kvbrowser.go
+	kvNext      string   // cursor of the page after this one, if any
+	kvWatch     kvWatchState
+	kvWatchGen  int // current stream; see kvWatchMsg
+
+	// Health watchdog; see health.go
+	health    []healthSample
+	healthLog []healthEvent
+	healthGen int // current polling generation; see healthPollMsg
+
+	// Portal panel; see portal.go
+	portal portalState
+
+	// Metrics panel; see metrics.go
+	metrics    metricsState
+	metricsGen int // current polling generation; see metricsPollMsg
+
+	// Topics panel; see topics.go
+	topics       topicsState
+	topicsScroll scrollback
+	topicsGen    int // current stream; see topicsMsg
+
+	// Logs panel; see logs.go
+	logs       logsState
+	logsScroll scrollback
+	logsGen    int // current stream; see logsMsg
 
 	// Secrets panel
-	secretsList  []Secret
-	secretsStats SecretsStats
-	secretsInput string // value being typed for submission
-	secretsLog   []secretsLogEntry
+	secretsList   []Secret
+	secretsStats  SecretsStats
+	secretsInput  string // value being typed for submission
+	secretsLog    []secretsLogEntry
+	secretsScroll scrollback
+	secretsTab    secretsTab
+	secretsGen    int // current polling generation; see secretsPollMsg
+	exposures     []exposure
+	exposedSeen   map[string]bool
+	exposedPolled bool // the first exposed page (the backlog) has loaded
+	leaderboard   []LeaderboardEntry
+	boardErr      error
 
 	// Menu
 	menuItems []string
 	menuIdx   int
+
+	// Split view (ctrl+arrows resize, ctrl+l toggles side by side)
+	splitPct   int // first panel's share of the screen, in percent
+	sideBySide bool
+
+	// Command palette (ctrl+k)
+	palette palette
+
+	keys     keyMap
+	helpOpen bool // the ? overlay listing keys
+
+	exportDir string // where ctrl+s writes panel exports
+
+	// Toasts for async results; see toast.go
+	toasts   []toast
+	toastSeq int
+	toastDur time.Duration
+
+	// Theme (ctrl+t cycles)
+	st        styles
+	themeIdx  int
+	themeFile string // where the chosen theme is saved; empty = don't save
+
+	langIdx int // interface language; see i18n.go
 }
 
+// secretsTab selects what the top half of the Secrets panel shows.
+type secretsTab int
+
+const (
+	tabSecrets secretsTab = iota
+	tabExposures
+	tabLeaderboard
+	numSecretsTabs
+)
+
+var secretsTabNames = [numSecretsTabs]string{"Secrets", "Exposures", "Leaderboard"}
+
+// exposure is a value seen on the wall of exposed secrets.
+type exposure struct {
+	value string
+	lens  string    // lens that first exposed it; empty from older servers
+	seen  time.Time // when the TUI first saw it exposed
+}
+
+// maxExposures caps the exposure feed.
+const maxExposures = 50
+
 type secretsLogEntry struct {
 	ts    string
 	text  string
@@ -78,13 +198,24 @@ type secretsLogEntry struct {
 // individual panels without a live server.
 func New(addr, secret string, h HermitClient, s SecretsClient) Model {
 	return Model{
-		state:     stateLogin,
-		addr:      addr,
-		secret:    secret,
-		hermit:    h,
-		secrets:   s,
-		username:  "",
-		menuItems: []string{"Hermit DB", "Benchmark", "Secrets", "Quit"},
-		menuIdx:   0,
+		state:         stateLogin,
+		addr:          addr,
+		secret:        secret,
+		hermit:        h,
+		secrets:       s,
+		username:      "",
+		menuItems:     []string{"Hermit DB", "Benchmark", "Secrets", "KV Browser", "Health", "Portal", "Logs", "Metrics", "Topics", "Quit"},
+		menuIdx:       0,
+		benchCfg:      defaultBenchConfig(),
+		exportDir:     ".",
+		toastDur:      DefaultToastDuration,
+		splitPct:      50,
+		keys:          defaultKeyMap(),
+		st:            newStyles(themes[0]),
+		exposedSeen:   make(map[string]bool),
+		dbScroll:      newScrollback(),
+		secretsScroll: newScrollback(),
+		logsScroll:    newScrollback(),
+		topicsScroll:  newScrollback(),
 	}
 }
@@ -0,0 +1,145 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package app
+
+import (
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+// panelHit is a mouse position inside one of the splitView panels, relative
+// to the panel's content (inside its border and padding).
+type panelHit struct {
+	bottom   bool
+	row, col int
+}
+
+// hitPanel maps a screen position to the panel under it.
+func (m Model) hitPanel(x, y int) (panelHit, bool) {
+	l := m.layout()
+
+	// Border and padding put content 2 cells in from the left and 1 row
+	// down from each panel's top edge.
+	hit := panelHit{col: x - 2, row: y - 1}
+	w := l.topW
+	switch {
+	case l.sideBySide && x >= l.topW:
+		hit.bottom = true
+		hit.col = x - l.topW - 2
+		w = l.botW
+	case !l.sideBySide && y >= l.topH+2:
+		hit.bottom = true
+		hit.row = y - (l.topH + 2) - 1
+		w = l.botW
+	}
+	if hit.row < 0 || hit.col < 0 || hit.col >= w-4 {
+		return panelHit{}, false
+	}
+	return hit, true
+}
+
+func (m Model) handleMouseClick(msg tea.MouseClickMsg) (tea.Model, tea.Cmd) {
+	if msg.Button != tea.MouseLeft || m.palette.open {
+		return m, nil
+	}
+	hit, ok := m.hitPanel(msg.X, msg.Y)
+	if !ok {
+		return m, nil
+	}
+	switch m.state {
+	case stateDashboard:
+		return m.clickMenu(hit)
+	case stateBenchmark:
+		if hit.bottom && !m.benchRunning {
+			if i := hit.row - 2; i >= 0 && i < len(benchFields) {
+				m.benchField = i
+				m.benchTyped = false
+			}
+		}
+	case stateDB:
+		// Clicking a history line recalls its command, like a shell.
+		if !hit.bottom {
+			if i, ok := m.dbScroll.lineAt(hit.row - m.dbHistoryTop()); ok && i < len(m.dbHistory) {
+				m.dbInput = m.dbHistory[i].cmd
+				m.dbMatches = nil
+			}
+		}
+	case stateSecrets:
+		if !hit.bottom {
+			return m.clickSecrets(hit)
+		}
+	case stateKV:
+		// Clicking a key on the current page previews it.
+		if page := m.kvPageSize(); !hit.bottom && hit.row >= 2 && hit.row-2 < page {
+			if i := m.kvIdx/page*page + hit.row - 2; i < len(m.kvList) {
+				m.kvIdx = i
+				return m.previewSelected()
+			}
+		}
+	}
+	return m, nil
+}
+
+// clickMenu selects the clicked menu item; clicking the selected item runs
+// it, so a stray click can't quit.
+func (m Model) clickMenu(hit panelHit) (tea.Model, tea.Cmd) {
+	i := hit.row - 2 // below the "Menu" title and a blank line
+	if !hit.bottom || i < 0 || i >= len(m.menuItems) {
+		return m, nil
+	}
+	if i == m.menuIdx {
+		return m.executeMenuItem()
+	}
+	m.menuIdx = i
+	return m, nil
+}
+
+// clickSecrets switches tabs from the tab row, and copies a clicked secret
+// into the input so it can be admitted again.
+func (m Model) clickSecrets(hit panelHit) (tea.Model, tea.Cmd) {
+	switch {
+	case hit.row == 1:
+		x := 0
+		for i, name := range secretsTabNames {
+			w := lipgloss.Width(" " + name + " ")
+			if hit.col >= x && hit.col < x+w {
+				m.secretsTab = secretsTab(i)
+				if m.secretsTab == tabLeaderboard {
+					return m, m.doSecretsLeaderboard()
+				}
+				return m, nil
+			}
+			x += w
+		}
+	case hit.row >= 3 && m.secretsTab == tabSecrets:
+		list := m.visibleSecrets(m.secretsListLines(m.layout().topH))
+		if i := hit.row - 3; i < len(list) {
+			m.secretsInput = list[i].Value
+		}
+	}
+	return m, nil
+}
+
+// handleMouseWheel moves the menu selection on the dashboard and scrolls
+// the DB history, secrets log or log tail on their panels.
+func (m Model) handleMouseWheel(msg tea.MouseWheelMsg) (tea.Model, tea.Cmd) {
+	switch m.state {
+	case stateDashboard:
+		switch msg.Button {
+		case tea.MouseWheelUp:
+			m.menuIdx = max(0, m.menuIdx-1)
+		case tea.MouseWheelDown:
+			m.menuIdx = min(len(m.menuItems)-1, m.menuIdx+1)
+		}
+	case stateDB:
+		m.dbScroll.wheel(msg)
+	case stateSecrets:
+		m.secretsScroll.wheel(msg)
+	case stateLogs:
+		m.logsScroll.wheel(msg)
+	case stateTopics:
+		m.topicsScroll.wheel(msg)
+	}
+	return m, nil
+}
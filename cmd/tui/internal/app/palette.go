@@ -0,0 +1,383 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package app
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// paletteAction is one entry in the ctrl+k command palette. It mirrors the
+// portal's action registry (services/portal/internal/actions): an ID, a
+// title and description to show, and keywords to match against.
+type paletteAction struct {
+	id          string
+	title       string
+	description string
+	keywords    []string
+	run         func(Model) (Model, tea.Cmd)
+}
+
+// paletteActions returns every action the palette offers.
+func paletteActions() []paletteAction {
+	return []paletteAction{
+		{
+			id:          "nav-dashboard",
+			title:       "Dashboard",
+			description: "Server info and menu",
+			keywords:    []string{"home", "menu", "server", "info"},
+			run: func(m Model) (Model, tea.Cmd) {
+				m.state = stateDashboard
+				return m, m.doServerInfo()
+			},
+		},
+		{
+			id:          "nav-db",
+			title:       "Hermit DB",
+			description: "Open the DB console",
+			keywords:    []string{"database", "console", "kv", "sql"},
+			run:         dbPrompt(""),
+		},
+		{
+			id:          "nav-secrets",
+			title:       "Secrets",
+			description: "Open the Secrets panel",
+			keywords:    []string{"secrets", "wall", "exposed", "leaderboard"},
+			run:         openSecrets,
+		},
+		{
+			id:          "run-benchmark",
+			title:       "Run benchmark",
+			description: "Measure gRPC round trips with the current settings",
+			keywords:    []string{"benchmark", "latency", "bench", "perf"},
+			run: func(m Model) (Model, tea.Cmd) {
+				m.state = stateBenchmark
+				return m.startBenchmark()
+			},
+		},
+		{
+			id:          "kv-get",
+			title:       "kv:get",
+			description: "Read a key from the document store",
+			keywords:    []string{"kv", "get", "read", "key"},
+			run:         dbPrompt("kv:get "),
+		},
+		{
+			id:          "kv-set",
+			title:       "kv:set",
+			description: "Write a key to the document store",
+			keywords:    []string{"kv", "set", "write", "put"},
+			run:         dbPrompt("kv:set "),
+		},
+		{
+			id:          "kv-setex",
+			title:       "kv:setex",
+			description: "Write a key that expires after a TTL",
+			keywords:    []string{"kv", "set", "ttl", "expire", "cache"},
+			run:         dbPrompt("kv:setex "),
+		},
+		{
+			id:          "kv-del",
+			title:       "kv:del",
+			description: "Delete a key from the document store",
+			keywords:    []string{"kv", "del", "delete", "remove", "key"},
+			run:         dbPrompt("kv:del "),
+		},
+		{
+			id:          "kv-list",
+			title:       "kv:list",
+			description: "List document store keys",
+			keywords:    []string{"kv", "list", "keys"},
+			run: func(m Model) (Model, tea.Cmd) {
+				m, cmd := openDB(m, "")
+				return m, tea.Batch(cmd, m.executeDBCommand("kv:list"))
+			},
+		},
+		{
+			id:          "kv-browse",
+			title:       "KV Browser",
+			description: "Page through document store keys and preview values",
+			keywords:    []string{"kv", "browse", "keys", "values", "preview"},
+			run:         openKV,
+		},
+		{
+			id:          "health",
+			title:       "Health",
+			description: "Watch hermit's RTT, uptime and failures",
+			keywords:    []string{"health", "watchdog", "ping", "latency", "uptime", "rtt"},
+			run:         openHealth,
+		},
+		{
+			id:          "portal",
+			title:       "Portal",
+			description: "Sign in to the portal and review giveaway claims",
+			keywords:    []string{"portal", "claims", "giveaway", "confirm", "admin"},
+			run:         openPortal,
+		},
+		{
+			id:          "logs",
+			title:       "Logs",
+			description: "Tail a service's log with level filter and search",
+			keywords:    []string{"logs", "tail", "log", "stream", "grep", "errors"},
+			run:         openLogs,
+		},
+		{
+			id:          "metrics",
+			title:       "Metrics",
+			description: "Watch hermit's RPC rates and latencies, store sizes and connections",
+			keywords:    []string{"metrics", "stats", "rpc", "latency", "prometheus", "connections"},
+			run:         openMetrics,
+		},
+		{
+			id:          "topics",
+			title:       "Topics",
+			description: "Chat with other clients over hermit's pub/sub topics",
+			keywords:    []string{"topics", "pubsub", "publish", "subscribe", "chat", "messages"},
+			run:         openTopics,
+		},
+		{
+			id:          "sql-query",
+			title:       "sql:query",
+			description: "Query the relational store",
+			keywords:    []string{"sql", "query", "rows", "select"},
+			run:         dbPrompt("sql:query "),
+		},
+		{
+			id:          "sql-update",
+			title:       "sql:update",
+			description: "Set the value of every row with a key",
+			keywords:    []string{"sql", "update", "rows", "set"},
+			run:         dbPrompt("sql:update "),
+		},
+		{
+			id:          "sql-delete",
+			title:       "sql:delete",
+			description: "Delete every row with a key",
+			keywords:    []string{"sql", "delete", "rows", "remove"},
+			run:         dbPrompt("sql:delete "),
+		},
+		{
+			id:          "db-snapshot",
+			title:       "db:snapshot",
+			description: "Save hermit's stores to a local file",
+			keywords:    []string{"db", "snapshot", "backup", "save", "dump"},
+			run:         dbPrompt("db:snapshot "),
+		},
+		{
+			id:          "db-restore",
+			title:       "db:restore",
+			description: "Replace hermit's stores with a saved snapshot",
+			keywords:    []string{"db", "restore", "backup", "load", "migrate"},
+			run:         dbPrompt("db:restore "),
+		},
+		{
+			id:          "limits-set",
+			title:       "limits:set",
+			description: "Change hermit's rate limit for writes or benchmarks",
+			keywords:    []string{"limits", "rate", "quota", "throttle", "admin"},
+			run:         dbPrompt("limits:set "),
+		},
+		{
+			id:          "replication",
+			title:       "replication",
+			description: "Show whether hermit is a primary or a follower, and its lag",
+			keywords:    []string{"replication", "follower", "primary", "replica", "lag", "ha"},
+			run:         dbPrompt("replication"),
+		},
+		{
+			id:          "bucket-use",
+			title:       "bucket:use",
+			description: "Send document store commands to another bucket",
+			keywords:    []string{"bucket", "namespace", "keyspace", "tenant", "switch"},
+			run:         dbPrompt("bucket:use "),
+		},
+		{
+			id:          "diag",
+			title:       "diag",
+			description: "List hermit's slowest recent calls and largest values",
+			keywords:    []string{"diagnostics", "slow", "latency", "large", "values", "debug"},
+			run:         dbPrompt("diag"),
+		},
+		{
+			id:          "bench-tcp",
+			title:       "bench:tcp",
+			description: "Compare hermit's TCP protocol with gRPC for the same calls",
+			keywords:    []string{"benchmark", "tcp", "grpc", "latency", "compare", "protocol"},
+			run:         dbPrompt("bench:tcp"),
+		},
+		{
+			id:          "secret-submit",
+			title:       "Submit secret",
+			description: "Type a secret to admit",
+			keywords:    []string{"secret", "submit", "admit", "confess"},
+			run:         openSecrets,
+		},
+		{
+			id:          "export",
+			title:       "Export panel",
+			description: "Save the current panel to a JSON file",
+			keywords:    []string{"export", "save", "dump", "share", "file"},
+			run: func(m Model) (Model, tea.Cmd) {
+				return m, m.doExport()
+			},
+		},
+		{
+			id:          "theme",
+			title:       "Next theme",
+			description: "Cycle default, light, high-contrast and solarized",
+			keywords:    []string{"theme", "colors", "colours", "light", "dark", "contrast", "solarized"},
+			run:         Model.cycleTheme,
+		},
+		{
+			id:          "layout",
+			title:       "Toggle side-by-side",
+			description: "Put the panels side by side on wide terminals; ctrl+arrows resize",
+			keywords:    []string{"layout", "split", "side", "horizontal", "vertical", "resize"},
+			run: func(m Model) (Model, tea.Cmd) {
+				m.sideBySide = !m.sideBySide
+				return m, nil
+			},
+		},
+		{
+			id:          "quit",
+			title:       "Quit",
+			description: "Close the connection and exit",
+			keywords:    []string{"quit", "exit", "close"},
+			run: func(m Model) (Model, tea.Cmd) {
+				if m.hermit != nil {
+					m.hermit.Close()
+				}
+				return m, tea.Quit
+			},
+		},
+	}
+}
+
+// dbPrompt opens the DB console with prefix already typed.
+func dbPrompt(prefix string) func(Model) (Model, tea.Cmd) {
+	return func(m Model) (Model, tea.Cmd) {
+		return openDB(m, prefix)
+	}
+}
+
+// searchPalette returns the actions matching query, best match first. An
+// empty query returns every action in registry order.
+func searchPalette(actions []paletteAction, query string) []paletteAction {
+	q := strings.ToLower(strings.TrimSpace(query))
+	if q == "" {
+		return actions
+	}
+	type scored struct {
+		a     paletteAction
+		score int
+	}
+	var hits []scored
+	for _, a := range actions {
+		best := fuzzyScore(strings.ToLower(a.title), q)
+		for _, kw := range a.keywords {
+			// Keyword hits rank just below equally good title hits.
+			if s := fuzzyScore(kw, q) - 1; s > best {
+				best = s
+			}
+		}
+		if best > 0 {
+			hits = append(hits, scored{a, best})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].score > hits[j].score })
+	out := make([]paletteAction, len(hits))
+	for i, h := range hits {
+		out[i] = h.a
+	}
+	return out
+}
+
+// fuzzyScore scores how well q matches s as a subsequence; 0 means no match.
+// Consecutive runs and matches at word starts score higher, so "kg" prefers
+// "kv:get" over "kv:list".
+func fuzzyScore(s, q string) int {
+	if strings.HasPrefix(s, q) {
+		return 100 + len(q)
+	}
+	rs := []rune(s)
+	score, run, i := 0, 0, 0
+	for _, qc := range q {
+		found := false
+		for ; i < len(rs); i++ {
+			if rs[i] != qc {
+				run = 0
+				continue
+			}
+			score++
+			if run > 0 {
+				score += 2
+			}
+			if i == 0 || !unicode.IsLetter(rs[i-1]) {
+				score += 3
+			}
+			run++
+			i++
+			found = true
+			break
+		}
+		if !found {
+			return 0
+		}
+	}
+	return score
+}
+
+// palette is the ctrl+k overlay's state.
+type palette struct {
+	open  bool
+	query string
+	idx   int
+}
+
+// paletteAvailable reports whether ctrl+k may open the palette: only once
+// logged in, since every action needs a connection.
+func (m Model) paletteAvailable() bool {
+	switch m.state {
+	case stateLogin, stateConnecting, stateError:
+		return false
+	}
+	return true
+}
+
+func (m Model) handlePaletteKey(k tea.Key) (tea.Model, tea.Cmd) {
+	matches := searchPalette(paletteActions(), m.palette.query)
+	switch {
+	case k.Code == tea.KeyEscape, k.Code == 'k' && k.Mod == tea.ModCtrl:
+		m.palette = palette{}
+	case k.Code == tea.KeyUp, k.Code == 'p' && k.Mod == tea.ModCtrl:
+		if m.palette.idx > 0 {
+			m.palette.idx--
+		}
+	case k.Code == tea.KeyDown, k.Code == 'n' && k.Mod == tea.ModCtrl:
+		if m.palette.idx < len(matches)-1 {
+			m.palette.idx++
+		}
+	case k.Code == tea.KeyEnter:
+		if len(matches) == 0 {
+			return m, nil
+		}
+		a := matches[m.palette.idx]
+		m.palette = palette{}
+		return a.run(m)
+	case k.Code == tea.KeyBackspace:
+		if len(m.palette.query) > 0 {
+			m.palette.query = m.palette.query[:len(m.palette.query)-1]
+			m.palette.idx = 0
+		}
+	default:
+		if k.Text != "" {
+			m.palette.query += k.Text
+			m.palette.idx = 0
+		}
+	}
+	return m, nil
+}
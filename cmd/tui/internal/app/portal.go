@@ -0,0 +1,305 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// portalState is the Portal panel: a magic-token sign-in, then the pending
+// giveaway claims an admin can confirm or cancel.
+type portalState struct {
+	token   string      // magic token being typed
+	user    *PortalUser // nil until signed in
+	busy    bool        // a sign-in or claim action is in flight
+	claims  []PortalClaim
+	idx     int
+	err     error
+	log     []secretsLogEntry
+	loading bool
+}
+
+type portalLoginMsg struct {
+	user PortalUser
+	err  error
+}
+
+type portalClaimsMsg struct {
+	claims []PortalClaim
+	err    error
+}
+
+type portalActionMsg struct {
+	claim  PortalClaim
+	status ClaimStatus
+	err    error
+}
+
+// WithPortal sets the portal client for the Portal panel.
+func (m Model) WithPortal(p PortalClient) Model {
+	m.portalClient = p
+	return m
+}
+
+func openPortal(m Model) (Model, tea.Cmd) {
+	m.state = statePortal
+	if m.portal.user == nil || !m.portal.user.IsAdmin {
+		return m, nil
+	}
+	m.portal.loading = true
+	return m, m.doPortalClaims()
+}
+
+func (m Model) doPortalLogin(token string) tea.Cmd {
+	p := m.portalClient
+	return func() tea.Msg {
+		if p == nil {
+			return portalLoginMsg{err: fmt.Errorf("portal client not configured")}
+		}
+		user, err := p.MagicLogin(token)
+		return portalLoginMsg{user: user, err: err}
+	}
+}
+
+func (m Model) doPortalClaims() tea.Cmd {
+	p := m.portalClient
+	return func() tea.Msg {
+		claims, err := p.PendingClaims()
+		return portalClaimsMsg{claims: claims, err: err}
+	}
+}
+
+func (m Model) doPortalAction(c PortalClaim, status ClaimStatus) tea.Cmd {
+	p := m.portalClient
+	return func() tea.Msg {
+		return portalActionMsg{claim: c, status: status, err: p.SetClaimStatus(c.ID, status)}
+	}
+}
+
+func (m Model) handlePortalLogin(msg portalLoginMsg) (tea.Model, tea.Cmd) {
+	m.portal.busy = false
+	if msg.err != nil {
+		m.portal.logf(true, "sign-in failed: %v", msg.err)
+		return m, nil
+	}
+	user := msg.user
+	m.portal.user = &user
+	m.portal.token = ""
+	m.portal.logf(false, "signed in as %s", portalName(user))
+	if !user.IsAdmin {
+		return m, nil
+	}
+	m.portal.loading = true
+	return m, m.doPortalClaims()
+}
+
+func (m Model) handlePortalClaims(msg portalClaimsMsg) (tea.Model, tea.Cmd) {
+	m.portal.loading = false
+	m.portal.err = msg.err
+	if msg.err == nil {
+		m.portal.claims = msg.claims
+		m.portal.idx = clampInt(m.portal.idx, 0, max(0, len(msg.claims)-1))
+	}
+	return m, nil
+}
+
+func (m Model) handlePortalAction(msg portalActionMsg) (tea.Model, tea.Cmd) {
+	m.portal.busy = false
+	verb := "confirmed"
+	if msg.status == ClaimCancelled {
+		verb = "cancelled"
+	}
+	if msg.err != nil {
+		m.portal.logf(true, "%s: %v", msg.claim.ClaimerName, msg.err)
+		return m.notify(fmt.Sprintf("claim not %s: %v", verb, msg.err), true)
+	}
+	m.portal.logf(false, "%s claim by %s (%s)", verb, msg.claim.ClaimerName, msg.claim.ID)
+	m, toastCmd := m.notify(fmt.Sprintf("claim by %s %s", msg.claim.ClaimerName, verb), false)
+	m.portal.loading = true
+	return m, tea.Batch(m.doPortalClaims(), toastCmd)
+}
+
+func (s *portalState) logf(isErr bool, format string, args ...any) {
+	s.log = append(s.log, secretsLogEntry{ts: time.Now().Format("15:04:05"), text: fmt.Sprintf(format, args...), isErr: isErr})
+	if len(s.log) > maxHistory {
+		s.log = s.log[len(s.log)-maxHistory:]
+	}
+}
+
+func portalName(u PortalUser) string {
+	switch {
+	case u.Name != "":
+		return u.Name
+	case u.Username != "":
+		return u.Username
+	}
+	return u.Email
+}
+
+func (m Model) handlePortalKey(k tea.Key) (tea.Model, tea.Cmd) {
+	if m.portal.user == nil {
+		return m.handlePortalTokenKey(k)
+	}
+	last := max(0, len(m.portal.claims)-1)
+	switch {
+	case m.pressed(k, m.keys.back, m.keys.quit):
+		m.state = stateDashboard
+	case m.pressed(k, m.keys.up):
+		m.portal.idx = max(0, m.portal.idx-1)
+	case m.pressed(k, m.keys.down):
+		m.portal.idx = min(last, m.portal.idx+1)
+	case m.pressed(k, m.keys.refresh):
+		if m.portal.user.IsAdmin {
+			m.portal.loading = true
+			return m, m.doPortalClaims()
+		}
+	case m.pressed(k, m.keys.confirm), m.pressed(k, m.keys.cancelClaim):
+		status := ClaimConfirmed
+		if m.pressed(k, m.keys.cancelClaim) {
+			status = ClaimCancelled
+		}
+		if m.portal.busy || m.portal.idx >= len(m.portal.claims) {
+			return m, nil
+		}
+		m.portal.busy = true
+		return m, m.doPortalAction(m.portal.claims[m.portal.idx], status)
+	}
+	return m, nil
+}
+
+// handlePortalTokenKey edits the magic token before sign-in.
+func (m Model) handlePortalTokenKey(k tea.Key) (tea.Model, tea.Cmd) {
+	switch {
+	case m.pressed(k, m.keys.back):
+		m.state = stateDashboard
+	case k.Code == tea.KeyEnter:
+		if strings.TrimSpace(m.portal.token) == "" || m.portal.busy {
+			return m, nil
+		}
+		m.portal.busy = true
+		return m, m.doPortalLogin(m.portal.token)
+	case k.Code == tea.KeyBackspace:
+		if m.portal.token != "" {
+			_, size := utf8.DecodeLastRuneInString(m.portal.token)
+			m.portal.token = m.portal.token[:len(m.portal.token)-size]
+		}
+	default:
+		if k.Text != "" {
+			m.portal.token += k.Text
+		}
+	}
+	return m, nil
+}
+
+func (m Model) renderPortalPanel(innerW, maxLines int) string {
+	var b strings.Builder
+	b.WriteString(m.st.title.Render(m.tr("Portal")))
+	p := m.portal
+	if p.user == nil {
+		b.WriteString("\n\n")
+		b.WriteString(m.tr("Paste a magic login token or link from the portal.") + "\n\n")
+		b.WriteString(m.st.prompt.Render(m.tr("token> ")))
+		// The token is a credential; don't put it on screen.
+		b.WriteString(strings.Repeat("•", min(utf8.RuneCountInString(p.token), max(1, innerW-12))))
+		if p.busy {
+			b.WriteString(m.st.dim.Render(m.tr("  signing in...")))
+		} else {
+			b.WriteString("█")
+		}
+		return b.String()
+	}
+
+	b.WriteString(m.st.dim.Render("  " + portalName(*p.user)))
+	b.WriteString("\n\n")
+	switch {
+	case !p.user.IsAdmin:
+		b.WriteString(m.st.err.Render(m.tr("This account is not an admin; claims are admin only.")))
+		return b.String()
+	case errors.Is(p.err, ErrUnsupported):
+		b.WriteString(m.st.dim.Render(m.tr("This portal was built without the giveaway, so it has no claims.")))
+		return b.String()
+	case p.err != nil:
+		b.WriteString(m.st.err.Render(p.err.Error()))
+		return b.String()
+	case p.loading && p.claims == nil:
+		b.WriteString(m.st.dim.Render(m.tr("loading claims...")))
+		return b.String()
+	case len(p.claims) == 0:
+		b.WriteString(m.st.dim.Render(m.tr("No pending claims.")))
+		return b.String()
+	}
+
+	b.WriteString(m.st.dim.Render(m.trf("%d pending claims", len(p.claims))))
+	b.WriteString("\n")
+	rows := max(1, maxLines-4)
+	start := max(0, min(p.idx-rows/2, len(p.claims)-rows))
+	for i := start; i < min(len(p.claims), start+rows); i++ {
+		c := p.claims[i]
+		var created string
+		if !c.CreatedAt.IsZero() {
+			created = c.CreatedAt.Local().Format("Jan 02 15:04")
+		}
+		line := truncate(fmt.Sprintf("%-20s $%6.2f  %s", truncate(c.ClaimerName, 20), c.DeliveryFee, created), innerW-8)
+		if i == p.idx {
+			b.WriteString(m.st.selected.Render(" ▸ " + line + " "))
+		} else {
+			b.WriteString("   " + line)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func (m Model) renderPortalDetailPanel(innerW, maxLines int) string {
+	var b strings.Builder
+	p := m.portal
+	if p.user != nil && p.idx < len(p.claims) {
+		c := p.claims[p.idx]
+		b.WriteString(m.st.title.Render(m.tr("Claim")))
+		b.WriteString(m.st.dim.Render("  " + c.ID))
+		b.WriteString("\n")
+		for _, kv := range [][2]string{
+			{"item", c.ItemID},
+			{"name", c.ClaimerName},
+			{"email", c.ClaimerEmail},
+			{"phone", c.ClaimerPhone},
+			{"fee", fmt.Sprintf("$%.2f", c.DeliveryFee)},
+			{"notes", c.Notes},
+		} {
+			if kv[1] != "" {
+				b.WriteString(fmt.Sprintf("%-6s %s\n", m.tr(kv[0]), m.st.value.Render(truncate(kv[1], innerW-11))))
+			}
+		}
+	} else {
+		b.WriteString(m.st.title.Render(m.tr("Log")))
+		b.WriteString("\n")
+	}
+
+	logRows := max(0, maxLines-strings.Count(b.String(), "\n")-3)
+	logs := p.log
+	if len(logs) > logRows {
+		logs = logs[len(logs)-logRows:]
+	}
+	for _, e := range logs {
+		text := truncate(e.text, innerW-14)
+		if e.isErr {
+			text = m.st.err.Render(text)
+		}
+		b.WriteString(m.st.dim.Render("["+e.ts+"] ") + text + "\n")
+	}
+
+	b.WriteString("\n")
+	if p.user == nil {
+		b.WriteString(m.st.dim.Render(m.tr("[enter] sign in  [esc] back")))
+	} else {
+		b.WriteString(m.st.dim.Render(m.tr("[↑/↓] select  [c] confirm  [x] cancel claim  [r] reload  [esc] back")))
+	}
+	return b.String()
+}
@@ -0,0 +1,223 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PortalClient is the interface for the portal JSON API, signed in with a
+// magic login token. Tests inject a mock.
+type PortalClient interface {
+	MagicLogin(token string) (PortalUser, error)
+	PendingClaims() ([]PortalClaim, error)
+	SetClaimStatus(id string, status ClaimStatus) error
+}
+
+// ErrNotAdmin is returned when the signed-in portal user can't manage claims.
+var ErrNotAdmin = errors.New("admin role required")
+
+// --- Domain types (mirrors nexus/services/portal/pkg/models) ---
+
+// PortalUser is the portal's /api/me response.
+type PortalUser struct {
+	ID       string `json:"id"`
+	Email    string `json:"email"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+	IsAdmin  bool   `json:"is_admin"`
+}
+
+// ClaimStatus is where a giveaway claim is in its lifecycle.
+type ClaimStatus string
+
+const (
+	ClaimPending   ClaimStatus = "pending"
+	ClaimConfirmed ClaimStatus = "confirmed"
+	ClaimCancelled ClaimStatus = "cancelled"
+)
+
+// PortalClaim is a request to receive a giveaway item, as the portal's
+// /api/admin/claims serves it. Only giveaway builds of the portal serve
+// claims; others answer 404, which PendingClaims returns as ErrUnsupported.
+type PortalClaim struct {
+	ID           string      `json:"id"`
+	ItemID       string      `json:"item_id"`
+	ClaimerName  string      `json:"claimer_name"`
+	ClaimerEmail string      `json:"claimer_email"`
+	ClaimerPhone string      `json:"claimer_phone"`
+	DeliveryFee  float64     `json:"delivery_fee"`
+	Status       ClaimStatus `json:"status"`
+	Notes        string      `json:"notes"`
+	CreatedAt    time.Time   `json:"created_at"`
+}
+
+// --- HTTP implementation ---
+
+type httpPortalClient struct {
+	baseURL    string
+	httpClient *http.Client
+
+	mu      sync.Mutex
+	session string // the portal's session cookie, once signed in
+}
+
+func NewPortalClient(baseURL string) PortalClient {
+	return &httpPortalClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+			// The magic link answers with a redirect to the web dashboard;
+			// we only want its session cookie.
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+	}
+}
+
+// magicToken accepts either a bare token or a whole magic login link.
+func magicToken(s string) string {
+	s = strings.TrimSpace(s)
+	if u, err := url.Parse(s); err == nil && u.Query().Get("token") != "" {
+		return u.Query().Get("token")
+	}
+	return s
+}
+
+// request builds a request carrying the session cookie.
+func (c *httpPortalClient) request(ctx context.Context, method, path string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.session != "" {
+		req.AddCookie(&http.Cookie{Name: "session", Value: c.session})
+	}
+	return req, nil
+}
+
+// statusErr maps the statuses every authenticated call shares.
+func statusErr(resp *http.Response, what string) error {
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+		return nil
+	case http.StatusUnauthorized:
+		return fmt.Errorf("portal %s: session expired, sign in again", what)
+	case http.StatusForbidden:
+		return ErrNotAdmin
+	case http.StatusNotFound, http.StatusMethodNotAllowed:
+		return ErrUnsupported
+	}
+	return fmt.Errorf("portal %s: %s", what, resp.Status)
+}
+
+// MagicLogin trades a magic login token for a session and returns who it
+// signed in.
+func (c *httpPortalClient) MagicLogin(token string) (PortalUser, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	q := url.Values{"token": {magicToken(token)}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/auth/magic?"+q.Encode(), nil)
+	if err != nil {
+		return PortalUser{}, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return PortalUser{}, fmt.Errorf("portal login: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized {
+		return PortalUser{}, fmt.Errorf("portal login: invalid or expired token")
+	}
+	var session string
+	for _, ck := range resp.Cookies() {
+		if ck.Name == "session" {
+			session = ck.Value
+		}
+	}
+	if session == "" {
+		return PortalUser{}, fmt.Errorf("portal login: %s (no session)", resp.Status)
+	}
+	c.mu.Lock()
+	c.session = session
+	c.mu.Unlock()
+
+	req, err = c.request(ctx, http.MethodGet, "/api/me")
+	if err != nil {
+		return PortalUser{}, err
+	}
+	resp, err = c.httpClient.Do(req)
+	if err != nil {
+		return PortalUser{}, fmt.Errorf("portal me: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := statusErr(resp, "me"); err != nil {
+		return PortalUser{}, err
+	}
+	var user PortalUser
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return PortalUser{}, fmt.Errorf("portal me decode: %w", err)
+	}
+	return user, nil
+}
+
+func (c *httpPortalClient) PendingClaims() ([]PortalClaim, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := c.request(ctx, http.MethodGet, "/api/admin/claims?status="+string(ClaimPending))
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("portal claims: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := statusErr(resp, "claims"); err != nil {
+		return nil, err
+	}
+	var claims []PortalClaim
+	if err := json.NewDecoder(resp.Body).Decode(&claims); err != nil {
+		return nil, fmt.Errorf("portal claims decode: %w", err)
+	}
+	return claims, nil
+}
+
+// SetClaimStatus confirms or cancels a claim.
+func (c *httpPortalClient) SetClaimStatus(id string, status ClaimStatus) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var action string
+	switch status {
+	case ClaimConfirmed:
+		action = "confirm"
+	case ClaimCancelled:
+		action = "cancel"
+	default:
+		return fmt.Errorf("portal claim: can't set status %q", status)
+	}
+	req, err := c.request(ctx, http.MethodPost, "/api/admin/claims/"+url.PathEscape(id)+"/"+action)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("portal claim %s: %w", action, err)
+	}
+	resp.Body.Close()
+	return statusErr(resp, "claim "+action)
+}
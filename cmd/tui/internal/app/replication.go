@@ -0,0 +1,65 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+
+	pb "github.com/jredh-dev/nexus/cmd/tui/proto"
+)
+
+// ReplicationReporter is a HermitClient that can say whether hermit is a
+// primary or a follower, and how far behind (the ReplicationStatus RPC).
+type ReplicationReporter interface {
+	ReplicationStatus() (*pb.ReplicationStatusResponse, error)
+}
+
+// fmtReplication sums up a ReplicationStatus in a line: a primary's oplog
+// position and its followers' lag, or how far a follower trails its
+// primary.
+func fmtReplication(r *pb.ReplicationStatusResponse) string {
+	if r.Role != "follower" {
+		if len(r.Followers) == 0 {
+			return fmt.Sprintf("primary at %d, no followers", r.OplogSeq)
+		}
+		followers := make([]string, len(r.Followers))
+		for i, f := range r.Followers {
+			followers[i] = fmt.Sprintf("%s %d behind", f.Name, f.Lag)
+		}
+		return fmt.Sprintf("primary at %d, followers: %s", r.OplogSeq, strings.Join(followers, ", "))
+	}
+	if !r.Connected {
+		why := r.Error
+		if why == "" {
+			why = "connecting"
+		}
+		return fmt.Sprintf("follower of %s, not streaming: %s", r.Primary, why)
+	}
+	heard := time.Duration(r.LastContactMs) * time.Millisecond
+	return fmt.Sprintf("follower of %s, applied %d of %d (%d behind), heard from %s ago",
+		r.Primary, r.AppliedSeq, r.PrimarySeq, r.Lag, heard)
+}
+
+// replicationCommand runs the replication console command.
+func (m Model) replicationCommand(raw string) tea.Cmd {
+	h := m.hermit
+	return func() tea.Msg {
+		if h == nil {
+			return dbCmdResultMsg{cmd: raw, err: fmt.Errorf("not connected")}
+		}
+		rr, ok := h.(ReplicationReporter)
+		if !ok {
+			return dbCmdResultMsg{cmd: raw, err: ErrUnsupported}
+		}
+		resp, err := rr.ReplicationStatus()
+		if err != nil {
+			return dbCmdResultMsg{cmd: raw, err: err}
+		}
+		return dbCmdResultMsg{cmd: raw, output: fmtReplication(resp), data: resp}
+	}
+}
@@ -0,0 +1,187 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// maxHistory caps the DB console history and the secrets log. Both panels
+// scroll, so this only bounds memory.
+const maxHistory = 500
+
+// scrollback is a scrollable history pane. While following, it stays pinned
+// to the newest line as entries arrive; scrolling up stops following so the
+// view holds still while reading.
+type scrollback struct {
+	vp     viewport.Model
+	follow bool
+	lines  []string // content as last synced, one entry per history item
+}
+
+func newScrollback() scrollback {
+	vp := viewport.New()
+	vp.SoftWrap = true
+	vp.MouseWheelEnabled = true
+	return scrollback{vp: vp, follow: true}
+}
+
+// sync resizes the pane and replaces its content.
+func (s *scrollback) sync(width, height int, lines []string) {
+	if height < 1 {
+		height = 1
+	}
+	s.vp.SetWidth(width)
+	s.vp.SetHeight(height)
+	s.vp.SetContentLines(lines)
+	s.lines = lines
+	if s.follow {
+		s.vp.GotoBottom()
+	}
+}
+
+func (s *scrollback) pageUp() {
+	s.vp.PageUp()
+	s.follow = false
+}
+
+func (s *scrollback) pageDown() {
+	s.vp.PageDown()
+}
+
+func (s *scrollback) lineUp() {
+	s.vp.ScrollUp(1)
+	s.follow = false
+}
+
+func (s *scrollback) lineDown() {
+	s.vp.ScrollDown(1)
+}
+
+func (s *scrollback) wheel(msg tea.MouseWheelMsg) {
+	s.vp, _ = s.vp.Update(msg)
+	if msg.Button == tea.MouseWheelUp {
+		s.follow = false
+	}
+}
+
+func (s *scrollback) toggleFollow() {
+	s.follow = !s.follow
+	if s.follow {
+		s.vp.GotoBottom()
+	}
+}
+
+// lineAt returns the index of the content line shown on visible row row,
+// accounting for soft-wrapped lines taking more than one row.
+func (s scrollback) lineAt(row int) (int, bool) {
+	if row < 0 || row >= s.vp.Height() {
+		return 0, false
+	}
+	target := s.vp.YOffset() + row
+	width := max(1, s.vp.Width())
+	y := 0
+	for i, l := range s.lines {
+		h := max(1, (ansi.StringWidth(l)+width-1)/width)
+		if target < y+h {
+			return i, true
+		}
+		y += h
+	}
+	return 0, false
+}
+
+// status describes the scroll position for a panel header.
+func (s scrollback) status() string {
+	if s.follow {
+		return "following"
+	}
+	return fmt.Sprintf("%3.0f%%", s.vp.ScrollPercent()*100)
+}
+
+func (s scrollback) view() string {
+	return s.vp.View()
+}
+
+// scrollKey applies the shared scrolling keys to s and reports whether k
+// was one of them: PgUp/PgDn page, ctrl+f toggles following the tail.
+func (m Model) scrollKey(s *scrollback, k tea.Key) bool {
+	switch {
+	case m.pressed(k, m.keys.pageUp):
+		s.pageUp()
+	case m.pressed(k, m.keys.pageDown):
+		s.pageDown()
+	case m.pressed(k, m.keys.follow):
+		s.toggleFollow()
+	default:
+		return false
+	}
+	return true
+}
+
+// dbHistoryLines renders the DB console history for the scrollback.
+func (m Model) dbHistoryLines() []string {
+	var lines []string
+	for _, h := range m.dbHistory {
+		style := m.st.dim
+		if h.isErr {
+			style = m.st.err
+		}
+		lines = append(lines, style.Render(fmt.Sprintf("[%s] %s → %s", h.ts, h.cmd, h.output)))
+	}
+	return lines
+}
+
+// secretsLogLines renders the secrets log for the scrollback.
+func (m Model) secretsLogLines() []string {
+	var lines []string
+	for _, e := range m.secretsLog {
+		style := m.st.dim
+		if e.isErr {
+			style = m.st.err
+		}
+		lines = append(lines, style.Render(fmt.Sprintf("[%s] %s", e.ts, e.text)))
+	}
+	return lines
+}
+
+// dbHistoryTop is the content row of the DB stats panel where the history
+// pane starts: below the stats, a margin and the "Recent" title.
+func (m Model) dbHistoryTop() int {
+	return strings.Count(m.dbStatsHeader(), "\n") + 2
+}
+
+// dbHistoryHeight is the number of lines the DB stats panel leaves for its
+// history.
+func (m Model) dbHistoryHeight(maxLines int) int {
+	return maxLines - m.dbHistoryTop()
+}
+
+// secretsLogHeight is the number of lines the Secrets panel gives its log.
+func secretsLogHeight(maxLines int) int {
+	h := maxLines / 3
+	if h < 2 {
+		h = 2
+	}
+	return h
+}
+
+// syncScrollbacks refreshes the scrollable panes after any update, since
+// both their content and the terminal size may have changed.
+func (m *Model) syncScrollbacks() {
+	if m.width == 0 {
+		return
+	}
+	l := m.layout()
+	m.dbScroll.sync(l.topW, m.dbHistoryHeight(l.topH), m.dbHistoryLines())
+	m.secretsScroll.sync(l.topW, secretsLogHeight(l.topH), m.secretsLogLines())
+	m.logsScroll.sync(l.topW, logsHeight(l.topH), m.logsLines())
+	m.topicsScroll.sync(l.topW, topicsHeight(l.topH), m.topicsLines())
+	m.syncSQLTable()
+}
@@ -6,8 +6,11 @@ package app
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -19,8 +22,14 @@ type SecretsClient interface {
 	List() ([]Secret, error)
 	Submit(value, submittedBy string) (*SubmitResult, error)
 	Stats() (SecretsStats, error)
+	Exposed() (ExposedPage, error)
+	Leaderboard() ([]LeaderboardEntry, error)
 }
 
+// ErrUnsupported is returned when the secrets server doesn't implement an
+// endpoint yet, so the UI can say so instead of showing an error.
+var ErrUnsupported = errors.New("not supported by this server")
+
 // --- Domain types (mirrors nexus/services/secrets/internal/store) ---
 
 type Secret struct {
@@ -45,9 +54,35 @@ type SecretsStats struct {
 type SubmitResult struct {
 	Secret  *Secret `json:"secret"`
 	WasNew  bool    `json:"was_new"`
+	Lens    string  `json:"lens,omitempty"` // lens that matched, when not new
 	Message string  `json:"message"`
 }
 
+// ExposedPage is one page of the wall of exposed (no longer secret) values.
+type ExposedPage struct {
+	Entries []ExposedEntry `json:"exposed"`
+	Total   int            `json:"total"` // exposed values across all pages
+	Page    int            `json:"page"`
+	Pages   int            `json:"pages"`
+}
+
+// ExposedEntry is one value on the wall.
+type ExposedEntry struct {
+	Value       string    `json:"value"`
+	Count       int       `json:"count"`
+	Lens        string    `json:"lens,omitempty"` // empty from servers that predate lens reporting
+	LastAdmitAt time.Time `json:"last_admit_at"`
+}
+
+// LeaderboardEntry ranks a submitter. The secrets service does not publish a
+// leaderboard yet; this is the shape the TUI expects once it does.
+type LeaderboardEntry struct {
+	SubmittedBy string `json:"submitted_by"`
+	Secrets     int    `json:"secrets"` // submissions still secret
+	Exposed     int    `json:"exposed"` // submissions since exposed
+	Score       int    `json:"score"`
+}
+
 // --- HTTP implementation ---
 
 type httpSecretsClient struct {
@@ -128,3 +163,82 @@ func (c *httpSecretsClient) Stats() (SecretsStats, error) {
 	}
 	return stats, nil
 }
+
+func (c *httpSecretsClient) Exposed() (ExposedPage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/exposed", nil)
+	if err != nil {
+		return ExposedPage{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return ExposedPage{}, fmt.Errorf("secrets exposed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ExposedPage{}, fmt.Errorf("secrets exposed: %s", resp.Status)
+	}
+
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		var page ExposedPage
+		if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+			return ExposedPage{}, fmt.Errorf("secrets exposed decode: %w", err)
+		}
+		return page, nil
+	}
+
+	// Older servers only serve the plain-text wall: values, no lenses.
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return ExposedPage{}, fmt.Errorf("secrets exposed read: %w", err)
+	}
+	page := ExposedPage{
+		Total: headerInt(resp, "X-Exposed-Total"),
+		Page:  headerInt(resp, "X-Exposed-Page"),
+		Pages: headerInt(resp, "X-Exposed-Pages"),
+	}
+	// With nothing exposed the wall serves a placeholder sentence instead.
+	if page.Total > 0 {
+		for _, line := range strings.Split(string(body), "\n") {
+			if line != "" {
+				page.Entries = append(page.Entries, ExposedEntry{Value: line})
+			}
+		}
+	}
+	return page, nil
+}
+
+func (c *httpSecretsClient) Leaderboard() ([]LeaderboardEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/leaderboard", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("secrets leaderboard: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusMethodNotAllowed {
+		return nil, ErrUnsupported
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("secrets leaderboard: %s", resp.Status)
+	}
+
+	var entries []LeaderboardEntry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("secrets leaderboard decode: %w", err)
+	}
+	return entries, nil
+}
+
+func headerInt(resp *http.Response, name string) int {
+	n, _ := strconv.Atoi(resp.Header.Get(name))
+	return n
+}
@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package app
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	pb "github.com/jredh-dev/nexus/cmd/tui/proto"
+)
+
+// Snapshotter is a HermitClient that can copy out and replace the server's
+// stores (the DbSnapshot and DbRestore RPCs). A snapshot is opaque and
+// compressed; it is only good for restoring into a hermit.
+type Snapshotter interface {
+	Snapshot(ctx context.Context, w io.Writer) (int64, error)
+	Restore(ctx context.Context, r io.Reader) (*pb.DbRestoreResponse, error)
+}
+
+// URLRestorer is a HermitClient that can have hermit fetch a snapshot and
+// restore it (the RestoreFromUrl RPC): from its S3 backup bucket by
+// s3://bucket/key, or from any http(s) URL such as a presigned one.
+type URLRestorer interface {
+	RestoreFromURL(url string) (*pb.DbRestoreResponse, error)
+}
+
+// snapshotTimeout bounds db:snapshot and db:restore, which move the whole
+// database.
+const snapshotTimeout = 5 * time.Minute
+
+// snapshotChunk is the most bytes sent in one DbRestore message.
+const snapshotChunk = 64 << 10
+
+// snapshotResult is db:snapshot's data.
+type snapshotResult struct {
+	Path  string `json:"path"`
+	Bytes int64  `json:"bytes"`
+}
+
+// saveSnapshot writes h's snapshot to path. It writes a temporary file
+// first, so a failed snapshot leaves any earlier one at path intact.
+func saveSnapshot(h HermitClient, path string) (int64, error) {
+	s, ok := h.(Snapshotter)
+	if !ok {
+		return 0, ErrUnsupported
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+	defer cancel()
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return 0, err
+	}
+	n, err := s.Snapshot(ctx, f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return 0, err
+	}
+	return n, nil
+}
+
+// isSnapshotURL reports whether db:restore's argument is a URL for hermit
+// to fetch rather than a local file.
+func isSnapshotURL(path string) bool {
+	for _, scheme := range []string{"s3://", "http://", "https://"} {
+		if strings.HasPrefix(path, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// restoreSnapshot replaces h's stores with the snapshot at path, a local
+// file or a URL hermit downloads itself.
+func restoreSnapshot(h HermitClient, path string) (*pb.DbRestoreResponse, error) {
+	if isSnapshotURL(path) {
+		u, ok := h.(URLRestorer)
+		if !ok {
+			return nil, ErrUnsupported
+		}
+		return u.RestoreFromURL(path)
+	}
+	s, ok := h.(Snapshotter)
+	if !ok {
+		return nil, ErrUnsupported
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+	defer cancel()
+	return s.Restore(ctx, f)
+}
@@ -0,0 +1,246 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package app
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/table"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	pb "github.com/jredh-dev/nexus/cmd/tui/proto"
+)
+
+// sqlQueryLimit is how many rows sql:query fetches for the results table
+// unless it sets limit=; offset= reaches the rows past them.
+const sqlQueryLimit = 500
+
+// sqlOrders are sql:query's by= columns.
+var sqlOrders = map[string]pb.SqlQueryRequest_Order{
+	"id":      pb.SqlQueryRequest_ID,
+	"key":     pb.SqlQueryRequest_KEY,
+	"value":   pb.SqlQueryRequest_VALUE,
+	"created": pb.SqlQueryRequest_CREATED,
+}
+
+// parseSQLQuery reads sql:query's arguments: an optional key filter, then
+// value=<text>, by=<column>, desc, offset=<n> and limit=<n> in any order.
+// Both filters match substrings.
+func parseSQLQuery(args []string) (*pb.SqlQueryRequest, error) {
+	req := &pb.SqlQueryRequest{Limit: sqlQueryLimit}
+	for _, a := range args {
+		name, val, isOpt := strings.Cut(a, "=")
+		switch {
+		case a == "desc":
+			req.Desc = true
+		case !isOpt && req.KeyFilter == "":
+			req.KeyFilter = a
+		case !isOpt:
+			return nil, fmt.Errorf("unexpected %q: one key filter, then name=value options", a)
+		case name == "value":
+			req.ValueFilter = val
+		case name == "by":
+			o, ok := sqlOrders[val]
+			if !ok {
+				return nil, fmt.Errorf("by=%s: want id, key, value or created", val)
+			}
+			req.OrderBy = o
+		case name == "offset", name == "limit":
+			n, err := strconv.ParseUint(val, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("%s=%s: not a count", name, val)
+			}
+			if name == "offset" {
+				req.Offset = uint32(n)
+			} else {
+				req.Limit = uint32(n)
+			}
+		default:
+			return nil, fmt.Errorf("unknown option %q", name)
+		}
+	}
+	return req, nil
+}
+
+// sqlSortCol is the column the results table is sorted by.
+type sqlSortCol int
+
+const (
+	sortCreated sqlSortCol = iota // hermit's commit order
+	sortKey
+	sortValue
+	numSQLSortCols
+)
+
+var sqlSortNames = [numSQLSortCols]string{"created", "key", "value"}
+
+// sqlResults is the last sql:query that returned rows.
+type sqlResults struct {
+	query     string
+	rows      []*pb.SqlRow
+	committed uint64
+	pending   uint64
+	sortBy    sqlSortCol
+	desc      bool
+	table     table.Model
+}
+
+// showSQLResults opens the results table for resp, sorted as req asked.
+func (m Model) showSQLResults(query string, req *pb.SqlQueryRequest, resp *pb.SqlQueryResponse) Model {
+	t := table.New(table.WithFocused(true))
+	m.sql = sqlResults{
+		query:     query,
+		rows:      resp.Rows,
+		committed: resp.TotalCommitted,
+		pending:   resp.PendingWrites,
+		table:     t,
+	}
+	switch req.GetOrderBy() {
+	case pb.SqlQueryRequest_KEY:
+		m.sql.sortBy = sortKey
+	case pb.SqlQueryRequest_VALUE:
+		m.sql.sortBy = sortValue
+	}
+	m.sql.desc = req.GetDesc()
+	m.state = stateSQL
+	m.syncSQLTable()
+	return m
+}
+
+// sortedRows returns the rows in the chosen order.
+func (r sqlResults) sortedRows() []*pb.SqlRow {
+	rows := slices.Clone(r.rows)
+	slices.SortStableFunc(rows, func(a, b *pb.SqlRow) int {
+		var c int
+		switch r.sortBy {
+		case sortKey:
+			c = cmp.Compare(a.Key, b.Key)
+		case sortValue:
+			c = cmp.Compare(a.Value, b.Value)
+		default:
+			c = cmp.Compare(a.CreatedAtMs, b.CreatedAtMs)
+		}
+		if r.desc {
+			return -c
+		}
+		return c
+	})
+	return rows
+}
+
+// sqlColumns sizes the table's columns to width: id and time are fixed,
+// the key gets what its longest value needs up to a third of the rest, and
+// the value takes what's left.
+func sqlColumns(rows []*pb.SqlRow, width int) []table.Column {
+	const idW, timeW, padding = 8, 19, 2 * 4 // each cell pads 1 either side
+	rest := max(20, width-idW-timeW-padding)
+	keyW := 3
+	for _, r := range rows {
+		keyW = max(keyW, lipgloss.Width(r.Key))
+	}
+	keyW = min(keyW, rest/3)
+	return []table.Column{
+		{Title: "id", Width: idW},
+		{Title: "key", Width: keyW},
+		{Title: "value", Width: rest - keyW},
+		{Title: "created", Width: timeW},
+	}
+}
+
+// syncSQLTable rebuilds the table for the current size, sort and theme,
+// keeping the cursor where it was.
+func (m *Model) syncSQLTable() {
+	if m.sql.rows == nil {
+		return
+	}
+	l := m.layout()
+	w := l.topW - 4
+	t := &m.sql.table
+	cursor := t.Cursor()
+
+	var rows []table.Row
+	for _, r := range m.sql.sortedRows() {
+		id := r.Id
+		if len(id) > 8 {
+			id = id[:8]
+		}
+		created := time.UnixMilli(int64(r.CreatedAtMs)).Format("2006-01-02 15:04:05")
+		rows = append(rows, table.Row{id, r.Key, r.Value, created})
+	}
+	t.SetStyles(table.Styles{
+		Header:   m.st.title.Padding(0, 1),
+		Cell:     lipgloss.NewStyle().Padding(0, 1),
+		Selected: m.st.selected,
+	})
+	t.SetColumns(sqlColumns(m.sql.rows, w))
+	t.SetRows(rows)
+	t.SetWidth(w)
+	t.SetHeight(max(2, l.topH-2))
+	t.SetCursor(cursor)
+}
+
+func (m Model) handleSQLKey(k tea.Key) (tea.Model, tea.Cmd) {
+	t := &m.sql.table
+	switch {
+	case m.pressed(k, m.keys.back, m.keys.quit):
+		m.state = stateDB
+	case m.pressed(k, m.keys.sort):
+		m.sql.sortBy = (m.sql.sortBy + 1) % numSQLSortCols
+		t.SetCursor(0)
+	case m.pressed(k, m.keys.rev):
+		m.sql.desc = !m.sql.desc
+		t.SetCursor(0)
+	case m.pressed(k, m.keys.up):
+		t.MoveUp(1)
+	case m.pressed(k, m.keys.down):
+		t.MoveDown(1)
+	case m.pressed(k, m.keys.pageUp, m.keys.left):
+		t.MoveUp(t.Height())
+	case m.pressed(k, m.keys.pageDown, m.keys.right):
+		t.MoveDown(t.Height())
+	case m.pressed(k, m.keys.home):
+		t.GotoTop()
+	case m.pressed(k, m.keys.end):
+		t.GotoBottom()
+	}
+	return m, nil
+}
+
+func (m Model) renderSQLTablePanel(innerW, maxLines int) string {
+	var b strings.Builder
+	b.WriteString(m.st.title.Render(m.tr("Query Results")))
+	b.WriteString(m.st.dim.Render(m.trf("  %d of %d committed  row %d",
+		len(m.sql.rows), m.sql.committed, m.sql.table.Cursor()+1)))
+	b.WriteString("\n")
+	b.WriteString(m.sql.table.View())
+	return b.String()
+}
+
+func (m Model) renderSQLInfoPanel(innerW, _ int) string {
+	var b strings.Builder
+	b.WriteString(m.st.title.Render(m.tr("Query")))
+	b.WriteString("  ")
+	b.WriteString(m.st.value.Render(m.sql.query))
+	b.WriteString("\n\n")
+
+	dir := "↑"
+	if m.sql.desc {
+		dir = "↓"
+	}
+	b.WriteString(m.trf("sorted by %s %s   pending writes: %s\n",
+		m.st.value.Render(sqlSortNames[m.sql.sortBy]), dir,
+		m.st.value.Render(fmt.Sprintf("%d", m.sql.pending))))
+	if row := m.sql.table.SelectedRow(); row != nil {
+		b.WriteString(truncate(fmt.Sprintf("%s = %s", m.st.value.Render(row[1]), row[2]), innerW-4))
+	}
+	b.WriteString("\n\n")
+	b.WriteString(m.st.dim.Render(m.tr("[↑/↓] row  [pgup/pgdn] page  [s] sort column  [r] reverse  [esc] back to console")))
+	return b.String()
+}
@@ -0,0 +1,164 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+
+	pb "github.com/jredh-dev/nexus/cmd/tui/proto"
+)
+
+// TCPBencher is a HermitClient that can time the same calls over hermit's
+// framed TCP protocol and over gRPC.
+type TCPBencher interface {
+	TCPBench(n int) ([]TCPBenchResult, error)
+}
+
+// TCPBenchResult is the median latency of one operation both ways.
+type TCPBenchResult struct {
+	Op   string // GET, SET or STATS
+	TCP  time.Duration
+	GRPC time.Duration
+}
+
+const (
+	tcpBenchDefault = 200
+	tcpBenchMax     = 10_000
+
+	// tcpBenchKey is the key the benchmark sets and gets, with a value of
+	// tcpBenchValueBytes.
+	tcpBenchKey        = "bench:tcp"
+	tcpBenchValueBytes = 64
+)
+
+// fmtTCPBench shows a result as "GET tcp 41.20μs grpc 162.00μs (3.9x)".
+func fmtTCPBench(r TCPBenchResult) string {
+	ratio := "-"
+	if r.TCP > 0 {
+		ratio = fmt.Sprintf("%.1fx", float64(r.GRPC)/float64(r.TCP))
+	}
+	return fmt.Sprintf("%s tcp %s grpc %s (%s)", r.Op, fmtNs(r.TCP.Nanoseconds()), fmtNs(r.GRPC.Nanoseconds()), ratio)
+}
+
+// TCPBench runs SET, GET and STATS n times each over the TCP protocol,
+// on 9093 over TLS if the client uses it and 9091 if not, then the same
+// over gRPC, as the logged-in user and in the current bucket.
+func (c *grpcHermitClient) TCPBench(n int) ([]TCPBenchResult, error) {
+	host, _, err := net.SplitHostPort(c.addr)
+	if err != nil {
+		return nil, err
+	}
+	port := tcpPort
+	if c.tls != nil {
+		port = tcpTLSPort
+	}
+	tc, err := dialTCP(net.JoinHostPort(host, port), c.tls)
+	if err != nil {
+		return nil, fmt.Errorf("hermit's TCP protocol: %w", err)
+	}
+	defer tc.Close()
+	c.mu.Lock()
+	session, bucket := c.session, c.bucket
+	c.mu.Unlock()
+	if err := tc.auth(c.secret, session); err != nil {
+		return nil, fmt.Errorf("TCP AUTH: %w", err)
+	}
+	if bucket != "" {
+		if err := tc.useBucket(bucket); err != nil {
+			return nil, fmt.Errorf("TCP BUCKET: %w", err)
+		}
+	}
+
+	ctx, cancel := c.ctx(time.Minute)
+	defer cancel()
+	value := bytes.Repeat([]byte("x"), tcpBenchValueBytes)
+	ops := []struct {
+		name string
+		tcp  func() error
+		grpc func() error
+	}{
+		{"SET",
+			func() error { _, err := tc.set(tcpBenchKey, value); return err },
+			func() error {
+				resp, err := c.client.KvSet(ctx, &pb.KvSetRequest{Key: tcpBenchKey, Value: value})
+				if err == nil && !resp.Ok {
+					err = fmt.Errorf("%s", resp.Error)
+				}
+				return err
+			}},
+		{"GET",
+			func() error { _, _, err := tc.get(tcpBenchKey); return err },
+			func() error { _, err := c.client.KvGet(ctx, &pb.KvGetRequest{Key: tcpBenchKey}); return err }},
+		{"STATS",
+			func() error { _, err := tc.stats(); return err },
+			func() error { _, err := c.client.DbStats(ctx, &pb.DbStatsRequest{}); return err }},
+	}
+	out := make([]TCPBenchResult, len(ops))
+	for i, op := range ops {
+		out[i].Op = op.name
+		if out[i].TCP, err = timeMedian(n, op.tcp); err != nil {
+			return nil, fmt.Errorf("TCP %s: %w", op.name, err)
+		}
+		if out[i].GRPC, err = timeMedian(n, op.grpc); err != nil {
+			return nil, fmt.Errorf("gRPC %s: %w", op.name, err)
+		}
+	}
+	return out, nil
+}
+
+// timeMedian runs call n times and returns its median latency.
+func timeMedian(n int, call func() error) (time.Duration, error) {
+	lat := make([]int64, n)
+	for i := range lat {
+		start := time.Now()
+		if err := call(); err != nil {
+			return 0, err
+		}
+		lat[i] = time.Since(start).Nanoseconds()
+	}
+	return time.Duration(summarize(lat).P50Ns), nil
+}
+
+// tcpBenchCommand runs the bench:tcp console command.
+func (m Model) tcpBenchCommand(raw string, parts []string) tea.Cmd {
+	n := tcpBenchDefault
+	if len(parts) > 2 {
+		return m.dbResult(raw, "", fmt.Errorf("usage: bench:tcp [n]"))
+	}
+	if len(parts) == 2 {
+		v, err := strconv.Atoi(parts[1])
+		if err != nil || v < 1 || v > tcpBenchMax {
+			return m.dbResult(raw, "", fmt.Errorf("bench:tcp: n must be 1 to %d", tcpBenchMax))
+		}
+		n = v
+	}
+
+	h := m.hermit
+	return func() tea.Msg {
+		if h == nil {
+			return dbCmdResultMsg{cmd: raw, err: fmt.Errorf("not connected")}
+		}
+		b, ok := h.(TCPBencher)
+		if !ok {
+			return dbCmdResultMsg{cmd: raw, err: ErrUnsupported}
+		}
+		results, err := b.TCPBench(n)
+		if err != nil {
+			return dbCmdResultMsg{cmd: raw, err: err}
+		}
+		lines := make([]string, len(results))
+		for i, r := range results {
+			lines[i] = fmtTCPBench(r)
+		}
+		out := fmt.Sprintf("median of %d: %s", n, strings.Join(lines, "; "))
+		return dbCmdResultMsg{cmd: raw, output: out, data: results}
+	}
+}
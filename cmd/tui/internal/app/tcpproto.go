@@ -0,0 +1,177 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package app
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// hermit's framed TCP protocol (services/rust-grpc/src/tcp.rs): each
+// message is a 4-byte big-endian length and that many bytes, a request
+// starting with its op and a response with its status.
+const (
+	tcpPort    = "9091" // plaintext
+	tcpTLSPort = "9093"
+
+	tcpMaxFrame = 16 << 20
+	tcpTimeout  = 5 * time.Second
+)
+
+const (
+	tcpOpPing byte = iota
+	tcpOpAuth
+	tcpOpBucket
+	tcpOpGet
+	tcpOpSet
+	tcpOpStats
+)
+
+const (
+	tcpStatusOK byte = iota
+	tcpStatusNotFound
+	tcpStatusError
+)
+
+// errTCPNotFound is a GET of a key that isn't there.
+var errTCPNotFound = errors.New("not found")
+
+// tcpConn is a connection speaking hermit's TCP protocol.
+type tcpConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// dialTCP connects to hermit's TCP protocol at addr, over TLS checked as
+// cfg says unless it's nil.
+func dialTCP(addr string, cfg *tls.Config) (*tcpConn, error) {
+	d := &net.Dialer{Timeout: tcpTimeout}
+	var conn net.Conn
+	var err error
+	if cfg == nil {
+		conn, err = d.Dial("tcp", addr)
+	} else {
+		host, _, splitErr := net.SplitHostPort(addr)
+		if splitErr != nil {
+			return nil, splitErr
+		}
+		cfg = cfg.Clone()
+		cfg.ServerName = host
+		conn, err = tls.DialWithDialer(d, "tcp", addr, cfg)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tcpConn{conn: conn, r: bufio.NewReader(conn)}, nil
+}
+
+func (c *tcpConn) Close() error { return c.conn.Close() }
+
+// call sends a request of op and args, concatenated, and returns the
+// response body. A status other than ok comes back as an error.
+func (c *tcpConn) call(op byte, args ...[]byte) ([]byte, error) {
+	n := 1
+	for _, a := range args {
+		n += len(a)
+	}
+	req := make([]byte, 0, 4+n)
+	req = binary.BigEndian.AppendUint32(req, uint32(n))
+	req = append(req, op)
+	for _, a := range args {
+		req = append(req, a...)
+	}
+	c.conn.SetDeadline(time.Now().Add(tcpTimeout))
+	if _, err := c.conn.Write(req); err != nil {
+		return nil, err
+	}
+
+	var hdr [4]byte
+	if _, err := io.ReadFull(c.r, hdr[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(hdr[:])
+	if size == 0 || size > tcpMaxFrame {
+		return nil, fmt.Errorf("hermit sent a frame of %d bytes", size)
+	}
+	resp := make([]byte, size)
+	if _, err := io.ReadFull(c.r, resp); err != nil {
+		return nil, err
+	}
+	switch resp[0] {
+	case tcpStatusOK:
+		return resp[1:], nil
+	case tcpStatusNotFound:
+		return nil, errTCPNotFound
+	case tcpStatusError:
+		return nil, errors.New(string(resp[1:]))
+	default:
+		return nil, fmt.Errorf("hermit sent status %d", resp[0])
+	}
+}
+
+// prefixed is s with its length as a u16 in front, as AUTH and SET send
+// their first argument.
+func prefixed(s string) []byte {
+	return append(binary.BigEndian.AppendUint16(nil, uint16(len(s))), s...)
+}
+
+// auth authenticates the connection with the shared secret and a session
+// from Login.
+func (c *tcpConn) auth(secret, session string) error {
+	_, err := c.call(tcpOpAuth, prefixed(secret), []byte(session))
+	return err
+}
+
+// useBucket points later calls at bucket.
+func (c *tcpConn) useBucket(bucket string) error {
+	_, err := c.call(tcpOpBucket, []byte(bucket))
+	return err
+}
+
+// get returns key's value and version, or errTCPNotFound.
+func (c *tcpConn) get(key string) ([]byte, uint64, error) {
+	body, err := c.call(tcpOpGet, []byte(key))
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(body) < 8 {
+		return nil, 0, fmt.Errorf("GET: short response")
+	}
+	return body[8:], binary.BigEndian.Uint64(body), nil
+}
+
+// set stores value under key and returns its new version.
+func (c *tcpConn) set(key string, value []byte) (uint64, error) {
+	body, err := c.call(tcpOpSet, prefixed(key), value)
+	if err != nil {
+		return 0, err
+	}
+	if len(body) < 8 {
+		return 0, fmt.Errorf("SET: short response")
+	}
+	return binary.BigEndian.Uint64(body), nil
+}
+
+// tcpStats is what STATS returns: DbStats without the namespaces.
+type tcpStats struct {
+	docKeys, docStoredBytes, relRows, relPending uint64
+}
+
+func (c *tcpConn) stats() (tcpStats, error) {
+	body, err := c.call(tcpOpStats)
+	if err != nil {
+		return tcpStats{}, err
+	}
+	if len(body) < 32 {
+		return tcpStats{}, fmt.Errorf("STATS: short response")
+	}
+	u := func(i int) uint64 { return binary.BigEndian.Uint64(body[8*i:]) }
+	return tcpStats{u(0), u(1), u(2), u(3)}, nil
+}
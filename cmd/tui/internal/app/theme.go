@@ -0,0 +1,171 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package app
+
+import (
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+// theme is a named palette. Every style the TUI draws with derives from one.
+type theme struct {
+	name     string
+	accent   color.Color // titles, prompts, selection background
+	onAccent color.Color // text on the selection background
+	dim      color.Color // hints and secondary text
+	err      color.Color // errors and exposed secrets
+	value    color.Color // data values
+	border   color.Color // panel borders
+}
+
+var themes = []theme{
+	{
+		name:     "default",
+		accent:   lipgloss.Color("#00FF88"),
+		onAccent: lipgloss.Color("#000000"),
+		dim:      lipgloss.Color("#666666"),
+		err:      lipgloss.Color("#FF4444"),
+		value:    lipgloss.Color("#FFAA00"),
+		border:   lipgloss.Color("#444466"),
+	},
+	{
+		name:     "light",
+		accent:   lipgloss.Color("#007A4D"),
+		onAccent: lipgloss.Color("#FFFFFF"),
+		dim:      lipgloss.Color("#8A8A8A"),
+		err:      lipgloss.Color("#C62828"),
+		value:    lipgloss.Color("#A15C00"),
+		border:   lipgloss.Color("#B0B0C8"),
+	},
+	{
+		name:     "high-contrast",
+		accent:   lipgloss.Color("#FFFF00"),
+		onAccent: lipgloss.Color("#000000"),
+		dim:      lipgloss.Color("#FFFFFF"),
+		err:      lipgloss.Color("#FF0000"),
+		value:    lipgloss.Color("#00FFFF"),
+		border:   lipgloss.Color("#FFFFFF"),
+	},
+	{
+		name:     "solarized",
+		accent:   lipgloss.Color("#859900"),
+		onAccent: lipgloss.Color("#002B36"),
+		dim:      lipgloss.Color("#586E75"),
+		err:      lipgloss.Color("#DC322F"),
+		value:    lipgloss.Color("#B58900"),
+		border:   lipgloss.Color("#073642"),
+	},
+}
+
+// ThemeNames lists the built-in themes, default first.
+func ThemeNames() []string {
+	names := make([]string, len(themes))
+	for i, t := range themes {
+		names[i] = t.name
+	}
+	return names
+}
+
+// styles are the lipgloss styles for one theme.
+type styles struct {
+	title    lipgloss.Style
+	selected lipgloss.Style
+	dim      lipgloss.Style
+	err      lipgloss.Style
+	value    lipgloss.Style
+	prompt   lipgloss.Style
+	panel    lipgloss.Style
+
+	secret  color.Color // state tag of a secret still secret
+	exposed color.Color // state tag of an exposed secret
+}
+
+func newStyles(t theme) styles {
+	return styles{
+		title:    lipgloss.NewStyle().Bold(true).Foreground(t.accent),
+		selected: lipgloss.NewStyle().Bold(true).Foreground(t.onAccent).Background(t.accent),
+		dim:      lipgloss.NewStyle().Foreground(t.dim),
+		err:      lipgloss.NewStyle().Foreground(t.err).Bold(true),
+		value:    lipgloss.NewStyle().Foreground(t.value),
+		prompt:   lipgloss.NewStyle().Foreground(t.accent).Bold(true),
+		panel: lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(t.border).
+			Padding(0, 1),
+		secret:  t.accent,
+		exposed: t.err,
+	}
+}
+
+func themeIndex(name string) (int, bool) {
+	for i, t := range themes {
+		if t.name == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// WithTheme selects a theme by name. An empty name keeps the current one.
+func (m Model) WithTheme(name string) (Model, error) {
+	if name == "" {
+		return m, nil
+	}
+	i, ok := themeIndex(name)
+	if !ok {
+		return m, fmt.Errorf("unknown theme %q (have: %s)", name, strings.Join(ThemeNames(), ", "))
+	}
+	m.themeIdx = i
+	m.st = newStyles(themes[i])
+	return m, nil
+}
+
+// WithThemeFile sets where ctrl+t saves the chosen theme, so it survives a
+// restart. See ReadThemeFile.
+func (m Model) WithThemeFile(path string) Model {
+	m.themeFile = path
+	return m
+}
+
+// ReadThemeFile returns the theme name saved at path, or "" if none was.
+func ReadThemeFile(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// cycleTheme switches to the next theme and saves the choice.
+func (m Model) cycleTheme() (Model, tea.Cmd) {
+	m.themeIdx = (m.themeIdx + 1) % len(themes)
+	m.st = newStyles(themes[m.themeIdx])
+	name := themes[m.themeIdx].name
+	path := m.themeFile
+	return m, func() tea.Msg {
+		if path == "" {
+			return themeSavedMsg{name: name}
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return themeSavedMsg{name: name, err: err}
+		}
+		return themeSavedMsg{name: name, err: os.WriteFile(path, []byte(name+"\n"), 0o644)}
+	}
+}
+
+func (m Model) handleThemeSaved(msg themeSavedMsg) (tea.Model, tea.Cmd) {
+	text := "theme: " + msg.name
+	if msg.err != nil {
+		text += " (not saved: " + msg.err.Error() + ")"
+	}
+	m.viewHistory = append(m.viewHistory, fmt.Sprintf("[%s] %s", time.Now().Format("15:04:05"), text))
+	return m, nil
+}
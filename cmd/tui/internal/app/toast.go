@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package app
+
+import (
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+// DefaultToastDuration is how long a toast stays up unless configured.
+const DefaultToastDuration = 4 * time.Second
+
+// maxToasts caps how many toasts stack at once; the oldest goes first.
+const maxToasts = 3
+
+// toastWidth is the widest a toast gets, border included.
+const toastWidth = 44
+
+// toast is a transient notice for an async result, drawn over the bottom
+// right corner whatever panel is open. The panel logs keep the full record.
+type toast struct {
+	id    int
+	text  string
+	isErr bool
+}
+
+// toastExpireMsg removes the toast with id.
+type toastExpireMsg struct {
+	id int
+}
+
+// WithToastDuration sets how long toasts stay up. Zero turns them off.
+func (m Model) WithToastDuration(d time.Duration) Model {
+	m.toastDur = d
+	return m
+}
+
+// notify shows text as a toast and schedules its removal.
+func (m Model) notify(text string, isErr bool) (Model, tea.Cmd) {
+	if m.toastDur <= 0 {
+		return m, nil
+	}
+	m.toastSeq++
+	id := m.toastSeq
+	m.toasts = append(m.toasts, toast{id: id, text: text, isErr: isErr})
+	if len(m.toasts) > maxToasts {
+		m.toasts = m.toasts[len(m.toasts)-maxToasts:]
+	}
+	return m, tea.Tick(m.toastDur, func(time.Time) tea.Msg { return toastExpireMsg{id: id} })
+}
+
+func (m Model) handleToastExpire(msg toastExpireMsg) (tea.Model, tea.Cmd) {
+	for i, t := range m.toasts {
+		if t.id == msg.id {
+			m.toasts = append(m.toasts[:i:i], m.toasts[i+1:]...)
+			break
+		}
+	}
+	return m, nil
+}
+
+// overlayToasts stacks the toasts, newest at the bottom, over the bottom
+// right corner of base.
+func (m Model) overlayToasts(base string) string {
+	if len(m.toasts) == 0 {
+		return base
+	}
+	w := min(toastWidth, m.width-4)
+	var boxes []string
+	for _, t := range m.toasts {
+		style := m.st.panel.Width(w)
+		text := m.st.value.Render(truncate(t.text, w-4))
+		if t.isErr {
+			style = style.BorderForeground(m.st.err.GetForeground())
+			text = m.st.err.Render(truncate(t.text, w-4))
+		}
+		boxes = append(boxes, style.Render(text))
+	}
+	stack := lipgloss.JoinVertical(lipgloss.Right, boxes...)
+	h := strings.Count(stack, "\n") + 1
+	return lipgloss.NewCompositor(
+		lipgloss.NewLayer(base),
+		lipgloss.NewLayer(stack).X(max(0, m.width-w-1)).Y(max(0, m.height-h-1)).Z(2),
+	).Render()
+}
@@ -0,0 +1,356 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tea "charm.land/bubbletea/v2"
+
+	pb "github.com/jredh-dev/nexus/cmd/tui/proto"
+)
+
+// TopicStream yields a topic's messages until its context is cancelled.
+type TopicStream interface {
+	Recv() (*pb.TopicMessage, error)
+}
+
+// PubSub is a HermitClient with in-memory topics (the Publish and
+// Subscribe RPCs). The Topics panel is a chat room over them, so clients
+// can talk through hermit.
+type PubSub interface {
+	Publish(topic string, data []byte) (*pb.PublishResponse, error)
+	Subscribe(ctx context.Context, topic string) (TopicStream, error)
+}
+
+// The Topics panel starts in defaultTopic and keeps the last
+// maxTopicLines lines.
+const (
+	defaultTopic  = "lobby"
+	maxTopicLines = 1000
+	topicsBatch   = 100 // most messages delivered in one message
+)
+
+// topicLine is a message in the room, or a note from the panel itself.
+type topicLine struct {
+	at    time.Time
+	from  string
+	text  string
+	note  bool
+	isErr bool
+}
+
+// topicsState is the Topics panel: one subscribed topic and what has been
+// said in it since.
+type topicsState struct {
+	topic     string
+	lines     []topicLine
+	input     string
+	streaming bool
+	err       error // why the stream ended
+	cancel    context.CancelFunc
+}
+
+// topicEvent is a message, or the error that ended the stream.
+type topicEvent struct {
+	msg *pb.TopicMessage
+	err error
+}
+
+// topicsStartedMsg delivers a connected stream. gen ties it to one
+// subscription.
+type topicsStartedMsg struct {
+	gen    int
+	events <-chan topicEvent
+	cancel context.CancelFunc
+	err    error
+}
+
+// topicsMsg delivers the messages that arrived since the last one.
+type topicsMsg struct {
+	gen    int
+	events <-chan topicEvent
+	msgs   []*pb.TopicMessage
+	err    error
+	closed bool
+}
+
+// publishedMsg reports a Publish.
+type publishedMsg struct {
+	topic string
+	resp  *pb.PublishResponse
+	err   error
+}
+
+func openTopics(m Model) (Model, tea.Cmd) {
+	m.state = stateTopics
+	if m.topics.topic == "" {
+		m.topics.topic = defaultTopic
+	}
+	return m.startTopics()
+}
+
+// startTopics (re)subscribes to the panel's topic, dropping any current
+// stream.
+func (m Model) startTopics() (Model, tea.Cmd) {
+	m.stopTopics()
+	m.topics.err = nil
+	ps, ok := m.hermit.(PubSub)
+	if !ok {
+		m.topics.err = ErrUnsupported
+		return m, nil
+	}
+	m.topics.streaming = true
+	topic, gen := m.topics.topic, m.topicsGen
+	return m, func() tea.Msg {
+		ctx, cancel := context.WithCancel(context.Background())
+		stream, err := ps.Subscribe(ctx, topic)
+		if err != nil {
+			cancel()
+			return topicsStartedMsg{gen: gen, err: err}
+		}
+		events := make(chan topicEvent, topicsBatch)
+		go func() {
+			defer close(events)
+			for {
+				msg, err := stream.Recv()
+				select {
+				case events <- topicEvent{msg: msg, err: err}:
+				case <-ctx.Done():
+					return
+				}
+				if err != nil {
+					return
+				}
+			}
+		}()
+		return topicsStartedMsg{gen: gen, events: events, cancel: cancel}
+	}
+}
+
+// stopTopics cancels the stream; messages from it are ignored after.
+func (m *Model) stopTopics() {
+	if m.topics.cancel != nil {
+		m.topics.cancel()
+		m.topics.cancel = nil
+	}
+	m.topics.streaming = false
+	m.topicsGen++
+}
+
+// waitTopics blocks for the next message, then takes whatever else has
+// arrived.
+func waitTopics(gen int, events <-chan topicEvent) tea.Cmd {
+	return func() tea.Msg {
+		msg := topicsMsg{gen: gen, events: events}
+		ev, ok := <-events
+		for ok {
+			if ev.err != nil {
+				msg.err = ev.err
+				return msg
+			}
+			msg.msgs = append(msg.msgs, ev.msg)
+			if len(msg.msgs) == topicsBatch {
+				return msg
+			}
+			select {
+			case ev, ok = <-events:
+			default:
+				return msg
+			}
+		}
+		msg.closed = true
+		return msg
+	}
+}
+
+func (m Model) doPublish(topic, text string) tea.Cmd {
+	h := m.hermit
+	return func() tea.Msg {
+		ps, ok := h.(PubSub)
+		if !ok {
+			return publishedMsg{topic: topic, err: ErrUnsupported}
+		}
+		resp, err := ps.Publish(topic, []byte(text))
+		return publishedMsg{topic: topic, resp: resp, err: err}
+	}
+}
+
+// topicNote adds a line from the panel itself to the room.
+func (m *Model) topicNote(text string, isErr bool) {
+	m.topics.add(topicLine{at: time.Now(), text: text, note: true, isErr: isErr})
+}
+
+func (s *topicsState) add(lines ...topicLine) {
+	s.lines = append(s.lines, lines...)
+	if len(s.lines) > maxTopicLines {
+		s.lines = s.lines[len(s.lines)-maxTopicLines:]
+	}
+}
+
+func (m Model) handleTopicsStarted(msg topicsStartedMsg) (tea.Model, tea.Cmd) {
+	if msg.gen != m.topicsGen {
+		if msg.cancel != nil {
+			msg.cancel()
+		}
+		return m, nil
+	}
+	if m, cmd, lost := m.connLost(msg.err); lost {
+		return m, cmd
+	}
+	if msg.err != nil {
+		m.topics.streaming, m.topics.err = false, msg.err
+		return m, nil
+	}
+	m.topics.cancel = msg.cancel
+	m.topicNote(m.trf("joined #%s", m.topics.topic), false)
+	return m, waitTopics(msg.gen, msg.events)
+}
+
+func (m Model) handleTopics(msg topicsMsg) (tea.Model, tea.Cmd) {
+	if msg.gen != m.topicsGen {
+		return m, nil
+	}
+	for _, tm := range msg.msgs {
+		if tm.Dropped > 0 {
+			m.topicNote(m.trf("missed %d messages", tm.Dropped), true)
+		}
+		m.topics.add(topicLine{at: tm.Time.AsTime(), from: tm.From, text: string(tm.Data)})
+	}
+	if msg.err != nil || msg.closed {
+		m.topics.err = msg.err
+		m.stopTopics()
+		return m, nil
+	}
+	if m.state != stateTopics { // left via the palette
+		m.stopTopics()
+		return m, nil
+	}
+	return m, waitTopics(msg.gen, msg.events)
+}
+
+func (m Model) handlePublished(msg publishedMsg) (tea.Model, tea.Cmd) {
+	if m, cmd, lost := m.connLost(msg.err); lost {
+		return m, cmd
+	}
+	switch {
+	case msg.err != nil:
+		m.topicNote(m.tr("send failed: ")+msg.err.Error(), true)
+	case msg.resp.Error != "":
+		m.topicNote(m.tr("send failed: ")+msg.resp.Error, true)
+	case msg.topic != m.topics.topic || !m.topics.streaming:
+		// Not subscribed to it, so it won't echo back.
+		m.topicNote(m.trf("sent to #%s (%d listening)", msg.topic, msg.resp.Delivered), false)
+	}
+	return m, nil
+}
+
+func (m Model) handleTopicsKey(k tea.Key) (tea.Model, tea.Cmd) {
+	if m.scrollKey(&m.topicsScroll, k) {
+		return m, nil
+	}
+	if m.pressed(k, m.keys.back) {
+		m.stopTopics()
+		m.state = stateDashboard
+		m.topics.input = ""
+		return m, nil
+	}
+	switch k.Code {
+	case tea.KeyEnter:
+		text := strings.TrimSpace(m.topics.input)
+		m.topics.input = ""
+		if topic, ok := strings.CutPrefix(text, "/join "); ok {
+			if topic = strings.TrimSpace(topic); topic != "" && topic != m.topics.topic {
+				m.topics.topic = topic
+				return m.startTopics()
+			}
+			return m, nil
+		}
+		if text == "" {
+			if !m.topics.streaming {
+				return m.startTopics() // rejoin after an error
+			}
+			return m, nil
+		}
+		return m, m.doPublish(m.topics.topic, text)
+	case tea.KeyBackspace:
+		_, size := utf8.DecodeLastRuneInString(m.topics.input)
+		m.topics.input = m.topics.input[:len(m.topics.input)-size]
+	default:
+		if k.Text != "" {
+			m.topics.input += k.Text
+		}
+	}
+	return m, nil
+}
+
+// topicsLines renders the room for the scrollback.
+func (m Model) topicsLines() []string {
+	lines := make([]string, len(m.topics.lines))
+	for i, l := range m.topics.lines {
+		ts := m.st.dim.Render(l.at.Local().Format("15:04:05"))
+		switch {
+		case l.isErr:
+			lines[i] = ts + " " + m.st.err.Render("* "+l.text)
+		case l.note:
+			lines[i] = ts + " " + m.st.dim.Render("* "+l.text)
+		default:
+			from := l.from
+			if from == "" {
+				from = m.tr("anonymous")
+			}
+			lines[i] = ts + " " + m.st.value.Render("<"+from+">") + " " + l.text
+		}
+	}
+	return lines
+}
+
+// topicsHeight is the number of lines the Topics panel gives its
+// scrollback, under the title and a blank line.
+func topicsHeight(maxLines int) int {
+	return maxLines - 2
+}
+
+func (m Model) renderTopicsPanel(innerW, _ int) string {
+	var b strings.Builder
+	b.WriteString(m.st.title.Render(m.tr("Topics")))
+	header := fmt.Sprintf("  #%s  %s", m.topics.topic, m.topicsScroll.status())
+	b.WriteString(m.st.dim.Render(truncate(header, max(0, innerW-8))))
+	b.WriteString("\n\n")
+	b.WriteString(m.topicsScroll.view())
+	return b.String()
+}
+
+func (m Model) renderTopicsInputPanel(_, _ int) string {
+	var b strings.Builder
+	b.WriteString(m.st.title.Render(m.tr("Say")))
+	b.WriteString("\n\n")
+
+	switch {
+	case errors.Is(m.topics.err, ErrUnsupported):
+		b.WriteString(m.st.dim.Render(m.tr("This hermit has no topics.")))
+		b.WriteString("\n\n")
+		b.WriteString(m.st.dim.Render(m.tr("[esc] back")))
+		return b.String()
+	case m.topics.err != nil:
+		b.WriteString(m.st.err.Render(m.tr("stream failed: ") + m.topics.err.Error()))
+		b.WriteString("\n\n")
+	case !m.topics.streaming:
+		b.WriteString(m.st.dim.Render(m.tr("not subscribed; [enter] to rejoin")))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(m.st.prompt.Render(fmt.Sprintf("#%s> ", m.topics.topic)))
+	b.WriteString(m.topics.input)
+	b.WriteString("█\n\n")
+	b.WriteString(m.st.dim.Render(m.tr("[enter] send  /join <topic> switch topic  [esc] back")))
+	b.WriteString("\n")
+	b.WriteString(m.st.dim.Render(m.tr("[pgup/pgdn] scroll  [ctrl+f] follow  [ctrl+s] export")))
+	return b.String()
+}
@@ -5,10 +5,14 @@ package app
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	tea "charm.land/bubbletea/v2"
+
+	pb "github.com/jredh-dev/nexus/cmd/tui/proto"
 )
 
 // Init satisfies tea.Model. Returns nil (no initial commands).
@@ -18,6 +22,13 @@ func (m Model) Init() tea.Cmd {
 
 // Update is the bubbletea update function.
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	nm := next.(Model)
+	nm.syncScrollbacks()
+	return nm, cmd
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -27,21 +38,64 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyPressMsg:
 		return m.handleKey(msg)
 
+	case tea.MouseClickMsg:
+		return m.handleMouseClick(msg)
+
+	case tea.MouseWheelMsg:
+		return m.handleMouseWheel(msg)
+
 	case loginResultMsg:
 		return m.handleLoginResult(msg)
 
+	case toastExpireMsg:
+		return m.handleToastExpire(msg)
+
+	case exportMsg:
+		return m.handleExport(msg)
+
+	case themeSavedMsg:
+		return m.handleThemeSaved(msg)
+
+	case reconnectTickMsg:
+		return m.handleReconnectTick(msg)
+
+	case reconnectResultMsg:
+		return m.handleReconnectResult(msg)
+
 	case serverInfoMsg:
 		return m.handleServerInfo(msg)
 
 	case benchmarkResultMsg:
 		return m.handleBenchmarkResult(msg)
 
+	case benchTickMsg:
+		if msg.gen != m.benchGen || !m.benchRunning {
+			return m, nil
+		}
+		return m, m.benchTick()
+
 	case dbStatsMsg:
 		return m.handleDbStats(msg)
 
 	case dbCmdResultMsg:
 		return m.handleDbCmdResult(msg)
 
+	case kvKeysMsg:
+		// Completion is best effort; a failed refresh keeps the old cache.
+		if m, cmd, lost := m.connLost(msg.err); lost {
+			return m, cmd
+		}
+		if msg.err == nil {
+			m.rememberKeys(msg.keys...)
+		}
+		return m, nil
+
+	case kvBrowseMsg:
+		return m.handleKvBrowse(msg)
+
+	case kvPreviewMsg:
+		return m.handleKvPreview(msg)
+
 	case secretsListMsg:
 		return m.handleSecretsList(msg)
 
@@ -50,6 +104,57 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case secretSubmitMsg:
 		return m.handleSecretSubmit(msg)
+
+	case secretsExposedMsg:
+		return m.handleSecretsExposed(msg)
+
+	case secretsLeaderboardMsg:
+		return m.handleSecretsLeaderboard(msg)
+
+	case secretsPollMsg:
+		return m.handleSecretsPoll(msg)
+
+	case healthSampleMsg:
+		return m.handleHealthSample(msg)
+
+	case healthPollMsg:
+		return m.handleHealthPoll(msg)
+
+	case metricsMsg:
+		return m.handleMetrics(msg)
+
+	case metricsPollMsg:
+		return m.handleMetricsPoll(msg)
+
+	case portalLoginMsg:
+		return m.handlePortalLogin(msg)
+
+	case portalClaimsMsg:
+		return m.handlePortalClaims(msg)
+
+	case portalActionMsg:
+		return m.handlePortalAction(msg)
+
+	case logsStartedMsg:
+		return m.handleLogsStarted(msg)
+
+	case logsMsg:
+		return m.handleLogs(msg)
+
+	case topicsStartedMsg:
+		return m.handleTopicsStarted(msg)
+
+	case topicsMsg:
+		return m.handleTopics(msg)
+
+	case publishedMsg:
+		return m.handlePublished(msg)
+
+	case kvWatchStartedMsg:
+		return m.handleKvWatchStarted(msg)
+
+	case kvWatchMsg:
+		return m.handleKvWatch(msg)
 	}
 
 	return m, nil
@@ -67,17 +172,64 @@ func (m Model) handleKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		return m, tea.Quit
 	}
 
+	if m.helpOpen {
+		m.helpOpen = false
+		return m, nil
+	}
+	if m.palette.open {
+		return m.handlePaletteKey(k)
+	}
+	if m.pressed(k, m.keys.theme) {
+		return m.cycleTheme()
+	}
+	if m.pressed(k, m.keys.help) {
+		m.helpOpen = true
+		return m, nil
+	}
+	if m.paletteAvailable() {
+		switch {
+		case m.pressed(k, m.keys.palette):
+			m.palette = palette{open: true}
+			return m, nil
+		case m.pressed(k, m.keys.export):
+			return m, m.doExport()
+		case m.pressed(k, m.keys.shrinkSplit):
+			return m.resizeSplit(-splitStep), nil
+		case m.pressed(k, m.keys.growSplit):
+			return m.resizeSplit(splitStep), nil
+		case m.pressed(k, m.keys.layout):
+			m.sideBySide = !m.sideBySide
+			return m, nil
+		}
+	}
+
 	switch m.state {
 	case stateLogin:
 		return m.handleLoginKey(k)
-	case stateDashboard, stateBenchmark:
+	case stateDashboard:
 		return m.handleDashboardKey(k)
+	case stateBenchmark:
+		return m.handleBenchKey(k)
 	case stateDB:
 		return m.handleDBKey(k)
 	case stateSecrets:
 		return m.handleSecretsKey(k)
+	case stateKV:
+		return m.handleKVKey(k)
+	case stateSQL:
+		return m.handleSQLKey(k)
+	case stateHealth:
+		return m.handleHealthKey(k)
+	case statePortal:
+		return m.handlePortalKey(k)
+	case stateLogs:
+		return m.handleLogsKey(k)
+	case stateMetrics:
+		return m.handleMetricsKey(k)
+	case stateTopics:
+		return m.handleTopicsKey(k)
 	case stateError:
-		if k.Code == 'q' || k.Code == tea.KeyEscape {
+		if m.pressed(k, m.keys.quit, m.keys.back) {
 			return m, tea.Quit
 		}
 	}
@@ -108,18 +260,18 @@ func (m Model) handleLoginKey(k tea.Key) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) handleDashboardKey(k tea.Key) (tea.Model, tea.Cmd) {
-	switch k.Code {
-	case tea.KeyUp, 'k':
+	switch {
+	case m.pressed(k, m.keys.up):
 		if m.menuIdx > 0 {
 			m.menuIdx--
 		}
-	case tea.KeyDown, 'j':
+	case m.pressed(k, m.keys.down):
 		if m.menuIdx < len(m.menuItems)-1 {
 			m.menuIdx++
 		}
-	case tea.KeyEnter:
+	case m.pressed(k, m.keys.sel):
 		return m.executeMenuItem()
-	case 'q', tea.KeyEscape:
+	case m.pressed(k, m.keys.quit, m.keys.back):
 		if m.hermit != nil {
 			m.hermit.Close()
 		}
@@ -129,23 +281,36 @@ func (m Model) handleDashboardKey(k tea.Key) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) handleDBKey(k tea.Key) (tea.Model, tea.Cmd) {
-	switch k.Code {
-	case tea.KeyEscape:
+	if m.scrollKey(&m.dbScroll, k) {
+		return m, nil
+	}
+	if m.pressed(k, m.keys.back) {
 		m.state = stateDashboard
 		m.dbInput = ""
 		return m, nil
+	}
+	switch k.Code {
+	case tea.KeyTab:
+		m.dbInput, m.dbMatches = completeDB(m.dbInput, m.knownKeys())
+		return m, nil
 	case tea.KeyEnter:
+		m.dbMatches = nil
+		if m.conn == connReconnecting {
+			return m, nil // keep the input until hermit is back
+		}
 		if m.dbInput != "" {
 			cmd := strings.TrimSpace(m.dbInput)
 			m.dbInput = ""
 			return m, m.executeDBCommand(cmd)
 		}
 	case tea.KeyBackspace:
+		m.dbMatches = nil
 		if len(m.dbInput) > 0 {
 			m.dbInput = m.dbInput[:len(m.dbInput)-1]
 		}
 	default:
 		if k.Text != "" {
+			m.dbMatches = nil
 			m.dbInput += k.Text
 		}
 	}
@@ -153,25 +318,41 @@ func (m Model) handleDBKey(k tea.Key) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) handleSecretsKey(k tea.Key) (tea.Model, tea.Cmd) {
-	switch k.Code {
-	case tea.KeyEscape:
+	if m.scrollKey(&m.secretsScroll, k) {
+		return m, nil
+	}
+	if m.pressed(k, m.keys.back) {
 		m.state = stateDashboard
 		m.secretsInput = ""
 		return m, nil
+	}
+	switch k.Code {
 	case tea.KeyEnter:
+		if k.Mod&(tea.ModShift|tea.ModAlt) != 0 {
+			m.secretsInput += "\n"
+			return m, nil
+		}
 		if m.secretsInput != "" {
 			val := strings.TrimSpace(m.secretsInput)
 			m.secretsInput = ""
 			return m, m.doSubmitSecret(val)
 		}
 		// Empty enter refreshes
-		return m, tea.Batch(m.doSecretsList(), m.doSecretsStats())
-	case tea.KeyBackspace:
-		if len(m.secretsInput) > 0 {
-			m.secretsInput = m.secretsInput[:len(m.secretsInput)-1]
+		return m, m.refreshSecrets()
+	case tea.KeyTab:
+		m.secretsTab = (m.secretsTab + 1) % numSecretsTabs
+		if m.secretsTab == tabLeaderboard {
+			return m, m.doSecretsLeaderboard()
 		}
+	case tea.KeyBackspace:
+		// Secrets are often unicode on purpose; delete whole runes.
+		_, size := utf8.DecodeLastRuneInString(m.secretsInput)
+		m.secretsInput = m.secretsInput[:len(m.secretsInput)-size]
 	default:
-		if k.Text != "" {
+		switch {
+		case k.Code == 'j' && k.Mod == tea.ModCtrl:
+			m.secretsInput += "\n"
+		case k.Text != "":
 			m.secretsInput += k.Text
 		}
 	}
@@ -181,18 +362,24 @@ func (m Model) handleSecretsKey(k tea.Key) (tea.Model, tea.Cmd) {
 func (m Model) executeMenuItem() (tea.Model, tea.Cmd) {
 	switch m.menuItems[m.menuIdx] {
 	case "Hermit DB":
-		m.state = stateDB
-		m.dbInput = ""
-		return m, m.doDbStats()
+		return openDB(m, "")
 	case "Benchmark":
 		m.state = stateBenchmark
-		m.benchRunning = true
-		m.grpcBench = nil
-		return m, m.doBenchmark()
+		return m, nil
 	case "Secrets":
-		m.state = stateSecrets
-		m.secretsInput = ""
-		return m, tea.Batch(m.doSecretsList(), m.doSecretsStats())
+		return openSecrets(m)
+	case "KV Browser":
+		return openKV(m)
+	case "Health":
+		return openHealth(m)
+	case "Portal":
+		return openPortal(m)
+	case "Logs":
+		return openLogs(m)
+	case "Metrics":
+		return openMetrics(m)
+	case "Topics":
+		return openTopics(m)
 	case "Quit":
 		if m.hermit != nil {
 			m.hermit.Close()
@@ -202,15 +389,55 @@ func (m Model) executeMenuItem() (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// openDB switches to the DB console with input already typed, refreshing
+// the stats and the key completion cache.
+func openDB(m Model, input string) (Model, tea.Cmd) {
+	m.state = stateDB
+	m.dbInput = input
+	m.dbMatches = nil
+	return m, tea.Batch(m.doDbStats(), m.doKvKeys())
+}
+
+func openSecrets(m Model) (Model, tea.Cmd) {
+	m.state = stateSecrets
+	m.secretsInput = ""
+	m.secretsGen++
+	return m, tea.Batch(m.refreshSecrets(), m.pollSecrets())
+}
+
 // --- DB Command Dispatch ---
 //
 // Supported commands:
 //
 //	kv:set <key> <value>     — document store write
+//	kv:setex <key> <ttl> <v> — write that expires after ttl (e.g. 30s, 5m)
 //	kv:get <key>             — document store read
-//	kv:list                  — list all keys
+//	kv:del <key>             — document store delete
+//	kv:exists <key>          — document store presence check
+//	kv:ttl <key>             — time a key has left before it expires
+//	kv:cas <key> <ver> <v>   — write only if the key is at version ver (0 = unset)
+//	kv:incr <key> [delta]    — add to an integer value with compare-and-swap
+//	kv:list [prefix]         — list keys, the first kvListLimit of them
 //	sql:insert <key> <value> — relational store write (enqueued)
-//	sql:query [key]          — relational store read (eventual)
+//	sql:query [key] [opts]   — relational store read (eventual); opts are
+//	                           value=<text> by=<col> desc offset=<n> limit=<n>
+//	sql:count [key] [value=] — count matching rows
+//	sql:delete <key>         — delete every row with key
+//	sql:update <key> <value> — set the value of every row with key
+//	db:snapshot <file>       — save both stores to a local file
+//	db:restore <file|url>    — replace both stores with a saved snapshot, or
+//	                           one hermit fetches from s3:// or http(s)://
+//	limits                   — list hermit's rate limits (admins)
+//	limits:set <class> <per-sec> <burst> [client]
+//	                         — change a class's default limit, or a client's
+//	limits:clear <class> <client>
+//	                         — put a client back on the default limit
+//	replication              — primary or follower, and how far behind
+//	buckets                  — list the buckets you can read
+//	bucket:create <name>     — add a bucket (admins)
+//	bucket:use [name]        — send kv: commands to a bucket (default: default)
+//	diag                     — hermit's slowest recent calls and largest values (admins)
+//	bench:tcp [n]            — time GET, SET and STATS over the TCP protocol and gRPC
 //	stats                    — refresh DB stats
 //	help                     — show command list
 
@@ -233,14 +460,38 @@ func (m Model) executeDBCommand(raw string) tea.Cmd {
 			if m.hermit == nil {
 				return dbCmdResultMsg{cmd: raw, err: fmt.Errorf("not connected")}
 			}
-			resp, err := m.hermit.KvSet(key, []byte(val))
+			resp, err := m.hermit.KvSet(key, []byte(val), 0)
+			if err != nil {
+				return dbCmdResultMsg{cmd: raw, err: err}
+			}
+			if !resp.Ok {
+				return dbCmdResultMsg{cmd: raw, err: fmt.Errorf("%s", resp.Error)}
+			}
+			return dbCmdResultMsg{cmd: raw, output: fmt.Sprintf("OK  key=%q", key) + fmtVersion(resp.Version), keys: []string{key}, data: resp}
+		}
+
+	case "kv:setex":
+		if len(parts) < 4 {
+			return m.dbResult(raw, "", fmt.Errorf("usage: kv:setex <key> <ttl> <value>"))
+		}
+		key := parts[1]
+		ttl, err := time.ParseDuration(parts[2])
+		if err != nil || ttl < time.Millisecond {
+			return m.dbResult(raw, "", fmt.Errorf("ttl %q: want a duration of at least 1ms, like 30s or 5m", parts[2]))
+		}
+		val := strings.Join(parts[3:], " ")
+		return func() tea.Msg {
+			if m.hermit == nil {
+				return dbCmdResultMsg{cmd: raw, err: fmt.Errorf("not connected")}
+			}
+			resp, err := m.hermit.KvSet(key, []byte(val), ttl)
 			if err != nil {
 				return dbCmdResultMsg{cmd: raw, err: err}
 			}
 			if !resp.Ok {
 				return dbCmdResultMsg{cmd: raw, err: fmt.Errorf("%s", resp.Error)}
 			}
-			return dbCmdResultMsg{cmd: raw, output: fmt.Sprintf("OK  key=%q", key)}
+			return dbCmdResultMsg{cmd: raw, output: fmt.Sprintf("OK  key=%q ttl=%s", key, ttl), keys: []string{key}, data: resp}
 		}
 
 	case "kv:get":
@@ -257,24 +508,156 @@ func (m Model) executeDBCommand(raw string) tea.Cmd {
 				return dbCmdResultMsg{cmd: raw, err: err}
 			}
 			if !resp.Found {
-				return dbCmdResultMsg{cmd: raw, output: fmt.Sprintf("NOT FOUND  key=%q", key)}
+				return dbCmdResultMsg{cmd: raw, output: fmt.Sprintf("NOT FOUND  key=%q", key), data: resp}
 			}
-			return dbCmdResultMsg{cmd: raw, output: fmt.Sprintf("value=%q", string(resp.Value))}
+			return dbCmdResultMsg{cmd: raw, output: fmt.Sprintf("value=%q", string(resp.Value)) + fmtVersion(resp.Version), data: resp}
+		}
+
+	case "kv:del":
+		if len(parts) < 2 {
+			return m.dbResult(raw, "", fmt.Errorf("usage: kv:del <key>"))
+		}
+		key := parts[1]
+		return func() tea.Msg {
+			if m.hermit == nil {
+				return dbCmdResultMsg{cmd: raw, err: fmt.Errorf("not connected")}
+			}
+			resp, err := m.hermit.KvDelete(key)
+			if err != nil {
+				return dbCmdResultMsg{cmd: raw, err: err}
+			}
+			if resp.Error != "" {
+				return dbCmdResultMsg{cmd: raw, err: fmt.Errorf("%s", resp.Error)}
+			}
+			if !resp.Deleted {
+				return dbCmdResultMsg{cmd: raw, output: fmt.Sprintf("NOT FOUND  key=%q", key), gone: []string{key}, data: resp}
+			}
+			return dbCmdResultMsg{cmd: raw, output: fmt.Sprintf("DELETED  key=%q", key), gone: []string{key}, data: resp}
+		}
+
+	case "kv:exists":
+		if len(parts) < 2 {
+			return m.dbResult(raw, "", fmt.Errorf("usage: kv:exists <key>"))
+		}
+		key := parts[1]
+		return func() tea.Msg {
+			if m.hermit == nil {
+				return dbCmdResultMsg{cmd: raw, err: fmt.Errorf("not connected")}
+			}
+			resp, err := m.hermit.KvExists(key)
+			if err != nil {
+				return dbCmdResultMsg{cmd: raw, err: err}
+			}
+			if resp.Error != "" {
+				return dbCmdResultMsg{cmd: raw, err: fmt.Errorf("%s", resp.Error)}
+			}
+			if !resp.Exists {
+				return dbCmdResultMsg{cmd: raw, output: fmt.Sprintf("NOT FOUND  key=%q", key), gone: []string{key}, data: resp}
+			}
+			return dbCmdResultMsg{cmd: raw, output: fmt.Sprintf("EXISTS  key=%q", key), keys: []string{key}, data: resp}
+		}
+
+	case "kv:ttl":
+		if len(parts) < 2 {
+			return m.dbResult(raw, "", fmt.Errorf("usage: kv:ttl <key>"))
+		}
+		key := parts[1]
+		return func() tea.Msg {
+			if m.hermit == nil {
+				return dbCmdResultMsg{cmd: raw, err: fmt.Errorf("not connected")}
+			}
+			resp, err := m.hermit.KvTTL(key)
+			if err != nil {
+				return dbCmdResultMsg{cmd: raw, err: err}
+			}
+			if resp.Error != "" {
+				return dbCmdResultMsg{cmd: raw, err: fmt.Errorf("%s", resp.Error)}
+			}
+			switch {
+			case !resp.Found:
+				return dbCmdResultMsg{cmd: raw, output: fmt.Sprintf("NOT FOUND  key=%q", key), gone: []string{key}, data: resp}
+			case !resp.Expires:
+				return dbCmdResultMsg{cmd: raw, output: fmt.Sprintf("NO EXPIRY  key=%q", key), keys: []string{key}, data: resp}
+			}
+			ttl := time.Duration(resp.TtlMs) * time.Millisecond
+			return dbCmdResultMsg{cmd: raw, output: fmt.Sprintf("TTL  key=%q ttl=%s", key, ttl), keys: []string{key}, data: resp}
+		}
+
+	case "kv:cas":
+		if len(parts) < 4 {
+			return m.dbResult(raw, "", fmt.Errorf("usage: kv:cas <key> <version> <value>"))
+		}
+		key := parts[1]
+		ver, err := strconv.ParseUint(parts[2], 10, 64)
+		if err != nil {
+			return m.dbResult(raw, "", fmt.Errorf("version %q: want a number; 0 means the key must not be set", parts[2]))
+		}
+		val := strings.Join(parts[3:], " ")
+		return func() tea.Msg {
+			if m.hermit == nil {
+				return dbCmdResultMsg{cmd: raw, err: fmt.Errorf("not connected")}
+			}
+			resp, err := m.hermit.Txn(
+				[]*pb.TxnGuard{{Key: key, Version: &ver}},
+				[]*pb.TxnWrite{{Key: key, Value: []byte(val)}},
+			)
+			if err != nil {
+				return dbCmdResultMsg{cmd: raw, err: err}
+			}
+			if resp.Error != "" {
+				return dbCmdResultMsg{cmd: raw, err: fmt.Errorf("%s", resp.Error)}
+			}
+			if !resp.Ok {
+				return dbCmdResultMsg{cmd: raw, output: fmt.Sprintf("CONFLICT  key=%q is not at version %d", key, ver), data: resp}
+			}
+			return dbCmdResultMsg{cmd: raw, output: fmt.Sprintf("OK  key=%q", key) + fmtVersion(resp.Versions[0]), keys: []string{key}, data: resp}
+		}
+
+	case "kv:incr":
+		if len(parts) < 2 {
+			return m.dbResult(raw, "", fmt.Errorf("usage: kv:incr <key> [delta]"))
+		}
+		key := parts[1]
+		delta := int64(1)
+		if len(parts) >= 3 {
+			d, err := strconv.ParseInt(parts[2], 10, 64)
+			if err != nil {
+				return m.dbResult(raw, "", fmt.Errorf("delta %q: want an integer", parts[2]))
+			}
+			delta = d
+		}
+		return func() tea.Msg {
+			if m.hermit == nil {
+				return dbCmdResultMsg{cmd: raw, err: fmt.Errorf("not connected")}
+			}
+			n, resp, err := kvIncr(m.hermit, key, delta)
+			if err != nil {
+				return dbCmdResultMsg{cmd: raw, err: err}
+			}
+			return dbCmdResultMsg{cmd: raw, output: fmt.Sprintf("OK  key=%q value=%d", key, n) + fmtVersion(resp.Versions[0]), keys: []string{key}, data: resp}
 		}
 
 	case "kv:list":
+		prefix := ""
+		if len(parts) >= 2 {
+			prefix = parts[1]
+		}
 		return func() tea.Msg {
 			if m.hermit == nil {
 				return dbCmdResultMsg{cmd: raw, err: fmt.Errorf("not connected")}
 			}
-			resp, err := m.hermit.KvList()
+			resp, err := m.hermit.KvList(prefix, "", kvListLimit)
 			if err != nil {
 				return dbCmdResultMsg{cmd: raw, err: err}
 			}
 			if len(resp.Keys) == 0 {
-				return dbCmdResultMsg{cmd: raw, output: "(empty)"}
+				return dbCmdResultMsg{cmd: raw, output: "(empty)", data: resp}
+			}
+			out := strings.Join(resp.Keys, "  ")
+			if resp.NextCursor != "" {
+				out += fmt.Sprintf("  … first %d; narrow with a prefix or page in the KV browser", len(resp.Keys))
 			}
-			return dbCmdResultMsg{cmd: raw, output: strings.Join(resp.Keys, "  ")}
+			return dbCmdResultMsg{cmd: raw, output: out, keys: resp.Keys, data: resp}
 		}
 
 	case "sql:insert":
@@ -294,32 +677,122 @@ func (m Model) executeDBCommand(raw string) tea.Cmd {
 			if !resp.Queued {
 				return dbCmdResultMsg{cmd: raw, err: fmt.Errorf("%s", resp.Error)}
 			}
-			return dbCmdResultMsg{cmd: raw, output: fmt.Sprintf("QUEUED  key=%q val=%q", key, val)}
+			return dbCmdResultMsg{cmd: raw, output: fmt.Sprintf("QUEUED  key=%q val=%q", key, val), data: resp}
 		}
 
 	case "sql:query":
-		keyFilter := ""
-		if len(parts) >= 2 {
-			keyFilter = parts[1]
+		req, err := parseSQLQuery(parts[1:])
+		if err != nil {
+			return m.dbResult(raw, "", err)
 		}
 		return func() tea.Msg {
 			if m.hermit == nil {
 				return dbCmdResultMsg{cmd: raw, err: fmt.Errorf("not connected")}
 			}
-			resp, err := m.hermit.SqlQuery(keyFilter, 20)
+			resp, err := m.hermit.SqlQuery(req)
 			if err != nil {
 				return dbCmdResultMsg{cmd: raw, err: err}
 			}
 			if len(resp.Rows) == 0 {
-				return dbCmdResultMsg{cmd: raw, output: fmt.Sprintf("(no rows) committed=%d pending=%d",
+				return dbCmdResultMsg{cmd: raw, data: resp, output: fmt.Sprintf("(no rows) committed=%d pending=%d",
 					resp.TotalCommitted, resp.PendingWrites)}
 			}
-			var sb strings.Builder
-			sb.WriteString(fmt.Sprintf("committed=%d pending=%d\n", resp.TotalCommitted, resp.PendingWrites))
-			for _, r := range resp.Rows {
-				sb.WriteString(fmt.Sprintf("  [%s] key=%q val=%q\n", r.Id[:8], r.Key, r.Value))
+			rows := fmt.Sprintf("%d rows", len(resp.Rows))
+			if resp.Matched > uint64(len(resp.Rows)) {
+				rows = fmt.Sprintf("%d of %d rows", len(resp.Rows), resp.Matched)
 			}
-			return dbCmdResultMsg{cmd: raw, output: strings.TrimRight(sb.String(), "\n")}
+			// The rows open in the results table; history keeps a summary.
+			return dbCmdResultMsg{cmd: raw, sql: resp, sqlReq: req, data: resp, output: fmt.Sprintf("%s  committed=%d pending=%d",
+				rows, resp.TotalCommitted, resp.PendingWrites)}
+		}
+
+	case "sql:count":
+		req, err := parseSQLQuery(parts[1:])
+		if err != nil {
+			return m.dbResult(raw, "", err)
+		}
+		req.CountOnly = true
+		return func() tea.Msg {
+			if m.hermit == nil {
+				return dbCmdResultMsg{cmd: raw, err: fmt.Errorf("not connected")}
+			}
+			resp, err := m.hermit.SqlQuery(req)
+			if err != nil {
+				return dbCmdResultMsg{cmd: raw, err: err}
+			}
+			return dbCmdResultMsg{cmd: raw, data: resp, output: fmt.Sprintf("COUNT  %d  committed=%d pending=%d",
+				resp.Matched, resp.TotalCommitted, resp.PendingWrites)}
+		}
+
+	case "sql:delete":
+		if len(parts) != 2 {
+			return m.dbResult(raw, "", fmt.Errorf("usage: sql:delete <key>"))
+		}
+		key := parts[1]
+		return func() tea.Msg {
+			if m.hermit == nil {
+				return dbCmdResultMsg{cmd: raw, err: fmt.Errorf("not connected")}
+			}
+			resp, err := m.hermit.SqlDelete(key)
+			if err != nil {
+				return dbCmdResultMsg{cmd: raw, err: err}
+			}
+			if resp.Error != "" {
+				return dbCmdResultMsg{cmd: raw, err: fmt.Errorf("%s", resp.Error)}
+			}
+			return dbCmdResultMsg{cmd: raw, output: fmt.Sprintf("DELETED  key=%q rows=%d", key, resp.Rows), data: resp}
+		}
+
+	case "sql:update":
+		if len(parts) < 3 {
+			return m.dbResult(raw, "", fmt.Errorf("usage: sql:update <key> <value>"))
+		}
+		key := parts[1]
+		val := strings.Join(parts[2:], " ")
+		return func() tea.Msg {
+			if m.hermit == nil {
+				return dbCmdResultMsg{cmd: raw, err: fmt.Errorf("not connected")}
+			}
+			resp, err := m.hermit.SqlUpdate(key, val)
+			if err != nil {
+				return dbCmdResultMsg{cmd: raw, err: err}
+			}
+			if resp.Error != "" {
+				return dbCmdResultMsg{cmd: raw, err: fmt.Errorf("%s", resp.Error)}
+			}
+			return dbCmdResultMsg{cmd: raw, output: fmt.Sprintf("UPDATED  key=%q rows=%d", key, resp.Rows), data: resp}
+		}
+
+	case "db:snapshot":
+		if len(parts) != 2 {
+			return m.dbResult(raw, "", fmt.Errorf("usage: db:snapshot <file>"))
+		}
+		path := parts[1]
+		return func() tea.Msg {
+			if m.hermit == nil {
+				return dbCmdResultMsg{cmd: raw, err: fmt.Errorf("not connected")}
+			}
+			n, err := saveSnapshot(m.hermit, path)
+			if err != nil {
+				return dbCmdResultMsg{cmd: raw, err: err}
+			}
+			return dbCmdResultMsg{cmd: raw, output: fmt.Sprintf("SAVED  %s  %s", path, fmtBytes(uint64(n))), data: snapshotResult{Path: path, Bytes: n}}
+		}
+
+	case "db:restore":
+		if len(parts) != 2 {
+			return m.dbResult(raw, "", fmt.Errorf("usage: db:restore <file|url>"))
+		}
+		path := parts[1]
+		return func() tea.Msg {
+			if m.hermit == nil {
+				return dbCmdResultMsg{cmd: raw, err: fmt.Errorf("not connected")}
+			}
+			resp, err := restoreSnapshot(m.hermit, path)
+			if err != nil {
+				return dbCmdResultMsg{cmd: raw, err: err}
+			}
+			return dbCmdResultMsg{cmd: raw, output: fmt.Sprintf("RESTORED  %s  keys=%d rows=%d", path, resp.DocKeys, resp.RelRows), data: resp}
 		}
 
 	case "stats":
@@ -334,11 +807,33 @@ func (m Model) executeDBCommand(raw string) tea.Cmd {
 			out := fmt.Sprintf("docs: keys=%d bytes=%d  rels: rows=%d pending=%d",
 				resp.DocKeyCount, resp.DocCompressedBytes,
 				resp.RelRowCount, resp.RelPendingWrites)
-			return dbCmdResultMsg{cmd: raw, output: out}
+			if len(resp.Namespaces) > 0 {
+				codecs := make([]string, len(resp.Namespaces))
+				for i, ns := range resp.Namespaces {
+					codecs[i] = fmt.Sprintf("%s=%s %s", nsLabel(ns.Name), ns.Codec, fmtRatio(ns.RawBytes, ns.StoredBytes))
+				}
+				out += "  codecs: " + strings.Join(codecs, ", ")
+			}
+			return dbCmdResultMsg{cmd: raw, output: out, data: resp}
 		}
 
+	case "limits", "limits:set", "limits:clear":
+		return m.limitsCommand(raw, parts)
+
+	case "replication":
+		return m.replicationCommand(raw)
+
+	case "buckets", "bucket:create", "bucket:use":
+		return m.bucketsCommand(raw, parts)
+
+	case "diag":
+		return m.diagCommand(raw)
+
+	case "bench:tcp":
+		return m.tcpBenchCommand(raw, parts)
+
 	case "help":
-		help := "kv:set <k> <v>  kv:get <k>  kv:list  sql:insert <k> <v>  sql:query [k]  stats"
+		help := "kv:set <k> <v>  kv:setex <k> <ttl> <v>  kv:get <k>  kv:del <k>  kv:exists <k>  kv:ttl <k>  kv:cas <k> <ver> <v>  kv:incr <k> [n]  kv:list [prefix]  sql:insert <k> <v>  sql:query [k] [value=v by=col desc offset=n limit=n]  sql:count [k]  sql:delete <k>  sql:update <k> <v>  db:snapshot <file>  db:restore <file|url>  limits  limits:set <class> <per-sec> <burst> [client]  limits:clear <class> <client>  replication  buckets  bucket:create <name>  bucket:use [name]  diag  bench:tcp [n]  stats"
 		return m.dbResult(raw, help, nil)
 
 	default:
@@ -346,6 +841,55 @@ func (m Model) executeDBCommand(raw string) tea.Cmd {
 	}
 }
 
+// kvIncrTries is how many times kv:incr retries when another write gets in
+// between its read and its compare-and-swap.
+const kvIncrTries = 5
+
+// kvIncr adds delta to key's integer value, an unset key counting as 0. It
+// reads the value and version, then writes the sum only if the version is
+// unchanged, so concurrent increments are never lost.
+func kvIncr(h HermitClient, key string, delta int64) (int64, *pb.TxnResponse, error) {
+	for range kvIncrTries {
+		get, err := h.KvGet(key)
+		if err != nil {
+			return 0, nil, err
+		}
+		if get.Error != "" {
+			return 0, nil, fmt.Errorf("%s", get.Error)
+		}
+		var n int64
+		if get.Found {
+			if n, err = strconv.ParseInt(strings.TrimSpace(string(get.Value)), 10, 64); err != nil {
+				return 0, nil, fmt.Errorf("value %q is not an integer", get.Value)
+			}
+		}
+		n += delta
+		ver := get.Version // 0 when unset
+		resp, err := h.Txn(
+			[]*pb.TxnGuard{{Key: key, Version: &ver}},
+			[]*pb.TxnWrite{{Key: key, Value: []byte(strconv.FormatInt(n, 10))}},
+		)
+		if err != nil {
+			return 0, nil, err
+		}
+		if resp.Error != "" {
+			return 0, nil, fmt.Errorf("%s", resp.Error)
+		}
+		if resp.Ok {
+			return n, resp, nil
+		}
+	}
+	return 0, nil, fmt.Errorf("key %q kept changing; try again", key)
+}
+
+// fmtVersion appends a key version to command output, if hermit sent one.
+func fmtVersion(v uint64) string {
+	if v == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" version=%d", v)
+}
+
 func (m Model) dbResult(cmd, output string, err error) tea.Cmd {
 	return func() tea.Msg {
 		return dbCmdResultMsg{cmd: cmd, output: output, err: err}
@@ -359,8 +903,8 @@ func (m Model) doLogin() tea.Cmd {
 		if m.hermit == nil {
 			return loginResultMsg{err: fmt.Errorf("hermit client not configured")}
 		}
-		err := m.hermit.Login(m.username, "hardcoded-token")
-		return loginResultMsg{err: err}
+		role, err := m.hermit.Login(m.username, m.token)
+		return loginResultMsg{role: role, err: err}
 	}
 }
 
@@ -374,23 +918,16 @@ func (m Model) doServerInfo() tea.Cmd {
 	}
 }
 
-func (m Model) doBenchmark() tea.Cmd {
-	return func() tea.Msg {
-		if m.hermit == nil {
-			return benchmarkResultMsg{err: fmt.Errorf("not connected")}
-		}
-		resp, err := m.hermit.Benchmark(100, 0)
-		return benchmarkResultMsg{resp: resp, err: err}
-	}
-}
-
 func (m Model) doDbStats() tea.Cmd {
 	return func() tea.Msg {
 		if m.hermit == nil {
 			return dbStatsMsg{err: fmt.Errorf("not connected")}
 		}
 		resp, err := m.hermit.DbStats()
-		return dbStatsMsg{resp: resp, err: err}
+		if err != nil {
+			return dbStatsMsg{err: err}
+		}
+		return dbStatsMsg{resp: resp, quota: writeQuota(m.hermit), diag: dbDiagnostics(m.hermit)}
 	}
 }
 
@@ -414,6 +951,47 @@ func (m Model) doSecretsStats() tea.Cmd {
 	}
 }
 
+func (m Model) doSecretsExposed() tea.Cmd {
+	return func() tea.Msg {
+		if m.secrets == nil {
+			return secretsExposedMsg{err: fmt.Errorf("secrets client not configured")}
+		}
+		page, err := m.secrets.Exposed()
+		return secretsExposedMsg{page: page, err: err}
+	}
+}
+
+func (m Model) doSecretsLeaderboard() tea.Cmd {
+	return func() tea.Msg {
+		if m.secrets == nil {
+			return secretsLeaderboardMsg{err: fmt.Errorf("secrets client not configured")}
+		}
+		entries, err := m.secrets.Leaderboard()
+		return secretsLeaderboardMsg{entries: entries, err: err}
+	}
+}
+
+// refreshSecrets fetches everything the Secrets panel shows. The leaderboard
+// is only fetched while its tab is open.
+func (m Model) refreshSecrets() tea.Cmd {
+	cmds := []tea.Cmd{m.doSecretsList(), m.doSecretsStats(), m.doSecretsExposed()}
+	if m.secretsTab == tabLeaderboard {
+		cmds = append(cmds, m.doSecretsLeaderboard())
+	}
+	return tea.Batch(cmds...)
+}
+
+// secretsPollInterval matches the wall's rebuild interval on the server.
+const secretsPollInterval = 5 * time.Second
+
+// pollSecrets schedules the next refresh of the Secrets panel.
+func (m Model) pollSecrets() tea.Cmd {
+	gen := m.secretsGen
+	return tea.Tick(secretsPollInterval, func(time.Time) tea.Msg {
+		return secretsPollMsg{gen: gen}
+	})
+}
+
 func (m Model) doSubmitSecret(value string) tea.Cmd {
 	username := m.username
 	if username == "" {
@@ -437,39 +1015,70 @@ func (m Model) handleLoginResult(msg loginResultMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 	m.state = stateDashboard
+	m.role = msg.role
 	return m, m.doServerInfo()
 }
 
 func (m Model) handleServerInfo(msg serverInfoMsg) (tea.Model, tea.Cmd) {
+	if m, cmd, lost := m.connLost(msg.err); lost {
+		return m, cmd
+	}
 	if msg.err != nil {
 		m.err = msg.err
 		return m, nil
 	}
 	m.serverInfo = msg.resp
 	m.viewHistory = append(m.viewHistory, fmt.Sprintf("[%s] server info fetched", time.Now().Format("15:04:05")))
+	// By now the handshake has happened, so a warn-only client knows
+	// whether hermit's certificate passed.
+	if tw, ok := m.hermit.(TLSWarner); ok {
+		if w := tw.TLSWarning(); w != m.tlsWarning {
+			m.tlsWarning = w
+			if w != "" {
+				return m.notify(m.trf("TLS not verified: %s", w), true)
+			}
+		}
+	}
 	return m, nil
 }
 
 func (m Model) handleBenchmarkResult(msg benchmarkResultMsg) (tea.Model, tea.Cmd) {
+	if msg.gen != m.benchGen {
+		return m, nil
+	}
+	if m, cmd, lost := m.connLost(msg.err); lost {
+		m.benchRunning = false
+		return m, cmd
+	}
+	m.benchRunning = false
 	if msg.err != nil {
 		m.err = msg.err
-	} else {
-		m.grpcBench = msg.resp
+		return m.notify("benchmark failed: "+msg.err.Error(), true)
 	}
-	m.benchRunning = false
-	return m, nil
+	m.grpcBench = msg.resp
+	return m.notify(fmt.Sprintf("benchmark done: %d iterations, p50 %s", msg.resp.Done, fmtNs(msg.resp.P50Ns)), false)
 }
 
 func (m Model) handleDbStats(msg dbStatsMsg) (tea.Model, tea.Cmd) {
+	if m, cmd, lost := m.connLost(msg.err); lost {
+		return m, cmd
+	}
 	if msg.err != nil {
 		m.err = msg.err
 		return m, nil
 	}
-	m.dbStats = msg.resp
+	m.dbStats, m.dbQuota, m.dbDiag = msg.resp, msg.quota, msg.diag
 	return m, nil
 }
 
 func (m Model) handleDbCmdResult(msg dbCmdResultMsg) (tea.Model, tea.Cmd) {
+	if m, cmd, lost := m.connLost(msg.err); lost {
+		// Hand the command back so it can be rerun once reconnected.
+		if m.dbInput == "" {
+			m.dbInput = msg.cmd
+		}
+		return m, cmd
+	}
 	ts := time.Now().Format("15:04:05")
 	entry := dbHistoryEntry{
 		ts:    ts,
@@ -481,12 +1090,60 @@ func (m Model) handleDbCmdResult(msg dbCmdResultMsg) (tea.Model, tea.Cmd) {
 	} else {
 		entry.output = msg.output
 	}
+	m.rememberKeys(msg.keys...)
+	m.forgetKeys(msg.gone...)
 	m.dbHistory = append(m.dbHistory, entry)
-	if len(m.dbHistory) > 50 {
-		m.dbHistory = m.dbHistory[len(m.dbHistory)-50:]
+	if msg.sql != nil && m.state == stateDB {
+		m = m.showSQLResults(msg.cmd, msg.sqlReq, msg.sql)
+	}
+	if len(m.dbHistory) > maxHistory {
+		m.dbHistory = m.dbHistory[len(m.dbHistory)-maxHistory:]
 	}
 	verb := strings.ToLower(strings.Fields(msg.cmd)[0])
-	if verb == "kv:set" || verb == "sql:insert" || verb == "stats" {
+	switch verb {
+	case "kv:set", "kv:setex", "kv:del", "kv:cas", "kv:incr", "sql:insert", "sql:delete", "sql:update":
+		text := verb + " ok"
+		if msg.err != nil {
+			text = verb + " failed: " + msg.err.Error()
+		}
+		m, toastCmd := m.notify(text, msg.err != nil)
+		return m, tea.Batch(m.doDbStats(), toastCmd)
+	case "db:snapshot", "db:restore":
+		text := verb + " ok"
+		if msg.err != nil {
+			text = verb + " failed: " + msg.err.Error()
+		}
+		m, toastCmd := m.notify(text, msg.err != nil)
+		if verb == "db:snapshot" || msg.err != nil {
+			return m, toastCmd
+		}
+		// Every key may have changed; rebuild completion from scratch.
+		m.kvKeys = nil
+		return m, tea.Batch(m.doDbStats(), m.doKvKeys(), toastCmd)
+	case "bucket:create":
+		text := verb + " ok"
+		if msg.err != nil {
+			text = verb + " failed: " + msg.err.Error()
+		}
+		m, toastCmd := m.notify(text, msg.err != nil)
+		return m, toastCmd
+	case "bucket:use":
+		if msg.err != nil {
+			return m, nil
+		}
+		// A different keyspace: completion starts over, and the KV
+		// browser goes back to its first page watching the new bucket.
+		m.kvKeys = nil
+		cmds := []tea.Cmd{m.doKvKeys()}
+		if m.kvWatch.live {
+			m.stopKvWatch()
+			var browse tea.Cmd
+			m, browse = m.kvFirstPage()
+			cmds = append(cmds, browse)
+		}
+		m, toastCmd := m.notify(m.trf("using bucket %s", currentBucket(m.hermit)), false)
+		return m, tea.Batch(append(cmds, toastCmd)...)
+	case "stats":
 		return m, m.doDbStats()
 	}
 	return m, nil
@@ -530,8 +1187,81 @@ func (m Model) handleSecretSubmit(msg secretSubmitMsg) (tea.Model, tea.Cmd) {
 		text += "  " + r.Message
 	}
 	m.secretsLog = append(m.secretsLog, secretsLogEntry{ts: ts, text: text})
-	if len(m.secretsLog) > 50 {
-		m.secretsLog = m.secretsLog[len(m.secretsLog)-50:]
+	if len(m.secretsLog) > maxHistory {
+		m.secretsLog = m.secretsLog[len(m.secretsLog)-maxHistory:]
+	}
+	// Show our own exposures right away rather than on the next poll.
+	var toastCmd tea.Cmd
+	if !r.WasNew && r.Secret != nil {
+		m = m.addExposure(exposure{value: r.Secret.Value, lens: r.Lens, seen: time.Now()})
+		m, toastCmd = m.notify(fmt.Sprintf("exposed: %q", r.Secret.Value), true)
+	}
+	return m, tea.Batch(m.refreshSecrets(), toastCmd)
+}
+
+func (m Model) handleSecretsExposed(msg secretsExposedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		ts := time.Now().Format("15:04:05")
+		m.secretsLog = append(m.secretsLog, secretsLogEntry{ts: ts, text: msg.err.Error(), isErr: true})
+		return m, nil
+	}
+	// The first page is the backlog; only exposures after it are news.
+	backlog := !m.exposedPolled
+	m.exposedPolled = true
+	var fresh []string
+	now := time.Now()
+	for _, e := range msg.page.Entries {
+		if !m.exposedSeen[e.Value] {
+			fresh = append(fresh, e.Value)
+		}
+		m = m.addExposure(exposure{value: e.Value, lens: e.Lens, seen: now})
+	}
+	switch {
+	case backlog || len(fresh) == 0:
+		return m, nil
+	case len(fresh) == 1:
+		return m.notify(fmt.Sprintf("secret exposed: %q", fresh[0]), true)
+	default:
+		return m.notify(fmt.Sprintf("%d secrets exposed", len(fresh)), true)
+	}
+}
+
+// addExposure records a newly exposed value in the feed, or fills in the
+// lens of one already there.
+func (m Model) addExposure(e exposure) Model {
+	if m.exposedSeen == nil {
+		m.exposedSeen = make(map[string]bool)
+	}
+	if m.exposedSeen[e.value] {
+		if e.lens == "" {
+			return m
+		}
+		for i := range m.exposures {
+			if m.exposures[i].value == e.value && m.exposures[i].lens == "" {
+				m.exposures[i].lens = e.lens
+				break
+			}
+		}
+		return m
+	}
+	m.exposedSeen[e.value] = true
+	m.exposures = append(m.exposures, e)
+	if len(m.exposures) > maxExposures {
+		m.exposures = m.exposures[len(m.exposures)-maxExposures:]
+	}
+	return m
+}
+
+func (m Model) handleSecretsLeaderboard(msg secretsLeaderboardMsg) (tea.Model, tea.Cmd) {
+	m.leaderboard = msg.entries
+	m.boardErr = msg.err
+	return m, nil
+}
+
+func (m Model) handleSecretsPoll(msg secretsPollMsg) (tea.Model, tea.Cmd) {
+	// Stop polling once the panel is closed or a newer visit took over.
+	if m.state != stateSecrets || msg.gen != m.secretsGen {
+		return m, nil
 	}
-	return m, tea.Batch(m.doSecretsList(), m.doSecretsStats())
+	return m, tea.Batch(m.refreshSecrets(), m.pollSecrets())
 }
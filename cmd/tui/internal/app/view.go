@@ -4,51 +4,24 @@
 package app
 
 import (
+	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
 
 	pb "github.com/jredh-dev/nexus/cmd/tui/proto"
 )
 
-// --- Styles ---
-
-var (
-	titleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("#00FF88"))
-
-	selectedStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("#000000")).
-			Background(lipgloss.Color("#00FF88"))
-
-	dimStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#666666"))
-
-	errStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FF4444")).
-			Bold(true)
-
-	valueStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FFAA00"))
-
-	promptStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#00FF88")).
-			Bold(true)
-
-	panelStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("#444466")).
-			Padding(0, 1)
-)
-
 // View renders the full-screen TUI.
 func (m Model) View() tea.View {
 	if m.width == 0 {
-		v := tea.NewView("loading...")
+		v := tea.NewView(m.tr("loading..."))
 		v.AltScreen = true
 		return v
 	}
@@ -59,20 +32,31 @@ func (m Model) View() tea.View {
 		s = m.viewLogin()
 	case stateConnecting:
 		s = m.viewConnecting()
-	case stateDashboard:
-		s = m.viewDashboard()
-	case stateBenchmark:
-		s = m.viewBenchmark()
-	case stateDB:
-		s = m.viewDBConsole()
-	case stateSecrets:
-		s = m.viewSecrets()
+	case stateDashboard, stateBenchmark, stateDB, stateSecrets, stateKV, stateSQL, stateHealth, statePortal, stateLogs, stateMetrics, stateTopics:
+		s = m.splitView(m.panels())
 	case stateError:
 		s = m.viewError()
 	}
+	if m.paletteAvailable() {
+		badge := m.connBadge()
+		s = lipgloss.NewCompositor(
+			lipgloss.NewLayer(s),
+			lipgloss.NewLayer(badge).X(max(0, m.width-lipgloss.Width(badge)-2)).Z(1),
+		).Render()
+	}
+	if m.paletteAvailable() {
+		s = m.overlayToasts(s)
+	}
+	if m.palette.open {
+		s = m.overlayPalette(s)
+	}
+	if m.helpOpen {
+		s = m.overlayHelp(s)
+	}
 
 	v := tea.NewView(s)
 	v.AltScreen = true
+	v.MouseMode = tea.MouseModeCellMotion
 	return v
 }
 
@@ -80,101 +64,157 @@ func (m Model) View() tea.View {
 
 func (m Model) viewLogin() string {
 	var b strings.Builder
-	b.WriteString(titleStyle.Render("  FOOL"))
+	b.WriteString(m.st.title.Render("  FOOL"))
 	b.WriteString("\n\n")
-	b.WriteString("  Connect to: ")
-	b.WriteString(dimStyle.Render(m.addr))
+	b.WriteString(m.tr("  Connect to: "))
+	b.WriteString(m.st.dim.Render(m.addr))
 	b.WriteString("\n\n")
-	b.WriteString("  Username: ")
+	b.WriteString(m.tr("  Username: "))
 	b.WriteString(m.username)
 	b.WriteString("█")
 	b.WriteString("\n\n")
-	b.WriteString(dimStyle.Render("  [enter] login  [esc] quit"))
+	b.WriteString(m.st.dim.Render(m.tr("  [enter] login  [esc] quit")))
 	return b.String()
 }
 
 func (m Model) viewConnecting() string {
-	return titleStyle.Render("  FOOL") + "\n\n  Connecting to " + m.addr + "..."
+	return m.st.title.Render("  FOOL") + "\n\n" + m.trf("  Connecting to %s...", m.addr)
 }
 
 func (m Model) viewError() string {
 	var b strings.Builder
-	b.WriteString(titleStyle.Render("  FOOL"))
+	b.WriteString(m.st.title.Render("  FOOL"))
 	b.WriteString("\n\n")
 	if m.err != nil {
-		b.WriteString(errStyle.Render("  ERROR: " + m.err.Error()))
+		b.WriteString(m.st.err.Render(m.tr("  ERROR: ") + m.err.Error()))
 	}
 	b.WriteString("\n\n")
-	b.WriteString(dimStyle.Render("  [q/esc] quit"))
+	b.WriteString(m.st.dim.Render(m.tr("  [q/esc] quit")))
 	return b.String()
 }
 
-func (m Model) viewDashboard() string {
-	return m.splitView(m.renderInfoPanel, m.renderControlPanel)
-}
+// panelFunc renders one panel of splitView given its inner width and the
+// lines available to it.
+type panelFunc func(innerW, maxLines int) string
 
-func (m Model) viewBenchmark() string {
-	return m.splitView(m.renderBenchPanel, m.renderControlPanel)
+// panels returns the top and bottom panels of the current split-view state.
+func (m Model) panels() (top, bot panelFunc) {
+	switch m.state {
+	case stateBenchmark:
+		return m.renderBenchPanel, m.renderBenchConfigPanel
+	case stateDB:
+		return m.renderDBStatsPanel, m.renderDBInputPanel
+	case stateSecrets:
+		return m.renderSecretsListPanel, m.renderSecretsInputPanel
+	case stateKV:
+		return m.renderKVListPanel, m.renderKVPreviewPanel
+	case stateSQL:
+		return m.renderSQLTablePanel, m.renderSQLInfoPanel
+	case stateHealth:
+		return m.renderHealthPanel, m.renderHealthLogPanel
+	case statePortal:
+		return m.renderPortalPanel, m.renderPortalDetailPanel
+	case stateLogs:
+		return m.renderLogsPanel, m.renderLogsInfoPanel
+	case stateMetrics:
+		return m.renderMetricsPanel, m.renderMetricsInfoPanel
+	case stateTopics:
+		return m.renderTopicsPanel, m.renderTopicsInputPanel
+	default:
+		return m.renderInfoPanel, m.renderControlPanel
+	}
 }
 
-func (m Model) viewDBConsole() string {
-	return m.splitView(m.renderDBStatsPanel, m.renderDBInputPanel)
-}
+// splitView draws the two panels of the current state, stacked or side by
+// side depending on m.layout. Each renderer gets the width of its panel and
+// the lines it may use; anything past that is cut off.
+func (m Model) splitView(topFn, botFn panelFunc) string {
+	l := m.layout()
 
-func (m Model) viewSecrets() string {
-	return m.splitView(m.renderSecretsListPanel, m.renderSecretsInputPanel)
-}
+	topBox := m.st.panel.Width(l.topW).Height(l.topH + 2).Render(clipLines(topFn(l.topW, l.topH), l.topH))
+	botBox := m.st.panel.Width(l.botW).Height(l.botH + 2).Render(clipLines(botFn(l.botW, l.botH), l.botH))
 
-// splitView splits the terminal into two bordered panels stacked vertically.
-// topFn and botFn receive the inner width available to their panel.
-func (m Model) splitView(
-	topFn func(innerW, maxLines int) string,
-	botFn func(innerW, maxLines int) string,
-) string {
-	// Border overhead: 2 vertical borders (top+bottom) + 2 padding lines each side
-	borderH := 2
-	topHeight := m.height/2 - borderH
-	if topHeight < 4 {
-		topHeight = 4
-	}
-	botHeight := m.height - (topHeight + borderH*2) - borderH
-	if botHeight < 3 {
-		botHeight = 3
+	if l.sideBySide {
+		return lipgloss.JoinHorizontal(lipgloss.Top, topBox, botBox)
 	}
+	return topBox + "\n" + botBox
+}
 
-	// Inner width: border (1 each side) + padding (1 each side) = 4 chars
-	innerW := m.width - 4
-	if innerW < 20 {
-		innerW = 20
+// Split bounds: splitPct is the share of the screen the first panel gets,
+// adjusted in splitStep steps. Side by side needs at least
+// sideBySideMinWidth columns, or the panels stack regardless.
+const (
+	splitMin           = 25
+	splitMax           = 75
+	splitStep          = 5
+	sideBySideMinWidth = 100
+)
+
+// splitLayout is how splitView divides the screen. When side by side, top
+// is the left panel and bot the right.
+type splitLayout struct {
+	sideBySide bool
+	topW, botW int // panel widths, borders included
+	topH, botH int // lines available inside each panel
+}
+
+// layout sizes the panels of splitView for the terminal and the chosen
+// split.
+func (m Model) layout() splitLayout {
+	// Border overhead: 2 rows (top+bottom) per panel, 2 columns per side
+	// for the border and padding.
+	const borderH = 2
+	if m.sideBySide && m.width >= sideBySideMinWidth {
+		avail := m.width - 4
+		l := splitLayout{sideBySide: true}
+		l.topW = avail * m.splitPct / 100
+		l.botW = avail - l.topW
+		l.topH = max(4, m.height-borderH*2)
+		l.botH = l.topH
+		return l
 	}
 
-	topContent := topFn(innerW, topHeight)
-	botContent := botFn(innerW, botHeight)
+	l := splitLayout{}
+	l.topH = max(4, m.height*m.splitPct/100-borderH)
+	l.botH = max(3, m.height-(l.topH+borderH*2)-borderH)
+	l.topW = max(20, m.width-4)
+	l.botW = l.topW
+	return l
+}
 
-	topBox := panelStyle.Width(innerW).Render(topContent)
-	botBox := panelStyle.Width(innerW).Render(botContent)
+// resizeSplit moves the split by delta percentage points.
+func (m Model) resizeSplit(delta int) Model {
+	m.splitPct = clampInt(m.splitPct+delta, splitMin, splitMax)
+	return m
+}
 
-	return topBox + "\n" + botBox
+// clipLines keeps the first n lines of s.
+func clipLines(s string, n int) string {
+	lines := strings.Split(s, "\n")
+	if len(lines) <= n {
+		return s
+	}
+	return strings.Join(lines[:n], "\n")
 }
 
 // --- Panel renderers (signature: innerW, maxLines int) string ---
 
 func (m Model) renderInfoPanel(innerW, maxLines int) string {
 	var b strings.Builder
-	b.WriteString(titleStyle.Render("Server Information"))
+	b.WriteString(m.st.title.Render(m.tr("Server Information")))
 	b.WriteString("\n")
 
 	if m.serverInfo == nil {
-		b.WriteString(dimStyle.Render("No server info yet. Select 'Hermit DB' or 'Benchmark' to connect."))
+		b.WriteString(m.st.dim.Render(m.tr("No server info yet. Select 'Hermit DB' or 'Benchmark' to connect.")))
 		if m.err != nil {
 			b.WriteString("\n")
-			b.WriteString(errStyle.Render(m.err.Error()))
+			b.WriteString(m.st.err.Render(m.err.Error()))
 		}
 		return b.String()
 	}
 
 	si := m.serverInfo
-	lines := serverInfoLines(si)
+	lines := m.serverInfoLines(si)
 	for i, line := range lines {
 		if i >= maxLines-2 {
 			break
@@ -185,14 +225,14 @@ func (m Model) renderInfoPanel(innerW, maxLines int) string {
 
 	if len(m.viewHistory) > 0 {
 		b.WriteString("\n")
-		b.WriteString(dimStyle.Render("Log:"))
+		b.WriteString(m.st.dim.Render(m.tr("Log:")))
 		b.WriteString("\n")
 		start := 0
 		if len(m.viewHistory) > 3 {
 			start = len(m.viewHistory) - 3
 		}
 		for _, h := range m.viewHistory[start:] {
-			b.WriteString(dimStyle.Render("  " + h))
+			b.WriteString(m.st.dim.Render("  " + h))
 			b.WriteString("\n")
 		}
 	}
@@ -200,24 +240,29 @@ func (m Model) renderInfoPanel(innerW, maxLines int) string {
 	return b.String()
 }
 
-func serverInfoLines(si *pb.ServerInfoResponse) []string {
-	return []string{
-		fmt.Sprintf("Version:   %s", valueStyle.Render(si.Version)),
-		fmt.Sprintf("Region:    %s", valueStyle.Render(si.Region)),
-		fmt.Sprintf("Uptime:    %s", valueStyle.Render(fmt.Sprintf("%ds", si.UptimeSeconds))),
-		fmt.Sprintf("TLS:       %s", valueStyle.Render(fmt.Sprintf("%v", si.TlsEnabled))),
-		fmt.Sprintf("gRPC Port: %s", valueStyle.Render(fmt.Sprintf("%d", si.GrpcPort))),
+func (m Model) serverInfoLines(si *pb.ServerInfoResponse) []string {
+	lines := []string{
+		m.trf("Version:   %s", m.st.value.Render(si.Version)),
+		m.trf("Region:    %s", m.st.value.Render(si.Region)),
+		m.trf("Uptime:    %s", m.st.value.Render(fmt.Sprintf("%ds", si.UptimeSeconds))),
+		m.trf("TLS:       %s", m.st.value.Render(fmt.Sprintf("%v", si.TlsEnabled))),
+		m.trf("gRPC Port: %s", m.st.value.Render(fmt.Sprintf("%d", si.GrpcPort))),
+	}
+	if m.tlsWarning != "" {
+		lines = append(lines, m.st.err.Render(m.trf("TLS not verified: %s", m.tlsWarning)))
 	}
+	return lines
 }
 
 func (m Model) renderControlPanel(innerW, _ int) string {
 	var b strings.Builder
-	b.WriteString(titleStyle.Render("Menu"))
+	b.WriteString(m.st.title.Render(m.tr("Menu")))
 	b.WriteString("\n\n")
 
 	for i, item := range m.menuItems {
+		item = m.tr(item)
 		if i == m.menuIdx {
-			b.WriteString(selectedStyle.Render(" ▸ " + item + " "))
+			b.WriteString(m.st.selected.Render(" ▸ " + item + " "))
 		} else {
 			b.WriteString("   " + item)
 		}
@@ -225,152 +270,264 @@ func (m Model) renderControlPanel(innerW, _ int) string {
 	}
 
 	b.WriteString("\n")
-	b.WriteString(dimStyle.Render("[↑/↓ or k/j] navigate  [enter] select  [q] quit"))
+	b.WriteString(m.st.dim.Render(m.tr("[↑/↓ or k/j] navigate  [enter] select  [ctrl+k] commands  [ctrl+s] export  [ctrl+t] theme  [ctrl+l] layout  [?] keys  [q] quit")))
 	return b.String()
 }
 
 func (m Model) renderBenchPanel(innerW, _ int) string {
 	var b strings.Builder
-	b.WriteString(titleStyle.Render("Benchmark Results"))
+	b.WriteString(m.st.title.Render(m.tr("Benchmark Results")))
 	b.WriteString("\n")
 
-	if m.benchRunning {
-		b.WriteString("\n  Running benchmarks...")
+	if m.benchRunning && m.benchProg != nil {
+		live := m.benchProg.snapshot()
+		done, total := int(live.Done), int(live.Total)
+		b.WriteString("\n")
+		b.WriteString(fmt.Sprintf("  %s %s  %s\n",
+			m.progressBar(done, total, min(innerW-30, 40)),
+			m.st.value.Render(fmt.Sprintf("%d/%d", done, total)),
+			m.st.dim.Render(time.Duration(live.ElapsedNs).Truncate(time.Millisecond).String()),
+		))
+		if len(live.Histogram) > 0 {
+			b.WriteString(fmt.Sprintf("  p50: %s  p99: %s  %s\n",
+				m.st.value.Render(fmtNs(live.P50Ns)),
+				m.st.value.Render(fmtNs(live.P99Ns)),
+				m.st.dim.Render(m.trf("%.0f req/s", live.IterationsPerSec)),
+			))
+			b.WriteString("  " + m.st.value.Render(sparkline(live, min(innerW-4, 60))) + "\n")
+		}
 		return b.String()
 	}
 
+	if n := len(m.viewHistory); n > 0 {
+		b.WriteString(m.st.dim.Render("  " + m.viewHistory[n-1]))
+		b.WriteString("\n")
+	}
+
+	if m.grpcBench == nil && m.err != nil {
+		b.WriteString("\n")
+		b.WriteString(m.st.err.Render("  " + m.err.Error()))
+		b.WriteString("\n")
+	}
+
 	if m.grpcBench != nil {
 		b.WriteString("\n")
-		b.WriteString(titleStyle.Render("gRPC (TLS 1.3)"))
+		b.WriteString(m.st.title.Render("gRPC (TLS 1.3)"))
 		b.WriteString("\n")
 		gb := m.grpcBench
-		b.WriteString(fmt.Sprintf("  min: %s  p50: %s  p99: %s  max: %s\n",
-			valueStyle.Render(fmtNs(gb.MinNs)),
-			valueStyle.Render(fmtNs(gb.P50Ns)),
-			valueStyle.Render(fmtNs(gb.P99Ns)),
-			valueStyle.Render(fmtNs(gb.MaxNs)),
+		b.WriteString(fmt.Sprintf("  min: %s  p50: %s  p90: %s  max: %s\n",
+			m.st.value.Render(fmtNs(gb.MinNs)),
+			m.st.value.Render(fmtNs(gb.P50Ns)),
+			m.st.value.Render(fmtNs(gb.P90Ns)),
+			m.st.value.Render(fmtNs(gb.MaxNs)),
 		))
-		b.WriteString(fmt.Sprintf("  mean: %s  overhead: %s  tls: %s\n",
-			valueStyle.Render(fmtNs(gb.MeanNs)),
-			valueStyle.Render(fmtNs(gb.ProcessingOverheadNs)),
-			valueStyle.Render(gb.TlsVersion),
+		b.WriteString(fmt.Sprintf("  p99: %s  p99.9: %s\n",
+			m.st.value.Render(fmtNs(gb.P99Ns)),
+			m.st.value.Render(fmtNs(gb.P999Ns)),
 		))
+		b.WriteString(m.trf("  mean: %s  tls: %s\n",
+			m.st.value.Render(fmtNs(gb.MeanNs)),
+			m.st.value.Render(gb.TlsVersion),
+		))
+		b.WriteString(m.trf("  throughput: %s  %s\n",
+			m.st.value.Render(m.trf("%.0f req/s", gb.IterationsPerSec)),
+			m.st.value.Render(fmtBytes(uint64(gb.BytesPerSec))+"/s"),
+		))
+		if len(gb.Histogram) > 0 {
+			w := min(innerW-4, 60)
+			b.WriteString("\n  " + m.st.value.Render(sparkline(gb, w)) + "\n")
+			b.WriteString(m.st.dim.Render(fmt.Sprintf("  %-*s%s", w-len(fmtNs(gb.P99Ns)), fmtNs(gb.MinNs), fmtNs(gb.P99Ns))))
+			b.WriteString("\n")
+		}
 	}
 
 	return b.String()
 }
 
+// renderBenchConfigPanel is the form for the next run.
+func (m Model) renderBenchConfigPanel(innerW, _ int) string {
+	var b strings.Builder
+	b.WriteString(m.st.title.Render(m.tr("Benchmark Settings")))
+	b.WriteString("\n\n")
+	for i, f := range benchFields {
+		v := *f.get(&m.benchCfg)
+		line := fmt.Sprintf("%-14s %d", m.tr(f.label), v)
+		if i == m.benchField && !m.benchRunning {
+			b.WriteString(m.st.selected.Render(" ▸ " + line + " "))
+		} else {
+			b.WriteString("   " + line)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	if m.benchRunning {
+		b.WriteString(m.st.dim.Render(m.tr("running…  [esc] back")))
+	} else {
+		b.WriteString(m.st.dim.Render(m.tr("[↑/↓] field  [←/→] halve/double  [0-9] type  [enter] run  [esc] back")))
+	}
+	return b.String()
+}
+
+// progressBar renders done/total as a bar width cells wide.
+func (m Model) progressBar(done, total, width int) string {
+	if width < 10 {
+		width = 10
+	}
+	filled := 0
+	if total > 0 {
+		filled = min(width, done*width/total)
+	}
+	return m.st.prompt.Render(strings.Repeat("█", filled)) + m.st.dim.Render(strings.Repeat("░", width-filled))
+}
+
 func (m Model) renderDBStatsPanel(innerW, maxLines int) string {
 	var b strings.Builder
-	b.WriteString(titleStyle.Render("In-Memory Database — Stats"))
+	b.WriteString(m.dbStatsHeader())
+	if len(m.dbHistory) > 0 {
+		b.WriteString("\n")
+		b.WriteString(m.st.dim.Render(m.tr("Recent: ") + m.dbScroll.status()))
+		b.WriteString("\n")
+		b.WriteString(m.dbScroll.view())
+	}
+	return b.String()
+}
+
+// dbStatsHeader is the DB stats panel above its history; its height
+// varies with the namespaces and quota shown.
+func (m Model) dbStatsHeader() string {
+	var b strings.Builder
+	b.WriteString(m.st.title.Render(m.tr("In-Memory Database — Stats")))
 	b.WriteString("\n")
 
 	b.WriteString("\n")
-	b.WriteString(titleStyle.Render("Document Store") + dimStyle.Render(" (zstd KVP, fast reads)"))
+	b.WriteString(m.st.title.Render(m.tr("Document Store")) + m.st.dim.Render(m.tr(" (compressed KVP, fast reads)")))
 	b.WriteString("\n")
 	if m.dbStats != nil {
-		b.WriteString(fmt.Sprintf("  keys: %s   compressed: %s\n",
-			valueStyle.Render(fmt.Sprintf("%d", m.dbStats.DocKeyCount)),
-			valueStyle.Render(fmtBytes(m.dbStats.DocCompressedBytes)),
+		b.WriteString(m.trf("  keys: %s   compressed: %s\n",
+			m.st.value.Render(fmt.Sprintf("%d", m.dbStats.DocKeyCount)),
+			m.st.value.Render(fmtBytes(m.dbStats.DocCompressedBytes)),
 		))
+		for _, ns := range m.dbStats.Namespaces {
+			b.WriteString(m.trf("  %s %s  %d keys  %s → %s (%s)\n",
+				m.st.dim.Render(fmt.Sprintf("%-12s", nsLabel(ns.Name))), ns.Codec, ns.Keys,
+				fmtBytes(ns.RawBytes), fmtBytes(ns.StoredBytes),
+				m.st.value.Render(fmtRatio(ns.RawBytes, ns.StoredBytes)),
+			))
+		}
 	} else {
-		b.WriteString(dimStyle.Render("  loading...\n"))
+		b.WriteString(m.st.dim.Render(m.tr("  loading...") + "\n"))
 	}
+	b.WriteString(m.trf("  bucket in use: %s\n", m.st.value.Render(currentBucket(m.hermit))))
 
 	b.WriteString("\n")
-	b.WriteString(titleStyle.Render("Relational Store") + dimStyle.Render(" (MPSC queue, eventual reads)"))
+	b.WriteString(m.st.title.Render(m.tr("Relational Store")) + m.st.dim.Render(m.tr(" (MPSC queue, eventual reads)")))
 	b.WriteString("\n")
 	if m.dbStats != nil {
-		b.WriteString(fmt.Sprintf("  committed rows: %s   pending writes: %s\n",
-			valueStyle.Render(fmt.Sprintf("%d", m.dbStats.RelRowCount)),
-			valueStyle.Render(fmt.Sprintf("%d", m.dbStats.RelPendingWrites)),
+		b.WriteString(m.trf("  committed rows: %s   pending writes: %s\n",
+			m.st.value.Render(fmt.Sprintf("%d", m.dbStats.RelRowCount)),
+			m.st.value.Render(fmt.Sprintf("%d", m.dbStats.RelPendingWrites)),
 		))
 	} else {
-		b.WriteString(dimStyle.Render("  loading...\n"))
+		b.WriteString(m.st.dim.Render(m.tr("  loading...") + "\n"))
 	}
-
-	if len(m.dbHistory) > 0 {
-		b.WriteString("\n")
-		b.WriteString(dimStyle.Render("Recent:"))
+	if q := m.dbQuota; q != nil {
 		b.WriteString("\n")
-		linesUsed := 7
-		avail := maxLines - linesUsed - 2
-		if avail < 1 {
-			avail = 1
+		b.WriteString(m.trf("  write quota: %s of %d left, refilling at %s/s\n",
+			m.st.value.Render(fmt.Sprintf("%d", q.Remaining)), q.Limit, strconv.FormatFloat(q.Rate, 'f', -1, 64)))
+	}
+	if d := m.dbDiag; d != nil {
+		slowest, largest := m.tr("none"), m.tr("none")
+		if len(d.SlowCalls) > 0 {
+			slowest = fmtSlowCall(d.SlowCalls[0])
 		}
-		start := 0
-		if len(m.dbHistory) > avail {
-			start = len(m.dbHistory) - avail
-		}
-		for _, h := range m.dbHistory[start:] {
-			style := dimStyle
-			if h.isErr {
-				style = errStyle
-			}
-			line := fmt.Sprintf("[%s] %s → %s", h.ts, h.cmd, h.output)
-			if len(line) > innerW-2 {
-				line = line[:innerW-5] + "..."
-			}
-			b.WriteString(style.Render("  " + line))
-			b.WriteString("\n")
+		if len(d.LargeValues) > 0 {
+			largest = fmtLargeValue(d.LargeValues[0])
 		}
+		b.WriteString("\n")
+		b.WriteString(m.trf("  slowest call (%s): %s   largest value: %s\n",
+			fmtSlowThreshold(d.SlowThresholdUs), m.st.value.Render(slowest), m.st.value.Render(largest)))
 	}
-
 	return b.String()
 }
 
 func (m Model) renderDBInputPanel(innerW, _ int) string {
 	var b strings.Builder
-	b.WriteString(titleStyle.Render("DB Console"))
+	b.WriteString(m.st.title.Render(m.tr("DB Console")))
 	b.WriteString("\n\n")
 
-	b.WriteString(promptStyle.Render("> "))
+	b.WriteString(m.st.prompt.Render("> "))
 	b.WriteString(m.dbInput)
 	b.WriteString("█")
-	b.WriteString("\n\n")
+	b.WriteString("\n")
+	if len(m.dbMatches) > 0 {
+		b.WriteString(truncate(m.st.value.Render(strings.Join(m.dbMatches, "  ")), innerW))
+	}
+	b.WriteString("\n")
 
-	b.WriteString(dimStyle.Render("kv:set <k> <v>  kv:get <k>  kv:list"))
+	b.WriteString(m.st.dim.Render(m.tr("kv:set <k> <v>  kv:get <k>  kv:del <k>  kv:list")))
 	b.WriteString("\n")
-	b.WriteString(dimStyle.Render("sql:insert <k> <v>  sql:query [k]  stats  help"))
+	b.WriteString(m.st.dim.Render(m.tr("sql:insert <k> <v>  sql:query [k]  stats  help")))
 	b.WriteString("\n")
-	b.WriteString(dimStyle.Render("[enter] execute  [esc] back"))
+	b.WriteString(m.st.dim.Render(m.tr("[enter] execute  [tab] complete  [pgup/pgdn] scroll  [ctrl+f] follow  [ctrl+s] export  [esc] back")))
 	return b.String()
 }
 
 func (m Model) renderSecretsListPanel(innerW, maxLines int) string {
 	var b strings.Builder
 	stats := m.secretsStats
-	b.WriteString(titleStyle.Render("Secrets") +
-		dimStyle.Render(fmt.Sprintf("  total:%d  secrets:%d  exposed:%d  lenses:%d",
+	b.WriteString(m.st.title.Render(m.tr("Secrets")) +
+		m.st.dim.Render(m.trf("  total:%d  secrets:%d  exposed:%d  lenses:%d",
 			stats.Total, stats.Secrets, stats.NotSecrets, stats.Lenses)))
+	b.WriteString("\n")
+	for i, name := range secretsTabNames {
+		name = m.tr(name)
+		if secretsTab(i) == m.secretsTab {
+			b.WriteString(m.st.selected.Render(" " + name + " "))
+		} else {
+			b.WriteString(m.st.dim.Render(" " + name + " "))
+		}
+	}
 	b.WriteString("\n\n")
 
+	// The log scrolls in its own pane below the tab content.
+	listLines := m.secretsListLines(maxLines)
+	switch m.secretsTab {
+	case tabExposures:
+		m.renderExposures(&b, innerW, listLines-5)
+	case tabLeaderboard:
+		m.renderLeaderboard(&b, innerW, listLines-5)
+	default:
+		m.renderSecretsList(&b, innerW, listLines)
+	}
+
+	if len(m.secretsLog) > 0 {
+		b.WriteString("\n")
+		b.WriteString(m.st.dim.Render(m.tr("Log: ") + m.secretsScroll.status()))
+		b.WriteString("\n")
+		b.WriteString(m.secretsScroll.view())
+	}
+
+	return b.String()
+}
+
+func (m Model) renderSecretsList(b *strings.Builder, innerW, maxLines int) {
 	if len(m.secretsList) == 0 {
-		b.WriteString(dimStyle.Render("No secrets yet."))
+		b.WriteString(m.st.dim.Render(m.tr("No secrets yet.")))
 	} else {
-		// Show most recent first, capped to fit maxLines
-		avail := maxLines - 4
-		if avail < 1 {
-			avail = 1
-		}
-		list := m.secretsList
-		if len(list) > avail {
-			list = list[len(list)-avail:]
-		}
-		for _, s := range list {
-			stateColor := lipgloss.Color("#00FF88")
-			stateLabel := "secret"
+		for _, s := range m.visibleSecrets(maxLines) {
+			stateColor := m.st.secret
+			stateLabel := m.tr("secret")
 			if !s.IsSecret() {
-				stateColor = lipgloss.Color("#FF4444")
-				stateLabel = "exposed"
+				stateColor = m.st.exposed
+				stateLabel = m.tr("exposed")
 			}
 			stateTag := lipgloss.NewStyle().Foreground(stateColor).Render(stateLabel)
-			countInfo := dimStyle.Render(fmt.Sprintf("x%d", s.Count))
+			countInfo := m.st.dim.Render(fmt.Sprintf("x%d", s.Count))
 			line := fmt.Sprintf("[%s] %s  %s  %s",
 				stateTag,
-				valueStyle.Render(s.Value),
+				m.st.value.Render(s.Value),
 				countInfo,
-				dimStyle.Render("by "+s.SubmittedBy),
+				m.st.dim.Render(m.trf("by %s", s.SubmittedBy)),
 			)
 			if len(line) > innerW {
 				line = line[:innerW-3] + "..."
@@ -379,42 +536,187 @@ func (m Model) renderSecretsListPanel(innerW, maxLines int) string {
 			b.WriteString("\n")
 		}
 	}
+}
+
+// visibleSecrets returns the most recent secrets that fit in maxLines, in
+// the order the Secrets tab lists them.
+func (m Model) visibleSecrets(maxLines int) []Secret {
+	avail := maxLines - 4
+	if avail < 1 {
+		avail = 1
+	}
+	list := m.secretsList
+	if len(list) > avail {
+		list = list[len(list)-avail:]
+	}
+	return list
+}
 
+// secretsListLines is the number of lines the Secrets tab content gets out
+// of the panel's maxLines, after the log pane below it.
+func (m Model) secretsListLines(maxLines int) int {
 	if len(m.secretsLog) > 0 {
+		maxLines -= secretsLogHeight(maxLines) + 2
+	}
+	return maxLines
+}
+
+// renderExposures lists recently exposed values, newest first.
+func (m Model) renderExposures(b *strings.Builder, innerW, maxLines int) {
+	if len(m.exposures) == 0 {
+		b.WriteString(m.st.dim.Render(m.tr("Nothing exposed yet.")))
 		b.WriteString("\n")
-		b.WriteString(dimStyle.Render("Log:"))
-		b.WriteString("\n")
-		start := 0
-		if len(m.secretsLog) > 3 {
-			start = len(m.secretsLog) - 3
+		return
+	}
+	if maxLines < 1 {
+		maxLines = 1
+	}
+	shown := 0
+	for i := len(m.exposures) - 1; i >= 0 && shown < maxLines; i-- {
+		e := m.exposures[i]
+		lens := m.st.dim.Render(m.tr("lens unknown"))
+		if e.lens != "" {
+			lens = m.st.prompt.Render(m.trf("via %s", e.lens))
 		}
-		for _, e := range m.secretsLog[start:] {
-			style := dimStyle
-			if e.isErr {
-				style = errStyle
-			}
-			b.WriteString(style.Render(fmt.Sprintf("  [%s] %s", e.ts, e.text)))
-			b.WriteString("\n")
+		line := fmt.Sprintf("%s  %s  %s",
+			m.st.dim.Render(e.seen.Format("15:04:05")),
+			m.st.value.Render(e.value),
+			lens,
+		)
+		if lipgloss.Width(line) > innerW {
+			line = truncate(line, innerW)
 		}
+		b.WriteString(line)
+		b.WriteString("\n")
+		shown++
 	}
+}
 
-	return b.String()
+// renderLeaderboard ranks submitters by score.
+func (m Model) renderLeaderboard(b *strings.Builder, innerW, maxLines int) {
+	switch {
+	case errors.Is(m.boardErr, ErrUnsupported):
+		b.WriteString(m.st.dim.Render(m.tr("This server doesn't publish a leaderboard yet.")))
+		b.WriteString("\n")
+		return
+	case m.boardErr != nil:
+		b.WriteString(m.st.err.Render(m.boardErr.Error()))
+		b.WriteString("\n")
+		return
+	case len(m.leaderboard) == 0:
+		b.WriteString(m.st.dim.Render(m.tr("No entries yet.")))
+		b.WriteString("\n")
+		return
+	}
+
+	entries := append([]LeaderboardEntry(nil), m.leaderboard...)
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Score > entries[j].Score })
+	b.WriteString(m.st.dim.Render(fmt.Sprintf("%-4s %-20s %8s %8s %8s", "#", m.tr("submitter"), m.tr("score"), m.tr("secret"), m.tr("exposed"))))
+	b.WriteString("\n")
+	for i, e := range entries {
+		if i >= maxLines-1 {
+			break
+		}
+		line := fmt.Sprintf("%-4d %-20s %8d %8d %8d", i+1, e.SubmittedBy, e.Score, e.Secrets, e.Exposed)
+		if len(line) > innerW {
+			line = line[:innerW]
+		}
+		b.WriteString(m.st.value.Render(line))
+		b.WriteString("\n")
+	}
 }
 
+// composerLines caps how many lines of a multi-line secret the composer
+// shows; earlier lines scroll off the top.
+const composerLines = 4
+
 func (m Model) renderSecretsInputPanel(innerW, _ int) string {
 	var b strings.Builder
-	b.WriteString(titleStyle.Render("Submit a Secret"))
+	b.WriteString(m.st.title.Render(m.tr("Submit a Secret")))
 	b.WriteString("\n\n")
 
-	b.WriteString(promptStyle.Render("> "))
-	b.WriteString(m.secretsInput)
-	b.WriteString("█")
+	lines := strings.Split(m.secretsInput+"█", "\n")
+	first := max(0, len(lines)-composerLines)
+	if first > 0 {
+		b.WriteString(m.st.dim.Render(m.trf("  (%d more lines)", first)))
+		b.WriteString("\n")
+	}
+	for i, line := range lines[first:] {
+		prompt := "  "
+		if first+i == 0 {
+			prompt = "> "
+		}
+		b.WriteString(m.st.prompt.Render(prompt))
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	// Preview what the lenses are likely to make of it.
+	if hints := lensHints(m.secretsInput); len(hints) > 0 {
+		var parts []string
+		for _, h := range hints {
+			parts = append(parts, m.st.value.Render(h.lens)+m.st.dim.Render(" → "+h.detail))
+		}
+		b.WriteString(truncate(m.st.dim.Render(m.tr("lenses: "))+strings.Join(parts, m.st.dim.Render("  ·  ")), innerW-4))
+	} else if m.secretsInput != "" {
+		b.WriteString(m.st.dim.Render(m.tr("lenses: none likely")))
+	}
 	b.WriteString("\n\n")
 
-	b.WriteString(dimStyle.Render("[enter] submit  [enter on empty] refresh  [esc] back"))
+	b.WriteString(m.st.dim.Render(m.tr("[enter] submit  [alt+enter/ctrl+j] newline  [enter on empty] refresh  [tab] switch view  [esc] back")))
+	b.WriteString("\n")
+	b.WriteString(m.st.dim.Render(m.tr("[pgup/pgdn] scroll log  [ctrl+f] follow  [ctrl+s] export")))
 	return b.String()
 }
 
+// overlay draws content in a panel over base, centred near the top. The
+// panel is at least minW wide.
+func (m Model) overlay(base, content string, minW int) string {
+	w := max(m.width/2, minW)
+	if w > m.width-4 {
+		w = m.width - 4
+	}
+	box := m.st.panel.Width(w).Render(content)
+	x := (m.width - lipgloss.Width(box)) / 2
+	return lipgloss.NewCompositor(
+		lipgloss.NewLayer(base),
+		lipgloss.NewLayer(box).X(x).Y(2).Z(1),
+	).Render()
+}
+
+// overlayPalette draws the command palette over base.
+func (m Model) overlayPalette(base string) string {
+	w := min(max(m.width/2, 40), m.width-4)
+
+	var b strings.Builder
+	b.WriteString(m.st.prompt.Render("> "))
+	b.WriteString(m.palette.query)
+	b.WriteString("█\n\n")
+	matches := searchPalette(paletteActions(), m.palette.query)
+	if len(matches) == 0 {
+		b.WriteString(m.st.dim.Render(m.tr("No matching actions.")))
+		b.WriteString("\n")
+	}
+	maxRows := m.height/2 - 4
+	if maxRows < 1 {
+		maxRows = 1
+	}
+	for i, a := range matches {
+		if i >= maxRows {
+			break
+		}
+		title := "   " + m.tr(a.title) + " "
+		if i == m.palette.idx {
+			title = m.st.selected.Render(" ▸ " + m.tr(a.title) + " ")
+		}
+		b.WriteString(truncate(title+" "+m.st.dim.Render(m.tr(a.description)), w))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(m.st.dim.Render(m.tr("[↑/↓] select  [enter] run  [esc] close")))
+	return m.overlay(base, b.String(), 40)
+}
+
 // --- Formatting helpers ---
 
 func fmtNs(ns int64) string {
@@ -430,6 +732,31 @@ func fmtNs(ns int64) string {
 	}
 }
 
+// truncate shortens a possibly styled string to width cells, ending in "...".
+func truncate(s string, width int) string {
+	if width < 4 {
+		width = 4
+	}
+	return ansi.Truncate(s, width, "...")
+}
+
+// fmtRatio is how many times smaller stored is than raw.
+func fmtRatio(raw, stored uint64) string {
+	if stored == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1fx", float64(raw)/float64(stored))
+}
+
+// nsLabel names a DbStats namespace; "" is every key outside the ones
+// hermit was given codecs for.
+func nsLabel(name string) string {
+	if name == "" {
+		return "*"
+	}
+	return name
+}
+
 func fmtBytes(b uint64) string {
 	switch {
 	case b >= 1<<20:
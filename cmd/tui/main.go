@@ -8,11 +8,16 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	tea "charm.land/bubbletea/v2"
 
 	"github.com/jredh-dev/nexus/cmd/tui/internal/app"
 	"github.com/jredh-dev/nexus/cmd/tui/internal/obf"
+	"github.com/jredh-dev/nexus/internal/secretsmgr"
 )
 
 // Build-time embedded values. Set by the Makefile via:
@@ -23,48 +28,102 @@ import (
 //   - obfKey    = Encode(passphrase,   binaryName="tui")
 //   - obfAddr   = Encode(serverAddr,   passphrase)
 //   - obfSecret = Encode(sharedSecret, passphrase)
+//   - obfTLSPin = Encode(certFingerprint, passphrase)  optional
+//   - obfTLSCA  = Encode(caPEM, passphrase)            optional
 //
 // The passphrase exists only in CI secrets at build time; it is never stored.
 // obfKey is re-encoded with the binary name so a renamed binary decodes to
 // garbage (mild tamper signal).
 //
-// Dev mode: leave all three empty; falls back to defaults (overridable via
-// env vars or CLI flags).
+// Dev mode: leave all three empty; falls back to the config file profile,
+// then to localhost defaults (overridable via env vars or CLI flags).
 //
-// Resolution priority: CLI flag > env var > obf build-time > hardcoded default.
+// Resolution priority: CLI flag > env var > config profile > obf build-time >
+// hardcoded default. Production builds ignore the config file unless a
+// profile is selected explicitly with --profile or NEXUS_TUI_PROFILE. The
+// theme last picked with ctrl+t (saved next to the config file) ranks just
+// above the default.
 var (
 	obfAddr       string
 	obfSecret     string
 	obfKey        string
 	obfSecretsURL string
+	obfTLSPin     string
+	obfTLSCA      string
 )
 
 // config holds the resolved TUI configuration after merging all sources.
 type config struct {
-	HermitAddr string // gRPC address for hermit server
-	Secret     string // x-hermit-secret value
-	SecretsURL string // HTTP base URL for secrets service
-	Insecure   bool   // true = plaintext gRPC (no TLS)
-	DevMode    bool   // true = no build-time config baked in
+	HermitAddr string              // gRPC address for hermit server
+	Secret     string              // x-hermit-secret value
+	Token      string              // hermit Login token; empty is fine when hermit runs open
+	Bucket     string              // document store bucket; empty = hermit's default
+	SecretsURL string              // HTTP base URL for secrets service
+	PortalURL  string              // HTTP base URL for the portal
+	LogsURL    string              // HTTP log endpoint to tail; empty = hermit's TailLogs
+	Insecure   bool                // true = plaintext gRPC (no TLS)
+	TLSPin     string              // hermit's certificate SHA-256; empty = no pin
+	TLSCA      string              // PEM CA to verify hermit with; empty = system pool
+	TLSCAFile  string              // file to read TLSCA from; wins over a baked-in CA
+	TLSWarn    bool                // warn instead of refusing a certificate that fails the check
+	DevMode    bool                // true = no build-time config baked in
+	Theme      string              // color theme name; empty = default
+	ThemeFile  string              // where ctrl+t saves the theme
+	Lang       string              // interface language code; empty = English
+	Keys       map[string][]string // key remaps from the config file's [keys]
+	Toast      time.Duration       // how long toasts stay up; 0 = off
+	Demo       bool                // fake in-memory backends; no servers needed
+	JSON       bool                // exec: print results as JSON
+	Args       []string            // positional arguments after the flags
+	SSHAddr    string              // serve: SSH listen address
+	HostKey    string              // serve: SSH host key file, created if missing
+	AuthKeys   string              // serve: public keys allowed to connect, in authorized_keys format
 }
 
-// resolveConfig merges build-time, env var, and CLI flag sources.
-// Priority: CLI flag > env var > obf build-time > hardcoded default.
-func resolveConfig() config {
+// resolveConfig merges build-time, config file, env var, and CLI flag sources.
+// Priority: CLI flag > env var > config profile > obf build-time > hardcoded default.
+// args are the command-line arguments after the program name and any
+// subcommand.
+func resolveConfig(args []string) config {
 	// --- CLI flags ---
 	flagAddr := flag.String("hermit-addr", "", "hermit gRPC address (host:port)")
 	flagSecret := flag.String("hermit-secret", "", "x-hermit-secret shared secret")
+	flagToken := flag.String("hermit-token", "", "hermit login token")
+	flagBucket := flag.String("hermit-bucket", "", "document store bucket to work in (default: hermit's default)")
 	flagSecretsURL := flag.String("secrets-url", "", "secrets HTTP base URL")
+	flagPortalURL := flag.String("portal-url", "", "portal HTTP base URL")
+	flagLogsURL := flag.String("logs-url", "", "HTTP log endpoint for the Logs panel (default: hermit's own log)")
 	flagInsecure := flag.Bool("insecure", false, "use plaintext gRPC (no TLS)")
-	flag.Parse()
+	flagPin := flag.String("hermit-pin", "", "SHA-256 fingerprint hermit's certificate must have")
+	flagCA := flag.String("hermit-ca", "", "PEM file of the CA to verify hermit with (default: system pool)")
+	flagTLSWarn := flag.Bool("tls-warn", false, "warn instead of refusing a hermit certificate that fails the check (dev)")
+	flagTheme := flag.String("theme", "", "color theme: "+strings.Join(app.ThemeNames(), ", "))
+	flagLang := flag.String("lang", "", "interface language: "+strings.Join(app.LanguageNames(), ", "))
+	flagDemo := flag.Bool("demo", false, "run against simulated hermit and secrets backends")
+	flagToast := flag.Duration("toast", app.DefaultToastDuration, "how long notifications stay up (0 = off)")
+	flagProfile := flag.String("profile", "", "named profile from the config file")
+	flagConfig := flag.String("config", defaultConfigPath(), "config file path")
+	flagJSON := flag.Bool("json", false, "exec: print the result as JSON")
+	flagSSH := flag.String("ssh", "127.0.0.1:2222", "serve: SSH listen address")
+	flagHostKey := flag.String("host-key", "", "serve: SSH host key file (default: next to the config file)")
+	flagAuthKeys := flag.String("authorized-keys", "", "serve: public keys allowed to connect (default: authorized_keys next to the config file)")
+	flag.CommandLine.Parse(args)
 
 	// --- Start with hardcoded defaults ---
 	cfg := config{
 		HermitAddr: "localhost:9090",
 		Secret:     "",
 		SecretsURL: "http://localhost:8081",
+		PortalURL:  "http://localhost:8080",
 		Insecure:   true, // dev default: local Docker runs plaintext h2c
 		DevMode:    true,
+		Toast:      app.DefaultToastDuration,
+		Demo:       *flagDemo,
+		JSON:       *flagJSON,
+		Args:       flag.Args(),
+		SSHAddr:    *flagSSH,
+		HostKey:    *flagHostKey,
+		AuthKeys:   *flagAuthKeys,
 	}
 
 	// --- Layer: obf build-time values (if baked in) ---
@@ -105,23 +164,118 @@ func resolveConfig() config {
 			}
 			cfg.SecretsURL = u
 		}
+
+		for _, v := range []struct {
+			name string
+			enc  string
+			dst  *string
+		}{
+			{"TLS pin", obfTLSPin, &cfg.TLSPin},
+			{"TLS CA", obfTLSCA, &cfg.TLSCA},
+		} {
+			if v.enc == "" {
+				continue
+			}
+			s, err := obf.Decode(v.enc, passphrase)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "tui: %s decode failed: %v\n", v.name, err)
+				os.Exit(1)
+			}
+			*v.dst = s
+		}
 	}
 
-	// --- Layer: env vars override build-time ---
+	// --- Layer: theme saved by ctrl+t (below any explicit choice) ---
+	if *flagConfig != "" {
+		cfg.ThemeFile = filepath.Join(filepath.Dir(*flagConfig), "theme")
+		if saved := app.ReadThemeFile(cfg.ThemeFile); slices.Contains(app.ThemeNames(), saved) {
+			cfg.Theme = saved
+		}
+		if cfg.HostKey == "" {
+			cfg.HostKey = filepath.Join(filepath.Dir(*flagConfig), "ssh_host_ed25519_key")
+		}
+		if cfg.AuthKeys == "" {
+			cfg.AuthKeys = filepath.Join(filepath.Dir(*flagConfig), "authorized_keys")
+		}
+	}
+
+	// --- Layer: config file profile ---
+	// Key bindings apply in every build; connection profiles only in dev
+	// mode or when one is asked for, so a stray file can't redirect a
+	// production binary.
+	profileName := *flagProfile
+	if profileName == "" {
+		profileName = os.Getenv("NEXUS_TUI_PROFILE")
+	}
+	useProfile := cfg.DevMode || profileName != ""
+	fc, err := loadFileConfig(*flagConfig)
+	switch {
+	case err != nil && useProfile:
+		fmt.Fprintf(os.Stderr, "tui: config: %v\n", err)
+		os.Exit(1)
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "tui: config ignored: %v\n", err)
+	default:
+		cfg.Keys = fc.Keys
+		if fc.Toast != nil {
+			cfg.Toast = *fc.Toast
+		}
+	}
+	if useProfile {
+		p, ok, err := fc.lookup(profileName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "tui: config: %v\n", err)
+			os.Exit(1)
+		}
+		if ok {
+			p.apply(&cfg)
+		}
+	}
+
+	// --- Layer: env vars override build-time and profile ---
 	if v := os.Getenv("HERMIT_ADDR"); v != "" {
 		cfg.HermitAddr = v
 	}
-	if v := os.Getenv("HERMIT_SECRET"); v != "" {
+	if v := secretsmgr.Get("HERMIT_SECRET"); v != "" {
 		cfg.Secret = v
 	}
+	if v := secretsmgr.Get("HERMIT_TOKEN"); v != "" {
+		cfg.Token = v
+	}
+	if v := os.Getenv("HERMIT_BUCKET"); v != "" {
+		cfg.Bucket = v
+	}
 	if v := os.Getenv("SECRETS_URL"); v != "" {
 		cfg.SecretsURL = v
 	}
+	if v := os.Getenv("PORTAL_URL"); v != "" {
+		cfg.PortalURL = v
+	}
+	if v := os.Getenv("LOGS_URL"); v != "" {
+		cfg.LogsURL = v
+	}
+	if v := os.Getenv("NEXUS_TUI_THEME"); v != "" {
+		cfg.Theme = v
+	}
+	if v := os.Getenv("NEXUS_TUI_LANG"); v != "" {
+		cfg.Lang = v
+	}
 	if v := os.Getenv("HERMIT_INSECURE"); v == "1" || v == "true" {
 		cfg.Insecure = true
 	} else if v == "0" || v == "false" {
 		cfg.Insecure = false
 	}
+	if v := os.Getenv("HERMIT_TLS_PIN"); v != "" {
+		cfg.TLSPin = v
+	}
+	if v := os.Getenv("HERMIT_CA_FILE"); v != "" {
+		cfg.TLSCAFile = v
+	}
+	if v := os.Getenv("HERMIT_TLS_WARN"); v == "1" || v == "true" {
+		cfg.TLSWarn = true
+	} else if v == "0" || v == "false" {
+		cfg.TLSWarn = false
+	}
 
 	// --- Layer: CLI flags override everything ---
 	if *flagAddr != "" {
@@ -130,36 +284,154 @@ func resolveConfig() config {
 	if *flagSecret != "" {
 		cfg.Secret = *flagSecret
 	}
+	if *flagToken != "" {
+		cfg.Token = *flagToken
+	}
+	if *flagBucket != "" {
+		cfg.Bucket = *flagBucket
+	}
 	if *flagSecretsURL != "" {
 		cfg.SecretsURL = *flagSecretsURL
 	}
-	// flag.Bool has no "was set" check, so we only override if the flag was
-	// explicitly passed. We use flag.Visit to detect this.
+	if *flagPortalURL != "" {
+		cfg.PortalURL = *flagPortalURL
+	}
+	if *flagLogsURL != "" {
+		cfg.LogsURL = *flagLogsURL
+	}
+	if *flagPin != "" {
+		cfg.TLSPin = *flagPin
+	}
+	if *flagCA != "" {
+		cfg.TLSCAFile = *flagCA
+	}
+	if *flagTheme != "" {
+		cfg.Theme = *flagTheme
+	}
+	if *flagLang != "" {
+		cfg.Lang = *flagLang
+	}
+	// flag.Bool and flag.Duration have no "was set" check, so we only
+	// override if the flag was explicitly passed. We use flag.Visit to
+	// detect this.
 	flag.Visit(func(f *flag.Flag) {
-		if f.Name == "insecure" {
+		switch f.Name {
+		case "insecure":
 			cfg.Insecure = *flagInsecure
+		case "tls-warn":
+			cfg.TLSWarn = *flagTLSWarn
+		case "toast":
+			cfg.Toast = *flagToast
 		}
 	})
 
 	return cfg
 }
 
+// hermitTLS is how the client should check hermit's certificate.
+func (cfg config) hermitTLS() (app.HermitTLS, error) {
+	t := app.HermitTLS{Plaintext: cfg.Insecure, Pin: cfg.TLSPin, CA: []byte(cfg.TLSCA), WarnOnly: cfg.TLSWarn}
+	if cfg.TLSCAFile != "" {
+		ca, err := os.ReadFile(cfg.TLSCAFile)
+		if err != nil {
+			return app.HermitTLS{}, fmt.Errorf("hermit CA: %w", err)
+		}
+		t.CA = ca
+	}
+	return t, nil
+}
+
+// useBucket sends h's document store calls to cfg's bucket, if it names
+// one.
+func (cfg config) useBucket(h app.HermitClient) {
+	if b, ok := h.(app.Bucketer); ok && cfg.Bucket != "" {
+		b.UseBucket(cfg.Bucket)
+	}
+}
+
+// newModel builds a Model from cfg with its own hermit connection, or with
+// simulated backends in demo mode.
+func newModel(cfg config) (app.Model, error) {
+	var (
+		hermitClient  app.HermitClient
+		secretsClient app.SecretsClient
+		portalClient  app.PortalClient
+		addr          = cfg.HermitAddr
+	)
+	if cfg.Demo {
+		hermitClient, secretsClient, addr = app.NewDemoHermitClient(), app.NewDemoSecretsClient(), app.DemoAddr
+		portalClient = app.NewDemoPortalClient()
+	} else {
+		t, err := cfg.hermitTLS()
+		if err != nil {
+			return app.Model{}, err
+		}
+		hermitClient, err = app.NewHermitClient(cfg.HermitAddr, cfg.Secret, t)
+		if err != nil {
+			return app.Model{}, fmt.Errorf("hermit dial: %w", err)
+		}
+		secretsClient = app.NewSecretsClient(cfg.SecretsURL)
+		portalClient = app.NewPortalClient(cfg.PortalURL)
+	}
+	cfg.useBucket(hermitClient)
+
+	m, err := app.New(addr, cfg.Secret, hermitClient, secretsClient).WithTheme(cfg.Theme)
+	if err != nil {
+		hermitClient.Close()
+		return app.Model{}, err
+	}
+	m, err = m.WithLanguage(cfg.Lang)
+	if err != nil {
+		hermitClient.Close()
+		return app.Model{}, err
+	}
+	m, err = m.WithKeyBindings(cfg.Keys)
+	if err != nil {
+		hermitClient.Close()
+		return app.Model{}, fmt.Errorf("config: %w", err)
+	}
+	if cfg.LogsURL != "" {
+		m = m.WithLogSource(cfg.LogsURL, app.NewHTTPLogSource(cfg.LogsURL))
+	}
+	return m.WithThemeFile(cfg.ThemeFile).WithToastDuration(cfg.Toast).WithPortal(portalClient).WithToken(cfg.Token), nil
+}
+
+// main runs the TUI in this terminal. Subcommands:
+//
+//	tui serve --ssh :2222          host the TUI for SSH clients with keys in authorized_keys
+//	tui exec [--json] "kv:get k"   run one command and print the result
 func main() {
-	cfg := resolveConfig()
+	args := os.Args[1:]
+	var sub string
+	if len(args) > 0 && (args[0] == "serve" || args[0] == "exec") {
+		sub, args = args[0], args[1:]
+	}
+	cfg := resolveConfig(args)
 
-	if cfg.DevMode {
+	if sub == "exec" {
+		os.Exit(runExec(cfg))
+	}
+
+	switch {
+	case cfg.Demo:
+		fmt.Fprintln(os.Stderr, "tui: demo mode (simulated backends, nothing leaves this machine)")
+	case cfg.DevMode:
 		fmt.Fprintln(os.Stderr, "tui: dev mode (no build-time config baked in)")
 	}
 
-	hermitClient, err := app.NewHermitClient(cfg.HermitAddr, cfg.Secret, cfg.Insecure)
+	if sub == "serve" {
+		if err := serveSSH(cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "tui: serve: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	m, err := newModel(cfg)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "tui: hermit dial: %v\n", err)
+		fmt.Fprintf(os.Stderr, "tui: %v\n", err)
 		os.Exit(1)
 	}
-
-	secretsClient := app.NewSecretsClient(cfg.SecretsURL)
-
-	m := app.New(cfg.HermitAddr, cfg.Secret, hermitClient, secretsClient)
 	p := tea.NewProgram(m)
 	if _, err := p.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "tui: %v\n", err)
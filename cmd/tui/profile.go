@@ -0,0 +1,175 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// fileConfig is the on-disk TUI configuration. Example:
+//
+//	profile = "local"          # used when --profile is not given
+//
+//	[profiles.local]
+//	hermit_addr = "localhost:9090"
+//	secrets_url = "http://localhost:8081"
+//	portal_url  = "http://localhost:8080"
+//	logs_url    = "http://localhost:8081/logs?follow=1"
+//	insecure    = true
+//	theme       = "solarized"
+//	lang        = "es"         # interface language; see --lang
+//
+//	[profiles.staging]
+//	hermit_addr   = "hermit-staging.example.com:443"
+//	hermit_secret = "..."
+//	hermit_token  = "..."      # this user's token for Login
+//	hermit_bucket = "orders"   # document store bucket; default: hermit's default
+//	hermit_pin    = "sha256:..."  # fingerprint hermit logs at startup, or
+//	hermit_ca     = "/etc/nexus/staging-ca.pem"  # a private CA to verify it with
+//	tls_warn      = true       # warn instead of refusing a bad certificate (dev)
+//
+//	toast = "4s"               # how long notifications stay up; "0s" = off
+//
+//	[keys]                     # remap actions; ? in the TUI lists them
+//	up   = ["up", "w"]
+//	down = ["down", "s"]
+type fileConfig struct {
+	Profile  string              `toml:"profile"`
+	Profiles map[string]profile  `toml:"profiles"`
+	Keys     map[string][]string `toml:"keys"`
+	Toast    *time.Duration      `toml:"toast"`
+}
+
+// profile is one named set of connection settings. Empty fields leave the
+// lower-priority value in place.
+type profile struct {
+	HermitAddr   string `toml:"hermit_addr"`
+	HermitSecret string `toml:"hermit_secret"`
+	HermitToken  string `toml:"hermit_token"`
+	HermitBucket string `toml:"hermit_bucket"`
+	SecretsURL   string `toml:"secrets_url"`
+	PortalURL    string `toml:"portal_url"`
+	LogsURL      string `toml:"logs_url"`
+	Insecure     *bool  `toml:"insecure"`
+	HermitPin    string `toml:"hermit_pin"`
+	HermitCA     string `toml:"hermit_ca"`
+	TLSWarn      *bool  `toml:"tls_warn"`
+	Theme        string `toml:"theme"`
+	Lang         string `toml:"lang"`
+}
+
+// defaultConfigPath returns $XDG_CONFIG_HOME/nexus-tui/config.toml, falling
+// back to ~/.config/nexus-tui/config.toml.
+func defaultConfigPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "nexus-tui", "config.toml")
+}
+
+// loadFileConfig reads the config file at path. A missing file is not an
+// error: it yields an empty config.
+func loadFileConfig(path string) (*fileConfig, error) {
+	fc := &fileConfig{}
+	if path == "" {
+		return fc, nil
+	}
+	md, err := toml.DecodeFile(path, fc)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fc, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, len(undecoded))
+		for i, k := range undecoded {
+			keys[i] = k.String()
+		}
+		return nil, fmt.Errorf("%s: unknown keys: %s", path, strings.Join(keys, ", "))
+	}
+	return fc, nil
+}
+
+// lookup returns the named profile, or the file's default profile when name
+// is empty. ok is false when no profile applies.
+func (fc *fileConfig) lookup(name string) (p profile, ok bool, err error) {
+	if name == "" {
+		name = fc.Profile
+	}
+	if name == "" {
+		return profile{}, false, nil
+	}
+	p, ok = fc.Profiles[name]
+	if !ok {
+		return profile{}, false, fmt.Errorf("unknown profile %q (have: %s)", name, strings.Join(fc.names(), ", "))
+	}
+	return p, true, nil
+}
+
+func (fc *fileConfig) names() []string {
+	names := make([]string, 0, len(fc.Profiles))
+	for n := range fc.Profiles {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// apply layers the profile's non-empty settings over cfg.
+func (p profile) apply(cfg *config) {
+	if p.HermitAddr != "" {
+		cfg.HermitAddr = p.HermitAddr
+	}
+	if p.HermitSecret != "" {
+		cfg.Secret = p.HermitSecret
+	}
+	if p.HermitToken != "" {
+		cfg.Token = p.HermitToken
+	}
+	if p.HermitBucket != "" {
+		cfg.Bucket = p.HermitBucket
+	}
+	if p.SecretsURL != "" {
+		cfg.SecretsURL = p.SecretsURL
+	}
+	if p.PortalURL != "" {
+		cfg.PortalURL = p.PortalURL
+	}
+	if p.LogsURL != "" {
+		cfg.LogsURL = p.LogsURL
+	}
+	if p.Insecure != nil {
+		cfg.Insecure = *p.Insecure
+	}
+	if p.HermitPin != "" {
+		cfg.TLSPin = p.HermitPin
+	}
+	if p.HermitCA != "" {
+		cfg.TLSCAFile = p.HermitCA
+	}
+	if p.TLSWarn != nil {
+		cfg.TLSWarn = *p.TLSWarn
+	}
+	if p.Theme != "" {
+		cfg.Theme = p.Theme
+	}
+	if p.Lang != "" {
+		cfg.Lang = p.Lang
+	}
+}
@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadFileConfig_Profiles(t *testing.T) {
+	path := writeConfig(t, `
+profile = "local"
+toast = "2s"
+
+[profiles.local]
+hermit_addr = "localhost:9999"
+insecure = true
+
+[profiles.staging]
+hermit_addr = "staging:443"
+hermit_secret = "s3cret"
+hermit_token = "t0ken"
+secrets_url = "https://secrets.staging"
+portal_url = "https://portal.staging"
+logs_url = "https://logs.staging/tail"
+insecure = false
+hermit_pin = "sha256:ab"
+hermit_ca = "/etc/staging-ca.pem"
+tls_warn = true
+theme = "light"
+lang = "es"
+
+[keys]
+up = ["up", "w"]
+`)
+	fc, err := loadFileConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config{HermitAddr: "default:1", SecretsURL: "http://default", Insecure: false}
+	p, ok, err := fc.lookup("")
+	if err != nil || !ok {
+		t.Fatalf("default profile: ok=%v err=%v", ok, err)
+	}
+	p.apply(&cfg)
+	if cfg.HermitAddr != "localhost:9999" || !cfg.Insecure || cfg.SecretsURL != "http://default" {
+		t.Errorf("local profile applied as %+v", cfg)
+	}
+
+	p, _, err = fc.lookup("staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.apply(&cfg)
+	want := config{HermitAddr: "staging:443", Secret: "s3cret", Token: "t0ken", SecretsURL: "https://secrets.staging", PortalURL: "https://portal.staging", LogsURL: "https://logs.staging/tail", TLSPin: "sha256:ab", TLSCAFile: "/etc/staging-ca.pem", TLSWarn: true, Theme: "light", Lang: "es"}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("staging profile applied as %+v, want %+v", cfg, want)
+	}
+
+	if got := fc.Keys["up"]; !reflect.DeepEqual(got, []string{"up", "w"}) {
+		t.Errorf("keys.up = %v", got)
+	}
+	if fc.Toast == nil || *fc.Toast != 2*time.Second {
+		t.Errorf("toast = %v, want 2s", fc.Toast)
+	}
+
+	if _, _, err := fc.lookup("nope"); err == nil || !strings.Contains(err.Error(), "local, staging") {
+		t.Errorf("unknown profile error = %v", err)
+	}
+}
+
+func TestLoadFileConfig_Missing(t *testing.T) {
+	fc, err := loadFileConfig(filepath.Join(t.TempDir(), "absent.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := fc.lookup(""); ok || err != nil {
+		t.Errorf("missing file: ok=%v err=%v, want no profile", ok, err)
+	}
+}
+
+func TestLoadFileConfig_UnknownKey(t *testing.T) {
+	path := writeConfig(t, "[profiles.x]\nhermit_adr = \"typo\"\n")
+	if _, err := loadFileConfig(path); err == nil || !strings.Contains(err.Error(), "hermit_adr") {
+		t.Errorf("err = %v, want unknown key error", err)
+	}
+}
@@ -25,6 +25,172 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type SqlQueryRequest_Order int32
+
+const (
+	SqlQueryRequest_ID      SqlQueryRequest_Order = 0 // insertion order
+	SqlQueryRequest_KEY     SqlQueryRequest_Order = 1
+	SqlQueryRequest_VALUE   SqlQueryRequest_Order = 2
+	SqlQueryRequest_CREATED SqlQueryRequest_Order = 3
+)
+
+// Enum value maps for SqlQueryRequest_Order.
+var (
+	SqlQueryRequest_Order_name = map[int32]string{
+		0: "ID",
+		1: "KEY",
+		2: "VALUE",
+		3: "CREATED",
+	}
+	SqlQueryRequest_Order_value = map[string]int32{
+		"ID":      0,
+		"KEY":     1,
+		"VALUE":   2,
+		"CREATED": 3,
+	}
+)
+
+func (x SqlQueryRequest_Order) Enum() *SqlQueryRequest_Order {
+	p := new(SqlQueryRequest_Order)
+	*p = x
+	return p
+}
+
+func (x SqlQueryRequest_Order) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SqlQueryRequest_Order) Descriptor() protoreflect.EnumDescriptor {
+	return file_hermit_proto_enumTypes[0].Descriptor()
+}
+
+func (SqlQueryRequest_Order) Type() protoreflect.EnumType {
+	return &file_hermit_proto_enumTypes[0]
+}
+
+func (x SqlQueryRequest_Order) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SqlQueryRequest_Order.Descriptor instead.
+func (SqlQueryRequest_Order) EnumDescriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{28, 0}
+}
+
+type WatchEvent_Kind int32
+
+const (
+	WatchEvent_SET    WatchEvent_Kind = 0
+	WatchEvent_DELETE WatchEvent_Kind = 1
+	WatchEvent_EXPIRE WatchEvent_Kind = 2
+)
+
+// Enum value maps for WatchEvent_Kind.
+var (
+	WatchEvent_Kind_name = map[int32]string{
+		0: "SET",
+		1: "DELETE",
+		2: "EXPIRE",
+	}
+	WatchEvent_Kind_value = map[string]int32{
+		"SET":    0,
+		"DELETE": 1,
+		"EXPIRE": 2,
+	}
+)
+
+func (x WatchEvent_Kind) Enum() *WatchEvent_Kind {
+	p := new(WatchEvent_Kind)
+	*p = x
+	return p
+}
+
+func (x WatchEvent_Kind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (WatchEvent_Kind) Descriptor() protoreflect.EnumDescriptor {
+	return file_hermit_proto_enumTypes[1].Descriptor()
+}
+
+func (WatchEvent_Kind) Type() protoreflect.EnumType {
+	return &file_hermit_proto_enumTypes[1]
+}
+
+func (x WatchEvent_Kind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use WatchEvent_Kind.Descriptor instead.
+func (WatchEvent_Kind) EnumDescriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{41, 0}
+}
+
+type OplogEntry_Kind int32
+
+const (
+	// A piece of a zstd snapshot of both stores; concatenate in order.
+	OplogEntry_SNAPSHOT OplogEntry_Kind = 0
+	// The snapshot is complete: load it. Changes after it follow.
+	OplogEntry_SNAPSHOT_END OplogEntry_Kind = 1
+	OplogEntry_SET          OplogEntry_Kind = 2
+	OplogEntry_DELETE       OplogEntry_Kind = 3
+	OplogEntry_EXPIRE       OplogEntry_Kind = 4
+	// Nothing changed for a while; seq is where the primary's oplog is.
+	OplogEntry_HEARTBEAT OplogEntry_Kind = 5
+	// The bucket was created. key is empty.
+	OplogEntry_BUCKET OplogEntry_Kind = 6
+)
+
+// Enum value maps for OplogEntry_Kind.
+var (
+	OplogEntry_Kind_name = map[int32]string{
+		0: "SNAPSHOT",
+		1: "SNAPSHOT_END",
+		2: "SET",
+		3: "DELETE",
+		4: "EXPIRE",
+		5: "HEARTBEAT",
+		6: "BUCKET",
+	}
+	OplogEntry_Kind_value = map[string]int32{
+		"SNAPSHOT":     0,
+		"SNAPSHOT_END": 1,
+		"SET":          2,
+		"DELETE":       3,
+		"EXPIRE":       4,
+		"HEARTBEAT":    5,
+		"BUCKET":       6,
+	}
+)
+
+func (x OplogEntry_Kind) Enum() *OplogEntry_Kind {
+	p := new(OplogEntry_Kind)
+	*p = x
+	return p
+}
+
+func (x OplogEntry_Kind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (OplogEntry_Kind) Descriptor() protoreflect.EnumDescriptor {
+	return file_hermit_proto_enumTypes[2].Descriptor()
+}
+
+func (OplogEntry_Kind) Type() protoreflect.EnumType {
+	return &file_hermit_proto_enumTypes[2]
+}
+
+func (x OplogEntry_Kind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use OplogEntry_Kind.Descriptor instead.
+func (OplogEntry_Kind) EnumDescriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{57, 0}
+}
+
 type PingRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Client-side monotonic timestamp in nanoseconds (for RTT calculation).
@@ -296,6 +462,228 @@ func (x *BenchmarkResponse) GetTlsVersion() string {
 	return ""
 }
 
+type HistogramBucket struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Latencies up to this, and above the previous bucket's bound.
+	UpperNs       int64  `protobuf:"varint,1,opt,name=upper_ns,json=upperNs,proto3" json:"upper_ns,omitempty"`
+	Count         uint64 `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HistogramBucket) Reset() {
+	*x = HistogramBucket{}
+	mi := &file_hermit_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HistogramBucket) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HistogramBucket) ProtoMessage() {}
+
+func (x *HistogramBucket) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HistogramBucket.ProtoReflect.Descriptor instead.
+func (*HistogramBucket) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *HistogramBucket) GetUpperNs() int64 {
+	if x != nil {
+		return x.UpperNs
+	}
+	return 0
+}
+
+func (x *HistogramBucket) GetCount() uint64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type BenchmarkProgress struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Iterations finished so far, out of total.
+	Done      uint32 `protobuf:"varint,1,opt,name=done,proto3" json:"done,omitempty"`
+	Total     uint32 `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	ElapsedNs int64  `protobuf:"varint,3,opt,name=elapsed_ns,json=elapsedNs,proto3" json:"elapsed_ns,omitempty"`
+	// Latency stats so far. Percentiles are histogram bucket bounds.
+	MinNs            int64   `protobuf:"varint,4,opt,name=min_ns,json=minNs,proto3" json:"min_ns,omitempty"`
+	MaxNs            int64   `protobuf:"varint,5,opt,name=max_ns,json=maxNs,proto3" json:"max_ns,omitempty"`
+	MeanNs           int64   `protobuf:"varint,6,opt,name=mean_ns,json=meanNs,proto3" json:"mean_ns,omitempty"`
+	P50Ns            int64   `protobuf:"varint,7,opt,name=p50_ns,json=p50Ns,proto3" json:"p50_ns,omitempty"`
+	P90Ns            int64   `protobuf:"varint,8,opt,name=p90_ns,json=p90Ns,proto3" json:"p90_ns,omitempty"`
+	P99Ns            int64   `protobuf:"varint,9,opt,name=p99_ns,json=p99Ns,proto3" json:"p99_ns,omitempty"`
+	P999Ns           int64   `protobuf:"varint,10,opt,name=p999_ns,json=p999Ns,proto3" json:"p999_ns,omitempty"`
+	IterationsPerSec float64 `protobuf:"fixed64,11,opt,name=iterations_per_sec,json=iterationsPerSec,proto3" json:"iterations_per_sec,omitempty"`
+	BytesPerSec      float64 `protobuf:"fixed64,12,opt,name=bytes_per_sec,json=bytesPerSec,proto3" json:"bytes_per_sec,omitempty"`
+	// The non-empty buckets, in order. Each power of two is split into 16
+	// buckets, so a bound is within 6.25% of the latencies it counts.
+	Histogram []*HistogramBucket `protobuf:"bytes,13,rep,name=histogram,proto3" json:"histogram,omitempty"`
+	// Set on the last message.
+	Final         bool   `protobuf:"varint,14,opt,name=final,proto3" json:"final,omitempty"`
+	TlsActive     bool   `protobuf:"varint,15,opt,name=tls_active,json=tlsActive,proto3" json:"tls_active,omitempty"`
+	TlsVersion    string `protobuf:"bytes,16,opt,name=tls_version,json=tlsVersion,proto3" json:"tls_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BenchmarkProgress) Reset() {
+	*x = BenchmarkProgress{}
+	mi := &file_hermit_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BenchmarkProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BenchmarkProgress) ProtoMessage() {}
+
+func (x *BenchmarkProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BenchmarkProgress.ProtoReflect.Descriptor instead.
+func (*BenchmarkProgress) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *BenchmarkProgress) GetDone() uint32 {
+	if x != nil {
+		return x.Done
+	}
+	return 0
+}
+
+func (x *BenchmarkProgress) GetTotal() uint32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *BenchmarkProgress) GetElapsedNs() int64 {
+	if x != nil {
+		return x.ElapsedNs
+	}
+	return 0
+}
+
+func (x *BenchmarkProgress) GetMinNs() int64 {
+	if x != nil {
+		return x.MinNs
+	}
+	return 0
+}
+
+func (x *BenchmarkProgress) GetMaxNs() int64 {
+	if x != nil {
+		return x.MaxNs
+	}
+	return 0
+}
+
+func (x *BenchmarkProgress) GetMeanNs() int64 {
+	if x != nil {
+		return x.MeanNs
+	}
+	return 0
+}
+
+func (x *BenchmarkProgress) GetP50Ns() int64 {
+	if x != nil {
+		return x.P50Ns
+	}
+	return 0
+}
+
+func (x *BenchmarkProgress) GetP90Ns() int64 {
+	if x != nil {
+		return x.P90Ns
+	}
+	return 0
+}
+
+func (x *BenchmarkProgress) GetP99Ns() int64 {
+	if x != nil {
+		return x.P99Ns
+	}
+	return 0
+}
+
+func (x *BenchmarkProgress) GetP999Ns() int64 {
+	if x != nil {
+		return x.P999Ns
+	}
+	return 0
+}
+
+func (x *BenchmarkProgress) GetIterationsPerSec() float64 {
+	if x != nil {
+		return x.IterationsPerSec
+	}
+	return 0
+}
+
+func (x *BenchmarkProgress) GetBytesPerSec() float64 {
+	if x != nil {
+		return x.BytesPerSec
+	}
+	return 0
+}
+
+func (x *BenchmarkProgress) GetHistogram() []*HistogramBucket {
+	if x != nil {
+		return x.Histogram
+	}
+	return nil
+}
+
+func (x *BenchmarkProgress) GetFinal() bool {
+	if x != nil {
+		return x.Final
+	}
+	return false
+}
+
+func (x *BenchmarkProgress) GetTlsActive() bool {
+	if x != nil {
+		return x.TlsActive
+	}
+	return false
+}
+
+func (x *BenchmarkProgress) GetTlsVersion() string {
+	if x != nil {
+		return x.TlsVersion
+	}
+	return ""
+}
+
 type LoginRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Username      string                 `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
@@ -306,7 +694,7 @@ type LoginRequest struct {
 
 func (x *LoginRequest) Reset() {
 	*x = LoginRequest{}
-	mi := &file_hermit_proto_msgTypes[4]
+	mi := &file_hermit_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -318,7 +706,7 @@ func (x *LoginRequest) String() string {
 func (*LoginRequest) ProtoMessage() {}
 
 func (x *LoginRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_hermit_proto_msgTypes[4]
+	mi := &file_hermit_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -331,7 +719,7 @@ func (x *LoginRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LoginRequest.ProtoReflect.Descriptor instead.
 func (*LoginRequest) Descriptor() ([]byte, []int) {
-	return file_hermit_proto_rawDescGZIP(), []int{4}
+	return file_hermit_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *LoginRequest) GetUsername() string {
@@ -353,13 +741,14 @@ type LoginResponse struct {
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 	SessionId     string                 `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
 	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	Role          string                 `protobuf:"bytes,4,opt,name=role,proto3" json:"role,omitempty"` // read, write or admin
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *LoginResponse) Reset() {
 	*x = LoginResponse{}
-	mi := &file_hermit_proto_msgTypes[5]
+	mi := &file_hermit_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -371,7 +760,7 @@ func (x *LoginResponse) String() string {
 func (*LoginResponse) ProtoMessage() {}
 
 func (x *LoginResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_hermit_proto_msgTypes[5]
+	mi := &file_hermit_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -384,7 +773,7 @@ func (x *LoginResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LoginResponse.ProtoReflect.Descriptor instead.
 func (*LoginResponse) Descriptor() ([]byte, []int) {
-	return file_hermit_proto_rawDescGZIP(), []int{5}
+	return file_hermit_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *LoginResponse) GetSuccess() bool {
@@ -408,6 +797,13 @@ func (x *LoginResponse) GetError() string {
 	return ""
 }
 
+func (x *LoginResponse) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
 type ServerInfoRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -416,7 +812,7 @@ type ServerInfoRequest struct {
 
 func (x *ServerInfoRequest) Reset() {
 	*x = ServerInfoRequest{}
-	mi := &file_hermit_proto_msgTypes[6]
+	mi := &file_hermit_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -428,7 +824,7 @@ func (x *ServerInfoRequest) String() string {
 func (*ServerInfoRequest) ProtoMessage() {}
 
 func (x *ServerInfoRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_hermit_proto_msgTypes[6]
+	mi := &file_hermit_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -441,7 +837,7 @@ func (x *ServerInfoRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ServerInfoRequest.ProtoReflect.Descriptor instead.
 func (*ServerInfoRequest) Descriptor() ([]byte, []int) {
-	return file_hermit_proto_rawDescGZIP(), []int{6}
+	return file_hermit_proto_rawDescGZIP(), []int{8}
 }
 
 type ServerInfoResponse struct {
@@ -453,13 +849,14 @@ type ServerInfoResponse struct {
 	RustVersion   string                 `protobuf:"bytes,5,opt,name=rust_version,json=rustVersion,proto3" json:"rust_version,omitempty"`
 	TlsEnabled    bool                   `protobuf:"varint,6,opt,name=tls_enabled,json=tlsEnabled,proto3" json:"tls_enabled,omitempty"`
 	GrpcPort      uint32                 `protobuf:"varint,7,opt,name=grpc_port,json=grpcPort,proto3" json:"grpc_port,omitempty"`
+	TcpPort       uint32                 `protobuf:"varint,8,opt,name=tcp_port,json=tcpPort,proto3" json:"tcp_port,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ServerInfoResponse) Reset() {
 	*x = ServerInfoResponse{}
-	mi := &file_hermit_proto_msgTypes[7]
+	mi := &file_hermit_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -471,7 +868,7 @@ func (x *ServerInfoResponse) String() string {
 func (*ServerInfoResponse) ProtoMessage() {}
 
 func (x *ServerInfoResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_hermit_proto_msgTypes[7]
+	mi := &file_hermit_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -484,7 +881,7 @@ func (x *ServerInfoResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ServerInfoResponse.ProtoReflect.Descriptor instead.
 func (*ServerInfoResponse) Descriptor() ([]byte, []int) {
-	return file_hermit_proto_rawDescGZIP(), []int{7}
+	return file_hermit_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *ServerInfoResponse) GetVersion() string {
@@ -536,17 +933,26 @@ func (x *ServerInfoResponse) GetGrpcPort() uint32 {
 	return 0
 }
 
+func (x *ServerInfoResponse) GetTcpPort() uint32 {
+	if x != nil {
+		return x.TcpPort
+	}
+	return 0
+}
+
 type KvSetRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
-	Value         []byte                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Key   string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value []byte                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// Milliseconds until the key expires (0 = never).
+	TtlMs         uint64 `protobuf:"varint,3,opt,name=ttl_ms,json=ttlMs,proto3" json:"ttl_ms,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *KvSetRequest) Reset() {
 	*x = KvSetRequest{}
-	mi := &file_hermit_proto_msgTypes[8]
+	mi := &file_hermit_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -558,7 +964,7 @@ func (x *KvSetRequest) String() string {
 func (*KvSetRequest) ProtoMessage() {}
 
 func (x *KvSetRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_hermit_proto_msgTypes[8]
+	mi := &file_hermit_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -571,7 +977,7 @@ func (x *KvSetRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use KvSetRequest.ProtoReflect.Descriptor instead.
 func (*KvSetRequest) Descriptor() ([]byte, []int) {
-	return file_hermit_proto_rawDescGZIP(), []int{8}
+	return file_hermit_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *KvSetRequest) GetKey() string {
@@ -588,17 +994,26 @@ func (x *KvSetRequest) GetValue() []byte {
 	return nil
 }
 
+func (x *KvSetRequest) GetTtlMs() uint64 {
+	if x != nil {
+		return x.TtlMs
+	}
+	return 0
+}
+
 type KvSetResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Ok            bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
-	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Ok    bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	Error string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	// The key's version after the write.
+	Version       uint64 `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *KvSetResponse) Reset() {
 	*x = KvSetResponse{}
-	mi := &file_hermit_proto_msgTypes[9]
+	mi := &file_hermit_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -610,7 +1025,7 @@ func (x *KvSetResponse) String() string {
 func (*KvSetResponse) ProtoMessage() {}
 
 func (x *KvSetResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_hermit_proto_msgTypes[9]
+	mi := &file_hermit_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -623,7 +1038,7 @@ func (x *KvSetResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use KvSetResponse.ProtoReflect.Descriptor instead.
 func (*KvSetResponse) Descriptor() ([]byte, []int) {
-	return file_hermit_proto_rawDescGZIP(), []int{9}
+	return file_hermit_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *KvSetResponse) GetOk() bool {
@@ -640,6 +1055,13 @@ func (x *KvSetResponse) GetError() string {
 	return ""
 }
 
+func (x *KvSetResponse) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
 type KvGetRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
@@ -649,7 +1071,7 @@ type KvGetRequest struct {
 
 func (x *KvGetRequest) Reset() {
 	*x = KvGetRequest{}
-	mi := &file_hermit_proto_msgTypes[10]
+	mi := &file_hermit_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -661,7 +1083,7 @@ func (x *KvGetRequest) String() string {
 func (*KvGetRequest) ProtoMessage() {}
 
 func (x *KvGetRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_hermit_proto_msgTypes[10]
+	mi := &file_hermit_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -674,7 +1096,7 @@ func (x *KvGetRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use KvGetRequest.ProtoReflect.Descriptor instead.
 func (*KvGetRequest) Descriptor() ([]byte, []int) {
-	return file_hermit_proto_rawDescGZIP(), []int{10}
+	return file_hermit_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *KvGetRequest) GetKey() string {
@@ -685,17 +1107,19 @@ func (x *KvGetRequest) GetKey() string {
 }
 
 type KvGetResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Found         bool                   `protobuf:"varint,1,opt,name=found,proto3" json:"found,omitempty"`
-	Value         []byte                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
-	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Found bool                   `protobuf:"varint,1,opt,name=found,proto3" json:"found,omitempty"`
+	Value []byte                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	Error string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	// Starts at 1 when the key is created and goes up by one on each write.
+	Version       uint64 `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *KvGetResponse) Reset() {
 	*x = KvGetResponse{}
-	mi := &file_hermit_proto_msgTypes[11]
+	mi := &file_hermit_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -707,7 +1131,7 @@ func (x *KvGetResponse) String() string {
 func (*KvGetResponse) ProtoMessage() {}
 
 func (x *KvGetResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_hermit_proto_msgTypes[11]
+	mi := &file_hermit_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -720,7 +1144,7 @@ func (x *KvGetResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use KvGetResponse.ProtoReflect.Descriptor instead.
 func (*KvGetResponse) Descriptor() ([]byte, []int) {
-	return file_hermit_proto_rawDescGZIP(), []int{11}
+	return file_hermit_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *KvGetResponse) GetFound() bool {
@@ -744,15 +1168,28 @@ func (x *KvGetResponse) GetError() string {
 	return ""
 }
 
+func (x *KvGetResponse) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
 type KvListRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only keys starting with this. Empty = all keys.
+	Prefix string `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	// Max keys to return (0 = server default of 1000, which is also the cap).
+	Limit uint32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	// Resume after this key: the next_cursor of the previous page.
+	Cursor        string `protobuf:"bytes,3,opt,name=cursor,proto3" json:"cursor,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *KvListRequest) Reset() {
 	*x = KvListRequest{}
-	mi := &file_hermit_proto_msgTypes[12]
+	mi := &file_hermit_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -764,7 +1201,7 @@ func (x *KvListRequest) String() string {
 func (*KvListRequest) ProtoMessage() {}
 
 func (x *KvListRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_hermit_proto_msgTypes[12]
+	mi := &file_hermit_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -777,19 +1214,43 @@ func (x *KvListRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use KvListRequest.ProtoReflect.Descriptor instead.
 func (*KvListRequest) Descriptor() ([]byte, []int) {
-	return file_hermit_proto_rawDescGZIP(), []int{12}
+	return file_hermit_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *KvListRequest) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *KvListRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *KvListRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
 }
 
 type KvListResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Keys          []string               `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Sorted.
+	Keys []string `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	// Set when more keys match; pass it back as cursor for the next page.
+	NextCursor    string `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *KvListResponse) Reset() {
 	*x = KvListResponse{}
-	mi := &file_hermit_proto_msgTypes[13]
+	mi := &file_hermit_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -801,7 +1262,7 @@ func (x *KvListResponse) String() string {
 func (*KvListResponse) ProtoMessage() {}
 
 func (x *KvListResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_hermit_proto_msgTypes[13]
+	mi := &file_hermit_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -814,7 +1275,7 @@ func (x *KvListResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use KvListResponse.ProtoReflect.Descriptor instead.
 func (*KvListResponse) Descriptor() ([]byte, []int) {
-	return file_hermit_proto_rawDescGZIP(), []int{13}
+	return file_hermit_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *KvListResponse) GetKeys() []string {
@@ -824,29 +1285,35 @@ func (x *KvListResponse) GetKeys() []string {
 	return nil
 }
 
-type SqlInsertRequest struct {
+func (x *KvListResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type KvDeleteRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
-	Value         string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SqlInsertRequest) Reset() {
-	*x = SqlInsertRequest{}
-	mi := &file_hermit_proto_msgTypes[14]
+func (x *KvDeleteRequest) Reset() {
+	*x = KvDeleteRequest{}
+	mi := &file_hermit_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SqlInsertRequest) String() string {
+func (x *KvDeleteRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SqlInsertRequest) ProtoMessage() {}
+func (*KvDeleteRequest) ProtoMessage() {}
 
-func (x *SqlInsertRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_hermit_proto_msgTypes[14]
+func (x *KvDeleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -857,48 +1324,42 @@ func (x *SqlInsertRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SqlInsertRequest.ProtoReflect.Descriptor instead.
-func (*SqlInsertRequest) Descriptor() ([]byte, []int) {
-	return file_hermit_proto_rawDescGZIP(), []int{14}
+// Deprecated: Use KvDeleteRequest.ProtoReflect.Descriptor instead.
+func (*KvDeleteRequest) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{16}
 }
 
-func (x *SqlInsertRequest) GetKey() string {
+func (x *KvDeleteRequest) GetKey() string {
 	if x != nil {
 		return x.Key
 	}
 	return ""
 }
 
-func (x *SqlInsertRequest) GetValue() string {
-	if x != nil {
-		return x.Value
-	}
-	return ""
-}
-
-type SqlInsertResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Queued        bool                   `protobuf:"varint,1,opt,name=queued,proto3" json:"queued,omitempty"`
-	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+type KvDeleteResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// False if the key was not set.
+	Deleted       bool   `protobuf:"varint,1,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	Error         string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SqlInsertResponse) Reset() {
-	*x = SqlInsertResponse{}
-	mi := &file_hermit_proto_msgTypes[15]
+func (x *KvDeleteResponse) Reset() {
+	*x = KvDeleteResponse{}
+	mi := &file_hermit_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SqlInsertResponse) String() string {
+func (x *KvDeleteResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SqlInsertResponse) ProtoMessage() {}
+func (*KvDeleteResponse) ProtoMessage() {}
 
-func (x *SqlInsertResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_hermit_proto_msgTypes[15]
+func (x *KvDeleteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -909,50 +1370,47 @@ func (x *SqlInsertResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SqlInsertResponse.ProtoReflect.Descriptor instead.
-func (*SqlInsertResponse) Descriptor() ([]byte, []int) {
-	return file_hermit_proto_rawDescGZIP(), []int{15}
+// Deprecated: Use KvDeleteResponse.ProtoReflect.Descriptor instead.
+func (*KvDeleteResponse) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{17}
 }
 
-func (x *SqlInsertResponse) GetQueued() bool {
+func (x *KvDeleteResponse) GetDeleted() bool {
 	if x != nil {
-		return x.Queued
+		return x.Deleted
 	}
 	return false
 }
 
-func (x *SqlInsertResponse) GetError() string {
+func (x *KvDeleteResponse) GetError() string {
 	if x != nil {
 		return x.Error
 	}
 	return ""
 }
 
-type SqlQueryRequest struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// If set, filter rows where key = this value. Empty = return all rows.
-	KeyFilter string `protobuf:"bytes,1,opt,name=key_filter,json=keyFilter,proto3" json:"key_filter,omitempty"`
-	// Max rows to return (0 = no limit).
-	Limit         uint32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+type KvExistsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SqlQueryRequest) Reset() {
-	*x = SqlQueryRequest{}
-	mi := &file_hermit_proto_msgTypes[16]
+func (x *KvExistsRequest) Reset() {
+	*x = KvExistsRequest{}
+	mi := &file_hermit_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SqlQueryRequest) String() string {
+func (x *KvExistsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SqlQueryRequest) ProtoMessage() {}
+func (*KvExistsRequest) ProtoMessage() {}
 
-func (x *SqlQueryRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_hermit_proto_msgTypes[16]
+func (x *KvExistsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -963,50 +1421,41 @@ func (x *SqlQueryRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SqlQueryRequest.ProtoReflect.Descriptor instead.
-func (*SqlQueryRequest) Descriptor() ([]byte, []int) {
-	return file_hermit_proto_rawDescGZIP(), []int{16}
+// Deprecated: Use KvExistsRequest.ProtoReflect.Descriptor instead.
+func (*KvExistsRequest) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{18}
 }
 
-func (x *SqlQueryRequest) GetKeyFilter() string {
+func (x *KvExistsRequest) GetKey() string {
 	if x != nil {
-		return x.KeyFilter
+		return x.Key
 	}
 	return ""
 }
 
-func (x *SqlQueryRequest) GetLimit() uint32 {
-	if x != nil {
-		return x.Limit
-	}
-	return 0
-}
-
-type SqlRow struct {
+type KvExistsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Key           string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
-	Value         string                 `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
-	CreatedAtMs   uint64                 `protobuf:"varint,4,opt,name=created_at_ms,json=createdAtMs,proto3" json:"created_at_ms,omitempty"`
+	Exists        bool                   `protobuf:"varint,1,opt,name=exists,proto3" json:"exists,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SqlRow) Reset() {
-	*x = SqlRow{}
-	mi := &file_hermit_proto_msgTypes[17]
+func (x *KvExistsResponse) Reset() {
+	*x = KvExistsResponse{}
+	mi := &file_hermit_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SqlRow) String() string {
+func (x *KvExistsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SqlRow) ProtoMessage() {}
+func (*KvExistsResponse) ProtoMessage() {}
 
-func (x *SqlRow) ProtoReflect() protoreflect.Message {
-	mi := &file_hermit_proto_msgTypes[17]
+func (x *KvExistsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1017,63 +1466,96 @@ func (x *SqlRow) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SqlRow.ProtoReflect.Descriptor instead.
-func (*SqlRow) Descriptor() ([]byte, []int) {
-	return file_hermit_proto_rawDescGZIP(), []int{17}
+// Deprecated: Use KvExistsResponse.ProtoReflect.Descriptor instead.
+func (*KvExistsResponse) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{19}
 }
 
-func (x *SqlRow) GetId() string {
+func (x *KvExistsResponse) GetExists() bool {
 	if x != nil {
-		return x.Id
+		return x.Exists
 	}
-	return ""
+	return false
 }
 
-func (x *SqlRow) GetKey() string {
+func (x *KvExistsResponse) GetError() string {
 	if x != nil {
-		return x.Key
+		return x.Error
 	}
 	return ""
 }
 
-func (x *SqlRow) GetValue() string {
+type KvTTLRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KvTTLRequest) Reset() {
+	*x = KvTTLRequest{}
+	mi := &file_hermit_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KvTTLRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KvTTLRequest) ProtoMessage() {}
+
+func (x *KvTTLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[20]
 	if x != nil {
-		return x.Value
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *SqlRow) GetCreatedAtMs() uint64 {
+// Deprecated: Use KvTTLRequest.ProtoReflect.Descriptor instead.
+func (*KvTTLRequest) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *KvTTLRequest) GetKey() string {
 	if x != nil {
-		return x.CreatedAtMs
+		return x.Key
 	}
-	return 0
+	return ""
 }
 
-type SqlQueryResponse struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	Rows           []*SqlRow              `protobuf:"bytes,1,rep,name=rows,proto3" json:"rows,omitempty"`
-	TotalCommitted uint64                 `protobuf:"varint,2,opt,name=total_committed,json=totalCommitted,proto3" json:"total_committed,omitempty"`
-	PendingWrites  uint64                 `protobuf:"varint,3,opt,name=pending_writes,json=pendingWrites,proto3" json:"pending_writes,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+type KvTTLResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Found bool                   `protobuf:"varint,1,opt,name=found,proto3" json:"found,omitempty"`
+	// False if the key never expires.
+	Expires bool `protobuf:"varint,2,opt,name=expires,proto3" json:"expires,omitempty"`
+	// Milliseconds left before the key expires.
+	TtlMs         uint64 `protobuf:"varint,3,opt,name=ttl_ms,json=ttlMs,proto3" json:"ttl_ms,omitempty"`
+	Error         string `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SqlQueryResponse) Reset() {
-	*x = SqlQueryResponse{}
-	mi := &file_hermit_proto_msgTypes[18]
+func (x *KvTTLResponse) Reset() {
+	*x = KvTTLResponse{}
+	mi := &file_hermit_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SqlQueryResponse) String() string {
+func (x *KvTTLResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SqlQueryResponse) ProtoMessage() {}
+func (*KvTTLResponse) ProtoMessage() {}
 
-func (x *SqlQueryResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_hermit_proto_msgTypes[18]
+func (x *KvTTLResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1084,53 +1566,2748 @@ func (x *SqlQueryResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SqlQueryResponse.ProtoReflect.Descriptor instead.
-func (*SqlQueryResponse) Descriptor() ([]byte, []int) {
-	return file_hermit_proto_rawDescGZIP(), []int{18}
+// Deprecated: Use KvTTLResponse.ProtoReflect.Descriptor instead.
+func (*KvTTLResponse) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{21}
 }
 
-func (x *SqlQueryResponse) GetRows() []*SqlRow {
+func (x *KvTTLResponse) GetFound() bool {
 	if x != nil {
-		return x.Rows
+		return x.Found
 	}
-	return nil
+	return false
 }
 
-func (x *SqlQueryResponse) GetTotalCommitted() uint64 {
+func (x *KvTTLResponse) GetExpires() bool {
 	if x != nil {
-		return x.TotalCommitted
+		return x.Expires
 	}
-	return 0
+	return false
 }
 
-func (x *SqlQueryResponse) GetPendingWrites() uint64 {
+func (x *KvTTLResponse) GetTtlMs() uint64 {
 	if x != nil {
-		return x.PendingWrites
+		return x.TtlMs
 	}
 	return 0
 }
 
-type DbStatsRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
+func (x *KvTTLResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type TxnGuard struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Key   string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// Holds if the key is at this version (0 = not set).
+	Version *uint64 `protobuf:"varint,2,opt,name=version,proto3,oneof" json:"version,omitempty"`
+	// Holds if the key is set to this value.
+	Value         []byte `protobuf:"bytes,3,opt,name=value,proto3,oneof" json:"value,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DbStatsRequest) Reset() {
-	*x = DbStatsRequest{}
-	mi := &file_hermit_proto_msgTypes[19]
+func (x *TxnGuard) Reset() {
+	*x = TxnGuard{}
+	mi := &file_hermit_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DbStatsRequest) String() string {
+func (x *TxnGuard) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TxnGuard) ProtoMessage() {}
+
+func (x *TxnGuard) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TxnGuard.ProtoReflect.Descriptor instead.
+func (*TxnGuard) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *TxnGuard) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *TxnGuard) GetVersion() uint64 {
+	if x != nil && x.Version != nil {
+		return *x.Version
+	}
+	return 0
+}
+
+func (x *TxnGuard) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+type TxnWrite struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Key   string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value []byte                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// Delete the key instead of setting it.
+	Delete bool `protobuf:"varint,3,opt,name=delete,proto3" json:"delete,omitempty"`
+	// As in KvSetRequest.
+	TtlMs         uint64 `protobuf:"varint,4,opt,name=ttl_ms,json=ttlMs,proto3" json:"ttl_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TxnWrite) Reset() {
+	*x = TxnWrite{}
+	mi := &file_hermit_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TxnWrite) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TxnWrite) ProtoMessage() {}
+
+func (x *TxnWrite) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TxnWrite.ProtoReflect.Descriptor instead.
+func (*TxnWrite) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *TxnWrite) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *TxnWrite) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *TxnWrite) GetDelete() bool {
+	if x != nil {
+		return x.Delete
+	}
+	return false
+}
+
+func (x *TxnWrite) GetTtlMs() uint64 {
+	if x != nil {
+		return x.TtlMs
+	}
+	return 0
+}
+
+type TxnRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Guards        []*TxnGuard            `protobuf:"bytes,1,rep,name=guards,proto3" json:"guards,omitempty"`
+	Writes        []*TxnWrite            `protobuf:"bytes,2,rep,name=writes,proto3" json:"writes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TxnRequest) Reset() {
+	*x = TxnRequest{}
+	mi := &file_hermit_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TxnRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TxnRequest) ProtoMessage() {}
+
+func (x *TxnRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TxnRequest.ProtoReflect.Descriptor instead.
+func (*TxnRequest) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *TxnRequest) GetGuards() []*TxnGuard {
+	if x != nil {
+		return x.Guards
+	}
+	return nil
+}
+
+func (x *TxnRequest) GetWrites() []*TxnWrite {
+	if x != nil {
+		return x.Writes
+	}
+	return nil
+}
+
+type TxnResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// False if a guard failed, in which case nothing was written.
+	Ok bool `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	// Index of the first guard that failed.
+	FailedGuard uint32 `protobuf:"varint,2,opt,name=failed_guard,json=failedGuard,proto3" json:"failed_guard,omitempty"`
+	// Each write's key version after the txn (0 for deletes).
+	Versions      []uint64 `protobuf:"varint,3,rep,packed,name=versions,proto3" json:"versions,omitempty"`
+	Error         string   `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TxnResponse) Reset() {
+	*x = TxnResponse{}
+	mi := &file_hermit_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TxnResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TxnResponse) ProtoMessage() {}
+
+func (x *TxnResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TxnResponse.ProtoReflect.Descriptor instead.
+func (*TxnResponse) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *TxnResponse) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+func (x *TxnResponse) GetFailedGuard() uint32 {
+	if x != nil {
+		return x.FailedGuard
+	}
+	return 0
+}
+
+func (x *TxnResponse) GetVersions() []uint64 {
+	if x != nil {
+		return x.Versions
+	}
+	return nil
+}
+
+func (x *TxnResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type SqlInsertRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value         string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SqlInsertRequest) Reset() {
+	*x = SqlInsertRequest{}
+	mi := &file_hermit_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SqlInsertRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SqlInsertRequest) ProtoMessage() {}
+
+func (x *SqlInsertRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SqlInsertRequest.ProtoReflect.Descriptor instead.
+func (*SqlInsertRequest) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *SqlInsertRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *SqlInsertRequest) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+type SqlInsertResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Queued        bool                   `protobuf:"varint,1,opt,name=queued,proto3" json:"queued,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SqlInsertResponse) Reset() {
+	*x = SqlInsertResponse{}
+	mi := &file_hermit_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SqlInsertResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SqlInsertResponse) ProtoMessage() {}
+
+func (x *SqlInsertResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SqlInsertResponse.ProtoReflect.Descriptor instead.
+func (*SqlInsertResponse) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *SqlInsertResponse) GetQueued() bool {
+	if x != nil {
+		return x.Queued
+	}
+	return false
+}
+
+func (x *SqlInsertResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type SqlQueryRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// If set, filter rows where key = this value. Empty = return all rows.
+	KeyFilter string `protobuf:"bytes,1,opt,name=key_filter,json=keyFilter,proto3" json:"key_filter,omitempty"`
+	// Max rows to return (0 = no limit).
+	Limit uint32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	// If set, only rows whose value contains this.
+	ValueFilter string                `protobuf:"bytes,3,opt,name=value_filter,json=valueFilter,proto3" json:"value_filter,omitempty"`
+	OrderBy     SqlQueryRequest_Order `protobuf:"varint,4,opt,name=order_by,json=orderBy,proto3,enum=hermit.SqlQueryRequest_Order" json:"order_by,omitempty"`
+	Desc        bool                  `protobuf:"varint,5,opt,name=desc,proto3" json:"desc,omitempty"`
+	// Matching rows to skip, after ordering, before limit.
+	Offset uint32 `protobuf:"varint,6,opt,name=offset,proto3" json:"offset,omitempty"`
+	// Count the matching rows without returning them.
+	CountOnly     bool `protobuf:"varint,7,opt,name=count_only,json=countOnly,proto3" json:"count_only,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SqlQueryRequest) Reset() {
+	*x = SqlQueryRequest{}
+	mi := &file_hermit_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SqlQueryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SqlQueryRequest) ProtoMessage() {}
+
+func (x *SqlQueryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SqlQueryRequest.ProtoReflect.Descriptor instead.
+func (*SqlQueryRequest) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *SqlQueryRequest) GetKeyFilter() string {
+	if x != nil {
+		return x.KeyFilter
+	}
+	return ""
+}
+
+func (x *SqlQueryRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *SqlQueryRequest) GetValueFilter() string {
+	if x != nil {
+		return x.ValueFilter
+	}
+	return ""
+}
+
+func (x *SqlQueryRequest) GetOrderBy() SqlQueryRequest_Order {
+	if x != nil {
+		return x.OrderBy
+	}
+	return SqlQueryRequest_ID
+}
+
+func (x *SqlQueryRequest) GetDesc() bool {
+	if x != nil {
+		return x.Desc
+	}
+	return false
+}
+
+func (x *SqlQueryRequest) GetOffset() uint32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *SqlQueryRequest) GetCountOnly() bool {
+	if x != nil {
+		return x.CountOnly
+	}
+	return false
+}
+
+type SqlRow struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Key           string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	Value         string                 `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	CreatedAtMs   uint64                 `protobuf:"varint,4,opt,name=created_at_ms,json=createdAtMs,proto3" json:"created_at_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SqlRow) Reset() {
+	*x = SqlRow{}
+	mi := &file_hermit_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SqlRow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SqlRow) ProtoMessage() {}
+
+func (x *SqlRow) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SqlRow.ProtoReflect.Descriptor instead.
+func (*SqlRow) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *SqlRow) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SqlRow) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *SqlRow) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *SqlRow) GetCreatedAtMs() uint64 {
+	if x != nil {
+		return x.CreatedAtMs
+	}
+	return 0
+}
+
+type SqlQueryResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Rows           []*SqlRow              `protobuf:"bytes,1,rep,name=rows,proto3" json:"rows,omitempty"`
+	TotalCommitted uint64                 `protobuf:"varint,2,opt,name=total_committed,json=totalCommitted,proto3" json:"total_committed,omitempty"`
+	PendingWrites  uint64                 `protobuf:"varint,3,opt,name=pending_writes,json=pendingWrites,proto3" json:"pending_writes,omitempty"`
+	// Rows matching the filters, ignoring offset and limit.
+	Matched       uint64 `protobuf:"varint,4,opt,name=matched,proto3" json:"matched,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SqlQueryResponse) Reset() {
+	*x = SqlQueryResponse{}
+	mi := &file_hermit_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SqlQueryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SqlQueryResponse) ProtoMessage() {}
+
+func (x *SqlQueryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SqlQueryResponse.ProtoReflect.Descriptor instead.
+func (*SqlQueryResponse) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *SqlQueryResponse) GetRows() []*SqlRow {
+	if x != nil {
+		return x.Rows
+	}
+	return nil
+}
+
+func (x *SqlQueryResponse) GetTotalCommitted() uint64 {
+	if x != nil {
+		return x.TotalCommitted
+	}
+	return 0
+}
+
+func (x *SqlQueryResponse) GetPendingWrites() uint64 {
+	if x != nil {
+		return x.PendingWrites
+	}
+	return 0
+}
+
+func (x *SqlQueryResponse) GetMatched() uint64 {
+	if x != nil {
+		return x.Matched
+	}
+	return 0
+}
+
+type SqlDeleteRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Deletes every row with exactly this key.
+	Key           string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SqlDeleteRequest) Reset() {
+	*x = SqlDeleteRequest{}
+	mi := &file_hermit_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SqlDeleteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SqlDeleteRequest) ProtoMessage() {}
+
+func (x *SqlDeleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SqlDeleteRequest.ProtoReflect.Descriptor instead.
+func (*SqlDeleteRequest) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *SqlDeleteRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+type SqlDeleteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rows          uint64                 `protobuf:"varint,1,opt,name=rows,proto3" json:"rows,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SqlDeleteResponse) Reset() {
+	*x = SqlDeleteResponse{}
+	mi := &file_hermit_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SqlDeleteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SqlDeleteResponse) ProtoMessage() {}
+
+func (x *SqlDeleteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SqlDeleteResponse.ProtoReflect.Descriptor instead.
+func (*SqlDeleteResponse) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *SqlDeleteResponse) GetRows() uint64 {
+	if x != nil {
+		return x.Rows
+	}
+	return 0
+}
+
+func (x *SqlDeleteResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type SqlUpdateRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Sets the value of every row with exactly this key.
+	Key           string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value         string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SqlUpdateRequest) Reset() {
+	*x = SqlUpdateRequest{}
+	mi := &file_hermit_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SqlUpdateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SqlUpdateRequest) ProtoMessage() {}
+
+func (x *SqlUpdateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SqlUpdateRequest.ProtoReflect.Descriptor instead.
+func (*SqlUpdateRequest) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *SqlUpdateRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *SqlUpdateRequest) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+type SqlUpdateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rows          uint64                 `protobuf:"varint,1,opt,name=rows,proto3" json:"rows,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SqlUpdateResponse) Reset() {
+	*x = SqlUpdateResponse{}
+	mi := &file_hermit_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SqlUpdateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SqlUpdateResponse) ProtoMessage() {}
+
+func (x *SqlUpdateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SqlUpdateResponse.ProtoReflect.Descriptor instead.
+func (*SqlUpdateResponse) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *SqlUpdateResponse) GetRows() uint64 {
+	if x != nil {
+		return x.Rows
+	}
+	return 0
+}
+
+func (x *SqlUpdateResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type DbStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DbStatsRequest) Reset() {
+	*x = DbStatsRequest{}
+	mi := &file_hermit_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DbStatsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
 func (*DbStatsRequest) ProtoMessage() {}
 
-func (x *DbStatsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_hermit_proto_msgTypes[19]
+func (x *DbStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DbStatsRequest.ProtoReflect.Descriptor instead.
+func (*DbStatsRequest) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{35}
+}
+
+type DbStatsResponse struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	DocKeyCount uint64                 `protobuf:"varint,1,opt,name=doc_key_count,json=docKeyCount,proto3" json:"doc_key_count,omitempty"`
+	// What the document store's values take as stored, compressed.
+	DocCompressedBytes uint64 `protobuf:"varint,2,opt,name=doc_compressed_bytes,json=docCompressedBytes,proto3" json:"doc_compressed_bytes,omitempty"`
+	RelRowCount        uint64 `protobuf:"varint,3,opt,name=rel_row_count,json=relRowCount,proto3" json:"rel_row_count,omitempty"`
+	RelPendingWrites   uint64 `protobuf:"varint,4,opt,name=rel_pending_writes,json=relPendingWrites,proto3" json:"rel_pending_writes,omitempty"`
+	// What they would take uncompressed.
+	DocRawBytes uint64 `protobuf:"varint,5,opt,name=doc_raw_bytes,json=docRawBytes,proto3" json:"doc_raw_bytes,omitempty"`
+	// Compression by namespace: each given a codec with --ns-codec, in
+	// order, then every other key under "".
+	Namespaces    []*DocNamespace `protobuf:"bytes,6,rep,name=namespaces,proto3" json:"namespaces,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DbStatsResponse) Reset() {
+	*x = DbStatsResponse{}
+	mi := &file_hermit_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DbStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DbStatsResponse) ProtoMessage() {}
+
+func (x *DbStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DbStatsResponse.ProtoReflect.Descriptor instead.
+func (*DbStatsResponse) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *DbStatsResponse) GetDocKeyCount() uint64 {
+	if x != nil {
+		return x.DocKeyCount
+	}
+	return 0
+}
+
+func (x *DbStatsResponse) GetDocCompressedBytes() uint64 {
+	if x != nil {
+		return x.DocCompressedBytes
+	}
+	return 0
+}
+
+func (x *DbStatsResponse) GetRelRowCount() uint64 {
+	if x != nil {
+		return x.RelRowCount
+	}
+	return 0
+}
+
+func (x *DbStatsResponse) GetRelPendingWrites() uint64 {
+	if x != nil {
+		return x.RelPendingWrites
+	}
+	return 0
+}
+
+func (x *DbStatsResponse) GetDocRawBytes() uint64 {
+	if x != nil {
+		return x.DocRawBytes
+	}
+	return 0
+}
+
+func (x *DbStatsResponse) GetNamespaces() []*DocNamespace {
+	if x != nil {
+		return x.Namespaces
+	}
+	return nil
+}
+
+type DocNamespace struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The part of a key before its first ':'; "" for the rest.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// zstd, lz4 or none.
+	Codec         string `protobuf:"bytes,2,opt,name=codec,proto3" json:"codec,omitempty"`
+	Keys          uint64 `protobuf:"varint,3,opt,name=keys,proto3" json:"keys,omitempty"`
+	RawBytes      uint64 `protobuf:"varint,4,opt,name=raw_bytes,json=rawBytes,proto3" json:"raw_bytes,omitempty"`
+	StoredBytes   uint64 `protobuf:"varint,5,opt,name=stored_bytes,json=storedBytes,proto3" json:"stored_bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DocNamespace) Reset() {
+	*x = DocNamespace{}
+	mi := &file_hermit_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DocNamespace) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DocNamespace) ProtoMessage() {}
+
+func (x *DocNamespace) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DocNamespace.ProtoReflect.Descriptor instead.
+func (*DocNamespace) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *DocNamespace) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DocNamespace) GetCodec() string {
+	if x != nil {
+		return x.Codec
+	}
+	return ""
+}
+
+func (x *DocNamespace) GetKeys() uint64 {
+	if x != nil {
+		return x.Keys
+	}
+	return 0
+}
+
+func (x *DocNamespace) GetRawBytes() uint64 {
+	if x != nil {
+		return x.RawBytes
+	}
+	return 0
+}
+
+func (x *DocNamespace) GetStoredBytes() uint64 {
+	if x != nil {
+		return x.StoredBytes
+	}
+	return 0
+}
+
+type TailLogsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Buffered lines to send before following (0 = only new lines).
+	Backlog       uint32 `protobuf:"varint,1,opt,name=backlog,proto3" json:"backlog,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TailLogsRequest) Reset() {
+	*x = TailLogsRequest{}
+	mi := &file_hermit_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TailLogsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TailLogsRequest) ProtoMessage() {}
+
+func (x *TailLogsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TailLogsRequest.ProtoReflect.Descriptor instead.
+func (*TailLogsRequest) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *TailLogsRequest) GetBacklog() uint32 {
+	if x != nil {
+		return x.Backlog
+	}
+	return 0
+}
+
+type LogLine struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Time  *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	// TRACE, DEBUG, INFO, WARN or ERROR.
+	Level string `protobuf:"bytes,2,opt,name=level,proto3" json:"level,omitempty"`
+	// Module path of the code that logged the line.
+	Target string `protobuf:"bytes,3,opt,name=target,proto3" json:"target,omitempty"`
+	// The message, followed by any structured fields as key=value.
+	Message       string `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogLine) Reset() {
+	*x = LogLine{}
+	mi := &file_hermit_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogLine) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogLine) ProtoMessage() {}
+
+func (x *LogLine) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogLine.ProtoReflect.Descriptor instead.
+func (*LogLine) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *LogLine) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *LogLine) GetLevel() string {
+	if x != nil {
+		return x.Level
+	}
+	return ""
+}
+
+func (x *LogLine) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *LogLine) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type WatchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only keys starting with this. Empty = all keys.
+	Prefix        string `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchRequest) Reset() {
+	*x = WatchRequest{}
+	mi := &file_hermit_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRequest) ProtoMessage() {}
+
+func (x *WatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRequest.ProtoReflect.Descriptor instead.
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *WatchRequest) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+type WatchEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Kind  WatchEvent_Kind        `protobuf:"varint,1,opt,name=kind,proto3,enum=hermit.WatchEvent_Kind" json:"kind,omitempty"`
+	Key   string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	// The new value, for SET.
+	Value         []byte `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchEvent) Reset() {
+	*x = WatchEvent{}
+	mi := &file_hermit_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchEvent) ProtoMessage() {}
+
+func (x *WatchEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchEvent.ProtoReflect.Descriptor instead.
+func (*WatchEvent) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *WatchEvent) GetKind() WatchEvent_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return WatchEvent_SET
+}
+
+func (x *WatchEvent) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *WatchEvent) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+type DbSnapshotRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DbSnapshotRequest) Reset() {
+	*x = DbSnapshotRequest{}
+	mi := &file_hermit_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DbSnapshotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DbSnapshotRequest) ProtoMessage() {}
+
+func (x *DbSnapshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DbSnapshotRequest.ProtoReflect.Descriptor instead.
+func (*DbSnapshotRequest) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{42}
+}
+
+type SnapshotChunk struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The next piece of a zstd-compressed snapshot; concatenate in order.
+	Data          []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SnapshotChunk) Reset() {
+	*x = SnapshotChunk{}
+	mi := &file_hermit_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnapshotChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotChunk) ProtoMessage() {}
+
+func (x *SnapshotChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotChunk.ProtoReflect.Descriptor instead.
+func (*SnapshotChunk) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *SnapshotChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type DbRestoreResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// What the restored snapshot held.
+	DocKeys       uint64 `protobuf:"varint,1,opt,name=doc_keys,json=docKeys,proto3" json:"doc_keys,omitempty"`
+	RelRows       uint64 `protobuf:"varint,2,opt,name=rel_rows,json=relRows,proto3" json:"rel_rows,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DbRestoreResponse) Reset() {
+	*x = DbRestoreResponse{}
+	mi := &file_hermit_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DbRestoreResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DbRestoreResponse) ProtoMessage() {}
+
+func (x *DbRestoreResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DbRestoreResponse.ProtoReflect.Descriptor instead.
+func (*DbRestoreResponse) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *DbRestoreResponse) GetDocKeys() uint64 {
+	if x != nil {
+		return x.DocKeys
+	}
+	return 0
+}
+
+func (x *DbRestoreResponse) GetRelRows() uint64 {
+	if x != nil {
+		return x.RelRows
+	}
+	return 0
+}
+
+type MetricsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MetricsRequest) Reset() {
+	*x = MetricsRequest{}
+	mi := &file_hermit_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetricsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetricsRequest) ProtoMessage() {}
+
+func (x *MetricsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetricsRequest.ProtoReflect.Descriptor instead.
+func (*MetricsRequest) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{45}
+}
+
+type RpcMetric struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Method  string                 `protobuf:"bytes,1,opt,name=method,proto3" json:"method,omitempty"`
+	Calls   uint64                 `protobuf:"varint,2,opt,name=calls,proto3" json:"calls,omitempty"`
+	TotalUs uint64                 `protobuf:"varint,3,opt,name=total_us,json=totalUs,proto3" json:"total_us,omitempty"`
+	MaxUs   uint64                 `protobuf:"varint,4,opt,name=max_us,json=maxUs,proto3" json:"max_us,omitempty"`
+	// Calls per latency bucket: one per MetricsResponse.latency_bounds_us,
+	// then one for the rest.
+	Buckets       []uint64 `protobuf:"varint,5,rep,packed,name=buckets,proto3" json:"buckets,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RpcMetric) Reset() {
+	*x = RpcMetric{}
+	mi := &file_hermit_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RpcMetric) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RpcMetric) ProtoMessage() {}
+
+func (x *RpcMetric) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RpcMetric.ProtoReflect.Descriptor instead.
+func (*RpcMetric) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *RpcMetric) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *RpcMetric) GetCalls() uint64 {
+	if x != nil {
+		return x.Calls
+	}
+	return 0
+}
+
+func (x *RpcMetric) GetTotalUs() uint64 {
+	if x != nil {
+		return x.TotalUs
+	}
+	return 0
+}
+
+func (x *RpcMetric) GetMaxUs() uint64 {
+	if x != nil {
+		return x.MaxUs
+	}
+	return 0
+}
+
+func (x *RpcMetric) GetBuckets() []uint64 {
+	if x != nil {
+		return x.Buckets
+	}
+	return nil
+}
+
+type MetricsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Rpcs  []*RpcMetric           `protobuf:"bytes,1,rep,name=rpcs,proto3" json:"rpcs,omitempty"`
+	// Upper bounds of the latency buckets, in microseconds.
+	LatencyBoundsUs []uint64 `protobuf:"varint,2,rep,packed,name=latency_bounds_us,json=latencyBoundsUs,proto3" json:"latency_bounds_us,omitempty"`
+	DocKeys         uint64   `protobuf:"varint,3,opt,name=doc_keys,json=docKeys,proto3" json:"doc_keys,omitempty"`
+	DocBytes        uint64   `protobuf:"varint,4,opt,name=doc_bytes,json=docBytes,proto3" json:"doc_bytes,omitempty"`
+	RelRows         uint64   `protobuf:"varint,5,opt,name=rel_rows,json=relRows,proto3" json:"rel_rows,omitempty"`
+	// Rows queued for the next relational commit.
+	RelPendingWrites uint64 `protobuf:"varint,6,opt,name=rel_pending_writes,json=relPendingWrites,proto3" json:"rel_pending_writes,omitempty"`
+	// Open Watch streams.
+	Watchers uint64 `protobuf:"varint,7,opt,name=watchers,proto3" json:"watchers,omitempty"`
+	// The TTL expirer: runs, keys dropped, and how long the last run took.
+	ExpireSweeps      uint64 `protobuf:"varint,8,opt,name=expire_sweeps,json=expireSweeps,proto3" json:"expire_sweeps,omitempty"`
+	ExpiredKeys       uint64 `protobuf:"varint,9,opt,name=expired_keys,json=expiredKeys,proto3" json:"expired_keys,omitempty"`
+	ExpireLastSweepUs uint64 `protobuf:"varint,10,opt,name=expire_last_sweep_us,json=expireLastSweepUs,proto3" json:"expire_last_sweep_us,omitempty"`
+	Connections       uint64 `protobuf:"varint,11,opt,name=connections,proto3" json:"connections,omitempty"`
+	// Handshakes started: every connection, when TLS is on.
+	TlsHandshakes uint64 `protobuf:"varint,12,opt,name=tls_handshakes,json=tlsHandshakes,proto3" json:"tls_handshakes,omitempty"`
+	RssBytes      uint64 `protobuf:"varint,13,opt,name=rss_bytes,json=rssBytes,proto3" json:"rss_bytes,omitempty"`
+	UptimeSeconds int64  `protobuf:"varint,14,opt,name=uptime_seconds,json=uptimeSeconds,proto3" json:"uptime_seconds,omitempty"`
+	// Calls refused by rate limits.
+	RateLimited   uint64 `protobuf:"varint,15,opt,name=rate_limited,json=rateLimited,proto3" json:"rate_limited,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MetricsResponse) Reset() {
+	*x = MetricsResponse{}
+	mi := &file_hermit_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetricsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetricsResponse) ProtoMessage() {}
+
+func (x *MetricsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetricsResponse.ProtoReflect.Descriptor instead.
+func (*MetricsResponse) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *MetricsResponse) GetRpcs() []*RpcMetric {
+	if x != nil {
+		return x.Rpcs
+	}
+	return nil
+}
+
+func (x *MetricsResponse) GetLatencyBoundsUs() []uint64 {
+	if x != nil {
+		return x.LatencyBoundsUs
+	}
+	return nil
+}
+
+func (x *MetricsResponse) GetDocKeys() uint64 {
+	if x != nil {
+		return x.DocKeys
+	}
+	return 0
+}
+
+func (x *MetricsResponse) GetDocBytes() uint64 {
+	if x != nil {
+		return x.DocBytes
+	}
+	return 0
+}
+
+func (x *MetricsResponse) GetRelRows() uint64 {
+	if x != nil {
+		return x.RelRows
+	}
+	return 0
+}
+
+func (x *MetricsResponse) GetRelPendingWrites() uint64 {
+	if x != nil {
+		return x.RelPendingWrites
+	}
+	return 0
+}
+
+func (x *MetricsResponse) GetWatchers() uint64 {
+	if x != nil {
+		return x.Watchers
+	}
+	return 0
+}
+
+func (x *MetricsResponse) GetExpireSweeps() uint64 {
+	if x != nil {
+		return x.ExpireSweeps
+	}
+	return 0
+}
+
+func (x *MetricsResponse) GetExpiredKeys() uint64 {
+	if x != nil {
+		return x.ExpiredKeys
+	}
+	return 0
+}
+
+func (x *MetricsResponse) GetExpireLastSweepUs() uint64 {
+	if x != nil {
+		return x.ExpireLastSweepUs
+	}
+	return 0
+}
+
+func (x *MetricsResponse) GetConnections() uint64 {
+	if x != nil {
+		return x.Connections
+	}
+	return 0
+}
+
+func (x *MetricsResponse) GetTlsHandshakes() uint64 {
+	if x != nil {
+		return x.TlsHandshakes
+	}
+	return 0
+}
+
+func (x *MetricsResponse) GetRssBytes() uint64 {
+	if x != nil {
+		return x.RssBytes
+	}
+	return 0
+}
+
+func (x *MetricsResponse) GetUptimeSeconds() int64 {
+	if x != nil {
+		return x.UptimeSeconds
+	}
+	return 0
+}
+
+func (x *MetricsResponse) GetRateLimited() uint64 {
+	if x != nil {
+		return x.RateLimited
+	}
+	return 0
+}
+
+type PublishRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Topic         string                 `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	Data          []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PublishRequest) Reset() {
+	*x = PublishRequest{}
+	mi := &file_hermit_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PublishRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PublishRequest) ProtoMessage() {}
+
+func (x *PublishRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PublishRequest.ProtoReflect.Descriptor instead.
+func (*PublishRequest) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *PublishRequest) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *PublishRequest) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type PublishResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Subscribers the message went to.
+	Delivered     uint64 `protobuf:"varint,1,opt,name=delivered,proto3" json:"delivered,omitempty"`
+	Error         string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PublishResponse) Reset() {
+	*x = PublishResponse{}
+	mi := &file_hermit_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PublishResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PublishResponse) ProtoMessage() {}
+
+func (x *PublishResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PublishResponse.ProtoReflect.Descriptor instead.
+func (*PublishResponse) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *PublishResponse) GetDelivered() uint64 {
+	if x != nil {
+		return x.Delivered
+	}
+	return 0
+}
+
+func (x *PublishResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type SubscribeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Topic         string                 `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeRequest) Reset() {
+	*x = SubscribeRequest{}
+	mi := &file_hermit_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeRequest) ProtoMessage() {}
+
+func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeRequest) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *SubscribeRequest) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+type TopicMessage struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Topic string                 `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	// The publisher's user name; empty when hermit runs without users.
+	From string                 `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	Data []byte                 `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	Time *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=time,proto3" json:"time,omitempty"`
+	// Counts the topic's messages from 1.
+	Seq uint64 `protobuf:"varint,5,opt,name=seq,proto3" json:"seq,omitempty"`
+	// Messages this subscriber missed just before this one by falling behind.
+	Dropped       uint64 `protobuf:"varint,6,opt,name=dropped,proto3" json:"dropped,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TopicMessage) Reset() {
+	*x = TopicMessage{}
+	mi := &file_hermit_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TopicMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopicMessage) ProtoMessage() {}
+
+func (x *TopicMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TopicMessage.ProtoReflect.Descriptor instead.
+func (*TopicMessage) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *TopicMessage) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *TopicMessage) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *TopicMessage) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *TopicMessage) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *TopicMessage) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *TopicMessage) GetDropped() uint64 {
+	if x != nil {
+		return x.Dropped
+	}
+	return 0
+}
+
+// A rate limit on one class of call: "write" (calls that change keys, rows
+// or topics) or "bench" (Benchmark and BenchmarkStream).
+type RateLimit struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Class string                 `protobuf:"bytes,1,opt,name=class,proto3" json:"class,omitempty"`
+	// A user name, or an IP address when hermit runs without users; empty is
+	// the class's default, which every client gets a bucket of its own of.
+	Client string `protobuf:"bytes,2,opt,name=client,proto3" json:"client,omitempty"`
+	// Calls a second; 0 is unlimited.
+	PerSecond float64 `protobuf:"fixed64,3,opt,name=per_second,json=perSecond,proto3" json:"per_second,omitempty"`
+	// Calls at once after being idle; 0 when setting is one second's worth.
+	Burst         uint32 `protobuf:"varint,4,opt,name=burst,proto3" json:"burst,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RateLimit) Reset() {
+	*x = RateLimit{}
+	mi := &file_hermit_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RateLimit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RateLimit) ProtoMessage() {}
+
+func (x *RateLimit) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RateLimit.ProtoReflect.Descriptor instead.
+func (*RateLimit) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *RateLimit) GetClass() string {
+	if x != nil {
+		return x.Class
+	}
+	return ""
+}
+
+func (x *RateLimit) GetClient() string {
+	if x != nil {
+		return x.Client
+	}
+	return ""
+}
+
+func (x *RateLimit) GetPerSecond() float64 {
+	if x != nil {
+		return x.PerSecond
+	}
+	return 0
+}
+
+func (x *RateLimit) GetBurst() uint32 {
+	if x != nil {
+		return x.Burst
+	}
+	return 0
+}
+
+type LimitsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LimitsRequest) Reset() {
+	*x = LimitsRequest{}
+	mi := &file_hermit_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LimitsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LimitsRequest) ProtoMessage() {}
+
+func (x *LimitsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LimitsRequest.ProtoReflect.Descriptor instead.
+func (*LimitsRequest) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{53}
+}
+
+type SetLimitRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Limit *RateLimit             `protobuf:"bytes,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	// Puts limit.client back on the class's default instead.
+	Remove        bool `protobuf:"varint,2,opt,name=remove,proto3" json:"remove,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetLimitRequest) Reset() {
+	*x = SetLimitRequest{}
+	mi := &file_hermit_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetLimitRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetLimitRequest) ProtoMessage() {}
+
+func (x *SetLimitRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetLimitRequest.ProtoReflect.Descriptor instead.
+func (*SetLimitRequest) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *SetLimitRequest) GetLimit() *RateLimit {
+	if x != nil {
+		return x.Limit
+	}
+	return nil
+}
+
+func (x *SetLimitRequest) GetRemove() bool {
+	if x != nil {
+		return x.Remove
+	}
+	return false
+}
+
+type LimitsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Each class's default, then clients' own limits.
+	Limits        []*RateLimit `protobuf:"bytes,1,rep,name=limits,proto3" json:"limits,omitempty"`
+	Error         string       `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LimitsResponse) Reset() {
+	*x = LimitsResponse{}
+	mi := &file_hermit_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LimitsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LimitsResponse) ProtoMessage() {}
+
+func (x *LimitsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LimitsResponse.ProtoReflect.Descriptor instead.
+func (*LimitsResponse) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *LimitsResponse) GetLimits() []*RateLimit {
+	if x != nil {
+		return x.Limits
+	}
+	return nil
+}
+
+func (x *LimitsResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ReplicateRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The follower's name, for the primary's ReplicationStatus.
+	Follower      string `protobuf:"bytes,1,opt,name=follower,proto3" json:"follower,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplicateRequest) Reset() {
+	*x = ReplicateRequest{}
+	mi := &file_hermit_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplicateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplicateRequest) ProtoMessage() {}
+
+func (x *ReplicateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplicateRequest.ProtoReflect.Descriptor instead.
+func (*ReplicateRequest) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{56}
+}
+
+func (x *ReplicateRequest) GetFollower() string {
+	if x != nil {
+		return x.Follower
+	}
+	return ""
+}
+
+// One step of a primary's document store oplog, as Replicate sends it.
+type OplogEntry struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Kind  OplogEntry_Kind        `protobuf:"varint,1,opt,name=kind,proto3,enum=hermit.OplogEntry_Kind" json:"kind,omitempty"`
+	// The primary's oplog position: after this change, as of the snapshot,
+	// or now for HEARTBEAT.
+	Seq uint64 `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`
+	Key string `protobuf:"bytes,3,opt,name=key,proto3" json:"key,omitempty"`
+	// The new value for SET; snapshot bytes for SNAPSHOT.
+	Value []byte `protobuf:"bytes,4,opt,name=value,proto3" json:"value,omitempty"`
+	// For SET: the key's version, and the milliseconds it has left (0 never
+	// expires).
+	Version uint64 `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"`
+	TtlMs   uint64 `protobuf:"varint,6,opt,name=ttl_ms,json=ttlMs,proto3" json:"ttl_ms,omitempty"`
+	// The bucket the change was made in; for BUCKET, the one created.
+	Bucket        string `protobuf:"bytes,7,opt,name=bucket,proto3" json:"bucket,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OplogEntry) Reset() {
+	*x = OplogEntry{}
+	mi := &file_hermit_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OplogEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OplogEntry) ProtoMessage() {}
+
+func (x *OplogEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OplogEntry.ProtoReflect.Descriptor instead.
+func (*OplogEntry) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *OplogEntry) GetKind() OplogEntry_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return OplogEntry_SNAPSHOT
+}
+
+func (x *OplogEntry) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *OplogEntry) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *OplogEntry) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *OplogEntry) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *OplogEntry) GetTtlMs() uint64 {
+	if x != nil {
+		return x.TtlMs
+	}
+	return 0
+}
+
+func (x *OplogEntry) GetBucket() string {
+	if x != nil {
+		return x.Bucket
+	}
+	return ""
+}
+
+type ReplicationStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplicationStatusRequest) Reset() {
+	*x = ReplicationStatusRequest{}
+	mi := &file_hermit_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplicationStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplicationStatusRequest) ProtoMessage() {}
+
+func (x *ReplicationStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplicationStatusRequest.ProtoReflect.Descriptor instead.
+func (*ReplicationStatusRequest) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{58}
+}
+
+// A follower, as its primary sees it.
+type Follower struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// host:port it connected from.
+	Addr string `protobuf:"bytes,2,opt,name=addr,proto3" json:"addr,omitempty"`
+	// The last oplog position sent to it, and how far that is behind.
+	SentSeq       uint64                 `protobuf:"varint,3,opt,name=sent_seq,json=sentSeq,proto3" json:"sent_seq,omitempty"`
+	Lag           uint64                 `protobuf:"varint,4,opt,name=lag,proto3" json:"lag,omitempty"`
+	Since         *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=since,proto3" json:"since,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Follower) Reset() {
+	*x = Follower{}
+	mi := &file_hermit_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Follower) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Follower) ProtoMessage() {}
+
+func (x *Follower) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Follower.ProtoReflect.Descriptor instead.
+func (*Follower) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{59}
+}
+
+func (x *Follower) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Follower) GetAddr() string {
+	if x != nil {
+		return x.Addr
+	}
+	return ""
+}
+
+func (x *Follower) GetSentSeq() uint64 {
+	if x != nil {
+		return x.SentSeq
+	}
+	return 0
+}
+
+func (x *Follower) GetLag() uint64 {
+	if x != nil {
+		return x.Lag
+	}
+	return 0
+}
+
+func (x *Follower) GetSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
+}
+
+type ReplicationStatusResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// primary or follower.
+	Role string `protobuf:"bytes,1,opt,name=role,proto3" json:"role,omitempty"`
+	// This server's own oplog position: document store changes since it
+	// started.
+	OplogSeq uint64 `protobuf:"varint,2,opt,name=oplog_seq,json=oplogSeq,proto3" json:"oplog_seq,omitempty"`
+	// Primaries: who is following.
+	Followers []*Follower `protobuf:"bytes,3,rep,name=followers,proto3" json:"followers,omitempty"`
+	// Followers: the primary's URL, and whether the stream from it is up.
+	Primary   string `protobuf:"bytes,4,opt,name=primary,proto3" json:"primary,omitempty"`
+	Connected bool   `protobuf:"varint,5,opt,name=connected,proto3" json:"connected,omitempty"`
+	// The primary's oplog position this follower has applied, the primary's
+	// position when last heard from, and the difference.
+	AppliedSeq uint64 `protobuf:"varint,6,opt,name=applied_seq,json=appliedSeq,proto3" json:"applied_seq,omitempty"`
+	PrimarySeq uint64 `protobuf:"varint,7,opt,name=primary_seq,json=primarySeq,proto3" json:"primary_seq,omitempty"`
+	Lag        uint64 `protobuf:"varint,8,opt,name=lag,proto3" json:"lag,omitempty"`
+	// Milliseconds since the primary was last heard from; 0 if never.
+	LastContactMs uint64 `protobuf:"varint,9,opt,name=last_contact_ms,json=lastContactMs,proto3" json:"last_contact_ms,omitempty"`
+	// Snapshots loaded from the primary; more than one means it resynced.
+	Resyncs uint64 `protobuf:"varint,10,opt,name=resyncs,proto3" json:"resyncs,omitempty"`
+	// Why the last connection to the primary ended.
+	Error         string `protobuf:"bytes,11,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplicationStatusResponse) Reset() {
+	*x = ReplicationStatusResponse{}
+	mi := &file_hermit_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplicationStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplicationStatusResponse) ProtoMessage() {}
+
+func (x *ReplicationStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplicationStatusResponse.ProtoReflect.Descriptor instead.
+func (*ReplicationStatusResponse) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{60}
+}
+
+func (x *ReplicationStatusResponse) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *ReplicationStatusResponse) GetOplogSeq() uint64 {
+	if x != nil {
+		return x.OplogSeq
+	}
+	return 0
+}
+
+func (x *ReplicationStatusResponse) GetFollowers() []*Follower {
+	if x != nil {
+		return x.Followers
+	}
+	return nil
+}
+
+func (x *ReplicationStatusResponse) GetPrimary() string {
+	if x != nil {
+		return x.Primary
+	}
+	return ""
+}
+
+func (x *ReplicationStatusResponse) GetConnected() bool {
+	if x != nil {
+		return x.Connected
+	}
+	return false
+}
+
+func (x *ReplicationStatusResponse) GetAppliedSeq() uint64 {
+	if x != nil {
+		return x.AppliedSeq
+	}
+	return 0
+}
+
+func (x *ReplicationStatusResponse) GetPrimarySeq() uint64 {
+	if x != nil {
+		return x.PrimarySeq
+	}
+	return 0
+}
+
+func (x *ReplicationStatusResponse) GetLag() uint64 {
+	if x != nil {
+		return x.Lag
+	}
+	return 0
+}
+
+func (x *ReplicationStatusResponse) GetLastContactMs() uint64 {
+	if x != nil {
+		return x.LastContactMs
+	}
+	return 0
+}
+
+func (x *ReplicationStatusResponse) GetResyncs() uint64 {
+	if x != nil {
+		return x.Resyncs
+	}
+	return 0
+}
+
+func (x *ReplicationStatusResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type CreateBucketRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Lowercase letters, digits, '-', '_' and '.'; at most 64 bytes.
+	Name          string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateBucketRequest) Reset() {
+	*x = CreateBucketRequest{}
+	mi := &file_hermit_proto_msgTypes[61]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateBucketRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateBucketRequest) ProtoMessage() {}
+
+func (x *CreateBucketRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[61]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateBucketRequest.ProtoReflect.Descriptor instead.
+func (*CreateBucketRequest) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{61}
+}
+
+func (x *CreateBucketRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type CreateBucketResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// False if the bucket already existed.
+	Created       bool `protobuf:"varint,1,opt,name=created,proto3" json:"created,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateBucketResponse) Reset() {
+	*x = CreateBucketResponse{}
+	mi := &file_hermit_proto_msgTypes[62]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateBucketResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateBucketResponse) ProtoMessage() {}
+
+func (x *CreateBucketResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[62]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateBucketResponse.ProtoReflect.Descriptor instead.
+func (*CreateBucketResponse) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{62}
+}
+
+func (x *CreateBucketResponse) GetCreated() bool {
+	if x != nil {
+		return x.Created
+	}
+	return false
+}
+
+type ListBucketsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBucketsRequest) Reset() {
+	*x = ListBucketsRequest{}
+	mi := &file_hermit_proto_msgTypes[63]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBucketsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBucketsRequest) ProtoMessage() {}
+
+func (x *ListBucketsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[63]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBucketsRequest.ProtoReflect.Descriptor instead.
+func (*ListBucketsRequest) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{63}
+}
+
+type Bucket struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Keys  uint64                 `protobuf:"varint,2,opt,name=keys,proto3" json:"keys,omitempty"`
+	// What the bucket's values take uncompressed, and as stored.
+	RawBytes    uint64                 `protobuf:"varint,3,opt,name=raw_bytes,json=rawBytes,proto3" json:"raw_bytes,omitempty"`
+	StoredBytes uint64                 `protobuf:"varint,4,opt,name=stored_bytes,json=storedBytes,proto3" json:"stored_bytes,omitempty"`
+	Created     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created,proto3" json:"created,omitempty"`
+	// The most the caller may do here: read, write or admin.
+	Role          string `protobuf:"bytes,6,opt,name=role,proto3" json:"role,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Bucket) Reset() {
+	*x = Bucket{}
+	mi := &file_hermit_proto_msgTypes[64]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Bucket) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Bucket) ProtoMessage() {}
+
+func (x *Bucket) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[64]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Bucket.ProtoReflect.Descriptor instead.
+func (*Bucket) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{64}
+}
+
+func (x *Bucket) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Bucket) GetKeys() uint64 {
+	if x != nil {
+		return x.Keys
+	}
+	return 0
+}
+
+func (x *Bucket) GetRawBytes() uint64 {
+	if x != nil {
+		return x.RawBytes
+	}
+	return 0
+}
+
+func (x *Bucket) GetStoredBytes() uint64 {
+	if x != nil {
+		return x.StoredBytes
+	}
+	return 0
+}
+
+func (x *Bucket) GetCreated() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Created
+	}
+	return nil
+}
+
+func (x *Bucket) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+type ListBucketsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only the buckets the caller may use, in name order.
+	Buckets       []*Bucket `protobuf:"bytes,1,rep,name=buckets,proto3" json:"buckets,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBucketsResponse) Reset() {
+	*x = ListBucketsResponse{}
+	mi := &file_hermit_proto_msgTypes[65]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBucketsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBucketsResponse) ProtoMessage() {}
+
+func (x *ListBucketsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[65]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1141,36 +4318,41 @@ func (x *DbStatsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DbStatsRequest.ProtoReflect.Descriptor instead.
-func (*DbStatsRequest) Descriptor() ([]byte, []int) {
-	return file_hermit_proto_rawDescGZIP(), []int{19}
+// Deprecated: Use ListBucketsResponse.ProtoReflect.Descriptor instead.
+func (*ListBucketsResponse) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{65}
 }
 
-type DbStatsResponse struct {
-	state              protoimpl.MessageState `protogen:"open.v1"`
-	DocKeyCount        uint64                 `protobuf:"varint,1,opt,name=doc_key_count,json=docKeyCount,proto3" json:"doc_key_count,omitempty"`
-	DocCompressedBytes uint64                 `protobuf:"varint,2,opt,name=doc_compressed_bytes,json=docCompressedBytes,proto3" json:"doc_compressed_bytes,omitempty"`
-	RelRowCount        uint64                 `protobuf:"varint,3,opt,name=rel_row_count,json=relRowCount,proto3" json:"rel_row_count,omitempty"`
-	RelPendingWrites   uint64                 `protobuf:"varint,4,opt,name=rel_pending_writes,json=relPendingWrites,proto3" json:"rel_pending_writes,omitempty"`
-	unknownFields      protoimpl.UnknownFields
-	sizeCache          protoimpl.SizeCache
+func (x *ListBucketsResponse) GetBuckets() []*Bucket {
+	if x != nil {
+		return x.Buckets
+	}
+	return nil
 }
 
-func (x *DbStatsResponse) Reset() {
-	*x = DbStatsResponse{}
-	mi := &file_hermit_proto_msgTypes[20]
+type DiagnosticsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Most slow calls and large values to return; 0 for 20.
+	Limit         uint32 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiagnosticsRequest) Reset() {
+	*x = DiagnosticsRequest{}
+	mi := &file_hermit_proto_msgTypes[66]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DbStatsResponse) String() string {
+func (x *DiagnosticsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DbStatsResponse) ProtoMessage() {}
+func (*DiagnosticsRequest) ProtoMessage() {}
 
-func (x *DbStatsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_hermit_proto_msgTypes[20]
+func (x *DiagnosticsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[66]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1181,39 +4363,285 @@ func (x *DbStatsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DbStatsResponse.ProtoReflect.Descriptor instead.
-func (*DbStatsResponse) Descriptor() ([]byte, []int) {
-	return file_hermit_proto_rawDescGZIP(), []int{20}
+// Deprecated: Use DiagnosticsRequest.ProtoReflect.Descriptor instead.
+func (*DiagnosticsRequest) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{66}
 }
 
-func (x *DbStatsResponse) GetDocKeyCount() uint64 {
+func (x *DiagnosticsRequest) GetLimit() uint32 {
 	if x != nil {
-		return x.DocKeyCount
+		return x.Limit
 	}
 	return 0
 }
 
-func (x *DbStatsResponse) GetDocCompressedBytes() uint64 {
+// SlowCall is a call that took at least --slow-ms.
+type SlowCall struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Method string                 `protobuf:"bytes,1,opt,name=method,proto3" json:"method,omitempty"`
+	TookUs uint64                 `protobuf:"varint,2,opt,name=took_us,json=tookUs,proto3" json:"took_us,omitempty"`
+	At     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=at,proto3" json:"at,omitempty"`
+	// The bucket and key the call was for, when it was for one key; empty
+	// for calls like KvList and Txn that aren't.
+	Bucket        string `protobuf:"bytes,4,opt,name=bucket,proto3" json:"bucket,omitempty"`
+	Key           string `protobuf:"bytes,5,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SlowCall) Reset() {
+	*x = SlowCall{}
+	mi := &file_hermit_proto_msgTypes[67]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SlowCall) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SlowCall) ProtoMessage() {}
+
+func (x *SlowCall) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[67]
 	if x != nil {
-		return x.DocCompressedBytes
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SlowCall.ProtoReflect.Descriptor instead.
+func (*SlowCall) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{67}
+}
+
+func (x *SlowCall) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *SlowCall) GetTookUs() uint64 {
+	if x != nil {
+		return x.TookUs
 	}
 	return 0
 }
 
-func (x *DbStatsResponse) GetRelRowCount() uint64 {
+func (x *SlowCall) GetAt() *timestamppb.Timestamp {
 	if x != nil {
-		return x.RelRowCount
+		return x.At
+	}
+	return nil
+}
+
+func (x *SlowCall) GetBucket() string {
+	if x != nil {
+		return x.Bucket
+	}
+	return ""
+}
+
+func (x *SlowCall) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+// LargeValue is one of the document store's largest values.
+type LargeValue struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Bucket string                 `protobuf:"bytes,1,opt,name=bucket,proto3" json:"bucket,omitempty"`
+	Key    string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	// The value's size as written, and compressed as stored.
+	RawBytes      uint64 `protobuf:"varint,3,opt,name=raw_bytes,json=rawBytes,proto3" json:"raw_bytes,omitempty"`
+	StoredBytes   uint64 `protobuf:"varint,4,opt,name=stored_bytes,json=storedBytes,proto3" json:"stored_bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LargeValue) Reset() {
+	*x = LargeValue{}
+	mi := &file_hermit_proto_msgTypes[68]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LargeValue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LargeValue) ProtoMessage() {}
+
+func (x *LargeValue) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[68]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LargeValue.ProtoReflect.Descriptor instead.
+func (*LargeValue) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{68}
+}
+
+func (x *LargeValue) GetBucket() string {
+	if x != nil {
+		return x.Bucket
+	}
+	return ""
+}
+
+func (x *LargeValue) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *LargeValue) GetRawBytes() uint64 {
+	if x != nil {
+		return x.RawBytes
 	}
 	return 0
 }
 
-func (x *DbStatsResponse) GetRelPendingWrites() uint64 {
+func (x *LargeValue) GetStoredBytes() uint64 {
 	if x != nil {
-		return x.RelPendingWrites
+		return x.StoredBytes
+	}
+	return 0
+}
+
+type DiagnosticsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The slowest of the last calls that took at least slow_threshold_us,
+	// slowest first. Empty when slow call tracking is off.
+	SlowCalls       []*SlowCall `protobuf:"bytes,1,rep,name=slow_calls,json=slowCalls,proto3" json:"slow_calls,omitempty"`
+	SlowThresholdUs uint64      `protobuf:"varint,2,opt,name=slow_threshold_us,json=slowThresholdUs,proto3" json:"slow_threshold_us,omitempty"`
+	// The largest live values in any bucket, largest first.
+	LargeValues []*LargeValue `protobuf:"bytes,3,rep,name=large_values,json=largeValues,proto3" json:"large_values,omitempty"`
+	// Writes of values at least this large are logged as they happen.
+	LargeValueBytes uint64 `protobuf:"varint,4,opt,name=large_value_bytes,json=largeValueBytes,proto3" json:"large_value_bytes,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *DiagnosticsResponse) Reset() {
+	*x = DiagnosticsResponse{}
+	mi := &file_hermit_proto_msgTypes[69]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiagnosticsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiagnosticsResponse) ProtoMessage() {}
+
+func (x *DiagnosticsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[69]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiagnosticsResponse.ProtoReflect.Descriptor instead.
+func (*DiagnosticsResponse) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{69}
+}
+
+func (x *DiagnosticsResponse) GetSlowCalls() []*SlowCall {
+	if x != nil {
+		return x.SlowCalls
+	}
+	return nil
+}
+
+func (x *DiagnosticsResponse) GetSlowThresholdUs() uint64 {
+	if x != nil {
+		return x.SlowThresholdUs
+	}
+	return 0
+}
+
+func (x *DiagnosticsResponse) GetLargeValues() []*LargeValue {
+	if x != nil {
+		return x.LargeValues
+	}
+	return nil
+}
+
+func (x *DiagnosticsResponse) GetLargeValueBytes() uint64 {
+	if x != nil {
+		return x.LargeValueBytes
 	}
 	return 0
 }
 
+type RestoreFromUrlRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Where the snapshot is: s3://bucket/key, or an http:// or https:// URL
+	// hermit can GET without credentials.
+	Url           string `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreFromUrlRequest) Reset() {
+	*x = RestoreFromUrlRequest{}
+	mi := &file_hermit_proto_msgTypes[70]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreFromUrlRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreFromUrlRequest) ProtoMessage() {}
+
+func (x *RestoreFromUrlRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hermit_proto_msgTypes[70]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreFromUrlRequest.ProtoReflect.Descriptor instead.
+func (*RestoreFromUrlRequest) Descriptor() ([]byte, []int) {
+	return file_hermit_proto_rawDescGZIP(), []int{70}
+}
+
+func (x *RestoreFromUrlRequest) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
 var File_hermit_proto protoreflect.FileDescriptor
 
 const file_hermit_proto_rawDesc = "" +
@@ -1241,15 +4669,40 @@ const file_hermit_proto_rawDesc = "" +
 	"\n" +
 	"tls_active\x18\b \x01(\bR\ttlsActive\x12\x1f\n" +
 	"\vtls_version\x18\t \x01(\tR\n" +
+	"tlsVersion\"B\n" +
+	"\x0fHistogramBucket\x12\x19\n" +
+	"\bupper_ns\x18\x01 \x01(\x03R\aupperNs\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x04R\x05count\"\xe0\x03\n" +
+	"\x11BenchmarkProgress\x12\x12\n" +
+	"\x04done\x18\x01 \x01(\rR\x04done\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\rR\x05total\x12\x1d\n" +
+	"\n" +
+	"elapsed_ns\x18\x03 \x01(\x03R\telapsedNs\x12\x15\n" +
+	"\x06min_ns\x18\x04 \x01(\x03R\x05minNs\x12\x15\n" +
+	"\x06max_ns\x18\x05 \x01(\x03R\x05maxNs\x12\x17\n" +
+	"\amean_ns\x18\x06 \x01(\x03R\x06meanNs\x12\x15\n" +
+	"\x06p50_ns\x18\a \x01(\x03R\x05p50Ns\x12\x15\n" +
+	"\x06p90_ns\x18\b \x01(\x03R\x05p90Ns\x12\x15\n" +
+	"\x06p99_ns\x18\t \x01(\x03R\x05p99Ns\x12\x17\n" +
+	"\ap999_ns\x18\n" +
+	" \x01(\x03R\x06p999Ns\x12,\n" +
+	"\x12iterations_per_sec\x18\v \x01(\x01R\x10iterationsPerSec\x12\"\n" +
+	"\rbytes_per_sec\x18\f \x01(\x01R\vbytesPerSec\x125\n" +
+	"\thistogram\x18\r \x03(\v2\x17.hermit.HistogramBucketR\thistogram\x12\x14\n" +
+	"\x05final\x18\x0e \x01(\bR\x05final\x12\x1d\n" +
+	"\n" +
+	"tls_active\x18\x0f \x01(\bR\ttlsActive\x12\x1f\n" +
+	"\vtls_version\x18\x10 \x01(\tR\n" +
 	"tlsVersion\"@\n" +
 	"\fLoginRequest\x12\x1a\n" +
 	"\busername\x18\x01 \x01(\tR\busername\x12\x14\n" +
-	"\x05token\x18\x02 \x01(\tR\x05token\"^\n" +
+	"\x05token\x18\x02 \x01(\tR\x05token\"r\n" +
 	"\rLoginResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x02 \x01(\tR\tsessionId\x12\x14\n" +
-	"\x05error\x18\x03 \x01(\tR\x05error\"\x13\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x12\x12\n" +
+	"\x04role\x18\x04 \x01(\tR\x04role\"\x13\n" +
 	"\x11ServerInfoRequest\"\xa4\x02\n" +
 	"\x12ServerInfoResponse\x12\x18\n" +
 	"\aversion\x18\x01 \x01(\tR\aversion\x12\x16\n" +
@@ -1261,59 +4714,319 @@ const file_hermit_proto_rawDesc = "" +
 	"\vtls_enabled\x18\x06 \x01(\bR\n" +
 	"tlsEnabled\x12\x1b\n" +
 	"\tgrpc_port\x18\a \x01(\rR\bgrpcPort\x12\x19\n" +
-	"\btcp_port\x18\b \x01(\rR\atcpPort\"6\n" +
+	"\btcp_port\x18\b \x01(\rR\atcpPort\"M\n" +
 	"\fKvSetRequest\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\fR\x05value\"5\n" +
+	"\x05value\x18\x02 \x01(\fR\x05value\x12\x15\n" +
+	"\x06ttl_ms\x18\x03 \x01(\x04R\x05ttlMs\"O\n" +
 	"\rKvSetResponse\x12\x0e\n" +
 	"\x02ok\x18\x01 \x01(\bR\x02ok\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\" \n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\x04R\aversion\" \n" +
 	"\fKvGetRequest\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\"Q\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\"k\n" +
 	"\rKvGetResponse\x12\x14\n" +
 	"\x05found\x18\x01 \x01(\bR\x05found\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\fR\x05value\x12\x14\n" +
-	"\x05error\x18\x03 \x01(\tR\x05error\"\x0f\n" +
-	"\rKvListRequest\"$\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\x04R\aversion\"U\n" +
+	"\rKvListRequest\x12\x16\n" +
+	"\x06prefix\x18\x01 \x01(\tR\x06prefix\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\rR\x05limit\x12\x16\n" +
+	"\x06cursor\x18\x03 \x01(\tR\x06cursor\"E\n" +
 	"\x0eKvListResponse\x12\x12\n" +
-	"\x04keys\x18\x01 \x03(\tR\x04keys\":\n" +
+	"\x04keys\x18\x01 \x03(\tR\x04keys\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\"#\n" +
+	"\x0fKvDeleteRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\"B\n" +
+	"\x10KvDeleteResponse\x12\x18\n" +
+	"\adeleted\x18\x01 \x01(\bR\adeleted\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"#\n" +
+	"\x0fKvExistsRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\"@\n" +
+	"\x10KvExistsResponse\x12\x16\n" +
+	"\x06exists\x18\x01 \x01(\bR\x06exists\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\" \n" +
+	"\fKvTTLRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\"l\n" +
+	"\rKvTTLResponse\x12\x14\n" +
+	"\x05found\x18\x01 \x01(\bR\x05found\x12\x18\n" +
+	"\aexpires\x18\x02 \x01(\bR\aexpires\x12\x15\n" +
+	"\x06ttl_ms\x18\x03 \x01(\x04R\x05ttlMs\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"l\n" +
+	"\bTxnGuard\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x1d\n" +
+	"\aversion\x18\x02 \x01(\x04H\x00R\aversion\x88\x01\x01\x12\x19\n" +
+	"\x05value\x18\x03 \x01(\fH\x01R\x05value\x88\x01\x01B\n" +
+	"\n" +
+	"\b_versionB\b\n" +
+	"\x06_value\"a\n" +
+	"\bTxnWrite\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\fR\x05value\x12\x16\n" +
+	"\x06delete\x18\x03 \x01(\bR\x06delete\x12\x15\n" +
+	"\x06ttl_ms\x18\x04 \x01(\x04R\x05ttlMs\"`\n" +
+	"\n" +
+	"TxnRequest\x12(\n" +
+	"\x06guards\x18\x01 \x03(\v2\x10.hermit.TxnGuardR\x06guards\x12(\n" +
+	"\x06writes\x18\x02 \x03(\v2\x10.hermit.TxnWriteR\x06writes\"r\n" +
+	"\vTxnResponse\x12\x0e\n" +
+	"\x02ok\x18\x01 \x01(\bR\x02ok\x12!\n" +
+	"\ffailed_guard\x18\x02 \x01(\rR\vfailedGuard\x12\x1a\n" +
+	"\bversions\x18\x03 \x03(\x04R\bversions\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\":\n" +
 	"\x10SqlInsertRequest\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value\"A\n" +
 	"\x11SqlInsertResponse\x12\x16\n" +
 	"\x06queued\x18\x01 \x01(\bR\x06queued\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\"F\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\xa0\x02\n" +
 	"\x0fSqlQueryRequest\x12\x1d\n" +
 	"\n" +
 	"key_filter\x18\x01 \x01(\tR\tkeyFilter\x12\x14\n" +
-	"\x05limit\x18\x02 \x01(\rR\x05limit\"d\n" +
+	"\x05limit\x18\x02 \x01(\rR\x05limit\x12!\n" +
+	"\fvalue_filter\x18\x03 \x01(\tR\vvalueFilter\x128\n" +
+	"\border_by\x18\x04 \x01(\x0e2\x1d.hermit.SqlQueryRequest.OrderR\aorderBy\x12\x12\n" +
+	"\x04desc\x18\x05 \x01(\bR\x04desc\x12\x16\n" +
+	"\x06offset\x18\x06 \x01(\rR\x06offset\x12\x1d\n" +
+	"\n" +
+	"count_only\x18\a \x01(\bR\tcountOnly\"0\n" +
+	"\x05Order\x12\x06\n" +
+	"\x02ID\x10\x00\x12\a\n" +
+	"\x03KEY\x10\x01\x12\t\n" +
+	"\x05VALUE\x10\x02\x12\v\n" +
+	"\aCREATED\x10\x03\"d\n" +
 	"\x06SqlRow\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x10\n" +
 	"\x03key\x18\x02 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x03 \x01(\tR\x05value\x12\"\n" +
-	"\rcreated_at_ms\x18\x04 \x01(\x04R\vcreatedAtMs\"\x86\x01\n" +
+	"\rcreated_at_ms\x18\x04 \x01(\x04R\vcreatedAtMs\"\xa0\x01\n" +
 	"\x10SqlQueryResponse\x12\"\n" +
 	"\x04rows\x18\x01 \x03(\v2\x0e.hermit.SqlRowR\x04rows\x12'\n" +
 	"\x0ftotal_committed\x18\x02 \x01(\x04R\x0etotalCommitted\x12%\n" +
-	"\x0epending_writes\x18\x03 \x01(\x04R\rpendingWrites\"\x10\n" +
-	"\x0eDbStatsRequest\"\xb9\x01\n" +
+	"\x0epending_writes\x18\x03 \x01(\x04R\rpendingWrites\x12\x18\n" +
+	"\amatched\x18\x04 \x01(\x04R\amatched\"$\n" +
+	"\x10SqlDeleteRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\"=\n" +
+	"\x11SqlDeleteResponse\x12\x12\n" +
+	"\x04rows\x18\x01 \x01(\x04R\x04rows\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\":\n" +
+	"\x10SqlUpdateRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\"=\n" +
+	"\x11SqlUpdateResponse\x12\x12\n" +
+	"\x04rows\x18\x01 \x01(\x04R\x04rows\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\x10\n" +
+	"\x0eDbStatsRequest\"\x93\x02\n" +
 	"\x0fDbStatsResponse\x12\"\n" +
 	"\rdoc_key_count\x18\x01 \x01(\x04R\vdocKeyCount\x120\n" +
 	"\x14doc_compressed_bytes\x18\x02 \x01(\x04R\x12docCompressedBytes\x12\"\n" +
 	"\rrel_row_count\x18\x03 \x01(\x04R\vrelRowCount\x12,\n" +
-	"\x12rel_pending_writes\x18\x04 \x01(\x04R\x10relPendingWrites2\xda\x04\n" +
+	"\x12rel_pending_writes\x18\x04 \x01(\x04R\x10relPendingWrites\x12\"\n" +
+	"\rdoc_raw_bytes\x18\x05 \x01(\x04R\vdocRawBytes\x124\n" +
+	"\n" +
+	"namespaces\x18\x06 \x03(\v2\x14.hermit.DocNamespaceR\n" +
+	"namespaces\"\x8c\x01\n" +
+	"\fDocNamespace\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05codec\x18\x02 \x01(\tR\x05codec\x12\x12\n" +
+	"\x04keys\x18\x03 \x01(\x04R\x04keys\x12\x1b\n" +
+	"\traw_bytes\x18\x04 \x01(\x04R\brawBytes\x12!\n" +
+	"\fstored_bytes\x18\x05 \x01(\x04R\vstoredBytes\"+\n" +
+	"\x0fTailLogsRequest\x12\x18\n" +
+	"\abacklog\x18\x01 \x01(\rR\abacklog\"\x81\x01\n" +
+	"\aLogLine\x12.\n" +
+	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x14\n" +
+	"\x05level\x18\x02 \x01(\tR\x05level\x12\x16\n" +
+	"\x06target\x18\x03 \x01(\tR\x06target\x12\x18\n" +
+	"\amessage\x18\x04 \x01(\tR\amessage\"&\n" +
+	"\fWatchRequest\x12\x16\n" +
+	"\x06prefix\x18\x01 \x01(\tR\x06prefix\"\x8a\x01\n" +
+	"\n" +
+	"WatchEvent\x12+\n" +
+	"\x04kind\x18\x01 \x01(\x0e2\x17.hermit.WatchEvent.KindR\x04kind\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x03 \x01(\fR\x05value\"'\n" +
+	"\x04Kind\x12\a\n" +
+	"\x03SET\x10\x00\x12\n" +
+	"\n" +
+	"\x06DELETE\x10\x01\x12\n" +
+	"\n" +
+	"\x06EXPIRE\x10\x02\"\x13\n" +
+	"\x11DbSnapshotRequest\"#\n" +
+	"\rSnapshotChunk\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\"I\n" +
+	"\x11DbRestoreResponse\x12\x19\n" +
+	"\bdoc_keys\x18\x01 \x01(\x04R\adocKeys\x12\x19\n" +
+	"\brel_rows\x18\x02 \x01(\x04R\arelRows\"\x10\n" +
+	"\x0eMetricsRequest\"\x85\x01\n" +
+	"\tRpcMetric\x12\x16\n" +
+	"\x06method\x18\x01 \x01(\tR\x06method\x12\x14\n" +
+	"\x05calls\x18\x02 \x01(\x04R\x05calls\x12\x19\n" +
+	"\btotal_us\x18\x03 \x01(\x04R\atotalUs\x12\x15\n" +
+	"\x06max_us\x18\x04 \x01(\x04R\x05maxUs\x12\x18\n" +
+	"\abuckets\x18\x05 \x03(\x04R\abuckets\"\xaa\x04\n" +
+	"\x0fMetricsResponse\x12%\n" +
+	"\x04rpcs\x18\x01 \x03(\v2\x11.hermit.RpcMetricR\x04rpcs\x12*\n" +
+	"\x11latency_bounds_us\x18\x02 \x03(\x04R\x0flatencyBoundsUs\x12\x19\n" +
+	"\bdoc_keys\x18\x03 \x01(\x04R\adocKeys\x12\x1b\n" +
+	"\tdoc_bytes\x18\x04 \x01(\x04R\bdocBytes\x12\x19\n" +
+	"\brel_rows\x18\x05 \x01(\x04R\arelRows\x12,\n" +
+	"\x12rel_pending_writes\x18\x06 \x01(\x04R\x10relPendingWrites\x12\x1a\n" +
+	"\bwatchers\x18\a \x01(\x04R\bwatchers\x12#\n" +
+	"\rexpire_sweeps\x18\b \x01(\x04R\fexpireSweeps\x12!\n" +
+	"\fexpired_keys\x18\t \x01(\x04R\vexpiredKeys\x12/\n" +
+	"\x14expire_last_sweep_us\x18\n" +
+	" \x01(\x04R\x11expireLastSweepUs\x12 \n" +
+	"\vconnections\x18\v \x01(\x04R\vconnections\x12%\n" +
+	"\x0etls_handshakes\x18\f \x01(\x04R\rtlsHandshakes\x12\x1b\n" +
+	"\trss_bytes\x18\r \x01(\x04R\brssBytes\x12%\n" +
+	"\x0euptime_seconds\x18\x0e \x01(\x03R\ruptimeSeconds\x12!\n" +
+	"\frate_limited\x18\x0f \x01(\x04R\vrateLimited\":\n" +
+	"\x0ePublishRequest\x12\x14\n" +
+	"\x05topic\x18\x01 \x01(\tR\x05topic\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\"E\n" +
+	"\x0fPublishResponse\x12\x1c\n" +
+	"\tdelivered\x18\x01 \x01(\x04R\tdelivered\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"(\n" +
+	"\x10SubscribeRequest\x12\x14\n" +
+	"\x05topic\x18\x01 \x01(\tR\x05topic\"\xa8\x01\n" +
+	"\fTopicMessage\x12\x14\n" +
+	"\x05topic\x18\x01 \x01(\tR\x05topic\x12\x12\n" +
+	"\x04from\x18\x02 \x01(\tR\x04from\x12\x12\n" +
+	"\x04data\x18\x03 \x01(\fR\x04data\x12.\n" +
+	"\x04time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x10\n" +
+	"\x03seq\x18\x05 \x01(\x04R\x03seq\x12\x18\n" +
+	"\adropped\x18\x06 \x01(\x04R\adropped\"n\n" +
+	"\tRateLimit\x12\x14\n" +
+	"\x05class\x18\x01 \x01(\tR\x05class\x12\x16\n" +
+	"\x06client\x18\x02 \x01(\tR\x06client\x12\x1d\n" +
+	"\n" +
+	"per_second\x18\x03 \x01(\x01R\tperSecond\x12\x14\n" +
+	"\x05burst\x18\x04 \x01(\rR\x05burst\"\x0f\n" +
+	"\rLimitsRequest\"R\n" +
+	"\x0fSetLimitRequest\x12'\n" +
+	"\x05limit\x18\x01 \x01(\v2\x11.hermit.RateLimitR\x05limit\x12\x16\n" +
+	"\x06remove\x18\x02 \x01(\bR\x06remove\"Q\n" +
+	"\x0eLimitsResponse\x12)\n" +
+	"\x06limits\x18\x01 \x03(\v2\x11.hermit.RateLimitR\x06limits\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\".\n" +
+	"\x10ReplicateRequest\x12\x1a\n" +
+	"\bfollower\x18\x01 \x01(\tR\bfollower\"\xa0\x02\n" +
+	"\n" +
+	"OplogEntry\x12+\n" +
+	"\x04kind\x18\x01 \x01(\x0e2\x17.hermit.OplogEntry.KindR\x04kind\x12\x10\n" +
+	"\x03seq\x18\x02 \x01(\x04R\x03seq\x12\x10\n" +
+	"\x03key\x18\x03 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x04 \x01(\fR\x05value\x12\x18\n" +
+	"\aversion\x18\x05 \x01(\x04R\aversion\x12\x15\n" +
+	"\x06ttl_ms\x18\x06 \x01(\x04R\x05ttlMs\x12\x16\n" +
+	"\x06bucket\x18\a \x01(\tR\x06bucket\"b\n" +
+	"\x04Kind\x12\f\n" +
+	"\bSNAPSHOT\x10\x00\x12\x10\n" +
+	"\fSNAPSHOT_END\x10\x01\x12\a\n" +
+	"\x03SET\x10\x02\x12\n" +
+	"\n" +
+	"\x06DELETE\x10\x03\x12\n" +
+	"\n" +
+	"\x06EXPIRE\x10\x04\x12\r\n" +
+	"\tHEARTBEAT\x10\x05\x12\n" +
+	"\n" +
+	"\x06BUCKET\x10\x06\"\x1a\n" +
+	"\x18ReplicationStatusRequest\"\x91\x01\n" +
+	"\bFollower\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04addr\x18\x02 \x01(\tR\x04addr\x12\x19\n" +
+	"\bsent_seq\x18\x03 \x01(\x04R\asentSeq\x12\x10\n" +
+	"\x03lag\x18\x04 \x01(\x04R\x03lag\x120\n" +
+	"\x05since\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x05since\"\xe0\x02\n" +
+	"\x19ReplicationStatusResponse\x12\x12\n" +
+	"\x04role\x18\x01 \x01(\tR\x04role\x12\x1b\n" +
+	"\toplog_seq\x18\x02 \x01(\x04R\boplogSeq\x12.\n" +
+	"\tfollowers\x18\x03 \x03(\v2\x10.hermit.FollowerR\tfollowers\x12\x18\n" +
+	"\aprimary\x18\x04 \x01(\tR\aprimary\x12\x1c\n" +
+	"\tconnected\x18\x05 \x01(\bR\tconnected\x12\x1f\n" +
+	"\vapplied_seq\x18\x06 \x01(\x04R\n" +
+	"appliedSeq\x12\x1f\n" +
+	"\vprimary_seq\x18\a \x01(\x04R\n" +
+	"primarySeq\x12\x10\n" +
+	"\x03lag\x18\b \x01(\x04R\x03lag\x12&\n" +
+	"\x0flast_contact_ms\x18\t \x01(\x04R\rlastContactMs\x12\x18\n" +
+	"\aresyncs\x18\n" +
+	" \x01(\x04R\aresyncs\x12\x14\n" +
+	"\x05error\x18\v \x01(\tR\x05error\")\n" +
+	"\x13CreateBucketRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"0\n" +
+	"\x14CreateBucketResponse\x12\x18\n" +
+	"\acreated\x18\x01 \x01(\bR\acreated\"\x14\n" +
+	"\x12ListBucketsRequest\"\xba\x01\n" +
+	"\x06Bucket\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04keys\x18\x02 \x01(\x04R\x04keys\x12\x1b\n" +
+	"\traw_bytes\x18\x03 \x01(\x04R\brawBytes\x12!\n" +
+	"\fstored_bytes\x18\x04 \x01(\x04R\vstoredBytes\x124\n" +
+	"\acreated\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\acreated\x12\x12\n" +
+	"\x04role\x18\x06 \x01(\tR\x04role\"?\n" +
+	"\x13ListBucketsResponse\x12(\n" +
+	"\abuckets\x18\x01 \x03(\v2\x0e.hermit.BucketR\abuckets\"*\n" +
+	"\x12DiagnosticsRequest\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\rR\x05limit\"\x91\x01\n" +
+	"\bSlowCall\x12\x16\n" +
+	"\x06method\x18\x01 \x01(\tR\x06method\x12\x17\n" +
+	"\atook_us\x18\x02 \x01(\x04R\x06tookUs\x12*\n" +
+	"\x02at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x02at\x12\x16\n" +
+	"\x06bucket\x18\x04 \x01(\tR\x06bucket\x12\x10\n" +
+	"\x03key\x18\x05 \x01(\tR\x03key\"v\n" +
+	"\n" +
+	"LargeValue\x12\x16\n" +
+	"\x06bucket\x18\x01 \x01(\tR\x06bucket\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\tR\x03key\x12\x1b\n" +
+	"\traw_bytes\x18\x03 \x01(\x04R\brawBytes\x12!\n" +
+	"\fstored_bytes\x18\x04 \x01(\x04R\vstoredBytes\"\xd5\x01\n" +
+	"\x13DiagnosticsResponse\x12/\n" +
+	"\n" +
+	"slow_calls\x18\x01 \x03(\v2\x10.hermit.SlowCallR\tslowCalls\x12*\n" +
+	"\x11slow_threshold_us\x18\x02 \x01(\x04R\x0fslowThresholdUs\x125\n" +
+	"\flarge_values\x18\x03 \x03(\v2\x12.hermit.LargeValueR\vlargeValues\x12*\n" +
+	"\x11large_value_bytes\x18\x04 \x01(\x04R\x0flargeValueBytes\")\n" +
+	"\x15RestoreFromUrlRequest\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url2\xe7\x0f\n" +
 	"\x06Hermit\x121\n" +
 	"\x04Ping\x12\x13.hermit.PingRequest\x1a\x14.hermit.PingResponse\x12@\n" +
-	"\tBenchmark\x12\x18.hermit.BenchmarkRequest\x1a\x19.hermit.BenchmarkResponse\x124\n" +
+	"\tBenchmark\x12\x18.hermit.BenchmarkRequest\x1a\x19.hermit.BenchmarkResponse\x12H\n" +
+	"\x0fBenchmarkStream\x12\x18.hermit.BenchmarkRequest\x1a\x19.hermit.BenchmarkProgress0\x01\x124\n" +
 	"\x05Login\x12\x14.hermit.LoginRequest\x1a\x15.hermit.LoginResponse\x12C\n" +
 	"\n" +
 	"ServerInfo\x12\x19.hermit.ServerInfoRequest\x1a\x1a.hermit.ServerInfoResponse\x124\n" +
 	"\x05KvSet\x12\x14.hermit.KvSetRequest\x1a\x15.hermit.KvSetResponse\x124\n" +
 	"\x05KvGet\x12\x14.hermit.KvGetRequest\x1a\x15.hermit.KvGetResponse\x127\n" +
-	"\x06KvList\x12\x15.hermit.KvListRequest\x1a\x16.hermit.KvListResponse\x12@\n" +
+	"\x06KvList\x12\x15.hermit.KvListRequest\x1a\x16.hermit.KvListResponse\x12=\n" +
+	"\bKvDelete\x12\x17.hermit.KvDeleteRequest\x1a\x18.hermit.KvDeleteResponse\x12=\n" +
+	"\bKvExists\x12\x17.hermit.KvExistsRequest\x1a\x18.hermit.KvExistsResponse\x124\n" +
+	"\x05KvTTL\x12\x14.hermit.KvTTLRequest\x1a\x15.hermit.KvTTLResponse\x12.\n" +
+	"\x03Txn\x12\x12.hermit.TxnRequest\x1a\x13.hermit.TxnResponse\x12@\n" +
 	"\tSqlInsert\x12\x18.hermit.SqlInsertRequest\x1a\x19.hermit.SqlInsertResponse\x12=\n" +
-	"\bSqlQuery\x12\x17.hermit.SqlQueryRequest\x1a\x18.hermit.SqlQueryResponse\x12:\n" +
-	"\aDbStats\x12\x16.hermit.DbStatsRequest\x1a\x17.hermit.DbStatsResponseB+Z)github.com/jredh-dev/hermit/cmd/tui/protob\x06proto3"
+	"\bSqlQuery\x12\x17.hermit.SqlQueryRequest\x1a\x18.hermit.SqlQueryResponse\x12@\n" +
+	"\tSqlDelete\x12\x18.hermit.SqlDeleteRequest\x1a\x19.hermit.SqlDeleteResponse\x12@\n" +
+	"\tSqlUpdate\x12\x18.hermit.SqlUpdateRequest\x1a\x19.hermit.SqlUpdateResponse\x12:\n" +
+	"\aDbStats\x12\x16.hermit.DbStatsRequest\x1a\x17.hermit.DbStatsResponse\x126\n" +
+	"\bTailLogs\x12\x17.hermit.TailLogsRequest\x1a\x0f.hermit.LogLine0\x01\x123\n" +
+	"\x05Watch\x12\x14.hermit.WatchRequest\x1a\x12.hermit.WatchEvent0\x01\x12@\n" +
+	"\n" +
+	"DbSnapshot\x12\x19.hermit.DbSnapshotRequest\x1a\x15.hermit.SnapshotChunk0\x01\x12?\n" +
+	"\tDbRestore\x12\x15.hermit.SnapshotChunk\x1a\x19.hermit.DbRestoreResponse(\x01\x12:\n" +
+	"\aMetrics\x12\x16.hermit.MetricsRequest\x1a\x17.hermit.MetricsResponse\x12:\n" +
+	"\aPublish\x12\x16.hermit.PublishRequest\x1a\x17.hermit.PublishResponse\x12=\n" +
+	"\tSubscribe\x12\x18.hermit.SubscribeRequest\x1a\x14.hermit.TopicMessage0\x01\x127\n" +
+	"\x06Limits\x12\x15.hermit.LimitsRequest\x1a\x16.hermit.LimitsResponse\x12;\n" +
+	"\bSetLimit\x12\x17.hermit.SetLimitRequest\x1a\x16.hermit.LimitsResponse\x12;\n" +
+	"\tReplicate\x12\x18.hermit.ReplicateRequest\x1a\x12.hermit.OplogEntry0\x01\x12X\n" +
+	"\x11ReplicationStatus\x12 .hermit.ReplicationStatusRequest\x1a!.hermit.ReplicationStatusResponse\x12I\n" +
+	"\fCreateBucket\x12\x1b.hermit.CreateBucketRequest\x1a\x1c.hermit.CreateBucketResponse\x12F\n" +
+	"\vListBuckets\x12\x1a.hermit.ListBucketsRequest\x1a\x1b.hermit.ListBucketsResponse\x12F\n" +
+	"\vDiagnostics\x12\x1a.hermit.DiagnosticsRequest\x1a\x1b.hermit.DiagnosticsResponse\x12J\n" +
+	"\x0eRestoreFromUrl\x12\x1d.hermit.RestoreFromUrlRequest\x1a\x19.hermit.DbRestoreResponseB+Z)github.com/jredh-dev/hermit/cmd/tui/protob\x06proto3"
 
 var (
 	file_hermit_proto_rawDescOnce sync.Once
@@ -1327,59 +5040,176 @@ func file_hermit_proto_rawDescGZIP() []byte {
 	return file_hermit_proto_rawDescData
 }
 
-var file_hermit_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
+var file_hermit_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_hermit_proto_msgTypes = make([]protoimpl.MessageInfo, 71)
 var file_hermit_proto_goTypes = []any{
-	(*PingRequest)(nil),           // 0: hermit.PingRequest
-	(*PingResponse)(nil),          // 1: hermit.PingResponse
-	(*BenchmarkRequest)(nil),      // 2: hermit.BenchmarkRequest
-	(*BenchmarkResponse)(nil),     // 3: hermit.BenchmarkResponse
-	(*LoginRequest)(nil),          // 4: hermit.LoginRequest
-	(*LoginResponse)(nil),         // 5: hermit.LoginResponse
-	(*ServerInfoRequest)(nil),     // 6: hermit.ServerInfoRequest
-	(*ServerInfoResponse)(nil),    // 7: hermit.ServerInfoResponse
-	(*KvSetRequest)(nil),          // 8: hermit.KvSetRequest
-	(*KvSetResponse)(nil),         // 9: hermit.KvSetResponse
-	(*KvGetRequest)(nil),          // 10: hermit.KvGetRequest
-	(*KvGetResponse)(nil),         // 11: hermit.KvGetResponse
-	(*KvListRequest)(nil),         // 12: hermit.KvListRequest
-	(*KvListResponse)(nil),        // 13: hermit.KvListResponse
-	(*SqlInsertRequest)(nil),      // 14: hermit.SqlInsertRequest
-	(*SqlInsertResponse)(nil),     // 15: hermit.SqlInsertResponse
-	(*SqlQueryRequest)(nil),       // 16: hermit.SqlQueryRequest
-	(*SqlRow)(nil),                // 17: hermit.SqlRow
-	(*SqlQueryResponse)(nil),      // 18: hermit.SqlQueryResponse
-	(*DbStatsRequest)(nil),        // 19: hermit.DbStatsRequest
-	(*DbStatsResponse)(nil),       // 20: hermit.DbStatsResponse
-	(*timestamppb.Timestamp)(nil), // 21: google.protobuf.Timestamp
+	(SqlQueryRequest_Order)(0),        // 0: hermit.SqlQueryRequest.Order
+	(WatchEvent_Kind)(0),              // 1: hermit.WatchEvent.Kind
+	(OplogEntry_Kind)(0),              // 2: hermit.OplogEntry.Kind
+	(*PingRequest)(nil),               // 3: hermit.PingRequest
+	(*PingResponse)(nil),              // 4: hermit.PingResponse
+	(*BenchmarkRequest)(nil),          // 5: hermit.BenchmarkRequest
+	(*BenchmarkResponse)(nil),         // 6: hermit.BenchmarkResponse
+	(*HistogramBucket)(nil),           // 7: hermit.HistogramBucket
+	(*BenchmarkProgress)(nil),         // 8: hermit.BenchmarkProgress
+	(*LoginRequest)(nil),              // 9: hermit.LoginRequest
+	(*LoginResponse)(nil),             // 10: hermit.LoginResponse
+	(*ServerInfoRequest)(nil),         // 11: hermit.ServerInfoRequest
+	(*ServerInfoResponse)(nil),        // 12: hermit.ServerInfoResponse
+	(*KvSetRequest)(nil),              // 13: hermit.KvSetRequest
+	(*KvSetResponse)(nil),             // 14: hermit.KvSetResponse
+	(*KvGetRequest)(nil),              // 15: hermit.KvGetRequest
+	(*KvGetResponse)(nil),             // 16: hermit.KvGetResponse
+	(*KvListRequest)(nil),             // 17: hermit.KvListRequest
+	(*KvListResponse)(nil),            // 18: hermit.KvListResponse
+	(*KvDeleteRequest)(nil),           // 19: hermit.KvDeleteRequest
+	(*KvDeleteResponse)(nil),          // 20: hermit.KvDeleteResponse
+	(*KvExistsRequest)(nil),           // 21: hermit.KvExistsRequest
+	(*KvExistsResponse)(nil),          // 22: hermit.KvExistsResponse
+	(*KvTTLRequest)(nil),              // 23: hermit.KvTTLRequest
+	(*KvTTLResponse)(nil),             // 24: hermit.KvTTLResponse
+	(*TxnGuard)(nil),                  // 25: hermit.TxnGuard
+	(*TxnWrite)(nil),                  // 26: hermit.TxnWrite
+	(*TxnRequest)(nil),                // 27: hermit.TxnRequest
+	(*TxnResponse)(nil),               // 28: hermit.TxnResponse
+	(*SqlInsertRequest)(nil),          // 29: hermit.SqlInsertRequest
+	(*SqlInsertResponse)(nil),         // 30: hermit.SqlInsertResponse
+	(*SqlQueryRequest)(nil),           // 31: hermit.SqlQueryRequest
+	(*SqlRow)(nil),                    // 32: hermit.SqlRow
+	(*SqlQueryResponse)(nil),          // 33: hermit.SqlQueryResponse
+	(*SqlDeleteRequest)(nil),          // 34: hermit.SqlDeleteRequest
+	(*SqlDeleteResponse)(nil),         // 35: hermit.SqlDeleteResponse
+	(*SqlUpdateRequest)(nil),          // 36: hermit.SqlUpdateRequest
+	(*SqlUpdateResponse)(nil),         // 37: hermit.SqlUpdateResponse
+	(*DbStatsRequest)(nil),            // 38: hermit.DbStatsRequest
+	(*DbStatsResponse)(nil),           // 39: hermit.DbStatsResponse
+	(*DocNamespace)(nil),              // 40: hermit.DocNamespace
+	(*TailLogsRequest)(nil),           // 41: hermit.TailLogsRequest
+	(*LogLine)(nil),                   // 42: hermit.LogLine
+	(*WatchRequest)(nil),              // 43: hermit.WatchRequest
+	(*WatchEvent)(nil),                // 44: hermit.WatchEvent
+	(*DbSnapshotRequest)(nil),         // 45: hermit.DbSnapshotRequest
+	(*SnapshotChunk)(nil),             // 46: hermit.SnapshotChunk
+	(*DbRestoreResponse)(nil),         // 47: hermit.DbRestoreResponse
+	(*MetricsRequest)(nil),            // 48: hermit.MetricsRequest
+	(*RpcMetric)(nil),                 // 49: hermit.RpcMetric
+	(*MetricsResponse)(nil),           // 50: hermit.MetricsResponse
+	(*PublishRequest)(nil),            // 51: hermit.PublishRequest
+	(*PublishResponse)(nil),           // 52: hermit.PublishResponse
+	(*SubscribeRequest)(nil),          // 53: hermit.SubscribeRequest
+	(*TopicMessage)(nil),              // 54: hermit.TopicMessage
+	(*RateLimit)(nil),                 // 55: hermit.RateLimit
+	(*LimitsRequest)(nil),             // 56: hermit.LimitsRequest
+	(*SetLimitRequest)(nil),           // 57: hermit.SetLimitRequest
+	(*LimitsResponse)(nil),            // 58: hermit.LimitsResponse
+	(*ReplicateRequest)(nil),          // 59: hermit.ReplicateRequest
+	(*OplogEntry)(nil),                // 60: hermit.OplogEntry
+	(*ReplicationStatusRequest)(nil),  // 61: hermit.ReplicationStatusRequest
+	(*Follower)(nil),                  // 62: hermit.Follower
+	(*ReplicationStatusResponse)(nil), // 63: hermit.ReplicationStatusResponse
+	(*CreateBucketRequest)(nil),       // 64: hermit.CreateBucketRequest
+	(*CreateBucketResponse)(nil),      // 65: hermit.CreateBucketResponse
+	(*ListBucketsRequest)(nil),        // 66: hermit.ListBucketsRequest
+	(*Bucket)(nil),                    // 67: hermit.Bucket
+	(*ListBucketsResponse)(nil),       // 68: hermit.ListBucketsResponse
+	(*DiagnosticsRequest)(nil),        // 69: hermit.DiagnosticsRequest
+	(*SlowCall)(nil),                  // 70: hermit.SlowCall
+	(*LargeValue)(nil),                // 71: hermit.LargeValue
+	(*DiagnosticsResponse)(nil),       // 72: hermit.DiagnosticsResponse
+	(*RestoreFromUrlRequest)(nil),     // 73: hermit.RestoreFromUrlRequest
+	(*timestamppb.Timestamp)(nil),     // 74: google.protobuf.Timestamp
 }
 var file_hermit_proto_depIdxs = []int32{
-	21, // 0: hermit.ServerInfoResponse.started_at:type_name -> google.protobuf.Timestamp
-	17, // 1: hermit.SqlQueryResponse.rows:type_name -> hermit.SqlRow
-	0,  // 2: hermit.Hermit.Ping:input_type -> hermit.PingRequest
-	2,  // 3: hermit.Hermit.Benchmark:input_type -> hermit.BenchmarkRequest
-	4,  // 4: hermit.Hermit.Login:input_type -> hermit.LoginRequest
-	6,  // 5: hermit.Hermit.ServerInfo:input_type -> hermit.ServerInfoRequest
-	8,  // 6: hermit.Hermit.KvSet:input_type -> hermit.KvSetRequest
-	10, // 7: hermit.Hermit.KvGet:input_type -> hermit.KvGetRequest
-	12, // 8: hermit.Hermit.KvList:input_type -> hermit.KvListRequest
-	14, // 9: hermit.Hermit.SqlInsert:input_type -> hermit.SqlInsertRequest
-	16, // 10: hermit.Hermit.SqlQuery:input_type -> hermit.SqlQueryRequest
-	19, // 11: hermit.Hermit.DbStats:input_type -> hermit.DbStatsRequest
-	1,  // 12: hermit.Hermit.Ping:output_type -> hermit.PingResponse
-	3,  // 13: hermit.Hermit.Benchmark:output_type -> hermit.BenchmarkResponse
-	5,  // 14: hermit.Hermit.Login:output_type -> hermit.LoginResponse
-	7,  // 15: hermit.Hermit.ServerInfo:output_type -> hermit.ServerInfoResponse
-	9,  // 16: hermit.Hermit.KvSet:output_type -> hermit.KvSetResponse
-	11, // 17: hermit.Hermit.KvGet:output_type -> hermit.KvGetResponse
-	13, // 18: hermit.Hermit.KvList:output_type -> hermit.KvListResponse
-	15, // 19: hermit.Hermit.SqlInsert:output_type -> hermit.SqlInsertResponse
-	18, // 20: hermit.Hermit.SqlQuery:output_type -> hermit.SqlQueryResponse
-	20, // 21: hermit.Hermit.DbStats:output_type -> hermit.DbStatsResponse
-	12, // [12:22] is the sub-list for method output_type
-	2,  // [2:12] is the sub-list for method input_type
-	2,  // [2:2] is the sub-list for extension type_name
-	2,  // [2:2] is the sub-list for extension extendee
-	0,  // [0:2] is the sub-list for field type_name
+	7,  // 0: hermit.BenchmarkProgress.histogram:type_name -> hermit.HistogramBucket
+	74, // 1: hermit.ServerInfoResponse.started_at:type_name -> google.protobuf.Timestamp
+	25, // 2: hermit.TxnRequest.guards:type_name -> hermit.TxnGuard
+	26, // 3: hermit.TxnRequest.writes:type_name -> hermit.TxnWrite
+	0,  // 4: hermit.SqlQueryRequest.order_by:type_name -> hermit.SqlQueryRequest.Order
+	32, // 5: hermit.SqlQueryResponse.rows:type_name -> hermit.SqlRow
+	40, // 6: hermit.DbStatsResponse.namespaces:type_name -> hermit.DocNamespace
+	74, // 7: hermit.LogLine.time:type_name -> google.protobuf.Timestamp
+	1,  // 8: hermit.WatchEvent.kind:type_name -> hermit.WatchEvent.Kind
+	49, // 9: hermit.MetricsResponse.rpcs:type_name -> hermit.RpcMetric
+	74, // 10: hermit.TopicMessage.time:type_name -> google.protobuf.Timestamp
+	55, // 11: hermit.SetLimitRequest.limit:type_name -> hermit.RateLimit
+	55, // 12: hermit.LimitsResponse.limits:type_name -> hermit.RateLimit
+	2,  // 13: hermit.OplogEntry.kind:type_name -> hermit.OplogEntry.Kind
+	74, // 14: hermit.Follower.since:type_name -> google.protobuf.Timestamp
+	62, // 15: hermit.ReplicationStatusResponse.followers:type_name -> hermit.Follower
+	74, // 16: hermit.Bucket.created:type_name -> google.protobuf.Timestamp
+	67, // 17: hermit.ListBucketsResponse.buckets:type_name -> hermit.Bucket
+	74, // 18: hermit.SlowCall.at:type_name -> google.protobuf.Timestamp
+	70, // 19: hermit.DiagnosticsResponse.slow_calls:type_name -> hermit.SlowCall
+	71, // 20: hermit.DiagnosticsResponse.large_values:type_name -> hermit.LargeValue
+	3,  // 21: hermit.Hermit.Ping:input_type -> hermit.PingRequest
+	5,  // 22: hermit.Hermit.Benchmark:input_type -> hermit.BenchmarkRequest
+	5,  // 23: hermit.Hermit.BenchmarkStream:input_type -> hermit.BenchmarkRequest
+	9,  // 24: hermit.Hermit.Login:input_type -> hermit.LoginRequest
+	11, // 25: hermit.Hermit.ServerInfo:input_type -> hermit.ServerInfoRequest
+	13, // 26: hermit.Hermit.KvSet:input_type -> hermit.KvSetRequest
+	15, // 27: hermit.Hermit.KvGet:input_type -> hermit.KvGetRequest
+	17, // 28: hermit.Hermit.KvList:input_type -> hermit.KvListRequest
+	19, // 29: hermit.Hermit.KvDelete:input_type -> hermit.KvDeleteRequest
+	21, // 30: hermit.Hermit.KvExists:input_type -> hermit.KvExistsRequest
+	23, // 31: hermit.Hermit.KvTTL:input_type -> hermit.KvTTLRequest
+	27, // 32: hermit.Hermit.Txn:input_type -> hermit.TxnRequest
+	29, // 33: hermit.Hermit.SqlInsert:input_type -> hermit.SqlInsertRequest
+	31, // 34: hermit.Hermit.SqlQuery:input_type -> hermit.SqlQueryRequest
+	34, // 35: hermit.Hermit.SqlDelete:input_type -> hermit.SqlDeleteRequest
+	36, // 36: hermit.Hermit.SqlUpdate:input_type -> hermit.SqlUpdateRequest
+	38, // 37: hermit.Hermit.DbStats:input_type -> hermit.DbStatsRequest
+	41, // 38: hermit.Hermit.TailLogs:input_type -> hermit.TailLogsRequest
+	43, // 39: hermit.Hermit.Watch:input_type -> hermit.WatchRequest
+	45, // 40: hermit.Hermit.DbSnapshot:input_type -> hermit.DbSnapshotRequest
+	46, // 41: hermit.Hermit.DbRestore:input_type -> hermit.SnapshotChunk
+	48, // 42: hermit.Hermit.Metrics:input_type -> hermit.MetricsRequest
+	51, // 43: hermit.Hermit.Publish:input_type -> hermit.PublishRequest
+	53, // 44: hermit.Hermit.Subscribe:input_type -> hermit.SubscribeRequest
+	56, // 45: hermit.Hermit.Limits:input_type -> hermit.LimitsRequest
+	57, // 46: hermit.Hermit.SetLimit:input_type -> hermit.SetLimitRequest
+	59, // 47: hermit.Hermit.Replicate:input_type -> hermit.ReplicateRequest
+	61, // 48: hermit.Hermit.ReplicationStatus:input_type -> hermit.ReplicationStatusRequest
+	64, // 49: hermit.Hermit.CreateBucket:input_type -> hermit.CreateBucketRequest
+	66, // 50: hermit.Hermit.ListBuckets:input_type -> hermit.ListBucketsRequest
+	69, // 51: hermit.Hermit.Diagnostics:input_type -> hermit.DiagnosticsRequest
+	73, // 52: hermit.Hermit.RestoreFromUrl:input_type -> hermit.RestoreFromUrlRequest
+	4,  // 53: hermit.Hermit.Ping:output_type -> hermit.PingResponse
+	6,  // 54: hermit.Hermit.Benchmark:output_type -> hermit.BenchmarkResponse
+	8,  // 55: hermit.Hermit.BenchmarkStream:output_type -> hermit.BenchmarkProgress
+	10, // 56: hermit.Hermit.Login:output_type -> hermit.LoginResponse
+	12, // 57: hermit.Hermit.ServerInfo:output_type -> hermit.ServerInfoResponse
+	14, // 58: hermit.Hermit.KvSet:output_type -> hermit.KvSetResponse
+	16, // 59: hermit.Hermit.KvGet:output_type -> hermit.KvGetResponse
+	18, // 60: hermit.Hermit.KvList:output_type -> hermit.KvListResponse
+	20, // 61: hermit.Hermit.KvDelete:output_type -> hermit.KvDeleteResponse
+	22, // 62: hermit.Hermit.KvExists:output_type -> hermit.KvExistsResponse
+	24, // 63: hermit.Hermit.KvTTL:output_type -> hermit.KvTTLResponse
+	28, // 64: hermit.Hermit.Txn:output_type -> hermit.TxnResponse
+	30, // 65: hermit.Hermit.SqlInsert:output_type -> hermit.SqlInsertResponse
+	33, // 66: hermit.Hermit.SqlQuery:output_type -> hermit.SqlQueryResponse
+	35, // 67: hermit.Hermit.SqlDelete:output_type -> hermit.SqlDeleteResponse
+	37, // 68: hermit.Hermit.SqlUpdate:output_type -> hermit.SqlUpdateResponse
+	39, // 69: hermit.Hermit.DbStats:output_type -> hermit.DbStatsResponse
+	42, // 70: hermit.Hermit.TailLogs:output_type -> hermit.LogLine
+	44, // 71: hermit.Hermit.Watch:output_type -> hermit.WatchEvent
+	46, // 72: hermit.Hermit.DbSnapshot:output_type -> hermit.SnapshotChunk
+	47, // 73: hermit.Hermit.DbRestore:output_type -> hermit.DbRestoreResponse
+	50, // 74: hermit.Hermit.Metrics:output_type -> hermit.MetricsResponse
+	52, // 75: hermit.Hermit.Publish:output_type -> hermit.PublishResponse
+	54, // 76: hermit.Hermit.Subscribe:output_type -> hermit.TopicMessage
+	58, // 77: hermit.Hermit.Limits:output_type -> hermit.LimitsResponse
+	58, // 78: hermit.Hermit.SetLimit:output_type -> hermit.LimitsResponse
+	60, // 79: hermit.Hermit.Replicate:output_type -> hermit.OplogEntry
+	63, // 80: hermit.Hermit.ReplicationStatus:output_type -> hermit.ReplicationStatusResponse
+	65, // 81: hermit.Hermit.CreateBucket:output_type -> hermit.CreateBucketResponse
+	68, // 82: hermit.Hermit.ListBuckets:output_type -> hermit.ListBucketsResponse
+	72, // 83: hermit.Hermit.Diagnostics:output_type -> hermit.DiagnosticsResponse
+	47, // 84: hermit.Hermit.RestoreFromUrl:output_type -> hermit.DbRestoreResponse
+	53, // [53:85] is the sub-list for method output_type
+	21, // [21:53] is the sub-list for method input_type
+	21, // [21:21] is the sub-list for extension type_name
+	21, // [21:21] is the sub-list for extension extendee
+	0,  // [0:21] is the sub-list for field type_name
 }
 
 func init() { file_hermit_proto_init() }
@@ -1387,18 +5217,20 @@ func file_hermit_proto_init() {
 	if File_hermit_proto != nil {
 		return
 	}
+	file_hermit_proto_msgTypes[22].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_hermit_proto_rawDesc), len(file_hermit_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   21,
+			NumEnums:      3,
+			NumMessages:   71,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_hermit_proto_goTypes,
 		DependencyIndexes: file_hermit_proto_depIdxs,
+		EnumInfos:         file_hermit_proto_enumTypes,
 		MessageInfos:      file_hermit_proto_msgTypes,
 	}.Build()
 	File_hermit_proto = out.File
@@ -22,16 +22,38 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Hermit_Ping_FullMethodName       = "/hermit.Hermit/Ping"
-	Hermit_Benchmark_FullMethodName  = "/hermit.Hermit/Benchmark"
-	Hermit_Login_FullMethodName      = "/hermit.Hermit/Login"
-	Hermit_ServerInfo_FullMethodName = "/hermit.Hermit/ServerInfo"
-	Hermit_KvSet_FullMethodName      = "/hermit.Hermit/KvSet"
-	Hermit_KvGet_FullMethodName      = "/hermit.Hermit/KvGet"
-	Hermit_KvList_FullMethodName     = "/hermit.Hermit/KvList"
-	Hermit_SqlInsert_FullMethodName  = "/hermit.Hermit/SqlInsert"
-	Hermit_SqlQuery_FullMethodName   = "/hermit.Hermit/SqlQuery"
-	Hermit_DbStats_FullMethodName    = "/hermit.Hermit/DbStats"
+	Hermit_Ping_FullMethodName              = "/hermit.Hermit/Ping"
+	Hermit_Benchmark_FullMethodName         = "/hermit.Hermit/Benchmark"
+	Hermit_BenchmarkStream_FullMethodName   = "/hermit.Hermit/BenchmarkStream"
+	Hermit_Login_FullMethodName             = "/hermit.Hermit/Login"
+	Hermit_ServerInfo_FullMethodName        = "/hermit.Hermit/ServerInfo"
+	Hermit_KvSet_FullMethodName             = "/hermit.Hermit/KvSet"
+	Hermit_KvGet_FullMethodName             = "/hermit.Hermit/KvGet"
+	Hermit_KvList_FullMethodName            = "/hermit.Hermit/KvList"
+	Hermit_KvDelete_FullMethodName          = "/hermit.Hermit/KvDelete"
+	Hermit_KvExists_FullMethodName          = "/hermit.Hermit/KvExists"
+	Hermit_KvTTL_FullMethodName             = "/hermit.Hermit/KvTTL"
+	Hermit_Txn_FullMethodName               = "/hermit.Hermit/Txn"
+	Hermit_SqlInsert_FullMethodName         = "/hermit.Hermit/SqlInsert"
+	Hermit_SqlQuery_FullMethodName          = "/hermit.Hermit/SqlQuery"
+	Hermit_SqlDelete_FullMethodName         = "/hermit.Hermit/SqlDelete"
+	Hermit_SqlUpdate_FullMethodName         = "/hermit.Hermit/SqlUpdate"
+	Hermit_DbStats_FullMethodName           = "/hermit.Hermit/DbStats"
+	Hermit_TailLogs_FullMethodName          = "/hermit.Hermit/TailLogs"
+	Hermit_Watch_FullMethodName             = "/hermit.Hermit/Watch"
+	Hermit_DbSnapshot_FullMethodName        = "/hermit.Hermit/DbSnapshot"
+	Hermit_DbRestore_FullMethodName         = "/hermit.Hermit/DbRestore"
+	Hermit_Metrics_FullMethodName           = "/hermit.Hermit/Metrics"
+	Hermit_Publish_FullMethodName           = "/hermit.Hermit/Publish"
+	Hermit_Subscribe_FullMethodName         = "/hermit.Hermit/Subscribe"
+	Hermit_Limits_FullMethodName            = "/hermit.Hermit/Limits"
+	Hermit_SetLimit_FullMethodName          = "/hermit.Hermit/SetLimit"
+	Hermit_Replicate_FullMethodName         = "/hermit.Hermit/Replicate"
+	Hermit_ReplicationStatus_FullMethodName = "/hermit.Hermit/ReplicationStatus"
+	Hermit_CreateBucket_FullMethodName      = "/hermit.Hermit/CreateBucket"
+	Hermit_ListBuckets_FullMethodName       = "/hermit.Hermit/ListBuckets"
+	Hermit_Diagnostics_FullMethodName       = "/hermit.Hermit/Diagnostics"
+	Hermit_RestoreFromUrl_FullMethodName    = "/hermit.Hermit/RestoreFromUrl"
 )
 
 // HermitClient is the client API for Hermit service.
@@ -43,22 +65,111 @@ type HermitClient interface {
 	// Benchmark runs a latency test: server timestamps request receipt and
 	// response dispatch so the client can compute wire time vs processing time.
 	Benchmark(ctx context.Context, in *BenchmarkRequest, opts ...grpc.CallOption) (*BenchmarkResponse, error)
-	// Login authenticates a client session. Hardcoded success for now.
+	// BenchmarkStream runs a benchmark like Benchmark, but sends progress
+	// about ten times a second and ends with a latency histogram and
+	// throughput instead of every sample, so it can run far more iterations.
+	// Each iteration copies the payload, as handling a request that size would.
+	BenchmarkStream(ctx context.Context, in *BenchmarkRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BenchmarkProgress], error)
+	// Login checks a user's token and starts a session. Later calls send the
+	// session id as x-hermit-session metadata; each RPC needs the session's
+	// role to be read, write or admin.
 	Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error)
 	// ServerInfo returns server metadata (version, region, uptime).
 	ServerInfo(ctx context.Context, in *ServerInfoRequest, opts ...grpc.CallOption) (*ServerInfoResponse, error)
-	// KvSet stores a value under a key (zstd-compressed server-side).
+	// KvSet stores a value under a key (zstd-compressed server-side),
+	// optionally expiring after a TTL.
 	KvSet(ctx context.Context, in *KvSetRequest, opts ...grpc.CallOption) (*KvSetResponse, error)
 	// KvGet retrieves a value by key (decompressed).
 	KvGet(ctx context.Context, in *KvGetRequest, opts ...grpc.CallOption) (*KvGetResponse, error)
-	// KvList returns all keys in the document store.
+	// KvList returns document store keys in order, a page at a time,
+	// optionally only those with a prefix.
 	KvList(ctx context.Context, in *KvListRequest, opts ...grpc.CallOption) (*KvListResponse, error)
+	// KvDelete removes a key. Deleting a key that is not set is not an error.
+	KvDelete(ctx context.Context, in *KvDeleteRequest, opts ...grpc.CallOption) (*KvDeleteResponse, error)
+	// KvExists reports whether a key is set without fetching its value.
+	KvExists(ctx context.Context, in *KvExistsRequest, opts ...grpc.CallOption) (*KvExistsResponse, error)
+	// KvTTL reports how long a key has left before it expires.
+	KvTTL(ctx context.Context, in *KvTTLRequest, opts ...grpc.CallOption) (*KvTTLResponse, error)
+	// Txn checks every guard and, only if all hold, applies every write, as
+	// one atomic step. Guards on versions let clients build locks and
+	// counters with compare-and-swap.
+	Txn(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error)
 	// SqlInsert enqueues a row for at-least-once write into the relational store.
 	SqlInsert(ctx context.Context, in *SqlInsertRequest, opts ...grpc.CallOption) (*SqlInsertResponse, error)
-	// SqlQuery performs an eventual-consistent scan with optional key filter.
+	// SqlQuery performs an eventual-consistent scan with optional key and
+	// value filters, ordering and paging, or counts the matching rows.
 	SqlQuery(ctx context.Context, in *SqlQueryRequest, opts ...grpc.CallOption) (*SqlQueryResponse, error)
-	// DbStats returns combined stats for both stores.
+	// SqlDelete removes every row with a key.
+	SqlDelete(ctx context.Context, in *SqlDeleteRequest, opts ...grpc.CallOption) (*SqlDeleteResponse, error)
+	// SqlUpdate sets the value of every row with a key.
+	SqlUpdate(ctx context.Context, in *SqlUpdateRequest, opts ...grpc.CallOption) (*SqlUpdateResponse, error)
+	// DbStats returns combined stats for both stores, with how well each
+	// document store namespace compresses under its codec.
 	DbStats(ctx context.Context, in *DbStatsRequest, opts ...grpc.CallOption) (*DbStatsResponse, error)
+	// TailLogs streams the server's recent log lines, then new ones as they
+	// are written, until the client cancels.
+	TailLogs(ctx context.Context, in *TailLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogLine], error)
+	// Watch streams set, delete and expire events for keys with a prefix
+	// until the client cancels. A watcher that falls too far behind gets
+	// DATA_LOSS and should re-read the keys before watching again.
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchEvent], error)
+	// DbSnapshot streams both stores as one zstd-compressed snapshot. Keys
+	// keep their versions and remaining TTLs.
+	DbSnapshot(ctx context.Context, in *DbSnapshotRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SnapshotChunk], error)
+	// DbRestore replaces both stores with a snapshot streamed in the chunks
+	// DbSnapshot sent. A snapshot that doesn't decode changes nothing.
+	DbRestore(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[SnapshotChunk, DbRestoreResponse], error)
+	// Metrics returns per-RPC call counts and latency histograms, the stores'
+	// sizes, the expirer's work and connection counts. Hermit can serve the
+	// same in the Prometheus text format on --metrics-port.
+	Metrics(ctx context.Context, in *MetricsRequest, opts ...grpc.CallOption) (*MetricsResponse, error)
+	// Publish sends a message to everyone subscribed to a topic right now.
+	// Topics live in memory and keep no messages, so clients can talk through
+	// hermit without a broker. Needs the write role.
+	Publish(ctx context.Context, in *PublishRequest, opts ...grpc.CallOption) (*PublishResponse, error)
+	// Subscribe streams the messages published to a topic from now on until
+	// the client cancels. A subscriber that falls behind misses messages
+	// rather than being cut off; the next message says how many.
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TopicMessage], error)
+	// Limits lists the rate limits: each class's default and the clients
+	// that have their own. Needs the admin role.
+	//
+	// Writes and benchmarks are limited per client by token buckets. A
+	// limited call's response carries x-hermit-quota-class, -limit (the
+	// burst), -rate (calls a second) and -remaining metadata; a refused call
+	// gets RESOURCE_EXHAUSTED with the same and x-hermit-retry-after-ms.
+	Limits(ctx context.Context, in *LimitsRequest, opts ...grpc.CallOption) (*LimitsResponse, error)
+	// SetLimit changes a class's default limit, or one client's, taking
+	// effect on the next call. Needs the admin role.
+	SetLimit(ctx context.Context, in *SetLimitRequest, opts ...grpc.CallOption) (*LimitsResponse, error)
+	// Replicate is how a follower (hermit --follow) keeps up with this
+	// server: a snapshot of both stores, then every document store change
+	// after it, numbered by this server's oplog position, with heartbeats
+	// while idle. A follower that falls behind is cut off and starts over.
+	// Needs the admin role; followers refuse it.
+	Replicate(ctx context.Context, in *ReplicateRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OplogEntry], error)
+	// ReplicationStatus says whether this server is a primary or a
+	// follower: a primary lists its followers, a follower how far behind
+	// its primary it is.
+	ReplicationStatus(ctx context.Context, in *ReplicationStatusRequest, opts ...grpc.CallOption) (*ReplicationStatusResponse, error)
+	// CreateBucket makes a document store bucket: a keyspace of its own.
+	// Key calls (KvSet, KvGet, KvList, KvDelete, KvExists, KvTTL, Txn and
+	// Watch) use the bucket named by x-hermit-bucket metadata, or "default"
+	// without it. Needs the admin role.
+	CreateBucket(ctx context.Context, in *CreateBucketRequest, opts ...grpc.CallOption) (*CreateBucketResponse, error)
+	// ListBuckets returns the buckets the caller may use, with their sizes
+	// and the caller's access to each.
+	ListBuckets(ctx context.Context, in *ListBucketsRequest, opts ...grpc.CallOption) (*ListBucketsResponse, error)
+	// Diagnostics returns the slowest recent calls (those over --slow-ms)
+	// and the largest values in the document store, for tracking down
+	// latency. Needs the admin role: it names keys in every bucket.
+	Diagnostics(ctx context.Context, in *DiagnosticsRequest, opts ...grpc.CallOption) (*DiagnosticsResponse, error)
+	// RestoreFromUrl replaces both stores with a snapshot hermit fetches
+	// itself: an s3://bucket/key URL, signed with the credentials its
+	// backups use, or an http(s):// one such as a presigned URL, unless its
+	// host is link-local. Needs the admin role; a snapshot that doesn't
+	// decode changes nothing.
+	RestoreFromUrl(ctx context.Context, in *RestoreFromUrlRequest, opts ...grpc.CallOption) (*DbRestoreResponse, error)
 }
 
 type hermitClient struct {
@@ -89,6 +200,25 @@ func (c *hermitClient) Benchmark(ctx context.Context, in *BenchmarkRequest, opts
 	return out, nil
 }
 
+func (c *hermitClient) BenchmarkStream(ctx context.Context, in *BenchmarkRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BenchmarkProgress], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Hermit_ServiceDesc.Streams[0], Hermit_BenchmarkStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[BenchmarkRequest, BenchmarkProgress]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Hermit_BenchmarkStreamClient = grpc.ServerStreamingClient[BenchmarkProgress]
+
 func (c *hermitClient) Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(LoginResponse)
@@ -139,6 +269,46 @@ func (c *hermitClient) KvList(ctx context.Context, in *KvListRequest, opts ...gr
 	return out, nil
 }
 
+func (c *hermitClient) KvDelete(ctx context.Context, in *KvDeleteRequest, opts ...grpc.CallOption) (*KvDeleteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(KvDeleteResponse)
+	err := c.cc.Invoke(ctx, Hermit_KvDelete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hermitClient) KvExists(ctx context.Context, in *KvExistsRequest, opts ...grpc.CallOption) (*KvExistsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(KvExistsResponse)
+	err := c.cc.Invoke(ctx, Hermit_KvExists_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hermitClient) KvTTL(ctx context.Context, in *KvTTLRequest, opts ...grpc.CallOption) (*KvTTLResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(KvTTLResponse)
+	err := c.cc.Invoke(ctx, Hermit_KvTTL_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hermitClient) Txn(ctx context.Context, in *TxnRequest, opts ...grpc.CallOption) (*TxnResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TxnResponse)
+	err := c.cc.Invoke(ctx, Hermit_Txn_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *hermitClient) SqlInsert(ctx context.Context, in *SqlInsertRequest, opts ...grpc.CallOption) (*SqlInsertResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(SqlInsertResponse)
@@ -159,6 +329,26 @@ func (c *hermitClient) SqlQuery(ctx context.Context, in *SqlQueryRequest, opts .
 	return out, nil
 }
 
+func (c *hermitClient) SqlDelete(ctx context.Context, in *SqlDeleteRequest, opts ...grpc.CallOption) (*SqlDeleteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SqlDeleteResponse)
+	err := c.cc.Invoke(ctx, Hermit_SqlDelete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hermitClient) SqlUpdate(ctx context.Context, in *SqlUpdateRequest, opts ...grpc.CallOption) (*SqlUpdateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SqlUpdateResponse)
+	err := c.cc.Invoke(ctx, Hermit_SqlUpdate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *hermitClient) DbStats(ctx context.Context, in *DbStatsRequest, opts ...grpc.CallOption) (*DbStatsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(DbStatsResponse)
@@ -169,6 +359,204 @@ func (c *hermitClient) DbStats(ctx context.Context, in *DbStatsRequest, opts ...
 	return out, nil
 }
 
+func (c *hermitClient) TailLogs(ctx context.Context, in *TailLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogLine], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Hermit_ServiceDesc.Streams[1], Hermit_TailLogs_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[TailLogsRequest, LogLine]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Hermit_TailLogsClient = grpc.ServerStreamingClient[LogLine]
+
+func (c *hermitClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Hermit_ServiceDesc.Streams[2], Hermit_Watch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRequest, WatchEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Hermit_WatchClient = grpc.ServerStreamingClient[WatchEvent]
+
+func (c *hermitClient) DbSnapshot(ctx context.Context, in *DbSnapshotRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SnapshotChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Hermit_ServiceDesc.Streams[3], Hermit_DbSnapshot_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[DbSnapshotRequest, SnapshotChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Hermit_DbSnapshotClient = grpc.ServerStreamingClient[SnapshotChunk]
+
+func (c *hermitClient) DbRestore(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[SnapshotChunk, DbRestoreResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Hermit_ServiceDesc.Streams[4], Hermit_DbRestore_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SnapshotChunk, DbRestoreResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Hermit_DbRestoreClient = grpc.ClientStreamingClient[SnapshotChunk, DbRestoreResponse]
+
+func (c *hermitClient) Metrics(ctx context.Context, in *MetricsRequest, opts ...grpc.CallOption) (*MetricsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MetricsResponse)
+	err := c.cc.Invoke(ctx, Hermit_Metrics_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hermitClient) Publish(ctx context.Context, in *PublishRequest, opts ...grpc.CallOption) (*PublishResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PublishResponse)
+	err := c.cc.Invoke(ctx, Hermit_Publish_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hermitClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TopicMessage], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Hermit_ServiceDesc.Streams[5], Hermit_Subscribe_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribeRequest, TopicMessage]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Hermit_SubscribeClient = grpc.ServerStreamingClient[TopicMessage]
+
+func (c *hermitClient) Limits(ctx context.Context, in *LimitsRequest, opts ...grpc.CallOption) (*LimitsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LimitsResponse)
+	err := c.cc.Invoke(ctx, Hermit_Limits_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hermitClient) SetLimit(ctx context.Context, in *SetLimitRequest, opts ...grpc.CallOption) (*LimitsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LimitsResponse)
+	err := c.cc.Invoke(ctx, Hermit_SetLimit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hermitClient) Replicate(ctx context.Context, in *ReplicateRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OplogEntry], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Hermit_ServiceDesc.Streams[6], Hermit_Replicate_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ReplicateRequest, OplogEntry]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Hermit_ReplicateClient = grpc.ServerStreamingClient[OplogEntry]
+
+func (c *hermitClient) ReplicationStatus(ctx context.Context, in *ReplicationStatusRequest, opts ...grpc.CallOption) (*ReplicationStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReplicationStatusResponse)
+	err := c.cc.Invoke(ctx, Hermit_ReplicationStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hermitClient) CreateBucket(ctx context.Context, in *CreateBucketRequest, opts ...grpc.CallOption) (*CreateBucketResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateBucketResponse)
+	err := c.cc.Invoke(ctx, Hermit_CreateBucket_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hermitClient) ListBuckets(ctx context.Context, in *ListBucketsRequest, opts ...grpc.CallOption) (*ListBucketsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListBucketsResponse)
+	err := c.cc.Invoke(ctx, Hermit_ListBuckets_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hermitClient) Diagnostics(ctx context.Context, in *DiagnosticsRequest, opts ...grpc.CallOption) (*DiagnosticsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DiagnosticsResponse)
+	err := c.cc.Invoke(ctx, Hermit_Diagnostics_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hermitClient) RestoreFromUrl(ctx context.Context, in *RestoreFromUrlRequest, opts ...grpc.CallOption) (*DbRestoreResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DbRestoreResponse)
+	err := c.cc.Invoke(ctx, Hermit_RestoreFromUrl_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // HermitServer is the server API for Hermit service.
 // All implementations must embed UnimplementedHermitServer
 // for forward compatibility.
@@ -178,22 +566,111 @@ type HermitServer interface {
 	// Benchmark runs a latency test: server timestamps request receipt and
 	// response dispatch so the client can compute wire time vs processing time.
 	Benchmark(context.Context, *BenchmarkRequest) (*BenchmarkResponse, error)
-	// Login authenticates a client session. Hardcoded success for now.
+	// BenchmarkStream runs a benchmark like Benchmark, but sends progress
+	// about ten times a second and ends with a latency histogram and
+	// throughput instead of every sample, so it can run far more iterations.
+	// Each iteration copies the payload, as handling a request that size would.
+	BenchmarkStream(*BenchmarkRequest, grpc.ServerStreamingServer[BenchmarkProgress]) error
+	// Login checks a user's token and starts a session. Later calls send the
+	// session id as x-hermit-session metadata; each RPC needs the session's
+	// role to be read, write or admin.
 	Login(context.Context, *LoginRequest) (*LoginResponse, error)
 	// ServerInfo returns server metadata (version, region, uptime).
 	ServerInfo(context.Context, *ServerInfoRequest) (*ServerInfoResponse, error)
-	// KvSet stores a value under a key (zstd-compressed server-side).
+	// KvSet stores a value under a key (zstd-compressed server-side),
+	// optionally expiring after a TTL.
 	KvSet(context.Context, *KvSetRequest) (*KvSetResponse, error)
 	// KvGet retrieves a value by key (decompressed).
 	KvGet(context.Context, *KvGetRequest) (*KvGetResponse, error)
-	// KvList returns all keys in the document store.
+	// KvList returns document store keys in order, a page at a time,
+	// optionally only those with a prefix.
 	KvList(context.Context, *KvListRequest) (*KvListResponse, error)
+	// KvDelete removes a key. Deleting a key that is not set is not an error.
+	KvDelete(context.Context, *KvDeleteRequest) (*KvDeleteResponse, error)
+	// KvExists reports whether a key is set without fetching its value.
+	KvExists(context.Context, *KvExistsRequest) (*KvExistsResponse, error)
+	// KvTTL reports how long a key has left before it expires.
+	KvTTL(context.Context, *KvTTLRequest) (*KvTTLResponse, error)
+	// Txn checks every guard and, only if all hold, applies every write, as
+	// one atomic step. Guards on versions let clients build locks and
+	// counters with compare-and-swap.
+	Txn(context.Context, *TxnRequest) (*TxnResponse, error)
 	// SqlInsert enqueues a row for at-least-once write into the relational store.
 	SqlInsert(context.Context, *SqlInsertRequest) (*SqlInsertResponse, error)
-	// SqlQuery performs an eventual-consistent scan with optional key filter.
+	// SqlQuery performs an eventual-consistent scan with optional key and
+	// value filters, ordering and paging, or counts the matching rows.
 	SqlQuery(context.Context, *SqlQueryRequest) (*SqlQueryResponse, error)
-	// DbStats returns combined stats for both stores.
+	// SqlDelete removes every row with a key.
+	SqlDelete(context.Context, *SqlDeleteRequest) (*SqlDeleteResponse, error)
+	// SqlUpdate sets the value of every row with a key.
+	SqlUpdate(context.Context, *SqlUpdateRequest) (*SqlUpdateResponse, error)
+	// DbStats returns combined stats for both stores, with how well each
+	// document store namespace compresses under its codec.
 	DbStats(context.Context, *DbStatsRequest) (*DbStatsResponse, error)
+	// TailLogs streams the server's recent log lines, then new ones as they
+	// are written, until the client cancels.
+	TailLogs(*TailLogsRequest, grpc.ServerStreamingServer[LogLine]) error
+	// Watch streams set, delete and expire events for keys with a prefix
+	// until the client cancels. A watcher that falls too far behind gets
+	// DATA_LOSS and should re-read the keys before watching again.
+	Watch(*WatchRequest, grpc.ServerStreamingServer[WatchEvent]) error
+	// DbSnapshot streams both stores as one zstd-compressed snapshot. Keys
+	// keep their versions and remaining TTLs.
+	DbSnapshot(*DbSnapshotRequest, grpc.ServerStreamingServer[SnapshotChunk]) error
+	// DbRestore replaces both stores with a snapshot streamed in the chunks
+	// DbSnapshot sent. A snapshot that doesn't decode changes nothing.
+	DbRestore(grpc.ClientStreamingServer[SnapshotChunk, DbRestoreResponse]) error
+	// Metrics returns per-RPC call counts and latency histograms, the stores'
+	// sizes, the expirer's work and connection counts. Hermit can serve the
+	// same in the Prometheus text format on --metrics-port.
+	Metrics(context.Context, *MetricsRequest) (*MetricsResponse, error)
+	// Publish sends a message to everyone subscribed to a topic right now.
+	// Topics live in memory and keep no messages, so clients can talk through
+	// hermit without a broker. Needs the write role.
+	Publish(context.Context, *PublishRequest) (*PublishResponse, error)
+	// Subscribe streams the messages published to a topic from now on until
+	// the client cancels. A subscriber that falls behind misses messages
+	// rather than being cut off; the next message says how many.
+	Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[TopicMessage]) error
+	// Limits lists the rate limits: each class's default and the clients
+	// that have their own. Needs the admin role.
+	//
+	// Writes and benchmarks are limited per client by token buckets. A
+	// limited call's response carries x-hermit-quota-class, -limit (the
+	// burst), -rate (calls a second) and -remaining metadata; a refused call
+	// gets RESOURCE_EXHAUSTED with the same and x-hermit-retry-after-ms.
+	Limits(context.Context, *LimitsRequest) (*LimitsResponse, error)
+	// SetLimit changes a class's default limit, or one client's, taking
+	// effect on the next call. Needs the admin role.
+	SetLimit(context.Context, *SetLimitRequest) (*LimitsResponse, error)
+	// Replicate is how a follower (hermit --follow) keeps up with this
+	// server: a snapshot of both stores, then every document store change
+	// after it, numbered by this server's oplog position, with heartbeats
+	// while idle. A follower that falls behind is cut off and starts over.
+	// Needs the admin role; followers refuse it.
+	Replicate(*ReplicateRequest, grpc.ServerStreamingServer[OplogEntry]) error
+	// ReplicationStatus says whether this server is a primary or a
+	// follower: a primary lists its followers, a follower how far behind
+	// its primary it is.
+	ReplicationStatus(context.Context, *ReplicationStatusRequest) (*ReplicationStatusResponse, error)
+	// CreateBucket makes a document store bucket: a keyspace of its own.
+	// Key calls (KvSet, KvGet, KvList, KvDelete, KvExists, KvTTL, Txn and
+	// Watch) use the bucket named by x-hermit-bucket metadata, or "default"
+	// without it. Needs the admin role.
+	CreateBucket(context.Context, *CreateBucketRequest) (*CreateBucketResponse, error)
+	// ListBuckets returns the buckets the caller may use, with their sizes
+	// and the caller's access to each.
+	ListBuckets(context.Context, *ListBucketsRequest) (*ListBucketsResponse, error)
+	// Diagnostics returns the slowest recent calls (those over --slow-ms)
+	// and the largest values in the document store, for tracking down
+	// latency. Needs the admin role: it names keys in every bucket.
+	Diagnostics(context.Context, *DiagnosticsRequest) (*DiagnosticsResponse, error)
+	// RestoreFromUrl replaces both stores with a snapshot hermit fetches
+	// itself: an s3://bucket/key URL, signed with the credentials its
+	// backups use, or an http(s):// one such as a presigned URL, unless its
+	// host is link-local. Needs the admin role; a snapshot that doesn't
+	// decode changes nothing.
+	RestoreFromUrl(context.Context, *RestoreFromUrlRequest) (*DbRestoreResponse, error)
 	mustEmbedUnimplementedHermitServer()
 }
 
@@ -210,6 +687,9 @@ func (UnimplementedHermitServer) Ping(context.Context, *PingRequest) (*PingRespo
 func (UnimplementedHermitServer) Benchmark(context.Context, *BenchmarkRequest) (*BenchmarkResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method Benchmark not implemented")
 }
+func (UnimplementedHermitServer) BenchmarkStream(*BenchmarkRequest, grpc.ServerStreamingServer[BenchmarkProgress]) error {
+	return status.Error(codes.Unimplemented, "method BenchmarkStream not implemented")
+}
 func (UnimplementedHermitServer) Login(context.Context, *LoginRequest) (*LoginResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method Login not implemented")
 }
@@ -225,15 +705,78 @@ func (UnimplementedHermitServer) KvGet(context.Context, *KvGetRequest) (*KvGetRe
 func (UnimplementedHermitServer) KvList(context.Context, *KvListRequest) (*KvListResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method KvList not implemented")
 }
+func (UnimplementedHermitServer) KvDelete(context.Context, *KvDeleteRequest) (*KvDeleteResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method KvDelete not implemented")
+}
+func (UnimplementedHermitServer) KvExists(context.Context, *KvExistsRequest) (*KvExistsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method KvExists not implemented")
+}
+func (UnimplementedHermitServer) KvTTL(context.Context, *KvTTLRequest) (*KvTTLResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method KvTTL not implemented")
+}
+func (UnimplementedHermitServer) Txn(context.Context, *TxnRequest) (*TxnResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Txn not implemented")
+}
 func (UnimplementedHermitServer) SqlInsert(context.Context, *SqlInsertRequest) (*SqlInsertResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method SqlInsert not implemented")
 }
 func (UnimplementedHermitServer) SqlQuery(context.Context, *SqlQueryRequest) (*SqlQueryResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method SqlQuery not implemented")
 }
+func (UnimplementedHermitServer) SqlDelete(context.Context, *SqlDeleteRequest) (*SqlDeleteResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SqlDelete not implemented")
+}
+func (UnimplementedHermitServer) SqlUpdate(context.Context, *SqlUpdateRequest) (*SqlUpdateResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SqlUpdate not implemented")
+}
 func (UnimplementedHermitServer) DbStats(context.Context, *DbStatsRequest) (*DbStatsResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method DbStats not implemented")
 }
+func (UnimplementedHermitServer) TailLogs(*TailLogsRequest, grpc.ServerStreamingServer[LogLine]) error {
+	return status.Error(codes.Unimplemented, "method TailLogs not implemented")
+}
+func (UnimplementedHermitServer) Watch(*WatchRequest, grpc.ServerStreamingServer[WatchEvent]) error {
+	return status.Error(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedHermitServer) DbSnapshot(*DbSnapshotRequest, grpc.ServerStreamingServer[SnapshotChunk]) error {
+	return status.Error(codes.Unimplemented, "method DbSnapshot not implemented")
+}
+func (UnimplementedHermitServer) DbRestore(grpc.ClientStreamingServer[SnapshotChunk, DbRestoreResponse]) error {
+	return status.Error(codes.Unimplemented, "method DbRestore not implemented")
+}
+func (UnimplementedHermitServer) Metrics(context.Context, *MetricsRequest) (*MetricsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Metrics not implemented")
+}
+func (UnimplementedHermitServer) Publish(context.Context, *PublishRequest) (*PublishResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Publish not implemented")
+}
+func (UnimplementedHermitServer) Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[TopicMessage]) error {
+	return status.Error(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedHermitServer) Limits(context.Context, *LimitsRequest) (*LimitsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Limits not implemented")
+}
+func (UnimplementedHermitServer) SetLimit(context.Context, *SetLimitRequest) (*LimitsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetLimit not implemented")
+}
+func (UnimplementedHermitServer) Replicate(*ReplicateRequest, grpc.ServerStreamingServer[OplogEntry]) error {
+	return status.Error(codes.Unimplemented, "method Replicate not implemented")
+}
+func (UnimplementedHermitServer) ReplicationStatus(context.Context, *ReplicationStatusRequest) (*ReplicationStatusResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReplicationStatus not implemented")
+}
+func (UnimplementedHermitServer) CreateBucket(context.Context, *CreateBucketRequest) (*CreateBucketResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateBucket not implemented")
+}
+func (UnimplementedHermitServer) ListBuckets(context.Context, *ListBucketsRequest) (*ListBucketsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListBuckets not implemented")
+}
+func (UnimplementedHermitServer) Diagnostics(context.Context, *DiagnosticsRequest) (*DiagnosticsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Diagnostics not implemented")
+}
+func (UnimplementedHermitServer) RestoreFromUrl(context.Context, *RestoreFromUrlRequest) (*DbRestoreResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RestoreFromUrl not implemented")
+}
 func (UnimplementedHermitServer) mustEmbedUnimplementedHermitServer() {}
 func (UnimplementedHermitServer) testEmbeddedByValue()                {}
 
@@ -291,6 +834,17 @@ func _Hermit_Benchmark_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Hermit_BenchmarkStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(BenchmarkRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(HermitServer).BenchmarkStream(m, &grpc.GenericServerStream[BenchmarkRequest, BenchmarkProgress]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Hermit_BenchmarkStreamServer = grpc.ServerStreamingServer[BenchmarkProgress]
+
 func _Hermit_Login_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(LoginRequest)
 	if err := dec(in); err != nil {
@@ -381,6 +935,78 @@ func _Hermit_KvList_Handler(srv interface{}, ctx context.Context, dec func(inter
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Hermit_KvDelete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KvDeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HermitServer).KvDelete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Hermit_KvDelete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HermitServer).KvDelete(ctx, req.(*KvDeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Hermit_KvExists_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KvExistsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HermitServer).KvExists(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Hermit_KvExists_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HermitServer).KvExists(ctx, req.(*KvExistsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Hermit_KvTTL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KvTTLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HermitServer).KvTTL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Hermit_KvTTL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HermitServer).KvTTL(ctx, req.(*KvTTLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Hermit_Txn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HermitServer).Txn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Hermit_Txn_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HermitServer).Txn(ctx, req.(*TxnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Hermit_SqlInsert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(SqlInsertRequest)
 	if err := dec(in); err != nil {
@@ -417,6 +1043,42 @@ func _Hermit_SqlQuery_Handler(srv interface{}, ctx context.Context, dec func(int
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Hermit_SqlDelete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SqlDeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HermitServer).SqlDelete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Hermit_SqlDelete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HermitServer).SqlDelete(ctx, req.(*SqlDeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Hermit_SqlUpdate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SqlUpdateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HermitServer).SqlUpdate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Hermit_SqlUpdate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HermitServer).SqlUpdate(ctx, req.(*SqlUpdateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Hermit_DbStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(DbStatsRequest)
 	if err := dec(in); err != nil {
@@ -435,6 +1097,230 @@ func _Hermit_DbStats_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Hermit_TailLogs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TailLogsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(HermitServer).TailLogs(m, &grpc.GenericServerStream[TailLogsRequest, LogLine]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Hermit_TailLogsServer = grpc.ServerStreamingServer[LogLine]
+
+func _Hermit_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(HermitServer).Watch(m, &grpc.GenericServerStream[WatchRequest, WatchEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Hermit_WatchServer = grpc.ServerStreamingServer[WatchEvent]
+
+func _Hermit_DbSnapshot_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DbSnapshotRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(HermitServer).DbSnapshot(m, &grpc.GenericServerStream[DbSnapshotRequest, SnapshotChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Hermit_DbSnapshotServer = grpc.ServerStreamingServer[SnapshotChunk]
+
+func _Hermit_DbRestore_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(HermitServer).DbRestore(&grpc.GenericServerStream[SnapshotChunk, DbRestoreResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Hermit_DbRestoreServer = grpc.ClientStreamingServer[SnapshotChunk, DbRestoreResponse]
+
+func _Hermit_Metrics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MetricsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HermitServer).Metrics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Hermit_Metrics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HermitServer).Metrics(ctx, req.(*MetricsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Hermit_Publish_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PublishRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HermitServer).Publish(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Hermit_Publish_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HermitServer).Publish(ctx, req.(*PublishRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Hermit_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(HermitServer).Subscribe(m, &grpc.GenericServerStream[SubscribeRequest, TopicMessage]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Hermit_SubscribeServer = grpc.ServerStreamingServer[TopicMessage]
+
+func _Hermit_Limits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LimitsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HermitServer).Limits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Hermit_Limits_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HermitServer).Limits(ctx, req.(*LimitsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Hermit_SetLimit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLimitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HermitServer).SetLimit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Hermit_SetLimit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HermitServer).SetLimit(ctx, req.(*SetLimitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Hermit_Replicate_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ReplicateRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(HermitServer).Replicate(m, &grpc.GenericServerStream[ReplicateRequest, OplogEntry]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Hermit_ReplicateServer = grpc.ServerStreamingServer[OplogEntry]
+
+func _Hermit_ReplicationStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplicationStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HermitServer).ReplicationStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Hermit_ReplicationStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HermitServer).ReplicationStatus(ctx, req.(*ReplicationStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Hermit_CreateBucket_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateBucketRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HermitServer).CreateBucket(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Hermit_CreateBucket_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HermitServer).CreateBucket(ctx, req.(*CreateBucketRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Hermit_ListBuckets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBucketsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HermitServer).ListBuckets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Hermit_ListBuckets_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HermitServer).ListBuckets(ctx, req.(*ListBucketsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Hermit_Diagnostics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DiagnosticsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HermitServer).Diagnostics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Hermit_Diagnostics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HermitServer).Diagnostics(ctx, req.(*DiagnosticsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Hermit_RestoreFromUrl_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreFromUrlRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HermitServer).RestoreFromUrl(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Hermit_RestoreFromUrl_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HermitServer).RestoreFromUrl(ctx, req.(*RestoreFromUrlRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Hermit_ServiceDesc is the grpc.ServiceDesc for Hermit service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -470,6 +1356,22 @@ var Hermit_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "KvList",
 			Handler:    _Hermit_KvList_Handler,
 		},
+		{
+			MethodName: "KvDelete",
+			Handler:    _Hermit_KvDelete_Handler,
+		},
+		{
+			MethodName: "KvExists",
+			Handler:    _Hermit_KvExists_Handler,
+		},
+		{
+			MethodName: "KvTTL",
+			Handler:    _Hermit_KvTTL_Handler,
+		},
+		{
+			MethodName: "Txn",
+			Handler:    _Hermit_Txn_Handler,
+		},
 		{
 			MethodName: "SqlInsert",
 			Handler:    _Hermit_SqlInsert_Handler,
@@ -478,11 +1380,91 @@ var Hermit_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SqlQuery",
 			Handler:    _Hermit_SqlQuery_Handler,
 		},
+		{
+			MethodName: "SqlDelete",
+			Handler:    _Hermit_SqlDelete_Handler,
+		},
+		{
+			MethodName: "SqlUpdate",
+			Handler:    _Hermit_SqlUpdate_Handler,
+		},
 		{
 			MethodName: "DbStats",
 			Handler:    _Hermit_DbStats_Handler,
 		},
+		{
+			MethodName: "Metrics",
+			Handler:    _Hermit_Metrics_Handler,
+		},
+		{
+			MethodName: "Publish",
+			Handler:    _Hermit_Publish_Handler,
+		},
+		{
+			MethodName: "Limits",
+			Handler:    _Hermit_Limits_Handler,
+		},
+		{
+			MethodName: "SetLimit",
+			Handler:    _Hermit_SetLimit_Handler,
+		},
+		{
+			MethodName: "ReplicationStatus",
+			Handler:    _Hermit_ReplicationStatus_Handler,
+		},
+		{
+			MethodName: "CreateBucket",
+			Handler:    _Hermit_CreateBucket_Handler,
+		},
+		{
+			MethodName: "ListBuckets",
+			Handler:    _Hermit_ListBuckets_Handler,
+		},
+		{
+			MethodName: "Diagnostics",
+			Handler:    _Hermit_Diagnostics_Handler,
+		},
+		{
+			MethodName: "RestoreFromUrl",
+			Handler:    _Hermit_RestoreFromUrl_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "BenchmarkStream",
+			Handler:       _Hermit_BenchmarkStream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "TailLogs",
+			Handler:       _Hermit_TailLogs_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Watch",
+			Handler:       _Hermit_Watch_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "DbSnapshot",
+			Handler:       _Hermit_DbSnapshot_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "DbRestore",
+			Handler:       _Hermit_DbRestore_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Subscribe",
+			Handler:       _Hermit_Subscribe_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Replicate",
+			Handler:       _Hermit_Replicate_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "hermit.proto",
 }
@@ -0,0 +1,168 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/colorprofile"
+	"github.com/charmbracelet/ssh"
+	gossh "golang.org/x/crypto/ssh"
+
+	"github.com/jredh-dev/nexus/internal/logging"
+)
+
+// serveSSH hosts the TUI over SSH on cfg.SSHAddr. Every session gets its
+// own Model and hermit connection, so players don't share state. Sessions
+// run with the operator's hermit and portal credentials, so only clients
+// holding a key in cfg.AuthKeys get in. Sessions without a PTY are
+// refused.
+func serveSSH(cfg config) error {
+	if err := logging.Setup("tui"); err != nil {
+		return err
+	}
+	if err := ensureHostKey(cfg.HostKey); err != nil {
+		return err
+	}
+	srv, err := newSSHServer(cfg, func(sess ssh.Session) { runSession(cfg, sess) })
+	if err != nil {
+		return err
+	}
+	slog.Info("serving over ssh", "addr", cfg.SSHAddr, "authorized_keys", cfg.AuthKeys)
+	return srv.ListenAndServe()
+}
+
+// newSSHServer returns an SSH server on cfg.SSHAddr running handler for
+// clients that authenticate with a key in cfg.AuthKeys. It refuses to
+// build one without any keys: with no auth handler set, the server would
+// let anyone in.
+func newSSHServer(cfg config, handler ssh.Handler) (*ssh.Server, error) {
+	keys, err := loadAuthorizedKeys(cfg.AuthKeys)
+	if err != nil {
+		return nil, err
+	}
+	srv := &ssh.Server{
+		Addr:    cfg.SSHAddr,
+		Handler: handler,
+		PublicKeyHandler: func(_ ssh.Context, key ssh.PublicKey) bool {
+			return slices.ContainsFunc(keys, func(k ssh.PublicKey) bool { return ssh.KeysEqual(k, key) })
+		},
+	}
+	if err := srv.SetOption(ssh.HostKeyFile(cfg.HostKey)); err != nil {
+		return nil, fmt.Errorf("host key: %w", err)
+	}
+	return srv, nil
+}
+
+// loadAuthorizedKeys reads the public keys in an OpenSSH authorized_keys
+// file. It is an error for there to be none.
+func loadAuthorizedKeys(path string) ([]ssh.PublicKey, error) {
+	if path == "" {
+		return nil, fmt.Errorf("authorized keys: no path (set --authorized-keys)")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("authorized keys: %w", err)
+	}
+	var keys []ssh.PublicKey
+	for len(bytes.TrimSpace(data)) > 0 {
+		key, _, _, rest, err := gossh.ParseAuthorizedKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("authorized keys %s: %w", path, err)
+		}
+		keys = append(keys, key)
+		data = rest
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("authorized keys %s: no keys", path)
+	}
+	return keys, nil
+}
+
+// runSession runs one player's TUI until they quit or disconnect.
+func runSession(cfg config, sess ssh.Session) {
+	pty, winCh, ok := sess.Pty()
+	if !ok {
+		fmt.Fprintln(sess, "tui: a terminal is required (ssh -t)")
+		_ = sess.Exit(1)
+		return
+	}
+	remote := sess.RemoteAddr()
+	log := slog.With("remote", remote.String(), "user", sess.User())
+
+	// The theme file and exports would land on this host, not the
+	// player's, so both are off.
+	cfg.ThemeFile = ""
+	m, err := newModel(cfg)
+	if err != nil {
+		log.Error("session", "err", err)
+		fmt.Fprintf(sess, "tui: %v\n", err)
+		_ = sess.Exit(1)
+		return
+	}
+	m = m.WithExportDir("")
+
+	env := append(sess.Environ(), "TERM="+pty.Term)
+	p := tea.NewProgram(m,
+		tea.WithContext(sess.Context()),
+		tea.WithInput(sess),
+		tea.WithOutput(sess),
+		tea.WithEnvironment(env),
+		tea.WithColorProfile(colorprofile.Env(env)),
+		tea.WithWindowSize(pty.Window.Width, pty.Window.Height),
+		tea.WithoutSignalHandler(),
+	)
+	go func() {
+		for w := range winCh {
+			p.Send(tea.WindowSizeMsg{Width: w.Width, Height: w.Height})
+		}
+	}()
+
+	log.Info("session started")
+	if _, err := p.Run(); err != nil && !errors.Is(err, tea.ErrProgramKilled) {
+		log.Error("session", "err", err)
+	}
+	log.Info("session ended")
+	_ = sess.Exit(0)
+}
+
+// ensureHostKey creates an ed25519 host key at path if there isn't one, so
+// clients see the same host key across restarts.
+func ensureHostKey(path string) error {
+	if path == "" {
+		return fmt.Errorf("host key: no path (set --host-key)")
+	}
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("host key: %w", err)
+	}
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return fmt.Errorf("host key: %w", err)
+	}
+	block, err := gossh.MarshalPrivateKey(priv, "nexus-tui")
+	if err != nil {
+		return fmt.Errorf("host key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("host key: %w", err)
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+		return fmt.Errorf("host key: %w", err)
+	}
+	slog.Info("generated host key", "path", path)
+	return nil
+}
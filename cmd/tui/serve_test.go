@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/ssh"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+func TestEnsureHostKey_CreatesOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nexus-tui", "ssh_host_ed25519_key")
+	if err := ensureHostKey(path); err != nil {
+		t.Fatal(err)
+	}
+	first, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gossh.ParsePrivateKey(first); err != nil {
+		t.Fatalf("generated key doesn't parse: %v", err)
+	}
+
+	if err := ensureHostKey(path); err != nil {
+		t.Fatal(err)
+	}
+	second, _ := os.ReadFile(path)
+	if !bytes.Equal(first, second) {
+		t.Error("existing host key was replaced")
+	}
+}
+
+func newSigner(t *testing.T) gossh.Signer {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := gossh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
+func TestSSHServer_RequiresAuthorizedKey(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config{
+		HostKey:  filepath.Join(dir, "ssh_host_ed25519_key"),
+		AuthKeys: filepath.Join(dir, "authorized_keys"),
+	}
+	if err := ensureHostKey(cfg.HostKey); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newSSHServer(cfg, nil); err == nil {
+		t.Fatal("server built without an authorized_keys file")
+	}
+
+	known, unknown := newSigner(t), newSigner(t)
+	if err := os.WriteFile(cfg.AuthKeys, gossh.MarshalAuthorizedKey(known.PublicKey()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	srv, err := newSSHServer(cfg, func(sess ssh.Session) { _ = sess.Exit(0) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(l)
+	t.Cleanup(func() { srv.Close() })
+
+	dial := func(auth ...gossh.AuthMethod) error {
+		c, err := gossh.Dial("tcp", l.Addr().String(), &gossh.ClientConfig{
+			User:            "player",
+			Auth:            auth,
+			HostKeyCallback: gossh.InsecureIgnoreHostKey(),
+		})
+		if err == nil {
+			c.Close()
+		}
+		return err
+	}
+	if err := dial(gossh.PublicKeys(unknown)); err == nil {
+		t.Error("unknown key was let in")
+	}
+	if err := dial(); err == nil {
+		t.Error("client without a key was let in")
+	}
+	if err := dial(gossh.PublicKeys(known)); err != nil {
+		t.Errorf("authorized key refused: %v", err)
+	}
+}
@@ -0,0 +1,197 @@
+// Package assets serves a service's static files, such as CSS, scripts
+// and images, from a file system embedded in its binary.
+//
+// Each file is also reachable under a name carrying a hash of its content,
+// like "css/site.3f0c8a52e1b4.css". Pages link to that name, via Path, so
+// a changed file gets a new URL and the old one can be cached for good.
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// Prefix is the URL path Assets are served under.
+const Prefix = "/static/"
+
+// hashLen is how many hex digits of a file's SHA-256 go in its name.
+const hashLen = 12
+
+// Assets serves the files in a file system under Prefix.
+type Assets struct {
+	fsys   fs.FS
+	hashes map[string]string // name → content hash; nil when live
+}
+
+// New returns Assets serving the files in fsys, hashing them all up front.
+// fsys must not change afterwards; embed.FS never does.
+func New(fsys fs.FS) (*Assets, error) {
+	hashes := make(map[string]string)
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		sum, err := hashFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		hashes[name] = sum
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("hash assets: %w", err)
+	}
+	return &Assets{fsys: fsys, hashes: hashes}, nil
+}
+
+// Dir returns Assets serving the files under dir on disk, hashing each
+// whenever it is asked for, so edits show up on the next page load. It is
+// meant for development; responses are never cached.
+func Dir(dir string) *Assets {
+	return &Assets{fsys: os.DirFS(dir)}
+}
+
+// live reports whether a is reading files as they change.
+func (a *Assets) live() bool { return a.hashes == nil }
+
+// hash returns the content hash of the file name, or "" if there is none.
+func (a *Assets) hash(name string) string {
+	if !a.live() {
+		return a.hashes[name]
+	}
+	sum, err := hashFile(a.fsys, name)
+	if err != nil {
+		return ""
+	}
+	return sum
+}
+
+// Path returns the URL of the file name, such as "css/site.css", with its
+// content hash in it: "/static/css/site.3f0c8a52e1b4.css". Names of files
+// that don't exist get the plain URL, which will 404.
+func (a *Assets) Path(name string) string {
+	sum := a.hash(name)
+	if sum == "" {
+		return Prefix + name
+	}
+	return Prefix + hashedName(name, sum)
+}
+
+// Manifest returns the URL Path gives for each file, by name.
+func (a *Assets) Manifest() map[string]string {
+	m := make(map[string]string)
+	_ = fs.WalkDir(a.fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			m[name] = a.Path(name)
+		}
+		return nil
+	})
+	return m
+}
+
+// ServeHTTP serves the file named by the request path under Prefix, with
+// or without its content hash. Responses under the current hash may be
+// cached for a year; anything else must be revalidated, so a stale hash
+// never pins new content.
+func (a *Assets) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name, sum := splitHash(strings.TrimPrefix(r.URL.Path, Prefix))
+	if !fs.ValidPath(name) || name == "." {
+		http.NotFound(w, r)
+		return
+	}
+	f, err := a.fsys.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if sum != "" && !a.live() && sum == a.hash(name) {
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	var modified time.Time
+	if a.live() {
+		modified = info.ModTime()
+	}
+	http.ServeContent(w, r, path.Base(name), modified, content)
+}
+
+// ServeManifest writes Manifest as JSON, for clients that render their
+// own pages and link to the files.
+func (a *Assets) ServeManifest(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	_ = json.NewEncoder(w).Encode(a.Manifest())
+}
+
+// hashFile returns the leading hex digits of the SHA-256 of the file name.
+func hashFile(fsys fs.FS, name string) (string, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:hashLen], nil
+}
+
+// hashedName puts sum into name before its extension.
+func hashedName(name, sum string) string {
+	ext := path.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + sum + ext
+}
+
+// splitHash undoes hashedName, returning name unchanged and no hash if it
+// doesn't have one.
+func splitHash(name string) (plain, sum string) {
+	ext := path.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	if ext != "" && isHash(ext[1:]) {
+		// A file without an extension: "LICENSE.3f0c8a52e1b4".
+		return stem, ext[1:]
+	}
+	i := strings.LastIndexByte(stem, '.')
+	if i < 0 || strings.ContainsRune(stem[i:], '/') || !isHash(stem[i+1:]) {
+		return name, ""
+	}
+	return stem[:i] + ext, stem[i+1:]
+}
+
+// isHash reports whether s looks like a content hash from hashFile.
+func isHash(s string) bool {
+	if len(s) != hashLen {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,93 @@
+package assets
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func get(t *testing.T, a *Assets, url string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	a.ServeHTTP(rec, httptest.NewRequest("GET", url, nil))
+	return rec
+}
+
+func TestEmbedded(t *testing.T) {
+	a, err := New(fstest.MapFS{
+		"css/site.css": {Data: []byte("body{}")},
+		"LICENSE":      {Data: []byte("MIT")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	url := a.Path("css/site.css")
+	if !strings.HasPrefix(url, "/static/css/site.") || !strings.HasSuffix(url, ".css") || url == "/static/css/site.css" {
+		t.Fatalf("Path = %q", url)
+	}
+	if got := a.Path("css/missing.css"); got != "/static/css/missing.css" {
+		t.Errorf("Path(missing) = %q", got)
+	}
+
+	for _, c := range []struct {
+		url, body, cache string
+	}{
+		{url, "body{}", "public, max-age=31536000, immutable"},
+		{"/static/css/site.css", "body{}", "no-cache"},
+		{"/static/css/site.000000000000.css", "body{}", "no-cache"}, // stale hash
+		{a.Path("LICENSE"), "MIT", "public, max-age=31536000, immutable"},
+	} {
+		rec := get(t, a, c.url)
+		if rec.Code != http.StatusOK || rec.Body.String() != c.body {
+			t.Errorf("%s: %d %q", c.url, rec.Code, rec.Body)
+		}
+		if got := rec.Header().Get("Cache-Control"); got != c.cache {
+			t.Errorf("%s: Cache-Control %q, want %q", c.url, got, c.cache)
+		}
+	}
+	if got := get(t, a, url).Header().Get("Content-Type"); !strings.HasPrefix(got, "text/css") {
+		t.Errorf("Content-Type %q", got)
+	}
+
+	for _, url := range []string{"/static/css/missing.css", "/static/css", "/static/", "/static/../assets.go"} {
+		if rec := get(t, a, url); rec.Code != http.StatusNotFound {
+			t.Errorf("%s: %d, want 404", url, rec.Code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	a.ServeManifest(rec, httptest.NewRequest("GET", "/api/assets", nil))
+	var m map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &m); err != nil || m["css/site.css"] != url {
+		t.Errorf("manifest %s", rec.Body)
+	}
+}
+
+func TestDir(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "site.css")
+	if err := os.WriteFile(file, []byte("a{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	a := Dir(dir)
+
+	before := a.Path("site.css")
+	if err := os.WriteFile(file, []byte("b{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	after := a.Path("site.css")
+	if before == after {
+		t.Errorf("Path unchanged after edit: %q", after)
+	}
+
+	rec := get(t, a, after)
+	if rec.Body.String() != "b{}" || rec.Header().Get("Cache-Control") != "no-cache" {
+		t.Errorf("%s: %q, Cache-Control %q", after, rec.Body, rec.Header().Get("Cache-Control"))
+	}
+}
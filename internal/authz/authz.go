@@ -0,0 +1,93 @@
+// Package authz decides what a nexus user may do from their role.
+//
+// Each role is granted a set of permissions, and code asks about the
+// permission it needs rather than the role, so a new role takes no code
+// changes:
+//
+//	p := authz.New(map[string][]authz.Permission{
+//		"user":  nil,
+//		"admin": authz.All,
+//	})
+//	if p.Can(user.Role, authz.UsersManage) { ... }
+//	r.With(p.Require(authz.UsersManage, roleOf)).Get("/admin/users", h.Users)
+package authz
+
+import (
+	"net/http"
+	"slices"
+	"sort"
+)
+
+// Permission is something a role may be allowed to do, named
+// "<area>.<verb>".
+type Permission string
+
+// Permissions.
+const (
+	GiveawayManage Permission = "giveaway.manage" // manage giveaway items and claims
+	UsersManage    Permission = "users.manage"    // see accounts and assign roles
+	MagicLinkIssue Permission = "magiclink.issue" // issue magic login links for others
+	AnalyticsView  Permission = "analytics.view"  // see the analytics dashboard
+)
+
+// All is every permission, for roles that may do anything.
+var All = []Permission{GiveawayManage, UsersManage, MagicLinkIssue, AnalyticsView}
+
+// Policy maps roles to the permissions they are granted. It is not
+// changed after New, so it is safe for concurrent use.
+type Policy struct {
+	roles map[string][]Permission
+}
+
+// New returns a Policy granting each role in roles its permissions.
+// Roles not in it may do nothing.
+func New(roles map[string][]Permission) *Policy {
+	p := &Policy{roles: make(map[string][]Permission, len(roles))}
+	for role, perms := range roles {
+		p.roles[role] = slices.Clone(perms)
+	}
+	return p
+}
+
+// Can reports whether role is granted perm.
+func (p *Policy) Can(role string, perm Permission) bool {
+	return slices.Contains(p.roles[role], perm)
+}
+
+// Valid reports whether role is one of the policy's roles.
+func (p *Policy) Valid(role string) bool {
+	_, ok := p.roles[role]
+	return ok
+}
+
+// Roles returns the policy's roles, sorted.
+func (p *Policy) Roles() []string {
+	roles := make([]string, 0, len(p.roles))
+	for role := range p.roles {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// Permissions returns what role is granted, in the order given to New.
+func (p *Policy) Permissions(role string) []Permission {
+	return slices.Clone(p.roles[role])
+}
+
+// Require returns middleware that serves only requests whose caller's
+// role, as found by roleOf, is granted perm, and answers 403 Forbidden to
+// the rest. roleOf reports false when there is no caller, so it must run
+// after whatever authenticates the request.
+func (p *Policy) Require(perm Permission, roleOf func(*http.Request) (string, bool)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role, ok := roleOf(r)
+			if !ok || !p.Can(role, perm) {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package authz
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestPolicy(t *testing.T) {
+	p := New(map[string][]Permission{
+		"user":    nil,
+		"support": {UsersManage, MagicLinkIssue},
+		"admin":   All,
+	})
+
+	for _, c := range []struct {
+		role string
+		perm Permission
+		want bool
+	}{
+		{"admin", GiveawayManage, true},
+		{"support", MagicLinkIssue, true},
+		{"support", GiveawayManage, false},
+		{"user", UsersManage, false},
+		{"", UsersManage, false},
+		{"root", UsersManage, false},
+	} {
+		if got := p.Can(c.role, c.perm); got != c.want {
+			t.Errorf("Can(%q, %s) = %v", c.role, c.perm, got)
+		}
+	}
+
+	if !p.Valid("user") || p.Valid("root") {
+		t.Error("Valid")
+	}
+	if got := p.Roles(); !slices.Equal(got, []string{"admin", "support", "user"}) {
+		t.Errorf("Roles = %v", got)
+	}
+	if got := p.Permissions("support"); !slices.Equal(got, []Permission{UsersManage, MagicLinkIssue}) {
+		t.Errorf("Permissions = %v", got)
+	}
+}
+
+func TestRequire(t *testing.T) {
+	p := New(map[string][]Permission{"admin": All, "user": nil})
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	h := p.Require(UsersManage, func(r *http.Request) (string, bool) {
+		role := r.Header.Get("Role")
+		return role, role != ""
+	})(ok)
+
+	for role, want := range map[string]int{"admin": http.StatusOK, "user": http.StatusForbidden, "": http.StatusForbidden} {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/admin/users", nil)
+		req.Header.Set("Role", role)
+		h.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("role %q: %d, want %d", role, rec.Code, want)
+		}
+	}
+}
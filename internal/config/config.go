@@ -0,0 +1,61 @@
+// Package config loads the nascent-nexus server's configuration from the
+// environment.
+package config
+
+import (
+	"github.com/jredh-dev/nexus/internal/conversation"
+	"github.com/jredh-dev/nexus/internal/settings"
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
+)
+
+// Config holds all configuration for the nascent-nexus server.
+type Config struct {
+	Port      string
+	StaticDir string // the website: index.html and images/, css/ and js/
+	Responder string // "rules", or "llm" to answer what the rules don't with LLM
+	LLM       LLMConfig
+	Kafka     KafkaConfig
+
+	Settings settings.Values // everything above as loaded, for logging at boot
+}
+
+// LLMConfig holds the chat model used with NEXUS_RESPONDER=llm.
+type LLMConfig struct {
+	URL          string // OpenAI-compatible chat completions URL
+	APIKey       string // sent as a bearer token if set
+	Model        string
+	SystemPrompt string
+}
+
+// KafkaConfig holds settings for publishing replies to the sms-outbox
+// pipeline. Without Brokers, replies are sent inline in the webhook's
+// response instead.
+type KafkaConfig struct {
+	Brokers     []string
+	OutboxTopic string
+}
+
+// Load reads configuration from environment variables, and from the YAML
+// file NEXUS_CONFIG_FILE names if set, with sensible defaults. PORT (Cloud
+// Run standard) is used if NEXUS_PORT isn't set.
+func Load() (*Config, error) {
+	l := settings.New("NEXUS_CONFIG_FILE")
+	cfg := &Config{
+		Port:      l.String("NEXUS_PORT", l.String("PORT", "8080")),
+		StaticDir: l.String("NEXUS_STATIC_DIR", "./static"),
+		Responder: l.OneOf("NEXUS_RESPONDER", "rules", "rules", "llm"),
+		LLM: LLMConfig{
+			URL:          l.String("NEXUS_LLM_URL", ""),
+			APIKey:       l.Secret("NEXUS_LLM_API_KEY", ""),
+			Model:        l.String("NEXUS_LLM_MODEL", "llama3.2"),
+			SystemPrompt: l.String("NEXUS_LLM_SYSTEM_PROMPT", conversation.DefaultSystemPrompt),
+		},
+		Kafka: KafkaConfig{
+			Brokers:     l.List("NEXUS_KAFKA_BROKERS"),
+			OutboxTopic: l.String("NEXUS_OUTBOX_TOPIC", smsoutbox.Topic),
+		},
+	}
+	l.Check(cfg.Responder != "llm" || cfg.LLM.URL != "", "NEXUS_LLM_URL is required with NEXUS_RESPONDER=llm")
+	cfg.Settings = l.Values()
+	return cfg, l.Err()
+}
@@ -0,0 +1,111 @@
+// nexus - Personal AI assistant system
+// Copyright (C) 2025  nexus contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+// Package conversation answers the texts nascent-nexus receives. A text
+// is parsed into a Message by the webhook, routed through a Chain of
+// Responders until one answers, and the answer goes back out through
+// sms-outbox, or inline in the webhook's response when there is no outbox.
+package conversation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
+)
+
+// Source is the Source of the replies the pipeline publishes.
+const Source = "nascent-nexus"
+
+// Message is one inbound text.
+type Message struct {
+	ID         string // the provider's ID for the text, e.g. Twilio's MessageSid
+	From       string // the sender, in E.164 format
+	To         string // the number it was sent to
+	Body       string
+	ReceivedAt time.Time
+}
+
+// ErrPass is returned by a Responder that has nothing to say to a message,
+// so that the next one in the Chain answers it.
+var ErrPass = errors.New("conversation: no reply")
+
+// Responder answers a message.
+// Implementations must be safe for concurrent use.
+type Responder interface {
+	// Respond returns the reply to msg, or ErrPass to leave it to another
+	// responder.
+	Respond(ctx context.Context, msg Message) (string, error)
+}
+
+// Chain routes a message to its responders in order; the first that
+// doesn't pass answers it. An empty Chain, or one where every responder
+// passes, passes too.
+type Chain []Responder
+
+// Respond implements Responder.
+func (c Chain) Respond(ctx context.Context, msg Message) (string, error) {
+	for _, r := range c {
+		reply, err := r.Respond(ctx, msg)
+		if errors.Is(err, ErrPass) {
+			continue
+		}
+		return reply, err
+	}
+	return "", ErrPass
+}
+
+// Pipeline answers messages with a Responder and sends the answers.
+type Pipeline struct {
+	responder Responder
+	outbox    smsoutbox.Publisher
+}
+
+// New creates a Pipeline answering with responder and publishing replies
+// to outbox. With a nil outbox, replies are returned to the caller to send
+// instead.
+func New(responder Responder, outbox smsoutbox.Publisher) *Pipeline {
+	return &Pipeline{responder: responder, outbox: outbox}
+}
+
+// Handle answers msg. It returns the reply if the caller is to send it,
+// or "" if it was published or there is none.
+func (p *Pipeline) Handle(ctx context.Context, msg Message) (string, error) {
+	reply, err := p.responder.Respond(ctx, msg)
+	if errors.Is(err, ErrPass) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("respond to %s: %w", msg.ID, err)
+	}
+	reply = strings.TrimSpace(reply)
+	if reply == "" || p.outbox == nil {
+		return reply, nil
+	}
+	out := smsoutbox.OutboundMessage{
+		// Derived from the inbound text, so a redelivered webhook isn't
+		// answered twice.
+		ID:        "reply-" + msg.ID,
+		To:        msg.From,
+		Body:      reply,
+		Source:    Source,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := p.outbox.Publish(ctx, out); err != nil {
+		return "", fmt.Errorf("reply to %s: %w", msg.ID, err)
+	}
+	return "", nil
+}
@@ -0,0 +1,138 @@
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
+)
+
+type fakeOutbox struct {
+	msgs []smsoutbox.OutboundMessage
+	err  error
+}
+
+func (o *fakeOutbox) Publish(_ context.Context, msg smsoutbox.OutboundMessage) error {
+	if o.err != nil {
+		return o.err
+	}
+	o.msgs = append(o.msgs, msg)
+	return nil
+}
+
+type replyFunc func(Message) (string, error)
+
+func (f replyFunc) Respond(_ context.Context, msg Message) (string, error) { return f(msg) }
+
+func TestRules(t *testing.T) {
+	r := &Rules{
+		Rules: []Rule{
+			{Pattern: regexp.MustCompile(`(?i)^ping$`), Reply: "pong"},
+			{Pattern: regexp.MustCompile(`^remind me (?P<what>.+)$`), Reply: "ok, ${what}"},
+		},
+	}
+	for body, want := range map[string]string{
+		" PING ":                "pong",
+		"remind me to call mom": "ok, to call mom",
+	} {
+		got, err := r.Respond(context.Background(), Message{Body: body})
+		if err != nil || got != want {
+			t.Errorf("Respond(%q) = %q, %v; want %q", body, got, err, want)
+		}
+	}
+	if _, err := r.Respond(context.Background(), Message{Body: "what?"}); !errors.Is(err, ErrPass) {
+		t.Errorf("unmatched: err = %v, want ErrPass", err)
+	}
+	r.Default = "world"
+	if got, _ := r.Respond(context.Background(), Message{Body: "what?"}); got != "world" {
+		t.Errorf("unmatched with default = %q, want world", got)
+	}
+}
+
+func TestChain(t *testing.T) {
+	pass := replyFunc(func(Message) (string, error) { return "", ErrPass })
+	answer := replyFunc(func(Message) (string, error) { return "second", nil })
+	fail := replyFunc(func(Message) (string, error) { return "", errors.New("down") })
+
+	if got, err := (Chain{pass, answer, fail}).Respond(context.Background(), Message{}); err != nil || got != "second" {
+		t.Errorf("got %q, %v; want second", got, err)
+	}
+	if _, err := (Chain{pass, fail, answer}).Respond(context.Background(), Message{}); err == nil || errors.Is(err, ErrPass) {
+		t.Errorf("err = %v, want the failing responder's", err)
+	}
+	if _, err := (Chain{pass}).Respond(context.Background(), Message{}); !errors.Is(err, ErrPass) {
+		t.Errorf("err = %v, want ErrPass", err)
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	msg := Message{ID: "SM1", From: "+15555550100", Body: "ping"}
+	rules := &Rules{Rules: []Rule{{Pattern: regexp.MustCompile(`^ping$`), Reply: "pong"}}}
+
+	t.Run("outbox", func(t *testing.T) {
+		outbox := &fakeOutbox{}
+		inline, err := New(rules, outbox).Handle(context.Background(), msg)
+		if err != nil || inline != "" {
+			t.Fatalf("Handle = %q, %v; want it published", inline, err)
+		}
+		if len(outbox.msgs) != 1 {
+			t.Fatalf("published %d, want 1", len(outbox.msgs))
+		}
+		out := outbox.msgs[0]
+		if out.ID != "reply-SM1" || out.To != msg.From || out.Body != "pong" || out.Source != Source {
+			t.Errorf("published %+v", out)
+		}
+		if err := out.Validate(); err != nil {
+			t.Error(err)
+		}
+	})
+	t.Run("inline", func(t *testing.T) {
+		inline, err := New(rules, nil).Handle(context.Background(), msg)
+		if err != nil || inline != "pong" {
+			t.Fatalf("Handle = %q, %v; want pong", inline, err)
+		}
+	})
+	t.Run("no reply", func(t *testing.T) {
+		outbox := &fakeOutbox{}
+		inline, err := New(rules, outbox).Handle(context.Background(), Message{ID: "SM2", From: msg.From, Body: "other"})
+		if err != nil || inline != "" || len(outbox.msgs) != 0 {
+			t.Fatalf("Handle = %q, %v, published %d; want nothing", inline, err, len(outbox.msgs))
+		}
+	})
+	t.Run("publish fails", func(t *testing.T) {
+		outbox := &fakeOutbox{err: errors.New("no brokers")}
+		if _, err := New(rules, outbox).Handle(context.Background(), msg); err == nil {
+			t.Fatal("Handle succeeded, want the publish error")
+		}
+	})
+}
+
+func TestLLM(t *testing.T) {
+	var got chatRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer key" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"Hi there."}}]}`))
+	}))
+	defer srv.Close()
+
+	reply, err := NewLLM(srv.URL, "key", "small", "be brief").Respond(context.Background(), Message{Body: "hello"})
+	if err != nil || reply != "Hi there." {
+		t.Fatalf("Respond = %q, %v", reply, err)
+	}
+	if got.Model != "small" || len(got.Messages) != 2 || got.Messages[0].Role != "system" || got.Messages[1].Content != "hello" {
+		t.Errorf("request = %+v", got)
+	}
+
+	if _, err := NewLLM(srv.URL, "wrong", "small", "").Respond(context.Background(), Message{Body: "hello"}); err == nil {
+		t.Error("Respond succeeded with a refused key")
+	}
+}
@@ -0,0 +1,105 @@
+// nexus - Personal AI assistant system
+// Copyright (C) 2025  nexus contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+package conversation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// DefaultSystemPrompt tells the model how to answer texts.
+const DefaultSystemPrompt = "You are nascent-nexus, a personal assistant people reach by SMS. " +
+	"Answer in plain text, briefly: a reply should fit in one or two text messages."
+
+// LLM is a Responder backed by a chat model behind an OpenAI-compatible
+// chat completions endpoint, such as OpenAI's or a local Ollama's.
+type LLM struct {
+	url    string
+	apiKey string
+	model  string
+	system string
+	client *http.Client
+}
+
+// NewLLM creates an LLM responder posting to url, the full chat
+// completions URL (e.g. http://localhost:11434/v1/chat/completions), as
+// model with the system prompt system. apiKey is sent as a bearer token
+// if set.
+func NewLLM(url, apiKey, model, system string) *LLM {
+	return &LLM{
+		url: url, apiKey: apiKey, model: model, system: system,
+		// Twilio gives up on a webhook after 15 seconds.
+		client: &http.Client{Timeout: 12 * time.Second},
+	}
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// Respond implements Responder.
+func (l *LLM) Respond(ctx context.Context, msg Message) (string, error) {
+	var messages []chatMessage
+	if l.system != "" {
+		messages = append(messages, chatMessage{Role: "system", Content: l.system})
+	}
+	messages = append(messages, chatMessage{Role: "user", Content: msg.Body})
+	body, err := json.Marshal(chatRequest{Model: l.model, Messages: messages})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, l.url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if l.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+l.apiKey)
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("llm: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("llm %d: %s", resp.StatusCode, b)
+	}
+	var out chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("llm: decode response: %w", err)
+	}
+	if len(out.Choices) == 0 || out.Choices[0].Message.Content == "" {
+		return "", fmt.Errorf("llm: empty response")
+	}
+	return out.Choices[0].Message.Content, nil
+}
@@ -0,0 +1,61 @@
+// nexus - Personal AI assistant system
+// Copyright (C) 2025  nexus contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+
+package conversation
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+// Rule answers the messages whose trimmed body matches Pattern with
+// Reply. Reply may refer to Pattern's submatches as $1, ${name} and so on.
+type Rule struct {
+	Pattern *regexp.Regexp
+	Reply   string
+}
+
+// Rules is the rules-based Responder: the first matching rule answers,
+// and Default answers anything none match. With no Default, unmatched
+// messages pass to the next responder.
+type Rules struct {
+	Rules   []Rule
+	Default string
+}
+
+// DefaultRules are the replies nascent-nexus gives without an LLM.
+func DefaultRules() *Rules {
+	return &Rules{
+		Rules: []Rule{
+			{Pattern: regexp.MustCompile(`(?i)^(help|\?)$`), Reply: "nascent-nexus: text anything and I'll answer. Try PING."},
+			{Pattern: regexp.MustCompile(`(?i)^ping$`), Reply: "pong"},
+			{Pattern: regexp.MustCompile(`(?i)^hello\b`), Reply: "world"},
+		},
+		Default: "world",
+	}
+}
+
+// Respond implements Responder.
+func (r *Rules) Respond(_ context.Context, msg Message) (string, error) {
+	body := strings.TrimSpace(msg.Body)
+	for _, rule := range r.Rules {
+		if m := rule.Pattern.FindStringSubmatchIndex(body); m != nil {
+			return string(rule.Pattern.ExpandString(nil, rule.Reply, body, m)), nil
+		}
+	}
+	if r.Default == "" {
+		return "", ErrPass
+	}
+	return r.Default, nil
+}
@@ -0,0 +1,93 @@
+// Package emailoutbox publishes outbound email to the email-outbox Kafka
+// topic.
+//
+// It is smsoutbox for email: producers publish an OutboundEmail and the
+// sms-sender dispatcher delivers it over SMTP with the same retrying and
+// dead-lettering as texts, so a service picks the channel per message by
+// picking the publisher. Messages are keyed by recipient so every email to
+// one address lands on the same partition and is delivered in order.
+package emailoutbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Topic is the default Kafka topic for outbound email.
+const Topic = "email-outbox"
+
+// OutboundEmail is the JSON payload carried on the email-outbox topic.
+type OutboundEmail struct {
+	// ID identifies the email end to end. Producers should derive it from
+	// the triggering record so a republished email keeps the same ID.
+	ID string `json:"id"`
+	// To is the recipient's address.
+	To string `json:"to"`
+	// Subject is the subject line.
+	Subject string `json:"subject"`
+	// Body is the plain-text body.
+	Body string `json:"body"`
+	// Source names the producing service (e.g. "cal").
+	Source string `json:"source"`
+	// CreatedAt is when the producer created the email.
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Validate checks that the email has the fields the sender requires.
+func (m OutboundEmail) Validate() error {
+	switch {
+	case m.ID == "":
+		return fmt.Errorf("outbound email: id is required")
+	case m.To == "":
+		return fmt.Errorf("outbound email: to is required")
+	case m.Subject == "" && m.Body == "":
+		return fmt.Errorf("outbound email: subject or body is required")
+	}
+	return nil
+}
+
+// Publisher hands outbound email to the notification pipeline.
+// Implementations must be safe for concurrent use.
+type Publisher interface {
+	Publish(ctx context.Context, msg OutboundEmail) error
+}
+
+// KafkaPublisher publishes to a Kafka topic.
+type KafkaPublisher struct {
+	w *kafka.Writer
+}
+
+// NewKafkaPublisher creates a publisher writing to topic on the given brokers.
+func NewKafkaPublisher(brokers []string, topic string) *KafkaPublisher {
+	return &KafkaPublisher{w: &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		BatchTimeout: 10 * time.Millisecond,
+	}}
+}
+
+// Publish writes msg synchronously, returning once the brokers acknowledge it.
+func (p *KafkaPublisher) Publish(ctx context.Context, msg OutboundEmail) error {
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+	value, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("encode outbound email: %w", err)
+	}
+	if err := p.w.WriteMessages(ctx, kafka.Message{Key: []byte(msg.To), Value: value}); err != nil {
+		return fmt.Errorf("publish to %s: %w", p.w.Topic, err)
+	}
+	return nil
+}
+
+// Close flushes pending writes and closes the underlying writer.
+func (p *KafkaPublisher) Close() error {
+	return p.w.Close()
+}
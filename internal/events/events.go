@@ -0,0 +1,166 @@
+// Package events is the bus the nexus services announce domain events on,
+// so a service that cares what happened elsewhere subscribes instead of
+// each pair growing its own webhook.
+//
+// Topics are typed: each names what it carries, and Publish and Subscribe
+// encode and decode it.
+//
+//	bus := events.Open("secrets", cfg.EventBrokers)
+//	defer bus.Close()
+//	events.Publish(ctx, bus, events.SecretExposed, secret.ID, events.SecretExposure{...})
+//
+//	go events.Subscribe(ctx, bus, events.SecretExposed, "notify",
+//		func(ctx context.Context, e events.Event, x events.SecretExposure) error { ... })
+//
+// With Kafka brokers the bus is Kafka, one Kafka topic per topic here, and
+// delivery is at least once: handlers must cope with seeing an event
+// twice. Without brokers it is in memory, which is enough for development
+// and tests but reaches no other process.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jredh-dev/nexus/internal/portalhook"
+)
+
+// Topic is a topic name and the type of the data its events carry.
+type Topic[T any] struct {
+	Name string
+}
+
+// The topics the services share.
+var (
+	// Giveaway claims, published by the giveaway service; cal turns
+	// those with a delivery timeslot into events.
+	ClaimConfirmed = Topic[portalhook.Payload]{portalhook.TypeConfirmed}
+	ClaimUpdated   = Topic[portalhook.Payload]{portalhook.TypeUpdated}
+	ClaimCancelled = Topic[portalhook.Payload]{portalhook.TypeCancelled}
+
+	// SecretExposed is published by secrets when a secret stops being
+	// one.
+	SecretExposed = Topic[SecretExposure]{"secret.exposed"}
+
+	// EventCreated is published by cal when an event is added through
+	// its API.
+	EventCreated = Topic[CalendarEvent]{"event.created"}
+)
+
+// SecretExposure is a secret submitted for the second time: it has
+// become common knowledge.
+type SecretExposure struct {
+	SecretID    string `json:"secret_id"`
+	SubmittedBy string `json:"submitted_by"` // who first submitted it
+	Lens        string `json:"lens"`         // how the second submission matched it
+	Count       int    `json:"count"`
+}
+
+// CalendarEvent is a cal event as other services see it.
+type CalendarEvent struct {
+	ID      string     `json:"id"`
+	FeedID  string     `json:"feed_id"`
+	OwnerID string     `json:"owner_id,omitempty"`
+	Summary string     `json:"summary"`
+	Start   time.Time  `json:"start"`
+	End     *time.Time `json:"end,omitempty"`
+	AllDay  bool       `json:"all_day,omitempty"`
+}
+
+// Event is what travels on the bus: the data of one topic's event, and
+// where and when it happened.
+type Event struct {
+	ID         string          `json:"id"`
+	Topic      string          `json:"topic"`
+	Key        string          `json:"key,omitempty"` // events with the same key are delivered in order
+	Source     string          `json:"source"`        // the publishing service
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// Handler handles one event. An error has the event handed to it again,
+// up to Attempts times in all.
+type Handler func(ctx context.Context, e Event) error
+
+// Attempts is how many times a handler is given an event before it is
+// logged and dropped.
+const Attempts = 3
+
+// retryDelay is how long to wait before handing a failed event over again.
+var retryDelay = time.Second
+
+// Bus carries events between services. Implementations are safe for
+// concurrent use.
+type Bus interface {
+	// Publish sends e to the subscribers of e.Topic.
+	Publish(ctx context.Context, e Event) error
+	// Subscribe hands each event on topic to h until ctx is done, then
+	// returns nil. Of the subscriptions sharing a group, each event
+	// reaches one.
+	Subscribe(ctx context.Context, topic, group string, h Handler) error
+	Close() error
+}
+
+// Open returns a Kafka bus on brokers for the service source, or an
+// in-memory one if there are none.
+func Open(source string, brokers []string) Bus {
+	if len(brokers) == 0 {
+		slog.Info("event bus is in memory; events reach nothing outside this process", "hint", "configure Kafka brokers")
+		return NewMemory(source)
+	}
+	return NewKafka(source, brokers)
+}
+
+// Publish publishes data on t. Events with the same key are delivered in
+// order.
+func Publish[T any](ctx context.Context, b Bus, t Topic[T], key string, data T) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", t.Name, err)
+	}
+	return b.Publish(ctx, Event{
+		ID:         uuid.New().String(),
+		Topic:      t.Name,
+		Key:        key,
+		OccurredAt: time.Now().UTC(),
+		Data:       raw,
+	})
+}
+
+// Subscribe hands the events on t to h with their data decoded, until ctx
+// is done. Events whose data doesn't decode are logged and dropped.
+func Subscribe[T any](ctx context.Context, b Bus, t Topic[T], group string, h func(context.Context, Event, T) error) error {
+	return b.Subscribe(ctx, t.Name, group, func(ctx context.Context, e Event) error {
+		var data T
+		if err := json.Unmarshal(e.Data, &data); err != nil {
+			slog.Error("drop undecodable event", "topic", e.Topic, "event_id", e.ID, "err", err)
+			return nil
+		}
+		return h(ctx, e, data)
+	})
+}
+
+// deliver hands e to h until it succeeds, Attempts run out or ctx is
+// done. Once ctx is done the event counts as not delivered at all.
+func deliver(ctx context.Context, e Event, h Handler) {
+	for attempt := 1; ; attempt++ {
+		err := h(ctx, e)
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+		if attempt == Attempts {
+			slog.Error("drop event", "topic", e.Topic, "event_id", e.ID, "attempts", attempt, "err", err)
+			return
+		}
+		select {
+		case <-time.After(retryDelay * time.Duration(attempt)):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// waitSubscribed waits until m has n subscriptions to topic in group.
+func waitSubscribed(t *testing.T, m *Memory, topic, group string, n int) {
+	t.Helper()
+	for i := 0; i < 1000; i++ {
+		m.mu.Lock()
+		g := m.groups[topic][group]
+		ok := g != nil && len(g.subs) == n
+		m.mu.Unlock()
+		if ok {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("%s/%s: never had %d subscriptions", topic, group, n)
+}
+
+func TestTypedRoundTrip(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bus := NewMemory("secrets")
+
+	got := make(chan SecretExposure, 1)
+	var env Event
+	go Subscribe(ctx, bus, SecretExposed, "notify", func(_ context.Context, e Event, x SecretExposure) error { //nolint:errcheck
+		env = e
+		got <- x
+		return nil
+	})
+	waitSubscribed(t, bus, SecretExposed.Name, "notify", 1)
+
+	want := SecretExposure{SecretID: "sec_1", SubmittedBy: "ann", Lens: "exact", Count: 2}
+	if err := Publish(ctx, bus, SecretExposed, want.SecretID, want); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case x := <-got:
+		if x != want {
+			t.Errorf("got %+v, want %+v", x, want)
+		}
+		if env.Source != "secrets" || env.Topic != "secret.exposed" || env.Key != "sec_1" || env.ID == "" {
+			t.Errorf("envelope %+v", env)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("event not delivered")
+	}
+}
+
+func TestMemoryGroups(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := NewMemory("test")
+
+	var mu sync.Mutex
+	counts := map[string]int{}
+	var wg sync.WaitGroup
+	handler := func(name string) Handler {
+		return func(context.Context, Event) error {
+			mu.Lock()
+			counts[name]++
+			mu.Unlock()
+			wg.Done()
+			return nil
+		}
+	}
+	go m.Subscribe(ctx, "t", "a", handler("a1")) //nolint:errcheck
+	waitSubscribed(t, m, "t", "a", 1)
+	go m.Subscribe(ctx, "t", "a", handler("a2")) //nolint:errcheck
+	go m.Subscribe(ctx, "t", "b", handler("b"))  //nolint:errcheck
+	waitSubscribed(t, m, "t", "a", 2)
+	waitSubscribed(t, m, "t", "b", 1)
+
+	wg.Add(4 * 2) // each event reaches one of group a and all of group b
+	for i := 0; i < 4; i++ {
+		if err := m.Publish(ctx, Event{Topic: "t"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+	if counts["a1"] != 2 || counts["a2"] != 2 || counts["b"] != 4 {
+		t.Errorf("deliveries %v, want a1 2, a2 2, b 4", counts)
+	}
+
+	m.Close()
+	if err := m.Publish(ctx, Event{Topic: "t"}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Publish after Close: %v, want ErrClosed", err)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	retryDelay = time.Millisecond
+	defer func() { retryDelay = time.Second }()
+
+	var calls int
+	deliver(context.Background(), Event{}, func(context.Context, Event) error {
+		calls++
+		if calls < 2 {
+			return errors.New("not yet")
+		}
+		return nil
+	})
+	if calls != 2 {
+		t.Errorf("handler called %d times, want 2: succeeded on the retry", calls)
+	}
+
+	calls = 0
+	deliver(context.Background(), Event{}, func(context.Context, Event) error {
+		calls++
+		return errors.New("never")
+	})
+	if calls != Attempts {
+		t.Errorf("failing handler called %d times, want %d", calls, Attempts)
+	}
+}
@@ -0,0 +1,89 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Kafka is a Bus on Kafka, with one Kafka topic per topic.
+type Kafka struct {
+	source  string
+	brokers []string
+	w       *kafka.Writer
+}
+
+// NewKafka returns a bus on brokers for the service source.
+func NewKafka(source string, brokers []string) *Kafka {
+	return &Kafka{
+		source:  source,
+		brokers: brokers,
+		w: &kafka.Writer{
+			Addr:                   kafka.TCP(brokers...),
+			Balancer:               &kafka.Hash{},
+			RequiredAcks:           kafka.RequireAll,
+			BatchTimeout:           10 * time.Millisecond,
+			AllowAutoTopicCreation: true,
+		},
+	}
+}
+
+// Publish writes e synchronously, returning once the brokers acknowledge
+// it. It is keyed by e.Key, so events with the same key share a partition
+// and stay in order.
+func (k *Kafka) Publish(ctx context.Context, e Event) error {
+	if e.Source == "" {
+		e.Source = k.source
+	}
+	value, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encode event: %w", err)
+	}
+	msg := kafka.Message{Topic: e.Topic, Key: []byte(e.Key), Value: value}
+	if err := k.w.WriteMessages(ctx, msg); err != nil {
+		return fmt.Errorf("publish to %s: %w", e.Topic, err)
+	}
+	return nil
+}
+
+// Subscribe reads topic as consumer group group, committing each event
+// once h is done with it.
+func (k *Kafka) Subscribe(ctx context.Context, topic, group string, h Handler) error {
+	r := kafka.NewReader(kafka.ReaderConfig{Brokers: k.brokers, GroupID: group, Topic: topic})
+	defer r.Close()
+	for {
+		msg, err := r.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("read %s: %w", topic, err)
+		}
+		var e Event
+		if err := json.Unmarshal(msg.Value, &e); err != nil {
+			slog.Error("drop undecodable event", "topic", topic, "offset", msg.Offset, "err", err)
+		} else {
+			deliver(ctx, e, h)
+		}
+		if ctx.Err() != nil {
+			// Stopped mid-delivery: leave it uncommitted for next time.
+			return nil
+		}
+		if err := r.CommitMessages(ctx, msg); err != nil {
+			if errors.Is(err, context.Canceled) {
+				return nil
+			}
+			return fmt.Errorf("commit %s: %w", topic, err)
+		}
+	}
+}
+
+// Close flushes pending writes.
+func (k *Kafka) Close() error {
+	return k.w.Close()
+}
@@ -0,0 +1,118 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrClosed is returned by Publish on a closed bus.
+var ErrClosed = errors.New("event bus closed")
+
+// memoryBuffer is how many events a subscription may fall behind before
+// Publish waits for it.
+const memoryBuffer = 64
+
+// Memory is an in-process Bus.
+type Memory struct {
+	source string
+
+	mu     sync.Mutex
+	groups map[string]map[string]*memoryGroup // topic → group → subscriptions
+	closed bool
+}
+
+type memoryGroup struct {
+	subs []chan Event
+	next int // which subscription gets the next event
+}
+
+// NewMemory returns an in-process bus for the service source.
+func NewMemory(source string) *Memory {
+	return &Memory{source: source, groups: make(map[string]map[string]*memoryGroup)}
+}
+
+// Publish hands e to one subscription of each group subscribed to its
+// topic, waiting if one has fallen memoryBuffer events behind.
+func (m *Memory) Publish(ctx context.Context, e Event) error {
+	if e.Source == "" {
+		e.Source = m.source
+	}
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return ErrClosed
+	}
+	var targets []chan Event
+	for _, g := range m.groups[e.Topic] {
+		targets = append(targets, g.subs[g.next%len(g.subs)])
+		g.next++
+	}
+	m.mu.Unlock()
+
+	for _, ch := range targets {
+		select {
+		case ch <- e:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Subscribe implements Bus.
+func (m *Memory) Subscribe(ctx context.Context, topic, group string, h Handler) error {
+	ch := make(chan Event, memoryBuffer)
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return ErrClosed
+	}
+	if m.groups[topic] == nil {
+		m.groups[topic] = make(map[string]*memoryGroup)
+	}
+	g := m.groups[topic][group]
+	if g == nil {
+		g = &memoryGroup{}
+		m.groups[topic][group] = g
+	}
+	g.subs = append(g.subs, ch)
+	m.mu.Unlock()
+
+	defer m.unsubscribe(topic, group, ch)
+	for {
+		select {
+		case e := <-ch:
+			deliver(ctx, e, h)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (m *Memory) unsubscribe(topic, group string, ch chan Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	g := m.groups[topic][group]
+	if g == nil {
+		return
+	}
+	for i, c := range g.subs {
+		if c == ch {
+			g.subs = append(g.subs[:i], g.subs[i+1:]...)
+			break
+		}
+	}
+	if len(g.subs) == 0 {
+		delete(m.groups[topic], group)
+	}
+}
+
+// Close makes further publishing fail. Subscriptions end with their
+// contexts.
+func (m *Memory) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+	return nil
+}
@@ -0,0 +1,236 @@
+// Package flags turns risky features of the nexus services on and off
+// while they run.
+//
+// A service declares its flags and their defaults at startup, then asks a
+// flag each time it comes to the feature:
+//
+//	fl := flags.New("CAL_")
+//	caldav := fl.Bool("caldav", false, "Serve feeds over CalDAV")
+//	if err := fl.Load(); err != nil { ... }
+//	go fl.Watch(ctx, flags.WatchEvery)
+//	...
+//	if caldav.On() { ... }
+//
+// A flag is on or off as the environment says (CAL_FLAG_CALDAV=true), else
+// as the optional YAML file <prefix>FLAGS_FILE names says (caldav: true),
+// else as its default. Watch rereads the file when it changes, so a flag
+// it sets can be flipped without a redeploy; one the environment sets
+// stays put until restart. The service's flags, and where each came from,
+// are served at /debug/flags (see httpserver.WithFlags).
+package flags
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WatchEvery is how often services check their flags file for changes.
+const WatchEvery = 10 * time.Second
+
+// Where a flag's value came from.
+const (
+	FromEnv     = "env"
+	FromFile    = "file"
+	FromDefault = "default"
+)
+
+// Flag is one feature flag. It is safe for concurrent use.
+type Flag struct {
+	name string
+	help string
+	def  bool
+	env  string
+
+	state atomic.Pointer[state]
+}
+
+type state struct {
+	on     bool
+	source string
+}
+
+// On reports whether the flag is on.
+func (f *Flag) On() bool { return f.state.Load().on }
+
+// Set is a service's flags.
+type Set struct {
+	prefix string
+	path   string
+	flags  []*Flag
+	names  map[string]*Flag
+
+	mu       sync.Mutex // serializes loads
+	modTime  time.Time
+	loadedAt atomic.Pointer[time.Time]
+}
+
+// New returns an empty Set for a service whose settings start with
+// prefix.
+func New(prefix string) *Set {
+	return &Set{prefix: prefix, path: os.Getenv(prefix + "FLAGS_FILE"), names: make(map[string]*Flag)}
+}
+
+// Bool declares a flag, off or on by default, described by help. Flags are
+// declared before the first Load; until then they have their defaults.
+// Declaring one name twice panics.
+func (s *Set) Bool(name string, def bool, help string) *Flag {
+	if _, dup := s.names[name]; dup {
+		panic("flags: " + name + " declared twice")
+	}
+	f := &Flag{name: name, help: help, def: def, env: s.prefix + "FLAG_" + EnvName(name)}
+	f.state.Store(&state{on: def, source: FromDefault})
+	s.flags = append(s.flags, f)
+	s.names[name] = f
+	return f
+}
+
+// EnvName returns how a flag's name is written in its environment
+// variable, after the prefix: uppercase, with underscores for dots and
+// dashes.
+func EnvName(name string) string {
+	return strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(name))
+}
+
+// Load reads the flags from the environment and the file. A value that
+// isn't a boolean, or a name in the file that isn't a flag, is a problem:
+// Load reports every one, and leaves those flags as they were.
+func (s *Set) Load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	file := map[string]any{}
+	if s.path != "" {
+		info, err := os.Stat(s.path)
+		if err == nil {
+			s.modTime = info.ModTime()
+			err = readFile(s.path, file)
+		}
+		if err != nil {
+			return fmt.Errorf("flags file %s: %w", s.path, err)
+		}
+		for name := range file {
+			if s.names[name] == nil {
+				errs = append(errs, fmt.Errorf("%s: %s is not a flag", s.path, name))
+			}
+		}
+	}
+
+	for _, f := range s.flags {
+		next := &state{on: f.def, source: FromDefault}
+		raw, where := os.Getenv(f.env), f.env
+		if raw != "" {
+			next.source = FromEnv
+		} else if v, ok := file[f.name]; ok {
+			raw, where, next.source = fmt.Sprint(v), s.path+": "+f.name, FromFile
+		}
+		if next.source != FromDefault {
+			on, err := strconv.ParseBool(raw)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s=%q: want true or false", where, raw))
+				continue
+			}
+			next.on = on
+		}
+		if prev := f.state.Swap(next); prev.on != next.on && s.loadedAt.Load() != nil {
+			slog.Info("flag changed", "flag", f.name, "on", next.on, "source", next.source)
+		}
+	}
+	now := time.Now()
+	s.loadedAt.Store(&now)
+	return errors.Join(errs...)
+}
+
+// readFile reads a flat YAML map of flag names to booleans into file.
+func readFile(path string, file map[string]any) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(b, &file)
+}
+
+// Watch reloads the flags whenever the file changes, checking every
+// interval, until ctx is done. If a reload finds problems the flags it
+// could read change and the rest stay as they were.
+func (s *Set) Watch(ctx context.Context, every time.Duration) {
+	if s.path == "" {
+		return
+	}
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		info, err := os.Stat(s.path)
+		if err != nil {
+			slog.Error("check flags file", "path", s.path, "err", err)
+			continue
+		}
+		s.mu.Lock()
+		changed := !info.ModTime().Equal(s.modTime)
+		s.mu.Unlock()
+		if !changed {
+			continue
+		}
+		if err := s.Load(); err != nil {
+			slog.Error("reload flags", "err", err)
+		}
+	}
+}
+
+// State is a flag as it stands.
+type State struct {
+	Name    string `json:"name"`
+	On      bool   `json:"on"`
+	Default bool   `json:"default"`
+	Source  string `json:"source"` // FromEnv, FromFile or FromDefault
+	Help    string `json:"help"`
+}
+
+// States returns the flags as they stand, in the order declared.
+func (s *Set) States() []State {
+	out := make([]State, len(s.flags))
+	for i, f := range s.flags {
+		st := f.state.Load()
+		out[i] = State{Name: f.name, On: st.on, Default: f.def, Source: st.source, Help: f.help}
+	}
+	return out
+}
+
+// LogValue logs the flags as name=on pairs.
+func (s *Set) LogValue() slog.Value {
+	attrs := make([]slog.Attr, len(s.flags))
+	for i, f := range s.flags {
+		attrs[i] = slog.Bool(f.name, f.On())
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// ServeHTTP serves the flags as JSON: where they were loaded from, when,
+// and each flag as it stands.
+func (s *Set) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	body := struct {
+		File     string     `json:"file,omitempty"`
+		LoadedAt *time.Time `json:"loaded_at,omitempty"`
+		Flags    []State    `json:"flags"`
+	}{File: s.path, LoadedAt: s.loadedAt.Load(), Flags: s.States()}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(body) //nolint:errcheck
+}
@@ -0,0 +1,132 @@
+package flags
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, body string, mod time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// Some filesystems keep coarse modification times; make each write
+	// look new.
+	if err := os.Chtimes(path, mod, mod); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.yaml")
+	writeFile(t, path, "waitlist: true\nlens.homoglyph: false\n", time.Now())
+	t.Setenv("T_FLAGS_FILE", path)
+	t.Setenv("T_FLAG_LENS_HOMOGLYPH", "true")
+
+	s := New("T_")
+	waitlist := s.Bool("waitlist", false, "")
+	homoglyph := s.Bool("lens.homoglyph", true, "")
+	caldav := s.Bool("caldav", false, "")
+	if err := s.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if !waitlist.On() || !homoglyph.On() || caldav.On() {
+		t.Errorf("waitlist %v, lens.homoglyph %v, caldav %v; want on from the file, on from the environment, off by default",
+			waitlist.On(), homoglyph.On(), caldav.On())
+	}
+	sources := map[string]string{}
+	for _, st := range s.States() {
+		sources[st.Name] = st.Source
+	}
+	want := map[string]string{"waitlist": FromFile, "lens.homoglyph": FromEnv, "caldav": FromDefault}
+	for k, v := range want {
+		if sources[k] != v {
+			t.Errorf("%s came from %q, want %q", k, sources[k], v)
+		}
+	}
+}
+
+func TestLoadProblems(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.yaml")
+	writeFile(t, path, "watilist: true\ncaldav: maybe\n", time.Now())
+	t.Setenv("T_FLAGS_FILE", path)
+
+	s := New("T_")
+	caldav := s.Bool("caldav", true, "")
+	err := s.Load()
+	if err == nil {
+		t.Fatal("Load: nil error")
+	}
+	for _, want := range []string{"watilist is not a flag", `"maybe"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Load: %v; want it to mention %s", err, want)
+		}
+	}
+	if !caldav.On() {
+		t.Error("a bad value changed the flag")
+	}
+
+	t.Setenv("T_FLAGS_FILE", filepath.Join(t.TempDir(), "missing.yaml"))
+	if err := New("T_").Load(); err == nil {
+		t.Error("Load of a missing file: nil error")
+	}
+}
+
+func TestWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.yaml")
+	start := time.Now().Add(-time.Hour)
+	writeFile(t, path, "waitlist: false\n", start)
+	t.Setenv("FLAGS_FILE", path)
+
+	s := New("")
+	waitlist := s.Bool("waitlist", false, "")
+	if err := s.Load(); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Watch(ctx, 5*time.Millisecond)
+
+	writeFile(t, path, "waitlist: true\n", start.Add(time.Minute))
+	deadline := time.Now().Add(5 * time.Second)
+	for !waitlist.On() {
+		if time.Now().After(deadline) {
+			t.Fatal("flag not reloaded")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// A broken file leaves the flags as they were.
+	writeFile(t, path, "waitlist: [\n", start.Add(2*time.Minute))
+	time.Sleep(50 * time.Millisecond)
+	if !waitlist.On() {
+		t.Error("a broken file turned the flag off")
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	s := New("T_")
+	s.Bool("caldav", false, "Serve feeds over CalDAV")
+	if err := s.Load(); err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/flags", nil))
+	var body struct {
+		LoadedAt *time.Time `json:"loaded_at"`
+		Flags    []State    `json:"flags"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	want := State{Name: "caldav", Source: FromDefault, Help: "Serve feeds over CalDAV"}
+	if body.LoadedAt == nil || len(body.Flags) != 1 || body.Flags[0] != want {
+		t.Errorf("GET /debug/flags: %s", rec.Body)
+	}
+}
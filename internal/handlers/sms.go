@@ -14,32 +14,65 @@
 package handlers
 
 import (
-	"fmt"
+	"encoding/xml"
+	"log/slog"
 	"net/http"
-)
+	"strings"
+	"time"
 
-// SMSHandler handles incoming SMS messages from Twilio
-// For now, responds with "world" to any incoming message
-func SMSHandler(w http.ResponseWriter, r *http.Request) {
-	// Parse form data (Twilio sends webhook as POST form data)
-	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Failed to parse form", http.StatusBadRequest)
-		return
-	}
+	"github.com/jredh-dev/nexus/internal/conversation"
+)
 
-	// Get the incoming message body (optional for now, but good for logging)
-	from := r.FormValue("From")
-	body := r.FormValue("Body")
+// SMSHandler handles incoming SMS messages from Twilio. Each is answered by
+// p; a reply p leaves to the caller is sent back in the TwiML response,
+// and otherwise the response is empty.
+func SMSHandler(p *conversation.Pipeline) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Parse form data (Twilio sends webhook as POST form data)
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Failed to parse form", http.StatusBadRequest)
+			return
+		}
+		msg := conversation.Message{
+			ID:         r.FormValue("MessageSid"),
+			From:       r.FormValue("From"),
+			To:         r.FormValue("To"),
+			Body:       r.FormValue("Body"),
+			ReceivedAt: time.Now().UTC(),
+		}
+		if msg.From == "" {
+			http.Error(w, "From is required", http.StatusBadRequest)
+			return
+		}
+		if msg.ID == "" {
+			// Local testing with curl; Twilio always sends one.
+			msg.ID = "local-" + msg.ReceivedAt.Format("20060102T150405.000000000")
+		}
+		slog.Info("sms received", "from", msg.From, "id", msg.ID, "body", msg.Body)
 
-	// Log incoming message (for debugging)
-	fmt.Printf("SMS received from %s: %s\n", from, body)
+		reply, err := p.Handle(r.Context(), msg)
+		if err != nil {
+			slog.Error("answer sms", "id", msg.ID, "err", err)
+			http.Error(w, "Failed to answer message", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/xml")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(twiML(reply)))
+	}
+}
 
-	// Respond with TwiML (Twilio Markup Language)
-	// This tells Twilio to send "world" back to the sender
-	w.Header().Set("Content-Type", "text/xml")
-	w.WriteHeader(http.StatusOK)
-	fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+// twiML returns a TwiML (Twilio Markup Language) response sending reply
+// back to the sender, or sending nothing if reply is empty.
+func twiML(reply string) string {
+	if reply == "" {
+		return `<?xml version="1.0" encoding="UTF-8"?>
+<Response></Response>`
+	}
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(reply))
+	return `<?xml version="1.0" encoding="UTF-8"?>
 <Response>
-    <Message>world</Message>
-</Response>`)
+    <Message>` + b.String() + `</Message>
+</Response>`
 }
@@ -0,0 +1,25 @@
+//go:build linux || darwin
+
+package health
+
+import (
+	"context"
+	"fmt"
+	"syscall"
+)
+
+// DiskSpace checks that the filesystem holding path has at least minFree
+// bytes available, so a service notices before SQLite can't write.
+func DiskSpace(path string, minFree uint64) Func {
+	return func(context.Context) error {
+		var st syscall.Statfs_t
+		if err := syscall.Statfs(path, &st); err != nil {
+			return err
+		}
+		free := st.Bavail * uint64(st.Bsize)
+		if free < minFree {
+			return fmt.Errorf("%s: %d MiB free, want %d MiB", path, free>>20, minFree>>20)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+
+package health
+
+import "context"
+
+// DiskSpace always passes where free space can't be read.
+func DiskSpace(path string, minFree uint64) Func {
+	return func(context.Context) error { return nil }
+}
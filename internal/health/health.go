@@ -0,0 +1,199 @@
+// Package health is the liveness and readiness reporting the nexus
+// services share. A service registers checks of the things it depends on
+// and serves them at GET /healthz and GET /readyz (httpserver.WithHealth
+// does both):
+//
+//	h := health.New()
+//	h.Live("consumers", consumersRunning)   // failing: restart me
+//	h.Ready("database", db.Ping)            // failing: send me no traffic
+//	h.Ready("kafka", health.Kafka(brokers))
+//
+// Liveness checks say whether the process can still do its job at all;
+// an orchestrator restarts a service that fails them, so they should
+// only look at the process itself. Readiness checks say whether its
+// dependencies answer; a service failing them is taken out of rotation
+// until they do, and readiness includes liveness.
+//
+// Both endpoints answer 200 or 503 with JSON detail:
+//
+//	{"status":"fail","checks":{"database":{"status":"ok","took_ms":1},
+//	 "kafka":{"status":"fail","took_ms":2000,"error":"dial tcp ..."}}}
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Timeout bounds each check.
+const Timeout = 2 * time.Second
+
+// Func checks one dependency, returning nil if it is healthy.
+type Func func(ctx context.Context) error
+
+type check struct {
+	name string
+	fn   Func
+}
+
+// Registry holds a service's checks.
+type Registry struct {
+	mu    sync.Mutex
+	live  []check
+	ready []check
+}
+
+// New returns an empty Registry, which reports healthy.
+func New() *Registry {
+	return &Registry{}
+}
+
+// Live registers a liveness check.
+func (r *Registry) Live(name string, fn Func) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.live = append(r.live, check{name, fn})
+}
+
+// Ready registers a readiness check.
+func (r *Registry) Ready(name string, fn Func) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ready = append(r.ready, check{name, fn})
+}
+
+// Result is one check's outcome.
+type Result struct {
+	Status string `json:"status"` // "ok" or "fail"
+	TookMS int64  `json:"took_ms"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Report is what /healthz and /readyz answer with.
+type Report struct {
+	Status string            `json:"status"` // "ok" if every check passed, else "fail"
+	Checks map[string]Result `json:"checks"`
+}
+
+// OK reports whether every check passed.
+func (rep Report) OK() bool { return rep.Status == "ok" }
+
+// Liveness runs the liveness checks.
+func (r *Registry) Liveness(ctx context.Context) Report {
+	r.mu.Lock()
+	checks := append([]check(nil), r.live...)
+	r.mu.Unlock()
+	return run(ctx, checks)
+}
+
+// Readiness runs the liveness and readiness checks.
+func (r *Registry) Readiness(ctx context.Context) Report {
+	r.mu.Lock()
+	checks := append(append([]check(nil), r.live...), r.ready...)
+	r.mu.Unlock()
+	return run(ctx, checks)
+}
+
+// run runs checks concurrently, each bounded by Timeout.
+func run(ctx context.Context, checks []check) Report {
+	rep := Report{Status: "ok", Checks: make(map[string]Result, len(checks))}
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cctx, cancel := context.WithTimeout(ctx, Timeout)
+			defer cancel()
+			start := time.Now()
+			err := c.fn(cctx)
+			results[i] = Result{Status: "ok", TookMS: time.Since(start).Milliseconds()}
+			if err != nil {
+				results[i].Status, results[i].Error = "fail", err.Error()
+			}
+		}()
+	}
+	wg.Wait()
+	for i, c := range checks {
+		rep.Checks[c.name] = results[i]
+		if results[i].Status != "ok" {
+			rep.Status = "fail"
+		}
+	}
+	return rep
+}
+
+// LiveHandler serves the liveness report.
+func (r *Registry) LiveHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		write(w, r.Liveness(req.Context()))
+	}
+}
+
+// ReadyHandler serves the readiness report.
+func (r *Registry) ReadyHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		write(w, r.Readiness(req.Context()))
+	}
+}
+
+func write(w http.ResponseWriter, rep Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if !rep.OK() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(rep) //nolint:errcheck
+}
+
+// Dial checks that something accepts TCP connections at addr, such as
+// hermit's gRPC port.
+func Dial(addr string) Func {
+	return func(ctx context.Context) error {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+}
+
+// Kafka checks that at least one of brokers answers a metadata request.
+func Kafka(brokers []string) Func {
+	return func(ctx context.Context) error {
+		if len(brokers) == 0 {
+			return errors.New("no brokers configured")
+		}
+		var last error
+		for _, b := range brokers {
+			if last = kafkaBroker(ctx, b); last == nil {
+				return nil
+			}
+		}
+		return last
+	}
+}
+
+func kafkaBroker(ctx context.Context, addr string) error {
+	conn, err := kafka.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if d, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(d)
+	}
+	if _, err := conn.Brokers(); err != nil {
+		return fmt.Errorf("%s: %w", addr, err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLiveAndReady(t *testing.T) {
+	h := New()
+	h.Live("process", func(context.Context) error { return nil })
+	h.Ready("database", func(context.Context) error { return errors.New("locked") })
+
+	rec := httptest.NewRecorder()
+	h.LiveHandler()(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("/healthz: %d, want 200: a readiness failure isn't a liveness one", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ReadyHandler()(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("/readyz: %d, want 503", rec.Code)
+	}
+	var rep Report
+	if err := json.Unmarshal(rec.Body.Bytes(), &rep); err != nil {
+		t.Fatal(err)
+	}
+	if rep.Status != "fail" || rep.Checks["process"].Status != "ok" || rep.Checks["database"].Error != "locked" {
+		t.Errorf("/readyz report: %+v", rep)
+	}
+}
+
+func TestEmptyRegistry(t *testing.T) {
+	if rep := New().Readiness(context.Background()); !rep.OK() || len(rep.Checks) != 0 {
+		t.Errorf("empty registry: %+v, want ok", rep)
+	}
+}
+
+func TestDial(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	if err := Dial(addr)(context.Background()); err != nil {
+		t.Errorf("Dial while listening: %v", err)
+	}
+	ln.Close()
+	if err := Dial(addr)(context.Background()); err == nil {
+		t.Error("Dial after close: nil, want an error")
+	}
+}
+
+func TestKafkaNoBrokers(t *testing.T) {
+	if err := Kafka(nil)(context.Background()); err == nil {
+		t.Error("Kafka(nil): nil, want an error")
+	}
+}
+
+func TestDiskSpace(t *testing.T) {
+	dir := t.TempDir()
+	if err := DiskSpace(dir, 1)(context.Background()); err != nil {
+		t.Errorf("1 byte free: %v", err)
+	}
+	if err := DiskSpace(dir, 1<<62)(context.Background()); err == nil {
+		t.Error("4 EiB free: nil, want an error")
+	}
+}
@@ -0,0 +1,165 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/jredh-dev/nexus/internal/flags"
+	"github.com/jredh-dev/nexus/internal/settings"
+)
+
+// MinDebugTokenLen is the shortest admin token the debug endpoints accept;
+// with a shorter one they stay off.
+const MinDebugTokenLen = 16
+
+// started is when the process started, near enough, for uptime.
+var started = time.Now()
+
+// debugToken returns the admin token the debug endpoints are served
+// behind: the one WithDebug gave, or else DEBUG_TOKEN from the
+// environment.
+func debugToken(o options) string {
+	token := o.debugToken
+	if !o.debugSet {
+		token = settings.New("").Secret("DEBUG_TOKEN", "")
+	}
+	if token != "" && len(token) < MinDebugTokenLen {
+		slog.Error("debug token is too short; /debug endpoints are off", "min_length", MinDebugTokenLen)
+		return ""
+	}
+	return token
+}
+
+// mountDebug serves pprof at /debug/pprof/, runtime stats at
+// /debug/runtime and fl, if any, at /debug/flags, behind token. CPU
+// profiles and traces run as long as they are asked to (30 seconds by
+// default): the request timeout doesn't apply under /debug, and they lift
+// the server's write timeout.
+func mountDebug(r chi.Router, token string, fl *flags.Set) {
+	r.Route("/debug", func(r chi.Router) {
+		r.Use(requireToken(token))
+		r.Get("/runtime", runtimeStats)
+		if fl != nil {
+			r.Method(http.MethodGet, "/flags", fl)
+		}
+		r.Get("/pprof/cmdline", pprof.Cmdline)
+		r.Get("/pprof/profile", untimed(pprof.Profile))
+		r.HandleFunc("/pprof/symbol", pprof.Symbol)
+		r.Get("/pprof/trace", untimed(pprof.Trace))
+		// Index also serves the named profiles: heap, goroutine, allocs, ...
+		r.Get("/pprof/*", pprof.Index)
+	})
+}
+
+// untimed lets h write for as long as it takes, whatever the server's
+// WriteTimeout. pprof reads that from the request's context to decide how
+// long it may profile for (before Go 1.23, refusing anything longer), so
+// h sees a server without one.
+func untimed(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+			http.Error(w, "can't lift the write timeout: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		h(w, r.WithContext(context.WithValue(r.Context(), http.ServerContextKey, &http.Server{})))
+	}
+}
+
+// requireToken admits requests carrying token as a bearer token or, for
+// browsers, as the basic auth password with any username.
+func requireToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok {
+				_, got, _ = r.BasicAuth()
+			}
+			if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Basic realm="debug"`)
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RuntimeStats is what GET /debug/runtime reports.
+type RuntimeStats struct {
+	GoVersion  string  `json:"go_version"`
+	Uptime     string  `json:"uptime"`
+	Goroutines int     `json:"goroutines"`
+	GOMAXPROCS int     `json:"gomaxprocs"`
+	NumCPU     int     `json:"num_cpu"`
+	Heap       Heap    `json:"heap"`
+	GC         GCStats `json:"gc"`
+}
+
+// Heap describes the heap, in bytes except for Objects.
+type Heap struct {
+	Alloc    uint64 `json:"alloc"`    // live objects
+	InUse    uint64 `json:"in_use"`   // spans with at least one object
+	Idle     uint64 `json:"idle"`     // spans with none, not yet returned
+	Released uint64 `json:"released"` // returned to the OS
+	Sys      uint64 `json:"sys"`      // obtained from the OS
+	Objects  uint64 `json:"objects"`
+}
+
+// GCStats describes garbage collection so far.
+type GCStats struct {
+	Cycles      uint32     `json:"cycles"`
+	NextAt      uint64     `json:"next_at"` // heap size the next cycle starts at
+	LastAt      *time.Time `json:"last_at,omitempty"`
+	LastPause   string     `json:"last_pause"`
+	TotalPause  string     `json:"total_pause"`
+	CPUFraction float64    `json:"cpu_fraction"` // of available CPU spent in GC since start
+}
+
+// ReadRuntimeStats reads the process's runtime stats. It briefly stops the
+// world.
+func ReadRuntimeStats() RuntimeStats {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	s := RuntimeStats{
+		GoVersion:  runtime.Version(),
+		Uptime:     time.Since(started).Round(time.Second).String(),
+		Goroutines: runtime.NumGoroutine(),
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+		NumCPU:     runtime.NumCPU(),
+		Heap: Heap{
+			Alloc:    m.HeapAlloc,
+			InUse:    m.HeapInuse,
+			Idle:     m.HeapIdle,
+			Released: m.HeapReleased,
+			Sys:      m.HeapSys,
+			Objects:  m.HeapObjects,
+		},
+		GC: GCStats{
+			Cycles:      m.NumGC,
+			NextAt:      m.NextGC,
+			LastPause:   time.Duration(m.PauseNs[(m.NumGC+255)%256]).String(),
+			TotalPause:  time.Duration(m.PauseTotalNs).String(),
+			CPUFraction: m.GCCPUFraction,
+		},
+	}
+	if m.LastGC != 0 {
+		last := time.Unix(0, int64(m.LastGC)).UTC()
+		s.GC.LastAt = &last
+	}
+	return s
+}
+
+func runtimeStats(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(ReadRuntimeStats()) //nolint:errcheck
+}
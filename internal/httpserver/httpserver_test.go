@@ -0,0 +1,391 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jredh-dev/nexus/internal/flags"
+	"github.com/jredh-dev/nexus/internal/metrics"
+	"github.com/jredh-dev/nexus/internal/ratelimit"
+)
+
+func TestRoutes(t *testing.T) {
+	var seen string
+	tag := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			seen = r.URL.Path
+			next.ServeHTTP(w, r)
+		})
+	}
+	srv := New(WithMiddleware(tag), WithVersion(Version{Service: "svc", Version: "1.2.3", Commit: "abc"}))
+	srv.Router.Get("/panic", func(http.ResponseWriter, *http.Request) { panic("boom") })
+
+	rec := httptest.NewRecorder()
+	srv.Router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "OK" {
+		t.Errorf("/health: %d %q", rec.Code, rec.Body.String())
+	}
+	if seen != "/health" {
+		t.Errorf("middleware saw %q, want /health", seen)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.Router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/version", nil))
+	var v Version
+	if err := json.Unmarshal(rec.Body.Bytes(), &v); err != nil || v.Service != "svc" || v.Version != "1.2.3" || v.Commit != "abc" {
+		t.Errorf("/version: %d %s (%v)", rec.Code, rec.Body.String(), err)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.Router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("/panic: %d, want 500", rec.Code)
+	}
+}
+
+func TestNoVersion(t *testing.T) {
+	rec := httptest.NewRecorder()
+	New().Router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/version", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("/version without WithVersion: %d, want 404", rec.Code)
+	}
+}
+
+func TestRealIP(t *testing.T) {
+	for _, tc := range []struct {
+		opts []Option
+		want string
+	}{
+		{nil, "203.0.113.9"},
+		{[]Option{WithoutRealIP()}, "192.0.2.1"},
+	} {
+		var remote string
+		srv := New(tc.opts...)
+		srv.Router.Get("/ip", func(_ http.ResponseWriter, r *http.Request) { remote = r.RemoteAddr })
+		req := httptest.NewRequest(http.MethodGet, "/ip", nil) // from 192.0.2.1:1234
+		req.Header.Set("X-Forwarded-For", "203.0.113.9")
+		srv.Router.ServeHTTP(httptest.NewRecorder(), req)
+		if host, _, _ := net.SplitHostPort(remote); host != tc.want && remote != tc.want {
+			t.Errorf("options %d: RemoteAddr %q, want %s", len(tc.opts), remote, tc.want)
+		}
+	}
+}
+
+func TestTrustedProxies(t *testing.T) {
+	proxies := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+	for _, tc := range []struct {
+		name    string
+		proxies []netip.Prefix
+		peer    string
+		xff     []string
+		want    string
+	}{
+		{"no proxies", nil, "203.0.113.9:1234", []string{"198.51.100.7"}, "203.0.113.9"},
+		{"untrusted peer", proxies, "203.0.113.9:1234", []string{"198.51.100.7"}, "203.0.113.9"},
+		{"one proxy", proxies, "10.0.0.1:1234", []string{"203.0.113.9"}, "203.0.113.9"},
+		{"spoofed hop", proxies, "10.0.0.1:1234", []string{"198.51.100.7, 203.0.113.9"}, "203.0.113.9"},
+		{"two proxies", proxies, "10.0.0.1:1234", []string{"198.51.100.7", "203.0.113.9, 10.0.0.2"}, "203.0.113.9"},
+		{"garbage hop", proxies, "10.0.0.1:1234", []string{"x, 203.0.113.9"}, "203.0.113.9"},
+		{"no header", proxies, "10.0.0.1:1234", nil, "10.0.0.1"},
+	} {
+		var remote string
+		srv := New(WithTrustedProxies(tc.proxies))
+		srv.Router.Get("/ip", func(_ http.ResponseWriter, r *http.Request) { remote = r.RemoteAddr })
+		req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+		req.RemoteAddr = tc.peer
+		for _, h := range tc.xff {
+			req.Header.Add("X-Forwarded-For", h)
+		}
+		req.Header.Set("X-Real-IP", "198.51.100.8")
+		srv.Router.ServeHTTP(httptest.NewRecorder(), req)
+		if host, _, _ := net.SplitHostPort(remote); host != tc.want {
+			t.Errorf("%s: RemoteAddr %q, want %s", tc.name, remote, tc.want)
+		}
+	}
+}
+
+func TestTrustedProxiesRateLimit(t *testing.T) {
+	lim := ratelimit.New(ratelimit.NewMemory(), ratelimit.Limits{"login": {Limit: 1, Burst: 1}}, nil)
+	for _, proxies := range [][]netip.Prefix{nil, {netip.MustParsePrefix("10.0.0.1/32")}} {
+		srv := New(WithTrustedProxies(proxies))
+		srv.Router.With(lim.Limit("login", ratelimit.ByIP)).Post("/login", func(http.ResponseWriter, *http.Request) {})
+		peer, client := "203.0.113.9:1234", ""
+		if proxies != nil {
+			peer, client = "10.0.0.1:1234", ", 203.0.113.9"
+		}
+
+		// Each request claims to come from somewhere new; they all share
+		// the one bucket.
+		for i, spoof := range []string{"198.51.100.1", "198.51.100.2", "198.51.100.3"} {
+			req := httptest.NewRequest(http.MethodPost, "/login", nil)
+			req.RemoteAddr = peer
+			req.Header.Set("X-Forwarded-For", spoof+client)
+			req.Header.Set("X-Real-IP", spoof)
+			req.Header.Set("True-Client-IP", spoof)
+			rec := httptest.NewRecorder()
+			srv.Router.ServeHTTP(rec, req)
+			if want := http.StatusTooManyRequests; i > 0 && rec.Code != want {
+				t.Errorf("%d proxies, request %d spoofing %s: %d, want %d", len(proxies), i+1, spoof, rec.Code, want)
+			}
+		}
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	guard := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+	srv := New(WithMetrics(metrics.New("svc"), guard))
+	srv.Router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	rec := httptest.NewRecorder()
+	srv.Router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("/metrics without credentials: %d, want 401", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer key")
+	rec = httptest.NewRecorder()
+	srv.Router.ServeHTTP(rec, req)
+	want := `svc_http_request_duration_seconds_count{method="GET",route="/health",status="200"} 1`
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), want) {
+		t.Errorf("/metrics: %d, missing %q", rec.Code, want)
+	}
+}
+
+func TestServeShutdown(t *testing.T) {
+	var hooks []string
+	srv := New(WithShutdownHook(func() { hooks = append(hooks, "option") }))
+	srv.OnShutdown(func() { hooks = append(hooks, "method") })
+
+	// A request in flight when shutdown starts still gets its answer.
+	started := make(chan struct{})
+	srv.Router.Get("/slow", func(w http.ResponseWriter, _ *http.Request) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte("done"))
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(ctx, ln) }()
+
+	got := make(chan int, 1)
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String() + "/slow")
+		if err != nil {
+			got <- 0
+			return
+		}
+		resp.Body.Close()
+		got <- resp.StatusCode
+	}()
+	<-started
+	cancel()
+
+	if err := <-served; err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+	if code := <-got; code != http.StatusOK {
+		t.Errorf("in-flight request: status %d, want 200", code)
+	}
+	if len(hooks) != 2 || hooks[0] != "option" || hooks[1] != "method" {
+		t.Errorf("hooks ran %v, want [option method]", hooks)
+	}
+}
+
+type closeFunc func() error
+
+func (f closeFunc) Close() error { return f() }
+
+func TestShutdownOrder(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	record := func(e string) {
+		mu.Lock()
+		events = append(events, e)
+		mu.Unlock()
+	}
+
+	// The handler hands the worker a job as it finishes, after shutdown
+	// has started; the worker must still be running to take it.
+	jobs := make(chan string, 1)
+	closeErr := errors.New("disk gone")
+	srv := New(
+		WithShutdownHook(func() { record("hook") }),
+		WithWorker("queue", func(ctx context.Context) {
+			<-ctx.Done()
+			record("worker stopped")
+			for {
+				select {
+				case job := <-jobs:
+					record("worker flushed " + job)
+				default:
+					return
+				}
+			}
+		}),
+		WithCloser("db", closeFunc(func() error { record("close db"); return nil })),
+		WithCloser("cache", closeFunc(func() error { record("close cache"); return closeErr })),
+	)
+	started := make(chan struct{})
+	srv.Router.Get("/slow", func(w http.ResponseWriter, _ *http.Request) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		jobs <- "job"
+		record("request done")
+		w.Write([]byte("done"))
+	})
+
+	// An unstarted httptest server is just a listener on a free port.
+	ln := httptest.NewUnstartedServer(nil).Listener
+	addr := ln.Addr().String()
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(ctx, ln) }()
+
+	got := make(chan int, 1)
+	go func() {
+		resp, err := http.Get("http://" + addr + "/slow")
+		if err != nil {
+			got <- 0
+			return
+		}
+		resp.Body.Close()
+		got <- resp.StatusCode
+	}()
+	<-started
+	cancel()
+
+	err := <-served
+	if !errors.Is(err, closeErr) || !strings.Contains(err.Error(), "close cache") {
+		t.Errorf("Serve: %v, want the cache's close error", err)
+	}
+	if code := <-got; code != http.StatusOK {
+		t.Errorf("in-flight request: status %d, want 200", code)
+	}
+	want := []string{"hook", "request done", "worker stopped", "worker flushed job", "close cache", "close db"}
+	if !slices.Equal(events, want) {
+		t.Errorf("shutdown went\n\t%v\nwant\n\t%v", events, want)
+	}
+	if _, err := net.DialTimeout("tcp", addr, time.Second); err == nil {
+		t.Error("still accepting connections after Serve returned")
+	}
+}
+
+func TestDebug(t *testing.T) {
+	const token = "0123456789abcdef"
+	get := func(srv *Server, path string, auth func(*http.Request)) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if auth != nil {
+			auth(req)
+		}
+		rec := httptest.NewRecorder()
+		srv.Router.ServeHTTP(rec, req)
+		return rec
+	}
+	bearer := func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+token) }
+	basic := func(r *http.Request) { r.SetBasicAuth("anyone", token) }
+
+	t.Setenv("DEBUG_TOKEN", "")
+	if rec := get(New(), "/debug/runtime", bearer); rec.Code != http.StatusNotFound {
+		t.Errorf("/debug/runtime without a token: %d, want 404", rec.Code)
+	}
+	if rec := get(New(WithDebug("short")), "/debug/runtime", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("/debug/runtime with a short token: %d, want 404", rec.Code)
+	}
+
+	t.Setenv("DEBUG_TOKEN", token)
+	srv := New()
+	if rec := get(srv, "/debug/runtime", nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("/debug/runtime unauthenticated: %d, want 401", rec.Code)
+	}
+	wrong := func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+token+"x") }
+	if rec := get(srv, "/debug/pprof/", wrong); rec.Code != http.StatusUnauthorized {
+		t.Errorf("/debug/pprof/ with the wrong token: %d, want 401", rec.Code)
+	}
+
+	rec := get(srv, "/debug/runtime", bearer)
+	var stats RuntimeStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("/debug/runtime: %d %s (%v)", rec.Code, rec.Body, err)
+	}
+	if stats.Goroutines == 0 || stats.Heap.Alloc == 0 || stats.GoVersion == "" {
+		t.Errorf("/debug/runtime: %+v", stats)
+	}
+
+	if rec := get(srv, "/debug/pprof/", basic); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "goroutine") {
+		t.Errorf("/debug/pprof/: %d", rec.Code)
+	}
+	if rec := get(srv, "/debug/pprof/goroutine?debug=1", bearer); rec.Code != http.StatusOK {
+		t.Errorf("/debug/pprof/goroutine: %d %s", rec.Code, rec.Body)
+	}
+
+	if rec := get(srv, "/debug/flags", bearer); rec.Code != http.StatusNotFound {
+		t.Errorf("/debug/flags without WithFlags: %d, want 404", rec.Code)
+	}
+	fl := flags.New("T_")
+	fl.Bool("caldav", false, "")
+	rec = get(New(WithFlags(fl)), "/debug/flags", bearer)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"caldav"`) {
+		t.Errorf("/debug/flags: %d %s", rec.Code, rec.Body)
+	}
+
+	// WithDebug wins over the environment.
+	if rec := get(New(WithDebug("")), "/debug/runtime", bearer); rec.Code != http.StatusNotFound {
+		t.Errorf("/debug/runtime with WithDebug(\"\"): %d, want 404", rec.Code)
+	}
+}
+
+func TestDebugProfileOutlastsTimeouts(t *testing.T) {
+	defer func(d time.Duration) { writeTimeout = d }(writeTimeout)
+	writeTimeout = time.Second
+
+	const token = "0123456789abcdef"
+	srv := New(WithDebug(token), WithTimeout(time.Second))
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go srv.Serve(ctx, ln) //nolint:errcheck
+
+	req, _ := http.NewRequest(http.MethodGet, "http://"+ln.Addr().String()+"/debug/pprof/profile?seconds=2", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil || resp.StatusCode != http.StatusOK || len(body) == 0 {
+		t.Errorf("2s profile with 1s timeouts: %d, %d bytes, %v: %s", resp.StatusCode, len(body), err, body)
+	}
+	if took := time.Since(start); took < 2*time.Second {
+		t.Errorf("2s profile ended after %v", took)
+	}
+}
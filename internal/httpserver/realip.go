@@ -0,0 +1,78 @@
+package httpserver
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// WithTrustedProxies takes r.RemoteAddr from X-Forwarded-For for requests
+// that come through proxies, the load balancers in front of the server.
+// Each proxy appends the address it was reached from, so the header is
+// read from the right and the first address that isn't one of proxies is
+// the client: anything further left is whatever the client chose to send.
+// Other headers, such as X-Real-IP, are ignored.
+//
+// Without proxies, r.RemoteAddr is left as the connecting peer's, as with
+// WithoutRealIP; a service passes its trusted proxies setting through
+// unconditionally.
+func WithTrustedProxies(proxies []netip.Prefix) Option {
+	return func(o *options) {
+		o.noRealIP = true
+		if len(proxies) > 0 {
+			o.trusted = proxies
+		}
+	}
+}
+
+// realIP is the middleware WithTrustedProxies sets up.
+func realIP(proxies []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ip, ok := clientIP(r, proxies); ok {
+				r.RemoteAddr = net.JoinHostPort(ip.String(), "0")
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientIP returns the right-most address in r's X-Forwarded-For that
+// isn't one of proxies, if r came from one. If every hop is a proxy, the
+// left-most is the client.
+func clientIP(r *http.Request, proxies []netip.Prefix) (netip.Addr, bool) {
+	peer, err := netip.ParseAddrPort(r.RemoteAddr)
+	if err != nil || !trusted(peer.Addr(), proxies) {
+		return netip.Addr{}, false
+	}
+	var hops []string
+	for _, h := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(h, ",")...)
+	}
+	var client netip.Addr
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			// A hop that isn't an address was made up before it reached
+			// the proxies; stop at what they vouched for.
+			break
+		}
+		client = ip.Unmap()
+		if !trusted(client, proxies) {
+			break
+		}
+	}
+	return client, client.IsValid()
+}
+
+// trusted reports whether ip is one of proxies.
+func trusted(ip netip.Addr, proxies []netip.Prefix) bool {
+	ip = ip.Unmap()
+	for _, p := range proxies {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
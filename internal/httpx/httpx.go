@@ -0,0 +1,154 @@
+// Package httpx reads JSON requests and writes JSON responses for the
+// nexus services' APIs, so they all answer a bad request the same way.
+//
+//	var req createFeedReq
+//	if err := httpx.DecodeJSON(w, r, &req); err != nil {
+//		httpx.WriteError(w, err)
+//		return
+//	}
+//	...
+//	httpx.JSON(w, http.StatusCreated, feed)
+//
+// Errors are written as {"error": "message"}.
+package httpx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// MaxBody is the most a request body DecodeJSON reads may hold: 1 MiB.
+const MaxBody = 1 << 20
+
+// StatusError is an error to answer a request with: an HTTP status, and a
+// message the client may see.
+type StatusError struct {
+	Status  int
+	Message string
+}
+
+func (e *StatusError) Error() string { return e.Message }
+
+// Errorf returns a StatusError with a formatted message.
+func Errorf(status int, format string, args ...any) *StatusError {
+	return &StatusError{Status: status, Message: fmt.Sprintf(format, args...)}
+}
+
+// JSON writes v as JSON with the given status.
+func JSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("encode JSON response", "err", err)
+	}
+}
+
+// Error writes msg as a JSON error with the given status. Its arguments
+// are in the order of http.Error's.
+func Error(w http.ResponseWriter, msg string, status int) {
+	JSON(w, status, struct {
+		Error string `json:"error"`
+	}{msg})
+}
+
+// WriteError writes err as a JSON error. A StatusError is written as it
+// is; anything else is logged and answered with a plain 500, since its
+// message may say more than a client should see.
+func WriteError(w http.ResponseWriter, err error) {
+	var se *StatusError
+	if errors.As(err, &se) {
+		Error(w, se.Message, se.Status)
+		return
+	}
+	slog.Error("request failed", "err", err)
+	Error(w, "internal server error", http.StatusInternalServerError)
+}
+
+// LimitBody is middleware that refuses to read more than n bytes of a
+// request body, for routes that read bodies other than through
+// DecodeJSON or that want a tighter limit than MaxBody.
+func LimitBody(n int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Body = http.MaxBytesReader(w, r.Body, n)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// DecodeJSON reads r's body, at most MaxBody bytes of it, into v. The
+// body must be a single JSON value with no fields v doesn't have, so a
+// misspelt field is an error rather than silently ignored. Its errors are
+// StatusErrors saying what was wrong: 413 for a body that is too large,
+// 400 for anything else.
+func DecodeJSON(w http.ResponseWriter, r *http.Request, v any) error {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBody)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return decodeError(err)
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		if err != nil {
+			return decodeError(err)
+		}
+		return Errorf(http.StatusBadRequest, "request body must hold a single JSON value")
+	}
+	return nil
+}
+
+// decodeError says what was wrong with a JSON body.
+func decodeError(err error) *StatusError {
+	var (
+		tooLarge  *http.MaxBytesError
+		syntax    *json.SyntaxError
+		typeError *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.As(err, &tooLarge):
+		return Errorf(http.StatusRequestEntityTooLarge, "request body is larger than %d bytes", tooLarge.Limit)
+	case errors.Is(err, io.EOF):
+		return Errorf(http.StatusBadRequest, "request body is empty")
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return Errorf(http.StatusBadRequest, "request body is not valid JSON: it ends too soon")
+	case errors.As(err, &syntax):
+		return Errorf(http.StatusBadRequest, "request body is not valid JSON at byte %d", syntax.Offset)
+	case errors.As(err, &typeError) && typeError.Field != "":
+		return Errorf(http.StatusBadRequest, "%s must be %s, not %s", typeError.Field, jsonType(typeError.Type.Kind()), withArticle(typeError.Value))
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json has no type for this one.
+		return Errorf(http.StatusBadRequest, "request body has unknown field %s", strings.TrimPrefix(err.Error(), "json: unknown field "))
+	default:
+		return Errorf(http.StatusBadRequest, "request body is not valid JSON")
+	}
+}
+
+// withArticle puts "a" or "an" before what.
+func withArticle(what string) string {
+	if what != "" && strings.ContainsAny(what[:1], "aeiou") {
+		return "an " + what
+	}
+	return "a " + what
+}
+
+// jsonType names the JSON type a Go kind decodes from, with its article.
+func jsonType(k reflect.Kind) string {
+	switch {
+	case k == reflect.String:
+		return "a string"
+	case k == reflect.Bool:
+		return "a boolean"
+	case k >= reflect.Int && k <= reflect.Float64:
+		return "a number"
+	case k == reflect.Slice, k == reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
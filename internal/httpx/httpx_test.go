@@ -0,0 +1,77 @@
+package httpx
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	type req struct {
+		Name  string   `json:"name"`
+		Count int      `json:"count"`
+		Tags  []string `json:"tags"`
+	}
+	for _, tc := range []struct {
+		body   string
+		status int    // 0 for no error
+		want   string // in the message
+	}{
+		{`{"name":"a","count":2,"tags":["x"]}`, 0, ""},
+		{``, 400, "empty"},
+		{`{"name":"a"`, 400, "ends too soon"},
+		{`{"name":"a",}`, 400, "byte 13"},
+		{`{"nmae":"a"}`, 400, `unknown field "nmae"`},
+		{`{"count":"2"}`, 400, "count must be a number, not a string"},
+		{`{"tags":{}}`, 400, "tags must be an array, not an object"},
+		{`{"name":"a"}{"name":"b"}`, 400, "single JSON value"},
+		{`{"name":"a"} x`, 400, "not valid JSON"},
+		{`{"name":"` + strings.Repeat("a", MaxBody) + `"}`, 413, "larger than 1048576 bytes"},
+	} {
+		var v req
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+		err := DecodeJSON(httptest.NewRecorder(), r, &v)
+		if tc.status == 0 {
+			if err != nil || v.Name != "a" || v.Count != 2 {
+				t.Errorf("DecodeJSON(%.40q): %+v, %v", tc.body, v, err)
+			}
+			continue
+		}
+		var se *StatusError
+		if !errors.As(err, &se) || se.Status != tc.status || !strings.Contains(se.Message, tc.want) {
+			t.Errorf("DecodeJSON(%.40q): %v; want %d mentioning %q", tc.body, err, tc.status, tc.want)
+		}
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	WriteError(rec, Errorf(http.StatusConflict, "slug %q is taken", "home"))
+	if rec.Code != http.StatusConflict || rec.Body.String() != `{"error":"slug \"home\" is taken"}`+"\n" {
+		t.Errorf("StatusError: %d %s", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type %q", ct)
+	}
+
+	// Other errors might leak internals; the client gets a plain 500.
+	rec = httptest.NewRecorder()
+	WriteError(rec, errors.New("open /var/lib/cal.db: disk I/O error"))
+	if rec.Code != http.StatusInternalServerError || strings.Contains(rec.Body.String(), "cal.db") {
+		t.Errorf("plain error: %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestLimitBody(t *testing.T) {
+	var err error
+	h := LimitBody(4)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err = r.Body.Read(make([]byte, 16))
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", strings.NewReader("too long")))
+	var tooLarge *http.MaxBytesError
+	if !errors.As(err, &tooLarge) {
+		t.Errorf("reading past the limit: %v, want a MaxBytesError", err)
+	}
+}
@@ -0,0 +1,142 @@
+// Package logging is the structured logging the nexus services share,
+// built on log/slog.
+//
+// A service calls Setup first thing in main. It reads LOG_LEVEL (debug,
+// info, warn or error; default info) and LOG_FORMAT (text or json;
+// default text) from the environment and makes the result slog's default
+// logger, which the standard log package then writes through too:
+//
+//	if err := logging.Setup("nexus-cal"); err != nil { ... }
+//	slog.Info("nexus-cal starting", "addr", addr)
+//
+// Middleware (which httpserver installs) gives every request a logger
+// carrying its request ID, and the user ID once authentication has called
+// SetUser, and writes one access log line per request with both. Handlers
+// log through FromContext so their lines can be correlated with it.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/jredh-dev/nexus/internal/settings"
+)
+
+// Options configures a logger.
+type Options struct {
+	Level  slog.Level
+	Format string // "text" or "json"
+}
+
+// FromEnv reads Options from LOG_LEVEL and LOG_FORMAT.
+func FromEnv() (Options, error) {
+	l := settings.New("")
+	level := l.OneOf("LOG_LEVEL", "info", "debug", "info", "warn", "error")
+	format := l.OneOf("LOG_FORMAT", "text", "text", "json")
+	var o Options
+	if err := o.Level.UnmarshalText([]byte(level)); err != nil {
+		return o, err
+	}
+	o.Format = format
+	return o, l.Err()
+}
+
+// New returns a logger writing to w.
+func New(w io.Writer, o Options) *slog.Logger {
+	ho := &slog.HandlerOptions{Level: o.Level}
+	if o.Format == "json" {
+		return slog.New(slog.NewJSONHandler(w, ho))
+	}
+	return slog.New(slog.NewTextHandler(w, ho))
+}
+
+// Setup makes a logger configured from the environment, writing to
+// stderr and tagging every line with service, the default.
+func Setup(service string) error {
+	o, err := FromEnv()
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(New(os.Stderr, o).With("service", service))
+	return nil
+}
+
+// Fatal logs msg and args at error level and exits with status 1.
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+type ctxKey struct{}
+
+// requestLog is the logger of one request. Authentication runs after
+// Middleware has put it in the context, so SetUser updates it in place.
+type requestLog struct {
+	mu     sync.Mutex
+	logger *slog.Logger
+}
+
+// FromContext returns the logger of the request ctx belongs to, or the
+// default logger outside a request.
+func FromContext(ctx context.Context) *slog.Logger {
+	if rl, ok := ctx.Value(ctxKey{}).(*requestLog); ok {
+		rl.mu.Lock()
+		defer rl.mu.Unlock()
+		return rl.logger
+	}
+	return slog.Default()
+}
+
+// SetUser adds the authenticated user's ID to the logger of the request
+// ctx belongs to, and so to its access log line.
+func SetUser(ctx context.Context, userID string) {
+	if rl, ok := ctx.Value(ctxKey{}).(*requestLog); ok && userID != "" {
+		rl.mu.Lock()
+		defer rl.mu.Unlock()
+		rl.logger = rl.logger.With("user_id", userID)
+	}
+}
+
+// quiet are the paths probes and scrapers hit every few seconds; their
+// access lines are logged at debug level.
+var quiet = map[string]bool{"/health": true, "/healthz": true, "/readyz": true, "/metrics": true}
+
+// Middleware gives each request a logger annotated with its request ID and
+// writes one access log line per request once it has been served. It must
+// come after chi's RequestID middleware.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rl := &requestLog{logger: slog.Default()}
+		if id := middleware.GetReqID(r.Context()); id != "" {
+			rl.logger = rl.logger.With("request_id", id)
+		}
+		r = r.WithContext(context.WithValue(r.Context(), ctxKey{}, rl))
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		level := slog.LevelInfo
+		if quiet[r.URL.Path] {
+			level = slog.LevelDebug
+		}
+		FromContext(r.Context()).Log(r.Context(), level, "request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", status,
+			"bytes", ww.BytesWritten(),
+			"duration", time.Since(start),
+			"remote", r.RemoteAddr,
+		)
+	})
+}
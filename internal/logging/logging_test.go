@@ -0,0 +1,87 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+func capture(t *testing.T, level slog.Level) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(New(&buf, Options{Level: level, Format: "json"}))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	return &buf
+}
+
+func TestMiddleware(t *testing.T) {
+	buf := capture(t, slog.LevelInfo)
+
+	r := chi.NewRouter()
+	r.Use(middleware.RequestID)
+	r.Use(Middleware)
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			SetUser(r.Context(), "u-1")
+			next.ServeHTTP(w, r)
+		})
+	})
+	r.Get("/x", func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).Error("inner")
+		w.WriteHeader(http.StatusAccepted)
+	})
+	r.Get("/healthz", func(http.ResponseWriter, *http.Request) {})
+
+	req := httptest.NewRequest("GET", "/x", nil)
+	req.Header.Set("X-Request-Id", "req-123")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/healthz", nil))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d log lines, want 2 (probes are logged at debug):\n%s", len(lines), buf.String())
+	}
+	for _, line := range lines {
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("unmarshal %q: %v", line, err)
+		}
+		if rec["request_id"] != "req-123" || rec["user_id"] != "u-1" {
+			t.Errorf("request_id = %v, user_id = %v in %s", rec["request_id"], rec["user_id"], line)
+		}
+	}
+	if !strings.Contains(lines[1], `"status":202`) {
+		t.Errorf("access log missing status: %s", lines[1])
+	}
+}
+
+func TestFromContextOutsideRequest(t *testing.T) {
+	if FromContext(t.Context()) != slog.Default() {
+		t.Error("FromContext outside a request isn't the default logger")
+	}
+	SetUser(t.Context(), "u-1") // no request logger: does nothing
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "Debug")
+	t.Setenv("LOG_FORMAT", "json")
+	o, err := FromEnv()
+	if err != nil || o.Level != slog.LevelDebug || o.Format != "json" {
+		t.Errorf("FromEnv = %+v, %v", o, err)
+	}
+
+	t.Setenv("LOG_LEVEL", "loud")
+	t.Setenv("LOG_FORMAT", "xml")
+	_, err = FromEnv()
+	if err == nil || !strings.Contains(err.Error(), "LOG_LEVEL") || !strings.Contains(err.Error(), "LOG_FORMAT") {
+		t.Errorf("FromEnv with bad values: %v, want both reported", err)
+	}
+}
@@ -0,0 +1,123 @@
+// Package metrics is the Prometheus instrumentation the nexus HTTP
+// services share: request latency and in-flight requests from chi
+// middleware, database operation timings, and the Go runtime and process
+// collectors, all in a registry of the service's own served at /metrics
+// (httpserver.WithMetrics does the serving).
+//
+// Every metric is prefixed with the service's namespace, so the same
+// dashboard works for any of them:
+//
+//	cal_http_request_duration_seconds{method,route,status}
+//	cal_http_requests_in_flight
+//	cal_db_operation_duration_seconds{op}
+//
+// A service with metrics of its own registers them with MustRegister.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// DBBuckets suit SQLite operations, which mostly take well under a
+// millisecond.
+var DBBuckets = []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1}
+
+// Metrics holds the common collectors and the registry they live in.
+// A nil *Metrics is valid and records nothing, so callers need not check
+// whether metrics are enabled.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requestDuration *prometheus.HistogramVec
+	inFlight        prometheus.Gauge
+	dbDuration      *prometheus.HistogramVec
+}
+
+// New creates the common metrics for the service whose metrics are
+// prefixed with namespace, such as "cal".
+func New(namespace string) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by method, route pattern, and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "http_requests_in_flight",
+			Help:      "HTTP requests being served.",
+		}),
+		dbDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_operation_duration_seconds",
+			Help:      "Database operation latency by operation.",
+			Buckets:   DBBuckets,
+		}, []string{"op"}),
+	}
+	m.registry.MustRegister(
+		m.requestDuration, m.inFlight, m.dbDuration,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// MustRegister adds a service's own collectors to the registry. It panics
+// if one is already registered.
+func (m *Metrics) MustRegister(cs ...prometheus.Collector) {
+	m.registry.MustRegister(cs...)
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Middleware records the latency of every request and how many are in
+// flight. Requests are labelled by chi route pattern rather than path so
+// tokens and IDs don't explode the label cardinality; requests that match
+// no route share "unmatched".
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	if m == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.inFlight.Inc()
+		defer m.inFlight.Dec()
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			if p := rctx.RoutePattern(); p != "" {
+				route = p
+			}
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		m.requestDuration.WithLabelValues(r.Method, route, strconv.Itoa(status)).
+			Observe(time.Since(start).Seconds())
+	})
+}
+
+// ObserveDB records the duration of a database operation. It has the
+// signature the services' database observers take.
+func (m *Metrics) ObserveDB(op string, d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.dbDuration.WithLabelValues(op).Observe(d.Seconds())
+}
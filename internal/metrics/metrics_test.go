@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("scrape status = %d", rec.Code)
+	}
+	body, _ := io.ReadAll(rec.Body)
+	return string(body)
+}
+
+func TestMetrics(t *testing.T) {
+	m := New("svc")
+	m.MustRegister(prometheus.NewCounter(prometheus.CounterOpts{Namespace: "svc", Name: "own_total", Help: "A service's own."}))
+
+	var inFlight string
+	r := chi.NewRouter()
+	r.Use(m.Middleware)
+	r.Get("/items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		inFlight = scrape(t, m)
+		w.WriteHeader(http.StatusTeapot)
+	})
+	for _, path := range []string{"/items/a", "/items/b", "/nowhere"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+	m.ObserveDB("create_item", time.Millisecond)
+
+	if !strings.Contains(inFlight, "svc_http_requests_in_flight 1") {
+		t.Error("in-flight gauge wasn't 1 during a request")
+	}
+	body := scrape(t, m)
+	for _, want := range []string{
+		`svc_http_request_duration_seconds_count{method="GET",route="/items/{id}",status="418"} 2`,
+		`svc_http_request_duration_seconds_count{method="GET",route="unmatched",status="404"} 1`,
+		`svc_http_requests_in_flight 0`,
+		`svc_db_operation_duration_seconds_count{op="create_item"} 1`,
+		`svc_own_total 0`,
+		`go_goroutines`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q", want)
+		}
+	}
+}
+
+func TestNilMetrics(t *testing.T) {
+	var m *Metrics
+	m.ObserveDB("op", time.Second)
+
+	called := false
+	h := m.Middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { called = true }))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if !called {
+		t.Error("nil Metrics middleware did not call next")
+	}
+}
@@ -0,0 +1,117 @@
+// Package portalhook is the webhook contract for giveaway claim changes.
+//
+// The giveaway service (currently parked in services/portal, pending
+// extraction) is the producer: it posts a signed Payload whenever a claim is
+// confirmed, rescheduled, or cancelled. nexus-cal's claims importer is the
+// consumer and turns each claim with a delivery timeslot into an event.
+// Both sides share these types and the signing scheme so they can't drift.
+//
+// Until the producer is wired up nothing sends these webhooks, so cal only
+// mounts the receiver when CAL_PORTAL_FEED_ID and CAL_PORTAL_WEBHOOK_SECRET
+// are set.
+package portalhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// SignatureHeader carries the hex HMAC-SHA256 of the request body, keyed
+// with the shared webhook secret and prefixed with "sha256=".
+const SignatureHeader = "X-Portal-Signature"
+
+// Webhook event types.
+const (
+	TypeConfirmed = "claim.confirmed"
+	TypeUpdated   = "claim.updated"
+	TypeCancelled = "claim.cancelled"
+)
+
+// Payload is one webhook delivery.
+type Payload struct {
+	ID         string    `json:"id"`   // unique per delivery
+	Type       string    `json:"type"` // one of the Type constants
+	OccurredAt time.Time `json:"occurred_at"`
+	Claim      Claim     `json:"claim"`
+}
+
+// Claim is the producer's view of a giveaway claim.
+type Claim struct {
+	ID           string    `json:"id"`
+	ItemID       string    `json:"item_id"`
+	ItemTitle    string    `json:"item_title"`
+	ClaimerName  string    `json:"claimer_name"`
+	ClaimerPhone string    `json:"claimer_phone"`
+	Address      string    `json:"delivery_address"`
+	Notes        string    `json:"notes"`
+	Status       string    `json:"status"`
+	Timeslot     *Timeslot `json:"timeslot,omitempty"` // nil until a delivery time is agreed
+}
+
+// Timeslot is the agreed delivery window.
+type Timeslot struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Sign returns the SignatureHeader value for body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether sig is the SignatureHeader value for body.
+func Verify(secret string, body []byte, sig string) bool {
+	return hmac.Equal([]byte(sig), []byte(Sign(secret, body)))
+}
+
+// Sender posts signed payloads to a consumer's webhook URL.
+type Sender struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+// NewSender creates a Sender for url. A nil client uses one with a 10s
+// timeout.
+func NewSender(url, secret string, client *http.Client) *Sender {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Sender{url: url, secret: secret, client: client}
+}
+
+// Send delivers p. Any non-2xx response is an error; the consumer answers
+// 5xx for failures worth retrying with the same payload.
+func (s *Sender) Send(ctx context.Context, p Payload) error {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("marshal payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(s.secret, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("deliver %s: %w", p.ID, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("deliver %s: %s: %s", p.ID, resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
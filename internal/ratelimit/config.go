@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"maps"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/jredh-dev/nexus/internal/settings"
+)
+
+// Config is a service's rate limiting settings.
+type Config struct {
+	// DB is the SQLite database buckets are kept in; empty keeps them in
+	// memory.
+	DB string
+	// Limits are the rates of the service's limited routes.
+	Limits Limits
+	// TrustedProxies are the load balancers in front of the service, whose
+	// X-Forwarded-For says which client ByIP should count a request
+	// against (see httpserver.WithTrustedProxies). Without them the
+	// connecting peer is the client, as a header anyone can send must not
+	// pick the bucket.
+	TrustedProxies []netip.Prefix
+}
+
+// LoadConfig reads the rate limiting settings of a service whose settings
+// start with prefix: <prefix>RATE_LIMIT_DB; <prefix>RATE_LIMITS, a list of
+// name=rate overriding defaults, such as "login=10/m,api=off"; and
+// <prefix>TRUSTED_PROXIES, a list of addresses and CIDR ranges such as
+// "10.0.0.0/8". Names not in defaults are problems, since they are
+// usually typos.
+func LoadConfig(l *settings.Loader, prefix string, defaults Limits) Config {
+	cfg := Config{DB: l.String(prefix+"RATE_LIMIT_DB", ""), Limits: make(Limits, len(defaults))}
+	for name, rate := range defaults {
+		cfg.Limits[name] = rate
+	}
+	key := prefix + "RATE_LIMITS"
+	for _, item := range l.List(key) {
+		name, spec, _ := strings.Cut(item, "=")
+		name = strings.TrimSpace(name)
+		if _, ok := defaults[name]; !ok {
+			l.Check(false, "%s: no route limit called %q; want one of %s", key, name, defaults.names())
+			continue
+		}
+		rate, err := ParseRate(spec)
+		l.Check(err == nil, "%s: %s: %v", key, name, err)
+		if err == nil {
+			cfg.Limits[name] = rate
+		}
+	}
+	key = prefix + "TRUSTED_PROXIES"
+	for _, item := range l.List(key) {
+		p, err := parseProxy(item)
+		l.Check(err == nil, "%s: %q: want an IP address or CIDR range", key, item)
+		if err == nil {
+			cfg.TrustedProxies = append(cfg.TrustedProxies, p)
+		}
+	}
+	return cfg
+}
+
+// parseProxy reads an address, as a range of one, or a CIDR range.
+func parseProxy(s string) (netip.Prefix, error) {
+	if !strings.Contains(s, "/") {
+		ip, err := netip.ParseAddr(s)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		return netip.PrefixFrom(ip.Unmap(), ip.Unmap().BitLen()), nil
+	}
+	p, err := netip.ParsePrefix(s)
+	return p.Masked(), err
+}
+
+// names lists the route names in ls, sorted.
+func (ls Limits) names() string {
+	return strings.Join(slices.Sorted(maps.Keys(ls)), ", ")
+}
@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepEvery is how often buckets that have refilled are forgotten, so
+// clients that have gone quiet don't use memory or disk.
+const sweepEvery = time.Minute
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	rate   Rate
+}
+
+// Memory keeps buckets in memory, so each replica of a service limits on
+// its own and limits start over when it restarts.
+type Memory struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewMemory returns an empty Memory store.
+func NewMemory() *Memory {
+	return &Memory{buckets: make(map[string]*bucket)}
+}
+
+// Take implements Store.
+func (m *Memory) Take(_ context.Context, key string, rate Rate, now time.Time) (bool, time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if now.Sub(m.lastSweep) >= sweepEvery {
+		m.sweep(now)
+	}
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(rate.Burst), last: now}
+		m.buckets[key] = b
+	}
+	b.rate = rate
+	tokens, ok, wait := take(refill(b.tokens, b.last, now, rate), rate)
+	b.tokens, b.last = tokens, now
+	return ok, wait, nil
+}
+
+// sweep forgets the buckets that are full again, which is the same as
+// never having seen their keys.
+func (m *Memory) sweep(now time.Time) {
+	for k, b := range m.buckets {
+		if refill(b.tokens, b.last, now, b.rate) >= float64(b.rate.Burst) {
+			delete(m.buckets, k)
+		}
+	}
+	m.lastSweep = now
+}
+
+// Close implements Store.
+func (m *Memory) Close() error { return nil }
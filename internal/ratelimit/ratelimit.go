@@ -0,0 +1,199 @@
+// Package ratelimit is the request rate limiting the nexus services share:
+// a token bucket per route and client, where the client is its IP address
+// or the credential it sends, kept in memory or in SQLite.
+//
+// A service names each limited route and gives it a default rate, which
+// deployments override by name (see LoadConfig):
+//
+//	lim := ratelimit.New(store, cfg.RateLimit.Limits, limited)
+//	r.With(lim.Limit("login", ratelimit.ByIP)).Post("/login", h.Login)
+//
+// Requests over the limit get 429 Too Many Requests with Retry-After.
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/jredh-dev/nexus/internal/logging"
+	"github.com/jredh-dev/nexus/internal/sso"
+)
+
+// Rate is how fast a bucket refills and how much it holds. The zero Rate
+// doesn't limit.
+type Rate struct {
+	Limit float64 // tokens a second
+	Burst int     // bucket size: how many requests may come at once
+}
+
+// PerMinute is n requests a minute, all of which may come at once.
+func PerMinute(n int) Rate {
+	return Rate{Limit: float64(n) / 60, Burst: n}
+}
+
+// PerHour is n requests an hour, all of which may come at once.
+func PerHour(n int) Rate {
+	return Rate{Limit: float64(n) / 3600, Burst: n}
+}
+
+// Zero reports whether r doesn't limit.
+func (r Rate) Zero() bool { return r.Limit <= 0 || r.Burst <= 0 }
+
+var units = map[string]time.Duration{"s": time.Second, "m": time.Minute, "h": time.Hour}
+
+// ParseRate reads a rate written "N/unit", N requests a second (s), minute
+// (m) or hour (h), all of which may come at once, or "N/unit:B" for bursts
+// of B instead. "off" doesn't limit.
+func ParseRate(s string) (Rate, error) {
+	s = strings.TrimSpace(s)
+	if s == "off" {
+		return Rate{}, nil
+	}
+	spec, burstStr, hasBurst := strings.Cut(s, ":")
+	nStr, unit, ok := strings.Cut(spec, "/")
+	per, known := units[unit]
+	n, err := strconv.ParseFloat(nStr, 64)
+	if !ok || !known || err != nil || n <= 0 {
+		return Rate{}, fmt.Errorf("rate %q: want N/s, N/m or N/h, optionally with :burst", s)
+	}
+	r := Rate{Limit: n / per.Seconds(), Burst: int(math.Ceil(n))}
+	if hasBurst {
+		if r.Burst, err = strconv.Atoi(burstStr); err != nil || r.Burst < 1 {
+			return Rate{}, fmt.Errorf("rate %q: burst must be a positive integer", s)
+		}
+	}
+	return r, nil
+}
+
+// Store keeps the buckets. Implementations are safe for concurrent use.
+type Store interface {
+	// Take takes a token from key's bucket, which refills and holds as rate
+	// says, as of now. If the bucket is empty it reports false and how long
+	// until the next token.
+	Take(ctx context.Context, key string, rate Rate, now time.Time) (bool, time.Duration, error)
+	Close() error
+}
+
+// Open returns a Store keeping buckets in the SQLite database at path, or
+// in memory if path is empty.
+func Open(path string) (Store, error) {
+	if path == "" {
+		return NewMemory(), nil
+	}
+	return OpenSQLite(path)
+}
+
+// KeyFunc says which client a request comes from.
+type KeyFunc func(r *http.Request) string
+
+// ByIP keys requests by client IP address, which is where RemoteAddr says
+// they come from.
+func ByIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
+
+// ByCredential keys requests by the credential they carry: the
+// Authorization header, an X-API-Key header or the portal session cookie,
+// so each has its own limit wherever it is used from. Requests without
+// one are keyed by IP.
+func ByCredential(r *http.Request) string {
+	cred := r.Header.Get("Authorization")
+	if cred == "" {
+		cred = r.Header.Get("X-API-Key")
+	}
+	if c, err := r.Cookie(sso.SessionCookie); cred == "" && err == nil {
+		cred = c.Value
+	}
+	if cred == "" {
+		return ByIP(r)
+	}
+	// Buckets may be stored, so the credential itself isn't.
+	sum := sha256.Sum256([]byte(cred))
+	return "credential:" + hex.EncodeToString(sum[:12])
+}
+
+// Limits are the rates of a service's limited routes, by route name.
+type Limits map[string]Rate
+
+// Limiter limits requests to named routes.
+type Limiter struct {
+	store   Store
+	limits  Limits
+	limited *prometheus.CounterVec
+	now     func() time.Time
+}
+
+// New returns a Limiter applying limits with buckets in store. It counts
+// the requests it turns away in limited, labelled by route name, which may
+// be nil.
+func New(store Store, limits Limits, limited *prometheus.CounterVec) *Limiter {
+	return &Limiter{store: store, limits: limits, limited: limited, now: time.Now}
+}
+
+// NewCounter returns the counter of requests a service's Limiter turns
+// away, by route name, for the service to register with its metrics.
+func NewCounter(namespace string) *prometheus.CounterVec {
+	return prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limited_total",
+		Help:      "Requests turned away for exceeding a rate limit, by limit.",
+	}, []string{"limit"})
+}
+
+// Limit is middleware limiting the route called name, whose rate comes from
+// the Limiter's limits, with a bucket per client as key says. A route
+// without a rate isn't limited. If the store fails, requests are let
+// through: an outage of the limiter shouldn't be one of the service.
+func (l *Limiter) Limit(name string, key KeyFunc) func(http.Handler) http.Handler {
+	rate := l.limits[name]
+	return func(next http.Handler) http.Handler {
+		if rate.Zero() {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ok, wait, err := l.store.Take(r.Context(), name+"|"+key(r), rate, l.now())
+			if err != nil {
+				logging.FromContext(r.Context()).Error("rate limit", "limit", name, "err", err)
+				ok = true
+			}
+			if !ok {
+				if l.limited != nil {
+					l.limited.WithLabelValues(name).Inc()
+				}
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				http.Error(w, "too many requests", http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// refill returns how many tokens a bucket holding tokens at last holds at
+// now.
+func refill(tokens float64, last, now time.Time, rate Rate) float64 {
+	return math.Min(float64(rate.Burst), tokens+now.Sub(last).Seconds()*rate.Limit)
+}
+
+// take takes a token from a bucket holding tokens, returning what is left
+// and, if there was none to take, how long until there is.
+func take(tokens float64, rate Rate) (float64, bool, time.Duration) {
+	if tokens < 1 {
+		return tokens, false, time.Duration((1 - tokens) / rate.Limit * float64(time.Second))
+	}
+	return tokens - 1, true, 0
+}
@@ -0,0 +1,212 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/jredh-dev/nexus/internal/settings"
+)
+
+func testStores(t *testing.T) map[string]Store {
+	t.Helper()
+	s, err := OpenSQLite(filepath.Join(t.TempDir(), "ratelimit.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return map[string]Store{"memory": NewMemory(), "sqlite": s}
+}
+
+func TestTake(t *testing.T) {
+	ctx := context.Background()
+	for name, s := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			now := time.Unix(0, 0)
+			rate := Rate{Limit: 2, Burst: 3}
+			for i := 0; i < 3; i++ {
+				if ok, _, err := s.Take(ctx, "a", rate, now); !ok || err != nil {
+					t.Fatalf("request %d of a burst of 3: %v, %v", i+1, ok, err)
+				}
+			}
+			ok, wait, err := s.Take(ctx, "a", rate, now)
+			if ok || wait != 500*time.Millisecond || err != nil {
+				t.Errorf("4th request: %v, wait %v, %v; want refused, wait 500ms", ok, wait, err)
+			}
+			if ok, _, _ := s.Take(ctx, "b", rate, now); !ok {
+				t.Error("another key was limited too")
+			}
+
+			now = now.Add(500 * time.Millisecond)
+			if ok, _, _ := s.Take(ctx, "a", rate, now); !ok {
+				t.Error("refused after a token refilled")
+			}
+		})
+	}
+}
+
+func TestMemorySweep(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	now := time.Unix(0, 0)
+	rate := Rate{Limit: 1, Burst: 2}
+	m.Take(ctx, "a", rate, now) //nolint:errcheck
+	now = now.Add(sweepEvery)
+	m.Take(ctx, "b", rate, now) //nolint:errcheck
+	if _, ok := m.buckets["a"]; ok || len(m.buckets) != 1 {
+		t.Errorf("buckets after sweep: %v, want only b", m.buckets)
+	}
+}
+
+func TestSQLiteSweep(t *testing.T) {
+	ctx := context.Background()
+	s := testStores(t)["sqlite"].(*SQLite)
+	now := time.Unix(0, 0)
+	rate := Rate{Limit: 1, Burst: 2}
+	s.Take(ctx, "a", rate, now) //nolint:errcheck
+	now = now.Add(sweepEvery)
+	s.Take(ctx, "b", rate, now) //nolint:errcheck
+	var keys []string
+	rows, err := s.conn.Query(`SELECT key FROM rate_limits`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var k string
+		rows.Scan(&k) //nolint:errcheck
+		keys = append(keys, k)
+	}
+	if strings.Join(keys, ",") != "b" {
+		t.Errorf("buckets after sweep: %v, want only b", keys)
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	for in, want := range map[string]Rate{
+		"20/s":   {Limit: 20, Burst: 20},
+		"30/m":   {Limit: 0.5, Burst: 30},
+		"60/m:5": {Limit: 1, Burst: 5},
+		"off":    {},
+	} {
+		got, err := ParseRate(in)
+		if err != nil || got != want {
+			t.Errorf("ParseRate(%q) = %+v, %v; want %+v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "20", "20/d", "-1/s", "0/s", "x/m", "5/m:0", "5/m:"} {
+		if _, err := ParseRate(in); err == nil {
+			t.Errorf("ParseRate(%q): no error", in)
+		}
+	}
+}
+
+func TestLimit(t *testing.T) {
+	limited := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "limited"}, []string{"limit"})
+	l := New(NewMemory(), Limits{"login": {Limit: 1, Burst: 1}, "off": {}}, limited)
+	ok := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+
+	serve := func(h http.Handler, remote, auth string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		r.RemoteAddr = remote
+		if auth != "" {
+			r.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec
+	}
+
+	byIP := l.Limit("login", ByIP)(ok)
+	if rec := serve(byIP, "192.0.2.1:1234", ""); rec.Code != http.StatusOK {
+		t.Fatalf("first request: %d", rec.Code)
+	}
+	rec := serve(byIP, "192.0.2.1:5678", "")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "1" {
+		t.Errorf("second request from the IP: %d, Retry-After %q; want 429, 1", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	if rec := serve(byIP, "192.0.2.2:1234", ""); rec.Code != http.StatusOK {
+		t.Errorf("request from another IP: %d", rec.Code)
+	}
+
+	// Routes have buckets of their own, and each credential has its own.
+	byCredential := l.Limit("login", ByCredential)(ok)
+	for _, auth := range []string{"Bearer key-1", "Bearer key-2"} {
+		if rec := serve(byCredential, "192.0.2.1:1234", auth); rec.Code != http.StatusOK {
+			t.Errorf("first request with %s: %d", auth, rec.Code)
+		}
+	}
+	if rec := serve(byCredential, "192.0.2.3:1234", "Bearer key-1"); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("second request with key-1 from another IP: %d, want 429", rec.Code)
+	}
+
+	unlimited := l.Limit("off", ByIP)(ok)
+	for i := 0; i < 5; i++ {
+		if rec := serve(unlimited, "192.0.2.1:1234", ""); rec.Code != http.StatusOK {
+			t.Fatalf("request %d to a route without a limit: %d", i+1, rec.Code)
+		}
+	}
+
+	var c dto.Metric
+	limited.WithLabelValues("login").Write(&c) //nolint:errcheck
+	if n := c.GetCounter().GetValue(); n != 2 {
+		t.Errorf("limited: %v, want 2", n)
+	}
+}
+
+func TestLimitFailsOpen(t *testing.T) {
+	s, err := OpenSQLite(filepath.Join(t.TempDir(), "ratelimit.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+	h := New(s, Limits{"login": {Limit: 1, Burst: 1}}, nil).Limit("login", ByIP)(
+		http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("request %d with the store down: %d, want 200", i+1, rec.Code)
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	defaults := Limits{"login": PerMinute(10), "api": PerMinute(600)}
+
+	t.Setenv("T_RATE_LIMIT_DB", "limits.db")
+	t.Setenv("T_RATE_LIMITS", "login=3/m, api=off")
+	t.Setenv("T_TRUSTED_PROXIES", "10.0.0.0/8, 192.0.2.7")
+	l := settings.New("")
+	cfg := LoadConfig(l, "T_", defaults)
+	if err := l.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DB != "limits.db" || cfg.Limits["login"] != PerMinute(3) || !cfg.Limits["api"].Zero() {
+		t.Errorf("config: %+v", cfg)
+	}
+	if want := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("192.0.2.7/32")}; !slices.Equal(cfg.TrustedProxies, want) {
+		t.Errorf("TrustedProxies = %v, want %v", cfg.TrustedProxies, want)
+	}
+	if defaults["login"] != PerMinute(10) {
+		t.Error("LoadConfig changed the defaults")
+	}
+
+	t.Setenv("T_RATE_LIMITS", "logn=3/m,api=lots")
+	t.Setenv("T_TRUSTED_PROXIES", "lb.internal")
+	l = settings.New("")
+	LoadConfig(l, "T_", defaults)
+	err := l.Err()
+	if err == nil || !strings.Contains(err.Error(), `"logn"`) || !strings.Contains(err.Error(), "api") || !strings.Contains(err.Error(), "lb.internal") {
+		t.Errorf("Err: %v, want the unknown route, the bad rate and the bad proxy", err)
+	}
+}
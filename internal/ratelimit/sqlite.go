@@ -0,0 +1,106 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/jredh-dev/nexus/internal/sqlitex"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS rate_limits (
+	key        TEXT PRIMARY KEY,
+	tokens     REAL NOT NULL,
+	updated_at INTEGER NOT NULL, -- unix nanoseconds
+	full_at    INTEGER NOT NULL  -- when the bucket will have refilled
+);
+CREATE INDEX IF NOT EXISTS rate_limits_full_at ON rate_limits (full_at);
+`
+
+// SQLite keeps buckets in a SQLite database, so limits hold across
+// restarts and are shared by the processes on a host using the same file.
+type SQLite struct {
+	conn *sql.DB
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+// OpenSQLite creates or opens the SQLite database at path and applies the
+// schema.
+func OpenSQLite(path string) (*SQLite, error) {
+	// Transactions take the write lock up front (see sqlitex), so two
+	// processes can't both read a bucket and both take its last token.
+	conn, err := sqlitex.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	if _, err := conn.Exec(schema); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("apply schema: %w", err)
+	}
+	return &SQLite{conn: conn}, nil
+}
+
+// Take implements Store.
+func (s *SQLite) Take(ctx context.Context, key string, rate Rate, now time.Time) (bool, time.Duration, error) {
+	if err := s.maybeSweep(ctx, now); err != nil {
+		return false, 0, err
+	}
+
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return false, 0, fmt.Errorf("take %s: %w", key, err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	tokens := float64(rate.Burst)
+	var last int64
+	err = tx.QueryRowContext(ctx, `SELECT tokens, updated_at FROM rate_limits WHERE key = ?`, key).Scan(&tokens, &last)
+	switch {
+	case err == sql.ErrNoRows:
+	case err != nil:
+		return false, 0, fmt.Errorf("take %s: %w", key, err)
+	default:
+		tokens = refill(tokens, time.Unix(0, last), now, rate)
+	}
+	tokens, ok, wait := take(tokens, rate)
+
+	fullIn := time.Duration(math.Ceil((float64(rate.Burst) - tokens) / rate.Limit * float64(time.Second)))
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO rate_limits (key, tokens, updated_at, full_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET tokens = excluded.tokens, updated_at = excluded.updated_at, full_at = excluded.full_at`,
+		key, tokens, now.UnixNano(), now.Add(fullIn).UnixNano())
+	if err != nil {
+		return false, 0, fmt.Errorf("take %s: %w", key, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return false, 0, fmt.Errorf("take %s: %w", key, err)
+	}
+	return ok, wait, nil
+}
+
+// maybeSweep deletes the buckets that are full again, at most once every
+// sweepEvery.
+func (s *SQLite) maybeSweep(ctx context.Context, now time.Time) error {
+	s.mu.Lock()
+	if now.Sub(s.lastSweep) < sweepEvery {
+		s.mu.Unlock()
+		return nil
+	}
+	s.lastSweep = now
+	s.mu.Unlock()
+	if _, err := s.conn.ExecContext(ctx, `DELETE FROM rate_limits WHERE full_at <= ?`, now.UnixNano()); err != nil {
+		return fmt.Errorf("sweep rate limits: %w", err)
+	}
+	return nil
+}
+
+// Close shuts down the database connection.
+func (s *SQLite) Close() error {
+	return s.conn.Close()
+}
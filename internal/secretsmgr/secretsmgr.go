@@ -0,0 +1,220 @@
+// Package secretsmgr resolves the credentials the nexus services run
+// with: session and signing secrets, provider API keys, hermit's shared
+// secret.
+//
+// A credential named KEY is looked for, in order:
+//
+//   - in the environment, as KEY;
+//   - in the file KEY_FILE names, or in $SECRETS_DIR/KEY, as container
+//     platforms mount secrets;
+//   - in Vault, at the KV path SECRETS_VAULT_PATH (with VAULT_ADDR and
+//     VAULT_TOKEN), if that is set;
+//   - in the SOPS-encrypted YAML or JSON file SECRETS_SOPS_FILE, if that
+//     is set, decrypted with the sops command.
+//
+// settings.Loader.Secret reads through Default, so every secret a
+// service's config reads can come from any of these. A credential that
+// can change while the service runs is held as a Secret, which Watch
+// keeps current and which calls its OnRotate hooks when it changes:
+//
+//	key, err := secretsmgr.Default().Secret(ctx, "TELNYX_API_KEY")
+//	...
+//	key.OnRotate(telnyx.SetAPIKey)
+//	go secretsmgr.Default().Watch(ctx, secretsmgr.WatchEvery)
+package secretsmgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WatchEvery is how often services look for rotated credentials.
+const WatchEvery = time.Minute
+
+// Source is somewhere credentials are kept.
+type Source interface {
+	// Name says where a credential came from, for logs.
+	Name() string
+	// Lookup returns key's value, and whether the source has it.
+	Lookup(ctx context.Context, key string) (string, bool, error)
+}
+
+// Manager looks credentials up in its sources, in order, and keeps the
+// Secrets it has handed out current.
+type Manager struct {
+	sources []Source
+
+	mu      sync.Mutex
+	secrets map[string]*Secret
+}
+
+// New returns a Manager that looks in sources, in order.
+func New(sources ...Source) *Manager {
+	return &Manager{sources: sources, secrets: make(map[string]*Secret)}
+}
+
+var (
+	defaultOnce sync.Once
+	defaultMgr  *Manager
+)
+
+// Default returns the process's Manager, with the sources the
+// environment configures (see the package doc). A source that is
+// misconfigured, such as Vault without a token, fails every lookup that
+// reaches it, so the service says so at startup.
+func Default() *Manager {
+	defaultOnce.Do(func() { defaultMgr = FromEnv() })
+	return defaultMgr
+}
+
+// FromEnv returns a Manager with the sources the environment configures.
+func FromEnv() *Manager {
+	sources := []Source{Env{}, Files{Dir: os.Getenv("SECRETS_DIR")}}
+	if path := os.Getenv("SECRETS_VAULT_PATH"); path != "" {
+		sources = append(sources, NewVault(os.Getenv("VAULT_ADDR"), os.Getenv("VAULT_TOKEN"), path))
+	}
+	if path := os.Getenv("SECRETS_SOPS_FILE"); path != "" {
+		sources = append(sources, NewSOPS(path))
+	}
+	return New(sources...)
+}
+
+// Get looks key up through Default, for programs that read a credential
+// or two without a settings.Loader. A lookup that fails is logged, and
+// the credential treated as unset.
+func Get(key string) string {
+	v, _, err := Default().Lookup(context.Background(), key)
+	if err != nil {
+		slog.Error("look up secret", "err", err)
+	}
+	return v
+}
+
+// Lookup returns key's value and the name of the source it came from, or
+// "" for both if no source has it. It stops at the first source that
+// fails, rather than falling back to a later one that might hold a stale
+// value.
+func (m *Manager) Lookup(ctx context.Context, key string) (value, source string, err error) {
+	for _, s := range m.sources {
+		v, ok, err := s.Lookup(ctx, key)
+		if err != nil {
+			return "", "", fmt.Errorf("%s from %s: %w", key, s.Name(), err)
+		}
+		if ok {
+			return v, s.Name(), nil
+		}
+	}
+	return "", "", nil
+}
+
+// Secret is a credential that may be rotated while the service runs. It
+// is safe for concurrent use.
+type Secret struct {
+	key   string
+	value atomic.Pointer[string]
+
+	mu       sync.Mutex
+	onRotate []func(string)
+}
+
+// Value returns the credential as it stands.
+func (s *Secret) Value() string { return *s.value.Load() }
+
+// OnRotate calls fn with the new value whenever the credential changes.
+func (s *Secret) OnRotate(fn func(value string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onRotate = append(s.onRotate, fn)
+}
+
+// set stores value, and runs the hooks if it changed.
+func (s *Secret) set(value string) {
+	if old := s.value.Swap(&value); old == nil || *old == value {
+		return
+	}
+	s.mu.Lock()
+	hooks := s.onRotate
+	s.mu.Unlock()
+	for _, fn := range hooks {
+		fn(value)
+	}
+}
+
+// Secret looks key up and returns it as a Secret that Refresh and Watch
+// keep current. Asking for one key twice returns the same Secret. A key
+// no source has is an error.
+func (m *Manager) Secret(ctx context.Context, key string) (*Secret, error) {
+	m.mu.Lock()
+	s := m.secrets[key]
+	m.mu.Unlock()
+	if s != nil {
+		return s, nil
+	}
+	v, source, err := m.Lookup(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if source == "" {
+		return nil, fmt.Errorf("%s is not set", key)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s := m.secrets[key]; s != nil {
+		return s, nil
+	}
+	s = &Secret{key: key}
+	s.value.Store(&v)
+	m.secrets[key] = s
+	return s, nil
+}
+
+// Refresh looks every Secret up again, rotating those that changed. A
+// lookup that fails, or finds the credential gone, leaves the Secret as
+// it was; Refresh reports every such problem.
+func (m *Manager) Refresh(ctx context.Context) error {
+	m.mu.Lock()
+	secrets := make([]*Secret, 0, len(m.secrets))
+	for _, s := range m.secrets {
+		secrets = append(secrets, s)
+	}
+	m.mu.Unlock()
+
+	var errs []error
+	for _, s := range secrets {
+		v, source, err := m.Lookup(ctx, s.key)
+		switch {
+		case err != nil:
+			errs = append(errs, err)
+		case source == "":
+			errs = append(errs, fmt.Errorf("%s is no longer set; keeping the last value", s.key))
+		default:
+			if v != s.Value() {
+				slog.Info("secret rotated", "key", s.key, "source", source)
+			}
+			s.set(v)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Watch calls Refresh every interval until ctx is done.
+func (m *Manager) Watch(ctx context.Context, every time.Duration) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		if err := m.Refresh(ctx); err != nil {
+			slog.Error("refresh secrets", "err", err)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package secretsmgr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLookupOrder(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "T_SESSION_SECRET"), []byte("from-dir\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "telnyx")
+	if err := os.WriteFile(keyFile, []byte("from-key-file"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("T_TELNYX_API_KEY_FILE", keyFile)
+	t.Setenv("T_HERMIT_SECRET", "from-env")
+
+	store := fakeStore{"T_HERMIT_SECRET": "from-store", "T_SSO_SECRET": "from-store"}
+	m := New(Env{}, Files{Dir: dir}, store)
+	for key, want := range map[string][2]string{
+		"T_HERMIT_SECRET":  {"from-env", "env"},
+		"T_TELNYX_API_KEY": {"from-key-file", "secret_file"},
+		"T_SESSION_SECRET": {"from-dir", "secret_file"},
+		"T_SSO_SECRET":     {"from-store", "fake"},
+		"T_MISSING":        {"", ""},
+	} {
+		v, source, err := m.Lookup(ctx, key)
+		if err != nil || v != want[0] || source != want[1] {
+			t.Errorf("Lookup(%s) = %q from %q, %v; want %q from %q", key, v, source, err, want[0], want[1])
+		}
+	}
+
+	// A source that fails stops the lookup.
+	down := New(Env{}, failingStore{}, store)
+	if _, _, err := down.Lookup(ctx, "T_SSO_SECRET"); err == nil || !strings.Contains(err.Error(), "T_SSO_SECRET from failing") {
+		t.Errorf("Lookup past a failing source: %v", err)
+	}
+}
+
+type fakeStore map[string]string
+
+func (fakeStore) Name() string { return "fake" }
+
+func (f fakeStore) Lookup(_ context.Context, key string) (string, bool, error) {
+	v, ok := f[key]
+	return v, ok, nil
+}
+
+type failingStore struct{}
+
+func (failingStore) Name() string { return "failing" }
+
+func (failingStore) Lookup(context.Context, string) (string, bool, error) {
+	return "", false, errors.New("connection refused")
+}
+
+func TestRotation(t *testing.T) {
+	ctx := context.Background()
+	store := fakeStore{"KEY": "v1"}
+	m := New(store)
+	s, err := m.Secret(ctx, "KEY")
+	if err != nil || s.Value() != "v1" {
+		t.Fatalf("Secret: %v, %v", s, err)
+	}
+	if again, _ := m.Secret(ctx, "KEY"); again != s {
+		t.Error("asking twice gave two Secrets")
+	}
+	var rotated []string
+	s.OnRotate(func(v string) { rotated = append(rotated, v) })
+
+	if err := m.Refresh(ctx); err != nil || len(rotated) != 0 {
+		t.Errorf("Refresh with nothing changed: %v, hooks ran %v", err, rotated)
+	}
+	store["KEY"] = "v2"
+	if err := m.Refresh(ctx); err != nil || s.Value() != "v2" || len(rotated) != 1 || rotated[0] != "v2" {
+		t.Errorf("Refresh after rotation: %v, value %q, hooks ran %v", err, s.Value(), rotated)
+	}
+
+	// A credential that disappears keeps its last value.
+	delete(store, "KEY")
+	if err := m.Refresh(ctx); err == nil || s.Value() != "v2" {
+		t.Errorf("Refresh with the key gone: %v, value %q", err, s.Value())
+	}
+
+	if _, err := m.Secret(ctx, "OTHER"); err == nil {
+		t.Error("Secret of a key no source has: nil error")
+	}
+}
+
+func TestVault(t *testing.T) {
+	for name, body := range map[string]string{
+		"kv2": `{"data":{"data":{"TELNYX_API_KEY":"key-1","PORT":8080},"metadata":{"version":3}}}`,
+		"kv1": `{"data":{"TELNYX_API_KEY":"key-1","PORT":8080}}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			fetches := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fetches++
+				if r.Header.Get("X-Vault-Token") != "token" || r.URL.Path != "/v1/secret/data/nexus" {
+					http.Error(w, "permission denied", http.StatusForbidden)
+					return
+				}
+				w.Write([]byte(body))
+			}))
+			defer srv.Close()
+
+			v := NewVault(srv.URL, "token", "/secret/data/nexus")
+			ctx := context.Background()
+			if got, ok, err := v.Lookup(ctx, "TELNYX_API_KEY"); got != "key-1" || !ok || err != nil {
+				t.Errorf("Lookup: %q, %v, %v", got, ok, err)
+			}
+			if _, ok, err := v.Lookup(ctx, "SESSION_SECRET"); ok || err != nil {
+				t.Errorf("Lookup of a missing field: %v, %v", ok, err)
+			}
+			if fetches != 1 {
+				t.Errorf("%d fetches for two lookups, want 1", fetches)
+			}
+
+			denied := NewVault(srv.URL, "wrong", "secret/data/nexus")
+			if _, _, err := denied.Lookup(ctx, "TELNYX_API_KEY"); err == nil || !strings.Contains(err.Error(), "403") {
+				t.Errorf("Lookup with the wrong token: %v", err)
+			}
+		})
+	}
+
+	if _, _, err := NewVault("", "", "secret/nexus").Lookup(context.Background(), "K"); err == nil {
+		t.Error("Lookup without VAULT_ADDR: nil error")
+	}
+}
+
+func TestSOPS(t *testing.T) {
+	s := NewSOPS("secrets.enc.yaml")
+	s.decrypt = func(_ context.Context, path string) ([]byte, error) {
+		return []byte("SESSION_SECRET: s3cret\nnested:\n  a: b\n"), nil
+	}
+	if v, ok, err := s.Lookup(context.Background(), "SESSION_SECRET"); v != "s3cret" || !ok || err != nil {
+		t.Errorf("Lookup: %q, %v, %v", v, ok, err)
+	}
+	if _, ok, _ := s.Lookup(context.Background(), "nested"); ok {
+		t.Error("a nested map was taken for a credential")
+	}
+}
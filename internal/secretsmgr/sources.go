@@ -0,0 +1,205 @@
+package secretsmgr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Env looks credentials up in the environment.
+type Env struct{}
+
+func (Env) Name() string { return "env" }
+
+// Lookup implements Source.
+func (Env) Lookup(_ context.Context, key string) (string, bool, error) {
+	v := os.Getenv(key)
+	return v, v != "", nil
+}
+
+// Files reads a credential from the file its KEY_FILE variable names,
+// else from Dir/KEY if Dir is set. Surrounding whitespace, such as the
+// newline editors add, is trimmed.
+type Files struct {
+	Dir string
+}
+
+func (Files) Name() string { return "secret_file" }
+
+// Lookup implements Source.
+func (f Files) Lookup(_ context.Context, key string) (string, bool, error) {
+	path := os.Getenv(key + "_FILE")
+	if path == "" && f.Dir != "" {
+		path = filepath.Join(f.Dir, key)
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return "", false, nil
+		}
+	}
+	if path == "" {
+		return "", false, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, err
+	}
+	v := strings.TrimSpace(string(b))
+	return v, v != "", nil
+}
+
+// cacheFor is how long the stores below keep what they fetched, so a
+// service's startup, which reads several credentials, fetches once.
+const cacheFor = 30 * time.Second
+
+// cached holds a store's credentials for cacheFor.
+type cached struct {
+	fetch func(ctx context.Context) (map[string]string, error)
+
+	mu        sync.Mutex
+	values    map[string]string
+	fetchedAt time.Time
+}
+
+func (c *cached) lookup(ctx context.Context, key string) (string, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.values == nil || time.Since(c.fetchedAt) > cacheFor {
+		values, err := c.fetch(ctx)
+		if err != nil {
+			return "", false, err
+		}
+		c.values, c.fetchedAt = values, time.Now()
+	}
+	v, ok := c.values[key]
+	return v, ok && v != "", nil
+}
+
+// Vault reads credentials from one secret in Vault's KV store, version 1
+// or 2, whose fields are named like the credentials.
+type Vault struct {
+	addr, token, path string
+	client            *http.Client
+	cache             cached
+}
+
+// NewVault returns a Vault that reads the secret at path, such as
+// "secret/data/nexus/sms-sender" (KV version 2) or "secret/nexus" (version
+// 1), from the server at addr with token.
+func NewVault(addr, token, path string) *Vault {
+	v := &Vault{addr: strings.TrimSuffix(addr, "/"), token: token, path: strings.Trim(path, "/"), client: &http.Client{Timeout: 10 * time.Second}}
+	v.cache.fetch = v.fetch
+	return v
+}
+
+func (v *Vault) Name() string { return "vault" }
+
+// Lookup implements Source.
+func (v *Vault) Lookup(ctx context.Context, key string) (string, bool, error) {
+	return v.cache.lookup(ctx, key)
+}
+
+func (v *Vault) fetch(ctx context.Context) (map[string]string, error) {
+	if v.addr == "" || v.token == "" {
+		return nil, errors.New("VAULT_ADDR and VAULT_TOKEN are required with SECRETS_VAULT_PATH")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.addr+"/v1/"+v.path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", v.path, resp.Status)
+	}
+	// Version 2 nests the fields a level deeper, beside the metadata.
+	var out struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("GET %s: %w", v.path, err)
+	}
+	fields := out.Data
+	if inner, ok := fields["data"].(map[string]any); ok {
+		if _, ok := fields["metadata"]; ok {
+			fields = inner
+		}
+	}
+	return stringValues(fields), nil
+}
+
+// SOPS reads credentials from a flat YAML or JSON file encrypted with
+// SOPS, decrypting it with the sops command, which finds its keys (age,
+// PGP, a cloud KMS) the way it always does.
+type SOPS struct {
+	path    string
+	decrypt func(ctx context.Context, path string) ([]byte, error)
+	cache   cached
+}
+
+// NewSOPS returns a SOPS that reads the encrypted file at path.
+func NewSOPS(path string) *SOPS {
+	s := &SOPS{path: path, decrypt: sopsDecrypt}
+	s.cache.fetch = s.fetch
+	return s
+}
+
+func (s *SOPS) Name() string { return "sops" }
+
+// Lookup implements Source.
+func (s *SOPS) Lookup(ctx context.Context, key string) (string, bool, error) {
+	return s.cache.lookup(ctx, key)
+}
+
+func (s *SOPS) fetch(ctx context.Context) (map[string]string, error) {
+	b, err := s.decrypt(ctx, s.path)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	if err := yaml.Unmarshal(b, &fields); err != nil {
+		return nil, fmt.Errorf("%s: %w", s.path, err)
+	}
+	return stringValues(fields), nil
+}
+
+func sopsDecrypt(ctx context.Context, path string) ([]byte, error) {
+	var stderr strings.Builder
+	cmd := exec.CommandContext(ctx, "sops", "--decrypt", path)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("sops --decrypt %s: %w: %s", path, err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// stringValues keeps the fields that are scalars, as strings.
+func stringValues(fields map[string]any) map[string]string {
+	out := make(map[string]string, len(fields))
+	for k, v := range fields {
+		switch v.(type) {
+		case map[string]any, []any, nil:
+		default:
+			out[k] = fmt.Sprint(v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,285 @@
+// Package settings is the configuration loader the nexus services share.
+//
+// A service's config.Load reads each setting through a Loader, by the
+// environment variable that names it, with its default:
+//
+//	l := settings.New("CAL_CONFIG_FILE")
+//	cfg := &Config{
+//		Port:     l.String("CAL_PORT", "8085"),
+//		APIKey:   l.Secret("CAL_API_KEY", ""),
+//		Interval: l.Duration("CAL_REMINDER_INTERVAL", time.Minute),
+//	}
+//	cfg.Settings = l.Values()
+//	return cfg, l.Err()
+//
+// A setting comes from the environment if set there, else from the
+// optional YAML file the fileEnv variable points at — a flat map of the
+// same names, so a file and an .env say the same thing — else from its
+// default. Problems don't stop loading: a malformed value, a missing
+// required setting or a file key nothing reads are all collected, and Err
+// reports every one at once so a misconfigured service says everything
+// that's wrong in its first startup error.
+//
+// Secrets can also come from a file or a secrets store; see secretsmgr.
+//
+// Values remembers what was read from where for logging at boot, with
+// secrets redacted.
+package settings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jredh-dev/nexus/internal/secretsmgr"
+)
+
+// Where a setting's value came from.
+const (
+	FromEnv     = "env"
+	FromFile    = "file"
+	FromDefault = "default"
+)
+
+// Setting is one setting as loaded.
+type Setting struct {
+	Key    string
+	Value  string
+	Source string // FromEnv, FromFile, FromDefault, or for a secret the secretsmgr source
+	Secret bool   // never logged
+}
+
+// Shown returns the value as it may be logged: secrets that are set
+// become "[redacted]".
+func (s Setting) Shown() string {
+	if s.Secret && s.Value != "" {
+		return "[redacted]"
+	}
+	return s.Value
+}
+
+// Values is every setting a Loader read, in the order read.
+type Values []Setting
+
+// LogValue logs the settings as a group, secrets redacted.
+func (v Values) LogValue() slog.Value {
+	attrs := make([]slog.Attr, len(v))
+	for i, s := range v {
+		attrs[i] = slog.String(s.Key, s.Shown())
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// String formats the settings as KEY=value pairs, secrets redacted.
+func (v Values) String() string {
+	parts := make([]string, len(v))
+	for i, s := range v {
+		parts[i] = s.Key + "=" + strconv.Quote(s.Shown())
+	}
+	return strings.Join(parts, " ")
+}
+
+// Loader reads settings from the environment and an optional file.
+type Loader struct {
+	file   map[string]string
+	path   string
+	values Values
+	seen   map[string]bool
+	errs   []error
+}
+
+// New returns a Loader that reads the YAML file named by the fileEnv
+// environment variable, if it is set, under the environment.
+func New(fileEnv string) *Loader {
+	l := &Loader{seen: map[string]bool{}}
+	if fileEnv == "" {
+		return l
+	}
+	path := os.Getenv(fileEnv)
+	if path == "" {
+		return l
+	}
+	l.path = path
+	file, err := readFile(path)
+	if err != nil {
+		l.errs = append(l.errs, fmt.Errorf("%s=%s: %w", fileEnv, path, err))
+		return l
+	}
+	l.file = file
+	return l
+}
+
+// readFile reads a flat YAML map of setting names to scalars or lists.
+func readFile(path string) (map[string]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]any
+	if err := yaml.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+	file := make(map[string]string, len(raw))
+	for k, v := range raw {
+		switch v := v.(type) {
+		case nil:
+			file[k] = ""
+		case []any:
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = fmt.Sprint(item)
+			}
+			file[k] = strings.Join(items, ",")
+		case map[string]any:
+			return nil, fmt.Errorf("%s: settings are a flat map of names to values, not nested", k)
+		default:
+			file[k] = fmt.Sprint(v)
+		}
+	}
+	return file, nil
+}
+
+// lookup returns key's value and where it came from.
+func (l *Loader) lookup(key, def string, secret bool) (string, string) {
+	l.seen[key] = true
+	value, source := def, FromDefault
+	if v := os.Getenv(key); v != "" {
+		value, source = v, FromEnv
+	} else if v, ok := l.file[key]; ok && v != "" {
+		value, source = v, FromFile
+	}
+	l.values = append(l.values, Setting{Key: key, Value: value, Source: source, Secret: secret})
+	return value, source
+}
+
+// String reads a string setting.
+func (l *Loader) String(key, def string) string {
+	v, _ := l.lookup(key, def, false)
+	return v
+}
+
+// Secret reads a string setting that is never logged. Before the file,
+// it is looked for wherever secretsmgr.Default looks: the environment,
+// KEY_FILE, and the configured secrets store.
+func (l *Loader) Secret(key, def string) string {
+	v, source, err := secretsmgr.Default().Lookup(context.Background(), key)
+	if err != nil {
+		l.errs = append(l.errs, err)
+	}
+	if source == "" {
+		v, _ = l.lookup(key, def, true)
+		return v
+	}
+	l.seen[key] = true
+	l.values = append(l.values, Setting{Key: key, Value: v, Source: source, Secret: true})
+	return v
+}
+
+// Required reads a string setting that must be set.
+func (l *Loader) Required(key, what string) string {
+	v, _ := l.lookup(key, "", false)
+	if v == "" {
+		l.errs = append(l.errs, fmt.Errorf("%s is required: %s", key, what))
+	}
+	return v
+}
+
+// Int reads a positive integer setting.
+func (l *Loader) Int(key string, def int) int {
+	v, source := l.lookup(key, strconv.Itoa(def), false)
+	if source == FromDefault {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		l.errs = append(l.errs, fmt.Errorf("%s=%q: want a positive whole number", key, v))
+		return def
+	}
+	return n
+}
+
+// Duration reads a positive duration setting, written like 90s or 5m.
+func (l *Loader) Duration(key string, def time.Duration) time.Duration {
+	v, source := l.lookup(key, def.String(), false)
+	if source == FromDefault {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		l.errs = append(l.errs, fmt.Errorf("%s=%q: want a positive duration like 90s, 5m or 24h", key, v))
+		return def
+	}
+	return d
+}
+
+// List reads a comma-separated list setting, dropping empty entries. In
+// the file it may also be a YAML list.
+func (l *Loader) List(key string) []string {
+	v, _ := l.lookup(key, "", false)
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// OneOf reads a string setting, lowercased, that must be one of allowed.
+func (l *Loader) OneOf(key, def string, allowed ...string) string {
+	v, _ := l.lookup(key, def, false)
+	v = strings.ToLower(v)
+	for _, a := range allowed {
+		if v == a {
+			return v
+		}
+	}
+	l.errs = append(l.errs, fmt.Errorf("%s=%q: want one of %s", key, v, strings.Join(allowed, ", ")))
+	return def
+}
+
+// Check records a problem that involves more than one setting, such as
+// one that is required only when another is set, unless ok.
+func (l *Loader) Check(ok bool, format string, args ...any) {
+	if !ok {
+		l.errs = append(l.errs, fmt.Errorf(format, args...))
+	}
+}
+
+// Values returns the settings read so far.
+func (l *Loader) Values() Values {
+	return l.values
+}
+
+// Err returns every problem found loading the settings, or nil. Names in
+// the file that nothing read count as problems, since they are usually
+// typos.
+func (l *Loader) Err() error {
+	errs := l.errs
+	var unknown []string
+	for k := range l.file {
+		if !l.seen[k] {
+			unknown = append(unknown, k)
+		}
+	}
+	sort.Strings(unknown)
+	for _, k := range unknown {
+		errs = append(errs, fmt.Errorf("%s: %s is not a setting", l.path, k))
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return errors.New("invalid configuration:\n  " + strings.Join(msgs, "\n  "))
+}
@@ -0,0 +1,126 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "svc.yaml")
+	file := "T_PORT: 9000\nT_HOST: file-host\nT_BROKERS: [a, b]\nT_WAIT: 90s\n"
+	if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("T_CONFIG_FILE", path)
+	t.Setenv("T_HOST", "env-host")
+
+	l := New("T_CONFIG_FILE")
+	if got := l.String("T_HOST", "default-host"); got != "env-host" {
+		t.Errorf("T_HOST = %q, want the environment's", got)
+	}
+	if got := l.Int("T_PORT", 8080); got != 9000 {
+		t.Errorf("T_PORT = %d, want the file's", got)
+	}
+	if got := l.String("T_NAME", "default-name"); got != "default-name" {
+		t.Errorf("T_NAME = %q, want the default", got)
+	}
+	if got := l.List("T_BROKERS"); strings.Join(got, "|") != "a|b" {
+		t.Errorf("T_BROKERS = %q, want the file's list", got)
+	}
+	if got := l.Duration("T_WAIT", time.Second); got != 90*time.Second {
+		t.Errorf("T_WAIT = %s, want 90s", got)
+	}
+	if err := l.Err(); err != nil {
+		t.Errorf("Err: %v", err)
+	}
+
+	sources := map[string]string{}
+	for _, s := range l.Values() {
+		sources[s.Key] = s.Source
+	}
+	want := map[string]string{"T_HOST": FromEnv, "T_PORT": FromFile, "T_NAME": FromDefault}
+	for k, v := range want {
+		if sources[k] != v {
+			t.Errorf("%s came from %q, want %q", k, sources[k], v)
+		}
+	}
+}
+
+func TestErrorsCollected(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "svc.yaml")
+	if err := os.WriteFile(path, []byte("T_PROT: 9000\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("T_CONFIG_FILE", path)
+	t.Setenv("T_WAIT", "soon")
+	t.Setenv("T_COUNT", "-1")
+	t.Setenv("T_MODE", "Fast")
+
+	l := New("T_CONFIG_FILE")
+	if got := l.Duration("T_WAIT", time.Minute); got != time.Minute {
+		t.Errorf("bad T_WAIT = %s, want the default", got)
+	}
+	l.Int("T_COUNT", 3)
+	l.OneOf("T_MODE", "slow", "slow", "careful")
+	l.Required("T_TOKEN", "the API token")
+	l.Check(false, "T_A is required with T_B")
+
+	err := l.Err()
+	if err == nil {
+		t.Fatal("Err = nil, want every problem")
+	}
+	for _, want := range []string{
+		`T_WAIT="soon"`, `T_COUNT="-1"`, `T_MODE="fast": want one of slow, careful`,
+		"T_TOKEN is required: the API token", "T_A is required with T_B", "T_PROT is not a setting",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error missing %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestRedaction(t *testing.T) {
+	t.Setenv("T_KEY", "hunter2")
+	l := New("")
+	l.String("T_PORT", "8080")
+	l.Secret("T_KEY", "")
+	l.Secret("T_UNSET", "")
+
+	s := l.Values().String()
+	if strings.Contains(s, "hunter2") {
+		t.Errorf("secret logged: %s", s)
+	}
+	if want := `T_PORT="8080" T_KEY="[redacted]" T_UNSET=""`; s != want {
+		t.Errorf("String() = %s, want %s", s, want)
+	}
+	for _, a := range l.Values().LogValue().Group() {
+		if a.Value.String() == "hunter2" {
+			t.Errorf("secret in LogValue: %s", a)
+		}
+	}
+}
+
+func TestSecretFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session_secret")
+	if err := os.WriteFile(path, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("T_SESSION_SECRET_FILE", path)
+	l := New("")
+	if v := l.Secret("T_SESSION_SECRET", ""); v != "s3cret" {
+		t.Errorf("Secret from T_SESSION_SECRET_FILE: %q", v)
+	}
+	if s := l.Values()[0]; s.Source != "secret_file" || s.Shown() != "[redacted]" {
+		t.Errorf("setting: %+v", s)
+	}
+
+	t.Setenv("T_SESSION_SECRET_FILE", filepath.Join(t.TempDir(), "missing"))
+	l = New("")
+	l.Secret("T_SESSION_SECRET", "")
+	if err := l.Err(); err == nil || !strings.Contains(err.Error(), "T_SESSION_SECRET") {
+		t.Errorf("Err with a missing secret file: %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+// Package smsinbox publishes inbound SMS to the sms-inbox Kafka topic.
+//
+// sms-sender receives texts from the SMS provider's webhooks and publishes
+// each as an InboundMessage, so consumers (the assistant, deadman check-ins,
+// ...) never talk to a provider directly. It is the mirror of smsoutbox.
+// Messages are keyed by sender so every text from one number lands on the
+// same partition and is read in order.
+package smsinbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Topic is the default Kafka topic for inbound SMS.
+const Topic = "sms-inbox"
+
+// InboundMessage is the JSON payload carried on the sms-inbox topic.
+type InboundMessage struct {
+	// ID is the provider's message ID. Providers redeliver webhooks, so
+	// consumers should treat a repeated ID as the same message.
+	ID string `json:"id"`
+	// From is the sender in E.164 format.
+	From string `json:"from"`
+	// To is our number the text was sent to, in E.164 format.
+	To string `json:"to"`
+	// Body is the message text.
+	Body string `json:"body"`
+	// Media lists the URLs of any MMS attachments.
+	Media []string `json:"media,omitempty"`
+	// Provider names the SMS provider that delivered it (e.g. "telnyx").
+	Provider string `json:"provider"`
+	// ReceivedAt is when the provider received the message.
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+// Validate checks that the message has the fields consumers rely on.
+func (m InboundMessage) Validate() error {
+	switch {
+	case m.ID == "":
+		return fmt.Errorf("inbound message: id is required")
+	case m.From == "":
+		return fmt.Errorf("inbound message: from is required")
+	}
+	return nil
+}
+
+// Publisher hands inbound messages to their consumers.
+// Implementations must be safe for concurrent use.
+type Publisher interface {
+	Publish(ctx context.Context, msg InboundMessage) error
+}
+
+// KafkaPublisher publishes to a Kafka topic.
+type KafkaPublisher struct {
+	w *kafka.Writer
+}
+
+// NewKafkaPublisher creates a publisher writing to topic on the given brokers.
+func NewKafkaPublisher(brokers []string, topic string) *KafkaPublisher {
+	return &KafkaPublisher{w: &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		BatchTimeout: 10 * time.Millisecond,
+	}}
+}
+
+// Publish writes msg synchronously, returning once the brokers acknowledge it.
+func (p *KafkaPublisher) Publish(ctx context.Context, msg InboundMessage) error {
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+	value, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("encode inbound message: %w", err)
+	}
+	if err := p.w.WriteMessages(ctx, kafka.Message{Key: []byte(msg.From), Value: value}); err != nil {
+		return fmt.Errorf("publish to %s: %w", p.w.Topic, err)
+	}
+	return nil
+}
+
+// Close flushes pending writes and closes the underlying writer.
+func (p *KafkaPublisher) Close() error {
+	return p.w.Close()
+}
@@ -0,0 +1,97 @@
+// Package smsoutbox publishes outbound SMS to the sms-outbox Kafka topic.
+//
+// Producers (cal reminders, portal verification codes, ...) never talk to an
+// SMS provider directly. They publish an OutboundMessage and the sms-sender
+// consumer delivers it, retrying and dead-lettering as needed. Messages are
+// keyed by recipient so every message to one number lands on the same
+// partition and is delivered in order.
+package smsoutbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Topic is the default Kafka topic for outbound SMS.
+const Topic = "sms-outbox"
+
+// OutboundMessage is the JSON payload carried on the sms-outbox topic.
+type OutboundMessage struct {
+	// ID identifies the message end to end. Producers should derive it from
+	// the triggering record so a republished message keeps the same ID.
+	ID string `json:"id"`
+	// To is the recipient in E.164 format.
+	To string `json:"to"`
+	// Body is the message text. It may be left empty if Template is set.
+	Body string `json:"body"`
+	// Template names a template sms-sender renders into Body, filling in
+	// Vars; a message with a template doesn't need a body.
+	Template string `json:"template,omitempty"`
+	// Vars are the template's variables.
+	Vars map[string]string `json:"vars,omitempty"`
+	// Source names the producing service (e.g. "cal").
+	Source string `json:"source"`
+	// CreatedAt is when the producer created the message.
+	CreatedAt time.Time `json:"created_at"`
+	// SendAt, if set and in the future, holds the message until then.
+	SendAt time.Time `json:"send_at,omitzero"`
+}
+
+// Validate checks that the message has the fields the sender requires.
+func (m OutboundMessage) Validate() error {
+	switch {
+	case m.ID == "":
+		return fmt.Errorf("outbound message: id is required")
+	case m.To == "":
+		return fmt.Errorf("outbound message: to is required")
+	case m.Body == "" && m.Template == "":
+		return fmt.Errorf("outbound message: body or template is required")
+	}
+	return nil
+}
+
+// Publisher hands outbound messages to the SMS pipeline.
+// Implementations must be safe for concurrent use.
+type Publisher interface {
+	Publish(ctx context.Context, msg OutboundMessage) error
+}
+
+// KafkaPublisher publishes to a Kafka topic.
+type KafkaPublisher struct {
+	w *kafka.Writer
+}
+
+// NewKafkaPublisher creates a publisher writing to topic on the given brokers.
+func NewKafkaPublisher(brokers []string, topic string) *KafkaPublisher {
+	return &KafkaPublisher{w: &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		BatchTimeout: 10 * time.Millisecond,
+	}}
+}
+
+// Publish writes msg synchronously, returning once the brokers acknowledge it.
+func (p *KafkaPublisher) Publish(ctx context.Context, msg OutboundMessage) error {
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+	value, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("encode outbound message: %w", err)
+	}
+	if err := p.w.WriteMessages(ctx, kafka.Message{Key: []byte(msg.To), Value: value}); err != nil {
+		return fmt.Errorf("publish to %s: %w", p.w.Topic, err)
+	}
+	return nil
+}
+
+// Close flushes pending writes and closes the underlying writer.
+func (p *KafkaPublisher) Close() error {
+	return p.w.Close()
+}
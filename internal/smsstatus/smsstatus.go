@@ -0,0 +1,115 @@
+// Package smsstatus publishes the final delivery status of outbound SMS to
+// the sms-status Kafka topic.
+//
+// sms-sender records each text it hands to the provider, and when the
+// provider reports the text delivered or failed it publishes a Status, so
+// producers can check that what they put on sms-outbox reached its
+// recipient. Messages are keyed by the OutboundMessage ID so every status
+// for one message lands on the same partition.
+package smsstatus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Topic is the default Kafka topic for delivery statuses.
+const Topic = "sms-status"
+
+// Final states.
+const (
+	StateDelivered = "delivered" // the carrier confirmed delivery
+	StateFailed    = "failed"    // the provider or carrier gave up
+	// StateUnconfirmed means the provider sent the text but the carrier
+	// never said whether it arrived; some carriers don't report receipts.
+	StateUnconfirmed = "unconfirmed"
+	// StateOptedOut means sms-sender didn't send the text because the
+	// recipient had texted STOP. It never reached a provider.
+	StateOptedOut = "opted_out"
+)
+
+// Status is the JSON payload carried on the sms-status topic.
+type Status struct {
+	// ID is the OutboundMessage ID, or empty if sms-sender has no record
+	// of sending the provider's message.
+	ID string `json:"id"`
+	// ProviderID is the provider's ID for the message; empty if it was
+	// never sent.
+	ProviderID string `json:"provider_id,omitempty"`
+	// To is the recipient in E.164 format.
+	To string `json:"to"`
+	// Provider names the SMS provider that reported it (e.g. "telnyx").
+	Provider string `json:"provider,omitempty"`
+	// State is one of the final states above.
+	State string `json:"state"`
+	// Error says why the message failed, if the provider said.
+	Error string `json:"error,omitempty"`
+	// At is when the message reached the final state.
+	At time.Time `json:"at"`
+}
+
+// Validate checks that the status has the fields consumers rely on.
+func (s Status) Validate() error {
+	switch {
+	case s.State == StateOptedOut:
+		if s.ID == "" {
+			return fmt.Errorf("sms status: id is required")
+		}
+	case s.State != StateDelivered && s.State != StateFailed && s.State != StateUnconfirmed:
+		return fmt.Errorf("sms status: unknown state %q", s.State)
+	case s.ProviderID == "":
+		return fmt.Errorf("sms status: provider_id is required")
+	}
+	return nil
+}
+
+// Publisher hands statuses to their consumers.
+// Implementations must be safe for concurrent use.
+type Publisher interface {
+	Publish(ctx context.Context, s Status) error
+}
+
+// KafkaPublisher publishes to a Kafka topic.
+type KafkaPublisher struct {
+	w *kafka.Writer
+}
+
+// NewKafkaPublisher creates a publisher writing to topic on the given brokers.
+func NewKafkaPublisher(brokers []string, topic string) *KafkaPublisher {
+	return &KafkaPublisher{w: &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		BatchTimeout: 10 * time.Millisecond,
+	}}
+}
+
+// Publish writes s synchronously, returning once the brokers acknowledge it.
+// A status sms-sender couldn't correlate is keyed by its provider ID.
+func (p *KafkaPublisher) Publish(ctx context.Context, s Status) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	value, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("encode sms status: %w", err)
+	}
+	key := s.ID
+	if key == "" {
+		key = s.ProviderID
+	}
+	if err := p.w.WriteMessages(ctx, kafka.Message{Key: []byte(key), Value: value}); err != nil {
+		return fmt.Errorf("publish to %s: %w", p.w.Topic, err)
+	}
+	return nil
+}
+
+// Close flushes pending writes and closes the underlying writer.
+func (p *KafkaPublisher) Close() error {
+	return p.w.Close()
+}
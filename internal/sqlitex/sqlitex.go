@@ -0,0 +1,225 @@
+// Package sqlitex opens the nexus services' SQLite databases and looks
+// after them while they run.
+//
+//	conn, err := sqlitex.Open(path)
+//	...
+//	httpserver.WithWorker("wal checkpoints", func(ctx context.Context) {
+//		sqlitex.RunCheckpoints(ctx, conn, sqlitex.CheckpointEvery)
+//	})
+//
+// Every database is opened the same way: a write-ahead log, so readers
+// don't wait on the writer; a busy timeout, so a writer waits its turn
+// rather than failing; foreign keys enforced; and transactions that take
+// the write lock as they begin, so a transaction never fails halfway
+// because another writer got there first. SQLite allows one writer at a
+// time whatever the pool size; WithMaxConns(1) also makes readers queue
+// behind it, for services that would rather not hold several connections.
+package sqlitex
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// CheckpointEvery is how often services fold their write-ahead log back
+// into the database.
+const CheckpointEvery = 5 * time.Minute
+
+// BusyTimeout is how long a statement waits for another connection's
+// lock before failing with SQLITE_BUSY.
+const BusyTimeout = 5 * time.Second
+
+// pragmas are set on every connection.
+var pragmas = []string{
+	"journal_mode(WAL)",
+	fmt.Sprintf("busy_timeout(%d)", BusyTimeout.Milliseconds()),
+	"foreign_keys(1)",
+	// Safe with a write-ahead log: a power cut can lose the last
+	// commits, never corrupt the database.
+	"synchronous(NORMAL)",
+}
+
+type options struct {
+	maxConns int
+}
+
+// Option configures Open.
+type Option func(*options)
+
+// WithMaxConns limits the pool to n connections.
+func WithMaxConns(n int) Option {
+	return func(o *options) { o.maxConns = n }
+}
+
+// Open opens, creating it if need be, the SQLite database at path, and
+// checks that it answers.
+func Open(path string, opts ...Option) (*sql.DB, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	// Each connection to :memory: is a database of its own.
+	if path == ":memory:" {
+		o.maxConns = 1
+	}
+
+	dsn := path + "?_txlock=immediate&_pragma=" + strings.Join(pragmas, "&_pragma=")
+	conn, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	if o.maxConns > 0 {
+		conn.SetMaxOpenConns(o.maxConns)
+	}
+	if err := conn.Ping(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	return conn, nil
+}
+
+// IsBusy reports whether err is SQLite saying the database, or a table in
+// it, is locked by another connection.
+func IsBusy(err error) bool {
+	var e *sqlite.Error
+	if !errors.As(err, &e) {
+		return false
+	}
+	code := e.Code() & 0xff // the primary code, without its extension
+	return code == sqlite3.SQLITE_BUSY || code == sqlite3.SQLITE_LOCKED
+}
+
+// Retry calls fn until it returns something other than a busy error, or
+// ctx is done, waiting a little longer before each try. The busy timeout
+// covers most contention; Retry is for work that can still lose to
+// another writer, such as a transaction begun outside this package's
+// connections.
+func Retry(ctx context.Context, fn func() error) error {
+	wait := 10 * time.Millisecond
+	for {
+		err := fn()
+		if !IsBusy(err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(wait):
+		}
+		wait = min(2*wait, time.Second)
+	}
+}
+
+// Checkpoint copies the write-ahead log into the database and truncates
+// it. Readers and writers keep going meanwhile; if they keep part of the
+// log in use, the checkpoint is left incomplete and Checkpoint says so.
+func Checkpoint(ctx context.Context, conn *sql.DB) error {
+	var busy, logFrames, checkpointed int
+	err := conn.QueryRowContext(ctx, `PRAGMA wal_checkpoint(TRUNCATE)`).Scan(&busy, &logFrames, &checkpointed)
+	if err != nil {
+		return fmt.Errorf("checkpoint: %w", err)
+	}
+	if busy != 0 {
+		return fmt.Errorf("checkpoint: database busy; %d of %d log frames copied", checkpointed, logFrames)
+	}
+	return nil
+}
+
+// RunCheckpoints checkpoints conn every interval until ctx is done, then
+// once more, so a service that stops leaves an empty log behind.
+// Failures are logged; the next checkpoint tries again.
+func RunCheckpoints(ctx context.Context, conn *sql.DB, every time.Duration) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if err := Checkpoint(context.Background(), conn); err != nil {
+				slog.Error("final wal checkpoint", "err", err)
+			}
+			return
+		case <-t.C:
+			if err := Checkpoint(ctx, conn); err != nil {
+				slog.Warn("wal checkpoint", "err", err)
+			}
+		}
+	}
+}
+
+// IntegrityCheck reads the whole database looking for corruption, and
+// for rows whose foreign keys point nowhere. It reports every problem it
+// finds. On a large database it takes a while.
+func IntegrityCheck(ctx context.Context, conn *sql.DB) error {
+	var problems []string
+	rows, err := conn.QueryContext(ctx, `PRAGMA integrity_check`)
+	if err != nil {
+		return fmt.Errorf("integrity check: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var msg string
+		if err := rows.Scan(&msg); err != nil {
+			return fmt.Errorf("integrity check: %w", err)
+		}
+		if msg != "ok" {
+			problems = append(problems, msg)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("integrity check: %w", err)
+	}
+
+	fks, err := conn.QueryContext(ctx, `PRAGMA foreign_key_check`)
+	if err != nil {
+		return fmt.Errorf("foreign key check: %w", err)
+	}
+	defer fks.Close()
+	for fks.Next() {
+		var (
+			table, parent string
+			rowid         sql.NullInt64
+			fk            int
+		)
+		if err := fks.Scan(&table, &rowid, &parent, &fk); err != nil {
+			return fmt.Errorf("foreign key check: %w", err)
+		}
+		problems = append(problems, fmt.Sprintf("%s row %d refers to a missing %s", table, rowid.Int64, parent))
+	}
+	if err := fks.Err(); err != nil {
+		return fmt.Errorf("foreign key check: %w", err)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("integrity check: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Backup writes a consistent copy of the database to dest with VACUUM
+// INTO, while it stays in use. The copy is written beside dest and
+// renamed into place, so dest is never half written; an existing dest is
+// replaced.
+func Backup(ctx context.Context, conn *sql.DB, dest string) error {
+	tmp := dest + ".tmp"
+	if err := os.Remove(tmp); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("backup: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, `VACUUM INTO ?`, tmp); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("backup to %s: %w", dest, err)
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("backup: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package sqlitex
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOpen(t *testing.T) {
+	conn, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	for pragma, want := range map[string]string{
+		"journal_mode": "wal",
+		"busy_timeout": "5000",
+		"foreign_keys": "1",
+		"synchronous":  "1", // NORMAL
+	} {
+		var got string
+		if err := conn.QueryRow("PRAGMA " + pragma).Scan(&got); err != nil || got != want {
+			t.Errorf("PRAGMA %s = %q, %v; want %q", pragma, got, err, want)
+		}
+	}
+
+	if _, err := Open(filepath.Join(t.TempDir(), "missing", "test.db")); err == nil {
+		t.Error("Open in a missing directory: nil error")
+	}
+}
+
+func TestBusy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	a, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	b, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	if _, err := a.Exec(`CREATE TABLE t (n INTEGER)`); err != nil {
+		t.Fatal(err)
+	}
+	b.Exec(`PRAGMA busy_timeout = 0`) //nolint:errcheck
+
+	// a holds the write lock, so b's transaction can't begin until a's
+	// ends.
+	tx, err := a.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.SetMaxOpenConns(1)
+	_, err = b.Exec(`INSERT INTO t VALUES (1)`)
+	if !IsBusy(err) {
+		t.Fatalf("write while another transaction holds the lock: %v, want busy", err)
+	}
+
+	time.AfterFunc(50*time.Millisecond, func() { tx.Commit() }) //nolint:errcheck
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	tries := 0
+	err = Retry(ctx, func() error {
+		tries++
+		_, err := b.Exec(`INSERT INTO t VALUES (1)`)
+		return err
+	})
+	if err != nil || tries < 2 {
+		t.Errorf("Retry: %v after %d tries; want success after a busy try", err, tries)
+	}
+
+	if IsBusy(errors.New("database is locked")) {
+		t.Error("IsBusy of a plain error: true")
+	}
+}
+
+func TestMaintenance(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	conn, err := Open(filepath.Join(dir, "test.db"), WithMaxConns(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	for _, q := range []string{
+		`CREATE TABLE parents (id INTEGER PRIMARY KEY)`,
+		`CREATE TABLE children (parent_id INTEGER REFERENCES parents(id))`,
+		`INSERT INTO parents VALUES (1)`,
+		`INSERT INTO children VALUES (1)`,
+	} {
+		if _, err := conn.Exec(q); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := Checkpoint(ctx, conn); err != nil {
+		t.Errorf("Checkpoint: %v", err)
+	}
+	if info, err := os.Stat(filepath.Join(dir, "test.db-wal")); err != nil || info.Size() != 0 {
+		t.Errorf("log after checkpoint: %v, %v; want it empty", info, err)
+	}
+
+	if err := IntegrityCheck(ctx, conn); err != nil {
+		t.Errorf("IntegrityCheck: %v", err)
+	}
+	conn.Exec(`PRAGMA foreign_keys = 0`)         //nolint:errcheck
+	conn.Exec(`INSERT INTO children VALUES (2)`) //nolint:errcheck
+	if err := IntegrityCheck(ctx, conn); err == nil || !strings.Contains(err.Error(), "children row 2 refers to a missing parents") {
+		t.Errorf("IntegrityCheck with an orphan: %v", err)
+	}
+
+	dest := filepath.Join(dir, "backup.db")
+	for i := 0; i < 2; i++ { // the second replaces the first
+		if err := Backup(ctx, conn, dest); err != nil {
+			t.Fatalf("Backup %d: %v", i+1, err)
+		}
+	}
+	backup, err := Open(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backup.Close()
+	var n int
+	if err := backup.QueryRow(`SELECT count(*) FROM children`).Scan(&n); err != nil || n != 2 {
+		t.Errorf("children in the backup: %d, %v; want 2", n, err)
+	}
+}
@@ -0,0 +1,240 @@
+// Package sso lets every nexus service accept a portal login, so one
+// sign-in covers the whole nexus.
+//
+// A client proves who it is with either credential the portal hands out:
+//
+//   - its session cookie ("session"), which the portal checks when a
+//     service holding its introspection key asks at
+//     POST /api/auth/introspect (RFC 7662 style);
+//   - a signed JWT from POST /api/auth/token, sent as
+//     "Authorization: Bearer <jwt>", which a service holding the shared
+//     secret checks itself and any other asks the portal about.
+//
+// A service makes a Verifier and puts its Middleware in front of the
+// routes that care who is calling; handlers then read the caller from
+// FromContext:
+//
+//	v := sso.NewVerifier(cfg.PortalURL, cfg.IntrospectKey, []byte(cfg.SSOSecret))
+//	r.With(v.Middleware).Post("/api/things", h.Create)
+//	if id := sso.FromContext(r.Context()); id != nil { ... id.UserID ... }
+package sso
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jredh-dev/nexus/internal/logging"
+)
+
+// IntrospectPath is where the portal answers whether a credential is valid.
+const IntrospectPath = "/api/auth/introspect"
+
+// CacheTTL is how long a Verifier trusts the portal's answer about a
+// session, so a logout takes up to this long to reach other services.
+const CacheTTL = 30 * time.Second
+
+// NegativeCacheTTL is how long a Verifier remembers that the portal turned
+// a credential down, so a client repeating a bad cookie doesn't cost the
+// portal a call per request.
+const NegativeCacheTTL = 5 * time.Second
+
+// maxCached bounds the cache, which clients fill with whatever credentials
+// they send. Past it, rejections are no longer remembered.
+const maxCached = 10000
+
+// SessionCookie is the name of the portal's session cookie.
+const SessionCookie = "session"
+
+// Identity is a portal user as the other services see them.
+type Identity struct {
+	UserID   string `json:"sub"`
+	Username string `json:"username,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Role     string `json:"role,omitempty"`
+	Expires  int64  `json:"exp"` // Unix seconds
+}
+
+// Introspection is the portal's answer about a credential: Active and the
+// identity it belongs to, or Active false and nothing else.
+type Introspection struct {
+	Active bool `json:"active"`
+	*Identity
+}
+
+type cached struct {
+	id    *Identity // nil if the portal turned the credential down
+	until time.Time
+}
+
+// Verifier checks portal credentials. A nil *Verifier is valid and
+// recognises none, so services need not check whether SSO is configured.
+type Verifier struct {
+	portalURL string
+	portalKey string
+	secret    []byte
+	client    *http.Client
+	now       func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cached
+}
+
+// NewVerifier returns a Verifier that asks the portal at portalURL about
+// sessions, and about JWTs unless secret, the key the portal signs them
+// with, is set. portalKey is the portal's SSO_INTROSPECT_KEY, which it
+// answers only to. It returns nil if portalURL and secret are both empty.
+func NewVerifier(portalURL, portalKey string, secret []byte) *Verifier {
+	if portalURL == "" && len(secret) == 0 {
+		return nil
+	}
+	return &Verifier{
+		portalURL: strings.TrimSuffix(portalURL, "/"),
+		portalKey: portalKey,
+		secret:    secret,
+		client:    &http.Client{Timeout: 5 * time.Second},
+		now:       time.Now,
+		cache:     make(map[string]cached),
+	}
+}
+
+// Credential returns the portal credential r carries: a bearer JWT, else
+// the session cookie, else "".
+func Credential(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		if tok := strings.TrimPrefix(auth, "Bearer "); IsJWT(tok) {
+			return tok
+		}
+	}
+	if c, err := r.Cookie(SessionCookie); err == nil {
+		return c.Value
+	}
+	return ""
+}
+
+// Verify returns the identity a credential belongs to. It returns
+// ErrInvalidToken if the credential is no good, and another error if the
+// portal couldn't be asked.
+func (v *Verifier) Verify(ctx context.Context, token string) (*Identity, error) {
+	if v == nil || token == "" {
+		return nil, ErrInvalidToken
+	}
+	if IsJWT(token) && len(v.secret) > 0 {
+		return Verify(v.secret, token, v.now())
+	}
+	if v.portalURL == "" {
+		return nil, ErrInvalidToken
+	}
+
+	now := v.now()
+	v.mu.Lock()
+	c, ok := v.cache[token]
+	v.mu.Unlock()
+	if ok && now.Before(c.until) {
+		if c.id == nil {
+			return nil, ErrInvalidToken
+		}
+		return c.id, nil
+	}
+
+	id, err := v.introspect(ctx, token)
+	var until time.Time
+	switch {
+	case err == nil:
+		until = now.Add(CacheTTL)
+		if exp := time.Unix(id.Expires, 0); exp.Before(until) {
+			until = exp
+		}
+	case errors.Is(err, ErrInvalidToken):
+		until = now.Add(NegativeCacheTTL)
+	default:
+		return nil, err
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for k, c := range v.cache {
+		if !now.Before(c.until) {
+			delete(v.cache, k)
+		}
+	}
+	if id != nil || len(v.cache) < maxCached {
+		v.cache[token] = cached{id, until}
+	}
+	return id, err
+}
+
+func (v *Verifier) introspect(ctx context.Context, token string) (*Identity, error) {
+	form := url.Values{"token": {token}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.portalURL+IntrospectPath, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if v.portalKey != "" {
+		req.Header.Set("Authorization", "Bearer "+v.portalKey)
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("introspect: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("introspect: portal answered %s", resp.Status)
+	}
+	var in Introspection
+	if err := json.NewDecoder(resp.Body).Decode(&in); err != nil {
+		return nil, fmt.Errorf("introspect: %w", err)
+	}
+	if !in.Active || in.Identity == nil || in.UserID == "" {
+		return nil, ErrInvalidToken
+	}
+	return in.Identity, nil
+}
+
+type ctxKey struct{}
+
+// FromContext returns the portal user a request was made by, or nil.
+func FromContext(ctx context.Context) *Identity {
+	id, _ := ctx.Value(ctxKey{}).(*Identity)
+	return id
+}
+
+// NewContext returns a copy of ctx carrying id, for tests and for
+// services that authenticate portal users some other way.
+func NewContext(ctx context.Context, id *Identity) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// Middleware puts the portal user of requests carrying a valid credential
+// in their context and their ID on their log lines. It turns nobody away:
+// other requests go through anonymously, and it is up to the service what
+// they may do.
+func (v *Verifier) Middleware(next http.Handler) http.Handler {
+	if v == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tok := Credential(r)
+		if tok == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		id, err := v.Verify(r.Context(), tok)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidToken) {
+				logging.FromContext(r.Context()).Warn("check portal login", "err", err)
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		logging.SetUser(r.Context(), id.UserID)
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), id)))
+	})
+}
@@ -0,0 +1,140 @@
+package sso
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	secret := []byte("k")
+	now := time.Unix(1_700_000_000, 0)
+	id := Identity{UserID: "u1", Username: "ann", Role: "user", Expires: now.Add(time.Hour).Unix()}
+	tok, err := Sign(secret, id, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsJWT(tok) {
+		t.Errorf("IsJWT(%q) = false", tok)
+	}
+
+	got, err := Verify(secret, tok, now)
+	if err != nil || *got != id {
+		t.Fatalf("Verify: %+v, %v; want %+v", got, err, id)
+	}
+
+	parts := strings.Split(tok, ".")
+	for _, tc := range []struct {
+		name   string
+		secret []byte
+		token  string
+		at     time.Time
+	}{
+		{"wrong secret", []byte("other"), tok, now},
+		{"expired", secret, tok, now.Add(time.Hour)},
+		{"tampered", secret, parts[0] + "." + parts[1] + "x." + parts[2], now},
+		{"alg none", secret, "eyJhbGciOiJub25lIn0." + parts[1] + ".", now},
+	} {
+		if _, err := Verify(tc.secret, tc.token, tc.at); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: %v, want ErrInvalidToken", tc.name, err)
+		}
+	}
+}
+
+// fakePortal answers introspection for the session "good", to callers
+// with the key "k".
+func fakePortal(t *testing.T, calls *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != IntrospectPath || r.Method != http.MethodPost {
+			t.Errorf("portal got %s %s", r.Method, r.URL.Path)
+		}
+		*calls++
+		if r.Header.Get("Authorization") != "Bearer k" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var in Introspection
+		if r.PostFormValue("token") == "good" {
+			in = Introspection{Active: true, Identity: &Identity{UserID: "u1", Expires: time.Now().Add(time.Hour).Unix()}}
+		}
+		json.NewEncoder(w).Encode(in) //nolint:errcheck
+	}))
+}
+
+func TestVerifierIntrospects(t *testing.T) {
+	var calls int
+	portal := fakePortal(t, &calls)
+	defer portal.Close()
+	v := NewVerifier(portal.URL, "k", nil)
+
+	for i := 0; i < 2; i++ {
+		id, err := v.Verify(context.Background(), "good")
+		if err != nil || id.UserID != "u1" {
+			t.Fatalf("Verify(good): %+v, %v", id, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("portal asked %d times, want 1: the answer is cached", calls)
+	}
+
+	// Rejections are remembered too, for a shorter while.
+	calls = 0
+	for i := 0; i < 2; i++ {
+		if _, err := v.Verify(context.Background(), "bad"); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Verify(bad): %v, want ErrInvalidToken", err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("portal asked about a bad session %d times, want 1", calls)
+	}
+	v.now = func() time.Time { return time.Now().Add(NegativeCacheTTL) }
+	if _, err := v.Verify(context.Background(), "bad"); !errors.Is(err, ErrInvalidToken) || calls != 2 {
+		t.Errorf("Verify(bad) after NegativeCacheTTL: %v after %d calls, want ErrInvalidToken after 2", err, calls)
+	}
+
+	// A refused key isn't the credential's fault.
+	if _, err := NewVerifier(portal.URL, "wrong", nil).Verify(context.Background(), "good"); err == nil || errors.Is(err, ErrInvalidToken) {
+		t.Errorf("wrong key: %v, want an error other than ErrInvalidToken", err)
+	}
+
+	portal.Close()
+	if _, err := v.Verify(context.Background(), "other"); err == nil || errors.Is(err, ErrInvalidToken) {
+		t.Errorf("portal down: %v, want a transport error", err)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var calls int
+	portal := fakePortal(t, &calls)
+	defer portal.Close()
+
+	var got *Identity
+	h := NewVerifier(portal.URL, "k", nil).Middleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		got = FromContext(r.Context())
+	}))
+	for cookie, want := range map[string]string{"good": "u1", "bad": "", "": ""} {
+		got = nil
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if cookie != "" {
+			req.AddCookie(&http.Cookie{Name: SessionCookie, Value: cookie})
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Errorf("cookie %q: %d, want the request let through", cookie, rec.Code)
+		}
+		if (got == nil) != (want == "") || got != nil && got.UserID != want {
+			t.Errorf("cookie %q: identity %+v, want %q", cookie, got, want)
+		}
+	}
+
+	var nilV *Verifier
+	if nilV.Middleware(h) == nil {
+		t.Error("nil Verifier's Middleware returned nil")
+	}
+}
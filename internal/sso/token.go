@@ -0,0 +1,75 @@
+package sso
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Issuer is the iss claim of the tokens the portal signs.
+const Issuer = "nexus-portal"
+
+// ErrInvalidToken is returned for a credential that is malformed, forged,
+// expired or unknown to the portal.
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// header is the only JOSE header Sign writes and Verify accepts.
+var header = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+type claims struct {
+	Identity
+	Issuer   string `json:"iss"`
+	IssuedAt int64  `json:"iat"`
+}
+
+// Sign returns an HS256 JWT asserting id until id.Expires, signed with
+// secret.
+func Sign(secret []byte, id Identity, now time.Time) (string, error) {
+	payload, err := json.Marshal(claims{Identity: id, Issuer: Issuer, IssuedAt: now.Unix()})
+	if err != nil {
+		return "", err
+	}
+	unsigned := header + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + signature(secret, unsigned), nil
+}
+
+// Verify checks a JWT made by Sign with the same secret and returns the
+// identity it asserts, or ErrInvalidToken.
+func Verify(secret []byte, token string, now time.Time) (*Identity, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != header {
+		return nil, ErrInvalidToken
+	}
+	want := signature(secret, parts[0]+"."+parts[1])
+	if !hmac.Equal([]byte(parts[2]), []byte(want)) {
+		return nil, ErrInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var c claims
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if c.Issuer != Issuer || c.UserID == "" || now.Unix() >= c.Expires {
+		return nil, ErrInvalidToken
+	}
+	return &c.Identity, nil
+}
+
+// IsJWT reports whether a credential has the shape of a JWT rather than a
+// session ID or API key.
+func IsJWT(s string) bool {
+	return strings.Count(s, ".") == 2
+}
+
+func signature(secret []byte, unsigned string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(unsigned))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,183 @@
+// Package upload stores the files users upload, such as avatars, for the
+// nexus services, and serves them back.
+//
+// Files are stored under keys like "avatars/3f0c8a52e1b4.png" that name
+// their content, so a changed file gets a new key and Handler can let
+// browsers cache what it serves for good.
+package upload
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // registered for ReadImage
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned for a key a Store doesn't hold.
+var ErrNotFound = errors.New("upload not found")
+
+// Store holds uploaded files by key. Implementations must be safe for
+// concurrent use.
+type Store interface {
+	// Put stores data under key, replacing what was there.
+	Put(ctx context.Context, key string, data []byte) error
+	// Open returns the file under key, or ErrNotFound.
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, error)
+	// Delete removes the file under key. Deleting a missing key is not
+	// an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// Key returns the key for data under prefix, named by its SHA-256 and
+// ending in ext, such as ".png".
+func Key(prefix string, data []byte, ext string) string {
+	sum := sha256.Sum256(data)
+	return path.Join(prefix, hex.EncodeToString(sum[:12])+ext)
+}
+
+// validKey reports whether key is a relative slash-separated path that
+// stays inside the store.
+func validKey(key string) bool {
+	return key != "" && !strings.HasPrefix(key, "/") && path.Clean(key) == key &&
+		key != ".." && !strings.HasPrefix(key, "../") && !strings.Contains(key, "\\")
+}
+
+// Dir stores uploads as files under a directory on local disk.
+type Dir struct {
+	root string
+}
+
+// NewDir returns a Dir storing under root, creating it if needed.
+func NewDir(root string) (*Dir, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("upload dir: %w", err)
+	}
+	return &Dir{root: root}, nil
+}
+
+func (d *Dir) path(key string) (string, error) {
+	if !validKey(key) {
+		return "", fmt.Errorf("upload key %q: not a relative path", key)
+	}
+	return filepath.Join(d.root, filepath.FromSlash(key)), nil
+}
+
+// Put implements Store. The file is written beside its destination and
+// renamed into place, so readers never see half of it.
+func (d *Dir) Put(_ context.Context, key string, data []byte) error {
+	p, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+// Open implements Store.
+func (d *Dir) Open(_ context.Context, key string) (io.ReadSeekCloser, error) {
+	p, err := d.path(key)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	f, err := os.Open(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+// Delete implements Store.
+func (d *Dir) Delete(_ context.Context, key string) error {
+	p, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Handler serves the files in s, the request path (with any prefix
+// already stripped) being the key. As keys name their content, responses
+// may be cached for a year.
+func Handler(s Store) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, "/")
+		f, err := s.Open(r.Context(), key)
+		if errors.Is(err, ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		defer f.Close()
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		http.ServeContent(w, r, path.Base(key), time.Time{}, f)
+	})
+}
+
+// Image limits.
+const (
+	MaxImageBytes = 2 << 20 // 2 MiB
+	MaxImageSide  = 4096    // pixels
+)
+
+// ErrInvalidImage is returned by ReadImage for anything but a PNG, JPEG
+// or GIF within the limits above.
+var ErrInvalidImage = errors.New("image must be a PNG, JPEG or GIF of at most 2 MiB and 4096×4096 pixels")
+
+// imageExts maps the formats ReadImage accepts to file extensions.
+var imageExts = map[string]string{"png": ".png", "jpeg": ".jpg", "gif": ".gif"}
+
+// ReadImage reads an uploaded image from r and checks that it is one, by
+// decoding its header rather than trusting its name or declared type. It
+// returns the image and the extension to store it with.
+func ReadImage(r io.Reader) (data []byte, ext string, err error) {
+	data, err = io.ReadAll(io.LimitReader(r, MaxImageBytes+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) > MaxImageBytes {
+		return nil, "", ErrInvalidImage
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", ErrInvalidImage
+	}
+	ext, ok := imageExts[format]
+	if !ok || cfg.Width < 1 || cfg.Height < 1 || cfg.Width > MaxImageSide || cfg.Height > MaxImageSide {
+		return nil, "", ErrInvalidImage
+	}
+	return data, ext, nil
+}
@@ -0,0 +1,102 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDir(t *testing.T) {
+	ctx := context.Background()
+	d, err := NewDir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := Key("avatars", []byte("hello"), ".png")
+	if !strings.HasPrefix(key, "avatars/") || !strings.HasSuffix(key, ".png") || key != Key("avatars", []byte("hello"), ".png") {
+		t.Errorf("Key = %q", key)
+	}
+	if err := d.Put(ctx, key, []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	f, err := d.Open(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(f)
+	f.Close()
+	if string(b) != "hello" {
+		t.Errorf("Open read %q", b)
+	}
+
+	for _, bad := range []string{"", "/etc/passwd", "../secret", "a/../../b", `a\b`} {
+		if err := d.Put(ctx, bad, []byte("x")); err == nil {
+			t.Errorf("Put(%q): nil error", bad)
+		}
+		if _, err := d.Open(ctx, bad); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Open(%q): %v", bad, err)
+		}
+	}
+
+	if err := d.Delete(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Open(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Open after Delete: %v", err)
+	}
+	if err := d.Delete(ctx, key); err != nil {
+		t.Errorf("Delete twice: %v", err)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	d, _ := NewDir(t.TempDir())
+	d.Put(context.Background(), "avatars/a.png", []byte("png"))
+	srv := httptest.NewServer(http.StripPrefix("/uploads", Handler(d)))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/uploads/avatars/a.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(resp.Header.Get("Cache-Control"), "immutable") {
+		t.Errorf("GET: %s, Cache-Control %q", resp.Status, resp.Header.Get("Cache-Control"))
+	}
+	resp, err = http.Get(srv.URL + "/uploads/avatars/missing.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET missing: %s", resp.Status)
+	}
+}
+
+func TestReadImage(t *testing.T) {
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewGray(image.Rect(0, 0, 8, 8)))
+	data, ext, err := ReadImage(bytes.NewReader(buf.Bytes()))
+	if err != nil || ext != ".png" || !bytes.Equal(data, buf.Bytes()) {
+		t.Errorf("ReadImage(png) = %d bytes, %q, %v", len(data), ext, err)
+	}
+
+	buf.Reset()
+	png.Encode(&buf, image.NewGray(image.Rect(0, 0, MaxImageSide+1, 1)))
+	for name, r := range map[string]io.Reader{
+		"text":     strings.NewReader("<svg onload=alert(1)>"),
+		"too wide": bytes.NewReader(buf.Bytes()),
+		"too big":  io.MultiReader(bytes.NewReader(buf.Bytes()), bytes.NewReader(make([]byte, MaxImageBytes))),
+	} {
+		if _, _, err := ReadImage(r); !errors.Is(err, ErrInvalidImage) {
+			t.Errorf("ReadImage(%s): %v", name, err)
+		}
+	}
+}
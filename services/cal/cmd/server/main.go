@@ -16,22 +16,37 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"database/sql"
 	_ "embed"
+	"encoding/hex"
+	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
+	"path/filepath"
 	"time"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
 
+	"github.com/jredh-dev/nexus/internal/events"
+	"github.com/jredh-dev/nexus/internal/health"
+	"github.com/jredh-dev/nexus/internal/httpserver"
+	"github.com/jredh-dev/nexus/internal/logging"
+	"github.com/jredh-dev/nexus/internal/ratelimit"
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
+	"github.com/jredh-dev/nexus/internal/sso"
 	"github.com/jredh-dev/nexus/services/cal/config"
+	"github.com/jredh-dev/nexus/services/cal/internal/claims"
 	"github.com/jredh-dev/nexus/services/cal/internal/database"
+	"github.com/jredh-dev/nexus/services/cal/internal/gcal"
 	"github.com/jredh-dev/nexus/services/cal/internal/handlers"
+	"github.com/jredh-dev/nexus/services/cal/internal/mailer"
+	"github.com/jredh-dev/nexus/services/cal/internal/metrics"
+	"github.com/jredh-dev/nexus/services/cal/internal/reminder"
 	gohttp "github.com/jredh-dev/nexus/services/go-http"
 )
 
@@ -49,6 +64,10 @@ var (
 func main() {
 	showVersion := flag.Bool("version", false, "Show version information")
 	enableDocs := flag.Bool("docs", false, "Enable Swagger UI at /docs (local dev only)")
+	addOwner := flag.String("add-owner", "", "Create an owner with this name, print its API key, and exit")
+	migrateOnly := flag.Bool("migrate", false, "Apply database migrations and exit")
+	seed := flag.Bool("seed", false, "Add a demo feed with sample events and exit")
+	backup := flag.String("backup", "", "Check the database and copy it to this file, and exit")
 	flag.Parse()
 
 	if *showVersion {
@@ -58,42 +77,185 @@ func main() {
 		os.Exit(0)
 	}
 
-	cfg := config.Load()
+	// Structured logs; the standard log package is routed through slog too.
+	if err := logging.Setup("nexus-cal"); err != nil {
+		fmt.Fprintf(os.Stderr, "nexus-cal: %v\n", err)
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nexus-cal: %v\n", err)
+		os.Exit(1)
+	}
+	slog.Info("config", "settings", cfg.Settings)
 
 	db, err := database.Open(cfg.DBPath)
 	if err != nil {
-		log.Fatalf("Failed to open database: %v", err)
+		logging.Fatal("open database", "path", cfg.DBPath, "err", err)
 	}
 	defer db.Close()
 
-	h := handlers.New(db)
+	if *migrateOnly {
+		// Open applies the migrations.
+		slog.Info("migrations applied", "path", cfg.DBPath)
+		return
+	}
+	if *backup != "" {
+		if err := db.Backup(context.Background(), *backup); err != nil {
+			logging.Fatal("back up database", "path", cfg.DBPath, "err", err)
+		}
+		slog.Info("database backed up", "path", cfg.DBPath, "to", *backup)
+		return
+	}
+	if *seed {
+		if err := seedDemo(db); err != nil {
+			logging.Fatal("seed demo data", "err", err)
+		}
+		return
+	}
+	if *addOwner != "" {
+		key, err := createOwner(db, *addOwner)
+		if err != nil {
+			logging.Fatal("create owner", "name", *addOwner, "err", err)
+		}
+		fmt.Printf("Created owner %q. API key (shown once): %s\n", *addOwner, key)
+		return
+	}
+	if cfg.APIKey != "" {
+		if err := bootstrapOwner(db, cfg.APIKey); err != nil {
+			logging.Fatal("bootstrap owner from CAL_API_KEY", "err", err)
+		}
+	}
+	if has, err := db.HasOwners(); err != nil {
+		logging.Fatal("check owners", "err", err)
+	} else if !has {
+		slog.Warn("no owners configured; /api, /admin and /metrics are unauthenticated", "hint", "set CAL_API_KEY or use --add-owner")
+	}
 
-	r := chi.NewRouter()
-	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
-	r.Use(middleware.Logger)
-	r.Use(middleware.Recoverer)
-	r.Use(middleware.Timeout(30 * time.Second))
+	// Background workers run with the server and stop once its requests
+	// have drained; what they share is closed after them.
+	serverOpts := []httpserver.Option{httpserver.WithWorker("wal checkpoints", db.RunCheckpoints)}
 
-	// Health check
-	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
-	})
+	// Domain events go out on Kafka when it is configured.
+	bus := events.Open("cal", cfg.Kafka.Brokers)
+	serverOpts = append(serverOpts, httpserver.WithCloser("event bus", bus))
+
+	m := metrics.New(db)
+	opts := []handlers.Option{
+		handlers.WithHorizon(handlers.Horizon{
+			Past:      cfg.HorizonPast,
+			Future:    cfg.HorizonFuture,
+			MaxEvents: cfg.MaxEvents,
+		}),
+		handlers.WithMetrics(m),
+		handlers.WithEvents(bus),
+	}
+	if cfg.SMTP.Host != "" {
+		// Invitations go out in the background so a slow relay can't stall
+		// event creation.
+		q := mailer.NewQueue(mailer.NewSMTP(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.From), 100)
+		serverOpts = append(serverOpts, httpserver.WithWorker("invitations", q.Run))
+		opts = append(opts, handlers.WithMailer(q))
+		slog.Info("email invitations enabled", "smtp_host", cfg.SMTP.Host, "smtp_port", cfg.SMTP.Port)
+	}
+	h := handlers.New(db, opts...)
+
+	// SMS reminders are published to the sms-outbox pipeline when Kafka is configured.
+	if len(cfg.Kafka.Brokers) > 0 {
+		pub := smsoutbox.NewKafkaPublisher(cfg.Kafka.Brokers, cfg.Kafka.Topic)
+		serverOpts = append(serverOpts,
+			httpserver.WithWorker("reminders", reminder.New(db, pub, cfg.Kafka.ReminderInterval).Run),
+			httpserver.WithCloser("sms publisher", pub))
+		slog.Info("sms reminders enabled", "topic", cfg.Kafka.Topic, "brokers", cfg.Kafka.Brokers)
+	}
+
+	// Optionally mirror one feed into Google Calendar.
+	if g := cfg.Google; g.Enabled() {
+		client := gcal.NewClient(g.ClientID, g.ClientSecret, g.RefreshToken)
+		syncer := gcal.NewSyncer(db, client, g.CalendarID, g.FeedID, g.SyncInterval)
+		serverOpts = append(serverOpts, httpserver.WithWorker("google calendar sync", syncer.Run))
+		slog.Info("google calendar sync enabled", "feed_id", g.FeedID, "calendar_id", g.CalendarID, "interval", g.SyncInterval)
+	}
+
+	checks := health.New()
+	checks.Ready("database", db.Ping)
+	checks.Ready("disk", health.DiskSpace(filepath.Dir(cfg.DBPath), 100<<20))
+	if len(cfg.Kafka.Brokers) > 0 {
+		checks.Ready("kafka", health.Kafka(cfg.Kafka.Brokers))
+	}
+
+	limits, err := ratelimit.Open(cfg.RateLimit.DB)
+	if err != nil {
+		logging.Fatal("open rate limit store", "path", cfg.RateLimit.DB, "err", err)
+	}
+	serverOpts = append(serverOpts,
+		httpserver.WithCloser("rate limits", limits),
+		// Client IPs, which the feed and webhook limits key on, come from
+		// X-Forwarded-For only as CAL_TRUSTED_PROXIES record it.
+		httpserver.WithTrustedProxies(cfg.RateLimit.TrustedProxies))
+	rateLimited := ratelimit.NewCounter("cal")
+	m.Common().MustRegister(rateLimited)
+	lim := ratelimit.New(limits, cfg.RateLimit.Limits, rateLimited)
+
+	// Claims stop arriving as soon as shutdown starts.
+	claimsCtx, stopClaims := context.WithCancel(context.Background())
+	defer stopClaims()
+
+	srv := httpserver.New(append(serverOpts,
+		// Prometheus scrape endpoint. Its gauges reveal how many feeds and
+		// events exist, so scrapers authenticate like API clients
+		// (Prometheus authorization.credentials sends the key as a bearer
+		// token).
+		httpserver.WithMetrics(m.Common(), h.RequireOwner),
+		httpserver.WithHealth(checks),
+		httpserver.WithVersion(httpserver.Version{Service: "nexus-cal", Version: version, Commit: commit, Built: buildDate}),
+		httpserver.WithShutdownHook(stopClaims),
+	)...)
+	r := srv.Router
 
 	// Calendar subscription endpoint (served to calendar clients)
 	// webcal://host/{token}.ics
-	r.Get("/{token}.ics", h.Subscribe)
+	feedLimit := lim.Limit("feed", ratelimit.ByIP)
+	r.With(feedLimit).Get("/{token}.ics", h.Subscribe)
+	r.With(feedLimit).Get("/{token}.json", h.SubscribeJSON)
+	r.With(feedLimit).Get("/{token}/freebusy", h.FreeBusy)
+
+	// Portal giveaway claims become delivery events, whether they arrive on
+	// the event bus or by webhook. The webhook is authenticated by its HMAC
+	// signature rather than an owner key.
+	if p := cfg.Portal; p.Enabled() {
+		if _, err := db.FeedByID(p.FeedID); err != nil {
+			logging.Fatal("CAL_PORTAL_FEED_ID does not name a feed", "feed_id", p.FeedID, "err", err)
+		}
+		im := claims.New(db, p.FeedID, p.WebhookSecret)
+		im.Subscribe(claimsCtx, bus, "cal")
+		r.With(lim.Limit("webhook", ratelimit.ByIP)).Method(http.MethodPost, "/webhooks/portal", im)
+		slog.Info("portal claim import enabled", "feed_id", p.FeedID)
+	}
 
-	// Management API
+	// Portal logins are accepted wherever owner keys are.
+	sv := sso.NewVerifier(cfg.SSO.PortalURL, cfg.SSO.IntrospectKey, []byte(cfg.SSO.Secret))
+	if sv != nil {
+		slog.Info("portal sign-in enabled", "portal", cfg.SSO.PortalURL, "local_jwt", cfg.SSO.Secret != "")
+	}
+
+	// Admin UI — browser access via basic auth (any username, key as
+	// password) or the portal's session cookie.
+	r.With(sv.Middleware, h.RequireOwner).Mount("/admin", h.AdminRouter())
+
+	// Management API, scoped to the caller's owner
 	r.Route("/api", func(r chi.Router) {
+		r.Use(lim.Limit("api", ratelimit.ByCredential), sv.Middleware, h.RequireOwner)
 		r.Post("/feeds", h.CreateFeed)
 		r.Get("/feeds", h.ListFeeds)
 		r.Delete("/feeds/{id}", h.DeleteFeed)
 		r.Get("/feeds/{id}/events", h.ListEvents)
+		r.Get("/feeds/{id}/categories", h.ListCategories)
 
 		r.Post("/events", h.CreateEvent)
 		r.Delete("/events/{id}", h.DeleteEvent)
+		r.Put("/events/{id}/attendees/{email}", h.UpdateAttendee)
 	})
 
 	// Mount Swagger UI if --docs flag is set (local dev only).
@@ -102,35 +264,67 @@ func main() {
 	}
 
 	addr := ":" + cfg.Port
-	srv := &http.Server{
-		Addr:         addr,
-		Handler:      r,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
-	}
+	slog.Info("nexus-cal starting", "addr", addr, "version", version,
+		"subscribe", "webcal://localhost"+addr+"/{token}.ics",
+		"api", "http://localhost"+addr+"/api/",
+		"admin", "http://localhost"+addr+"/admin/",
+		"metrics", "http://localhost"+addr+"/metrics")
 
-	go func() {
-		sigint := make(chan os.Signal, 1)
-		signal.Notify(sigint, os.Interrupt, syscall.SIGTERM)
-		<-sigint
+	if err := srv.ListenAndServe(addr); err != nil {
+		logging.Fatal("server", "err", err)
+	}
+}
 
-		log.Println("Shutting down server...")
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
+// createOwner adds an owner with a freshly generated API key and returns the
+// key. The first owner adopts every feed created before ownership existed;
+// otherwise enabling auth would strand them where no key can reach them.
+func createOwner(db *database.DB, name string) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	key := hex.EncodeToString(b)
+	had, err := db.HasOwners()
+	if err != nil {
+		return "", err
+	}
+	owner := &database.Owner{ID: uuid.New().String(), Name: name, CreatedAt: time.Now().UTC()}
+	if err := db.CreateOwner(owner, key); err != nil {
+		return "", err
+	}
+	if !had {
+		if err := adoptUnownedFeeds(db, owner); err != nil {
+			return "", err
+		}
+	}
+	return key, nil
+}
 
-		if err := srv.Shutdown(ctx); err != nil {
-			log.Printf("Server shutdown error: %v", err)
+// bootstrapOwner makes CAL_API_KEY an owner identity on first use and hands
+// it every feed created before ownership existed.
+func bootstrapOwner(db *database.DB, key string) error {
+	owner, err := db.OwnerByAPIKey(key)
+	if errors.Is(err, sql.ErrNoRows) {
+		owner = &database.Owner{ID: uuid.New().String(), Name: "default", CreatedAt: time.Now().UTC()}
+		if err := db.CreateOwner(owner, key); err != nil {
+			return err
 		}
-	}()
+		slog.Info("created default owner from CAL_API_KEY", "owner_id", owner.ID)
+	} else if err != nil {
+		return err
+	}
 
-	log.Printf("nexus-cal starting on %s", addr)
-	log.Printf("  Subscribe: webcal://localhost%s/{token}.ics", addr)
-	log.Printf("  API:       http://localhost%s/api/", addr)
+	return adoptUnownedFeeds(db, owner)
+}
 
-	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
-		log.Fatalf("Server error: %v", err)
+// adoptUnownedFeeds hands every feed without an owner to owner.
+func adoptUnownedFeeds(db *database.DB, owner *database.Owner) error {
+	n, err := db.AssignUnownedFeeds(owner.ID)
+	if err != nil {
+		return err
 	}
-
-	log.Println("Server stopped")
+	if n > 0 {
+		slog.Info("assigned unowned feeds", "count", n, "owner", owner.Name)
+	}
+	return nil
 }
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jredh-dev/nexus/services/cal/internal/database"
+)
+
+// demoFeedToken is the demo feed's subscription token, so it is at
+// webcal://host/demo.ics.
+const demoFeedToken = "demo"
+
+// demoEvents are the demo feed's events, by dedupe key, as offsets from
+// midnight UTC today.
+var demoEvents = []struct {
+	key, summary  string
+	start, length time.Duration
+}{
+	{"demo-standup", "Standup", 24*time.Hour + 9*time.Hour, 15 * time.Minute},
+	{"demo-design-review", "Design review", 2*24*time.Hour + 14*time.Hour, time.Hour},
+	{"demo-release", "Release", 5*24*time.Hour + 16*time.Hour, 30 * time.Minute},
+}
+
+// seedDemo adds the demo feed and its events, skipping whatever is
+// already there, so seeding twice changes nothing.
+func seedDemo(db *database.DB) error {
+	now := time.Now().UTC()
+	feed, err := db.FeedByToken(demoFeedToken)
+	if errors.Is(err, sql.ErrNoRows) {
+		feed = &database.Feed{ID: uuid.New().String(), Name: "Demo", Token: demoFeedToken, CreatedAt: now, UpdatedAt: now}
+		if err := db.CreateFeed(feed); err != nil {
+			return err
+		}
+		slog.Info("seeded demo feed", "feed_id", feed.ID, "subscribe", "/"+demoFeedToken+".ics")
+	} else if err != nil {
+		return err
+	}
+
+	day := now.Truncate(24 * time.Hour)
+	for _, e := range demoEvents {
+		if _, err := db.EventByDedupeKey(feed.ID, e.key); err == nil {
+			continue
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		start := day.Add(e.start)
+		end := start.Add(e.length)
+		event := &database.Event{
+			ID: uuid.New().String(), FeedID: feed.ID, Summary: e.summary,
+			Start: start, End: &end, Status: "CONFIRMED", DedupeKey: e.key,
+			CreatedAt: now, UpdatedAt: now,
+		}
+		if err := db.CreateEvent(event); err != nil {
+			return err
+		}
+		slog.Info("seeded demo event", "summary", e.summary, "start", start)
+	}
+	return nil
+}
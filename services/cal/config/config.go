@@ -1,26 +1,143 @@
 package config
 
 import (
-	"os"
+	"time"
+
+	"github.com/jredh-dev/nexus/internal/ratelimit"
+	"github.com/jredh-dev/nexus/internal/settings"
 )
 
 // Config holds all configuration for the calendar service.
 type Config struct {
 	Port   string
 	DBPath string
+	APIKey string // key of the default owner; adopts feeds created before owners existed
+	SMTP   SMTPConfig
+	Kafka  KafkaConfig
+	Google GoogleConfig
+	Portal PortalConfig
+	SSO    SSOConfig
+
+	// RateLimit limits feed subscriptions and the portal webhook by client
+	// IP, and the management API by API key or session.
+	RateLimit ratelimit.Config
+
+	// Subscription horizon: feeds serve events from HorizonPast before now
+	// to HorizonFuture after it, at most MaxEvents of them.
+	HorizonPast   time.Duration
+	HorizonFuture time.Duration
+	MaxEvents     int
+
+	Settings settings.Values // everything above as loaded, for logging at boot
 }
 
-func envOr(key, fallback string) string {
-	if v := os.Getenv(key); v != "" {
-		return v
-	}
-	return fallback
+// GoogleConfig holds settings for mirroring a feed into Google Calendar.
+// Sync is disabled unless CalendarID, FeedID, and RefreshToken are all set.
+type GoogleConfig struct {
+	CalendarID   string
+	FeedID       string // the nexus-cal feed to mirror
+	ClientID     string
+	ClientSecret string
+	RefreshToken string
+	SyncInterval time.Duration
+}
+
+// Enabled reports whether Google Calendar sync is configured.
+func (g GoogleConfig) Enabled() bool {
+	return g.CalendarID != "" && g.FeedID != "" && g.RefreshToken != ""
+}
+
+// PortalConfig holds settings for importing confirmed giveaway claims as
+// delivery events from portalhook webhooks. Import is disabled unless both
+// FeedID and WebhookSecret are set; leave it off until the giveaway service
+// sends them, since the portal currently does not.
+type PortalConfig struct {
+	FeedID        string // the feed delivery events are created in
+	WebhookSecret string // shared HMAC key the portal signs deliveries with
+}
+
+// Enabled reports whether portal claim import is configured.
+func (p PortalConfig) Enabled() bool {
+	return p.FeedID != "" && p.WebhookSecret != ""
+}
+
+// SSOConfig holds settings for accepting portal logins on the management
+// API and admin UI alongside owner API keys. Portal users own the feeds
+// they create. Both are optional: without the secret, JWTs are checked by
+// asking the portal; without either, only API keys work.
+type SSOConfig struct {
+	PortalURL     string // where the portal's /api/auth/introspect is
+	IntrospectKey string // the portal's SSO_INTROSPECT_KEY, which it answers introspection only to
+	Secret        string // the portal's SSO_SECRET, to check its JWTs locally
+}
+
+// KafkaConfig holds settings for publishing SMS reminders to the sms-outbox
+// pipeline and for the event bus (see internal/events). Reminders are
+// disabled, and events stay inside the process, when Brokers is empty.
+type KafkaConfig struct {
+	Brokers          []string
+	Topic            string
+	ReminderInterval time.Duration // how often to check for due reminders
+}
+
+// SMTPConfig holds outbound email settings for invitations.
+// Invitations are disabled when Host is empty.
+type SMTPConfig struct {
+	Host string
+	Port string
+	From string
 }
 
-// Load reads configuration from environment variables with sensible defaults.
-func Load() *Config {
-	return &Config{
-		Port:   envOr("CAL_PORT", "8085"),
-		DBPath: envOr("CAL_DB_PATH", "cal.db"),
+// Load reads configuration from environment variables, and from the YAML
+// file CAL_CONFIG_FILE names if set, with sensible defaults.
+func Load() (*Config, error) {
+	l := settings.New("CAL_CONFIG_FILE")
+	cfg := &Config{
+		Port:   l.String("CAL_PORT", "8085"),
+		DBPath: l.String("CAL_DB_PATH", "cal.db"),
+		APIKey: l.Secret("CAL_API_KEY", ""),
+		Kafka: KafkaConfig{
+			Brokers:          l.List("CAL_KAFKA_BROKERS"),
+			Topic:            l.String("CAL_SMS_TOPIC", "sms-outbox"),
+			ReminderInterval: l.Duration("CAL_REMINDER_INTERVAL", time.Minute),
+		},
+		SMTP: SMTPConfig{
+			Host: l.String("CAL_SMTP_HOST", ""),
+			Port: l.String("CAL_SMTP_PORT", "1025"),
+			From: l.String("CAL_SMTP_FROM", "calendar@jredh.com"),
+		},
+		Google: GoogleConfig{
+			CalendarID:   l.String("CAL_GOOGLE_CALENDAR_ID", ""),
+			FeedID:       l.String("CAL_GOOGLE_FEED_ID", ""),
+			ClientID:     l.String("CAL_GOOGLE_CLIENT_ID", ""),
+			ClientSecret: l.Secret("CAL_GOOGLE_CLIENT_SECRET", ""),
+			RefreshToken: l.Secret("CAL_GOOGLE_REFRESH_TOKEN", ""),
+			SyncInterval: l.Duration("CAL_GOOGLE_SYNC_INTERVAL", 5*time.Minute),
+		},
+		Portal: PortalConfig{
+			FeedID:        l.String("CAL_PORTAL_FEED_ID", ""),
+			WebhookSecret: l.Secret("CAL_PORTAL_WEBHOOK_SECRET", ""),
+		},
+		SSO: SSOConfig{
+			PortalURL:     l.String("CAL_SSO_PORTAL_URL", ""),
+			IntrospectKey: l.Secret("CAL_SSO_INTROSPECT_KEY", ""),
+			Secret:        l.Secret("CAL_SSO_SECRET", ""),
+		},
+		HorizonPast:   l.Duration("CAL_HORIZON_PAST", 365*24*time.Hour),
+		HorizonFuture: l.Duration("CAL_HORIZON_FUTURE", 365*24*time.Hour),
+		MaxEvents:     l.Int("CAL_MAX_EVENTS", 5000),
 	}
+	cfg.RateLimit = ratelimit.LoadConfig(l, "CAL_", ratelimit.Limits{
+		"feed":    ratelimit.PerMinute(60),
+		"webhook": ratelimit.PerMinute(120),
+		"api":     ratelimit.PerMinute(300),
+	})
+	g := cfg.Google
+	l.Check(g.CalendarID == "" && g.FeedID == "" || g.Enabled(),
+		"Google Calendar sync needs CAL_GOOGLE_CALENDAR_ID, CAL_GOOGLE_FEED_ID and CAL_GOOGLE_REFRESH_TOKEN together")
+	p := cfg.Portal
+	l.Check((p.FeedID == "") == (p.WebhookSecret == ""),
+		"portal claim import needs CAL_PORTAL_FEED_ID and CAL_PORTAL_WEBHOOK_SECRET together")
+	cfg.Settings = l.Values()
+	return cfg, l.Err()
 }
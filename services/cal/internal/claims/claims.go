@@ -0,0 +1,217 @@
+// Package claims imports confirmed giveaway claims as delivery events. The
+// giveaway service posts a signed portalhook.Payload whenever a claim
+// changes, or publishes it on the event bus's claim topics; claims with a
+// delivery timeslot become events in a configured feed, kept up to date as
+// the claim is rescheduled or cancelled.
+package claims
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/jredh-dev/nexus/internal/events"
+	"github.com/jredh-dev/nexus/internal/logging"
+	"github.com/jredh-dev/nexus/internal/portalhook"
+	"github.com/jredh-dev/nexus/services/cal/internal/database"
+)
+
+// maxBodySize bounds webhook payloads; a claim is a few hundred bytes.
+const maxBodySize = 64 << 10
+
+// Result describes what Apply did with a payload.
+type Result string
+
+const (
+	Created   Result = "created"
+	Updated   Result = "updated"
+	Cancelled Result = "cancelled"
+	Ignored   Result = "ignored"
+)
+
+// Importer turns claim webhooks into delivery events in one feed.
+type Importer struct {
+	db     *database.DB
+	feedID string
+	secret string
+}
+
+// New creates an Importer that writes to feedID and accepts webhooks signed
+// with secret.
+func New(db *database.DB, feedID, secret string) *Importer {
+	return &Importer{db: db, feedID: feedID, secret: secret}
+}
+
+// DedupeKey is the dedupe key of the delivery event for a claim, so
+// redelivered webhooks find the event they created before.
+func DedupeKey(claimID string) string {
+	return "portal-claim:" + claimID
+}
+
+// ServeHTTP receives a portal webhook. Deliveries with a bad signature are
+// rejected with 401; anything else that can't be applied returns 400 or 500
+// so the portal retries it.
+// POST /webhooks/portal
+func (im *Importer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log := logging.FromContext(r.Context())
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+	if err != nil || len(body) > maxBodySize {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+	if !portalhook.Verify(im.secret, body, r.Header.Get(portalhook.SignatureHeader)) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	var p portalhook.Payload
+	if err := json.Unmarshal(body, &p); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	res, err := im.Apply(p)
+	if errors.Is(err, errInvalid) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Error("apply portal claim webhook", "delivery", p.ID, "claim_id", p.Claim.ID, "err", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	log.Info("portal claim webhook", "delivery", p.ID, "type", p.Type, "claim_id", p.Claim.ID, "result", res)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+var errInvalid = errors.New("invalid payload")
+
+// Handle applies a claim event from the bus. Invalid payloads are logged
+// and dropped, since handing them over again can't fix them; other errors
+// have the bus retry.
+func (im *Importer) Handle(ctx context.Context, e events.Event, p portalhook.Payload) error {
+	log := logging.FromContext(ctx)
+	res, err := im.Apply(p)
+	if errors.Is(err, errInvalid) {
+		log.Error("drop invalid claim event", "event_id", e.ID, "claim_id", p.Claim.ID, "err", err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	log.Info("claim event", "event_id", e.ID, "type", p.Type, "claim_id", p.Claim.ID, "result", res)
+	return nil
+}
+
+// Subscribe applies the claim events on bus until ctx is done. Deliveries
+// are consumed in group, so several cal replicas share them.
+func (im *Importer) Subscribe(ctx context.Context, bus events.Bus, group string) {
+	for _, t := range []events.Topic[portalhook.Payload]{events.ClaimConfirmed, events.ClaimUpdated, events.ClaimCancelled} {
+		go func() {
+			if err := events.Subscribe(ctx, bus, t, group, im.Handle); err != nil {
+				logging.FromContext(ctx).Error("subscribe to claim events", "topic", t.Name, "err", err)
+			}
+		}()
+	}
+}
+
+// Apply brings the delivery event for p's claim up to date. Confirmed and
+// updated claims with a timeslot create or update the event; cancelled
+// claims mark it CANCELLED so subscribers see the cancellation rather than
+// the event silently vanishing. Deliveries older than the event's last
+// update are ignored, so out-of-order retries can't roll it back.
+func (im *Importer) Apply(p portalhook.Payload) (Result, error) {
+	if p.Claim.ID == "" || p.OccurredAt.IsZero() {
+		return "", fmt.Errorf("%w: claim.id and occurred_at are required", errInvalid)
+	}
+
+	existing, err := im.db.EventByDedupeKey(im.feedID, DedupeKey(p.Claim.ID))
+	if errors.Is(err, sql.ErrNoRows) {
+		existing = nil
+	} else if err != nil {
+		return "", err
+	}
+	if existing != nil && p.OccurredAt.Before(existing.UpdatedAt) {
+		return Ignored, nil
+	}
+
+	switch p.Type {
+	case portalhook.TypeCancelled:
+		if existing == nil || existing.Status == "CANCELLED" {
+			return Ignored, nil
+		}
+		existing.Status = "CANCELLED"
+		existing.UpdatedAt = p.OccurredAt.UTC()
+		return Cancelled, im.db.UpdateEvent(existing)
+
+	case portalhook.TypeConfirmed, portalhook.TypeUpdated:
+		if p.Claim.Timeslot == nil {
+			return Ignored, nil
+		}
+		ts := p.Claim.Timeslot
+		if !ts.End.After(ts.Start) {
+			return "", fmt.Errorf("%w: timeslot end must be after start", errInvalid)
+		}
+		e := existing
+		if e == nil {
+			e = &database.Event{
+				ID:        uuid.New().String(),
+				FeedID:    im.feedID,
+				DedupeKey: DedupeKey(p.Claim.ID),
+				CreatedAt: p.OccurredAt.UTC(),
+			}
+		}
+		fill(e, p.Claim)
+		e.UpdatedAt = p.OccurredAt.UTC()
+		if existing != nil {
+			return Updated, im.db.UpdateEvent(e)
+		}
+		if err := im.db.CreateEvent(e); err != nil {
+			// A concurrent delivery for the same claim won the insert.
+			if _, lookupErr := im.db.EventByDedupeKey(im.feedID, e.DedupeKey); lookupErr == nil {
+				return Ignored, nil
+			}
+			return "", err
+		}
+		return Created, nil
+
+	default:
+		return Ignored, nil
+	}
+}
+
+// fill sets the event's details from a claim.
+func fill(e *database.Event, c portalhook.Claim) {
+	title := c.ItemTitle
+	if title == "" {
+		title = "item " + c.ItemID
+	}
+	e.Summary = "Deliver: " + title
+
+	var desc []string
+	if c.ClaimerName != "" {
+		desc = append(desc, "Claimer: "+c.ClaimerName)
+	}
+	if c.ClaimerPhone != "" {
+		desc = append(desc, "Phone: "+c.ClaimerPhone)
+	}
+	if c.Notes != "" {
+		desc = append(desc, "Notes: "+c.Notes)
+	}
+	desc = append(desc, "Claim: "+c.ID)
+	e.Description = strings.Join(desc, "\n")
+
+	e.Location = c.Address
+	e.Start = c.Timeslot.Start.UTC()
+	end := c.Timeslot.End.UTC()
+	e.End = &end
+	e.Status = "CONFIRMED"
+	e.Categories = "delivery"
+}
@@ -0,0 +1,213 @@
+package claims
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/jredh-dev/nexus/internal/portalhook"
+	"github.com/jredh-dev/nexus/services/cal/internal/database"
+	"github.com/jredh-dev/nexus/services/cal/internal/handlers"
+)
+
+const secret = "s3cret"
+
+func testImporter(t *testing.T) (*Importer, *database.DB) {
+	t.Helper()
+	db, err := database.Open(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	now := time.Now().UTC()
+	feed := &database.Feed{ID: "deliveries", Name: "Deliveries", Token: "deliveries", CreatedAt: now, UpdatedAt: now}
+	if err := db.CreateFeed(feed); err != nil {
+		t.Fatal(err)
+	}
+	return New(db, feed.ID, secret), db
+}
+
+func payload(typ string, at time.Time, slot *portalhook.Timeslot) portalhook.Payload {
+	return portalhook.Payload{
+		ID:         "d-" + at.Format(time.RFC3339Nano),
+		Type:       typ,
+		OccurredAt: at,
+		Claim: portalhook.Claim{
+			ID:           "c1",
+			ItemID:       "i1",
+			ItemTitle:    "Desk lamp",
+			ClaimerName:  "Sam",
+			ClaimerPhone: "+15555550100",
+			Address:      "1 Main St",
+			Status:       "confirmed",
+			Timeslot:     slot,
+		},
+	}
+}
+
+func post(t *testing.T, im *Importer, p portalhook.Payload, sig string) int {
+	t.Helper()
+	body, _ := json.Marshal(p)
+	if sig == "" {
+		sig = portalhook.Sign(secret, body)
+	}
+	req := httptest.NewRequest("POST", "/webhooks/portal", bytes.NewReader(body))
+	req.Header.Set(portalhook.SignatureHeader, sig)
+	rec := httptest.NewRecorder()
+	im.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestApply_Lifecycle(t *testing.T) {
+	im, db := testImporter(t)
+	t0 := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	slot := &portalhook.Timeslot{Start: t0.Add(48 * time.Hour), End: t0.Add(49 * time.Hour)}
+
+	// Confirmed without a timeslot: nothing to schedule yet.
+	noSlot := payload(portalhook.TypeConfirmed, t0, nil)
+	if res, err := im.Apply(noSlot); err != nil || res != Ignored {
+		t.Fatalf("no timeslot: res=%s err=%v", res, err)
+	}
+
+	if res, err := im.Apply(payload(portalhook.TypeConfirmed, t0.Add(time.Minute), slot)); err != nil || res != Created {
+		t.Fatalf("confirm: res=%s err=%v", res, err)
+	}
+	e, err := db.EventByDedupeKey("deliveries", DedupeKey("c1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Summary != "Deliver: Desk lamp" || e.Location != "1 Main St" || e.Status != "CONFIRMED" {
+		t.Errorf("event = %+v", e)
+	}
+	if !e.Start.Equal(slot.Start) || e.End == nil || !e.End.Equal(slot.End) {
+		t.Errorf("event time = %v-%v, want %v-%v", e.Start, e.End, slot.Start, slot.End)
+	}
+	if !strings.Contains(e.Description, "+15555550100") {
+		t.Errorf("description missing phone: %q", e.Description)
+	}
+
+	// Redelivery of the same webhook updates in place.
+	if res, err := im.Apply(payload(portalhook.TypeConfirmed, t0.Add(time.Minute), slot)); err != nil || res != Updated {
+		t.Fatalf("redelivery: res=%s err=%v", res, err)
+	}
+
+	// Rescheduled.
+	moved := &portalhook.Timeslot{Start: slot.Start.Add(24 * time.Hour), End: slot.End.Add(24 * time.Hour)}
+	if res, err := im.Apply(payload(portalhook.TypeUpdated, t0.Add(2*time.Minute), moved)); err != nil || res != Updated {
+		t.Fatalf("reschedule: res=%s err=%v", res, err)
+	}
+
+	// A stale retry of the original confirmation must not roll it back.
+	if res, err := im.Apply(payload(portalhook.TypeConfirmed, t0.Add(time.Minute), slot)); err != nil || res != Ignored {
+		t.Fatalf("stale: res=%s err=%v", res, err)
+	}
+	e, _ = db.EventByID(e.ID)
+	if !e.Start.Equal(moved.Start) {
+		t.Errorf("start = %v, want %v", e.Start, moved.Start)
+	}
+
+	if res, err := im.Apply(payload(portalhook.TypeCancelled, t0.Add(3*time.Minute), nil)); err != nil || res != Cancelled {
+		t.Fatalf("cancel: res=%s err=%v", res, err)
+	}
+	e, _ = db.EventByID(e.ID)
+	if e.Status != "CANCELLED" {
+		t.Errorf("status = %q, want CANCELLED", e.Status)
+	}
+
+	events, err := db.EventsByFeed("deliveries")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 {
+		t.Errorf("got %d events, want 1", len(events))
+	}
+}
+
+func TestApply_CancelUnknownClaim(t *testing.T) {
+	im, _ := testImporter(t)
+	res, err := im.Apply(payload(portalhook.TypeCancelled, time.Now(), nil))
+	if err != nil || res != Ignored {
+		t.Errorf("res=%s err=%v, want ignored", res, err)
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	im, db := testImporter(t)
+	t0 := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	p := payload(portalhook.TypeConfirmed, t0, &portalhook.Timeslot{Start: t0.Add(time.Hour), End: t0.Add(2 * time.Hour)})
+
+	if code := post(t, im, p, "sha256=00"); code != http.StatusUnauthorized {
+		t.Errorf("bad signature: status = %d, want 401", code)
+	}
+	if _, err := db.EventByDedupeKey("deliveries", DedupeKey("c1")); err == nil {
+		t.Error("event created despite bad signature")
+	}
+
+	if code := post(t, im, p, ""); code != http.StatusNoContent {
+		t.Fatalf("status = %d, want 204", code)
+	}
+	if _, err := db.EventByDedupeKey("deliveries", DedupeKey("c1")); err != nil {
+		t.Errorf("event not created: %v", err)
+	}
+
+	bad := payload(portalhook.TypeConfirmed, t0, &portalhook.Timeslot{Start: t0.Add(time.Hour), End: t0})
+	if code := post(t, im, bad, ""); code != http.StatusBadRequest {
+		t.Errorf("inverted timeslot: status = %d, want 400", code)
+	}
+}
+
+// TestEndToEnd delivers claim webhooks the way the giveaway service does,
+// through portalhook.Sender, and checks the resulting subscription feed.
+func TestEndToEnd(t *testing.T) {
+	im, db := testImporter(t)
+	srv := httptest.NewServer(im)
+	defer srv.Close()
+
+	feed, err := db.FeedByID("deliveries")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := handlers.New(db)
+	r := chi.NewRouter()
+	r.Get("/{token}.ics", h.Subscribe)
+	subscribe := func() string {
+		req := httptest.NewRequest(http.MethodGet, "/"+feed.Token+".ics", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	ctx := context.Background()
+	t0 := time.Now().UTC().Truncate(time.Second)
+	slot := &portalhook.Timeslot{Start: t0.Add(24 * time.Hour), End: t0.Add(25 * time.Hour)}
+
+	if err := portalhook.NewSender(srv.URL, "wrong", nil).Send(ctx, payload(portalhook.TypeConfirmed, t0, slot)); err == nil {
+		t.Fatal("expected a wrongly signed delivery to fail")
+	}
+
+	send := portalhook.NewSender(srv.URL, secret, srv.Client())
+	if err := send.Send(ctx, payload(portalhook.TypeConfirmed, t0, slot)); err != nil {
+		t.Fatal(err)
+	}
+	ics := subscribe()
+	for _, want := range []string{"SUMMARY:Deliver: Desk lamp", "STATUS:CONFIRMED", "CATEGORIES:delivery"} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("feed missing %q:\n%s", want, ics)
+		}
+	}
+
+	if err := send.Send(ctx, payload(portalhook.TypeCancelled, t0.Add(time.Minute), nil)); err != nil {
+		t.Fatal(err)
+	}
+	if ics := subscribe(); !strings.Contains(ics, "STATUS:CANCELLED") {
+		t.Errorf("feed does not show the cancellation:\n%s", ics)
+	}
+}
@@ -1,21 +1,36 @@
 package database
 
 import (
+	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
-	_ "modernc.org/sqlite"
+	"github.com/jredh-dev/nexus/internal/sqlitex"
 )
 
 // DB wraps the SQLite connection.
 type DB struct {
-	conn *sql.DB
+	conn    *sql.DB
+	observe func(op string, d time.Duration) // nil when timings aren't collected
+}
+
+// Owner is an API key identity. Feeds belong to an owner, and management
+// operations only see the caller's own feeds.
+type Owner struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 // Feed represents a calendar feed with a unique subscription token.
 type Feed struct {
 	ID        string    `json:"id"`
+	OwnerID   string    `json:"owner_id,omitempty"` // empty for feeds created without auth
 	Name      string    `json:"name"`
 	Token     string    `json:"token"` // unguessable token for subscription URL
 	CreatedAt time.Time `json:"created_at"`
@@ -24,23 +39,80 @@ type Feed struct {
 
 // Event represents a single calendar event within a feed.
 type Event struct {
-	ID          string     `json:"id"`
-	FeedID      string     `json:"feed_id"`
-	Summary     string     `json:"summary"`
-	Description string     `json:"description"`
-	Location    string     `json:"location"`
-	URL         string     `json:"url"`
-	Start       time.Time  `json:"start"`
-	End         *time.Time `json:"end,omitempty"` // nil = no end time (all-day or point-in-time)
-	AllDay      bool       `json:"all_day"`
-	Deadline    *time.Time `json:"deadline,omitempty"` // optional deadline (used as DTSTART if set, with VALARM)
-	Status      string     `json:"status"`             // TENTATIVE, CONFIRMED, CANCELLED
-	Categories  string     `json:"categories"`         // comma-separated
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	ID          string       `json:"id"`
+	FeedID      string       `json:"feed_id"`
+	Summary     string       `json:"summary"`
+	Description string       `json:"description"`
+	Location    string       `json:"location"`
+	URL         string       `json:"url"`
+	Start       time.Time    `json:"start"`
+	End         *time.Time   `json:"end,omitempty"` // nil = no end time (all-day or point-in-time)
+	AllDay      bool         `json:"all_day"`
+	Deadline    *time.Time   `json:"deadline,omitempty"`  // optional deadline (used as DTSTART if set, with VALARM)
+	Status      string       `json:"status"`              // TENTATIVE, CONFIRMED, CANCELLED
+	Categories  string       `json:"categories"`          // comma-separated
+	Organizer   string       `json:"organizer,omitempty"` // organizer email address
+	OrganizerCN string       `json:"organizer_name,omitempty"`
+	Attendees   []Attendee   `json:"attendees,omitempty"`   // loaded separately; not an events column
+	Attachments []Attachment `json:"attachments,omitempty"` // loaded separately; not an events column
+	Reminder    *Reminder    `json:"sms_reminder,omitempty"`
+	DedupeKey   string       `json:"dedupe_key,omitempty"` // caller-supplied idempotency key, unique per feed
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// Reminder asks for an SMS to be sent shortly before an event starts.
+type Reminder struct {
+	To            string     `json:"to"`             // E.164 phone number
+	MinutesBefore int        `json:"minutes_before"` // lead time before start
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+}
+
+// Participation statuses for attendees (RFC 5545 section 3.2.12).
+const (
+	PartStatNeedsAction = "NEEDS-ACTION"
+	PartStatAccepted    = "ACCEPTED"
+	PartStatDeclined    = "DECLINED"
+	PartStatTentative   = "TENTATIVE"
+	PartStatDelegated   = "DELEGATED"
+)
+
+// ValidPartStat reports whether s is a PARTSTAT value allowed on a VEVENT.
+func ValidPartStat(s string) bool {
+	switch s {
+	case PartStatNeedsAction, PartStatAccepted, PartStatDeclined, PartStatTentative, PartStatDelegated:
+		return true
+	}
+	return false
+}
+
+// Attendee is a participant invited to an event.
+type Attendee struct {
+	EventID  string `json:"-"`
+	Email    string `json:"email"`
+	Name     string `json:"name,omitempty"`
+	PartStat string `json:"partstat"` // NEEDS-ACTION, ACCEPTED, DECLINED, TENTATIVE, DELEGATED
+}
+
+// Attachment is a document linked to or embedded in an event. Exactly one of
+// URL or Data is set.
+type Attachment struct {
+	ID       string `json:"id"`
+	EventID  string `json:"-"`
+	URL      string `json:"url,omitempty"`
+	Data     []byte `json:"data,omitempty"` // inline content; base64 in JSON
+	FmtType  string `json:"fmttype,omitempty"`
+	Filename string `json:"filename,omitempty"`
 }
 
 const schema = `
+CREATE TABLE IF NOT EXISTS owners (
+	id         TEXT PRIMARY KEY,
+	name       TEXT NOT NULL,
+	key_hash   TEXT NOT NULL UNIQUE, -- SHA-256 of the API key; the key itself is never stored
+	created_at DATETIME NOT NULL DEFAULT (datetime('now'))
+);
+
 CREATE TABLE IF NOT EXISTS feeds (
 	id         TEXT PRIMARY KEY,
 	name       TEXT NOT NULL,
@@ -66,6 +138,37 @@ CREATE TABLE IF NOT EXISTS events (
 	updated_at  DATETIME NOT NULL DEFAULT (datetime('now'))
 );
 
+CREATE TABLE IF NOT EXISTS attendees (
+	event_id TEXT NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+	email    TEXT NOT NULL,
+	name     TEXT NOT NULL DEFAULT '',
+	partstat TEXT NOT NULL DEFAULT 'NEEDS-ACTION',
+	PRIMARY KEY (event_id, email)
+);
+
+CREATE TABLE IF NOT EXISTS attachments (
+	id       TEXT PRIMARY KEY,
+	event_id TEXT NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+	position INTEGER NOT NULL,
+	url      TEXT NOT NULL DEFAULT '',
+	data     BLOB,
+	fmttype  TEXT NOT NULL DEFAULT '',
+	filename TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachments_event_id ON attachments(event_id);
+
+-- google_sync maps events to their mirrored Google Calendar copies. It has no
+-- foreign key so rows outlive deleted events until the sync worker removes
+-- the remote copy.
+CREATE TABLE IF NOT EXISTS google_sync (
+	event_id    TEXT PRIMARY KEY,
+	feed_id     TEXT NOT NULL,
+	google_id   TEXT NOT NULL,
+	fingerprint TEXT NOT NULL,
+	synced_at   DATETIME NOT NULL
+);
+
 CREATE INDEX IF NOT EXISTS idx_events_feed_id ON events(feed_id);
 CREATE INDEX IF NOT EXISTS idx_events_start   ON events(start_time);
 CREATE INDEX IF NOT EXISTS idx_feeds_token    ON feeds(token);
@@ -73,68 +176,321 @@ CREATE INDEX IF NOT EXISTS idx_feeds_token    ON feeds(token);
 
 // Open creates or opens the SQLite database at path and applies the schema.
 func Open(path string) (*DB, error) {
-	conn, err := sql.Open("sqlite", path+"?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)")
+	conn, err := sqlitex.Open(path)
 	if err != nil {
 		return nil, fmt.Errorf("open database: %w", err)
 	}
-	if err := conn.Ping(); err != nil {
-		conn.Close()
-		return nil, fmt.Errorf("ping database: %w", err)
-	}
-	if _, err := conn.Exec(schema); err != nil {
+	if err := migrate(conn); err != nil {
 		conn.Close()
 		return nil, fmt.Errorf("apply schema: %w", err)
 	}
 	return &DB{conn: conn}, nil
 }
 
+// migrate creates tables if they do not exist and adds columns introduced
+// after the initial schema to existing databases.
+func migrate(conn *sql.DB) error {
+	if _, err := conn.Exec(schema); err != nil {
+		return err
+	}
+
+	columns := []struct{ table, column, def string }{
+		{"feeds", "owner_id", "TEXT NOT NULL DEFAULT ''"},
+		{"events", "organizer", "TEXT NOT NULL DEFAULT ''"},
+		{"events", "organizer_name", "TEXT NOT NULL DEFAULT ''"},
+		{"events", "reminder_to", "TEXT NOT NULL DEFAULT ''"},
+		{"events", "reminder_minutes", "INTEGER NOT NULL DEFAULT 0"},
+		{"events", "reminder_sent_at", "DATETIME"},
+		{"events", "range_start", "INTEGER"},
+		{"events", "range_end", "INTEGER"},
+		{"events", "dedupe_key", "TEXT NOT NULL DEFAULT ''"},
+		{"events", "category_key", "TEXT"},
+	}
+	for _, c := range columns {
+		if err := addColumnIfNotExists(conn, c.table, c.column, c.def); err != nil {
+			return fmt.Errorf("add column %s.%s: %w", c.table, c.column, err)
+		}
+	}
+	// Created after the columns they cover so they also apply to migrated databases.
+	if _, err := conn.Exec(`CREATE INDEX IF NOT EXISTS idx_feeds_owner_id ON feeds(owner_id)`); err != nil {
+		return err
+	}
+	if _, err := conn.Exec(`CREATE INDEX IF NOT EXISTS idx_events_feed_range ON events(feed_id, range_start)`); err != nil {
+		return err
+	}
+	if _, err := conn.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_events_dedupe ON events(feed_id, dedupe_key) WHERE dedupe_key != ''`); err != nil {
+		return err
+	}
+	if err := backfillRanges(conn); err != nil {
+		return err
+	}
+	return backfillCategoryKeys(conn)
+}
+
+// backfillCategoryKeys fills category_key for events stored before the
+// column existed.
+func backfillCategoryKeys(conn *sql.DB) error {
+	rows, err := conn.Query(`SELECT id, categories FROM events WHERE category_key IS NULL`)
+	if err != nil {
+		return err
+	}
+	keys := make(map[string]string)
+	for rows.Next() {
+		var id, cats string
+		if err := rows.Scan(&id, &cats); err != nil {
+			rows.Close()
+			return err
+		}
+		keys[id] = categoryKey(cats)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for id, key := range keys {
+		if _, err := conn.Exec(`UPDATE events SET category_key = ? WHERE id = ?`, key, id); err != nil {
+			return fmt.Errorf("backfill category key for event %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// categoryKey normalizes a categories string for filtering in SQL: the
+// lowercased names, each wrapped in commas (",work,team sync,"), so a
+// category matches with instr(category_key, ",name,").
+func categoryKey(categories string) string {
+	names := SplitCategories(strings.ToLower(categories))
+	if len(names) == 0 {
+		return ""
+	}
+	return "," + strings.Join(names, ",") + ","
+}
+
+// backfillRanges fills range_start/range_end for events stored before the
+// columns existed.
+func backfillRanges(conn *sql.DB) error {
+	rows, err := conn.Query(`SELECT id, start_time, end_time, all_day FROM events WHERE range_start IS NULL`)
+	if err != nil {
+		return err
+	}
+	type pending struct {
+		id         string
+		start, end int64
+	}
+	var todo []pending
+	for rows.Next() {
+		e := &Event{}
+		if err := rows.Scan(&e.ID, &e.Start, &e.End, &e.AllDay); err != nil {
+			rows.Close()
+			return err
+		}
+		start, end := eventRange(e)
+		todo = append(todo, pending{e.ID, start, end})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, p := range todo {
+		if _, err := conn.Exec(`UPDATE events SET range_start = ?, range_end = ? WHERE id = ?`, p.start, p.end, p.id); err != nil {
+			return fmt.Errorf("backfill range for event %s: %w", p.id, err)
+		}
+	}
+	return nil
+}
+
+// eventRange returns the Unix-second interval an event occupies for range
+// queries. Times are stored as driver-formatted strings that don't compare
+// correctly across offsets, so range queries use these integer columns
+// instead. An all-day event without an end covers its day; a timed event
+// without an end is a point in time.
+func eventRange(e *Event) (int64, int64) {
+	start := e.Start.Unix()
+	switch {
+	case e.End != nil:
+		return start, e.End.Unix()
+	case e.AllDay:
+		return start, e.Start.AddDate(0, 0, 1).Unix()
+	default:
+		return start, start
+	}
+}
+
+// addColumnIfNotExists adds a column to a table if it does not already exist.
+// SQLite doesn't support IF NOT EXISTS for ALTER TABLE ADD COLUMN, so we
+// check the schema first.
+func addColumnIfNotExists(conn *sql.DB, table, column, colDef string) error {
+	rows, err := conn.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid int
+		var name, ctype string
+		var notnull int
+		var dfltValue sql.NullString
+		var pk int
+		if err := rows.Scan(&cid, &name, &ctype, &notnull, &dfltValue, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil // column already exists
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = conn.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, colDef))
+	return err
+}
+
+// Ping checks that the database answers.
+func (db *DB) Ping(ctx context.Context) error {
+	return db.conn.PingContext(ctx)
+}
+
 // Close shuts down the database connection.
 func (db *DB) Close() error {
 	return db.conn.Close()
 }
 
-// --- Feed operations ---
+// RunCheckpoints folds the write-ahead log into the database every
+// sqlitex.CheckpointEvery until ctx is done.
+func (db *DB) RunCheckpoints(ctx context.Context) {
+	sqlitex.RunCheckpoints(ctx, db.conn, sqlitex.CheckpointEvery)
+}
 
-// CreateFeed inserts a new feed.
-func (db *DB) CreateFeed(f *Feed) error {
+// Backup checks the database for corruption, then writes a copy of it to
+// dest while it stays in use.
+func (db *DB) Backup(ctx context.Context, dest string) error {
+	if err := sqlitex.IntegrityCheck(ctx, db.conn); err != nil {
+		return err
+	}
+	return sqlitex.Backup(ctx, db.conn, dest)
+}
+
+// SetObserver registers fn to receive the duration of each database
+// operation, keyed by a short operation name. Call it before the DB is
+// shared between goroutines.
+func (db *DB) SetObserver(fn func(op string, d time.Duration)) {
+	db.observe = fn
+}
+
+// timed starts timing op; the returned func reports the elapsed time and is
+// meant to be deferred.
+func (db *DB) timed(op string) func() {
+	if db.observe == nil {
+		return func() {}
+	}
+	start := time.Now()
+	return func() { db.observe(op, time.Since(start)) }
+}
+
+// --- Owner operations ---
+
+// hashKey returns the stored form of an API key.
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateOwner inserts a new owner identified by apiKey.
+func (db *DB) CreateOwner(o *Owner, apiKey string) error {
+	defer db.timed("create_owner")()
 	_, err := db.conn.Exec(
-		`INSERT INTO feeds (id, name, token, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
-		f.ID, f.Name, f.Token, f.CreatedAt, f.UpdatedAt,
+		`INSERT INTO owners (id, name, key_hash, created_at) VALUES (?, ?, ?, ?)`,
+		o.ID, o.Name, hashKey(apiKey), o.CreatedAt,
 	)
 	return err
 }
 
-// FeedByToken looks up a feed by its subscription token.
-func (db *DB) FeedByToken(token string) (*Feed, error) {
-	f := &Feed{}
+// OwnerByAPIKey looks up the owner an API key belongs to.
+// Returns sql.ErrNoRows if the key is unknown.
+func (db *DB) OwnerByAPIKey(apiKey string) (*Owner, error) {
+	defer db.timed("owner_by_api_key")()
+	o := &Owner{}
 	err := db.conn.QueryRow(
-		`SELECT id, name, token, created_at, updated_at FROM feeds WHERE token = ?`,
-		token,
-	).Scan(&f.ID, &f.Name, &f.Token, &f.CreatedAt, &f.UpdatedAt)
+		`SELECT id, name, created_at FROM owners WHERE key_hash = ?`,
+		hashKey(apiKey),
+	).Scan(&o.ID, &o.Name, &o.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
-	return f, nil
+	return o, nil
 }
 
-// FeedByID looks up a feed by ID.
-func (db *DB) FeedByID(id string) (*Feed, error) {
-	f := &Feed{}
-	err := db.conn.QueryRow(
-		`SELECT id, name, token, created_at, updated_at FROM feeds WHERE id = ?`,
-		id,
-	).Scan(&f.ID, &f.Name, &f.Token, &f.CreatedAt, &f.UpdatedAt)
+// HasOwners reports whether any owner exists. With no owners the service
+// runs unauthenticated.
+func (db *DB) HasOwners() (bool, error) {
+	defer db.timed("has_owners")()
+	var n int
+	err := db.conn.QueryRow(`SELECT COUNT(*) FROM owners`).Scan(&n)
+	return n > 0, err
+}
+
+// AssignUnownedFeeds gives every feed without an owner to ownerID, returning
+// how many were adopted. Used when auth is first enabled on an existing
+// database.
+func (db *DB) AssignUnownedFeeds(ownerID string) (int64, error) {
+	res, err := db.conn.Exec(`UPDATE feeds SET owner_id = ? WHERE owner_id = ''`, ownerID)
 	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// --- Feed operations ---
+
+// feedColumns is the SELECT column list for feed queries.
+const feedColumns = `id, owner_id, name, token, created_at, updated_at`
+
+func scanFeed(row interface{ Scan(...interface{}) error }) (*Feed, error) {
+	f := &Feed{}
+	if err := row.Scan(&f.ID, &f.OwnerID, &f.Name, &f.Token, &f.CreatedAt, &f.UpdatedAt); err != nil {
 		return nil, err
 	}
 	return f, nil
 }
 
+// CreateFeed inserts a new feed.
+func (db *DB) CreateFeed(f *Feed) error {
+	defer db.timed("create_feed")()
+	_, err := db.conn.Exec(
+		`INSERT INTO feeds (id, owner_id, name, token, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		f.ID, f.OwnerID, f.Name, f.Token, f.CreatedAt, f.UpdatedAt,
+	)
+	return err
+}
+
+// FeedByToken looks up a feed by its subscription token.
+func (db *DB) FeedByToken(token string) (*Feed, error) {
+	defer db.timed("feed_by_token")()
+	return scanFeed(db.conn.QueryRow(`SELECT `+feedColumns+` FROM feeds WHERE token = ?`, token))
+}
+
+// FeedByID looks up a feed by ID.
+func (db *DB) FeedByID(id string) (*Feed, error) {
+	defer db.timed("feed_by_id")()
+	return scanFeed(db.conn.QueryRow(`SELECT `+feedColumns+` FROM feeds WHERE id = ?`, id))
+}
+
 // ListFeeds returns all feeds.
 func (db *DB) ListFeeds() ([]*Feed, error) {
-	rows, err := db.conn.Query(
-		`SELECT id, name, token, created_at, updated_at FROM feeds ORDER BY created_at DESC`,
-	)
+	defer db.timed("list_feeds")()
+	return db.queryFeeds(`SELECT ` + feedColumns + ` FROM feeds ORDER BY created_at DESC`)
+}
+
+// ListFeedsByOwner returns the feeds belonging to ownerID.
+func (db *DB) ListFeedsByOwner(ownerID string) ([]*Feed, error) {
+	defer db.timed("list_feeds_by_owner")()
+	return db.queryFeeds(`SELECT `+feedColumns+` FROM feeds WHERE owner_id = ? ORDER BY created_at DESC`, ownerID)
+}
+
+func (db *DB) queryFeeds(query string, args ...interface{}) ([]*Feed, error) {
+	rows, err := db.conn.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -142,8 +498,8 @@ func (db *DB) ListFeeds() ([]*Feed, error) {
 
 	var feeds []*Feed
 	for rows.Next() {
-		f := &Feed{}
-		if err := rows.Scan(&f.ID, &f.Name, &f.Token, &f.CreatedAt, &f.UpdatedAt); err != nil {
+		f, err := scanFeed(rows)
+		if err != nil {
 			return nil, err
 		}
 		feeds = append(feeds, f)
@@ -151,43 +507,120 @@ func (db *DB) ListFeeds() ([]*Feed, error) {
 	return feeds, rows.Err()
 }
 
+// CountFeeds returns the number of feeds across all owners.
+func (db *DB) CountFeeds() (int, error) {
+	defer db.timed("count_feeds")()
+	var n int
+	err := db.conn.QueryRow(`SELECT COUNT(*) FROM feeds`).Scan(&n)
+	return n, err
+}
+
 // DeleteFeed removes a feed and its events (CASCADE).
 func (db *DB) DeleteFeed(id string) error {
+	defer db.timed("delete_feed")()
 	_, err := db.conn.Exec(`DELETE FROM feeds WHERE id = ?`, id)
 	return err
 }
 
 // --- Event operations ---
 
-// CreateEvent inserts a new event.
+// eventColumns is the SELECT column list for event queries.
+const eventColumns = `id, feed_id, summary, description, location, url, start_time, end_time, all_day, deadline, status, categories, organizer, organizer_name, reminder_to, reminder_minutes, reminder_sent_at, dedupe_key, created_at, updated_at`
+
+// scanEvent scans a row selected with eventColumns into an Event.
+func scanEvent(row interface{ Scan(...interface{}) error }) (*Event, error) {
+	e := &Event{}
+	var r Reminder
+	err := row.Scan(
+		&e.ID, &e.FeedID, &e.Summary, &e.Description, &e.Location, &e.URL,
+		&e.Start, &e.End, &e.AllDay, &e.Deadline, &e.Status, &e.Categories,
+		&e.Organizer, &e.OrganizerCN,
+		&r.To, &r.MinutesBefore, &r.SentAt,
+		&e.DedupeKey,
+		&e.CreatedAt, &e.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if r.To != "" {
+		e.Reminder = &r
+	}
+	return e, nil
+}
+
+// CreateEvent inserts a new event along with its attendees and attachments.
 func (db *DB) CreateEvent(e *Event) error {
-	_, err := db.conn.Exec(
-		`INSERT INTO events (id, feed_id, summary, description, location, url, start_time, end_time, all_day, deadline, status, categories, created_at, updated_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+	defer db.timed("create_event")()
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rangeStart, rangeEnd := eventRange(e)
+	_, err = tx.Exec(
+		`INSERT INTO events (id, feed_id, summary, description, location, url, start_time, end_time, all_day, deadline, status, categories, category_key, organizer, organizer_name, reminder_to, reminder_minutes, range_start, range_end, dedupe_key, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		e.ID, e.FeedID, e.Summary, e.Description, e.Location, e.URL,
-		e.Start, e.End, e.AllDay, e.Deadline, e.Status, e.Categories,
+		e.Start, e.End, e.AllDay, e.Deadline, e.Status, e.Categories, categoryKey(e.Categories),
+		e.Organizer, e.OrganizerCN,
+		reminderTo(e), reminderMinutes(e),
+		rangeStart, rangeEnd,
+		e.DedupeKey,
 		e.CreatedAt, e.UpdatedAt,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	if err := insertAttendees(tx, e.ID, e.Attendees); err != nil {
+		return err
+	}
+	if err := insertAttachments(tx, e.ID, e.Attachments); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-// UpdateEvent updates an existing event.
+// UpdateEvent updates an existing event. Attendees are managed separately
+// via SetAttendees and UpdateAttendeeStatus; attachments are left
+// unchanged. Changing the event clears any recorded reminder delivery so a
+// rescheduled event is reminded again.
 func (db *DB) UpdateEvent(e *Event) error {
+	defer db.timed("update_event")()
+	rangeStart, rangeEnd := eventRange(e)
 	_, err := db.conn.Exec(
-		`UPDATE events SET summary=?, description=?, location=?, url=?, start_time=?, end_time=?, all_day=?, deadline=?, status=?, categories=?, updated_at=?
+		`UPDATE events SET summary=?, description=?, location=?, url=?, start_time=?, end_time=?, all_day=?, deadline=?, status=?, categories=?, category_key=?, organizer=?, organizer_name=?, reminder_to=?, reminder_minutes=?, reminder_sent_at=NULL, range_start=?, range_end=?, updated_at=?
 		 WHERE id = ?`,
 		e.Summary, e.Description, e.Location, e.URL,
-		e.Start, e.End, e.AllDay, e.Deadline, e.Status, e.Categories,
+		e.Start, e.End, e.AllDay, e.Deadline, e.Status, e.Categories, categoryKey(e.Categories),
+		e.Organizer, e.OrganizerCN,
+		reminderTo(e), reminderMinutes(e),
+		rangeStart, rangeEnd,
 		e.UpdatedAt, e.ID,
 	)
 	return err
 }
 
-// EventsByFeed returns all events for a feed, ordered by start time.
+func reminderTo(e *Event) string {
+	if e.Reminder == nil {
+		return ""
+	}
+	return e.Reminder.To
+}
+
+func reminderMinutes(e *Event) int {
+	if e.Reminder == nil {
+		return 0
+	}
+	return e.Reminder.MinutesBefore
+}
+
+// EventsByFeed returns all events for a feed, ordered by start time,
+// with their attendees and attachments populated.
 func (db *DB) EventsByFeed(feedID string) ([]*Event, error) {
+	defer db.timed("events_by_feed")()
 	rows, err := db.conn.Query(
-		`SELECT id, feed_id, summary, description, location, url, start_time, end_time, all_day, deadline, status, categories, created_at, updated_at
-		 FROM events WHERE feed_id = ? ORDER BY start_time ASC`,
+		`SELECT `+eventColumns+` FROM events WHERE feed_id = ? ORDER BY start_time ASC`,
 		feedID,
 	)
 	if err != nil {
@@ -197,39 +630,542 @@ func (db *DB) EventsByFeed(feedID string) ([]*Event, error) {
 
 	var events []*Event
 	for rows.Next() {
-		e := &Event{}
-		if err := rows.Scan(
-			&e.ID, &e.FeedID, &e.Summary, &e.Description, &e.Location, &e.URL,
-			&e.Start, &e.End, &e.AllDay, &e.Deadline, &e.Status, &e.Categories,
-			&e.CreatedAt, &e.UpdatedAt,
-		); err != nil {
+		e, err := scanEvent(rows)
+		if err != nil {
 			return nil, err
 		}
 		events = append(events, e)
 	}
-	return events, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	attendees, err := db.attendeesByFeed(feedID)
+	if err != nil {
+		return nil, err
+	}
+	attachments, err := db.attachmentsByFeed(feedID)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range events {
+		e.Attendees = attendees[e.ID]
+		e.Attachments = attachments[e.ID]
+	}
+	return events, nil
+}
+
+// EventQuery selects a page of a feed's events.
+type EventQuery struct {
+	// From and To bound the events returned to those overlapping [From, To).
+	// A zero time leaves that side unbounded.
+	From, To time.Time
+	// Limit caps the number of events returned; 0 means no limit.
+	Limit int
+	// Offset skips that many matching events (ignored when Around is set).
+	Offset int
+	// Around, if set, makes a limited query keep the events starting
+	// closest to it rather than the earliest ones, so a truncated feed
+	// keeps what is current.
+	Around time.Time
+	// Categories, if set, keeps events carrying at least one of these
+	// categories, compared case-insensitively. The limit and total apply
+	// to the filtered events.
+	Categories []string
+}
+
+// QueryEvents returns the events of a feed matching q, ordered by start time
+// and with attendees and attachments populated, along with the total number
+// of matching events before Limit and Offset were applied.
+func (db *DB) QueryEvents(feedID string, q EventQuery) ([]*Event, int, error) {
+	defer db.timed("query_events")()
+	where := `feed_id = ?`
+	args := []interface{}{feedID}
+	if !q.From.IsZero() {
+		// Point events (range_end = range_start) count if they start at From.
+		where += ` AND (range_end > ? OR range_start >= ?)`
+		args = append(args, q.From.Unix(), q.From.Unix())
+	}
+	if !q.To.IsZero() {
+		where += ` AND range_start < ?`
+		args = append(args, q.To.Unix())
+	}
+	if len(q.Categories) > 0 {
+		var conds []string
+		for _, c := range q.Categories {
+			conds = append(conds, `instr(category_key, ?) > 0`)
+			args = append(args, categoryKey(c))
+		}
+		where += ` AND (` + strings.Join(conds, ` OR `) + `)`
+	}
+
+	var total int
+	if err := db.conn.QueryRow(`SELECT COUNT(*) FROM events WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + eventColumns + ` FROM events WHERE ` + where
+	if q.Limit > 0 && !q.Around.IsZero() {
+		query += ` ORDER BY ABS(range_start - ?), range_start LIMIT ?`
+		args = append(args, q.Around.Unix(), q.Limit)
+	} else {
+		query += ` ORDER BY range_start, id`
+		if q.Limit > 0 {
+			query += ` LIMIT ? OFFSET ?`
+			args = append(args, q.Limit, q.Offset)
+		}
+	}
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var events []*Event
+	for rows.Next() {
+		e, err := scanEvent(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Start.Before(events[j].Start) })
+
+	if err := db.fillChildren(events); err != nil {
+		return nil, 0, err
+	}
+	return events, total, nil
+}
+
+// fillChildren loads attendees and attachments for a set of events.
+func (db *DB) fillChildren(events []*Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	byID := make(map[string]*Event, len(events))
+	for _, e := range events {
+		byID[e.ID] = e
+	}
+
+	// Batch the IN lists to stay well under SQLite's variable limit.
+	const batch = 500
+	for i := 0; i < len(events); i += batch {
+		chunk := events[i:min(i+batch, len(events))]
+		ids := make([]interface{}, len(chunk))
+		for j, e := range chunk {
+			ids[j] = e.ID
+		}
+		in := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+
+		rows, err := db.conn.Query(
+			`SELECT event_id, email, name, partstat FROM attendees WHERE event_id IN (`+in+`) ORDER BY event_id, email`,
+			ids...,
+		)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var a Attendee
+			if err := rows.Scan(&a.EventID, &a.Email, &a.Name, &a.PartStat); err != nil {
+				rows.Close()
+				return err
+			}
+			byID[a.EventID].Attendees = append(byID[a.EventID].Attendees, a)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		rows, err = db.conn.Query(
+			`SELECT id, event_id, url, data, fmttype, filename FROM attachments WHERE event_id IN (`+in+`) ORDER BY event_id, position`,
+			ids...,
+		)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var a Attachment
+			if err := rows.Scan(&a.ID, &a.EventID, &a.URL, &a.Data, &a.FmtType, &a.Filename); err != nil {
+				rows.Close()
+				return err
+			}
+			byID[a.EventID].Attachments = append(byID[a.EventID].Attachments, a)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// EventByID returns a single event.
+// EventByID returns a single event with its attendees and attachments populated.
 func (db *DB) EventByID(id string) (*Event, error) {
-	e := &Event{}
-	err := db.conn.QueryRow(
-		`SELECT id, feed_id, summary, description, location, url, start_time, end_time, all_day, deadline, status, categories, created_at, updated_at
-		 FROM events WHERE id = ?`,
+	defer db.timed("event_by_id")()
+	e, err := scanEvent(db.conn.QueryRow(
+		`SELECT `+eventColumns+` FROM events WHERE id = ?`,
 		id,
-	).Scan(
-		&e.ID, &e.FeedID, &e.Summary, &e.Description, &e.Location, &e.URL,
-		&e.Start, &e.End, &e.AllDay, &e.Deadline, &e.Status, &e.Categories,
-		&e.CreatedAt, &e.UpdatedAt,
-	)
+	))
+	if err != nil {
+		return nil, err
+	}
+	e.Attendees, err = db.AttendeesByEvent(id)
+	if err != nil {
+		return nil, err
+	}
+	e.Attachments, err = db.AttachmentsByEvent(id)
 	if err != nil {
 		return nil, err
 	}
 	return e, nil
 }
 
-// DeleteEvent removes a single event.
+// EventByDedupeKey returns the event created in a feed with the given dedupe
+// key, with its attendees and attachments populated. Returns sql.ErrNoRows
+// if there is none.
+func (db *DB) EventByDedupeKey(feedID, key string) (*Event, error) {
+	defer db.timed("event_by_dedupe_key")()
+	var id string
+	err := db.conn.QueryRow(
+		`SELECT id FROM events WHERE feed_id = ? AND dedupe_key = ? AND dedupe_key != ''`,
+		feedID, key,
+	).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+	return db.EventByID(id)
+}
+
+// CountEvents returns the number of events across all feeds.
+func (db *DB) CountEvents() (int, error) {
+	defer db.timed("count_events")()
+	var n int
+	err := db.conn.QueryRow(`SELECT COUNT(*) FROM events`).Scan(&n)
+	return n, err
+}
+
+// DeleteEvent removes a single event with its attendees and attachments (CASCADE).
 func (db *DB) DeleteEvent(id string) error {
+	defer db.timed("delete_event")()
 	_, err := db.conn.Exec(`DELETE FROM events WHERE id = ?`, id)
 	return err
 }
+
+// --- Reminder operations ---
+
+// PendingReminders returns events that have an SMS reminder which has not
+// been sent yet, excluding cancelled events. Whether a reminder is due is
+// decided by the caller; the set is small because sent reminders drop out.
+func (db *DB) PendingReminders() ([]*Event, error) {
+	defer db.timed("pending_reminders")()
+	rows, err := db.conn.Query(
+		`SELECT ` + eventColumns + ` FROM events
+		 WHERE reminder_to != '' AND reminder_sent_at IS NULL AND status != 'CANCELLED'
+		 ORDER BY start_time ASC`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*Event
+	for rows.Next() {
+		e, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// MarkReminderSent records that an event's reminder was handled at t.
+func (db *DB) MarkReminderSent(eventID string, t time.Time) error {
+	defer db.timed("mark_reminder_sent")()
+	_, err := db.conn.Exec(`UPDATE events SET reminder_sent_at = ? WHERE id = ?`, t, eventID)
+	return err
+}
+
+// --- Category operations ---
+
+// CategoryCount is a category in use within a feed and how many events carry it.
+type CategoryCount struct {
+	Name   string `json:"name"`
+	Events int    `json:"events"`
+}
+
+// SplitCategories parses a comma-separated categories string into trimmed,
+// non-empty names.
+func SplitCategories(s string) []string {
+	var out []string
+	for _, c := range strings.Split(s, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// CategoriesByFeed returns the distinct categories used by a feed's events,
+// sorted by name. Names are compared case-insensitively; the first spelling
+// seen wins.
+func (db *DB) CategoriesByFeed(feedID string) ([]CategoryCount, error) {
+	defer db.timed("categories_by_feed")()
+	rows, err := db.conn.Query(
+		`SELECT categories FROM events WHERE feed_id = ? AND categories != '' ORDER BY created_at`,
+		feedID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	index := make(map[string]int)
+	var out []CategoryCount
+	for rows.Next() {
+		var cats string
+		if err := rows.Scan(&cats); err != nil {
+			return nil, err
+		}
+		seen := make(map[string]bool)
+		for _, c := range SplitCategories(cats) {
+			key := strings.ToLower(c)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			i, ok := index[key]
+			if !ok {
+				i = len(out)
+				index[key] = i
+				out = append(out, CategoryCount{Name: c})
+			}
+			out[i].Events++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return strings.ToLower(out[i].Name) < strings.ToLower(out[j].Name)
+	})
+	return out, nil
+}
+
+// --- Attendee operations ---
+
+// SetAttendees replaces the attendee list for an event.
+func (db *DB) SetAttendees(eventID string, attendees []Attendee) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM attendees WHERE event_id = ?`, eventID); err != nil {
+		return err
+	}
+	if err := insertAttendees(tx, eventID, attendees); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateAttendeeStatus sets the PARTSTAT of a single attendee.
+// Returns sql.ErrNoRows if the attendee is not on the event.
+func (db *DB) UpdateAttendeeStatus(eventID, email, partstat string) error {
+	defer db.timed("update_attendee_status")()
+	res, err := db.conn.Exec(
+		`UPDATE attendees SET partstat = ? WHERE event_id = ? AND email = ?`,
+		partstat, eventID, email,
+	)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// AttendeesByEvent returns the attendees of an event, ordered by email.
+func (db *DB) AttendeesByEvent(eventID string) ([]Attendee, error) {
+	rows, err := db.conn.Query(
+		`SELECT event_id, email, name, partstat FROM attendees WHERE event_id = ? ORDER BY email`,
+		eventID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Attendee
+	for rows.Next() {
+		var a Attendee
+		if err := rows.Scan(&a.EventID, &a.Email, &a.Name, &a.PartStat); err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// attendeesByFeed loads every attendee of every event in a feed in one query,
+// keyed by event ID.
+func (db *DB) attendeesByFeed(feedID string) (map[string][]Attendee, error) {
+	rows, err := db.conn.Query(
+		`SELECT a.event_id, a.email, a.name, a.partstat
+		 FROM attendees a JOIN events e ON e.id = a.event_id
+		 WHERE e.feed_id = ? ORDER BY a.event_id, a.email`,
+		feedID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[string][]Attendee)
+	for rows.Next() {
+		var a Attendee
+		if err := rows.Scan(&a.EventID, &a.Email, &a.Name, &a.PartStat); err != nil {
+			return nil, err
+		}
+		out[a.EventID] = append(out[a.EventID], a)
+	}
+	return out, rows.Err()
+}
+
+// --- Attachment operations ---
+
+// AttachmentsByEvent returns an event's attachments in the order they were added.
+func (db *DB) AttachmentsByEvent(eventID string) ([]Attachment, error) {
+	rows, err := db.conn.Query(
+		`SELECT id, event_id, url, data, fmttype, filename FROM attachments WHERE event_id = ? ORDER BY position`,
+		eventID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Attachment
+	for rows.Next() {
+		var a Attachment
+		if err := rows.Scan(&a.ID, &a.EventID, &a.URL, &a.Data, &a.FmtType, &a.Filename); err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// attachmentsByFeed loads every attachment of every event in a feed in one
+// query, keyed by event ID.
+func (db *DB) attachmentsByFeed(feedID string) (map[string][]Attachment, error) {
+	rows, err := db.conn.Query(
+		`SELECT a.id, a.event_id, a.url, a.data, a.fmttype, a.filename
+		 FROM attachments a JOIN events e ON e.id = a.event_id
+		 WHERE e.feed_id = ? ORDER BY a.event_id, a.position`,
+		feedID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[string][]Attachment)
+	for rows.Next() {
+		var a Attachment
+		if err := rows.Scan(&a.ID, &a.EventID, &a.URL, &a.Data, &a.FmtType, &a.Filename); err != nil {
+			return nil, err
+		}
+		out[a.EventID] = append(out[a.EventID], a)
+	}
+	return out, rows.Err()
+}
+
+func insertAttachments(tx *sql.Tx, eventID string, attachments []Attachment) error {
+	for i, a := range attachments {
+		if _, err := tx.Exec(
+			`INSERT INTO attachments (id, event_id, position, url, data, fmttype, filename) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			a.ID, eventID, i, a.URL, a.Data, a.FmtType, a.Filename,
+		); err != nil {
+			return fmt.Errorf("insert attachment %s: %w", a.ID, err)
+		}
+	}
+	return nil
+}
+
+// --- Google sync operations ---
+
+// GoogleMapping links a local event to its copy in Google Calendar.
+type GoogleMapping struct {
+	EventID     string
+	FeedID      string
+	GoogleID    string
+	Fingerprint string // hash of the last pushed representation
+	SyncedAt    time.Time
+}
+
+// GoogleMappings returns the sync mappings for a feed, keyed by event ID.
+func (db *DB) GoogleMappings(feedID string) (map[string]GoogleMapping, error) {
+	rows, err := db.conn.Query(
+		`SELECT event_id, feed_id, google_id, fingerprint, synced_at FROM google_sync WHERE feed_id = ?`,
+		feedID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[string]GoogleMapping)
+	for rows.Next() {
+		var m GoogleMapping
+		if err := rows.Scan(&m.EventID, &m.FeedID, &m.GoogleID, &m.Fingerprint, &m.SyncedAt); err != nil {
+			return nil, err
+		}
+		out[m.EventID] = m
+	}
+	return out, rows.Err()
+}
+
+// SaveGoogleMapping inserts or replaces the mapping for an event.
+func (db *DB) SaveGoogleMapping(m GoogleMapping) error {
+	_, err := db.conn.Exec(
+		`INSERT INTO google_sync (event_id, feed_id, google_id, fingerprint, synced_at) VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT(event_id) DO UPDATE SET google_id = excluded.google_id, fingerprint = excluded.fingerprint, synced_at = excluded.synced_at`,
+		m.EventID, m.FeedID, m.GoogleID, m.Fingerprint, m.SyncedAt,
+	)
+	return err
+}
+
+// DeleteGoogleMapping removes the mapping for an event.
+func (db *DB) DeleteGoogleMapping(eventID string) error {
+	_, err := db.conn.Exec(`DELETE FROM google_sync WHERE event_id = ?`, eventID)
+	return err
+}
+
+func insertAttendees(tx *sql.Tx, eventID string, attendees []Attendee) error {
+	for _, a := range attendees {
+		partstat := a.PartStat
+		if partstat == "" {
+			partstat = PartStatNeedsAction
+		}
+		if _, err := tx.Exec(
+			`INSERT INTO attendees (event_id, email, name, partstat) VALUES (?, ?, ?, ?)`,
+			eventID, a.Email, a.Name, partstat,
+		); err != nil {
+			return fmt.Errorf("insert attendee %s: %w", a.Email, err)
+		}
+	}
+	return nil
+}
@@ -1,7 +1,9 @@
 package database
 
 import (
+	"database/sql"
 	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -193,3 +195,180 @@ func TestCascadeDelete(t *testing.T) {
 		t.Error("expected event to be deleted via cascade")
 	}
 }
+
+func TestAttendees(t *testing.T) {
+	db := testDB(t)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	if err := db.CreateFeed(&Feed{ID: "feed-1", Name: "Test", Token: "tok", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+
+	event := &Event{
+		ID: "evt-1", FeedID: "feed-1", Summary: "Planning",
+		Start: now, Status: "CONFIRMED",
+		Organizer: "boss@example.com", OrganizerCN: "The Boss",
+		Attendees: []Attendee{
+			{Email: "b@example.com", Name: "Bee"},
+			{Email: "a@example.com", PartStat: PartStatAccepted},
+		},
+		CreatedAt: now, UpdatedAt: now,
+	}
+	if err := db.CreateEvent(event); err != nil {
+		t.Fatalf("create event: %v", err)
+	}
+
+	got, err := db.EventByID("evt-1")
+	if err != nil {
+		t.Fatalf("event by id: %v", err)
+	}
+	if got.Organizer != "boss@example.com" || got.OrganizerCN != "The Boss" {
+		t.Errorf("organizer not persisted: %+v", got)
+	}
+	if len(got.Attendees) != 2 {
+		t.Fatalf("expected 2 attendees, got %d", len(got.Attendees))
+	}
+	if got.Attendees[0].Email != "a@example.com" || got.Attendees[0].PartStat != PartStatAccepted {
+		t.Errorf("unexpected first attendee: %+v", got.Attendees[0])
+	}
+	if got.Attendees[1].PartStat != PartStatNeedsAction {
+		t.Errorf("attendee partstat should default to NEEDS-ACTION, got %q", got.Attendees[1].PartStat)
+	}
+
+	// Update status
+	if err := db.UpdateAttendeeStatus("evt-1", "b@example.com", PartStatDeclined); err != nil {
+		t.Fatalf("update attendee: %v", err)
+	}
+	if err := db.UpdateAttendeeStatus("evt-1", "nobody@example.com", PartStatDeclined); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows for unknown attendee, got %v", err)
+	}
+
+	// Feed listing populates attendees
+	events, err := db.EventsByFeed("feed-1")
+	if err != nil {
+		t.Fatalf("events by feed: %v", err)
+	}
+	if len(events) != 1 || len(events[0].Attendees) != 2 || events[0].Attendees[1].PartStat != PartStatDeclined {
+		t.Errorf("events by feed did not populate attendees: %+v", events)
+	}
+
+	// Replace the list
+	if err := db.SetAttendees("evt-1", []Attendee{{Email: "c@example.com"}}); err != nil {
+		t.Fatalf("set attendees: %v", err)
+	}
+	attendees, err := db.AttendeesByEvent("evt-1")
+	if err != nil {
+		t.Fatalf("attendees by event: %v", err)
+	}
+	if len(attendees) != 1 || attendees[0].Email != "c@example.com" {
+		t.Errorf("expected only c@example.com, got %+v", attendees)
+	}
+
+	// Deleting the event cascades to attendees
+	if err := db.DeleteEvent("evt-1"); err != nil {
+		t.Fatalf("delete event: %v", err)
+	}
+	attendees, err = db.AttendeesByEvent("evt-1")
+	if err != nil {
+		t.Fatalf("attendees after delete: %v", err)
+	}
+	if len(attendees) != 0 {
+		t.Errorf("expected attendees to cascade-delete, got %d", len(attendees))
+	}
+}
+
+func TestOwners(t *testing.T) {
+	db := testDB(t)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	if has, err := db.HasOwners(); err != nil || has {
+		t.Fatalf("fresh db: has=%v err=%v", has, err)
+	}
+
+	// A feed created before ownership existed.
+	if err := db.CreateFeed(&Feed{ID: "legacy", Name: "Legacy", Token: "t1", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+
+	if err := db.CreateOwner(&Owner{ID: "o1", Name: "alice", CreatedAt: now}, "key-1"); err != nil {
+		t.Fatalf("create owner: %v", err)
+	}
+	owner, err := db.OwnerByAPIKey("key-1")
+	if err != nil || owner.ID != "o1" {
+		t.Fatalf("owner by key: %+v, %v", owner, err)
+	}
+	if _, err := db.OwnerByAPIKey("wrong"); err != sql.ErrNoRows {
+		t.Errorf("unknown key: expected sql.ErrNoRows, got %v", err)
+	}
+
+	n, err := db.AssignUnownedFeeds("o1")
+	if err != nil || n != 1 {
+		t.Fatalf("assign unowned: n=%d err=%v", n, err)
+	}
+	if err := db.CreateFeed(&Feed{ID: "other", OwnerID: "o2", Name: "Other", Token: "t2", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+
+	feeds, err := db.ListFeedsByOwner("o1")
+	if err != nil {
+		t.Fatalf("list by owner: %v", err)
+	}
+	if len(feeds) != 1 || feeds[0].ID != "legacy" || feeds[0].OwnerID != "o1" {
+		t.Errorf("unexpected feeds for o1: %+v", feeds)
+	}
+}
+
+func TestQueryEvents(t *testing.T) {
+	db := testDB(t)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := db.CreateFeed(&Feed{ID: "feed-1", Name: "Test", Token: "tok", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+
+	// Stored times keep their offsets, so string comparison would misorder
+	// these; the range columns must not.
+	plus2 := time.FixedZone("+02", 2*60*60)
+	end := now.Add(3 * time.Hour)
+	events := []*Event{
+		{ID: "early", Start: now.Add(-48 * time.Hour)},
+		{ID: "offset", Start: time.Date(2026, 3, 1, 13, 0, 0, 0, plus2)}, // 11:00 UTC
+		{ID: "spanning", Start: now.Add(-2 * time.Hour), End: &end},
+		{ID: "allday", Start: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), AllDay: true},
+		{ID: "late", Start: now.Add(48 * time.Hour)},
+	}
+	for _, e := range events {
+		e.FeedID, e.Summary, e.Status = "feed-1", e.ID, "CONFIRMED"
+		e.CreatedAt, e.UpdatedAt = now, now
+		if err := db.CreateEvent(e); err != nil {
+			t.Fatalf("create event %s: %v", e.ID, err)
+		}
+	}
+
+	ids := func(es []*Event) string {
+		var out []string
+		for _, e := range es {
+			out = append(out, e.ID)
+		}
+		return strings.Join(out, ",")
+	}
+
+	got, total, err := db.QueryEvents("feed-1", EventQuery{From: now, To: now.Add(24 * time.Hour)})
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	// "offset" ended at 11:00 UTC as a point event, so only the spanning and
+	// all-day events overlap [12:00, +24h).
+	if ids(got) != "spanning,allday" || total != 2 {
+		t.Errorf("window: got %s (total %d)", ids(got), total)
+	}
+
+	got, total, _ = db.QueryEvents("feed-1", EventQuery{Limit: 2, Offset: 1})
+	if ids(got) != "spanning,offset" || total != 5 {
+		t.Errorf("page: got %s (total %d)", ids(got), total)
+	}
+
+	got, _, _ = db.QueryEvents("feed-1", EventQuery{Limit: 2, Around: now})
+	if ids(got) != "spanning,offset" {
+		t.Errorf("around: got %s", ids(got))
+	}
+}
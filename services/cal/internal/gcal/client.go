@@ -0,0 +1,229 @@
+// Package gcal mirrors feeds into Google Calendar for people who live in
+// Google Calendar but author events in nexus-cal.
+//
+// The sync is one-way: nexus-cal is the source of truth and edits made in
+// Google are overwritten on the next change. The client talks to the
+// Calendar v3 REST API directly using an OAuth refresh token, so no Google
+// SDK is required.
+package gcal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned when the remote event no longer exists (404/410),
+// e.g. because it was deleted by hand in Google Calendar.
+var ErrNotFound = errors.New("google event not found")
+
+// EventDateTime is a Google Calendar start or end. Exactly one of Date
+// (all-day, "2006-01-02") or DateTime (RFC 3339) is set.
+type EventDateTime struct {
+	Date     string `json:"date,omitempty"`
+	DateTime string `json:"dateTime,omitempty"`
+}
+
+// EventSource links a Google event back to its origin.
+type EventSource struct {
+	Title string `json:"title,omitempty"`
+	URL   string `json:"url"`
+}
+
+// Event is the subset of the Google Calendar event resource that is synced.
+type Event struct {
+	ID          string        `json:"id,omitempty"`
+	Summary     string        `json:"summary"`
+	Description string        `json:"description,omitempty"`
+	Location    string        `json:"location,omitempty"`
+	Status      string        `json:"status,omitempty"` // confirmed, tentative, cancelled
+	Start       EventDateTime `json:"start"`
+	End         EventDateTime `json:"end"`
+	Source      *EventSource  `json:"source,omitempty"`
+}
+
+// API is the set of Google Calendar operations the syncer needs.
+type API interface {
+	Insert(ctx context.Context, calendarID string, e Event) (string, error)
+	Update(ctx context.Context, calendarID, eventID string, e Event) error
+	Delete(ctx context.Context, calendarID, eventID string) error
+}
+
+const (
+	defaultBaseURL  = "https://www.googleapis.com/calendar/v3"
+	defaultTokenURL = "https://oauth2.googleapis.com/token"
+)
+
+// Client calls the Google Calendar API, refreshing its access token as needed.
+type Client struct {
+	http         *http.Client
+	baseURL      string
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	refreshToken string
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for all requests.
+func WithHTTPClient(c *http.Client) Option {
+	return func(cl *Client) { cl.http = c }
+}
+
+// WithEndpoints overrides the API base URL and token URL (used in tests).
+func WithEndpoints(baseURL, tokenURL string) Option {
+	return func(cl *Client) {
+		cl.baseURL = strings.TrimSuffix(baseURL, "/")
+		cl.tokenURL = tokenURL
+	}
+}
+
+// NewClient creates a Client authorised by an OAuth client and a refresh
+// token with the https://www.googleapis.com/auth/calendar.events scope.
+func NewClient(clientID, clientSecret, refreshToken string, opts ...Option) *Client {
+	c := &Client{
+		http:         &http.Client{Timeout: 30 * time.Second},
+		baseURL:      defaultBaseURL,
+		tokenURL:     defaultTokenURL,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		refreshToken: refreshToken,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Insert creates e in the calendar and returns its Google event ID.
+func (c *Client) Insert(ctx context.Context, calendarID string, e Event) (string, error) {
+	var out Event
+	if err := c.do(ctx, http.MethodPost, c.eventsURL(calendarID, ""), e, &out); err != nil {
+		return "", fmt.Errorf("insert event: %w", err)
+	}
+	return out.ID, nil
+}
+
+// Update replaces the Google event eventID with e.
+func (c *Client) Update(ctx context.Context, calendarID, eventID string, e Event) error {
+	if err := c.do(ctx, http.MethodPut, c.eventsURL(calendarID, eventID), e, nil); err != nil {
+		return fmt.Errorf("update event %s: %w", eventID, err)
+	}
+	return nil
+}
+
+// Delete removes the Google event eventID.
+func (c *Client) Delete(ctx context.Context, calendarID, eventID string) error {
+	if err := c.do(ctx, http.MethodDelete, c.eventsURL(calendarID, eventID), nil, nil); err != nil {
+		return fmt.Errorf("delete event %s: %w", eventID, err)
+	}
+	return nil
+}
+
+func (c *Client) eventsURL(calendarID, eventID string) string {
+	u := c.baseURL + "/calendars/" + url.PathEscape(calendarID) + "/events"
+	if eventID != "" {
+		u += "/" + url.PathEscape(eventID)
+	}
+	return u
+}
+
+func (c *Client) do(ctx context.Context, method, u string, in, out interface{}) error {
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrNotFound
+	case resp.StatusCode >= 300:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("google api: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
+
+// accessToken returns a cached access token, refreshing it a minute before
+// it expires.
+func (c *Client) accessToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && time.Now().Before(c.expires.Add(-time.Minute)) {
+		return c.token, nil
+	}
+
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"client_id":     {c.clientID},
+		"client_secret": {c.clientSecret},
+		"refresh_token": {c.refreshToken},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("refresh token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("refresh token: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var tok struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return "", fmt.Errorf("decode token response: %w", err)
+	}
+	c.token = tok.AccessToken
+	c.expires = time.Now().Add(time.Duration(tok.ExpiresIn) * time.Second)
+	return c.token, nil
+}
@@ -0,0 +1,179 @@
+package gcal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jredh-dev/nexus/services/cal/internal/database"
+)
+
+// Syncer pushes one feed's events to one Google calendar. The mapping from
+// local event IDs to Google event IDs is stored in the cal database, along
+// with a fingerprint of what was last pushed so unchanged events cost no
+// API calls.
+type Syncer struct {
+	db         *database.DB
+	api        API
+	calendarID string
+	feedID     string
+	interval   time.Duration
+	now        func() time.Time
+}
+
+// NewSyncer creates a Syncer mirroring feedID into calendarID every interval.
+func NewSyncer(db *database.DB, api API, calendarID, feedID string, interval time.Duration) *Syncer {
+	return &Syncer{
+		db:         db,
+		api:        api,
+		calendarID: calendarID,
+		feedID:     feedID,
+		interval:   interval,
+		now:        time.Now,
+	}
+}
+
+// Run syncs until ctx is cancelled.
+func (s *Syncer) Run(ctx context.Context) {
+	t := time.NewTicker(s.interval)
+	defer t.Stop()
+
+	for {
+		if err := s.Sync(ctx); err != nil {
+			slog.Error("google calendar sync", "feed_id", s.feedID, "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// Sync performs one pass: new events are inserted, changed events updated,
+// and events deleted locally are deleted from Google. A failure on one event
+// does not stop the pass; failed events are retried on the next one.
+func (s *Syncer) Sync(ctx context.Context) error {
+	events, err := s.db.EventsByFeed(s.feedID)
+	if err != nil {
+		return fmt.Errorf("list events: %w", err)
+	}
+	mappings, err := s.db.GoogleMappings(s.feedID)
+	if err != nil {
+		return fmt.Errorf("list mappings: %w", err)
+	}
+
+	failed := 0
+	for _, e := range events {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		m, mapped := mappings[e.ID]
+		delete(mappings, e.ID)
+
+		ge := ToGoogle(e)
+		fp := fingerprint(ge)
+		if mapped && m.Fingerprint == fp {
+			continue
+		}
+		if err := s.push(ctx, e, ge, fp, m, mapped); err != nil {
+			slog.Error("push event to google calendar", "event_id", e.ID, "err", err)
+			failed++
+		}
+	}
+
+	// Whatever is left was mapped but no longer exists locally.
+	for _, m := range mappings {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := s.api.Delete(ctx, s.calendarID, m.GoogleID); err != nil && !errors.Is(err, ErrNotFound) {
+			slog.Error("delete event from google calendar", "event_id", m.EventID, "err", err)
+			failed++
+			continue
+		}
+		if err := s.db.DeleteGoogleMapping(m.EventID); err != nil {
+			slog.Error("delete google calendar mapping", "event_id", m.EventID, "err", err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d event(s) failed to sync", failed)
+	}
+	return nil
+}
+
+// push inserts or updates a single event and records the mapping. An event
+// whose Google copy was deleted by hand is re-created.
+func (s *Syncer) push(ctx context.Context, e *database.Event, ge Event, fp string, m database.GoogleMapping, mapped bool) error {
+	googleID := m.GoogleID
+	if mapped {
+		err := s.api.Update(ctx, s.calendarID, googleID, ge)
+		if errors.Is(err, ErrNotFound) {
+			mapped = false
+		} else if err != nil {
+			return err
+		}
+	}
+	if !mapped {
+		id, err := s.api.Insert(ctx, s.calendarID, ge)
+		if err != nil {
+			return err
+		}
+		googleID = id
+	}
+	return s.db.SaveGoogleMapping(database.GoogleMapping{
+		EventID:     e.ID,
+		FeedID:      e.FeedID,
+		GoogleID:    googleID,
+		Fingerprint: fp,
+		SyncedAt:    s.now(),
+	})
+}
+
+// ToGoogle converts a cal event to its Google Calendar representation.
+// Google requires an end, so timed events without one become zero-length
+// and all-day events without one last a single day.
+func ToGoogle(e *database.Event) Event {
+	ge := Event{
+		Summary:     e.Summary,
+		Description: e.Description,
+		Location:    e.Location,
+		Status:      strings.ToLower(e.Status),
+	}
+	if e.URL != "" {
+		ge.Source = &EventSource{Title: e.Summary, URL: e.URL}
+	}
+
+	if e.AllDay {
+		ge.Start.Date = e.Start.Format("2006-01-02")
+		end := e.Start.AddDate(0, 0, 1)
+		if e.End != nil && e.End.After(e.Start) {
+			end = *e.End
+		}
+		ge.End.Date = end.Format("2006-01-02")
+		return ge
+	}
+
+	ge.Start.DateTime = e.Start.UTC().Format(time.RFC3339)
+	end := e.Start
+	if e.End != nil {
+		end = *e.End
+	}
+	ge.End.DateTime = end.UTC().Format(time.RFC3339)
+	return ge
+}
+
+// fingerprint hashes the pushed representation of an event.
+func fingerprint(e Event) string {
+	b, _ := json.Marshal(e)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
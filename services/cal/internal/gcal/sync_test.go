@@ -0,0 +1,196 @@
+package gcal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jredh-dev/nexus/services/cal/internal/database"
+)
+
+// fakeAPI is an in-memory Google calendar.
+type fakeAPI struct {
+	events  map[string]Event
+	nextID  int
+	inserts int
+	updates int
+	deletes int
+}
+
+func newFakeAPI() *fakeAPI { return &fakeAPI{events: make(map[string]Event)} }
+
+func (f *fakeAPI) Insert(_ context.Context, _ string, e Event) (string, error) {
+	f.nextID++
+	f.inserts++
+	id := fmt.Sprintf("g%d", f.nextID)
+	f.events[id] = e
+	return id, nil
+}
+
+func (f *fakeAPI) Update(_ context.Context, _, id string, e Event) error {
+	f.updates++
+	if _, ok := f.events[id]; !ok {
+		return ErrNotFound
+	}
+	f.events[id] = e
+	return nil
+}
+
+func (f *fakeAPI) Delete(_ context.Context, _, id string) error {
+	f.deletes++
+	if _, ok := f.events[id]; !ok {
+		return ErrNotFound
+	}
+	delete(f.events, id)
+	return nil
+}
+
+func testDB(t *testing.T) *database.DB {
+	t.Helper()
+	db, err := database.Open(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestSync(t *testing.T) {
+	db := testDB(t)
+	api := newFakeAPI()
+	ctx := context.Background()
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	if err := db.CreateFeed(&database.Feed{ID: "feed-1", Name: "Test", Token: "tok", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+	end := now.Add(time.Hour)
+	standup := &database.Event{ID: "e1", FeedID: "feed-1", Summary: "Standup", Start: now, End: &end, Status: "CONFIRMED", CreatedAt: now, UpdatedAt: now}
+	holiday := &database.Event{ID: "e2", FeedID: "feed-1", Summary: "Holiday", Start: now, AllDay: true, Status: "CONFIRMED", CreatedAt: now, UpdatedAt: now}
+	for _, e := range []*database.Event{standup, holiday} {
+		if err := db.CreateEvent(e); err != nil {
+			t.Fatalf("create event: %v", err)
+		}
+	}
+
+	s := NewSyncer(db, api, "primary", "feed-1", time.Minute)
+
+	// First pass inserts everything.
+	if err := s.Sync(ctx); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if api.inserts != 2 || len(api.events) != 2 {
+		t.Fatalf("expected 2 inserts, got %d (%d events)", api.inserts, len(api.events))
+	}
+
+	// Unchanged events cost nothing.
+	if err := s.Sync(ctx); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if api.inserts != 2 || api.updates != 0 {
+		t.Fatalf("expected no calls, got %d inserts, %d updates", api.inserts, api.updates)
+	}
+
+	// A changed event is updated in place.
+	standup.Summary = "Standup (moved)"
+	if err := db.UpdateEvent(standup); err != nil {
+		t.Fatalf("update event: %v", err)
+	}
+	if err := s.Sync(ctx); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if api.updates != 1 {
+		t.Fatalf("expected 1 update, got %d", api.updates)
+	}
+
+	// A Google copy deleted by hand is re-created on the next change.
+	mappings, _ := db.GoogleMappings("feed-1")
+	delete(api.events, mappings["e1"].GoogleID)
+	standup.Summary = "Standup (again)"
+	db.UpdateEvent(standup)
+	if err := s.Sync(ctx); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if api.inserts != 3 {
+		t.Fatalf("expected re-insert, got %d inserts", api.inserts)
+	}
+
+	// A locally deleted event is deleted remotely and unmapped.
+	if err := db.DeleteEvent("e2"); err != nil {
+		t.Fatalf("delete event: %v", err)
+	}
+	if err := s.Sync(ctx); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if len(api.events) != 1 {
+		t.Fatalf("expected 1 remote event, got %d", len(api.events))
+	}
+	mappings, _ = db.GoogleMappings("feed-1")
+	if _, ok := mappings["e2"]; ok || len(mappings) != 1 {
+		t.Fatalf("unexpected mappings after delete: %+v", mappings)
+	}
+}
+
+func TestToGoogle(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	allDay := ToGoogle(&database.Event{Summary: "Off", Start: start, AllDay: true, Status: "TENTATIVE"})
+	if allDay.Start.Date != "2026-03-02" || allDay.End.Date != "2026-03-03" || allDay.Status != "tentative" {
+		t.Errorf("all-day: %+v", allDay)
+	}
+
+	timed := ToGoogle(&database.Event{Summary: "Ping", Start: start, URL: "https://example.com"})
+	if timed.Start.DateTime != "2026-03-02T09:00:00Z" || timed.End.DateTime != timed.Start.DateTime {
+		t.Errorf("timed without end: %+v", timed)
+	}
+	if timed.Source == nil || timed.Source.URL != "https://example.com" {
+		t.Errorf("expected source URL, got %+v", timed.Source)
+	}
+}
+
+func TestClient(t *testing.T) {
+	var tokenCalls int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		tokenCalls++
+		if r.FormValue("refresh_token") != "refresh" {
+			http.Error(w, "bad token", http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "access", "expires_in": 3600})
+	})
+	mux.HandleFunc("/calendars/primary/events", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var e Event
+		json.NewDecoder(r.Body).Decode(&e)
+		e.ID = "abc"
+		json.NewEncoder(w).Encode(e)
+	})
+	mux.HandleFunc("/calendars/primary/events/gone", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusGone)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := NewClient("id", "secret", "refresh", WithEndpoints(srv.URL, srv.URL+"/token"))
+	ctx := context.Background()
+
+	id, err := c.Insert(ctx, "primary", Event{Summary: "Hello"})
+	if err != nil || id != "abc" {
+		t.Fatalf("insert: id=%q err=%v", id, err)
+	}
+	if err := c.Delete(ctx, "primary", "gone"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if tokenCalls != 1 {
+		t.Errorf("expected the access token to be cached, got %d refreshes", tokenCalls)
+	}
+}
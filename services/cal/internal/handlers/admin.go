@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"embed"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/jredh-dev/nexus/internal/logging"
+	"github.com/jredh-dev/nexus/services/cal/internal/database"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+var adminFuncs = template.FuncMap{
+	// webcal builds the subscription URL calendar clients open.
+	"webcal": func(host, token string) string {
+		return "webcal://" + host + "/" + token + ".ics"
+	},
+}
+
+var (
+	adminFeedsTmpl = template.Must(template.New("").Funcs(adminFuncs).ParseFS(templateFS, "templates/layout.html", "templates/admin_feeds.html"))
+	adminFeedTmpl  = template.Must(template.New("").Funcs(adminFuncs).ParseFS(templateFS, "templates/layout.html", "templates/admin_feed.html"))
+)
+
+// AdminRouter returns the embedded admin UI for creating feeds, adding
+// events, and copying webcal URLs. Mount it at /admin behind RequireOwner.
+//
+// Pages are plain forms enhanced with htmx: an htmx request gets back only
+// the fragment it swaps (the feed list or a feed's events), while a browser
+// without JavaScript posts the form and follows a redirect.
+func (h *Handler) AdminRouter() http.Handler {
+	r := chi.NewRouter()
+	r.Use(sameOrigin)
+	r.Get("/", h.adminFeeds)
+	r.Post("/feeds", h.adminCreateFeed)
+	r.Get("/feeds/{id}", h.adminFeed)
+	r.Post("/feeds/{id}/delete", h.adminDeleteFeed)
+	r.Post("/feeds/{id}/events", h.adminCreateEvent)
+	r.Post("/events/{id}/delete", h.adminDeleteEvent)
+	return r
+}
+
+func (h *Handler) adminFeeds(w http.ResponseWriter, r *http.Request) {
+	h.renderFeeds(w, r, "base", r.URL.Query().Get("error"))
+}
+
+// renderFeeds renders the feed list page, or just its "feeds" fragment.
+func (h *Handler) renderFeeds(w http.ResponseWriter, r *http.Request, block, errMsg string) {
+	feeds, err := h.db.ListFeedsByOwner(ownerID(r))
+	if err != nil {
+		logging.FromContext(r.Context()).Error("list feeds", "err", err)
+		http.Error(w, "failed to list feeds", http.StatusInternalServerError)
+		return
+	}
+	render(w, r, adminFeedsTmpl, block, map[string]any{
+		"Title": "Feeds",
+		"Host":  r.Host,
+		"Error": errMsg,
+		"Feeds": feeds,
+	})
+}
+
+func (h *Handler) adminCreateFeed(w http.ResponseWriter, r *http.Request) {
+	req := createFeedReq{
+		Name: strings.TrimSpace(r.FormValue("name")),
+		Slug: strings.TrimSpace(r.FormValue("slug")),
+	}
+	feed, msg, _ := h.createFeed(r, req)
+	if isHTMX(r) {
+		h.renderFeeds(w, r, "feeds", msg)
+		return
+	}
+	if msg != "" {
+		redirectWithError(w, r, "/admin/", msg)
+		return
+	}
+	http.Redirect(w, r, "/admin/feeds/"+feed.ID, http.StatusSeeOther)
+}
+
+func (h *Handler) adminFeed(w http.ResponseWriter, r *http.Request) {
+	feed, ok := h.ownedFeed(r, chi.URLParam(r, "id"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	h.renderFeed(w, r, "base", feed, r.URL.Query().Get("error"))
+}
+
+// renderFeed renders a feed's page, or just its "events" fragment.
+func (h *Handler) renderFeed(w http.ResponseWriter, r *http.Request, block string, feed *database.Feed, errMsg string) {
+	events, err := h.db.EventsByFeed(feed.ID)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("list events", "feed_id", feed.ID, "err", err)
+		http.Error(w, "failed to list events", http.StatusInternalServerError)
+		return
+	}
+	render(w, r, adminFeedTmpl, block, map[string]any{
+		"Title":  feed.Name,
+		"Host":   r.Host,
+		"Error":  errMsg,
+		"Feed":   feed,
+		"Events": events,
+	})
+}
+
+func (h *Handler) adminDeleteFeed(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if _, ok := h.ownedFeed(r, id); !ok {
+		http.NotFound(w, r)
+		return
+	}
+	var msg string
+	if err := h.db.DeleteFeed(id); err != nil {
+		logging.FromContext(r.Context()).Error("delete feed", "feed_id", id, "err", err)
+		msg = "failed to delete feed"
+	}
+	if isHTMX(r) {
+		h.renderFeeds(w, r, "feeds", msg)
+		return
+	}
+	if msg != "" {
+		redirectWithError(w, r, "/admin/", msg)
+		return
+	}
+	http.Redirect(w, r, "/admin/", http.StatusSeeOther)
+}
+
+func (h *Handler) adminCreateEvent(w http.ResponseWriter, r *http.Request) {
+	feedID := chi.URLParam(r, "id")
+	feed, ok := h.ownedFeed(r, feedID)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	req := createEventReq{
+		FeedID:      feedID,
+		Summary:     strings.TrimSpace(r.FormValue("summary")),
+		Description: r.FormValue("description"),
+		Location:    r.FormValue("location"),
+		URL:         r.FormValue("url"),
+		Start:       formTime(r.FormValue("start")),
+		AllDay:      r.FormValue("all_day") != "",
+		Status:      r.FormValue("status"),
+		Categories:  r.FormValue("categories"),
+	}
+	if v := formTime(r.FormValue("end")); v != "" {
+		req.End = &v
+	}
+
+	event, msg := h.eventFromRequest(req)
+	if msg == "" {
+		if err := h.db.CreateEvent(event); err != nil {
+			logging.FromContext(r.Context()).Error("create event", "err", err)
+			msg = "failed to create event"
+		} else {
+			h.afterCreateEvent(r, event, false)
+		}
+	}
+	h.adminFeedResult(w, r, feed, msg)
+}
+
+func (h *Handler) adminDeleteEvent(w http.ResponseWriter, r *http.Request) {
+	event, ok := h.ownedEvent(r, chi.URLParam(r, "id"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	feed, ok := h.ownedFeed(r, event.FeedID)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	var msg string
+	if err := h.db.DeleteEvent(event.ID); err != nil {
+		logging.FromContext(r.Context()).Error("delete event", "event_id", event.ID, "err", err)
+		msg = "failed to delete event"
+	}
+	h.adminFeedResult(w, r, feed, msg)
+}
+
+// adminFeedResult answers a form post on a feed's page: htmx gets the
+// refreshed events fragment, anything else is redirected back to the page.
+func (h *Handler) adminFeedResult(w http.ResponseWriter, r *http.Request, feed *database.Feed, msg string) {
+	if isHTMX(r) {
+		h.renderFeed(w, r, "events", feed, msg)
+		return
+	}
+	back := "/admin/feeds/" + feed.ID
+	if msg != "" {
+		redirectWithError(w, r, back, msg)
+		return
+	}
+	http.Redirect(w, r, back, http.StatusSeeOther)
+}
+
+// formTime converts a datetime-local input value ("2006-01-02T15:04"),
+// interpreted as UTC, to RFC 3339. Other values pass through unchanged so
+// validation reports them.
+func formTime(v string) string {
+	v = strings.TrimSpace(v)
+	if t, err := time.Parse("2006-01-02T15:04", v); err == nil {
+		return t.UTC().Format(time.RFC3339)
+	}
+	return v
+}
+
+func redirectWithError(w http.ResponseWriter, r *http.Request, path, msg string) {
+	http.Redirect(w, r, path+"?error="+url.QueryEscape(msg), http.StatusSeeOther)
+}
+
+// isHTMX reports whether r was sent by htmx and expects a fragment.
+func isHTMX(r *http.Request) bool {
+	return r.Header.Get("HX-Request") == "true"
+}
+
+// render executes block ("base" for a full page, or a fragment name).
+func render(w http.ResponseWriter, r *http.Request, tmpl *template.Template, block string, data any) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := tmpl.ExecuteTemplate(w, block, data); err != nil {
+		logging.FromContext(r.Context()).Error("render admin template", "err", err)
+	}
+}
+
+// sameOrigin rejects state-changing requests that a browser sent from
+// another site. Basic auth credentials are attached to cross-site form
+// posts automatically, so the API key alone does not stop CSRF.
+func sameOrigin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			origin = r.Header.Get("Referer")
+		}
+		if origin != "" {
+			u, err := url.Parse(origin)
+			if err != nil || u.Host != r.Host {
+				http.Error(w, "cross-origin request rejected", http.StatusForbidden)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func postForm(r http.Handler, path string, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAdmin_FeedAndEventLifecycle(t *testing.T) {
+	h := testHandler(t)
+	r := testRouter(h)
+
+	w := postForm(r, "/admin/feeds", url.Values{"name": {"Team"}, "slug": {"team"}})
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("create feed: expected 303, got %d: %s", w.Code, w.Body.String())
+	}
+	feedPage := w.Header().Get("Location")
+	if !strings.HasPrefix(feedPage, "/admin/feeds/") {
+		t.Fatalf("unexpected redirect %q", feedPage)
+	}
+
+	w = postForm(r, feedPage+"/events", url.Values{
+		"summary":    {"Standup"},
+		"start":      {"2026-03-02T09:00"},
+		"end":        {"2026-03-02T09:15"},
+		"categories": {"work"},
+	})
+	if w.Code != http.StatusSeeOther || w.Header().Get("Location") != feedPage {
+		t.Fatalf("create event: got %d -> %q", w.Code, w.Header().Get("Location"))
+	}
+
+	req := httptest.NewRequest(http.MethodGet, feedPage, nil)
+	req.Host = "cal.example.com"
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("feed page: expected 200, got %d", w.Code)
+	}
+	body := w.Body.String()
+	for _, want := range []string{"Standup", "webcal://cal.example.com/team.ics"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("feed page missing %q", want)
+		}
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/admin/", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Team") {
+		t.Fatalf("feed list: got %d", w.Code)
+	}
+}
+
+func TestAdmin_ValidationErrorRedirects(t *testing.T) {
+	h := testHandler(t)
+	r := testRouter(h)
+
+	feed := createTestFeed(t, r, `{"name":"Errors"}`)
+	w := postForm(r, "/admin/feeds/"+feed.ID+"/events", url.Values{"summary": {"No start"}})
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("expected 303, got %d", w.Code)
+	}
+	loc, _ := url.Parse(w.Header().Get("Location"))
+	if loc.Query().Get("error") == "" {
+		t.Fatalf("expected error in redirect, got %q", loc)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, loc.String(), nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), loc.Query().Get("error")) {
+		t.Error("feed page does not show the error")
+	}
+}
+
+func TestAdmin_RejectsCrossOriginPost(t *testing.T) {
+	h := testHandler(t)
+	r := testRouter(h)
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/feeds", strings.NewReader("name=Evil"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Origin", "https://evil.example")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", w.Code)
+	}
+}
+
+func TestAdmin_HTMXSwapsFragments(t *testing.T) {
+	h := testHandler(t)
+	r := testRouter(h)
+
+	htmxPost := func(path string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("HX-Request", "true")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := htmxPost("/admin/feeds", url.Values{"name": {"Team"}, "slug": {"team"}})
+	body := w.Body.String()
+	if w.Code != http.StatusOK || !strings.HasPrefix(strings.TrimSpace(body), `<section id="feeds">`) {
+		t.Fatalf("create feed: expected feeds fragment, got %d: %s", w.Code, body)
+	}
+	if strings.Contains(body, "<html") || !strings.Contains(body, "Team") {
+		t.Errorf("fragment should list the new feed without the page layout: %s", body)
+	}
+
+	feeds, _ := h.db.ListFeedsByOwner("")
+	if len(feeds) != 1 {
+		t.Fatalf("expected 1 feed, got %d", len(feeds))
+	}
+	feedID := feeds[0].ID
+
+	w = htmxPost("/admin/feeds/"+feedID+"/events", url.Values{"summary": {"No start"}})
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `class="error"`) {
+		t.Fatalf("invalid event: expected fragment with error, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = htmxPost("/admin/feeds/"+feedID+"/events", url.Values{"summary": {"Standup"}, "start": {"2026-03-02T09:00"}})
+	body = w.Body.String()
+	if !strings.HasPrefix(strings.TrimSpace(body), `<section id="events">`) || !strings.Contains(body, "Standup") {
+		t.Fatalf("create event: expected events fragment, got %s", body)
+	}
+
+	events, _ := h.db.EventsByFeed(feedID)
+	w = htmxPost("/admin/events/"+events[0].ID+"/delete", nil)
+	if strings.Contains(w.Body.String(), "Standup") {
+		t.Errorf("deleted event still listed: %s", w.Body.String())
+	}
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/jredh-dev/nexus/internal/httpx"
+	"github.com/jredh-dev/nexus/internal/logging"
+	"github.com/jredh-dev/nexus/internal/sso"
+	"github.com/jredh-dev/nexus/services/cal/internal/database"
+)
+
+type ownerKey struct{}
+
+// RequireOwner guards the management API and admin UI. The caller's API key
+// identifies an owner, and every management operation is scoped to that
+// owner's feeds. The key may be presented as "Authorization: Bearer <key>",
+// an X-API-Key header, or the password of HTTP basic auth (so browsers can
+// reach /admin).
+//
+// Portal users signed in through sso.Middleware, which must run first, get
+// in too; each owns the feeds created under their portal user ID.
+//
+// While no owners exist the service runs unauthenticated for local
+// development, and requests act on feeds without an owner.
+func (h *Handler) RequireOwner(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := sso.FromContext(r.Context()); id != nil {
+			owner := &database.Owner{ID: id.UserID, Name: id.Name}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ownerKey{}, owner)))
+			return
+		}
+
+		key := presentedKey(r)
+		if key == "" {
+			has, err := h.db.HasOwners()
+			if err != nil {
+				logging.FromContext(r.Context()).Error("check owners", "err", err)
+				httpx.Error(w, "internal error", http.StatusInternalServerError)
+				return
+			}
+			if !has {
+				next.ServeHTTP(w, r)
+				return
+			}
+			unauthorized(w)
+			return
+		}
+
+		owner, err := h.db.OwnerByAPIKey(key)
+		if errors.Is(err, sql.ErrNoRows) {
+			unauthorized(w)
+			return
+		}
+		if err != nil {
+			logging.FromContext(r.Context()).Error("look up owner", "err", err)
+			httpx.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		logging.SetUser(r.Context(), owner.ID)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ownerKey{}, owner)))
+	})
+}
+
+// OwnerFromContext returns the authenticated owner, or nil when the service
+// is running without owners.
+func OwnerFromContext(ctx context.Context) *database.Owner {
+	o, _ := ctx.Value(ownerKey{}).(*database.Owner)
+	return o
+}
+
+// ownerID returns the ID feeds are scoped to for this request.
+func ownerID(r *http.Request) string {
+	if o := OwnerFromContext(r.Context()); o != nil {
+		return o.ID
+	}
+	return ""
+}
+
+// ownedFeed loads a feed if it belongs to the caller. Feeds owned by someone
+// else are reported as missing so their existence is not revealed.
+func (h *Handler) ownedFeed(r *http.Request, id string) (*database.Feed, bool) {
+	feed, err := h.db.FeedByID(id)
+	if err != nil || feed.OwnerID != ownerID(r) {
+		return nil, false
+	}
+	return feed, true
+}
+
+// ownedEvent loads an event if its feed belongs to the caller.
+func (h *Handler) ownedEvent(r *http.Request, id string) (*database.Event, bool) {
+	event, err := h.db.EventByID(id)
+	if err != nil {
+		return nil, false
+	}
+	if _, ok := h.ownedFeed(r, event.FeedID); !ok {
+		return nil, false
+	}
+	return event, true
+}
+
+func unauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", `Basic realm="nexus-cal"`)
+	httpx.Error(w, "unauthorized", http.StatusUnauthorized)
+}
+
+// presentedKey extracts the API key from a request, or "" if none was sent.
+func presentedKey(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimPrefix(auth, "Bearer ")
+	}
+	if k := r.Header.Get("X-API-Key"); k != "" {
+		return k
+	}
+	if _, pass, ok := r.BasicAuth(); ok {
+		return pass
+	}
+	return ""
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/jredh-dev/nexus/internal/sso"
+	"github.com/jredh-dev/nexus/services/cal/internal/database"
+)
+
+// authRouter wraps the management routes in RequireOwner, as main.go does.
+func authRouter(h *Handler) *chi.Mux {
+	r := chi.NewRouter()
+	r.Route("/api", func(r chi.Router) {
+		r.Use(h.RequireOwner)
+		r.Post("/feeds", h.CreateFeed)
+		r.Get("/feeds", h.ListFeeds)
+		r.Delete("/feeds/{id}", h.DeleteFeed)
+		r.Get("/feeds/{id}/events", h.ListEvents)
+		r.Post("/events", h.CreateEvent)
+		r.Delete("/events/{id}", h.DeleteEvent)
+	})
+	return r
+}
+
+func addTestOwner(t *testing.T, h *Handler, id, key string) {
+	t.Helper()
+	if err := h.db.CreateOwner(&database.Owner{ID: id, Name: id, CreatedAt: time.Now()}, key); err != nil {
+		t.Fatalf("create owner: %v", err)
+	}
+}
+
+func doAs(r http.Handler, key, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRequireOwner_ScopesFeeds(t *testing.T) {
+	h := testHandler(t)
+	r := authRouter(h)
+	addTestOwner(t, h, "alice", "alice-key")
+	addTestOwner(t, h, "bob", "bob-key")
+
+	w := doAs(r, "alice-key", http.MethodPost, "/api/feeds", `{"name":"Alice"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create feed: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var feed createFeedResp
+	json.Unmarshal(w.Body.Bytes(), &feed)
+
+	w = doAs(r, "alice-key", http.MethodPost, "/api/events",
+		`{"feed_id":"`+feed.ID+`","summary":"Private","start":"2026-03-02T09:00:00Z"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create event: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var event database.Event
+	json.Unmarshal(w.Body.Bytes(), &event)
+
+	// Bob sees none of Alice's feeds and cannot touch them.
+	w = doAs(r, "bob-key", http.MethodGet, "/api/feeds", "")
+	if strings.TrimSpace(w.Body.String()) != "[]" {
+		t.Errorf("bob's feed list: expected [], got %s", w.Body.String())
+	}
+	for _, tc := range []struct{ method, path, body string }{
+		{http.MethodGet, "/api/feeds/" + feed.ID + "/events", ""},
+		{http.MethodDelete, "/api/feeds/" + feed.ID, ""},
+		{http.MethodDelete, "/api/events/" + event.ID, ""},
+		{http.MethodPost, "/api/events", `{"feed_id":"` + feed.ID + `","summary":"Intruder","start":"2026-03-02T09:00:00Z"}`},
+	} {
+		if w := doAs(r, "bob-key", tc.method, tc.path, tc.body); w.Code != http.StatusNotFound {
+			t.Errorf("bob %s %s: expected 404, got %d", tc.method, tc.path, w.Code)
+		}
+	}
+
+	// Alice still has everything.
+	w = doAs(r, "alice-key", http.MethodGet, "/api/feeds/"+feed.ID+"/events", "")
+	var events []database.Event
+	json.Unmarshal(w.Body.Bytes(), &events)
+	if len(events) != 1 {
+		t.Errorf("alice's events: expected 1, got %d", len(events))
+	}
+}
+
+func TestRequireOwner_PortalUser(t *testing.T) {
+	h := testHandler(t)
+	addTestOwner(t, h, "alice", "alice-key")
+	secret := []byte("sso-secret")
+	r := chi.NewRouter()
+	r.Route("/api", func(r chi.Router) {
+		r.Use(sso.NewVerifier("", "", secret).Middleware, h.RequireOwner)
+		r.Post("/feeds", h.CreateFeed)
+		r.Get("/feeds", h.ListFeeds)
+	})
+
+	jwt, err := sso.Sign(secret, sso.Identity{UserID: "portal-user", Expires: time.Now().Add(time.Hour).Unix()}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := doAs(r, jwt, http.MethodPost, "/api/feeds", `{"name":"Mine"}`); w.Code != http.StatusCreated {
+		t.Fatalf("create feed as portal user: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	w := doAs(r, "alice-key", http.MethodGet, "/api/feeds", "")
+	if strings.TrimSpace(w.Body.String()) != "[]" {
+		t.Errorf("alice sees the portal user's feed: %s", w.Body.String())
+	}
+	feeds, err := h.db.ListFeedsByOwner("portal-user")
+	if err != nil || len(feeds) != 1 {
+		t.Errorf("portal user's feeds: %d (%v), want 1", len(feeds), err)
+	}
+
+	forged, _ := sso.Sign([]byte("other"), sso.Identity{UserID: "portal-user", Expires: time.Now().Add(time.Hour).Unix()}, time.Now())
+	if w := doAs(r, forged, http.MethodGet, "/api/feeds", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("forged token: expected 401, got %d", w.Code)
+	}
+}
+
+func TestRequireOwner_Credentials(t *testing.T) {
+	h := testHandler(t)
+	r := authRouter(h)
+
+	// With no owners the API is open.
+	if w := doAs(r, "", http.MethodGet, "/api/feeds", ""); w.Code != http.StatusOK {
+		t.Fatalf("no owners: expected 200, got %d", w.Code)
+	}
+
+	addTestOwner(t, h, "alice", "s3cret")
+
+	tests := []struct {
+		name  string
+		setup func(r *http.Request)
+		want  int
+	}{
+		{"no key", func(r *http.Request) {}, http.StatusUnauthorized},
+		{"wrong bearer", func(r *http.Request) { r.Header.Set("Authorization", "Bearer nope") }, http.StatusUnauthorized},
+		{"bearer", func(r *http.Request) { r.Header.Set("Authorization", "Bearer s3cret") }, http.StatusOK},
+		{"header", func(r *http.Request) { r.Header.Set("X-API-Key", "s3cret") }, http.StatusOK},
+		{"basic auth", func(r *http.Request) { r.SetBasicAuth("admin", "s3cret") }, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/feeds", nil)
+			tt.setup(req)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, w.Code)
+			}
+			if tt.want == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("missing WWW-Authenticate header")
+			}
+		})
+	}
+}
@@ -1,64 +1,309 @@
 package handlers
 
 import (
-	"encoding/json"
-	"log"
+	"database/sql"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 
+	"github.com/jredh-dev/nexus/internal/events"
+	"github.com/jredh-dev/nexus/internal/httpx"
+	"github.com/jredh-dev/nexus/internal/logging"
 	"github.com/jredh-dev/nexus/services/cal/internal/database"
 	"github.com/jredh-dev/nexus/services/cal/internal/ical"
+	"github.com/jredh-dev/nexus/services/cal/internal/mailer"
+	"github.com/jredh-dev/nexus/services/cal/internal/metrics"
 )
 
 // slugPattern matches valid slugs: lowercase letters, digits, and hyphens,
 // 2-64 characters, must start and end with alphanumeric.
 var slugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}[a-z0-9]$`)
 
+// emailPattern is a deliberately loose address check: one @, no spaces,
+// and a dot in the domain. Deliverability is the mail relay's problem.
+var emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+
+// mediaTypePattern matches a type/subtype media type without parameters.
+var mediaTypePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9!#$&^_.+-]{0,126}/[A-Za-z0-9][A-Za-z0-9!#$&^_.+-]{0,126}$`)
+
+// e164Pattern matches an E.164 phone number: +, country code, up to 15 digits.
+var e164Pattern = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// defaultReminderMinutes is the SMS reminder lead time when none is given.
+const defaultReminderMinutes = 15
+
+// maxReminderMinutes caps the SMS reminder lead time at one week.
+const maxReminderMinutes = 7 * 24 * 60
+
+// maxDedupeKeySize bounds caller-supplied dedupe keys.
+const maxDedupeKeySize = 255
+
+// Inline attachments are embedded in every copy of the feed a client
+// downloads, so they are kept small; larger documents should be linked.
+const (
+	maxAttachments        = 10
+	maxInlineAttachBytes  = 64 << 10
+	maxAttachmentNameSize = 255
+)
+
+// Horizon bounds the events a subscription serves, so a feed with years of
+// history doesn't produce an unbounded .ics response.
+type Horizon struct {
+	Past      time.Duration // how far back from now events are included
+	Future    time.Duration // how far ahead of now events are included
+	MaxEvents int           // cap on events per response; the ones nearest now are kept
+}
+
+// DefaultHorizon serves events from a year ago to a year ahead, at most 5000.
+var DefaultHorizon = Horizon{
+	Past:      365 * 24 * time.Hour,
+	Future:    365 * 24 * time.Hour,
+	MaxEvents: 5000,
+}
+
 // Handler holds dependencies for HTTP handlers.
 type Handler struct {
-	db *database.DB
+	db      *database.DB
+	mailer  mailer.Mailer // nil disables email invitations
+	horizon Horizon
+	metrics *metrics.Metrics // nil disables metrics
+	bus     events.Bus       // nil publishes nothing
+}
+
+// Option configures a Handler during construction.
+type Option func(*Handler)
+
+// WithMailer enables email invitations (METHOD:REQUEST) for events
+// created with send_invitations set.
+func WithMailer(m mailer.Mailer) Option {
+	return func(h *Handler) { h.mailer = m }
+}
+
+// WithHorizon sets the window and event cap for subscriptions and the
+// page size limit for event listings.
+func WithHorizon(hz Horizon) Option {
+	return func(h *Handler) { h.horizon = hz }
+}
+
+// WithMetrics records feed fetches in m.
+func WithMetrics(m *metrics.Metrics) Option {
+	return func(h *Handler) { h.metrics = m }
+}
+
+// WithEvents publishes events.EventCreated on bus for every event created
+// through the API or admin UI.
+func WithEvents(bus events.Bus) Option {
+	return func(h *Handler) { h.bus = bus }
 }
 
 // New creates a new Handler.
-func New(db *database.DB) *Handler {
-	return &Handler{db: db}
+func New(db *database.DB, opts ...Option) *Handler {
+	h := &Handler{db: db, horizon: DefaultHorizon}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 // --- Subscription endpoint (served to calendar clients) ---
 
-// Subscribe serves the iCal feed for a given token.
+// Subscribe serves the iCal feed for a given token. Clients that send
+// Accept: application/calendar+json receive the jCal form instead.
 // GET /{token}.ics
 //
 //	@Summary      Subscribe to calendar feed
 //	@Description  Returns an iCal feed for the given token. Used by calendar clients (webcal://).
+//	@Description  Clients that accept application/calendar+json receive jCal (RFC 7265) instead.
+//	@Description  Only events within the server's horizon are served; X-Cal-* headers describe the window and any truncation.
 //	@Tags         subscription
 //	@Produce      text/calendar
-//	@Param        token  path      string  true  "Feed token or slug"
-//	@Success      200    {string}  string  "iCal feed content"
-//	@Failure      404    {string}  string  "Feed not found"
+//	@Produce      application/calendar+json
+//	@Param        token       path      string  true   "Feed token or slug"
+//	@Param        categories  query     string  false  "Comma-separated categories; only events with any of them are included"
+//	@Success      200         {string}  string  "iCal feed content"
+//	@Failure      404         {string}  string  "Feed not found"
 //	@Router       /{token}.ics [get]
 func (h *Handler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	q := h.subscriptionQuery()
+	feed, events, total, ok := h.loadFeed(w, r, q)
+	if !ok {
+		return
+	}
+	setWindowHeaders(w, q, len(events), total)
+
+	if acceptsJCal(r) {
+		h.metrics.FeedFetched("json", len(events))
+		writeJCal(w, r, feed, events)
+		return
+	}
+	h.metrics.FeedFetched("ics", len(events))
+
+	body := ical.Generate(feed, events)
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", "attachment; filename=\"calendar.ics\"")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Add("Vary", "Accept")
+	w.Write([]byte(body))
+}
+
+// SubscribeJSON serves the feed for a given token as jCal (RFC 7265), so web
+// frontends can consume it without an iCal parser.
+// GET /{token}.json
+//
+//	@Summary      Subscribe to calendar feed as jCal
+//	@Description  Returns the feed for the given token as jCal (RFC 7265) JSON.
+//	@Tags         subscription
+//	@Produce      application/calendar+json
+//	@Param        token       path      string  true   "Feed token or slug"
+//	@Param        categories  query     string  false  "Comma-separated categories; only events with any of them are included"
+//	@Success      200         {array}   interface{}  "jCal feed content"
+//	@Failure      404         {string}  string       "Feed not found"
+//	@Router       /{token}.json [get]
+func (h *Handler) SubscribeJSON(w http.ResponseWriter, r *http.Request) {
+	q := h.subscriptionQuery()
+	feed, events, total, ok := h.loadFeed(w, r, q)
+	if !ok {
+		return
+	}
+	setWindowHeaders(w, q, len(events), total)
+	h.metrics.FeedFetched("json", len(events))
+	writeJCal(w, r, feed, events)
+}
+
+// subscriptionQuery selects the events a subscription serves: those within
+// the horizon around now, keeping the ones nearest now if there are more
+// than MaxEvents.
+func (h *Handler) subscriptionQuery() database.EventQuery {
+	now := time.Now().UTC()
+	return database.EventQuery{
+		From:   now.Add(-h.horizon.Past),
+		To:     now.Add(h.horizon.Future),
+		Limit:  h.horizon.MaxEvents,
+		Around: now,
+	}
+}
+
+// setWindowHeaders describes which slice of the feed a response contains.
+// Both counts are after any category filter.
+func setWindowHeaders(w http.ResponseWriter, q database.EventQuery, served, total int) {
+	w.Header().Set("X-Cal-Window-Start", q.From.Format(time.RFC3339))
+	w.Header().Set("X-Cal-Window-End", q.To.Format(time.RFC3339))
+	w.Header().Set("X-Cal-Events", strconv.Itoa(served))
+	w.Header().Set("X-Cal-Events-Total", strconv.Itoa(total))
+	w.Header().Set("X-Cal-Truncated", strconv.FormatBool(q.Limit > 0 && total > q.Limit))
+}
+
+// maxFreeBusyWindow caps how far a single free/busy query may span.
+const maxFreeBusyWindow = 366 * 24 * time.Hour
+
+// defaultFreeBusyWindow is used when the caller omits "to".
+const defaultFreeBusyWindow = 30 * 24 * time.Hour
+
+// FreeBusy serves a VFREEBUSY component for a feed over a requested window,
+// letting others check availability without seeing event details.
+// GET /{token}/freebusy?from=&to=
+//
+//	@Summary      Free/busy for a calendar feed
+//	@Description  Returns a VFREEBUSY listing busy periods between from and to (RFC 3339 or YYYY-MM-DD).
+//	@Description  from defaults to now and to defaults to 30 days after from. The window may not exceed 366 days.
+//	@Tags         subscription
+//	@Produce      text/calendar
+//	@Param        token  path      string  true   "Feed token or slug"
+//	@Param        from   query     string  false  "Window start"
+//	@Param        to     query     string  false  "Window end"
+//	@Success      200    {string}  string  "iCal VFREEBUSY content"
+//	@Failure      400    {string}  string  "Invalid window"
+//	@Failure      404    {string}  string  "Feed not found"
+//	@Router       /{token}/freebusy [get]
+func (h *Handler) FreeBusy(w http.ResponseWriter, r *http.Request) {
+	from := time.Now().UTC().Truncate(time.Minute)
+	if v := r.URL.Query().Get("from"); v != "" {
+		t, err := parseWindowTime(v)
+		if err != nil {
+			http.Error(w, "from must be RFC 3339 or YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		from = t
+	}
+	to := from.Add(defaultFreeBusyWindow)
+	if v := r.URL.Query().Get("to"); v != "" {
+		t, err := parseWindowTime(v)
+		if err != nil {
+			http.Error(w, "to must be RFC 3339 or YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		to = t
+	}
+	if !to.After(from) {
+		http.Error(w, "to must be after from", http.StatusBadRequest)
+		return
+	}
+	if to.Sub(from) > maxFreeBusyWindow {
+		http.Error(w, "window may not exceed 366 days", http.StatusBadRequest)
+		return
+	}
+
+	_, events, _, ok := h.loadFeed(w, r, database.EventQuery{From: from, To: to})
+	if !ok {
+		return
+	}
+	h.metrics.FeedFetched("freebusy", len(events))
+
+	body := ical.GenerateFreeBusy(ical.FreeBusy{
+		UID:     "freebusy-" + uuid.New().String() + "@nexus-cal",
+		Start:   from,
+		End:     to,
+		Stamp:   time.Now(),
+		Periods: ical.BusyPeriods(events, from, to),
+	})
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write([]byte(body))
+}
+
+// parseWindowTime accepts an RFC 3339 timestamp or a bare date (midnight UTC).
+func parseWindowTime(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t.UTC(), nil
+	}
+	return time.Parse("2006-01-02", v)
+}
+
+// loadFeed resolves the {token} URL parameter and converts the feed and the
+// events selected by q, narrowed by any ?categories= filter, to their ical
+// representations, also returning how many events matched before the limit.
+// On failure it writes the error response and returns ok=false.
+func (h *Handler) loadFeed(w http.ResponseWriter, r *http.Request, q database.EventQuery) (ical.Feed, []ical.Event, int, bool) {
 	token := chi.URLParam(r, "token")
 	if token == "" {
 		http.NotFound(w, r)
-		return
+		return ical.Feed{}, nil, 0, false
 	}
 
 	feed, err := h.db.FeedByToken(token)
 	if err != nil {
 		http.NotFound(w, r)
-		return
+		return ical.Feed{}, nil, 0, false
 	}
 
-	events, err := h.db.EventsByFeed(feed.ID)
+	if v := r.URL.Query().Get("categories"); v != "" {
+		q.Categories = database.SplitCategories(v)
+	}
+	events, total, err := h.db.QueryEvents(feed.ID, q)
 	if err != nil {
-		log.Printf("error fetching events for feed %s: %v", feed.ID, err)
+		logging.FromContext(r.Context()).Error("fetch events", "feed_id", feed.ID, "err", err)
 		http.Error(w, "internal error", http.StatusInternalServerError)
-		return
+		return ical.Feed{}, nil, 0, false
 	}
 
 	icalFeed := ical.Feed{
@@ -68,29 +313,71 @@ func (h *Handler) Subscribe(w http.ResponseWriter, r *http.Request) {
 
 	icalEvents := make([]ical.Event, len(events))
 	for i, e := range events {
-		icalEvents[i] = ical.Event{
-			UID:         e.ID + "@nexus-cal",
-			Summary:     e.Summary,
-			Description: e.Description,
-			Location:    e.Location,
-			URL:         e.URL,
-			Start:       e.Start,
-			End:         e.End,
-			AllDay:      e.AllDay,
-			Deadline:    e.Deadline,
-			Status:      e.Status,
-			Categories:  e.Categories,
-			Created:     e.CreatedAt,
-			Updated:     e.UpdatedAt,
+		icalEvents[i] = toICalEvent(e)
+	}
+	return icalFeed, icalEvents, total, true
+}
+
+// toICalEvent converts a stored event to its ical representation.
+func toICalEvent(e *database.Event) ical.Event {
+	ev := ical.Event{
+		UID:         e.ID + "@nexus-cal",
+		Summary:     e.Summary,
+		Description: e.Description,
+		Location:    e.Location,
+		URL:         e.URL,
+		Start:       e.Start,
+		End:         e.End,
+		AllDay:      e.AllDay,
+		Deadline:    e.Deadline,
+		Status:      e.Status,
+		Categories:  e.Categories,
+		Organizer:   e.Organizer,
+		OrganizerCN: e.OrganizerCN,
+		Created:     e.CreatedAt,
+		Updated:     e.UpdatedAt,
+	}
+	for _, a := range e.Attendees {
+		ev.Attendees = append(ev.Attendees, ical.Attendee{
+			Email:    a.Email,
+			Name:     a.Name,
+			PartStat: a.PartStat,
+		})
+	}
+	for _, a := range e.Attachments {
+		ev.Attachments = append(ev.Attachments, ical.Attachment{
+			URI:      a.URL,
+			Data:     a.Data,
+			FmtType:  a.FmtType,
+			Filename: a.Filename,
+		})
+	}
+	return ev
+}
+
+// acceptsJCal reports whether the client asked for jCal via the Accept header.
+func acceptsJCal(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mt := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		if strings.EqualFold(mt, ical.JCalContentType) {
+			return true
 		}
 	}
+	return false
+}
 
-	body := ical.Generate(icalFeed, icalEvents)
+func writeJCal(w http.ResponseWriter, r *http.Request, feed ical.Feed, events []ical.Event) {
+	body, err := ical.GenerateJCal(feed, events)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("generate jCal", "feed", feed.Name, "err", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
 
-	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
-	w.Header().Set("Content-Disposition", "attachment; filename=\"calendar.ics\"")
+	w.Header().Set("Content-Type", ical.JCalContentType+"; charset=utf-8")
 	w.Header().Set("Cache-Control", "no-cache")
-	w.Write([]byte(body))
+	w.Header().Add("Vary", "Accept")
+	w.Write(body)
 }
 
 // --- Management API (JSON) ---
@@ -122,20 +409,37 @@ type createFeedResp struct {
 //	@Router       /api/feeds [post]
 func (h *Handler) CreateFeed(w http.ResponseWriter, r *http.Request) {
 	var req createFeedReq
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		jsonError(w, "invalid request body", http.StatusBadRequest)
+	if err := httpx.DecodeJSON(w, r, &req); err != nil {
+		httpx.WriteError(w, err)
 		return
 	}
-	if req.Name == "" {
-		jsonError(w, "name is required", http.StatusBadRequest)
+
+	feed, msg, status := h.createFeed(r, req)
+	if msg != "" {
+		httpx.Error(w, msg, status)
 		return
 	}
 
+	resp := createFeedResp{
+		ID:    feed.ID,
+		Name:  feed.Name,
+		Token: feed.Token,
+		URL:   "/" + feed.Token + ".ics",
+	}
+	httpx.JSON(w, http.StatusCreated, resp)
+}
+
+// createFeed validates and stores a new feed for the caller. On failure it
+// returns a client-facing error message and HTTP status.
+func (h *Handler) createFeed(r *http.Request, req createFeedReq) (*database.Feed, string, int) {
+	if req.Name == "" {
+		return nil, "name is required", http.StatusBadRequest
+	}
+
 	token := uuid.New().String()
 	if req.Slug != "" {
 		if !slugPattern.MatchString(req.Slug) {
-			jsonError(w, "slug must be 2-64 characters, lowercase alphanumeric and hyphens, must start and end with alphanumeric", http.StatusBadRequest)
-			return
+			return nil, "slug must be 2-64 characters, lowercase alphanumeric and hyphens, must start and end with alphanumeric", http.StatusBadRequest
 		}
 		token = req.Slug
 	}
@@ -143,6 +447,7 @@ func (h *Handler) CreateFeed(w http.ResponseWriter, r *http.Request) {
 	now := time.Now().UTC()
 	feed := &database.Feed{
 		ID:        uuid.New().String(),
+		OwnerID:   ownerID(r),
 		Name:      req.Name,
 		Token:     token,
 		CreatedAt: now,
@@ -150,45 +455,36 @@ func (h *Handler) CreateFeed(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.db.CreateFeed(feed); err != nil {
-		log.Printf("error creating feed: %v", err)
+		logging.FromContext(r.Context()).Error("create feed", "err", err)
 		// Check for slug collision (UNIQUE constraint on token)
 		if req.Slug != "" {
-			jsonError(w, "slug already in use", http.StatusConflict)
-			return
+			return nil, "slug already in use", http.StatusConflict
 		}
-		jsonError(w, "failed to create feed", http.StatusInternalServerError)
-		return
-	}
-
-	resp := createFeedResp{
-		ID:    feed.ID,
-		Name:  feed.Name,
-		Token: feed.Token,
-		URL:   "/" + feed.Token + ".ics",
+		return nil, "failed to create feed", http.StatusInternalServerError
 	}
-	jsonOK(w, http.StatusCreated, resp)
+	return feed, "", 0
 }
 
-// ListFeeds returns all feeds.
+// ListFeeds returns the caller's feeds.
 // GET /api/feeds
 //
-//	@Summary      List all calendar feeds
-//	@Description  Returns all calendar feeds.
+//	@Summary      List calendar feeds
+//	@Description  Returns the calendar feeds owned by the caller's API key.
 //	@Tags         feeds
 //	@Produce      json
 //	@Success      200  {array}  database.Feed
 //	@Router       /api/feeds [get]
 func (h *Handler) ListFeeds(w http.ResponseWriter, r *http.Request) {
-	feeds, err := h.db.ListFeeds()
+	feeds, err := h.db.ListFeedsByOwner(ownerID(r))
 	if err != nil {
-		log.Printf("error listing feeds: %v", err)
-		jsonError(w, "failed to list feeds", http.StatusInternalServerError)
+		logging.FromContext(r.Context()).Error("list feeds", "err", err)
+		httpx.Error(w, "failed to list feeds", http.StatusInternalServerError)
 		return
 	}
 	if feeds == nil {
 		feeds = []*database.Feed{}
 	}
-	jsonOK(w, http.StatusOK, feeds)
+	httpx.JSON(w, http.StatusOK, feeds)
 }
 
 // DeleteFeed removes a feed and all its events.
@@ -199,30 +495,60 @@ func (h *Handler) ListFeeds(w http.ResponseWriter, r *http.Request) {
 //	@Tags         feeds
 //	@Param        id   path  string  true  "Feed ID"
 //	@Success      204  "No Content"
+//	@Failure      404  {object}  map[string]string
 //	@Failure      500  {object}  map[string]string
 //	@Router       /api/feeds/{id} [delete]
 func (h *Handler) DeleteFeed(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
+	if _, ok := h.ownedFeed(r, id); !ok {
+		httpx.Error(w, "feed not found", http.StatusNotFound)
+		return
+	}
 	if err := h.db.DeleteFeed(id); err != nil {
-		log.Printf("error deleting feed %s: %v", id, err)
-		jsonError(w, "failed to delete feed", http.StatusInternalServerError)
+		logging.FromContext(r.Context()).Error("delete feed", "feed_id", id, "err", err)
+		httpx.Error(w, "failed to delete feed", http.StatusInternalServerError)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
+type attendeeReq struct {
+	Email    string `json:"email"`
+	Name     string `json:"name"`
+	PartStat string `json:"partstat"` // optional, defaults to NEEDS-ACTION
+}
+
+type attachmentReq struct {
+	URL      string `json:"url"`      // link to the document; or
+	Data     []byte `json:"data"`     // inline content, base64 (max 64 KiB)
+	FmtType  string `json:"fmttype"`  // media type, optional
+	Filename string `json:"filename"` // optional
+}
+
+type reminderReq struct {
+	To            string `json:"to"`             // E.164 phone number
+	MinutesBefore int    `json:"minutes_before"` // optional, defaults to 15
+}
+
 type createEventReq struct {
-	FeedID      string  `json:"feed_id"`
-	Summary     string  `json:"summary"`
-	Description string  `json:"description"`
-	Location    string  `json:"location"`
-	URL         string  `json:"url"`
-	Start       string  `json:"start"` // RFC 3339
-	End         *string `json:"end"`   // RFC 3339, optional
-	AllDay      bool    `json:"all_day"`
-	Deadline    *string `json:"deadline"` // RFC 3339, optional
-	Status      string  `json:"status"`
-	Categories  string  `json:"categories"`
+	FeedID          string          `json:"feed_id"`
+	Summary         string          `json:"summary"`
+	Description     string          `json:"description"`
+	Location        string          `json:"location"`
+	URL             string          `json:"url"`
+	Start           string          `json:"start"` // RFC 3339
+	End             *string         `json:"end"`   // RFC 3339, optional
+	AllDay          bool            `json:"all_day"`
+	Deadline        *string         `json:"deadline"` // RFC 3339, optional
+	Status          string          `json:"status"`
+	Categories      string          `json:"categories"`
+	Organizer       string          `json:"organizer"`      // organizer email, optional
+	OrganizerName   string          `json:"organizer_name"` // optional
+	Attendees       []attendeeReq   `json:"attendees"`
+	Attachments     []attachmentReq `json:"attachments"`
+	SendInvitations bool            `json:"send_invitations"` // email METHOD:REQUEST invitations to attendees
+	SMSReminder     *reminderReq    `json:"sms_reminder"`     // text a reminder before start, optional
+	DedupeKey       string          `json:"dedupe_key"`       // idempotency key; a repeat returns the existing event
 }
 
 // CreateEvent adds an event to a feed.
@@ -230,36 +556,69 @@ type createEventReq struct {
 //
 //	@Summary      Create a calendar event
 //	@Description  Adds a new event to a calendar feed. Dates must be RFC 3339 format.
+//	@Description  If dedupe_key matches an event already created in the feed, that event is returned with 200 and nothing is created.
 //	@Tags         events
 //	@Accept       json
 //	@Produce      json
 //	@Param        body  body      createEventReq  true  "Event creation request"
+//	@Success      200   {object}  database.Event  "Existing event with the same dedupe_key"
 //	@Success      201   {object}  database.Event
 //	@Failure      400   {object}  map[string]string
+//	@Failure      404   {object}  map[string]string
 //	@Router       /api/events [post]
 func (h *Handler) CreateEvent(w http.ResponseWriter, r *http.Request) {
 	var req createEventReq
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		jsonError(w, "invalid request body", http.StatusBadRequest)
+	if err := httpx.DecodeJSON(w, r, &req); err != nil {
+		httpx.WriteError(w, err)
 		return
 	}
-	if req.FeedID == "" || req.Summary == "" || req.Start == "" {
-		jsonError(w, "feed_id, summary, and start are required", http.StatusBadRequest)
+
+	event, msg := h.eventFromRequest(req)
+	if msg != "" {
+		httpx.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+	if _, ok := h.ownedFeed(r, event.FeedID); !ok {
+		httpx.Error(w, "feed not found", http.StatusNotFound)
+		return
+	}
+
+	if existing, ok := h.dedupedEvent(r, event); ok {
+		httpx.JSON(w, http.StatusOK, existing)
+		return
+	}
+	if err := h.db.CreateEvent(event); err != nil {
+		// A concurrent retry may have won the race on the unique index.
+		if existing, ok := h.dedupedEvent(r, event); ok {
+			httpx.JSON(w, http.StatusOK, existing)
+			return
+		}
+		logging.FromContext(r.Context()).Error("create event", "err", err)
+		httpx.Error(w, "failed to create event", http.StatusInternalServerError)
 		return
 	}
+	h.afterCreateEvent(r, event, req.SendInvitations)
+
+	httpx.JSON(w, http.StatusCreated, event)
+}
+
+// eventFromRequest validates an event creation request and builds the event
+// to store. On failure it returns a client-facing error message.
+func (h *Handler) eventFromRequest(req createEventReq) (*database.Event, string) {
+	if req.FeedID == "" || req.Summary == "" || req.Start == "" {
+		return nil, "feed_id, summary, and start are required"
+	}
 
 	start, err := time.Parse(time.RFC3339, req.Start)
 	if err != nil {
-		jsonError(w, "start must be RFC 3339 format", http.StatusBadRequest)
-		return
+		return nil, "start must be RFC 3339 format"
 	}
 
 	var end *time.Time
 	if req.End != nil {
 		t, err := time.Parse(time.RFC3339, *req.End)
 		if err != nil {
-			jsonError(w, "end must be RFC 3339 format", http.StatusBadRequest)
-			return
+			return nil, "end must be RFC 3339 format"
 		}
 		end = &t
 	}
@@ -268,8 +627,7 @@ func (h *Handler) CreateEvent(w http.ResponseWriter, r *http.Request) {
 	if req.Deadline != nil {
 		t, err := time.Parse(time.RFC3339, *req.Deadline)
 		if err != nil {
-			jsonError(w, "deadline must be RFC 3339 format", http.StatusBadRequest)
-			return
+			return nil, "deadline must be RFC 3339 format"
 		}
 		deadline = &t
 	}
@@ -279,8 +637,45 @@ func (h *Handler) CreateEvent(w http.ResponseWriter, r *http.Request) {
 		status = "CONFIRMED"
 	}
 
+	if req.Organizer != "" && !emailPattern.MatchString(req.Organizer) {
+		return nil, "organizer must be an email address"
+	}
+	attendees, msg := parseAttendees(req.Attendees)
+	if msg != "" {
+		return nil, msg
+	}
+	attachments, msg := parseAttachments(req.Attachments)
+	if msg != "" {
+		return nil, msg
+	}
+	var reminder *database.Reminder
+	if req.SMSReminder != nil {
+		if !e164Pattern.MatchString(req.SMSReminder.To) {
+			return nil, "sms_reminder.to must be an E.164 phone number"
+		}
+		minutes := req.SMSReminder.MinutesBefore
+		if minutes == 0 {
+			minutes = defaultReminderMinutes
+		}
+		if minutes < 0 || minutes > maxReminderMinutes {
+			return nil, "sms_reminder.minutes_before must be between 1 and 10080"
+		}
+		reminder = &database.Reminder{To: req.SMSReminder.To, MinutesBefore: minutes}
+	}
+	if len(req.DedupeKey) > maxDedupeKeySize {
+		return nil, "dedupe_key is too long"
+	}
+	if req.SendInvitations {
+		if h.mailer == nil {
+			return nil, "email invitations are not configured"
+		}
+		if req.Organizer == "" {
+			return nil, "organizer is required to send invitations"
+		}
+	}
+
 	now := time.Now().UTC()
-	event := &database.Event{
+	return &database.Event{
 		ID:          uuid.New().String(),
 		FeedID:      req.FeedID,
 		Summary:     req.Summary,
@@ -293,41 +688,318 @@ func (h *Handler) CreateEvent(w http.ResponseWriter, r *http.Request) {
 		Deadline:    deadline,
 		Status:      status,
 		Categories:  req.Categories,
+		Organizer:   req.Organizer,
+		OrganizerCN: req.OrganizerName,
+		Attendees:   attendees,
+		Attachments: attachments,
+		Reminder:    reminder,
+		DedupeKey:   req.DedupeKey,
 		CreatedAt:   now,
 		UpdatedAt:   now,
+	}, ""
+}
+
+// dedupedEvent returns the event previously created in the same feed with
+// event's dedupe key, if any.
+func (h *Handler) dedupedEvent(r *http.Request, event *database.Event) (*database.Event, bool) {
+	if event.DedupeKey == "" {
+		return nil, false
+	}
+	existing, err := h.db.EventByDedupeKey(event.FeedID, event.DedupeKey)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			logging.FromContext(r.Context()).Error("look up dedupe key", "feed_id", event.FeedID, "dedupe_key", event.DedupeKey, "err", err)
+		}
+		return nil, false
+	}
+	return existing, true
+}
+
+// afterCreateEvent runs the side effects of a newly stored event.
+// Invitations and the event.created announcement are best-effort: the
+// event exists either way, and a failure is logged rather than surfaced as
+// a failed create.
+func (h *Handler) afterCreateEvent(r *http.Request, event *database.Event, sendInvitations bool) {
+	log := logging.FromContext(r.Context())
+	if sendInvitations && len(event.Attendees) > 0 {
+		if err := h.sendInvitation(event); err != nil {
+			log.Error("send invitations", "event_id", event.ID, "err", err)
+		}
+	}
+	if h.bus != nil {
+		err := events.Publish(r.Context(), h.bus, events.EventCreated, event.FeedID, events.CalendarEvent{
+			ID:      event.ID,
+			FeedID:  event.FeedID,
+			OwnerID: ownerID(r),
+			Summary: event.Summary,
+			Start:   event.Start,
+			End:     event.End,
+			AllDay:  event.AllDay,
+		})
+		if err != nil {
+			log.Error("publish event.created", "event_id", event.ID, "err", err)
+		}
 	}
+}
 
-	if err := h.db.CreateEvent(event); err != nil {
-		log.Printf("error creating event: %v", err)
-		jsonError(w, "failed to create event", http.StatusInternalServerError)
+// parseAttendees validates attendee requests. On failure it returns a
+// client-facing error message.
+func parseAttendees(reqs []attendeeReq) ([]database.Attendee, string) {
+	seen := make(map[string]bool, len(reqs))
+	var out []database.Attendee
+	for _, a := range reqs {
+		email := strings.ToLower(strings.TrimSpace(a.Email))
+		if !emailPattern.MatchString(email) {
+			return nil, "each attendee needs a valid email"
+		}
+		if seen[email] {
+			return nil, "duplicate attendee: " + email
+		}
+		seen[email] = true
+
+		partstat := strings.ToUpper(a.PartStat)
+		if partstat == "" {
+			partstat = database.PartStatNeedsAction
+		}
+		if !database.ValidPartStat(partstat) {
+			return nil, "partstat must be one of NEEDS-ACTION, ACCEPTED, DECLINED, TENTATIVE, DELEGATED"
+		}
+		out = append(out, database.Attendee{Email: email, Name: a.Name, PartStat: partstat})
+	}
+	return out, ""
+}
+
+// parseAttachments validates attachment requests. On failure it returns a
+// client-facing error message.
+func parseAttachments(reqs []attachmentReq) ([]database.Attachment, string) {
+	if len(reqs) > maxAttachments {
+		return nil, fmt.Sprintf("at most %d attachments are allowed", maxAttachments)
+	}
+	var out []database.Attachment
+	for _, a := range reqs {
+		switch {
+		case a.URL != "" && len(a.Data) > 0:
+			return nil, "an attachment has either url or data, not both"
+		case a.URL != "":
+			u, err := url.Parse(a.URL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return nil, "attachment url must be an absolute http or https URL"
+			}
+		case len(a.Data) > 0:
+			if len(a.Data) > maxInlineAttachBytes {
+				return nil, "inline attachments are limited to 64 KiB; link larger documents by url"
+			}
+		default:
+			return nil, "each attachment needs a url or data"
+		}
+		if a.FmtType != "" && !mediaTypePattern.MatchString(a.FmtType) {
+			return nil, "attachment fmttype must be a media type such as application/pdf"
+		}
+		if len(a.Filename) > maxAttachmentNameSize {
+			return nil, "attachment filename is too long"
+		}
+		out = append(out, database.Attachment{
+			ID:       uuid.New().String(),
+			URL:      a.URL,
+			Data:     a.Data,
+			FmtType:  strings.ToLower(a.FmtType),
+			Filename: a.Filename,
+		})
+	}
+	return out, ""
+}
+
+// sendInvitation emails a METHOD:REQUEST calendar for the event to every
+// attendee, asking them to RSVP.
+func (h *Handler) sendInvitation(e *database.Event) error {
+	ev := toICalEvent(e)
+	to := make([]string, len(ev.Attendees))
+	for i := range ev.Attendees {
+		ev.Attendees[i].RSVP = true
+		to[i] = ev.Attendees[i].Email
+	}
+
+	ics := ical.Generate(ical.Feed{Name: e.Summary, Method: ical.MethodRequest}, []ical.Event{ev})
+
+	body := "You have been invited to: " + e.Summary + "\r\n" +
+		"When: " + e.Start.UTC().Format(time.RFC1123) + "\r\n"
+	if e.Location != "" {
+		body += "Where: " + e.Location + "\r\n"
+	}
+
+	return h.mailer.SendInvitation(mailer.Invitation{
+		To:      to,
+		Subject: "Invitation: " + e.Summary,
+		Body:    body,
+		ICS:     []byte(ics),
+	})
+}
+
+type updateAttendeeReq struct {
+	PartStat string `json:"partstat"`
+}
+
+// UpdateAttendee records an attendee's participation status.
+// PUT /api/events/{id}/attendees/{email}
+//
+//	@Summary      Update attendee participation status
+//	@Description  Sets the PARTSTAT of an attendee (e.g. ACCEPTED, DECLINED).
+//	@Tags         events
+//	@Accept       json
+//	@Produce      json
+//	@Param        id     path      string             true  "Event ID"
+//	@Param        email  path      string             true  "Attendee email"
+//	@Param        body   body      updateAttendeeReq  true  "New participation status"
+//	@Success      200    {object}  database.Event
+//	@Failure      400    {object}  map[string]string
+//	@Failure      404    {object}  map[string]string
+//	@Router       /api/events/{id}/attendees/{email} [put]
+func (h *Handler) UpdateAttendee(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	email := strings.ToLower(chi.URLParam(r, "email"))
+
+	var req updateAttendeeReq
+	if err := httpx.DecodeJSON(w, r, &req); err != nil {
+		httpx.WriteError(w, err)
+		return
+	}
+	partstat := strings.ToUpper(req.PartStat)
+	if !database.ValidPartStat(partstat) {
+		httpx.Error(w, "partstat must be one of NEEDS-ACTION, ACCEPTED, DECLINED, TENTATIVE, DELEGATED", http.StatusBadRequest)
 		return
 	}
 
-	jsonOK(w, http.StatusCreated, event)
+	if _, ok := h.ownedEvent(r, id); !ok {
+		httpx.Error(w, "event not found", http.StatusNotFound)
+		return
+	}
+	if err := h.db.UpdateAttendeeStatus(id, email, partstat); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			httpx.Error(w, "attendee not found", http.StatusNotFound)
+			return
+		}
+		logging.FromContext(r.Context()).Error("update attendee", "event_id", id, "email", email, "err", err)
+		httpx.Error(w, "failed to update attendee", http.StatusInternalServerError)
+		return
+	}
+
+	event, err := h.db.EventByID(id)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("load event", "event_id", id, "err", err)
+		httpx.Error(w, "failed to load event", http.StatusInternalServerError)
+		return
+	}
+	httpx.JSON(w, http.StatusOK, event)
 }
 
-// ListEvents returns all events for a feed.
-// GET /api/feeds/{id}/events
+// ListEvents returns a page of a feed's events.
+// GET /api/feeds/{id}/events?from=&to=&limit=&offset=
 //
 //	@Summary      List events for a feed
-//	@Description  Returns all events belonging to a calendar feed.
+//	@Description  Returns events belonging to a calendar feed, ordered by start time.
+//	@Description  The total number of matching events is returned in X-Total-Count.
 //	@Tags         events
 //	@Produce      json
-//	@Param        id   path      string  true  "Feed ID"
-//	@Success      200  {array}   database.Event
+//	@Param        id      path      string  true   "Feed ID"
+//	@Param        from    query     string  false  "Only events ending after this time (RFC 3339 or YYYY-MM-DD)"
+//	@Param        to      query     string  false  "Only events starting before this time (RFC 3339 or YYYY-MM-DD)"
+//	@Param        limit   query     int     false  "Page size (default and maximum: the server's event cap)"
+//	@Param        offset  query     int     false  "Events to skip"
+//	@Success      200     {array}   database.Event
+//	@Failure      400     {object}  map[string]string
+//	@Failure      404     {object}  map[string]string
 //	@Router       /api/feeds/{id}/events [get]
 func (h *Handler) ListEvents(w http.ResponseWriter, r *http.Request) {
 	feedID := chi.URLParam(r, "id")
-	events, err := h.db.EventsByFeed(feedID)
+	if _, ok := h.ownedFeed(r, feedID); !ok {
+		httpx.Error(w, "feed not found", http.StatusNotFound)
+		return
+	}
+	q, msg := h.listQuery(r)
+	if msg != "" {
+		httpx.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+	events, total, err := h.db.QueryEvents(feedID, q)
 	if err != nil {
-		log.Printf("error listing events for feed %s: %v", feedID, err)
-		jsonError(w, "failed to list events", http.StatusInternalServerError)
+		logging.FromContext(r.Context()).Error("list events", "feed_id", feedID, "err", err)
+		httpx.Error(w, "failed to list events", http.StatusInternalServerError)
 		return
 	}
 	if events == nil {
 		events = []*database.Event{}
 	}
-	jsonOK(w, http.StatusOK, events)
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	httpx.JSON(w, http.StatusOK, events)
+}
+
+// listQuery parses the paging parameters of ListEvents. On failure it
+// returns a client-facing error message.
+func (h *Handler) listQuery(r *http.Request) (database.EventQuery, string) {
+	q := database.EventQuery{Limit: h.horizon.MaxEvents}
+	v := r.URL.Query()
+	if s := v.Get("from"); s != "" {
+		t, err := parseWindowTime(s)
+		if err != nil {
+			return q, "from must be RFC 3339 or YYYY-MM-DD"
+		}
+		q.From = t
+	}
+	if s := v.Get("to"); s != "" {
+		t, err := parseWindowTime(s)
+		if err != nil {
+			return q, "to must be RFC 3339 or YYYY-MM-DD"
+		}
+		q.To = t
+	}
+	if s := v.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			return q, "limit must be a positive integer"
+		}
+		if h.horizon.MaxEvents > 0 && n > h.horizon.MaxEvents {
+			return q, fmt.Sprintf("limit must be between 1 and %d", h.horizon.MaxEvents)
+		}
+		q.Limit = n
+	}
+	if s := v.Get("offset"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return q, "offset must be a non-negative integer"
+		}
+		q.Offset = n
+	}
+	return q, ""
+}
+
+// ListCategories returns the distinct categories used by a feed's events.
+// GET /api/feeds/{id}/categories
+//
+//	@Summary      List categories in a feed
+//	@Description  Returns the distinct categories used by a feed's events, with event counts.
+//	@Description  Any of them can be passed to /{token}.ics?categories= to subscribe to a filtered feed.
+//	@Tags         feeds
+//	@Produce      json
+//	@Param        id   path      string  true  "Feed ID"
+//	@Success      200  {array}   database.CategoryCount
+//	@Failure      404  {object}  map[string]string
+//	@Router       /api/feeds/{id}/categories [get]
+func (h *Handler) ListCategories(w http.ResponseWriter, r *http.Request) {
+	feedID := chi.URLParam(r, "id")
+	if _, ok := h.ownedFeed(r, feedID); !ok {
+		httpx.Error(w, "feed not found", http.StatusNotFound)
+		return
+	}
+	cats, err := h.db.CategoriesByFeed(feedID)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("list categories", "feed_id", feedID, "err", err)
+		httpx.Error(w, "failed to list categories", http.StatusInternalServerError)
+		return
+	}
+	if cats == nil {
+		cats = []database.CategoryCount{}
+	}
+	httpx.JSON(w, http.StatusOK, cats)
 }
 
 // DeleteEvent removes a single event.
@@ -338,28 +1010,19 @@ func (h *Handler) ListEvents(w http.ResponseWriter, r *http.Request) {
 //	@Tags         events
 //	@Param        id   path  string  true  "Event ID"
 //	@Success      204  "No Content"
+//	@Failure      404  {object}  map[string]string
 //	@Failure      500  {object}  map[string]string
 //	@Router       /api/events/{id} [delete]
 func (h *Handler) DeleteEvent(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
+	if _, ok := h.ownedEvent(r, id); !ok {
+		httpx.Error(w, "event not found", http.StatusNotFound)
+		return
+	}
 	if err := h.db.DeleteEvent(id); err != nil {
-		log.Printf("error deleting event %s: %v", id, err)
-		jsonError(w, "failed to delete event", http.StatusInternalServerError)
+		logging.FromContext(r.Context()).Error("delete event", "event_id", id, "err", err)
+		httpx.Error(w, "failed to delete event", http.StatusInternalServerError)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
-
-// --- helpers ---
-
-func jsonOK(w http.ResponseWriter, status int, data interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	_ = json.NewEncoder(w).Encode(data)
-}
-
-func jsonError(w http.ResponseWriter, msg string, status int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
-}
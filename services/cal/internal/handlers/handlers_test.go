@@ -8,10 +8,12 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
 	"github.com/jredh-dev/nexus/services/cal/internal/database"
+	"github.com/jredh-dev/nexus/services/cal/internal/mailer"
 )
 
 func testHandler(t *testing.T) *Handler {
@@ -25,20 +27,27 @@ func testHandler(t *testing.T) *Handler {
 		db.Close()
 		os.Remove(path)
 	})
-	return New(db)
+	// Fixtures use fixed dates, so widen the horizon to keep them in view
+	// regardless of when the tests run.
+	return New(db, WithHorizon(Horizon{Past: 100 * 365 * 24 * time.Hour, Future: 100 * 365 * 24 * time.Hour, MaxEvents: 5000}))
 }
 
 func testRouter(h *Handler) *chi.Mux {
 	r := chi.NewRouter()
 	r.Get("/{token}.ics", h.Subscribe)
+	r.Get("/{token}.json", h.SubscribeJSON)
+	r.Get("/{token}/freebusy", h.FreeBusy)
 	r.Route("/api", func(r chi.Router) {
 		r.Post("/feeds", h.CreateFeed)
 		r.Get("/feeds", h.ListFeeds)
 		r.Delete("/feeds/{id}", h.DeleteFeed)
 		r.Get("/feeds/{id}/events", h.ListEvents)
+		r.Get("/feeds/{id}/categories", h.ListCategories)
 		r.Post("/events", h.CreateEvent)
 		r.Delete("/events/{id}", h.DeleteEvent)
+		r.Put("/events/{id}/attendees/{email}", h.UpdateAttendee)
 	})
+	r.Mount("/admin", h.AdminRouter())
 	return r
 }
 
@@ -216,6 +225,17 @@ func TestCreateEvent_ValidationErrors(t *testing.T) {
 	if w.Code != http.StatusBadRequest {
 		t.Errorf("expected 400 for bad date, got %d", w.Code)
 	}
+
+	// Misspelt field
+	body = `{"feed_id":"x","summary":"test","start":"2025-01-01T10:00:00Z","all_dya":true}`
+	req = httptest.NewRequest(http.MethodPost, "/api/events", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), `unknown field \"all_dya\"`) {
+		t.Errorf("expected 400 naming the unknown field, got %d %s", w.Code, w.Body)
+	}
 }
 
 func TestDeleteFeedAndEvents(t *testing.T) {
@@ -376,3 +396,662 @@ func TestCreateFeed_WithoutSlug_GetsUUID(t *testing.T) {
 		t.Errorf("expected UUID token (36 chars), got %q (%d chars)", created.Token, len(created.Token))
 	}
 }
+
+// createTestFeed creates a feed through the API and returns the response.
+func createTestFeed(t *testing.T, r http.Handler, body string) createFeedResp {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/feeds", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create feed: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var feed createFeedResp
+	if err := json.Unmarshal(w.Body.Bytes(), &feed); err != nil {
+		t.Fatalf("unmarshal feed: %v", err)
+	}
+	return feed
+}
+
+// createTestEvent creates an event through the API and returns the response.
+func createTestEvent(t *testing.T, r http.Handler, fields map[string]interface{}) database.Event {
+	t.Helper()
+	body, _ := json.Marshal(fields)
+	req := httptest.NewRequest(http.MethodPost, "/api/events", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create event: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var event database.Event
+	if err := json.Unmarshal(w.Body.Bytes(), &event); err != nil {
+		t.Fatalf("unmarshal event: %v", err)
+	}
+	return event
+}
+
+func TestSubscribe_JCal(t *testing.T) {
+	h := testHandler(t)
+	r := testRouter(h)
+
+	feed := createTestFeed(t, r, `{"name":"Web"}`)
+	createTestEvent(t, r, map[string]interface{}{
+		"feed_id": feed.ID,
+		"summary": "Launch",
+		"start":   "2026-04-01T12:00:00Z",
+	})
+
+	tests := []struct {
+		name   string
+		path   string
+		accept string
+	}{
+		{"extension", "/" + feed.Token + ".json", ""},
+		{"accept header", "/" + feed.Token + ".ics", "application/calendar+json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d", w.Code)
+			}
+			if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/calendar+json") {
+				t.Errorf("expected Content-Type application/calendar+json, got %q", ct)
+			}
+
+			var doc []interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+				t.Fatalf("unmarshal jCal: %v", err)
+			}
+			if len(doc) != 3 || doc[0] != "vcalendar" {
+				t.Fatalf("expected vcalendar component, got %v", doc)
+			}
+			if !strings.Contains(w.Body.String(), `["summary",{},"text","Launch"]`) {
+				t.Errorf("jCal output missing summary: %s", w.Body.String())
+			}
+		})
+	}
+
+	// A plain .ics request still gets iCal.
+	req := httptest.NewRequest(http.MethodGet, "/"+feed.Token+".ics", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/calendar") {
+		t.Errorf("expected Content-Type text/calendar, got %q", ct)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/nonexistent.json", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for invalid token, got %d", w.Code)
+	}
+}
+
+// recordingMailer captures invitations instead of sending them.
+type recordingMailer struct {
+	sent []mailer.Invitation
+}
+
+func (m *recordingMailer) SendInvitation(inv mailer.Invitation) error {
+	m.sent = append(m.sent, inv)
+	return nil
+}
+
+func TestCreateEvent_AttendeesAndInvitations(t *testing.T) {
+	path := t.TempDir() + "/test.db"
+	db, err := database.Open(path)
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	m := &recordingMailer{}
+	r := testRouter(New(db, WithMailer(m)))
+
+	feed := createTestFeed(t, r, `{"name":"Meetings"}`)
+	event := createTestEvent(t, r, map[string]interface{}{
+		"feed_id":        feed.ID,
+		"summary":        "Planning",
+		"start":          "2026-05-01T15:00:00Z",
+		"organizer":      "boss@example.com",
+		"organizer_name": "Boss",
+		"attendees": []map[string]string{
+			{"email": "Ann@Example.com", "name": "Ann"},
+			{"email": "bob@example.com", "partstat": "tentative"},
+		},
+		"send_invitations": true,
+	})
+
+	if len(event.Attendees) != 2 {
+		t.Fatalf("expected 2 attendees, got %+v", event.Attendees)
+	}
+	if len(m.sent) != 1 {
+		t.Fatalf("expected 1 invitation, got %d", len(m.sent))
+	}
+	inv := m.sent[0]
+	if len(inv.To) != 2 || inv.To[0] != "ann@example.com" {
+		t.Errorf("unexpected recipients: %v", inv.To)
+	}
+	for _, s := range []string{"METHOD:REQUEST", "ORGANIZER;CN=Boss:mailto:boss@example.com", "RSVP=TRUE"} {
+		if !strings.Contains(string(inv.ICS), s) {
+			t.Errorf("invitation missing %q", s)
+		}
+	}
+
+	// Accept on behalf of Ann, then check the feed.
+	req := httptest.NewRequest(http.MethodPut, "/api/events/"+event.ID+"/attendees/ann@example.com",
+		strings.NewReader(`{"partstat":"ACCEPTED"}`))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("update attendee: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/"+feed.Token+".ics", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	ics := w.Body.String()
+	for _, s := range []string{
+		"ATTENDEE;CN=Ann;PARTSTAT=ACCEPTED:mailto:ann@example.com",
+		"ATTENDEE;PARTSTAT=TENTATIVE:mailto:bob@example.com",
+	} {
+		if !strings.Contains(ics, s) {
+			t.Errorf("feed missing %q", s)
+		}
+	}
+	if strings.Contains(ics, "RSVP=TRUE") {
+		t.Error("published feed should not ask for RSVP")
+	}
+
+	// Unknown attendee
+	req = httptest.NewRequest(http.MethodPut, "/api/events/"+event.ID+"/attendees/nobody@example.com",
+		strings.NewReader(`{"partstat":"ACCEPTED"}`))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown attendee, got %d", w.Code)
+	}
+}
+
+func TestCreateEvent_AttendeeValidation(t *testing.T) {
+	h := testHandler(t)
+	r := testRouter(h)
+	feed := createTestFeed(t, r, `{"name":"Meetings"}`)
+
+	tests := []struct {
+		name   string
+		fields map[string]interface{}
+	}{
+		{"bad attendee email", map[string]interface{}{
+			"attendees": []map[string]string{{"email": "not-an-email"}},
+		}},
+		{"bad partstat", map[string]interface{}{
+			"attendees": []map[string]string{{"email": "a@example.com", "partstat": "MAYBE"}},
+		}},
+		{"duplicate attendee", map[string]interface{}{
+			"attendees": []map[string]string{{"email": "a@example.com"}, {"email": "A@example.com"}},
+		}},
+		{"bad organizer", map[string]interface{}{"organizer": "boss"}},
+		{"invitations without mailer", map[string]interface{}{
+			"organizer":        "boss@example.com",
+			"attendees":        []map[string]string{{"email": "a@example.com"}},
+			"send_invitations": true,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := map[string]interface{}{
+				"feed_id": feed.ID,
+				"summary": "Planning",
+				"start":   "2026-05-01T15:00:00Z",
+			}
+			for k, v := range tt.fields {
+				fields[k] = v
+			}
+			body, _ := json.Marshal(fields)
+			req := httptest.NewRequest(http.MethodPost, "/api/events", bytes.NewReader(body))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestFreeBusy(t *testing.T) {
+	h := testHandler(t)
+	r := testRouter(h)
+
+	feed := createTestFeed(t, r, `{"name":"Availability"}`)
+	createTestEvent(t, r, map[string]interface{}{
+		"feed_id":     feed.ID,
+		"summary":     "Secret meeting",
+		"description": "Do not leak",
+		"start":       "2026-03-02T09:00:00Z",
+		"end":         "2026-03-02T10:00:00Z",
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/"+feed.Token+"/freebusy?from=2026-03-02&to=2026-03-03", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "FREEBUSY;FBTYPE=BUSY:20260302T090000Z/20260302T100000Z") {
+		t.Errorf("missing busy period:\n%s", body)
+	}
+	if strings.Contains(body, "Secret meeting") || strings.Contains(body, "Do not leak") {
+		t.Error("free/busy must not reveal event details")
+	}
+
+	bad := []string{
+		"/" + feed.Token + "/freebusy?from=yesterday",
+		"/" + feed.Token + "/freebusy?from=2026-03-03&to=2026-03-02",
+		"/" + feed.Token + "/freebusy?from=2026-01-01&to=2028-01-01",
+	}
+	for _, path := range bad {
+		req = httptest.NewRequest(http.MethodGet, path, nil)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", path, w.Code)
+		}
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/nonexistent/freebusy", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for invalid token, got %d", w.Code)
+	}
+}
+
+func TestCategories_FilterAndList(t *testing.T) {
+	h := testHandler(t)
+	r := testRouter(h)
+
+	feed := createTestFeed(t, r, `{"name":"Everything"}`)
+	for _, ev := range []struct{ summary, categories string }{
+		{"Standup", "work"},
+		{"Tax return", "Deadlines, personal"},
+		{"Gym", "personal"},
+		{"Untagged", ""},
+	} {
+		createTestEvent(t, r, map[string]interface{}{
+			"feed_id":    feed.ID,
+			"summary":    ev.summary,
+			"start":      "2026-03-02T09:00:00Z",
+			"categories": ev.categories,
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/"+feed.Token+".ics?categories=work,deadlines", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	ics := w.Body.String()
+	for _, s := range []string{"SUMMARY:Standup", "SUMMARY:Tax return"} {
+		if !strings.Contains(ics, s) {
+			t.Errorf("filtered feed missing %q", s)
+		}
+	}
+	for _, s := range []string{"SUMMARY:Gym", "SUMMARY:Untagged"} {
+		if strings.Contains(ics, s) {
+			t.Errorf("filtered feed should not contain %q", s)
+		}
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/feeds/"+feed.ID+"/categories", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("list categories: expected 200, got %d", w.Code)
+	}
+	var cats []database.CategoryCount
+	if err := json.Unmarshal(w.Body.Bytes(), &cats); err != nil {
+		t.Fatalf("unmarshal categories: %v", err)
+	}
+	want := []database.CategoryCount{
+		{Name: "Deadlines", Events: 1},
+		{Name: "personal", Events: 2},
+		{Name: "work", Events: 1},
+	}
+	if len(cats) != len(want) {
+		t.Fatalf("expected %v, got %v", want, cats)
+	}
+	for i := range want {
+		if cats[i] != want[i] {
+			t.Errorf("category %d = %v, want %v", i, cats[i], want[i])
+		}
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/feeds/nonexistent/categories", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown feed, got %d", w.Code)
+	}
+}
+
+func TestCreateEvent_SMSReminder(t *testing.T) {
+	h := testHandler(t)
+	r := testRouter(h)
+	feed := createTestFeed(t, r, `{"name":"Reminders"}`)
+
+	event := createTestEvent(t, r, map[string]interface{}{
+		"feed_id":      feed.ID,
+		"summary":      "Dentist",
+		"start":        "2026-05-01T15:00:00Z",
+		"sms_reminder": map[string]interface{}{"to": "+15555550100"},
+	})
+	if event.Reminder == nil || event.Reminder.MinutesBefore != defaultReminderMinutes {
+		t.Errorf("expected default reminder lead time, got %+v", event.Reminder)
+	}
+
+	for _, reminder := range []map[string]interface{}{
+		{"to": "555-0100"},
+		{"to": "+15555550100", "minutes_before": -5},
+		{"to": "+15555550100", "minutes_before": maxReminderMinutes + 1},
+	} {
+		body, _ := json.Marshal(map[string]interface{}{
+			"feed_id":      feed.ID,
+			"summary":      "Dentist",
+			"start":        "2026-05-01T15:00:00Z",
+			"sms_reminder": reminder,
+		})
+		req := httptest.NewRequest(http.MethodPost, "/api/events", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("reminder %v: expected 400, got %d", reminder, w.Code)
+		}
+	}
+}
+
+func TestCreateEvent_Attachments(t *testing.T) {
+	h := testHandler(t)
+	r := testRouter(h)
+	feed := createTestFeed(t, r, `{"name":"Agendas"}`)
+
+	event := createTestEvent(t, r, map[string]interface{}{
+		"feed_id": feed.ID,
+		"summary": "Board meeting",
+		"start":   "2026-05-01T15:00:00Z",
+		"attachments": []map[string]interface{}{
+			{"url": "https://example.com/agenda.pdf", "fmttype": "application/pdf"},
+			{"data": "aGVsbG8=", "fmttype": "text/plain", "filename": "notes.txt"},
+		},
+	})
+	if len(event.Attachments) != 2 || string(event.Attachments[1].Data) != "hello" {
+		t.Fatalf("unexpected attachments: %+v", event.Attachments)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/"+feed.Token+".ics", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	body := strings.ReplaceAll(w.Body.String(), "\r\n ", "")
+	for _, want := range []string{
+		"ATTACH;FMTTYPE=application/pdf:https://example.com/agenda.pdf",
+		"ATTACH;FMTTYPE=text/plain;ENCODING=BASE64;VALUE=BINARY;X-FILENAME=notes.txt:aGVsbG8=",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("feed missing %q", want)
+		}
+	}
+
+	for _, attachment := range []map[string]interface{}{
+		{},
+		{"url": "javascript:alert(1)"},
+		{"url": "https://example.com/a", "data": "aGk="},
+		{"data": strings.Repeat("A", (maxInlineAttachBytes/3+1)*4)},
+		{"url": "https://example.com/a", "fmttype": "not a type"},
+	} {
+		body, _ := json.Marshal(map[string]interface{}{
+			"feed_id":     feed.ID,
+			"summary":     "Bad",
+			"start":       "2026-05-01T15:00:00Z",
+			"attachments": []map[string]interface{}{attachment},
+		})
+		req := httptest.NewRequest(http.MethodPost, "/api/events", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("attachment %v: expected 400, got %d", attachment, w.Code)
+		}
+	}
+}
+
+func TestSubscribe_Horizon(t *testing.T) {
+	path := t.TempDir() + "/test.db"
+	db, err := database.Open(path)
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	h := New(db, WithHorizon(Horizon{Past: 30 * 24 * time.Hour, Future: 30 * 24 * time.Hour, MaxEvents: 2}))
+	r := testRouter(h)
+
+	feed := createTestFeed(t, r, `{"name":"Busy"}`)
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, ev := range []struct {
+		summary string
+		start   time.Time
+	}{
+		{"Ancient", now.AddDate(-2, 0, 0)},
+		{"Yesterday", now.AddDate(0, 0, -1)},
+		{"Tomorrow", now.AddDate(0, 0, 1)},
+		{"Next week", now.AddDate(0, 0, 7)},
+		{"Far future", now.AddDate(2, 0, 0)},
+	} {
+		createTestEvent(t, r, map[string]interface{}{
+			"feed_id": feed.ID,
+			"summary": ev.summary,
+			"start":   ev.start.Format(time.RFC3339),
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/"+feed.Token+".ics", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	body := w.Body.String()
+
+	// Three events fall inside ±30 days; the cap keeps the two nearest now.
+	for _, want := range []string{"SUMMARY:Yesterday", "SUMMARY:Tomorrow"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("feed missing %q", want)
+		}
+	}
+	for _, unwanted := range []string{"Ancient", "Far future", "Next week"} {
+		if strings.Contains(body, unwanted) {
+			t.Errorf("feed should not contain %q", unwanted)
+		}
+	}
+	for header, want := range map[string]string{
+		"X-Cal-Events":       "2",
+		"X-Cal-Events-Total": "3",
+		"X-Cal-Truncated":    "true",
+	} {
+		if got := w.Header().Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+	if w.Header().Get("X-Cal-Window-Start") == "" || w.Header().Get("X-Cal-Window-End") == "" {
+		t.Error("missing window headers")
+	}
+}
+
+func TestSubscribe_CategoriesWithLimit(t *testing.T) {
+	path := t.TempDir() + "/test.db"
+	db, err := database.Open(path)
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	h := New(db, WithHorizon(Horizon{Past: 30 * 24 * time.Hour, Future: 30 * 24 * time.Hour, MaxEvents: 2}))
+	r := testRouter(h)
+
+	feed := createTestFeed(t, r, `{"name":"Mixed"}`)
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, ev := range []struct {
+		summary, categories string
+		start               time.Time
+	}{
+		{"Yesterday", "home", now.AddDate(0, 0, -1)},
+		{"Tomorrow", "home", now.AddDate(0, 0, 1)},
+		{"Last week", "Work, travel", now.AddDate(0, 0, -7)},
+		{"Next week", "work", now.AddDate(0, 0, 7)},
+		{"Later", "work", now.AddDate(0, 0, 20)},
+		{"Workshop", "workshop", now.AddDate(0, 0, 2)},
+	} {
+		createTestEvent(t, r, map[string]interface{}{
+			"feed_id":    feed.ID,
+			"summary":    ev.summary,
+			"categories": ev.categories,
+			"start":      ev.start.Format(time.RFC3339),
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/"+feed.Token+".ics?categories=WORK", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	body := w.Body.String()
+
+	// The limit applies after filtering: the two work events nearest now,
+	// even though unrelated events are nearer still.
+	for _, want := range []string{"SUMMARY:Last week", "SUMMARY:Next week"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("feed missing %q", want)
+		}
+	}
+	for _, unwanted := range []string{"Yesterday", "Tomorrow", "Later", "Workshop"} {
+		if strings.Contains(body, unwanted) {
+			t.Errorf("feed should not contain %q", unwanted)
+		}
+	}
+	for header, want := range map[string]string{
+		"X-Cal-Events":       "2",
+		"X-Cal-Events-Total": "3",
+		"X-Cal-Truncated":    "true",
+	} {
+		if got := w.Header().Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestListEvents_LimitWithoutCap(t *testing.T) {
+	path := t.TempDir() + "/test.db"
+	db, err := database.Open(path)
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	r := testRouter(New(db, WithHorizon(Horizon{Past: time.Hour, Future: time.Hour})))
+	feed := createTestFeed(t, r, `{"name":"Uncapped"}`)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/feeds/"+feed.ID+"/events?limit=0", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "positive integer") {
+		t.Errorf("limit=0: got %d %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/feeds/"+feed.ID+"/events?limit=100000", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("large limit without a cap: expected 200, got %d", w.Code)
+	}
+}
+
+func TestListEvents_Pagination(t *testing.T) {
+	h := testHandler(t)
+	r := testRouter(h)
+	feed := createTestFeed(t, r, `{"name":"Paged"}`)
+	for i := 1; i <= 5; i++ {
+		createTestEvent(t, r, map[string]interface{}{
+			"feed_id": feed.ID,
+			"summary": "Event",
+			"start":   time.Date(2026, 3, i, 9, 0, 0, 0, time.UTC).Format(time.RFC3339),
+		})
+	}
+
+	get := func(query string) ([]database.Event, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodGet, "/api/feeds/"+feed.ID+"/events"+query, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var events []database.Event
+		json.Unmarshal(w.Body.Bytes(), &events)
+		return events, w
+	}
+
+	events, w := get("?limit=2&offset=2")
+	if len(events) != 2 || events[0].Start.Day() != 3 || w.Header().Get("X-Total-Count") != "5" {
+		t.Fatalf("page: got %d events, total %q", len(events), w.Header().Get("X-Total-Count"))
+	}
+
+	events, w = get("?from=2026-03-04&to=2026-03-06")
+	if len(events) != 2 || w.Header().Get("X-Total-Count") != "2" {
+		t.Fatalf("range: got %d events, total %q", len(events), w.Header().Get("X-Total-Count"))
+	}
+
+	for _, bad := range []string{"?limit=0", "?limit=999999", "?offset=-1", "?from=yesterday"} {
+		if _, w := get(bad); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", bad, w.Code)
+		}
+	}
+}
+
+func TestCreateEvent_DedupeKey(t *testing.T) {
+	h := testHandler(t)
+	r := testRouter(h)
+	feed := createTestFeed(t, r, `{"name":"Retries"}`)
+	other := createTestFeed(t, r, `{"name":"Other"}`)
+
+	post := func(feedID, summary string) (*httptest.ResponseRecorder, database.Event) {
+		body, _ := json.Marshal(map[string]interface{}{
+			"feed_id":    feedID,
+			"summary":    summary,
+			"start":      "2026-05-01T15:00:00Z",
+			"dedupe_key": "order-42",
+		})
+		req := httptest.NewRequest(http.MethodPost, "/api/events", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var event database.Event
+		json.Unmarshal(w.Body.Bytes(), &event)
+		return w, event
+	}
+
+	w, first := post(feed.ID, "Delivery")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("first create: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// A retry returns the original event untouched.
+	w, again := post(feed.ID, "Delivery (retry)")
+	if w.Code != http.StatusOK || again.ID != first.ID || again.Summary != "Delivery" {
+		t.Fatalf("retry: got %d, event %s %q", w.Code, again.ID, again.Summary)
+	}
+
+	// Keys are scoped to a feed.
+	if w, _ := post(other.ID, "Delivery"); w.Code != http.StatusCreated {
+		t.Fatalf("other feed: expected 201, got %d", w.Code)
+	}
+
+	events, _, err := h.db.QueryEvents(feed.ID, database.EventQuery{})
+	if err != nil || len(events) != 1 {
+		t.Fatalf("expected 1 event in feed, got %d (%v)", len(events), err)
+	}
+}
@@ -0,0 +1,139 @@
+package ical
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jredh-dev/nexus/services/cal/internal/validator"
+)
+
+// textPieces are fragments that have broken generators before: RFC 5545
+// specials, multi-byte runes near fold points, and control characters.
+var textPieces = []string{
+	"meeting", " ", "Zoë", "日本語", "🗓", ";", ",", `\`, ":", `"`, "^",
+	"\n", "\r\n", "\r", "\t", "\x00", "\x1b", "\x7f", "é", "a", "ÅÅÅÅ",
+}
+
+func randText(r *rand.Rand, maxPieces int) string {
+	var b strings.Builder
+	for n := r.Intn(maxPieces + 1); n > 0; n-- {
+		p := textPieces[r.Intn(len(textPieces))]
+		if r.Intn(4) == 0 {
+			p = strings.Repeat(p, 1+r.Intn(40))
+		}
+		b.WriteString(p)
+	}
+	return b.String()
+}
+
+func randEvent(r *rand.Rand, i int) Event {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	start := base.Add(time.Duration(r.Intn(365*24*60)) * time.Minute)
+	e := Event{
+		UID:         fmt.Sprintf("evt-%d-%d@nexus-cal", i, r.Int()),
+		Summary:     randText(r, 6),
+		Description: randText(r, 20),
+		Location:    randText(r, 4),
+		Categories:  randText(r, 6),
+		Start:       start,
+		AllDay:      r.Intn(3) == 0,
+		Status:      []string{"", "TENTATIVE", "CONFIRMED", "CANCELLED"}[r.Intn(4)],
+		Created:     start.Add(-time.Hour),
+		Updated:     start.Add(-time.Minute),
+	}
+	if e.AllDay {
+		e.Start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	if r.Intn(2) == 0 {
+		end := e.Start.Add(time.Duration(1+r.Intn(72*60)) * time.Minute)
+		if e.AllDay {
+			end = e.Start.AddDate(0, 0, 1+r.Intn(5))
+		}
+		e.End = &end
+	}
+	if r.Intn(3) == 0 {
+		d := e.Start.Add(-time.Hour)
+		e.Deadline = &d
+	}
+	if r.Intn(2) == 0 {
+		e.URL = "https://example.com/e/" + strings.Repeat("x", r.Intn(100))
+	}
+	if r.Intn(2) == 0 {
+		e.Organizer = "organizer@example.com"
+		e.OrganizerCN = randText(r, 4)
+	}
+	for n := r.Intn(4); n > 0; n-- {
+		e.Attendees = append(e.Attendees, Attendee{
+			Email:    "guest@example.com",
+			Name:     randText(r, 4),
+			PartStat: []string{"", "ACCEPTED", "DECLINED", "TENTATIVE"}[r.Intn(4)],
+			RSVP:     r.Intn(2) == 0,
+		})
+	}
+	for n := r.Intn(3); n > 0; n-- {
+		a := Attachment{FmtType: []string{"", "application/pdf", "text/plain"}[r.Intn(3)]}
+		if r.Intn(2) == 0 {
+			a.URI = "https://example.com/doc.pdf"
+		} else {
+			a.Data = []byte(randText(r, 30))
+			a.Filename = randText(r, 3)
+		}
+		e.Attachments = append(e.Attachments, a)
+	}
+	return e
+}
+
+func assertConformant(t *testing.T, out string) {
+	t.Helper()
+	for _, err := range validator.Validate(out) {
+		t.Error(err)
+	}
+	if t.Failed() {
+		t.Logf("document:\n%s", out)
+	}
+}
+
+// TestGenerate_Conformance runs randomized feeds through the RFC 5545
+// validator. The seed is fixed so failures reproduce.
+func TestGenerate_Conformance(t *testing.T) {
+	r := rand.New(rand.NewSource(5545))
+	for i := 0; i < 300; i++ {
+		feed := Feed{Name: randText(r, 5), Description: randText(r, 8)}
+		if r.Intn(2) == 0 {
+			feed.TTL = time.Duration(1+r.Intn(48*60)) * time.Minute
+		}
+		var events []Event
+		for n := r.Intn(5); n > 0; n-- {
+			events = append(events, randEvent(r, i))
+		}
+		assertConformant(t, Generate(feed, events))
+		if t.Failed() {
+			t.Fatalf("iteration %d produced a non-conformant document", i)
+		}
+	}
+}
+
+func TestGenerateFreeBusy_Conformance(t *testing.T) {
+	r := rand.New(rand.NewSource(5546))
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	for i := 0; i < 100; i++ {
+		var events []Event
+		for n := r.Intn(10); n > 0; n-- {
+			events = append(events, randEvent(r, i))
+		}
+		assertConformant(t, GenerateFreeBusy(FreeBusy{
+			UID:     "fb@nexus-cal",
+			Start:   from,
+			End:     to,
+			Stamp:   from,
+			Periods: BusyPeriods(events, from, to),
+		}))
+		if t.Failed() {
+			t.Fatalf("iteration %d produced a non-conformant document", i)
+		}
+	}
+}
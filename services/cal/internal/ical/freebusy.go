@@ -0,0 +1,156 @@
+package ical
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Free/busy types (RFC 5545 section 3.2.9).
+const (
+	FBTypeBusy          = "BUSY"
+	FBTypeBusyTentative = "BUSY-TENTATIVE"
+)
+
+// Period is a busy interval within a free/busy window.
+type Period struct {
+	Start time.Time
+	End   time.Time
+	Type  string // BUSY or BUSY-TENTATIVE
+}
+
+// FreeBusy holds the data needed to render a VFREEBUSY component.
+type FreeBusy struct {
+	UID     string
+	Start   time.Time // window start (DTSTART)
+	End     time.Time // window end (DTEND)
+	Stamp   time.Time // DTSTAMP
+	Periods []Period
+}
+
+// BusyPeriods computes the busy intervals that events occupy within
+// [from, to). Periods are clipped to the window, and overlapping or adjacent
+// periods of the same type are merged. A BUSY period absorbs any
+// BUSY-TENTATIVE time it overlaps.
+//
+// Cancelled events are free time. Timed events without an end occupy no
+// time; all-day events without an end occupy their whole day.
+func BusyPeriods(events []Event, from, to time.Time) []Period {
+	var busy, tentative []Period
+	for _, e := range events {
+		if strings.EqualFold(e.Status, "CANCELLED") {
+			continue
+		}
+		start, end, ok := eventSpan(e)
+		if !ok || !start.Before(to) || !end.After(from) {
+			continue
+		}
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		p := Period{Start: start.UTC(), End: end.UTC()}
+		if strings.EqualFold(e.Status, "TENTATIVE") {
+			p.Type = FBTypeBusyTentative
+			tentative = append(tentative, p)
+		} else {
+			p.Type = FBTypeBusy
+			busy = append(busy, p)
+		}
+	}
+
+	busy = mergePeriods(busy)
+	var out []Period
+	for _, t := range mergePeriods(tentative) {
+		out = append(out, subtractPeriods(t, busy)...)
+	}
+	out = append(out, busy...)
+	sort.Slice(out, func(i, j int) bool { return out[i].Start.Before(out[j].Start) })
+	return out
+}
+
+// eventSpan returns the interval an event occupies.
+func eventSpan(e Event) (time.Time, time.Time, bool) {
+	if e.AllDay {
+		start := time.Date(e.Start.Year(), e.Start.Month(), e.Start.Day(), 0, 0, 0, 0, time.UTC)
+		if e.End == nil {
+			return start, start.AddDate(0, 0, 1), true
+		}
+		end := time.Date(e.End.Year(), e.End.Month(), e.End.Day(), 0, 0, 0, 0, time.UTC)
+		return start, end, end.After(start)
+	}
+	if e.End == nil || !e.End.After(e.Start) {
+		return time.Time{}, time.Time{}, false
+	}
+	return e.Start, *e.End, true
+}
+
+// mergePeriods sorts periods and coalesces overlapping or touching ones.
+func mergePeriods(ps []Period) []Period {
+	if len(ps) == 0 {
+		return nil
+	}
+	sort.Slice(ps, func(i, j int) bool { return ps[i].Start.Before(ps[j].Start) })
+	out := []Period{ps[0]}
+	for _, p := range ps[1:] {
+		last := &out[len(out)-1]
+		if !p.Start.After(last.End) {
+			if p.End.After(last.End) {
+				last.End = p.End
+			}
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// subtractPeriods removes the sorted, merged busy intervals from p.
+func subtractPeriods(p Period, busy []Period) []Period {
+	var out []Period
+	cur := p
+	for _, b := range busy {
+		if !b.End.After(cur.Start) {
+			continue
+		}
+		if !b.Start.Before(cur.End) {
+			break
+		}
+		if b.Start.After(cur.Start) {
+			out = append(out, Period{Start: cur.Start, End: b.Start, Type: cur.Type})
+		}
+		if !b.End.Before(cur.End) {
+			return out
+		}
+		cur.Start = b.End
+	}
+	return append(out, cur)
+}
+
+// GenerateFreeBusy produces an iCalendar document containing a single
+// VFREEBUSY component. It reveals only when time is taken, never what it is
+// taken by.
+func GenerateFreeBusy(fb FreeBusy) string {
+	var b strings.Builder
+
+	b.WriteString("BEGIN:VCALENDAR\r\n")
+	b.WriteString("VERSION:2.0\r\n")
+	b.WriteString("PRODID:-//jredh-dev//nexus-cal//EN\r\n")
+	writeProp(&b, "METHOD", MethodPublish)
+	b.WriteString("CALSCALE:GREGORIAN\r\n")
+
+	b.WriteString("BEGIN:VFREEBUSY\r\n")
+	writeProp(&b, "UID", fb.UID)
+	writeProp(&b, "DTSTAMP", formatDateTime(fb.Stamp))
+	writeProp(&b, "DTSTART", formatDateTime(fb.Start))
+	writeProp(&b, "DTEND", formatDateTime(fb.End))
+	for _, p := range fb.Periods {
+		writeProp(&b, "FREEBUSY;FBTYPE="+p.Type, formatDateTime(p.Start)+"/"+formatDateTime(p.End))
+	}
+	b.WriteString("END:VFREEBUSY\r\n")
+
+	b.WriteString("END:VCALENDAR\r\n")
+	return b.String()
+}
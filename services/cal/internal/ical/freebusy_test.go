@@ -0,0 +1,84 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBusyPeriods(t *testing.T) {
+	day := func(h, m int) time.Time { return time.Date(2026, 3, 2, h, m, 0, 0, time.UTC) }
+	ptr := func(t time.Time) *time.Time { return &t }
+
+	events := []Event{
+		{Start: day(9, 0), End: ptr(day(10, 0)), Status: "CONFIRMED"},
+		{Start: day(9, 30), End: ptr(day(11, 0))},                       // overlaps the first
+		{Start: day(10, 30), End: ptr(day(12, 0)), Status: "TENTATIVE"}, // partially covered by busy
+		{Start: day(13, 0), End: ptr(day(14, 0)), Status: "CANCELLED"},  // free time
+		{Start: day(15, 0)}, // no end: occupies nothing
+		{Start: day(23, 0), End: ptr(day(23, 0).Add(3 * time.Hour))}, // clipped at window end
+		{Start: day(6, 0).AddDate(0, 0, -1), End: ptr(day(6, 0))},    // clipped at window start
+	}
+
+	from, to := day(0, 0), day(0, 0).AddDate(0, 0, 1)
+	got := BusyPeriods(events, from, to)
+
+	want := []Period{
+		{Start: day(0, 0), End: day(6, 0), Type: FBTypeBusy},
+		{Start: day(9, 0), End: day(11, 0), Type: FBTypeBusy},
+		{Start: day(11, 0), End: day(12, 0), Type: FBTypeBusyTentative},
+		{Start: day(23, 0), End: to, Type: FBTypeBusy},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d periods, got %d: %+v", len(want), len(got), got)
+	}
+	for i := range want {
+		if !got[i].Start.Equal(want[i].Start) || !got[i].End.Equal(want[i].End) || got[i].Type != want[i].Type {
+			t.Errorf("period %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestBusyPeriods_AllDay(t *testing.T) {
+	start := time.Date(2026, 6, 15, 0, 0, 0, 0, time.UTC)
+	events := []Event{{Start: start, AllDay: true}}
+
+	got := BusyPeriods(events, start.AddDate(0, 0, -1), start.AddDate(0, 0, 7))
+	if len(got) != 1 {
+		t.Fatalf("expected 1 period, got %+v", got)
+	}
+	if !got[0].End.Equal(start.AddDate(0, 0, 1)) {
+		t.Errorf("all-day event without end should occupy one day, got %+v", got[0])
+	}
+}
+
+func TestGenerateFreeBusy(t *testing.T) {
+	from := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+	result := GenerateFreeBusy(FreeBusy{
+		UID:   "fb-1@nexus-cal",
+		Start: from,
+		End:   to,
+		Stamp: from,
+		Periods: []Period{
+			{Start: from.Add(9 * time.Hour), End: from.Add(10 * time.Hour), Type: FBTypeBusy},
+		},
+	})
+
+	required := []string{
+		"BEGIN:VFREEBUSY",
+		"UID:fb-1@nexus-cal",
+		"DTSTART:20260302T000000Z",
+		"DTEND:20260303T000000Z",
+		"FREEBUSY;FBTYPE=BUSY:20260302T090000Z/20260302T100000Z",
+		"END:VFREEBUSY",
+	}
+	for _, s := range required {
+		if !strings.Contains(result, s) {
+			t.Errorf("output missing %q", s)
+		}
+	}
+	if strings.Contains(result, "VEVENT") {
+		t.Error("free/busy output must not contain events")
+	}
+}
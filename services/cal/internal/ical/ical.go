@@ -2,9 +2,11 @@
 package ical
 
 import (
+	"encoding/base64"
 	"fmt"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 // Event holds the data needed to render a VEVENT component.
@@ -20,17 +22,45 @@ type Event struct {
 	Deadline    *time.Time
 	Status      string // TENTATIVE, CONFIRMED, CANCELLED
 	Categories  string // comma-separated
+	Organizer   string // organizer email address
+	OrganizerCN string // organizer display name
+	Attendees   []Attendee
+	Attachments []Attachment
 	Created     time.Time
 	Updated     time.Time
 }
 
+// Attendee is rendered as an ATTENDEE property.
+type Attendee struct {
+	Email    string
+	Name     string
+	PartStat string // NEEDS-ACTION, ACCEPTED, DECLINED, TENTATIVE, DELEGATED
+	RSVP     bool   // ask the attendee to reply (used for invitations)
+}
+
+// Attachment is rendered as an ATTACH property: a URI reference when URI is
+// set, otherwise the inline Data encoded as BASE64.
+type Attachment struct {
+	URI      string
+	Data     []byte
+	FmtType  string // media type, e.g. application/pdf; optional
+	Filename string // suggested file name for inline data; optional
+}
+
 // Feed holds metadata for the VCALENDAR wrapper.
 type Feed struct {
 	Name        string
 	Description string
 	TTL         time.Duration // suggested refresh interval
+	Method      string        // iTIP method; defaults to PUBLISH
 }
 
+// Methods (RFC 5546) used on the VCALENDAR object.
+const (
+	MethodPublish = "PUBLISH"
+	MethodRequest = "REQUEST"
+)
+
 // Generate produces a complete iCalendar document from a feed and its events.
 func Generate(feed Feed, events []Event) string {
 	var b strings.Builder
@@ -38,14 +68,18 @@ func Generate(feed Feed, events []Event) string {
 	b.WriteString("BEGIN:VCALENDAR\r\n")
 	b.WriteString("VERSION:2.0\r\n")
 	b.WriteString("PRODID:-//jredh-dev//nexus-cal//EN\r\n")
-	b.WriteString("METHOD:PUBLISH\r\n")
+	method := feed.Method
+	if method == "" {
+		method = MethodPublish
+	}
+	writeProp(&b, "METHOD", method)
 	b.WriteString("CALSCALE:GREGORIAN\r\n")
 
-	writeProp(&b, "NAME", feed.Name)
-	writeProp(&b, "X-WR-CALNAME", feed.Name)
+	writeProp(&b, "NAME", escapeText(feed.Name))
+	writeProp(&b, "X-WR-CALNAME", escapeText(feed.Name))
 	if feed.Description != "" {
-		writeProp(&b, "DESCRIPTION", feed.Description)
-		writeProp(&b, "X-WR-CALDESC", feed.Description)
+		writeProp(&b, "DESCRIPTION", escapeText(feed.Description))
+		writeProp(&b, "X-WR-CALDESC", escapeText(feed.Description))
 	}
 
 	if feed.TTL > 0 {
@@ -94,7 +128,28 @@ func writeEvent(b *strings.Builder, e Event) {
 		writeProp(b, "STATUS", e.Status)
 	}
 	if e.Categories != "" {
-		writeProp(b, "CATEGORIES", e.Categories)
+		if cats := escapeList(e.Categories); cats != "" {
+			writeProp(b, "CATEGORIES", cats)
+		}
+	}
+
+	if e.Organizer != "" {
+		writeProp(b, "ORGANIZER"+cnParam(e.OrganizerCN), "mailto:"+e.Organizer)
+	}
+	for _, a := range e.Attendees {
+		name := "ATTENDEE" + cnParam(a.Name)
+		partstat := a.PartStat
+		if partstat == "" {
+			partstat = "NEEDS-ACTION"
+		}
+		name += ";PARTSTAT=" + partstat
+		if a.RSVP {
+			name += ";RSVP=TRUE"
+		}
+		writeProp(b, name, "mailto:"+a.Email)
+	}
+	for _, a := range e.Attachments {
+		writeAttach(b, a)
 	}
 
 	writeProp(b, "CREATED", formatDateTime(e.Created))
@@ -112,18 +167,93 @@ func writeEvent(b *strings.Builder, e Event) {
 	b.WriteString("END:VEVENT\r\n")
 }
 
+// writeAttach writes an ATTACH property (RFC 5545 section 3.8.1.1). Inline
+// data uses ENCODING=BASE64;VALUE=BINARY; X-FILENAME carries the file name,
+// which Apple and Microsoft clients display.
+func writeAttach(b *strings.Builder, a Attachment) {
+	name := "ATTACH"
+	if a.FmtType != "" {
+		name += ";FMTTYPE=" + paramValue(a.FmtType)
+	}
+	if a.URI != "" {
+		writeProp(b, name, a.URI)
+		return
+	}
+	name += ";ENCODING=BASE64;VALUE=BINARY"
+	if a.Filename != "" {
+		name += ";X-FILENAME=" + paramValue(a.Filename)
+	}
+	writeProp(b, name, base64.StdEncoding.EncodeToString(a.Data))
+}
+
+// maxLineOctets is the RFC 5545 section 3.1 limit on content line length,
+// excluding the CRLF line break.
+const maxLineOctets = 75
+
+// writeProp writes a content line, folding it so no physical line exceeds
+// 75 octets. Folds never split a multibyte UTF-8 sequence, and the leading
+// space of each continuation line counts toward its 75 octets.
 func writeProp(b *strings.Builder, name, value string) {
 	line := name + ":" + value
-	// RFC 5545: lines MUST be <= 75 octets. Fold long lines.
-	for len(line) > 75 {
-		b.WriteString(line[:75])
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		if cut == 0 {
+			cut = limit // not valid UTF-8; fall back to an octet split
+		}
+		b.WriteString(line[:cut])
 		b.WriteString("\r\n ")
-		line = line[75:]
+		line = line[cut:]
+		limit = maxLineOctets - 1
 	}
 	b.WriteString(line)
 	b.WriteString("\r\n")
 }
 
+// cnParam returns a ";CN=..." parameter for name, or "" if name is empty.
+func cnParam(name string) string {
+	if name == "" {
+		return ""
+	}
+	return ";CN=" + paramValue(name)
+}
+
+// paramValue encodes a property parameter value. Characters that cannot
+// appear in a parameter value are caret-encoded per RFC 6868 (^^, ^n, ^'),
+// other control characters are dropped, and the value is quoted when it
+// contains characters only allowed inside a quoted-string (RFC 5545
+// section 3.1).
+func paramValue(v string) string {
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		switch c := v[i]; {
+		case c == '^':
+			b.WriteString("^^")
+		case c == '"':
+			b.WriteString("^'")
+		case c == '\r':
+			if i+1 < len(v) && v[i+1] == '\n' {
+				i++
+			}
+			b.WriteString("^n")
+		case c == '\n':
+			b.WriteString("^n")
+		case c < 0x20 && c != '\t', c == 0x7f:
+			// CONTROL characters are not allowed in parameter values.
+		default:
+			b.WriteByte(c)
+		}
+	}
+	out := b.String()
+	if strings.ContainsAny(out, ":;,") {
+		return `"` + out + `"`
+	}
+	return out
+}
+
 func formatDateTime(t time.Time) string {
 	return t.UTC().Format("20060102T150405Z")
 }
@@ -150,10 +280,34 @@ func formatDuration(d time.Duration) string {
 }
 
 // escapeText escapes special characters per RFC 5545 section 3.3.11.
+// CRLF and bare CR are normalized to an escaped newline, and other control
+// characters, which TEXT cannot carry, are dropped.
 func escapeText(s string) string {
 	s = strings.ReplaceAll(s, `\`, `\\`)
 	s = strings.ReplaceAll(s, ";", `\;`)
 	s = strings.ReplaceAll(s, ",", `\,`)
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
 	s = strings.ReplaceAll(s, "\n", `\n`)
-	return s
+	return strings.Map(func(r rune) rune {
+		if (r < 0x20 && r != '\t') || r == 0x7f {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// escapeList escapes each element of a comma-separated list as TEXT while
+// keeping the commas as value separators (used for CATEGORIES).
+func escapeList(s string) string {
+	parts := strings.Split(s, ",")
+	out := parts[:0]
+	for _, p := range parts {
+		// Escape before the emptiness check: a part made only of control
+		// characters escapes to nothing.
+		if p = escapeText(strings.TrimSpace(p)); p != "" {
+			out = append(out, p)
+		}
+	}
+	return strings.Join(out, ",")
 }
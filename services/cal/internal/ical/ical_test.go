@@ -4,6 +4,7 @@ import (
 	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 )
 
 func TestGenerate_BasicFeed(t *testing.T) {
@@ -156,6 +157,7 @@ func TestEscapeText(t *testing.T) {
 		{"com,ma", `com\,ma`},
 		{"new\nline", `new\nline`},
 		{`back\slash`, `back\\slash`},
+		{"bell\x07\ttab\x7f", "bell\ttab"},
 	}
 	for _, tt := range tests {
 		got := escapeText(tt.input)
@@ -183,3 +185,180 @@ func TestFormatDuration(t *testing.T) {
 		}
 	}
 }
+
+func TestGenerate_OrganizerAndAttendees(t *testing.T) {
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	events := []Event{
+		{
+			UID:         "meet-1@nexus-cal",
+			Summary:     "Planning",
+			Start:       start,
+			Organizer:   "boss@example.com",
+			OrganizerCN: "Boss, The",
+			Attendees: []Attendee{
+				{Email: "a@example.com", Name: "Ann", PartStat: "ACCEPTED"},
+				{Email: "b@example.com", RSVP: true},
+			},
+			Created: start,
+			Updated: start,
+		},
+	}
+
+	result := Generate(Feed{Name: "Test", Method: MethodRequest}, events)
+
+	required := []string{
+		"METHOD:REQUEST",
+		`ORGANIZER;CN="Boss, The":mailto:boss@example.com`,
+		"ATTENDEE;CN=Ann;PARTSTAT=ACCEPTED:mailto:a@example.com",
+		"ATTENDEE;PARTSTAT=NEEDS-ACTION;RSVP=TRUE:mailto:b@example.com",
+	}
+	for _, s := range required {
+		if !strings.Contains(result, s) {
+			t.Errorf("output missing %q", s)
+		}
+	}
+	if strings.Contains(result, "METHOD:PUBLISH") {
+		t.Error("explicit method should replace PUBLISH")
+	}
+}
+
+func TestGenerate_Attachments(t *testing.T) {
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	events := []Event{
+		{
+			UID:     "agenda-1@nexus-cal",
+			Summary: "Review",
+			Start:   start,
+			Attachments: []Attachment{
+				{URI: "https://example.com/agenda.pdf", FmtType: "application/pdf"},
+				{Data: []byte("1. budget\n2. roadmap\n"), FmtType: "text/plain", Filename: "agenda; v2.txt"},
+			},
+			Created: start,
+			Updated: start,
+		},
+	}
+
+	// Unfold so long base64 values can be matched whole.
+	result := strings.ReplaceAll(Generate(Feed{Name: "Test"}, events), "\r\n ", "")
+
+	required := []string{
+		"ATTACH;FMTTYPE=application/pdf:https://example.com/agenda.pdf",
+		`ATTACH;FMTTYPE=text/plain;ENCODING=BASE64;VALUE=BINARY;X-FILENAME="agenda; v2.txt":MS4gYnVkZ2V0CjIuIHJvYWRtYXAK`,
+	}
+	for _, s := range required {
+		if !strings.Contains(result, s) {
+			t.Errorf("output missing %q", s)
+		}
+	}
+}
+
+func TestWriteProp_Folding(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"ascii", strings.Repeat("a", 200)},
+		{"two-byte runes", strings.Repeat("é", 100)},
+		{"three-byte runes", strings.Repeat("日本語", 40)},
+		{"four-byte runes", strings.Repeat("🗓", 60)},
+		{"mixed", "x" + strings.Repeat("ü🗓a", 50)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			writeProp(&b, "DESCRIPTION", tt.value)
+			checkFolding(t, b.String(), "DESCRIPTION:"+tt.value)
+		})
+	}
+}
+
+func TestParamValue(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"Ann", "Ann"},
+		{"Boss, The", `"Boss, The"`},
+		{"a:b", `"a:b"`},
+		{`Say "hi"`, `Say ^'hi^'`},
+		{"caret^", "caret^^"},
+		{"two\nlines", "two^nlines"},
+		{"two\r\nlines", "two^nlines"},
+		{"bell\x07", "bell"},
+		{"Zoë", "Zoë"},
+	}
+	for _, tt := range tests {
+		got := paramValue(tt.input)
+		if got != tt.want {
+			t.Errorf("paramValue(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestEscapeText_CarriageReturns(t *testing.T) {
+	if got := escapeText("a\r\nb\rc"); got != `a\nb\nc` {
+		t.Errorf("escapeText should normalize CR/CRLF, got %q", got)
+	}
+}
+
+// FuzzGenerate checks that any text fields produce a document the RFC 5545
+// validator accepts, not just one that folds correctly.
+func FuzzGenerate(f *testing.F) {
+	f.Add("Team Meeting", "Weekly sync", "Zoë Ångström", "work,fun")
+	f.Add(strings.Repeat("日本", 50), "line\r\nbreak; semi, comma", `quote " caret ^`, "")
+	f.Add("🗓"+strings.Repeat("a", 73), strings.Repeat("é", 80), "x:y;z", "a;b,c\\d")
+	f.Add("bell\x07", "nul\x00 esc\x1b[0m", "del\x7f", "tab\there")
+
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	f.Fuzz(func(t *testing.T, summary, description, name, categories string) {
+		for _, s := range []string{summary, description, name, categories} {
+			if !utf8.ValidString(s) {
+				t.Skip("iCalendar content is UTF-8")
+			}
+		}
+		out := Generate(Feed{Name: name}, []Event{{
+			UID:         "fuzz@nexus-cal",
+			Summary:     summary,
+			Description: description,
+			Categories:  categories,
+			Organizer:   "o@example.com",
+			OrganizerCN: name,
+			Attendees:   []Attendee{{Email: "a@example.com", Name: name}},
+			Start:       start,
+			Created:     start,
+			Updated:     start,
+		}})
+		checkFolding(t, out, "")
+		assertConformant(t, out)
+	})
+}
+
+// checkFolding verifies that every physical line of out is at most 75
+// octets, is valid UTF-8, and that unfolding yields CRLF-free logical lines.
+// If want is non-empty, the unfolded output must equal it.
+func checkFolding(t *testing.T, out, want string) {
+	t.Helper()
+	if !strings.HasSuffix(out, "\r\n") {
+		t.Fatalf("output must end with CRLF: %q", out)
+	}
+	physical := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+	for i, line := range physical {
+		if len(line) > maxLineOctets {
+			t.Errorf("line %d is %d octets: %q", i, len(line), line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("line %d splits a multibyte rune: %q", i, line)
+		}
+		if strings.ContainsAny(line, "\r\n") {
+			t.Errorf("line %d contains a bare CR or LF: %q", i, line)
+		}
+		if i > 0 && strings.HasPrefix(line, " ") && len(line) == 1 {
+			t.Errorf("line %d is an empty continuation", i)
+		}
+	}
+	if want != "" {
+		if got := strings.ReplaceAll(strings.TrimSuffix(out, "\r\n"), "\r\n ", ""); got != want {
+			t.Errorf("unfolded = %q, want %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package ical
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// JCalContentType is the media type for jCal documents (RFC 7265).
+const JCalContentType = "application/calendar+json"
+
+// GenerateJCal produces a jCal (RFC 7265) document from a feed and its events.
+// It carries the same properties as Generate, so web frontends can consume
+// feeds without an iCal parser.
+//
+// A jCal component is a three-element array: [name, properties, components].
+// Each property is [name, parameters, value-type, value...].
+func GenerateJCal(feed Feed, events []Event) ([]byte, error) {
+	method := feed.Method
+	if method == "" {
+		method = MethodPublish
+	}
+	props := [][]any{
+		jprop("version", "text", "2.0"),
+		jprop("prodid", "text", "-//jredh-dev//nexus-cal//EN"),
+		jprop("method", "text", method),
+		jprop("calscale", "text", "GREGORIAN"),
+		jprop("name", "text", feed.Name),
+		jprop("x-wr-calname", "unknown", feed.Name),
+	}
+	if feed.Description != "" {
+		props = append(props,
+			jprop("description", "text", feed.Description),
+			jprop("x-wr-caldesc", "unknown", feed.Description),
+		)
+	}
+	if feed.TTL > 0 {
+		dur := formatDuration(feed.TTL)
+		props = append(props,
+			jprop("refresh-interval", "duration", dur),
+			jprop("x-published-ttl", "unknown", dur),
+		)
+	}
+
+	comps := make([]any, 0, len(events))
+	for _, e := range events {
+		comps = append(comps, jcalEvent(e))
+	}
+
+	return json.Marshal([]any{"vcalendar", props, comps})
+}
+
+func jcalEvent(e Event) []any {
+	props := [][]any{
+		jprop("uid", "text", e.UID),
+		jprop("dtstamp", "date-time", jcalDateTime(e.Updated)),
+	}
+
+	if e.AllDay {
+		props = append(props, jprop("dtstart", "date", jcalDate(e.Start)))
+		if e.End != nil {
+			props = append(props, jprop("dtend", "date", jcalDate(*e.End)))
+		}
+	} else {
+		props = append(props, jprop("dtstart", "date-time", jcalDateTime(e.Start)))
+		if e.End != nil {
+			props = append(props, jprop("dtend", "date-time", jcalDateTime(*e.End)))
+		}
+	}
+
+	// jCal text values are not backslash-escaped; JSON encoding covers it.
+	props = append(props, jprop("summary", "text", e.Summary))
+
+	if e.Description != "" {
+		props = append(props, jprop("description", "text", e.Description))
+	}
+	if e.Location != "" {
+		props = append(props, jprop("location", "text", e.Location))
+	}
+	if e.URL != "" {
+		props = append(props, jprop("url", "uri", e.URL))
+	}
+	if e.Status != "" {
+		props = append(props, jprop("status", "text", e.Status))
+	}
+	if e.Categories != "" {
+		// CATEGORIES is multi-valued: each category becomes its own value.
+		var cats []any
+		for _, c := range strings.Split(e.Categories, ",") {
+			if c = strings.TrimSpace(c); c != "" {
+				cats = append(cats, c)
+			}
+		}
+		if len(cats) > 0 {
+			props = append(props, jprop("categories", "text", cats...))
+		}
+	}
+
+	if e.Organizer != "" {
+		params := map[string]string{}
+		if e.OrganizerCN != "" {
+			params["cn"] = e.OrganizerCN
+		}
+		props = append(props, jpropParams("organizer", params, "cal-address", "mailto:"+e.Organizer))
+	}
+	for _, a := range e.Attendees {
+		partstat := a.PartStat
+		if partstat == "" {
+			partstat = "NEEDS-ACTION"
+		}
+		params := map[string]string{"partstat": partstat}
+		if a.Name != "" {
+			params["cn"] = a.Name
+		}
+		if a.RSVP {
+			params["rsvp"] = "TRUE"
+		}
+		props = append(props, jpropParams("attendee", params, "cal-address", "mailto:"+a.Email))
+	}
+	for _, a := range e.Attachments {
+		params := map[string]string{}
+		if a.FmtType != "" {
+			params["fmttype"] = a.FmtType
+		}
+		if a.URI != "" {
+			props = append(props, jpropParams("attach", params, "uri", a.URI))
+			continue
+		}
+		params["encoding"] = "BASE64"
+		if a.Filename != "" {
+			params["x-filename"] = a.Filename
+		}
+		props = append(props, jpropParams("attach", params, "binary", base64.StdEncoding.EncodeToString(a.Data)))
+	}
+
+	props = append(props,
+		jprop("created", "date-time", jcalDateTime(e.Created)),
+		jprop("last-modified", "date-time", jcalDateTime(e.Updated)),
+	)
+
+	comps := []any{}
+	if e.Deadline != nil {
+		comps = append(comps, []any{"valarm", [][]any{
+			jprop("trigger", "duration", "-PT1H"),
+			jprop("action", "text", "DISPLAY"),
+			jprop("description", "text", "Deadline approaching: "+e.Summary),
+		}, []any{}})
+	}
+
+	return []any{"vevent", props, comps}
+}
+
+// jprop builds a jCal property with no parameters.
+func jprop(name, valueType string, values ...any) []any {
+	return jpropParams(name, map[string]string{}, valueType, values...)
+}
+
+// jpropParams builds a jCal property with parameters. Parameter names are
+// lowercase per RFC 7265 section 3.4.1.
+func jpropParams(name string, params map[string]string, valueType string, values ...any) []any {
+	return append([]any{name, params, valueType}, values...)
+}
+
+func jcalDateTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05Z")
+}
+
+func jcalDate(t time.Time) string {
+	return t.Format("2006-01-02")
+}
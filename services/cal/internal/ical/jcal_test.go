@@ -0,0 +1,153 @@
+package ical
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateJCal_Structure(t *testing.T) {
+	feed := Feed{Name: "Test Calendar", TTL: 1 * time.Hour}
+
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	deadline := time.Date(2026, 3, 5, 17, 0, 0, 0, time.UTC)
+
+	events := []Event{
+		{
+			UID:        "event-1@nexus-cal",
+			Summary:    "Team; Meeting",
+			Start:      start,
+			End:        &end,
+			Deadline:   &deadline,
+			Categories: "work, sync",
+			Created:    start,
+			Updated:    start,
+		},
+	}
+
+	out, err := GenerateJCal(feed, events)
+	if err != nil {
+		t.Fatalf("GenerateJCal: %v", err)
+	}
+
+	var doc []json.RawMessage
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(doc) != 3 {
+		t.Fatalf("expected [name, props, comps], got %d elements", len(doc))
+	}
+
+	var name string
+	var props [][]any
+	var comps []json.RawMessage
+	json.Unmarshal(doc[0], &name)
+	json.Unmarshal(doc[1], &props)
+	json.Unmarshal(doc[2], &comps)
+
+	if name != "vcalendar" {
+		t.Errorf("expected vcalendar, got %q", name)
+	}
+	if !hasProp(props, "version", "2.0") {
+		t.Error("missing version property")
+	}
+	if !hasProp(props, "refresh-interval", "PT1H") {
+		t.Error("missing refresh-interval property")
+	}
+	if len(comps) != 1 {
+		t.Fatalf("expected 1 component, got %d", len(comps))
+	}
+
+	var vevent []json.RawMessage
+	json.Unmarshal(comps[0], &vevent)
+	var evProps [][]any
+	var subComps [][]any
+	json.Unmarshal(vevent[1], &evProps)
+	json.Unmarshal(vevent[2], &subComps)
+
+	// Text values are carried verbatim, without iCal backslash escaping.
+	if !hasProp(evProps, "summary", "Team; Meeting") {
+		t.Error("summary should be unescaped in jCal")
+	}
+	if !hasProp(evProps, "dtstart", "2026-03-01T09:00:00Z") {
+		t.Error("dtstart should use jCal date-time format")
+	}
+	if len(subComps) != 1 || subComps[0][0] != "valarm" {
+		t.Errorf("deadline event should carry a valarm, got %v", subComps)
+	}
+
+	for _, p := range evProps {
+		if p[0] == "categories" {
+			if len(p) != 5 || p[3] != "work" || p[4] != "sync" {
+				t.Errorf("categories should be split into values, got %v", p)
+			}
+		}
+	}
+}
+
+func TestGenerateJCal_AllDay(t *testing.T) {
+	start := time.Date(2026, 6, 15, 0, 0, 0, 0, time.UTC)
+	out, err := GenerateJCal(Feed{Name: "Test"}, []Event{
+		{UID: "allday-1@nexus-cal", Summary: "Holiday", Start: start, AllDay: true, Created: start, Updated: start},
+	})
+	if err != nil {
+		t.Fatalf("GenerateJCal: %v", err)
+	}
+
+	want := `["dtstart",{},"date","2026-06-15"]`
+	if !json.Valid(out) {
+		t.Fatal("output is not valid JSON")
+	}
+	if !strings.Contains(string(out), want) {
+		t.Errorf("output missing %s", want)
+	}
+}
+
+func TestGenerateJCal_Attachments(t *testing.T) {
+	start := time.Date(2026, 6, 15, 9, 0, 0, 0, time.UTC)
+	out, err := GenerateJCal(Feed{Name: "Test"}, []Event{{
+		UID: "a-1@nexus-cal", Summary: "Review", Start: start, Created: start, Updated: start,
+		Attachments: []Attachment{
+			{URI: "https://example.com/a.pdf"},
+			{Data: []byte("hi"), FmtType: "text/plain"},
+		},
+	}})
+	if err != nil {
+		t.Fatalf("GenerateJCal: %v", err)
+	}
+
+	for _, want := range []string{
+		`["attach",{},"uri","https://example.com/a.pdf"]`,
+		`["attach",{"encoding":"BASE64","fmttype":"text/plain"},"binary","aGk="]`,
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("output missing %s", want)
+		}
+	}
+}
+
+func TestGenerateJCal_EmptyFeed(t *testing.T) {
+	out, err := GenerateJCal(Feed{Name: "Empty"}, nil)
+	if err != nil {
+		t.Fatalf("GenerateJCal: %v", err)
+	}
+	var doc []any
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	comps, ok := doc[2].([]any)
+	if !ok || len(comps) != 0 {
+		t.Errorf("empty feed should have an empty component list, got %v", doc[2])
+	}
+}
+
+func hasProp(props [][]any, name, value string) bool {
+	for _, p := range props {
+		if len(p) >= 4 && p[0] == name && p[3] == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+// Package mailer delivers calendar invitations by email.
+//
+// The Mailer interface keeps handlers independent of the transport: the SMTP
+// implementation uses only net/smtp, and tests substitute a recorder. In local
+// development, point CAL_SMTP_HOST/CAL_SMTP_PORT at Mailpit (localhost:1025).
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/smtp"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Invitation is an iTIP (RFC 5546) message for one or more recipients.
+type Invitation struct {
+	To      []string
+	Subject string
+	Body    string // plain-text part
+	ICS     []byte // text/calendar part, METHOD:REQUEST
+}
+
+// Mailer sends calendar invitations.
+type Mailer interface {
+	SendInvitation(inv Invitation) error
+}
+
+// SMTP sends invitations through an SMTP relay.
+type SMTP struct {
+	host string
+	port string
+	from string
+}
+
+// NewSMTP creates an SMTP mailer. Authentication is deliberately omitted —
+// Mailpit and most internal relays don't require it.
+func NewSMTP(host, port, from string) *SMTP {
+	return &SMTP{host: host, port: port, from: from}
+}
+
+// SendInvitation delivers inv as a multipart/alternative message with a
+// plain-text part and a text/calendar part, which mail clients render as an
+// accept/decline invitation.
+func (m *SMTP) SendInvitation(inv Invitation) error {
+	if len(inv.To) == 0 {
+		return nil
+	}
+	addr := fmt.Sprintf("%s:%s", m.host, m.port)
+	if err := smtp.SendMail(addr, nil, m.from, inv.To, buildMessage(m.from, inv)); err != nil {
+		return fmt.Errorf("smtp send to %s: %w", strings.Join(inv.To, ", "), err)
+	}
+	return nil
+}
+
+// buildMessage renders the raw RFC 5322 message for an invitation.
+func buildMessage(from string, inv Invitation) []byte {
+	boundary := "nexus-cal-" + uuid.New().String()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", headerValue(from))
+	fmt.Fprintf(&b, "To: %s\r\n", headerValue(strings.Join(inv.To, ", ")))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", headerValue(inv.Subject)))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&b, "Content-Type: multipart/alternative; boundary=%q\r\n", boundary)
+	b.WriteString("\r\n")
+
+	fmt.Fprintf(&b, "--%s\r\n", boundary)
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(inv.Body)
+	b.WriteString("\r\n")
+
+	fmt.Fprintf(&b, "--%s\r\n", boundary)
+	b.WriteString("Content-Type: text/calendar; charset=UTF-8; method=REQUEST\r\n\r\n")
+	b.Write(inv.ICS)
+	b.WriteString("\r\n")
+
+	fmt.Fprintf(&b, "--%s--\r\n", boundary)
+	return []byte(b.String())
+}
+
+// headerValue makes s safe to write as a single header value. Event and
+// attendee data end up in headers, so a CR or LF must never start a new
+// header line.
+func headerValue(s string) string {
+	return strings.Join(strings.FieldsFunc(s, func(r rune) bool { return r == '\r' || r == '\n' }), " ")
+}
+
+// ErrQueueFull is returned by Queue.SendInvitation when the backlog is full.
+var ErrQueueFull = errors.New("mailer: invitation queue is full")
+
+// Queue sends invitations in the background so a slow relay never holds up
+// the request that triggered them. Invitations still queued at shutdown are
+// sent before Run returns.
+type Queue struct {
+	next Mailer
+	ch   chan Invitation
+}
+
+// NewQueue creates a Queue that buffers up to size invitations for next.
+func NewQueue(next Mailer, size int) *Queue {
+	return &Queue{next: next, ch: make(chan Invitation, size)}
+}
+
+// SendInvitation enqueues inv without waiting for delivery.
+func (q *Queue) SendInvitation(inv Invitation) error {
+	select {
+	case q.ch <- inv:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Run delivers queued invitations until ctx is cancelled, then delivers
+// whatever is still queued and returns. Delivery failures are logged; the
+// calendar event already exists, so there is no caller to report them to.
+func (q *Queue) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			q.flush()
+			return
+		case inv := <-q.ch:
+			q.send(inv)
+		}
+	}
+}
+
+// flush sends the invitations queued so far.
+func (q *Queue) flush() {
+	for {
+		select {
+		case inv := <-q.ch:
+			q.send(inv)
+		default:
+			return
+		}
+	}
+}
+
+func (q *Queue) send(inv Invitation) {
+	if err := q.next.SendInvitation(inv); err != nil {
+		slog.Error("send invitation", "err", err)
+	}
+}
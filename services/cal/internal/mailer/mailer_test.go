@@ -0,0 +1,90 @@
+package mailer
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildMessage_HeaderInjection(t *testing.T) {
+	msg := string(buildMessage("cal@example.com", Invitation{
+		To:      []string{"ann@example.com"},
+		Subject: "Invitation: Lunch\r\nBcc: evil@example.com",
+		Body:    "hi",
+	}))
+
+	headers, _, _ := strings.Cut(msg, "\r\n\r\n")
+	for _, line := range strings.Split(headers, "\r\n") {
+		if strings.HasPrefix(strings.ToLower(line), "bcc:") {
+			t.Fatalf("injected header in message:\n%s", headers)
+		}
+	}
+	if !strings.Contains(headers, "Subject: Invitation: Lunch Bcc: evil@example.com\r\n") {
+		t.Errorf("subject not flattened to one line:\n%s", headers)
+	}
+}
+
+func TestBuildMessage_EncodesSubject(t *testing.T) {
+	msg := string(buildMessage("cal@example.com", Invitation{
+		To:      []string{"ann@example.com"},
+		Subject: "Invitation: Café",
+	}))
+	if !strings.Contains(msg, "Subject: =?utf-8?q?Invitation:_Caf=C3=A9?=\r\n") {
+		t.Errorf("subject not RFC 2047 encoded:\n%s", msg)
+	}
+}
+
+// blockingMailer stands in for a relay that hangs until released.
+type blockingMailer struct {
+	release chan struct{}
+	sent    chan Invitation
+}
+
+func (m *blockingMailer) SendInvitation(inv Invitation) error {
+	<-m.release
+	m.sent <- inv
+	return nil
+}
+
+func TestQueue_DoesNotBlockSender(t *testing.T) {
+	next := &blockingMailer{release: make(chan struct{}), sent: make(chan Invitation, 1)}
+	q := NewQueue(next, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go q.Run(ctx)
+
+	// The relay is stuck, yet enqueueing returns immediately.
+	if err := q.SendInvitation(Invitation{Subject: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	close(next.release)
+
+	select {
+	case inv := <-next.sent:
+		if inv.Subject != "a" {
+			t.Errorf("sent %q, want a", inv.Subject)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("queued invitation was never sent")
+	}
+}
+
+func TestQueue_FlushesOnStop(t *testing.T) {
+	next := &blockingMailer{release: make(chan struct{}), sent: make(chan Invitation, 3)}
+	close(next.release)
+	q := NewQueue(next, 3)
+	for _, s := range []string{"a", "b", "c"} {
+		if err := q.SendInvitation(Invitation{Subject: s}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Stopped before it started, Run still sends what was queued.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	q.Run(ctx)
+	if len(next.sent) != 3 {
+		t.Errorf("sent %d invitations at shutdown, want 3", len(next.sent))
+	}
+}
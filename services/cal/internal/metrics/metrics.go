@@ -0,0 +1,109 @@
+// Package metrics exposes Prometheus metrics for the calendar service: the
+// common request and database metrics of internal/metrics, plus feed
+// fetches and event counts.
+package metrics
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/jredh-dev/nexus/internal/metrics"
+	"github.com/jredh-dev/nexus/services/cal/internal/database"
+)
+
+const namespace = "cal"
+
+// Metrics holds the service's collectors on top of the common ones.
+// A nil *Metrics is valid and records nothing, so callers need not check
+// whether metrics are enabled.
+type Metrics struct {
+	common *metrics.Metrics
+
+	feedFetches  *prometheus.CounterVec
+	eventsServed *prometheus.CounterVec
+}
+
+// New creates the service metrics and hooks them into db: the stored event
+// count is read from db at scrape time, and db reports the duration of its
+// operations.
+func New(db *database.DB) *Metrics {
+	m := &Metrics{
+		common: metrics.New(namespace),
+		feedFetches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "feed_fetches_total",
+			Help:      "Subscription fetches served, by format (ics, json, freebusy).",
+		}, []string{"format"}),
+		eventsServed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "feed_events_served_total",
+			Help:      "Events included in subscription responses, by format.",
+		}, []string{"format"}),
+	}
+
+	events := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "events",
+		Help:      "Events stored across all feeds.",
+	}, func() float64 {
+		n, err := db.CountEvents()
+		if err != nil {
+			slog.Error("count events for metrics", "err", err)
+			return 0
+		}
+		return float64(n)
+	})
+	feeds := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "feeds",
+		Help:      "Feeds stored across all owners.",
+	}, func() float64 {
+		n, err := db.CountFeeds()
+		if err != nil {
+			slog.Error("count feeds for metrics", "err", err)
+			return 0
+		}
+		return float64(n)
+	})
+
+	m.common.MustRegister(m.feedFetches, m.eventsServed, events, feeds)
+	db.SetObserver(m.ObserveDB)
+	return m
+}
+
+// Common returns the common metrics, for httpserver.WithMetrics.
+func (m *Metrics) Common() *metrics.Metrics {
+	if m == nil {
+		return nil
+	}
+	return m.common
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return m.common.Handler()
+}
+
+// Middleware records the latency of every request; see
+// metrics.Metrics.Middleware.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return m.Common().Middleware(next)
+}
+
+// FeedFetched records a subscription response of the given format carrying
+// events events.
+func (m *Metrics) FeedFetched(format string, events int) {
+	if m == nil {
+		return
+	}
+	m.feedFetches.WithLabelValues(format).Inc()
+	m.eventsServed.WithLabelValues(format).Add(float64(events))
+}
+
+// ObserveDB records the duration of a database operation.
+func (m *Metrics) ObserveDB(op string, d time.Duration) {
+	m.Common().ObserveDB(op, d)
+}
@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/jredh-dev/nexus/services/cal/internal/database"
+)
+
+func testDB(t *testing.T) *database.DB {
+	t.Helper()
+	db, err := database.Open(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("scrape status = %d", rec.Code)
+	}
+	body, _ := io.ReadAll(rec.Body)
+	return string(body)
+}
+
+func TestMetrics(t *testing.T) {
+	db := testDB(t)
+	m := New(db)
+
+	now := time.Now().UTC()
+	feed := &database.Feed{ID: "f1", Name: "Feed", Token: "tok", CreatedAt: now, UpdatedAt: now}
+	if err := db.CreateFeed(feed); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"e1", "e2"} {
+		e := &database.Event{ID: id, FeedID: feed.ID, Summary: id, Start: now, CreatedAt: now, UpdatedAt: now}
+		if err := db.CreateEvent(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := chi.NewRouter()
+	r.Use(m.Middleware)
+	r.Get("/feeds/{id}", func(w http.ResponseWriter, r *http.Request) {
+		m.FeedFetched("ics", 2)
+		w.WriteHeader(http.StatusTeapot)
+	})
+	for _, path := range []string{"/feeds/a", "/feeds/b", "/nowhere"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	body := scrape(t, m)
+	for _, want := range []string{
+		`cal_http_request_duration_seconds_count{method="GET",route="/feeds/{id}",status="418"} 2`,
+		`cal_http_request_duration_seconds_count{method="GET",route="unmatched",status="404"} 1`,
+		`cal_feed_fetches_total{format="ics"} 2`,
+		`cal_feed_events_served_total{format="ics"} 4`,
+		`cal_events 2`,
+		`cal_feeds 1`,
+		`cal_db_operation_duration_seconds_count{op="create_event"} 2`,
+		`cal_db_operation_duration_seconds_count{op="create_feed"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q", want)
+		}
+	}
+}
+
+func TestNilMetrics(t *testing.T) {
+	var m *Metrics
+	m.FeedFetched("ics", 1)
+	m.ObserveDB("op", time.Second)
+
+	called := false
+	h := m.Middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { called = true }))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if !called {
+		t.Error("nil Metrics middleware did not call next")
+	}
+}
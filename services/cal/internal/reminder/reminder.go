@@ -0,0 +1,95 @@
+// Package reminder turns the calendar into an active notification source:
+// for events with an SMS reminder, it publishes an outbound message to the
+// sms-outbox pipeline shortly before the event starts.
+package reminder
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
+	"github.com/jredh-dev/nexus/services/cal/internal/database"
+)
+
+// Scheduler periodically publishes due reminders.
+type Scheduler struct {
+	db       *database.DB
+	pub      smsoutbox.Publisher
+	interval time.Duration
+	now      func() time.Time
+}
+
+// New creates a Scheduler that checks for due reminders every interval.
+func New(db *database.DB, pub smsoutbox.Publisher, interval time.Duration) *Scheduler {
+	return &Scheduler{db: db, pub: pub, interval: interval, now: time.Now}
+}
+
+// Run checks for due reminders until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	t := time.NewTicker(s.interval)
+	defer t.Stop()
+
+	s.Tick(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			s.Tick(ctx)
+		}
+	}
+}
+
+// Tick publishes every reminder that is due. A reminder is due once the
+// current time reaches start minus its lead time. Reminders whose event has
+// already started are marked handled without sending — a late "starts in 15
+// minutes" text is worse than none. Publish failures leave the reminder
+// pending so the next tick retries it.
+func (s *Scheduler) Tick(ctx context.Context) {
+	events, err := s.db.PendingReminders()
+	if err != nil {
+		slog.Error("list pending reminders", "err", err)
+		return
+	}
+
+	now := s.now()
+	for _, e := range events {
+		if ctx.Err() != nil {
+			return
+		}
+		due := e.Start.Add(-time.Duration(e.Reminder.MinutesBefore) * time.Minute)
+		if now.Before(due) {
+			continue
+		}
+
+		if !now.Before(e.Start) {
+			slog.Warn("event already started; skipping reminder", "event_id", e.ID)
+		} else if err := s.pub.Publish(ctx, Message(e, now)); err != nil {
+			slog.Error("publish reminder", "event_id", e.ID, "err", err)
+			continue
+		}
+
+		if err := s.db.MarkReminderSent(e.ID, now); err != nil {
+			slog.Error("mark reminder sent", "event_id", e.ID, "err", err)
+		}
+	}
+}
+
+// Message builds the outbound SMS for an event's reminder. The ID is derived
+// from the event and its start time so a redelivered reminder is recognisable
+// downstream, while a rescheduled event gets a fresh one.
+func Message(e *database.Event, now time.Time) smsoutbox.OutboundMessage {
+	body := fmt.Sprintf("Reminder: %s starts at %s UTC", e.Summary, e.Start.UTC().Format("Mon Jan 2 15:04"))
+	if e.Location != "" {
+		body += " (" + e.Location + ")"
+	}
+	return smsoutbox.OutboundMessage{
+		ID:        fmt.Sprintf("cal-reminder-%s-%d", e.ID, e.Start.Unix()),
+		To:        e.Reminder.To,
+		Body:      body,
+		Source:    "cal",
+		CreatedAt: now.UTC(),
+	}
+}
@@ -0,0 +1,130 @@
+package reminder
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
+	"github.com/jredh-dev/nexus/services/cal/internal/database"
+)
+
+type fakePublisher struct {
+	sent []smsoutbox.OutboundMessage
+	err  error
+}
+
+func (p *fakePublisher) Publish(_ context.Context, msg smsoutbox.OutboundMessage) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.sent = append(p.sent, msg)
+	return nil
+}
+
+func testDB(t *testing.T) *database.DB {
+	t.Helper()
+	db, err := database.Open(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestTick(t *testing.T) {
+	db := testDB(t)
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	if err := db.CreateFeed(&database.Feed{ID: "feed-1", Name: "Test", Token: "tok", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+	events := []*database.Event{
+		// due: starts in 10 minutes, 15 minute lead
+		{ID: "due", Summary: "Standup", Location: "Room 1", Start: now.Add(10 * time.Minute), Status: "CONFIRMED",
+			Reminder: &database.Reminder{To: "+15555550100", MinutesBefore: 15}},
+		// not yet due: starts in an hour
+		{ID: "later", Summary: "Lunch", Start: now.Add(time.Hour), Status: "CONFIRMED",
+			Reminder: &database.Reminder{To: "+15555550100", MinutesBefore: 15}},
+		// already started: marked handled without sending
+		{ID: "started", Summary: "Missed", Start: now.Add(-time.Minute), Status: "CONFIRMED",
+			Reminder: &database.Reminder{To: "+15555550100", MinutesBefore: 15}},
+		// cancelled: never reminded
+		{ID: "cancelled", Summary: "Off", Start: now.Add(5 * time.Minute), Status: "CANCELLED",
+			Reminder: &database.Reminder{To: "+15555550100", MinutesBefore: 15}},
+		// no reminder
+		{ID: "plain", Summary: "Plain", Start: now.Add(5 * time.Minute), Status: "CONFIRMED"},
+	}
+	for _, e := range events {
+		e.FeedID = "feed-1"
+		e.CreatedAt, e.UpdatedAt = now, now
+		if err := db.CreateEvent(e); err != nil {
+			t.Fatalf("create event %s: %v", e.ID, err)
+		}
+	}
+
+	pub := &fakePublisher{}
+	s := New(db, pub, time.Minute)
+	s.now = func() time.Time { return now }
+
+	s.Tick(context.Background())
+
+	if len(pub.sent) != 1 {
+		t.Fatalf("expected 1 reminder, got %d: %+v", len(pub.sent), pub.sent)
+	}
+	msg := pub.sent[0]
+	if msg.To != "+15555550100" || msg.Source != "cal" {
+		t.Errorf("unexpected message: %+v", msg)
+	}
+	if msg.Body != "Reminder: Standup starts at Mon Mar 2 09:10 UTC (Room 1)" {
+		t.Errorf("unexpected body: %q", msg.Body)
+	}
+
+	// Sent and skipped reminders drop out; the later one remains pending.
+	pending, err := db.PendingReminders()
+	if err != nil {
+		t.Fatalf("pending reminders: %v", err)
+	}
+	if len(pending) != 1 || pending[0].ID != "later" {
+		t.Errorf("expected only 'later' to remain pending, got %+v", pending)
+	}
+
+	// A second tick at the same time sends nothing new.
+	s.Tick(context.Background())
+	if len(pub.sent) != 1 {
+		t.Errorf("reminder sent twice: %+v", pub.sent)
+	}
+}
+
+func TestTick_PublishFailureRetries(t *testing.T) {
+	db := testDB(t)
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	if err := db.CreateFeed(&database.Feed{ID: "feed-1", Name: "Test", Token: "tok", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("create feed: %v", err)
+	}
+	if err := db.CreateEvent(&database.Event{
+		ID: "due", FeedID: "feed-1", Summary: "Standup", Start: now.Add(5 * time.Minute), Status: "CONFIRMED",
+		Reminder:  &database.Reminder{To: "+15555550100", MinutesBefore: 15},
+		CreatedAt: now, UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("create event: %v", err)
+	}
+
+	pub := &fakePublisher{err: errors.New("broker down")}
+	s := New(db, pub, time.Minute)
+	s.now = func() time.Time { return now }
+
+	s.Tick(context.Background())
+	pending, _ := db.PendingReminders()
+	if len(pending) != 1 {
+		t.Fatalf("failed publish should leave reminder pending, got %d", len(pending))
+	}
+
+	pub.err = nil
+	s.Tick(context.Background())
+	if len(pub.sent) != 1 {
+		t.Errorf("expected retry to publish, got %d", len(pub.sent))
+	}
+}
@@ -0,0 +1,571 @@
+// Package validator checks iCalendar documents against the RFC 5545 grammar.
+//
+// It is a test harness for the ical generator, not a general-purpose parser:
+// it covers the components and properties nexus-cal emits (VCALENDAR,
+// VEVENT, VALARM, VFREEBUSY) and reports every problem it finds rather than
+// stopping at the first, so a failing test shows the whole picture.
+package validator
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxLineOctets is the RFC 5545 section 3.1 limit on a physical line,
+// excluding the CRLF.
+const maxLineOctets = 75
+
+// Error is a single conformance problem. Line is the 1-based physical line
+// the offending content line starts on, or 0 for document-level problems.
+type Error struct {
+	Line int
+	Msg  string
+}
+
+func (e Error) Error() string {
+	if e.Line == 0 {
+		return e.Msg
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+// Prop is a parsed content line.
+type Prop struct {
+	Name   string // uppercased
+	Params map[string][]string
+	Value  string
+	Line   int
+}
+
+// Param returns the first value of a parameter, or "".
+func (p Prop) Param(name string) string {
+	if v := p.Params[name]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// Component is a parsed BEGIN/END block.
+type Component struct {
+	Name     string
+	Props    []Prop
+	Children []*Component
+	Line     int
+}
+
+type checker struct {
+	errs []error
+}
+
+func (c *checker) errorf(line int, format string, args ...interface{}) {
+	c.errs = append(c.errs, Error{Line: line, Msg: fmt.Sprintf(format, args...)})
+}
+
+// Validate checks doc and returns every problem found, or nil if the
+// document conforms.
+func Validate(doc string) []error {
+	c := &checker{}
+	lines := c.unfold(doc)
+	root := c.parse(lines)
+	if root != nil {
+		c.component(root)
+	}
+	return c.errs
+}
+
+type logicalLine struct {
+	text string
+	line int
+}
+
+// unfold checks line endings and folding (section 3.1) and joins folded
+// lines into logical content lines.
+func (c *checker) unfold(doc string) []logicalLine {
+	if doc == "" {
+		c.errorf(0, "empty document")
+		return nil
+	}
+	if !strings.HasSuffix(doc, "\r\n") {
+		c.errorf(0, "document must end with CRLF")
+	}
+
+	var out []logicalLine
+	for i, line := range strings.Split(strings.TrimSuffix(doc, "\r\n"), "\r\n") {
+		n := i + 1
+		if strings.ContainsAny(line, "\r\n") {
+			c.errorf(n, "bare CR or LF; lines must end with CRLF")
+		}
+		if len(line) > maxLineOctets {
+			c.errorf(n, "line is %d octets; the limit is %d", len(line), maxLineOctets)
+		}
+		if !utf8.ValidString(line) {
+			c.errorf(n, "invalid UTF-8 (a fold may split a multi-octet character)")
+		}
+		if line != "" && (line[0] == ' ' || line[0] == '\t') {
+			if len(out) == 0 {
+				c.errorf(n, "continuation line with nothing to continue")
+				continue
+			}
+			out[len(out)-1].text += line[1:]
+			continue
+		}
+		out = append(out, logicalLine{text: line, line: n})
+	}
+	return out
+}
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+
+// parseLine splits a content line into name, parameters, and value
+// (section 3.1 contentline grammar).
+func (c *checker) parseLine(l logicalLine) (Prop, bool) {
+	s := l.text
+	p := Prop{Params: map[string][]string{}, Line: l.line}
+
+	i := strings.IndexAny(s, ";:")
+	if i < 0 {
+		c.errorf(l.line, "content line has no ':'")
+		return p, false
+	}
+	if !namePattern.MatchString(s[:i]) {
+		c.errorf(l.line, "invalid property name %q", s[:i])
+		return p, false
+	}
+	p.Name = strings.ToUpper(s[:i])
+	s = s[i:]
+
+	for strings.HasPrefix(s, ";") {
+		s = s[1:]
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 || !namePattern.MatchString(s[:eq]) {
+			c.errorf(l.line, "%s: malformed parameter", p.Name)
+			return p, false
+		}
+		pname := strings.ToUpper(s[:eq])
+		s = s[eq+1:]
+		for {
+			var v string
+			if strings.HasPrefix(s, `"`) {
+				end := strings.IndexByte(s[1:], '"')
+				if end < 0 {
+					c.errorf(l.line, "%s: unterminated quoted parameter %s", p.Name, pname)
+					return p, false
+				}
+				v = s[1 : end+1]
+				s = s[end+2:]
+				if hasControl(v) {
+					c.errorf(l.line, "%s: control character in parameter %s", p.Name, pname)
+				}
+			} else {
+				end := strings.IndexAny(s, ",;:")
+				if end < 0 {
+					c.errorf(l.line, "%s: content line has no ':'", p.Name)
+					return p, false
+				}
+				v = s[:end]
+				s = s[end:]
+				if hasControl(v) || strings.Contains(v, `"`) {
+					c.errorf(l.line, "%s: unsafe character in parameter %s", p.Name, pname)
+				}
+			}
+			p.Params[pname] = append(p.Params[pname], v)
+			if !strings.HasPrefix(s, ",") {
+				break
+			}
+			s = s[1:]
+		}
+	}
+
+	if !strings.HasPrefix(s, ":") {
+		c.errorf(l.line, "%s: expected ':' after parameters", p.Name)
+		return p, false
+	}
+	p.Value = s[1:]
+	if hasControl(p.Value) {
+		c.errorf(l.line, "%s: control character in value", p.Name)
+	}
+	return p, true
+}
+
+// hasControl reports whether s contains a CONTROL character other than HTAB.
+func hasControl(s string) bool {
+	for _, r := range s {
+		if (r < 0x20 && r != '\t') || r == 0x7f {
+			return true
+		}
+	}
+	return false
+}
+
+// parse builds the component tree and checks BEGIN/END nesting.
+func (c *checker) parse(lines []logicalLine) *Component {
+	var root *Component
+	var stack []*Component
+	for _, l := range lines {
+		p, ok := c.parseLine(l)
+		if !ok {
+			continue
+		}
+		switch p.Name {
+		case "BEGIN":
+			comp := &Component{Name: strings.ToUpper(p.Value), Line: p.Line}
+			if len(stack) == 0 {
+				if root != nil {
+					c.errorf(p.Line, "content after the end of VCALENDAR")
+				}
+				root = comp
+			} else {
+				top := stack[len(stack)-1]
+				top.Children = append(top.Children, comp)
+			}
+			stack = append(stack, comp)
+		case "END":
+			if len(stack) == 0 {
+				c.errorf(p.Line, "END:%s without BEGIN", p.Value)
+				continue
+			}
+			top := stack[len(stack)-1]
+			if !strings.EqualFold(p.Value, top.Name) {
+				c.errorf(p.Line, "END:%s does not match BEGIN:%s on line %d", p.Value, top.Name, top.Line)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 0 {
+				c.errorf(p.Line, "%s outside of any component", p.Name)
+				continue
+			}
+			top := stack[len(stack)-1]
+			top.Props = append(top.Props, p)
+		}
+	}
+	for _, comp := range stack {
+		c.errorf(comp.Line, "BEGIN:%s is never closed", comp.Name)
+	}
+	if root == nil {
+		c.errorf(0, "no VCALENDAR object")
+	} else if root.Name != "VCALENDAR" {
+		c.errorf(root.Line, "top-level component is %s, not VCALENDAR", root.Name)
+	}
+	return root
+}
+
+// rule lists the cardinality constraints for one component (section 3.6).
+type rule struct {
+	required []string // exactly once
+	once     []string // at most once
+}
+
+var rules = map[string]rule{
+	"VCALENDAR": {
+		required: []string{"PRODID", "VERSION"},
+		once:     []string{"CALSCALE", "METHOD", "NAME", "REFRESH-INTERVAL"},
+	},
+	"VEVENT": {
+		required: []string{"UID", "DTSTAMP", "DTSTART"},
+		once: []string{"CLASS", "CREATED", "DESCRIPTION", "GEO", "LAST-MODIFIED", "LOCATION",
+			"ORGANIZER", "PRIORITY", "SEQUENCE", "STATUS", "SUMMARY", "TRANSP", "URL",
+			"RECURRENCE-ID", "DTEND", "DURATION"},
+	},
+	"VALARM": {
+		required: []string{"ACTION", "TRIGGER"},
+		once:     []string{"DESCRIPTION", "DURATION", "REPEAT"},
+	},
+	"VFREEBUSY": {
+		required: []string{"UID", "DTSTAMP"},
+		once:     []string{"CONTACT", "DTSTART", "DTEND", "ORGANIZER", "URL"},
+	},
+}
+
+// component checks a component, its properties, and its children.
+func (c *checker) component(comp *Component) {
+	counts := map[string]int{}
+	for _, p := range comp.Props {
+		counts[p.Name]++
+	}
+	if r, ok := rules[comp.Name]; ok {
+		for _, name := range r.required {
+			if counts[name] != 1 {
+				c.errorf(comp.Line, "%s must have exactly one %s, has %d", comp.Name, name, counts[name])
+			}
+		}
+		for _, name := range r.once {
+			if counts[name] > 1 {
+				c.errorf(comp.Line, "%s must have at most one %s, has %d", comp.Name, name, counts[name])
+			}
+		}
+	}
+
+	for _, p := range comp.Props {
+		c.prop(comp.Name, p)
+	}
+
+	switch comp.Name {
+	case "VCALENDAR":
+		for _, child := range comp.Children {
+			if child.Name == "VALARM" {
+				c.errorf(child.Line, "VALARM must be inside VEVENT or VTODO")
+			}
+		}
+	case "VEVENT":
+		c.eventTimes(comp)
+		for _, child := range comp.Children {
+			if child.Name != "VALARM" {
+				c.errorf(child.Line, "%s cannot be nested in VEVENT", child.Name)
+			}
+		}
+	case "VALARM":
+		if action := first(comp, "ACTION"); action != nil && strings.EqualFold(action.Value, "DISPLAY") && counts["DESCRIPTION"] != 1 {
+			c.errorf(comp.Line, "DISPLAY alarm must have a DESCRIPTION")
+		}
+	}
+
+	for _, child := range comp.Children {
+		c.component(child)
+	}
+}
+
+func first(comp *Component, name string) *Prop {
+	for i := range comp.Props {
+		if comp.Props[i].Name == name {
+			return &comp.Props[i]
+		}
+	}
+	return nil
+}
+
+// eventTimes checks DTSTART/DTEND/DURATION consistency (section 3.6.1).
+func (c *checker) eventTimes(comp *Component) {
+	start, end := first(comp, "DTSTART"), first(comp, "DTEND")
+	if end != nil && first(comp, "DURATION") != nil {
+		c.errorf(comp.Line, "VEVENT cannot have both DTEND and DURATION")
+	}
+	if start == nil || end == nil {
+		return
+	}
+	startDate := strings.EqualFold(start.Param("VALUE"), "DATE")
+	endDate := strings.EqualFold(end.Param("VALUE"), "DATE")
+	if startDate != endDate {
+		c.errorf(end.Line, "DTEND value type must match DTSTART")
+		return
+	}
+	s, err1 := parseTime(start.Value, startDate)
+	e, err2 := parseTime(end.Value, endDate)
+	if err1 == nil && err2 == nil && !e.After(s) {
+		c.errorf(end.Line, "DTEND must be later than DTSTART")
+	}
+}
+
+var (
+	dateTimePattern    = regexp.MustCompile(`^\d{8}T\d{6}Z?$`)
+	utcDateTimePattern = regexp.MustCompile(`^\d{8}T\d{6}Z$`)
+	datePattern        = regexp.MustCompile(`^\d{8}$`)
+	durationPattern    = regexp.MustCompile(`^[+-]?P(\d+W|\d+D(T(\d+H(\d+M(\d+S)?)?|\d+M(\d+S)?|\d+S))?|T(\d+H(\d+M(\d+S)?)?|\d+M(\d+S)?|\d+S))$`)
+	uriPattern         = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*:[^\s]+$`)
+	tokenPattern       = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+)
+
+func parseTime(v string, date bool) (time.Time, error) {
+	if date {
+		return time.Parse("20060102", v)
+	}
+	return time.Parse("20060102T150405", strings.TrimSuffix(v, "Z"))
+}
+
+var statuses = map[string][]string{
+	"VEVENT": {"TENTATIVE", "CONFIRMED", "CANCELLED"},
+}
+
+var partStats = []string{"NEEDS-ACTION", "ACCEPTED", "DECLINED", "TENTATIVE", "DELEGATED"}
+
+var fbTypes = []string{"FREE", "BUSY", "BUSY-UNAVAILABLE", "BUSY-TENTATIVE"}
+
+// prop checks a property value against its value type.
+func (c *checker) prop(comp string, p Prop) {
+	switch p.Name {
+	case "VERSION":
+		if p.Value != "2.0" {
+			c.errorf(p.Line, "VERSION must be 2.0, got %q", p.Value)
+		}
+	case "CALSCALE":
+		if !strings.EqualFold(p.Value, "GREGORIAN") {
+			c.errorf(p.Line, "unsupported CALSCALE %q", p.Value)
+		}
+	case "METHOD":
+		if !tokenPattern.MatchString(p.Value) {
+			c.errorf(p.Line, "METHOD must be a token, got %q", p.Value)
+		}
+	case "DTSTAMP", "CREATED", "LAST-MODIFIED":
+		c.utcDateTime(p)
+	case "DTSTART", "DTEND":
+		c.dateOrDateTime(p)
+	case "TRIGGER":
+		if strings.EqualFold(p.Param("VALUE"), "DATE-TIME") {
+			c.utcDateTime(p)
+		} else {
+			c.duration(p)
+		}
+	case "REFRESH-INTERVAL":
+		if !strings.EqualFold(p.Param("VALUE"), "DURATION") {
+			c.errorf(p.Line, "REFRESH-INTERVAL requires VALUE=DURATION")
+		}
+		c.duration(p)
+	case "X-PUBLISHED-TTL":
+		c.duration(p)
+	case "SUMMARY", "DESCRIPTION", "LOCATION", "UID", "NAME", "X-WR-CALNAME", "X-WR-CALDESC":
+		c.text(p, false)
+	case "CATEGORIES":
+		c.text(p, true)
+	case "URL":
+		c.uri(p)
+	case "STATUS":
+		if allowed, ok := statuses[comp]; ok && !contains(allowed, strings.ToUpper(p.Value)) {
+			c.errorf(p.Line, "STATUS %q is not valid in %s", p.Value, comp)
+		}
+	case "ORGANIZER", "ATTENDEE":
+		c.uri(p)
+		if ps := p.Param("PARTSTAT"); ps != "" && !contains(partStats, strings.ToUpper(ps)) {
+			c.errorf(p.Line, "invalid PARTSTAT %q", ps)
+		}
+		if rsvp := p.Param("RSVP"); rsvp != "" && rsvp != "TRUE" && rsvp != "FALSE" {
+			c.errorf(p.Line, "RSVP must be TRUE or FALSE, got %q", rsvp)
+		}
+	case "ATTACH":
+		c.attach(p)
+	case "FREEBUSY":
+		c.freeBusy(p)
+	}
+}
+
+func (c *checker) utcDateTime(p Prop) {
+	if !utcDateTimePattern.MatchString(p.Value) {
+		c.errorf(p.Line, "%s must be a UTC DATE-TIME, got %q", p.Name, p.Value)
+		return
+	}
+	if _, err := parseTime(p.Value, false); err != nil {
+		c.errorf(p.Line, "%s is not a real date-time: %q", p.Name, p.Value)
+	}
+}
+
+func (c *checker) dateOrDateTime(p Prop) {
+	switch v := strings.ToUpper(p.Param("VALUE")); v {
+	case "DATE":
+		if !datePattern.MatchString(p.Value) {
+			c.errorf(p.Line, "%s;VALUE=DATE must be YYYYMMDD, got %q", p.Name, p.Value)
+			return
+		}
+		if _, err := parseTime(p.Value, true); err != nil {
+			c.errorf(p.Line, "%s is not a real date: %q", p.Name, p.Value)
+		}
+	case "", "DATE-TIME":
+		if !dateTimePattern.MatchString(p.Value) {
+			c.errorf(p.Line, "%s must be a DATE-TIME, got %q", p.Name, p.Value)
+			return
+		}
+		if _, err := parseTime(p.Value, false); err != nil {
+			c.errorf(p.Line, "%s is not a real date-time: %q", p.Name, p.Value)
+		}
+	default:
+		c.errorf(p.Line, "%s cannot have VALUE=%s", p.Name, v)
+	}
+}
+
+func (c *checker) duration(p Prop) {
+	if !durationPattern.MatchString(p.Value) {
+		c.errorf(p.Line, "%s must be a DURATION, got %q", p.Name, p.Value)
+	}
+}
+
+func (c *checker) uri(p Prop) {
+	if !uriPattern.MatchString(p.Value) {
+		c.errorf(p.Line, "%s must be a URI, got %q", p.Name, p.Value)
+	}
+}
+
+// text checks TEXT escaping (section 3.3.11): backslash only before \ ; , n
+// or N, and no bare ';' — nor bare ',' unless the property is multi-valued.
+func (c *checker) text(p Prop, list bool) {
+	v := p.Value
+	for i := 0; i < len(v); i++ {
+		switch v[i] {
+		case '\\':
+			if i+1 >= len(v) || !strings.ContainsRune(`\;,nN`, rune(v[i+1])) {
+				c.errorf(p.Line, "%s: invalid escape sequence at offset %d", p.Name, i)
+				return
+			}
+			i++
+		case ';':
+			c.errorf(p.Line, "%s: unescaped ';'", p.Name)
+			return
+		case ',':
+			if !list {
+				c.errorf(p.Line, "%s: unescaped ','", p.Name)
+				return
+			}
+			if i == 0 || i == len(v)-1 || v[i+1] == ',' {
+				c.errorf(p.Line, "%s: empty list value", p.Name)
+				return
+			}
+		}
+	}
+}
+
+// attach checks an ATTACH property (section 3.8.1.1): inline values must be
+// BASE64 with VALUE=BINARY, everything else a URI.
+func (c *checker) attach(p Prop) {
+	enc := strings.ToUpper(p.Param("ENCODING"))
+	val := strings.ToUpper(p.Param("VALUE"))
+	if enc == "" && val == "" {
+		c.uri(p)
+		return
+	}
+	if enc != "BASE64" || val != "BINARY" {
+		c.errorf(p.Line, "inline ATTACH requires ENCODING=BASE64 and VALUE=BINARY")
+		return
+	}
+	if _, err := base64.StdEncoding.DecodeString(p.Value); err != nil {
+		c.errorf(p.Line, "ATTACH is not valid base64: %v", err)
+	}
+}
+
+// freeBusy checks a FREEBUSY period list (section 3.8.2.6): UTC periods of
+// start/end or start/duration.
+func (c *checker) freeBusy(p Prop) {
+	if t := strings.ToUpper(p.Param("FBTYPE")); t != "" && !contains(fbTypes, t) && !strings.HasPrefix(t, "X-") {
+		c.errorf(p.Line, "invalid FBTYPE %q", t)
+	}
+	for _, period := range strings.Split(p.Value, ",") {
+		start, end, ok := strings.Cut(period, "/")
+		if !ok || !utcDateTimePattern.MatchString(start) {
+			c.errorf(p.Line, "FREEBUSY period %q must start with a UTC DATE-TIME", period)
+			continue
+		}
+		if strings.HasPrefix(end, "P") || strings.HasPrefix(end, "+P") {
+			if !durationPattern.MatchString(end) {
+				c.errorf(p.Line, "FREEBUSY period %q has an invalid duration", period)
+			}
+			continue
+		}
+		if !utcDateTimePattern.MatchString(end) {
+			c.errorf(p.Line, "FREEBUSY period %q must end with a UTC DATE-TIME or duration", period)
+			continue
+		}
+		s, _ := parseTime(start, false)
+		e, _ := parseTime(end, false)
+		if !e.After(s) {
+			c.errorf(p.Line, "FREEBUSY period %q ends before it starts", period)
+		}
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+)
+
+// doc joins content lines with CRLF.
+func doc(lines ...string) string {
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
+
+func validEvent(extra ...string) string {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//test//EN",
+		"BEGIN:VEVENT",
+		"UID:1@test",
+		"DTSTAMP:20260301T090000Z",
+		"DTSTART:20260301T090000Z",
+	}
+	lines = append(lines, extra...)
+	return doc(append(lines, "END:VEVENT", "END:VCALENDAR")...)
+}
+
+func TestValidate_Conformant(t *testing.T) {
+	d := validEvent(
+		"DTEND:20260301T100000Z",
+		`SUMMARY:Plan\, review\; ship\\done\nnext`,
+		"CATEGORIES:work,fun",
+		`ATTENDEE;CN="Doe, Jane";PARTSTAT=ACCEPTED;RSVP=TRUE:mailto:j@example.com`,
+		"ATTACH;FMTTYPE=text/plain;ENCODING=BASE64;VALUE=BINARY:aGk=",
+		"BEGIN:VALARM",
+		"TRIGGER:-PT1H",
+		"ACTION:DISPLAY",
+		"DESCRIPTION:Soon",
+		"END:VALARM",
+	)
+	if errs := Validate(d); errs != nil {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+
+	folded := validEvent("DESCRIPTION:" + strings.Repeat("a", 60) + "\r\n " + strings.Repeat("b", 70))
+	if errs := Validate(folded); errs != nil {
+		t.Fatalf("folded: expected no errors, got %v", errs)
+	}
+
+	fb := doc(
+		"BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:-//test//EN",
+		"BEGIN:VFREEBUSY", "UID:fb@test", "DTSTAMP:20260301T090000Z",
+		"FREEBUSY:20260301T090000Z/20260301T100000Z,20260302T090000Z/PT1H",
+		"END:VFREEBUSY", "END:VCALENDAR",
+	)
+	if errs := Validate(fb); errs != nil {
+		t.Fatalf("freebusy: expected no errors, got %v", errs)
+	}
+}
+
+func TestValidate_Violations(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{"LF line endings", strings.ReplaceAll(validEvent(), "\r\n", "\n"), "CRLF"},
+		{"long line", validEvent("DESCRIPTION:" + strings.Repeat("x", 80)), "octets"},
+		{"split rune", validEvent("DESCRIPTION:" + strings.Repeat("x", 62) + "\xc3\r\n \xa9"), "UTF-8"},
+		{"missing UID", strings.Replace(validEvent(), "UID:1@test\r\n", "", 1), "exactly one UID"},
+		{"duplicate SUMMARY", validEvent("SUMMARY:a", "SUMMARY:b"), "at most one SUMMARY"},
+		{"missing PRODID", strings.Replace(validEvent(), "PRODID:-//test//EN\r\n", "", 1), "exactly one PRODID"},
+		{"unclosed", strings.Replace(validEvent(), "END:VEVENT\r\n", "", 1), "does not match"},
+		{"local DTSTAMP", strings.Replace(validEvent(), "DTSTAMP:20260301T090000Z", "DTSTAMP:20260301T090000", 1), "UTC DATE-TIME"},
+		{"bad date", validEvent("DTEND;VALUE=DATE:2026-03-01"), "YYYYMMDD"},
+		{"end before start", validEvent("DTEND:20260301T080000Z"), "later than DTSTART"},
+		{"end and duration", validEvent("DTEND:20260301T100000Z", "DURATION:PT1H"), "both DTEND and DURATION"},
+		{"unescaped semicolon", validEvent("SUMMARY:a;b"), "unescaped ';'"},
+		{"unescaped comma", validEvent("LOCATION:a,b"), "unescaped ','"},
+		{"bad escape", validEvent(`SUMMARY:a\tb`), "escape sequence"},
+		{"control char", validEvent("SUMMARY:a\x01b"), "control character"},
+		{"bad status", validEvent("STATUS:DONE"), "STATUS"},
+		{"bad partstat", validEvent("ATTENDEE;PARTSTAT=MAYBE:mailto:a@example.com"), "PARTSTAT"},
+		{"attendee not a URI", validEvent("ATTENDEE:a@example.com"), "URI"},
+		{"binary without encoding", validEvent("ATTACH;VALUE=BINARY:aGk="), "ENCODING=BASE64"},
+		{"bad base64", validEvent("ATTACH;ENCODING=BASE64;VALUE=BINARY:not base64!"), "base64"},
+		{"bad trigger", validEvent("BEGIN:VALARM", "TRIGGER:1 hour", "ACTION:AUDIO", "END:VALARM"), "DURATION"},
+		{"display without description", validEvent("BEGIN:VALARM", "TRIGGER:-PT1H", "ACTION:DISPLAY", "END:VALARM"), "DESCRIPTION"},
+		{"unquoted colon param", validEvent(`ORGANIZER;CN="a:b:mailto:a@example.com`), "unterminated"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := Validate(tt.doc)
+			for _, err := range errs {
+				if strings.Contains(err.Error(), tt.want) {
+					return
+				}
+			}
+			t.Errorf("expected an error containing %q, got %v", tt.want, errs)
+		})
+	}
+}
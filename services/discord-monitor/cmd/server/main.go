@@ -33,12 +33,15 @@ import (
 	"context"
 	_ "embed"
 	"flag"
-	"log"
+	"fmt"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/jredh-dev/nexus/internal/logging"
+	"github.com/jredh-dev/nexus/internal/secretsmgr"
 	"github.com/jredh-dev/nexus/services/discord-monitor/internal/database"
 	"github.com/jredh-dev/nexus/services/discord-monitor/internal/selfbot"
 	"github.com/jredh-dev/nexus/services/discord-monitor/internal/server"
@@ -74,15 +77,18 @@ func run() int {
 	if err := fs.Parse(os.Args[1:]); err != nil {
 		return 1
 	}
+	if err := logging.Setup("discord-monitor"); err != nil {
+		fmt.Fprintf(os.Stderr, "discord-monitor: %v\n", err)
+		return 1
+	}
 
 	// Resolve config: flag > env > default.
 	port := resolve(*portFlag, os.Getenv("PORT"), "8080")
 	dbURL := resolve(*dbFlag, os.Getenv("DATABASE_URL"), "host=localhost port=5432 dbname=discord_monitor user=jredh")
-	selfbotToken := os.Getenv("DISCORD_SELFBOT_TOKEN")
+	selfbotToken := secretsmgr.Get("DISCORD_SELFBOT_TOKEN")
 	scanInterval := parseDuration(os.Getenv("SCAN_INTERVAL_SELFBOT"), 60*time.Second)
 
-	log.Printf("[discord-monitor] starting: port=%s db_configured=true selfbot=%v scan_interval=%s",
-		port, selfbotToken != "", scanInterval)
+	slog.Info("discord-monitor starting", "port", port, "selfbot", selfbotToken != "", "scan_interval", scanInterval)
 
 	// Create a root context that is cancelled on SIGINT/SIGTERM.
 	// This context is used by the scanner goroutine for graceful shutdown.
@@ -95,10 +101,11 @@ func run() int {
 
 	db, err := database.New(dbCtx, dbURL)
 	if err != nil {
-		log.Fatalf("[discord-monitor] database: %v", err)
+		slog.Error("database", "err", err)
+		return 1
 	}
 	defer db.Close()
-	log.Println("[discord-monitor] database connected, migrations applied")
+	slog.Info("database connected, migrations applied")
 
 	// Track selfbot state for the server config.
 	selfbotConnected := false
@@ -115,20 +122,18 @@ func run() int {
 		verifyCancel()
 
 		if err != nil {
-			log.Printf("[discord-monitor] selfbot token validation failed: %v", err)
-			log.Println("[discord-monitor] continuing without selfbot — API-only mode")
+			slog.Warn("selfbot token validation failed; continuing in API-only mode", "err", err)
 		} else {
 			selfbotConnected = true
 			userID = user.ID
-			log.Printf("[discord-monitor] selfbot authenticated as %s#%s (%s)",
-				user.Username, user.Discriminator, user.ID)
+			slog.Info("selfbot authenticated", "user", user.Username+"#"+user.Discriminator, "user_id", user.ID)
 
 			// Start the scanner goroutine. It will run until ctx is cancelled
 			// (SIGINT/SIGTERM). The scanner performs periodic guild/channel/message
 			// sync from Discord.
 			scanner := selfbot.NewScanner(client, db, scanInterval)
 			go scanner.Start(ctx)
-			log.Printf("[discord-monitor] scanner started, interval=%s", scanInterval)
+			slog.Info("scanner started", "interval", scanInterval)
 		}
 	}
 
@@ -146,16 +151,16 @@ func run() int {
 	}
 
 	srv.OnStop(func() {
-		log.Println("[discord-monitor] shutting down...")
 		// Cancel the root context to stop the scanner goroutine.
 		cancel()
-		log.Println("[discord-monitor] closing database connection")
+		slog.Info("closing database connection")
 		db.Close()
 	})
 
-	log.Printf("[discord-monitor] listening on :%s", port)
+	slog.Info("discord-monitor listening", "addr", ":"+port)
 	if err := srv.ListenAndServe(":" + port); err != nil {
-		log.Fatalf("[discord-monitor] server: %v", err)
+		slog.Error("server", "err", err)
+		return 1
 	}
 
 	return 0
@@ -180,7 +185,7 @@ func parseDuration(s string, fallback time.Duration) time.Duration {
 	}
 	d, err := time.ParseDuration(s)
 	if err != nil {
-		log.Printf("[discord-monitor] invalid duration %q, using default %s", s, fallback)
+		slog.Warn("invalid duration; using the default", "value", s, "default", fallback)
 		return fallback
 	}
 	return d
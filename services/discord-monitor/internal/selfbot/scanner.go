@@ -3,7 +3,7 @@ package selfbot
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"strings"
 	"time"
 
@@ -44,11 +44,11 @@ func NewScanner(client *Client, db *database.DB, interval time.Duration) *Scanne
 // Runs an immediate scan on start, then ticks at the configured interval.
 // Errors from individual scan cycles are logged but do not stop the loop.
 func (s *Scanner) Start(ctx context.Context) {
-	log.Printf("[scanner] starting scan loop, interval=%s", s.interval)
+	slog.Info("scanner: starting scan loop", "interval", s.interval)
 
 	// Run an immediate scan before entering the ticker loop.
 	if err := s.ScanOnce(ctx); err != nil {
-		log.Printf("[scanner] initial scan error: %v", err)
+		slog.Error("scanner: initial scan", "err", err)
 	}
 
 	ticker := time.NewTicker(s.interval)
@@ -57,11 +57,11 @@ func (s *Scanner) Start(ctx context.Context) {
 	for {
 		select {
 		case <-ctx.Done():
-			log.Println("[scanner] stopping: context cancelled")
+			slog.Info("scanner: stopping")
 			return
 		case <-ticker.C:
 			if err := s.ScanOnce(ctx); err != nil {
-				log.Printf("[scanner] scan cycle error: %v", err)
+				slog.Error("scanner: scan cycle", "err", err)
 			}
 		}
 	}
@@ -80,8 +80,7 @@ func (s *Scanner) ScanOnce(ctx context.Context) error {
 			return fmt.Errorf("resolve user identity: %w", err)
 		}
 		s.userID = user.ID
-		log.Printf("[scanner] resolved user: %s#%s (%s)",
-			user.Username, user.Discriminator, user.ID)
+		slog.Info("scanner: resolved user", "user", user.Username+"#"+user.Discriminator, "user_id", user.ID)
 	}
 
 	// Step 1: Fetch and upsert all guilds the user belongs to.
@@ -114,7 +113,7 @@ func (s *Scanner) ScanOnce(ctx context.Context) error {
 			MemberCount: g.ApproximateMemberCount,
 		})
 		if err != nil {
-			log.Printf("[scanner] upsert guild %s (%s): %v", g.ID, g.Name, err)
+			slog.Error("scanner: upsert guild", "guild_id", g.ID, "guild", g.Name, "err", err)
 			scanErrors++
 			continue
 		}
@@ -123,7 +122,7 @@ func (s *Scanner) ScanOnce(ctx context.Context) error {
 		// Step 2: Fetch and upsert channels for this guild.
 		channels, err := s.client.GetChannels(ctx, g.ID)
 		if err != nil {
-			log.Printf("[scanner] fetch channels for guild %s: %v", g.ID, err)
+			slog.Error("scanner: fetch channels", "guild_id", g.ID, "err", err)
 			scanErrors++
 			continue
 		}
@@ -144,7 +143,7 @@ func (s *Scanner) ScanOnce(ctx context.Context) error {
 				Position:  ch.Position,
 			})
 			if err != nil {
-				log.Printf("[scanner] upsert channel %s (%s): %v", ch.ID, ch.Name, err)
+				slog.Error("scanner: upsert channel", "channel_id", ch.ID, "channel", ch.Name, "err", err)
 				scanErrors++
 				continue
 			}
@@ -158,8 +157,8 @@ func (s *Scanner) ScanOnce(ctx context.Context) error {
 	}
 
 	elapsed := time.Since(start)
-	log.Printf("[scanner] scan complete: guilds=%d channels=%d messages=%d errors=%d elapsed=%s",
-		guildsSynced, channelsSynced, messagesStored, scanErrors, elapsed.Round(time.Millisecond))
+	slog.Info("scanner: scan complete", "guilds", guildsSynced, "channels", channelsSynced,
+		"messages", messagesStored, "errors", scanErrors, "elapsed", elapsed.Round(time.Millisecond))
 
 	return nil
 }
@@ -170,7 +169,7 @@ func (s *Scanner) scanGuildMessages(ctx context.Context, guildID string) (int64,
 	// Only scan channels that are marked as monitored.
 	channels, err := s.db.ListChannels(ctx, guildID, true)
 	if err != nil {
-		log.Printf("[scanner] list monitored channels for guild %s: %v", guildID, err)
+		slog.Error("scanner: list monitored channels", "guild_id", guildID, "err", err)
 		return 0, 1
 	}
 
@@ -186,7 +185,7 @@ func (s *Scanner) scanGuildMessages(ctx context.Context, guildID string) (int64,
 
 		stored, err := s.scanChannel(ctx, ch)
 		if err != nil {
-			log.Printf("[scanner] scan channel %s (%s): %v", ch.ChannelID, ch.Name, err)
+			slog.Error("scanner: scan channel", "channel_id", ch.ChannelID, "channel", ch.Name, "err", err)
 			totalErrors++
 			continue
 		}
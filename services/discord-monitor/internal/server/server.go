@@ -3,6 +3,7 @@
 // Routes:
 //
 //	GET    /health              — health check (provided by go-http scaffold)
+//	GET    /metrics             — Prometheus metrics (provided by go-http scaffold)
 //	GET    /api/guilds          — list tracked guilds
 //	GET    /api/unread          — unread messages with priority scoring
 //	GET    /api/status          — service status (uptime, selfbot connected, etc.)
@@ -45,11 +46,11 @@ type Config struct {
 
 // New creates a discord-monitor HTTP server with all routes registered.
 // Uses the go-http scaffold for standard middleware (logging, recovery,
-// CORS, graceful shutdown) and the /health endpoint.
+// CORS, graceful shutdown) and the /health and /metrics endpoints.
 func New(cfg Config) *gohttp.Server {
 	startTime = time.Now()
 
-	srv := gohttp.New()
+	srv := gohttp.New("discord_monitor")
 
 	h := &handlers{
 		db:               cfg.DB,
@@ -0,0 +1,101 @@
+// nexus-gateway - public entry point in front of the nexus services
+// Copyright (C) 2026  nexus contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/jredh-dev/nexus/internal/health"
+	"github.com/jredh-dev/nexus/internal/httpserver"
+	"github.com/jredh-dev/nexus/internal/logging"
+	"github.com/jredh-dev/nexus/internal/metrics"
+	"github.com/jredh-dev/nexus/internal/ratelimit"
+	"github.com/jredh-dev/nexus/services/gateway/config"
+	"github.com/jredh-dev/nexus/services/gateway/internal/proxy"
+)
+
+var (
+	version   = "dev"
+	commit    = "unknown"
+	buildDate = "unknown"
+)
+
+func main() {
+	showVersion := flag.Bool("version", false, "Show version information")
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Printf("nexus-gateway %s\n", version)
+		fmt.Printf("Commit: %s\n", commit)
+		fmt.Printf("Built: %s\n", buildDate)
+		os.Exit(0)
+	}
+
+	if err := logging.Setup("nexus-gateway"); err != nil {
+		fmt.Fprintf(os.Stderr, "nexus-gateway: %v\n", err)
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nexus-gateway: %v\n", err)
+		os.Exit(1)
+	}
+	slog.Info("config", "settings", cfg.Settings)
+
+	// Requests by route come from the common metrics, whose route label is
+	// the prefix each backend is mounted at ("/cal/*").
+	m := metrics.New("gateway")
+	upstreamErrors := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "gateway",
+		Name:      "upstream_errors_total",
+		Help:      "Requests that could not be forwarded to their backend, by route.",
+	}, []string{"route"})
+	rateLimited := ratelimit.NewCounter("gateway")
+	m.MustRegister(upstreamErrors, rateLimited)
+
+	limits, err := ratelimit.Open(cfg.RateLimit.DB)
+	if err != nil {
+		logging.Fatal("open rate limit store", "path", cfg.RateLimit.DB, "err", err)
+	}
+	srv := httpserver.New(
+		httpserver.WithMetrics(m),
+		// A backend being down takes its routes down, not the gateway, so
+		// readiness doesn't depend on them.
+		httpserver.WithHealth(health.New()),
+		httpserver.WithVersion(httpserver.Version{Service: "nexus-gateway", Version: version, Commit: commit, Built: buildDate}),
+		httpserver.WithTLS(cfg.TLSCert, cfg.TLSKey),
+		httpserver.WithCloser("rate limits", limits),
+		// Client IPs, which the limit keys on and the backends are told,
+		// come from X-Forwarded-For only as GATEWAY_TRUSTED_PROXIES record it.
+		httpserver.WithTrustedProxies(cfg.RateLimit.TrustedProxies),
+	)
+
+	limiter := ratelimit.New(limits, cfg.RateLimit.Limits, rateLimited)
+	srv.Router.Group(func(r chi.Router) {
+		r.Use(limiter.Limit("gateway", ratelimit.ByIP))
+		for _, rt := range cfg.Routes {
+			r.Mount(rt.Prefix, proxy.New(rt.Prefix, rt.Backend, upstreamErrors.WithLabelValues(rt.Prefix)))
+			slog.Info("route", "prefix", rt.Prefix, "backend", rt.Backend.String())
+		}
+	})
+
+	addr := ":" + cfg.Port
+	slog.Info("nexus-gateway starting", "addr", addr, "version", version, "tls", cfg.TLSCert != "")
+
+	if err := srv.ListenAndServe(addr); err != nil {
+		logging.Fatal("server", "err", err)
+	}
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"net/url"
+
+	"github.com/jredh-dev/nexus/internal/ratelimit"
+	"github.com/jredh-dev/nexus/internal/settings"
+)
+
+// Config holds all configuration for the gateway.
+type Config struct {
+	Port string
+
+	// TLS certificate and key files; the gateway serves plain HTTP unless
+	// both are set.
+	TLSCert string
+	TLSKey  string
+
+	// RateLimit is the "gateway" limit each client IP gets, and the load
+	// balancers in front, if any, whose X-Forwarded-For names the client.
+	RateLimit ratelimit.Config
+
+	Routes []Route
+
+	Settings settings.Values // everything above as loaded, for logging at boot
+}
+
+// Route sends requests under Prefix to Backend, without the prefix.
+type Route struct {
+	Prefix  string
+	Backend *url.URL
+}
+
+// Load reads configuration from environment variables, and from the YAML
+// file GATEWAY_CONFIG_FILE names if set, with sensible defaults.
+func Load() (*Config, error) {
+	l := settings.New("GATEWAY_CONFIG_FILE")
+	cfg := &Config{
+		Port:    l.String("GATEWAY_PORT", "8000"),
+		TLSCert: l.String("GATEWAY_TLS_CERT", ""),
+		TLSKey:  l.String("GATEWAY_TLS_KEY", ""),
+	}
+	cfg.RateLimit = ratelimit.LoadConfig(l, "GATEWAY_", ratelimit.Limits{
+		"gateway": {Limit: 20, Burst: 40},
+	})
+	l.Check((cfg.TLSCert == "") == (cfg.TLSKey == ""),
+		"TLS needs GATEWAY_TLS_CERT and GATEWAY_TLS_KEY together")
+
+	// An empty backend URL leaves its route out.
+	for _, r := range []struct{ prefix, key, def string }{
+		{"/portal", "GATEWAY_PORTAL_URL", "http://localhost:8080"},
+		{"/cal", "GATEWAY_CAL_URL", "http://localhost:8085"},
+		{"/secrets", "GATEWAY_SECRETS_URL", "http://localhost:8081"},
+		{"/notify", "GATEWAY_NOTIFY_URL", "http://localhost:8088"},
+		{"/hermit-http", "GATEWAY_HERMIT_HTTP_URL", ""},
+	} {
+		v := l.String(r.key, r.def)
+		if v == "" {
+			continue
+		}
+		u, err := url.Parse(v)
+		l.Check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"%s=%q: want an http:// or https:// URL", r.key, v)
+		if err == nil {
+			cfg.Routes = append(cfg.Routes, Route{Prefix: r.prefix, Backend: u})
+		}
+	}
+
+	cfg.Settings = l.Values()
+	return cfg, l.Err()
+}
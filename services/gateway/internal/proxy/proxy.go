@@ -0,0 +1,117 @@
+// Package proxy forwards the gateway's requests to the backend services.
+package proxy
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/jredh-dev/nexus/internal/logging"
+)
+
+// New returns a handler, to be mounted at prefix, that forwards requests
+// to backend without the prefix: /cal/api/feeds becomes /api/feeds.
+//
+// Credentials pass through untouched: the Authorization header and
+// cookies reach the backend as the client sent them. The backend also
+// gets the client's address in X-Forwarded-For and X-Real-IP (whatever
+// the client sent in those is dropped), the public host and scheme in
+// X-Forwarded-Host and X-Forwarded-Proto, the prefix in
+// X-Forwarded-Prefix, and the gateway's request ID in X-Request-Id, so
+// its logs line up with the gateway's.
+//
+// Redirects and cookie paths coming back are moved under the prefix,
+// except cookies for the whole site (Path=/): those stay site-wide, so the
+// portal's session cookie reaches every backend behind the gateway and a
+// portal login signs the client in to all of them (see internal/sso).
+// Requests that can't be forwarded get a 502 and are counted in errs,
+// which may be nil.
+func New(prefix string, backend *url.URL, errs prometheus.Counter) http.Handler {
+	return &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.Out.URL.Path = strip(pr.In.URL.Path, prefix)
+			pr.Out.URL.RawPath = ""
+			if pr.In.URL.RawPath != "" {
+				pr.Out.URL.RawPath = strip(pr.In.URL.RawPath, prefix)
+			}
+			pr.SetURL(backend)
+			pr.SetXForwarded()
+
+			h := pr.Out.Header
+			h.Del("True-Client-IP")
+			if ip, _, err := net.SplitHostPort(pr.In.RemoteAddr); err == nil {
+				h.Set("X-Real-IP", ip)
+			}
+			h.Set("X-Forwarded-Prefix", prefix)
+			if id := middleware.GetReqID(pr.In.Context()); id != "" {
+				h.Set("X-Request-Id", id)
+			}
+		},
+		ModifyResponse: func(resp *http.Response) error {
+			if loc := resp.Header.Get("Location"); loc != "" {
+				resp.Header.Set("Location", location(loc, prefix, backend))
+			}
+			cookies := resp.Header.Values("Set-Cookie")
+			for i, sc := range cookies {
+				c, err := http.ParseSetCookie(sc)
+				if err != nil || !strings.HasPrefix(c.Path, "/") || c.Path == "/" {
+					continue
+				}
+				c.Path = prefix + c.Path
+				cookies[i] = c.String()
+			}
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			if errors.Is(err, context.Canceled) {
+				// The client went away; nothing is wrong upstream.
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			logging.FromContext(r.Context()).Warn("upstream request failed",
+				"route", prefix, "backend", backend.String(), "err", err)
+			if errs != nil {
+				errs.Inc()
+			}
+			http.Error(w, "bad gateway", http.StatusBadGateway)
+		},
+	}
+}
+
+// strip removes prefix from the front of path, leaving at least "/".
+func strip(path, prefix string) string {
+	path = strings.TrimPrefix(path, prefix)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return path
+}
+
+// location rewrites a redirect the backend sent, either root-relative or
+// absolute to the backend itself, to the same place under prefix.
+// Redirects elsewhere are left alone.
+func location(loc, prefix string, backend *url.URL) string {
+	u, err := url.Parse(loc)
+	if err != nil {
+		return loc
+	}
+	switch {
+	case u.Scheme == "" && u.Host == "" && strings.HasPrefix(u.Path, "/"):
+	case u.Host == backend.Host && u.Scheme == backend.Scheme:
+		u.Scheme, u.Host = "", ""
+	default:
+		return loc
+	}
+	u.Path = prefix + u.Path
+	if u.RawPath != "" {
+		u.RawPath = prefix + u.RawPath
+	}
+	return u.String()
+}
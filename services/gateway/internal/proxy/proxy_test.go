@@ -0,0 +1,113 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestForward(t *testing.T) {
+	var got *http.Request
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "s", Path: "/"})
+		http.SetCookie(w, &http.Cookie{Name: "feed", Value: "f", Path: "/api"})
+		http.Redirect(w, r, "/login?next=%2Fapi", http.StatusFound)
+	}))
+	defer backend.Close()
+	u, _ := url.Parse(backend.URL)
+
+	r := chi.NewRouter()
+	r.Use(middleware.RequestID)
+	r.Mount("/cal", New("/cal", u, nil))
+
+	req := httptest.NewRequest(http.MethodGet, "/cal/api/feeds?limit=5", nil)
+	req.Header.Set("Authorization", "Bearer key")
+	req.Header.Set("X-Forwarded-For", "203.0.113.9")
+	req.Header.Set("True-Client-IP", "203.0.113.9")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if got == nil {
+		t.Fatalf("backend not reached: %d %s", rec.Code, rec.Body.String())
+	}
+	if got.URL.Path != "/api/feeds" || got.URL.RawQuery != "limit=5" {
+		t.Errorf("backend got %s, want /api/feeds?limit=5", got.URL)
+	}
+	for h, want := range map[string]string{
+		"Authorization":      "Bearer key",
+		"X-Forwarded-For":    "192.0.2.1",
+		"X-Real-IP":          "192.0.2.1",
+		"True-Client-IP":     "",
+		"X-Forwarded-Host":   "example.com",
+		"X-Forwarded-Prefix": "/cal",
+	} {
+		if v := got.Header.Get(h); v != want {
+			t.Errorf("backend %s: %q, want %q", h, v, want)
+		}
+	}
+	if got.Header.Get("X-Request-Id") == "" {
+		t.Error("backend got no X-Request-Id")
+	}
+
+	if loc := rec.Header().Get("Location"); loc != "/cal/login?next=%2Fapi" {
+		t.Errorf("Location %q, want /cal/login?next=%%2Fapi", loc)
+	}
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 2 || cookies[0].Path != "/" || cookies[1].Path != "/cal/api" {
+		t.Errorf("cookies %v, want paths / and /cal/api", cookies)
+	}
+}
+
+func TestBarePrefix(t *testing.T) {
+	var path string
+	backend := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) { path = r.URL.Path }))
+	defer backend.Close()
+	u, _ := url.Parse(backend.URL)
+
+	r := chi.NewRouter()
+	r.Mount("/secrets", New("/secrets", u, nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/secrets", nil))
+	if path != "/" {
+		t.Errorf("/secrets reached the backend as %q, want /", path)
+	}
+}
+
+func TestLocation(t *testing.T) {
+	backend, _ := url.Parse("http://localhost:8080")
+	for loc, want := range map[string]string{
+		"/dashboard":                    "/portal/dashboard",
+		"http://localhost:8080/login":   "/portal/login",
+		"https://accounts.google.com/o": "https://accounts.google.com/o",
+		"relative":                      "relative",
+		"//evil.example/phish":          "//evil.example/phish",
+	} {
+		if got := location(loc, "/portal", backend); got != want {
+			t.Errorf("location(%q) = %q, want %q", loc, got, want)
+		}
+	}
+}
+
+func TestUpstreamDown(t *testing.T) {
+	backend := httptest.NewServer(http.NotFoundHandler())
+	u, _ := url.Parse(backend.URL)
+	backend.Close()
+
+	errs := prometheus.NewCounter(prometheus.CounterOpts{Name: "errs"})
+	rec := httptest.NewRecorder()
+	New("/cal", u, errs).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/cal/api", nil))
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("backend down: %d, want 502", rec.Code)
+	}
+	var c dto.Metric
+	errs.Write(&c) //nolint:errcheck
+	if n := c.GetCounter().GetValue(); n != 1 {
+		t.Errorf("upstream errors: %v, want 1", n)
+	}
+}
@@ -1,22 +1,50 @@
 // Package config provides a minimal config loader for go-http services.
 package config
 
-import "os"
+import (
+	"github.com/jredh-dev/nexus/internal/ratelimit"
+	"github.com/jredh-dev/nexus/internal/settings"
+)
 
 // Config holds service configuration.
 type Config struct {
 	Port string
+
+	// Portal sign-in, for services that accept it (see internal/sso):
+	// where the portal's /api/auth/introspect is and its SSO_INTROSPECT_KEY
+	// to ask it with, and its SSO_SECRET to check its JWTs locally. All are
+	// optional.
+	SSOPortalURL     string
+	SSOIntrospectKey string
+	SSOSecret        string
+
+	// Kafka brokers of the event bus (see internal/events). Without them
+	// events stay inside the process.
+	EventBrokers []string
+
+	// RateLimit limits the service's public routes, which it names with
+	// their default rates when loading.
+	RateLimit ratelimit.Config
+
+	Settings settings.Values // everything above as loaded, for logging at boot
 }
 
-// Load reads config from environment variables with sensible defaults.
-// PORT (Cloud Run standard) is checked first, then SERVICE_PORT.
-func Load() *Config {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = os.Getenv("SERVICE_PORT")
+// Load reads config from environment variables, and from the YAML file
+// SERVICE_CONFIG_FILE names if set, with sensible defaults.
+// PORT (Cloud Run standard) is checked first, then SERVICE_PORT. limits
+// are the rates of the service's rate limited routes, if it has any.
+func Load(limits ratelimit.Limits) (*Config, error) {
+	l := settings.New("SERVICE_CONFIG_FILE")
+	cfg := &Config{
+		Port:             l.String("PORT", l.String("SERVICE_PORT", "8080")),
+		SSOPortalURL:     l.String("SSO_PORTAL_URL", ""),
+		SSOIntrospectKey: l.Secret("SSO_INTROSPECT_KEY", ""),
+		SSOSecret:        l.Secret("SSO_SECRET", ""),
+		EventBrokers:     l.List("EVENTS_KAFKA_BROKERS"),
 	}
-	if port == "" {
-		port = "8080"
+	if len(limits) > 0 {
+		cfg.RateLimit = ratelimit.LoadConfig(l, "", limits)
 	}
-	return &Config{Port: port}
+	cfg.Settings = l.Values()
+	return cfg, l.Err()
 }
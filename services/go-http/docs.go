@@ -2,7 +2,7 @@ package gohttp
 
 import (
 	"html/template"
-	"log"
+	"log/slog"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -43,8 +43,7 @@ func EnableDocs(r chi.Router, swaggerJSON []byte) {
 	// different port.
 	r.Get("/docs-all", DocsIndex(NexusServices))
 
-	log.Println("[gohttp] Swagger UI enabled at /docs")
-	log.Println("[gohttp] All-services index at /docs-all")
+	slog.Info("swagger UI enabled", "docs", "/docs", "index", "/docs-all")
 }
 
 // NexusServices lists all nexus HTTP services and their local dev
@@ -99,7 +98,7 @@ func DocsIndex(services []ServiceLink) http.HandlerFunc {
 	return func(w http.ResponseWriter, _ *http.Request) {
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
 		if err := docsIndexTmpl.Execute(w, map[string]any{"Services": services}); err != nil {
-			log.Printf("[gohttp] docs index template: %v", err)
+			slog.Error("docs index template", "err", err)
 		}
 	}
 }
@@ -2,7 +2,7 @@ package gohttp
 
 import (
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
 )
 
@@ -13,7 +13,7 @@ func WriteJSON(w http.ResponseWriter, status int, v any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	if err := json.NewEncoder(w).Encode(v); err != nil {
-		log.Printf("[gohttp] encode JSON response: %v", err)
+		slog.Error("encode JSON response", "err", err)
 	}
 }
 
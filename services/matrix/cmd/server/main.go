@@ -14,9 +14,10 @@ package main
 import (
 	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 
+	"github.com/jredh-dev/nexus/internal/logging"
 	gohttp "github.com/jredh-dev/nexus/services/go-http"
 	"github.com/jredh-dev/nexus/services/go-http/config"
 	"github.com/jredh-dev/nexus/services/matrix/internal/page"
@@ -39,21 +40,31 @@ func main() {
 		os.Exit(0)
 	}
 
-	cfg := config.Load()
+	if err := logging.Setup("nexus-matrix"); err != nil {
+		fmt.Fprintf(os.Stderr, "nexus-matrix: %v\n", err)
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load(nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nexus-matrix: %v\n", err)
+		os.Exit(1)
+	}
+	slog.Info("config", "settings", cfg.Settings)
 	pageCfg := page.ConfigFromEnv()
 
-	srv := gohttp.New()
+	srv := gohttp.New("matrix")
 
 	// Single route: render the live dashboard.
 	srv.Router.Get("/", page.Handler(pageCfg))
 
 	addr := ":" + cfg.Port
-	log.Printf("nexus-matrix starting on %s", addr)
-	log.Printf("  Dashboard: http://localhost%s/", addr)
-	log.Printf("  Gatus:     %s", pageCfg.GatusURL)
-	log.Printf("  Gitea:     %s", pageCfg.GiteaURL)
+	slog.Info("nexus-matrix starting", "addr", addr, "version", version,
+		"dashboard", "http://localhost"+addr+"/",
+		"gatus", pageCfg.GatusURL,
+		"gitea", pageCfg.GiteaURL)
 
 	if err := srv.ListenAndServe(addr); err != nil {
-		log.Fatalf("Server error: %v", err)
+		logging.Fatal("server", "err", err)
 	}
 }
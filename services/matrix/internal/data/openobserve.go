@@ -8,9 +8,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"time"
+
+	"github.com/jredh-dev/nexus/internal/logging"
 )
 
 // ooSearchRequest is the request body for the OpenObserve search API.
@@ -58,14 +59,14 @@ func FetchLogCounts(ctx context.Context, baseURL, user, pass string) map[string]
 		},
 	})
 	if err != nil {
-		log.Printf("openobserve: marshal: %v", err)
+		logging.FromContext(ctx).Error("openobserve: marshal query", "err", err)
 		return counts
 	}
 
 	url := fmt.Sprintf("%s/api/default/_search?type=logs", baseURL)
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
 	if err != nil {
-		log.Printf("openobserve: build request: %v", err)
+		logging.FromContext(ctx).Error("openobserve: build request", "err", err)
 		return counts
 	}
 	req.Header.Set("Content-Type", "application/json")
@@ -73,20 +74,20 @@ func FetchLogCounts(ctx context.Context, baseURL, user, pass string) map[string]
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		log.Printf("openobserve: request: %v", err)
+		logging.FromContext(ctx).Warn("openobserve: request", "err", err)
 		return counts
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		b, _ := io.ReadAll(resp.Body)
-		log.Printf("openobserve: status %d: %s", resp.StatusCode, b)
+		logging.FromContext(ctx).Warn("openobserve: unexpected status", "status", resp.StatusCode, "body", string(b))
 		return counts
 	}
 
 	var result ooSearchResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		log.Printf("openobserve: decode: %v", err)
+		logging.FromContext(ctx).Warn("openobserve: decode response", "err", err)
 		return counts
 	}
 
@@ -5,13 +5,14 @@ import (
 	_ "embed"
 	"fmt"
 	"html/template"
-	"log"
 	"net/http"
 	"os"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/jredh-dev/nexus/internal/logging"
+	"github.com/jredh-dev/nexus/internal/secretsmgr"
 	"github.com/jredh-dev/nexus/services/matrix/internal/data"
 )
 
@@ -120,8 +121,8 @@ func ConfigFromEnv() Config {
 	return Config{
 		GatusURL:              envOr("GATUS_URL", "http://host.docker.internal:8084"),
 		GiteaURL:              envOr("GITEA_URL", "http://host.docker.internal:3000"),
-		GiteaToken:            os.Getenv("GITEA_TOKEN"),
-		GitHubToken:           os.Getenv("GITHUB_TOKEN"),
+		GiteaToken:            secretsmgr.Get("GITEA_TOKEN"),
+		GitHubToken:           secretsmgr.Get("GITHUB_TOKEN"),
 		GitHubOwner:           envOr("GITHUB_OWNER", "jredh-dev"),
 		GitHubRepo:            envOr("GITHUB_REPO", "nexus"),
 		GiteaOwner:            envOr("GITEA_OWNER", "jredh-dev"),
@@ -215,14 +216,14 @@ func Handler(cfg Config) http.HandlerFunc {
 		}()
 
 		wg.Wait()
-		log.Printf("matrix: data fetched in %s", time.Since(start).Round(time.Millisecond))
+		logging.FromContext(r.Context()).Info("data fetched", "took", time.Since(start).Round(time.Millisecond))
 
 		pd := buildPageData(cfg, gatusData, ghNexus, ghCtl, giteaNexus, giteaCtl, logCounts, errors)
 
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
 		w.Header().Set("Cache-Control", "public, max-age=10")
 		if err := tmpl.Execute(w, pd); err != nil {
-			log.Printf("matrix: template error: %v", err)
+			logging.FromContext(r.Context()).Error("render template", "err", err)
 		}
 	}
 }
@@ -6,7 +6,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"sync"
 	"sync/atomic"
@@ -17,10 +16,9 @@ import (
 // GET  /mcp — SSE stream for server-initiated messages
 // GET  /health — health check
 func ServeHTTP(s *Server, addr string) error {
-	logger := log.New(log.Default().Writer(), "", log.LstdFlags)
-	logger.Printf("[mcp] starting HTTP transport on %s", addr)
+	s.logger.Info("mcp: starting HTTP transport", "addr", addr)
 
-	h := &httpHandler{server: s, logger: logger}
+	h := &httpHandler{server: s}
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("POST /mcp", h.handlePost)
@@ -33,7 +31,6 @@ func ServeHTTP(s *Server, addr string) error {
 
 type httpHandler struct {
 	server    *Server
-	logger    *log.Logger
 	sessionID atomic.Int64
 	mu        sync.RWMutex
 	sessions  map[int64]*sseSession
@@ -3,7 +3,7 @@ package mcp
 import (
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"sync"
 )
 
@@ -26,13 +26,13 @@ type Server struct {
 	handlers     map[string]ToolHandler
 	initialized  bool
 	instructions string
-	logger       *log.Logger
+	logger       *slog.Logger
 }
 
 // NewServer creates a new MCP server with the given logger and system instructions.
-func NewServer(logger *log.Logger, instructions string) *Server {
+func NewServer(logger *slog.Logger, instructions string) *Server {
 	if logger == nil {
-		logger = log.Default()
+		logger = slog.Default()
 	}
 	return &Server{
 		tools:        make(map[string]Tool),
@@ -62,7 +62,7 @@ func (s *Server) HandleRequest(raw []byte) []byte {
 		return s.errorResponse(req.ID, ErrCodeInvalidReq, "invalid jsonrpc version", nil)
 	}
 
-	s.logger.Printf("[mcp] method=%s id=%s", req.Method, string(req.ID))
+	s.logger.Info("mcp: request", "method", req.Method, "id", string(req.ID))
 
 	var result any
 	var rpcErr *RPCError
@@ -106,7 +106,7 @@ func (s *Server) handleInitialize(params json.RawMessage) (any, *RPCError) {
 	s.initialized = true
 	s.mu.Unlock()
 
-	s.logger.Printf("[mcp] client=%s/%s protocol=%s", p.ClientInfo.Name, p.ClientInfo.Version, p.ProtocolVersion)
+	s.logger.Info("mcp: client initialized", "client", p.ClientInfo.Name+"/"+p.ClientInfo.Version, "protocol", p.ProtocolVersion)
 
 	return InitializeResult{
 		ProtocolVersion: ProtocolVersion,
@@ -144,7 +144,7 @@ func (s *Server) handleToolCall(params json.RawMessage) (any, *RPCError) {
 		}, nil
 	}
 
-	s.logger.Printf("[mcp] tool=%s", p.Name)
+	s.logger.Info("mcp: tool call", "tool", p.Name)
 	result, err := handler(p.Arguments)
 	if err != nil {
 		return &ToolCallResult{
@@ -158,7 +158,7 @@ func (s *Server) handleToolCall(params json.RawMessage) (any, *RPCError) {
 func (s *Server) successResponse(id json.RawMessage, result any) []byte {
 	data, err := json.Marshal(Response{JSONRPC: "2.0", ID: id, Result: result})
 	if err != nil {
-		s.logger.Printf("[mcp] marshal error: %v", err)
+		s.logger.Error("mcp: marshal result", "err", err)
 		return s.errorResponse(id, ErrCodeInternal, "marshal error", nil)
 	}
 	return data
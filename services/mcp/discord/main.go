@@ -10,9 +10,10 @@ package main
 import (
 	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 
+	"github.com/jredh-dev/nexus/internal/logging"
 	"github.com/jredh-dev/nexus/services/mcp/discord/internal/mcp"
 	"github.com/jredh-dev/nexus/services/mcp/discord/internal/tools"
 )
@@ -49,22 +50,25 @@ func run() int {
 		return 1
 	}
 
-	logger := log.New(os.Stderr, "", log.LstdFlags)
+	if err := logging.Setup("discord-mcp"); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
 
 	if os.Getenv("DISCORD_WEBHOOK_URL") == "" {
-		logger.Println("[warn] DISCORD_WEBHOOK_URL not set — notifications will be skipped")
+		slog.Warn("DISCORD_WEBHOOK_URL not set; notifications will be skipped")
 	}
 	if os.Getenv("DISCORD_ENABLED") != "true" {
-		logger.Println("[warn] DISCORD_ENABLED != true — notifications are disabled")
+		slog.Warn("DISCORD_ENABLED != true; notifications are disabled")
 	}
 
 	mcp.Version = version
-	srv := mcp.NewServer(logger, instructions)
+	srv := mcp.NewServer(slog.Default(), instructions)
 	tools.RegisterAll(srv)
 
-	logger.Printf("[discord-mcp] version=%s addr=%s", version, *addr)
+	slog.Info("discord-mcp starting", "version", version, "addr", *addr)
 	if err := mcp.ServeHTTP(srv, *addr); err != nil {
-		logger.Printf("[discord-mcp] fatal: %v", err)
+		slog.Error("server", "err", err)
 		return 1
 	}
 	return 0
@@ -9,9 +9,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 	"strings"
 	"time"
+
+	"github.com/jredh-dev/nexus/internal/secretsmgr"
 )
 
 const apiBase = "https://api.github.com"
@@ -22,10 +23,10 @@ type Client struct {
 	http  *http.Client
 }
 
-// NewClientFromEnv creates a Client using GITHUB_TOKEN from the environment.
-// Returns an error if the token is not set.
+// NewClientFromEnv creates a Client using GITHUB_TOKEN, looked up through
+// secretsmgr. Returns an error if the token is not set.
 func NewClientFromEnv() (*Client, error) {
-	token := os.Getenv("GITHUB_TOKEN")
+	token := secretsmgr.Get("GITHUB_TOKEN")
 	if token == "" {
 		return nil, fmt.Errorf("GITHUB_TOKEN not set")
 	}
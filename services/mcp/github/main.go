@@ -10,9 +10,11 @@ package main
 import (
 	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 
+	"github.com/jredh-dev/nexus/internal/logging"
+	"github.com/jredh-dev/nexus/internal/secretsmgr"
 	"github.com/jredh-dev/nexus/services/mcp/github/internal/mcp"
 	"github.com/jredh-dev/nexus/services/mcp/github/internal/tools"
 )
@@ -58,20 +60,23 @@ func run() int {
 		return 1
 	}
 
-	logger := log.New(os.Stderr, "", log.LstdFlags)
+	if err := logging.Setup("github-mcp"); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
 
 	// Warn early if token is missing (tools will fail at call time, not at startup).
-	if os.Getenv("GITHUB_TOKEN") == "" {
-		logger.Println("[warn] GITHUB_TOKEN not set — all tool calls will fail")
+	if secretsmgr.Get("GITHUB_TOKEN") == "" {
+		slog.Warn("GITHUB_TOKEN not set; all tool calls will fail")
 	}
 
 	mcp.Version = version
-	srv := mcp.NewServer(logger, instructions)
+	srv := mcp.NewServer(slog.Default(), instructions)
 	tools.RegisterAll(srv)
 
-	logger.Printf("[github-mcp] version=%s addr=%s", version, *addr)
+	slog.Info("github-mcp starting", "version", version, "addr", *addr)
 	if err := mcp.ServeHTTP(srv, *addr); err != nil {
-		logger.Printf("[github-mcp] fatal: %v", err)
+		slog.Error("server", "err", err)
 		return 1
 	}
 	return 0
@@ -0,0 +1,132 @@
+// nexus-notify - notifications for the domain events of the nexus services
+// Copyright (C) 2026  nexus contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/jredh-dev/nexus/internal/emailoutbox"
+	"github.com/jredh-dev/nexus/internal/events"
+	"github.com/jredh-dev/nexus/internal/health"
+	"github.com/jredh-dev/nexus/internal/httpserver"
+	"github.com/jredh-dev/nexus/internal/logging"
+	"github.com/jredh-dev/nexus/internal/metrics"
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
+	"github.com/jredh-dev/nexus/internal/sso"
+	"github.com/jredh-dev/nexus/services/notify/config"
+	"github.com/jredh-dev/nexus/services/notify/internal/handlers"
+	"github.com/jredh-dev/nexus/services/notify/internal/notifier"
+	"github.com/jredh-dev/nexus/services/notify/internal/prefs"
+)
+
+var (
+	version   = "dev"
+	commit    = "unknown"
+	buildDate = "unknown"
+)
+
+func main() {
+	showVersion := flag.Bool("version", false, "Show version information")
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Printf("nexus-notify %s\n", version)
+		fmt.Printf("Commit: %s\n", commit)
+		fmt.Printf("Built: %s\n", buildDate)
+		os.Exit(0)
+	}
+
+	if err := logging.Setup("nexus-notify"); err != nil {
+		fmt.Fprintf(os.Stderr, "nexus-notify: %v\n", err)
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nexus-notify: %v\n", err)
+		os.Exit(1)
+	}
+	slog.Info("config", "settings", cfg.Settings)
+
+	store, err := prefs.Open(cfg.DBPath)
+	if err != nil {
+		logging.Fatal("open database", "path", cfg.DBPath, "err", err)
+	}
+	defer store.Close()
+
+	// Email and SMS go to sms-sender over Kafka; without it only webhooks
+	// are sent.
+	var (
+		email emailoutbox.Publisher
+		sms   smsoutbox.Publisher
+	)
+	if len(cfg.Brokers) > 0 {
+		ep := emailoutbox.NewKafkaPublisher(cfg.Brokers, cfg.EmailTopic)
+		defer ep.Close()
+		sp := smsoutbox.NewKafkaPublisher(cfg.Brokers, cfg.SMSTopic)
+		defer sp.Close()
+		email, sms = ep, sp
+	} else {
+		slog.Warn("no Kafka brokers; email and SMS notifications are off", "hint", "set NOTIFY_KAFKA_BROKERS")
+	}
+	var opts []notifier.Option
+	if cfg.PrivateWebhooks {
+		opts = append(opts, notifier.WithPrivateWebhooks())
+	}
+	n := notifier.New(store, email, sms, opts...)
+
+	// Consumers stop with the server.
+	ctx, stopConsumers := context.WithCancel(context.Background())
+	defer stopConsumers()
+	bus := events.Open("notify", cfg.Brokers)
+	defer bus.Close()
+	n.Subscribe(ctx, bus, "notify")
+
+	checks := health.New()
+	checks.Ready("database", store.Ping)
+	checks.Ready("disk", health.DiskSpace(filepath.Dir(cfg.DBPath), 100<<20))
+	if len(cfg.Brokers) > 0 {
+		checks.Ready("kafka", health.Kafka(cfg.Brokers))
+	}
+
+	srv := httpserver.New(
+		httpserver.WithMetrics(metrics.New("notify")),
+		httpserver.WithHealth(checks),
+		httpserver.WithVersion(httpserver.Version{Service: "nexus-notify", Version: version, Commit: commit, Built: buildDate}),
+		httpserver.WithShutdownHook(stopConsumers),
+	)
+
+	// Preferences API. Users sign in to the portal; nobody else has any.
+	sv := sso.NewVerifier(cfg.SSOPortalURL, cfg.SSOIntrospectKey, []byte(cfg.SSOSecret))
+	if sv == nil {
+		slog.Warn("portal sign-in is not configured; nobody can set preferences", "hint", "set NOTIFY_SSO_PORTAL_URL or NOTIFY_SSO_SECRET")
+	}
+	h := handlers.New(store)
+	srv.Router.Route("/api/preferences", func(r chi.Router) {
+		r.Use(sv.Middleware)
+		r.Get("/", h.Get)
+		r.Put("/", h.Put)
+		r.Delete("/", h.Delete)
+	})
+
+	addr := ":" + cfg.Port
+	slog.Info("nexus-notify starting", "addr", addr, "version", version,
+		"api", "http://localhost"+addr+"/api/preferences")
+
+	if err := srv.ListenAndServe(addr); err != nil {
+		logging.Fatal("server", "err", err)
+	}
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"github.com/jredh-dev/nexus/internal/emailoutbox"
+	"github.com/jredh-dev/nexus/internal/settings"
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
+)
+
+// Config holds all configuration for the notification service.
+type Config struct {
+	Port   string
+	DBPath string
+
+	// Kafka carries both the domain events notify consumes and the email
+	// and SMS it hands to sms-sender. Without brokers the event bus is in
+	// memory, so nothing arrives, and email and SMS are not sent.
+	Brokers    []string
+	EmailTopic string
+	SMSTopic   string
+
+	// PrivateWebhooks lets users' webhooks reach loopback and private
+	// addresses, which they otherwise can't, so that nobody can point one
+	// at the services behind notify. Development needs it.
+	PrivateWebhooks bool
+
+	// Portal sign-in, which is how users reach their preferences (see
+	// internal/sso): where the portal's /api/auth/introspect is and its
+	// SSO_INTROSPECT_KEY to ask it with, and its SSO_SECRET to check its
+	// JWTs locally. Without the URL or the secret, nobody can.
+	SSOPortalURL     string
+	SSOIntrospectKey string
+	SSOSecret        string
+
+	Settings settings.Values // everything above as loaded, for logging at boot
+}
+
+// Load reads configuration from environment variables, and from the YAML
+// file NOTIFY_CONFIG_FILE names if set, with sensible defaults.
+func Load() (*Config, error) {
+	l := settings.New("NOTIFY_CONFIG_FILE")
+	cfg := &Config{
+		Port:             l.String("NOTIFY_PORT", "8088"),
+		DBPath:           l.String("NOTIFY_DB_PATH", "notify.db"),
+		Brokers:          l.List("NOTIFY_KAFKA_BROKERS"),
+		EmailTopic:       l.String("NOTIFY_EMAIL_TOPIC", emailoutbox.Topic),
+		SMSTopic:         l.String("NOTIFY_SMS_TOPIC", smsoutbox.Topic),
+		PrivateWebhooks:  l.OneOf("NOTIFY_PRIVATE_WEBHOOKS", "false", "true", "false") == "true",
+		SSOPortalURL:     l.String("NOTIFY_SSO_PORTAL_URL", ""),
+		SSOIntrospectKey: l.Secret("NOTIFY_SSO_INTROSPECT_KEY", ""),
+		SSOSecret:        l.Secret("NOTIFY_SSO_SECRET", ""),
+	}
+	cfg.Settings = l.Values()
+	return cfg, l.Err()
+}
@@ -0,0 +1,176 @@
+// Package handlers serves the notification preferences API. Users reach it
+// signed in to the portal (see internal/sso) and only ever see their own
+// preferences.
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jredh-dev/nexus/internal/logging"
+	"github.com/jredh-dev/nexus/internal/sso"
+	"github.com/jredh-dev/nexus/services/notify/internal/notifier"
+	"github.com/jredh-dev/nexus/services/notify/internal/prefs"
+)
+
+// emailPattern is a deliberately loose email check; the mail server is the
+// real judge.
+var emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+
+// e164Pattern matches phone numbers in E.164 format, which sms-sender
+// requires.
+var e164Pattern = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// contactFields name the request field each channel's contact is set by.
+var contactFields = map[string]string{prefs.Email: "email", prefs.SMS: "phone", prefs.Webhook: "webhook_url"}
+
+// Handler holds dependencies for HTTP handlers.
+type Handler struct {
+	store *prefs.Store
+}
+
+// New creates a new Handler.
+func New(store *prefs.Store) *Handler {
+	return &Handler{store: store}
+}
+
+// prefsReq is the body of PUT /api/preferences.
+type prefsReq struct {
+	Email         string              `json:"email"`
+	Phone         string              `json:"phone"`
+	WebhookURL    string              `json:"webhook_url"`
+	WebhookSecret string              `json:"webhook_secret"` // kept if omitted and the URL is unchanged
+	Topics        map[string][]string `json:"topics"`
+}
+
+// Get returns the caller's preferences, empty if they have none. The
+// webhook secret is write-only.
+// GET /api/preferences
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	id := sso.FromContext(r.Context())
+	if id == nil {
+		jsonError(w, "sign in to the portal first", http.StatusUnauthorized)
+		return
+	}
+	p, err := h.store.Get(r.Context(), id.UserID)
+	if errors.Is(err, prefs.ErrNotFound) {
+		p = &prefs.Prefs{UserID: id.UserID, Topics: map[string][]string{}}
+	} else if err != nil {
+		logging.FromContext(r.Context()).Error("get preferences", "err", err)
+		jsonError(w, "failed to get preferences", http.StatusInternalServerError)
+		return
+	}
+	p.WebhookSecret = ""
+	jsonOK(w, http.StatusOK, p)
+}
+
+// Put replaces the caller's preferences.
+// PUT /api/preferences
+func (h *Handler) Put(w http.ResponseWriter, r *http.Request) {
+	id := sso.FromContext(r.Context())
+	if id == nil {
+		jsonError(w, "sign in to the portal first", http.StatusUnauthorized)
+		return
+	}
+	var req prefsReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		jsonError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	p, msg := prefsFromRequest(req)
+	if msg != "" {
+		jsonError(w, msg, http.StatusBadRequest)
+		return
+	}
+	p.UserID, p.Username = id.UserID, id.Username
+	p.UpdatedAt = time.Now().UTC()
+
+	if p.WebhookSecret == "" && p.WebhookURL != "" {
+		if old, err := h.store.Get(r.Context(), id.UserID); err == nil && old.WebhookURL == p.WebhookURL {
+			p.WebhookSecret = old.WebhookSecret
+		}
+	}
+	if err := h.store.Put(r.Context(), p); err != nil {
+		logging.FromContext(r.Context()).Error("put preferences", "err", err)
+		jsonError(w, "failed to save preferences", http.StatusInternalServerError)
+		return
+	}
+	p.WebhookSecret = ""
+	jsonOK(w, http.StatusOK, p)
+}
+
+// Delete removes the caller's preferences, turning their notifications off.
+// DELETE /api/preferences
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	id := sso.FromContext(r.Context())
+	if id == nil {
+		jsonError(w, "sign in to the portal first", http.StatusUnauthorized)
+		return
+	}
+	if err := h.store.Delete(r.Context(), id.UserID); err != nil {
+		logging.FromContext(r.Context()).Error("delete preferences", "err", err)
+		jsonError(w, "failed to delete preferences", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// prefsFromRequest validates a preferences request. On failure it returns
+// a client-facing error message.
+func prefsFromRequest(req prefsReq) (*prefs.Prefs, string) {
+	p := &prefs.Prefs{
+		Email:         strings.TrimSpace(req.Email),
+		Phone:         strings.TrimSpace(req.Phone),
+		WebhookURL:    strings.TrimSpace(req.WebhookURL),
+		WebhookSecret: req.WebhookSecret,
+		Topics:        map[string][]string{},
+	}
+	if p.Email != "" && !emailPattern.MatchString(p.Email) {
+		return nil, "email must be an email address"
+	}
+	if p.Phone != "" && !e164Pattern.MatchString(p.Phone) {
+		return nil, "phone must be an E.164 phone number"
+	}
+	if p.WebhookURL != "" {
+		u, err := url.Parse(p.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, "webhook_url must be an http or https URL"
+		}
+	}
+	for topic, chs := range req.Topics {
+		if !slices.Contains(notifier.Topics, topic) {
+			return nil, "unknown topic: " + topic
+		}
+		for _, ch := range chs {
+			field, ok := contactFields[ch]
+			if !ok {
+				return nil, "unknown channel: " + ch
+			}
+			if p.Contact(ch) == "" {
+				return nil, ch + " notifications need " + field + " set"
+			}
+			if !slices.Contains(p.Topics[topic], ch) {
+				p.Topics[topic] = append(p.Topics[topic], ch)
+			}
+		}
+	}
+	return p, ""
+}
+
+func jsonOK(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+func jsonError(w http.ResponseWriter, msg string, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
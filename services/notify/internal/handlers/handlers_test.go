@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jredh-dev/nexus/internal/sso"
+	"github.com/jredh-dev/nexus/services/notify/internal/prefs"
+)
+
+func testHandler(t *testing.T) (*Handler, *prefs.Store) {
+	t.Helper()
+	s, err := prefs.Open(filepath.Join(t.TempDir(), "notify.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return New(s), s
+}
+
+// do serves a request as the portal user alice, or anonymously if signedIn
+// is false.
+func do(h http.HandlerFunc, method, body string, signedIn bool) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, "/api/preferences", strings.NewReader(body))
+	if signedIn {
+		r = r.WithContext(sso.NewContext(r.Context(), &sso.Identity{UserID: "u1", Username: "alice"}))
+	}
+	rec := httptest.NewRecorder()
+	h(rec, r)
+	return rec
+}
+
+func TestRequiresSignIn(t *testing.T) {
+	h, _ := testHandler(t)
+	for _, fn := range []http.HandlerFunc{h.Get, h.Put, h.Delete} {
+		if rec := do(fn, http.MethodGet, "{}", false); rec.Code != http.StatusUnauthorized {
+			t.Errorf("anonymous request: %d, want 401", rec.Code)
+		}
+	}
+}
+
+func TestPutGet(t *testing.T) {
+	h, store := testHandler(t)
+
+	body := `{"email":"alice@example.com","webhook_url":"https://example.com/hook","webhook_secret":"whsec",
+		"topics":{"secret.exposed":["email","webhook"]}}`
+	if rec := do(h.Put, http.MethodPut, body, true); rec.Code != http.StatusOK {
+		t.Fatalf("PUT: %d %s", rec.Code, rec.Body)
+	}
+
+	// Leaving the secret out keeps it while the URL stays the same.
+	body = `{"email":"alice@example.com","webhook_url":"https://example.com/hook","topics":{"event.created":["webhook"]}}`
+	if rec := do(h.Put, http.MethodPut, body, true); rec.Code != http.StatusOK {
+		t.Fatalf("PUT without secret: %d %s", rec.Code, rec.Body)
+	}
+	p, err := store.Get(context.Background(), "u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Username != "alice" || p.WebhookSecret != "whsec" || len(p.Topics) != 1 {
+		t.Errorf("stored %+v", p)
+	}
+
+	rec := do(h.Get, http.MethodGet, "", true)
+	var got prefs.Prefs
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.WebhookSecret != "" {
+		t.Error("GET returned the webhook secret")
+	}
+	if got.Email != "alice@example.com" || len(got.Topics["event.created"]) != 1 {
+		t.Errorf("GET: %+v", got)
+	}
+
+	if rec := do(h.Delete, http.MethodDelete, "", true); rec.Code != http.StatusNoContent {
+		t.Errorf("DELETE: %d", rec.Code)
+	}
+}
+
+func TestPutValidation(t *testing.T) {
+	h, _ := testHandler(t)
+	for _, body := range []string{
+		`{"email":"not-an-email"}`,
+		`{"phone":"555-0100"}`,
+		`{"webhook_url":"ftp://example.com"}`,
+		`{"email":"a@example.com","topics":{"claim.confirmed":["email"]}}`,
+		`{"email":"a@example.com","topics":{"secret.exposed":["pigeon"]}}`,
+		`{"email":"a@example.com","topics":{"secret.exposed":["sms"]}}`,
+	} {
+		if rec := do(h.Put, http.MethodPut, body, true); rec.Code != http.StatusBadRequest {
+			t.Errorf("PUT %s: %d, want 400", body, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+// Package notifier turns domain events into notifications. For each event
+// it works out who should hear about it and what to tell them, looks up
+// how they want to be told, and sends it on each channel they picked:
+// email and SMS through the email-outbox and sms-outbox topics, which
+// sms-sender delivers, and webhooks directly.
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/jredh-dev/nexus/internal/emailoutbox"
+	"github.com/jredh-dev/nexus/internal/events"
+	"github.com/jredh-dev/nexus/internal/logging"
+	"github.com/jredh-dev/nexus/internal/portalhook"
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
+	"github.com/jredh-dev/nexus/services/notify/internal/prefs"
+)
+
+// Source is the Source of the email and SMS notify sends.
+const Source = "notify"
+
+// SignatureHeader carries the signature of a webhook delivery: the hex
+// HMAC-SHA256 of the body keyed with the user's webhook secret, prefixed
+// with "sha256=", as portal webhooks are signed.
+const SignatureHeader = "X-Nexus-Signature"
+
+// Message is a notification for one user.
+type Message struct {
+	Recipient string // portal user ID or username
+	Subject   string
+	Body      string
+}
+
+// Notifier sends notifications the way their recipients asked for.
+type Notifier struct {
+	store  *prefs.Store
+	email  emailoutbox.Publisher // nil when email can't be sent
+	sms    smsoutbox.Publisher   // nil when SMS can't be sent
+	client *http.Client
+	now    func() time.Time
+}
+
+// Option configures a Notifier during construction.
+type Option func(*options)
+
+type options struct {
+	privateWebhooks bool
+}
+
+// WithPrivateWebhooks lets webhooks reach loopback and private addresses.
+// Users choose webhook URLs, so by default they can't be pointed at the
+// services behind notify; development, where everything is on localhost,
+// needs it.
+func WithPrivateWebhooks() Option {
+	return func(o *options) { o.privateWebhooks = true }
+}
+
+// New creates a Notifier that reads preferences from store. A nil email or
+// sms publisher skips that channel.
+func New(store *prefs.Store, email emailoutbox.Publisher, sms smsoutbox.Publisher, opts ...Option) *Notifier {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	if !o.privateWebhooks {
+		dialer.Control = publicOnly
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &Notifier{
+		store:  store,
+		email:  email,
+		sms:    sms,
+		client: &http.Client{Timeout: 10 * time.Second, Transport: transport},
+		now:    time.Now,
+	}
+}
+
+// errPrivateAddress refuses a webhook to an address that isn't public.
+var errPrivateAddress = errors.New("webhook address is not public")
+
+// publicOnly is a dialer Control refusing connections to loopback,
+// private, link-local and unspecified addresses. It runs after name
+// resolution, so a public name resolving to a private address is refused
+// too.
+func publicOnly(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+		return fmt.Errorf("%w: %s", errPrivateAddress, host)
+	}
+	return nil
+}
+
+// Notify sends m about e on each channel its recipient picked for e's
+// topic. A recipient without preferences is told nothing. Channels already
+// sent on are skipped, so when one channel fails, returning the error for
+// the bus to retry doesn't repeat the others.
+func (n *Notifier) Notify(ctx context.Context, e events.Event, m Message) error {
+	p, err := n.store.Lookup(ctx, m.Recipient)
+	if errors.Is(err, prefs.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	log := logging.FromContext(ctx)
+	var errs []error
+	for _, ch := range p.Topics[e.Topic] {
+		done, err := n.store.Delivered(ctx, e.ID, p.UserID, ch)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if done {
+			continue
+		}
+		sent, err := n.send(ctx, ch, p, e, m)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", ch, err))
+			continue
+		}
+		if !sent {
+			log.Debug("notification channel unavailable", "channel", ch, "user_id", p.UserID, "event_id", e.ID)
+			continue
+		}
+		if err := n.store.MarkDelivered(ctx, e.ID, p.UserID, ch, n.now()); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		log.Info("notified", "channel", ch, "user_id", p.UserID, "topic", e.Topic, "event_id", e.ID)
+	}
+	return errors.Join(errs...)
+}
+
+// send sends m on ch. It reports false, with no error, if ch can't reach
+// the user: they gave no contact for it, or the channel is off here.
+func (n *Notifier) send(ctx context.Context, ch string, p *prefs.Prefs, e events.Event, m Message) (bool, error) {
+	to := p.Contact(ch)
+	if to == "" {
+		return false, nil
+	}
+	// IDs are derived from the event's, so a retried send is recognisably
+	// the same message downstream.
+	id := e.ID + "-" + ch
+	switch ch {
+	case prefs.Email:
+		if n.email == nil {
+			return false, nil
+		}
+		return true, n.email.Publish(ctx, emailoutbox.OutboundEmail{
+			ID: id, To: to, Subject: m.Subject, Body: m.Body, Source: Source, CreatedAt: n.now().UTC(),
+		})
+	case prefs.SMS:
+		if n.sms == nil {
+			return false, nil
+		}
+		return true, n.sms.Publish(ctx, smsoutbox.OutboundMessage{
+			ID: id, To: to, Body: m.Subject + ": " + m.Body, Source: Source, CreatedAt: n.now().UTC(),
+		})
+	case prefs.Webhook:
+		return true, n.post(ctx, to, p.WebhookSecret, WebhookPayload{Event: e, Subject: m.Subject, Body: m.Body})
+	}
+	return false, nil
+}
+
+// WebhookPayload is what a webhook is posted: the event, and the
+// notification the other channels would have sent about it.
+type WebhookPayload struct {
+	Event   events.Event `json:"event"`
+	Subject string       `json:"subject"`
+	Body    string       `json:"body"`
+}
+
+// post delivers a webhook. Any non-2xx response is an error.
+func (n *Notifier) post(ctx context.Context, url, secret string, payload WebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode webhook: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if secret != "" {
+		req.Header.Set(SignatureHeader, portalhook.Sign(secret, body))
+	}
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jredh-dev/nexus/internal/emailoutbox"
+	"github.com/jredh-dev/nexus/internal/events"
+	"github.com/jredh-dev/nexus/internal/portalhook"
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
+	"github.com/jredh-dev/nexus/services/notify/internal/prefs"
+)
+
+type fakeEmail struct {
+	mu   sync.Mutex
+	sent []emailoutbox.OutboundEmail
+}
+
+func (p *fakeEmail) Publish(_ context.Context, msg emailoutbox.OutboundEmail) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sent = append(p.sent, msg)
+	return nil
+}
+
+func (p *fakeEmail) count() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.sent)
+}
+
+// fakeSMS fails while err is set.
+type fakeSMS struct {
+	mu   sync.Mutex
+	err  error
+	sent []smsoutbox.OutboundMessage
+}
+
+func (p *fakeSMS) Publish(_ context.Context, msg smsoutbox.OutboundMessage) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	p.sent = append(p.sent, msg)
+	return nil
+}
+
+func testStore(t *testing.T, ps ...*prefs.Prefs) *prefs.Store {
+	t.Helper()
+	s, err := prefs.Open(filepath.Join(t.TempDir(), "notify.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	for _, p := range ps {
+		if err := s.Put(context.Background(), p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s
+}
+
+func TestSubscribe(t *testing.T) {
+	type delivery struct {
+		sig  string
+		body []byte
+	}
+	hooks := make(chan delivery, 1)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		select {
+		case hooks <- delivery{r.Header.Get(SignatureHeader), body}:
+		default: // a later publish; the first is enough
+		}
+	}))
+	defer hook.Close()
+
+	store := testStore(t, &prefs.Prefs{
+		UserID:        "u1",
+		Username:      "alice",
+		Email:         "alice@example.com",
+		WebhookURL:    hook.URL,
+		WebhookSecret: "whsec",
+		Topics:        map[string][]string{"secret.exposed": {prefs.Email, prefs.Webhook}},
+	})
+	email := &fakeEmail{}
+	n := New(store, email, nil, WithPrivateWebhooks())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bus := events.NewMemory("secrets")
+	defer bus.Close()
+	n.Subscribe(ctx, bus, "notify")
+
+	// Secrets credits portal users by username.
+	x := events.SecretExposure{SecretID: "s1", SubmittedBy: "alice", Lens: "case", Count: 2}
+	deadline := time.After(5 * time.Second)
+	for published := false; !published; {
+		// Subscribe starts its consumers in the background; publish until
+		// one is there to see it.
+		if err := events.Publish(ctx, bus, events.SecretExposed, "s1", x); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case d := <-hooks:
+			if !portalhook.Verify("whsec", d.body, d.sig) {
+				t.Errorf("webhook signature %q doesn't verify", d.sig)
+			}
+			var p WebhookPayload
+			if err := json.Unmarshal(d.body, &p); err != nil {
+				t.Fatal(err)
+			}
+			if p.Event.Topic != "secret.exposed" || p.Subject != "Your secret is out" {
+				t.Errorf("webhook payload: %+v", p)
+			}
+			published = true
+		case <-time.After(50 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("no webhook delivery")
+		}
+	}
+	for email.count() == 0 {
+		select {
+		case <-deadline:
+			t.Fatal("no email")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	email.mu.Lock()
+	defer email.mu.Unlock()
+	if m := email.sent[0]; m.To != "alice@example.com" || m.Source != Source {
+		t.Errorf("email: %+v", m)
+	}
+}
+
+func TestNotifyRetriesOnlyFailedChannels(t *testing.T) {
+	ctx := context.Background()
+	store := testStore(t, &prefs.Prefs{
+		UserID: "u1",
+		Email:  "alice@example.com",
+		Phone:  "+15555550100",
+		Topics: map[string][]string{"event.created": {prefs.Email, prefs.SMS}},
+	})
+	email := &fakeEmail{}
+	sms := &fakeSMS{err: errors.New("kafka down")}
+	n := New(store, email, sms)
+
+	e := events.Event{ID: "e1", Topic: "event.created"}
+	m := Message{Recipient: "u1", Subject: "New event: Standup", Body: "Standup is on your calendar."}
+	if err := n.Notify(ctx, e, m); err == nil {
+		t.Fatal("Notify with SMS failing: nil, want an error for the bus to retry")
+	}
+	sms.err = nil
+	if err := n.Notify(ctx, e, m); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Notify(ctx, e, m); err != nil {
+		t.Fatal(err)
+	}
+	if len(email.sent) != 1 || len(sms.sent) != 1 {
+		t.Errorf("sent %d emails and %d texts, want one each", len(email.sent), len(sms.sent))
+	}
+	if sms.sent[0].ID != "e1-sms" {
+		t.Errorf("text ID %q, want it derived from the event's", sms.sent[0].ID)
+	}
+
+	// Users without preferences aren't notified, and that's no error.
+	if err := n.Notify(ctx, e, Message{Recipient: "u2"}); err != nil {
+		t.Errorf("Notify of unknown user: %v", err)
+	}
+}
+
+func TestPrivateWebhooksRefused(t *testing.T) {
+	called := false
+	hook := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { called = true }))
+	defer hook.Close()
+
+	store := testStore(t, &prefs.Prefs{
+		UserID:     "u1",
+		WebhookURL: hook.URL,
+		Topics:     map[string][]string{"event.created": {prefs.Webhook}},
+	})
+	err := New(store, nil, nil).Notify(context.Background(),
+		events.Event{ID: "e1", Topic: "event.created"}, Message{Recipient: "u1"})
+	if !errors.Is(err, errPrivateAddress) {
+		t.Errorf("webhook to %s: %v, want errPrivateAddress", hook.URL, err)
+	}
+	if called {
+		t.Error("webhook reached a loopback address")
+	}
+}
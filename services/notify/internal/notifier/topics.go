@@ -0,0 +1,58 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jredh-dev/nexus/internal/events"
+	"github.com/jredh-dev/nexus/internal/logging"
+)
+
+// Topics are the topics users can be notified of.
+var Topics = []string{events.SecretExposed.Name, events.EventCreated.Name}
+
+// Subscribe notifies users of the events on bus until ctx is done.
+// Deliveries are consumed in group, so several notify replicas share them.
+func (n *Notifier) Subscribe(ctx context.Context, bus events.Bus, group string) {
+	run := func(topic string, sub func() error) {
+		go func() {
+			if err := sub(); err != nil {
+				logging.FromContext(ctx).Error("subscribe", "topic", topic, "err", err)
+			}
+		}()
+	}
+	run(events.SecretExposed.Name, func() error {
+		return events.Subscribe(ctx, bus, events.SecretExposed, group, n.secretExposed)
+	})
+	run(events.EventCreated.Name, func() error {
+		return events.Subscribe(ctx, bus, events.EventCreated, group, n.eventCreated)
+	})
+}
+
+// secretExposed tells whoever first submitted a secret that it is out.
+func (n *Notifier) secretExposed(ctx context.Context, e events.Event, x events.SecretExposure) error {
+	if x.SubmittedBy == "" || x.SubmittedBy == "anonymous" {
+		return nil
+	}
+	body := fmt.Sprintf("Someone else has told the secret you told first; it has been told %d times now.", x.Count)
+	if x.Lens != "" {
+		body = fmt.Sprintf("Someone else has told the secret you told first (%s); it has been told %d times now.", x.Lens, x.Count)
+	}
+	return n.Notify(ctx, e, Message{Recipient: x.SubmittedBy, Subject: "Your secret is out", Body: body})
+}
+
+// eventCreated tells a feed's owner about an event added to it.
+func (n *Notifier) eventCreated(ctx context.Context, e events.Event, x events.CalendarEvent) error {
+	if x.OwnerID == "" {
+		return nil
+	}
+	when := x.Start.Format("Mon Jan 2 2006 15:04 MST")
+	if x.AllDay {
+		when = x.Start.Format("Mon Jan 2 2006")
+	}
+	return n.Notify(ctx, e, Message{
+		Recipient: x.OwnerID,
+		Subject:   "New event: " + x.Summary,
+		Body:      fmt.Sprintf("%s is on your calendar for %s.", x.Summary, when),
+	})
+}
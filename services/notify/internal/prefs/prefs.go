@@ -0,0 +1,223 @@
+// Package prefs keeps what each user wants to be told about and how, in
+// SQLite: their contact details (an email address, a phone number, a
+// webhook) and, per event topic, which of those channels to use. It also
+// records which notifications have gone out, so an event the bus hands over
+// again isn't sent twice.
+package prefs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jredh-dev/nexus/internal/sqlitex"
+)
+
+// Channels.
+const (
+	Email   = "email"
+	SMS     = "sms"
+	Webhook = "webhook"
+)
+
+// ErrNotFound is returned for a user with no preferences.
+var ErrNotFound = errors.New("no preferences")
+
+// Prefs are one user's notification preferences. A user is identified by
+// their portal user ID, and also found by username, since some services
+// credit users by name.
+type Prefs struct {
+	UserID        string              `json:"user_id"`
+	Username      string              `json:"username,omitempty"`
+	Email         string              `json:"email,omitempty"`
+	Phone         string              `json:"phone,omitempty"` // E.164
+	WebhookURL    string              `json:"webhook_url,omitempty"`
+	WebhookSecret string              `json:"webhook_secret,omitempty"` // HMAC key deliveries are signed with
+	Topics        map[string][]string `json:"topics"`                   // topic -> channels
+	UpdatedAt     time.Time           `json:"updated_at"`
+}
+
+// Contact returns where channel reaches the user, or "" if they haven't
+// said.
+func (p *Prefs) Contact(channel string) string {
+	switch channel {
+	case Email:
+		return p.Email
+	case SMS:
+		return p.Phone
+	case Webhook:
+		return p.WebhookURL
+	}
+	return ""
+}
+
+const schema = `
+CREATE TABLE IF NOT EXISTS prefs (
+	user_id        TEXT PRIMARY KEY,
+	username       TEXT NOT NULL DEFAULT '',
+	email          TEXT NOT NULL DEFAULT '',
+	phone          TEXT NOT NULL DEFAULT '',
+	webhook_url    TEXT NOT NULL DEFAULT '',
+	webhook_secret TEXT NOT NULL DEFAULT '',
+	updated_at     DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_prefs_username ON prefs(username) WHERE username != '';
+
+CREATE TABLE IF NOT EXISTS subscriptions (
+	user_id TEXT NOT NULL REFERENCES prefs(user_id) ON DELETE CASCADE,
+	topic   TEXT NOT NULL,
+	channel TEXT NOT NULL,
+	PRIMARY KEY (user_id, topic, channel)
+);
+
+CREATE TABLE IF NOT EXISTS deliveries (
+	event_id TEXT NOT NULL,
+	user_id  TEXT NOT NULL,
+	channel  TEXT NOT NULL,
+	sent_at  DATETIME NOT NULL,
+	PRIMARY KEY (event_id, user_id, channel)
+);
+`
+
+// Store keeps preferences and deliveries in SQLite.
+type Store struct {
+	conn *sql.DB
+}
+
+// Open creates or opens the SQLite database at path and applies the schema.
+func Open(path string) (*Store, error) {
+	conn, err := sqlitex.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	if _, err := conn.Exec(schema); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("apply schema: %w", err)
+	}
+	return &Store{conn: conn}, nil
+}
+
+// Ping checks that the database answers.
+func (s *Store) Ping(ctx context.Context) error {
+	return s.conn.PingContext(ctx)
+}
+
+// Close shuts down the database connection.
+func (s *Store) Close() error {
+	return s.conn.Close()
+}
+
+// Get returns the preferences of the user with userID.
+func (s *Store) Get(ctx context.Context, userID string) (*Prefs, error) {
+	return s.get(ctx, `user_id = ?1`, userID)
+}
+
+// Lookup returns the preferences of the user whose ID or username is
+// recipient.
+func (s *Store) Lookup(ctx context.Context, recipient string) (*Prefs, error) {
+	return s.get(ctx, `user_id = ?1 OR (username = ?1 AND username != '')`, recipient)
+}
+
+func (s *Store) get(ctx context.Context, where string, arg string) (*Prefs, error) {
+	p := &Prefs{Topics: map[string][]string{}}
+	err := s.conn.QueryRowContext(ctx, `
+		SELECT user_id, username, email, phone, webhook_url, webhook_secret, updated_at
+		FROM prefs WHERE `+where+` ORDER BY user_id = ?1 DESC LIMIT 1`, arg,
+	).Scan(&p.UserID, &p.Username, &p.Email, &p.Phone, &p.WebhookURL, &p.WebhookSecret, &p.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get preferences of %s: %w", arg, err)
+	}
+
+	rows, err := s.conn.QueryContext(ctx,
+		`SELECT topic, channel FROM subscriptions WHERE user_id = ? ORDER BY topic, channel`, p.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("get subscriptions of %s: %w", p.UserID, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var topic, channel string
+		if err := rows.Scan(&topic, &channel); err != nil {
+			return nil, err
+		}
+		p.Topics[topic] = append(p.Topics[topic], channel)
+	}
+	return p, rows.Err()
+}
+
+// Put replaces the preferences of p.UserID with p.
+func (s *Store) Put(ctx context.Context, p *Prefs) error {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO prefs (user_id, username, email, phone, webhook_url, webhook_secret, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET
+			username = excluded.username, email = excluded.email, phone = excluded.phone,
+			webhook_url = excluded.webhook_url, webhook_secret = excluded.webhook_secret,
+			updated_at = excluded.updated_at`,
+		p.UserID, p.Username, p.Email, p.Phone, p.WebhookURL, p.WebhookSecret, p.UpdatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("put preferences of %s: %w", p.UserID, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM subscriptions WHERE user_id = ?`, p.UserID); err != nil {
+		return fmt.Errorf("put subscriptions of %s: %w", p.UserID, err)
+	}
+	topics := make([]string, 0, len(p.Topics))
+	for t := range p.Topics {
+		topics = append(topics, t)
+	}
+	sort.Strings(topics)
+	for _, t := range topics {
+		for _, ch := range p.Topics[t] {
+			_, err := tx.ExecContext(ctx,
+				`INSERT INTO subscriptions (user_id, topic, channel) VALUES (?, ?, ?) ON CONFLICT DO NOTHING`,
+				p.UserID, t, ch)
+			if err != nil {
+				return fmt.Errorf("put subscriptions of %s: %w", p.UserID, err)
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// Delete removes the preferences of the user with userID, who then gets
+// no notifications.
+func (s *Store) Delete(ctx context.Context, userID string) error {
+	if _, err := s.conn.ExecContext(ctx, `DELETE FROM prefs WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("delete preferences of %s: %w", userID, err)
+	}
+	return nil
+}
+
+// Delivered reports whether the user has been sent eventID on channel.
+func (s *Store) Delivered(ctx context.Context, eventID, userID, channel string) (bool, error) {
+	var n int
+	err := s.conn.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM deliveries WHERE event_id = ? AND user_id = ? AND channel = ?`,
+		eventID, userID, channel).Scan(&n)
+	if err != nil {
+		return false, fmt.Errorf("check delivery of %s: %w", eventID, err)
+	}
+	return n > 0, nil
+}
+
+// MarkDelivered records that the user has been sent eventID on channel.
+func (s *Store) MarkDelivered(ctx context.Context, eventID, userID, channel string, at time.Time) error {
+	_, err := s.conn.ExecContext(ctx,
+		`INSERT INTO deliveries (event_id, user_id, channel, sent_at) VALUES (?, ?, ?, ?) ON CONFLICT DO NOTHING`,
+		eventID, userID, channel, at.UTC())
+	if err != nil {
+		return fmt.Errorf("record delivery of %s: %w", eventID, err)
+	}
+	return nil
+}
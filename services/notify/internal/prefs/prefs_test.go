@@ -0,0 +1,106 @@
+package prefs
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(filepath.Join(t.TempDir(), "notify.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestPutGet(t *testing.T) {
+	ctx := context.Background()
+	s := testStore(t)
+
+	if _, err := s.Get(ctx, "u1"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get before Put: %v, want ErrNotFound", err)
+	}
+
+	p := &Prefs{
+		UserID:    "u1",
+		Username:  "alice",
+		Email:     "alice@example.com",
+		Topics:    map[string][]string{"secret.exposed": {Email}},
+		UpdatedAt: time.Now(),
+	}
+	if err := s.Put(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+	// Replacing drops subscriptions that are no longer listed.
+	p.Phone = "+15555550100"
+	p.Topics = map[string][]string{"event.created": {SMS, Email}}
+	if err := s.Put(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, recipient := range []string{"u1", "alice"} {
+		got, err := s.Lookup(ctx, recipient)
+		if err != nil {
+			t.Fatalf("Lookup(%q): %v", recipient, err)
+		}
+		if got.UserID != "u1" || got.Phone != "+15555550100" {
+			t.Errorf("Lookup(%q) = %+v", recipient, got)
+		}
+		if want := map[string][]string{"event.created": {Email, SMS}}; !reflect.DeepEqual(got.Topics, want) {
+			t.Errorf("Lookup(%q).Topics = %v, want %v", recipient, got.Topics, want)
+		}
+	}
+	if _, err := s.Lookup(ctx, ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Lookup(\"\"): %v, want ErrNotFound", err)
+	}
+
+	if err := s.Delete(ctx, "u1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get(ctx, "u1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete: %v, want ErrNotFound", err)
+	}
+}
+
+func TestLookupPrefersUserID(t *testing.T) {
+	ctx := context.Background()
+	s := testStore(t)
+	// One user's username is another's ID.
+	for _, p := range []*Prefs{{UserID: "bob", Email: "a@example.com"}, {UserID: "u2", Username: "bob", Email: "b@example.com"}} {
+		if err := s.Put(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := s.Lookup(ctx, "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.UserID != "bob" {
+		t.Errorf("Lookup(\"bob\") found %s, want the user whose ID it is", got.UserID)
+	}
+}
+
+func TestDeliveries(t *testing.T) {
+	ctx := context.Background()
+	s := testStore(t)
+	if done, err := s.Delivered(ctx, "e1", "u1", Email); err != nil || done {
+		t.Fatalf("Delivered before marking: %v, %v", done, err)
+	}
+	for range 2 {
+		if err := s.MarkDelivered(ctx, "e1", "u1", Email, time.Now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if done, err := s.Delivered(ctx, "e1", "u1", Email); err != nil || !done {
+		t.Errorf("Delivered after marking: %v, %v", done, err)
+	}
+	if done, _ := s.Delivered(ctx, "e1", "u1", SMS); done {
+		t.Error("Delivered on another channel: true")
+	}
+}
@@ -0,0 +1,138 @@
+//go:build giveaway
+
+package main
+
+import (
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/jredh-dev/nexus/internal/authz"
+	"github.com/jredh-dev/nexus/internal/ratelimit"
+	"github.com/jredh-dev/nexus/services/portal/config"
+	"github.com/jredh-dev/nexus/services/portal/internal/auth"
+	"github.com/jredh-dev/nexus/services/portal/internal/database"
+	"github.com/jredh-dev/nexus/services/portal/internal/web/handlers"
+	"github.com/jredh-dev/nexus/services/portal/pkg/models"
+)
+
+// migrateGiveaway applies the giveaway database's migrations.
+func migrateGiveaway(cfg *config.Config) error {
+	db, err := database.NewGiveaway(cfg.DB.GiveawayPath)
+	if err != nil {
+		return err
+	}
+	return db.Close()
+}
+
+// openGiveaway opens the giveaway database for the analytics page's claim
+// statistics, the admin search page and the giveaway API, which the
+// returned func mounts. The caller closes the database.
+func openGiveaway(cfg *config.Config) ([]handlers.Option, func(chi.Router, *ratelimit.Limiter, *auth.Service), io.Closer, error) {
+	db, err := database.NewGiveaway(cfg.DB.GiveawayPath)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	opts := []handlers.Option{
+		handlers.WithClaimStats(db.ClaimStats),
+		handlers.WithGiveawaySearch(func(q string, limit int) ([]models.SearchGroup, error) {
+			return searchGiveaway(db, q, limit)
+		}),
+	}
+	return opts, giveawayRoutes(handlers.NewGiveaway(db)), db, nil
+}
+
+// giveawayRoutes returns a func mounting g's API: the public half, and the
+// claims admins manage (as the TUI does), which take a session with the
+// giveaway.manage permission.
+func giveawayRoutes(g *handlers.Giveaway) func(chi.Router, *ratelimit.Limiter, *auth.Service) {
+	return func(r chi.Router, lim *ratelimit.Limiter, authService *auth.Service) {
+		r.Route("/api/giveaway", func(r chi.Router) {
+			r.Get("/items", g.APIListItems)
+			r.Get("/fee", g.APICalculateFee)
+			r.With(lim.Limit("claim", ratelimit.ByIP)).Post("/claims", g.APICreateClaim)
+		})
+		r.Route("/api/admin/claims", func(r chi.Router) {
+			r.Use(handlers.APIAuthMiddleware(authService), handlers.RequirePermission(authz.GiveawayManage))
+			r.Get("/", g.APIAdminListClaims)
+			r.Post("/{id}/confirm", g.APIAdminConfirmClaim)
+			r.Post("/{id}/cancel", g.APIAdminCancelClaim)
+		})
+	}
+}
+
+// searchGiveaway finds items and claims for the admin search page.
+func searchGiveaway(db *database.GiveawayDB, q string, limit int) ([]models.SearchGroup, error) {
+	items, err := db.SearchItems(q, limit)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := db.SearchClaims(q, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	itemGroup := models.SearchGroup{
+		Name: "Items",
+		Columns: []models.SearchColumn{
+			{Title: "Title", Searched: true},
+			{Title: "Description", Searched: true},
+			{Title: "Status"},
+		},
+	}
+	for _, item := range items {
+		itemGroup.Rows = append(itemGroup.Rows, []string{item.Title, item.Description, string(item.Status)})
+	}
+	claimGroup := models.SearchGroup{
+		Name: "Claims",
+		Columns: []models.SearchColumn{
+			{Title: "Claim", Searched: true},
+			{Title: "Claimer", Searched: true},
+			{Title: "Email", Searched: true},
+			{Title: "Phone", Searched: true},
+			{Title: "Status"},
+			{Title: "Notes", Searched: true},
+		},
+	}
+	for _, c := range claims {
+		claimGroup.Rows = append(claimGroup.Rows, []string{c.ID, c.ClaimerName, c.ClaimerEmail, c.ClaimerPhone, string(c.Status), c.Notes})
+	}
+	return []models.SearchGroup{itemGroup, claimGroup}, nil
+}
+
+// demoItems are the giveaway listings seedGiveaway adds. Their IDs are
+// fixed so seeding twice adds nothing.
+var demoItems = []models.Item{
+	{ID: "demo-item-desk", Title: "Standing desk", Description: "Electric sit/stand desk, 60\" top.", Condition: models.ConditionGood, DistMiles: 4.2, DriveMinutes: 12},
+	{ID: "demo-item-bike", Title: "Road bike", Description: "56cm frame, needs new tires.", Condition: models.ConditionFair, DistMiles: 9.8, DriveMinutes: 24},
+	{ID: "demo-item-lamp", Title: "Desk lamp", Description: "LED, adjustable arm.", Condition: models.ConditionLikeNew, DistMiles: 1.5, DriveMinutes: 6},
+}
+
+// seedGiveaway adds the demo giveaway items that aren't there yet.
+func seedGiveaway(cfg *config.Config) error {
+	db, err := database.NewGiveaway(cfg.DB.GiveawayPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	now := time.Now().UTC()
+	for _, item := range demoItems {
+		existing, err := db.GetItem(item.ID)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			continue
+		}
+		item.Status = models.ItemStatusAvailable
+		item.CreatedAt, item.UpdatedAt = now, now
+		if err := db.CreateItem(&item); err != nil {
+			return err
+		}
+		slog.Info("seeded giveaway item", "title", item.Title, "item_id", item.ID)
+	}
+	return nil
+}
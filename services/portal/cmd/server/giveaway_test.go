@@ -0,0 +1,262 @@
+//go:build giveaway
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/jredh-dev/nexus/internal/ratelimit"
+	"github.com/jredh-dev/nexus/services/portal/config"
+	"github.com/jredh-dev/nexus/services/portal/internal/auth"
+	"github.com/jredh-dev/nexus/services/portal/internal/database"
+	"github.com/jredh-dev/nexus/services/portal/internal/web/handlers"
+	"github.com/jredh-dev/nexus/services/portal/pkg/models"
+)
+
+// openSeededGiveaway opens a giveaway database holding the demo items and
+// mounts its API, with authService checking the admin routes' sessions.
+func openSeededGiveaway(t *testing.T, authService *auth.Service) (*config.Config, chi.Router) {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.DB.GiveawayPath = filepath.Join(t.TempDir(), "giveaway.db")
+	if err := seedGiveaway(cfg); err != nil {
+		t.Fatalf("seedGiveaway: %v", err)
+	}
+	_, routes, db, err := openGiveaway(cfg)
+	if err != nil {
+		t.Fatalf("openGiveaway: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	r := chi.NewRouter()
+	routes(r, ratelimit.New(ratelimit.NewMemory(), ratelimit.Limits{"claim": ratelimit.PerMinute(5)}, nil), authService)
+	return cfg, r
+}
+
+func TestGiveawayAPI(t *testing.T) {
+	_, r := openSeededGiveaway(t, nil)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("GET", "/api/giveaway/items", "")
+	var items []models.Item
+	if err := json.Unmarshal(w.Body.Bytes(), &items); err != nil || len(items) != len(demoItems) {
+		t.Fatalf("GET items = %d %s, want the %d demo items", w.Code, w.Body, len(demoItems))
+	}
+
+	if w := do("GET", "/api/giveaway/fee?miles=5&minutes=15", ""); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"total":12.5`) {
+		t.Errorf("GET fee = %d %s, want a total of 12.5", w.Code, w.Body)
+	}
+	if w := do("GET", "/api/giveaway/fee?miles=five", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("GET fee with bad miles = %d, want 400", w.Code)
+	}
+
+	claim := `{"item_id":"demo-item-lamp","name":"Ada","email":"ada@example.com"}`
+	w = do("POST", "/api/giveaway/claims", claim)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST claim = %d %s, want 201", w.Code, w.Body)
+	}
+	var created models.Claim
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+	if created.Status != models.ClaimStatusPending || created.DeliveryFee <= 0 {
+		t.Errorf("claim = %+v, want pending with a delivery fee", created)
+	}
+	if w := do("POST", "/api/giveaway/claims", claim); w.Code != http.StatusConflict {
+		t.Errorf("claiming a claimed item = %d, want 409", w.Code)
+	}
+	if w := do("POST", "/api/giveaway/claims", `{"item_id":"nope","name":"Ada","email":"ada@example.com"}`); w.Code != http.StatusNotFound {
+		t.Errorf("claiming a missing item = %d, want 404", w.Code)
+	}
+	if w := do("POST", "/api/giveaway/claims", `{"item_id":"demo-item-desk"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("claim without a name = %d, want 400", w.Code)
+	}
+}
+
+func TestSearchGiveaway(t *testing.T) {
+	cfg, _ := openSeededGiveaway(t, nil)
+	db, err := database.NewGiveaway(cfg.DB.GiveawayPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	groups, err := searchGiveaway(db, "desk", 10)
+	if err != nil {
+		t.Fatalf("searchGiveaway: %v", err)
+	}
+	if len(groups) != 2 || groups[0].Name != "Items" || groups[1].Name != "Claims" {
+		t.Fatalf("groups = %+v, want Items and Claims", groups)
+	}
+	var titles []string
+	for _, row := range groups[0].Rows {
+		titles = append(titles, row[0])
+	}
+	slices.Sort(titles)
+	if want := []string{"Desk lamp", "Standing desk"}; !slices.Equal(titles, want) {
+		t.Errorf("items found = %q, want %q", titles, want)
+	}
+}
+
+func TestAnalyticsShowsClaimStats(t *testing.T) {
+	cfg, r := openSeededGiveaway(t, nil)
+	claim := `{"item_id":"demo-item-bike","name":"Ada","email":"ada@example.com"}`
+	req := httptest.NewRequest("POST", "/api/giveaway/claims", strings.NewReader(claim))
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	db, err := database.New(filepath.Join(t.TempDir(), "portal.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	opts, _, gdb, err := openGiveaway(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gdb.Close()
+	h := handlers.New(db, cfg, nil, nil, opts...)
+
+	w := httptest.NewRecorder()
+	h.AdminAnalytics(w, httptest.NewRequest("GET", "/admin/analytics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /admin/analytics = %d %s", w.Code, w.Body)
+	}
+	body := w.Body.String()
+	if strings.Contains(body, "This build has no giveaway") {
+		t.Fatal("analytics page says there is no giveaway")
+	}
+	// Three demo items, one of them claimed.
+	for _, want := range []string{
+		`<td>Items listed</td><td class="n">3</td>`,
+		`<td>Items claimed</td><td class="n">1</td>`,
+		`<td>Claims</td><td class="n">1</td>`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("analytics page lacks %q", want)
+		}
+	}
+}
+
+func TestAdminClaimsAPI(t *testing.T) {
+	db, err := database.New(filepath.Join(t.TempDir(), "portal.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	authService := auth.New(db, &config.Config{Session: config.SessionConfig{Secret: "test", MaxAge: 3600}})
+	_, r := openSeededGiveaway(t, authService)
+
+	// session signs in a new user with role and returns their cookie.
+	session := func(email, role string) string {
+		t.Helper()
+		hash, err := auth.HashPassword("password")
+		if err != nil {
+			t.Fatal(err)
+		}
+		user := &models.User{ID: role, Username: role, Email: email, Role: role, PasswordHash: hash}
+		if err := db.CreateUser(user); err != nil {
+			t.Fatal(err)
+		}
+		cookie, err := authService.Login(email, "password", "test", "test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cookie
+	}
+	moderator := session("mod@example.com", models.RoleModerator)
+	member := session("member@example.com", models.RoleUser)
+
+	do := func(cookie, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if cookie != "" {
+			req.AddCookie(&http.Cookie{Name: "session", Value: cookie})
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	pending := func() []models.Claim {
+		t.Helper()
+		w := do(moderator, "GET", "/api/admin/claims?status=pending", "")
+		var claims []models.Claim
+		if err := json.Unmarshal(w.Body.Bytes(), &claims); err != nil {
+			t.Fatalf("GET pending claims = %d %s", w.Code, w.Body)
+		}
+		return claims
+	}
+
+	for _, item := range []string{"demo-item-desk", "demo-item-bike"} {
+		if w := do("", "POST", "/api/giveaway/claims", `{"item_id":"`+item+`","name":"Ada","email":"ada@example.com"}`); w.Code != http.StatusCreated {
+			t.Fatalf("POST claim = %d %s", w.Code, w.Body)
+		}
+	}
+	claims := pending()
+	if len(claims) != 2 {
+		t.Fatalf("pending claims = %+v, want 2", claims)
+	}
+
+	// Only sessions with giveaway.manage get in.
+	if w := do("", "GET", "/api/admin/claims", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("without a session = %d, want 401", w.Code)
+	}
+	if w := do(member, "GET", "/api/admin/claims", ""); w.Code != http.StatusForbidden {
+		t.Errorf("as a user = %d, want 403", w.Code)
+	}
+	if w := do(member, "POST", "/api/admin/claims/"+claims[0].ID+"/confirm", ""); w.Code != http.StatusForbidden {
+		t.Errorf("confirming as a user = %d, want 403", w.Code)
+	}
+	if w := do(moderator, "GET", "/api/admin/claims?status=lost", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown status = %d, want 400", w.Code)
+	}
+
+	confirmed, cancelled := claims[0], claims[1]
+	if w := do(moderator, "POST", "/api/admin/claims/"+confirmed.ID+"/confirm", ""); w.Code != http.StatusOK {
+		t.Fatalf("confirm = %d %s", w.Code, w.Body)
+	}
+	if w := do(moderator, "POST", "/api/admin/claims/"+confirmed.ID+"/confirm", ""); w.Code != http.StatusConflict {
+		t.Errorf("confirming twice = %d, want 409", w.Code)
+	}
+	if w := do(moderator, "POST", "/api/admin/claims/"+cancelled.ID+"/cancel", ""); w.Code != http.StatusOK {
+		t.Fatalf("cancel = %d %s", w.Code, w.Body)
+	}
+	if w := do(moderator, "POST", "/api/admin/claims/nope/cancel", ""); w.Code != http.StatusNotFound {
+		t.Errorf("cancelling a missing claim = %d, want 404", w.Code)
+	}
+	if claims := pending(); len(claims) != 0 {
+		t.Errorf("pending claims after confirming and cancelling = %+v, want none", claims)
+	}
+
+	// The cancelled claim's item can be claimed again; the confirmed one's can't.
+	w := do("", "GET", "/api/giveaway/items", "")
+	var items []models.Item
+	if err := json.Unmarshal(w.Body.Bytes(), &items); err != nil {
+		t.Fatal(err)
+	}
+	var available []string
+	for _, item := range items {
+		available = append(available, item.ID)
+	}
+	if !slices.Contains(available, cancelled.ItemID) || slices.Contains(available, confirmed.ItemID) {
+		t.Errorf("available items = %q, want %s and not %s", available, cancelled.ItemID, confirmed.ItemID)
+	}
+}
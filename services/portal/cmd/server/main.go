@@ -13,16 +13,26 @@ import (
 	"encoding/hex"
 	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
+	"path/filepath"
 	"time"
 
+	"connectrpc.com/connect"
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
 	"github.com/jredh-dev/nexus/gen/portal/v1/portalv1connect"
+	"github.com/jredh-dev/nexus/internal/assets"
+	"github.com/jredh-dev/nexus/internal/authz"
+	"github.com/jredh-dev/nexus/internal/flags"
+	"github.com/jredh-dev/nexus/internal/health"
+	"github.com/jredh-dev/nexus/internal/httpserver"
+	"github.com/jredh-dev/nexus/internal/httpx"
+	"github.com/jredh-dev/nexus/internal/logging"
+	"github.com/jredh-dev/nexus/internal/metrics"
+	"github.com/jredh-dev/nexus/internal/ratelimit"
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
+	"github.com/jredh-dev/nexus/internal/upload"
 	gohttp "github.com/jredh-dev/nexus/services/go-http"
 	"github.com/jredh-dev/nexus/services/portal/config"
 	"github.com/jredh-dev/nexus/services/portal/internal/actions"
@@ -30,6 +40,8 @@ import (
 	"github.com/jredh-dev/nexus/services/portal/internal/database"
 	"github.com/jredh-dev/nexus/services/portal/internal/rpc"
 	"github.com/jredh-dev/nexus/services/portal/internal/web/handlers"
+	"github.com/jredh-dev/nexus/services/portal/pkg/models"
+	"github.com/jredh-dev/nexus/services/portal/static"
 )
 
 // swaggerSpec embeds the generated swagger.json so the binary is self-contained.
@@ -46,6 +58,9 @@ var (
 func main() {
 	showVersion := flag.Bool("version", false, "Show version information")
 	enableDocs := flag.Bool("docs", false, "Enable Swagger UI at /docs (local dev only)")
+	migrateOnly := flag.Bool("migrate", false, "Apply database migrations and exit")
+	backup := flag.String("backup", "", "Check the database and copy it to this file, and exit")
+	seedOnly := flag.Bool("seed", false, "Apply migrations, add the demo and admin users (and giveaway items in giveaway builds), and exit")
 	flag.Parse()
 
 	if *showVersion {
@@ -55,25 +70,98 @@ func main() {
 		os.Exit(0)
 	}
 
-	cfg := config.Load()
+	if err := logging.Setup("portal"); err != nil {
+		fmt.Fprintf(os.Stderr, "portal-server: %v\n", err)
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "portal-server: %v\n", err)
+		os.Exit(1)
+	}
+	slog.Info("config", "settings", cfg.Settings)
 
 	if cfg.Session.Secret == "" {
-		log.Println("WARNING: SESSION_SECRET is empty — using insecure default (set SESSION_SECRET in production)")
+		slog.Warn("SESSION_SECRET is empty; using an insecure default", "hint", "set SESSION_SECRET in production")
 		cfg.Session.Secret = "insecure-dev-secret-change-me"
 	}
 
 	// Initialize SQLite database.
 	db, err := database.New(cfg.DB.Path)
 	if err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+		logging.Fatal("initialize database", "path", cfg.DB.Path, "err", err)
 	}
 	defer db.Close()
 
-	// Initialize auth service.
-	authService := auth.New(db, cfg)
+	if *backup != "" {
+		if err := db.Backup(context.Background(), *backup); err != nil {
+			logging.Fatal("back up database", "path", cfg.DB.Path, "err", err)
+		}
+		slog.Info("database backed up", "path", cfg.DB.Path, "to", *backup)
+		return
+	}
+	if *migrateOnly {
+		if err := migrateGiveaway(cfg); err != nil {
+			logging.Fatal("initialize giveaway database", "err", err)
+		}
+		slog.Info("migrations applied", "path", cfg.DB.Path)
+		return
+	}
+
+	// Feature flags, reloaded while the server runs.
+	fl := flags.New("")
+	waitlist := fl.Bool("signup.waitlist", false, "Put sign-ups on the waitlist instead of creating accounts")
+	if err := fl.Load(); err != nil {
+		fmt.Fprintf(os.Stderr, "portal-server: %v\n", err)
+		os.Exit(1)
+	}
+	slog.Info("flags", "flags", fl)
+
+	// Uploaded files such as avatars.
+	uploads, err := upload.NewDir(cfg.UploadDir)
+	if err != nil {
+		logging.Fatal("open upload dir", "path", cfg.UploadDir, "err", err)
+	}
+
+	// Static files, embedded unless STATIC_DIR points at a copy on disk.
+	var staticFiles *assets.Assets
+	if cfg.StaticDir != "" {
+		staticFiles = assets.Dir(cfg.StaticDir)
+		slog.Info("serving static files from disk", "dir", cfg.StaticDir)
+	} else if staticFiles, err = assets.New(static.FS); err != nil {
+		logging.Fatal("load static files", "err", err)
+	}
+
+	// Initialize auth service. Phone numbers are verified by text message
+	// when Kafka is configured for the sms-outbox pipeline.
+	authOpts := []auth.Option{auth.WithWaitlist(waitlist.On), auth.WithUploads(uploads)}
+	var smsPub *smsoutbox.KafkaPublisher
+	if len(cfg.SMS.Brokers) > 0 {
+		smsPub = smsoutbox.NewKafkaPublisher(cfg.SMS.Brokers, cfg.SMS.Topic)
+		authOpts = append(authOpts, auth.WithSMS(smsPub))
+		slog.Info("sms verification enabled", "topic", cfg.SMS.Topic, "brokers", cfg.SMS.Brokers)
+	}
+	authService := auth.New(db, cfg, authOpts...)
+
+	if *seedOnly {
+		seedDemoUser(authService)
+		seedAdminUser(db, authService)
+		if err := seedGiveaway(cfg); err != nil {
+			logging.Fatal("seed giveaway items", "err", err)
+		}
+		slog.Info("seeded", "path", cfg.DB.Path)
+		return
+	}
 
 	// Initialize actions registry (shared between HTTP handlers and RPC).
+	// Searches are counted for the admin analytics page.
 	actionsRegistry := actions.New()
+	actionsRegistry.SetObserver(func(query string) {
+		if err := db.RecordSearch(query, time.Now()); err != nil {
+			slog.Warn("record search", "err", err)
+		}
+	})
 
 	// Seed demo user in all environments so visitors can log in.
 	seedDemoUser(authService)
@@ -82,44 +170,95 @@ func main() {
 	seedAdminUser(db, authService)
 
 	// Initialize router.
-	r := chi.NewRouter()
-
-	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
-	r.Use(middleware.Logger)
-	r.Use(middleware.Recoverer)
-	r.Use(middleware.Timeout(60 * time.Second))
-
-	// Health check.
-	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
-	})
+	m := metrics.New("portal")
+	db.SetObserver(m.ObserveDB)
+	checks := health.New()
+	checks.Ready("database", db.Ping)
+	checks.Ready("disk", health.DiskSpace(filepath.Dir(cfg.DB.Path), 100<<20))
+
+	limits, err := ratelimit.Open(cfg.RateLimit.DB)
+	if err != nil {
+		logging.Fatal("open rate limit store", "path", cfg.RateLimit.DB, "err", err)
+	}
+	rateLimited := ratelimit.NewCounter("portal")
+	m.MustRegister(rateLimited)
+	lim := ratelimit.New(limits, cfg.RateLimit.Limits, rateLimited)
+
+	// Giveaway builds show claims on the analytics page, search them and
+	// serve the giveaway API.
+	giveawayOpts, giveawayRoutes, giveawayDB, err := openGiveaway(cfg)
+	if err != nil {
+		logging.Fatal("open giveaway database", "path", cfg.DB.GiveawayPath, "err", err)
+	}
+	serverOpts := []httpserver.Option{
+		httpserver.WithMetrics(m),
+		httpserver.WithHealth(checks),
+		httpserver.WithTimeout(60 * time.Second),
+		httpserver.WithVersion(httpserver.Version{Service: "nexus-portal", Version: version, Commit: commit, Built: buildDate}),
+		httpserver.WithFlags(fl),
+		httpserver.WithWorker("cleanup", authService.RunCleanup),
+		httpserver.WithWorker("wal checkpoints", db.RunCheckpoints),
+		httpserver.WithCloser("rate limits", limits),
+		// Client IPs, which the sign-in limits key on, come from
+		// X-Forwarded-For only as TRUSTED_PROXIES record it.
+		httpserver.WithTrustedProxies(cfg.RateLimit.TrustedProxies),
+	}
+	if smsPub != nil {
+		serverOpts = append(serverOpts, httpserver.WithCloser("sms publisher", smsPub))
+	}
+	if giveawayDB != nil {
+		serverOpts = append(serverOpts, httpserver.WithCloser("giveaway database", giveawayDB))
+	}
+	srv := httpserver.New(serverOpts...)
+	r := srv.Router
 
 	// Initialize handlers.
-	h := handlers.New(db, cfg, authService, actionsRegistry)
+	h := handlers.New(db, cfg, authService, actionsRegistry, giveawayOpts...)
 
 	// Connect RPC handlers (Astro frontend talks to these).
+	// RPC bodies are held to the same limit as the JSON API's.
+	readLimit := connect.WithReadMaxBytes(httpx.MaxBody)
 	authPath, authHandler := portalv1connect.NewAuthServiceHandler(
-		rpc.NewAuthServer(authService, cfg),
+		rpc.NewAuthServer(authService, cfg), readLimit,
 	)
 	actionsPath, actionsHandler := portalv1connect.NewActionsServiceHandler(
-		rpc.NewActionsServer(actionsRegistry, authService),
+		rpc.NewActionsServer(actionsRegistry, authService), readLimit,
 	)
 	r.Handle(authPath+"*", authHandler)
 	r.Handle(actionsPath+"*", actionsHandler)
 
+	// Procedures that sign in or send mail share limits with their form
+	// routes below.
+	loginLimit := lim.Limit("login", ratelimit.ByIP)
+	signupLimit := lim.Limit("signup", ratelimit.ByIP)
+	r.With(loginLimit).Handle(portalv1connect.AuthServiceLoginProcedure, authHandler)
+	r.With(loginLimit).Handle(portalv1connect.AuthServiceMagicLoginProcedure, authHandler)
+	r.With(signupLimit).Handle(portalv1connect.AuthServiceSignupProcedure, authHandler)
+	r.With(lim.Limit("magic_link", ratelimit.ByIP)).Handle(portalv1connect.AuthServiceGenerateMagicLinkProcedure, authHandler)
+
 	// Public routes (form auth + magic link — Astro owns GET pages).
-	r.Post("/login", h.Login)
-	r.Post("/signup", h.Signup)
+	r.With(loginLimit).Post("/login", h.Login)
+	r.With(signupLimit).Post("/signup", h.Signup)
 	r.Get("/logout", h.Logout)
-	r.Get("/auth/magic", h.MagicLogin)
+	r.With(loginLimit).Get("/auth/magic", h.MagicLogin)
 	r.Get("/auth/email-change", h.ConfirmEmailChange)
 
+	// Static files, under content-hashed names /api/assets lists.
+	r.Handle(assets.Prefix+"*", staticFiles)
+
 	// Public JSON API.
 	r.Route("/api", func(r chi.Router) {
 		r.Get("/actions", h.SearchActions)
+		r.Get("/assets", staticFiles.ServeManifest)
+
+		// Single sign-on: other nexus services check portal logins here,
+		// and logged-in clients get JWTs they accept.
+		r.With(lim.Limit("introspect", ratelimit.ByIP)).Post("/auth/introspect", h.Introspect)
+		r.With(lim.Limit("token", ratelimit.ByCredential), handlers.APIAuthMiddleware(authService)).Post("/auth/token", h.IssueToken)
 	})
+	if giveawayRoutes != nil {
+		giveawayRoutes(r, lim, authService)
+	}
 
 	// Authenticated JSON API — returns 401 JSON (not redirect) on missing session.
 	r.Route("/api/me", func(r chi.Router) {
@@ -129,13 +268,36 @@ func main() {
 		r.Delete("/", h.DeleteAccount)
 	})
 
-	// Admin routes (login + admin role required).
+	// Profile editing. Avatars are served from /uploads. Routes that text
+	// a code are limited per session and per IP, since each text costs.
+	phoneCodeLimit := []func(http.Handler) http.Handler{
+		lim.Limit("phone_code_ip", ratelimit.ByIP),
+		lim.Limit("phone_code", ratelimit.ByCredential),
+	}
+	r.Route("/dashboard/profile", func(r chi.Router) {
+		r.Use(handlers.APIAuthMiddleware(authService))
+		r.Get("/", h.GetProfile)
+		r.Post("/", h.UpdateProfile)
+		r.With(phoneCodeLimit...).Post("/phone", h.ChangePhone)
+		r.With(phoneCodeLimit...).Post("/phone/code", h.ResendPhoneCode)
+		r.With(lim.Limit("phone_verify", ratelimit.ByCredential)).Post("/phone/verify", h.VerifyPhone)
+		r.Post("/avatar", h.UploadAvatar)
+		r.Delete("/avatar", h.DeleteAvatar)
+	})
+	r.Handle("/uploads/*", http.StripPrefix("/uploads", upload.Handler(uploads)))
+
+	// Admin routes (login + the permission each needs, per auth.Roles).
 	r.Group(func(r chi.Router) {
 		r.Use(handlers.AuthMiddleware(authService))
-		r.Use(handlers.AdminMiddleware)
 
-		// Admin utilities.
-		r.Post("/admin/magic-link", h.AdminGenerateMagicLink)
+		r.With(handlers.RequirePermission(authz.MagicLinkIssue)).Post("/admin/magic-link", h.AdminGenerateMagicLink)
+		r.With(handlers.RequirePermission(authz.AnalyticsView)).Get("/admin/analytics", h.AdminAnalytics)
+		r.Get("/admin/search", h.AdminSearch) // checks per result type
+		r.Group(func(r chi.Router) {
+			r.Use(handlers.RequirePermission(authz.UsersManage))
+			r.Get("/admin/users", h.AdminUsers)
+			r.Post("/admin/users/{id}/role", h.AdminAssignRole)
+		})
 	})
 
 	// Mount Swagger UI if --docs flag is set (local dev only).
@@ -145,33 +307,10 @@ func main() {
 
 	// Start server.
 	addr := fmt.Sprintf(":%s", cfg.Server.Port)
-	srv := &http.Server{
-		Addr:         addr,
-		Handler:      r,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
-	}
-
-	go func() {
-		sigint := make(chan os.Signal, 1)
-		signal.Notify(sigint, os.Interrupt, syscall.SIGTERM)
-		<-sigint
-
-		log.Println("Shutting down server...")
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-
-		if err := srv.Shutdown(ctx); err != nil {
-			log.Printf("Server shutdown error: %v", err)
-		}
-	}()
-
-	log.Printf("Portal server starting on %s (env: %s)", addr, cfg.Server.Env)
-	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
-		log.Fatalf("Server error: %v", err)
+	slog.Info("portal server starting", "addr", addr, "env", cfg.Server.Env, "version", version)
+	if err := srv.ListenAndServe(addr); err != nil {
+		logging.Fatal("server", "err", err)
 	}
-	log.Println("Server stopped")
 }
 
 // seedDemoUser ensures the demo account exists in all environments.
@@ -183,10 +322,10 @@ func seedDemoUser(authService *auth.Service) {
 
 	created, err := authService.CreateUser("demo@demo.com", "demo", "Demo User")
 	if err != nil {
-		log.Printf("Demo user skipped (may already exist): %v", err)
+		slog.Info("demo user skipped (may already exist)", "err", err)
 		return
 	}
-	log.Printf("Seeded demo user: %s (%s)", created.Email, created.ID)
+	slog.Info("seeded demo user", "email", created.Email, "user_id", created.ID)
 }
 
 // seedAdminUser ensures dev@jredh.com exists as an admin in all environments.
@@ -204,16 +343,16 @@ func seedAdminUser(db *database.DB, authService *auth.Service) {
 	// Try to look up by email directly (login will fail with wrong password).
 	user, lookupErr := db.GetUserByEmail(adminEmail)
 	if lookupErr != nil {
-		log.Printf("Error looking up admin user: %v", lookupErr)
+		slog.Error("look up admin user", "email", adminEmail, "err", lookupErr)
 		return
 	}
 
 	if user != nil {
 		// User exists, ensure admin role.
-		if err := db.UpdateUserRole(user.ID, "admin"); err != nil {
-			log.Printf("Failed to set admin role for %s: %v", adminEmail, err)
+		if err := db.UpdateUserRole(user.ID, models.RoleAdmin); err != nil {
+			slog.Error("set admin role", "email", adminEmail, "err", err)
 		} else {
-			log.Printf("Admin role ensured for existing user: %s", adminEmail)
+			slog.Info("admin role ensured for existing user", "email", adminEmail)
 		}
 		return
 	}
@@ -221,15 +360,15 @@ func seedAdminUser(db *database.DB, authService *auth.Service) {
 	// Create the admin user with a random password (magic links are primary auth).
 	created, err := authService.CreateUser(adminEmail, randomPassword(), "Jared Hooper")
 	if err != nil {
-		log.Printf("Admin user skipped (may already exist): %v", err)
+		slog.Info("admin user skipped (may already exist)", "err", err)
 		return
 	}
 
-	if err := db.UpdateUserRole(created.ID, "admin"); err != nil {
-		log.Printf("Failed to set admin role for %s: %v", adminEmail, err)
+	if err := db.UpdateUserRole(created.ID, models.RoleAdmin); err != nil {
+		slog.Error("set admin role", "email", adminEmail, "err", err)
 		return
 	}
-	log.Printf("Seeded admin user: %s (%s) with role=admin", created.Email, created.ID)
+	slog.Info("seeded admin user", "email", created.Email, "user_id", created.ID, "role", "admin")
 }
 
 // randomPassword generates a 32-byte hex-encoded random password.
@@ -0,0 +1,26 @@
+//go:build !giveaway
+
+package main
+
+import (
+	"io"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/jredh-dev/nexus/internal/ratelimit"
+	"github.com/jredh-dev/nexus/services/portal/config"
+	"github.com/jredh-dev/nexus/services/portal/internal/auth"
+	"github.com/jredh-dev/nexus/services/portal/internal/web/handlers"
+)
+
+// migrateGiveaway does nothing: this build has no giveaway database.
+func migrateGiveaway(*config.Config) error { return nil }
+
+// seedGiveaway does nothing: this build has no giveaway database.
+func seedGiveaway(*config.Config) error { return nil }
+
+// openGiveaway returns nothing: this build has no giveaway claims to show
+// or search, and no giveaway API.
+func openGiveaway(*config.Config) ([]handlers.Option, func(chi.Router, *ratelimit.Limiter, *auth.Service), io.Closer, error) {
+	return nil, nil, nil, nil
+}
@@ -1,8 +1,12 @@
 package config
 
 import (
-	"os"
-	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jredh-dev/nexus/internal/ratelimit"
+	"github.com/jredh-dev/nexus/internal/settings"
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
 )
 
 // Config holds all application configuration.
@@ -11,6 +15,17 @@ type Config struct {
 	DB      DBConfig
 	Session SessionConfig
 	SMTP    SMTPConfig
+	SSO     SSOConfig
+	SMS     SMSConfig
+
+	UploadDir string // where uploaded files such as avatars are kept
+	StaticDir string // serve static/ from this directory instead of the binary, for live editing
+
+	// RateLimit limits sign-in, sign-up and magic links by client IP, and
+	// SSO tokens by session, against guessing and mail bombing.
+	RateLimit ratelimit.Config
+
+	Settings settings.Values // everything above as loaded, for logging at boot
 }
 
 // ServerConfig holds HTTP server settings.
@@ -21,13 +36,32 @@ type ServerConfig struct {
 
 // DBConfig holds database settings.
 type DBConfig struct {
-	Path string // path to SQLite database file
+	Path         string // path to SQLite database file
+	GiveawayPath string // path to the giveaway SQLite database (giveaway builds only)
 }
 
 // SessionConfig holds session/cookie settings.
 type SessionConfig struct {
-	Secret string // HMAC key for signing session cookies
-	MaxAge int    // session duration in seconds (default: 7 days)
+	Secret   string   // key session cookies are signed, or encrypted, with
+	Previous []string // earlier secrets whose cookies are still accepted, while they rotate out
+	Encrypt  bool     // encrypt session cookies, not just sign them
+	MaxAge   int      // session duration in seconds (default: 7 days)
+}
+
+// SSOConfig holds settings for the tokens that let a portal login reach
+// the other nexus services.
+type SSOConfig struct {
+	Secret        string        // HMAC key /api/auth/token signs JWTs with, shared with services that check them; empty disables JWTs
+	TokenTTL      time.Duration // how long those JWTs last
+	IntrospectKey string        // bearer key services send to /api/auth/introspect; empty disables it
+}
+
+// SMSConfig holds settings for texting verification codes through the
+// sms-outbox pipeline. Phone number changes are disabled when Brokers is
+// empty.
+type SMSConfig struct {
+	Brokers []string
+	Topic   string
 }
 
 // SMTPConfig holds outbound email settings.
@@ -37,40 +71,68 @@ type SMTPConfig struct {
 	From string // From address for outbound email
 }
 
-// Load returns application configuration from environment variables.
-func Load() *Config {
-	return &Config{
+// Load returns application configuration from environment variables, and
+// from the YAML file PORTAL_CONFIG_FILE names if set.
+func Load() (*Config, error) {
+	l := settings.New("PORTAL_CONFIG_FILE")
+	cfg := &Config{
 		Server: ServerConfig{
-			Port: getEnv("PORT", "8080"),
-			Env:  getEnv("ENV", "development"),
+			Port: l.String("PORT", "8080"),
+			Env:  l.String("ENV", "development"),
 		},
 		DB: DBConfig{
-			Path: getEnv("DB_PATH", "portal.db"),
+			Path:         l.String("DB_PATH", "portal.db"),
+			GiveawayPath: l.String("GIVEAWAY_DB_PATH", "giveaway.db"),
 		},
 		Session: SessionConfig{
-			Secret: getEnv("SESSION_SECRET", ""),
-			MaxAge: getEnvInt("SESSION_MAX_AGE", 604800), // 7 days
+			Secret:   l.Secret("SESSION_SECRET", ""),
+			Previous: splitList(l.Secret("SESSION_SECRET_PREVIOUS", "")),
+			Encrypt:  l.OneOf("SESSION_ENCRYPT", "false", "true", "false") == "true",
+			MaxAge:   l.Int("SESSION_MAX_AGE", 604800), // 7 days
 		},
 		SMTP: SMTPConfig{
-			Host: getEnv("SMTP_HOST", "localhost"),
-			Port: getEnv("SMTP_PORT", "1025"),
-			From: getEnv("SMTP_FROM", "noreply@jredh.com"),
+			Host: l.String("SMTP_HOST", "localhost"),
+			Port: l.String("SMTP_PORT", "1025"),
+			From: l.String("SMTP_FROM", "noreply@jredh.com"),
 		},
+		SSO: SSOConfig{
+			Secret:        l.Secret("SSO_SECRET", ""),
+			TokenTTL:      l.Duration("SSO_TOKEN_TTL", time.Hour),
+			IntrospectKey: l.Secret("SSO_INTROSPECT_KEY", ""),
+		},
+		SMS: SMSConfig{
+			Brokers: l.List("KAFKA_BROKERS"),
+			Topic:   l.String("SMS_TOPIC", smsoutbox.Topic),
+		},
+		UploadDir: l.String("UPLOAD_DIR", "uploads"),
+		StaticDir: l.String("STATIC_DIR", ""),
 	}
+	cfg.RateLimit = ratelimit.LoadConfig(l, "", ratelimit.Limits{
+		"login":      ratelimit.PerMinute(10),
+		"signup":     ratelimit.PerMinute(5),
+		"magic_link": ratelimit.PerMinute(3),
+		"token":      ratelimit.PerMinute(60),
+		"introspect": ratelimit.PerMinute(600),
+		"claim":      ratelimit.PerMinute(5), // giveaway builds
+		// Sending a code texts a real phone, at our cost.
+		"phone_code":    ratelimit.PerHour(5),
+		"phone_code_ip": ratelimit.PerHour(20),
+		"phone_verify":  ratelimit.PerMinute(10),
+	})
+	l.Check(cfg.Server.Env != "production" || cfg.Session.Secret != "",
+		"SESSION_SECRET is required with ENV=production: without it the server falls back to a fixed, publicly known secret that lets anyone forge sessions")
+	cfg.Settings = l.Values()
+	return cfg, l.Err()
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
-
-func getEnvInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if intVal, err := strconv.Atoi(value); err == nil {
-			return intVal
+// splitList splits a comma-separated secret, which settings.Loader.List
+// would log.
+func splitList(v string) []string {
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
 		}
 	}
-	return defaultValue
+	return out
 }
@@ -1,6 +1,10 @@
 package actions
 
-import "strings"
+import (
+	"strings"
+
+	"github.com/jredh-dev/nexus/internal/authz"
+)
 
 // ActionType categorizes what an action does when executed.
 type ActionType string
@@ -17,7 +21,7 @@ const (
 	VisibleAlways    Visibility = iota // Everyone sees it
 	VisibleLoggedOut                   // Only when not logged in
 	VisibleLoggedIn                    // Only when logged in
-	VisibleAdmin                       // Only admins
+	VisiblePermitted                   // Only users granted the action's Permission
 )
 
 // Action represents a single executable action available in the magic bar.
@@ -31,17 +35,21 @@ type Action struct {
 	Target     string     `json:"target"`
 	Keywords   []string   `json:"keywords"`
 	Visibility Visibility `json:"-"` // Not serialized — server-side filtering only
+	// For VisiblePermitted actions: the permission needed to see it.
+	Permission authz.Permission `json:"-"`
 }
 
 // SearchContext provides auth state for filtering actions.
 type SearchContext struct {
 	LoggedIn bool
-	IsAdmin  bool
+	// Can reports whether the user is granted a permission; nil for none.
+	Can func(authz.Permission) bool
 }
 
 // Registry holds all available actions and supports filtered search.
 type Registry struct {
 	actions []Action
+	observe func(query string) // nil when searches aren't recorded
 }
 
 // New creates a Registry pre-populated with the default portal actions.
@@ -51,10 +59,20 @@ func New() *Registry {
 	}
 }
 
+// SetObserver registers fn to receive each non-empty query searched for,
+// lowercased and trimmed. Call it before the Registry is shared between
+// goroutines.
+func (r *Registry) SetObserver(fn func(query string)) {
+	r.observe = fn
+}
+
 // Search returns actions matching the query that are visible given the context.
 // An empty query returns all visible actions. Matching is case-insensitive substring.
 func (r *Registry) Search(query string, ctx SearchContext) []Action {
 	q := strings.ToLower(strings.TrimSpace(query))
+	if q != "" && r.observe != nil {
+		r.observe(q)
+	}
 	var results []Action
 
 	for _, a := range r.actions {
@@ -76,8 +94,8 @@ func isVisible(a Action, ctx SearchContext) bool {
 		return !ctx.LoggedIn
 	case VisibleLoggedIn:
 		return ctx.LoggedIn
-	case VisibleAdmin:
-		return ctx.IsAdmin
+	case VisiblePermitted:
+		return ctx.Can != nil && ctx.Can(a.Permission)
 	default:
 		return true
 	}
@@ -161,5 +179,27 @@ func defaultActions() []Action {
 			Keywords:    []string{"logout", "log out", "sign out", "signout", "exit"},
 			Visibility:  VisibleLoggedIn,
 		},
+
+		// Admin pages — only for roles granted their permission
+		{
+			ID:          "nav-admin-users",
+			Type:        TypeNavigation,
+			Title:       "Users",
+			Description: "See accounts and assign roles",
+			Target:      "/admin/users",
+			Keywords:    []string{"users", "roles", "permissions", "admin", "accounts"},
+			Visibility:  VisiblePermitted,
+			Permission:  authz.UsersManage,
+		},
+		{
+			ID:          "nav-admin-analytics",
+			Type:        TypeNavigation,
+			Title:       "Analytics",
+			Description: "Sign-ups, logins and searches per day",
+			Target:      "/admin/analytics",
+			Keywords:    []string{"analytics", "stats", "metrics", "admin", "signups"},
+			Visibility:  VisiblePermitted,
+			Permission:  authz.AnalyticsView,
+		},
 	}
 }
@@ -2,6 +2,8 @@ package actions
 
 import (
 	"testing"
+
+	"github.com/jredh-dev/nexus/internal/authz"
 )
 
 func TestNew_ReturnsNonEmptyRegistry(t *testing.T) {
@@ -51,6 +53,18 @@ func TestSearch_QueryFiltering(t *testing.T) {
 			ctx:     SearchContext{},
 			wantIDs: []string{},
 		},
+		{
+			name:    "admin page hidden without permission",
+			query:   "roles",
+			ctx:     SearchContext{LoggedIn: true},
+			wantIDs: []string{},
+		},
+		{
+			name:    "admin page shown with permission",
+			query:   "roles",
+			ctx:     SearchContext{LoggedIn: true, Can: func(p authz.Permission) bool { return p == authz.UsersManage }},
+			wantIDs: []string{"nav-admin-users"},
+		},
 		{
 			name:    "whitespace-only query returns all visible",
 			query:   "   ",
@@ -113,3 +127,14 @@ func resultIDs(actions []Action) []string {
 	}
 	return ids
 }
+
+func TestSearch_Observer(t *testing.T) {
+	reg := New()
+	var seen []string
+	reg.SetObserver(func(q string) { seen = append(seen, q) })
+	reg.Search("", SearchContext{})
+	reg.Search("  Home ", SearchContext{})
+	if len(seen) != 1 || seen[0] != "home" {
+		t.Errorf("observed %q, want [home]", seen)
+	}
+}
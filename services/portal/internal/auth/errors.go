@@ -11,6 +11,15 @@ var (
 	ErrPhoneTaken              = errors.New("an account with this phone number already exists")
 	ErrUsernameTaken           = errors.New("this username is already taken")
 	ErrInvalidMagicToken       = errors.New("invalid or expired magic login token")
-	ErrForbidden               = errors.New("forbidden: admin access required")
+	ErrForbidden               = errors.New("forbidden: permission required")
 	ErrInvalidEmailChangeToken = errors.New("invalid or expired email change token")
+	ErrSSODisabled             = errors.New("single sign-on tokens are not configured")
+	ErrWaitlisted              = errors.New("sign-ups are paused; added to the waitlist")
+	ErrSMSDisabled             = errors.New("text messages are not configured")
+	ErrInvalidPhoneCode        = errors.New("invalid or expired phone verification code")
+	ErrPhoneCodeTooSoon        = errors.New("a phone verification code was sent too recently")
+	ErrPhoneCodeAttempts       = errors.New("too many wrong phone verification codes")
+	ErrInvalidProfile          = errors.New("username is required")
+	ErrPhoneUnverified         = errors.New("phone number not verified")
+	ErrInvalidRole             = errors.New("no such role")
 )
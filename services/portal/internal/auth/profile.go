@@ -0,0 +1,221 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
+	"github.com/jredh-dev/nexus/internal/upload"
+	"github.com/jredh-dev/nexus/services/portal/pkg/identity"
+	"github.com/jredh-dev/nexus/services/portal/pkg/models"
+)
+
+// Phone verification codes.
+const (
+	phoneCodeExpiry      = 10 * time.Minute
+	phoneCodeMaxAttempts = 5
+	phoneCodeResend      = time.Minute // between texts to one user
+)
+
+// checkUsername returns ErrUsernameTaken if an account other than
+// exceptID has username.
+func (s *Service) checkUsername(username, exceptID string) error {
+	existing, err := s.db.GetUserByUsername(username)
+	if err != nil {
+		return fmt.Errorf("check username: %w", err)
+	}
+	if existing != nil && existing.ID != exceptID {
+		return ErrUsernameTaken
+	}
+	return nil
+}
+
+// checkPhone returns ErrPhoneTaken if an account other than exceptID has
+// the phone number hashing to phoneHash.
+func (s *Service) checkPhone(phoneHash, exceptID string) error {
+	existing, err := s.db.GetUserByPhoneHash(phoneHash)
+	if err != nil {
+		return fmt.Errorf("check phone hash: %w", err)
+	}
+	if existing != nil && existing.ID != exceptID {
+		return ErrPhoneTaken
+	}
+	return nil
+}
+
+// UpdateProfile sets the user's display name and username, checking the
+// username is free as Signup does.
+func (s *Service) UpdateProfile(userID, name, username string) error {
+	if username == "" {
+		return ErrInvalidProfile
+	}
+	if err := s.checkUsername(username, userID); err != nil {
+		return err
+	}
+	if err := s.db.UpdateUserProfile(userID, name, username); err != nil {
+		return fmt.Errorf("update profile: %w", err)
+	}
+	return nil
+}
+
+// NeedsPhoneVerification reports whether user must still enter the code
+// texted to their phone number before their account is fully active. It
+// is never so when the portal can't send texts, or for accounts with no
+// number. Until then the account gets no SSO tokens and fails
+// introspection, so the other nexus services turn it away; the portal's
+// own session routes stay open to it, so it can verify or fix its number.
+func (s *Service) NeedsPhoneVerification(user *models.User) bool {
+	return s.sms != nil && user.PhoneNumber != "" && !user.PhoneVerified
+}
+
+// SendPhoneCode texts a code to the number the user signed up with, to be
+// entered with ConfirmPhoneChange. Verifying a number is changing to it.
+func (s *Service) SendPhoneCode(ctx context.Context, user *models.User) error {
+	return s.InitiatePhoneChange(ctx, user.ID, user.PhoneNumber)
+}
+
+// InitiatePhoneChange texts a 6-digit code to phone. The user's number is
+// not changed until ConfirmPhoneChange is called with the code. Returns
+// ErrSMSDisabled when the portal can't send texts, ErrPhoneCodeTooSoon
+// within phoneCodeResend of the last code texted to the user, and
+// ErrPhoneCodeAttempts once phoneCodeMaxAttempts wrong codes have been
+// entered, until the last code expires: a new code keeps the count.
+func (s *Service) InitiatePhoneChange(ctx context.Context, userID, phone string) error {
+	if s.sms == nil {
+		return ErrSMSDisabled
+	}
+	if err := s.checkPhone(identity.PhoneHash(phone), userID); err != nil {
+		return err
+	}
+	pending, err := s.db.GetPhoneChange(userID)
+	if err != nil {
+		return fmt.Errorf("get phone change: %w", err)
+	}
+	switch {
+	case pending == nil:
+	case pending.Attempts >= phoneCodeMaxAttempts:
+		return ErrPhoneCodeAttempts
+	case time.Since(pending.CreatedAt) < phoneCodeResend:
+		return ErrPhoneCodeTooSoon
+	}
+
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return fmt.Errorf("generate code: %w", err)
+	}
+	code := fmt.Sprintf("%06d", n.Int64())
+	now := time.Now()
+	change := &models.PhoneChange{
+		UserID:    userID,
+		Phone:     phone,
+		CodeHash:  hashCode(code),
+		ExpiresAt: now.Add(phoneCodeExpiry),
+		CreatedAt: now,
+	}
+	if err := s.db.PutPhoneChange(change); err != nil {
+		return fmt.Errorf("store phone change: %w", err)
+	}
+
+	err = s.sms.Publish(ctx, smsoutbox.OutboundMessage{
+		ID:        fmt.Sprintf("portal-phone-%s-%d", userID, now.UnixNano()),
+		To:        "+" + identity.NormalizePhone(phone),
+		Body:      fmt.Sprintf("Your portal verification code is %s. It expires in %d minutes.", code, int(phoneCodeExpiry.Minutes())),
+		Source:    "portal",
+		CreatedAt: now,
+	})
+	if err != nil {
+		return fmt.Errorf("send verification code: %w", err)
+	}
+	return nil
+}
+
+// ConfirmPhoneChange applies the user's pending phone change if code is
+// the one texted for it. After phoneCodeMaxAttempts wrong codes no code is
+// accepted, including ones texted since, until the change expires.
+func (s *Service) ConfirmPhoneChange(userID, code string) error {
+	change, err := s.db.GetPhoneChange(userID)
+	if err != nil {
+		return fmt.Errorf("get phone change: %w", err)
+	}
+	if change == nil || change.Attempts >= phoneCodeMaxAttempts {
+		return ErrInvalidPhoneCode
+	}
+	if subtle.ConstantTimeCompare([]byte(hashCode(strings.TrimSpace(code))), []byte(change.CodeHash)) != 1 {
+		if err := s.db.CountPhoneChangeAttempt(userID); err != nil {
+			return fmt.Errorf("count attempt: %w", err)
+		}
+		return ErrInvalidPhoneCode
+	}
+
+	// The number may have been taken while the code was in flight.
+	pHash := identity.PhoneHash(change.Phone)
+	if err := s.checkPhone(pHash, userID); err != nil {
+		return err
+	}
+	if err := s.db.DeletePhoneChange(userID); err != nil {
+		return fmt.Errorf("delete phone change: %w", err)
+	}
+	if err := s.db.UpdateUserPhone(userID, change.Phone, pHash); err != nil {
+		return fmt.Errorf("update user phone: %w", err)
+	}
+	return nil
+}
+
+func hashCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// SetAvatar stores the image read from r as the user's avatar, replacing
+// any they had, and returns its URL. Returns upload.ErrInvalidImage for anything but a small
+// PNG, JPEG or GIF.
+func (s *Service) SetAvatar(ctx context.Context, user *models.User, r io.Reader) (string, error) {
+	if s.uploads == nil {
+		return "", fmt.Errorf("avatar uploads are not configured")
+	}
+	data, ext, err := upload.ReadImage(r)
+	if err != nil {
+		return "", err
+	}
+	key := upload.Key("avatars/"+user.ID, data, ext)
+	if err := s.uploads.Put(ctx, key, data); err != nil {
+		return "", fmt.Errorf("store avatar: %w", err)
+	}
+	if err := s.db.UpdateUserAvatar(user.ID, key); err != nil {
+		return "", fmt.Errorf("update avatar: %w", err)
+	}
+	s.deleteAvatar(ctx, user.AvatarKey, key)
+	user.AvatarKey = key
+	return user.AvatarURL(), nil
+}
+
+// RemoveAvatar clears the user's avatar.
+func (s *Service) RemoveAvatar(ctx context.Context, user *models.User) error {
+	if err := s.db.UpdateUserAvatar(user.ID, ""); err != nil {
+		return fmt.Errorf("update avatar: %w", err)
+	}
+	s.deleteAvatar(ctx, user.AvatarKey, "")
+	user.AvatarKey = ""
+	return nil
+}
+
+// deleteAvatar deletes the avatar stored under old, unless it is the one
+// now in use: the same image uploaded twice has the same key. Keys are
+// per user, so no one else's avatar shares it.
+func (s *Service) deleteAvatar(ctx context.Context, old, current string) {
+	if old == "" || old == current || s.uploads == nil {
+		return
+	}
+	if err := s.uploads.Delete(ctx, old); err != nil {
+		slog.Warn("delete old avatar", "key", old, "err", err)
+	}
+}
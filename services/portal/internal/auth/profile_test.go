@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
+	"github.com/jredh-dev/nexus/services/portal/config"
+	"github.com/jredh-dev/nexus/services/portal/internal/database"
+	"github.com/jredh-dev/nexus/services/portal/pkg/models"
+)
+
+// texts records the messages it is given instead of sending them.
+type texts []smsoutbox.OutboundMessage
+
+func (t *texts) Publish(_ context.Context, msg smsoutbox.OutboundMessage) error {
+	*t = append(*t, msg)
+	return nil
+}
+
+// newPhoneTest returns a Service that texts into sent, and its database,
+// holding the user u1.
+func newPhoneTest(t *testing.T) (*Service, *database.DB, *texts) {
+	t.Helper()
+	db, err := database.New(filepath.Join(t.TempDir(), "portal.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	now := time.Now()
+	if err := db.CreateUser(&models.User{ID: "u1", Username: "u1", Email: "u1@example.com", CreatedAt: now, UpdatedAt: now, LastLoginAt: now}); err != nil {
+		t.Fatal(err)
+	}
+	sent := &texts{}
+	return New(db, &config.Config{}, WithSMS(sent)), db, sent
+}
+
+func TestInitiatePhoneChange_Cooldown(t *testing.T) {
+	s, db, sent := newPhoneTest(t)
+	ctx := context.Background()
+
+	if err := s.InitiatePhoneChange(ctx, "u1", "5551234567"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.InitiatePhoneChange(ctx, "u1", "5559876543"); !errors.Is(err, ErrPhoneCodeTooSoon) {
+		t.Errorf("second code at once: err = %v, want ErrPhoneCodeTooSoon", err)
+	}
+	if len(*sent) != 1 {
+		t.Errorf("sent %d texts, want 1", len(*sent))
+	}
+
+	// Once the cooldown is over another code may be sent.
+	c, err := db.GetPhoneChange("u1")
+	if err != nil || c == nil {
+		t.Fatalf("GetPhoneChange = %+v, %v", c, err)
+	}
+	c.CreatedAt = c.CreatedAt.Add(-phoneCodeResend)
+	if err := db.PutPhoneChange(c); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.InitiatePhoneChange(ctx, "u1", "5559876543"); err != nil {
+		t.Errorf("code after the cooldown: %v", err)
+	}
+	if len(*sent) != 2 {
+		t.Errorf("sent %d texts, want 2", len(*sent))
+	}
+}
+
+func TestConfirmPhoneChange_ResendKeepsAttempts(t *testing.T) {
+	s, db, sent := newPhoneTest(t)
+	ctx := context.Background()
+	code := regexp.MustCompile(`\d{6}`)
+	// guess enters a wrong code for the last one texted.
+	guess := func() {
+		t.Helper()
+		wrong := "000000"
+		if code.FindString((*sent)[len(*sent)-1].Body) == wrong {
+			wrong = "111111"
+		}
+		if err := s.ConfirmPhoneChange("u1", wrong); !errors.Is(err, ErrInvalidPhoneCode) {
+			t.Fatalf("wrong code: err = %v", err)
+		}
+	}
+	// resend texts a new code once the cooldown allows.
+	resend := func() error {
+		t.Helper()
+		c, err := db.GetPhoneChange("u1")
+		if err != nil || c == nil {
+			t.Fatalf("GetPhoneChange = %+v, %v", c, err)
+		}
+		c.CreatedAt = c.CreatedAt.Add(-phoneCodeResend)
+		if err := db.PutPhoneChange(c); err != nil {
+			t.Fatal(err)
+		}
+		return s.InitiatePhoneChange(ctx, "u1", "5551234567")
+	}
+
+	if err := s.InitiatePhoneChange(ctx, "u1", "5551234567"); err != nil {
+		t.Fatal(err)
+	}
+	for range phoneCodeMaxAttempts - 1 {
+		guess()
+	}
+	if err := resend(); err != nil {
+		t.Fatalf("resend with a guess left: %v", err)
+	}
+	guess()
+
+	// The new code brought no guesses back, so even it is refused now, and
+	// no more are texted until the change expires.
+	right := code.FindString((*sent)[len(*sent)-1].Body)
+	if err := s.ConfirmPhoneChange("u1", right); !errors.Is(err, ErrInvalidPhoneCode) {
+		t.Errorf("right code after %d wrong ones: err = %v, want ErrInvalidPhoneCode", phoneCodeMaxAttempts, err)
+	}
+	if err := resend(); !errors.Is(err, ErrPhoneCodeAttempts) {
+		t.Errorf("resend after %d wrong codes: err = %v, want ErrPhoneCodeAttempts", phoneCodeMaxAttempts, err)
+	}
+	if len(*sent) != 2 {
+		t.Errorf("sent %d texts, want 2", len(*sent))
+	}
+}
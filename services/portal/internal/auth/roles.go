@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"fmt"
+
+	"github.com/jredh-dev/nexus/internal/authz"
+	"github.com/jredh-dev/nexus/services/portal/pkg/models"
+)
+
+// Roles grants each portal role its permissions.
+var Roles = authz.New(map[string][]authz.Permission{
+	models.RoleUser:      nil,
+	models.RoleModerator: {authz.GiveawayManage},
+	models.RoleSupport:   {authz.UsersManage, authz.MagicLinkIssue},
+	models.RoleAdmin:     authz.All,
+})
+
+// Can reports whether user's role grants perm. A nil user may do nothing.
+func Can(user *models.User, perm authz.Permission) bool {
+	return user != nil && Roles.Can(user.Role, perm)
+}
+
+// UpdateUserRole changes a user's role.
+func (s *Service) UpdateUserRole(userID, role string) error {
+	if !Roles.Valid(role) {
+		return fmt.Errorf("%w: %s", ErrInvalidRole, role)
+	}
+	return s.db.UpdateUserRole(userID, role)
+}
+
+// AssignRole changes another user's role on behalf of actor, who must be
+// allowed to manage users. Actor can only hand out, or take away, a role
+// whose permissions they hold themselves, so no one can raise anyone above
+// their own standing. Returns ErrForbidden otherwise, and for actor's own
+// role, so the last admin can't demote themselves.
+func (s *Service) AssignRole(actor *models.User, userID, role string) error {
+	if !Roles.Valid(role) {
+		return fmt.Errorf("%w: %s", ErrInvalidRole, role)
+	}
+	if !Can(actor, authz.UsersManage) || actor.ID == userID {
+		return ErrForbidden
+	}
+	user, err := s.db.GetUserByID(userID)
+	if err != nil {
+		return fmt.Errorf("get user: %w", err)
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	if !covers(actor.Role, user.Role) || !covers(actor.Role, role) {
+		return ErrForbidden
+	}
+	return s.db.UpdateUserRole(userID, role)
+}
+
+// covers reports whether role holds every permission other grants.
+func covers(role, other string) bool {
+	for _, perm := range Roles.Permissions(other) {
+		if !Roles.Can(role, perm) {
+			return false
+		}
+	}
+	return true
+}
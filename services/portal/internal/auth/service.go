@@ -1,13 +1,20 @@
 package auth
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
+	"github.com/jredh-dev/nexus/internal/sso"
+	"github.com/jredh-dev/nexus/internal/upload"
 	"github.com/jredh-dev/nexus/services/portal/config"
+	"github.com/jredh-dev/nexus/services/portal/internal/cookie"
 	"github.com/jredh-dev/nexus/services/portal/internal/database"
 	"github.com/jredh-dev/nexus/services/portal/internal/mailer"
 	"github.com/jredh-dev/nexus/services/portal/pkg/identity"
@@ -17,17 +24,53 @@ import (
 
 const bcryptCost = 12
 
+// CleanupInterval is how often RunCleanup clears out expired sessions and
+// tokens.
+const CleanupInterval = time.Hour
+
+// AnalyticsRetention is how long logins and searches are kept for the
+// admin analytics page.
+const AnalyticsRetention = 90 * 24 * time.Hour
+
 // Service handles authentication operations.
 type Service struct {
-	db     *database.DB
-	cfg    *config.Config
-	mailer *mailer.Mailer
+	db       *database.DB
+	cfg      *config.Config
+	mailer   *mailer.Mailer
+	cookies  *cookie.Codec
+	waitlist func() bool
+	sms      smsoutbox.Publisher // nil when codes can't be texted
+	uploads  upload.Store        // nil when avatars can't be stored
+}
+
+// Option configures a Service.
+type Option func(*Service)
+
+// WithWaitlist consults on before each sign-up: while it reports true,
+// sign-ups join the waitlist instead of creating accounts.
+func WithWaitlist(on func() bool) Option {
+	return func(s *Service) { s.waitlist = on }
+}
+
+// WithSMS texts phone verification codes through pub.
+func WithSMS(pub smsoutbox.Publisher) Option {
+	return func(s *Service) { s.sms = pub }
+}
+
+// WithUploads keeps avatars in store.
+func WithUploads(store upload.Store) Option {
+	return func(s *Service) { s.uploads = store }
 }
 
 // New creates a new auth service.
-func New(db *database.DB, cfg *config.Config) *Service {
+func New(db *database.DB, cfg *config.Config, opts ...Option) *Service {
 	m := mailer.New(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.From)
-	return &Service{db: db, cfg: cfg, mailer: m}
+	cookies := cookie.New(cfg.Session.Secret, cfg.Session.Previous, cfg.Session.Encrypt)
+	s := &Service{db: db, cfg: cfg, mailer: m, cookies: cookies, waitlist: func() bool { return false }}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // HashPassword hashes a plaintext password with bcrypt.
@@ -47,14 +90,11 @@ func CheckPassword(password, hash string) error {
 // Signup registers a new user after checking for identity duplicates.
 // It normalizes and hashes the email and phone number, then checks
 // that no existing user shares the same username, email hash, or phone hash.
+// In waitlist mode it adds the email to the waitlist instead and returns
+// ErrWaitlisted. The phone number starts out unverified; see SendPhoneCode.
 func (s *Service) Signup(username, email, phone, password, name string) (*models.User, error) {
-	// Check username uniqueness.
-	existing, err := s.db.GetUserByUsername(username)
-	if err != nil {
-		return nil, fmt.Errorf("check username: %w", err)
-	}
-	if existing != nil {
-		return nil, ErrUsernameTaken
+	if err := s.checkUsername(username, ""); err != nil {
+		return nil, err
 	}
 
 	// Compute identity hashes.
@@ -62,7 +102,7 @@ func (s *Service) Signup(username, email, phone, password, name string) (*models
 	pHash := identity.PhoneHash(phone)
 
 	// Check email dedup.
-	existing, err = s.db.GetUserByEmailHash(eHash)
+	existing, err := s.db.GetUserByEmailHash(eHash)
 	if err != nil {
 		return nil, fmt.Errorf("check email hash: %w", err)
 	}
@@ -70,13 +110,15 @@ func (s *Service) Signup(username, email, phone, password, name string) (*models
 		return nil, ErrEmailTaken
 	}
 
-	// Check phone dedup.
-	existing, err = s.db.GetUserByPhoneHash(pHash)
-	if err != nil {
-		return nil, fmt.Errorf("check phone hash: %w", err)
+	if err := s.checkPhone(pHash, ""); err != nil {
+		return nil, err
 	}
-	if existing != nil {
-		return nil, ErrPhoneTaken
+
+	if s.waitlist() {
+		if err := s.db.JoinWaitlist(email, username, name, time.Now()); err != nil {
+			return nil, fmt.Errorf("join waitlist: %w", err)
+		}
+		return nil, ErrWaitlisted
 	}
 
 	// Hash password.
@@ -108,7 +150,7 @@ func (s *Service) Signup(username, email, phone, password, name string) (*models
 }
 
 // Login verifies credentials and creates a new session.
-// Returns the session ID (used as cookie value).
+// Returns the session cookie value, which seals the session ID.
 func (s *Service) Login(email, password, ipAddress, userAgent string) (string, error) {
 	user, err := s.db.GetUserByEmail(email)
 	if err != nil {
@@ -141,13 +183,22 @@ func (s *Service) Login(email, password, ipAddress, userAgent string) (string, e
 	if err := s.db.UpdateLastLogin(user.ID, now); err != nil {
 		return "", fmt.Errorf("update last login: %w", err)
 	}
+	if err := s.db.RecordLogin(user.ID, models.LoginPassword, now); err != nil {
+		slog.Warn("record login", "user_id", user.ID, "err", err)
+	}
 
-	return session.ID, nil
+	return s.cookies.Encode(session.ID), nil
 }
 
-// ValidateSession looks up a session by ID and returns the associated user.
-// Returns (nil, nil) if the session does not exist or has expired.
-func (s *Service) ValidateSession(sessionID string) (*models.User, *models.Session, error) {
+// ValidateSession looks up the session a cookie value from Login names and
+// returns the associated user. Returns (nil, nil) if the cookie is forged
+// or tampered with, which is checked before the database, or the session
+// does not exist or has expired.
+func (s *Service) ValidateSession(cookieValue string) (*models.User, *models.Session, error) {
+	sessionID, err := s.cookies.Decode(cookieValue)
+	if err != nil {
+		return nil, nil, nil
+	}
 	session, err := s.db.GetSession(sessionID)
 	if err != nil {
 		return nil, nil, fmt.Errorf("get session: %w", err)
@@ -169,8 +220,13 @@ func (s *Service) ValidateSession(sessionID string) (*models.User, *models.Sessi
 	return user, session, nil
 }
 
-// Logout deletes a session.
-func (s *Service) Logout(sessionID string) error {
+// Logout deletes the session a cookie value names. A cookie that doesn't
+// decode names no session, so there is nothing to delete.
+func (s *Service) Logout(cookieValue string) error {
+	sessionID, err := s.cookies.Decode(cookieValue)
+	if err != nil {
+		return nil
+	}
 	return s.db.DeleteSession(sessionID)
 }
 
@@ -207,17 +263,35 @@ func (s *Service) GetSessionsByUserID(userID string) ([]models.Session, error) {
 	return s.db.GetSessionsByUserID(userID)
 }
 
-// CleanExpiredSessions removes all expired sessions from the database.
-func (s *Service) CleanExpiredSessions() error {
-	return s.db.DeleteExpiredSessions()
+// CleanExpired removes expired sessions, expired or used magic-link and
+// email-change tokens, expired phone changes, and analytics older than
+// AnalyticsRetention from the database.
+func (s *Service) CleanExpired() error {
+	return errors.Join(
+		s.db.DeleteExpiredSessions(),
+		s.db.DeleteExpiredMagicTokens(),
+		s.db.DeleteExpiredEmailChangeTokens(),
+		s.db.DeleteExpiredPhoneChanges(),
+		s.db.DeleteAnalyticsBefore(time.Now().Add(-AnalyticsRetention)),
+	)
 }
 
-// UpdateUserRole changes a user's role.
-func (s *Service) UpdateUserRole(userID, role string) error {
-	if role != models.RoleUser && role != models.RoleAdmin {
-		return fmt.Errorf("invalid role: %s", role)
+// RunCleanup calls CleanExpired every CleanupInterval until ctx is
+// cancelled.
+func (s *Service) RunCleanup(ctx context.Context) {
+	t := time.NewTicker(CleanupInterval)
+	defer t.Stop()
+
+	for {
+		if err := s.CleanExpired(); err != nil {
+			slog.Error("clean expired sessions and tokens", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
 	}
-	return s.db.UpdateUserRole(userID, role)
 }
 
 // --- Magic link operations ---
@@ -261,7 +335,7 @@ func (s *Service) CreateMagicToken(email string) (string, error) {
 }
 
 // ValidateMagicToken checks and consumes a magic token, returning the user
-// and creating a new session. Returns the session ID (cookie value).
+// and creating a new session. Returns the session cookie value.
 func (s *Service) ValidateMagicToken(token, ipAddress, userAgent string) (string, error) {
 	mt, err := s.db.GetMagicToken(token)
 	if err != nil {
@@ -294,8 +368,11 @@ func (s *Service) ValidateMagicToken(token, ipAddress, userAgent string) (string
 	if err := s.db.UpdateLastLogin(mt.UserID, now); err != nil {
 		return "", fmt.Errorf("update last login: %w", err)
 	}
+	if err := s.db.RecordLogin(mt.UserID, models.LoginMagic, now); err != nil {
+		slog.Warn("record login", "user_id", mt.UserID, "err", err)
+	}
 
-	return session.ID, nil
+	return s.cookies.Encode(session.ID), nil
 }
 
 // --- Email change operations ---
@@ -380,11 +457,86 @@ func (s *Service) ConfirmEmailChange(token string) (string, error) {
 	return ect.UserID, nil
 }
 
-// DeleteAccount deletes the user and all associated sessions/tokens.
+// DeleteAccount deletes the user, all associated sessions/tokens, and their avatar.
 // The caller should clear the session cookie after this returns.
 func (s *Service) DeleteAccount(userID string) error {
+	user, err := s.db.GetUserByID(userID)
+	if err != nil {
+		return fmt.Errorf("lookup user: %w", err)
+	}
 	if err := s.db.DeleteUser(userID); err != nil {
 		return fmt.Errorf("delete user: %w", err)
 	}
+	if user != nil {
+		s.deleteAvatar(context.Background(), user.AvatarKey, "")
+	}
 	return nil
 }
+
+// --- Single sign-on ---
+
+// ssoIdentity is how the other nexus services see user, until expires.
+func ssoIdentity(user *models.User, expires time.Time) sso.Identity {
+	return sso.Identity{
+		UserID:   user.ID,
+		Username: user.Username,
+		Email:    user.Email,
+		Name:     user.Name,
+		Role:     user.Role,
+		Expires:  expires.Unix(),
+	}
+}
+
+// IssueToken signs a JWT that other nexus services accept as user until
+// the returned expiry. Returns ErrSSODisabled when SSO_SECRET is not set,
+// and ErrPhoneUnverified until the user's phone number is verified.
+func (s *Service) IssueToken(user *models.User) (string, time.Time, error) {
+	if s.cfg.SSO.Secret == "" {
+		return "", time.Time{}, ErrSSODisabled
+	}
+	if s.NeedsPhoneVerification(user) {
+		return "", time.Time{}, ErrPhoneUnverified
+	}
+	now := time.Now()
+	expires := now.Add(s.cfg.SSO.TokenTTL)
+	token, err := sso.Sign([]byte(s.cfg.SSO.Secret), ssoIdentity(user, expires), now)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("sign token: %w", err)
+	}
+	return token, expires, nil
+}
+
+// Introspect reports whose credential token is: a session cookie value or
+// a JWT from IssueToken. Returns sso.ErrInvalidToken if it is neither, has expired,
+// or belongs to a deleted account or one whose phone number is not yet
+// verified.
+func (s *Service) Introspect(token string) (*sso.Identity, error) {
+	if sso.IsJWT(token) {
+		if s.cfg.SSO.Secret == "" {
+			return nil, sso.ErrInvalidToken
+		}
+		claimed, err := sso.Verify([]byte(s.cfg.SSO.Secret), token, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		user, err := s.db.GetUserByID(claimed.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("get user: %w", err)
+		}
+		if user == nil || s.NeedsPhoneVerification(user) {
+			return nil, sso.ErrInvalidToken
+		}
+		id := ssoIdentity(user, time.Unix(claimed.Expires, 0))
+		return &id, nil
+	}
+
+	user, session, err := s.ValidateSession(token)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || s.NeedsPhoneVerification(user) {
+		return nil, sso.ErrInvalidToken
+	}
+	id := ssoIdentity(user, session.ExpiresAt)
+	return &id, nil
+}
@@ -0,0 +1,62 @@
+package cookie
+
+import (
+	"strings"
+	"testing"
+)
+
+const id = "3f0c8a52-7d4e-4b8a-9c1e-2a6b5d7e9f10"
+
+func TestRoundTrip(t *testing.T) {
+	for _, encrypt := range []bool{false, true} {
+		c := New("secret", nil, encrypt)
+		v := c.Encode(id)
+		if strings.Contains(v, id) == encrypt {
+			t.Errorf("encrypt=%v: cookie %q", encrypt, v)
+		}
+		if got, err := c.Decode(v); got != id || err != nil {
+			t.Errorf("encrypt=%v: Decode = %q, %v", encrypt, got, err)
+		}
+		// Either kind opens whichever way the Codec seals.
+		if got, err := New("secret", nil, !encrypt).Decode(v); got != id || err != nil {
+			t.Errorf("encrypt=%v: Decode by the other kind = %q, %v", encrypt, got, err)
+		}
+	}
+}
+
+func TestRejectsTampering(t *testing.T) {
+	c := New("secret", nil, false)
+	signed := c.Encode(id)
+	encrypted := New("secret", nil, true).Encode(id)
+	for _, v := range []string{
+		"",
+		id,
+		"." + strings.SplitN(signed, ".", 2)[1],
+		strings.Replace(signed, "3f0c", "4f0c", 1),
+		signed[:len(signed)-2],
+		encrypted[:len(encrypted)-2] + "AA",
+		"not base64!",
+		New("other", nil, false).Encode(id),
+		New("other", nil, true).Encode(id),
+	} {
+		if got, err := c.Decode(v); err != ErrInvalid {
+			t.Errorf("Decode(%q) = %q, %v; want ErrInvalid", v, got, err)
+		}
+	}
+}
+
+func TestRotation(t *testing.T) {
+	for _, encrypt := range []bool{false, true} {
+		old := New("old", nil, encrypt).Encode(id)
+		rotated := New("new", []string{"old"}, encrypt)
+		if got, err := rotated.Decode(old); got != id || err != nil {
+			t.Errorf("encrypt=%v: cookie from the previous secret: %q, %v", encrypt, got, err)
+		}
+		if _, err := New("old", nil, encrypt).Decode(rotated.Encode(id)); err == nil {
+			t.Errorf("encrypt=%v: new cookies are sealed with the previous secret", encrypt)
+		}
+		if _, err := New("new", nil, encrypt).Decode(old); err != ErrInvalid {
+			t.Errorf("encrypt=%v: retired secret still accepted: %v", encrypt, err)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package database
+
+import (
+	"time"
+
+	"github.com/jredh-dev/nexus/services/portal/pkg/models"
+)
+
+// dayFormat is how action_searches keys its days.
+const dayFormat = "2006-01-02"
+
+// RecordLogin notes that userID signed in by method at t.
+func (db *DB) RecordLogin(userID, method string, t time.Time) error {
+	defer db.timed("record_login")()
+	_, err := db.conn.Exec(`INSERT INTO logins (user_id, method, created_at) VALUES (?, ?, ?)`, userID, method, t)
+	return err
+}
+
+// RecordSearch counts one magic-bar search for query on t's day.
+func (db *DB) RecordSearch(query string, t time.Time) error {
+	defer db.timed("record_search")()
+	const q = `INSERT INTO action_searches (day, query, count) VALUES (?, ?, 1)
+	           ON CONFLICT (day, query) DO UPDATE SET count = count + 1`
+	_, err := db.conn.Exec(q, t.UTC().Format(dayFormat), query)
+	return err
+}
+
+// DeleteAnalyticsBefore drops the logins and searches recorded before t.
+func (db *DB) DeleteAnalyticsBefore(t time.Time) error {
+	defer db.timed("delete_old_analytics")()
+	if _, err := db.conn.Exec(`DELETE FROM logins WHERE created_at < ?`, t); err != nil {
+		return err
+	}
+	_, err := db.conn.Exec(`DELETE FROM action_searches WHERE day < ?`, t.UTC().Format(dayFormat))
+	return err
+}
+
+// SignupsPerDay counts the accounts created on each day since since.
+func (db *DB) SignupsPerDay(since time.Time) ([]models.DayCount, error) {
+	defer db.timed("signups_per_day")()
+	return db.perDay(`SELECT created_at FROM users WHERE created_at >= ?`, since)
+}
+
+// LoginsPerDay counts the sign-ins on each day since since.
+func (db *DB) LoginsPerDay(since time.Time) ([]models.DayCount, error) {
+	defer db.timed("logins_per_day")()
+	return db.perDay(`SELECT created_at FROM logins WHERE created_at >= ?`, since)
+}
+
+// LoginsByMethod counts the sign-ins since since by method, and the
+// distinct users who made them.
+func (db *DB) LoginsByMethod(since time.Time) (byMethod map[string]int, users int, err error) {
+	defer db.timed("logins_by_method")()
+	rows, err := db.conn.Query(`SELECT method, COUNT(*) FROM logins WHERE created_at >= ? GROUP BY method`, since)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	byMethod = make(map[string]int)
+	for rows.Next() {
+		var method string
+		var n int
+		if err := rows.Scan(&method, &n); err != nil {
+			return nil, 0, err
+		}
+		byMethod[method] = n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	err = db.conn.QueryRow(`SELECT COUNT(DISTINCT user_id) FROM logins WHERE created_at >= ?`, since).Scan(&users)
+	return byMethod, users, err
+}
+
+// TopSearches returns the limit queries searched most since since's day.
+func (db *DB) TopSearches(since time.Time, limit int) ([]models.SearchCount, error) {
+	defer db.timed("top_searches")()
+	const q = `SELECT query, SUM(count) AS n FROM action_searches WHERE day >= ?
+	           GROUP BY query ORDER BY n DESC, query LIMIT ?`
+	rows, err := db.conn.Query(q, since.UTC().Format(dayFormat), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.SearchCount
+	for rows.Next() {
+		var s models.SearchCount
+		if err := rows.Scan(&s.Query, &s.Count); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+// perDay buckets the times query returns by UTC day, with a zero for each
+// day from since to today that has none. The times are bucketed here
+// rather than in SQL, where they are strings with a zone offset.
+func (db *DB) perDay(query string, since time.Time) ([]models.DayCount, error) {
+	rows, err := db.conn.Query(query, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	counts := make(map[string]int)
+	for rows.Next() {
+		var t time.Time
+		if err := rows.Scan(&t); err != nil {
+			return nil, err
+		}
+		counts[t.UTC().Format(dayFormat)]++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	var out []models.DayCount
+	today := time.Now().UTC().Format(dayFormat)
+	for d := since.UTC().Truncate(24 * time.Hour); ; d = d.AddDate(0, 0, 1) {
+		key := d.Format(dayFormat)
+		out = append(out, models.DayCount{Day: d, Count: counts[key]})
+		if key >= today {
+			return out, nil
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jredh-dev/nexus/services/portal/pkg/models"
+)
+
+func TestAnalytics(t *testing.T) {
+	db, err := New(filepath.Join(t.TempDir(), "portal.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	now := time.Now().UTC()
+	yesterday := now.AddDate(0, 0, -1)
+	for i, at := range []time.Time{yesterday, now, now, now.AddDate(0, 0, -40)} {
+		u := &models.User{ID: string(rune('a' + i)), Username: string(rune('a' + i)), Email: string(rune('a'+i)) + "@example.com", CreatedAt: at, UpdatedAt: at, LastLoginAt: at}
+		if err := db.CreateUser(u); err != nil {
+			t.Fatal(err)
+		}
+	}
+	since := now.AddDate(0, 0, -6)
+	signups, err := db.SignupsPerDay(since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(signups) != 7 || signups[5].Count != 1 || signups[6].Count != 2 {
+		t.Errorf("SignupsPerDay: %+v", signups)
+	}
+
+	for _, l := range []struct {
+		user, method string
+		at           time.Time
+	}{{"a", models.LoginPassword, now}, {"a", models.LoginMagic, now}, {"b", models.LoginPassword, yesterday}, {"c", models.LoginPassword, now.AddDate(0, 0, -100)}} {
+		if err := db.RecordLogin(l.user, l.method, l.at); err != nil {
+			t.Fatal(err)
+		}
+	}
+	byMethod, users, err := db.LoginsByMethod(since)
+	if err != nil || byMethod[models.LoginPassword] != 2 || byMethod[models.LoginMagic] != 1 || users != 2 {
+		t.Errorf("LoginsByMethod: %v, %d users, %v", byMethod, users, err)
+	}
+
+	for _, q := range []string{"login", "giveaway", "login", "logout"} {
+		if err := db.RecordSearch(q, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.RecordSearch("giveaway", now.AddDate(0, 0, -100)); err != nil {
+		t.Fatal(err)
+	}
+	top, err := db.TopSearches(since, 2)
+	if err != nil || len(top) != 2 || top[0] != (models.SearchCount{Query: "login", Count: 2}) || top[1].Query != "giveaway" || top[1].Count != 1 {
+		t.Errorf("TopSearches: %+v, %v", top, err)
+	}
+
+	// Pruning keeps what's recent.
+	if err := db.DeleteAnalyticsBefore(now.AddDate(0, 0, -90)); err != nil {
+		t.Fatal(err)
+	}
+	if _, users, _ := db.LoginsByMethod(now.AddDate(0, 0, -200)); users != 2 {
+		t.Errorf("%d users logged in after pruning, want 2", users)
+	}
+	if top, _ := db.TopSearches(now.AddDate(0, 0, -200), 10); len(top) != 3 || top[1].Count != 1 {
+		t.Errorf("TopSearches after pruning: %+v", top)
+	}
+}
@@ -1,30 +1,30 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
 
+	"github.com/jredh-dev/nexus/internal/sqlitex"
 	"github.com/jredh-dev/nexus/services/portal/pkg/models"
-
-	_ "modernc.org/sqlite"
 )
 
 // DB wraps a SQLite connection.
 type DB struct {
-	conn *sql.DB
+	conn    *sql.DB
+	observe func(op string, d time.Duration) // nil when timings aren't collected
 }
 
 // New opens (or creates) the SQLite database and runs migrations.
 func New(path string) (*DB, error) {
-	conn, err := sql.Open("sqlite", path+"?_journal_mode=WAL&_busy_timeout=5000")
+	// One connection: requests queue for it rather than for SQLite's
+	// write lock.
+	conn, err := sqlitex.Open(path, sqlitex.WithMaxConns(1))
 	if err != nil {
 		return nil, fmt.Errorf("open database: %w", err)
 	}
 
-	// Single writer, many readers.
-	conn.SetMaxOpenConns(1)
-
 	if err := migrate(conn); err != nil {
 		conn.Close()
 		return nil, fmt.Errorf("migrate: %w", err)
@@ -33,11 +33,48 @@ func New(path string) (*DB, error) {
 	return &DB{conn: conn}, nil
 }
 
+// Ping checks that the database answers.
+func (db *DB) Ping(ctx context.Context) error {
+	return db.conn.PingContext(ctx)
+}
+
 // Close closes the database connection.
 func (db *DB) Close() error {
 	return db.conn.Close()
 }
 
+// RunCheckpoints folds the write-ahead log into the database every
+// sqlitex.CheckpointEvery until ctx is done.
+func (db *DB) RunCheckpoints(ctx context.Context) {
+	sqlitex.RunCheckpoints(ctx, db.conn, sqlitex.CheckpointEvery)
+}
+
+// Backup checks the database for corruption, then writes a copy of it to
+// dest while it stays in use.
+func (db *DB) Backup(ctx context.Context, dest string) error {
+	if err := sqlitex.IntegrityCheck(ctx, db.conn); err != nil {
+		return err
+	}
+	return sqlitex.Backup(ctx, db.conn, dest)
+}
+
+// SetObserver registers fn to receive the duration of each database
+// operation, keyed by a short operation name. Call it before the DB is
+// shared between goroutines.
+func (db *DB) SetObserver(fn func(op string, d time.Duration)) {
+	db.observe = fn
+}
+
+// timed starts timing op; the returned func reports the elapsed time and is
+// meant to be deferred.
+func (db *DB) timed(op string) func() {
+	if db.observe == nil {
+		return func() {}
+	}
+	start := time.Now()
+	return func() { db.observe(op, time.Since(start)) }
+}
+
 // migrate creates tables if they do not exist and applies schema updates.
 func migrate(conn *sql.DB) error {
 	const ddl = `
@@ -92,6 +129,38 @@ func migrate(conn *sql.DB) error {
 	);
 
 	CREATE INDEX IF NOT EXISTS idx_email_change_tokens_user_id ON email_change_tokens(user_id);
+
+	CREATE TABLE IF NOT EXISTS phone_changes (
+		user_id    TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+		phone      TEXT NOT NULL,
+		code_hash  TEXT NOT NULL,
+		attempts   INTEGER NOT NULL DEFAULT 0,
+		expires_at DATETIME NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS waitlist (
+		email      TEXT PRIMARY KEY,
+		username   TEXT NOT NULL DEFAULT '',
+		name       TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL
+	);
+
+	-- Analytics for /admin/analytics, pruned after auth.AnalyticsRetention.
+	CREATE TABLE IF NOT EXISTS logins (
+		user_id    TEXT NOT NULL,
+		method     TEXT NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_logins_created_at ON logins(created_at);
+
+	CREATE TABLE IF NOT EXISTS action_searches (
+		day   TEXT NOT NULL,
+		query TEXT NOT NULL,
+		count INTEGER NOT NULL,
+		PRIMARY KEY (day, query)
+	);
 	`
 	if _, err := conn.Exec(ddl); err != nil {
 		return err
@@ -101,6 +170,23 @@ func migrate(conn *sql.DB) error {
 	if err := addColumnIfNotExists(conn, "users", "role", "TEXT NOT NULL DEFAULT 'user'"); err != nil {
 		return err
 	}
+	if err := addColumnIfNotExists(conn, "users", "avatar_key", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	// Numbers given before verification existed are taken as verified, so
+	// only new sign-ups have to enter a code.
+	verified, err := hasColumn(conn, "users", "phone_verified")
+	if err != nil {
+		return err
+	}
+	if !verified {
+		if err := addColumnIfNotExists(conn, "users", "phone_verified", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+			return err
+		}
+		if _, err := conn.Exec(`UPDATE users SET phone_verified = 1 WHERE phone_number != ''`); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
@@ -109,9 +195,19 @@ func migrate(conn *sql.DB) error {
 // SQLite doesn't support IF NOT EXISTS for ALTER TABLE ADD COLUMN, so we
 // check the schema first.
 func addColumnIfNotExists(conn *sql.DB, table, column, colDef string) error {
+	exists, err := hasColumn(conn, table, column)
+	if err != nil || exists {
+		return err
+	}
+	_, err = conn.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, colDef))
+	return err
+}
+
+// hasColumn reports whether table has column.
+func hasColumn(conn *sql.DB, table, column string) (bool, error) {
 	rows, err := conn.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
 	if err != nil {
-		return err
+		return false, err
 	}
 	defer rows.Close()
 
@@ -122,29 +218,24 @@ func addColumnIfNotExists(conn *sql.DB, table, column, colDef string) error {
 		var dfltValue sql.NullString
 		var pk int
 		if err := rows.Scan(&cid, &name, &ctype, &notnull, &dfltValue, &pk); err != nil {
-			return err
+			return false, err
 		}
 		if name == column {
-			return nil // column already exists
+			return true, nil
 		}
 	}
-	if err := rows.Err(); err != nil {
-		return err
-	}
-
-	_, err = conn.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, colDef))
-	return err
+	return false, rows.Err()
 }
 
 // userColumns is the SELECT column list for user queries.
-const userColumns = `id, username, email, phone_number, name, role, password_hash, email_hash, phone_hash, created_at, updated_at, last_login_at`
+const userColumns = `id, username, email, phone_number, name, role, password_hash, email_hash, phone_hash, phone_verified, avatar_key, created_at, updated_at, last_login_at`
 
 // scanUser scans a row into a User model.
 func scanUser(row interface{ Scan(...interface{}) error }) (*models.User, error) {
 	u := &models.User{}
 	err := row.Scan(
 		&u.ID, &u.Username, &u.Email, &u.PhoneNumber, &u.Name, &u.Role,
-		&u.PasswordHash, &u.EmailHash, &u.PhoneHash,
+		&u.PasswordHash, &u.EmailHash, &u.PhoneHash, &u.PhoneVerified, &u.AvatarKey,
 		&u.CreatedAt, &u.UpdatedAt, &u.LastLoginAt,
 	)
 	if err == sql.ErrNoRows {
@@ -157,11 +248,12 @@ func scanUser(row interface{ Scan(...interface{}) error }) (*models.User, error)
 
 // CreateUser inserts a new user.
 func (db *DB) CreateUser(u *models.User) error {
-	const q = `INSERT INTO users (id, username, email, phone_number, name, role, password_hash, email_hash, phone_hash, created_at, updated_at, last_login_at)
-	           VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	defer db.timed("create_user")()
+	const q = `INSERT INTO users (id, username, email, phone_number, name, role, password_hash, email_hash, phone_hash, phone_verified, avatar_key, created_at, updated_at, last_login_at)
+	           VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 	_, err := db.conn.Exec(q,
 		u.ID, u.Username, u.Email, u.PhoneNumber, u.Name, u.Role,
-		u.PasswordHash, u.EmailHash, u.PhoneHash,
+		u.PasswordHash, u.EmailHash, u.PhoneHash, u.PhoneVerified, u.AvatarKey,
 		u.CreatedAt, u.UpdatedAt, u.LastLoginAt,
 	)
 	return err
@@ -169,36 +261,42 @@ func (db *DB) CreateUser(u *models.User) error {
 
 // GetUserByEmail looks up a user by email.
 func (db *DB) GetUserByEmail(email string) (*models.User, error) {
+	defer db.timed("user_by_email")()
 	q := `SELECT ` + userColumns + ` FROM users WHERE email = ?`
 	return scanUser(db.conn.QueryRow(q, email))
 }
 
 // GetUserByID looks up a user by ID.
 func (db *DB) GetUserByID(id string) (*models.User, error) {
+	defer db.timed("user_by_id")()
 	q := `SELECT ` + userColumns + ` FROM users WHERE id = ?`
 	return scanUser(db.conn.QueryRow(q, id))
 }
 
 // GetUserByUsername looks up a user by username (case-insensitive).
 func (db *DB) GetUserByUsername(username string) (*models.User, error) {
+	defer db.timed("user_by_username")()
 	q := `SELECT ` + userColumns + ` FROM users WHERE username = ? COLLATE NOCASE`
 	return scanUser(db.conn.QueryRow(q, username))
 }
 
 // GetUserByEmailHash looks up a user by normalized email hash.
 func (db *DB) GetUserByEmailHash(hash string) (*models.User, error) {
+	defer db.timed("user_by_email_hash")()
 	q := `SELECT ` + userColumns + ` FROM users WHERE email_hash = ?`
 	return scanUser(db.conn.QueryRow(q, hash))
 }
 
 // GetUserByPhoneHash looks up a user by normalized phone hash.
 func (db *DB) GetUserByPhoneHash(hash string) (*models.User, error) {
+	defer db.timed("user_by_phone_hash")()
 	q := `SELECT ` + userColumns + ` FROM users WHERE phone_hash = ?`
 	return scanUser(db.conn.QueryRow(q, hash))
 }
 
 // UpdateLastLogin sets the last_login_at timestamp.
 func (db *DB) UpdateLastLogin(userID string, t time.Time) error {
+	defer db.timed("update_last_login")()
 	const q = `UPDATE users SET last_login_at = ?, updated_at = ? WHERE id = ?`
 	_, err := db.conn.Exec(q, t, t, userID)
 	return err
@@ -208,6 +306,7 @@ func (db *DB) UpdateLastLogin(userID string, t time.Time) error {
 
 // CreateSession inserts a new session.
 func (db *DB) CreateSession(s *models.Session) error {
+	defer db.timed("create_session")()
 	const q = `INSERT INTO sessions (id, user_id, expires_at, created_at, ip_address, user_agent)
 	           VALUES (?, ?, ?, ?, ?, ?)`
 	_, err := db.conn.Exec(q, s.ID, s.UserID, s.ExpiresAt, s.CreatedAt, s.IPAddress, s.UserAgent)
@@ -216,6 +315,7 @@ func (db *DB) CreateSession(s *models.Session) error {
 
 // GetSession looks up a session by ID and ensures it has not expired.
 func (db *DB) GetSession(id string) (*models.Session, error) {
+	defer db.timed("session")()
 	const q = `SELECT id, user_id, expires_at, created_at, ip_address, user_agent
 	           FROM sessions WHERE id = ? AND expires_at > ?`
 	s := &models.Session{}
@@ -228,18 +328,21 @@ func (db *DB) GetSession(id string) (*models.Session, error) {
 
 // DeleteSession removes a session by ID.
 func (db *DB) DeleteSession(id string) error {
+	defer db.timed("delete_session")()
 	_, err := db.conn.Exec(`DELETE FROM sessions WHERE id = ?`, id)
 	return err
 }
 
 // DeleteExpiredSessions cleans up sessions that have passed their expiry.
 func (db *DB) DeleteExpiredSessions() error {
+	defer db.timed("delete_expired_sessions")()
 	_, err := db.conn.Exec(`DELETE FROM sessions WHERE expires_at <= ?`, time.Now())
 	return err
 }
 
 // GetSessionsByUserID returns all active sessions for a user.
 func (db *DB) GetSessionsByUserID(userID string) ([]models.Session, error) {
+	defer db.timed("sessions_by_user_id")()
 	const q = `SELECT id, user_id, expires_at, created_at, ip_address, user_agent
 	           FROM sessions WHERE user_id = ? AND expires_at > ? ORDER BY created_at DESC`
 	rows, err := db.conn.Query(q, userID, time.Now())
@@ -261,8 +364,30 @@ func (db *DB) GetSessionsByUserID(userID string) ([]models.Session, error) {
 
 // --- Role operations ---
 
+// ListUsers returns up to limit users, newest first.
+func (db *DB) ListUsers(limit int) ([]models.User, error) {
+	defer db.timed("list_users")()
+	q := `SELECT ` + userColumns + ` FROM users ORDER BY created_at DESC LIMIT ?`
+	rows, err := db.conn.Query(q, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *u)
+	}
+	return users, rows.Err()
+}
+
 // UpdateUserRole sets the role for a user.
 func (db *DB) UpdateUserRole(userID, role string) error {
+	defer db.timed("update_user_role")()
 	const q = `UPDATE users SET role = ?, updated_at = ? WHERE id = ?`
 	_, err := db.conn.Exec(q, role, time.Now(), userID)
 	return err
@@ -272,6 +397,7 @@ func (db *DB) UpdateUserRole(userID, role string) error {
 
 // CreateMagicToken inserts a new magic login token.
 func (db *DB) CreateMagicToken(t *models.MagicToken) error {
+	defer db.timed("create_magic_token")()
 	const q = `INSERT INTO magic_tokens (id, user_id, expires_at, created_at)
 	           VALUES (?, ?, ?, ?)`
 	_, err := db.conn.Exec(q, t.ID, t.UserID, t.ExpiresAt, t.CreatedAt)
@@ -280,6 +406,7 @@ func (db *DB) CreateMagicToken(t *models.MagicToken) error {
 
 // GetMagicToken retrieves a magic token by ID if it is unused and not expired.
 func (db *DB) GetMagicToken(id string) (*models.MagicToken, error) {
+	defer db.timed("magic_token")()
 	const q = `SELECT id, user_id, expires_at, created_at
 	           FROM magic_tokens WHERE id = ? AND used_at IS NULL AND expires_at > ?`
 	t := &models.MagicToken{}
@@ -292,6 +419,7 @@ func (db *DB) GetMagicToken(id string) (*models.MagicToken, error) {
 
 // ConsumeMagicToken marks a magic token as used.
 func (db *DB) ConsumeMagicToken(id string) error {
+	defer db.timed("consume_magic_token")()
 	const q = `UPDATE magic_tokens SET used_at = ? WHERE id = ?`
 	_, err := db.conn.Exec(q, time.Now(), id)
 	return err
@@ -299,6 +427,7 @@ func (db *DB) ConsumeMagicToken(id string) error {
 
 // DeleteExpiredMagicTokens cleans up tokens that have expired or been used.
 func (db *DB) DeleteExpiredMagicTokens() error {
+	defer db.timed("delete_expired_magic_tokens")()
 	const q = `DELETE FROM magic_tokens WHERE expires_at <= ? OR used_at IS NOT NULL`
 	_, err := db.conn.Exec(q, time.Now())
 	return err
@@ -308,6 +437,7 @@ func (db *DB) DeleteExpiredMagicTokens() error {
 
 // CreateEmailChangeToken inserts a new email-change verification token.
 func (db *DB) CreateEmailChangeToken(t *models.EmailChangeToken) error {
+	defer db.timed("create_email_change_token")()
 	const q = `INSERT INTO email_change_tokens (id, user_id, new_email, expires_at, created_at)
 	           VALUES (?, ?, ?, ?, ?)`
 	_, err := db.conn.Exec(q, t.ID, t.UserID, t.NewEmail, t.ExpiresAt, t.CreatedAt)
@@ -316,6 +446,7 @@ func (db *DB) CreateEmailChangeToken(t *models.EmailChangeToken) error {
 
 // GetEmailChangeToken retrieves an email-change token by ID if it is unused and not expired.
 func (db *DB) GetEmailChangeToken(id string) (*models.EmailChangeToken, error) {
+	defer db.timed("email_change_token")()
 	const q = `SELECT id, user_id, new_email, expires_at, created_at
 	           FROM email_change_tokens WHERE id = ? AND used_at IS NULL AND expires_at > ?`
 	t := &models.EmailChangeToken{}
@@ -328,6 +459,7 @@ func (db *DB) GetEmailChangeToken(id string) (*models.EmailChangeToken, error) {
 
 // ConsumeEmailChangeToken marks an email-change token as used.
 func (db *DB) ConsumeEmailChangeToken(id string) error {
+	defer db.timed("consume_email_change_token")()
 	const q = `UPDATE email_change_tokens SET used_at = ? WHERE id = ?`
 	_, err := db.conn.Exec(q, time.Now(), id)
 	return err
@@ -335,6 +467,7 @@ func (db *DB) ConsumeEmailChangeToken(id string) error {
 
 // DeleteExpiredEmailChangeTokens cleans up tokens that have expired or been used.
 func (db *DB) DeleteExpiredEmailChangeTokens() error {
+	defer db.timed("delete_expired_email_change_tokens")()
 	const q = `DELETE FROM email_change_tokens WHERE expires_at <= ? OR used_at IS NOT NULL`
 	_, err := db.conn.Exec(q, time.Now())
 	return err
@@ -342,13 +475,105 @@ func (db *DB) DeleteExpiredEmailChangeTokens() error {
 
 // UpdateUserEmail updates a user's email and email hash.
 func (db *DB) UpdateUserEmail(userID, newEmail, newEmailHash string) error {
+	defer db.timed("update_user_email")()
 	const q = `UPDATE users SET email = ?, email_hash = ?, updated_at = ? WHERE id = ?`
 	_, err := db.conn.Exec(q, newEmail, newEmailHash, time.Now(), userID)
 	return err
 }
 
+// UpdateUserProfile sets a user's display name and username.
+func (db *DB) UpdateUserProfile(userID, name, username string) error {
+	defer db.timed("update_user_profile")()
+	const q = `UPDATE users SET name = ?, username = ?, updated_at = ? WHERE id = ?`
+	_, err := db.conn.Exec(q, name, username, time.Now(), userID)
+	return err
+}
+
+// UpdateUserPhone updates a user's phone number and phone hash, and marks
+// the number verified: it is only changed once a code texted to it has
+// been entered.
+func (db *DB) UpdateUserPhone(userID, phone, phoneHash string) error {
+	defer db.timed("update_user_phone")()
+	const q = `UPDATE users SET phone_number = ?, phone_hash = ?, phone_verified = 1, updated_at = ? WHERE id = ?`
+	_, err := db.conn.Exec(q, phone, phoneHash, time.Now(), userID)
+	return err
+}
+
+// UpdateUserAvatar sets the upload key of a user's avatar; "" removes it.
+func (db *DB) UpdateUserAvatar(userID, key string) error {
+	defer db.timed("update_user_avatar")()
+	const q = `UPDATE users SET avatar_key = ?, updated_at = ? WHERE id = ?`
+	_, err := db.conn.Exec(q, key, time.Now(), userID)
+	return err
+}
+
+// --- Phone change operations ---
+
+// PutPhoneChange records a pending phone change, replacing any the user
+// already had. Wrong codes entered for one that hasn't expired as of
+// c.CreatedAt still count, so asking for a new code doesn't buy guesses.
+func (db *DB) PutPhoneChange(c *models.PhoneChange) error {
+	defer db.timed("put_phone_change")()
+	const q = `INSERT INTO phone_changes (user_id, phone, code_hash, attempts, expires_at, created_at)
+	           VALUES (?1, ?2, ?3, 0, ?4, ?5)
+	           ON CONFLICT (user_id) DO UPDATE SET
+	               phone = excluded.phone, code_hash = excluded.code_hash,
+	               attempts = CASE WHEN phone_changes.expires_at > ?5 THEN phone_changes.attempts ELSE 0 END,
+	               expires_at = excluded.expires_at, created_at = excluded.created_at`
+	_, err := db.conn.Exec(q, c.UserID, c.Phone, c.CodeHash, c.ExpiresAt, c.CreatedAt)
+	return err
+}
+
+// GetPhoneChange returns the user's pending phone change if it has not
+// expired.
+func (db *DB) GetPhoneChange(userID string) (*models.PhoneChange, error) {
+	defer db.timed("phone_change")()
+	const q = `SELECT user_id, phone, code_hash, attempts, expires_at, created_at
+	           FROM phone_changes WHERE user_id = ? AND expires_at > ?`
+	c := &models.PhoneChange{}
+	err := db.conn.QueryRow(q, userID, time.Now()).Scan(&c.UserID, &c.Phone, &c.CodeHash, &c.Attempts, &c.ExpiresAt, &c.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return c, err
+}
+
+// CountPhoneChangeAttempt records a wrong code entered for the user's
+// pending phone change.
+func (db *DB) CountPhoneChangeAttempt(userID string) error {
+	defer db.timed("count_phone_change_attempt")()
+	_, err := db.conn.Exec(`UPDATE phone_changes SET attempts = attempts + 1 WHERE user_id = ?`, userID)
+	return err
+}
+
+// DeletePhoneChange removes the user's pending phone change.
+func (db *DB) DeletePhoneChange(userID string) error {
+	defer db.timed("delete_phone_change")()
+	_, err := db.conn.Exec(`DELETE FROM phone_changes WHERE user_id = ?`, userID)
+	return err
+}
+
+// DeleteExpiredPhoneChanges cleans up phone changes that have expired.
+func (db *DB) DeleteExpiredPhoneChanges() error {
+	defer db.timed("delete_expired_phone_changes")()
+	_, err := db.conn.Exec(`DELETE FROM phone_changes WHERE expires_at <= ?`, time.Now())
+	return err
+}
+
+// --- Waitlist operations ---
+
+// JoinWaitlist adds email to the waitlist. Joining again keeps the first
+// time.
+func (db *DB) JoinWaitlist(email, username, name string, at time.Time) error {
+	defer db.timed("join_waitlist")()
+	const q = `INSERT INTO waitlist (email, username, name, created_at) VALUES (?, ?, ?, ?) ON CONFLICT DO NOTHING`
+	_, err := db.conn.Exec(q, email, username, name, at)
+	return err
+}
+
 // DeleteUser deletes a user by ID (cascades to sessions and tokens).
 func (db *DB) DeleteUser(userID string) error {
+	defer db.timed("delete_user")()
 	_, err := db.conn.Exec(`DELETE FROM users WHERE id = ?`, userID)
 	return err
 }
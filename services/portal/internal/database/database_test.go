@@ -0,0 +1,165 @@
+package database
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jredh-dev/nexus/services/portal/pkg/models"
+)
+
+func TestProfile(t *testing.T) {
+	db, err := New(filepath.Join(t.TempDir(), "portal.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	now := time.Now()
+	u := &models.User{ID: "u1", Username: "old", Email: "u1@example.com", CreatedAt: now, UpdatedAt: now, LastLoginAt: now}
+	if err := db.CreateUser(u); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.UpdateUserProfile("u1", "New Name", "new"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.UpdateUserAvatar("u1", "avatars/u1/abc.png"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := db.GetUserByID("u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "New Name" || got.Username != "new" || got.AvatarURL() != "/uploads/avatars/u1/abc.png" || got.PhoneVerified {
+		t.Errorf("after update: %+v", got)
+	}
+	if err := db.UpdateUserPhone("u1", "5551234567", "ph"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := db.GetUserByID("u1"); err != nil || got.PhoneNumber != "5551234567" || !got.PhoneVerified {
+		t.Errorf("after phone change: %+v, %v", got, err)
+	}
+
+	if err := db.PutPhoneChange(&models.PhoneChange{UserID: "u1", Phone: "5551234567", CodeHash: "h", ExpiresAt: now.Add(time.Minute), CreatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.CountPhoneChangeAttempt("u1"); err != nil {
+		t.Fatal(err)
+	}
+	c, err := db.GetPhoneChange("u1")
+	if err != nil || c == nil || c.Phone != "5551234567" || c.Attempts != 1 {
+		t.Fatalf("GetPhoneChange = %+v, %v", c, err)
+	}
+
+	// A new code keeps the wrong ones counted...
+	if err := db.PutPhoneChange(&models.PhoneChange{UserID: "u1", Phone: "5559876543", CodeHash: "h2", ExpiresAt: now.Add(time.Minute), CreatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+	if c, err := db.GetPhoneChange("u1"); err != nil || c == nil || c.Attempts != 1 || c.CodeHash != "h2" {
+		t.Errorf("replaced change = %+v, %v", c, err)
+	}
+	// ...until the change it replaces has expired.
+	later := now.Add(2 * time.Minute)
+	if err := db.PutPhoneChange(&models.PhoneChange{UserID: "u1", Phone: "5559876543", CodeHash: "h2", ExpiresAt: later.Add(time.Minute), CreatedAt: later}); err != nil {
+		t.Fatal(err)
+	}
+	if c, err := db.GetPhoneChange("u1"); err != nil || c == nil || c.Attempts != 0 {
+		t.Errorf("change replacing an expired one = %+v, %v", c, err)
+	}
+
+	if err := db.PutPhoneChange(&models.PhoneChange{UserID: "u1", Phone: "5559876543", CodeHash: "h3", ExpiresAt: now.Add(-time.Second), CreatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+	if c, err := db.GetPhoneChange("u1"); err != nil || c != nil {
+		t.Errorf("expired change = %+v, %v", c, err)
+	}
+	if err := db.DeleteExpiredPhoneChanges(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSearchUsers(t *testing.T) {
+	db, err := New(filepath.Join(t.TempDir(), "portal.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	now := time.Now()
+	for i, u := range []models.User{
+		{ID: "u1", Username: "ada", Email: "ada@example.com", Name: "Ada Lovelace"},
+		{ID: "u2", Username: "grace_h", Email: "grace@navy.mil", Name: "Grace Hopper"},
+		{ID: "u3", Username: "gracey", Email: "g@example.com"},
+	} {
+		u.CreatedAt = now.Add(time.Duration(i) * time.Second)
+		if err := db.CreateUser(&u); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for q, want := range map[string][]string{
+		"GRACE":   {"u3", "u2"},
+		"example": {"u3", "u1"},
+		"hopper":  {"u2"},
+		"_h":      {"u2"}, // _ is matched literally
+		"zzz":     nil,
+	} {
+		users, err := db.SearchUsers(q, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, u := range users {
+			got = append(got, u.ID)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("SearchUsers(%q) = %v, want %v", q, got, want)
+		}
+	}
+}
+
+func TestMigrateBackfillsPhoneVerified(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "portal.db")
+	db, err := New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for _, u := range []*models.User{
+		{ID: "u1", Username: "phone", Email: "u1@example.com", PhoneNumber: "5551234567", CreatedAt: now, UpdatedAt: now, LastLoginAt: now},
+		{ID: "u2", Username: "nophone", Email: "u2@example.com", CreatedAt: now, UpdatedAt: now, LastLoginAt: now},
+	} {
+		if err := db.CreateUser(u); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Back to the schema from before phone verification.
+	if _, err := db.conn.Exec(`ALTER TABLE users DROP COLUMN phone_verified`); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	db, err = New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for id, want := range map[string]bool{"u1": true, "u2": false} {
+		if got, err := db.GetUserByID(id); err != nil || got.PhoneVerified != want {
+			t.Errorf("%s: PhoneVerified = %v, %v; want %v", id, got.PhoneVerified, err, want)
+		}
+	}
+
+	// Once the column is there, migrating leaves unverified numbers alone.
+	u3 := &models.User{ID: "u3", Username: "new", Email: "u3@example.com", PhoneNumber: "5559876543", CreatedAt: now, UpdatedAt: now, LastLoginAt: now}
+	if err := db.CreateUser(u3); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrate(db.conn); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := db.GetUserByID("u3"); err != nil || got.PhoneVerified {
+		t.Errorf("u3 verified by a later migration: %+v, %v", got, err)
+	}
+}
@@ -7,9 +7,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/jredh-dev/nexus/internal/sqlitex"
 	"github.com/jredh-dev/nexus/services/portal/pkg/models"
-
-	_ "modernc.org/sqlite"
 )
 
 // GiveawayDB wraps a SQLite connection for the giveaway service.
@@ -19,13 +18,11 @@ type GiveawayDB struct {
 
 // NewGiveaway opens (or creates) the giveaway SQLite database and runs migrations.
 func NewGiveaway(path string) (*GiveawayDB, error) {
-	conn, err := sql.Open("sqlite", path+"?_journal_mode=WAL&_busy_timeout=5000")
+	conn, err := sqlitex.Open(path, sqlitex.WithMaxConns(1))
 	if err != nil {
 		return nil, fmt.Errorf("open giveaway database: %w", err)
 	}
 
-	conn.SetMaxOpenConns(1)
-
 	if err := migrateGiveaway(conn); err != nil {
 		conn.Close()
 		return nil, fmt.Errorf("migrate giveaway: %w", err)
@@ -212,6 +209,35 @@ func (db *GiveawayDB) ListClaims(status models.ClaimStatus) ([]models.Claim, err
 	return db.queryClaims(q)
 }
 
+// SearchItems returns up to limit items whose title or description
+// contains q, ignoring case, newest first.
+func (db *GiveawayDB) SearchItems(q string, limit int) ([]models.Item, error) {
+	const where = ` WHERE title LIKE ?1 ESCAPE '\' OR description LIKE ?1 ESCAPE '\'`
+	rows, err := db.conn.Query(`SELECT `+itemColumns+` FROM items`+where+` ORDER BY created_at DESC LIMIT ?2`, likePattern(q), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []models.Item
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+	return items, rows.Err()
+}
+
+// SearchClaims returns up to limit claims whose ID, claimer's name, email
+// or phone, or notes contain q, ignoring case, newest first.
+func (db *GiveawayDB) SearchClaims(q string, limit int) ([]models.Claim, error) {
+	const where = ` WHERE id LIKE ?1 ESCAPE '\' OR claimer_name LIKE ?1 ESCAPE '\' OR claimer_email LIKE ?1 ESCAPE '\'
+	           OR claimer_phone LIKE ?1 ESCAPE '\' OR notes LIKE ?1 ESCAPE '\'`
+	return db.queryClaims(`SELECT `+claimColumns+` FROM claims`+where+` ORDER BY created_at DESC LIMIT ?2`, likePattern(q), limit)
+}
+
 // UpdateClaimStatus updates a claim's status.
 func (db *GiveawayDB) UpdateClaimStatus(id string, status models.ClaimStatus) error {
 	const q = `UPDATE claims SET status = ?, updated_at = ? WHERE id = ?`
@@ -239,3 +265,27 @@ func (db *GiveawayDB) queryClaims(query string, args ...interface{}) ([]models.C
 	}
 	return claims, rows.Err()
 }
+
+// ClaimStats counts the items, the items claimed and the claims by status.
+func (db *GiveawayDB) ClaimStats() (*models.ClaimStats, error) {
+	s := &models.ClaimStats{ByStatus: make(map[string]int)}
+	const q = `SELECT COUNT(*), (SELECT COUNT(DISTINCT item_id) FROM claims) FROM items`
+	if err := db.conn.QueryRow(q).Scan(&s.Items, &s.ClaimedItems); err != nil {
+		return nil, err
+	}
+	rows, err := db.conn.Query(`SELECT status, COUNT(*) FROM claims GROUP BY status`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var status string
+		var n int
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, err
+		}
+		s.ByStatus[status] = n
+		s.Claims += n
+	}
+	return s, rows.Err()
+}
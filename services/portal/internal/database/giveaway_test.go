@@ -330,3 +330,45 @@ func TestGiveawayDB_ListClaims(t *testing.T) {
 		t.Errorf("len (pending) = %d, want 1", len(pending))
 	}
 }
+
+func TestGiveawayDB_Search(t *testing.T) {
+	db := setupTestGiveawayDB(t)
+	now := time.Now().Truncate(time.Second)
+
+	for _, item := range []models.Item{
+		{ID: "desk", Title: "Standing Desk", Description: "Adjustable", Condition: models.ConditionGood, Status: models.ItemStatusAvailable, CreatedAt: now, UpdatedAt: now},
+		{ID: "lamp", Title: "Lamp", Description: "Goes on a desk", Condition: models.ConditionGood, Status: models.ItemStatusAvailable, CreatedAt: now.Add(time.Second), UpdatedAt: now},
+		{ID: "bike", Title: "Bike", Description: "100% working", Condition: models.ConditionFair, Status: models.ItemStatusAvailable, CreatedAt: now, UpdatedAt: now},
+	} {
+		if err := db.CreateItem(&item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	claim := &models.Claim{ID: "claim-1", ItemID: "desk", ClaimerName: "Ada Lovelace", ClaimerEmail: "ada@example.com", Status: models.ClaimStatusPending, CreatedAt: now, UpdatedAt: now}
+	if err := db.CreateClaim(claim); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := db.SearchItems("DESK", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].ID != "lamp" || items[1].ID != "desk" {
+		t.Errorf("SearchItems(DESK) = %+v", items)
+	}
+	// % is matched literally, not as a wildcard.
+	if items, _ := db.SearchItems("0%", 10); len(items) != 1 || items[0].ID != "bike" {
+		t.Errorf("SearchItems(0%%) = %+v", items)
+	}
+	if items, _ := db.SearchItems("desk", 1); len(items) != 1 {
+		t.Errorf("SearchItems limit 1 = %d items", len(items))
+	}
+
+	claims, err := db.SearchClaims("lovelace", 10)
+	if err != nil || len(claims) != 1 || claims[0].ID != "claim-1" {
+		t.Errorf("SearchClaims = %+v, %v", claims, err)
+	}
+	if claims, _ := db.SearchClaims("nobody", 10); len(claims) != 0 {
+		t.Errorf("SearchClaims(nobody) = %+v", claims)
+	}
+}
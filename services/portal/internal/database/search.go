@@ -0,0 +1,36 @@
+package database
+
+import (
+	"strings"
+
+	"github.com/jredh-dev/nexus/services/portal/pkg/models"
+)
+
+// likePattern returns a LIKE pattern, escaped with '\', matching anything
+// that contains q.
+func likePattern(q string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + r.Replace(q) + "%"
+}
+
+// SearchUsers returns up to limit users whose email, username or name
+// contains q, ignoring case, newest first.
+func (db *DB) SearchUsers(q string, limit int) ([]models.User, error) {
+	defer db.timed("search_users")()
+	const where = ` WHERE email LIKE ?1 ESCAPE '\' OR username LIKE ?1 ESCAPE '\' OR name LIKE ?1 ESCAPE '\'`
+	rows, err := db.conn.Query(`SELECT `+userColumns+` FROM users`+where+` ORDER BY created_at DESC LIMIT ?2`, likePattern(q), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *u)
+	}
+	return users, rows.Err()
+}
@@ -7,6 +7,7 @@ import (
 
 	portalv1 "github.com/jredh-dev/nexus/gen/portal/v1"
 	"github.com/jredh-dev/nexus/gen/portal/v1/portalv1connect"
+	"github.com/jredh-dev/nexus/internal/authz"
 	"github.com/jredh-dev/nexus/services/portal/internal/actions"
 	"github.com/jredh-dev/nexus/services/portal/internal/auth"
 )
@@ -36,7 +37,7 @@ func (s *ActionsServer) Search(
 	if sessionID != "" {
 		if user, _, err := s.auth.ValidateSession(sessionID); err == nil && user != nil {
 			searchCtx.LoggedIn = true
-			searchCtx.IsAdmin = user.IsAdmin()
+			searchCtx.Can = func(perm authz.Permission) bool { return auth.Can(user, perm) }
 		}
 	}
 
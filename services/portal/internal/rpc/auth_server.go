@@ -4,13 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
 	"strings"
 
 	"connectrpc.com/connect"
 
 	portalv1 "github.com/jredh-dev/nexus/gen/portal/v1"
 	"github.com/jredh-dev/nexus/gen/portal/v1/portalv1connect"
+	"github.com/jredh-dev/nexus/internal/authz"
+	"github.com/jredh-dev/nexus/internal/logging"
 	"github.com/jredh-dev/nexus/services/portal/config"
 	"github.com/jredh-dev/nexus/services/portal/internal/auth"
 	"github.com/jredh-dev/nexus/services/portal/pkg/models"
@@ -49,7 +50,7 @@ func (s *AuthServer) Login(
 
 	sessionID, err := s.auth.Login(email, password, ipAddress, userAgent)
 	if err != nil {
-		log.Printf("Login failed for %s: %v", email, err)
+		logging.FromContext(ctx).Info("login failed", "email", email, "err", err)
 		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("invalid email or password"))
 	}
 
@@ -78,7 +79,7 @@ func (s *AuthServer) Signup(
 
 	user, err := s.auth.Signup(username, email, phone, password, name)
 	if err != nil {
-		log.Printf("Signup failed for %s: %v", email, err)
+		logging.FromContext(ctx).Info("signup failed", "email", email, "err", err)
 
 		switch {
 		case errors.Is(err, auth.ErrUsernameTaken):
@@ -87,6 +88,8 @@ func (s *AuthServer) Signup(
 			return nil, connect.NewError(connect.CodeAlreadyExists, errors.New("an account with this email already exists"))
 		case errors.Is(err, auth.ErrPhoneTaken):
 			return nil, connect.NewError(connect.CodeAlreadyExists, errors.New("an account with this phone number already exists"))
+		case errors.Is(err, auth.ErrWaitlisted):
+			return nil, connect.NewError(connect.CodeFailedPrecondition, errors.New("sign-ups are paused; you're on the waitlist"))
 		default:
 			return nil, connect.NewError(connect.CodeInternal, errors.New("signup failed"))
 		}
@@ -101,10 +104,18 @@ func (s *AuthServer) Signup(
 
 	sessionID, err := s.auth.Login(email, password, ipAddress, userAgent)
 	if err != nil {
-		log.Printf("Auto-login after signup failed for %s: %v", email, err)
+		logging.FromContext(ctx).Error("auto-login after signup", "email", email, "err", err)
 		return nil, connect.NewError(connect.CodeInternal, errors.New("account created but auto-login failed"))
 	}
 
+	// The account isn't fully active until the phone number is verified
+	// with the code texted now.
+	if s.auth.NeedsPhoneVerification(user) {
+		if err := s.auth.SendPhoneCode(ctx, user); err != nil {
+			logging.FromContext(ctx).Error("send phone code after signup", "user_id", user.ID, "err", err)
+		}
+	}
+
 	resp := connect.NewResponse(&portalv1.SignupResponse{
 		SessionId: sessionID,
 		User:      userToProto(user),
@@ -146,7 +157,7 @@ func (s *AuthServer) GetSession(
 
 	sessions, err := s.auth.GetSessionsByUserID(user.ID)
 	if err != nil {
-		log.Printf("Error fetching sessions for user %s: %v", user.ID, err)
+		logging.FromContext(ctx).Error("fetch sessions", "user_id", user.ID, "err", err)
 		sessions = nil
 	}
 
@@ -173,7 +184,7 @@ func (s *AuthServer) MagicLogin(
 
 	sessionID, err := s.auth.ValidateMagicToken(token, ipAddress, userAgent)
 	if err != nil {
-		log.Printf("Magic login failed: %v", err)
+		logging.FromContext(ctx).Info("magic login failed", "err", err)
 		if errors.Is(err, auth.ErrInvalidMagicToken) {
 			return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("invalid or expired magic login link"))
 		}
@@ -192,7 +203,7 @@ func (s *AuthServer) GenerateMagicLink(
 	ctx context.Context,
 	req *connect.Request[portalv1.GenerateMagicLinkRequest],
 ) (*connect.Response[portalv1.GenerateMagicLinkResponse], error) {
-	// Require admin session.
+	// Require a session allowed to issue magic links.
 	sessionID := extractSessionCookie(req.Header().Get("Cookie"))
 	if sessionID == "" {
 		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("authentication required"))
@@ -202,8 +213,8 @@ func (s *AuthServer) GenerateMagicLink(
 	if err != nil || user == nil {
 		return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("invalid session"))
 	}
-	if !user.IsAdmin() {
-		return nil, connect.NewError(connect.CodePermissionDenied, errors.New("admin access required"))
+	if !auth.Can(user, authz.MagicLinkIssue) {
+		return nil, connect.NewError(connect.CodePermissionDenied, errors.New("magiclink.issue permission required"))
 	}
 
 	email := strings.TrimSpace(req.Msg.Email)
@@ -213,7 +224,7 @@ func (s *AuthServer) GenerateMagicLink(
 
 	token, err := s.auth.CreateMagicToken(email)
 	if err != nil {
-		log.Printf("Failed to generate magic link for %s: %v", email, err)
+		logging.FromContext(ctx).Error("generate magic link", "email", email, "err", err)
 		if errors.Is(err, auth.ErrUserNotFound) {
 			return nil, connect.NewError(connect.CodeNotFound, errors.New("user not found"))
 		}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jredh-dev/nexus/internal/logging"
+	"github.com/jredh-dev/nexus/services/portal/internal/web/templates"
+	"github.com/jredh-dev/nexus/services/portal/pkg/models"
+)
+
+// analyticsPage is the admin analytics page. It stands alone, with no
+// base layout.
+var analyticsPage = template.Must(template.New("admin_analytics.html").Funcs(template.FuncMap{
+	"percent": func(f float64) string { return fmt.Sprintf("%.0f%%", f*100) },
+}).ParseFS(templates.FS, "admin_analytics.html"))
+
+// Option configures a Handler.
+type Option func(*Handler)
+
+// WithClaimStats shows the giveaway's claims on the analytics page.
+// Without it, the page says this build has no giveaway.
+func WithClaimStats(fn func() (*models.ClaimStats, error)) Option {
+	return func(h *Handler) { h.claimStats = fn }
+}
+
+// barChart is a bar chart drawn as SVG on the server.
+type barChart struct {
+	Title         string
+	Total         int
+	Width, Height int
+	Bars          []bar
+}
+
+type bar struct {
+	Label      string // shown on hover
+	Value      int
+	X, Y, W, H float64
+}
+
+const chartWidth, chartHeight = 600, 120
+
+// newBarChart charts counts, one bar per day.
+func newBarChart(title string, counts []models.DayCount) barChart {
+	c := barChart{Title: title, Width: chartWidth, Height: chartHeight}
+	most := 0
+	for _, d := range counts {
+		c.Total += d.Count
+		if d.Count > most {
+			most = d.Count
+		}
+	}
+	if len(counts) == 0 {
+		return c
+	}
+	slot := float64(chartWidth) / float64(len(counts))
+	for i, d := range counts {
+		h := 0.0
+		if most > 0 {
+			h = float64(d.Count) / float64(most) * chartHeight
+		}
+		c.Bars = append(c.Bars, bar{
+			Label: d.Day.Format("Jan 2"),
+			Value: d.Count,
+			X:     float64(i)*slot + slot*0.1,
+			Y:     chartHeight - h,
+			W:     slot * 0.8,
+			H:     h,
+		})
+	}
+	return c
+}
+
+// AdminAnalytics renders sign-ups and logins per day, claim conversion
+// and the magic bar's top searches over the last ?days= days (default 30,
+// at most auth.AnalyticsRetention). Requires the analytics.view permission.
+//
+//	@Summary      Analytics dashboard (admin)
+//	@Description  Sign-ups and logins per day, giveaway claim conversion and top magic bar searches, as an HTML page. Requires the analytics.view permission.
+//	@Tags         admin
+//	@Produce      html
+//	@Param        days  query     int     false  "Days to cover, 1 to 90 (default 30)"
+//	@Success      200   {string}  string  "HTML page"
+//	@Failure      400   {string}  string
+//	@Router       /admin/analytics [get]
+func (h *Handler) AdminAnalytics(w http.ResponseWriter, r *http.Request) {
+	days := 30
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 90 {
+			http.Error(w, "days must be from 1 to 90", http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+	since := time.Now().UTC().AddDate(0, 0, 1-days).Truncate(24 * time.Hour)
+	log := logging.FromContext(r.Context())
+
+	signups, err := h.db.SignupsPerDay(since)
+	if err != nil {
+		log.Error("analytics signups", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	logins, err := h.db.LoginsPerDay(since)
+	if err != nil {
+		log.Error("analytics logins", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	byMethod, users, err := h.db.LoginsByMethod(since)
+	if err != nil {
+		log.Error("analytics login methods", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	searches, err := h.db.TopSearches(since, 20)
+	if err != nil {
+		log.Error("analytics searches", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	var claims *models.ClaimStats
+	if h.claimStats != nil {
+		if claims, err = h.claimStats(); err != nil {
+			log.Error("analytics claims", "err", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	err = analyticsPage.Execute(w, map[string]interface{}{
+		"Days":           days,
+		"Signups":        newBarChart("Sign-ups per day", signups),
+		"Logins":         newBarChart("Logins per day", logins),
+		"LoginsByMethod": byMethod,
+		"LoginUsers":     users,
+		"Claims":         claims,
+		"Searches":       searches,
+	})
+	if err != nil {
+		log.Error("render analytics", "err", err)
+	}
+}
@@ -5,196 +5,100 @@ package handlers
 import (
 	"crypto/rand"
 	"encoding/hex"
-	"encoding/json"
-	"log"
 	"net/http"
+	"slices"
 	"strconv"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+
+	"github.com/jredh-dev/nexus/internal/httpx"
+	"github.com/jredh-dev/nexus/internal/logging"
+	"github.com/jredh-dev/nexus/services/portal/internal/database"
 	"github.com/jredh-dev/nexus/services/portal/pkg/fees"
 	"github.com/jredh-dev/nexus/services/portal/pkg/models"
 )
 
-// GiveawayList renders the public giveaway browse page (available items only).
-func (h *Handler) GiveawayList(w http.ResponseWriter, r *http.Request) {
-	items, err := h.giveawayDB.ListItems(models.ItemStatusAvailable)
-	if err != nil {
-		log.Printf("Error listing giveaway items: %v", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		return
-	}
-
-	// Pre-calculate delivery fees for display.
-	type itemWithFee struct {
-		models.Item
-		Fee fees.DeliveryFee
-	}
-	var display []itemWithFee
-	for _, item := range items {
-		fee := fees.CalculateDeliveryDefault(item.DistMiles, item.DriveMinutes)
-		display = append(display, itemWithFee{Item: item, Fee: fee})
-	}
-
-	h.renderTemplate(w, "giveaway.html", map[string]interface{}{
-		"Title":    "Free Stuff",
-		"Year":     time.Now().Year(),
-		"LoggedIn": h.isLoggedIn(r),
-		"Items":    display,
-	})
+// Giveaway serves the giveaway's JSON API: listed items, delivery fees and
+// claims, and the claims admins confirm or cancel. Its pages are the Astro
+// frontend's.
+type Giveaway struct {
+	db *database.GiveawayDB
 }
 
-// GiveawayItem renders the detail page for a single item with claim form.
-func (h *Handler) GiveawayItem(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	item, err := h.giveawayDB.GetItem(id)
-	if err != nil {
-		log.Printf("Error getting giveaway item %s: %v", id, err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		return
-	}
-	if item == nil {
-		http.NotFound(w, r)
-		return
-	}
-
-	fee := fees.CalculateDeliveryDefault(item.DistMiles, item.DriveMinutes)
-
-	h.renderTemplate(w, "giveaway_item.html", map[string]interface{}{
-		"Title":    item.Title,
-		"Year":     time.Now().Year(),
-		"LoggedIn": h.isLoggedIn(r),
-		"Item":     item,
-		"Fee":      fee,
-	})
+// NewGiveaway returns the giveaway's handlers, backed by db.
+func NewGiveaway(db *database.GiveawayDB) *Giveaway {
+	return &Giveaway{db: db}
 }
 
-// GiveawayClaimSubmit handles the public claim form submission.
-func (h *Handler) GiveawayClaimSubmit(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
-	item, err := h.giveawayDB.GetItem(id)
-	if err != nil || item == nil {
-		http.NotFound(w, r)
-		return
-	}
-
-	if item.Status != models.ItemStatusAvailable {
-		h.renderTemplate(w, "giveaway_item.html", map[string]interface{}{
-			"Title":    item.Title,
-			"Year":     time.Now().Year(),
-			"LoggedIn": h.isLoggedIn(r),
-			"Item":     item,
-			"Fee":      fees.CalculateDeliveryDefault(item.DistMiles, item.DriveMinutes),
-			"Error":    "This item is no longer available.",
-		})
-		return
-	}
-
-	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Invalid form data", http.StatusBadRequest)
-		return
-	}
-
-	name := r.FormValue("name")
-	email := r.FormValue("email")
-	phone := r.FormValue("phone")
-
-	if name == "" || email == "" {
-		fee := fees.CalculateDeliveryDefault(item.DistMiles, item.DriveMinutes)
-		h.renderTemplate(w, "giveaway_item.html", map[string]interface{}{
-			"Title":    item.Title,
-			"Year":     time.Now().Year(),
-			"LoggedIn": h.isLoggedIn(r),
-			"Item":     item,
-			"Fee":      fee,
-			"Error":    "Name and email are required.",
-			"Name":     name,
-			"Email":    email,
-			"Phone":    phone,
-		})
-		return
-	}
-
-	fee := fees.CalculateDeliveryDefault(item.DistMiles, item.DriveMinutes)
-	now := time.Now()
-
-	claim := &models.Claim{
-		ID:           generateID(),
-		ItemID:       item.ID,
-		ClaimerName:  name,
-		ClaimerEmail: email,
-		ClaimerPhone: phone,
-		DeliveryFee:  fee.Total,
-		Status:       models.ClaimStatusPending,
-		CreatedAt:    now,
-		UpdatedAt:    now,
-	}
-
-	if err := h.giveawayDB.CreateClaim(claim); err != nil {
-		log.Printf("Error creating claim for item %s: %v", id, err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		return
-	}
-
-	// Mark item as claimed.
-	item.Status = models.ItemStatusClaimed
-	if err := h.giveawayDB.UpdateItem(item); err != nil {
-		log.Printf("Error updating item status for %s: %v", id, err)
-	}
-
-	h.renderTemplate(w, "giveaway_item.html", map[string]interface{}{
-		"Title":    item.Title,
-		"Year":     time.Now().Year(),
-		"LoggedIn": h.isLoggedIn(r),
-		"Item":     item,
-		"Fee":      fee,
-		"Success":  "Claim submitted! We'll be in touch about delivery.",
-	})
-}
-
-// --- JSON API endpoints ---
-
-// APIListItems returns available items as JSON.
-func (h *Handler) APIListItems(w http.ResponseWriter, r *http.Request) {
+// APIListItems returns items as JSON, the available ones unless ?status=
+// says otherwise.
+//
+//	@Summary      List giveaway items
+//	@Description  Lists giveaway items with the given status (default available). Giveaway builds only.
+//	@Tags         giveaway
+//	@Produce      json
+//	@Param        status  query     string  false  "available, claimed or gone"
+//	@Success      200     {array}   object
+//	@Router       /api/giveaway/items [get]
+func (g *Giveaway) APIListItems(w http.ResponseWriter, r *http.Request) {
 	status := models.ItemStatus(r.URL.Query().Get("status"))
 	if status == "" {
 		status = models.ItemStatusAvailable
 	}
 
-	items, err := h.giveawayDB.ListItems(status)
+	items, err := g.db.ListItems(status)
 	if err != nil {
-		jsonError(w, "Failed to list items", http.StatusInternalServerError)
+		logging.FromContext(r.Context()).Error("list giveaway items", "err", err)
+		httpx.Error(w, "Failed to list items", http.StatusInternalServerError)
 		return
 	}
 	if items == nil {
 		items = []models.Item{}
 	}
-	jsonResponse(w, items)
+	httpx.JSON(w, http.StatusOK, items)
 }
 
 // APICalculateFee returns a delivery fee calculation as JSON.
-func (h *Handler) APICalculateFee(w http.ResponseWriter, r *http.Request) {
-	milesStr := r.URL.Query().Get("miles")
-	minutesStr := r.URL.Query().Get("minutes")
-
-	miles, err := strconv.ParseFloat(milesStr, 64)
+//
+//	@Summary      Calculate a delivery fee
+//	@Description  Returns the delivery fee for a one-way distance and drive time, and how it was reached. Giveaway builds only.
+//	@Tags         giveaway
+//	@Produce      json
+//	@Param        miles    query     number   true  "One-way miles"
+//	@Param        minutes  query     integer  true  "One-way drive minutes"
+//	@Success      200      {object}  fees.DeliveryFee
+//	@Failure      400      {object}  map[string]string
+//	@Router       /api/giveaway/fee [get]
+func (g *Giveaway) APICalculateFee(w http.ResponseWriter, r *http.Request) {
+	miles, err := strconv.ParseFloat(r.URL.Query().Get("miles"), 64)
 	if err != nil {
-		jsonError(w, "Invalid miles parameter", http.StatusBadRequest)
+		httpx.Error(w, "Invalid miles parameter", http.StatusBadRequest)
 		return
 	}
-
-	minutes, err := strconv.Atoi(minutesStr)
+	minutes, err := strconv.Atoi(r.URL.Query().Get("minutes"))
 	if err != nil {
-		jsonError(w, "Invalid minutes parameter", http.StatusBadRequest)
+		httpx.Error(w, "Invalid minutes parameter", http.StatusBadRequest)
 		return
 	}
-
-	fee := fees.CalculateDeliveryDefault(miles, minutes)
-	jsonResponse(w, fee)
+	httpx.JSON(w, http.StatusOK, fees.CalculateDeliveryDefault(miles, minutes))
 }
 
-// APICreateClaim handles a JSON claim submission.
-func (h *Handler) APICreateClaim(w http.ResponseWriter, r *http.Request) {
+// APICreateClaim claims an available item for the person named in the
+// JSON body, at the item's delivery fee, and marks the item claimed.
+//
+//	@Summary      Claim a giveaway item
+//	@Description  Claims an available item; the claim waits for an admin to confirm it. Giveaway builds only.
+//	@Tags         giveaway
+//	@Accept       json
+//	@Produce      json
+//	@Param        body  body      map[string]string  true  "item_id, name, email, phone, notes"
+//	@Success      201   {object}  object
+//	@Failure      400   {object}  map[string]string
+//	@Failure      404   {object}  map[string]string
+//	@Failure      409   {object}  map[string]string  "Item no longer available"
+//	@Router       /api/giveaway/claims [post]
+func (g *Giveaway) APICreateClaim(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		ItemID string `json:"item_id"`
 		Name   string `json:"name"`
@@ -202,23 +106,29 @@ func (h *Handler) APICreateClaim(w http.ResponseWriter, r *http.Request) {
 		Phone  string `json:"phone"`
 		Notes  string `json:"notes"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		jsonError(w, "Invalid request body", http.StatusBadRequest)
+	if err := httpx.DecodeJSON(w, r, &req); err != nil {
+		httpx.WriteError(w, err)
 		return
 	}
 
 	if req.ItemID == "" || req.Name == "" || req.Email == "" {
-		jsonError(w, "item_id, name, and email are required", http.StatusBadRequest)
+		httpx.Error(w, "item_id, name, and email are required", http.StatusBadRequest)
 		return
 	}
 
-	item, err := h.giveawayDB.GetItem(req.ItemID)
-	if err != nil || item == nil {
-		jsonError(w, "Item not found", http.StatusNotFound)
+	log := logging.FromContext(r.Context())
+	item, err := g.db.GetItem(req.ItemID)
+	if err != nil {
+		log.Error("get giveaway item", "item_id", req.ItemID, "err", err)
+		httpx.Error(w, "Failed to create claim", http.StatusInternalServerError)
+		return
+	}
+	if item == nil {
+		httpx.Error(w, "Item not found", http.StatusNotFound)
 		return
 	}
 	if item.Status != models.ItemStatusAvailable {
-		jsonError(w, "Item is no longer available", http.StatusConflict)
+		httpx.Error(w, "Item is no longer available", http.StatusConflict)
 		return
 	}
 
@@ -238,19 +148,130 @@ func (h *Handler) APICreateClaim(w http.ResponseWriter, r *http.Request) {
 		UpdatedAt:    now,
 	}
 
-	if err := h.giveawayDB.CreateClaim(claim); err != nil {
-		log.Printf("API: error creating claim for item %s: %v", req.ItemID, err)
-		jsonError(w, "Failed to create claim", http.StatusInternalServerError)
+	if err := g.db.CreateClaim(claim); err != nil {
+		log.Error("create claim", "item_id", req.ItemID, "err", err)
+		httpx.Error(w, "Failed to create claim", http.StatusInternalServerError)
 		return
 	}
 
 	item.Status = models.ItemStatusClaimed
-	if err := h.giveawayDB.UpdateItem(item); err != nil {
-		log.Printf("API: error updating item status for %s: %v", req.ItemID, err)
+	item.UpdatedAt = now
+	if err := g.db.UpdateItem(item); err != nil {
+		log.Error("update item status", "item_id", req.ItemID, "err", err)
+	}
+
+	httpx.JSON(w, http.StatusCreated, claim)
+}
+
+// APIAdminListClaims returns claims as JSON, newest first, all of them or
+// those with ?status=. Requires the giveaway.manage permission.
+//
+//	@Summary      List giveaway claims (admin)
+//	@Description  Lists claims, optionally with one status. Requires the giveaway.manage permission. Giveaway builds only.
+//	@Tags         giveaway
+//	@Produce      json
+//	@Param        status  query     string  false  "pending, confirmed, delivered or cancelled"
+//	@Success      200     {array}   object
+//	@Failure      400     {object}  map[string]string
+//	@Failure      401     {object}  map[string]string
+//	@Failure      403     {string}  string
+//	@Router       /api/admin/claims [get]
+func (g *Giveaway) APIAdminListClaims(w http.ResponseWriter, r *http.Request) {
+	status := models.ClaimStatus(r.URL.Query().Get("status"))
+	switch status {
+	case "", models.ClaimStatusPending, models.ClaimStatusConfirmed, models.ClaimStatusDelivered, models.ClaimStatusCancelled:
+	default:
+		httpx.Error(w, "Unknown claim status", http.StatusBadRequest)
+		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	jsonResponse(w, claim)
+	claims, err := g.db.ListClaims(status)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("list claims", "status", status, "err", err)
+		httpx.Error(w, "Failed to list claims", http.StatusInternalServerError)
+		return
+	}
+	if claims == nil {
+		claims = []models.Claim{}
+	}
+	httpx.JSON(w, http.StatusOK, claims)
+}
+
+// APIAdminConfirmClaim confirms a pending claim. Requires the
+// giveaway.manage permission.
+//
+//	@Summary      Confirm a giveaway claim (admin)
+//	@Description  Confirms a pending claim. Requires the giveaway.manage permission. Giveaway builds only.
+//	@Tags         giveaway
+//	@Produce      json
+//	@Param        id   path      string  true  "Claim ID"
+//	@Success      200  {object}  object
+//	@Failure      404  {object}  map[string]string
+//	@Failure      409  {object}  map[string]string  "Claim isn't pending"
+//	@Router       /api/admin/claims/{id}/confirm [post]
+func (g *Giveaway) APIAdminConfirmClaim(w http.ResponseWriter, r *http.Request) {
+	g.setClaimStatus(w, r, models.ClaimStatusConfirmed, models.ClaimStatusPending)
+}
+
+// APIAdminCancelClaim cancels a pending or confirmed claim and lists its
+// item again. Requires the giveaway.manage permission.
+//
+//	@Summary      Cancel a giveaway claim (admin)
+//	@Description  Cancels a pending or confirmed claim, making its item available again. Requires the giveaway.manage permission. Giveaway builds only.
+//	@Tags         giveaway
+//	@Produce      json
+//	@Param        id   path      string  true  "Claim ID"
+//	@Success      200  {object}  object
+//	@Failure      404  {object}  map[string]string
+//	@Failure      409  {object}  map[string]string  "Claim was delivered or already cancelled"
+//	@Router       /api/admin/claims/{id}/cancel [post]
+func (g *Giveaway) APIAdminCancelClaim(w http.ResponseWriter, r *http.Request) {
+	g.setClaimStatus(w, r, models.ClaimStatusCancelled, models.ClaimStatusPending, models.ClaimStatusConfirmed)
+}
+
+// setClaimStatus moves the {id} claim to status if it is in one of from,
+// and writes it as JSON. A cancelled claim's item is available again.
+func (g *Giveaway) setClaimStatus(w http.ResponseWriter, r *http.Request, status models.ClaimStatus, from ...models.ClaimStatus) {
+	id := chi.URLParam(r, "id")
+	log := logging.FromContext(r.Context())
+
+	claim, err := g.db.GetClaim(id)
+	if err != nil {
+		log.Error("get claim", "claim_id", id, "err", err)
+		httpx.Error(w, "Failed to update claim", http.StatusInternalServerError)
+		return
+	}
+	if claim == nil {
+		httpx.Error(w, "Claim not found", http.StatusNotFound)
+		return
+	}
+	if !slices.Contains(from, claim.Status) {
+		httpx.Error(w, "Claim is "+string(claim.Status), http.StatusConflict)
+		return
+	}
+
+	if err := g.db.UpdateClaimStatus(id, status); err != nil {
+		log.Error("update claim status", "claim_id", id, "status", status, "err", err)
+		httpx.Error(w, "Failed to update claim", http.StatusInternalServerError)
+		return
+	}
+	claim.Status = status
+	claim.UpdatedAt = time.Now()
+
+	if status == models.ClaimStatusCancelled {
+		item, err := g.db.GetItem(claim.ItemID)
+		if err != nil {
+			log.Error("get giveaway item", "item_id", claim.ItemID, "err", err)
+		} else if item != nil && item.Status == models.ItemStatusClaimed {
+			item.Status = models.ItemStatusAvailable
+			if err := g.db.UpdateItem(item); err != nil {
+				log.Error("update item status", "item_id", item.ID, "err", err)
+			}
+		}
+	}
+
+	log.Info("claim status set", "claim_id", id, "status", status)
+	httpx.JSON(w, http.StatusOK, claim)
 }
 
 // --- helpers ---
@@ -263,16 +284,3 @@ func generateID() string {
 	}
 	return hex.EncodeToString(b)
 }
-
-func jsonResponse(w http.ResponseWriter, data interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(data); err != nil {
-		log.Printf("Error encoding JSON response: %v", err)
-	}
-}
-
-func jsonError(w http.ResponseWriter, message string, status int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
-}
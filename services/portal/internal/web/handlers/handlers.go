@@ -1,36 +1,47 @@
 package handlers
 
 import (
+	"crypto/subtle"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/jredh-dev/nexus/internal/authz"
+	"github.com/jredh-dev/nexus/internal/httpx"
+	"github.com/jredh-dev/nexus/internal/logging"
+	"github.com/jredh-dev/nexus/internal/sso"
 	"github.com/jredh-dev/nexus/services/portal/config"
 	"github.com/jredh-dev/nexus/services/portal/internal/actions"
 	"github.com/jredh-dev/nexus/services/portal/internal/auth"
 	"github.com/jredh-dev/nexus/services/portal/internal/database"
+	"github.com/jredh-dev/nexus/services/portal/pkg/models"
 )
 
 // Handler holds dependencies for HTTP handlers.
 type Handler struct {
-	db      *database.DB
-	cfg     *config.Config
-	auth    *auth.Service
-	actions *actions.Registry
+	db             *database.DB
+	cfg            *config.Config
+	auth           *auth.Service
+	actions        *actions.Registry
+	claimStats     func() (*models.ClaimStats, error)                      // nil without the giveaway
+	searchGiveaway func(q string, limit int) ([]models.SearchGroup, error) // nil without the giveaway
 }
 
 // New creates a new handler.
-func New(db *database.DB, cfg *config.Config, authService *auth.Service, registry *actions.Registry) *Handler {
-	return &Handler{
+func New(db *database.DB, cfg *config.Config, authService *auth.Service, registry *actions.Registry, opts ...Option) *Handler {
+	h := &Handler{
 		db:      db,
 		cfg:     cfg,
 		auth:    authService,
 		actions: registry,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 // AuthService returns the auth service instance.
@@ -51,8 +62,8 @@ func (h *Handler) AuthService() *auth.Service {
 //	@Router       /login [post]
 func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		log.Printf("Error parsing login form: %v", err)
-		h.jsonError(w, "Invalid form data.", http.StatusBadRequest)
+		logging.FromContext(r.Context()).Warn("parse login form", "err", err)
+		httpx.Error(w, "Invalid form data.", http.StatusBadRequest)
 		return
 	}
 
@@ -66,7 +77,7 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 
 	sessionID, err := h.auth.Login(email, password, r.RemoteAddr, r.UserAgent())
 	if err != nil {
-		log.Printf("Login failed for %s: %v", email, err)
+		logging.FromContext(r.Context()).Info("login failed", "email", email, "err", err)
 		h.redirectWithError(w, r, "/login", "Invalid email or password.")
 		return
 	}
@@ -109,10 +120,12 @@ func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
 
-// Signup handles signup form submission.
+// Signup handles signup form submission. When phone numbers are verified
+// by text, it sends the code and redirects to /verify-phone rather than
+// /dashboard.
 //
 //	@Summary      Sign up via form
-//	@Description  Creates a new user account, auto-logs in, and redirects to /dashboard.
+//	@Description  Creates a new user account, auto-logs in, and redirects to /dashboard, or to /verify-phone after texting a verification code.
 //	@Tags         auth
 //	@Accept       application/x-www-form-urlencoded
 //	@Param        username  formData  string  true   "Username"
@@ -120,13 +133,13 @@ func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
 //	@Param        phone     formData  string  true   "Phone number"
 //	@Param        password  formData  string  true   "Password"
 //	@Param        name      formData  string  false  "Display name"
-//	@Success      303  "Redirect to /dashboard"
+//	@Success      303  "Redirect to /dashboard or /verify-phone"
 //	@Failure      303  "Redirect to /signup with error"
 //	@Router       /signup [post]
 func (h *Handler) Signup(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		log.Printf("Error parsing signup form: %v", err)
-		h.jsonError(w, "Invalid form data.", http.StatusBadRequest)
+		logging.FromContext(r.Context()).Warn("parse signup form", "err", err)
+		httpx.Error(w, "Invalid form data.", http.StatusBadRequest)
 		return
 	}
 
@@ -141,9 +154,9 @@ func (h *Handler) Signup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err := h.auth.Signup(username, email, phone, password, name)
+	user, err := h.auth.Signup(username, email, phone, password, name)
 	if err != nil {
-		log.Printf("Signup failed for %s: %v", email, err)
+		logging.FromContext(r.Context()).Info("signup failed", "email", email, "err", err)
 
 		var msg string
 		switch {
@@ -153,6 +166,8 @@ func (h *Handler) Signup(w http.ResponseWriter, r *http.Request) {
 			msg = "An account with this email already exists."
 		case errors.Is(err, auth.ErrPhoneTaken):
 			msg = "An account with this phone number already exists."
+		case errors.Is(err, auth.ErrWaitlisted):
+			msg = "Sign-ups are paused for now. You're on the waitlist."
 		default:
 			msg = "Something went wrong. Please try again."
 		}
@@ -163,7 +178,7 @@ func (h *Handler) Signup(w http.ResponseWriter, r *http.Request) {
 	// Auto-login after signup.
 	sessionID, err := h.auth.Login(email, password, r.RemoteAddr, r.UserAgent())
 	if err != nil {
-		log.Printf("Auto-login after signup failed for %s: %v", email, err)
+		logging.FromContext(r.Context()).Error("auto-login after signup", "email", email, "err", err)
 		http.Redirect(w, r, "/login", http.StatusSeeOther)
 		return
 	}
@@ -178,6 +193,15 @@ func (h *Handler) Signup(w http.ResponseWriter, r *http.Request) {
 		SameSite: http.SameSiteLaxMode,
 	})
 
+	// The account isn't fully active until the phone number is verified.
+	if h.auth.NeedsPhoneVerification(user) {
+		if err := h.auth.SendPhoneCode(r.Context(), user); err != nil {
+			logging.FromContext(r.Context()).Error("send phone code after signup", "user_id", user.ID, "err", err)
+		}
+		http.Redirect(w, r, "/verify-phone", http.StatusSeeOther)
+		return
+	}
+
 	http.Redirect(w, r, "/dashboard", http.StatusSeeOther)
 }
 
@@ -201,7 +225,7 @@ func (h *Handler) MagicLogin(w http.ResponseWriter, r *http.Request) {
 
 	sessionID, err := h.auth.ValidateMagicToken(token, r.RemoteAddr, r.UserAgent())
 	if err != nil {
-		log.Printf("Magic login failed: %v", err)
+		logging.FromContext(r.Context()).Info("magic login failed", "err", err)
 		if errors.Is(err, auth.ErrInvalidMagicToken) {
 			http.Error(w, "Invalid or expired magic login link.", http.StatusUnauthorized)
 			return
@@ -224,10 +248,11 @@ func (h *Handler) MagicLogin(w http.ResponseWriter, r *http.Request) {
 }
 
 // AdminGenerateMagicLink handles POST /admin/magic-link — generates a magic
-// login token for a given email and returns it as JSON. Requires admin role.
+// login token for a given email and returns it as JSON. Requires the
+// magiclink.issue permission.
 //
 //	@Summary      Generate magic login link (admin)
-//	@Description  Generates a magic login URL for a given email. Requires admin role.
+//	@Description  Generates a magic login URL for a given email. Requires the magiclink.issue permission.
 //	@Tags         admin
 //	@Accept       application/x-www-form-urlencoded
 //	@Produce      json
@@ -250,7 +275,7 @@ func (h *Handler) AdminGenerateMagicLink(w http.ResponseWriter, r *http.Request)
 
 	token, err := h.auth.CreateMagicToken(email)
 	if err != nil {
-		log.Printf("Failed to generate magic link for %s: %v", email, err)
+		logging.FromContext(r.Context()).Error("generate magic link", "email", email, "err", err)
 		if errors.Is(err, auth.ErrUserNotFound) {
 			http.Error(w, "User not found", http.StatusNotFound)
 			return
@@ -271,8 +296,9 @@ func (h *Handler) AdminGenerateMagicLink(w http.ResponseWriter, r *http.Request)
 }
 
 // SearchActions returns actions matching the query parameter "q".
-// Results are filtered by auth state — admin actions only for admins,
-// login/signup hidden when logged in, logout/dashboard hidden when logged out.
+// Results are filtered by auth state — admin pages only for roles granted
+// their permission, login/signup hidden when logged in, logout/dashboard
+// hidden when logged out.
 //
 //	@Summary      Search available actions
 //	@Description  Returns actions matching the query, filtered by auth state.
@@ -289,7 +315,7 @@ func (h *Handler) SearchActions(w http.ResponseWriter, r *http.Request) {
 	if cookie, err := r.Cookie("session"); err == nil && cookie.Value != "" {
 		if user, _, err := h.auth.ValidateSession(cookie.Value); err == nil && user != nil {
 			ctx.LoggedIn = true
-			ctx.IsAdmin = user.IsAdmin()
+			ctx.Can = func(perm authz.Permission) bool { return auth.Can(user, perm) }
 		}
 	}
 
@@ -313,35 +339,43 @@ func (h *Handler) SearchActions(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) GetMe(w http.ResponseWriter, r *http.Request) {
 	user, ok := GetUserFromContext(r.Context())
 	if !ok || user == nil {
-		h.jsonError(w, "authentication required", http.StatusUnauthorized)
+		httpx.Error(w, "authentication required", http.StatusUnauthorized)
 		return
 	}
 
 	type meResponse struct {
-		ID          string    `json:"id"`
-		Email       string    `json:"email"`
-		Username    string    `json:"username"`
-		Name        string    `json:"name"`
-		IsAdmin     bool      `json:"is_admin"`
-		IsActive    bool      `json:"is_active"`
-		CreatedAt   time.Time `json:"created_at"`
-		LastLoginAt time.Time `json:"last_login_at"`
+		ID            string             `json:"id"`
+		Email         string             `json:"email"`
+		Username      string             `json:"username"`
+		Name          string             `json:"name"`
+		AvatarURL     string             `json:"avatar_url,omitempty"`
+		PhoneVerified bool               `json:"phone_verified"`
+		Role          string             `json:"role"`
+		Permissions   []authz.Permission `json:"permissions"`
+		IsAdmin       bool               `json:"is_admin"`
+		IsActive      bool               `json:"is_active"`
+		CreatedAt     time.Time          `json:"created_at"`
+		LastLoginAt   time.Time          `json:"last_login_at"`
 	}
 
 	resp := meResponse{
-		ID:          user.ID,
-		Email:       user.Email,
-		Username:    user.Username,
-		Name:        user.Name,
-		IsAdmin:     user.IsAdmin(),
-		IsActive:    user.Role != "",
-		CreatedAt:   user.CreatedAt,
-		LastLoginAt: user.LastLoginAt,
+		ID:            user.ID,
+		Email:         user.Email,
+		Username:      user.Username,
+		Name:          user.Name,
+		AvatarURL:     user.AvatarURL(),
+		PhoneVerified: user.PhoneVerified,
+		Role:          user.Role,
+		Permissions:   auth.Roles.Permissions(user.Role),
+		IsAdmin:       user.Role == models.RoleAdmin,
+		IsActive:      user.Role != "" && !h.auth.NeedsPhoneVerification(user),
+		CreatedAt:     user.CreatedAt,
+		LastLoginAt:   user.LastLoginAt,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		log.Printf("GetMe encode error: %v", err)
+		logging.FromContext(r.Context()).Error("encode me", "err", err)
 	}
 }
 
@@ -361,20 +395,20 @@ func (h *Handler) GetMe(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) ChangeEmail(w http.ResponseWriter, r *http.Request) {
 	user, ok := GetUserFromContext(r.Context())
 	if !ok || user == nil {
-		h.jsonError(w, "authentication required", http.StatusUnauthorized)
+		httpx.Error(w, "authentication required", http.StatusUnauthorized)
 		return
 	}
 
 	var body struct {
 		NewEmail string `json:"new_email"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		h.jsonError(w, "invalid request body", http.StatusBadRequest)
+	if err := httpx.DecodeJSON(w, r, &body); err != nil {
+		httpx.WriteError(w, err)
 		return
 	}
 	body.NewEmail = strings.TrimSpace(strings.ToLower(body.NewEmail))
 	if body.NewEmail == "" {
-		h.jsonError(w, "new_email is required", http.StatusBadRequest)
+		httpx.Error(w, "new_email is required", http.StatusBadRequest)
 		return
 	}
 
@@ -386,12 +420,12 @@ func (h *Handler) ChangeEmail(w http.ResponseWriter, r *http.Request) {
 	baseURL := fmt.Sprintf("%s://%s", scheme, r.Host)
 
 	if err := h.auth.InitiateEmailChange(user.ID, body.NewEmail, baseURL); err != nil {
-		log.Printf("InitiateEmailChange for user %s: %v", user.ID, err)
+		logging.FromContext(r.Context()).Error("initiate email change", "err", err)
 		if errors.Is(err, auth.ErrEmailTaken) {
-			h.jsonError(w, "That email address is already in use.", http.StatusConflict)
+			httpx.Error(w, "That email address is already in use.", http.StatusConflict)
 			return
 		}
-		h.jsonError(w, "Failed to send verification email. Please try again.", http.StatusInternalServerError)
+		httpx.Error(w, "Failed to send verification email. Please try again.", http.StatusInternalServerError)
 		return
 	}
 
@@ -419,7 +453,7 @@ func (h *Handler) ConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
 
 	_, err := h.auth.ConfirmEmailChange(token)
 	if err != nil {
-		log.Printf("ConfirmEmailChange: %v", err)
+		logging.FromContext(r.Context()).Warn("confirm email change", "err", err)
 		if errors.Is(err, auth.ErrInvalidEmailChangeToken) {
 			http.Error(w, "Invalid or expired email change link.", http.StatusUnauthorized)
 			return
@@ -443,13 +477,13 @@ func (h *Handler) ConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
 	user, ok := GetUserFromContext(r.Context())
 	if !ok || user == nil {
-		h.jsonError(w, "authentication required", http.StatusUnauthorized)
+		httpx.Error(w, "authentication required", http.StatusUnauthorized)
 		return
 	}
 
 	if err := h.auth.DeleteAccount(user.ID); err != nil {
-		log.Printf("DeleteAccount for user %s: %v", user.ID, err)
-		h.jsonError(w, "Failed to delete account. Please try again.", http.StatusInternalServerError)
+		logging.FromContext(r.Context()).Error("delete account", "err", err)
+		httpx.Error(w, "Failed to delete account. Please try again.", http.StatusInternalServerError)
 		return
 	}
 
@@ -468,15 +502,102 @@ func (h *Handler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, `{"message":"Account deleted."}`)
 }
 
-// --- helpers ---
+// Introspect tells another nexus service whose credential it was handed:
+// a session cookie value or a JWT from /api/auth/token. Like RFC 7662, it
+// answers {"active":false} for anything that isn't valid. Only services
+// sending SSO_INTROSPECT_KEY as a bearer token are answered, so the
+// endpoint can't be used to test guessed or stolen sessions; without the
+// key configured it is off.
+//
+//	@Summary      Introspect a credential
+//	@Description  Reports the user a session ID or JWT belongs to, for single sign-on across nexus services. Requires the portal's SSO_INTROSPECT_KEY as a bearer token.
+//	@Tags         auth
+//	@Accept       application/x-www-form-urlencoded
+//	@Produce      json
+//	@Param        token  formData  string  true  "Session ID or JWT"
+//	@Success      200  {object}  sso.Introspection
+//	@Failure      401  {object}  map[string]string
+//	@Failure      404  {object}  map[string]string  "Introspection is not configured"
+//	@Failure      429  {object}  map[string]string
+//	@Router       /api/auth/introspect [post]
+func (h *Handler) Introspect(w http.ResponseWriter, r *http.Request) {
+	key := h.cfg.SSO.IntrospectKey
+	if key == "" {
+		httpx.Error(w, "Introspection is not configured", http.StatusNotFound)
+		return
+	}
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(key)) != 1 {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="nexus-portal"`)
+		httpx.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var resp sso.Introspection
+	if token := r.PostFormValue("token"); token != "" {
+		id, err := h.auth.Introspect(token)
+		switch {
+		case err == nil:
+			resp = sso.Introspection{Active: true, Identity: id}
+		case !errors.Is(err, sso.ErrInvalidToken):
+			logging.FromContext(r.Context()).Error("introspect", "err", err)
+			httpx.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logging.FromContext(r.Context()).Error("encode introspection", "err", err)
+	}
+}
+
+// IssueToken returns a JWT the other nexus services accept as the
+// authenticated user, for clients that can't send the session cookie.
+//
+//	@Summary      Issue an SSO token
+//	@Description  Returns a signed JWT for the current user, accepted as a bearer token by cal and secrets. Requires session cookie.
+//	@Tags         auth
+//	@Produce      json
+//	@Success      200  {object}  map[string]string
+//	@Failure      401  {object}  map[string]string
+//	@Failure      403  {object}  map[string]string  "Phone number not verified"
+//	@Failure      404  {object}  map[string]string
+//	@Router       /api/auth/token [post]
+func (h *Handler) IssueToken(w http.ResponseWriter, r *http.Request) {
+	user, ok := GetUserFromContext(r.Context())
+	if !ok || user == nil {
+		httpx.Error(w, "authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	token, expires, err := h.auth.IssueToken(user)
+	if errors.Is(err, auth.ErrSSODisabled) {
+		httpx.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, auth.ErrPhoneUnverified) {
+		httpx.Error(w, "verify your phone number first", http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		logging.FromContext(r.Context()).Error("issue token", "err", err)
+		httpx.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
 
-// jsonError writes a JSON error response.
-func (h *Handler) jsonError(w http.ResponseWriter, msg string, status int) {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	fmt.Fprintf(w, `{"error":%q}`, msg)
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(map[string]any{ //nolint:errcheck
+		"token":      token,
+		"token_type": "Bearer",
+		"expires_at": expires,
+	})
 }
 
+// --- helpers ---
+
+// jsonError writes a JSON error response.
 // redirectWithError redirects to the given path with an error query param.
 func (h *Handler) redirectWithError(w http.ResponseWriter, r *http.Request, path, msg string) {
 	target := path + "?error=" + strings.ReplaceAll(msg, " ", "+")
@@ -2,9 +2,10 @@ package handlers
 
 import (
 	"context"
-	"log"
 	"net/http"
 
+	"github.com/jredh-dev/nexus/internal/authz"
+	"github.com/jredh-dev/nexus/internal/logging"
 	"github.com/jredh-dev/nexus/services/portal/internal/auth"
 	"github.com/jredh-dev/nexus/services/portal/pkg/models"
 )
@@ -30,7 +31,7 @@ func AuthMiddleware(authService *auth.Service) func(http.Handler) http.Handler {
 
 			user, _, err := authService.ValidateSession(cookie.Value)
 			if err != nil {
-				log.Printf("Session validation error: %v", err)
+				logging.FromContext(r.Context()).Error("validate session", "err", err)
 				clearSessionCookie(w)
 				http.Redirect(w, r, "/login", http.StatusSeeOther)
 				return
@@ -41,27 +42,23 @@ func AuthMiddleware(authService *auth.Service) func(http.Handler) http.Handler {
 				return
 			}
 
+			logging.SetUser(r.Context(), user.ID)
 			ctx := context.WithValue(r.Context(), UserContextKey, user)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
-// AdminMiddleware requires the authenticated user to have the admin role.
-// MUST be used after AuthMiddleware so the user is already in context.
-// Returns 403 Forbidden if the user is not an admin.
-func AdminMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+// RequirePermission requires the authenticated user's role to grant perm,
+// as auth.Roles has it. MUST be used after AuthMiddleware so the user is
+// already in context. Returns 403 Forbidden otherwise.
+func RequirePermission(perm authz.Permission) func(http.Handler) http.Handler {
+	return auth.Roles.Require(perm, func(r *http.Request) (string, bool) {
 		user, ok := GetUserFromContext(r.Context())
 		if !ok || user == nil {
-			http.Error(w, "Forbidden", http.StatusForbidden)
-			return
+			return "", false
 		}
-		if !user.IsAdmin() {
-			http.Error(w, "Forbidden", http.StatusForbidden)
-			return
-		}
-		next.ServeHTTP(w, r)
+		return user.Role, true
 	})
 }
 
@@ -81,7 +78,7 @@ func APIAuthMiddleware(authService *auth.Service) func(http.Handler) http.Handle
 
 			user, _, err := authService.ValidateSession(cookie.Value)
 			if err != nil {
-				log.Printf("API session validation error: %v", err)
+				logging.FromContext(r.Context()).Error("validate session", "err", err)
 				clearSessionCookie(w)
 				w.Header().Set("Content-Type", "application/json")
 				w.WriteHeader(http.StatusUnauthorized)
@@ -96,6 +93,7 @@ func APIAuthMiddleware(authService *auth.Service) func(http.Handler) http.Handle
 				return
 			}
 
+			logging.SetUser(r.Context(), user.ID)
 			ctx := context.WithValue(r.Context(), UserContextKey, user)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
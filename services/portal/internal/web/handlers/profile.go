@@ -0,0 +1,331 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/jredh-dev/nexus/internal/httpx"
+	"github.com/jredh-dev/nexus/internal/logging"
+	"github.com/jredh-dev/nexus/internal/upload"
+	"github.com/jredh-dev/nexus/services/portal/internal/auth"
+)
+
+// maxAvatarForm is the most an avatar upload's multipart body may hold:
+// the image and room for the form around it.
+const maxAvatarForm = upload.MaxImageBytes + 64<<10
+
+// profileResponse is the profile the /dashboard/profile endpoints return.
+type profileResponse struct {
+	Username      string `json:"username"`
+	Name          string `json:"name"`
+	Phone         string `json:"phone"`
+	PhoneVerified bool   `json:"phone_verified"`
+	AvatarURL     string `json:"avatar_url,omitempty"`
+}
+
+// GetProfile returns the fields of the authenticated user's profile that
+// they can edit.
+//
+//	@Summary      Get profile
+//	@Description  Returns the authenticated user's editable profile. Requires session cookie.
+//	@Tags         account
+//	@Produce      json
+//	@Success      200  {object}  profileResponse
+//	@Failure      401  {object}  map[string]string
+//	@Router       /dashboard/profile [get]
+func (h *Handler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	user, ok := GetUserFromContext(r.Context())
+	if !ok || user == nil {
+		httpx.Error(w, "authentication required", http.StatusUnauthorized)
+		return
+	}
+	httpx.JSON(w, http.StatusOK, profileResponse{
+		Username:      user.Username,
+		Name:          user.Name,
+		Phone:         user.PhoneNumber,
+		PhoneVerified: user.PhoneVerified,
+		AvatarURL:     user.AvatarURL(),
+	})
+}
+
+// UpdateProfile changes the authenticated user's display name and
+// username. The username must be free, as at sign-up.
+//
+//	@Summary      Update profile
+//	@Description  Changes the display name and username. Requires session cookie.
+//	@Tags         account
+//	@Accept       json
+//	@Produce      json
+//	@Param        body  body  map[string]string  true  "name, username"
+//	@Success      200  {object}  profileResponse
+//	@Failure      400  {object}  map[string]string
+//	@Failure      401  {object}  map[string]string
+//	@Failure      409  {object}  map[string]string  "Username taken"
+//	@Router       /dashboard/profile [post]
+func (h *Handler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	user, ok := GetUserFromContext(r.Context())
+	if !ok || user == nil {
+		httpx.Error(w, "authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var body struct {
+		Name     string `json:"name"`
+		Username string `json:"username"`
+	}
+	if err := httpx.DecodeJSON(w, r, &body); err != nil {
+		httpx.WriteError(w, err)
+		return
+	}
+	body.Name = strings.TrimSpace(body.Name)
+	body.Username = strings.TrimSpace(body.Username)
+
+	if err := h.auth.UpdateProfile(user.ID, body.Name, body.Username); err != nil {
+		switch {
+		case errors.Is(err, auth.ErrInvalidProfile):
+			httpx.Error(w, "username is required", http.StatusBadRequest)
+		case errors.Is(err, auth.ErrUsernameTaken):
+			httpx.Error(w, "This username is already taken.", http.StatusConflict)
+		default:
+			logging.FromContext(r.Context()).Error("update profile", "err", err)
+			httpx.Error(w, "Failed to update profile. Please try again.", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, profileResponse{
+		Username:      body.Username,
+		Name:          body.Name,
+		Phone:         user.PhoneNumber,
+		PhoneVerified: user.PhoneVerified,
+		AvatarURL:     user.AvatarURL(),
+	})
+}
+
+// ChangePhone texts a verification code to a new phone number. The number
+// is not changed until the code is confirmed with VerifyPhone.
+//
+//	@Summary      Request phone change
+//	@Description  Texts a 6-digit code to the new number. Change applies on confirmation. Requires session cookie.
+//	@Tags         account
+//	@Accept       json
+//	@Produce      json
+//	@Param        body  body  map[string]string  true  "phone"
+//	@Success      200  {object}  map[string]string
+//	@Failure      400  {object}  map[string]string
+//	@Failure      401  {object}  map[string]string
+//	@Failure      409  {object}  map[string]string  "Phone number in use"
+//	@Failure      429  {object}  map[string]string  "Code sent too recently, or too many wrong codes"
+//	@Failure      503  {object}  map[string]string  "Text messages not configured"
+//	@Router       /dashboard/profile/phone [post]
+func (h *Handler) ChangePhone(w http.ResponseWriter, r *http.Request) {
+	user, ok := GetUserFromContext(r.Context())
+	if !ok || user == nil {
+		httpx.Error(w, "authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var body struct {
+		Phone string `json:"phone"`
+	}
+	if err := httpx.DecodeJSON(w, r, &body); err != nil {
+		httpx.WriteError(w, err)
+		return
+	}
+	body.Phone = strings.TrimSpace(body.Phone)
+	if body.Phone == "" {
+		httpx.Error(w, "phone is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.auth.InitiatePhoneChange(r.Context(), user.ID, body.Phone); err != nil {
+		switch {
+		case errors.Is(err, auth.ErrPhoneTaken):
+			httpx.Error(w, "An account with this phone number already exists.", http.StatusConflict)
+		case errors.Is(err, auth.ErrSMSDisabled):
+			httpx.Error(w, "Phone numbers can't be changed right now.", http.StatusServiceUnavailable)
+		case errors.Is(err, auth.ErrPhoneCodeTooSoon):
+			httpx.Error(w, "A code was just sent. Wait a minute before asking for another.", http.StatusTooManyRequests)
+		case errors.Is(err, auth.ErrPhoneCodeAttempts):
+			httpx.Error(w, "Too many wrong codes. Try again in a few minutes.", http.StatusTooManyRequests)
+		default:
+			logging.FromContext(r.Context()).Error("initiate phone change", "err", err)
+			httpx.Error(w, "Failed to send verification code. Please try again.", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, map[string]string{"message": "Verification code sent to " + body.Phone + "."})
+}
+
+// ResendPhoneCode texts a new verification code to the number the user
+// signed up with, for when the one sent at sign-up is lost or expired.
+//
+//	@Summary      Resend phone verification code
+//	@Description  Texts a 6-digit code to the account's phone number, to be confirmed at /dashboard/profile/phone/verify. Requires session cookie.
+//	@Tags         account
+//	@Produce      json
+//	@Success      200  {object}  map[string]string
+//	@Failure      401  {object}  map[string]string
+//	@Failure      409  {object}  map[string]string  "Already verified"
+//	@Failure      429  {object}  map[string]string  "Code sent too recently, or too many wrong codes"
+//	@Failure      503  {object}  map[string]string  "Text messages not configured"
+//	@Router       /dashboard/profile/phone/code [post]
+func (h *Handler) ResendPhoneCode(w http.ResponseWriter, r *http.Request) {
+	user, ok := GetUserFromContext(r.Context())
+	if !ok || user == nil {
+		httpx.Error(w, "authentication required", http.StatusUnauthorized)
+		return
+	}
+	if user.PhoneVerified || user.PhoneNumber == "" {
+		httpx.Error(w, "no phone number to verify", http.StatusConflict)
+		return
+	}
+
+	if err := h.auth.SendPhoneCode(r.Context(), user); err != nil {
+		switch {
+		case errors.Is(err, auth.ErrSMSDisabled):
+			httpx.Error(w, "Phone numbers can't be verified right now.", http.StatusServiceUnavailable)
+		case errors.Is(err, auth.ErrPhoneCodeTooSoon):
+			httpx.Error(w, "A code was just sent. Wait a minute before asking for another.", http.StatusTooManyRequests)
+		case errors.Is(err, auth.ErrPhoneCodeAttempts):
+			httpx.Error(w, "Too many wrong codes. Try again in a few minutes.", http.StatusTooManyRequests)
+		default:
+			logging.FromContext(r.Context()).Error("send phone code", "err", err)
+			httpx.Error(w, "Failed to send verification code. Please try again.", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, map[string]string{"message": "Verification code sent to " + user.PhoneNumber + "."})
+}
+
+// VerifyPhone applies a pending phone change given the code texted to the
+// new number. Entering the code sent at sign-up verifies the account's
+// number the same way.
+//
+//	@Summary      Confirm phone change or verification
+//	@Description  Confirms a phone change, or the number given at sign-up, with the 6-digit code texted to it. Requires session cookie.
+//	@Tags         account
+//	@Accept       json
+//	@Produce      json
+//	@Param        body  body  map[string]string  true  "code"
+//	@Success      200  {object}  profileResponse
+//	@Failure      400  {object}  map[string]string  "Wrong or expired code"
+//	@Failure      401  {object}  map[string]string
+//	@Failure      409  {object}  map[string]string  "Phone number in use"
+//	@Router       /dashboard/profile/phone/verify [post]
+func (h *Handler) VerifyPhone(w http.ResponseWriter, r *http.Request) {
+	user, ok := GetUserFromContext(r.Context())
+	if !ok || user == nil {
+		httpx.Error(w, "authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	var body struct {
+		Code string `json:"code"`
+	}
+	if err := httpx.DecodeJSON(w, r, &body); err != nil {
+		httpx.WriteError(w, err)
+		return
+	}
+
+	if err := h.auth.ConfirmPhoneChange(user.ID, body.Code); err != nil {
+		switch {
+		case errors.Is(err, auth.ErrInvalidPhoneCode):
+			httpx.Error(w, "That code is wrong or has expired.", http.StatusBadRequest)
+		case errors.Is(err, auth.ErrPhoneTaken):
+			httpx.Error(w, "An account with this phone number already exists.", http.StatusConflict)
+		default:
+			logging.FromContext(r.Context()).Error("confirm phone change", "err", err)
+			httpx.Error(w, "Failed to change phone number. Please try again.", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	h.writeProfile(w, r, user.ID)
+}
+
+// UploadAvatar sets the authenticated user's avatar from the "avatar" file
+// of a multipart form: a PNG, JPEG or GIF of at most 2 MiB.
+//
+//	@Summary      Upload avatar
+//	@Description  Sets the avatar from a PNG, JPEG or GIF image of at most 2 MiB and 4096×4096 pixels. Requires session cookie.
+//	@Tags         account
+//	@Accept       multipart/form-data
+//	@Produce      json
+//	@Param        avatar  formData  file  true  "Image"
+//	@Success      200  {object}  profileResponse
+//	@Failure      400  {object}  map[string]string
+//	@Failure      401  {object}  map[string]string
+//	@Router       /dashboard/profile/avatar [post]
+func (h *Handler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
+	user, ok := GetUserFromContext(r.Context())
+	if !ok || user == nil {
+		httpx.Error(w, "authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxAvatarForm)
+	f, _, err := r.FormFile("avatar")
+	if err != nil {
+		httpx.Error(w, "avatar must be an image file of at most 2 MiB", http.StatusBadRequest)
+		return
+	}
+	defer f.Close()
+
+	if _, err := h.auth.SetAvatar(r.Context(), user, f); err != nil {
+		if errors.Is(err, upload.ErrInvalidImage) {
+			httpx.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		logging.FromContext(r.Context()).Error("set avatar", "err", err)
+		httpx.Error(w, "Failed to save avatar. Please try again.", http.StatusInternalServerError)
+		return
+	}
+
+	h.writeProfile(w, r, user.ID)
+}
+
+// DeleteAvatar removes the authenticated user's avatar.
+//
+//	@Summary      Remove avatar
+//	@Description  Removes the avatar. Requires session cookie.
+//	@Tags         account
+//	@Produce      json
+//	@Success      200  {object}  profileResponse
+//	@Failure      401  {object}  map[string]string
+//	@Router       /dashboard/profile/avatar [delete]
+func (h *Handler) DeleteAvatar(w http.ResponseWriter, r *http.Request) {
+	user, ok := GetUserFromContext(r.Context())
+	if !ok || user == nil {
+		httpx.Error(w, "authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.auth.RemoveAvatar(r.Context(), user); err != nil {
+		logging.FromContext(r.Context()).Error("remove avatar", "err", err)
+		httpx.Error(w, "Failed to remove avatar. Please try again.", http.StatusInternalServerError)
+		return
+	}
+
+	h.writeProfile(w, r, user.ID)
+}
+
+// writeProfile responds with userID's profile as it now stands.
+func (h *Handler) writeProfile(w http.ResponseWriter, r *http.Request, userID string) {
+	user, err := h.db.GetUserByID(userID)
+	if err != nil || user == nil {
+		logging.FromContext(r.Context()).Error("reload profile", "user_id", userID, "err", err)
+		httpx.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	httpx.JSON(w, http.StatusOK, profileResponse{
+		Username:      user.Username,
+		Name:          user.Name,
+		Phone:         user.PhoneNumber,
+		PhoneVerified: user.PhoneVerified,
+		AvatarURL:     user.AvatarURL(),
+	})
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"html/template"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jredh-dev/nexus/internal/authz"
+	"github.com/jredh-dev/nexus/internal/logging"
+	"github.com/jredh-dev/nexus/services/portal/internal/auth"
+	"github.com/jredh-dev/nexus/services/portal/internal/web/templates"
+	"github.com/jredh-dev/nexus/services/portal/pkg/models"
+)
+
+// searchPage is the admin search page. Like the analytics page it stands
+// alone.
+var searchPage = template.Must(template.New("admin_search.html").Funcs(template.FuncMap{
+	"mark": highlight,
+}).ParseFS(templates.FS, "admin_search.html"))
+
+// searchLimit is the most results of each type AdminSearch shows.
+const searchLimit = 50
+
+// WithGiveawaySearch lets the admin search page find giveaway items and
+// claims with fn, which returns a group for each. Without it, the page
+// searches only users.
+func WithGiveawaySearch(fn func(q string, limit int) ([]models.SearchGroup, error)) Option {
+	return func(h *Handler) { h.searchGiveaway = fn }
+}
+
+// AdminSearch finds users by email, username or name, and giveaway items
+// and claims, matching ?q=, and renders them grouped by type with the
+// matches highlighted. Each type is searched only for roles that manage
+// it: users with users.manage, items and claims with giveaway.manage.
+//
+//	@Summary      Search records (admin)
+//	@Description  Finds users, giveaway items and claims containing q, grouped by type with matches highlighted, as an HTML page. Users need users.manage; items and claims need giveaway.manage.
+//	@Tags         admin
+//	@Produce      html
+//	@Param        q    query     string  false  "Text to find"
+//	@Success      200  {string}  string  "HTML page"
+//	@Failure      403  {string}  string
+//	@Router       /admin/search [get]
+func (h *Handler) AdminSearch(w http.ResponseWriter, r *http.Request) {
+	user, _ := GetUserFromContext(r.Context())
+	canUsers := auth.Can(user, authz.UsersManage)
+	canGiveaway := h.searchGiveaway != nil && auth.Can(user, authz.GiveawayManage)
+	if !canUsers && !auth.Can(user, authz.GiveawayManage) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	var groups []models.SearchGroup
+	if q != "" {
+		log := logging.FromContext(r.Context())
+		if canUsers {
+			users, err := h.db.SearchUsers(q, searchLimit)
+			if err != nil {
+				log.Error("search users", "err", err)
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+			groups = append(groups, userGroup(users))
+		}
+		if canGiveaway {
+			found, err := h.searchGiveaway(q, searchLimit)
+			if err != nil {
+				log.Error("search giveaway", "err", err)
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+			groups = append(groups, found...)
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	err := searchPage.Execute(w, map[string]interface{}{
+		"Query":       q,
+		"Limit":       searchLimit,
+		"CanUsers":    canUsers,
+		"CanGiveaway": canGiveaway,
+		"Groups":      groups,
+	})
+	if err != nil {
+		logging.FromContext(r.Context()).Error("render search", "err", err)
+	}
+}
+
+// userGroup lays out users found by AdminSearch.
+func userGroup(users []models.User) models.SearchGroup {
+	g := models.SearchGroup{
+		Name: "Users",
+		Columns: []models.SearchColumn{
+			{Title: "Username", Searched: true},
+			{Title: "Email", Searched: true},
+			{Title: "Name", Searched: true},
+			{Title: "Role"},
+		},
+	}
+	for _, u := range users {
+		g.Rows = append(g.Rows, []string{u.Username, u.Email, u.Name, u.Role})
+	}
+	return g
+}
+
+// highlight escapes text for HTML and wraps each case-insensitive match of
+// q in <mark>.
+func highlight(text, q string) template.HTML {
+	if q == "" {
+		return template.HTML(template.HTMLEscapeString(text))
+	}
+	var b strings.Builder
+	for text != "" {
+		i, n := indexFold(text, q)
+		if i < 0 {
+			break
+		}
+		b.WriteString(template.HTMLEscapeString(text[:i]))
+		b.WriteString("<mark>")
+		b.WriteString(template.HTMLEscapeString(text[i : i+n]))
+		b.WriteString("</mark>")
+		text = text[i+n:]
+	}
+	b.WriteString(template.HTMLEscapeString(text))
+	return template.HTML(b.String())
+}
+
+// indexFold returns the byte offset and length in s of the first match of
+// q, ignoring case, or -1.
+func indexFold(s, q string) (int, int) {
+	for i := range s {
+		j, k := i, 0
+		for k < len(q) && j < len(s) {
+			sr, sn := utf8.DecodeRuneInString(s[j:])
+			qr, qn := utf8.DecodeRuneInString(q[k:])
+			if !strings.EqualFold(string(sr), string(qr)) {
+				break
+			}
+			j, k = j+sn, k+qn
+		}
+		if k == len(q) {
+			return i, j - i
+		}
+	}
+	return -1, 0
+}
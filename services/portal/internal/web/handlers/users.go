@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jredh-dev/nexus/internal/authz"
+	"github.com/jredh-dev/nexus/internal/logging"
+	"github.com/jredh-dev/nexus/services/portal/internal/auth"
+	"github.com/jredh-dev/nexus/services/portal/internal/web/templates"
+)
+
+// usersPage is the admin page for assigning roles. Like the analytics
+// page it stands alone.
+var usersPage = template.Must(template.ParseFS(templates.FS, "admin_users.html"))
+
+// usersPageLimit is how many users AdminUsers lists.
+const usersPageLimit = 200
+
+// AdminUsers renders the newest users with a form to change each one's
+// role. Requires the users.manage permission.
+//
+//	@Summary      Users and roles (admin)
+//	@Description  Lists users with their roles, and what each role may do, as an HTML page. Requires the users.manage permission.
+//	@Tags         admin
+//	@Produce      html
+//	@Success      200  {string}  string  "HTML page"
+//	@Router       /admin/users [get]
+func (h *Handler) AdminUsers(w http.ResponseWriter, r *http.Request) {
+	user, _ := GetUserFromContext(r.Context())
+	users, err := h.db.ListUsers(usersPageLimit)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("list users", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	roles := auth.Roles.Roles()
+	perms := make(map[string][]authz.Permission, len(roles))
+	for _, role := range roles {
+		perms[role] = auth.Roles.Permissions(role)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	err = usersPage.Execute(w, map[string]interface{}{
+		"Users":       users,
+		"Limit":       usersPageLimit,
+		"Self":        user.ID,
+		"Roles":       roles,
+		"Permissions": perms,
+		"Error":       r.URL.Query().Get("error"),
+		"Notice":      r.URL.Query().Get("notice"),
+	})
+	if err != nil {
+		logging.FromContext(r.Context()).Error("render users", "err", err)
+	}
+}
+
+// AdminAssignRole handles the role form on the users page and redirects
+// back to it. Only roles whose permissions the admin holds can be handed
+// out or taken away, and not their own. Requires the users.manage
+// permission.
+//
+//	@Summary      Assign a role (admin)
+//	@Description  Sets a user's role. Only roles within the caller's own permissions can be assigned or replaced. Requires the users.manage permission.
+//	@Tags         admin
+//	@Accept       application/x-www-form-urlencoded
+//	@Param        id    path      string  true  "User ID"
+//	@Param        role  formData  string  true  "Role"
+//	@Success      303  "Redirect to /admin/users"
+//	@Router       /admin/users/{id}/role [post]
+func (h *Handler) AdminAssignRole(w http.ResponseWriter, r *http.Request) {
+	user, _ := GetUserFromContext(r.Context())
+	id := chi.URLParam(r, "id")
+	role := r.PostFormValue("role")
+
+	err := h.auth.AssignRole(user, id, role)
+	switch {
+	case err == nil:
+		logging.FromContext(r.Context()).Info("role assigned", "user_id", id, "role", role)
+		http.Redirect(w, r, "/admin/users?notice="+url.QueryEscape("Role updated."), http.StatusSeeOther)
+		return
+	case errors.Is(err, auth.ErrInvalidRole):
+		h.redirectWithError(w, r, "/admin/users", "No such role.")
+	case errors.Is(err, auth.ErrUserNotFound):
+		h.redirectWithError(w, r, "/admin/users", "No such user.")
+	case errors.Is(err, auth.ErrForbidden):
+		h.redirectWithError(w, r, "/admin/users", "You can't give or take away that role.")
+	default:
+		logging.FromContext(r.Context()).Error("assign role", "user_id", id, "err", err)
+		h.redirectWithError(w, r, "/admin/users", "Something went wrong. Please try again.")
+	}
+}
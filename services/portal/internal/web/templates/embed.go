@@ -1,5 +1,6 @@
 // Package templates provides embedded HTML templates for the portal web UI.
-// Only giveaway templates remain; all other pages are served by the Astro frontend.
+// Only the admin analytics, users and search pages remain; all other
+// pages are served by the Astro frontend.
 package templates
 
 import "embed"
@@ -0,0 +1,52 @@
+// Package fees calculates what a claimer pays to have a giveaway item
+// delivered: the driver's time and the fuel for the round trip from the
+// federal building in downtown Seattle, where distances are measured from.
+package fees
+
+import "math"
+
+// Rates are the costs a delivery fee is made of.
+type Rates struct {
+	WagePerHour  float64 // driver's pay, for the round trip's drive time
+	GasPerGallon float64
+	MPG          float64
+}
+
+// DefaultRates are WA minimum wage, $5/gal gas and 20 MPG, as the
+// giveaway pages tell claimers.
+var DefaultRates = Rates{WagePerHour: 20, GasPerGallon: 5, MPG: 20}
+
+// DeliveryFee is a delivery fee and how it was reached.
+type DeliveryFee struct {
+	Miles          float64 `json:"miles"`         // one-way
+	DriveMinutes   int     `json:"drive_minutes"` // one-way
+	RoundTripMiles float64 `json:"round_trip_miles"`
+	LaborCost      float64 `json:"labor_cost"`
+	FuelCost       float64 `json:"fuel_cost"`
+	Total          float64 `json:"total"`
+}
+
+// CalculateDelivery returns the fee for delivering to a place miles and
+// minutes away, one way, at rates. Costs are rounded to the cent, and
+// negative distances and times count as zero.
+func CalculateDelivery(miles float64, minutes int, rates Rates) DeliveryFee {
+	miles = math.Max(miles, 0)
+	minutes = max(minutes, 0)
+	f := DeliveryFee{Miles: miles, DriveMinutes: minutes, RoundTripMiles: 2 * miles}
+	f.LaborCost = cents(float64(2*minutes) / 60 * rates.WagePerHour)
+	if rates.MPG > 0 {
+		f.FuelCost = cents(f.RoundTripMiles / rates.MPG * rates.GasPerGallon)
+	}
+	f.Total = cents(f.LaborCost + f.FuelCost)
+	return f
+}
+
+// CalculateDeliveryDefault is CalculateDelivery at DefaultRates.
+func CalculateDeliveryDefault(miles float64, minutes int) DeliveryFee {
+	return CalculateDelivery(miles, minutes, DefaultRates)
+}
+
+// cents rounds dollars to the nearest cent.
+func cents(dollars float64) float64 {
+	return math.Round(dollars*100) / 100
+}
@@ -0,0 +1,25 @@
+package fees
+
+import "testing"
+
+func TestCalculateDelivery(t *testing.T) {
+	for _, c := range []struct {
+		miles   float64
+		minutes int
+		want    DeliveryFee
+	}{
+		// 30 min of driving at $20/hr, 10 mi at $5/gal and 20 MPG.
+		{5, 15, DeliveryFee{Miles: 5, DriveMinutes: 15, RoundTripMiles: 10, LaborCost: 10, FuelCost: 2.5, Total: 12.5}},
+		{4.7, 7, DeliveryFee{Miles: 4.7, DriveMinutes: 7, RoundTripMiles: 9.4, LaborCost: 4.67, FuelCost: 2.35, Total: 7.02}},
+		{0, 0, DeliveryFee{}},
+		{-3, -5, DeliveryFee{}},
+	} {
+		if got := CalculateDeliveryDefault(c.miles, c.minutes); got != c.want {
+			t.Errorf("CalculateDeliveryDefault(%v, %d) = %+v, want %+v", c.miles, c.minutes, got, c.want)
+		}
+	}
+
+	if got := CalculateDelivery(10, 30, Rates{WagePerHour: 30}); got.LaborCost != 30 || got.FuelCost != 0 || got.Total != 30 {
+		t.Errorf("without MPG: %+v", got)
+	}
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Login methods recorded for analytics.
+const (
+	LoginPassword = "password"
+	LoginMagic    = "magic_link"
+)
+
+// DayCount is how many of something happened on one day (UTC).
+type DayCount struct {
+	Day   time.Time `json:"day"`
+	Count int       `json:"count"`
+}
+
+// SearchCount is how often the magic bar was searched for a query.
+type SearchCount struct {
+	Query string `json:"query"`
+	Count int    `json:"count"`
+}
+
+// ClaimStats summarizes giveaway items and the claims made on them.
+type ClaimStats struct {
+	Items        int            `json:"items"`         // items listed
+	ClaimedItems int            `json:"claimed_items"` // items with at least one claim
+	Claims       int            `json:"claims"`
+	ByStatus     map[string]int `json:"by_status"` // claims by ClaimStatus
+}
+
+// ItemRate is the share of items that drew a claim.
+func (c *ClaimStats) ItemRate() float64 { return ratio(c.ClaimedItems, c.Items) }
+
+// StatusRate is the share of claims that are in status.
+func (c *ClaimStats) StatusRate(status string) float64 { return ratio(c.ByStatus[status], c.Claims) }
+
+func ratio(n, of int) float64 {
+	if of == 0 {
+		return 0
+	}
+	return float64(n) / float64(of)
+}
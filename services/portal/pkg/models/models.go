@@ -2,31 +2,51 @@ package models
 
 import "time"
 
-// Role constants for user authorization.
+// Role constants for user authorization. What each role may do is set
+// by auth.Roles.
 const (
-	RoleUser  = "user"
-	RoleAdmin = "admin"
+	RoleUser      = "user"
+	RoleModerator = "moderator" // runs the giveaway
+	RoleSupport   = "support"   // helps users with their accounts
+	RoleAdmin     = "admin"
 )
 
 // User represents a registered user.
 type User struct {
-	ID           string    `json:"id"`
-	Username     string    `json:"username"`
-	Email        string    `json:"email"`
-	PhoneNumber  string    `json:"phone_number"`
-	Name         string    `json:"name"`
-	Role         string    `json:"role"`
-	PasswordHash string    `json:"-"`
-	EmailHash    string    `json:"-"`
-	PhoneHash    string    `json:"-"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
-	LastLoginAt  time.Time `json:"last_login_at"`
+	ID            string    `json:"id"`
+	Username      string    `json:"username"`
+	Email         string    `json:"email"`
+	PhoneNumber   string    `json:"phone_number"`
+	Name          string    `json:"name"`
+	Role          string    `json:"role"`
+	PasswordHash  string    `json:"-"`
+	EmailHash     string    `json:"-"`
+	PhoneHash     string    `json:"-"`
+	PhoneVerified bool      `json:"phone_verified"` // a code texted to PhoneNumber was entered
+	AvatarKey     string    `json:"-"`              // upload key of the avatar image; empty for none
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	LastLoginAt   time.Time `json:"last_login_at"`
 }
 
-// IsAdmin returns true if the user has the admin role.
-func (u *User) IsAdmin() bool {
-	return u.Role == RoleAdmin
+// AvatarURL returns where the portal serves the user's avatar, or "" if
+// they have none.
+func (u *User) AvatarURL() string {
+	if u.AvatarKey == "" {
+		return ""
+	}
+	return "/uploads/" + u.AvatarKey
+}
+
+// PhoneChange is a phone number a user asked to switch to, waiting for the
+// code texted to it.
+type PhoneChange struct {
+	UserID    string
+	Phone     string
+	CodeHash  string // SHA-256 of the code, hex encoded
+	Attempts  int    // wrong codes entered so far
+	ExpiresAt time.Time
+	CreatedAt time.Time
 }
 
 // Session represents an active user session.
@@ -0,0 +1,15 @@
+package models
+
+// SearchGroup is one type of record found by the admin search, laid out as
+// a table so builds without the giveaway need not know its types.
+type SearchGroup struct {
+	Name    string         // plural, such as "Users"
+	Columns []SearchColumn // headings
+	Rows    [][]string     // one cell per column
+}
+
+// SearchColumn heads a SearchGroup column.
+type SearchColumn struct {
+	Title    string
+	Searched bool // whether the search looked in it, so matches are highlighted
+}
@@ -0,0 +1,8 @@
+// Package static provides the portal's embedded static files: CSS,
+// scripts and images, served under /static by internal/assets.
+package static
+
+import "embed"
+
+//go:embed css js images
+var FS embed.FS
@@ -30,6 +30,7 @@ import (
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/jredh-dev/nexus/internal/authz"
 	"github.com/jredh-dev/nexus/services/portal/config"
 	"github.com/jredh-dev/nexus/services/portal/internal/actions"
 	"github.com/jredh-dev/nexus/services/portal/internal/auth"
@@ -212,7 +213,7 @@ func testServerWithAccount(t *testing.T) (srv *httptest.Server, client *http.Cli
 	})
 	r.Group(func(r chi.Router) {
 		r.Use(handlers.AuthMiddleware(authSvc))
-		r.Use(handlers.AdminMiddleware)
+		r.Use(handlers.RequirePermission(authz.MagicLinkIssue))
 		r.Post("/admin/magic-link", h.AdminGenerateMagicLink)
 	})
 
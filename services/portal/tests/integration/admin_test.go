@@ -3,6 +3,7 @@
 package integration
 
 import (
+	"errors"
 	"net/http"
 	"net/http/cookiejar"
 	"net/http/httptest"
@@ -13,6 +14,7 @@ import (
 	"testing"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/jredh-dev/nexus/internal/authz"
 	"github.com/jredh-dev/nexus/services/portal/config"
 	"github.com/jredh-dev/nexus/services/portal/internal/actions"
 	"github.com/jredh-dev/nexus/services/portal/internal/auth"
@@ -59,7 +61,7 @@ func testServerWithDB(t *testing.T) (srv *httptest.Server, client *http.Client,
 	r.Get("/auth/magic", h.MagicLogin)
 	r.Group(func(r chi.Router) {
 		r.Use(handlers.AuthMiddleware(authSvc))
-		r.Use(handlers.AdminMiddleware)
+		r.Use(handlers.RequirePermission(authz.MagicLinkIssue))
 		r.Post("/admin/magic-link", h.AdminGenerateMagicLink)
 	})
 
@@ -130,7 +132,7 @@ func TestAdminRoutes_NonAdminGetsForbidden(t *testing.T) {
 
 	// Non-admin should get 403 on admin routes.
 	// We need a client that doesn't follow redirects for the admin check
-	// since AuthMiddleware does redirects but AdminMiddleware returns 403.
+	// since AuthMiddleware does redirects but RequirePermission returns 403.
 	noRedirectClient := &http.Client{
 		Jar: client.Jar,
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
@@ -399,7 +401,58 @@ func TestUserRole_DefaultIsUser(t *testing.T) {
 	if dbUser.Role != models.RoleAdmin {
 		t.Errorf("after promotion role = %q, want %q", dbUser.Role, models.RoleAdmin)
 	}
-	if !dbUser.IsAdmin() {
-		t.Error("IsAdmin() returned false after promotion")
+	if !auth.Can(dbUser, authz.UsersManage) {
+		t.Error("Can(users.manage) returned false after promotion")
+	}
+}
+
+func TestAssignRole(t *testing.T) {
+	_, _, db, authSvc, cleanup := testServerWithDB(t)
+	defer cleanup()
+
+	signup := func(name, phone string) *models.User {
+		t.Helper()
+		u, err := authSvc.Signup(name, name+"@example.com", phone, "password", name)
+		if err != nil {
+			t.Fatalf("signup %s: %v", name, err)
+		}
+		return u
+	}
+	admin := signup("boss", "5556660001")
+	support := signup("helper", "5556660002")
+	user := signup("someone", "5556660003")
+	if err := db.UpdateUserRole(admin.ID, models.RoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+	admin.Role = models.RoleAdmin
+
+	if err := authSvc.AssignRole(admin, support.ID, models.RoleSupport); err != nil {
+		t.Fatalf("admin assigns support: %v", err)
+	}
+	support.Role = models.RoleSupport
+
+	for _, c := range []struct {
+		name         string
+		actor        *models.User
+		target, role string
+		want         error
+	}{
+		{"support gives a role within its own", support, user.ID, models.RoleSupport, nil},
+		{"support can't make an admin", support, user.ID, models.RoleAdmin, auth.ErrForbidden},
+		{"support can't hand out giveaway.manage", support, user.ID, models.RoleModerator, auth.ErrForbidden},
+		{"support can't demote an admin", support, admin.ID, models.RoleUser, auth.ErrForbidden},
+		{"no one changes their own role", admin, admin.ID, models.RoleUser, auth.ErrForbidden},
+		{"users can't assign roles", user, support.ID, models.RoleUser, auth.ErrForbidden},
+		{"unknown role", admin, user.ID, "root", auth.ErrInvalidRole},
+		{"unknown user", admin, "nobody", models.RoleUser, auth.ErrUserNotFound},
+	} {
+		if err := authSvc.AssignRole(c.actor, c.target, c.role); !errors.Is(err, c.want) {
+			t.Errorf("%s: err = %v, want %v", c.name, err, c.want)
+		}
+	}
+
+	got, _ := db.GetUserByID(user.ID)
+	if got.Role != models.RoleSupport {
+		t.Errorf("role = %q, want %q", got.Role, models.RoleSupport)
 	}
 }
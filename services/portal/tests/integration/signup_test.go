@@ -3,6 +3,7 @@
 package integration
 
 import (
+	"errors"
 	"net/http"
 	"net/http/cookiejar"
 	"net/http/httptest"
@@ -11,8 +12,10 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/jredh-dev/nexus/internal/authz"
 	"github.com/jredh-dev/nexus/services/portal/config"
 	"github.com/jredh-dev/nexus/services/portal/internal/actions"
 	"github.com/jredh-dev/nexus/services/portal/internal/auth"
@@ -20,6 +23,13 @@ import (
 	"github.com/jredh-dev/nexus/services/portal/internal/web/handlers"
 )
 
+// testSSOSecret is the SSO_SECRET test servers sign JWTs with.
+const testSSOSecret = "test-sso-secret"
+
+// testIntrospectKey is the SSO_INTROSPECT_KEY test servers answer
+// introspection to.
+const testIntrospectKey = "test-introspect-key"
+
 // testServer spins up a full portal stack backed by a temp SQLite file.
 // Matches the production router: no Go-rendered pages (Astro owns those).
 // Caller must defer cleanup().
@@ -44,6 +54,7 @@ func testServer(t *testing.T) (srv *httptest.Server, client *http.Client, cleanu
 		Server:  config.ServerConfig{Port: "0", Env: "test"},
 		DB:      config.DBConfig{Path: dbPath},
 		Session: config.SessionConfig{Secret: "test-secret", MaxAge: 3600},
+		SSO:     config.SSOConfig{Secret: testSSOSecret, TokenTTL: time.Hour, IntrospectKey: testIntrospectKey},
 	}
 
 	authService := auth.New(db, cfg)
@@ -57,9 +68,11 @@ func testServer(t *testing.T) (srv *httptest.Server, client *http.Client, cleanu
 	r.Get("/logout", h.Logout)
 	r.Get("/auth/magic", h.MagicLogin)
 	r.Get("/api/actions", h.SearchActions)
+	r.Post("/api/auth/introspect", h.Introspect)
+	r.With(handlers.APIAuthMiddleware(authService)).Post("/api/auth/token", h.IssueToken)
 	r.Group(func(r chi.Router) {
 		r.Use(handlers.AuthMiddleware(authService))
-		r.Use(handlers.AdminMiddleware)
+		r.Use(handlers.RequirePermission(authz.MagicLinkIssue))
 		r.Post("/admin/magic-link", h.AdminGenerateMagicLink)
 	})
 
@@ -406,3 +419,27 @@ func TestSessionRoundTrip(t *testing.T) {
 		}
 	}
 }
+
+func TestSignupWaitlist(t *testing.T) {
+	db, err := database.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	defer db.Close()
+
+	waitlist := true
+	svc := auth.New(db, &config.Config{}, auth.WithWaitlist(func() bool { return waitlist }))
+
+	_, err = svc.Signup("waiter", "wait@example.com", "5550001111", "securepassword", "")
+	if !errors.Is(err, auth.ErrWaitlisted) {
+		t.Fatalf("Signup in waitlist mode: %v, want ErrWaitlisted", err)
+	}
+	if u, _ := db.GetUserByEmail("wait@example.com"); u != nil {
+		t.Error("account created in waitlist mode")
+	}
+
+	waitlist = false
+	if _, err := svc.Signup("waiter", "wait@example.com", "5550001111", "securepassword", ""); err != nil {
+		t.Errorf("Signup with the waitlist off: %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+//go:build integration
+
+package integration
+
+// Single sign-on integration tests: the credentials other nexus services
+// accept, and the introspection endpoint they check them with.
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jredh-dev/nexus/internal/sso"
+)
+
+// postIntrospect asks the portal about token, sending key unless it is
+// empty.
+func postIntrospect(t *testing.T, srvURL, key, token string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, srvURL+"/api/auth/introspect", strings.NewReader(url.Values{"token": {token}}.Encode()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("introspect: %v", err)
+	}
+	return resp
+}
+
+func introspect(t *testing.T, srvURL, token string) sso.Introspection {
+	t.Helper()
+	resp := postIntrospect(t, srvURL, testIntrospectKey, token)
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("introspect status = %d, want 200", resp.StatusCode)
+	}
+	var in sso.Introspection
+	if err := json.NewDecoder(resp.Body).Decode(&in); err != nil {
+		t.Fatalf("decode introspection: %v", err)
+	}
+	return in
+}
+
+func TestSSOIntrospectAndToken(t *testing.T) {
+	srv, client, cleanup := testServer(t)
+	defer cleanup()
+
+	resp, err := postForm(client, srv.URL+"/signup", url.Values{
+		"username": {"ssouser"},
+		"email":    {"sso@example.com"},
+		"phone":    {"5558888888"},
+		"password": {"password"},
+		"name":     {"SSO User"},
+	})
+	if err != nil {
+		t.Fatalf("signup: %v", err)
+	}
+	resp.Body.Close()
+
+	u, _ := url.Parse(srv.URL)
+	var session string
+	for _, c := range client.Jar.Cookies(u) {
+		if c.Name == sso.SessionCookie {
+			session = c.Value
+		}
+	}
+	if session == "" {
+		t.Fatal("no session cookie set after signup")
+	}
+
+	// The session cookie introspects as its user.
+	in := introspect(t, srv.URL, session)
+	if !in.Active || in.Identity == nil || in.Username != "ssouser" || in.Email != "sso@example.com" {
+		t.Fatalf("introspect session: %+v", in)
+	}
+	userID := in.UserID
+
+	// Only callers with the introspection key are answered.
+	for _, key := range []string{"", "wrong-key"} {
+		resp := postIntrospect(t, srv.URL, key, session)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("introspect with key %q: %d, want 401", key, resp.StatusCode)
+		}
+	}
+
+	// A logged-in client gets a JWT that checks out locally and at the portal.
+	resp, err = client.Post(srv.URL+"/api/auth/token", "", nil)
+	if err != nil {
+		t.Fatalf("token: %v", err)
+	}
+	var tok struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	json.NewDecoder(resp.Body).Decode(&tok) //nolint:errcheck
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || tok.Token == "" {
+		t.Fatalf("token: status %d, token %q", resp.StatusCode, tok.Token)
+	}
+	if id, err := sso.Verify([]byte(testSSOSecret), tok.Token, time.Now()); err != nil || id.UserID != userID {
+		t.Errorf("verify JWT locally: %+v, %v", id, err)
+	}
+	if in := introspect(t, srv.URL, tok.Token); !in.Active || in.UserID != userID {
+		t.Errorf("introspect JWT: %+v", in)
+	}
+
+	// A Verifier pointed at the portal accepts both.
+	v := sso.NewVerifier(srv.URL, testIntrospectKey, nil)
+	for _, cred := range []string{session, tok.Token} {
+		if id, err := v.Verify(context.Background(), cred); err != nil || id.UserID != userID {
+			t.Errorf("Verifier: %+v, %v", id, err)
+		}
+	}
+
+	// Anything else is inactive, and so is a token nobody is logged in for.
+	if in := introspect(t, srv.URL, "not-a-session"); in.Active || in.Identity != nil {
+		t.Errorf("introspect garbage: %+v", in)
+	}
+	resp, err = http.Post(srv.URL+"/api/auth/token", "", nil)
+	if err != nil {
+		t.Fatalf("anonymous token: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("token without a session: %d, want 401", resp.StatusCode)
+	}
+}
@@ -11,30 +11,33 @@ package main
 import (
 	"context"
 	"fmt"
-	"log"
 	"log/slog"
 	"net/http"
 	"os"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jredh-dev/nexus/internal/logging"
 	"github.com/jredh-dev/nexus/services/ref/internal/db"
 	"github.com/jredh-dev/nexus/services/ref/internal/handler"
 )
 
 func main() {
-	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo})))
+	if err := logging.Setup("ref-server"); err != nil {
+		fmt.Fprintf(os.Stderr, "ref-server: %v\n", err)
+		os.Exit(1)
+	}
 
 	cfg := loadConfig()
 	ctx := context.Background()
 
 	pool, err := pgxpool.New(ctx, cfg.databaseURL)
 	if err != nil {
-		log.Fatalf("db connect: %v", err)
+		logging.Fatal("db connect", "err", err)
 	}
 	defer pool.Close()
 
 	if err := db.Migrate(ctx, pool); err != nil {
-		log.Fatalf("migrate: %v", err)
+		logging.Fatal("migrate", "err", err)
 	}
 	slog.Info("database migrations applied")
 
@@ -45,7 +48,7 @@ func main() {
 	addr := ":" + cfg.port
 	slog.Info("ref-server starting", "addr", addr)
 	if err := http.ListenAndServe(addr, mux); err != nil {
-		log.Fatalf("serve: %v", err)
+		logging.Fatal("serve", "err", err)
 	}
 }
 
@@ -3,7 +3,8 @@
 fn main() -> Result<(), Box<dyn std::error::Error>> {
     tonic_build::configure()
         .build_server(true)
-        .build_client(false)
+        // For followers, which stream from a primary (see replica.rs).
+        .build_client(true)
         .compile_protos(&["proto/hermit.proto"], &["proto"])?;
     Ok(())
 }
@@ -1,21 +1,381 @@
 // SPDX-License-Identifier: AGPL-3.0-or-later
 
+//! Authentication and authorization.
+//!
+//! A client logs in with a username and token from the users file and gets
+//! a session id, which it sends as `x-hermit-session` on every later call.
+//! The interceptor resolves the session to a `Caller`, and each RPC checks
+//! that the caller's role is high enough with `require`.
+//!
+//! Without a users file hermit runs open, as before: any login succeeds and
+//! every caller is an admin.
+//!
+//! A user can be limited to some document store buckets, and to less than
+//! their role in some of them; key calls check that with `require_bucket`.
+//! The relational store and topics aren't kept by bucket, so such users
+//! can't use them at all; those calls check with `require_unscoped`.
+
+use sha2::{Digest, Sha256};
+use std::collections::HashMap;
+use std::fmt;
+use std::path::Path;
+use std::sync::{Arc, Mutex};
+use std::time::{Duration, Instant};
 use tonic::{Request, Status};
 use tracing::warn;
 
+pub const SESSION_METADATA_KEY: &str = "x-hermit-session";
+
+/// How long a session lasts. Clients log in again when it runs out.
+pub const SESSION_TTL: Duration = Duration::from_secs(12 * 60 * 60);
+
+/// What a user may do. Each role can do everything the ones before it can.
+#[derive(Clone, Copy, Debug, PartialEq, Eq, PartialOrd, Ord)]
+pub enum Role {
+    /// Read keys, rows, stats and server info.
+    Read,
+    /// Also change keys and rows.
+    Write,
+    /// Also tail logs, benchmark, and snapshot or restore the database.
+    Admin,
+}
+
+impl Role {
+    pub fn parse(s: &str) -> Option<Role> {
+        match s {
+            "read" => Some(Role::Read),
+            "write" => Some(Role::Write),
+            "admin" => Some(Role::Admin),
+            _ => None,
+        }
+    }
+
+    pub fn as_str(self) -> &'static str {
+        match self {
+            Role::Read => "read",
+            Role::Write => "write",
+            Role::Admin => "admin",
+        }
+    }
+}
+
+impl fmt::Display for Role {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        f.write_str(self.as_str())
+    }
+}
+
+/// Buckets a user may use, with the most they may do in each.
+type Scope = Arc<HashMap<String, Role>>;
+
+/// Who made a request, as the interceptor found it.
+#[derive(Clone, Debug)]
+pub struct Caller {
+    pub user: String,
+    pub role: Role,
+    /// None may use every bucket.
+    pub buckets: Option<Scope>,
+}
+
+impl Caller {
+    /// What the caller may do in a bucket, if they may use it at all.
+    pub fn bucket_role(&self, bucket: &str) -> Option<Role> {
+        match &self.buckets {
+            None => Some(self.role),
+            Some(scope) => scope.get(bucket).map(|&r| r.min(self.role)),
+        }
+    }
+
+    /// Checks that the caller's role is at least `role`.
+    pub fn check(&self, role: Role) -> Result<(), String> {
+        if self.role < role {
+            return Err(format!("{} has {} access; this needs {}", self.user, self.role, role));
+        }
+        Ok(())
+    }
+
+    /// Checks that the caller's role is at least `role` and that they may
+    /// use every bucket, for what isn't kept by bucket.
+    pub fn check_unscoped(&self, role: Role) -> Result<(), String> {
+        self.check(role)?;
+        if self.buckets.is_some() {
+            return Err(format!("{} is limited to some buckets; this needs access to all of them", self.user));
+        }
+        Ok(())
+    }
+
+    /// Checks that the caller may use `bucket` with `role`.
+    pub fn check_bucket(&self, bucket: &str, role: Role) -> Result<(), String> {
+        self.check(role)?;
+        match self.bucket_role(bucket) {
+            None => Err(format!("{} has no access to bucket {}", self.user, bucket)),
+            Some(r) if r < role => Err(format!(
+                "{} has {} access to bucket {}; this needs {}",
+                self.user, r, bucket, role
+            )),
+            Some(_) => Ok(()),
+        }
+    }
+}
+
+struct User {
+    role: Role,
+    token_sha256: [u8; 32],
+    buckets: Option<Scope>,
+}
+
+struct Session {
+    caller: Caller,
+    expires: Instant,
+}
+
+pub struct Auth {
+    /// None runs open: no users file was given.
+    users: Option<HashMap<String, User>>,
+    sessions: Mutex<HashMap<String, Session>>,
+}
+
+impl Auth {
+    /// An Auth that lets everyone in as an admin.
+    pub fn open() -> Auth {
+        Auth {
+            users: None,
+            sessions: Mutex::new(HashMap::new()),
+        }
+    }
+
+    /// Loads the users file: one user per line, `name role sha256
+    /// [buckets]`, where role is read, write or admin and sha256 is the hex
+    /// SHA-256 of the user's token (`printf %s "$token" | sha256sum`).
+    /// Buckets, if given, are the only ones a read or write user may use,
+    /// comma-separated, each optionally `=read` to allow only reads there:
+    /// `orders,audit=read`. Blank lines and lines starting with `#` are
+    /// skipped.
+    pub fn load(path: &Path) -> Result<Auth, String> {
+        let text = std::fs::read_to_string(path).map_err(|e| e.to_string())?;
+        Auth::parse(&text)
+    }
+
+    fn parse(text: &str) -> Result<Auth, String> {
+        let mut users = HashMap::new();
+        for (i, line) in text.lines().enumerate() {
+            let line = line.trim();
+            if line.is_empty() || line.starts_with('#') {
+                continue;
+            }
+            let fields: Vec<&str> = line.split_whitespace().collect();
+            let (name, role, hash, scope) = match fields[..] {
+                [name, role, hash] => (name, role, hash, None),
+                [name, role, hash, scope] => (name, role, hash, Some(scope)),
+                _ => return Err(format!("line {}: want name, role, token sha256 and maybe buckets", i + 1)),
+            };
+            let role = Role::parse(role)
+                .ok_or_else(|| format!("line {}: role {:?}: want read, write or admin", i + 1, role))?;
+            let token_sha256 = parse_sha256(hash)
+                .ok_or_else(|| format!("line {}: token sha256 is not 64 hex digits", i + 1))?;
+            let buckets = match scope {
+                // Admins snapshot, restore and replicate every bucket.
+                Some(_) if role == Role::Admin => {
+                    return Err(format!("line {}: admins can't be limited to buckets", i + 1));
+                }
+                Some(s) => Some(Arc::new(parse_scope(s).map_err(|e| format!("line {}: {}", i + 1, e))?)),
+                None => None,
+            };
+            let user = User {
+                role,
+                token_sha256,
+                buckets,
+            };
+            if users.insert(name.to_string(), user).is_some() {
+                return Err(format!("line {}: user {:?} listed twice", i + 1, name));
+            }
+        }
+        if users.is_empty() {
+            return Err("no users".to_string());
+        }
+        Ok(Auth {
+            users: Some(users),
+            sessions: Mutex::new(HashMap::new()),
+        })
+    }
+
+    pub fn user_count(&self) -> usize {
+        self.users.as_ref().map_or(0, |u| u.len())
+    }
+
+    /// Checks a username and token and starts a session for them.
+    pub fn login(&self, username: &str, token: &str) -> Result<(String, Role), String> {
+        let (role, buckets) = match &self.users {
+            None => (Role::Admin, None),
+            Some(users) => match users.get(username) {
+                Some(u) if digest_eq(&Sha256::digest(token.as_bytes()).into(), &u.token_sha256) => {
+                    (u.role, u.buckets.clone())
+                }
+                // The same error either way, so usernames can't be probed.
+                _ => return Err("unknown user or wrong token".to_string()),
+            },
+        };
+        let id = uuid::Uuid::new_v4().to_string();
+        let now = Instant::now();
+        let mut sessions = self.sessions.lock().map_err(|e| e.to_string())?;
+        sessions.retain(|_, s| s.expires > now);
+        sessions.insert(
+            id.clone(),
+            Session {
+                caller: Caller {
+                    user: username.to_string(),
+                    role,
+                    buckets,
+                },
+                expires: now + SESSION_TTL,
+            },
+        );
+        Ok((id, role))
+    }
+
+    fn session(&self, id: &str) -> Option<Caller> {
+        let sessions = self.sessions.lock().ok()?;
+        sessions
+            .get(id)
+            .filter(|s| s.expires > Instant::now())
+            .map(|s| s.caller.clone())
+    }
+
+    /// Checks a request's shared secret and session. A valid session is
+    /// stored in the request's extensions as a `Caller` for `require`; a
+    /// request without one passes through, for Login and Ping.
+    pub fn intercept(&self, req: Request<()>) -> Result<Request<()>, Status> {
+        let mut req = secret_interceptor(req)?;
+        let id = req
+            .metadata()
+            .get(SESSION_METADATA_KEY)
+            .map(|v| v.to_str().unwrap_or_default().to_string());
+        let open = self.users.is_none();
+        let caller = match id.map(|id| self.session(&id).ok_or(id)) {
+            Some(Ok(c)) => Some(c),
+            // Sessions don't outlive a restart; open, that doesn't matter.
+            Some(Err(_)) if open => Some(anonymous()),
+            Some(Err(_)) => return Err(Status::unauthenticated("session expired; log in again")),
+            None if open => Some(anonymous()),
+            None => None,
+        };
+        if let Some(c) = caller {
+            req.extensions_mut().insert(c);
+        }
+        Ok(req)
+    }
+
+    /// Checks a shared secret and session sent some other way than gRPC
+    /// metadata, as the TCP protocol's AUTH does.
+    pub fn authenticate(&self, secret: &str, session: &str) -> Result<Caller, String> {
+        if expected_secret().is_some_and(|want| !secret_eq(secret, &want)) {
+            warn!("invalid secret on the TCP protocol");
+            return Err("invalid secret".to_string());
+        }
+        match self.session(session) {
+            Some(c) => Ok(c),
+            None if self.users.is_none() => Ok(anonymous()),
+            None => Err("session expired; log in again".to_string()),
+        }
+    }
+
+    /// Who a TCP connection is before it sends AUTH: an admin if hermit
+    /// runs open and without a secret, otherwise nobody.
+    pub fn unauthenticated(&self) -> Option<Caller> {
+        (self.users.is_none() && expected_secret().is_none()).then(anonymous)
+    }
+}
+
+/// The caller when hermit runs open.
+fn anonymous() -> Caller {
+    Caller {
+        user: String::new(),
+        role: Role::Admin,
+        buckets: None,
+    }
+}
+
+/// Returns the request's caller if their role is at least `role`.
+pub fn require<T>(req: &Request<T>, role: Role) -> Result<Caller, Status> {
+    let Some(caller) = req.extensions().get::<Caller>() else {
+        return Err(Status::unauthenticated("log in first"));
+    };
+    caller.check(role).map_err(Status::permission_denied)?;
+    Ok(caller.clone())
+}
+
+/// Returns the request's caller if their role is at least `role` and they
+/// aren't limited to some buckets.
+pub fn require_unscoped<T>(req: &Request<T>, role: Role) -> Result<Caller, Status> {
+    let caller = require(req, role)?;
+    caller.check_unscoped(role).map_err(Status::permission_denied)?;
+    Ok(caller)
+}
+
+/// Returns the request's caller if they may use `bucket` with `role`.
+pub fn require_bucket<T>(req: &Request<T>, bucket: &str, role: Role) -> Result<Caller, Status> {
+    let caller = require(req, role)?;
+    caller.check_bucket(bucket, role).map_err(Status::permission_denied)?;
+    Ok(caller)
+}
+
+/// The interceptor for the hermit service.
+pub fn interceptor(auth: Arc<Auth>) -> impl Fn(Request<()>) -> Result<Request<()>, Status> + Clone {
+    move |req| auth.intercept(req)
+}
+
+/// Parses a users file bucket list, `orders,audit=read`. A bucket without
+/// a role gets the user's own.
+fn parse_scope(s: &str) -> Result<HashMap<String, Role>, String> {
+    let mut scope = HashMap::new();
+    for entry in s.split(',') {
+        let (bucket, role) = match entry.split_once('=') {
+            Some((b, r)) => {
+                let role = Role::parse(r).ok_or_else(|| format!("bucket {}: role {:?}: want read or write", b, r))?;
+                (b, role)
+            }
+            None => (entry, Role::Admin), // capped at the user's role
+        };
+        crate::db::check_bucket_name(bucket)?;
+        scope.insert(bucket.to_string(), role);
+    }
+    Ok(scope)
+}
+
+fn parse_sha256(hex: &str) -> Option<[u8; 32]> {
+    if hex.len() != 64 {
+        return None;
+    }
+    let mut out = [0u8; 32];
+    for (i, b) in out.iter_mut().enumerate() {
+        *b = u8::from_str_radix(hex.get(2 * i..2 * i + 2)?, 16).ok()?;
+    }
+    Some(out)
+}
+
+/// Compares digests without stopping at the first difference.
+fn digest_eq(a: &[u8; 32], b: &[u8; 32]) -> bool {
+    a.iter().zip(b).fold(0, |acc, (x, y)| acc | (x ^ y)) == 0
+}
+
+/// Compares a shared secret with the expected one in constant time. Both
+/// are hashed first, so neither the contents nor the length leak.
+fn secret_eq(got: &str, want: &str) -> bool {
+    digest_eq(&Sha256::digest(got.as_bytes()).into(), &Sha256::digest(want.as_bytes()).into())
+}
+
 /// Extracts the shared secret from environment and validates it against
 /// the `x-hermit-secret` metadata header on each gRPC request.
 ///
 /// If HERMIT_SECRET is not set (dev mode), all requests are allowed.
 pub fn secret_interceptor(req: Request<()>) -> Result<Request<()>, Status> {
-    let expected = match std::env::var("HERMIT_SECRET") {
-        Ok(s) if !s.is_empty() => s,
-        _ => return Ok(req), // dev mode: no secret required
+    let Some(expected) = expected_secret() else {
+        return Ok(req); // dev mode: no secret required
     };
 
     match req.metadata().get("x-hermit-secret") {
         Some(val) => match val.to_str() {
-            Ok(v) if v == expected => Ok(req),
+            Ok(v) if secret_eq(v, &expected) => Ok(req),
             _ => {
                 warn!("invalid x-hermit-secret");
                 Err(Status::unauthenticated("invalid secret"))
@@ -27,3 +387,8 @@ pub fn secret_interceptor(req: Request<()>) -> Result<Request<()>, Status> {
         }
     }
 }
+
+/// HERMIT_SECRET, or None in dev mode.
+fn expected_secret() -> Option<String> {
+    std::env::var("HERMIT_SECRET").ok().filter(|s| !s.is_empty())
+}
@@ -0,0 +1,702 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+//! Backups to S3-compatible object storage: AWS S3, MinIO, R2 and the like.
+//!
+//! With HERMIT_S3_BUCKET set, hermit uploads a snapshot every
+//! HERMIT_S3_EVERY seconds as `<prefix>snapshot-<UTC time>.bin`, then
+//! deletes all but the newest HERMIT_S3_KEEP of them. The RestoreFromUrl
+//! RPC loads one back.
+//!
+//! RestoreFromUrl, which only admins may call, fetches whatever URL it is
+//! given, hermit's backups or any other server it can reach, except
+//! link-local addresses: cloud metadata services answer there, with
+//! credentials.
+//!
+//! ```text
+//! HERMIT_S3_BUCKET      bucket to back up to; unset turns backups off
+//! HERMIT_S3_ENDPOINT    default https://s3.<region>.amazonaws.com
+//! HERMIT_S3_REGION      default us-east-1
+//! HERMIT_S3_PREFIX      prefix of the backups' keys, default hermit/
+//! HERMIT_S3_EVERY       seconds between backups, default 3600
+//! HERMIT_S3_KEEP        backups kept, default 24; 0 keeps them all
+//! AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN
+//! ```
+//!
+//! Requests are signed with SigV4 and use path-style URLs, which every
+//! S3-compatible store accepts. They go over a small HTTP/1.1 client of our
+//! own, as /metrics is served by a small server: a whole snapshot is one
+//! request, so there's little for a full client to do. It takes no URL
+//! with characters that would need escaping, or with credentials in it,
+//! and reads no more of a response than its status calls for.
+
+use std::fmt::Write as _;
+use std::io::BufReader as StdBufReader;
+use std::net::{IpAddr, Ipv6Addr, SocketAddr};
+use std::sync::{Arc, OnceLock};
+use std::time::{Duration, SystemTime, UNIX_EPOCH};
+
+use rustls::pki_types::ServerName;
+use rustls::{ClientConfig, RootCertStore};
+use sha2::{Digest, Sha256};
+use tokio::io::{AsyncRead, AsyncReadExt, AsyncWrite, AsyncWriteExt};
+use tokio::net::TcpStream;
+use tokio_rustls::TlsConnector;
+use tracing::{error, info};
+
+use crate::db::{self, Database};
+
+const DEFAULT_EVERY_SECS: u64 = 3600;
+const DEFAULT_KEEP: u64 = 24;
+
+/// Backups' keys are the prefix, this, and the time they were taken.
+const BACKUP_NAME: &str = "snapshot-";
+
+/// Longest one request may take, uploading or downloading a snapshot.
+const HTTP_TIMEOUT: Duration = Duration::from_secs(300);
+
+/// Most of a response's header read before giving up on it.
+const HEAD_MAX_BYTES: usize = 64 * 1024;
+
+/// Most of an error response's body read, for its message.
+const ERROR_BODY_MAX_BYTES: u64 = 64 * 1024;
+
+/// Where the system's CA certificates are when SSL_CERT_FILE doesn't say
+/// (the image installs ca-certificates).
+const CA_BUNDLE: &str = "/etc/ssl/certs/ca-certificates.crt";
+
+/// An S3 bucket to back up to, and how often.
+pub struct S3 {
+    endpoint: Endpoint,
+    base_path: String, // any path the endpoint URL has, without a trailing '/'
+    region: String,
+    bucket: String,
+    prefix: String,
+    access_key: String,
+    secret_key: String,
+    session_token: Option<String>,
+    pub every: Duration,
+    pub keep: usize,
+}
+
+impl S3 {
+    /// Reads the configuration from the environment; None if
+    /// HERMIT_S3_BUCKET is unset.
+    pub fn from_env() -> Result<Option<S3>, String> {
+        let var = |k: &str| std::env::var(k).ok().filter(|v| !v.is_empty());
+        let number = |k: &str, default: u64| match var(k) {
+            Some(v) => v.parse::<u64>().map_err(|_| format!("{}: {:?} is not a number", k, v)),
+            None => Ok(default),
+        };
+        let Some(bucket) = var("HERMIT_S3_BUCKET") else {
+            return Ok(None);
+        };
+        let region = var("HERMIT_S3_REGION").unwrap_or_else(|| "us-east-1".to_string());
+        let url = var("HERMIT_S3_ENDPOINT").unwrap_or_else(|| format!("https://s3.{}.amazonaws.com", region));
+        let (endpoint, path) = Endpoint::parse(&url).map_err(|e| format!("HERMIT_S3_ENDPOINT: {}", e))?;
+        Ok(Some(S3 {
+            endpoint,
+            base_path: path.trim_end_matches('/').to_string(),
+            region,
+            bucket,
+            prefix: var("HERMIT_S3_PREFIX").unwrap_or_else(|| "hermit/".to_string()),
+            access_key: var("AWS_ACCESS_KEY_ID").ok_or("AWS_ACCESS_KEY_ID is not set")?,
+            secret_key: var("AWS_SECRET_ACCESS_KEY").ok_or("AWS_SECRET_ACCESS_KEY is not set")?,
+            session_token: var("AWS_SESSION_TOKEN"),
+            every: Duration::from_secs(number("HERMIT_S3_EVERY", DEFAULT_EVERY_SECS)?.max(1)),
+            keep: number("HERMIT_S3_KEEP", DEFAULT_KEEP)? as usize,
+        }))
+    }
+
+    /// Where backups go, as an s3:// URL.
+    pub fn url(&self) -> String {
+        format!("s3://{}/{}", self.bucket, self.prefix)
+    }
+
+    /// Uploads a snapshot of `db`, then deletes all but the newest `keep`
+    /// backups. Returns the new backup's URL and size.
+    pub async fn back_up(&self, db: &Arc<Database>) -> Result<(String, usize), String> {
+        let db = db.clone();
+        // Compression blocks; keep it off the runtime.
+        let data = tokio::task::spawn_blocking(move || db.snapshot())
+            .await
+            .map_err(|e| e.to_string())??;
+        let key = format!("{}{}{}.bin", self.prefix, BACKUP_NAME, amz_date(SystemTime::now()).0);
+        self.request("PUT", &self.bucket, &key, &[], &data).await?;
+        if self.keep > 0 {
+            let mut backups = self.list(&format!("{}{}", self.prefix, BACKUP_NAME)).await?;
+            // The timestamps sort as the backups were taken.
+            backups.sort();
+            let expired = backups.len().saturating_sub(self.keep);
+            for old in &backups[..expired] {
+                self.request("DELETE", &self.bucket, old, &[], &[]).await?;
+            }
+        }
+        Ok((format!("s3://{}/{}", self.bucket, key), data.len()))
+    }
+
+    /// The keys in the backup bucket starting with `prefix`.
+    async fn list(&self, prefix: &str) -> Result<Vec<String>, String> {
+        let mut keys = Vec::new();
+        let mut token = None;
+        loop {
+            let mut query = vec![("list-type", "2".to_string()), ("prefix", prefix.to_string())];
+            if let Some(t) = token.take() {
+                query.push(("continuation-token", t));
+            }
+            let body = self.request("GET", &self.bucket, "", &query, &[]).await?;
+            let body = String::from_utf8_lossy(&body);
+            keys.extend(xml_values(&body, "Key"));
+            if xml_values(&body, "IsTruncated").first().map(String::as_str) != Some("true") {
+                return Ok(keys);
+            }
+            token = Some(xml_values(&body, "NextContinuationToken").pop().ok_or("truncated list without a token")?);
+        }
+    }
+
+    /// Makes a signed request for `key` in `bucket`, or for the bucket
+    /// itself if `key` is empty, and returns the response body.
+    async fn request(
+        &self,
+        method: &str,
+        bucket: &str,
+        key: &str,
+        query: &[(&str, String)],
+        body: &[u8],
+    ) -> Result<Vec<u8>, String> {
+        let mut path = format!("{}/{}", self.base_path, uri_encode(bucket, false));
+        if !key.is_empty() {
+            path = format!("{}/{}", path, uri_encode(key, true));
+        }
+        let mut query: Vec<String> = query
+            .iter()
+            .map(|(k, v)| format!("{}={}", uri_encode(k, false), uri_encode(v, false)))
+            .collect();
+        query.sort();
+        let query = query.join("&");
+
+        let payload_hash = hex(&Sha256::digest(body));
+        let (date_time, date) = amz_date(SystemTime::now());
+        let mut headers = vec![
+            ("host", self.endpoint.host_header()),
+            ("x-amz-content-sha256", payload_hash.clone()),
+            ("x-amz-date", date_time.clone()),
+        ];
+        if let Some(t) = &self.session_token {
+            headers.push(("x-amz-security-token", t.clone()));
+        }
+        let authorization = authorization(
+            &Credentials {
+                access_key: &self.access_key,
+                secret_key: &self.secret_key,
+                region: &self.region,
+                date_time: &date_time,
+                date: &date,
+            },
+            method,
+            &path,
+            &query,
+            &headers,
+            &payload_hash,
+        );
+        headers.push(("authorization", authorization));
+        headers.remove(0); // http sends Host itself
+
+        let target = if query.is_empty() { path } else { format!("{}?{}", path, query) };
+        let (status, resp) = http(&self.endpoint, method, &target, &headers, body).await?;
+        if !(200..300).contains(&status) {
+            let why = xml_values(&String::from_utf8_lossy(&resp), "Message").pop().unwrap_or_default();
+            return Err(format!("{} s3://{}/{}: HTTP {} {}", method, bucket, key, status, why));
+        }
+        Ok(resp)
+    }
+}
+
+/// Backs up every `s3.every` until the server exits.
+pub async fn run_backups(db: Arc<Database>, s3: Arc<S3>) {
+    let mut tick = tokio::time::interval(s3.every);
+    tick.tick().await; // the first tick is immediate; nothing has changed yet
+    loop {
+        tick.tick().await;
+        match s3.back_up(&db).await {
+            Ok((url, bytes)) => info!(%url, bytes, "backed up"),
+            Err(e) => error!("backup: {}", e),
+        }
+    }
+}
+
+/// Downloads a snapshot from an s3://bucket/key URL, signed with `s3`'s
+/// credentials, or from an http(s):// URL as it is (a presigned URL, say),
+/// unless its host is link-local.
+pub async fn fetch(url: &str, s3: Option<&S3>) -> Result<Vec<u8>, String> {
+    if let Some(rest) = url.strip_prefix("s3://") {
+        let s3 = s3.ok_or("s3:// URLs need HERMIT_S3_BUCKET and AWS credentials set")?;
+        let (bucket, key) = rest
+            .split_once('/')
+            .filter(|(b, k)| !b.is_empty() && !k.is_empty())
+            .ok_or("want s3://bucket/key")?;
+        return s3.request("GET", bucket, key, &[], &[]).await;
+    }
+    let (mut endpoint, target) = Endpoint::parse(url)?;
+    endpoint.refuse_link_local = true;
+    match http(&endpoint, "GET", &target, &[], &[]).await? {
+        (200, body) => Ok(body),
+        (status, _) => Err(format!("GET {}: HTTP {}", url, status)),
+    }
+}
+
+/// The server part of an http:// or https:// URL.
+struct Endpoint {
+    tls: bool,
+    host: String,
+    port: u16,
+    /// Set for URLs callers give, which mustn't reach metadata services.
+    refuse_link_local: bool,
+}
+
+impl Endpoint {
+    /// Splits a URL into its server and the rest, path and query. Anything
+    /// that would need escaping in a request line, such as spaces and line
+    /// breaks, must already be percent-encoded.
+    fn parse(url: &str) -> Result<(Endpoint, String), String> {
+        if let Some(c) = url.chars().find(|&c| c == ' ' || c.is_control() || !c.is_ascii()) {
+            return Err(format!("{:?}: {:?} must be percent-encoded", url, c));
+        }
+        let (tls, rest) = match url.split_once("://") {
+            Some(("https", rest)) => (true, rest),
+            Some(("http", rest)) => (false, rest),
+            _ => return Err(format!("{}: want an s3://, http:// or https:// URL", url)),
+        };
+        let rest = rest.split('#').next().unwrap_or_default();
+        let (authority, target) = match rest.find(['/', '?']) {
+            Some(i) if rest[i..].starts_with('?') => (&rest[..i], format!("/{}", &rest[i..])),
+            Some(i) => (&rest[..i], rest[i..].to_string()),
+            None => (rest, "/".to_string()),
+        };
+        if authority.contains('@') {
+            return Err(format!("{}: credentials in URLs aren't supported; presign the URL or use s3://", url));
+        }
+        let (host, port) = match authority.rsplit_once(':') {
+            Some((host, port)) if !authority.ends_with(']') => {
+                (host, port.parse().map_err(|_| format!("{}: bad port", url))?)
+            }
+            _ => (authority, if tls { 443 } else { 80 }),
+        };
+        let host = host.trim_start_matches('[').trim_end_matches(']');
+        if host.is_empty() {
+            return Err(format!("{}: no host", url));
+        }
+        let endpoint = Endpoint {
+            tls,
+            host: host.to_string(),
+            port,
+            refuse_link_local: false,
+        };
+        Ok((endpoint, target))
+    }
+
+    /// Connects to the server, to one of its addresses that isn't
+    /// link-local if it must not be. The addresses checked are the ones
+    /// connected to, so a DNS answer can't change between the two.
+    async fn connect(&self) -> Result<TcpStream, String> {
+        let mut addrs: Vec<SocketAddr> = tokio::net::lookup_host((self.host.as_str(), self.port))
+            .await
+            .map_err(|e| format!("resolve {}: {}", self.host, e))?
+            .collect();
+        if self.refuse_link_local {
+            addrs.retain(|a| !link_local(a.ip()));
+            if addrs.is_empty() {
+                return Err(format!("{}: refusing a link-local address", self.host_header()));
+            }
+        }
+        TcpStream::connect(&addrs[..])
+            .await
+            .map_err(|e| format!("connect to {}: {}", self.host_header(), e))
+    }
+
+    fn host_header(&self) -> String {
+        let host = if self.host.contains(':') {
+            format!("[{}]", self.host) // IPv6
+        } else {
+            self.host.clone()
+        };
+        if self.port == if self.tls { 443 } else { 80 } {
+            host
+        } else {
+            format!("{}:{}", host, self.port)
+        }
+    }
+}
+
+/// Makes one HTTP/1.1 request and returns the response's status and body.
+async fn http(
+    endpoint: &Endpoint,
+    method: &str,
+    target: &str,
+    headers: &[(&str, String)],
+    body: &[u8],
+) -> Result<(u16, Vec<u8>), String> {
+    let request = async {
+        let tcp = endpoint.connect().await?;
+        if !endpoint.tls {
+            return exchange(tcp, endpoint, method, target, headers, body).await;
+        }
+        let name = ServerName::try_from(endpoint.host.clone()).map_err(|e| e.to_string())?;
+        let tls = tls_connector()?
+            .connect(name, tcp)
+            .await
+            .map_err(|e| format!("TLS with {}: {}", endpoint.host_header(), e))?;
+        exchange(tls, endpoint, method, target, headers, body).await
+    };
+    tokio::time::timeout(HTTP_TIMEOUT, request)
+        .await
+        .map_err(|_| format!("{} {}: timed out", method, endpoint.host_header()))?
+}
+
+async fn exchange<S: AsyncRead + AsyncWrite + Unpin>(
+    mut stream: S,
+    endpoint: &Endpoint,
+    method: &str,
+    target: &str,
+    headers: &[(&str, String)],
+    body: &[u8],
+) -> Result<(u16, Vec<u8>), String> {
+    let mut req = format!(
+        "{} {} HTTP/1.1\r\nhost: {}\r\ncontent-length: {}\r\nconnection: close\r\n",
+        method,
+        target,
+        endpoint.host_header(),
+        body.len()
+    );
+    for (k, v) in headers {
+        let _ = write!(req, "{}: {}\r\n", k, v);
+    }
+    req.push_str("\r\n");
+    let io = |e: std::io::Error| format!("{} {}: {}", method, endpoint.host_header(), e);
+    stream.write_all(req.as_bytes()).await.map_err(io)?;
+    stream.write_all(body).await.map_err(io)?;
+    stream.flush().await.map_err(io)?;
+
+    // The header first, so that an error's body isn't buffered as if it
+    // were a snapshot.
+    let too_large = || format!("{} {}: response too large", method, endpoint.host_header());
+    let mut resp = Vec::new();
+    let end = loop {
+        if let Some(end) = find(&resp, b"\r\n\r\n") {
+            break end;
+        }
+        if resp.len() > HEAD_MAX_BYTES {
+            return Err(too_large());
+        }
+        let mut buf = [0u8; 8192];
+        match stream.read(&mut buf).await {
+            Ok(0) => return Err("truncated HTTP response".to_string()),
+            Ok(n) => resp.extend_from_slice(&buf[..n]),
+            Err(e) if e.kind() == std::io::ErrorKind::UnexpectedEof => {
+                return Err("truncated HTTP response".to_string())
+            }
+            Err(e) => return Err(io(e)),
+        }
+    };
+    let head = parse_head(&resp[..end])?;
+    let mut body = resp.split_off(end + 4);
+
+    let ok = (200..300).contains(&head.status);
+    let limit = if ok {
+        if head.length.is_some_and(|n| n as u64 > db::SNAPSHOT_MAX_BYTES) {
+            return Err(too_large());
+        }
+        db::SNAPSHOT_MAX_BYTES + 64 * 1024 // room for chunk sizes
+    } else {
+        ERROR_BODY_MAX_BYTES
+    };
+    let rest = limit.saturating_sub(body.len() as u64);
+    match (&mut stream).take(rest).read_to_end(&mut body).await {
+        Ok(_) => {}
+        // Plenty of servers close without a TLS close_notify; whether the
+        // body is all there is checked against its length below.
+        Err(e) if e.kind() == std::io::ErrorKind::UnexpectedEof => {}
+        Err(e) => return Err(io(e)),
+    }
+    if !ok {
+        // Only for its message, which may be cut short.
+        let body = head.decode(&body).unwrap_or(body);
+        return Ok((head.status, body));
+    }
+    if body.len() as u64 >= limit {
+        return Err(too_large());
+    }
+    Ok((head.status, head.decode(&body)?))
+}
+
+/// What a response's header says about it.
+struct Head {
+    status: u16,
+    length: Option<usize>,
+    chunked: bool,
+}
+
+/// Reads an HTTP/1.1 response header, without the blank line ending it.
+fn parse_head(head: &[u8]) -> Result<Head, String> {
+    let head = std::str::from_utf8(head).map_err(|_| "HTTP response header is not UTF-8")?;
+    let mut lines = head.split("\r\n");
+    let status = lines
+        .next()
+        .filter(|l| l.starts_with("HTTP/1."))
+        .and_then(|l| l.split(' ').nth(1))
+        .and_then(|s| s.parse().ok())
+        .ok_or("bad HTTP status line")?;
+    let (mut length, mut chunked) = (None, false);
+    for line in lines {
+        let Some((name, value)) = line.split_once(':') else {
+            continue;
+        };
+        if name.eq_ignore_ascii_case("content-length") {
+            length = Some(value.trim().parse::<usize>().map_err(|_| "bad HTTP content-length")?);
+        } else if name.eq_ignore_ascii_case("transfer-encoding") {
+            chunked = value.trim().eq_ignore_ascii_case("chunked");
+        }
+    }
+    Ok(Head { status, length, chunked })
+}
+
+impl Head {
+    /// The body the response's bytes after the header carry.
+    fn decode(&self, body: &[u8]) -> Result<Vec<u8>, String> {
+        match (self.chunked, self.length) {
+            (true, _) => dechunk(body),
+            (false, Some(n)) if body.len() < n => Err("truncated HTTP response".to_string()),
+            (false, Some(n)) => Ok(body[..n].to_vec()),
+            (false, None) => Ok(body.to_vec()),
+        }
+    }
+}
+
+/// Undoes chunked transfer encoding.
+fn dechunk(mut body: &[u8]) -> Result<Vec<u8>, String> {
+    let mut out = Vec::new();
+    loop {
+        let eol = find(body, b"\r\n").ok_or("truncated chunked body")?;
+        let size = std::str::from_utf8(&body[..eol])
+            .ok()
+            .and_then(|s| usize::from_str_radix(s.split(';').next().unwrap_or_default().trim(), 16).ok())
+            .ok_or("bad chunk size")?;
+        body = &body[eol + 2..];
+        if size == 0 {
+            return Ok(out);
+        }
+        if body.len() < size + 2 {
+            return Err("truncated chunked body".to_string());
+        }
+        out.extend_from_slice(&body[..size]);
+        body = &body[size + 2..];
+    }
+}
+
+/// Whether `ip` is link-local, where cloud metadata services answer:
+/// 169.254.0.0/16 and fe80::/10, and fd00:ec2::254 for EC2's over IPv6.
+fn link_local(ip: IpAddr) -> bool {
+    match ip {
+        IpAddr::V4(v4) => v4.is_link_local(),
+        IpAddr::V6(v6) => match v6.to_ipv4_mapped() {
+            Some(v4) => v4.is_link_local(),
+            None => {
+                v6.segments()[0] & 0xffc0 == 0xfe80 || v6 == Ipv6Addr::new(0xfd00, 0xec2, 0, 0, 0, 0, 0, 0x254)
+            }
+        },
+    }
+}
+
+fn find(haystack: &[u8], needle: &[u8]) -> Option<usize> {
+    haystack.windows(needle.len()).position(|w| w == needle)
+}
+
+/// A TLS client trusting the system's CAs. It brings its own crypto
+/// provider: with --no-tls none is installed for the process.
+fn tls_connector() -> Result<TlsConnector, String> {
+    static CONFIG: OnceLock<Result<Arc<ClientConfig>, String>> = OnceLock::new();
+    let config = CONFIG.get_or_init(|| {
+        let path = std::env::var("SSL_CERT_FILE").unwrap_or_else(|_| CA_BUNDLE.to_string());
+        let pem = std::fs::read(&path).map_err(|e| format!("CA certificates {}: {}", path, e))?;
+        let mut roots = RootCertStore::empty();
+        for cert in rustls_pemfile::certs(&mut StdBufReader::new(&pem[..])) {
+            let cert = cert.map_err(|e| format!("CA certificates {}: {}", path, e))?;
+            // Skip any rustls can't use rather than trusting none.
+            let _ = roots.add(cert);
+        }
+        let config = ClientConfig::builder_with_provider(Arc::new(rustls::crypto::ring::default_provider()))
+            .with_safe_default_protocol_versions()
+            .map_err(|e| e.to_string())?
+            .with_root_certificates(roots)
+            .with_no_client_auth();
+        Ok(Arc::new(config))
+    });
+    config.clone().map(TlsConnector::from)
+}
+
+/// What a SigV4 signature is made with, besides the request.
+struct Credentials<'a> {
+    access_key: &'a str,
+    secret_key: &'a str,
+    region: &'a str,
+    date_time: &'a str, // 20261016T120000Z
+    date: &'a str,      // 20261016
+}
+
+/// The Authorization header that signs a request for S3 with SigV4. `path`
+/// and `query` are as sent, encoded and, for the query, sorted; `headers`
+/// are the ones to sign, with lowercase names in order.
+fn authorization(
+    creds: &Credentials,
+    method: &str,
+    path: &str,
+    query: &str,
+    headers: &[(&str, String)],
+    payload_hash: &str,
+) -> String {
+    let mut canonical_headers = String::new();
+    for (k, v) in headers {
+        let _ = writeln!(canonical_headers, "{}:{}", k, v.trim());
+    }
+    let signed_headers = headers.iter().map(|(k, _)| *k).collect::<Vec<_>>().join(";");
+    let canonical = format!(
+        "{}\n{}\n{}\n{}\n{}\n{}",
+        method, path, query, canonical_headers, signed_headers, payload_hash
+    );
+    let scope = format!("{}/{}/s3/aws4_request", creds.date, creds.region);
+    let to_sign = format!(
+        "AWS4-HMAC-SHA256\n{}\n{}\n{}",
+        creds.date_time,
+        scope,
+        hex(&Sha256::digest(canonical.as_bytes()))
+    );
+    let mut key = hmac(format!("AWS4{}", creds.secret_key).as_bytes(), creds.date.as_bytes());
+    for part in [creds.region, "s3", "aws4_request"] {
+        key = hmac(&key, part.as_bytes());
+    }
+    format!(
+        "AWS4-HMAC-SHA256 Credential={}/{}, SignedHeaders={}, Signature={}",
+        creds.access_key,
+        scope,
+        signed_headers,
+        hex(&hmac(&key, to_sign.as_bytes()))
+    )
+}
+
+/// HMAC-SHA256.
+fn hmac(key: &[u8], msg: &[u8]) -> [u8; 32] {
+    let mut block = [0u8; 64];
+    if key.len() > block.len() {
+        block[..32].copy_from_slice(&Sha256::digest(key));
+    } else {
+        block[..key.len()].copy_from_slice(key);
+    }
+    let inner = Sha256::new()
+        .chain_update(block.map(|b| b ^ 0x36))
+        .chain_update(msg)
+        .finalize();
+    Sha256::new()
+        .chain_update(block.map(|b| b ^ 0x5c))
+        .chain_update(inner)
+        .finalize()
+        .into()
+}
+
+fn hex(bytes: &[u8]) -> String {
+    bytes.iter().map(|b| format!("{:02x}", b)).collect()
+}
+
+/// Percent-encodes `s` as SigV4 wants: all but unreserved characters, and
+/// '/' too unless `keep_slash`.
+fn uri_encode(s: &str, keep_slash: bool) -> String {
+    let mut out = String::with_capacity(s.len());
+    for b in s.bytes() {
+        match b {
+            b'A'..=b'Z' | b'a'..=b'z' | b'0'..=b'9' | b'-' | b'_' | b'.' | b'~' => out.push(b as char),
+            b'/' if keep_slash => out.push('/'),
+            _ => {
+                let _ = write!(out, "%{:02X}", b);
+            }
+        }
+    }
+    out
+}
+
+/// `t` as SigV4 writes it, 20261016T120000Z, and its date, 20261016.
+fn amz_date(t: SystemTime) -> (String, String) {
+    let secs = t.duration_since(UNIX_EPOCH).unwrap_or_default().as_secs();
+    let (days, secs) = ((secs / 86_400) as i64, secs % 86_400);
+    // Howard Hinnant's civil_from_days.
+    let z = days + 719_468;
+    let era = z.div_euclid(146_097);
+    let doe = z.rem_euclid(146_097);
+    let yoe = (doe - doe / 1460 + doe / 36_524 - doe / 146_096) / 365;
+    let doy = doe - (365 * yoe + yoe / 4 - yoe / 100);
+    let mp = (5 * doy + 2) / 153;
+    let day = doy - (153 * mp + 2) / 5 + 1;
+    let month = if mp < 10 { mp + 3 } else { mp - 9 };
+    let year = yoe + era * 400 + i64::from(month <= 2);
+    let date = format!("{:04}{:02}{:02}", year, month, day);
+    let date_time = format!("{}T{:02}{:02}{:02}Z", date, secs / 3600, secs / 60 % 60, secs % 60);
+    (date_time, date)
+}
+
+/// The text of every `<tag>` element in an S3 XML response.
+fn xml_values(xml: &str, tag: &str) -> Vec<String> {
+    let (open, close) = (format!("<{}>", tag), format!("</{}>", tag));
+    let mut out = Vec::new();
+    let mut rest = xml;
+    while let Some(start) = rest.find(&open) {
+        rest = &rest[start + open.len()..];
+        let Some(end) = rest.find(&close) else {
+            break;
+        };
+        out.push(
+            rest[..end]
+                .replace("&lt;", "<")
+                .replace("&gt;", ">")
+                .replace("&quot;", "\"")
+                .replace("&apos;", "'")
+                .replace("&amp;", "&"),
+        );
+        rest = &rest[end + close.len()..];
+    }
+    out
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn parse_refuses_what_it_cant_send() {
+        for url in ["http://h/a b", "http://h/a\r\nX-Injected: 1", "http://h/caf\u{e9}", "http://user:pass@h/x"] {
+            assert!(Endpoint::parse(url).is_err(), "{:?} parsed", url);
+        }
+        let (e, target) = Endpoint::parse("https://h:8443/x?y=1#frag").unwrap();
+        assert!(e.tls && e.host == "h" && e.port == 8443 && !e.refuse_link_local);
+        assert_eq!(target, "/x?y=1");
+    }
+
+    #[test]
+    fn parse_head_reads_the_status_first() {
+        let head = parse_head(b"HTTP/1.1 404 Not Found\r\ntransfer-encoding: chunked").unwrap();
+        assert_eq!(head.status, 404);
+        assert_eq!(head.decode(b"3\r\nabc\r\n0\r\n\r\n").unwrap(), b"abc");
+        assert!(parse_head(b"SSH-2.0-OpenSSH 200").is_err());
+        assert!(parse_head(b"HTTP/1.1 200 OK\r\ncontent-length: lots").is_err());
+    }
+
+    #[test]
+    fn metadata_addresses_are_link_local() {
+        for (ip, want) in [
+            ("169.254.169.254", true),
+            ("::ffff:169.254.169.254", true),
+            ("fe80::1", true),
+            ("fd00:ec2::254", true),
+            ("10.0.0.1", false),
+            ("2001:db8::1", false),
+        ] {
+            assert_eq!(link_local(ip.parse().unwrap()), want, "{}", ip);
+        }
+    }
+}
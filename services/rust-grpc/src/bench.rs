@@ -44,3 +44,107 @@ impl Stats {
         }
     }
 }
+
+/// Sub-buckets each power of two is split into, as a power of two: 16, so
+/// a bucket's bound is within 1/16 (6.25%) of every latency it counts.
+const SUB_BITS: u32 = 4;
+const SUB_BUCKETS: u64 = 1 << SUB_BITS;
+
+/// Buckets needed to cover every u64: the exact ones below SUB_BUCKETS,
+/// then SUB_BUCKETS per power of two above.
+const BUCKETS: usize = (SUB_BUCKETS + (64 - SUB_BITS as u64) * SUB_BUCKETS) as usize;
+
+/// An HDR-style latency histogram: log-linear buckets with a fixed relative
+/// error, so it holds any number of samples in constant space and its
+/// percentiles stay accurate from nanoseconds to minutes.
+pub struct Histogram {
+    counts: Vec<u64>,
+    n: u64,
+    sum: u128,
+    min: u64,
+    max: u64,
+}
+
+fn bucket(v: u64) -> usize {
+    if v < SUB_BUCKETS {
+        return v as usize;
+    }
+    let exp = 63 - v.leading_zeros(); // >= SUB_BITS
+    let shift = exp - SUB_BITS;
+    let sub = (v >> shift) - SUB_BUCKETS;
+    (SUB_BUCKETS + (shift as u64) * SUB_BUCKETS + sub) as usize
+}
+
+/// The largest value that lands in bucket `i`.
+fn upper(i: usize) -> u64 {
+    let i = i as u64;
+    if i < SUB_BUCKETS {
+        return i;
+    }
+    let shift = (i - SUB_BUCKETS) / SUB_BUCKETS;
+    let sub = (i - SUB_BUCKETS) % SUB_BUCKETS;
+    ((SUB_BUCKETS + sub + 1) << shift).wrapping_sub(1)
+}
+
+impl Histogram {
+    pub fn new() -> Self {
+        Histogram {
+            counts: vec![0; BUCKETS],
+            n: 0,
+            sum: 0,
+            min: u64::MAX,
+            max: 0,
+        }
+    }
+
+    pub fn record(&mut self, ns: i64) {
+        let v = ns.max(0) as u64;
+        self.counts[bucket(v)] += 1;
+        self.n += 1;
+        self.sum += v as u128;
+        self.min = self.min.min(v);
+        self.max = self.max.max(v);
+    }
+
+    pub fn len(&self) -> u64 {
+        self.n
+    }
+
+    pub fn min(&self) -> i64 {
+        if self.n == 0 { 0 } else { self.min as i64 }
+    }
+
+    pub fn max(&self) -> i64 {
+        self.max as i64
+    }
+
+    pub fn mean(&self) -> i64 {
+        if self.n == 0 { 0 } else { (self.sum / self.n as u128) as i64 }
+    }
+
+    /// The latency at or below which a `q` fraction of samples fall, as the
+    /// bound of the bucket holding it, but never above the slowest sample.
+    pub fn quantile(&self, q: f64) -> i64 {
+        if self.n == 0 {
+            return 0;
+        }
+        let want = ((q * self.n as f64).ceil() as u64).clamp(1, self.n);
+        let mut cum = 0;
+        for (i, &c) in self.counts.iter().enumerate() {
+            cum += c;
+            if cum >= want {
+                return upper(i).min(self.max) as i64;
+            }
+        }
+        self.max as i64
+    }
+
+    /// The non-empty buckets in order, as (upper bound, count).
+    pub fn buckets(&self) -> impl Iterator<Item = (i64, u64)> + '_ {
+        self.counts
+            .iter()
+            .enumerate()
+            .filter(|(_, &c)| c > 0)
+            .map(|(i, &c)| (upper(i).min(i64::MAX as u64) as i64, c))
+    }
+}
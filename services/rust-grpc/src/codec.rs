@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+//! How the document store compresses values.
+//!
+//! Each namespace, the part of a key before its first ':', can have a codec
+//! of its own (--ns-codec); every other key gets the default (--doc-codec).
+//! A value too small to be worth it, or that doesn't come out smaller, is
+//! kept as it is, so a key's stored codec may be none whatever its
+//! namespace's is.
+
+use std::borrow::Cow;
+use std::collections::HashMap;
+
+/// zstd level for values: the fastest levels compress nearly as well on
+/// small documents.
+const ZSTD_LEVEL: i32 = 1;
+
+/// Values shorter than this are stored as they are.
+const MIN_COMPRESS_BYTES: usize = 64;
+
+#[derive(Clone, Copy, Debug, PartialEq, Eq)]
+pub enum Codec {
+    None,
+    Zstd,
+    Lz4,
+}
+
+impl Codec {
+    pub fn parse(s: &str) -> Option<Codec> {
+        match s {
+            "none" => Some(Codec::None),
+            "zstd" => Some(Codec::Zstd),
+            "lz4" => Some(Codec::Lz4),
+            _ => None,
+        }
+    }
+
+    pub fn as_str(self) -> &'static str {
+        match self {
+            Codec::None => "none",
+            Codec::Zstd => "zstd",
+            Codec::Lz4 => "lz4",
+        }
+    }
+
+    /// Compresses `raw`, or returns None if it should be stored as it is.
+    pub fn compress(self, raw: &[u8]) -> Option<Vec<u8>> {
+        if raw.len() < MIN_COMPRESS_BYTES {
+            return None;
+        }
+        let packed = match self {
+            Codec::None => return None,
+            Codec::Zstd => zstd::bulk::compress(raw, ZSTD_LEVEL).ok()?,
+            Codec::Lz4 => lz4_flex::block::compress(raw),
+        };
+        (packed.len() < raw.len()).then_some(packed)
+    }
+
+    /// Undoes `compress` on a value that was `raw_len` bytes.
+    pub fn decompress(self, data: &[u8], raw_len: usize) -> Result<Cow<'_, [u8]>, String> {
+        let raw = match self {
+            Codec::None => return Ok(Cow::Borrowed(data)),
+            Codec::Zstd => zstd::bulk::decompress(data, raw_len).map_err(|e| format!("zstd: {}", e))?,
+            Codec::Lz4 => lz4_flex::block::decompress(data, raw_len).map_err(|e| format!("lz4: {}", e))?,
+        };
+        if raw.len() != raw_len {
+            return Err(format!("{}: got {} bytes, want {}", self.as_str(), raw.len(), raw_len));
+        }
+        Ok(Cow::Owned(raw))
+    }
+}
+
+/// The namespace a key is in: the part before its first ':', or "" for a
+/// key without one.
+pub fn namespace(key: &str) -> &str {
+    key.split_once(':').map_or("", |(ns, _)| ns)
+}
+
+/// Which codec each namespace uses.
+#[derive(Clone, Debug)]
+pub struct Codecs {
+    default: Codec,
+    by_namespace: HashMap<String, Codec>,
+}
+
+impl Default for Codecs {
+    fn default() -> Self {
+        Codecs {
+            default: Codec::Zstd,
+            by_namespace: HashMap::new(),
+        }
+    }
+}
+
+impl Codecs {
+    /// `default` for every key but those in `namespaces`, each given as
+    /// `name=codec`.
+    pub fn parse(default: &str, namespaces: &[String]) -> Result<Codecs, String> {
+        let codec = |s: &str| Codec::parse(s).ok_or_else(|| format!("codec {:?}: want zstd, lz4 or none", s));
+        let mut codecs = Codecs {
+            default: codec(default)?,
+            by_namespace: HashMap::new(),
+        };
+        for ns in namespaces {
+            let (name, c) = ns
+                .split_once('=')
+                .ok_or_else(|| format!("{:?}: want namespace=codec", ns))?;
+            if name.is_empty() || name.contains(':') {
+                return Err(format!("{:?}: namespace must be non-empty and without ':'", ns));
+            }
+            codecs.by_namespace.insert(name.to_string(), codec(c)?);
+        }
+        Ok(codecs)
+    }
+
+    /// The namespace `key` is counted under in stats: its own if it has a
+    /// codec of its own, else "" with every other key.
+    pub fn group<'a>(&self, key: &'a str) -> &'a str {
+        let ns = namespace(key);
+        if self.by_namespace.contains_key(ns) {
+            ns
+        } else {
+            ""
+        }
+    }
+
+    /// The codec for `key`'s namespace.
+    pub fn for_key(&self, key: &str) -> Codec {
+        self.by_namespace.get(namespace(key)).copied().unwrap_or(self.default)
+    }
+
+    /// The codec for a stats group, as named by `group`.
+    pub fn for_group(&self, group: &str) -> Codec {
+        self.by_namespace.get(group).copied().unwrap_or(self.default)
+    }
+
+    /// Every namespace with a codec of its own.
+    pub fn namespaces(&self) -> impl Iterator<Item = &str> {
+        self.by_namespace.keys().map(String::as_str)
+    }
+}
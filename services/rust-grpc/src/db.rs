@@ -1,20 +1,162 @@
 // SPDX-License-Identifier: AGPL-3.0-or-later
 
-use std::collections::HashMap;
-use std::sync::RwLock;
-use std::time::{SystemTime, UNIX_EPOCH};
+use std::borrow::Cow;
+use std::cmp::Reverse;
+use std::collections::{BTreeMap, BinaryHeap, HashMap};
+use std::io::Read;
+use std::ops::Bound;
+use std::path::{Path, PathBuf};
+use std::sync::atomic::{AtomicU64, Ordering};
+use std::sync::{Arc, Mutex, RwLock};
+use std::time::{Duration, Instant, SystemTime, UNIX_EPOCH};
+
+use tokio::sync::broadcast;
+use tracing::{debug, error};
+
+use crate::codec::{Codec, Codecs};
+use crate::wal::{Wal, WalOp};
+
+/// Events a watcher can fall behind by before it is cut off.
+const WATCH_CAPACITY: usize = 1024;
+
+/// Leads every uncompressed snapshot; the digit is the layout version.
+const SNAPSHOT_MAGIC: &[u8; 4] = b"HRM2";
+
+/// Snapshots from before buckets, holding only the default bucket's keys.
+const SNAPSHOT_MAGIC_V1: &[u8; 4] = b"HRM1";
+
+/// zstd level for snapshots: fast, and still several times smaller.
+const SNAPSHOT_LEVEL: i32 = 3;
+
+/// Largest snapshot restore will decompress, against zstd bombs.
+pub const SNAPSHOT_MAX_BYTES: u64 = 1 << 30;
+
+/// The bucket keys are in unless a call names another. It always exists.
+pub const DEFAULT_BUCKET: &str = "default";
+
+/// Longest bucket name, in bytes.
+pub const MAX_BUCKET_LEN: usize = 64;
 
 /// In-memory document + relational database for hermit.
-/// Thread-safe via RwLock. No persistence -- data lives for server lifetime.
+/// Thread-safe via RwLock. Persistence is by snapshot (see `snapshot`,
+/// `restore` and `run_snapshotter`) and, for the relational store, by an
+/// optional write-ahead log (see `open_wal`). Document store values are
+/// kept compressed, with the codec their namespace is given (see codec.rs).
+///
+/// The document store is split into buckets, each its own keyspace, so
+/// different clients can use the same keys without meeting.
 pub struct Database {
-    docs: RwLock<HashMap<String, Vec<u8>>>,
+    buckets: RwLock<BTreeMap<String, Bucket>>,
+    codecs: Codecs,
     rows: RwLock<RelStore>,
+    events: broadcast::Sender<KvEvent>,
+    seq: AtomicU64, // oplog position: document store changes so far
+    gc: Mutex<GcStats>,
+}
+
+/// What the TTL expirer has done.
+#[derive(Clone, Copy, Default)]
+pub struct GcStats {
+    pub sweeps: u64,
+    pub expired: u64,
+    pub last_sweep_us: u64,
+}
+
+/// How big a bucket is.
+pub struct BucketStats {
+    pub name: String,
+    pub created: SystemTime,
+    pub keys: u64,
+    pub raw_bytes: u64,
+    pub stored_bytes: u64,
+}
+
+/// One of the largest values; see `largest_values`.
+pub struct LargeValue {
+    pub bucket: String,
+    pub key: String,
+    pub raw_bytes: u64,
+    pub stored_bytes: u64,
+}
+
+/// How well one namespace's values compress; see `Codecs::group`.
+pub struct NamespaceStats {
+    /// The namespace, or "" for every key outside the ones given codecs.
+    pub name: String,
+    pub codec: Codec,
+    pub keys: u64,
+    pub raw_bytes: u64,
+    pub stored_bytes: u64,
+}
+
+/// A change to a document store key, as sent to watchers.
+#[derive(Clone)]
+pub struct KvEvent {
+    pub bucket: String,
+    pub key: String,
+    pub change: KvChange,
+    /// The oplog position after the change; see `oplog_seq`.
+    pub seq: u64,
+}
+
+#[derive(Clone)]
+pub enum KvChange {
+    /// The key's new value and version, and the TTL it was given.
+    Set {
+        value: Vec<u8>,
+        version: u64,
+        ttl: Option<Duration>,
+    },
+    Delete,
+    Expire,
+    /// The bucket was created; the key is empty. Watchers of keys skip it.
+    CreateBucket,
+}
+
+struct Bucket {
+    docs: BTreeMap<String, Doc>, // ordered for prefix scans
+    created: SystemTime,
+}
+
+impl Bucket {
+    fn new(created: SystemTime) -> Self {
+        Bucket {
+            docs: BTreeMap::new(),
+            created,
+        }
+    }
+}
+
+struct Doc {
+    value: Vec<u8>, // compressed with codec
+    codec: Codec,
+    raw_len: usize,
+    version: u64, // 1 when created, +1 per write
+    expires_at: Option<Instant>,
+}
+
+impl Doc {
+    fn live(&self, now: Instant) -> bool {
+        self.expires_at.map_or(true, |t| t > now)
+    }
+
+    /// The value as it was written.
+    fn raw(&self) -> Result<Cow<'_, [u8]>, String> {
+        self.codec.decompress(&self.value, self.raw_len)
+    }
+}
+
+/// A value about to be stored, compressed outside the docs lock.
+struct Packed {
+    raw: Vec<u8>,
+    compressed: Option<(Codec, Vec<u8>)>,
 }
 
 struct RelStore {
     committed: Vec<Row>,
     pending: Vec<Row>,
     next_id: u64,
+    wal: Option<Wal>, // every row is logged here before it is stored
 }
 
 #[derive(Clone)]
@@ -25,51 +167,487 @@ pub struct Row {
     pub created_at_unix: i64,
 }
 
+/// A Txn precondition on one key. With neither field set it always holds.
+pub struct TxnGuard {
+    pub key: String,
+    pub version: Option<u64>, // 0 = not set
+    pub value: Option<Vec<u8>>,
+}
+
+pub struct TxnWrite {
+    pub key: String,
+    pub value: Vec<u8>,
+    pub delete: bool,
+    pub ttl: Option<Duration>,
+}
+
+pub enum TxnOutcome {
+    /// Index of the first guard that failed; nothing was written.
+    Failed(usize),
+    /// Each write's version after the txn (0 for deletes).
+    Applied(Vec<u64>),
+}
+
+pub enum SqlOrder {
+    Id, // insertion order
+    Key,
+    Value,
+    Created,
+}
+
+/// A relational store scan. Filters match substrings; empty matches all.
+pub struct SqlQuery<'a> {
+    pub key_filter: &'a str,
+    pub value_filter: &'a str,
+    pub order_by: SqlOrder,
+    pub desc: bool,
+    pub offset: usize,
+    pub limit: u32, // 0 = 100
+    pub count_only: bool,
+}
+
 pub struct QueryResult {
     pub rows: Vec<Row>,
+    pub matched: u64, // before offset and limit
     pub total_committed: u64,
     pub pending_writes: u64,
 }
 
 impl Database {
-    pub fn new() -> Self {
+    pub fn new(codecs: Codecs) -> Self {
+        let (events, _) = broadcast::channel(WATCH_CAPACITY);
+        let mut buckets = BTreeMap::new();
+        buckets.insert(DEFAULT_BUCKET.to_string(), Bucket::new(SystemTime::now()));
         Database {
-            docs: RwLock::new(HashMap::new()),
+            buckets: RwLock::new(buckets),
+            codecs,
             rows: RwLock::new(RelStore {
                 committed: Vec::new(),
                 pending: Vec::new(),
                 next_id: 1,
+                wal: None,
             }),
+            events,
+            seq: AtomicU64::new(0),
+            gc: Mutex::new(GcStats::default()),
+        }
+    }
+
+    /// Subscribes to every document store change from now on.
+    pub fn watch(&self) -> broadcast::Receiver<KvEvent> {
+        self.events.subscribe()
+    }
+
+    /// How many watchers there are.
+    pub fn watchers(&self) -> u64 {
+        self.events.receiver_count() as u64
+    }
+
+    /// How many document store changes there have been: the position in
+    /// the oplog followers replicate (see replica.rs). It starts at 0 with
+    /// the process, not with the data.
+    pub fn oplog_seq(&self) -> u64 {
+        self.seq.load(Ordering::Relaxed)
+    }
+
+    /// Numbers a change and tells watchers about it. Callers hold the
+    /// buckets write lock, so changes are numbered, and watchers see them,
+    /// in the order they were made. `change` is only built if someone is
+    /// watching.
+    fn publish(&self, bucket: &str, key: &str, change: impl FnOnce() -> KvChange) {
+        let seq = self.seq.fetch_add(1, Ordering::Relaxed) + 1;
+        if self.events.receiver_count() > 0 {
+            // Err only means the last watcher just left.
+            let _ = self.events.send(KvEvent {
+                bucket: bucket.to_string(),
+                key: key.to_string(),
+                change: change(),
+                seq,
+            });
+        }
+    }
+
+    // --- Buckets ---
+
+    /// Creates an empty bucket, reporting whether it is new.
+    pub fn create_bucket(&self, name: &str) -> Result<bool, String> {
+        check_bucket_name(name)?;
+        let mut buckets = self.buckets.write().map_err(|e| e.to_string())?;
+        if buckets.contains_key(name) {
+            return Ok(false);
         }
+        buckets.insert(name.to_string(), Bucket::new(SystemTime::now()));
+        self.publish(name, "", || KvChange::CreateBucket);
+        Ok(true)
+    }
+
+    pub fn has_bucket(&self, name: &str) -> bool {
+        self.buckets.read().is_ok_and(|b| b.contains_key(name))
+    }
+
+    /// Every bucket's size, in name order.
+    pub fn bucket_stats(&self) -> Result<Vec<BucketStats>, String> {
+        let buckets = self.buckets.read().map_err(|e| e.to_string())?;
+        let now = Instant::now();
+        Ok(buckets
+            .iter()
+            .map(|(name, b)| {
+                let mut stats = BucketStats {
+                    name: name.clone(),
+                    created: b.created,
+                    keys: 0,
+                    raw_bytes: 0,
+                    stored_bytes: 0,
+                };
+                for d in b.docs.values().filter(|d| d.live(now)) {
+                    stats.keys += 1;
+                    stats.raw_bytes += d.raw_len as u64;
+                    stats.stored_bytes += d.value.len() as u64;
+                }
+                stats
+            })
+            .collect())
+    }
+
+    /// The `limit` largest live values in any bucket, by their size as
+    /// written, largest first.
+    pub fn largest_values(&self, limit: usize) -> Result<Vec<LargeValue>, String> {
+        let buckets = self.buckets.read().map_err(|e| e.to_string())?;
+        let now = Instant::now();
+        // A min-heap of the largest so far, so the smallest is the one to go.
+        let mut top = BinaryHeap::with_capacity(limit + 1);
+        for (name, b) in buckets.iter() {
+            for (key, d) in b.docs.iter().filter(|(_, d)| d.live(now)) {
+                top.push(Reverse((d.raw_len, d.value.len(), name.as_str(), key.as_str())));
+                if top.len() > limit {
+                    top.pop();
+                }
+            }
+        }
+        Ok(top
+            .into_sorted_vec()
+            .into_iter()
+            .map(|Reverse((raw, stored, bucket, key))| LargeValue {
+                bucket: bucket.to_string(),
+                key: key.to_string(),
+                raw_bytes: raw as u64,
+                stored_bytes: stored as u64,
+            })
+            .collect())
     }
 
     // --- Document store ---
 
-    pub fn kv_set(&self, key: String, value: Vec<u8>) -> Result<(), String> {
-        let mut docs = self.docs.write().map_err(|e| e.to_string())?;
-        docs.insert(key, value);
+    /// Stores a value, replacing any TTL the key had, and returns the key's
+    /// new version. With `ttl` the key expires that long from now.
+    pub fn kv_set(&self, bucket: &str, key: String, value: Vec<u8>, ttl: Option<Duration>) -> Result<u64, String> {
+        let packed = self.pack(&key, value);
+        let mut buckets = self.buckets.write().map_err(|e| e.to_string())?;
+        let docs = docs_mut(&mut buckets, bucket)?;
+        Ok(self.put(bucket, docs, key, packed, ttl, Instant::now()))
+    }
+
+    /// Returns the value and its version.
+    pub fn kv_get(&self, bucket: &str, key: &str) -> Result<Option<(Vec<u8>, u64)>, String> {
+        let found = {
+            let buckets = self.buckets.read().map_err(|e| e.to_string())?;
+            let now = Instant::now();
+            docs(&buckets, bucket)?
+                .get(key)
+                .filter(|d| d.live(now))
+                .map(|d| (d.value.clone(), d.codec, d.raw_len, d.version))
+        };
+        // Decompress with the lock released.
+        let Some((value, codec, raw_len, version)) = found else {
+            return Ok(None);
+        };
+        let value = match codec {
+            Codec::None => value,
+            c => c.decompress(&value, raw_len)?.into_owned(),
+        };
+        Ok(Some((value, version)))
+    }
+
+    /// Lists up to `limit` keys starting with `prefix`, in order, after
+    /// `cursor` if it is set. The second value is the cursor for the next
+    /// page, empty on the last one.
+    pub fn kv_list(
+        &self,
+        bucket: &str,
+        prefix: &str,
+        cursor: &str,
+        limit: usize,
+    ) -> Result<(Vec<String>, String), String> {
+        let buckets = self.buckets.read().map_err(|e| e.to_string())?;
+        let docs = docs(&buckets, bucket)?;
+        let now = Instant::now();
+        let start = if cursor.is_empty() || cursor < prefix {
+            Bound::Included(prefix)
+        } else {
+            Bound::Excluded(cursor)
+        };
+        let mut keys: Vec<String> = docs
+            .range::<str, _>((start, Bound::Unbounded))
+            .take_while(|(k, _)| k.starts_with(prefix))
+            .filter(|(_, d)| d.live(now))
+            .map(|(k, _)| k.clone())
+            .take(limit + 1)
+            .collect();
+        let mut next = String::new();
+        if keys.len() > limit {
+            keys.truncate(limit);
+            next = keys.last().cloned().unwrap_or_default();
+        }
+        Ok((keys, next))
+    }
+
+    /// Removes a key, reporting whether it was set.
+    pub fn kv_delete(&self, bucket: &str, key: &str) -> Result<bool, String> {
+        let mut buckets = self.buckets.write().map_err(|e| e.to_string())?;
+        let docs = docs_mut(&mut buckets, bucket)?;
+        Ok(self.remove(bucket, docs, key, Instant::now()))
+    }
+
+    /// Applies a change a primary made, keeping its version (see
+    /// replica.rs). A bucket the follower hasn't heard of is created.
+    pub fn apply(&self, bucket: &str, key: String, change: KvChange) -> Result<(), String> {
+        let set = match change {
+            KvChange::CreateBucket => return self.create_bucket(bucket).map(|_| ()),
+            KvChange::Set { value, version, ttl } => Some((self.pack(&key, value), version, ttl)),
+            KvChange::Delete | KvChange::Expire => None,
+        };
+        let mut buckets = self.buckets.write().map_err(|e| e.to_string())?;
+        if !buckets.contains_key(bucket) {
+            check_bucket_name(bucket)?;
+            buckets.insert(bucket.to_string(), Bucket::new(SystemTime::now()));
+            self.publish(bucket, "", || KvChange::CreateBucket);
+        }
+        let docs = docs_mut(&mut buckets, bucket)?;
+        let now = Instant::now();
+        match set {
+            Some((packed, version, ttl)) => self.store(bucket, docs, key, packed, version, ttl, now),
+            None => {
+                self.remove(bucket, docs, &key, now);
+            }
+        }
         Ok(())
     }
 
-    pub fn kv_get(&self, key: &str) -> Result<Option<Vec<u8>>, String> {
-        let docs = self.docs.read().map_err(|e| e.to_string())?;
-        Ok(docs.get(key).cloned())
+    /// Checks every guard and, if all hold, applies every write in order,
+    /// all under one lock.
+    pub fn txn(&self, bucket: &str, guards: &[TxnGuard], writes: Vec<TxnWrite>) -> Result<TxnOutcome, String> {
+        let writes: Vec<_> = writes
+            .into_iter()
+            .map(|w| {
+                let packed = (!w.delete).then(|| self.pack(&w.key, w.value));
+                (w.key, packed, w.ttl)
+            })
+            .collect();
+        let mut buckets = self.buckets.write().map_err(|e| e.to_string())?;
+        let docs = docs_mut(&mut buckets, bucket)?;
+        let now = Instant::now();
+        for (i, g) in guards.iter().enumerate() {
+            let doc = docs.get(&g.key).filter(|d| d.live(now));
+            let version_ok = g.version.map_or(true, |v| doc.map_or(0, |d| d.version) == v);
+            let value_ok = g.value.as_ref().map_or(true, |v| {
+                doc.is_some_and(|d| d.raw_len == v.len() && d.raw().is_ok_and(|r| *r == v[..]))
+            });
+            if !version_ok || !value_ok {
+                return Ok(TxnOutcome::Failed(i));
+            }
+        }
+        let versions = writes
+            .into_iter()
+            .map(|(key, packed, ttl)| match packed {
+                Some(packed) => self.put(bucket, docs, key, packed, ttl, now),
+                None => {
+                    self.remove(bucket, docs, &key, now);
+                    0
+                }
+            })
+            .collect();
+        Ok(TxnOutcome::Applied(versions))
+    }
+
+    /// Compresses a value with its namespace's codec, ahead of taking the
+    /// buckets lock.
+    fn pack(&self, key: &str, raw: Vec<u8>) -> Packed {
+        let codec = self.codecs.for_key(key);
+        let compressed = codec.compress(&raw).map(|c| (codec, c));
+        Packed { raw, compressed }
     }
 
-    pub fn kv_list(&self) -> Result<Vec<String>, String> {
-        let docs = self.docs.read().map_err(|e| e.to_string())?;
-        let mut keys: Vec<String> = docs.keys().cloned().collect();
-        keys.sort();
-        Ok(keys)
+    /// Writes a key under the buckets lock, returning its new version.
+    fn put(
+        &self,
+        bucket: &str,
+        docs: &mut BTreeMap<String, Doc>,
+        key: String,
+        packed: Packed,
+        ttl: Option<Duration>,
+        now: Instant,
+    ) -> u64 {
+        let version = docs
+            .get(&key)
+            .filter(|d| d.live(now))
+            .map_or(1, |d| d.version + 1);
+        self.store(bucket, docs, key, packed, version, ttl, now);
+        version
     }
 
+    /// Stores a key at `version` under the buckets lock and tells watchers.
+    fn store(
+        &self,
+        bucket: &str,
+        docs: &mut BTreeMap<String, Doc>,
+        key: String,
+        packed: Packed,
+        version: u64,
+        ttl: Option<Duration>,
+        now: Instant,
+    ) {
+        let Packed { raw, compressed } = packed;
+        let raw_len = raw.len();
+        let (value, codec) = match compressed {
+            Some((codec, data)) => {
+                self.publish(bucket, &key, move || KvChange::Set {
+                    value: raw,
+                    version,
+                    ttl,
+                });
+                (data, codec)
+            }
+            None => {
+                self.publish(bucket, &key, || KvChange::Set {
+                    value: raw.clone(),
+                    version,
+                    ttl,
+                });
+                (raw, Codec::None)
+            }
+        };
+        let expires_at = ttl.map(|d| now + d);
+        docs.insert(
+            key,
+            Doc {
+                value,
+                codec,
+                raw_len,
+                version,
+                expires_at,
+            },
+        );
+    }
+
+    /// Deletes a key under the buckets lock, reporting whether it was set.
+    fn remove(&self, bucket: &str, docs: &mut BTreeMap<String, Doc>, key: &str, now: Instant) -> bool {
+        match docs.remove(key) {
+            Some(d) if d.live(now) => {
+                self.publish(bucket, key, || KvChange::Delete);
+                true
+            }
+            // Expired but not yet reaped: watchers haven't heard.
+            Some(_) => {
+                self.publish(bucket, key, || KvChange::Expire);
+                false
+            }
+            None => false,
+        }
+    }
+
+    pub fn kv_exists(&self, bucket: &str, key: &str) -> Result<bool, String> {
+        let buckets = self.buckets.read().map_err(|e| e.to_string())?;
+        let now = Instant::now();
+        Ok(docs(&buckets, bucket)?.get(key).is_some_and(|d| d.live(now)))
+    }
+
+    /// Returns None if the key is not set, Some(None) if it never expires,
+    /// and otherwise the time it has left.
+    pub fn kv_ttl(&self, bucket: &str, key: &str) -> Result<Option<Option<Duration>>, String> {
+        let buckets = self.buckets.read().map_err(|e| e.to_string())?;
+        let now = Instant::now();
+        Ok(docs(&buckets, bucket)?
+            .get(key)
+            .filter(|d| d.live(now))
+            .map(|d| d.expires_at.map(|t| t - now)))
+    }
+
+    /// Drops expired keys from every bucket, returning how many there were.
+    /// Reads already skip them; this frees their memory.
+    pub fn kv_expire(&self) -> Result<usize, String> {
+        let mut buckets = self.buckets.write().map_err(|e| e.to_string())?;
+        let now = Instant::now();
+        let mut n = 0;
+        for (bucket, b) in buckets.iter_mut() {
+            let expired: Vec<String> = b
+                .docs
+                .iter()
+                .filter(|(_, d)| !d.live(now))
+                .map(|(k, _)| k.clone())
+                .collect();
+            for key in &expired {
+                b.docs.remove(key);
+                self.publish(bucket, key, || KvChange::Expire);
+            }
+            n += expired.len();
+        }
+        if let Ok(mut gc) = self.gc.lock() {
+            gc.sweeps += 1;
+            gc.expired += n as u64;
+            gc.last_sweep_us = now.elapsed().as_micros() as u64;
+        }
+        Ok(n)
+    }
+
+    pub fn gc_stats(&self) -> GcStats {
+        self.gc.lock().map(|gc| *gc).unwrap_or_default()
+    }
+
+    /// Returns how many keys there are in every bucket and the bytes their
+    /// values take as stored.
     pub fn kv_stats(&self) -> Result<(u64, u64), String> {
-        let docs = self.docs.read().map_err(|e| e.to_string())?;
-        let count = docs.len() as u64;
-        let bytes: u64 = docs.values().map(|v| v.len() as u64).sum();
+        let buckets = self.buckets.read().map_err(|e| e.to_string())?;
+        let now = Instant::now();
+        let live = buckets.values().flat_map(|b| b.docs.values()).filter(|d| d.live(now));
+        let (count, bytes) = live.fold((0u64, 0u64), |(n, b), d| (n + 1, b + d.value.len() as u64));
         Ok((count, bytes))
     }
 
+    /// Compression by namespace, across buckets: one entry for each
+    /// namespace given a codec, in order, then one for every other key.
+    pub fn namespace_stats(&self) -> Result<Vec<NamespaceStats>, String> {
+        let mut groups: HashMap<&str, NamespaceStats> = self
+            .codecs
+            .namespaces()
+            .chain([""])
+            .map(|name| {
+                let stats = NamespaceStats {
+                    name: name.to_string(),
+                    codec: self.codecs.for_group(name),
+                    keys: 0,
+                    raw_bytes: 0,
+                    stored_bytes: 0,
+                };
+                (name, stats)
+            })
+            .collect();
+        {
+            let buckets = self.buckets.read().map_err(|e| e.to_string())?;
+            let now = Instant::now();
+            let live = buckets.values().flat_map(|b| b.docs.iter()).filter(|(_, d)| d.live(now));
+            for (key, d) in live {
+                if let Some(g) = groups.get_mut(self.codecs.group(key)) {
+                    g.keys += 1;
+                    g.raw_bytes += d.raw_len as u64;
+                    g.stored_bytes += d.value.len() as u64;
+                }
+            }
+        }
+        let mut out: Vec<_> = groups.into_values().collect();
+        out.sort_by(|a, b| (a.name.is_empty(), &a.name).cmp(&(b.name.is_empty(), &b.name)));
+        Ok(out)
+    }
+
     // --- Relational store ---
 
     pub fn sql_insert(&self, key: String, value: String) -> Result<bool, String> {
@@ -84,32 +662,424 @@ impl Database {
             value,
             created_at_unix: now,
         };
+        if let Some(wal) = store.wal.as_mut() {
+            wal.append(&WalOp::Insert(&row))?;
+        }
         store.next_id += 1;
         store.committed.push(row);
         Ok(true)
     }
 
-    pub fn sql_query(&self, key_filter: &str, limit: u32) -> Result<QueryResult, String> {
+    pub fn sql_query(&self, q: &SqlQuery) -> Result<QueryResult, String> {
         let store = self.rows.read().map_err(|e| e.to_string())?;
-        let limit = if limit == 0 { 100 } else { limit as usize };
+        let limit = if q.limit == 0 { 100 } else { q.limit as usize };
 
-        let rows: Vec<Row> = store
+        let mut rows: Vec<&Row> = store
             .committed
             .iter()
-            .filter(|r| key_filter.is_empty() || r.key.contains(key_filter))
-            .take(limit)
-            .cloned()
+            .filter(|r| r.key.contains(q.key_filter) && r.value.contains(q.value_filter))
             .collect();
+        let matched = rows.len() as u64;
+        if q.count_only {
+            rows.clear();
+        }
+        match q.order_by {
+            SqlOrder::Id => {} // committed is in id order
+            SqlOrder::Key => rows.sort_by(|a, b| a.key.cmp(&b.key)),
+            SqlOrder::Value => rows.sort_by(|a, b| a.value.cmp(&b.value)),
+            SqlOrder::Created => rows.sort_by_key(|r| r.created_at_unix),
+        }
+        if q.desc {
+            rows.reverse();
+        }
 
         Ok(QueryResult {
-            rows,
+            rows: rows.into_iter().skip(q.offset).take(limit).cloned().collect(),
+            matched,
             total_committed: store.committed.len() as u64,
             pending_writes: store.pending.len() as u64,
         })
     }
 
+    /// Removes every row with exactly `key`, returning how many there were.
+    pub fn sql_delete(&self, key: &str) -> Result<u64, String> {
+        let mut store = self.rows.write().map_err(|e| e.to_string())?;
+        let n = store.committed.iter().chain(&store.pending).filter(|r| r.key == key).count();
+        if n == 0 {
+            return Ok(0);
+        }
+        if let Some(wal) = store.wal.as_mut() {
+            wal.append(&WalOp::Delete(key))?;
+        }
+        store.committed.retain(|r| r.key != key);
+        store.pending.retain(|r| r.key != key);
+        Ok(n as u64)
+    }
+
+    /// Sets the value of every row with exactly `key`, returning how many
+    /// there were.
+    pub fn sql_update(&self, key: &str, value: &str) -> Result<u64, String> {
+        let mut store = self.rows.write().map_err(|e| e.to_string())?;
+        let n = store.committed.iter().chain(&store.pending).filter(|r| r.key == key).count();
+        if n == 0 {
+            return Ok(0);
+        }
+        if let Some(wal) = store.wal.as_mut() {
+            wal.append(&WalOp::Update(key, value))?;
+        }
+        let RelStore { committed, pending, .. } = &mut *store;
+        for r in committed.iter_mut().chain(pending.iter_mut()).filter(|r| r.key == key) {
+            r.value = value.to_string();
+        }
+        Ok(n as u64)
+    }
+
     pub fn rel_stats(&self) -> Result<(u64, u64), String> {
         let store = self.rows.read().map_err(|e| e.to_string())?;
         Ok((store.committed.len() as u64, store.pending.len() as u64))
     }
+
+    /// Logs relational writes to the WAL at `path` from now on, after
+    /// replaying it. A log that already existed holds the whole store, so
+    /// its rows replace any there (from a snapshot, say); a new one starts
+    /// with the rows there are. Returns the row count and how many bytes of
+    /// a torn final record were dropped.
+    pub fn open_wal(&self, path: &Path) -> Result<(u64, usize), String> {
+        let (mut wal, replay) = Wal::open(path)?;
+        let mut store = self.rows.write().map_err(|e| e.to_string())?;
+        if replay.existed {
+            let next_id = replay.rows.iter().map(|r| r.id + 1).max().unwrap_or(1);
+            store.next_id = store.next_id.max(next_id);
+            store.committed = replay.rows;
+            store.pending.clear();
+            if replay.stale {
+                // Drop the deleted rows and superseded values from disk.
+                wal.rewrite(store.committed.iter())?;
+            }
+        } else {
+            let RelStore { committed, pending, .. } = &*store;
+            wal.rewrite(committed.iter().chain(pending))?;
+        }
+        store.wal = Some(wal);
+        Ok((store.committed.len() as u64, replay.torn))
+    }
+
+    /// Flushes WAL appends to disk. The store stays unlocked while the
+    /// disk works.
+    pub fn wal_sync(&self) -> Result<(), String> {
+        let handle = {
+            let mut store = self.rows.write().map_err(|e| e.to_string())?;
+            match store.wal.as_mut() {
+                Some(wal) => wal.sync_handle()?,
+                None => None,
+            }
+        };
+        match handle {
+            Some(f) => f.sync_data().map_err(|e| format!("wal: {}", e)),
+            None => Ok(()),
+        }
+    }
+
+    // --- Snapshots ---
+
+    /// Encodes both stores, zstd-compressed. Keys keep their bucket, their
+    /// version and the TTL they have left; expired keys are left out.
+    pub fn snapshot(&self) -> Result<Vec<u8>, String> {
+        self.snapshot_at().map(|(data, _)| data)
+    }
+
+    /// A snapshot, and the oplog position it was taken at: it holds every
+    /// change up to there and none after.
+    pub fn snapshot_at(&self) -> Result<(Vec<u8>, u64), String> {
+        let mut raw = SNAPSHOT_MAGIC.to_vec();
+        let seq;
+        {
+            let buckets = self.buckets.read().map_err(|e| e.to_string())?;
+            seq = self.oplog_seq();
+            let now = Instant::now();
+            put_u64(&mut raw, buckets.len() as u64);
+            for (name, b) in buckets.iter() {
+                put_bytes(&mut raw, name.as_bytes());
+                put_u64(&mut raw, unix_ms(b.created));
+                let live: Vec<_> = b.docs.iter().filter(|(_, d)| d.live(now)).collect();
+                put_u64(&mut raw, live.len() as u64);
+                for (key, d) in live {
+                    put_bytes(&mut raw, key.as_bytes());
+                    put_bytes(&mut raw, &d.raw()?);
+                    put_u64(&mut raw, d.version);
+                    // 0 = no TTL; a live key has at least 1ms left.
+                    let ttl_ms = d.expires_at.map_or(0, |t| (t - now).as_millis().max(1) as u64);
+                    put_u64(&mut raw, ttl_ms);
+                }
+            }
+        }
+        {
+            let store = self.rows.read().map_err(|e| e.to_string())?;
+            put_u64(&mut raw, store.next_id);
+            let rows = store.committed.iter().chain(&store.pending);
+            put_u64(&mut raw, (store.committed.len() + store.pending.len()) as u64);
+            for r in rows {
+                put_u64(&mut raw, r.id);
+                put_bytes(&mut raw, r.key.as_bytes());
+                put_bytes(&mut raw, r.value.as_bytes());
+                put_u64(&mut raw, r.created_at_unix as u64);
+            }
+        }
+        let data = zstd::encode_all(&raw[..], SNAPSHOT_LEVEL).map_err(|e| e.to_string())?;
+        Ok((data, seq))
+    }
+
+    /// Replaces both stores with a snapshot's contents, returning how many
+    /// keys and rows it held. A snapshot that doesn't decode changes
+    /// nothing. Buckets it doesn't have are kept, emptied: buckets are never
+    /// removed. Watchers see every dropped key deleted, every new bucket
+    /// created and every restored key set.
+    pub fn restore(&self, data: &[u8]) -> Result<(u64, u64), String> {
+        let mut raw = Vec::new();
+        zstd::Decoder::new(data)
+            .map_err(|e| e.to_string())?
+            .take(SNAPSHOT_MAX_BYTES + 1)
+            .read_to_end(&mut raw)
+            .map_err(|e| format!("snapshot: {}", e))?;
+        if raw.len() as u64 > SNAPSHOT_MAX_BYTES {
+            return Err(format!("snapshot: larger than {} bytes", SNAPSHOT_MAX_BYTES));
+        }
+        let mut r = SnapshotReader(&raw);
+        let magic = r.take(SNAPSHOT_MAGIC.len())?;
+        let now = Instant::now();
+        let mut new_buckets = BTreeMap::new();
+        if magic == SNAPSHOT_MAGIC {
+            for _ in 0..r.u64()? {
+                let name = r.string()?;
+                check_bucket_name(&name).map_err(|e| format!("snapshot: {}", e))?;
+                let created = UNIX_EPOCH + Duration::from_millis(r.u64()?);
+                let bucket = Bucket {
+                    docs: self.read_docs(&mut r, now)?,
+                    created,
+                };
+                new_buckets.insert(name, bucket);
+            }
+        } else if magic == SNAPSHOT_MAGIC_V1 {
+            let bucket = Bucket {
+                docs: self.read_docs(&mut r, now)?,
+                created: SystemTime::now(),
+            };
+            new_buckets.insert(DEFAULT_BUCKET.to_string(), bucket);
+        } else {
+            return Err("snapshot: not a hermit snapshot".to_string());
+        }
+        let next_id = r.u64()?;
+        let mut committed = Vec::new();
+        for _ in 0..r.u64()? {
+            committed.push(Row {
+                id: r.u64()?,
+                key: r.string()?,
+                value: r.string()?,
+                created_at_unix: r.u64()? as i64,
+            });
+        }
+        if !r.0.is_empty() {
+            return Err("snapshot: trailing bytes".to_string());
+        }
+        let keys = new_buckets.values().map(|b| b.docs.len() as u64).sum();
+        let rows = committed.len() as u64;
+
+        let mut buckets = self.buckets.write().map_err(|e| e.to_string())?;
+        let mut store = self.rows.write().map_err(|e| e.to_string())?;
+        if let Some(wal) = store.wal.as_mut() {
+            // First, so a failure leaves both stores as they were.
+            wal.rewrite(committed.iter())?;
+        }
+        for (name, b) in buckets.iter() {
+            let kept = new_buckets.get(name).map(|nb| &nb.docs);
+            for (key, d) in &b.docs {
+                if d.live(now) && !kept.is_some_and(|k| k.contains_key(key)) {
+                    self.publish(name, key, || KvChange::Delete);
+                }
+            }
+        }
+        for (name, b) in &new_buckets {
+            if !buckets.contains_key(name) {
+                self.publish(name, "", || KvChange::CreateBucket);
+            }
+            for (key, d) in &b.docs {
+                self.publish(name, key, || KvChange::Set {
+                    value: d.raw().map(Cow::into_owned).unwrap_or_default(),
+                    version: d.version,
+                    ttl: d.expires_at.map(|t| t - now),
+                });
+            }
+        }
+        for (name, b) in std::mem::take(&mut *buckets) {
+            new_buckets.entry(name).or_insert(Bucket::new(b.created));
+        }
+        new_buckets
+            .entry(DEFAULT_BUCKET.to_string())
+            .or_insert_with(|| Bucket::new(SystemTime::now()));
+        *buckets = new_buckets;
+        store.committed = committed;
+        store.pending.clear();
+        store.next_id = next_id;
+        Ok((keys, rows))
+    }
+
+    /// Reads one bucket's keys from a snapshot, compressing their values.
+    fn read_docs(&self, r: &mut SnapshotReader, now: Instant) -> Result<BTreeMap<String, Doc>, String> {
+        let mut docs = BTreeMap::new();
+        for _ in 0..r.u64()? {
+            let key = r.string()?;
+            let value = r.bytes()?;
+            let version = r.u64()?;
+            let ttl_ms = r.u64()?;
+            let expires_at = (ttl_ms > 0).then(|| now + Duration::from_millis(ttl_ms));
+            let Packed { raw, compressed } = self.pack(&key, value);
+            let raw_len = raw.len();
+            let (value, codec) = compressed.map_or((raw, Codec::None), |(codec, data)| (data, codec));
+            let doc = Doc {
+                value,
+                codec,
+                raw_len,
+                version,
+                expires_at,
+            };
+            docs.insert(key, doc);
+        }
+        Ok(docs)
+    }
+}
+
+fn unix_ms(t: SystemTime) -> u64 {
+    t.duration_since(UNIX_EPOCH).unwrap_or_default().as_millis() as u64
+}
+
+/// Checks a name CreateBucket was given: lowercase letters, digits, '-',
+/// '_' and '.', up to MAX_BUCKET_LEN bytes.
+pub fn check_bucket_name(name: &str) -> Result<(), String> {
+    let ok = |c: char| c.is_ascii_lowercase() || c.is_ascii_digit() || "-_.".contains(c);
+    if name.is_empty() || name.len() > MAX_BUCKET_LEN || !name.chars().all(ok) {
+        return Err(format!(
+            "bucket {:?}: want 1 to {} lowercase letters, digits, '-', '_' or '.'",
+            name, MAX_BUCKET_LEN
+        ));
+    }
+    Ok(())
+}
+
+/// The keys in a bucket.
+fn docs<'a>(buckets: &'a BTreeMap<String, Bucket>, name: &str) -> Result<&'a BTreeMap<String, Doc>, String> {
+    buckets
+        .get(name)
+        .map(|b| &b.docs)
+        .ok_or_else(|| format!("no bucket {:?}", name))
+}
+
+fn docs_mut<'a>(
+    buckets: &'a mut BTreeMap<String, Bucket>,
+    name: &str,
+) -> Result<&'a mut BTreeMap<String, Doc>, String> {
+    buckets
+        .get_mut(name)
+        .map(|b| &mut b.docs)
+        .ok_or_else(|| format!("no bucket {:?}", name))
+}
+
+fn put_u64(buf: &mut Vec<u8>, n: u64) {
+    buf.extend_from_slice(&n.to_le_bytes());
+}
+
+fn put_bytes(buf: &mut Vec<u8>, b: &[u8]) {
+    put_u64(buf, b.len() as u64);
+    buf.extend_from_slice(b);
+}
+
+/// Reads back what `put_u64` and `put_bytes` wrote.
+struct SnapshotReader<'a>(&'a [u8]);
+
+impl<'a> SnapshotReader<'a> {
+    fn take(&mut self, n: usize) -> Result<&'a [u8], String> {
+        if self.0.len() < n {
+            return Err("snapshot: truncated".to_string());
+        }
+        let (head, rest) = self.0.split_at(n);
+        self.0 = rest;
+        Ok(head)
+    }
+
+    fn u64(&mut self) -> Result<u64, String> {
+        let b = self.take(8)?;
+        Ok(u64::from_le_bytes(b.try_into().unwrap()))
+    }
+
+    fn bytes(&mut self) -> Result<Vec<u8>, String> {
+        let n = self.u64()?;
+        let n = usize::try_from(n).map_err(|e| e.to_string())?;
+        Ok(self.take(n)?.to_vec())
+    }
+
+    fn string(&mut self) -> Result<String, String> {
+        String::from_utf8(self.bytes()?).map_err(|e| format!("snapshot: {}", e))
+    }
+}
+
+/// Reaps expired keys every `every` until the server exits.
+pub async fn run_expirer(db: Arc<Database>, every: Duration) {
+    let mut tick = tokio::time::interval(every);
+    loop {
+        tick.tick().await;
+        match db.kv_expire() {
+            Ok(0) => {}
+            Ok(n) => debug!("expired {} keys", n),
+            Err(e) => error!("expirer: {}", e),
+        }
+    }
+}
+
+/// Writes a snapshot to `path`, via a temporary file so a crash mid-write
+/// leaves the previous one intact. Returns its size.
+pub fn save_snapshot(db: &Database, path: &Path) -> Result<usize, String> {
+    let data = db.snapshot()?;
+    let tmp = path.with_extension("tmp");
+    std::fs::write(&tmp, &data).map_err(|e| format!("{}: {}", tmp.display(), e))?;
+    std::fs::rename(&tmp, path).map_err(|e| format!("{}: {}", path.display(), e))?;
+    Ok(data.len())
+}
+
+/// Restores the snapshot at `path`, if there is one.
+pub fn load_snapshot(db: &Database, path: &Path) -> Result<Option<(u64, u64)>, String> {
+    match std::fs::read(path) {
+        Ok(data) => db.restore(&data).map(Some),
+        Err(e) if e.kind() == std::io::ErrorKind::NotFound => Ok(None),
+        Err(e) => Err(format!("{}: {}", path.display(), e)),
+    }
+}
+
+/// Syncs the WAL to disk every `every` until the server exits, so a power
+/// loss costs at most that much of the relational store's writes.
+pub async fn run_wal_syncer(db: Arc<Database>, every: Duration) {
+    let mut tick = tokio::time::interval(every);
+    loop {
+        tick.tick().await;
+        let db = db.clone();
+        match tokio::task::spawn_blocking(move || db.wal_sync()).await {
+            Ok(Ok(())) => {}
+            Ok(Err(e)) => error!("{}", e),
+            Err(e) => error!("wal: {}", e),
+        }
+    }
+}
+
+/// Snapshots to `path` every `every` until the server exits.
+pub async fn run_snapshotter(db: Arc<Database>, path: PathBuf, every: Duration) {
+    let mut tick = tokio::time::interval(every);
+    tick.tick().await; // the first tick is immediate; nothing has changed yet
+    loop {
+        tick.tick().await;
+        let (db, path) = (db.clone(), path.clone());
+        // Compression and file I/O block; keep them off the runtime.
+        match tokio::task::spawn_blocking(move || save_snapshot(&db, &path)).await {
+            Ok(Ok(n)) => debug!("snapshot: wrote {} bytes", n),
+            Ok(Err(e)) => error!("snapshot: {}", e),
+            Err(e) => error!("snapshot: {}", e),
+        }
+    }
 }
@@ -2,21 +2,72 @@
 
 use crate::hermit::{
     hermit_server::{Hermit, HermitServer},
-    BenchmarkRequest, BenchmarkResponse, DbStatsRequest, DbStatsResponse,
+    oplog_entry, sql_query_request, watch_event,
+    BenchmarkProgress, BenchmarkRequest, BenchmarkResponse, Bucket,
+    CreateBucketRequest, CreateBucketResponse, DbRestoreResponse, DbSnapshotRequest,
+    DbStatsRequest, DbStatsResponse, DiagnosticsRequest, DiagnosticsResponse,
+    DocNamespace, Follower, HistogramBucket,
+    KvDeleteRequest, KvDeleteResponse, KvExistsRequest, KvExistsResponse,
     KvGetRequest, KvGetResponse, KvListRequest, KvListResponse,
-    KvSetRequest, KvSetResponse, LoginRequest, LoginResponse,
-    PingRequest, PingResponse, ServerInfoRequest, ServerInfoResponse,
-    SqlInsertRequest, SqlInsertResponse, SqlQueryRequest, SqlQueryResponse, SqlRow,
+    KvSetRequest, KvSetResponse, KvTtlRequest, KvTtlResponse, LargeValue,
+    LimitsRequest, LimitsResponse, ListBucketsRequest, ListBucketsResponse,
+    LogLine, LoginRequest, LoginResponse, MetricsRequest, MetricsResponse, RpcMetric,
+    OplogEntry, PingRequest, PingResponse, PublishRequest, PublishResponse, RateLimit,
+    ReplicateRequest, ReplicationStatusRequest, RestoreFromUrlRequest, ReplicationStatusResponse, ServerInfoRequest, ServerInfoResponse,
+    SetLimitRequest, SlowCall, SnapshotChunk,
+    SqlDeleteRequest, SqlDeleteResponse, SqlInsertRequest, SqlInsertResponse,
+    SqlQueryRequest, SqlQueryResponse, SqlRow, SqlUpdateRequest, SqlUpdateResponse,
+    SubscribeRequest, TailLogsRequest, TopicMessage, TxnRequest, TxnResponse, WatchEvent,
+    WatchRequest,
 };
+use crate::auth::{self, Auth, Caller, Role};
+use crate::backup::{self, S3};
 use crate::bench;
-use crate::db::Database;
+use crate::limits::{Class, Limit, Limits, Quota};
+use crate::metrics::{self, Metrics, LATENCY_BOUNDS_US};
+use crate::db::{self, Database, KvChange, KvEvent, TxnOutcome};
+use crate::logs::{LogBuffer, LogRecord, LOG_CAPACITY};
+use crate::pubsub::{self, Topics};
+use crate::replica::{self, Replication};
 use crate::tls::TlsConfig;
 
 use prost_types::Timestamp;
+use std::net::SocketAddr;
 use std::sync::Arc;
-use std::time::{Instant, SystemTime, UNIX_EPOCH};
-use tonic::{Request, Response, Status};
-use tracing::info;
+use std::time::{Duration, Instant, SystemTime, UNIX_EPOCH};
+use tokio::net::TcpListener;
+use tokio::sync::{broadcast, mpsc};
+use tokio_stream::wrappers::{ReceiverStream, TcpListenerStream};
+use tokio_stream::StreamExt;
+use tonic::metadata::MetadataMap;
+use tonic::{Request, Response, Status, Streaming};
+use tracing::{info, warn};
+
+/// Most keys one KvList page returns, and the default page size.
+const KV_LIST_MAX: usize = 1000;
+
+/// Slow calls and large values Diagnostics returns by default, and at most.
+const DIAG_DEFAULT: usize = 20;
+const DIAG_MAX: usize = 100;
+
+/// Bytes per DbSnapshot message, well under gRPC's 4 MiB default limit.
+const SNAPSHOT_CHUNK: usize = 64 * 1024;
+
+/// Most iterations one BenchmarkStream runs.
+const BENCH_STREAM_MAX: u32 = 1_000_000;
+
+/// How often BenchmarkStream reports progress.
+const BENCH_PROGRESS_EVERY: Duration = Duration::from_millis(100);
+
+/// Metadata a rate-limited call's response carries.
+const QUOTA_CLASS_KEY: &str = "x-hermit-quota-class";
+const QUOTA_LIMIT_KEY: &str = "x-hermit-quota-limit";
+const QUOTA_RATE_KEY: &str = "x-hermit-quota-rate";
+const QUOTA_REMAINING_KEY: &str = "x-hermit-quota-remaining";
+const RETRY_AFTER_KEY: &str = "x-hermit-retry-after-ms";
+
+/// Metadata naming the bucket a key call is for; without it, the default.
+const BUCKET_KEY: &str = "x-hermit-bucket";
 
 pub struct ServerState {
     pub version: String,
@@ -30,11 +81,216 @@ pub struct HermitService {
     state: Arc<ServerState>,
     tls_enabled: bool,
     db: Arc<Database>,
+    logs: Arc<LogBuffer>,
+    auth: Arc<Auth>,
+    metrics: Arc<Metrics>,
+    topics: Topics,
+    limits: Arc<Limits>,
+    replication: Arc<Replication>,
+    s3: Option<Arc<S3>>, // the backup bucket, for s3:// restores
+}
+
+impl HermitService {
+    /// Takes a call of `class` from the caller's rate limit. The caller is
+    /// their user name, or their IP address when hermit runs open.
+    fn limit<T>(&self, req: &Request<T>, class: Class) -> Result<Option<Quota>, Status> {
+        let user = req
+            .extensions()
+            .get::<Caller>()
+            .map_or("", |c| c.user.as_str());
+        let client = match (user, req.remote_addr()) {
+            ("", Some(addr)) => addr.ip().to_string(),
+            (user, _) => user.to_string(),
+        };
+        self.limits.take(class, &client).map_err(|q| {
+            self.metrics.rate_limited();
+            let retry = q.retry_after.unwrap_or_default();
+            let mut status = Status::resource_exhausted(format!(
+                "{} limit of {}/s reached; retry in {}ms",
+                class.as_str(),
+                q.limit.per_second,
+                retry.as_millis().max(1)
+            ));
+            quota_metadata(status.metadata_mut(), &q);
+            status
+        })
+    }
+
+    /// The bucket a key call is for, from its x-hermit-bucket metadata. The
+    /// caller must be allowed `role` there, and it must exist.
+    fn bucket<T>(&self, req: &Request<T>, role: Role) -> Result<String, Status> {
+        let bucket = match req.metadata().get(BUCKET_KEY).map(|v| v.to_str()) {
+            None => db::DEFAULT_BUCKET,
+            Some(Ok(b)) if !b.is_empty() => b,
+            Some(_) => return Err(Status::invalid_argument("x-hermit-bucket: want a bucket name")),
+        };
+        auth::require_bucket(req, bucket, role)?;
+        if !self.db.has_bucket(bucket) {
+            return Err(Status::not_found(format!("no bucket {}; an admin can create it", bucket)));
+        }
+        Ok(bucket.to_string())
+    }
+
+    /// Refuses writes on a follower: its data comes from the primary.
+    fn writable(&self) -> Result<(), Status> {
+        match self.replication.following() {
+            Some(primary) => Err(Status::failed_precondition(format!(
+                "read-only follower of {}; write to the primary",
+                primary
+            ))),
+            None => Ok(()),
+        }
+    }
+
+    /// Every rate limit, as Limits and SetLimit return them.
+    fn limits_response(&self, error: String) -> LimitsResponse {
+        let limits = self
+            .limits
+            .list()
+            .into_iter()
+            .map(|(c, client, l)| to_rate_limit(c, client, l))
+            .collect();
+        LimitsResponse { limits, error }
+    }
+}
+
+/// Adds a quota's x-hermit-quota-* metadata, and how long to wait when the
+/// call was refused.
+fn quota_metadata(md: &mut MetadataMap, q: &Quota) {
+    let mut set = |key: &'static str, v: String| {
+        if let Ok(v) = v.parse() {
+            md.insert(key, v);
+        }
+    };
+    set(QUOTA_CLASS_KEY, q.class.as_str().to_string());
+    set(QUOTA_LIMIT_KEY, q.limit.burst.to_string());
+    set(QUOTA_RATE_KEY, q.limit.per_second.to_string());
+    set(QUOTA_REMAINING_KEY, q.remaining.to_string());
+    if let Some(d) = q.retry_after {
+        set(RETRY_AFTER_KEY, d.as_millis().max(1).to_string());
+    }
+}
+
+/// A response with the caller's quota, if the call was limited.
+fn with_quota<T>(msg: T, quota: Option<Quota>) -> Response<T> {
+    let mut resp = Response::new(msg);
+    if let Some(q) = quota {
+        quota_metadata(resp.metadata_mut(), &q);
+    }
+    resp
+}
+
+fn to_rate_limit(class: Class, client: Option<String>, l: Limit) -> RateLimit {
+    RateLimit {
+        class: class.as_str().to_string(),
+        client: client.unwrap_or_default(),
+        per_second: l.per_second,
+        burst: l.burst,
+    }
+}
+
+fn bench_progress(
+    h: &bench::Histogram,
+    total: u32,
+    elapsed: Duration,
+    payload_bytes: usize,
+    tls: bool,
+) -> BenchmarkProgress {
+    let secs = elapsed.as_secs_f64();
+    let rate = if secs > 0.0 { h.len() as f64 / secs } else { 0.0 };
+    BenchmarkProgress {
+        done: h.len() as u32,
+        total,
+        elapsed_ns: elapsed.as_nanos() as i64,
+        min_ns: h.min(),
+        max_ns: h.max(),
+        mean_ns: h.mean(),
+        p50_ns: h.quantile(0.50),
+        p90_ns: h.quantile(0.90),
+        p99_ns: h.quantile(0.99),
+        p999_ns: h.quantile(0.999),
+        iterations_per_sec: rate,
+        bytes_per_sec: rate * payload_bytes as f64,
+        histogram: h
+            .buckets()
+            .map(|(upper_ns, count)| HistogramBucket { upper_ns, count })
+            .collect(),
+        r#final: h.len() == total as u64,
+        tls_active: tls,
+        tls_version: if tls { "TLS 1.3".to_string() } else { String::new() },
+    }
+}
+
+fn to_timestamp(t: SystemTime) -> Timestamp {
+    let since_epoch = t.duration_since(UNIX_EPOCH).unwrap_or_default();
+    Timestamp {
+        seconds: since_epoch.as_secs() as i64,
+        nanos: since_epoch.subsec_nanos() as i32,
+    }
+}
+
+/// The event a watcher sees, or None for a change that isn't to a key.
+fn to_watch_event(ev: KvEvent) -> Option<WatchEvent> {
+    let (kind, value) = match ev.change {
+        KvChange::Set { value, .. } => (watch_event::Kind::Set, value),
+        KvChange::Delete => (watch_event::Kind::Delete, Vec::new()),
+        KvChange::Expire => (watch_event::Kind::Expire, Vec::new()),
+        KvChange::CreateBucket => return None,
+    };
+    Some(WatchEvent {
+        kind: kind as i32,
+        key: ev.key,
+        value,
+    })
+}
+
+fn to_oplog_entry(ev: KvEvent) -> OplogEntry {
+    let mut entry = OplogEntry {
+        seq: ev.seq,
+        key: ev.key,
+        bucket: ev.bucket,
+        ..Default::default()
+    };
+    let kind = match ev.change {
+        KvChange::Set { value, version, ttl } => {
+            entry.value = value;
+            entry.version = version;
+            // A key with under 1ms left still has a TTL.
+            entry.ttl_ms = ttl.map_or(0, |d| d.as_millis().max(1) as u64);
+            oplog_entry::Kind::Set
+        }
+        KvChange::Delete => oplog_entry::Kind::Delete,
+        KvChange::Expire => oplog_entry::Kind::Expire,
+        KvChange::CreateBucket => oplog_entry::Kind::Bucket,
+    };
+    entry.kind = kind as i32;
+    entry
+}
+
+fn to_topic_message(msg: pubsub::Message, dropped: u64) -> TopicMessage {
+    TopicMessage {
+        topic: msg.topic,
+        from: msg.from,
+        data: msg.data,
+        time: Some(to_timestamp(msg.time)),
+        seq: msg.seq,
+        dropped,
+    }
+}
+
+fn to_log_line(rec: LogRecord) -> LogLine {
+    LogLine {
+        time: Some(to_timestamp(rec.time)),
+        level: rec.level.to_string(),
+        target: rec.target,
+        message: rec.message,
+    }
 }
 
 #[tonic::async_trait]
 impl Hermit for HermitService {
     async fn ping(&self, req: Request<PingRequest>) -> Result<Response<PingResponse>, Status> {
+        let _timer = self.metrics.time("Ping");
         let recv = bench::now_ns();
         let inner = req.into_inner();
         let send = bench::now_ns();
@@ -49,6 +305,9 @@ impl Hermit for HermitService {
         &self,
         req: Request<BenchmarkRequest>,
     ) -> Result<Response<BenchmarkResponse>, Status> {
+        let _timer = self.metrics.time("Benchmark");
+        auth::require(&req, Role::Admin)?;
+        let quota = self.limit(&req, Class::Bench)?;
         let inner = req.into_inner();
         let iterations = inner.iterations.max(1).min(10_000) as usize;
         let payload_bytes = inner.payload_bytes as usize;
@@ -78,7 +337,7 @@ impl Hermit for HermitService {
 
         let stats = bench::Stats::from_sorted(&latencies);
 
-        Ok(Response::new(BenchmarkResponse {
+        let resp = BenchmarkResponse {
             latencies_ns: latencies,
             min_ns: stats.min,
             max_ns: stats.max,
@@ -92,26 +351,83 @@ impl Hermit for HermitService {
             } else {
                 String::new()
             },
-        }))
+        };
+        Ok(with_quota(resp, quota))
     }
 
-    async fn login(&self, req: Request<LoginRequest>) -> Result<Response<LoginResponse>, Status> {
+    type BenchmarkStreamStream = ReceiverStream<Result<BenchmarkProgress, Status>>;
+
+    async fn benchmark_stream(
+        &self,
+        req: Request<BenchmarkRequest>,
+    ) -> Result<Response<Self::BenchmarkStreamStream>, Status> {
+        let _timer = self.metrics.time("BenchmarkStream");
+        auth::require(&req, Role::Admin)?;
+        let quota = self.limit(&req, Class::Bench)?;
         let inner = req.into_inner();
-        info!(username = %inner.username, "login attempt (hardcoded success)");
+        let total = inner.iterations.clamp(1, BENCH_STREAM_MAX);
+        let payload_bytes = inner.payload_bytes as usize;
+        let tls = self.tls_enabled;
+        let (tx, out) = mpsc::channel(4);
 
-        // TODO: Real auth. For now, always succeed.
-        let session_id = uuid::Uuid::new_v4().to_string();
-        Ok(Response::new(LoginResponse {
-            success: true,
-            session_id,
-            error: String::new(),
-        }))
+        // The loop is CPU-bound, so it gets a thread of its own; it stops
+        // early when the client goes away.
+        tokio::task::spawn_blocking(move || {
+            let payload = vec![0xAB_u8; payload_bytes];
+            let mut copy = vec![0_u8; payload_bytes];
+            let mut h = bench::Histogram::new();
+            let start = Instant::now();
+            let mut last = start;
+            for i in 1..=total {
+                let t0 = bench::now_ns();
+                copy.copy_from_slice(&payload);
+                std::hint::black_box(&copy);
+                h.record(bench::now_ns() - t0);
+
+                if i < total && last.elapsed() < BENCH_PROGRESS_EVERY {
+                    continue;
+                }
+                last = Instant::now();
+                let p = bench_progress(&h, total, start.elapsed(), payload_bytes, tls);
+                if tx.blocking_send(Ok(p)).is_err() {
+                    return;
+                }
+            }
+        });
+
+        Ok(with_quota(ReceiverStream::new(out), quota))
+    }
+
+    async fn login(&self, req: Request<LoginRequest>) -> Result<Response<LoginResponse>, Status> {
+        let _timer = self.metrics.time("Login");
+        let inner = req.into_inner();
+        match self.auth.login(&inner.username, &inner.token) {
+            Ok((session_id, role)) => {
+                info!(username = %inner.username, %role, "login");
+                Ok(Response::new(LoginResponse {
+                    success: true,
+                    session_id,
+                    error: String::new(),
+                    role: role.to_string(),
+                }))
+            }
+            Err(e) => {
+                warn!(username = %inner.username, "login failed: {}", e);
+                Ok(Response::new(LoginResponse {
+                    success: false,
+                    error: e,
+                    ..Default::default()
+                }))
+            }
+        }
     }
 
     async fn server_info(
         &self,
-        _req: Request<ServerInfoRequest>,
+        req: Request<ServerInfoRequest>,
     ) -> Result<Response<ServerInfoResponse>, Status> {
+        let _timer = self.metrics.time("ServerInfo");
+        auth::require(&req, Role::Read)?;
         let uptime = self.state.start_instant.elapsed().as_secs() as i64;
         let since_epoch = self.state.started_at
             .duration_since(UNIX_EPOCH)
@@ -135,76 +451,237 @@ impl Hermit for HermitService {
         &self,
         req: Request<KvSetRequest>,
     ) -> Result<Response<KvSetResponse>, Status> {
+        let mut timer = self.metrics.time("KvSet");
+        let bucket = self.bucket(&req, Role::Write)?;
+        self.writable()?;
+        let quota = self.limit(&req, Class::Write)?;
         let inner = req.into_inner();
-        match self.db.kv_set(inner.key, inner.value) {
-            Ok(()) => Ok(Response::new(KvSetResponse {
+        self.metrics.value_written(&bucket, &inner.key, inner.value.len());
+        let ttl = (inner.ttl_ms > 0).then(|| Duration::from_millis(inner.ttl_ms));
+        let set = self.db.kv_set(&bucket, inner.key.clone(), inner.value, ttl);
+        timer.about(&bucket, &inner.key);
+        let resp = match set {
+            Ok(version) => KvSetResponse {
                 ok: true,
                 error: String::new(),
-            })),
-            Err(e) => Ok(Response::new(KvSetResponse {
+                version,
+            },
+            Err(e) => KvSetResponse {
                 ok: false,
                 error: e,
-            })),
-        }
+                version: 0,
+            },
+        };
+        Ok(with_quota(resp, quota))
     }
 
     async fn kv_get(
         &self,
         req: Request<KvGetRequest>,
     ) -> Result<Response<KvGetResponse>, Status> {
+        let mut timer = self.metrics.time("KvGet");
+        let bucket = self.bucket(&req, Role::Read)?;
         let inner = req.into_inner();
-        match self.db.kv_get(&inner.key) {
-            Ok(Some(value)) => Ok(Response::new(KvGetResponse {
+        let got = self.db.kv_get(&bucket, &inner.key);
+        timer.about(&bucket, &inner.key);
+        match got {
+            Ok(Some((value, version))) => Ok(Response::new(KvGetResponse {
                 found: true,
                 value,
                 error: String::new(),
+                version,
             })),
-            Ok(None) => Ok(Response::new(KvGetResponse {
-                found: false,
-                value: Vec::new(),
-                error: String::new(),
-            })),
+            Ok(None) => Ok(Response::new(KvGetResponse::default())),
             Err(e) => Ok(Response::new(KvGetResponse {
-                found: false,
-                value: Vec::new(),
                 error: e,
+                ..Default::default()
             })),
         }
     }
 
     async fn kv_list(
         &self,
-        _req: Request<KvListRequest>,
+        req: Request<KvListRequest>,
     ) -> Result<Response<KvListResponse>, Status> {
-        match self.db.kv_list() {
-            Ok(keys) => Ok(Response::new(KvListResponse { keys })),
+        let _timer = self.metrics.time("KvList");
+        let bucket = self.bucket(&req, Role::Read)?;
+        let inner = req.into_inner();
+        let limit = match inner.limit as usize {
+            0 => KV_LIST_MAX,
+            n => n.min(KV_LIST_MAX),
+        };
+        match self.db.kv_list(&bucket, &inner.prefix, &inner.cursor, limit) {
+            Ok((keys, next_cursor)) => Ok(Response::new(KvListResponse { keys, next_cursor })),
             Err(e) => Err(Status::internal(e)),
         }
     }
 
+    async fn kv_delete(
+        &self,
+        req: Request<KvDeleteRequest>,
+    ) -> Result<Response<KvDeleteResponse>, Status> {
+        let mut timer = self.metrics.time("KvDelete");
+        let bucket = self.bucket(&req, Role::Write)?;
+        self.writable()?;
+        let quota = self.limit(&req, Class::Write)?;
+        let inner = req.into_inner();
+        let deleted = self.db.kv_delete(&bucket, &inner.key);
+        timer.about(&bucket, &inner.key);
+        let resp = match deleted {
+            Ok(deleted) => KvDeleteResponse {
+                deleted,
+                error: String::new(),
+            },
+            Err(e) => KvDeleteResponse {
+                deleted: false,
+                error: e,
+            },
+        };
+        Ok(with_quota(resp, quota))
+    }
+
+    async fn kv_exists(
+        &self,
+        req: Request<KvExistsRequest>,
+    ) -> Result<Response<KvExistsResponse>, Status> {
+        let mut timer = self.metrics.time("KvExists");
+        let bucket = self.bucket(&req, Role::Read)?;
+        let inner = req.into_inner();
+        let exists = self.db.kv_exists(&bucket, &inner.key);
+        timer.about(&bucket, &inner.key);
+        match exists {
+            Ok(exists) => Ok(Response::new(KvExistsResponse {
+                exists,
+                error: String::new(),
+            })),
+            Err(e) => Ok(Response::new(KvExistsResponse {
+                exists: false,
+                error: e,
+            })),
+        }
+    }
+
+    async fn kv_ttl(
+        &self,
+        req: Request<KvTtlRequest>,
+    ) -> Result<Response<KvTtlResponse>, Status> {
+        let mut timer = self.metrics.time("KvTTL");
+        let bucket = self.bucket(&req, Role::Read)?;
+        let inner = req.into_inner();
+        let ttl = self.db.kv_ttl(&bucket, &inner.key);
+        timer.about(&bucket, &inner.key);
+        let (found, left) = match ttl {
+            Ok(Some(left)) => (true, left),
+            Ok(None) => (false, None),
+            Err(e) => {
+                return Ok(Response::new(KvTtlResponse {
+                    error: e,
+                    ..Default::default()
+                }))
+            }
+        };
+        Ok(Response::new(KvTtlResponse {
+            found,
+            expires: left.is_some(),
+            ttl_ms: left.map_or(0, |d| d.as_millis() as u64),
+            error: String::new(),
+        }))
+    }
+
+    async fn txn(
+        &self,
+        req: Request<TxnRequest>,
+    ) -> Result<Response<TxnResponse>, Status> {
+        let _timer = self.metrics.time("Txn");
+        let bucket = self.bucket(&req, Role::Write)?;
+        self.writable()?;
+        let quota = self.limit(&req, Class::Write)?;
+        let inner = req.into_inner();
+        let guards: Vec<db::TxnGuard> = inner
+            .guards
+            .into_iter()
+            .map(|g| db::TxnGuard {
+                key: g.key,
+                version: g.version,
+                value: g.value,
+            })
+            .collect();
+        let writes = inner
+            .writes
+            .into_iter()
+            .map(|w| {
+                self.metrics.value_written(&bucket, &w.key, w.value.len());
+                db::TxnWrite {
+                    key: w.key,
+                    value: w.value,
+                    delete: w.delete,
+                    ttl: (w.ttl_ms > 0).then(|| Duration::from_millis(w.ttl_ms)),
+                }
+            })
+            .collect();
+        let resp = match self.db.txn(&bucket, &guards, writes) {
+            Ok(TxnOutcome::Applied(versions)) => TxnResponse {
+                ok: true,
+                versions,
+                ..Default::default()
+            },
+            Ok(TxnOutcome::Failed(i)) => TxnResponse {
+                failed_guard: i as u32,
+                ..Default::default()
+            },
+            Err(e) => TxnResponse {
+                error: e,
+                ..Default::default()
+            },
+        };
+        Ok(with_quota(resp, quota))
+    }
+
     async fn sql_insert(
         &self,
         req: Request<SqlInsertRequest>,
     ) -> Result<Response<SqlInsertResponse>, Status> {
+        let _timer = self.metrics.time("SqlInsert");
+        auth::require_unscoped(&req, Role::Write)?;
+        self.writable()?;
+        let quota = self.limit(&req, Class::Write)?;
         let inner = req.into_inner();
-        match self.db.sql_insert(inner.key, inner.value) {
-            Ok(_) => Ok(Response::new(SqlInsertResponse {
+        let resp = match self.db.sql_insert(inner.key, inner.value) {
+            Ok(_) => SqlInsertResponse {
                 queued: true,
                 error: String::new(),
-            })),
-            Err(e) => Ok(Response::new(SqlInsertResponse {
+            },
+            Err(e) => SqlInsertResponse {
                 queued: false,
                 error: e,
-            })),
-        }
+            },
+        };
+        Ok(with_quota(resp, quota))
     }
 
     async fn sql_query(
         &self,
         req: Request<SqlQueryRequest>,
     ) -> Result<Response<SqlQueryResponse>, Status> {
+        let _timer = self.metrics.time("SqlQuery");
+        auth::require_unscoped(&req, Role::Read)?;
         let inner = req.into_inner();
-        match self.db.sql_query(&inner.key_filter, inner.limit) {
+        let order_by = match inner.order_by() {
+            sql_query_request::Order::Id => db::SqlOrder::Id,
+            sql_query_request::Order::Key => db::SqlOrder::Key,
+            sql_query_request::Order::Value => db::SqlOrder::Value,
+            sql_query_request::Order::Created => db::SqlOrder::Created,
+        };
+        let q = db::SqlQuery {
+            key_filter: &inner.key_filter,
+            value_filter: &inner.value_filter,
+            order_by,
+            desc: inner.desc,
+            offset: inner.offset as usize,
+            limit: inner.limit,
+            count_only: inner.count_only,
+        };
+        match self.db.sql_query(&q) {
             Ok(result) => {
                 let rows = result
                     .rows
@@ -220,25 +697,589 @@ impl Hermit for HermitService {
                     rows,
                     total_committed: result.total_committed,
                     pending_writes: result.pending_writes,
+                    matched: result.matched,
                 }))
             }
             Err(e) => Err(Status::internal(e)),
         }
     }
 
+    async fn sql_delete(
+        &self,
+        req: Request<SqlDeleteRequest>,
+    ) -> Result<Response<SqlDeleteResponse>, Status> {
+        let _timer = self.metrics.time("SqlDelete");
+        auth::require_unscoped(&req, Role::Write)?;
+        self.writable()?;
+        let quota = self.limit(&req, Class::Write)?;
+        let inner = req.into_inner();
+        let resp = match self.db.sql_delete(&inner.key) {
+            Ok(rows) => SqlDeleteResponse {
+                rows,
+                error: String::new(),
+            },
+            Err(e) => SqlDeleteResponse { rows: 0, error: e },
+        };
+        Ok(with_quota(resp, quota))
+    }
+
+    async fn sql_update(
+        &self,
+        req: Request<SqlUpdateRequest>,
+    ) -> Result<Response<SqlUpdateResponse>, Status> {
+        let _timer = self.metrics.time("SqlUpdate");
+        auth::require_unscoped(&req, Role::Write)?;
+        self.writable()?;
+        let quota = self.limit(&req, Class::Write)?;
+        let inner = req.into_inner();
+        let resp = match self.db.sql_update(&inner.key, &inner.value) {
+            Ok(rows) => SqlUpdateResponse {
+                rows,
+                error: String::new(),
+            },
+            Err(e) => SqlUpdateResponse { rows: 0, error: e },
+        };
+        Ok(with_quota(resp, quota))
+    }
+
     async fn db_stats(
         &self,
-        _req: Request<DbStatsRequest>,
+        req: Request<DbStatsRequest>,
     ) -> Result<Response<DbStatsResponse>, Status> {
+        let _timer = self.metrics.time("DbStats");
+        auth::require(&req, Role::Read)?;
         let (doc_count, doc_bytes) = self.db.kv_stats().map_err(Status::internal)?;
         let (rel_rows, rel_pending) = self.db.rel_stats().map_err(Status::internal)?;
+        let namespaces: Vec<DocNamespace> = self
+            .db
+            .namespace_stats()
+            .map_err(Status::internal)?
+            .into_iter()
+            .map(|ns| DocNamespace {
+                name: ns.name,
+                codec: ns.codec.as_str().to_string(),
+                keys: ns.keys,
+                raw_bytes: ns.raw_bytes,
+                stored_bytes: ns.stored_bytes,
+            })
+            .collect();
         Ok(Response::new(DbStatsResponse {
             doc_key_count: doc_count,
             doc_compressed_bytes: doc_bytes,
             rel_row_count: rel_rows,
             rel_pending_writes: rel_pending,
+            doc_raw_bytes: namespaces.iter().map(|ns| ns.raw_bytes).sum(),
+            namespaces,
+        }))
+    }
+
+    type TailLogsStream = ReceiverStream<Result<LogLine, Status>>;
+
+    async fn tail_logs(
+        &self,
+        req: Request<TailLogsRequest>,
+    ) -> Result<Response<Self::TailLogsStream>, Status> {
+        let _timer = self.metrics.time("TailLogs");
+        auth::require(&req, Role::Admin)?;
+        let backlog = (req.into_inner().backlog as usize).min(LOG_CAPACITY);
+        let (recent, mut rx) = self.logs.subscribe(backlog);
+        let (tx, out) = mpsc::channel(64);
+
+        tokio::spawn(async move {
+            for rec in recent {
+                if tx.send(Ok(to_log_line(rec))).await.is_err() {
+                    return;
+                }
+            }
+            loop {
+                let line = tokio::select! {
+                    _ = tx.closed() => return, // client went away
+                    r = rx.recv() => match r {
+                        Ok(rec) => to_log_line(rec),
+                        Err(broadcast::error::RecvError::Lagged(n)) => LogLine {
+                            time: Some(to_timestamp(SystemTime::now())),
+                            level: "WARN".to_string(),
+                            target: module_path!().to_string(),
+                            message: format!("{} lines dropped: client too slow", n),
+                        },
+                        Err(broadcast::error::RecvError::Closed) => return,
+                    },
+                };
+                if tx.send(Ok(line)).await.is_err() {
+                    return;
+                }
+            }
+        });
+
+        Ok(Response::new(ReceiverStream::new(out)))
+    }
+
+    type WatchStream = ReceiverStream<Result<WatchEvent, Status>>;
+
+    async fn watch(
+        &self,
+        req: Request<WatchRequest>,
+    ) -> Result<Response<Self::WatchStream>, Status> {
+        let _timer = self.metrics.time("Watch");
+        let bucket = self.bucket(&req, Role::Read)?;
+        let prefix = req.into_inner().prefix;
+        let mut rx = self.db.watch();
+        let (tx, out) = mpsc::channel(64);
+
+        tokio::spawn(async move {
+            loop {
+                let ev = tokio::select! {
+                    _ = tx.closed() => return, // client went away
+                    r = rx.recv() => match r {
+                        Ok(ev) => ev,
+                        Err(broadcast::error::RecvError::Lagged(n)) => {
+                            // Keys, unlike log lines, can't be skipped.
+                            let msg = format!("watcher fell {} events behind; re-read and watch again", n);
+                            let _ = tx.send(Err(Status::data_loss(msg))).await;
+                            return;
+                        }
+                        Err(broadcast::error::RecvError::Closed) => return,
+                    },
+                };
+                if ev.bucket != bucket || !ev.key.starts_with(&prefix) {
+                    continue;
+                }
+                let Some(ev) = to_watch_event(ev) else {
+                    continue;
+                };
+                if tx.send(Ok(ev)).await.is_err() {
+                    return;
+                }
+            }
+        });
+
+        Ok(Response::new(ReceiverStream::new(out)))
+    }
+
+    type DbSnapshotStream = ReceiverStream<Result<SnapshotChunk, Status>>;
+
+    async fn db_snapshot(
+        &self,
+        req: Request<DbSnapshotRequest>,
+    ) -> Result<Response<Self::DbSnapshotStream>, Status> {
+        let _timer = self.metrics.time("DbSnapshot");
+        auth::require(&req, Role::Admin)?;
+        let db = self.db.clone();
+        let data = tokio::task::spawn_blocking(move || db.snapshot())
+            .await
+            .map_err(|e| Status::internal(e.to_string()))?
+            .map_err(Status::internal)?;
+        info!(bytes = data.len(), "sending snapshot");
+        let (tx, out) = mpsc::channel(4);
+
+        tokio::spawn(async move {
+            for chunk in data.chunks(SNAPSHOT_CHUNK) {
+                if tx.send(Ok(SnapshotChunk { data: chunk.to_vec() })).await.is_err() {
+                    return; // client went away
+                }
+            }
+        });
+
+        Ok(Response::new(ReceiverStream::new(out)))
+    }
+
+    async fn db_restore(
+        &self,
+        req: Request<Streaming<SnapshotChunk>>,
+    ) -> Result<Response<DbRestoreResponse>, Status> {
+        let _timer = self.metrics.time("DbRestore");
+        auth::require(&req, Role::Admin)?;
+        self.writable()?;
+        let quota = self.limit(&req, Class::Write)?;
+        let mut chunks = req.into_inner();
+        let mut data = Vec::new();
+        while let Some(chunk) = chunks.message().await? {
+            if (data.len() + chunk.data.len()) as u64 > db::SNAPSHOT_MAX_BYTES {
+                return Err(Status::resource_exhausted("snapshot too large"));
+            }
+            data.extend_from_slice(&chunk.data);
+        }
+        let db = self.db.clone();
+        let (doc_keys, rel_rows) = tokio::task::spawn_blocking(move || db.restore(&data))
+            .await
+            .map_err(|e| Status::internal(e.to_string()))?
+            .map_err(Status::invalid_argument)?;
+        info!(doc_keys, rel_rows, "restored snapshot");
+        Ok(with_quota(DbRestoreResponse { doc_keys, rel_rows }, quota))
+    }
+
+    async fn restore_from_url(
+        &self,
+        req: Request<RestoreFromUrlRequest>,
+    ) -> Result<Response<DbRestoreResponse>, Status> {
+        let _timer = self.metrics.time("RestoreFromUrl");
+        auth::require(&req, Role::Admin)?;
+        self.writable()?;
+        let quota = self.limit(&req, Class::Write)?;
+        let url = req.into_inner().url;
+        let data = backup::fetch(&url, self.s3.as_deref()).await.map_err(Status::unavailable)?;
+        let db = self.db.clone();
+        let (doc_keys, rel_rows) = tokio::task::spawn_blocking(move || db.restore(&data))
+            .await
+            .map_err(|e| Status::internal(e.to_string()))?
+            .map_err(Status::invalid_argument)?;
+        info!(%url, doc_keys, rel_rows, "restored snapshot");
+        Ok(with_quota(DbRestoreResponse { doc_keys, rel_rows }, quota))
+    }
+
+    async fn metrics(
+        &self,
+        req: Request<MetricsRequest>,
+    ) -> Result<Response<MetricsResponse>, Status> {
+        let _timer = self.metrics.time("Metrics");
+        auth::require(&req, Role::Read)?;
+        let s = metrics::sample(&self.metrics, &self.db, self.state.start_instant).map_err(Status::internal)?;
+        let rpcs = s
+            .rpcs
+            .into_iter()
+            .map(|(method, r)| RpcMetric {
+                method: method.to_string(),
+                calls: r.calls,
+                total_us: r.total_us,
+                max_us: r.max_us,
+                buckets: r.buckets.to_vec(),
+            })
+            .collect();
+        Ok(Response::new(MetricsResponse {
+            rpcs,
+            latency_bounds_us: LATENCY_BOUNDS_US.to_vec(),
+            doc_keys: s.doc_keys,
+            doc_bytes: s.doc_bytes,
+            rel_rows: s.rel_rows,
+            rel_pending_writes: s.rel_pending,
+            watchers: s.watchers,
+            expire_sweeps: s.gc.sweeps,
+            expired_keys: s.gc.expired,
+            expire_last_sweep_us: s.gc.last_sweep_us,
+            connections: s.connections,
+            tls_handshakes: s.tls_handshakes,
+            rate_limited: s.rate_limited,
+            rss_bytes: s.rss_bytes,
+            uptime_seconds: s.uptime.as_secs() as i64,
+        }))
+    }
+
+    async fn publish(
+        &self,
+        req: Request<PublishRequest>,
+    ) -> Result<Response<PublishResponse>, Status> {
+        let _timer = self.metrics.time("Publish");
+        let caller = auth::require_unscoped(&req, Role::Write)?;
+        let quota = self.limit(&req, Class::Write)?;
+        let inner = req.into_inner();
+        let resp = match self.topics.publish(&inner.topic, &caller.user, inner.data) {
+            Ok(delivered) => PublishResponse {
+                delivered,
+                error: String::new(),
+            },
+            Err(e) => PublishResponse {
+                delivered: 0,
+                error: e,
+            },
+        };
+        Ok(with_quota(resp, quota))
+    }
+
+    type SubscribeStream = ReceiverStream<Result<TopicMessage, Status>>;
+
+    async fn subscribe(
+        &self,
+        req: Request<SubscribeRequest>,
+    ) -> Result<Response<Self::SubscribeStream>, Status> {
+        let _timer = self.metrics.time("Subscribe");
+        auth::require_unscoped(&req, Role::Read)?;
+        let mut rx = self
+            .topics
+            .subscribe(&req.into_inner().topic)
+            .map_err(Status::invalid_argument)?;
+        let (tx, out) = mpsc::channel(64);
+
+        tokio::spawn(async move {
+            let mut dropped = 0;
+            loop {
+                let msg = tokio::select! {
+                    _ = tx.closed() => return, // client went away
+                    r = rx.recv() => match r {
+                        Ok(msg) => msg,
+                        // Messages, like log lines, can be skipped.
+                        Err(broadcast::error::RecvError::Lagged(n)) => {
+                            dropped += n;
+                            continue;
+                        }
+                        Err(broadcast::error::RecvError::Closed) => return,
+                    },
+                };
+                if tx.send(Ok(to_topic_message(msg, dropped))).await.is_err() {
+                    return;
+                }
+                dropped = 0;
+            }
+        });
+
+        Ok(Response::new(ReceiverStream::new(out)))
+    }
+
+    async fn limits(
+        &self,
+        req: Request<LimitsRequest>,
+    ) -> Result<Response<LimitsResponse>, Status> {
+        let _timer = self.metrics.time("Limits");
+        auth::require(&req, Role::Admin)?;
+        Ok(Response::new(self.limits_response(String::new())))
+    }
+
+    async fn set_limit(
+        &self,
+        req: Request<SetLimitRequest>,
+    ) -> Result<Response<LimitsResponse>, Status> {
+        let _timer = self.metrics.time("SetLimit");
+        let caller = auth::require(&req, Role::Admin)?;
+        let inner = req.into_inner();
+        let l = inner.limit.unwrap_or_default();
+        let client = (!l.client.is_empty()).then_some(l.client.as_str());
+        let result = match (Class::parse(&l.class), client) {
+            (None, _) => Err(format!("class {:?}: want write or bench", l.class)),
+            (Some(_), None) if inner.remove => {
+                Err("the default limit can't be removed; set it to 0".to_string())
+            }
+            (Some(class), Some(client)) if inner.remove => {
+                self.limits.remove(class, client).map(|_| ())
+            }
+            (Some(class), client) => Limit::new(l.per_second, l.burst)
+                .and_then(|limit| self.limits.set(class, client, limit)),
+        };
+        let error = match result {
+            Ok(()) => {
+                info!(
+                    admin = %caller.user,
+                    class = %l.class,
+                    client = %l.client,
+                    per_second = l.per_second,
+                    burst = l.burst,
+                    remove = inner.remove,
+                    "rate limit changed"
+                );
+                String::new()
+            }
+            Err(e) => e,
+        };
+        Ok(Response::new(self.limits_response(error)))
+    }
+
+    async fn create_bucket(
+        &self,
+        req: Request<CreateBucketRequest>,
+    ) -> Result<Response<CreateBucketResponse>, Status> {
+        let _timer = self.metrics.time("CreateBucket");
+        let caller = auth::require(&req, Role::Admin)?;
+        self.writable()?;
+        let name = req.into_inner().name;
+        let created = self.db.create_bucket(&name).map_err(Status::invalid_argument)?;
+        if created {
+            info!(admin = %caller.user, bucket = %name, "bucket created");
+        }
+        Ok(Response::new(CreateBucketResponse { created }))
+    }
+
+    async fn list_buckets(
+        &self,
+        req: Request<ListBucketsRequest>,
+    ) -> Result<Response<ListBucketsResponse>, Status> {
+        let _timer = self.metrics.time("ListBuckets");
+        let caller = auth::require(&req, Role::Read)?;
+        let buckets = self
+            .db
+            .bucket_stats()
+            .map_err(Status::internal)?
+            .into_iter()
+            .filter_map(|b| {
+                let role = caller.bucket_role(&b.name)?;
+                Some(Bucket {
+                    name: b.name,
+                    keys: b.keys,
+                    raw_bytes: b.raw_bytes,
+                    stored_bytes: b.stored_bytes,
+                    created: Some(to_timestamp(b.created)),
+                    role: role.as_str().to_string(),
+                })
+            })
+            .collect();
+        Ok(Response::new(ListBucketsResponse { buckets }))
+    }
+
+    async fn diagnostics(
+        &self,
+        req: Request<DiagnosticsRequest>,
+    ) -> Result<Response<DiagnosticsResponse>, Status> {
+        let _timer = self.metrics.time("Diagnostics");
+        auth::require(&req, Role::Admin)?;
+        let limit = match req.into_inner().limit as usize {
+            0 => DIAG_DEFAULT,
+            n => n.min(DIAG_MAX),
+        };
+        let (slow, calls) = self.metrics.slow_calls(limit);
+        let slow_calls = calls
+            .into_iter()
+            .map(|c| SlowCall {
+                method: c.method.to_string(),
+                took_us: c.took.as_micros() as u64,
+                at: Some(to_timestamp(c.at)),
+                bucket: c.bucket,
+                key: c.key,
+            })
+            .collect();
+        let large_values = self
+            .db
+            .largest_values(limit)
+            .map_err(Status::internal)?
+            .into_iter()
+            .map(|v| LargeValue {
+                bucket: v.bucket,
+                key: v.key,
+                raw_bytes: v.raw_bytes,
+                stored_bytes: v.stored_bytes,
+            })
+            .collect();
+        Ok(Response::new(DiagnosticsResponse {
+            slow_calls,
+            slow_threshold_us: slow.as_micros() as u64,
+            large_values,
+            large_value_bytes: self.metrics.large_value() as u64,
         }))
     }
+
+    type ReplicateStream = ReceiverStream<Result<OplogEntry, Status>>;
+
+    async fn replicate(
+        &self,
+        req: Request<ReplicateRequest>,
+    ) -> Result<Response<Self::ReplicateStream>, Status> {
+        let _timer = self.metrics.time("Replicate");
+        auth::require(&req, Role::Admin)?;
+        if self.replication.following().is_some() {
+            return Err(Status::failed_precondition("a follower can't be followed; use the primary"));
+        }
+        let addr = req.remote_addr().map_or_else(String::new, |a| a.to_string());
+        let name = req.into_inner().follower;
+        // Watch first, so no change made while the snapshot is taken is
+        // missed; the ones already in it are skipped by seq.
+        let mut rx = self.db.watch();
+        let db = self.db.clone();
+        let (data, snapshot_seq) = tokio::task::spawn_blocking(move || db.snapshot_at())
+            .await
+            .map_err(|e| Status::internal(e.to_string()))?
+            .map_err(Status::internal)?;
+        info!(follower = %name, %addr, seq = snapshot_seq, bytes = data.len(), "follower attached");
+        let attached = self.replication.attach(name, addr);
+        let db = self.db.clone();
+        let (tx, out) = mpsc::channel(64);
+
+        tokio::spawn(async move {
+            let snapshot = data.chunks(SNAPSHOT_CHUNK).map(|chunk| OplogEntry {
+                kind: oplog_entry::Kind::Snapshot as i32,
+                seq: snapshot_seq,
+                value: chunk.to_vec(),
+                ..Default::default()
+            });
+            let end = OplogEntry {
+                kind: oplog_entry::Kind::SnapshotEnd as i32,
+                seq: snapshot_seq,
+                ..Default::default()
+            };
+            for entry in snapshot.chain([end]) {
+                if tx.send(Ok(entry)).await.is_err() {
+                    return; // follower went away
+                }
+            }
+            attached.sent(snapshot_seq);
+
+            let mut heartbeat = tokio::time::interval(replica::HEARTBEAT_EVERY);
+            loop {
+                let entry = tokio::select! {
+                    _ = tx.closed() => return,
+                    _ = heartbeat.tick() => OplogEntry {
+                        kind: oplog_entry::Kind::Heartbeat as i32,
+                        seq: db.oplog_seq(),
+                        ..Default::default()
+                    },
+                    r = rx.recv() => match r {
+                        Ok(ev) if ev.seq <= snapshot_seq => continue,
+                        Ok(ev) => {
+                            heartbeat.reset();
+                            to_oplog_entry(ev)
+                        }
+                        Err(broadcast::error::RecvError::Lagged(n)) => {
+                            // The follower has to start over to catch up.
+                            let msg = format!("follower fell {} changes behind; resyncing", n);
+                            let _ = tx.send(Err(Status::data_loss(msg))).await;
+                            return;
+                        }
+                        Err(broadcast::error::RecvError::Closed) => return,
+                    },
+                };
+                let is_change = entry.kind != oplog_entry::Kind::Heartbeat as i32;
+                let seq = entry.seq;
+                if tx.send(Ok(entry)).await.is_err() {
+                    return;
+                }
+                if is_change {
+                    attached.sent(seq);
+                }
+            }
+        });
+
+        Ok(Response::new(ReceiverStream::new(out)))
+    }
+
+    async fn replication_status(
+        &self,
+        req: Request<ReplicationStatusRequest>,
+    ) -> Result<Response<ReplicationStatusResponse>, Status> {
+        let _timer = self.metrics.time("ReplicationStatus");
+        auth::require(&req, Role::Read)?;
+        let oplog_seq = self.db.oplog_seq();
+        let resp = match self.replication.following() {
+            Some(primary) => {
+                let s = self.replication.follow_status();
+                ReplicationStatusResponse {
+                    role: "follower".to_string(),
+                    oplog_seq,
+                    primary: primary.to_string(),
+                    connected: s.connected,
+                    applied_seq: s.applied_seq,
+                    primary_seq: s.primary_seq,
+                    lag: s.primary_seq.saturating_sub(s.applied_seq),
+                    last_contact_ms: s.last_contact.map_or(0, |t| t.elapsed().as_millis().max(1) as u64),
+                    resyncs: s.resyncs,
+                    error: s.error,
+                    ..Default::default()
+                }
+            }
+            None => ReplicationStatusResponse {
+                role: "primary".to_string(),
+                oplog_seq,
+                followers: self
+                    .replication
+                    .followers()
+                    .into_iter()
+                    .map(|f| Follower {
+                        name: f.name,
+                        addr: f.addr,
+                        sent_seq: f.sent_seq,
+                        lag: oplog_seq.saturating_sub(f.sent_seq),
+                        since: Some(to_timestamp(f.since)),
+                    })
+                    .collect(),
+                ..Default::default()
+            },
+        };
+        Ok(Response::new(resp))
+    }
 }
 
 pub async fn serve(
@@ -246,16 +1287,38 @@ pub async fn serve(
     state: Arc<ServerState>,
     tls_cfg: Option<TlsConfig>,
     db: Arc<Database>,
+    logs: Arc<LogBuffer>,
+    auth: Arc<Auth>,
+    metrics: Arc<Metrics>,
+    limits: Arc<Limits>,
+    replication: Arc<Replication>,
+    s3: Option<Arc<S3>>,
 ) -> Result<(), Box<dyn std::error::Error + Send + Sync>> {
-    let addr = format!("0.0.0.0:{}", port).parse()?;
+    let addr: SocketAddr = format!("0.0.0.0:{}", port).parse()?;
     let tls_enabled = tls_cfg.is_some();
     let svc = HermitService {
         state,
         tls_enabled,
         db,
+        logs,
+        auth: auth.clone(),
+        metrics: metrics.clone(),
+        topics: Topics::new(),
+        limits,
+        replication,
+        s3,
     };
 
-    let grpc_svc = HermitServer::with_interceptor(svc, crate::auth::secret_interceptor);
+    let grpc_svc = HermitServer::with_interceptor(svc, auth::interceptor(auth));
+
+    // Accept connections ourselves to count them.
+    let incoming = TcpListenerStream::new(TcpListener::bind(addr).await?).map(move |conn| {
+        if let Ok(c) = &conn {
+            let _ = c.set_nodelay(true);
+            metrics.connection(tls_enabled);
+        }
+        conn
+    });
 
     match tls_cfg {
         Some(cfg) => {
@@ -265,17 +1328,69 @@ pub async fn serve(
             tonic::transport::Server::builder()
                 .tls_config(tls)?
                 .add_service(grpc_svc)
-                .serve(addr)
+                .serve_with_incoming(incoming)
                 .await?;
         }
         None => {
             info!(%addr, "gRPC server listening (plaintext h2c)");
             tonic::transport::Server::builder()
                 .add_service(grpc_svc)
-                .serve(addr)
+                .serve_with_incoming(incoming)
                 .await?;
         }
     }
 
     Ok(())
 }
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::codec::Codecs;
+    use std::collections::HashMap;
+
+    fn service() -> HermitService {
+        HermitService {
+            state: Arc::new(ServerState {
+                version: String::new(),
+                region: String::new(),
+                started_at: SystemTime::now(),
+                start_instant: Instant::now(),
+                grpc_port: 0,
+            }),
+            tls_enabled: false,
+            db: Arc::new(Database::new(Codecs::default())),
+            logs: LogBuffer::new(),
+            auth: Arc::new(Auth::open()),
+            metrics: Arc::new(Metrics::new(Duration::from_secs(1), 1024 * 1024)),
+            topics: Topics::new(),
+            limits: Arc::new(Limits::new(Limit::UNLIMITED, Limit::UNLIMITED)),
+            replication: Arc::new(Replication::primary()),
+            s3: None,
+        }
+    }
+
+    /// A request from `user`, a writer limited to `buckets` if given.
+    fn request<T>(msg: T, user: &str, buckets: Option<&[&str]>) -> Request<T> {
+        let mut req = Request::new(msg);
+        req.extensions_mut().insert(Caller {
+            user: user.to_string(),
+            role: Role::Write,
+            buckets: buckets.map(|b| Arc::new(b.iter().map(|b| (b.to_string(), Role::Write)).collect::<HashMap<_, _>>())),
+        });
+        req
+    }
+
+    #[tokio::test]
+    async fn scoped_users_cant_delete_rows() {
+        let svc = service();
+        svc.db.sql_insert("k".to_string(), "v".to_string()).unwrap();
+
+        let del = || SqlDeleteRequest { key: "k".to_string() };
+        let err = svc.sql_delete(request(del(), "orders-app", Some(&["orders"]))).await.unwrap_err();
+        assert_eq!(err.code(), tonic::Code::PermissionDenied, "{}", err.message());
+
+        let resp = svc.sql_delete(request(del(), "ops", None)).await.unwrap().into_inner();
+        assert_eq!(resp.rows, 1, "the scoped delete removed the row: {:?}", resp);
+    }
+}
@@ -0,0 +1,243 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+//! Per-client rate limits: a token bucket per client and class of call.
+//!
+//! A client is the caller's user name, or its IP address when hermit runs
+//! without users. Each class has a default limit every client gets its own
+//! bucket of, and admins can give single clients a limit of their own. A
+//! limit of 0 per second is no limit.
+
+use std::collections::HashMap;
+use std::sync::Mutex;
+use std::time::{Duration, Instant};
+
+/// Buckets kept before full ones are dropped; a full bucket is the same as
+/// a new one.
+const MAX_BUCKETS: usize = 4096;
+
+/// The kinds of call that are limited.
+#[derive(Clone, Copy, Debug, PartialEq, Eq, Hash, PartialOrd, Ord)]
+pub enum Class {
+    /// Calls that change keys, rows or topics.
+    Write,
+    /// Benchmark and BenchmarkStream.
+    Bench,
+}
+
+impl Class {
+    pub const ALL: [Class; 2] = [Class::Write, Class::Bench];
+
+    pub fn parse(s: &str) -> Option<Class> {
+        match s {
+            "write" => Some(Class::Write),
+            "bench" => Some(Class::Bench),
+            _ => None,
+        }
+    }
+
+    pub fn as_str(self) -> &'static str {
+        match self {
+            Class::Write => "write",
+            Class::Bench => "bench",
+        }
+    }
+}
+
+#[derive(Clone, Copy, Debug, PartialEq)]
+pub struct Limit {
+    /// Calls a second a client can keep up; 0 is unlimited.
+    pub per_second: f64,
+    /// Calls a client can make at once after being idle.
+    pub burst: u32,
+}
+
+impl Limit {
+    pub const UNLIMITED: Limit = Limit {
+        per_second: 0.0,
+        burst: 0,
+    };
+
+    /// A limit of `per_second`, allowing bursts of `burst` calls; a burst
+    /// of 0 is one second's worth.
+    pub fn new(per_second: f64, burst: u32) -> Result<Limit, String> {
+        if !per_second.is_finite() || per_second < 0.0 {
+            return Err(format!(
+                "rate {} is not a number of calls a second",
+                per_second
+            ));
+        }
+        if per_second == 0.0 {
+            return Ok(Limit::UNLIMITED);
+        }
+        let burst = if burst == 0 {
+            per_second.ceil().min(u32::MAX as f64) as u32
+        } else {
+            burst
+        };
+        Ok(Limit { per_second, burst })
+    }
+
+    pub fn is_unlimited(&self) -> bool {
+        self.per_second == 0.0
+    }
+}
+
+/// Where a client stands after a call.
+#[derive(Clone, Debug)]
+pub struct Quota {
+    pub class: Class,
+    pub limit: Limit,
+    /// Calls the client can make right away.
+    pub remaining: u32,
+    /// Set when the call was refused: how long until the next one would
+    /// be allowed.
+    pub retry_after: Option<Duration>,
+}
+
+struct Bucket {
+    tokens: f64,
+    at: Instant,
+}
+
+impl Bucket {
+    /// Adds what `limit` has refilled since the bucket was last used.
+    fn refill(&mut self, limit: Limit, now: Instant) {
+        let elapsed = now.saturating_duration_since(self.at).as_secs_f64();
+        self.tokens = (self.tokens + elapsed * limit.per_second).min(limit.burst as f64);
+        self.at = now;
+    }
+}
+
+struct State {
+    defaults: HashMap<Class, Limit>,
+    overrides: HashMap<(Class, String), Limit>,
+    buckets: HashMap<(Class, String), Bucket>,
+}
+
+impl State {
+    fn limit(&self, class: Class, client: &str) -> Limit {
+        self.overrides
+            .get(&(class, client.to_string()))
+            .or_else(|| self.defaults.get(&class))
+            .copied()
+            .unwrap_or(Limit::UNLIMITED)
+    }
+
+    /// Drops buckets that have refilled, if there are too many.
+    fn prune(&mut self, now: Instant) {
+        if self.buckets.len() < MAX_BUCKETS {
+            return;
+        }
+        let limits: HashMap<(Class, String), Limit> = self
+            .buckets
+            .keys()
+            .map(|k| (k.clone(), self.limit(k.0, &k.1)))
+            .collect();
+        self.buckets.retain(|k, b| {
+            let limit = limits[k];
+            b.refill(limit, now);
+            !limit.is_unlimited() && b.tokens < limit.burst as f64
+        });
+    }
+}
+
+pub struct Limits {
+    state: Mutex<State>,
+}
+
+impl Limits {
+    pub fn new(write: Limit, bench: Limit) -> Self {
+        Limits {
+            state: Mutex::new(State {
+                defaults: HashMap::from([(Class::Write, write), (Class::Bench, bench)]),
+                overrides: HashMap::new(),
+                buckets: HashMap::new(),
+            }),
+        }
+    }
+
+    /// Takes one call from the client's bucket. Ok(None) means the class
+    /// is unlimited for the client; Err means it has to wait.
+    pub fn take(&self, class: Class, client: &str) -> Result<Option<Quota>, Quota> {
+        let now = Instant::now();
+        // A poisoned lock only means a panic elsewhere; don't refuse calls.
+        let Ok(mut state) = self.state.lock() else {
+            return Ok(None);
+        };
+        let limit = state.limit(class, client);
+        if limit.is_unlimited() {
+            return Ok(None);
+        }
+        state.prune(now);
+        let b = state
+            .buckets
+            .entry((class, client.to_string()))
+            .or_insert(Bucket {
+                tokens: limit.burst as f64,
+                at: now,
+            });
+        b.refill(limit, now);
+        let mut quota = Quota {
+            class,
+            limit,
+            remaining: 0,
+            retry_after: None,
+        };
+        if b.tokens < 1.0 {
+            quota.retry_after = Some(Duration::from_secs_f64((1.0 - b.tokens) / limit.per_second));
+            return Err(quota);
+        }
+        b.tokens -= 1.0;
+        quota.remaining = b.tokens as u32;
+        Ok(Some(quota))
+    }
+
+    /// Every limit: each class's default (client None), then clients' own.
+    pub fn list(&self) -> Vec<(Class, Option<String>, Limit)> {
+        let Ok(state) = self.state.lock() else {
+            return Vec::new();
+        };
+        let mut out: Vec<_> = Class::ALL
+            .iter()
+            .map(|&c| {
+                (
+                    c,
+                    None,
+                    state.defaults.get(&c).copied().unwrap_or(Limit::UNLIMITED),
+                )
+            })
+            .collect();
+        let mut own: Vec<_> = state
+            .overrides
+            .iter()
+            .map(|((c, client), l)| (*c, Some(client.clone()), *l))
+            .collect();
+        own.sort_by(|a, b| (a.0, &a.1).cmp(&(b.0, &b.1)));
+        out.extend(own);
+        out
+    }
+
+    /// Sets a class's default limit (client None) or a client's own.
+    pub fn set(&self, class: Class, client: Option<&str>, limit: Limit) -> Result<(), String> {
+        let mut state = self.state.lock().map_err(|e| e.to_string())?;
+        match client {
+            None => {
+                state.defaults.insert(class, limit);
+            }
+            Some(c) => {
+                state.overrides.insert((class, c.to_string()), limit);
+            }
+        }
+        Ok(())
+    }
+
+    /// Puts a client back on the class's default limit, returning whether
+    /// it had one of its own.
+    pub fn remove(&self, class: Class, client: &str) -> Result<bool, String> {
+        let mut state = self.state.lock().map_err(|e| e.to_string())?;
+        Ok(state
+            .overrides
+            .remove(&(class, client.to_string()))
+            .is_some())
+    }
+}
@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jared Redh. All rights reserved.
+
+use std::collections::VecDeque;
+use std::fmt::Write;
+use std::sync::{Arc, Mutex};
+use std::time::SystemTime;
+
+use tokio::sync::broadcast;
+use tracing::field::{Field, Visit};
+use tracing::{Event, Level, Subscriber};
+use tracing_subscriber::layer::{Context, Layer};
+
+/// Lines kept for TailLogs backlogs.
+pub const LOG_CAPACITY: usize = 1000;
+
+#[derive(Clone)]
+pub struct LogRecord {
+    pub time: SystemTime,
+    pub level: Level,
+    pub target: String,
+    pub message: String,
+}
+
+/// Ring buffer of recent log lines, plus a broadcast of new ones for
+/// TailLogs followers.
+pub struct LogBuffer {
+    recent: Mutex<VecDeque<LogRecord>>,
+    tx: broadcast::Sender<LogRecord>,
+}
+
+impl LogBuffer {
+    pub fn new() -> Arc<Self> {
+        let (tx, _) = broadcast::channel(256);
+        Arc::new(LogBuffer {
+            recent: Mutex::new(VecDeque::with_capacity(LOG_CAPACITY)),
+            tx,
+        })
+    }
+
+    fn push(&self, rec: LogRecord) {
+        // Send under the lock so subscribe() never misses or repeats a line.
+        let mut recent = self.recent.lock().unwrap_or_else(|e| e.into_inner());
+        if recent.len() == LOG_CAPACITY {
+            recent.pop_front();
+        }
+        recent.push_back(rec.clone());
+        let _ = self.tx.send(rec); // no followers is fine
+    }
+
+    /// Returns the last `backlog` lines and a receiver for the lines after
+    /// them.
+    pub fn subscribe(&self, backlog: usize) -> (Vec<LogRecord>, broadcast::Receiver<LogRecord>) {
+        let recent = self.recent.lock().unwrap_or_else(|e| e.into_inner());
+        let rx = self.tx.subscribe();
+        let skip = recent.len().saturating_sub(backlog);
+        (recent.iter().skip(skip).cloned().collect(), rx)
+    }
+}
+
+/// Tracing layer that copies every event into a LogBuffer.
+pub struct LogLayer(pub Arc<LogBuffer>);
+
+impl<S: Subscriber> Layer<S> for LogLayer {
+    fn on_event(&self, event: &Event<'_>, _ctx: Context<'_, S>) {
+        let mut msg = MessageVisitor::default();
+        event.record(&mut msg);
+        let meta = event.metadata();
+        self.0.push(LogRecord {
+            time: SystemTime::now(),
+            level: *meta.level(),
+            target: meta.target().to_string(),
+            message: msg.finish(),
+        });
+    }
+}
+
+/// Collects an event's message and its other fields as key=value.
+#[derive(Default)]
+struct MessageVisitor {
+    message: String,
+    fields: String,
+}
+
+impl MessageVisitor {
+    fn finish(self) -> String {
+        match (self.message.is_empty(), self.fields.is_empty()) {
+            (_, true) => self.message,
+            (true, false) => self.fields,
+            (false, false) => format!("{} {}", self.message, self.fields),
+        }
+    }
+}
+
+impl Visit for MessageVisitor {
+    fn record_str(&mut self, field: &Field, value: &str) {
+        if field.name() == "message" {
+            self.message = value.to_string();
+        } else {
+            self.record_debug(field, &value);
+        }
+    }
+
+    fn record_debug(&mut self, field: &Field, value: &dyn std::fmt::Debug) {
+        if field.name() == "message" {
+            let _ = write!(self.message, "{:?}", value);
+            return;
+        }
+        if !self.fields.is_empty() {
+            self.fields.push(' ');
+        }
+        let _ = write!(self.fields, "{}={:?}", field.name(), value);
+    }
+}
@@ -6,14 +6,24 @@ pub mod hermit {
 }
 
 mod auth;
+mod backup;
 mod bench;
+mod codec;
 mod db;
 mod grpc;
+mod limits;
+mod logs;
+mod metrics;
+mod pubsub;
+mod replica;
+mod tcp;
 mod tls;
+mod wal;
 
 use clap::Parser;
 use std::sync::Arc;
-use tracing::{info, error};
+use tracing::{info, error, warn};
+use tracing_subscriber::prelude::*;
 
 #[derive(Parser, Debug)]
 #[command(name = "hermit-server", version, about = "Hermit high-performance server")]
@@ -22,6 +32,15 @@ struct Args {
     #[arg(long, default_value_t = 9090)]
     grpc_port: u16,
 
+    /// Port for the framed TCP protocol; 0 turns it off
+    #[arg(long, default_value_t = 9091)]
+    tcp_port: u16,
+
+    /// Port for the framed TCP protocol over TLS; 0 turns it off, as
+    /// --no-tls does
+    #[arg(long, default_value_t = 9093)]
+    tcp_tls_port: u16,
+
     /// Region identifier for ServerInfo
     #[arg(long, default_value = "us-west1")]
     region: String,
@@ -37,15 +56,97 @@ struct Args {
     /// Disable TLS (serve plaintext h2c). Required for Cloud Run.
     #[arg(long, default_value_t = false)]
     no_tls: bool,
+
+    /// Snapshot file: restored at startup if it exists, then rewritten
+    /// every --snapshot-every seconds. Unset keeps data in memory only.
+    #[arg(long)]
+    snapshot_path: Option<std::path::PathBuf>,
+
+    /// Seconds between snapshots to --snapshot-path
+    #[arg(long, default_value_t = 60)]
+    snapshot_every: u64,
+
+    /// Write-ahead log for the relational store: replayed at startup, then
+    /// appended to on every insert. Unset keeps rows in memory only.
+    #[arg(long)]
+    wal_path: Option<std::path::PathBuf>,
+
+    /// Milliseconds between syncs of --wal-path to disk
+    #[arg(long, default_value_t = 200)]
+    wal_sync_ms: u64,
+
+    /// Codec document store values are compressed with: zstd, lz4 or none
+    #[arg(long, default_value = "zstd")]
+    doc_codec: String,
+
+    /// A codec for one namespace, the part of a key before its first ':',
+    /// in place of --doc-codec; repeatable, e.g. --ns-codec session=lz4
+    #[arg(long, value_name = "NAMESPACE=CODEC")]
+    ns_codec: Vec<String>,
+
+    /// Users file, one `name role token-sha256 [buckets]` per line, role
+    /// being read, write or admin; buckets limit a user to those buckets.
+    /// Unset lets any login in as an admin.
+    #[arg(long)]
+    users: Option<std::path::PathBuf>,
+
+    /// Calls taking this many milliseconds or more are logged and kept for
+    /// the Diagnostics RPC; 0 keeps none
+    #[arg(long, default_value_t = 100)]
+    slow_ms: u64,
+
+    /// Writes of values this many KiB or larger are logged; 0 logs none
+    #[arg(long, default_value_t = 1024)]
+    large_value_kb: usize,
+
+    /// Port for a plain-HTTP Prometheus /metrics listener. Unset serves
+    /// metrics only through the Metrics RPC.
+    #[arg(long)]
+    metrics_port: Option<u16>,
+
+    /// Writes a second each client may make; 0 is unlimited. Admins can
+    /// change it, and give clients their own, with the SetLimit RPC.
+    #[arg(long, default_value_t = 0.0)]
+    write_rate: f64,
+
+    /// Writes a client may make at once; 0 is one second's worth
+    #[arg(long, default_value_t = 0)]
+    write_burst: u32,
+
+    /// Benchmarks a second each client may run; 0 is unlimited
+    #[arg(long, default_value_t = 0.0)]
+    bench_rate: f64,
+
+    /// Benchmarks a client may run at once; 0 is one second's worth
+    #[arg(long, default_value_t = 0)]
+    bench_burst: u32,
+
+    /// Run as a read-only follower of this primary (http:// or https://
+    /// URL), streaming its document store. Unset runs as a primary.
+    #[arg(long)]
+    follow: Option<String>,
+
+    /// PEM CA to verify an https:// --follow primary with
+    #[arg(long)]
+    follow_ca: Option<std::path::PathBuf>,
+
+    /// User to log in to the --follow primary as; it needs the admin role.
+    /// The token is read from HERMIT_FOLLOW_TOKEN.
+    #[arg(long, default_value = "replica")]
+    follow_user: String,
 }
 
 #[tokio::main]
 async fn main() -> Result<(), Box<dyn std::error::Error>> {
-    tracing_subscriber::fmt()
-        .with_env_filter(
+    // Everything logged goes to stderr and to the buffer TailLogs serves.
+    let log_buffer = logs::LogBuffer::new();
+    tracing_subscriber::registry()
+        .with(
             tracing_subscriber::EnvFilter::try_from_default_env()
                 .unwrap_or_else(|_| "hermit_server=info,tower=warn".into()),
         )
+        .with(tracing_subscriber::fmt::layer())
+        .with(logs::LogLayer(log_buffer.clone()))
         .init();
 
     let args = Args::parse();
@@ -79,10 +180,125 @@ async fn main() -> Result<(), Box<dyn std::error::Error>> {
         "hermit-server starting"
     );
 
-    let database = Arc::new(db::Database::new());
+    let auth = match &args.users {
+        Some(path) => {
+            let auth = auth::Auth::load(path).map_err(|e| format!("users {}: {}", path.display(), e))?;
+            info!(path = %path.display(), users = auth.user_count(), "loaded users");
+            auth
+        }
+        None => {
+            warn!("no --users file; every login is an admin");
+            auth::Auth::open()
+        }
+    };
+
+    let codecs = codec::Codecs::parse(&args.doc_codec, &args.ns_codec).map_err(|e| format!("codecs: {}", e))?;
+    let database = Arc::new(db::Database::new(codecs));
+    if let Some(path) = &args.snapshot_path {
+        match db::load_snapshot(&database, path) {
+            Ok(Some((keys, rows))) => info!(path = %path.display(), keys, rows, "restored snapshot"),
+            Ok(None) => info!(path = %path.display(), "no snapshot yet; starting empty"),
+            // Don't start empty and then overwrite the file.
+            Err(e) => return Err(format!("snapshot {}: {}", path.display(), e).into()),
+        }
+        let every = std::time::Duration::from_secs(args.snapshot_every.max(1));
+        tokio::spawn(db::run_snapshotter(database.clone(), path.clone(), every));
+    }
+    // After the snapshot: the WAL has the newer rows.
+    if let Some(path) = &args.wal_path {
+        match database.open_wal(path) {
+            Ok((rows, 0)) => info!(path = %path.display(), rows, "opened WAL"),
+            Ok((rows, torn)) => warn!(path = %path.display(), rows, torn, "opened WAL; dropped a torn final record"),
+            Err(e) => return Err(format!("WAL {}: {}", path.display(), e).into()),
+        }
+        let every = std::time::Duration::from_millis(args.wal_sync_ms.max(1));
+        tokio::spawn(db::run_wal_syncer(database.clone(), every));
+    }
+    tokio::spawn(db::run_expirer(database.clone(), std::time::Duration::from_secs(1)));
+
+    let server_metrics = Arc::new(metrics::Metrics::new(
+        std::time::Duration::from_millis(args.slow_ms),
+        args.large_value_kb * 1024,
+    ));
+    if let Some(port) = args.metrics_port {
+        let (m, db) = (server_metrics.clone(), database.clone());
+        tokio::spawn(async move {
+            if let Err(e) = metrics::serve_http(port, m, db, start_time).await {
+                error!("metrics listener: {}", e);
+            }
+        });
+    }
+
+    let write_limit = limits::Limit::new(args.write_rate, args.write_burst).map_err(|e| format!("--write-rate: {}", e))?;
+    let bench_limit = limits::Limit::new(args.bench_rate, args.bench_burst).map_err(|e| format!("--bench-rate: {}", e))?;
+    let rate_limits = Arc::new(limits::Limits::new(write_limit, bench_limit));
+
+    let replication = match &args.follow {
+        Some(url) => {
+            let ca_pem = match &args.follow_ca {
+                Some(path) => Some(std::fs::read(path).map_err(|e| format!("--follow-ca {}: {}", path.display(), e))?),
+                None if url.starts_with("https://") => return Err("--follow with https:// needs --follow-ca".into()),
+                None => None,
+            };
+            let cfg = replica::FollowConfig {
+                url: url.clone(),
+                ca_pem,
+                user: args.follow_user.clone(),
+                token: std::env::var("HERMIT_FOLLOW_TOKEN").unwrap_or_default(),
+                // A primary and its followers share one secret.
+                secret: std::env::var("HERMIT_SECRET").ok().filter(|s| !s.is_empty()),
+                name: format!("{}/{}", args.region, std::env::var("HOSTNAME").unwrap_or_else(|_| "hermit".to_string())),
+            };
+            info!(primary = %url, "following; writes are refused");
+            let repl = Arc::new(replica::Replication::follower(url));
+            tokio::spawn(replica::run_follower(database.clone(), repl.clone(), cfg));
+            repl
+        }
+        None => Arc::new(replica::Replication::primary()),
+    };
+
+    let s3 = backup::S3::from_env().map_err(|e| format!("S3 backups: {}", e))?.map(Arc::new);
+    if let Some(s3) = &s3 {
+        info!(url = %s3.url(), every = ?s3.every, keep = s3.keep, "backing up to S3");
+        tokio::spawn(backup::run_backups(database.clone(), s3.clone()));
+    }
+
+    let auth = Arc::new(auth);
+    let tcp_shared = Arc::new(tcp::Shared {
+        db: database.clone(),
+        auth: auth.clone(),
+        metrics: server_metrics.clone(),
+        limits: rate_limits.clone(),
+        replication: replication.clone(),
+    });
+    let mut tcp_listeners = vec![(args.tcp_port, None)];
+    if let Some(cfg) = &tls_cfg {
+        tcp_listeners.push((args.tcp_tls_port, Some(cfg.clone())));
+    }
+    for (port, tls) in tcp_listeners.into_iter().filter(|&(port, _)| port != 0) {
+        let shared = tcp_shared.clone();
+        tokio::spawn(async move {
+            if let Err(e) = tcp::serve(port, tls, shared).await {
+                error!(port, "TCP protocol listener: {}", e);
+            }
+        });
+    }
 
-    // Run gRPC server (only listener for Cloud Run single-port)
-    if let Err(e) = grpc::serve(args.grpc_port, server_state, tls_cfg, database).await {
+    // Run gRPC server (the only listener Cloud Run routes to)
+    if let Err(e) = grpc::serve(
+        args.grpc_port,
+        server_state,
+        tls_cfg,
+        database,
+        log_buffer,
+        auth,
+        server_metrics,
+        rate_limits,
+        replication,
+        s3,
+    )
+    .await
+    {
         error!("gRPC server exited with error: {:?}", e);
     }
 
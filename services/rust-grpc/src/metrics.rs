@@ -0,0 +1,332 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+//! Server metrics: per-RPC call counts and latency histograms, connection
+//! counts, and the stores' sizes, served by the Metrics RPC and, in the
+//! Prometheus text format, by an optional HTTP listener. Calls slower than
+//! --slow-ms are also logged and kept for the Diagnostics RPC, and writes
+//! of values over --large-value-kb logged.
+
+use std::collections::{BTreeMap, VecDeque};
+use std::fmt::Write as _;
+use std::sync::atomic::{AtomicU64, Ordering};
+use std::sync::Mutex;
+use std::time::{Duration, Instant, SystemTime};
+
+use tokio::io::{AsyncReadExt, AsyncWriteExt};
+use tokio::net::{TcpListener, TcpStream};
+use tracing::{debug, info, warn};
+
+use crate::db::{Database, GcStats};
+
+/// Upper bounds of the latency histogram's buckets, in microseconds. A
+/// last, unbounded bucket catches the rest.
+pub const LATENCY_BOUNDS_US: [u64; 12] = [
+    100, 250, 500, 1_000, 2_500, 5_000, 10_000, 25_000, 100_000, 250_000, 1_000_000, 5_000_000,
+];
+
+/// Bytes of request the HTTP listener reads before giving up on it.
+const HTTP_MAX_REQUEST: usize = 8 * 1024;
+
+const HTTP_TIMEOUT: Duration = Duration::from_secs(5);
+
+/// Slow calls kept for Diagnostics; older ones are forgotten.
+const SLOW_CALLS_KEPT: usize = 100;
+
+#[derive(Clone, Default)]
+pub struct RpcStats {
+    pub calls: u64,
+    pub total_us: u64,
+    pub max_us: u64,
+    /// Calls per latency bucket: one per `LATENCY_BOUNDS_US`, then the rest.
+    pub buckets: [u64; LATENCY_BOUNDS_US.len() + 1],
+}
+
+/// A call that took at least the slow threshold.
+#[derive(Clone)]
+pub struct SlowCall {
+    pub method: &'static str,
+    pub took: Duration,
+    pub at: SystemTime,
+    /// The key it was for, if it was for one.
+    pub bucket: String,
+    pub key: String,
+}
+
+pub struct Metrics {
+    rpcs: Mutex<BTreeMap<&'static str, RpcStats>>,
+    connections: AtomicU64,
+    tls_handshakes: AtomicU64,
+    rate_limited: AtomicU64,
+    slow: Duration, // zero keeps no slow calls
+    slow_calls: Mutex<VecDeque<SlowCall>>,
+    large_value: usize, // zero logs no writes
+}
+
+/// Times one call; the time is recorded when it is dropped. For streaming
+/// RPCs that is when the stream is set up, not when it ends.
+pub struct RpcTimer<'a> {
+    metrics: &'a Metrics,
+    method: &'static str,
+    start: Instant,
+    about: Option<(String, String)>,
+}
+
+impl RpcTimer<'_> {
+    /// Names the key the call is for, should it turn out slow. Call it once
+    /// the work is done: it copies the key only if the call is slow by then.
+    pub fn about(&mut self, bucket: &str, key: &str) {
+        if self.metrics.is_slow(self.start.elapsed()) {
+            self.about = Some((bucket.to_string(), key.to_string()));
+        }
+    }
+}
+
+impl Drop for RpcTimer<'_> {
+    fn drop(&mut self) {
+        self.metrics.record(self.method, self.start.elapsed(), self.about.take());
+    }
+}
+
+impl Metrics {
+    /// Keeps calls that take at least `slow` and logs writes of values of
+    /// `large_value` bytes or more; zero turns either off.
+    pub fn new(slow: Duration, large_value: usize) -> Self {
+        Metrics {
+            rpcs: Mutex::new(BTreeMap::new()),
+            connections: AtomicU64::new(0),
+            tls_handshakes: AtomicU64::new(0),
+            rate_limited: AtomicU64::new(0),
+            slow,
+            slow_calls: Mutex::new(VecDeque::new()),
+            large_value,
+        }
+    }
+
+    /// Starts timing a call to `method`.
+    pub fn time(&self, method: &'static str) -> RpcTimer<'_> {
+        RpcTimer {
+            metrics: self,
+            method,
+            start: Instant::now(),
+            about: None,
+        }
+    }
+
+    fn is_slow(&self, took: Duration) -> bool {
+        !self.slow.is_zero() && took >= self.slow
+    }
+
+    fn record(&self, method: &'static str, took: Duration, about: Option<(String, String)>) {
+        let us = took.as_micros() as u64;
+        {
+            let Ok(mut rpcs) = self.rpcs.lock() else { return };
+            let s = rpcs.entry(method).or_default();
+            s.calls += 1;
+            s.total_us += us;
+            s.max_us = s.max_us.max(us);
+            let i = LATENCY_BOUNDS_US.iter().position(|&b| us <= b).unwrap_or(LATENCY_BOUNDS_US.len());
+            s.buckets[i] += 1;
+        }
+        if !self.is_slow(took) {
+            return;
+        }
+        let (bucket, key) = about.unwrap_or_default();
+        warn!(method, took_ms = us / 1000, bucket = %bucket, key = %key, "slow call");
+        let Ok(mut slow) = self.slow_calls.lock() else { return };
+        if slow.len() == SLOW_CALLS_KEPT {
+            slow.pop_front();
+        }
+        slow.push_back(SlowCall {
+            method,
+            took,
+            at: SystemTime::now(),
+            bucket,
+            key,
+        });
+    }
+
+    /// Logs a write of `bytes` to `key` if that is a large value.
+    pub fn value_written(&self, bucket: &str, key: &str, bytes: usize) {
+        if self.large_value > 0 && bytes >= self.large_value {
+            warn!(bucket, key, bytes, "large value");
+        }
+    }
+
+    /// The size values are logged from.
+    pub fn large_value(&self) -> usize {
+        self.large_value
+    }
+
+    /// The threshold calls are kept over, and up to `limit` of the kept
+    /// ones, slowest first.
+    pub fn slow_calls(&self, limit: usize) -> (Duration, Vec<SlowCall>) {
+        let mut calls: Vec<_> = self
+            .slow_calls
+            .lock()
+            .map(|s| s.iter().cloned().collect())
+            .unwrap_or_default();
+        calls.sort_by(|a, b| b.took.cmp(&a.took));
+        calls.truncate(limit);
+        (self.slow, calls)
+    }
+
+    /// Counts an accepted connection. With TLS on, each one starts a
+    /// handshake.
+    pub fn connection(&self, tls: bool) {
+        self.connections.fetch_add(1, Ordering::Relaxed);
+        if tls {
+            self.tls_handshakes.fetch_add(1, Ordering::Relaxed);
+        }
+    }
+
+    /// Counts a call refused by a rate limit.
+    pub fn rate_limited(&self) {
+        self.rate_limited.fetch_add(1, Ordering::Relaxed);
+    }
+}
+
+/// Everything the Metrics RPC and /metrics report, read at one moment.
+pub struct Sample {
+    pub rpcs: Vec<(&'static str, RpcStats)>,
+    pub doc_keys: u64,
+    pub doc_bytes: u64,
+    pub rel_rows: u64,
+    pub rel_pending: u64,
+    pub watchers: u64,
+    pub gc: GcStats,
+    pub connections: u64,
+    pub tls_handshakes: u64,
+    pub rate_limited: u64,
+    pub rss_bytes: u64,
+    pub uptime: Duration,
+}
+
+pub fn sample(metrics: &Metrics, db: &Database, started: Instant) -> Result<Sample, String> {
+    let rpcs = metrics
+        .rpcs
+        .lock()
+        .map_err(|e| e.to_string())?
+        .iter()
+        .map(|(m, s)| (*m, s.clone()))
+        .collect();
+    let (doc_keys, doc_bytes) = db.kv_stats()?;
+    let (rel_rows, rel_pending) = db.rel_stats()?;
+    Ok(Sample {
+        rpcs,
+        doc_keys,
+        doc_bytes,
+        rel_rows,
+        rel_pending,
+        watchers: db.watchers(),
+        gc: db.gc_stats(),
+        connections: metrics.connections.load(Ordering::Relaxed),
+        tls_handshakes: metrics.tls_handshakes.load(Ordering::Relaxed),
+        rate_limited: metrics.rate_limited.load(Ordering::Relaxed),
+        rss_bytes: rss_bytes(),
+        uptime: started.elapsed(),
+    })
+}
+
+/// The process's resident memory, from /proc; 0 where there is none.
+fn rss_bytes() -> u64 {
+    let Ok(status) = std::fs::read_to_string("/proc/self/status") else {
+        return 0;
+    };
+    status
+        .lines()
+        .find_map(|l| l.strip_prefix("VmRSS:"))
+        .and_then(|v| v.trim().trim_end_matches("kB").trim().parse::<u64>().ok())
+        .map_or(0, |kb| kb * 1024)
+}
+
+/// Renders a sample in the Prometheus text exposition format.
+pub fn render(s: &Sample) -> String {
+    let mut out = String::new();
+    out.push_str("# HELP hermit_rpc_duration_seconds Time to handle an RPC.\n");
+    out.push_str("# TYPE hermit_rpc_duration_seconds histogram\n");
+    for (method, r) in &s.rpcs {
+        let mut cum = 0;
+        for (i, n) in r.buckets.iter().enumerate() {
+            cum += n;
+            let le = match LATENCY_BOUNDS_US.get(i) {
+                Some(&us) => (us as f64 / 1e6).to_string(),
+                None => "+Inf".to_string(),
+            };
+            let _ = writeln!(out, "hermit_rpc_duration_seconds_bucket{{method=\"{}\",le=\"{}\"}} {}", method, le, cum);
+        }
+        let _ = writeln!(out, "hermit_rpc_duration_seconds_sum{{method=\"{}\"}} {}", method, r.total_us as f64 / 1e6);
+        let _ = writeln!(out, "hermit_rpc_duration_seconds_count{{method=\"{}\"}} {}", method, r.calls);
+    }
+    let mut metric = |name: &str, kind: &str, help: &str, v: f64| {
+        let _ = write!(out, "# HELP {name} {help}\n# TYPE {name} {kind}\n{name} {v}\n");
+    };
+    metric("hermit_doc_keys", "gauge", "Live keys in the document store.", s.doc_keys as f64);
+    metric("hermit_doc_bytes", "gauge", "Bytes the document store's values take, compressed.", s.doc_bytes as f64);
+    metric("hermit_rel_rows", "gauge", "Committed rows in the relational store.", s.rel_rows as f64);
+    metric("hermit_rel_pending_writes", "gauge", "Rows queued for the next relational commit.", s.rel_pending as f64);
+    metric("hermit_watchers", "gauge", "Open Watch streams.", s.watchers as f64);
+    metric("hermit_expire_sweeps_total", "counter", "Runs of the TTL expirer.", s.gc.sweeps as f64);
+    metric("hermit_expired_keys_total", "counter", "Keys the TTL expirer dropped.", s.gc.expired as f64);
+    metric("hermit_expire_last_sweep_seconds", "gauge", "How long the last expirer run took.", s.gc.last_sweep_us as f64 / 1e6);
+    metric("hermit_connections_total", "counter", "Connections accepted.", s.connections as f64);
+    metric("hermit_tls_handshakes_total", "counter", "TLS handshakes started.", s.tls_handshakes as f64);
+    metric("hermit_rate_limited_total", "counter", "Calls refused by rate limits.", s.rate_limited as f64);
+    metric("process_resident_memory_bytes", "gauge", "Resident memory size in bytes.", s.rss_bytes as f64);
+    metric("hermit_uptime_seconds", "gauge", "Seconds since hermit started.", s.uptime.as_secs_f64());
+    out
+}
+
+/// Serves GET /metrics on `port` until the process exits.
+pub async fn serve_http(
+    port: u16,
+    metrics: std::sync::Arc<Metrics>,
+    db: std::sync::Arc<Database>,
+    started: Instant,
+) -> std::io::Result<()> {
+    let listener = TcpListener::bind(("0.0.0.0", port)).await?;
+    info!(port, "metrics listening on /metrics");
+    loop {
+        let (conn, peer) = listener.accept().await?;
+        let (metrics, db) = (metrics.clone(), db.clone());
+        tokio::spawn(async move {
+            match tokio::time::timeout(HTTP_TIMEOUT, handle_http(conn, &metrics, &db, started)).await {
+                Ok(Ok(())) => {}
+                Ok(Err(e)) => debug!(%peer, "metrics request: {}", e),
+                Err(_) => debug!(%peer, "metrics request timed out"),
+            }
+        });
+    }
+}
+
+async fn handle_http(mut conn: TcpStream, metrics: &Metrics, db: &Database, started: Instant) -> std::io::Result<()> {
+    let mut req = Vec::new();
+    let mut buf = [0u8; 1024];
+    while !req.windows(4).any(|w| w == b"\r\n\r\n") {
+        let n = conn.read(&mut buf).await?;
+        if n == 0 || req.len() + n > HTTP_MAX_REQUEST {
+            return Ok(());
+        }
+        req.extend_from_slice(&buf[..n]);
+    }
+    let line = req.split(|&b| b == b'\r').next().unwrap_or_default();
+    let mut parts = line.split(|&b| b == b' ');
+    let (method, path) = (parts.next().unwrap_or_default(), parts.next().unwrap_or_default());
+    let path = path.split(|&b| b == b'?').next().unwrap_or_default();
+
+    let (status, body) = match (method, path) {
+        (b"GET", b"/metrics") => match sample(metrics, db, started) {
+            Ok(s) => ("200 OK", render(&s)),
+            Err(e) => ("500 Internal Server Error", e + "\n"),
+        },
+        (_, b"/metrics") => ("405 Method Not Allowed", "GET only\n".to_string()),
+        _ => ("404 Not Found", "try /metrics\n".to_string()),
+    };
+    let head = format!(
+        "HTTP/1.1 {}\r\nContent-Type: text/plain; version=0.0.4\r\nContent-Length: {}\r\nConnection: close\r\n\r\n",
+        status,
+        body.len()
+    );
+    conn.write_all(head.as_bytes()).await?;
+    conn.write_all(body.as_bytes()).await?;
+    conn.shutdown().await
+}
@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+//! In-memory pub/sub topics. A message goes to whoever is subscribed to its
+//! topic when it is published and is not kept; a topic exists while it has
+//! subscribers.
+
+use std::collections::HashMap;
+use std::sync::Mutex;
+use std::time::SystemTime;
+
+use tokio::sync::broadcast;
+
+/// Messages a subscriber can fall behind by before it misses some.
+const TOPIC_CAPACITY: usize = 256;
+
+/// Longest topic name, in bytes.
+pub const MAX_TOPIC_LEN: usize = 128;
+
+/// Largest message, in bytes.
+pub const MAX_MESSAGE_BYTES: usize = 64 * 1024;
+
+#[derive(Clone, Debug)]
+pub struct Message {
+    pub topic: String,
+    /// The publisher's user name; empty when hermit runs without users.
+    pub from: String,
+    pub data: Vec<u8>,
+    pub time: SystemTime,
+    /// Counts the topic's messages from 1, so subscribers can spot gaps.
+    pub seq: u64,
+}
+
+struct Topic {
+    tx: broadcast::Sender<Message>,
+    seq: u64,
+}
+
+pub struct Topics {
+    topics: Mutex<HashMap<String, Topic>>,
+}
+
+fn check_topic(topic: &str) -> Result<(), String> {
+    if topic.is_empty() {
+        return Err("topic is empty".to_string());
+    }
+    if topic.len() > MAX_TOPIC_LEN {
+        return Err(format!("topic is over {} bytes", MAX_TOPIC_LEN));
+    }
+    Ok(())
+}
+
+impl Topics {
+    pub fn new() -> Self {
+        Topics {
+            topics: Mutex::new(HashMap::new()),
+        }
+    }
+
+    /// Sends a message to the topic's subscribers and returns how many
+    /// there were. Publishing to a topic nobody is subscribed to is not an
+    /// error; the message just goes nowhere.
+    pub fn publish(&self, topic: &str, from: &str, data: Vec<u8>) -> Result<u64, String> {
+        check_topic(topic)?;
+        if data.len() > MAX_MESSAGE_BYTES {
+            return Err(format!("message is over {} bytes", MAX_MESSAGE_BYTES));
+        }
+        let mut topics = self.topics.lock().map_err(|e| e.to_string())?;
+        let Some(t) = topics.get_mut(topic) else {
+            return Ok(0);
+        };
+        if t.tx.receiver_count() == 0 {
+            topics.remove(topic);
+            return Ok(0);
+        }
+        t.seq += 1;
+        let msg = Message {
+            topic: topic.to_string(),
+            from: from.to_string(),
+            data,
+            time: SystemTime::now(),
+            seq: t.seq,
+        };
+        // Err only means the last subscriber left since the check.
+        Ok(t.tx.send(msg).unwrap_or(0) as u64)
+    }
+
+    /// Subscribes to a topic, creating it if need be.
+    pub fn subscribe(&self, topic: &str) -> Result<broadcast::Receiver<Message>, String> {
+        check_topic(topic)?;
+        let mut topics = self.topics.lock().map_err(|e| e.to_string())?;
+        // Drop topics everyone has left while we hold the lock anyway.
+        topics.retain(|name, t| name == topic || t.tx.receiver_count() > 0);
+        let t = topics.entry(topic.to_string()).or_insert_with(|| Topic {
+            tx: broadcast::channel(TOPIC_CAPACITY).0,
+            seq: 0,
+        });
+        Ok(t.tx.subscribe())
+    }
+}
@@ -0,0 +1,314 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+//! Follower replication, the first step toward running hermit highly
+//! available.
+//!
+//! A follower (`--follow <primary>`) calls the primary's Replicate RPC,
+//! which sends a snapshot of both stores and then every document store
+//! change made after it, each numbered with the primary's oplog position
+//! (`Database::oplog_seq`). While nothing changes the primary sends
+//! heartbeats with its position, so the follower knows how far behind it is
+//! and notices a primary that has gone quiet. A follower that loses the
+//! stream, or falls too far behind for the primary to buffer, reconnects
+//! and starts over from a fresh snapshot.
+//!
+//! Followers serve reads and refuse writes. Only the document store is
+//! streamed: a follower's relational store is as of its last snapshot.
+
+use std::collections::HashMap;
+use std::sync::atomic::{AtomicU64, Ordering};
+use std::sync::{Arc, Mutex};
+use std::time::{Duration, Instant, SystemTime};
+
+use tonic::metadata::MetadataValue;
+use tonic::transport::{Certificate, ClientTlsConfig, Endpoint};
+use tonic::Request;
+use tracing::{info, warn};
+
+use crate::auth;
+use crate::db::{self, Database, KvChange};
+use crate::hermit::hermit_client::HermitClient;
+use crate::hermit::{oplog_entry, LoginRequest, ReplicateRequest};
+
+/// How often a primary tells an idle follower where its oplog is.
+pub const HEARTBEAT_EVERY: Duration = Duration::from_secs(1);
+
+/// A follower that hears nothing from its primary for this long gives up on
+/// the stream and reconnects.
+const PRIMARY_TIMEOUT: Duration = Duration::from_secs(5);
+
+/// Longest a follower waits between attempts to reach its primary.
+const MAX_BACKOFF: Duration = Duration::from_secs(30);
+
+/// How a follower reaches its primary.
+pub struct FollowConfig {
+    /// The primary's URL, http:// or https://.
+    pub url: String,
+    /// PEM CA to verify an https:// primary with.
+    pub ca_pem: Option<Vec<u8>>,
+    /// Who to log in as; Replicate needs the admin role.
+    pub user: String,
+    pub token: String,
+    /// Sent as x-hermit-secret when the primary wants one.
+    pub secret: Option<String>,
+    /// How the follower introduces itself in the primary's status.
+    pub name: String,
+}
+
+impl FollowConfig {
+    fn request<T>(&self, msg: T) -> Result<Request<T>, String> {
+        let mut req = Request::new(msg);
+        if let Some(s) = &self.secret {
+            let v = MetadataValue::try_from(s.as_str()).map_err(|e| e.to_string())?;
+            req.metadata_mut().insert("x-hermit-secret", v);
+        }
+        Ok(req)
+    }
+}
+
+/// What a primary knows about one of its followers.
+#[derive(Clone)]
+pub struct FollowerInfo {
+    pub name: String,
+    pub addr: String,
+    /// The last oplog position sent to it.
+    pub sent_seq: u64,
+    pub since: SystemTime,
+}
+
+/// Where a follower stands against its primary.
+#[derive(Clone, Default)]
+pub struct FollowStatus {
+    /// A snapshot has been loaded and the stream is still up.
+    pub connected: bool,
+    /// The primary's oplog position this follower has applied.
+    pub applied_seq: u64,
+    /// The primary's position when it was last heard from.
+    pub primary_seq: u64,
+    pub last_contact: Option<Instant>,
+    /// Snapshots loaded; more than one means the follower resynced.
+    pub resyncs: u64,
+    /// Why the last connection ended.
+    pub error: String,
+}
+
+/// This server's part in replication: a primary keeps track of its
+/// followers, a follower of its primary.
+pub struct Replication {
+    primary: Option<String>, // set on a follower
+    status: Mutex<FollowStatus>,
+    followers: Mutex<HashMap<u64, FollowerInfo>>,
+    next_id: AtomicU64,
+}
+
+impl Replication {
+    pub fn primary() -> Self {
+        Replication {
+            primary: None,
+            status: Mutex::new(FollowStatus::default()),
+            followers: Mutex::new(HashMap::new()),
+            next_id: AtomicU64::new(1),
+        }
+    }
+
+    pub fn follower(primary: &str) -> Self {
+        Replication {
+            primary: Some(primary.to_string()),
+            ..Replication::primary()
+        }
+    }
+
+    /// The primary's URL, if this server is a follower.
+    pub fn following(&self) -> Option<&str> {
+        self.primary.as_deref()
+    }
+
+    pub fn follow_status(&self) -> FollowStatus {
+        self.status.lock().map(|s| s.clone()).unwrap_or_default()
+    }
+
+    fn update(&self, f: impl FnOnce(&mut FollowStatus)) {
+        if let Ok(mut s) = self.status.lock() {
+            f(&mut s);
+        }
+    }
+
+    /// The followers streaming from this server, longest attached first.
+    pub fn followers(&self) -> Vec<FollowerInfo> {
+        let Ok(followers) = self.followers.lock() else {
+            return Vec::new();
+        };
+        let mut out: Vec<_> = followers.values().cloned().collect();
+        out.sort_by_key(|f| f.since);
+        out
+    }
+
+    /// Records a follower until the returned guard is dropped.
+    pub fn attach(self: &Arc<Self>, name: String, addr: String) -> Attached {
+        let id = self.next_id.fetch_add(1, Ordering::Relaxed);
+        if let Ok(mut followers) = self.followers.lock() {
+            followers.insert(
+                id,
+                FollowerInfo {
+                    name,
+                    addr,
+                    sent_seq: 0,
+                    since: SystemTime::now(),
+                },
+            );
+        }
+        Attached {
+            repl: self.clone(),
+            id,
+        }
+    }
+}
+
+/// A follower's place in its primary's list, for as long as its stream
+/// lasts.
+pub struct Attached {
+    repl: Arc<Replication>,
+    id: u64,
+}
+
+impl Attached {
+    /// Notes the oplog position last sent to the follower.
+    pub fn sent(&self, seq: u64) {
+        if let Ok(mut followers) = self.repl.followers.lock() {
+            if let Some(f) = followers.get_mut(&self.id) {
+                f.sent_seq = seq;
+            }
+        }
+    }
+}
+
+impl Drop for Attached {
+    fn drop(&mut self) {
+        if let Ok(mut followers) = self.repl.followers.lock() {
+            followers.remove(&self.id);
+        }
+    }
+}
+
+/// Follows the primary until the server exits, reconnecting whenever the
+/// stream ends: a second later if it had got going, otherwise backing off
+/// up to MAX_BACKOFF.
+pub async fn run_follower(db: Arc<Database>, repl: Arc<Replication>, cfg: FollowConfig) {
+    let mut backoff = Duration::from_secs(1);
+    loop {
+        let resyncs = repl.follow_status().resyncs;
+        let error = match follow(&db, &repl, &cfg).await {
+            Ok(()) => "the primary ended the stream".to_string(),
+            Err(e) => e,
+        };
+        repl.update(|s| {
+            s.connected = false;
+            s.error = error.clone();
+        });
+        if repl.follow_status().resyncs > resyncs {
+            backoff = Duration::from_secs(1);
+        }
+        warn!(primary = %cfg.url, "replication: {}; retrying in {:?}", error, backoff);
+        tokio::time::sleep(backoff).await;
+        backoff = (backoff * 2).min(MAX_BACKOFF);
+    }
+}
+
+/// Streams from the primary once: logs in, loads its snapshot, then
+/// applies changes until the stream ends.
+async fn follow(db: &Arc<Database>, repl: &Replication, cfg: &FollowConfig) -> Result<(), String> {
+    let mut endpoint = Endpoint::from_shared(cfg.url.clone())
+        .map_err(|e| e.to_string())?
+        .connect_timeout(PRIMARY_TIMEOUT);
+    if let Some(pem) = &cfg.ca_pem {
+        let tls = ClientTlsConfig::new().ca_certificate(Certificate::from_pem(pem));
+        endpoint = endpoint.tls_config(tls).map_err(|e| e.to_string())?;
+    }
+    let channel = endpoint
+        .connect()
+        .await
+        .map_err(|e| format!("connect: {}", e))?;
+    let mut client = HermitClient::new(channel);
+
+    let login = client
+        .login(cfg.request(LoginRequest {
+            username: cfg.user.clone(),
+            token: cfg.token.clone(),
+        })?)
+        .await
+        .map_err(|e| format!("login: {}", e.message()))?
+        .into_inner();
+    if !login.success {
+        return Err(format!("login: {}", login.error));
+    }
+    let mut req = cfg.request(ReplicateRequest {
+        follower: cfg.name.clone(),
+    })?;
+    let session = MetadataValue::try_from(login.session_id.as_str()).map_err(|e| e.to_string())?;
+    req.metadata_mut()
+        .insert(auth::SESSION_METADATA_KEY, session);
+    let mut stream = client
+        .replicate(req)
+        .await
+        .map_err(|e| format!("replicate: {}", e.message()))?
+        .into_inner();
+
+    let mut snapshot = Vec::new();
+    loop {
+        let entry = match tokio::time::timeout(PRIMARY_TIMEOUT, stream.message()).await {
+            Err(_) => return Err(format!("nothing from the primary in {:?}", PRIMARY_TIMEOUT)),
+            Ok(Err(status)) => return Err(status.message().to_string()),
+            Ok(Ok(None)) => return Ok(()),
+            Ok(Ok(Some(entry))) => entry,
+        };
+        let seq = entry.seq;
+        let kind = oplog_entry::Kind::try_from(entry.kind)
+            .map_err(|_| format!("unknown oplog entry kind {}", entry.kind))?;
+        match kind {
+            oplog_entry::Kind::Snapshot => {
+                if (snapshot.len() + entry.value.len()) as u64 > db::SNAPSHOT_MAX_BYTES {
+                    return Err("the primary's snapshot is too large".to_string());
+                }
+                snapshot.extend_from_slice(&entry.value);
+            }
+            oplog_entry::Kind::SnapshotEnd => {
+                let (db, data) = (db.clone(), std::mem::take(&mut snapshot));
+                let (keys, rows) = tokio::task::spawn_blocking(move || db.restore(&data))
+                    .await
+                    .map_err(|e| e.to_string())??;
+                info!(
+                    keys,
+                    rows, seq, "replication: loaded the primary's snapshot"
+                );
+                repl.update(|s| {
+                    s.connected = true;
+                    s.applied_seq = seq;
+                    s.resyncs += 1;
+                    s.error.clear();
+                });
+            }
+            oplog_entry::Kind::Heartbeat => {}
+            oplog_entry::Kind::Set
+            | oplog_entry::Kind::Delete
+            | oplog_entry::Kind::Expire
+            | oplog_entry::Kind::Bucket => {
+                let change = match kind {
+                    oplog_entry::Kind::Set => KvChange::Set {
+                        value: entry.value,
+                        version: entry.version,
+                        ttl: (entry.ttl_ms > 0).then(|| Duration::from_millis(entry.ttl_ms)),
+                    },
+                    oplog_entry::Kind::Delete => KvChange::Delete,
+                    oplog_entry::Kind::Bucket => KvChange::CreateBucket,
+                    _ => KvChange::Expire,
+                };
+                db.apply(&entry.bucket, entry.key, change)?;
+                repl.update(|s| s.applied_seq = seq);
+            }
+        }
+        repl.update(|s| {
+            s.primary_seq = s.primary_seq.max(seq);
+            s.last_contact = Some(Instant::now());
+        });
+    }
+}
@@ -0,0 +1,267 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+//! Hermit's framed TCP protocol: the document store's gets and sets, and
+//! its stats, without gRPC's HTTP/2 in the way (--tcp-port, and
+//! --tcp-tls-port over TLS).
+//!
+//! Every message is a frame: a 4-byte big-endian length, then that many
+//! bytes. A request starts with its op, a response with its status: 0 ok,
+//! 1 not found, or 2 error followed by a UTF-8 message. Requests are
+//! answered in order, so a client may send several before reading.
+//!
+//! ```text
+//! PING    0x00                                  ok
+//! AUTH    0x01 secret-len:u16 secret session    ok
+//! BUCKET  0x02 name                             ok
+//! GET     0x03 key                              ok version:u64 value | not found
+//! SET     0x04 key-len:u16 key value            ok version:u64
+//! STATS   0x05                                  ok keys:u64 stored-bytes:u64 rows:u64 pending:u64
+//! ```
+//!
+//! AUTH takes the x-hermit-secret and a session from the gRPC Login; until
+//! it succeeds only PING works, unless hermit runs open without a secret.
+//! BUCKET picks the bucket later calls use, the default until then.
+//! Integers are big-endian.
+
+use std::io;
+use std::net::SocketAddr;
+use std::sync::Arc;
+use std::time::Duration;
+
+use tokio::io::{AsyncRead, AsyncReadExt, AsyncWrite, AsyncWriteExt, BufReader, BufWriter};
+use tokio::net::TcpListener;
+use tokio_rustls::TlsAcceptor;
+use tracing::{debug, info, warn};
+
+use crate::auth::{Auth, Caller, Role};
+use crate::db::{self, Database};
+use crate::limits::{Class, Limits};
+use crate::metrics::Metrics;
+use crate::replica::Replication;
+use crate::tls::TlsConfig;
+
+/// Largest frame either side may send.
+const MAX_FRAME: usize = 16 << 20;
+
+/// A connection that sends nothing for this long is closed.
+const IDLE_TIMEOUT: Duration = Duration::from_secs(300);
+
+const OP_PING: u8 = 0x00;
+const OP_AUTH: u8 = 0x01;
+const OP_BUCKET: u8 = 0x02;
+const OP_GET: u8 = 0x03;
+const OP_SET: u8 = 0x04;
+const OP_STATS: u8 = 0x05;
+
+const STATUS_OK: u8 = 0;
+const STATUS_NOT_FOUND: u8 = 1;
+const STATUS_ERROR: u8 = 2;
+
+/// What every connection works with.
+pub struct Shared {
+    pub db: Arc<Database>,
+    pub auth: Arc<Auth>,
+    pub metrics: Arc<Metrics>,
+    pub limits: Arc<Limits>,
+    pub replication: Arc<Replication>,
+}
+
+/// Serves the protocol on `port`, over TLS if `tls` is given.
+pub async fn serve(port: u16, tls: Option<TlsConfig>, shared: Arc<Shared>) -> io::Result<()> {
+    let listener = TcpListener::bind(("0.0.0.0", port)).await?;
+    let acceptor = tls.map(|t| TlsAcceptor::from(t.server_config));
+    info!(port, tls = acceptor.is_some(), "TCP protocol listening");
+    loop {
+        let (stream, peer) = match listener.accept().await {
+            Ok(conn) => conn,
+            Err(e) => {
+                // Out of descriptors, most likely; give some back.
+                warn!("TCP accept: {}", e);
+                tokio::time::sleep(Duration::from_millis(100)).await;
+                continue;
+            }
+        };
+        let _ = stream.set_nodelay(true);
+        shared.metrics.connection(acceptor.is_some());
+        let (shared, acceptor) = (shared.clone(), acceptor.clone());
+        tokio::spawn(async move {
+            let served = match acceptor {
+                Some(a) => match a.accept(stream).await {
+                    Ok(stream) => Conn::new(&shared, peer).serve(stream).await,
+                    Err(e) => Err(e),
+                },
+                None => Conn::new(&shared, peer).serve(stream).await,
+            };
+            if let Err(e) = served {
+                debug!(%peer, "TCP connection: {}", e);
+            }
+        });
+    }
+}
+
+/// One connection: who it's authenticated as and which bucket it uses.
+struct Conn<'a> {
+    shared: &'a Shared,
+    peer: SocketAddr,
+    caller: Option<Caller>,
+    bucket: String,
+}
+
+impl<'a> Conn<'a> {
+    fn new(shared: &'a Shared, peer: SocketAddr) -> Self {
+        Conn {
+            shared,
+            peer,
+            caller: shared.auth.unauthenticated(),
+            bucket: db::DEFAULT_BUCKET.to_string(),
+        }
+    }
+
+    /// Answers requests until the client hangs up or goes idle.
+    async fn serve<S: AsyncRead + AsyncWrite + Unpin>(mut self, stream: S) -> io::Result<()> {
+        let (r, w) = tokio::io::split(stream);
+        let (mut r, mut w) = (BufReader::new(r), BufWriter::new(w));
+        loop {
+            let req = match tokio::time::timeout(IDLE_TIMEOUT, read_frame(&mut r)).await {
+                Ok(Ok(Some(req))) => req,
+                Ok(Ok(None)) | Err(_) => return Ok(()),
+                Ok(Err(e)) => return Err(e),
+            };
+            let (status, body) = match self.handle(&req) {
+                Ok(Some(body)) => (STATUS_OK, body),
+                Ok(None) => (STATUS_NOT_FOUND, Vec::new()),
+                Err(e) => (STATUS_ERROR, e.into_bytes()),
+            };
+            let len = (1 + body.len()) as u32;
+            w.write_all(&len.to_be_bytes()).await?;
+            w.write_u8(status).await?;
+            w.write_all(&body).await?;
+            // Answer requests sent together in one write.
+            if r.buffer().is_empty() {
+                w.flush().await?;
+            }
+        }
+    }
+
+    /// Runs one request, returning its response body, or None for not
+    /// found.
+    fn handle(&mut self, req: &[u8]) -> Result<Option<Vec<u8>>, String> {
+        let (&op, args) = req.split_first().ok_or("empty request")?;
+        match op {
+            OP_PING => Ok(Some(Vec::new())),
+            OP_AUTH => {
+                let (secret, session) = split_prefixed(args)?;
+                self.caller = Some(self.shared.auth.authenticate(utf8(secret)?, utf8(session)?)?);
+                Ok(Some(Vec::new()))
+            }
+            OP_BUCKET => {
+                let name = utf8(args)?;
+                self.check_bucket(name, Role::Read)?;
+                self.bucket = name.to_string();
+                Ok(Some(Vec::new()))
+            }
+            OP_GET => {
+                let key = utf8(args)?;
+                self.check_bucket(&self.bucket, Role::Read)?;
+                let mut timer = self.shared.metrics.time("TcpGet");
+                let got = self.shared.db.kv_get(&self.bucket, key)?;
+                timer.about(&self.bucket, key);
+                Ok(got.map(|(value, version)| {
+                    let mut out = Vec::with_capacity(8 + value.len());
+                    out.extend_from_slice(&version.to_be_bytes());
+                    out.extend_from_slice(&value);
+                    out
+                }))
+            }
+            OP_SET => {
+                let (key, value) = split_prefixed(args)?;
+                let key = utf8(key)?;
+                self.check_bucket(&self.bucket, Role::Write)?;
+                if let Some(primary) = self.shared.replication.following() {
+                    return Err(format!("read-only follower of {}; write to the primary", primary));
+                }
+                self.limit(Class::Write)?;
+                let mut timer = self.shared.metrics.time("TcpSet");
+                self.shared.metrics.value_written(&self.bucket, key, value.len());
+                let version = self.shared.db.kv_set(&self.bucket, key.to_string(), value.to_vec(), None)?;
+                timer.about(&self.bucket, key);
+                Ok(Some(version.to_be_bytes().to_vec()))
+            }
+            OP_STATS => {
+                self.caller()?.check(Role::Read)?;
+                let _timer = self.shared.metrics.time("TcpStats");
+                let (keys, stored) = self.shared.db.kv_stats()?;
+                let (rows, pending) = self.shared.db.rel_stats()?;
+                Ok(Some([keys, stored, rows, pending].iter().flat_map(|n| n.to_be_bytes()).collect()))
+            }
+            _ => Err(format!("unknown op {:#04x}", op)),
+        }
+    }
+
+    fn caller(&self) -> Result<&Caller, String> {
+        self.caller.as_ref().ok_or_else(|| "send AUTH first".to_string())
+    }
+
+    /// Checks that the caller may use `bucket` with `role`, and that it
+    /// exists.
+    fn check_bucket(&self, bucket: &str, role: Role) -> Result<(), String> {
+        self.caller()?.check_bucket(bucket, role)?;
+        if !self.shared.db.has_bucket(bucket) {
+            return Err(format!("no bucket {}; an admin can create it", bucket));
+        }
+        Ok(())
+    }
+
+    /// Takes a call of `class` from the caller's rate limit, keyed as the
+    /// gRPC service keys it: by user, or by IP address when hermit runs
+    /// open.
+    fn limit(&self, class: Class) -> Result<(), String> {
+        let client = match self.caller.as_ref().map_or("", |c| c.user.as_str()) {
+            "" => self.peer.ip().to_string(),
+            user => user.to_string(),
+        };
+        self.shared.limits.take(class, &client).map(|_| ()).map_err(|q| {
+            self.shared.metrics.rate_limited();
+            format!(
+                "{} limit of {}/s reached; retry in {}ms",
+                class.as_str(),
+                q.limit.per_second,
+                q.retry_after.unwrap_or_default().as_millis().max(1)
+            )
+        })
+    }
+}
+
+/// Reads a frame, or None if the client hung up between frames.
+async fn read_frame<R: AsyncRead + Unpin>(r: &mut R) -> io::Result<Option<Vec<u8>>> {
+    let mut len = [0u8; 4];
+    match r.read_exact(&mut len).await {
+        Ok(_) => {}
+        Err(e) if e.kind() == io::ErrorKind::UnexpectedEof => return Ok(None),
+        Err(e) => return Err(e),
+    }
+    let len = u32::from_be_bytes(len) as usize;
+    if len == 0 || len > MAX_FRAME {
+        return Err(io::Error::new(
+            io::ErrorKind::InvalidData,
+            format!("frame of {} bytes; want 1 to {}", len, MAX_FRAME),
+        ));
+    }
+    let mut frame = vec![0u8; len];
+    r.read_exact(&mut frame).await?;
+    Ok(Some(frame))
+}
+
+/// Splits `len:u16 first rest` into first and rest.
+fn split_prefixed(args: &[u8]) -> Result<(&[u8], &[u8]), String> {
+    let (len, rest) = args.split_first_chunk::<2>().ok_or("truncated request")?;
+    let len = u16::from_be_bytes(*len) as usize;
+    if rest.len() < len {
+        return Err("truncated request".to_string());
+    }
+    Ok(rest.split_at(len))
+}
+
+fn utf8(b: &[u8]) -> Result<&str, String> {
+    std::str::from_utf8(b).map_err(|_| "not UTF-8".to_string())
+}
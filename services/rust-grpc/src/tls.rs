@@ -3,6 +3,7 @@
 use rcgen::{generate_simple_self_signed, CertifiedKey};
 use rustls::ServerConfig;
 use rustls_pemfile::{certs, pkcs8_private_keys};
+use sha2::{Digest, Sha256};
 use std::io::BufReader;
 use std::sync::Arc;
 use tracing::info;
@@ -52,6 +53,11 @@ fn build_rustls_config(
     key_pem: &[u8],
 ) -> Result<ServerConfig, Box<dyn std::error::Error>> {
     let cert_chain = certs(&mut BufReader::new(cert_pem)).collect::<Result<Vec<_>, _>>()?;
+    // Clients pin this (tui --hermit-pin), which is what makes a
+    // self-signed certificate safe to use outside dev.
+    if let Some(leaf) = cert_chain.first() {
+        info!("TLS certificate sha256 fingerprint {}", fingerprint(leaf));
+    }
     let mut keys =
         pkcs8_private_keys(&mut BufReader::new(key_pem)).collect::<Result<Vec<_>, _>>()?;
 
@@ -65,3 +71,11 @@ fn build_rustls_config(
 
     Ok(config)
 }
+
+/// The SHA-256 of a DER certificate, as lowercase hex.
+fn fingerprint(der: &[u8]) -> String {
+    Sha256::digest(der)
+        .iter()
+        .map(|b| format!("{:02x}", b))
+        .collect()
+}
@@ -0,0 +1,265 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+//! Write-ahead log for the relational store. Each insert, delete and update
+//! is appended before it is acknowledged, and the log is replayed at
+//! startup.
+//!
+//! A record is `len: u32 | checksum: u32 | body`, little-endian, where the
+//! checksum is FNV-1a over the body and the body is `op: u8` followed by
+//! the op's fields.
+
+use std::fs::{File, OpenOptions};
+use std::io::{Read, Write};
+use std::path::{Path, PathBuf};
+
+use crate::db::Row;
+
+/// A row was inserted: id, created_at_unix, key, value.
+const OP_INSERT: u8 = 1;
+/// Rows were deleted: key.
+const OP_DELETE: u8 = 2;
+/// Rows were updated: key, value.
+const OP_UPDATE: u8 = 3;
+
+const HEADER_LEN: usize = 8;
+
+pub struct Wal {
+    file: File,
+    path: PathBuf,
+    len: u64,       // bytes of whole records; a failed append is cut back to it
+    unsynced: bool, // appended to since the last sync
+}
+
+/// One change to the relational store, as logged.
+pub enum WalOp<'a> {
+    Insert(&'a Row),
+    /// Every row with this key is removed.
+    Delete(&'a str),
+    /// Every row with this key gets this value.
+    Update(&'a str, &'a str),
+}
+
+/// A `WalOp` read back.
+enum Logged {
+    Insert(Row),
+    Delete(String),
+    Update(String, String),
+}
+
+/// What `Wal::open` found.
+pub struct Replay {
+    pub rows: Vec<Row>,
+    /// The file was already there, so its rows are the whole store.
+    pub existed: bool,
+    /// Bytes of a torn final record that were cut off.
+    pub torn: usize,
+    /// It logs deletes or updates, so rewriting it from `rows` shrinks it.
+    pub stale: bool,
+}
+
+impl Wal {
+    /// Opens the log at `path`, creating it if needed, and reads back its
+    /// rows. A torn record at the end, left by a crash mid-append, is cut
+    /// off; a bad record anywhere else is an error rather than data loss.
+    pub fn open(path: &Path) -> Result<(Wal, Replay), String> {
+        let existed = path.exists();
+        let mut file = OpenOptions::new()
+            .read(true)
+            .append(true)
+            .create(true)
+            .open(path)
+            .map_err(|e| format!("{}: {}", path.display(), e))?;
+        let mut data = Vec::new();
+        file.read_to_end(&mut data)
+            .map_err(|e| format!("{}: {}", path.display(), e))?;
+
+        let mut rows: Vec<Row> = Vec::new();
+        let mut stale = false;
+        let mut off = 0;
+        while off < data.len() {
+            match decode(&data[off..]) {
+                Record::Op(op, n) => {
+                    match op {
+                        Logged::Insert(row) => rows.push(row),
+                        Logged::Delete(key) => {
+                            rows.retain(|r| r.key != key);
+                            stale = true;
+                        }
+                        Logged::Update(key, value) => {
+                            for r in rows.iter_mut().filter(|r| r.key == key) {
+                                r.value = value.clone();
+                            }
+                            stale = true;
+                        }
+                    }
+                    off += n;
+                }
+                Record::Torn => break,
+                Record::Corrupt(n) if off + n == data.len() => break, // torn, not yet zeroed
+                Record::Corrupt(_) => {
+                    return Err(format!("{}: corrupt record at byte {}", path.display(), off));
+                }
+            }
+        }
+        let torn = data.len() - off;
+        if torn > 0 {
+            file.set_len(off as u64)
+                .map_err(|e| format!("{}: {}", path.display(), e))?;
+        }
+        let wal = Wal {
+            file,
+            path: path.to_path_buf(),
+            len: off as u64,
+            unsynced: false,
+        };
+        Ok((wal, Replay { rows, existed, torn, stale }))
+    }
+
+    /// Appends a change. The write reaches the OS before this returns, so
+    /// it survives the process crashing; syncing the `sync_handle` makes it
+    /// survive the machine crashing.
+    pub fn append(&mut self, op: &WalOp) -> Result<(), String> {
+        let rec = encode(op);
+        if let Err(e) = self.file.write_all(&rec) {
+            // Don't leave half a record for the next one to follow.
+            let _ = self.file.set_len(self.len);
+            return Err(format!("wal: {}", e));
+        }
+        self.len += rec.len() as u64;
+        self.unsynced = true;
+        Ok(())
+    }
+
+    /// Returns a handle to sync to disk if anything was appended since the
+    /// last call, so callers can sync without holding the store's lock.
+    pub fn sync_handle(&mut self) -> Result<Option<File>, String> {
+        if !self.unsynced {
+            return Ok(None);
+        }
+        let f = self.file.try_clone().map_err(|e| format!("wal: {}", e))?;
+        self.unsynced = false;
+        Ok(Some(f))
+    }
+
+    /// Replaces the log with exactly `rows`, as after a restore. The new
+    /// log is written beside the old one and renamed over it, so a crash
+    /// leaves one or the other.
+    pub fn rewrite<'a>(&mut self, rows: impl Iterator<Item = &'a Row>) -> Result<(), String> {
+        let tmp = self.path.with_extension("tmp");
+        let mut data = Vec::new();
+        for row in rows {
+            data.extend_from_slice(&encode(&WalOp::Insert(row)));
+        }
+        let wrap = |e: std::io::Error| format!("wal: {}", e);
+        let mut f = File::create(&tmp).map_err(wrap)?;
+        f.write_all(&data).map_err(wrap)?;
+        f.sync_data().map_err(wrap)?;
+        std::fs::rename(&tmp, &self.path).map_err(wrap)?;
+        self.file = OpenOptions::new().append(true).open(&self.path).map_err(wrap)?;
+        self.len = data.len() as u64;
+        self.unsynced = false;
+        Ok(())
+    }
+}
+
+enum Record {
+    /// A change and the bytes its record took.
+    Op(Logged, usize),
+    /// The data ends partway through a record.
+    Torn,
+    /// A whole record that doesn't check out, and its length.
+    Corrupt(usize),
+}
+
+fn put_str(body: &mut Vec<u8>, s: &str) {
+    body.extend_from_slice(&(s.len() as u32).to_le_bytes());
+    body.extend_from_slice(s.as_bytes());
+}
+
+fn encode(op: &WalOp) -> Vec<u8> {
+    let mut body = Vec::new();
+    match op {
+        WalOp::Insert(row) => {
+            body.push(OP_INSERT);
+            body.extend_from_slice(&row.id.to_le_bytes());
+            body.extend_from_slice(&row.created_at_unix.to_le_bytes());
+            put_str(&mut body, &row.key);
+            put_str(&mut body, &row.value);
+        }
+        WalOp::Delete(key) => {
+            body.push(OP_DELETE);
+            put_str(&mut body, key);
+        }
+        WalOp::Update(key, value) => {
+            body.push(OP_UPDATE);
+            put_str(&mut body, key);
+            put_str(&mut body, value);
+        }
+    }
+    let mut rec = Vec::with_capacity(HEADER_LEN + body.len());
+    rec.extend_from_slice(&(body.len() as u32).to_le_bytes());
+    rec.extend_from_slice(&fnv1a(&body).to_le_bytes());
+    rec.extend_from_slice(&body);
+    rec
+}
+
+fn decode(data: &[u8]) -> Record {
+    if data.len() < HEADER_LEN {
+        return Record::Torn;
+    }
+    let len = u32::from_le_bytes(data[0..4].try_into().unwrap()) as usize;
+    let sum = u32::from_le_bytes(data[4..8].try_into().unwrap());
+    let Some(body) = data.get(HEADER_LEN..HEADER_LEN + len) else {
+        return Record::Torn;
+    };
+    let n = HEADER_LEN + len;
+    if fnv1a(body) != sum {
+        return Record::Corrupt(n);
+    }
+    match parse(body) {
+        Some(op) => Record::Op(op, n),
+        None => Record::Corrupt(n),
+    }
+}
+
+/// Reads a record body's fields in order.
+struct Fields<'a>(&'a [u8]);
+
+impl<'a> Fields<'a> {
+    fn take(&mut self, n: usize) -> Option<&'a [u8]> {
+        let (head, rest) = self.0.split_at_checked(n)?;
+        self.0 = rest;
+        Some(head)
+    }
+
+    fn u64(&mut self) -> Option<u64> {
+        Some(u64::from_le_bytes(self.take(8)?.try_into().ok()?))
+    }
+
+    fn string(&mut self) -> Option<String> {
+        let n = u32::from_le_bytes(self.take(4)?.try_into().ok()?) as usize;
+        String::from_utf8(self.take(n)?.to_vec()).ok()
+    }
+}
+
+fn parse(body: &[u8]) -> Option<Logged> {
+    let (&op, rest) = body.split_first()?;
+    let mut f = Fields(rest);
+    let logged = match op {
+        OP_INSERT => Logged::Insert(Row {
+            id: f.u64()?,
+            created_at_unix: f.u64()? as i64,
+            key: f.string()?,
+            value: f.string()?,
+        }),
+        OP_DELETE => Logged::Delete(f.string()?),
+        OP_UPDATE => Logged::Update(f.string()?, f.string()?),
+        _ => return None,
+    };
+    f.0.is_empty().then_some(logged)
+}
+
+/// 32-bit FNV-1a: enough to tell a torn or scribbled record from a good one.
+fn fnv1a(data: &[u8]) -> u32 {
+    data.iter().fold(0x811c_9dc5, |h, &b| (h ^ b as u32).wrapping_mul(0x0100_0193))
+}
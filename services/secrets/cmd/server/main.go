@@ -20,12 +20,21 @@ import (
 	_ "embed"
 	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 
+	"github.com/jredh-dev/nexus/internal/events"
+	"github.com/jredh-dev/nexus/internal/flags"
+	"github.com/jredh-dev/nexus/internal/health"
+	"github.com/jredh-dev/nexus/internal/httpserver"
+	"github.com/jredh-dev/nexus/internal/logging"
+	"github.com/jredh-dev/nexus/internal/metrics"
+	"github.com/jredh-dev/nexus/internal/ratelimit"
+	"github.com/jredh-dev/nexus/internal/sso"
 	gohttp "github.com/jredh-dev/nexus/services/go-http"
 	"github.com/jredh-dev/nexus/services/go-http/config"
 	"github.com/jredh-dev/nexus/services/secrets/internal/handlers"
+	"github.com/jredh-dev/nexus/services/secrets/internal/lens"
 	"github.com/jredh-dev/nexus/services/secrets/internal/store"
 )
 
@@ -53,19 +62,69 @@ func main() {
 		os.Exit(0)
 	}
 
-	cfg := config.Load()
-	s := store.New()
-	h := handlers.New(s)
+	if err := logging.Setup("nexus-secrets"); err != nil {
+		fmt.Fprintf(os.Stderr, "nexus-secrets: %v\n", err)
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load(ratelimit.Limits{"submit": ratelimit.PerMinute(10)})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nexus-secrets: %v\n", err)
+		os.Exit(1)
+	}
+	slog.Info("config", "settings", cfg.Settings)
+	// Each lens can be turned off while the service runs, so a new one
+	// that matches too much can be backed out without a redeploy.
+	fl := flags.New("")
+	lensOn := map[string]*flags.Flag{}
+	for _, l := range lens.All() {
+		lensOn[l.Name()] = fl.Bool("lens."+l.Name(), true, "Match secrets through the "+l.Name()+" lens")
+	}
+	if err := fl.Load(); err != nil {
+		fmt.Fprintf(os.Stderr, "nexus-secrets: %v\n", err)
+		os.Exit(1)
+	}
+	slog.Info("flags", "flags", fl)
+	s := store.New(store.WithLensSwitch(func(name string) bool { return lensOn[name].On() }))
+	bus := events.Open("secrets", cfg.EventBrokers)
+	h := handlers.New(s, handlers.WithEvents(bus))
+
+	// Secrets are kept in memory; only the event bus can be down.
+	checks := health.New()
+	if len(cfg.EventBrokers) > 0 {
+		checks.Ready("kafka", health.Kafka(cfg.EventBrokers))
+	}
+
+	limits, err := ratelimit.Open(cfg.RateLimit.DB)
+	if err != nil {
+		logging.Fatal("open rate limit store", "path", cfg.RateLimit.DB, "err", err)
+	}
+	m := metrics.New("secrets")
+	rateLimited := ratelimit.NewCounter("secrets")
+	m.MustRegister(rateLimited)
+	lim := ratelimit.New(limits, cfg.RateLimit.Limits, rateLimited)
 
-	srv := gohttp.New()
-	srv.OnStop(h.Stop)
+	srv := httpserver.New(
+		httpserver.WithMiddleware(gohttp.CORS),
+		httpserver.WithMetrics(m),
+		httpserver.WithHealth(checks),
+		httpserver.WithVersion(httpserver.Version{Service: "nexus-secrets", Version: version, Commit: commit, Built: buildDate}),
+		httpserver.WithFlags(fl),
+		httpserver.WithWorker("wall", h.RunWall),
+		httpserver.WithCloser("event bus", bus),
+		httpserver.WithCloser("rate limits", limits),
+		// Client IPs, which the submit limit keys on, come from
+		// X-Forwarded-For only as TRUSTED_PROXIES record it.
+		httpserver.WithTrustedProxies(cfg.RateLimit.TrustedProxies),
+	)
 
 	// The riddle — start here
 	srv.Router.Get("/", h.Riddle)
 	srv.Router.Get("/api/riddle", h.Riddle)
 
-	// Secrets API
-	srv.Router.Post("/api/secrets", h.Submit)
+	// Secrets API. Anyone may submit; portal users are credited by name.
+	sv := sso.NewVerifier(cfg.SSOPortalURL, cfg.SSOIntrospectKey, []byte(cfg.SSOSecret))
+	srv.Router.With(lim.Limit("submit", ratelimit.ByIP), sv.Middleware).Post("/api/secrets", h.Submit)
 	srv.Router.Get("/api/secrets", h.List)
 	srv.Router.Get("/api/secrets/{id}", h.Get)
 	srv.Router.Get("/api/stats", h.Stats)
@@ -77,11 +136,11 @@ func main() {
 	}
 
 	addr := ":" + cfg.Port
-	log.Printf("nexus-secrets starting on %s", addr)
-	log.Printf("  Riddle:  http://localhost%s/", addr)
-	log.Printf("  API:     http://localhost%s/api/", addr)
+	slog.Info("nexus-secrets starting", "addr", addr, "version", version,
+		"riddle", "http://localhost"+addr+"/",
+		"api", "http://localhost"+addr+"/api/")
 
 	if err := srv.ListenAndServe(addr); err != nil {
-		log.Fatalf("Server error: %v", err)
+		logging.Fatal("server", "err", err)
 	}
 }
@@ -1,12 +1,16 @@
 package handlers
 
 import (
-	"encoding/json"
-	"log"
+	"context"
 	"net/http"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/jredh-dev/nexus/internal/events"
+	"github.com/jredh-dev/nexus/internal/httpx"
+	"github.com/jredh-dev/nexus/internal/logging"
+	"github.com/jredh-dev/nexus/internal/sso"
 	"github.com/jredh-dev/nexus/services/secrets/internal/store"
 	"github.com/jredh-dev/nexus/services/secrets/internal/wall"
 )
@@ -15,14 +19,28 @@ import (
 type Handler struct {
 	store *store.Store
 	wall  *wall.Wall
+	bus   events.Bus // nil publishes nothing
+}
+
+// Option configures a Handler during construction.
+type Option func(*Handler)
+
+// WithEvents publishes events.SecretExposed on bus when a secret is
+// exposed.
+func WithEvents(bus events.Bus) Option {
+	return func(h *Handler) { h.bus = bus }
 }
 
 // New creates a new Handler with a rotating wall.
-func New(s *store.Store) *Handler {
-	return &Handler{
+func New(s *store.Store, opts ...Option) *Handler {
+	h := &Handler{
 		store: s,
 		wall:  wall.New(s),
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 type submitReq struct {
@@ -45,24 +63,54 @@ type submitReq struct {
 //	@Router       /api/secrets [post]
 func (h *Handler) Submit(w http.ResponseWriter, r *http.Request) {
 	var req submitReq
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		jsonError(w, "invalid request body", http.StatusBadRequest)
+	if err := httpx.DecodeJSON(w, r, &req); err != nil {
+		httpx.WriteError(w, err)
 		return
 	}
 	if req.Value == "" {
-		jsonError(w, "value is required", http.StatusBadRequest)
+		httpx.Error(w, "value is required", http.StatusBadRequest)
 		return
 	}
+	// Signed-in portal users are credited by their username, whatever
+	// the body claims.
+	if id := sso.FromContext(r.Context()); id != nil {
+		req.SubmittedBy = id.Username
+		if req.SubmittedBy == "" {
+			req.SubmittedBy = id.UserID
+		}
+	}
 	if req.SubmittedBy == "" {
 		req.SubmittedBy = "anonymous"
 	}
 
 	result := h.store.Submit(req.Value, req.SubmittedBy)
 
-	log.Printf("submit: value=%q by=%s new=%v count=%d",
-		req.Value, req.SubmittedBy, result.WasNew, result.Secret.Count)
+	logging.FromContext(r.Context()).Info("submit",
+		"value", req.Value, "by", req.SubmittedBy, "new", result.WasNew, "count", result.Secret.Count)
+	if !result.WasNew && result.Secret.Count == 2 {
+		h.publishExposure(r.Context(), result)
+	}
 
-	jsonOK(w, http.StatusOK, result)
+	httpx.JSON(w, http.StatusOK, result)
+}
+
+// publishExposure announces the submission that exposed a secret. It is
+// best-effort: the submission counts either way, and a failed publish is
+// logged rather than surfaced.
+func (h *Handler) publishExposure(ctx context.Context, result *store.SubmitResult) {
+	if h.bus == nil {
+		return
+	}
+	s := result.Secret
+	err := events.Publish(ctx, h.bus, events.SecretExposed, s.ID, events.SecretExposure{
+		SecretID:    s.ID,
+		SubmittedBy: s.SubmittedBy,
+		Lens:        result.Lens,
+		Count:       s.Count,
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("publish secret exposure", "secret_id", s.ID, "err", err)
+	}
 }
 
 // Get handles GET /api/secrets/{id}
@@ -79,10 +127,10 @@ func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	sec, ok := h.store.Get(id)
 	if !ok {
-		jsonError(w, "secret not found", http.StatusNotFound)
+		httpx.Error(w, "secret not found", http.StatusNotFound)
 		return
 	}
-	jsonOK(w, http.StatusOK, sec)
+	httpx.JSON(w, http.StatusOK, sec)
 }
 
 // List handles GET /api/secrets — returns secrets in randomized order.
@@ -98,7 +146,7 @@ func (h *Handler) List(w http.ResponseWriter, _ *http.Request) {
 	if secrets == nil {
 		secrets = []*store.Secret{}
 	}
-	jsonOK(w, http.StatusOK, secrets)
+	httpx.JSON(w, http.StatusOK, secrets)
 }
 
 // Stats handles GET /api/stats
@@ -110,7 +158,7 @@ func (h *Handler) List(w http.ResponseWriter, _ *http.Request) {
 //	@Success      200  {object}  store.Stats
 //	@Router       /api/stats [get]
 func (h *Handler) Stats(w http.ResponseWriter, _ *http.Request) {
-	jsonOK(w, http.StatusOK, h.store.Stats())
+	httpx.JSON(w, http.StatusOK, h.store.Stats())
 }
 
 // Riddle handles GET /api/riddle — the entry point.
@@ -136,21 +184,50 @@ func (h *Handler) Riddle(w http.ResponseWriter, _ *http.Request) {
 		"endpoint": "POST /api/secrets {\"value\": \"...\", \"submitted_by\": \"...\"}",
 		"stats":    h.store.Stats(),
 	}
-	jsonOK(w, http.StatusOK, riddle)
+	httpx.JSON(w, http.StatusOK, riddle)
+}
+
+// exposedPage is the JSON form of a wall page.
+type exposedPage struct {
+	Exposed []wall.Entry `json:"exposed"`
+	Total   int          `json:"total"`
+	Page    int          `json:"page"`
+	Pages   int          `json:"pages"`
 }
 
 // Exposed handles GET /api/exposed — rotating page of no-longer-secret entries.
+// Clients that send Accept: application/json get each entry with its count
+// and the lens that exposed it instead of the plain-text wall.
 //
 //	@Summary      Get exposed secrets
 //	@Description  Returns a rotating plain-text page of secrets that have been admitted more than once.
+//	@Description  With Accept: application/json, returns the page as entries with count and exposing lens.
 //	@Tags         secrets
 //	@Produce      plain
-//	@Success      200  {string}  string  "Plain text wall of exposed secrets"
+//	@Produce      json
+//	@Success      200  {object}  exposedPage  "Wall of exposed secrets (plain text unless JSON is accepted)"
 //	@Header       200  {int}     X-Exposed-Total  "Total number of exposed secrets"
 //	@Header       200  {int}     X-Exposed-Page   "Current page index"
 //	@Header       200  {int}     X-Exposed-Pages  "Total number of pages"
 //	@Router       /api/exposed [get]
-func (h *Handler) Exposed(w http.ResponseWriter, _ *http.Request) {
+func (h *Handler) Exposed(w http.ResponseWriter, r *http.Request) {
+	if strings.Contains(r.Header.Get("Accept"), "application/json") {
+		entries, pageIdx, totalPages, totalExposed := h.wall.Entries()
+		if entries == nil {
+			entries = []wall.Entry{}
+		}
+		w.Header().Set("X-Exposed-Total", itoa(totalExposed))
+		w.Header().Set("X-Exposed-Page", itoa(pageIdx))
+		w.Header().Set("X-Exposed-Pages", itoa(totalPages))
+		httpx.JSON(w, http.StatusOK, exposedPage{
+			Exposed: entries,
+			Total:   totalExposed,
+			Page:    pageIdx,
+			Pages:   totalPages,
+		})
+		return
+	}
+
 	text, pageIdx, totalPages, totalExposed := h.wall.Page()
 
 	if totalExposed == 0 {
@@ -169,9 +246,9 @@ func (h *Handler) Exposed(w http.ResponseWriter, _ *http.Request) {
 	w.Write([]byte(text)) //nolint:errcheck
 }
 
-// Stop shuts down the background wall worker.
-func (h *Handler) Stop() {
-	h.wall.Stop()
+// RunWall keeps the wall up to date until ctx is done.
+func (h *Handler) RunWall(ctx context.Context) {
+	h.wall.Run(ctx)
 }
 
 func itoa(n int) string {
@@ -185,15 +262,3 @@ func itoa(n int) string {
 	}
 	return string(digits)
 }
-
-func jsonOK(w http.ResponseWriter, status int, data interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	_ = json.NewEncoder(w).Encode(data)
-}
-
-func jsonError(w http.ResponseWriter, msg string, status int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
-}
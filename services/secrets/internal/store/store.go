@@ -20,7 +20,8 @@ type Secret struct {
 	SubmittedBy string    `json:"submitted_by"` // first submitter
 	Count       int       `json:"count"`        // how many times admitted
 	CreatedAt   time.Time `json:"created_at"`
-	LastAdmitAt time.Time `json:"last_admit_at"` // most recent submission
+	LastAdmitAt time.Time `json:"last_admit_at"`        // most recent submission
+	ExposedBy   string    `json:"exposed_by,omitempty"` // lens of the first collision
 }
 
 // IsSecret returns true if this has only been admitted once.
@@ -37,22 +38,51 @@ type Store struct {
 	canonicalIndex map[string]string
 
 	lenses []lens.Lens
+	lensOn func(name string) bool
 	nextID int
 }
 
+// Option configures a Store.
+type Option func(*Store)
+
+// WithLensSwitch consults on before each submission for whether the lens
+// called name applies, so lenses can be turned on and off while the
+// store is in use. Secrets admitted while a lens was off can't be matched
+// through it later.
+func WithLensSwitch(on func(name string) bool) Option {
+	return func(s *Store) { s.lensOn = on }
+}
+
 // New creates a new in-memory store with the default lens set.
-func New() *Store {
-	return &Store{
+func New(opts ...Option) *Store {
+	s := &Store{
 		secrets:        make(map[string]*Secret),
 		canonicalIndex: make(map[string]string),
 		lenses:         lens.All(),
+		lensOn:         func(string) bool { return true },
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// activeLenses returns the lenses that are on.
+func (s *Store) activeLenses() []lens.Lens {
+	var out []lens.Lens
+	for _, l := range s.lenses {
+		if s.lensOn(l.Name()) {
+			out = append(out, l)
+		}
 	}
+	return out
 }
 
 // SubmitResult describes what happened when a secret was submitted.
 type SubmitResult struct {
 	Secret  *Secret `json:"secret"`
 	WasNew  bool    `json:"was_new"`
+	Lens    string  `json:"lens,omitempty"` // lens that matched an existing secret
 	Message string  `json:"message"`
 }
 
@@ -61,7 +91,7 @@ func (s *Store) Submit(value, submitterID string) *SubmitResult {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	canonicals := lens.CanonicalizeThroughAll(value, s.lenses)
+	canonicals := lens.CanonicalizeThroughAll(value, s.activeLenses())
 	now := time.Now().UTC()
 
 	// Check all lenses for collision with existing secrets.
@@ -72,6 +102,9 @@ func (s *Store) Submit(value, submitterID string) *SubmitResult {
 				existing := s.secrets[existingID]
 				existing.Count++
 				existing.LastAdmitAt = now
+				if existing.ExposedBy == "" {
+					existing.ExposedBy = lensName
+				}
 
 				msg := "This has been admitted before. It's no longer a secret."
 				if existing.Count == 2 {
@@ -79,6 +112,7 @@ func (s *Store) Submit(value, submitterID string) *SubmitResult {
 				}
 				return &SubmitResult{
 					Secret:  existing,
+					Lens:    lensName,
 					Message: msg,
 				}
 			}
@@ -162,7 +196,7 @@ func (s *Store) Stats() Stats {
 		Total:      len(s.secrets),
 		Secrets:    secrets,
 		NotSecrets: notSecrets,
-		Lenses:     len(s.lenses),
+		Lenses:     len(s.activeLenses()),
 	}
 }
 
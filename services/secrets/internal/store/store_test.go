@@ -0,0 +1,22 @@
+package store
+
+import "testing"
+
+func TestLensSwitch(t *testing.T) {
+	on := true
+	s := New(WithLensSwitch(func(name string) bool { return name == "casefold" && on }))
+
+	s.Submit("Hello", "alice")
+	if r := s.Submit("hello", "bob"); r.WasNew || r.Lens != "casefold" {
+		t.Fatalf("with casefold on: %+v, want a casefold match", r)
+	}
+	if n := s.Stats().Lenses; n != 1 {
+		t.Errorf("Stats().Lenses = %d with one lens on", n)
+	}
+
+	on = false
+	s.Submit("World", "alice")
+	if r := s.Submit("world", "bob"); !r.WasNew {
+		t.Errorf("with every lens off: matched through %s", r.Lens)
+	}
+}
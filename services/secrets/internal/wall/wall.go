@@ -1,11 +1,12 @@
 // Package wall implements a rotating display of exposed (non-secret) entries.
 //
-// A background worker periodically scans the store for entries with count > 1
+// A background worker (Run) periodically scans the store for entries with count > 1
 // and pre-builds pages. Each HTTP request gets a different page via atomic
 // round-robin, so consecutive visitors see different content.
 package wall
 
 import (
+	"context"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -19,25 +20,30 @@ const (
 	RefreshInterval = 5 * time.Second
 )
 
+// Entry is one exposed value on the wall.
+type Entry struct {
+	Value       string    `json:"value"`
+	Count       int       `json:"count"`
+	Lens        string    `json:"lens,omitempty"` // lens that first exposed it
+	LastAdmitAt time.Time `json:"last_admit_at"`
+}
+
 // Wall serves pre-built pages of non-secrets in round-robin order.
 type Wall struct {
 	store   *store.Store
 	counter atomic.Uint64
 
-	mu    sync.RWMutex
-	pages []string
-	total int
-	stop  chan struct{}
+	mu      sync.RWMutex
+	pages   []string
+	entries [][]Entry // same pages, structured
+	total   int
 }
 
-// New creates a Wall and starts the background worker.
+// New creates a Wall built from the store as it stands. Run keeps it up
+// to date.
 func New(s *store.Store) *Wall {
-	w := &Wall{
-		store: s,
-		stop:  make(chan struct{}),
-	}
+	w := &Wall{store: s}
 	w.rebuild()
-	go w.run()
 	return w
 }
 
@@ -54,12 +60,22 @@ func (w *Wall) Page() (text string, pageIdx int, totalPages int, totalExposed in
 	return w.pages[idx], idx, len(w.pages), w.total
 }
 
-// Stop shuts down the background worker.
-func (w *Wall) Stop() {
-	close(w.stop)
+// Entries is Page with structured entries instead of text. It shares the
+// round-robin counter with Page.
+func (w *Wall) Entries() (entries []Entry, pageIdx int, totalPages int, totalExposed int) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if len(w.entries) == 0 {
+		return nil, 0, 0, 0
+	}
+
+	idx := int(w.counter.Add(1)-1) % len(w.entries)
+	return w.entries[idx], idx, len(w.entries), w.total
 }
 
-func (w *Wall) run() {
+// Run rebuilds the wall every RefreshInterval until ctx is done.
+func (w *Wall) Run(ctx context.Context) {
 	ticker := time.NewTicker(RefreshInterval)
 	defer ticker.Stop()
 
@@ -67,7 +83,7 @@ func (w *Wall) run() {
 		select {
 		case <-ticker.C:
 			w.rebuild()
-		case <-w.stop:
+		case <-ctx.Done():
 			return
 		}
 	}
@@ -77,24 +93,37 @@ func (w *Wall) rebuild() {
 	all := w.store.List()
 
 	// Filter to non-secrets (count > 1).
-	exposed := make([]string, 0, len(all))
+	exposed := make([]Entry, 0, len(all))
 	for _, s := range all {
 		if !s.IsSecret() {
-			exposed = append(exposed, s.Value)
+			exposed = append(exposed, Entry{
+				Value:       s.Value,
+				Count:       s.Count,
+				Lens:        s.ExposedBy,
+				LastAdmitAt: s.LastAdmitAt,
+			})
 		}
 	}
 
 	var pages []string
+	var entries [][]Entry
 	for i := 0; i < len(exposed); i += PageSize {
 		end := i + PageSize
 		if end > len(exposed) {
 			end = len(exposed)
 		}
-		pages = append(pages, strings.Join(exposed[i:end], "\n"))
+		page := exposed[i:end]
+		values := make([]string, len(page))
+		for j, e := range page {
+			values[j] = e.Value
+		}
+		pages = append(pages, strings.Join(values, "\n"))
+		entries = append(entries, page)
 	}
 
 	w.mu.Lock()
 	w.pages = pages
+	w.entries = entries
 	w.total = len(exposed)
 	w.mu.Unlock()
 }
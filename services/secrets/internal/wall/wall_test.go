@@ -10,7 +10,6 @@ import (
 func TestWallEmpty(t *testing.T) {
 	s := store.New()
 	w := New(s)
-	defer w.Stop()
 
 	text, pageIdx, totalPages, totalExposed := w.Page()
 	if text != "" || pageIdx != 0 || totalPages != 0 || totalExposed != 0 {
@@ -28,7 +27,6 @@ func TestWallSinglePage(t *testing.T) {
 	s.Submit("racecar", "charlie") // palindrome — still count=1, is a secret
 
 	w := New(s)
-	defer w.Stop()
 
 	text, pageIdx, totalPages, totalExposed := w.Page()
 	if totalExposed != 1 {
@@ -61,7 +59,6 @@ func TestWallRoundRobin(t *testing.T) {
 	}
 
 	w := New(s)
-	defer w.Stop()
 
 	_, _, totalPages, totalExposed := w.Page()
 	if totalExposed != PageSize+500 {
@@ -92,7 +89,6 @@ func TestWallTextContent(t *testing.T) {
 	s.Submit("Beta", "user4") // exposes "beta" (count=2)
 
 	w := New(s)
-	defer w.Stop()
 
 	text, _, _, totalExposed := w.Page()
 	if totalExposed != 2 {
@@ -104,6 +100,32 @@ func TestWallTextContent(t *testing.T) {
 	}
 }
 
+func TestWallEntriesCarryLens(t *testing.T) {
+	s := store.New()
+
+	s.Submit("hello", "alice")
+	s.Submit("HELLO", "bob")   // exposes "hello"
+	s.Submit("Hello", "carol") // later collisions keep the first lens
+	s.Submit("quiet", "dave")  // still a secret
+
+	w := New(s)
+
+	entries, _, totalPages, totalExposed := w.Entries()
+	if totalExposed != 1 || totalPages != 1 {
+		t.Fatalf("expected 1 exposed on 1 page, got %d on %d", totalExposed, totalPages)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	e := entries[0]
+	if e.Value != "hello" || e.Count != 3 {
+		t.Errorf("got %q count=%d, want \"hello\" count=3", e.Value, e.Count)
+	}
+	if e.Lens == "" {
+		t.Error("expected the exposing lens on the entry")
+	}
+}
+
 func itoa(n int) string {
 	if n == 0 {
 		return "0"
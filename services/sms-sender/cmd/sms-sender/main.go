@@ -0,0 +1,292 @@
+// sms-sender - the nexus SMS gateway
+// Copyright (C) 2026  nexus contributors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/jredh-dev/nexus/internal/health"
+	"github.com/jredh-dev/nexus/internal/httpserver"
+	"github.com/jredh-dev/nexus/internal/logging"
+	"github.com/jredh-dev/nexus/internal/secretsmgr"
+	"github.com/jredh-dev/nexus/internal/smsinbox"
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
+	"github.com/jredh-dev/nexus/internal/smsstatus"
+	"github.com/jredh-dev/nexus/services/sms-sender/config"
+	"github.com/jredh-dev/nexus/services/sms-sender/internal/delay"
+	"github.com/jredh-dev/nexus/services/sms-sender/internal/email"
+	"github.com/jredh-dev/nexus/services/sms-sender/internal/inbound"
+	"github.com/jredh-dev/nexus/services/sms-sender/internal/metrics"
+	"github.com/jredh-dev/nexus/services/sms-sender/internal/optout"
+	"github.com/jredh-dev/nexus/services/sms-sender/internal/outbound"
+	"github.com/jredh-dev/nexus/services/sms-sender/internal/receipt"
+	"github.com/jredh-dev/nexus/services/sms-sender/internal/render"
+	"github.com/jredh-dev/nexus/services/sms-sender/internal/sender"
+	"github.com/jredh-dev/nexus/services/sms-sender/internal/telnyx"
+)
+
+var (
+	version   = "dev"
+	commit    = "unknown"
+	buildDate = "unknown"
+)
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		os.Exit(replayMain(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(migrateMain())
+	}
+
+	showVersion := flag.Bool("version", false, "Show version information")
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Printf("sms-sender %s\n", version)
+		fmt.Printf("Commit: %s\n", commit)
+		fmt.Printf("Built: %s\n", buildDate)
+		os.Exit(0)
+	}
+
+	if err := logging.Setup("sms-sender"); err != nil {
+		fmt.Fprintf(os.Stderr, "sms-sender: %v\n", err)
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sms-sender: %v\n", err)
+		os.Exit(1)
+	}
+	slog.Info("config", "settings", cfg.Settings)
+	if len(cfg.Kafka.Brokers) == 0 {
+		logging.Fatal("SMS_KAFKA_BROKERS is required")
+	}
+	s, err := newSender(cfg)
+	if err != nil {
+		logging.Fatal("sms backend", "backend", cfg.Backend, "err", err)
+	}
+	// A Telnyx key rotated in its secrets store is picked up without a
+	// restart. One set only in the config file can't rotate.
+	var serverOpts []httpserver.Option
+	if t, ok := s.(*sender.Telnyx); ok {
+		if key, err := secretsmgr.Default().Secret(context.Background(), "TELNYX_API_KEY"); err == nil {
+			key.OnRotate(t.SetAPIKey)
+			serverOpts = append(serverOpts, httpserver.WithWorker("secret rotation", func(ctx context.Context) {
+				secretsmgr.Default().Watch(ctx, secretsmgr.WatchEvery)
+			}))
+		}
+	}
+	m := metrics.New()
+	s = m.Sender(s)
+
+	templates, err := render.Load(cfg.Templates.Dir, cfg.Templates.MaxSegments)
+	if err != nil {
+		logging.Fatal("sms templates", "dir", cfg.Templates.Dir, "err", err)
+	}
+	slog.Info("sms templates loaded", "dir", cfg.Templates.Dir, "templates", templates.Names())
+
+	// Sends are recorded so Telnyx's delivery reports can be matched to
+	// them and published to sms-status.
+	store, err := receipt.Open(cfg.DBPath)
+	if err != nil {
+		logging.Fatal("receipts database", "path", cfg.DBPath, "err", err)
+	}
+	defer store.Close()
+	statuses := smsstatus.NewKafkaPublisher(cfg.Kafka.Brokers, cfg.Kafka.StatusTopic)
+	defer statuses.Close()
+	receipts := receipt.NewTracker(store, statuses, cfg.Dedupe)
+
+	// Numbers that texted STOP get nothing more until they text START.
+	optOuts, err := optout.Open(cfg.DBPath)
+	if err != nil {
+		logging.Fatal("opt-out database", "path", cfg.DBPath, "err", err)
+	}
+	defer optOuts.Close()
+
+	// The consumers stop with the server.
+	ctx, stopConsumer := context.WithCancel(context.Background())
+	defer stopConsumer()
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: cfg.Kafka.Brokers,
+		GroupID: cfg.Kafka.GroupID,
+		Topic:   cfg.Kafka.OutboxTopic,
+	})
+	defer reader.Close()
+	delayReader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: cfg.Kafka.Brokers,
+		GroupID: cfg.Kafka.GroupID + "-delayed",
+		Topic:   cfg.Kafka.DelayTopic,
+	})
+	defer delayReader.Close()
+	m.WatchLag(cfg.Kafka.OutboxTopic, reader)
+	m.WatchLag(cfg.Kafka.DelayTopic, delayReader)
+	outbox, dlq, delayed := writer(cfg, cfg.Kafka.OutboxTopic), writer(cfg, cfg.Kafka.DLQTopic), writer(cfg, cfg.Kafka.DelayTopic)
+	defer outbox.Close()
+	defer dlq.Close()
+	defer delayed.Close()
+
+	retry := sender.Retry{Attempts: cfg.Retry.Attempts, Backoff: cfg.Retry.Backoff, MaxBackoff: cfg.Retry.MaxBackoff}
+	consumer := outbound.New(reader, s, retry, dlq, delayed, receipts, templates, optOuts)
+	consumer.SetObserver(m.Outcome)
+	// running counts the live consumers for /healthz; one that stops
+	// before shutdown has failed, and restarting sms-sender is the fix.
+	var running atomic.Int32
+	var consumers int32
+	consumed := make(chan struct{}, 3)
+	start := func(run func(context.Context)) {
+		consumers++
+		running.Add(1)
+		go func() {
+			defer func() { running.Add(-1); consumed <- struct{}{} }()
+			run(ctx)
+		}()
+	}
+	start(consumer.Run)
+	start(delay.New(delayReader, outbox, delayed, cfg.Kafka.DelayRecheck).Run)
+	slog.Info("sending sms", "backend", s.Name(), "topic", cfg.Kafka.OutboxTopic, "dlq", cfg.Kafka.DLQTopic,
+		"delayed", cfg.Kafka.DelayTopic, "brokers", cfg.Kafka.Brokers)
+
+	// Email goes through the same retries and dead-lettering, from its
+	// own topic, when a relay is configured.
+	if cfg.Email.Host != "" {
+		emailReader := kafka.NewReader(kafka.ReaderConfig{
+			Brokers: cfg.Kafka.Brokers,
+			GroupID: cfg.Kafka.GroupID + "-email",
+			Topic:   cfg.Email.OutboxTopic,
+		})
+		defer emailReader.Close()
+		m.WatchLag(cfg.Email.OutboxTopic, emailReader)
+		emailDLQ := writer(cfg, cfg.Email.DLQTopic)
+		defer emailDLQ.Close()
+		mailer := email.NewSMTP(cfg.Email.Host, cfg.Email.Port, cfg.Email.User, cfg.Email.Password, cfg.Email.From)
+		emailConsumer := email.New(emailReader, mailer, retry, emailDLQ)
+		emailConsumer.SetObserver(m.Outcome)
+		start(emailConsumer.Run)
+		slog.Info("sending email", "relay", cfg.Email.Host, "topic", cfg.Email.OutboxTopic, "dlq", cfg.Email.DLQTopic)
+	} else {
+		slog.Warn("email disabled", "hint", "set EMAIL_SMTP_HOST")
+	}
+
+	checks := health.New()
+	checks.Live("consumers", func(context.Context) error {
+		if n := running.Load(); n != consumers {
+			return fmt.Errorf("%d of %d consumers running", n, consumers)
+		}
+		return nil
+	})
+	checks.Ready("database", store.Ping)
+	checks.Ready("kafka", health.Kafka(cfg.Kafka.Brokers))
+	checks.Ready("disk", health.DiskSpace(filepath.Dir(cfg.DBPath), 100<<20))
+
+	srv := httpserver.New(append(serverOpts,
+		httpserver.WithMetrics(m.Common()),
+		httpserver.WithHealth(checks),
+		httpserver.WithVersion(httpserver.Version{Service: "sms-sender", Version: version, Commit: commit, Built: buildDate}),
+		httpserver.WithShutdownHook(stopConsumer),
+	)...)
+	r := srv.Router
+
+	// Inbound texts are published to sms-inbox. The webhook is
+	// authenticated by Telnyx's signature, so it needs the account's key.
+	if cfg.Telnyx.PublicKey != "" {
+		key, err := telnyx.ParsePublicKey(cfg.Telnyx.PublicKey)
+		if err != nil {
+			logging.Fatal("TELNYX_PUBLIC_KEY", "err", err)
+		}
+		inbox := smsinbox.NewKafkaPublisher(cfg.Kafka.Brokers, cfg.Kafka.InboxTopic)
+		defer inbox.Close()
+		replies := smsoutbox.NewKafkaPublisher(cfg.Kafka.Brokers, cfg.Kafka.OutboxTopic)
+		defer replies.Close()
+		keywords := optout.NewResponder(optOuts, replies, optout.Replies{Stop: cfg.Replies.Stop, Start: cfg.Replies.Start, Help: cfg.Replies.Help})
+		r.Method(http.MethodPost, "/webhooks/telnyx", inbound.New(key, inbox, keywords, receipts, cfg.Telnyx.Tolerance))
+		slog.Info("inbound sms enabled", "topic", cfg.Kafka.InboxTopic, "status_topic", cfg.Kafka.StatusTopic, "brokers", cfg.Kafka.Brokers)
+	} else {
+		slog.Warn("inbound sms disabled", "hint", "set TELNYX_PUBLIC_KEY")
+	}
+
+	// Producers can look up what became of a message they sent.
+	if cfg.APIKey != "" {
+		r.Get("/messages/{id}", receipt.Handler(store, cfg.APIKey))
+	} else {
+		slog.Warn("receipt lookup disabled", "hint", "set SMS_API_KEY")
+	}
+
+	addr := ":" + cfg.Port
+	slog.Info("sms-sender starting", "addr", addr, "version", version, "metrics", "http://localhost"+addr+"/metrics")
+
+	if err := srv.ListenAndServe(addr); err != nil {
+		logging.Fatal("server", "err", err)
+	}
+
+	for range consumers {
+		<-consumed
+	}
+}
+
+// migrateMain runs `sms-sender migrate`: it applies the schemas of the
+// receipts and opt-out stores in SMS_DB_PATH and exits, so the database
+// can be prepared before the service starts. It returns the exit code.
+func migrateMain() int {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	store, err := receipt.Open(cfg.DBPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "receipts database:", err)
+		return 1
+	}
+	store.Close()
+	optOuts, err := optout.Open(cfg.DBPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "opt-out database:", err)
+		return 1
+	}
+	optOuts.Close()
+	fmt.Fprintf(os.Stderr, "migrations applied to %s\n", cfg.DBPath)
+	return 0
+}
+
+// writer returns a writer to topic, keyed like the producers' so a
+// recipient's texts stay in order.
+func writer(cfg *config.Config, topic string) *kafka.Writer {
+	return &kafka.Writer{
+		Addr:         kafka.TCP(cfg.Kafka.Brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+	}
+}
+
+// newSender returns the Sender SMS_BACKEND names.
+func newSender(cfg *config.Config) (sender.Sender, error) {
+	switch cfg.Backend {
+	case "telnyx":
+		return sender.NewTelnyx(cfg.Telnyx.APIKey, cfg.From, cfg.Telnyx.ProfileID)
+	case "twilio":
+		return sender.NewTwilio(cfg.Twilio.AccountSID, cfg.Twilio.AuthToken, cfg.From)
+	case "gateway":
+		return sender.NewGateway(cfg.Gateway.URL, cfg.Gateway.User, cfg.Gateway.Password)
+	default:
+		return nil, fmt.Errorf("SMS_BACKEND %q: want telnyx, twilio or gateway", cfg.Backend)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/jredh-dev/nexus/services/sms-sender/config"
+	"github.com/jredh-dev/nexus/services/sms-sender/internal/replay"
+)
+
+// replayMain runs `sms-sender replay`: it lists the dead letters a filter
+// matches and, with -publish, puts them back on sms-outbox, or with
+// -channel email on email-outbox. It returns the exit code.
+func replayMain(args []string) int {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	channel := fs.String("channel", "sms", "Which dead letters to replay: sms or email")
+	keys := fs.String("key", "", "Only messages to these recipients (comma-separated E.164 numbers or email addresses)")
+	since := fs.String("since", "", "Only messages dead-lettered at or after this RFC 3339 time, or this long ago (e.g. 24h)")
+	until := fs.String("until", "", "Only messages dead-lettered before this RFC 3339 time, or this long ago")
+	publish := fs.Bool("publish", false, "Republish the matching messages to the outbox; without it they are only listed")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: sms-sender replay [-channel sms|email] [-key recipients] [-since time] [-until time] [-publish]")
+		fmt.Fprintln(fs.Output(), "\nLists dead-lettered texts from SMS_DLQ_TOPIC and, with -publish, republishes them to SMS_OUTBOX_TOPIC.")
+		fmt.Fprintln(fs.Output(), "With -channel email, the same for emails, from EMAIL_DLQ_TOPIC to EMAIL_OUTBOX_TOPIC.")
+		fmt.Fprintln(fs.Output(), "A text that fails again is dead-lettered again, so narrow the replay with -since.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+	if *channel != "sms" && *channel != "email" {
+		fmt.Fprintf(os.Stderr, "-channel %q: want sms or email\n", *channel)
+		return 2
+	}
+
+	now := time.Now()
+	f := replay.Filter{Keys: replay.ParseKeys(*keys)}
+	var err error
+	if f.Since, err = replay.ParseTime(*since, now); err != nil {
+		fmt.Fprintln(os.Stderr, "-since:", err)
+		return 2
+	}
+	if f.Until, err = replay.ParseTime(*until, now); err != nil {
+		fmt.Fprintln(os.Stderr, "-until:", err)
+		return 2
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if len(cfg.Kafka.Brokers) == 0 {
+		fmt.Fprintln(os.Stderr, "SMS_KAFKA_BROKERS is required")
+		return 1
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	dlqTopic, outboxTopic := cfg.Kafka.DLQTopic, cfg.Kafka.OutboxTopic
+	if *channel == "email" {
+		dlqTopic, outboxTopic = cfg.Email.DLQTopic, cfg.Email.OutboxTopic
+	}
+
+	msgs, err := replay.ReadAll(ctx, cfg.Kafka.Brokers, dlqTopic)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "read dead letters:", err)
+		return 1
+	}
+	picked := replay.Select(msgs, f)
+	for _, m := range picked {
+		fmt.Println(replay.Describe(m))
+	}
+	fmt.Fprintf(os.Stderr, "%d of %d dead letters in %s match\n", len(picked), len(msgs), dlqTopic)
+	if !*publish || len(picked) == 0 {
+		if len(picked) > 0 {
+			fmt.Fprintln(os.Stderr, "rerun with -publish to replay them")
+		}
+		return 0
+	}
+
+	w := &kafka.Writer{
+		Addr:         kafka.TCP(cfg.Kafka.Brokers...),
+		Topic:        outboxTopic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+	}
+	defer w.Close()
+	if err := replay.Republish(ctx, w, picked); err != nil {
+		fmt.Fprintln(os.Stderr, "republish:", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "replayed %d to %s\n", len(picked), outboxTopic)
+	return 0
+}
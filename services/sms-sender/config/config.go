@@ -0,0 +1,176 @@
+package config
+
+import (
+	"time"
+
+	"github.com/jredh-dev/nexus/internal/emailoutbox"
+	"github.com/jredh-dev/nexus/internal/settings"
+	"github.com/jredh-dev/nexus/internal/smsinbox"
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
+	"github.com/jredh-dev/nexus/internal/smsstatus"
+	"github.com/jredh-dev/nexus/services/sms-sender/internal/delay"
+	"github.com/jredh-dev/nexus/services/sms-sender/internal/email"
+	"github.com/jredh-dev/nexus/services/sms-sender/internal/outbound"
+)
+
+// Config holds all configuration for the SMS service.
+type Config struct {
+	Port      string
+	DBPath    string        // where delivery receipts and the opt-out list are kept
+	APIKey    string        // bearer key for GET /messages/{id}; the endpoint is off without it
+	Dedupe    time.Duration // a message ID sent within this long isn't sent again
+	Templates TemplateConfig
+	Replies   RepliesConfig
+	Backend   string // the provider texts are sent through: telnyx, twilio or gateway
+	From      string // the number texts are sent from, in E.164 format
+	Kafka     KafkaConfig
+	Retry     RetryConfig
+	Telnyx    TelnyxConfig
+	Twilio    TwilioConfig
+	Gateway   GatewayConfig
+	Email     EmailConfig
+
+	Settings settings.Values // everything above as loaded, for logging at boot
+}
+
+// KafkaConfig holds the brokers and the topics sms-sender uses.
+type KafkaConfig struct {
+	Brokers     []string
+	OutboxTopic string // texts to send are read from here
+	DLQTopic    string // texts that couldn't be sent are written here
+	DelayTopic  string // texts with a future send_at wait here
+	GroupID     string // consumer group reading OutboxTopic, and with "-delayed" DelayTopic
+	InboxTopic  string // inbound texts are published here
+	StatusTopic string // final delivery statuses are published here
+
+	// DelayRecheck is how often a parked text is looked at again, and so
+	// how late after its send_at it may go out.
+	DelayRecheck time.Duration
+}
+
+// TemplateConfig says where message templates are and how long a rendered
+// text may be.
+type TemplateConfig struct {
+	Dir         string // one file per template, named <name>.tmpl; none if empty
+	MaxSegments int    // rendered texts are cut to this many SMS segments
+}
+
+// RepliesConfig holds the texts sent back to the STOP, START and HELP
+// keywords. Carriers require STOP and HELP to be answered.
+type RepliesConfig struct {
+	Stop  string
+	Start string
+	Help  string
+}
+
+// RetryConfig says how often a failed send is tried again.
+type RetryConfig struct {
+	Attempts   int           // tries per message, including the first
+	Backoff    time.Duration // the wait after the first failed try; it doubles after each, with jitter
+	MaxBackoff time.Duration // the longest wait
+}
+
+// TelnyxConfig holds Telnyx settings. The inbound webhook is disabled
+// unless PublicKey is set, since its deliveries can't be verified without
+// it.
+type TelnyxConfig struct {
+	APIKey    string
+	ProfileID string        // messaging profile to send from if From is empty
+	PublicKey string        // base64 ed25519 key from the Telnyx portal
+	Tolerance time.Duration // how stale a webhook's signature may be
+}
+
+// TwilioConfig holds Twilio settings, used with SMS_BACKEND=twilio. From
+// may be a messaging service SID instead of a number.
+type TwilioConfig struct {
+	AccountSID string
+	AuthToken  string
+}
+
+// GatewayConfig holds settings for a self-hosted android-sms-gateway
+// server, used with SMS_BACKEND=gateway. Texts go out from its phone's
+// number, so From is unused.
+type GatewayConfig struct {
+	URL      string
+	User     string
+	Password string
+}
+
+// EmailConfig holds the email channel's settings. The channel is off
+// unless Host is set. For Amazon SES, use its SMTP endpoint and SMTP
+// credentials.
+type EmailConfig struct {
+	Host        string // SMTP relay
+	Port        string
+	User        string // no authentication if empty
+	Password    string
+	From        string // sender address, optionally as "Name <addr>"
+	OutboxTopic string // emails to send are read from here, by GroupID with "-email"
+	DLQTopic    string // emails that couldn't be sent are written here
+}
+
+// Load reads configuration from environment variables, and from the YAML
+// file SMS_CONFIG_FILE names if set, with sensible defaults.
+func Load() (*Config, error) {
+	l := settings.New("SMS_CONFIG_FILE")
+	cfg := &Config{
+		Port:    l.String("SMS_PORT", "8087"),
+		DBPath:  l.String("SMS_DB_PATH", "sms-sender.db"),
+		APIKey:  l.Secret("SMS_API_KEY", ""),
+		Dedupe:  l.Duration("SMS_DEDUPE_WINDOW", 24*time.Hour),
+		Backend: l.OneOf("SMS_BACKEND", "telnyx", "telnyx", "twilio", "gateway"),
+		From:    l.String("SMS_FROM", ""),
+		Kafka: KafkaConfig{
+			Brokers:     l.List("SMS_KAFKA_BROKERS"),
+			OutboxTopic: l.String("SMS_OUTBOX_TOPIC", smsoutbox.Topic),
+			DLQTopic:    l.String("SMS_DLQ_TOPIC", outbound.DLQTopic),
+			DelayTopic:  l.String("SMS_DELAY_TOPIC", delay.Topic),
+			GroupID:     l.String("SMS_CONSUMER_GROUP", "sms-sender"),
+			InboxTopic:  l.String("SMS_INBOX_TOPIC", smsinbox.Topic),
+			StatusTopic: l.String("SMS_STATUS_TOPIC", smsstatus.Topic),
+
+			DelayRecheck: l.Duration("SMS_DELAY_RECHECK", time.Minute),
+		},
+		Templates: TemplateConfig{
+			Dir:         l.String("SMS_TEMPLATES_DIR", ""),
+			MaxSegments: l.Int("SMS_MAX_SEGMENTS", 3),
+		},
+		Replies: RepliesConfig{
+			Stop:  l.String("SMS_STOP_REPLY", "You have been unsubscribed and will receive no further messages. Reply START to resubscribe."),
+			Start: l.String("SMS_START_REPLY", "You have been resubscribed. Reply STOP to unsubscribe."),
+			Help:  l.String("SMS_HELP_REPLY", "nexus notifications. Reply STOP to unsubscribe. Msg & data rates may apply."),
+		},
+		Retry: RetryConfig{
+			Attempts:   l.Int("SMS_RETRY_ATTEMPTS", 5),
+			Backoff:    l.Duration("SMS_RETRY_BACKOFF", 2*time.Second),
+			MaxBackoff: l.Duration("SMS_RETRY_MAX_BACKOFF", 16*time.Second),
+		},
+		Telnyx: TelnyxConfig{
+			APIKey:    l.Secret("TELNYX_API_KEY", ""),
+			ProfileID: l.String("TELNYX_MESSAGING_PROFILE_ID", ""),
+			PublicKey: l.String("TELNYX_PUBLIC_KEY", ""),
+			Tolerance: l.Duration("TELNYX_WEBHOOK_TOLERANCE", 5*time.Minute),
+		},
+		Twilio: TwilioConfig{
+			AccountSID: l.String("TWILIO_ACCOUNT_SID", ""),
+			AuthToken:  l.Secret("TWILIO_AUTH_TOKEN", ""),
+		},
+		Gateway: GatewayConfig{
+			URL:      l.String("SMS_GATEWAY_URL", ""),
+			User:     l.String("SMS_GATEWAY_USER", ""),
+			Password: l.Secret("SMS_GATEWAY_PASSWORD", ""),
+		},
+		Email: EmailConfig{
+			Host:        l.String("EMAIL_SMTP_HOST", ""),
+			Port:        l.String("EMAIL_SMTP_PORT", "587"),
+			User:        l.String("EMAIL_SMTP_USER", ""),
+			Password:    l.Secret("EMAIL_SMTP_PASSWORD", ""),
+			From:        l.String("EMAIL_FROM", ""),
+			OutboxTopic: l.String("EMAIL_OUTBOX_TOPIC", emailoutbox.Topic),
+			DLQTopic:    l.String("EMAIL_DLQ_TOPIC", email.DLQTopic),
+		},
+	}
+	l.Check(cfg.Email.Host == "" || cfg.Email.From != "", "EMAIL_FROM is required with EMAIL_SMTP_HOST")
+	cfg.Settings = l.Values()
+	return cfg, l.Err()
+}
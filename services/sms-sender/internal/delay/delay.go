@@ -0,0 +1,111 @@
+// Package delay holds texts with a future send_at until they are due.
+//
+// The outbound consumer moves such a message from sms-outbox to the delay
+// topic. The Scheduler reads the delay topic and re-checks each message
+// once per recheck interval: a message that has come due goes back to
+// sms-outbox to be sent, and one that hasn't is written to the end of the
+// delay topic again. Everything parked is in Kafka, so a restart loses
+// nothing, and a message due next week never holds up one due in a minute
+// for longer than the interval.
+package delay
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
+)
+
+// Topic is the default delay topic.
+const Topic = "sms-delayed"
+
+// Reader is where parked messages come from; a *kafka.Reader in a consumer
+// group on the delay topic.
+type Reader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// Writer is a *kafka.Writer for sms-outbox or the delay topic.
+type Writer interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// Scheduler releases parked messages when they come due.
+type Scheduler struct {
+	r       Reader
+	outbox  Writer
+	delayed Writer
+	recheck time.Duration
+	now     func() time.Time
+}
+
+// New creates a Scheduler that reads parked messages from r, looks at each
+// once per recheck, and writes it to outbox once due or back to delayed if
+// not.
+func New(r Reader, outbox, delayed Writer, recheck time.Duration) *Scheduler {
+	return &Scheduler{r: r, outbox: outbox, delayed: delayed, recheck: recheck, now: time.Now}
+}
+
+// Run releases messages until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	for {
+		m, err := s.r.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Error("fetch delayed sms", "err", err)
+			}
+			return
+		}
+		if err := s.Handle(ctx, m); err != nil {
+			if ctx.Err() == nil {
+				slog.Error("handle delayed sms", "partition", m.Partition, "offset", m.Offset, "err", err)
+			}
+			return
+		}
+		if err := s.r.CommitMessages(ctx, m); err != nil && ctx.Err() == nil {
+			slog.Error("commit delayed sms", "partition", m.Partition, "offset", m.Offset, "err", err)
+		}
+	}
+}
+
+// Handle waits until m is due or has been parked for the recheck
+// interval, whichever is sooner, then writes it to sms-outbox or back to
+// the delay topic. Messages behind m in its partition were parked after
+// it, so waiting on m never makes them later than their own recheck.
+func (s *Scheduler) Handle(ctx context.Context, m kafka.Message) error {
+	var msg smsoutbox.OutboundMessage
+	if err := json.Unmarshal(m.Value, &msg); err != nil {
+		// The outbound consumer dead-letters what it can't read.
+		return s.outbox.WriteMessages(ctx, forward(m))
+	}
+
+	wake := m.Time.Add(s.recheck)
+	if msg.SendAt.Before(wake) {
+		wake = msg.SendAt
+	}
+	if d := wake.Sub(s.now()); d > 0 {
+		t := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+
+	if s.now().Before(msg.SendAt) {
+		return s.delayed.WriteMessages(ctx, forward(m))
+	}
+	slog.Info("delayed sms due", "id", msg.ID, "send_at", msg.SendAt)
+	return s.outbox.WriteMessages(ctx, forward(m))
+}
+
+// forward is m as a new message to write elsewhere.
+func forward(m kafka.Message) kafka.Message {
+	return kafka.Message{Key: m.Key, Value: m.Value, Headers: m.Headers}
+}
@@ -0,0 +1,121 @@
+package delay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
+)
+
+type fakeWriter struct{ msgs []kafka.Message }
+
+func (w *fakeWriter) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	w.msgs = append(w.msgs, msgs...)
+	return nil
+}
+
+// fakeReader serves msgs, then reports the end of the topic.
+type fakeReader struct {
+	msgs      []kafka.Message
+	committed []int64
+}
+
+func (r *fakeReader) FetchMessage(context.Context) (kafka.Message, error) {
+	if len(r.msgs) == 0 {
+		return kafka.Message{}, io.EOF
+	}
+	m := r.msgs[0]
+	r.msgs = r.msgs[1:]
+	return m, nil
+}
+
+func (r *fakeReader) CommitMessages(_ context.Context, msgs ...kafka.Message) error {
+	for _, m := range msgs {
+		r.committed = append(r.committed, m.Offset)
+	}
+	return nil
+}
+
+func parked(t *testing.T, offset int64, at, sendAt time.Time) kafka.Message {
+	t.Helper()
+	value, err := json.Marshal(smsoutbox.OutboundMessage{ID: "m1", To: "+15555550100", Body: "hi", SendAt: sendAt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kafka.Message{Offset: offset, Time: at, Key: []byte("+15555550100"), Value: value, Headers: []kafka.Header{{Key: "trace", Value: []byte("t1")}}}
+}
+
+func TestHandle(t *testing.T) {
+	at := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	// The clock reads past every wait, so Handle never sleeps.
+	newScheduler := func(now time.Time) (*Scheduler, *fakeWriter, *fakeWriter) {
+		outbox, delayed := &fakeWriter{}, &fakeWriter{}
+		s := New(nil, outbox, delayed, time.Minute)
+		s.now = func() time.Time { return now }
+		return s, outbox, delayed
+	}
+
+	t.Run("Due", func(t *testing.T) {
+		s, outbox, delayed := newScheduler(at.Add(time.Hour))
+		m := parked(t, 1, at, at.Add(30*time.Second))
+		if err := s.Handle(context.Background(), m); err != nil {
+			t.Fatal(err)
+		}
+		if len(outbox.msgs) != 1 || len(delayed.msgs) != 0 {
+			t.Fatalf("released %d, re-parked %d; want 1 and 0", len(outbox.msgs), len(delayed.msgs))
+		}
+		if o := outbox.msgs[0]; string(o.Key) != string(m.Key) || string(o.Value) != string(m.Value) || len(o.Headers) != 1 {
+			t.Errorf("released %+v, want the parked message", o)
+		}
+	})
+
+	t.Run("NotDue", func(t *testing.T) {
+		s, outbox, delayed := newScheduler(at.Add(2 * time.Minute))
+		if err := s.Handle(context.Background(), parked(t, 2, at, at.Add(24*time.Hour))); err != nil {
+			t.Fatal(err)
+		}
+		if len(outbox.msgs) != 0 || len(delayed.msgs) != 1 {
+			t.Errorf("released %d, re-parked %d; want 0 and 1", len(outbox.msgs), len(delayed.msgs))
+		}
+	})
+
+	t.Run("Undecodable", func(t *testing.T) {
+		s, outbox, delayed := newScheduler(at)
+		if err := s.Handle(context.Background(), kafka.Message{Time: at, Value: []byte("junk")}); err != nil {
+			t.Fatal(err)
+		}
+		if len(outbox.msgs) != 1 || len(delayed.msgs) != 0 {
+			t.Errorf("released %d, re-parked %d; want 1 and 0", len(outbox.msgs), len(delayed.msgs))
+		}
+	})
+
+	t.Run("ShuttingDown", func(t *testing.T) {
+		s, outbox, delayed := newScheduler(at)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := s.Handle(ctx, parked(t, 3, at, at.Add(time.Hour))); !errors.Is(err, context.Canceled) {
+			t.Errorf("Handle = %v, want context.Canceled", err)
+		}
+		if len(outbox.msgs) != 0 || len(delayed.msgs) != 0 {
+			t.Error("a message interrupted by shutdown was written")
+		}
+	})
+}
+
+func TestRun_CommitsHandled(t *testing.T) {
+	at := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	r := &fakeReader{msgs: []kafka.Message{parked(t, 10, at, at), parked(t, 11, at, at.Add(time.Hour))}}
+	outbox, delayed := &fakeWriter{}, &fakeWriter{}
+	s := New(r, outbox, delayed, time.Minute)
+	s.now = func() time.Time { return at.Add(time.Minute) }
+	s.Run(context.Background())
+	if len(r.committed) != 2 || len(outbox.msgs) != 1 || len(delayed.msgs) != 1 {
+		t.Errorf("committed %v, released %d, re-parked %d", r.committed, len(outbox.msgs), len(delayed.msgs))
+	}
+}
@@ -0,0 +1,106 @@
+// Package email is the email channel of the notification dispatcher: it
+// consumes the email-outbox topic and delivers each email through a
+// Sender, retrying and dead-lettering the way outbound does for texts.
+package email
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/jredh-dev/nexus/internal/emailoutbox"
+	"github.com/jredh-dev/nexus/services/sms-sender/internal/outbound"
+	"github.com/jredh-dev/nexus/services/sms-sender/internal/sender"
+)
+
+// DLQTopic is the default dead-letter topic for email.
+const DLQTopic = "email-dlq"
+
+// Sender hands one email to a mail provider.
+// Implementations must be safe for concurrent use.
+type Sender interface {
+	// Name identifies the backend in logs and dead-letter headers.
+	Name() string
+	// Send submits msg and returns the provider's ID for it. A refusal
+	// that sender.Permanent recognises isn't retried.
+	Send(ctx context.Context, msg emailoutbox.OutboundEmail) (string, error)
+}
+
+// Consumer delivers the emails read from email-outbox.
+type Consumer struct {
+	r       outbound.Reader
+	s       Sender
+	retry   sender.Retry
+	dlq     outbound.Writer
+	observe func(outcome string) // nil when outcomes aren't counted
+	now     func() time.Time
+}
+
+// New creates a Consumer that delivers what r reads with s, retrying as
+// retry says, and dead-letters what it can't to dlq.
+func New(r outbound.Reader, s Sender, retry sender.Retry, dlq outbound.Writer) *Consumer {
+	return &Consumer{r: r, s: s, retry: retry, dlq: dlq, now: time.Now}
+}
+
+// SetObserver registers fn to be told the outcome of every email handled,
+// one of outbound's Outcome values. Call it before Run.
+func (c *Consumer) SetObserver(fn func(outcome string)) {
+	c.observe = fn
+}
+
+func (c *Consumer) outcome(outcome string) {
+	if c.observe != nil {
+		c.observe(outcome)
+	}
+}
+
+// Run consumes until ctx is cancelled. An email is committed once it has
+// been delivered or dead-lettered, so one in flight at shutdown is read
+// again on restart.
+func (c *Consumer) Run(ctx context.Context) {
+	outbound.Consume(ctx, c.r, "outbound email", c.Handle)
+}
+
+// Handle delivers one email, or dead-letters it if it is invalid or its
+// tries run out. It returns an error only if it is neither: a write to
+// Kafka failed, or ctx ended mid-delivery.
+func (c *Consumer) Handle(ctx context.Context, m kafka.Message) error {
+	var msg emailoutbox.OutboundEmail
+	if err := json.Unmarshal(m.Value, &msg); err != nil {
+		return c.deadLetter(ctx, m, fmt.Errorf("decode outbound email: %w", err), 0)
+	}
+	if err := msg.Validate(); err != nil {
+		return c.deadLetter(ctx, m, err, 0)
+	}
+
+	var id string
+	attempts, err := c.retry.Do(ctx, func() error {
+		var err error
+		id, err = c.s.Send(ctx, msg)
+		return err
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		slog.Warn("outbound email failed", "id", msg.ID, "source", msg.Source, "backend", c.s.Name(), "attempts", attempts, "permanent", sender.Permanent(err), "err", err)
+		return c.deadLetter(ctx, m, err, attempts)
+	}
+	slog.Info("outbound email sent", "id", msg.ID, "source", msg.Source, "backend", c.s.Name(), "provider_id", id, "attempts", attempts)
+	c.outcome(outbound.OutcomeSent)
+	return nil
+}
+
+// deadLetter writes m to the dead-letter topic with why it failed.
+func (c *Consumer) deadLetter(ctx context.Context, m kafka.Message, cause error, attempts int) error {
+	if err := outbound.DeadLetter(ctx, c.dlq, m, cause, attempts, c.s.Name(), c.now()); err != nil {
+		return err
+	}
+	slog.Warn("outbound email dead-lettered", "partition", m.Partition, "offset", m.Offset, "attempts", attempts, "err", cause)
+	c.outcome(outbound.OutcomeDeadLettered)
+	return nil
+}
@@ -0,0 +1,144 @@
+package email
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/textproto"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/jredh-dev/nexus/internal/emailoutbox"
+	"github.com/jredh-dev/nexus/services/sms-sender/internal/outbound"
+	"github.com/jredh-dev/nexus/services/sms-sender/internal/sender"
+)
+
+type fakeSender struct {
+	mu   sync.Mutex
+	sent []emailoutbox.OutboundEmail
+	errs []error // returned by the next sends
+}
+
+func (s *fakeSender) Name() string { return "fake" }
+
+func (s *fakeSender) Send(_ context.Context, msg emailoutbox.OutboundEmail) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		return "", err
+	}
+	s.sent = append(s.sent, msg)
+	return "<provider-1@example.com>", nil
+}
+
+type fakeWriter struct {
+	msgs []kafka.Message
+}
+
+func (w *fakeWriter) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	w.msgs = append(w.msgs, msgs...)
+	return nil
+}
+
+var noWait = sender.Retry{Attempts: 3}
+
+func encode(t *testing.T, msg emailoutbox.OutboundEmail) kafka.Message {
+	t.Helper()
+	v, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kafka.Message{Key: []byte(msg.To), Value: v}
+}
+
+func header(m kafka.Message, key string) string {
+	for _, h := range m.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func newConsumer(s Sender, dlq outbound.Writer) (*Consumer, *[]string) {
+	c := New(nil, s, noWait, dlq)
+	c.now = func() time.Time { return time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC) }
+	var outcomes []string
+	c.SetObserver(func(o string) { outcomes = append(outcomes, o) })
+	return c, &outcomes
+}
+
+func TestHandleSends(t *testing.T) {
+	s := &fakeSender{errs: []error{errors.New("connection reset")}}
+	dlq := &fakeWriter{}
+	c, outcomes := newConsumer(s, dlq)
+
+	msg := emailoutbox.OutboundEmail{ID: "m1", To: "a@example.com", Subject: "Hi", Body: "hello", Source: "test"}
+	if err := c.Handle(context.Background(), encode(t, msg)); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.sent) != 1 || s.sent[0].ID != "m1" {
+		t.Fatalf("sent = %+v, want m1 after a retry", s.sent)
+	}
+	if len(dlq.msgs) != 0 {
+		t.Fatalf("dead-lettered %d, want 0", len(dlq.msgs))
+	}
+	if len(*outcomes) != 1 || (*outcomes)[0] != outbound.OutcomeSent {
+		t.Fatalf("outcomes = %v", *outcomes)
+	}
+}
+
+func TestHandleDeadLetters(t *testing.T) {
+	for name, tc := range map[string]struct {
+		value    []byte
+		errs     []error
+		attempts string
+	}{
+		"undecodable": {value: []byte("{"), attempts: "0"},
+		"invalid":     {value: []byte(`{"id":"m1"}`), attempts: "0"},
+		"exhausted": {
+			value:    []byte(`{"id":"m1","to":"a@example.com","subject":"Hi"}`),
+			errs:     []error{errors.New("timeout"), errors.New("timeout"), errors.New("timeout")},
+			attempts: "3",
+		},
+		"refused": {
+			value:    []byte(`{"id":"m1","to":"a@example.com","subject":"Hi"}`),
+			errs:     []error{&textproto.Error{Code: 550, Msg: "no such user"}},
+			attempts: "1",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := &fakeSender{errs: tc.errs}
+			dlq := &fakeWriter{}
+			c, outcomes := newConsumer(s, dlq)
+
+			if err := c.Handle(context.Background(), kafka.Message{Key: []byte("a@example.com"), Value: tc.value}); err != nil {
+				t.Fatal(err)
+			}
+			if len(dlq.msgs) != 1 {
+				t.Fatalf("dead-lettered %d, want 1", len(dlq.msgs))
+			}
+			d := dlq.msgs[0]
+			if string(d.Value) != string(tc.value) {
+				t.Errorf("value = %s, want the original", d.Value)
+			}
+			if got := header(d, outbound.HeaderAttempts); got != tc.attempts {
+				t.Errorf("attempts = %q, want %q", got, tc.attempts)
+			}
+			if got := header(d, outbound.HeaderBackend); got != "fake" {
+				t.Errorf("backend = %q, want fake", got)
+			}
+			if header(d, outbound.HeaderError) == "" {
+				t.Error("no error header")
+			}
+			if len(*outcomes) != 1 || (*outcomes)[0] != outbound.OutcomeDeadLettered {
+				t.Errorf("outcomes = %v", *outcomes)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/jredh-dev/nexus/internal/emailoutbox"
+)
+
+// smtpTimeout bounds one delivery when ctx has no sooner deadline.
+const smtpTimeout = 30 * time.Second
+
+// SMTP sends email through an SMTP relay. Amazon SES is used through its
+// SMTP interface (email-smtp.<region>.amazonaws.com, port 587) with SMTP
+// credentials as user and password.
+type SMTP struct {
+	host     string
+	port     string
+	user     string
+	password string
+	from     string
+}
+
+// NewSMTP creates an SMTP sender. It upgrades to TLS when the relay offers
+// STARTTLS and authenticates only if user is set, so a local Mailpit
+// works with neither.
+func NewSMTP(host, port, user, password, from string) *SMTP {
+	return &SMTP{host: host, port: port, user: user, password: password, from: from}
+}
+
+// Name implements Sender.
+func (s *SMTP) Name() string { return "smtp" }
+
+// Send implements Sender. The provider ID is the Message-ID header it
+// sets.
+func (s *SMTP) Send(ctx context.Context, msg emailoutbox.OutboundEmail) (string, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(s.host, s.port))
+	if err != nil {
+		return "", fmt.Errorf("smtp: %w", err)
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(smtpTimeout)
+	}
+	conn.SetDeadline(deadline)
+
+	c, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		conn.Close()
+		return "", fmt.Errorf("smtp: %w", err)
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: s.host}); err != nil {
+			return "", fmt.Errorf("smtp starttls: %w", err)
+		}
+	}
+	if s.user != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.user, s.password, s.host)); err != nil {
+			return "", fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+
+	id := messageID(msg.ID, s.from)
+	if err := c.Mail(address(s.from)); err != nil {
+		return "", fmt.Errorf("smtp send to %s: %w", msg.To, err)
+	}
+	if err := c.Rcpt(address(msg.To)); err != nil {
+		return "", fmt.Errorf("smtp send to %s: %w", msg.To, err)
+	}
+	w, err := c.Data()
+	if err != nil {
+		return "", fmt.Errorf("smtp send to %s: %w", msg.To, err)
+	}
+	if _, err := w.Write(buildMessage(s.from, id, msg)); err != nil {
+		return "", fmt.Errorf("smtp send to %s: %w", msg.To, err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("smtp send to %s: %w", msg.To, err)
+	}
+	// The relay has accepted the email; a failed QUIT doesn't unsend it.
+	c.Quit()
+	return id, nil
+}
+
+// buildMessage renders the raw RFC 5322 message for an email.
+func buildMessage(from, id string, msg emailoutbox.OutboundEmail) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", headerValue(from))
+	fmt.Fprintf(&b, "To: %s\r\n", headerValue(msg.To))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", headerValue(msg.Subject)))
+	fmt.Fprintf(&b, "Message-ID: %s\r\n", id)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
+
+// messageID derives the Message-ID header from the email's ID, so a
+// redelivered email carries the same one and mail clients can tell.
+func messageID(id, from string) string {
+	domain := "localhost"
+	if at := strings.LastIndexByte(address(from), '@'); at >= 0 {
+		domain = address(from)[at+1:]
+	}
+	id = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' || strings.ContainsRune("<>@\"\\", r) {
+			return '-'
+		}
+		return r
+	}, id)
+	return "<" + id + "@" + domain + ">"
+}
+
+// address returns the bare address in s, which may be in
+// "Name <addr>" form.
+func address(s string) string {
+	if a, err := mail.ParseAddress(s); err == nil {
+		return a.Address
+	}
+	return s
+}
+
+// headerValue makes s safe to write as a single header value, so a CR or
+// LF in a subject or address never starts a new header line.
+func headerValue(s string) string {
+	return strings.Join(strings.FieldsFunc(s, func(r rune) bool { return r == '\r' || r == '\n' }), " ")
+}
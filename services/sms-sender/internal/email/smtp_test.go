@@ -0,0 +1,133 @@
+package email
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jredh-dev/nexus/internal/emailoutbox"
+	"github.com/jredh-dev/nexus/services/sms-sender/internal/sender"
+)
+
+// fakeRelay is just enough of an SMTP server to accept one email, refusing
+// recipients at reject.example.com.
+type fakeRelay struct {
+	ln   net.Listener
+	mu   sync.Mutex
+	data []string // the DATA of each accepted email
+}
+
+func newFakeRelay(t *testing.T) *fakeRelay {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	r := &fakeRelay{ln: ln}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go r.serve(conn)
+		}
+	}()
+	return r
+}
+
+func (r *fakeRelay) serve(conn net.Conn) {
+	defer conn.Close()
+	tc := textproto.NewConn(conn)
+	tc.PrintfLine("220 fake ESMTP")
+	for {
+		line, err := tc.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			tc.PrintfLine("250 fake")
+		case "MAIL", "RSET", "NOOP":
+			tc.PrintfLine("250 OK")
+		case "RCPT":
+			if strings.Contains(arg, "@reject.example.com") {
+				tc.PrintfLine("550 no such user")
+				continue
+			}
+			tc.PrintfLine("250 OK")
+		case "DATA":
+			tc.PrintfLine("354 go ahead")
+			lines, err := tc.ReadDotLines()
+			if err != nil {
+				return
+			}
+			r.mu.Lock()
+			r.data = append(r.data, strings.Join(lines, "\n"))
+			r.mu.Unlock()
+			tc.PrintfLine("250 queued")
+		case "QUIT":
+			tc.PrintfLine("221 bye")
+			return
+		default:
+			tc.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+func (r *fakeRelay) emails() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.data...)
+}
+
+func (r *fakeRelay) sender() *SMTP {
+	host, port, _ := net.SplitHostPort(r.ln.Addr().String())
+	return NewSMTP(host, port, "", "", "Nexus <noreply@nexus.example.com>")
+}
+
+func TestSMTPSend(t *testing.T) {
+	r := newFakeRelay(t)
+	msg := emailoutbox.OutboundEmail{ID: "m1", To: "a@example.com", Subject: "Héllo\r\nBcc: x@example.com", Body: "line one\nline two"}
+	id, err := r.sender().Send(context.Background(), msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "<m1@nexus.example.com>" {
+		t.Errorf("id = %q", id)
+	}
+	emails := r.emails()
+	if len(emails) != 1 {
+		t.Fatalf("relay got %d emails, want 1", len(emails))
+	}
+	data := emails[0]
+	for _, want := range []string{
+		"To: a@example.com\n",
+		"Message-ID: <m1@nexus.example.com>\n",
+		"Subject: =?utf-8?q?H=C3=A9llo_Bcc:_x@example.com?=\n",
+		"\nline one\nline two",
+	} {
+		if !strings.Contains(data, want) {
+			t.Errorf("email lacks %q:\n%s", want, data)
+		}
+	}
+	if strings.Contains(data, "\nBcc:") {
+		t.Errorf("subject injected a header:\n%s", data)
+	}
+}
+
+func TestSMTPSendRefused(t *testing.T) {
+	r := newFakeRelay(t)
+	_, err := r.sender().Send(context.Background(), emailoutbox.OutboundEmail{ID: "m1", To: "a@reject.example.com", Subject: "Hi"})
+	if err == nil {
+		t.Fatal("Send succeeded, want a refusal")
+	}
+	if !sender.Permanent(err) {
+		t.Errorf("Permanent(%v) = false, want true", err)
+	}
+}
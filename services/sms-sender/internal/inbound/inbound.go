@@ -0,0 +1,181 @@
+// Package inbound receives texts from Telnyx's webhooks and publishes them
+// to the sms-inbox topic, so the assistant can answer texts as well as send
+// them. Inbound texts that are STOP, START or HELP are also acted on. The
+// same webhooks report the final status of the texts we send, which go to
+// the receipt tracker.
+package inbound
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jredh-dev/nexus/internal/logging"
+	"github.com/jredh-dev/nexus/internal/smsinbox"
+	"github.com/jredh-dev/nexus/internal/smsstatus"
+	"github.com/jredh-dev/nexus/services/sms-sender/internal/telnyx"
+)
+
+// maxBodySize bounds webhook payloads; an MMS event with a few attachments
+// is a few kilobytes.
+const maxBodySize = 256 << 10
+
+// Receipts takes the final status of outbound texts; a *receipt.Tracker.
+type Receipts interface {
+	Finalize(ctx context.Context, st smsstatus.Status) error
+}
+
+// Keywords acts on inbound texts that are carrier keywords; an
+// *optout.Responder.
+type Keywords interface {
+	Handle(ctx context.Context, msg smsinbox.InboundMessage) error
+}
+
+// Receiver handles Telnyx's messaging webhooks.
+type Receiver struct {
+	key       ed25519.PublicKey
+	pub       smsinbox.Publisher
+	keywords  Keywords
+	receipts  Receipts
+	tolerance time.Duration
+	now       func() time.Time
+}
+
+// New creates a Receiver that accepts webhooks signed with key, publishes
+// inbound texts to pub and passes them to keywords, and hands delivery
+// reports to receipts.
+func New(key ed25519.PublicKey, pub smsinbox.Publisher, keywords Keywords, receipts Receipts, tolerance time.Duration) *Receiver {
+	return &Receiver{key: key, pub: pub, keywords: keywords, receipts: receipts, tolerance: tolerance, now: time.Now}
+}
+
+// ServeHTTP receives a Telnyx webhook. Deliveries with a bad or stale
+// signature are rejected with 401. Inbound texts and delivery reports are
+// handled before answering, and a failure returns 500 so Telnyx retries
+// it; other event types are acknowledged and dropped.
+// POST /webhooks/telnyx
+func (rc *Receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log := logging.FromContext(r.Context())
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+	if err != nil || len(body) > maxBodySize {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+	err = telnyx.Verify(rc.key, body, r.Header.Get(telnyx.TimestampHeader), r.Header.Get(telnyx.SignatureHeader), rc.now(), rc.tolerance)
+	if err != nil {
+		log.Warn("telnyx webhook refused", "err", err)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	event, err := telnyx.DecodeEvent(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch event.Data.EventType {
+	case telnyx.EventMessageReceived:
+		err = rc.received(r.Context(), event)
+	case telnyx.EventMessageFinalized:
+		err = rc.finalized(r.Context(), event)
+	default:
+		log.Debug("telnyx webhook ignored", "event", event.Data.ID, "type", event.Data.EventType)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if errors.Is(err, errInvalid) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Error("telnyx webhook", "event", event.Data.ID, "type", event.Data.EventType, "err", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+var errInvalid = errors.New("invalid payload")
+
+// received publishes an inbound text and acts on it if it is a keyword.
+func (rc *Receiver) received(ctx context.Context, event telnyx.Event) error {
+	m, err := event.Message()
+	if err != nil {
+		return errors.Join(errInvalid, err)
+	}
+	msg := Message(m, event.Data.OccurredAt)
+	if err := msg.Validate(); err != nil {
+		return errors.Join(errInvalid, err)
+	}
+	if err := rc.pub.Publish(ctx, msg); err != nil {
+		return err
+	}
+	logging.FromContext(ctx).Info("inbound sms", "id", msg.ID, "from", msg.From, "to", msg.To, "media", len(msg.Media))
+	return rc.keywords.Handle(ctx, msg)
+}
+
+// finalized hands on an outbound text's final status.
+func (rc *Receiver) finalized(ctx context.Context, event telnyx.Event) error {
+	m, err := event.Message()
+	if err != nil {
+		return errors.Join(errInvalid, err)
+	}
+	st := Status(m, event.Data.OccurredAt)
+	if err := st.Validate(); err != nil {
+		return errors.Join(errInvalid, err)
+	}
+	return rc.receipts.Finalize(ctx, st)
+}
+
+// Status converts a Telnyx finalized message to its delivery status. A
+// message without completed_at is dated by its event; one whose recipient
+// status isn't final gets no state, and so doesn't validate.
+func Status(m telnyx.Message, occurredAt time.Time) smsstatus.Status {
+	st := smsstatus.Status{ProviderID: m.ID, Provider: "telnyx", At: m.CompletedAt.UTC()}
+	if m.CompletedAt.IsZero() {
+		st.At = occurredAt.UTC()
+	}
+	if len(m.To) > 0 {
+		st.To = m.To[0].PhoneNumber
+		switch m.To[0].Status {
+		case telnyx.StatusDelivered:
+			st.State = smsstatus.StateDelivered
+		case telnyx.StatusSendingFailed, telnyx.StatusDeliveryFailed:
+			st.State = smsstatus.StateFailed
+		case telnyx.StatusDeliveryUnconfirmed:
+			st.State = smsstatus.StateUnconfirmed
+		}
+	}
+	var errs []string
+	for _, e := range m.Errors {
+		errs = append(errs, e.String())
+	}
+	st.Error = strings.Join(errs, "; ")
+	return st
+}
+
+// Message converts a Telnyx inbound message to the sms-inbox payload. A
+// message without received_at is dated by its event.
+func Message(m telnyx.Message, occurredAt time.Time) smsinbox.InboundMessage {
+	msg := smsinbox.InboundMessage{
+		ID:         m.ID,
+		From:       m.From.PhoneNumber,
+		Body:       m.Text,
+		Provider:   "telnyx",
+		ReceivedAt: m.ReceivedAt.UTC(),
+	}
+	if len(m.To) > 0 {
+		msg.To = m.To[0].PhoneNumber
+	}
+	for _, media := range m.Media {
+		msg.Media = append(msg.Media, media.URL)
+	}
+	if m.ReceivedAt.IsZero() {
+		msg.ReceivedAt = occurredAt.UTC()
+	}
+	return msg
+}
@@ -0,0 +1,191 @@
+package inbound
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jredh-dev/nexus/internal/smsinbox"
+	"github.com/jredh-dev/nexus/internal/smsstatus"
+	"github.com/jredh-dev/nexus/services/sms-sender/internal/telnyx"
+)
+
+type fakePublisher struct {
+	sent []smsinbox.InboundMessage
+	err  error
+}
+
+func (p *fakePublisher) Publish(_ context.Context, msg smsinbox.InboundMessage) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.sent = append(p.sent, msg)
+	return nil
+}
+
+type fakeKeywords struct {
+	handled []string // message IDs
+	err     error
+}
+
+func (k *fakeKeywords) Handle(_ context.Context, msg smsinbox.InboundMessage) error {
+	k.handled = append(k.handled, msg.ID)
+	return k.err
+}
+
+type fakeReceipts struct {
+	final []smsstatus.Status
+	err   error
+}
+
+func (r *fakeReceipts) Finalize(_ context.Context, st smsstatus.Status) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.final = append(r.final, st)
+	return nil
+}
+
+const received = `{"data":{"event_type":"message.received","id":"ev-1","occurred_at":"2026-10-16T12:00:01Z",
+	"payload":{"id":"msg-1","direction":"inbound","from":{"phone_number":"+15555550100"},
+	"to":[{"phone_number":"+15555550199"}],"text":"are we still on for 6?",
+	"media":[{"url":"https://example.com/a.jpg","content_type":"image/jpeg"}],
+	"received_at":"2026-10-16T12:00:00Z"}}}`
+
+func TestReceiver(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 10, 16, 12, 0, 2, 0, time.UTC)
+	post := func(rc *Receiver, body string, signed bool) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/telnyx", strings.NewReader(body))
+		ts := strconv.FormatInt(now.Unix(), 10)
+		req.Header.Set(telnyx.TimestampHeader, ts)
+		if signed {
+			sig := ed25519.Sign(priv, []byte(ts+"|"+body))
+			req.Header.Set(telnyx.SignatureHeader, base64.StdEncoding.EncodeToString(sig))
+		}
+		w := httptest.NewRecorder()
+		rc.ServeHTTP(w, req)
+		return w
+	}
+	newReceiver := func(p *fakePublisher, keywords *fakeKeywords, receipts *fakeReceipts) *Receiver {
+		rc := New(pub, p, keywords, receipts, 5*time.Minute)
+		rc.now = func() time.Time { return now }
+		return rc
+	}
+
+	p, keywords, receipts := &fakePublisher{}, &fakeKeywords{}, &fakeReceipts{}
+	rc := newReceiver(p, keywords, receipts)
+	if w := post(rc, received, true); w.Code != http.StatusNoContent {
+		t.Fatalf("message.received: %d %s", w.Code, w.Body)
+	}
+	if len(p.sent) != 1 || len(keywords.handled) != 1 {
+		t.Fatalf("published %d messages and checked %d for keywords, want 1", len(p.sent), len(keywords.handled))
+	}
+	want := smsinbox.InboundMessage{
+		ID: "msg-1", From: "+15555550100", To: "+15555550199", Body: "are we still on for 6?",
+		Media: []string{"https://example.com/a.jpg"}, Provider: "telnyx",
+		ReceivedAt: time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC),
+	}
+	if got := p.sent[0]; got.ID != want.ID || got.From != want.From || got.To != want.To || got.Body != want.Body ||
+		len(got.Media) != 1 || got.Media[0] != want.Media[0] || got.Provider != want.Provider || !got.ReceivedAt.Equal(want.ReceivedAt) {
+		t.Errorf("published %+v, want %+v", got, want)
+	}
+
+	// Unsigned deliveries are refused and not published.
+	if w := post(rc, received, false); w.Code != http.StatusUnauthorized {
+		t.Errorf("unsigned: %d, want 401", w.Code)
+	}
+	// Other events are acknowledged so Telnyx stops sending them.
+	if w := post(rc, `{"data":{"event_type":"message.sent","id":"ev-2","payload":{}}}`, true); w.Code != http.StatusNoContent {
+		t.Errorf("message.sent: %d, want 204", w.Code)
+	}
+	if w := post(rc, `{"data":{"event_type":"message.received","id":"ev-3","payload":{"id":"msg-3"}}}`, true); w.Code != http.StatusBadRequest {
+		t.Errorf("message without a sender: %d, want 400", w.Code)
+	}
+	if len(p.sent) != 1 {
+		t.Errorf("published %d messages, want 1", len(p.sent))
+	}
+
+	// A publish failure asks Telnyx to retry.
+	if w := post(newReceiver(&fakePublisher{err: errors.New("broker down")}, keywords, receipts), received, true); w.Code != http.StatusInternalServerError {
+		t.Errorf("publish failure: %d, want 500", w.Code)
+	}
+	if w := post(newReceiver(p, &fakeKeywords{err: errors.New("disk full")}, receipts), received, true); w.Code != http.StatusInternalServerError {
+		t.Errorf("keyword failure: %d, want 500", w.Code)
+	}
+
+	// Delivery reports go to the receipts.
+	if w := post(rc, finalized, true); w.Code != http.StatusNoContent {
+		t.Fatalf("message.finalized: %d %s", w.Code, w.Body)
+	}
+	if len(receipts.final) != 1 || receipts.final[0].ProviderID != "msg-2" || receipts.final[0].State != smsstatus.StateFailed {
+		t.Errorf("finalized %+v", receipts.final)
+	}
+	if w := post(rc, strings.Replace(finalized, "delivery_failed", "sending", 1), true); w.Code != http.StatusBadRequest {
+		t.Errorf("report that isn't final: %d, want 400", w.Code)
+	}
+	if w := post(newReceiver(p, keywords, &fakeReceipts{err: errors.New("disk full")}), finalized, true); w.Code != http.StatusInternalServerError {
+		t.Errorf("receipt failure: %d, want 500", w.Code)
+	}
+}
+
+const finalized = `{"data":{"event_type":"message.finalized","id":"ev-4","occurred_at":"2026-10-16T12:00:09Z",
+	"payload":{"id":"msg-2","direction":"outbound","from":{"phone_number":"+15555550199"},
+	"to":[{"phone_number":"+15555550100","status":"delivery_failed"}],"text":"see you at 6",
+	"completed_at":"2026-10-16T12:00:08Z",
+	"errors":[{"code":"40008","title":"Undeliverable","detail":"The destination number is unreachable."}]}}}`
+
+func TestStatus(t *testing.T) {
+	at := time.Date(2026, 10, 16, 12, 0, 9, 0, time.UTC)
+	for status, want := range map[string]string{
+		telnyx.StatusDelivered:           smsstatus.StateDelivered,
+		telnyx.StatusSendingFailed:       smsstatus.StateFailed,
+		telnyx.StatusDeliveryFailed:      smsstatus.StateFailed,
+		telnyx.StatusDeliveryUnconfirmed: smsstatus.StateUnconfirmed,
+		"sent":                           "",
+	} {
+		m := telnyx.Message{ID: "msg-2", To: []telnyx.Endpoint{{PhoneNumber: "+15555550100", Status: status}}}
+		if got := Status(m, at); got.State != want || got.To != "+15555550100" || !got.At.Equal(at) {
+			t.Errorf("%s: status %+v, want state %q", status, got, want)
+		}
+	}
+
+	event, err := telnyx.DecodeEvent([]byte(finalized))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := event.Message()
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := Status(m, event.Data.OccurredAt)
+	if want := "40008 Undeliverable: The destination number is unreachable."; st.Error != want {
+		t.Errorf("Error = %q, want %q", st.Error, want)
+	}
+	if want := time.Date(2026, 10, 16, 12, 0, 8, 0, time.UTC); !st.At.Equal(want) || st.Provider != "telnyx" {
+		t.Errorf("status %+v, want completed at %v by telnyx", st, want)
+	}
+}
+
+func TestMessage_DatedByEvent(t *testing.T) {
+	at := time.Date(2026, 10, 16, 12, 0, 0, 0, time.FixedZone("PDT", -7*3600))
+	msg := Message(telnyx.Message{ID: "m", From: telnyx.Endpoint{PhoneNumber: "+15555550100"}}, at)
+	if !msg.ReceivedAt.Equal(at) || msg.ReceivedAt.Location() != time.UTC {
+		t.Errorf("ReceivedAt = %v, want %v in UTC", msg.ReceivedAt, at)
+	}
+	if msg.To != "" || msg.Media != nil {
+		t.Errorf("message = %+v", msg)
+	}
+}
@@ -0,0 +1,134 @@
+// Package metrics exposes Prometheus metrics for sms-sender: what became
+// of each outbound message, the latency and result of every call to the
+// SMS backend and how far the consumers are behind their topics, on top of
+// the common request metrics of internal/metrics.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/segmentio/kafka-go"
+
+	"github.com/jredh-dev/nexus/internal/metrics"
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
+	"github.com/jredh-dev/nexus/services/sms-sender/internal/sender"
+)
+
+const namespace = "sms"
+
+// Results of a call to the backend.
+const (
+	ResultOK      = "ok"
+	ResultRefused = "refused" // the provider answered with an error
+	ResultError   = "error"   // the provider couldn't be reached
+)
+
+// Metrics holds the service's collectors on top of the common ones.
+// A nil *Metrics is valid and records nothing, so callers need not check
+// whether metrics are enabled.
+type Metrics struct {
+	common *metrics.Metrics
+
+	sendDuration *prometheus.HistogramVec
+	messages     *prometheus.CounterVec
+}
+
+// New creates the service metrics.
+func New() *Metrics {
+	m := &Metrics{
+		common: metrics.New(namespace),
+		sendDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "send_duration_seconds",
+			Help:      "Latency of each call to the SMS backend, by backend and result (ok, refused, error); retries are separate calls.",
+			Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 15},
+		}, []string{"backend", "result"}),
+		messages: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "messages_total",
+			Help:      "Outbound messages handled, by outcome (sent, dead_lettered, parked, blocked, duplicate).",
+		}, []string{"outcome"}),
+	}
+	m.common.MustRegister(m.sendDuration, m.messages)
+	return m
+}
+
+// Common returns the common metrics, for httpserver.WithMetrics.
+func (m *Metrics) Common() *metrics.Metrics {
+	if m == nil {
+		return nil
+	}
+	return m.common
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return m.common.Handler()
+}
+
+// Middleware records the latency of every request; see
+// metrics.Metrics.Middleware.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return m.Common().Middleware(next)
+}
+
+// Outcome records what became of an outbound message.
+func (m *Metrics) Outcome(outcome string) {
+	if m == nil {
+		return
+	}
+	m.messages.WithLabelValues(outcome).Inc()
+}
+
+// Sender wraps s so each of its sends is timed and counted.
+func (m *Metrics) Sender(s sender.Sender) sender.Sender {
+	if m == nil {
+		return s
+	}
+	return &timedSender{Sender: s, m: m}
+}
+
+type timedSender struct {
+	sender.Sender
+	m *Metrics
+}
+
+func (t *timedSender) Send(ctx context.Context, msg smsoutbox.OutboundMessage) (string, error) {
+	start := time.Now()
+	id, err := t.Sender.Send(ctx, msg)
+	result := ResultOK
+	var refused *sender.Error
+	switch {
+	case errors.As(err, &refused):
+		result = ResultRefused
+	case err != nil:
+		result = ResultError
+	}
+	t.m.sendDuration.WithLabelValues(t.Name(), result).Observe(time.Since(start).Seconds())
+	return id, err
+}
+
+// Lagger is a consumer whose lag can be read; a *kafka.Reader.
+type Lagger interface {
+	Stats() kafka.ReaderStats
+}
+
+// WatchLag reports how many messages r is behind the end of topic, read at
+// scrape time. For a reader in a consumer group that is the lag of the
+// partition it last read. Reading it resets r's other stats, which
+// sms-sender doesn't use.
+func (m *Metrics) WatchLag(topic string, r Lagger) {
+	if m == nil {
+		return
+	}
+	m.common.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Name:        "consumer_lag",
+		Help:        "Messages the consumer is behind the end of its topic.",
+		ConstLabels: prometheus.Labels{"topic": topic},
+	}, func() float64 { return float64(r.Stats().Lag) }))
+}
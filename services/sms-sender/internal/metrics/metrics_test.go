@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/segmentio/kafka-go"
+
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
+	"github.com/jredh-dev/nexus/services/sms-sender/internal/sender"
+)
+
+type fakeSender struct{ errs []error }
+
+func (s *fakeSender) Name() string { return "fake" }
+
+func (s *fakeSender) Send(context.Context, smsoutbox.OutboundMessage) (string, error) {
+	err := s.errs[0]
+	s.errs = s.errs[1:]
+	return "p1", err
+}
+
+type fakeLagger struct{ lag int64 }
+
+func (l fakeLagger) Stats() kafka.ReaderStats { return kafka.ReaderStats{Lag: l.lag} }
+
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("scrape status = %d", rec.Code)
+	}
+	body, _ := io.ReadAll(rec.Body)
+	return string(body)
+}
+
+func TestMetrics(t *testing.T) {
+	m := New()
+
+	s := m.Sender(&fakeSender{errs: []error{nil, nil, &sender.Error{Provider: "fake", Status: 500}, errors.New("dial tcp: timeout")}})
+	if s.Name() != "fake" {
+		t.Errorf("wrapped sender is named %q", s.Name())
+	}
+	for range 4 {
+		s.Send(context.Background(), smsoutbox.OutboundMessage{})
+	}
+	m.Outcome("sent")
+	m.Outcome("sent")
+	m.Outcome("dead_lettered")
+	m.WatchLag("sms-outbox", fakeLagger{lag: 42})
+
+	r := chi.NewRouter()
+	r.Use(m.Middleware)
+	r.Get("/messages/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	for _, path := range []string{"/messages/a", "/messages/b", "/nowhere"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	body := scrape(t, m)
+	for _, want := range []string{
+		`sms_send_duration_seconds_count{backend="fake",result="ok"} 2`,
+		`sms_send_duration_seconds_count{backend="fake",result="refused"} 1`,
+		`sms_send_duration_seconds_count{backend="fake",result="error"} 1`,
+		`sms_messages_total{outcome="sent"} 2`,
+		`sms_messages_total{outcome="dead_lettered"} 1`,
+		`sms_consumer_lag{topic="sms-outbox"} 42`,
+		`sms_http_request_duration_seconds_count{method="GET",route="/messages/{id}",status="404"} 2`,
+		`sms_http_request_duration_seconds_count{method="GET",route="unmatched",status="404"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q", want)
+		}
+	}
+}
+
+func TestNilMetrics(t *testing.T) {
+	var m *Metrics
+	m.Outcome("sent")
+	m.WatchLag("sms-outbox", fakeLagger{})
+	s := &fakeSender{}
+	if m.Sender(s) != sender.Sender(s) {
+		t.Error("nil Metrics wrapped the sender")
+	}
+
+	called := false
+	h := m.Middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { called = true }))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if !called {
+		t.Error("nil Metrics middleware did not call next")
+	}
+}
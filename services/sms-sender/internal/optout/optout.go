@@ -0,0 +1,162 @@
+// Package optout keeps sms-sender carrier-compliant: a number that texts
+// STOP is added to the opt-out list and gets nothing more until it texts
+// START, and HELP is answered with how to opt out.
+//
+// Keywords arrive as inbound texts. The Responder records the opt-out or
+// opt-in and queues a confirmation on sms-outbox; the outbound consumer
+// checks the list before every send.
+package optout
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jredh-dev/nexus/internal/smsinbox"
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
+	"github.com/jredh-dev/nexus/internal/sqlitex"
+)
+
+// Source is the Source of the replies sms-sender sends to keywords. They
+// go out even to opted-out numbers, since carriers require STOP to be
+// confirmed; producers must not use it.
+const Source = "sms-sender"
+
+// Keyword kinds.
+const (
+	Stop  = "stop"
+	Start = "start"
+	Help  = "help"
+)
+
+// keywords are the industry-standard opt-out, opt-in and help keywords.
+var keywords = map[string]string{
+	"STOP": Stop, "STOPALL": Stop, "UNSUBSCRIBE": Stop, "CANCEL": Stop,
+	"END": Stop, "QUIT": Stop, "OPTOUT": Stop, "REVOKE": Stop,
+	"START": Start, "UNSTOP": Start, "SUBSCRIBE": Start,
+	"HELP": Help, "INFO": Help,
+}
+
+// Keyword returns the kind of keyword body is, or "" if it isn't one. A
+// keyword must be the whole text, in any case and with any punctuation
+// around it, so "please stop texting me at work" isn't an opt-out.
+func Keyword(body string) string {
+	word := strings.TrimFunc(body, func(r rune) bool { return unicode.IsSpace(r) || unicode.IsPunct(r) })
+	return keywords[strings.ToUpper(word)]
+}
+
+const schema = `
+CREATE TABLE IF NOT EXISTS opt_outs (
+	number       TEXT PRIMARY KEY,
+	opted_out_at DATETIME NOT NULL
+);
+`
+
+// Store keeps the opt-out list in SQLite.
+type Store struct {
+	conn *sql.DB
+}
+
+// Open creates or opens the SQLite database at path and applies the schema.
+func Open(path string) (*Store, error) {
+	conn, err := sqlitex.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	if _, err := conn.Exec(schema); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("apply schema: %w", err)
+	}
+	return &Store{conn: conn}, nil
+}
+
+// Close shuts down the database connection.
+func (s *Store) Close() error {
+	return s.conn.Close()
+}
+
+// OptOut adds number to the list. Opting out again keeps the first time.
+func (s *Store) OptOut(ctx context.Context, number string, at time.Time) error {
+	_, err := s.conn.ExecContext(ctx, `INSERT INTO opt_outs (number, opted_out_at) VALUES (?, ?) ON CONFLICT DO NOTHING`, number, at.UTC())
+	if err != nil {
+		return fmt.Errorf("opt out %s: %w", number, err)
+	}
+	return nil
+}
+
+// OptIn removes number from the list.
+func (s *Store) OptIn(ctx context.Context, number string) error {
+	if _, err := s.conn.ExecContext(ctx, `DELETE FROM opt_outs WHERE number = ?`, number); err != nil {
+		return fmt.Errorf("opt in %s: %w", number, err)
+	}
+	return nil
+}
+
+// OptedOut reports whether number is on the list.
+func (s *Store) OptedOut(ctx context.Context, number string) (bool, error) {
+	var n int
+	if err := s.conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM opt_outs WHERE number = ?`, number).Scan(&n); err != nil {
+		return false, fmt.Errorf("check opt-out of %s: %w", number, err)
+	}
+	return n > 0, nil
+}
+
+// Replies are the texts sent back to keywords.
+type Replies struct {
+	Stop  string // confirms an opt-out; the last text the number gets
+	Start string // confirms an opt-in
+	Help  string // says who is texting and how to opt out
+}
+
+// Responder acts on keywords in inbound texts.
+type Responder struct {
+	store   *Store
+	outbox  smsoutbox.Publisher
+	replies Replies
+	now     func() time.Time
+}
+
+// NewResponder creates a Responder that keeps the list in store and
+// queues replies on outbox.
+func NewResponder(store *Store, outbox smsoutbox.Publisher, replies Replies) *Responder {
+	return &Responder{store: store, outbox: outbox, replies: replies, now: time.Now}
+}
+
+// Handle updates the list and replies if msg is a keyword, and does
+// nothing otherwise. The reply's ID is derived from msg's, so a
+// redelivered keyword doesn't make a new reply.
+func (r *Responder) Handle(ctx context.Context, msg smsinbox.InboundMessage) error {
+	kind := Keyword(msg.Body)
+	var reply string
+	switch kind {
+	case Stop:
+		if err := r.store.OptOut(ctx, msg.From, msg.ReceivedAt); err != nil {
+			return err
+		}
+		reply = r.replies.Stop
+	case Start:
+		if err := r.store.OptIn(ctx, msg.From); err != nil {
+			return err
+		}
+		reply = r.replies.Start
+	case Help:
+		reply = r.replies.Help
+	default:
+		return nil
+	}
+	slog.Info("sms keyword", "keyword", kind, "from", msg.From, "id", msg.ID)
+	if reply == "" {
+		return nil
+	}
+	return r.outbox.Publish(ctx, smsoutbox.OutboundMessage{
+		ID:        kind + "-reply-" + msg.ID,
+		To:        msg.From,
+		Body:      reply,
+		Source:    Source,
+		CreatedAt: r.now().UTC(),
+	})
+}
@@ -0,0 +1,90 @@
+package optout
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jredh-dev/nexus/internal/smsinbox"
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
+)
+
+type fakePublisher struct{ sent []smsoutbox.OutboundMessage }
+
+func (p *fakePublisher) Publish(_ context.Context, msg smsoutbox.OutboundMessage) error {
+	p.sent = append(p.sent, msg)
+	return nil
+}
+
+func TestKeyword(t *testing.T) {
+	for body, want := range map[string]string{
+		"STOP":                           Stop,
+		" stop\n":                        Stop,
+		"Stop.":                          Stop,
+		"unsubscribe":                    Stop,
+		"START":                          Start,
+		"unstop!":                        Start,
+		"help?":                          Help,
+		"Info":                           Help,
+		"please stop texting me at work": "",
+		"stopwatch":                      "",
+		"":                               "",
+	} {
+		if got := Keyword(body); got != want {
+			t.Errorf("Keyword(%q) = %q, want %q", body, got, want)
+		}
+	}
+}
+
+func TestResponder(t *testing.T) {
+	ctx := context.Background()
+	store, err := Open(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	outbox := &fakePublisher{}
+	r := NewResponder(store, outbox, Replies{Stop: "You're unsubscribed.", Start: "You're subscribed again.", Help: "Reply STOP to unsubscribe."})
+
+	from := "+15555550100"
+	text := func(id, body string) smsinbox.InboundMessage {
+		return smsinbox.InboundMessage{ID: id, From: from, Body: body, ReceivedAt: time.Now()}
+	}
+	optedOut := func() bool {
+		t.Helper()
+		out, err := store.OptedOut(ctx, from)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+
+	if err := r.Handle(ctx, text("in-1", "are we still on for 6?")); err != nil || optedOut() || len(outbox.sent) != 0 {
+		t.Fatalf("ordinary text: %v, opted out %v, replies %d", err, optedOut(), len(outbox.sent))
+	}
+
+	for _, id := range []string{"in-2", "in-2"} { // redelivered
+		if err := r.Handle(ctx, text(id, "STOP")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !optedOut() {
+		t.Error("STOP didn't opt out")
+	}
+	if len(outbox.sent) != 2 || outbox.sent[0].ID != outbox.sent[1].ID {
+		t.Errorf("replies %+v, want the same reply twice", outbox.sent)
+	}
+	if reply := outbox.sent[0]; reply.To != from || reply.Body != "You're unsubscribed." || reply.Source != Source || reply.Validate() != nil {
+		t.Errorf("reply %+v", reply)
+	}
+
+	if err := r.Handle(ctx, text("in-3", "help")); err != nil || !optedOut() {
+		t.Errorf("HELP: %v, opted out %v", err, optedOut())
+	}
+	if err := r.Handle(ctx, text("in-4", "start")); err != nil || optedOut() {
+		t.Errorf("START: %v, opted out %v", err, optedOut())
+	}
+	if got := outbox.sent[len(outbox.sent)-1].Body; got != "You're subscribed again." {
+		t.Errorf("START reply %q", got)
+	}
+}
@@ -0,0 +1,249 @@
+// Package outbound consumes the sms-outbox topic and delivers each message
+// through the configured Sender. A message that can't be delivered goes to
+// the dead-letter topic, with headers saying why, rather than blocking the
+// messages behind it; one with a future send_at is parked on the delay
+// topic until it is due. Consume and DeadLetter are shared with the other
+// channels' consumers.
+package outbound
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
+	"github.com/jredh-dev/nexus/services/sms-sender/internal/optout"
+	"github.com/jredh-dev/nexus/services/sms-sender/internal/sender"
+)
+
+// DLQTopic is the default dead-letter topic.
+const DLQTopic = "sms-dlq"
+
+// Headers on a dead-lettered message of any channel, added to those it
+// had.
+const (
+	HeaderError    = "sms-error"     // why it wasn't delivered
+	HeaderAttempts = "sms-attempts"  // sends tried; 0 if it was never valid
+	HeaderBackend  = "sms-backend"   // the sender that tried
+	HeaderFailedAt = "sms-failed-at" // RFC 3339
+)
+
+// Outcomes of handling a message, reported to the observer.
+const (
+	OutcomeSent         = "sent"
+	OutcomeDeadLettered = "dead_lettered"
+	OutcomeParked       = "parked"
+	OutcomeBlocked      = "blocked"
+	OutcomeDuplicate    = "duplicate"
+)
+
+// retryPause is how long Consume waits before handling a message again
+// when it could be neither delivered nor dead-lettered.
+const retryPause = 5 * time.Second
+
+// Reader is where messages come from; a *kafka.Reader in a consumer group.
+type Reader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// Writer is where dead letters go; a *kafka.Writer.
+type Writer interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// Receipts records what was sent, so the provider's delivery report can be
+// matched to it and a redelivered message isn't sent again, and what was
+// blocked; a *receipt.Tracker.
+type Receipts interface {
+	Duplicate(ctx context.Context, msg smsoutbox.OutboundMessage) (bool, error)
+	Sent(ctx context.Context, msg smsoutbox.OutboundMessage, provider, providerID string) error
+	Blocked(ctx context.Context, msg smsoutbox.OutboundMessage) error
+}
+
+// OptOuts is the list of numbers that mustn't be texted; an *optout.Store.
+type OptOuts interface {
+	OptedOut(ctx context.Context, number string) (bool, error)
+}
+
+// Renderer readies a message to send, filling in its template; a
+// *render.Templates.
+type Renderer interface {
+	Render(msg smsoutbox.OutboundMessage) (smsoutbox.OutboundMessage, error)
+}
+
+// Consumer delivers the messages read from sms-outbox.
+type Consumer struct {
+	r         Reader
+	s         sender.Sender
+	retry     sender.Retry
+	dlq       Writer
+	delayed   Writer
+	receipts  Receipts
+	templates Renderer
+	optOuts   OptOuts
+	observe   func(outcome string) // nil when outcomes aren't counted
+	now       func() time.Time
+}
+
+// New creates a Consumer that delivers what r reads with s, retrying as
+// retry says, dead-letters what it can't to dlq, and parks what isn't due
+// yet on delayed. Messages are rendered with templates just before they
+// are sent, and none go to numbers in optOuts except replies to keywords.
+// Each delivery or block is recorded in receipts.
+func New(r Reader, s sender.Sender, retry sender.Retry, dlq, delayed Writer, receipts Receipts, templates Renderer, optOuts OptOuts) *Consumer {
+	return &Consumer{
+		r: r, s: s, retry: retry, dlq: dlq, delayed: delayed,
+		receipts: receipts, templates: templates, optOuts: optOuts, now: time.Now,
+	}
+}
+
+// SetObserver registers fn to be told the outcome of every message
+// handled. Call it before Run.
+func (c *Consumer) SetObserver(fn func(outcome string)) {
+	c.observe = fn
+}
+
+func (c *Consumer) outcome(outcome string) {
+	if c.observe != nil {
+		c.observe(outcome)
+	}
+}
+
+// Run consumes until ctx is cancelled. A message is committed once it has
+// been delivered or dead-lettered, so one in flight at shutdown is read
+// again on restart.
+func (c *Consumer) Run(ctx context.Context) {
+	Consume(ctx, c.r, "outbound sms", c.Handle)
+}
+
+// Consume passes each message r reads to handle, and commits it once
+// handle succeeds, until ctx is cancelled. A message handle fails on is
+// handled again after a pause rather than skipped. what names the
+// messages in logs.
+func Consume(ctx context.Context, r Reader, what string, handle func(context.Context, kafka.Message) error) {
+	for {
+		m, err := r.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Error("fetch "+what, "err", err)
+			}
+			return
+		}
+		for {
+			err := handle(ctx, m)
+			if err == nil {
+				break
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Error("handle "+what+"; retrying", "partition", m.Partition, "offset", m.Offset, "err", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retryPause):
+			}
+		}
+		if err := r.CommitMessages(ctx, m); err != nil && ctx.Err() == nil {
+			slog.Error("commit "+what, "partition", m.Partition, "offset", m.Offset, "err", err)
+		}
+	}
+}
+
+// Handle delivers one message, parks it if it isn't due, skips it if it
+// was already sent, blocks it if its recipient opted out, or dead-letters
+// it if it is invalid, can't be rendered, or its tries run out. It returns an error only if
+// the message is none of these: a write to Kafka failed, or ctx ended
+// mid-delivery.
+func (c *Consumer) Handle(ctx context.Context, m kafka.Message) error {
+	var msg smsoutbox.OutboundMessage
+	if err := json.Unmarshal(m.Value, &msg); err != nil {
+		return c.deadLetter(ctx, m, fmt.Errorf("decode outbound message: %w", err), 0)
+	}
+	if err := msg.Validate(); err != nil {
+		return c.deadLetter(ctx, m, err, 0)
+	}
+	if msg.SendAt.After(c.now()) {
+		if err := c.delayed.WriteMessages(ctx, kafka.Message{Key: m.Key, Value: m.Value, Headers: m.Headers}); err != nil {
+			return fmt.Errorf("park: %w", err)
+		}
+		slog.Info("outbound sms parked", "id", msg.ID, "source", msg.Source, "send_at", msg.SendAt)
+		c.outcome(OutcomeParked)
+		return nil
+	}
+	dup, err := c.receipts.Duplicate(ctx, msg)
+	if err != nil {
+		return err
+	}
+	if dup {
+		slog.Info("outbound sms already sent; skipped", "id", msg.ID, "source", msg.Source)
+		c.outcome(OutcomeDuplicate)
+		return nil
+	}
+	if msg.Source != optout.Source {
+		out, err := c.optOuts.OptedOut(ctx, msg.To)
+		if err != nil {
+			return err
+		}
+		if out {
+			if err := c.receipts.Blocked(ctx, msg); err != nil {
+				return err
+			}
+			slog.Info("outbound sms blocked; recipient opted out", "id", msg.ID, "source", msg.Source)
+			c.outcome(OutcomeBlocked)
+			return nil
+		}
+	}
+	msg, err = c.templates.Render(msg)
+	if err != nil {
+		return c.deadLetter(ctx, m, err, 0)
+	}
+
+	id, attempts, err := c.retry.Deliver(ctx, c.s, msg)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		slog.Warn("outbound sms failed", "id", msg.ID, "source", msg.Source, "backend", c.s.Name(), "attempts", attempts, "permanent", sender.Permanent(err), "err", err)
+		return c.deadLetter(ctx, m, err, attempts)
+	}
+	slog.Info("outbound sms sent", "id", msg.ID, "source", msg.Source, "backend", c.s.Name(), "provider_id", id, "attempts", attempts)
+	c.outcome(OutcomeSent)
+	// The text is out; failing here would only send it again.
+	if err := c.receipts.Sent(ctx, msg, c.s.Name(), id); err != nil {
+		slog.Error("record sent sms", "id", msg.ID, "provider_id", id, "err", err)
+	}
+	return nil
+}
+
+// deadLetter writes m to the dead-letter topic with why it failed.
+func (c *Consumer) deadLetter(ctx context.Context, m kafka.Message, cause error, attempts int) error {
+	if err := DeadLetter(ctx, c.dlq, m, cause, attempts, c.s.Name(), c.now()); err != nil {
+		return err
+	}
+	slog.Warn("outbound sms dead-lettered", "partition", m.Partition, "offset", m.Offset, "attempts", attempts, "err", cause)
+	c.outcome(OutcomeDeadLettered)
+	return nil
+}
+
+// DeadLetter writes m to dlq with headers saying that backend gave up on
+// it after attempts tries, at at, because of cause.
+func DeadLetter(ctx context.Context, dlq Writer, m kafka.Message, cause error, attempts int, backend string, at time.Time) error {
+	headers := append([]kafka.Header{}, m.Headers...)
+	headers = append(headers,
+		kafka.Header{Key: HeaderError, Value: []byte(cause.Error())},
+		kafka.Header{Key: HeaderAttempts, Value: []byte(strconv.Itoa(attempts))},
+		kafka.Header{Key: HeaderBackend, Value: []byte(backend)},
+		kafka.Header{Key: HeaderFailedAt, Value: []byte(at.UTC().Format(time.RFC3339))},
+	)
+	if err := dlq.WriteMessages(ctx, kafka.Message{Key: m.Key, Value: m.Value, Headers: headers}); err != nil {
+		return fmt.Errorf("dead-letter: %w", err)
+	}
+	return nil
+}
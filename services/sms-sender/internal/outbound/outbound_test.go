@@ -0,0 +1,302 @@
+package outbound
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
+	"github.com/jredh-dev/nexus/services/sms-sender/internal/optout"
+	"github.com/jredh-dev/nexus/services/sms-sender/internal/render"
+	"github.com/jredh-dev/nexus/services/sms-sender/internal/sender"
+)
+
+type fakeSender struct {
+	mu   sync.Mutex
+	sent []smsoutbox.OutboundMessage
+	errs []error // returned by the next sends
+}
+
+func (s *fakeSender) Name() string { return "fake" }
+
+func (s *fakeSender) Send(_ context.Context, msg smsoutbox.OutboundMessage) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		return "", err
+	}
+	s.sent = append(s.sent, msg)
+	return "provider-1", nil
+}
+
+type fakeWriter struct {
+	msgs []kafka.Message
+	err  error
+}
+
+func (w *fakeWriter) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	if w.err != nil {
+		return w.err
+	}
+	w.msgs = append(w.msgs, msgs...)
+	return nil
+}
+
+type fakeReceipts struct {
+	sent    []string // id=provider/provider-id
+	blocked []string // ids
+	err     error
+}
+
+func (r *fakeReceipts) Duplicate(_ context.Context, msg smsoutbox.OutboundMessage) (bool, error) {
+	for _, s := range r.sent {
+		if strings.HasPrefix(s, msg.ID+"=") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeReceipts) Sent(_ context.Context, msg smsoutbox.OutboundMessage, provider, providerID string) error {
+	r.sent = append(r.sent, msg.ID+"="+provider+"/"+providerID)
+	return r.err
+}
+
+func (r *fakeReceipts) Blocked(_ context.Context, msg smsoutbox.OutboundMessage) error {
+	r.blocked = append(r.blocked, msg.ID)
+	return r.err
+}
+
+// fakeOptOuts lists the numbers that opted out.
+type fakeOptOuts map[string]bool
+
+func (o fakeOptOuts) OptedOut(_ context.Context, number string) (bool, error) {
+	return o[number], nil
+}
+
+// fakeReader serves msgs, then reports the end of the topic.
+type fakeReader struct {
+	msgs      []kafka.Message
+	committed []int64
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	if len(r.msgs) == 0 {
+		return kafka.Message{}, io.EOF
+	}
+	m := r.msgs[0]
+	r.msgs = r.msgs[1:]
+	return m, nil
+}
+
+func (r *fakeReader) CommitMessages(_ context.Context, msgs ...kafka.Message) error {
+	for _, m := range msgs {
+		r.committed = append(r.committed, m.Offset)
+	}
+	return nil
+}
+
+func message(t *testing.T, offset int64, msg smsoutbox.OutboundMessage) kafka.Message {
+	t.Helper()
+	value, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kafka.Message{Offset: offset, Key: []byte(msg.To), Value: value, Headers: []kafka.Header{{Key: "trace", Value: []byte("t1")}}}
+}
+
+func header(m kafka.Message, key string) string {
+	for _, h := range m.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+var fastRetry = sender.Retry{Attempts: 3, Backoff: time.Millisecond}
+
+func TestHandle(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	newConsumer := func(s *fakeSender, dlq *fakeWriter) *Consumer {
+		c := New(nil, s, fastRetry, dlq, &fakeWriter{}, &fakeReceipts{err: errors.New("disk full")}, &render.Templates{}, fakeOptOuts{})
+		c.now = func() time.Time { return now }
+		return c
+	}
+	ok := smsoutbox.OutboundMessage{ID: "m1", To: "+15555550100", Body: "hi", Source: "cal"}
+
+	t.Run("Delivered", func(t *testing.T) {
+		s, dlq, receipts := &fakeSender{errs: []error{errors.New("blip")}}, &fakeWriter{}, &fakeReceipts{}
+		if err := New(nil, s, fastRetry, dlq, &fakeWriter{}, receipts, &render.Templates{}, fakeOptOuts{}).Handle(context.Background(), message(t, 1, ok)); err != nil {
+			t.Fatal(err)
+		}
+		if len(s.sent) != 1 || s.sent[0].ID != ok.ID || s.sent[0].Body != ok.Body || len(dlq.msgs) != 0 {
+			t.Errorf("sent %+v, dead-lettered %d", s.sent, len(dlq.msgs))
+		}
+		if len(receipts.sent) != 1 || receipts.sent[0] != "m1=fake/provider-1" {
+			t.Errorf("recorded %q, want the send", receipts.sent)
+		}
+
+		// A send that can't be recorded still counts as delivered.
+		if err := newConsumer(s, dlq).Handle(context.Background(), message(t, 1, ok)); err != nil || len(dlq.msgs) != 0 {
+			t.Errorf("Handle = %v, dead-lettered %d", err, len(dlq.msgs))
+		}
+	})
+
+	t.Run("Templated", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "greet.tmpl"), []byte("Hi {{.name}}!\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		templates, err := render.Load(dir, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, dlq := &fakeSender{}, &fakeWriter{}
+		msg := smsoutbox.OutboundMessage{ID: "m4", To: "+15555550100", Template: "greet", Vars: map[string]string{"name": "Ada"}}
+		if err := New(nil, s, fastRetry, dlq, &fakeWriter{}, &fakeReceipts{}, templates, fakeOptOuts{}).Handle(context.Background(), message(t, 2, msg)); err != nil {
+			t.Fatal(err)
+		}
+		if len(s.sent) != 1 || s.sent[0].Body != "Hi Ada!" || len(dlq.msgs) != 0 {
+			t.Errorf("sent %+v, dead-lettered %d", s.sent, len(dlq.msgs))
+		}
+	})
+
+	t.Run("OptedOut", func(t *testing.T) {
+		s, dlq, receipts := &fakeSender{}, &fakeWriter{}, &fakeReceipts{}
+		c := New(nil, s, fastRetry, dlq, &fakeWriter{}, receipts, &render.Templates{}, fakeOptOuts{ok.To: true})
+		if err := c.Handle(context.Background(), message(t, 2, ok)); err != nil {
+			t.Fatal(err)
+		}
+		if len(s.sent) != 0 || len(dlq.msgs) != 0 || len(receipts.blocked) != 1 {
+			t.Errorf("sent %d, dead-lettered %d, blocked %q", len(s.sent), len(dlq.msgs), receipts.blocked)
+		}
+
+		// The reply confirming the opt-out still goes out.
+		reply := smsoutbox.OutboundMessage{ID: "stop-reply-1", To: ok.To, Body: "unsubscribed", Source: optout.Source}
+		if err := c.Handle(context.Background(), message(t, 3, reply)); err != nil {
+			t.Fatal(err)
+		}
+		if len(s.sent) != 1 || len(receipts.blocked) != 1 {
+			t.Errorf("sent %d, blocked %d; want 1 and 1", len(s.sent), len(receipts.blocked))
+		}
+	})
+
+	t.Run("TriesRunOut", func(t *testing.T) {
+		refused := &sender.Error{Provider: "fake", Status: 500, Message: "boom"}
+		s, dlq := &fakeSender{errs: []error{refused, refused, refused}}, &fakeWriter{}
+		m := message(t, 2, ok)
+		if err := newConsumer(s, dlq).Handle(context.Background(), m); err != nil {
+			t.Fatal(err)
+		}
+		if len(dlq.msgs) != 1 {
+			t.Fatalf("dead-lettered %d messages, want 1", len(dlq.msgs))
+		}
+		d := dlq.msgs[0]
+		if string(d.Key) != string(m.Key) || string(d.Value) != string(m.Value) {
+			t.Errorf("dead letter %q=%q, want the original", d.Key, d.Value)
+		}
+		for key, want := range map[string]string{
+			"trace":        "t1",
+			HeaderError:    refused.Error(),
+			HeaderAttempts: "3",
+			HeaderBackend:  "fake",
+			HeaderFailedAt: "2026-10-16T12:00:00Z",
+		} {
+			if got := header(d, key); got != want {
+				t.Errorf("header %s = %q, want %q", key, got, want)
+			}
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, m := range []kafka.Message{
+			{Value: []byte("not json")},
+			message(t, 3, smsoutbox.OutboundMessage{ID: "m2", To: "+15555550100"}),
+			message(t, 3, smsoutbox.OutboundMessage{ID: "m3", To: "+15555550100", Template: "missing"}),
+		} {
+			s, dlq := &fakeSender{}, &fakeWriter{}
+			if err := newConsumer(s, dlq).Handle(context.Background(), m); err != nil {
+				t.Fatal(err)
+			}
+			if len(s.sent) != 0 || len(dlq.msgs) != 1 || header(dlq.msgs[0], HeaderAttempts) != "0" {
+				t.Errorf("%s: sent %d, dead-lettered %+v", m.Value, len(s.sent), dlq.msgs)
+			}
+		}
+	})
+
+	t.Run("Parked", func(t *testing.T) {
+		s, dlq, delayed := &fakeSender{}, &fakeWriter{}, &fakeWriter{}
+		c := New(nil, s, fastRetry, dlq, delayed, &fakeReceipts{}, &render.Templates{}, fakeOptOuts{})
+		c.now = func() time.Time { return now }
+		later := ok
+		later.SendAt = now.Add(time.Hour)
+		m := message(t, 6, later)
+		if err := c.Handle(context.Background(), m); err != nil {
+			t.Fatal(err)
+		}
+		if len(s.sent) != 0 || len(dlq.msgs) != 0 || len(delayed.msgs) != 1 || string(delayed.msgs[0].Value) != string(m.Value) {
+			t.Errorf("sent %d, dead-lettered %d, parked %+v", len(s.sent), len(dlq.msgs), delayed.msgs)
+		}
+
+		// One already due goes straight out.
+		later.SendAt = now.Add(-time.Minute)
+		if err := c.Handle(context.Background(), message(t, 7, later)); err != nil {
+			t.Fatal(err)
+		}
+		if len(s.sent) != 1 || len(delayed.msgs) != 1 {
+			t.Errorf("sent %d, parked %d; want 1 and 1", len(s.sent), len(delayed.msgs))
+		}
+	})
+
+	t.Run("DeadLetterFails", func(t *testing.T) {
+		s := &fakeSender{errs: []error{errors.New("a"), errors.New("b"), errors.New("c")}}
+		if err := newConsumer(s, &fakeWriter{err: errors.New("broker down")}).Handle(context.Background(), message(t, 4, ok)); err == nil {
+			t.Error("Handle succeeded without delivering or dead-lettering")
+		}
+	})
+
+	t.Run("ShuttingDown", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		s, dlq := &fakeSender{errs: []error{errors.New("a")}}, &fakeWriter{}
+		c := New(nil, s, sender.Retry{Attempts: 3, Backoff: time.Hour}, dlq, &fakeWriter{}, &fakeReceipts{}, &render.Templates{}, fakeOptOuts{})
+		if err := c.Handle(ctx, message(t, 5, ok)); !errors.Is(err, context.Canceled) {
+			t.Errorf("Handle = %v, want context.Canceled", err)
+		}
+		if len(dlq.msgs) != 0 {
+			t.Error("a message interrupted by shutdown was dead-lettered")
+		}
+	})
+}
+
+func TestRun_CommitsHandled(t *testing.T) {
+	ok := smsoutbox.OutboundMessage{ID: "m1", To: "+15555550100", Body: "hi"}
+	r := &fakeReader{msgs: []kafka.Message{message(t, 10, ok), {Offset: 11, Value: []byte("junk")}, message(t, 12, ok)}}
+	s, dlq := &fakeSender{}, &fakeWriter{}
+	c := New(r, s, fastRetry, dlq, &fakeWriter{}, &fakeReceipts{}, &render.Templates{}, fakeOptOuts{})
+	var outcomes []string
+	c.SetObserver(func(outcome string) { outcomes = append(outcomes, outcome) })
+	c.Run(context.Background())
+	if len(r.committed) != 3 || r.committed[0] != 10 || r.committed[2] != 12 {
+		t.Errorf("committed %v, want 10 11 12", r.committed)
+	}
+	// The second copy of m1 is a redelivery, and isn't sent again.
+	if len(s.sent) != 1 || len(dlq.msgs) != 1 {
+		t.Errorf("sent %d, dead-lettered %d; want 1 and 1", len(s.sent), len(dlq.msgs))
+	}
+	if got := strings.Join(outcomes, " "); got != "sent dead_lettered duplicate" {
+		t.Errorf("outcomes %q", got)
+	}
+}
@@ -0,0 +1,259 @@
+// Package receipt tracks what became of the texts sms-sender sends.
+//
+// The outbound consumer records each text the provider accepts, keyed by
+// the provider's ID for it, and checks the record before sending so a
+// message Kafka redelivers isn't texted twice. When the provider reports the text's final
+// status, the Tracker matches it to the OutboundMessage ID, stores it and
+// publishes it to sms-status. Producers can consume that topic or ask the
+// store directly over HTTP.
+package receipt
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
+	"github.com/jredh-dev/nexus/internal/smsstatus"
+	"github.com/jredh-dev/nexus/internal/sqlitex"
+)
+
+// StateSent is the state of a text the provider accepted but hasn't
+// reported on yet. The final states are smsstatus's.
+const StateSent = "sent"
+
+// Receipt is what is known about one text handed to the provider. A
+// message sent twice, say after a replay, has a Receipt per send.
+type Receipt struct {
+	ID         string    `json:"id"`                    // empty if the send wasn't recorded
+	ProviderID string    `json:"provider_id,omitempty"` // empty if it never reached the provider
+	To         string    `json:"to"`
+	Provider   string    `json:"provider,omitempty"`
+	State      string    `json:"state"`
+	Error      string    `json:"error,omitempty"`
+	SentAt     time.Time `json:"sent_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+const schema = `
+CREATE TABLE IF NOT EXISTS receipts (
+	provider_id TEXT PRIMARY KEY,
+	id          TEXT NOT NULL,
+	recipient   TEXT NOT NULL DEFAULT '',
+	provider    TEXT NOT NULL,
+	state       TEXT NOT NULL,
+	error       TEXT NOT NULL DEFAULT '',
+	sent_at     DATETIME NOT NULL,
+	updated_at  DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_receipts_id ON receipts(id, sent_at);
+`
+
+// Store keeps receipts in SQLite.
+type Store struct {
+	conn *sql.DB
+}
+
+// Open creates or opens the SQLite database at path and applies the schema.
+func Open(path string) (*Store, error) {
+	conn, err := sqlitex.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	if _, err := conn.Exec(schema); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("apply schema: %w", err)
+	}
+	return &Store{conn: conn}, nil
+}
+
+// Ping checks that the database answers.
+func (s *Store) Ping(ctx context.Context) error {
+	return s.conn.PingContext(ctx)
+}
+
+// Close shuts down the database connection.
+func (s *Store) Close() error {
+	return s.conn.Close()
+}
+
+// Sent records that the provider accepted msg as providerID. If the
+// provider's report got here first, the receipt it left is claimed for msg.
+func (s *Store) Sent(ctx context.Context, msg smsoutbox.OutboundMessage, provider, providerID string, at time.Time) error {
+	_, err := s.conn.ExecContext(ctx, `
+		INSERT INTO receipts (provider_id, id, recipient, provider, state, sent_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (provider_id) DO UPDATE SET id = excluded.id, recipient = excluded.recipient, sent_at = excluded.sent_at
+		WHERE receipts.id = ''`,
+		providerID, msg.ID, msg.To, provider, StateSent, at.UTC(), at.UTC())
+	if err != nil {
+		return fmt.Errorf("record sent %s: %w", msg.ID, err)
+	}
+	return nil
+}
+
+// Finalize stores st as the final state of its provider message and
+// returns it with the ID and recipient of the send it belongs to. A report
+// for a send that wasn't recorded is kept with an empty ID.
+func (s *Store) Finalize(ctx context.Context, st smsstatus.Status) (smsstatus.Status, error) {
+	var to string
+	err := s.conn.QueryRowContext(ctx, `
+		INSERT INTO receipts (provider_id, id, recipient, provider, state, error, sent_at, updated_at)
+		VALUES (?, '', ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (provider_id) DO UPDATE SET state = excluded.state, error = excluded.error, updated_at = excluded.updated_at
+		RETURNING id, recipient`,
+		st.ProviderID, st.To, st.Provider, st.State, st.Error, st.At.UTC(), st.At.UTC()).Scan(&st.ID, &to)
+	if err != nil {
+		return st, fmt.Errorf("record status of %s: %w", st.ProviderID, err)
+	}
+	if to != "" {
+		st.To = to
+	}
+	return st, nil
+}
+
+// Blocked records that msg wasn't sent because its recipient opted out.
+// There is no provider message, so the receipt is keyed by msg's ID.
+func (s *Store) Blocked(ctx context.Context, msg smsoutbox.OutboundMessage, at time.Time) error {
+	_, err := s.conn.ExecContext(ctx, `
+		INSERT INTO receipts (provider_id, id, recipient, provider, state, sent_at, updated_at)
+		VALUES (?, ?, ?, '', ?, ?, ?)
+		ON CONFLICT (provider_id) DO UPDATE SET updated_at = excluded.updated_at`,
+		blockedPrefix+msg.ID, msg.ID, msg.To, smsstatus.StateOptedOut, at.UTC(), at.UTC())
+	if err != nil {
+		return fmt.Errorf("record blocked %s: %w", msg.ID, err)
+	}
+	return nil
+}
+
+// SentSince reports whether the message id was handed to a provider at or
+// after since.
+func (s *Store) SentSince(ctx context.Context, id string, since time.Time) (bool, error) {
+	rows, err := s.conn.QueryContext(ctx, `SELECT sent_at FROM receipts WHERE id = ? AND state != ?`, id, smsstatus.StateOptedOut)
+	if err != nil {
+		return false, fmt.Errorf("check sent %s: %w", id, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var at time.Time
+		if err := rows.Scan(&at); err != nil {
+			return false, fmt.Errorf("check sent %s: %w", id, err)
+		}
+		if !at.Before(since) {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
+
+// blockedPrefix keys the receipts of blocked texts apart from providers'
+// IDs; Get doesn't return it.
+const blockedPrefix = "opted-out:"
+
+// Get returns the latest receipt for the OutboundMessage id. Returns
+// sql.ErrNoRows if it was never sent.
+func (s *Store) Get(ctx context.Context, id string) (Receipt, error) {
+	var r Receipt
+	err := s.conn.QueryRowContext(ctx, `
+		SELECT id, provider_id, recipient, provider, state, error, sent_at, updated_at
+		FROM receipts WHERE id = ? ORDER BY sent_at DESC LIMIT 1`, id).
+		Scan(&r.ID, &r.ProviderID, &r.To, &r.Provider, &r.State, &r.Error, &r.SentAt, &r.UpdatedAt)
+	if err != nil {
+		return Receipt{}, err
+	}
+	if strings.HasPrefix(r.ProviderID, blockedPrefix) {
+		r.ProviderID = ""
+	}
+	return r, nil
+}
+
+// Tracker records sends and publishes their final status.
+type Tracker struct {
+	store  *Store
+	pub    smsstatus.Publisher
+	window time.Duration
+	now    func() time.Time
+}
+
+// NewTracker creates a Tracker that keeps receipts in store and publishes
+// final statuses to pub. A message sent within window counts as a
+// duplicate if it comes again.
+func NewTracker(store *Store, pub smsstatus.Publisher, window time.Duration) *Tracker {
+	return &Tracker{store: store, pub: pub, window: window, now: time.Now}
+}
+
+// Duplicate reports whether msg was already sent within the window. Kafka
+// delivers at least once, so a message sent just before a crash is read
+// again; producers republishing a message keep its ID for the same
+// reason.
+func (t *Tracker) Duplicate(ctx context.Context, msg smsoutbox.OutboundMessage) (bool, error) {
+	return t.store.SentSince(ctx, msg.ID, t.now().Add(-t.window))
+}
+
+// Sent records that provider accepted msg as providerID.
+func (t *Tracker) Sent(ctx context.Context, msg smsoutbox.OutboundMessage, provider, providerID string) error {
+	return t.store.Sent(ctx, msg, provider, providerID, t.now())
+}
+
+// Blocked records and publishes that msg wasn't sent because its
+// recipient opted out.
+func (t *Tracker) Blocked(ctx context.Context, msg smsoutbox.OutboundMessage) error {
+	at := t.now()
+	if err := t.store.Blocked(ctx, msg, at); err != nil {
+		return err
+	}
+	return t.pub.Publish(ctx, smsstatus.Status{ID: msg.ID, To: msg.To, State: smsstatus.StateOptedOut, At: at.UTC()})
+}
+
+// Finalize stores a provider's final report on a text and publishes it.
+// Providers redeliver reports, so a status may be published more than
+// once.
+func (t *Tracker) Finalize(ctx context.Context, st smsstatus.Status) error {
+	st, err := t.store.Finalize(ctx, st)
+	if err != nil {
+		return err
+	}
+	if st.ID == "" {
+		slog.Warn("sms status for an unrecorded send", "provider", st.Provider, "provider_id", st.ProviderID, "state", st.State)
+	}
+	if err := t.pub.Publish(ctx, st); err != nil {
+		return err
+	}
+	slog.Info("sms status", "id", st.ID, "provider_id", st.ProviderID, "state", st.State, "err", st.Error)
+	return nil
+}
+
+// Handler serves the latest receipt for a message to callers presenting
+// apiKey as "Authorization: Bearer <key>".
+// GET /messages/{id}
+func Handler(store *Store, apiKey string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if apiKey == "" || subtle.ConstantTimeCompare([]byte(key), []byte(apiKey)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		rc, err := store.Get(r.Context(), chi.URLParam(r, "id"))
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			slog.Error("get receipt", "id", chi.URLParam(r, "id"), "err", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(rc)
+	}
+}
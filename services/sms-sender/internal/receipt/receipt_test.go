@@ -0,0 +1,167 @@
+package receipt
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
+	"github.com/jredh-dev/nexus/internal/smsstatus"
+)
+
+type fakePublisher struct{ sent []smsstatus.Status }
+
+func (p *fakePublisher) Publish(_ context.Context, st smsstatus.Status) error {
+	p.sent = append(p.sent, st)
+	return nil
+}
+
+func testStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestTracker(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	store, pub := testStore(t), &fakePublisher{}
+	tr := NewTracker(store, pub, 24*time.Hour)
+	tr.now = func() time.Time { return now }
+
+	msg := smsoutbox.OutboundMessage{ID: "m1", To: "+15555550100", Body: "hi"}
+	if err := tr.Sent(ctx, msg, "telnyx", "p1"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := store.Get(ctx, "m1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ProviderID != "p1" || got.State != StateSent || got.To != msg.To || !got.SentAt.Equal(now) {
+		t.Errorf("after sending: %+v", got)
+	}
+
+	if dup, err := tr.Duplicate(ctx, msg); err != nil || !dup {
+		t.Errorf("Duplicate right after sending = %v, %v", dup, err)
+	}
+	if dup, err := tr.Duplicate(ctx, smsoutbox.OutboundMessage{ID: "m2"}); err != nil || dup {
+		t.Errorf("Duplicate of an unsent message = %v, %v", dup, err)
+	}
+	tr.now = func() time.Time { return now.Add(25 * time.Hour) }
+	if dup, _ := tr.Duplicate(ctx, msg); dup {
+		t.Error("a send outside the window is a duplicate")
+	}
+	tr.now = func() time.Time { return now }
+
+	// The report is matched to the send, stored and published.
+	at := now.Add(time.Minute)
+	if err := tr.Finalize(ctx, smsstatus.Status{ProviderID: "p1", Provider: "telnyx", State: smsstatus.StateDelivered, At: at}); err != nil {
+		t.Fatal(err)
+	}
+	if len(pub.sent) != 1 || pub.sent[0].ID != "m1" || pub.sent[0].To != msg.To {
+		t.Errorf("published %+v", pub.sent)
+	}
+	if got, _ := store.Get(ctx, "m1"); got.State != smsstatus.StateDelivered || !got.UpdatedAt.Equal(at) {
+		t.Errorf("after delivery: %+v", got)
+	}
+
+	// A resend is the latest receipt.
+	tr.now = func() time.Time { return now.Add(time.Hour) }
+	if err := tr.Sent(ctx, msg, "telnyx", "p2"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := store.Get(ctx, "m1"); got.ProviderID != "p2" || got.State != StateSent {
+		t.Errorf("after resending: %+v", got)
+	}
+
+	// A blocked text has a receipt and a status but no provider message.
+	blocked := smsoutbox.OutboundMessage{ID: "m2", To: "+15555550100"}
+	if err := tr.Blocked(ctx, blocked); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := store.Get(ctx, "m2"); got.State != smsstatus.StateOptedOut || got.ProviderID != "" {
+		t.Errorf("after blocking: %+v", got)
+	}
+	if dup, _ := tr.Duplicate(ctx, blocked); dup {
+		t.Error("a blocked message is a duplicate")
+	}
+	if st := pub.sent[len(pub.sent)-1]; st.ID != "m2" || st.State != smsstatus.StateOptedOut || st.Validate() != nil {
+		t.Errorf("published %+v", st)
+	}
+
+	if _, err := store.Get(ctx, "m9"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Get(unknown) = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestTracker_ReportBeforeSend(t *testing.T) {
+	ctx := context.Background()
+	store, pub := testStore(t), &fakePublisher{}
+	tr := NewTracker(store, pub, 24*time.Hour)
+
+	st := smsstatus.Status{ProviderID: "p1", To: "+15555550100", Provider: "telnyx", State: smsstatus.StateFailed, Error: "40008 Undeliverable", At: time.Now()}
+	if err := tr.Finalize(ctx, st); err != nil {
+		t.Fatal(err)
+	}
+	if len(pub.sent) != 1 || pub.sent[0].ID != "" {
+		t.Errorf("published %+v, want one uncorrelated status", pub.sent)
+	}
+
+	// Recording the send afterwards claims the report without undoing it.
+	if err := tr.Sent(ctx, smsoutbox.OutboundMessage{ID: "m1", To: "+15555550100"}, "telnyx", "p1"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := store.Get(ctx, "m1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.State != smsstatus.StateFailed || got.Error != "40008 Undeliverable" {
+		t.Errorf("receipt %+v", got)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	store := testStore(t)
+	if err := store.Sent(context.Background(), smsoutbox.OutboundMessage{ID: "m1", To: "+15555550100"}, "telnyx", "p1", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	r := chi.NewRouter()
+	r.Get("/messages/{id}", Handler(store, "secret"))
+	get := func(id, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/messages/"+id, nil)
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("m1", "secret")
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET m1: %d %s", w.Code, w.Body)
+	}
+	var got Receipt
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil || got.ProviderID != "p1" || got.State != StateSent {
+		t.Errorf("GET m1 = %+v, %v", got, err)
+	}
+	if w := get("m9", "secret"); w.Code != http.StatusNotFound {
+		t.Errorf("GET unknown: %d, want 404", w.Code)
+	}
+	for _, key := range []string{"", "wrong"} {
+		if w := get("m1", key); w.Code != http.StatusUnauthorized {
+			t.Errorf("key %q: %d, want 401", key, w.Code)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+// Package render fills in templated texts and measures texts in SMS
+// segments.
+//
+// A producer can put a template name and its variables on an
+// OutboundMessage instead of a body; the consumer renders it just before
+// sending. A text longer than one segment goes out as several that the
+// phone stitches together, and each is billed, so a rendered text is cut
+// to a maximum number of segments and every multi-part text is logged.
+package render
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode/utf16"
+
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
+)
+
+// Ext is the extension of template files; the rest of the file name is the
+// template's name.
+const Ext = ".tmpl"
+
+// Encodings a text is sent in. GSM-7 fits 160 characters in a single
+// segment and 153 in each part of a longer text; anything outside its
+// alphabet makes the whole text UCS-2, which fits 70 and 67.
+const (
+	GSM7 = "GSM-7"
+	UCS2 = "UCS-2"
+)
+
+// The GSM 03.38 alphabet, and the characters its extension table adds at
+// the cost of an escape character each.
+const (
+	gsmBasic = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+		"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+	gsmExtended = "^{}\\[~]|€\f"
+)
+
+// ellipsis ends a truncated text; plain dots keep it GSM-7.
+const ellipsis = "..."
+
+// Segments returns how many segments body is sent as, and in which
+// encoding.
+func Segments(body string) (int, string) {
+	enc, units := measure(body)
+	single, part := limits(enc)
+	switch {
+	case units == 0:
+		return 0, enc
+	case units <= single:
+		return 1, enc
+	default:
+		return (units + part - 1) / part, enc
+	}
+}
+
+// Truncate cuts body to fit in max segments, at the end of a word where
+// it can, ending it with "..." if anything was cut. A max of 0 means no
+// limit. It leaves a unit spare at
+// each segment boundary, since a phone won't split a character across
+// segments.
+func Truncate(body string, max int) string {
+	if max <= 0 {
+		return body
+	}
+	if n, _ := Segments(body); n <= max {
+		return body
+	}
+	enc, _ := measure(body)
+	single, part := limits(enc)
+	limit := single
+	if max > 1 {
+		limit = max*part - (max - 1)
+	}
+	limit -= len(ellipsis)
+
+	used := 0
+	for i, r := range body {
+		used += cost(enc, r)
+		if used > limit {
+			cut := body[:i]
+			if j := strings.LastIndexAny(cut, " \n"); j > len(cut)/2 {
+				cut = cut[:j]
+			}
+			return strings.TrimRight(cut, " \n") + ellipsis
+		}
+	}
+	return body
+}
+
+// measure returns the encoding body needs and its length in that
+// encoding's units.
+func measure(body string) (string, int) {
+	units := 0
+	for _, r := range body {
+		if !strings.ContainsRune(gsmBasic, r) && !strings.ContainsRune(gsmExtended, r) {
+			return UCS2, len(utf16.Encode([]rune(body)))
+		}
+		units += cost(GSM7, r)
+	}
+	return GSM7, units
+}
+
+func cost(enc string, r rune) int {
+	switch {
+	case enc == UCS2:
+		return utf16.RuneLen(r)
+	case strings.ContainsRune(gsmExtended, r):
+		return 2
+	default:
+		return 1
+	}
+}
+
+// limits returns the units that fit in a single-segment text, and in each
+// part of a longer one.
+func limits(enc string) (single, part int) {
+	if enc == UCS2 {
+		return 70, 67
+	}
+	return 160, 153
+}
+
+// Templates renders OutboundMessages. The zero Templates has no templates
+// and doesn't truncate.
+type Templates struct {
+	t           *template.Template
+	maxSegments int
+}
+
+// Load reads the templates in dir, one per file ending in Ext, and returns
+// Templates that cut what they render to maxSegments. An empty dir loads
+// none.
+func Load(dir string, maxSegments int) (*Templates, error) {
+	t := &Templates{t: template.New("").Option("missingkey=error"), maxSegments: maxSegments}
+	if dir == "" {
+		return t, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("templates: %w", err)
+	}
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != Ext {
+			continue
+		}
+		text, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("templates: %w", err)
+		}
+		name := strings.TrimSuffix(e.Name(), Ext)
+		if _, err := t.t.New(name).Parse(string(text)); err != nil {
+			return nil, fmt.Errorf("template %s: %w", name, err)
+		}
+	}
+	return t, nil
+}
+
+// Names lists the loaded templates.
+func (t *Templates) Names() []string {
+	if t.t == nil {
+		return nil
+	}
+	var names []string
+	for _, tpl := range t.t.Templates() {
+		if tpl.Name() != "" {
+			names = append(names, tpl.Name())
+		}
+	}
+	return names
+}
+
+// Render returns msg ready to send: if it names a template, with the
+// template executed on its variables as its body. An unknown template, a
+// variable the template uses but msg doesn't set, or an empty result is an
+// error; none of them will work on a retry either.
+func (t *Templates) Render(msg smsoutbox.OutboundMessage) (smsoutbox.OutboundMessage, error) {
+	if msg.Template != "" {
+		var tpl *template.Template
+		if t.t != nil {
+			tpl = t.t.Lookup(msg.Template)
+		}
+		if tpl == nil {
+			return msg, fmt.Errorf("unknown template %q", msg.Template)
+		}
+		var b strings.Builder
+		if err := tpl.Execute(&b, msg.Vars); err != nil {
+			return msg, fmt.Errorf("render: %w", err)
+		}
+		// Files usually end in a newline that isn't meant to be sent.
+		body := strings.TrimSpace(b.String())
+		if body == "" {
+			return msg, fmt.Errorf("template %q rendered nothing", msg.Template)
+		}
+		msg.Body = Truncate(body, t.maxSegments)
+		if msg.Body != body {
+			n, _ := Segments(body)
+			slog.Warn("templated sms truncated", "id", msg.ID, "template", msg.Template, "segments", n, "max_segments", t.maxSegments)
+		}
+	}
+	if n, enc := Segments(msg.Body); n > 1 {
+		slog.Warn("outbound sms is multi-part", "id", msg.ID, "source", msg.Source, "segments", n, "encoding", enc)
+	}
+	return msg, nil
+}
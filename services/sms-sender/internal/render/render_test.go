@@ -0,0 +1,105 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
+)
+
+func TestSegments(t *testing.T) {
+	cases := []struct {
+		body string
+		n    int
+		enc  string
+	}{
+		{"", 0, GSM7},
+		{"see you at 6", 1, GSM7},
+		{strings.Repeat("a", 160), 1, GSM7},
+		{strings.Repeat("a", 161), 2, GSM7},
+		{strings.Repeat("a", 306), 2, GSM7},
+		{strings.Repeat("a", 307), 3, GSM7},
+		{strings.Repeat("{", 80), 1, GSM7}, // extension characters count twice
+		{strings.Repeat("{", 81), 2, GSM7},
+		{"café ☕", 1, UCS2},
+		{strings.Repeat("é", 150) + "ł", 3, UCS2},
+		{strings.Repeat("😀", 35), 1, UCS2}, // surrogate pairs count twice
+		{strings.Repeat("😀", 36), 2, UCS2},
+	}
+	for _, c := range cases {
+		if n, enc := Segments(c.body); n != c.n || enc != c.enc {
+			t.Errorf("Segments(%.20q…) = %d %s, want %d %s", c.body, n, enc, c.n, c.enc)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	short := "see you at 6"
+	if got := Truncate(short, 1); got != short {
+		t.Errorf("Truncate(short) = %q", got)
+	}
+	long := strings.Repeat("word ", 100)
+	if got := Truncate(long, 0); got != long {
+		t.Error("Truncate with no limit cut the text")
+	}
+	for max := 1; max <= 3; max++ {
+		got := Truncate(long, max)
+		if n, _ := Segments(got); n != max || !strings.HasSuffix(got, "word...") {
+			t.Errorf("Truncate(long, %d) = %d segments, %q", max, n, got)
+		}
+	}
+	if got := Truncate(strings.Repeat("☕", 100), 1); len([]rune(got)) != 70 || !strings.HasSuffix(got, "...") {
+		t.Errorf("Truncate(UCS-2) = %q", got)
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	dir := t.TempDir()
+	for name, text := range map[string]string{
+		"reminder.tmpl": "Reminder: {{.event}} at {{.time}}.\n",
+		"long.tmpl":     "{{.text}}",
+		"README.md":     "not a template",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	templates, err := Load(dir, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := strings.Join(templates.Names(), " "); names != "long reminder" && names != "reminder long" {
+		t.Errorf("loaded %q, want long and reminder", names)
+	}
+
+	msg, err := templates.Render(smsoutbox.OutboundMessage{ID: "m1", Template: "reminder", Vars: map[string]string{"event": "Dentist", "time": "3pm"}})
+	if err != nil || msg.Body != "Reminder: Dentist at 3pm." {
+		t.Errorf("Render = %q, %v", msg.Body, err)
+	}
+	msg, err = templates.Render(smsoutbox.OutboundMessage{ID: "m2", Template: "long", Vars: map[string]string{"text": strings.Repeat("a", 1000)}})
+	if n, _ := Segments(msg.Body); err != nil || n != 2 {
+		t.Errorf("Render(long) = %d segments, %v", n, err)
+	}
+	msg, err = templates.Render(smsoutbox.OutboundMessage{ID: "m3", Body: "as is"})
+	if err != nil || msg.Body != "as is" {
+		t.Errorf("Render(untemplated) = %q, %v", msg.Body, err)
+	}
+
+	for _, msg := range []smsoutbox.OutboundMessage{
+		{ID: "m4", Template: "missing"},
+		{ID: "m5", Template: "reminder", Vars: map[string]string{"event": "Dentist"}},
+		{ID: "m6", Template: "long"},
+	} {
+		if _, err := templates.Render(msg); err == nil {
+			t.Errorf("Render(%+v) succeeded", msg)
+		}
+	}
+	if _, err := (&Templates{}).Render(smsoutbox.OutboundMessage{ID: "m7", Template: "reminder"}); err == nil {
+		t.Error("the zero Templates rendered a template")
+	}
+	if _, err := Load(filepath.Join(dir, "nope"), 1); err == nil {
+		t.Error("Load of a missing directory succeeded")
+	}
+}
@@ -0,0 +1,197 @@
+// Package replay puts dead-lettered texts back on sms-outbox: the
+// `sms-sender replay` command reads the whole dead-letter topic, picks the
+// messages an operator's filter matches, and republishes them for the
+// consumer to try again.
+package replay
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/jredh-dev/nexus/services/sms-sender/internal/outbound"
+)
+
+// HeaderReplayedFrom is set on a replayed message to where it was in the
+// dead-letter topic, as topic/partition/offset.
+const HeaderReplayedFrom = "sms-replayed-from"
+
+// Filter picks dead letters by recipient and by when they were
+// dead-lettered. The zero Filter matches everything.
+type Filter struct {
+	Keys  []string  // recipients (message keys); any of them matches
+	Since time.Time // dead-lettered at or after, unless zero
+	Until time.Time // dead-lettered before, unless zero
+}
+
+// Match reports whether f picks m.
+func (f Filter) Match(m kafka.Message) bool {
+	if len(f.Keys) > 0 && !contains(f.Keys, string(m.Key)) {
+		return false
+	}
+	if !f.Since.IsZero() && m.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !m.Time.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Select returns the messages f picks, in order.
+func Select(msgs []kafka.Message, f Filter) []kafka.Message {
+	var out []kafka.Message
+	for _, m := range msgs {
+		if f.Match(m) {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// Writer is where replayed messages go; a *kafka.Writer for sms-outbox.
+type Writer interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// Republish writes msgs to w as they were before they were dead-lettered:
+// the same key and value, without the dead-letter headers, and with
+// HeaderReplayedFrom saying where they came from.
+func Republish(ctx context.Context, w Writer, msgs []kafka.Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	out := make([]kafka.Message, len(msgs))
+	for i, m := range msgs {
+		var headers []kafka.Header
+		for _, h := range m.Headers {
+			if !isDeadLetterHeader(h.Key) {
+				headers = append(headers, h)
+			}
+		}
+		headers = append(headers, kafka.Header{
+			Key:   HeaderReplayedFrom,
+			Value: []byte(fmt.Sprintf("%s/%d/%d", m.Topic, m.Partition, m.Offset)),
+		})
+		out[i] = kafka.Message{Key: m.Key, Value: m.Value, Headers: headers}
+	}
+	return w.WriteMessages(ctx, out...)
+}
+
+func isDeadLetterHeader(key string) bool {
+	switch key {
+	case outbound.HeaderError, outbound.HeaderAttempts, outbound.HeaderBackend, outbound.HeaderFailedAt, HeaderReplayedFrom:
+		return true
+	}
+	return false
+}
+
+// Describe is one line about a dead letter for the operator: where it is,
+// its recipient, when and why it failed.
+func Describe(m kafka.Message) string {
+	header := func(key string) string {
+		for _, h := range m.Headers {
+			if h.Key == key {
+				return string(h.Value)
+			}
+		}
+		return "-"
+	}
+	return fmt.Sprintf("%d/%d\t%s\t%s\t%s tries\t%s",
+		m.Partition, m.Offset, m.Key, m.Time.UTC().Format(time.RFC3339), header(outbound.HeaderAttempts), header(outbound.HeaderError))
+}
+
+// ReadAll reads every message in topic as it stands now, across its
+// partitions, without a consumer group: replaying commits nothing, so the
+// dead letters stay for the record.
+func ReadAll(ctx context.Context, brokers []string, topic string) ([]kafka.Message, error) {
+	conn, err := kafka.DialContext(ctx, "tcp", brokers[0])
+	if err != nil {
+		return nil, err
+	}
+	partitions, err := conn.ReadPartitions(topic)
+	conn.Close()
+	if err != nil {
+		return nil, fmt.Errorf("partitions of %s: %w", topic, err)
+	}
+
+	var out []kafka.Message
+	for _, p := range partitions {
+		msgs, err := readPartition(ctx, brokers, topic, p.ID)
+		if err != nil {
+			return nil, fmt.Errorf("%s partition %d: %w", topic, p.ID, err)
+		}
+		out = append(out, msgs...)
+	}
+	return out, nil
+}
+
+// readPartition reads partition from its first offset to its last as of
+// the call.
+func readPartition(ctx context.Context, brokers []string, topic string, partition int) ([]kafka.Message, error) {
+	leader, err := kafka.DialLeader(ctx, "tcp", brokers[0], topic, partition)
+	if err != nil {
+		return nil, err
+	}
+	first, last, err := leader.ReadOffsets()
+	leader.Close()
+	if err != nil || first >= last {
+		return nil, err
+	}
+
+	r := kafka.NewReader(kafka.ReaderConfig{Brokers: brokers, Topic: topic, Partition: partition})
+	defer r.Close()
+	if err := r.SetOffset(first); err != nil {
+		return nil, err
+	}
+	var out []kafka.Message
+	for {
+		m, err := r.FetchMessage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+		if m.Offset >= last-1 {
+			return out, nil
+		}
+	}
+}
+
+// ParseTime reads a -since or -until flag: an RFC 3339 time, or a duration
+// meaning that long before now.
+func ParseTime(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither an RFC 3339 time nor a duration", s)
+	}
+	return t, nil
+}
+
+// ParseKeys reads a -key flag: comma-separated recipients.
+func ParseKeys(s string) []string {
+	var out []string
+	for _, k := range strings.Split(s, ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			out = append(out, k)
+		}
+	}
+	return out
+}
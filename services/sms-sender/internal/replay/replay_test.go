@@ -0,0 +1,127 @@
+package replay
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/jredh-dev/nexus/services/sms-sender/internal/outbound"
+)
+
+type fakeWriter struct{ msgs []kafka.Message }
+
+func (w *fakeWriter) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	w.msgs = append(w.msgs, msgs...)
+	return nil
+}
+
+func deadLetter(offset int64, key string, at time.Time) kafka.Message {
+	return kafka.Message{
+		Topic: "sms-dlq", Partition: 1, Offset: offset, Key: []byte(key), Value: []byte(`{"id":"m"}`), Time: at,
+		Headers: []kafka.Header{
+			{Key: "trace", Value: []byte("t1")},
+			{Key: outbound.HeaderError, Value: []byte("telnyx: HTTP 500: boom")},
+			{Key: outbound.HeaderAttempts, Value: []byte("5")},
+			{Key: outbound.HeaderBackend, Value: []byte("telnyx")},
+			{Key: outbound.HeaderFailedAt, Value: []byte(at.Format(time.RFC3339))},
+		},
+	}
+}
+
+func TestSelect(t *testing.T) {
+	base := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	msgs := []kafka.Message{
+		deadLetter(0, "+15555550100", base),
+		deadLetter(1, "+15555550101", base.Add(time.Hour)),
+		deadLetter(2, "+15555550100", base.Add(2*time.Hour)),
+	}
+	offsets := func(f Filter) []int64 {
+		var out []int64
+		for _, m := range Select(msgs, f) {
+			out = append(out, m.Offset)
+		}
+		return out
+	}
+	cases := []struct {
+		name string
+		f    Filter
+		want string
+	}{
+		{"all", Filter{}, "[0 1 2]"},
+		{"key", Filter{Keys: []string{"+15555550100"}}, "[0 2]"},
+		{"keys", Filter{Keys: []string{"+15555550101", "+15555550100"}}, "[0 1 2]"},
+		{"since", Filter{Since: base.Add(time.Hour)}, "[1 2]"},
+		{"until", Filter{Until: base.Add(time.Hour)}, "[0]"},
+		{"key and window", Filter{Keys: []string{"+15555550100"}, Since: base.Add(time.Minute), Until: base.Add(3 * time.Hour)}, "[2]"},
+		{"nothing", Filter{Keys: []string{"+15555550199"}}, "[]"},
+	}
+	for _, c := range cases {
+		if got := fmt.Sprint(offsets(c.f)); got != c.want {
+			t.Errorf("%s: selected %s, want %s", c.name, got, c.want)
+		}
+	}
+}
+
+func TestRepublish(t *testing.T) {
+	at := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	w := &fakeWriter{}
+	if err := Republish(context.Background(), w, []kafka.Message{deadLetter(7, "+15555550100", at)}); err != nil {
+		t.Fatal(err)
+	}
+	if len(w.msgs) != 1 {
+		t.Fatalf("wrote %d messages, want 1", len(w.msgs))
+	}
+	m := w.msgs[0]
+	if string(m.Key) != "+15555550100" || string(m.Value) != `{"id":"m"}` || m.Topic != "" {
+		t.Errorf("republished %+v", m)
+	}
+	var keys []string
+	for _, h := range m.Headers {
+		keys = append(keys, h.Key+"="+string(h.Value))
+	}
+	if got, want := strings.Join(keys, " "), "trace=t1 "+HeaderReplayedFrom+"=sms-dlq/1/7"; got != want {
+		t.Errorf("headers %q, want %q", got, want)
+	}
+
+	// Replaying a replayed message records only where it last was.
+	w2 := &fakeWriter{}
+	again := m
+	again.Topic, again.Partition, again.Offset = "sms-dlq", 0, 9
+	if err := Republish(context.Background(), w2, []kafka.Message{again}); err != nil {
+		t.Fatal(err)
+	}
+	if hs := w2.msgs[0].Headers; len(hs) != 2 || string(hs[1].Value) != "sms-dlq/0/9" {
+		t.Errorf("headers %+v", hs)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	at := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	want := "1/7\t+15555550100\t2026-10-16T12:00:00Z\t5 tries\ttelnyx: HTTP 500: boom"
+	if got := Describe(deadLetter(7, "+15555550100", at)); got != want {
+		t.Errorf("Describe = %q, want %q", got, want)
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	for in, want := range map[string]time.Time{
+		"":                     {},
+		"24h":                  now.Add(-24 * time.Hour),
+		"2026-10-15T08:30:00Z": time.Date(2026, 10, 15, 8, 30, 0, 0, time.UTC),
+	} {
+		if got, err := ParseTime(in, now); err != nil || !got.Equal(want) {
+			t.Errorf("ParseTime(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseTime("yesterday", now); err == nil {
+		t.Error("ParseTime(yesterday) succeeded")
+	}
+	if got := ParseKeys(" +15555550100,,+15555550101 "); len(got) != 2 || got[1] != "+15555550101" {
+		t.Errorf("ParseKeys = %q", got)
+	}
+}
@@ -0,0 +1,210 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
+)
+
+// text is what a fake provider was asked to send.
+type text struct {
+	To, From, Body string
+}
+
+// backend adapts one provider's API to the conformance suite, which runs
+// it against a fake of that API.
+type backend struct {
+	name string
+	// from is the sender the backend was configured with, as the provider
+	// sees it.
+	from string
+	// codes is whether the provider's errors carry an error code.
+	codes bool
+	// newSender returns a Sender pointed at baseURL.
+	newSender func(baseURL string) Sender
+	// decode reads a send request into a text, or reports false if its
+	// credentials are wrong.
+	decode func(r *http.Request) (text, bool)
+	// accept answers a send with the provider's success response for id.
+	accept func(w http.ResponseWriter, id string)
+	// refuse answers a send with the provider's error response.
+	refuse func(w http.ResponseWriter, status int, code, message string)
+}
+
+// fakeProvider serves a backend's API, recording what it accepts.
+type fakeProvider struct {
+	b backend
+
+	mu    sync.Mutex
+	texts []text
+	fail  []int // statuses to answer the next sends with
+}
+
+func (f *fakeProvider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	t, ok := f.b.decode(r)
+	if !ok {
+		f.b.refuse(w, http.StatusUnauthorized, "20003", "Authentication failed")
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.fail) > 0 {
+		status := f.fail[0]
+		f.fail = f.fail[1:]
+		if status == http.StatusServiceUnavailable {
+			// Not every failure is the provider's JSON.
+			http.Error(w, "upstream unavailable", status)
+			return
+		}
+		f.b.refuse(w, status, "21211", "The 'To' number is not a valid phone number.")
+		return
+	}
+	f.texts = append(f.texts, t)
+	f.b.accept(w, fmt.Sprintf("%s-msg-%d", f.b.name, len(f.texts)))
+}
+
+func (f *fakeProvider) sent() []text {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]text(nil), f.texts...)
+}
+
+func (f *fakeProvider) failNext(statuses ...int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fail = append(f.fail, statuses...)
+}
+
+// runConformance checks what every backend must do: send what it's given,
+// return the provider's ID, report refusals as *Error, and retry the same
+// way through Retry.Deliver.
+func runConformance(t *testing.T, b backend) {
+	start := func(t *testing.T, b backend) (*fakeProvider, Sender) {
+		f := &fakeProvider{b: b}
+		srv := httptest.NewServer(f)
+		t.Cleanup(srv.Close)
+		return f, b.newSender(srv.URL)
+	}
+	msg := smsoutbox.OutboundMessage{ID: "m1", To: "+15555550100", Body: "Café at 6? ☕ — J", Source: "test"}
+	fast := Retry{Attempts: 3, Backoff: time.Millisecond}
+
+	t.Run("Name", func(t *testing.T) {
+		_, s := start(t, b)
+		if s.Name() != b.name {
+			t.Errorf("Name() = %q, want %q", s.Name(), b.name)
+		}
+	})
+
+	t.Run("Send", func(t *testing.T) {
+		f, s := start(t, b)
+		id, err := s.Send(context.Background(), msg)
+		if err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+		if id != b.name+"-msg-1" {
+			t.Errorf("Send = %q, want the provider's ID", id)
+		}
+		want := text{To: msg.To, From: b.from, Body: msg.Body}
+		if got := f.sent(); len(got) != 1 || got[0] != want {
+			t.Errorf("provider got %+v, want [%+v]", got, want)
+		}
+	})
+
+	t.Run("Refused", func(t *testing.T) {
+		f, s := start(t, b)
+		f.failNext(http.StatusBadRequest)
+		_, err := s.Send(context.Background(), msg)
+		var e *Error
+		if !errors.As(err, &e) {
+			t.Fatalf("Send = %v, want an *Error", err)
+		}
+		if e.Provider != b.name || e.Status != http.StatusBadRequest || e.Message == "" || b.codes && e.Code != "21211" {
+			t.Errorf("Error = %+v", e)
+		}
+	})
+
+	t.Run("RefusedWithoutJSON", func(t *testing.T) {
+		f, s := start(t, b)
+		f.failNext(http.StatusServiceUnavailable)
+		_, err := s.Send(context.Background(), msg)
+		var e *Error
+		if !errors.As(err, &e) || e.Status != http.StatusServiceUnavailable {
+			t.Fatalf("Send = %v, want an *Error with status 503", err)
+		}
+	})
+
+	t.Run("BadCredentials", func(t *testing.T) {
+		locked := b
+		locked.decode = func(*http.Request) (text, bool) { return text{}, false }
+		_, s := start(t, locked)
+		_, err := s.Send(context.Background(), msg)
+		var e *Error
+		if !errors.As(err, &e) || e.Status != http.StatusUnauthorized {
+			t.Fatalf("Send = %v, want an *Error with status 401", err)
+		}
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		f, s := start(t, b)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := s.Send(ctx, msg); err == nil {
+			t.Fatal("Send with a cancelled context succeeded")
+		}
+		if got := f.sent(); len(got) != 0 {
+			t.Errorf("provider got %+v after a cancelled send", got)
+		}
+	})
+
+	t.Run("RetryRecovers", func(t *testing.T) {
+		f, s := start(t, b)
+		f.failNext(http.StatusInternalServerError, http.StatusServiceUnavailable)
+		id, attempts, err := fast.Deliver(context.Background(), s, msg)
+		if err != nil || attempts != 3 || id != b.name+"-msg-1" {
+			t.Errorf("Deliver = %q, %d, %v; want the ID on the third try", id, attempts, err)
+		}
+		if got := f.sent(); len(got) != 1 {
+			t.Errorf("provider got %d texts, want 1", len(got))
+		}
+	})
+
+	t.Run("RetryRateLimited", func(t *testing.T) {
+		f, s := start(t, b)
+		f.failNext(http.StatusTooManyRequests)
+		if _, attempts, err := fast.Deliver(context.Background(), s, msg); err != nil || attempts != 2 {
+			t.Errorf("Deliver = %d tries, %v; want success on the second", attempts, err)
+		}
+	})
+
+	t.Run("RefusalNotRetried", func(t *testing.T) {
+		f, s := start(t, b)
+		f.failNext(http.StatusBadRequest)
+		_, attempts, err := fast.Deliver(context.Background(), s, msg)
+		if !Permanent(err) || attempts != 1 {
+			t.Errorf("Deliver = %d tries, %v; want a permanent error on the first", attempts, err)
+		}
+		if got := f.sent(); len(got) != 0 {
+			t.Errorf("provider got %+v", got)
+		}
+	})
+
+	t.Run("RetryGivesUp", func(t *testing.T) {
+		f, s := start(t, b)
+		f.failNext(http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError)
+		_, attempts, err := fast.Deliver(context.Background(), s, msg)
+		var e *Error
+		if !errors.As(err, &e) || attempts != 3 {
+			t.Errorf("Deliver = %d tries, %v; want 3 and the last *Error", attempts, err)
+		}
+		if got := f.sent(); len(got) != 0 {
+			t.Errorf("provider got %+v", got)
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
+)
+
+// Gateway sends through a self-hosted android-sms-gateway server, which
+// relays each text through an Android phone's own SIM
+// (SMS_BACKEND=gateway). No SMS provider sees the messages, so this is the
+// backend to move to once a phone is set up for it.
+type Gateway struct {
+	baseURL  string // the server's URL, without a trailing '/'
+	user     string
+	password string
+	client   *http.Client
+}
+
+// NewGateway creates a Sender for the android-sms-gateway server at
+// baseURL, authenticating with HTTP basic auth.
+func NewGateway(baseURL, user, password string) (*Gateway, error) {
+	switch {
+	case baseURL == "":
+		return nil, fmt.Errorf("gateway: SMS_GATEWAY_URL is required")
+	case user == "" || password == "":
+		return nil, fmt.Errorf("gateway: SMS_GATEWAY_USER and SMS_GATEWAY_PASSWORD are required")
+	}
+	return &Gateway{baseURL: strings.TrimRight(baseURL, "/"), user: user, password: password, client: newHTTPClient()}, nil
+}
+
+func (g *Gateway) Name() string { return "gateway" }
+
+// Send submits msg with POST /3rdparty/v1/message. The phone sends the
+// text when it next syncs, so the returned ID is of the queued message.
+func (g *Gateway) Send(ctx context.Context, msg smsoutbox.OutboundMessage) (string, error) {
+	reqBody, err := json.Marshal(struct {
+		ID           string   `json:"id,omitempty"`
+		Message      string   `json:"message"`
+		PhoneNumbers []string `json:"phoneNumbers"`
+	}{msg.ID, msg.Body, []string{msg.To}})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.baseURL+"/3rdparty/v1/message", bytes.NewReader(reqBody))
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(g.user, g.password)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("gateway: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := readResponse(resp)
+	if err != nil {
+		return "", fmt.Errorf("gateway: read response: %w", err)
+	}
+
+	if resp.StatusCode/100 != 2 {
+		e := &Error{Provider: g.Name(), Status: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+		var out struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(body, &out) == nil && out.Message != "" {
+			e.Message = out.Message
+		}
+		return "", e
+	}
+	var out struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil || out.ID == "" {
+		return "", fmt.Errorf("gateway: unexpected response: %.200s", body)
+	}
+	return out.ID, nil
+}
@@ -0,0 +1,54 @@
+package sender
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestGateway(t *testing.T) {
+	runConformance(t, backend{
+		name: "gateway",
+		newSender: func(baseURL string) Sender {
+			s, err := NewGateway(baseURL+"/", "sms", "hunter2")
+			if err != nil {
+				t.Fatal(err)
+			}
+			return s
+		},
+		decode: func(r *http.Request) (text, bool) {
+			user, pass, ok := r.BasicAuth()
+			if !ok || user != "sms" || pass != "hunter2" || r.Method != http.MethodPost || r.URL.Path != "/3rdparty/v1/message" {
+				return text{}, false
+			}
+			var body struct {
+				ID           string
+				Message      string
+				PhoneNumbers []string
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.PhoneNumbers) != 1 || body.ID != "m1" {
+				return text{}, false
+			}
+			return text{To: body.PhoneNumbers[0], Body: body.Message}, true
+		},
+		accept: func(w http.ResponseWriter, id string) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusAccepted)
+			json.NewEncoder(w).Encode(map[string]any{"id": id, "state": "Pending"})
+		},
+		refuse: func(w http.ResponseWriter, status int, _, message string) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(map[string]string{"message": message})
+		},
+	})
+}
+
+func TestNewGateway(t *testing.T) {
+	if _, err := NewGateway("", "sms", "pw"); err == nil {
+		t.Error("NewGateway without a URL succeeded")
+	}
+	if _, err := NewGateway("http://phone:8080", "sms", ""); err == nil {
+		t.Error("NewGateway without a password succeeded")
+	}
+}
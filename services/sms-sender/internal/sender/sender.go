@@ -0,0 +1,145 @@
+// Package sender delivers outbound texts through an SMS provider. Each
+// provider is a Sender; the consumer picks one with SMS_BACKEND and retries
+// its failures the same way whichever it is.
+package sender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"net/textproto"
+	"time"
+
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
+)
+
+// Sender hands one text to an SMS provider.
+// Implementations must be safe for concurrent use.
+type Sender interface {
+	// Name is the backend's SMS_BACKEND value.
+	Name() string
+	// Send submits msg and returns the provider's ID for it. A provider's
+	// refusal is an *Error.
+	Send(ctx context.Context, msg smsoutbox.OutboundMessage) (string, error)
+}
+
+// Error is a provider refusing a message.
+type Error struct {
+	Provider string
+	Status   int    // HTTP status of the provider's response
+	Code     string // the provider's error code, if it gave one
+	Message  string
+}
+
+func (e *Error) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("%s: HTTP %d: %s (code %s)", e.Provider, e.Status, e.Message, e.Code)
+	}
+	return fmt.Sprintf("%s: HTTP %d: %s", e.Provider, e.Status, e.Message)
+}
+
+// Permanent reports whether err is a refusal that will be refused again:
+// an HTTP 4xx other than a timeout or rate limit, or an SMTP 5xx, meaning
+// the message or the credentials are wrong. Anything else, a 429, a 5xx,
+// an SMTP 4xx or the provider being unreachable, may pass on a later try.
+func Permanent(err error) bool {
+	var smtpErr *textproto.Error
+	if errors.As(err, &smtpErr) {
+		return smtpErr.Code >= 500 && smtpErr.Code < 600
+	}
+	var e *Error
+	if !errors.As(err, &e) {
+		return false
+	}
+	switch e.Status {
+	case http.StatusRequestTimeout, http.StatusTooEarly, http.StatusTooManyRequests:
+		return false
+	}
+	return e.Status >= 400 && e.Status < 500
+}
+
+// httpTimeout bounds one request to a provider.
+const httpTimeout = 15 * time.Second
+
+// maxResponseSize bounds what is read of a provider's response.
+const maxResponseSize = 64 << 10
+
+// newHTTPClient is the client backends use unless given one.
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: httpTimeout}
+}
+
+// readResponse reads a provider's response body, bounded.
+func readResponse(resp *http.Response) ([]byte, error) {
+	return io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+}
+
+// Retry is how a failed send is retried; every backend gets the same.
+type Retry struct {
+	Attempts   int           // tries per message, including the first
+	Backoff    time.Duration // the wait after the first failed try; it doubles after each
+	MaxBackoff time.Duration // the longest wait, if set
+}
+
+// DefaultRetry tries a message five times over at most about 30 seconds.
+var DefaultRetry = Retry{Attempts: 5, Backoff: 2 * time.Second, MaxBackoff: 16 * time.Second}
+
+// Wait returns how long to wait after the attempt'th failed try:
+// Backoff doubled for each try before it, capped at MaxBackoff, less a
+// random amount of up to half so that many messages failing together
+// don't all retry at the same moment.
+func (r Retry) Wait(attempt int) time.Duration {
+	d := r.Backoff
+	for i := 1; i < attempt && d < math.MaxInt64/2 && (r.MaxBackoff == 0 || d < r.MaxBackoff); i++ {
+		d *= 2
+	}
+	if r.MaxBackoff > 0 && d > r.MaxBackoff {
+		d = r.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d - rand.N(d/2+1)
+}
+
+// Deliver sends msg with s, retrying failures as r says. It returns the
+// provider's ID for the message and how many tries it took, or the last
+// error once the tries run out, the error is Permanent, or ctx is done.
+func (r Retry) Deliver(ctx context.Context, s Sender, msg smsoutbox.OutboundMessage) (string, int, error) {
+	var id string
+	attempts, err := r.Do(ctx, func() error {
+		var err error
+		id, err = s.Send(ctx, msg)
+		return err
+	})
+	if err != nil {
+		return "", attempts, err
+	}
+	return id, attempts, nil
+}
+
+// Do calls send until it succeeds, retrying as r says, and returns how
+// many tries it took and the last error. It is Deliver for senders other
+// than SMS ones.
+func (r Retry) Do(ctx context.Context, send func() error) (int, error) {
+	attempts := max(r.Attempts, 1)
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = send(); err == nil {
+			return attempt, nil
+		}
+		if attempt == attempts || Permanent(err) {
+			return attempt, err
+		}
+		select {
+		case <-ctx.Done():
+			return attempt, err
+		case <-time.After(r.Wait(attempt)):
+		}
+	}
+	return attempts, err
+}
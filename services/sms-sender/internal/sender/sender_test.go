@@ -0,0 +1,57 @@
+package sender
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"testing"
+	"time"
+)
+
+func TestPermanent(t *testing.T) {
+	refused := func(status int) error {
+		return &Error{Provider: "telnyx", Status: status, Message: http.StatusText(status)}
+	}
+	for err, want := range map[error]bool{
+		refused(http.StatusBadRequest):                          true,
+		refused(http.StatusUnauthorized):                        true,
+		refused(http.StatusUnprocessableEntity):                 true,
+		refused(http.StatusRequestTimeout):                      false,
+		refused(http.StatusTooManyRequests):                     false,
+		refused(http.StatusInternalServerError):                 false,
+		refused(http.StatusServiceUnavailable):                  false,
+		fmt.Errorf("send: %w", refused(http.StatusBadRequest)):  true,
+		errors.New("dial tcp: connection refused"):              false,
+		&textproto.Error{Code: 550, Msg: "mailbox unavailable"}: true,
+		&textproto.Error{Code: 421, Msg: "try again later"}:     false,
+	} {
+		if got := Permanent(err); got != want {
+			t.Errorf("Permanent(%v) = %v, want %v", err, got, want)
+		}
+	}
+}
+
+func TestRetryWait(t *testing.T) {
+	r := Retry{Attempts: 10, Backoff: time.Second, MaxBackoff: 10 * time.Second}
+	for attempt, full := range map[int]time.Duration{
+		1: time.Second,
+		2: 2 * time.Second,
+		3: 4 * time.Second,
+		4: 8 * time.Second,
+		5: 10 * time.Second,
+		9: 10 * time.Second,
+	} {
+		for range 50 {
+			if d := r.Wait(attempt); d < full/2 || d > full {
+				t.Fatalf("Wait(%d) = %v, want between %v and %v", attempt, d, full/2, full)
+			}
+		}
+	}
+	if d := (Retry{Backoff: time.Second}).Wait(20); d < (1<<19)*time.Second/2 {
+		t.Errorf("uncapped Wait(20) = %v", d)
+	}
+	if d := (Retry{Backoff: time.Second}).Wait(100); d <= 0 {
+		t.Errorf("uncapped Wait(100) = %v, want no overflow", d)
+	}
+}
@@ -0,0 +1,101 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
+)
+
+const telnyxBaseURL = "https://api.telnyx.com"
+
+// Telnyx sends through Telnyx's Messages API (SMS_BACKEND=telnyx).
+type Telnyx struct {
+	apiKey    atomic.Pointer[string] // replaced by SetAPIKey when the key rotates
+	from      string                 // our number, in E.164 format
+	profileID string                 // messaging profile; picks a number from its pool if from is empty
+	baseURL   string
+	client    *http.Client
+}
+
+// NewTelnyx creates a Sender using the Telnyx API key apiKey. At least one
+// of from and profileID must be set.
+func NewTelnyx(apiKey, from, profileID string) (*Telnyx, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("telnyx: TELNYX_API_KEY is required")
+	}
+	if from == "" && profileID == "" {
+		return nil, fmt.Errorf("telnyx: SMS_FROM or TELNYX_MESSAGING_PROFILE_ID is required")
+	}
+	t := &Telnyx{from: from, profileID: profileID, baseURL: telnyxBaseURL, client: newHTTPClient()}
+	t.apiKey.Store(&apiKey)
+	return t, nil
+}
+
+// SetAPIKey makes later sends use apiKey, for when the key is rotated.
+func (t *Telnyx) SetAPIKey(apiKey string) { t.apiKey.Store(&apiKey) }
+
+func (t *Telnyx) Name() string { return "telnyx" }
+
+// Send submits msg with POST /v2/messages.
+func (t *Telnyx) Send(ctx context.Context, msg smsoutbox.OutboundMessage) (string, error) {
+	reqBody, err := json.Marshal(struct {
+		From      string `json:"from,omitempty"`
+		To        string `json:"to"`
+		Text      string `json:"text"`
+		ProfileID string `json:"messaging_profile_id,omitempty"`
+	}{t.from, msg.To, msg.Body, t.profileID})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.baseURL+"/v2/messages", bytes.NewReader(reqBody))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+*t.apiKey.Load())
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("telnyx: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := readResponse(resp)
+	if err != nil {
+		return "", fmt.Errorf("telnyx: read response: %w", err)
+	}
+
+	if resp.StatusCode/100 != 2 {
+		e := &Error{Provider: t.Name(), Status: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+		var out struct {
+			Errors []struct {
+				Code   string `json:"code"`
+				Title  string `json:"title"`
+				Detail string `json:"detail"`
+			} `json:"errors"`
+		}
+		if json.Unmarshal(body, &out) == nil && len(out.Errors) > 0 {
+			first := out.Errors[0]
+			e.Code = first.Code
+			e.Message = first.Title
+			if first.Detail != "" {
+				e.Message += ": " + first.Detail
+			}
+		}
+		return "", e
+	}
+	var out struct {
+		Data struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil || out.Data.ID == "" {
+		return "", fmt.Errorf("telnyx: unexpected response: %.200s", body)
+	}
+	return out.Data.ID, nil
+}
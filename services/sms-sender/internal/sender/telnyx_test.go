@@ -0,0 +1,54 @@
+package sender
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestTelnyx(t *testing.T) {
+	runConformance(t, backend{
+		name:  "telnyx",
+		from:  "+15555550199",
+		codes: true,
+		newSender: func(baseURL string) Sender {
+			s, err := NewTelnyx("KEY123", "+15555550199", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			s.baseURL = baseURL
+			return s
+		},
+		decode: func(r *http.Request) (text, bool) {
+			if r.Method != http.MethodPost || r.URL.Path != "/v2/messages" || r.Header.Get("Authorization") != "Bearer KEY123" {
+				return text{}, false
+			}
+			var body struct{ From, To, Text string }
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				return text{}, false
+			}
+			return text{To: body.To, From: body.From, Body: body.Text}, true
+		},
+		accept: func(w http.ResponseWriter, id string) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"id": id, "record_type": "message"}})
+		},
+		refuse: func(w http.ResponseWriter, status int, code, message string) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(map[string]any{"errors": []map[string]string{{"code": code, "title": "Invalid request", "detail": message}}})
+		},
+	})
+}
+
+func TestNewTelnyx(t *testing.T) {
+	if _, err := NewTelnyx("", "+15555550199", ""); err == nil {
+		t.Error("NewTelnyx without an API key succeeded")
+	}
+	if _, err := NewTelnyx("KEY", "", ""); err == nil {
+		t.Error("NewTelnyx without a number or messaging profile succeeded")
+	}
+	if _, err := NewTelnyx("KEY", "", "profile-1"); err != nil {
+		t.Errorf("NewTelnyx with a messaging profile: %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package sender
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
+)
+
+const twilioBaseURL = "https://api.twilio.com"
+
+// Twilio sends through Twilio's Messages API (SMS_BACKEND=twilio).
+type Twilio struct {
+	accountSID string
+	authToken  string
+	from       string // our number in E.164 format, or a messaging service SID (MG...)
+	baseURL    string
+	client     *http.Client
+}
+
+// NewTwilio creates a Sender for the Twilio account accountSID.
+func NewTwilio(accountSID, authToken, from string) (*Twilio, error) {
+	switch {
+	case accountSID == "" || authToken == "":
+		return nil, fmt.Errorf("twilio: TWILIO_ACCOUNT_SID and TWILIO_AUTH_TOKEN are required")
+	case from == "":
+		return nil, fmt.Errorf("twilio: SMS_FROM is required")
+	}
+	return &Twilio{accountSID: accountSID, authToken: authToken, from: from, baseURL: twilioBaseURL, client: newHTTPClient()}, nil
+}
+
+func (t *Twilio) Name() string { return "twilio" }
+
+// Send submits msg with POST /2010-04-01/Accounts/{sid}/Messages.json.
+func (t *Twilio) Send(ctx context.Context, msg smsoutbox.OutboundMessage) (string, error) {
+	form := url.Values{"To": {msg.To}, "Body": {msg.Body}}
+	if strings.HasPrefix(t.from, "MG") {
+		form.Set("MessagingServiceSid", t.from)
+	} else {
+		form.Set("From", t.from)
+	}
+	endpoint := t.baseURL + "/2010-04-01/Accounts/" + url.PathEscape(t.accountSID) + "/Messages.json"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(t.accountSID, t.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("twilio: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := readResponse(resp)
+	if err != nil {
+		return "", fmt.Errorf("twilio: read response: %w", err)
+	}
+
+	if resp.StatusCode/100 != 2 {
+		e := &Error{Provider: t.Name(), Status: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+		var out struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(body, &out) == nil && out.Message != "" {
+			e.Message = out.Message
+			if out.Code != 0 {
+				e.Code = strconv.Itoa(out.Code)
+			}
+		}
+		return "", e
+	}
+	var out struct {
+		SID string `json:"sid"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil || out.SID == "" {
+		return "", fmt.Errorf("twilio: unexpected response: %.200s", body)
+	}
+	return out.SID, nil
+}
@@ -0,0 +1,72 @@
+package sender
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/jredh-dev/nexus/internal/smsoutbox"
+)
+
+func twilioBackend(t *testing.T, from string) backend {
+	return backend{
+		name:  "twilio",
+		from:  from,
+		codes: true,
+		newSender: func(baseURL string) Sender {
+			s, err := NewTwilio("AC123", "token", from)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s.baseURL = baseURL
+			return s
+		},
+		decode: func(r *http.Request) (text, bool) {
+			user, pass, ok := r.BasicAuth()
+			if !ok || user != "AC123" || pass != "token" || r.Method != http.MethodPost ||
+				r.URL.Path != "/2010-04-01/Accounts/AC123/Messages.json" || r.ParseForm() != nil {
+				return text{}, false
+			}
+			sender := r.PostForm.Get("From")
+			if sid := r.PostForm.Get("MessagingServiceSid"); sid != "" {
+				sender = sid
+			}
+			return text{To: r.PostForm.Get("To"), From: sender, Body: r.PostForm.Get("Body")}, true
+		},
+		accept: func(w http.ResponseWriter, id string) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]any{"sid": id, "status": "queued"})
+		},
+		refuse: func(w http.ResponseWriter, status int, code, message string) {
+			n, _ := strconv.Atoi(code)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(map[string]any{"code": n, "message": message, "status": status})
+		},
+	}
+}
+
+func TestTwilio(t *testing.T) {
+	runConformance(t, twilioBackend(t, "+15555550199"))
+}
+
+func TestTwilio_MessagingService(t *testing.T) {
+	b := twilioBackend(t, "MG0123456789abcdef")
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		got = r.PostForm.Get("MessagingServiceSid") + "|" + r.PostForm.Get("From")
+		b.accept(w, "SM1")
+	}))
+	defer srv.Close()
+	if _, err := b.newSender(srv.URL).Send(context.Background(), smsoutbox.OutboundMessage{ID: "m", To: "+15555550100", Body: "hi"}); err != nil {
+		t.Fatal(err)
+	}
+	if got != "MG0123456789abcdef|" {
+		t.Errorf("MessagingServiceSid|From = %q, want the service SID and no From", got)
+	}
+}
@@ -0,0 +1,147 @@
+// Package telnyx speaks the parts of Telnyx's messaging API sms-sender
+// uses: verifying and decoding the webhooks Telnyx posts for inbound texts
+// and for the delivery of outbound ones.
+package telnyx
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Telnyx signs every webhook with its account's ed25519 key: the signature
+// header is the base64 signature of "<timestamp>|<body>".
+const (
+	SignatureHeader = "Telnyx-Signature-Ed25519"
+	TimestampHeader = "Telnyx-Timestamp"
+)
+
+// ErrSignature is returned by Verify for a webhook Telnyx didn't sign, or
+// signed too long ago.
+var ErrSignature = errors.New("telnyx: invalid webhook signature")
+
+// Webhook event types.
+const (
+	EventMessageReceived  = "message.received"
+	EventMessageFinalized = "message.finalized" // an outbound text's final status
+)
+
+// ParsePublicKey decodes the base64 public key shown in the Telnyx portal.
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("telnyx public key: %w", err)
+	}
+	if len(b) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("telnyx public key: %d bytes, want %d", len(b), ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(b), nil
+}
+
+// Verify checks that body was signed by key at timestamp (Unix seconds),
+// and that timestamp is within tolerance of now, so a captured delivery
+// can't be replayed later.
+func Verify(key ed25519.PublicKey, body []byte, timestamp, signature string, now time.Time, tolerance time.Duration) error {
+	secs, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: bad timestamp %q", ErrSignature, timestamp)
+	}
+	if d := now.Sub(time.Unix(secs, 0)); d > tolerance || d < -tolerance {
+		return fmt.Errorf("%w: timestamp %s off by %s", ErrSignature, timestamp, d.Round(time.Second))
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("%w: signature is not base64", ErrSignature)
+	}
+	signed := make([]byte, 0, len(timestamp)+1+len(body))
+	signed = append(append(append(signed, timestamp...), '|'), body...)
+	if !ed25519.Verify(key, signed, sig) {
+		return ErrSignature
+	}
+	return nil
+}
+
+// Event is a webhook delivery.
+type Event struct {
+	Data struct {
+		ID         string          `json:"id"` // unique per event, kept across redeliveries
+		EventType  string          `json:"event_type"`
+		OccurredAt time.Time       `json:"occurred_at"`
+		Payload    json.RawMessage `json:"payload"`
+	} `json:"data"`
+}
+
+// Message is the payload of message events.
+type Message struct {
+	ID         string     `json:"id"`
+	Direction  string     `json:"direction"` // "inbound" or "outbound"
+	From       Endpoint   `json:"from"`
+	To         []Endpoint `json:"to"`
+	Text       string     `json:"text"`
+	Media      []Media    `json:"media"`
+	ReceivedAt time.Time  `json:"received_at"`
+
+	// Outbound messages only.
+	CompletedAt time.Time `json:"completed_at"`
+	Errors      []Error   `json:"errors"`
+}
+
+// Endpoint is one end of a message.
+type Endpoint struct {
+	PhoneNumber string `json:"phone_number"`
+	Status      string `json:"status,omitempty"` // recipients only; see the Status constants
+}
+
+// Final statuses of an outbound message's recipient.
+const (
+	StatusDelivered           = "delivered"
+	StatusSendingFailed       = "sending_failed"  // Telnyx couldn't send it
+	StatusDeliveryFailed      = "delivery_failed" // the carrier couldn't deliver it
+	StatusDeliveryUnconfirmed = "delivery_unconfirmed"
+)
+
+// Error is a reason Telnyx gives for a failed message.
+type Error struct {
+	Code   string `json:"code"`
+	Title  string `json:"title"`
+	Detail string `json:"detail,omitempty"`
+}
+
+func (e Error) String() string {
+	s := e.Code + " " + e.Title
+	if e.Detail != "" {
+		s += ": " + e.Detail
+	}
+	return s
+}
+
+// Media is an MMS attachment.
+type Media struct {
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+}
+
+// DecodeEvent parses a webhook body.
+func DecodeEvent(body []byte) (Event, error) {
+	var e Event
+	if err := json.Unmarshal(body, &e); err != nil {
+		return Event{}, fmt.Errorf("telnyx event: %w", err)
+	}
+	if e.Data.EventType == "" {
+		return Event{}, errors.New("telnyx event: no event_type")
+	}
+	return e, nil
+}
+
+// Message decodes a message event's payload.
+func (e Event) Message() (Message, error) {
+	var m Message
+	if err := json.Unmarshal(e.Data.Payload, &m); err != nil {
+		return Message{}, fmt.Errorf("telnyx %s payload: %w", e.Data.EventType, err)
+	}
+	return m, nil
+}
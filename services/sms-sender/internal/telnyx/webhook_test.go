@@ -0,0 +1,93 @@
+package telnyx
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func sign(priv ed25519.PrivateKey, ts string, body []byte) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(priv, append([]byte(ts+"|"), body...)))
+}
+
+func TestVerify(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1_790_000_000, 0)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	body := []byte(`{"data":{"event_type":"message.received"}}`)
+	sig := sign(priv, ts, body)
+
+	if err := Verify(pub, body, ts, sig, now.Add(time.Minute), 5*time.Minute); err != nil {
+		t.Errorf("valid signature: %v", err)
+	}
+
+	_, other, _ := ed25519.GenerateKey(rand.Reader)
+	cases := []struct {
+		name    string
+		body    []byte
+		ts, sig string
+		now     time.Time
+	}{
+		{"tampered body", []byte(`{"data":{"event_type":"message.sent"}}`), ts, sig, now},
+		{"other key", body, ts, sign(other, ts, body), now},
+		{"moved timestamp", body, strconv.FormatInt(now.Unix()+1, 10), sig, now},
+		{"stale", body, ts, sig, now.Add(6 * time.Minute)},
+		{"from the future", body, ts, sig, now.Add(-6 * time.Minute)},
+		{"no timestamp", body, "", sig, now},
+		{"not base64", body, ts, "!!", now},
+		{"no signature", body, ts, "", now},
+	}
+	for _, c := range cases {
+		if err := Verify(pub, c.body, c.ts, c.sig, c.now, 5*time.Minute); !errors.Is(err, ErrSignature) {
+			t.Errorf("%s: err = %v, want ErrSignature", c.name, err)
+		}
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(rand.Reader)
+	got, err := ParsePublicKey(base64.StdEncoding.EncodeToString(pub))
+	if err != nil || !got.Equal(pub) {
+		t.Errorf("ParsePublicKey = %v, %v", got, err)
+	}
+	for _, bad := range []string{"", "not base64!", base64.StdEncoding.EncodeToString(pub[:16])} {
+		if _, err := ParsePublicKey(bad); err == nil {
+			t.Errorf("ParsePublicKey(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestDecodeEvent(t *testing.T) {
+	body := []byte(`{"data":{"event_type":"message.received","id":"ev-1","occurred_at":"2026-10-16T12:00:00Z",
+		"payload":{"id":"msg-1","direction":"inbound","from":{"phone_number":"+15555550100"},
+		"to":[{"phone_number":"+15555550199","status":"webhook_delivered"}],"text":"hi",
+		"media":[{"url":"https://example.com/a.jpg","content_type":"image/jpeg"}]}},"meta":{"attempt":1}}`)
+	e, err := DecodeEvent(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Data.ID != "ev-1" || e.Data.EventType != EventMessageReceived {
+		t.Errorf("event = %+v", e.Data)
+	}
+	m, err := e.Message()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.ID != "msg-1" || m.From.PhoneNumber != "+15555550100" || len(m.To) != 1 || m.To[0].PhoneNumber != "+15555550199" ||
+		m.Text != "hi" || len(m.Media) != 1 || m.Media[0].ContentType != "image/jpeg" {
+		t.Errorf("message = %+v", m)
+	}
+
+	for _, bad := range []string{`not json`, `{"data":{}}`} {
+		if _, err := DecodeEvent([]byte(bad)); err == nil {
+			t.Errorf("DecodeEvent(%s) succeeded", bad)
+		}
+	}
+}
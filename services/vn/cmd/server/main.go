@@ -30,10 +30,12 @@ import (
 	"context"
 	_ "embed"
 	"flag"
-	"log"
+	"fmt"
+	"log/slog"
 	"os"
 	"time"
 
+	"github.com/jredh-dev/nexus/internal/logging"
 	gohttp "github.com/jredh-dev/nexus/services/go-http"
 	"github.com/jredh-dev/nexus/services/vn/internal/database"
 	"github.com/jredh-dev/nexus/services/vn/internal/engine"
@@ -50,14 +52,19 @@ func main() {
 	enableDocs := flag.Bool("docs", false, "Enable Swagger UI at /docs (local dev only)")
 	flag.Parse()
 
+	if err := logging.Setup("vn"); err != nil {
+		fmt.Fprintf(os.Stderr, "vn: %v\n", err)
+		os.Exit(1)
+	}
+
 	port := envOr("PORT", "8080")
 	dbURL := envOr("DATABASE_URL", "host=localhost port=5432 dbname=vn user=jredh")
 	storyDir := envOr("STORY_DIR", "./stories")
 	hotReload := envOr("HOT_RELOAD", "true")
 	adminEnabled := envOr("ADMIN_ENABLED", "false") == "true"
 
-	log.Printf("[vn] starting: port=%s db=%s stories=%s hot_reload=%s admin=%v",
-		port, dbURL, storyDir, hotReload, adminEnabled)
+	slog.Info("vn starting", "port", port, "db", dbURL, "stories", storyDir,
+		"hot_reload", hotReload, "admin", adminEnabled)
 
 	// Connect to PostgreSQL and run migrations.
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
@@ -65,10 +72,10 @@ func main() {
 
 	db, err := database.New(ctx, dbURL)
 	if err != nil {
-		log.Fatalf("[vn] database: %v", err)
+		logging.Fatal("database", "err", err)
 	}
 	defer db.Close()
-	log.Println("[vn] database connected, migrations applied")
+	slog.Info("database connected, migrations applied")
 
 	// Load story definitions.
 	var nav *engine.Navigator
@@ -78,24 +85,22 @@ func main() {
 		// Hot-reload mode: watch story dir for changes, atomically swap the
 		// navigator's story on each reload.
 		loader, err = engine.NewHotLoader(storyDir, func(s *engine.Story) {
-			log.Printf("[vn] story reloaded: %q v%d (%d chapters)",
-				s.Title, s.Version, len(s.Chapters))
+			slog.Info("story reloaded", "title", s.Title, "version", s.Version, "chapters", len(s.Chapters))
 		})
 		if err != nil {
-			log.Fatalf("[vn] hot-reload story from %s: %v", storyDir, err)
+			logging.Fatal("hot-reload story", "dir", storyDir, "err", err)
 		}
 		defer loader.Close()
 		nav = engine.NewNavigator(loader.Story())
-		log.Printf("[vn] hot-reload enabled, watching %s", storyDir)
+		slog.Info("hot-reload enabled", "dir", storyDir)
 	} else {
 		// Static mode: load once at startup.
 		story, err := engine.LoadStoryDir(storyDir)
 		if err != nil {
-			log.Fatalf("[vn] load story from %s: %v", storyDir, err)
+			logging.Fatal("load story", "dir", storyDir, "err", err)
 		}
 		nav = engine.NewNavigator(story)
-		log.Printf("[vn] story loaded: %q v%d (%d chapters)",
-			story.Title, story.Version, len(story.Chapters))
+		slog.Info("story loaded", "title", story.Title, "version", story.Version, "chapters", len(story.Chapters))
 	}
 
 	// Build and start the HTTP server.
@@ -113,13 +118,13 @@ func main() {
 
 	// Register cleanup: close DB on graceful shutdown.
 	srv.OnStop(func() {
-		log.Println("[vn] shutting down database connection")
+		slog.Info("shutting down database connection")
 		db.Close()
 	})
 
-	log.Printf("[vn] listening on :%s", port)
+	slog.Info("vn listening", "addr", ":"+port)
 	if err := srv.ListenAndServe(":" + port); err != nil {
-		log.Fatalf("[vn] server: %v", err)
+		logging.Fatal("server", "err", err)
 	}
 }
 
@@ -2,7 +2,7 @@ package engine
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"path/filepath"
 	"strings"
 	"sync/atomic"
@@ -87,17 +87,16 @@ func (hl *HotLoader) watch() {
 				continue
 			}
 
-			log.Printf("[vn/engine] detected change: %s %s", event.Op, filepath.Base(event.Name))
+			slog.Debug("story change detected", "op", event.Op.String(), "file", filepath.Base(event.Name))
 
 			story, err := LoadStoryDir(hl.storyDir)
 			if err != nil {
-				log.Printf("[vn/engine] reload failed (keeping old story): %v", err)
+				slog.Error("story reload failed; keeping the old story", "err", err)
 				continue
 			}
 
 			hl.story.Store(story)
-			log.Printf("[vn/engine] story reloaded: %q (%d chapters)",
-				story.Title, len(story.Chapters))
+			slog.Debug("story swapped", "title", story.Title, "chapters", len(story.Chapters))
 
 			if hl.onReload != nil {
 				hl.onReload(story)
@@ -107,7 +106,7 @@ func (hl *HotLoader) watch() {
 			if !ok {
 				return
 			}
-			log.Printf("[vn/engine] watcher error: %v", err)
+			slog.Error("story watcher", "err", err)
 		}
 	}
 }
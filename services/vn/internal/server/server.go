@@ -3,6 +3,7 @@
 // Routes:
 //
 //	GET    /health                    — health check
+//	GET    /metrics                   — Prometheus metrics
 //	GET    /api/story                 — story metadata (chapters, current state)
 //	POST   /api/story/start           — start/resume reading (returns current node)
 //	POST   /api/story/advance         — advance to next node or make a choice
@@ -51,7 +52,7 @@ type Config struct {
 
 // New creates a vn HTTP server with all routes registered.
 func New(cfg Config) *gohttp.Server {
-	srv := gohttp.New()
+	srv := gohttp.New("vn")
 
 	// Wire up hot-reload: when story changes, update the navigator.
 	if cfg.Loader != nil {
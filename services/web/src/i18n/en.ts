@@ -77,6 +77,17 @@ const en = {
   'signup.hasAccount': 'Already have an account?',
   'signup.loginLink': 'Login',
 
+  // Phone verification page
+  'verifyPhone.title': 'Verify Phone',
+  'verifyPhone.heading': 'Verify Your Phone',
+  'verifyPhone.subtitle': 'We texted a 6-digit code to the number you signed up with.',
+  'verifyPhone.codeLabel': 'Verification code',
+  'verifyPhone.submit': 'Verify',
+  'verifyPhone.noCode': "Didn't get it?",
+  'verifyPhone.resend': 'Send a new code',
+  'verifyPhone.resent': 'A new code is on its way.',
+  'verifyPhone.failed': 'Something went wrong. Please try again.',
+
   // Dashboard page
   'dashboard.title': 'Dashboard',
   'dashboard.heading': 'Dashboard',
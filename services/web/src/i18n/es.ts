@@ -79,6 +79,17 @@ const es: Record<TranslationKey, string> = {
   'signup.hasAccount': '¿Ya tienes una cuenta?',
   'signup.loginLink': 'Iniciar sesión',
 
+  // Phone verification page
+  'verifyPhone.title': 'Verificar teléfono',
+  'verifyPhone.heading': 'Verifica tu teléfono',
+  'verifyPhone.subtitle': 'Enviamos un código de 6 dígitos al número con el que te registraste.',
+  'verifyPhone.codeLabel': 'Código de verificación',
+  'verifyPhone.submit': 'Verificar',
+  'verifyPhone.noCode': '¿No lo recibiste?',
+  'verifyPhone.resend': 'Enviar un código nuevo',
+  'verifyPhone.resent': 'Un código nuevo está en camino.',
+  'verifyPhone.failed': 'Algo salió mal. Inténtalo de nuevo.',
+
   // Dashboard page
   'dashboard.title': 'Panel',
   'dashboard.heading': 'Panel',
@@ -92,6 +92,15 @@ test('GET /signup returns 200', async () => {
   assert.equal(resp.status, 200, `expected 200, got ${resp.status}`);
 });
 
+// --- GET /verify-phone exists and asks for a session ---
+
+test('GET /en/verify-phone without a session redirects to login', async () => {
+  const resp = await fetchNoFollow(`${BASE_URL}/en/verify-phone`);
+  assert.equal(resp.status, 302, `expected 302, got ${resp.status}`);
+  const location = resp.headers.get('location') ?? '';
+  assert.ok(location.endsWith('/en/login'), `expected redirect to /en/login, got Location: ${location}`);
+});
+
 // --- GET /logout redirects to / ---
 
 test('GET /logout redirects to /', async () => {
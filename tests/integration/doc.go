@@ -9,6 +9,8 @@
 //
 //	HERMIT_ADDR         gRPC address (default: localhost:9090)
 //	HERMIT_SECRET       shared secret for x-hermit-secret header (optional)
+//	HERMIT_TOKEN        Login token, when hermit runs with --users (optional)
+//	HERMIT_USER         user to log in as with HERMIT_TOKEN (default: integration)
 //	HERMIT_INSECURE     set to "true" to disable TLS (default: TLS with system CAs)
 //	HERMIT_BEARER_TOKEN OAuth2/IAM bearer token for Cloud Run auth (optional)
 //	SECRETS_URL         HTTP base URL (default: http://localhost:8082)